@@ -0,0 +1,454 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"raito/internal/backup"
+	"raito/internal/bootstrap"
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/migrate"
+	"raito/internal/scraper"
+	"raito/internal/store"
+)
+
+// subcommands are administrative commands run instead of the server when
+// the first argument names one of them, e.g. `raito-api doctor`.
+var subcommands = map[string]func(args []string) error{
+	"create-user":    runCreateUser,
+	"create-api-key": runCreateAPIKey,
+	"migrate":        runMigrate,
+	"doctor":         runDoctor,
+	"backup":         runBackup,
+	"restore":        runRestore,
+	// browser-render is started by the server itself when
+	// rod.isolation is "process"; it is not meant to be run by hand.
+	scraper.BrowserRenderCommand: runBrowserRender,
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configPath := fs.String("config", "config/config.yaml", "path to config file")
+	return fs, configPath
+}
+
+func openStore(cfg *config.Config) (*store.Store, error) {
+	conn, err := sql.Open("pgx", cfg.Database.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	return store.New(conn), nil
+}
+
+func runCreateUser(args []string) error {
+	fs, configPath := newFlagSet("create-user")
+	email := fs.String("email", "", "email of the new user (required)")
+	name := fs.String("name", "", "display name")
+	password := fs.String("password", "", "password; generated and printed when empty")
+	isAdmin := fs.Bool("admin", false, "make the user a system admin")
+	mustChange := fs.Bool("must-change-password", false, "require a password change on first login (always set for generated passwords)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*email) == "" {
+		return errors.New("-email is required")
+	}
+
+	generated := *password == ""
+	if generated {
+		p, err := bootstrap.GeneratePassword()
+		if err != nil {
+			return err
+		}
+		*password = p
+	}
+
+	cfg := config.Load(*configPath)
+	st, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.DB.Close()
+
+	created, err := bootstrap.CreateUser(context.Background(), st, config.BootstrapUserConfig{
+		Email:              *email,
+		Name:               *name,
+		IsSystemAdmin:      *isAdmin,
+		Provider:           "local",
+		Password:           *password,
+		MustChangePassword: *mustChange || generated,
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		return fmt.Errorf("a user with email %s already exists", *email)
+	}
+
+	fmt.Printf("created user %s\n", strings.ToLower(strings.TrimSpace(*email)))
+	if generated {
+		fmt.Printf("one-time password: %s\n", *password)
+	}
+	return nil
+}
+
+func runCreateAPIKey(args []string) error {
+	fs, configPath := newFlagSet("create-api-key")
+	label := fs.String("label", "cli", "label for the key")
+	isAdmin := fs.Bool("admin", false, "create a system admin key")
+	tenant := fs.String("tenant", "", "tenant ID to scope the key to")
+	rateLimit := fs.Int("rate-limit", 0, "requests per minute (0 uses the server default)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.Load(*configPath)
+	st, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.DB.Close()
+
+	ctx := context.Background()
+	var tenantID *string
+	if *tenant != "" {
+		id, err := uuid.Parse(*tenant)
+		if err != nil {
+			return fmt.Errorf("-tenant must be a valid UUID: %w", err)
+		}
+		if _, err := db.New(st.DB).GetTenantByID(ctx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("tenant %s not found", id)
+			}
+			return err
+		}
+		s := id.String()
+		tenantID = &s
+	}
+	var limit *int
+	if *rateLimit > 0 {
+		limit = rateLimit
+	}
+
+	raw, key, err := st.CreateRandomAPIKey(ctx, *label, *isAdmin, limit, tenantID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created api key %s (%s)\n", key.ID, key.Label)
+	fmt.Println(raw)
+	return nil
+}
+
+// runMigrate handles `migrate [up|down|status] [-to N]`. The action
+// defaults to up; -to migrates up or down to an exact version.
+func runMigrate(args []string) error {
+	action := "up"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	fs, configPath := newFlagSet("migrate")
+	to := fs.Int64("to", -1, "target schema version to migrate up or down to; applies all pending migrations when unset")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.Load(*configPath)
+	dsn := cfg.Database.DSN
+	switch action {
+	case "up":
+		if *to < 0 {
+			if err := migrate.Run(dsn); err != nil {
+				return err
+			}
+			fmt.Println("migrations applied")
+			return nil
+		}
+		if err := migrate.To(dsn, *to); err != nil {
+			return err
+		}
+		fmt.Printf("schema migrated to version %d\n", *to)
+		return nil
+	case "down":
+		if *to >= 0 {
+			return errors.New("use `migrate -to N` to roll back to a specific version")
+		}
+		if err := migrate.Down(dsn); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("unknown migrate action %q (expected up, down, or status)", action)
+	}
+
+	status, err := migrate.StatusFor(dsn)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current version: %d\nlatest version:  %d\n", status.CurrentVersion, status.LatestVersion)
+	if status.Ahead {
+		fmt.Println("database is ahead of this binary; roll back with the newer release before downgrading")
+	}
+	for _, m := range status.Pending {
+		fmt.Printf("pending: %s\n", m.Name)
+	}
+	return nil
+}
+
+func runBackup(args []string) error {
+	fs, configPath := newFlagSet("backup")
+	out := fs.String("o", "", "output archive path (required)")
+	includeJobs := fs.Bool("include-jobs", false, "include jobs and the data recorded for them, such as documents")
+	includeHashes := fs.Bool("include-password-hashes", false, "keep local users' password hashes")
+	includeConfig := fs.Bool("include-config", false, "add the config file (contains secrets) to the archive")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("-o is required")
+	}
+
+	cfg := config.Load(*configPath)
+	st, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.DB.Close()
+
+	f, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	opts := backup.Options{IncludeJobs: *includeJobs, IncludePasswordHashes: *includeHashes}
+	if *includeConfig {
+		opts.ConfigPath = *configPath
+	}
+	m, err := backup.Export(context.Background(), st.DB, f, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(*out)
+		return err
+	}
+
+	fmt.Printf("wrote %s (schema version %d)\n", *out, m.SchemaVersion)
+	for _, name := range sortedKeys(m.Tables) {
+		fmt.Printf("  %-14s %d rows\n", name, m.Tables[name])
+	}
+	return nil
+}
+
+func runRestore(args []string) error {
+	fs, configPath := newFlagSet("restore")
+	in := fs.String("i", "", "archive path to restore (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return errors.New("-i is required")
+	}
+
+	cfg := config.Load(*configPath)
+	st, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.DB.Close()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	res, err := backup.Restore(context.Background(), st.DB, f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored backup from %s (schema version %d)\n", res.Manifest.CreatedAt.Format(time.RFC3339), res.Manifest.SchemaVersion)
+	for _, name := range sortedKeys(res.Restored) {
+		fmt.Printf("  %-14s %d restored, %d skipped\n", name, res.Restored[name], res.Skipped[name])
+	}
+	if res.Manifest.IncludesConfig {
+		fmt.Println("the archive contains config.yaml; copy it into place manually if needed")
+	}
+	return nil
+}
+
+// runBrowserRender serves one browser scrape or screenshot read from
+// stdin and writes the result to stdout. A crash here only fails the
+// URL being rendered, not the worker that started the process.
+func runBrowserRender(args []string) error {
+	return scraper.ServeBrowserRender(context.Background(), os.Stdin, os.Stdout)
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// doctorCheck is the outcome of a single doctor check.
+type doctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+func runDoctor(args []string) error {
+	fs, configPath := newFlagSet("doctor")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.Load(*configPath)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var checks []doctorCheck
+	checks = append(checks, checkDatabase(ctx, cfg))
+	checks = append(checks, checkRedis(ctx, cfg))
+	checks = append(checks, checkChromium(cfg))
+	checks = append(checks, checkLLM(cfg)...)
+
+	if failed := printDoctorChecks(os.Stdout, checks); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func printDoctorChecks(w io.Writer, checks []doctorCheck) int {
+	failed := 0
+	for _, c := range checks {
+		status := "ok"
+		if !c.OK {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(w, "[%-4s] %-22s %s\n", status, c.Name, c.Detail)
+	}
+	return failed
+}
+
+func checkDatabase(ctx context.Context, cfg *config.Config) doctorCheck {
+	c := doctorCheck{Name: "database"}
+	conn, err := sql.Open("pgx", cfg.Database.DSN)
+	if err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	defer conn.Close()
+	if err := conn.PingContext(ctx); err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	c.OK = true
+	c.Detail = "connected"
+	return c
+}
+
+func checkRedis(ctx context.Context, cfg *config.Config) doctorCheck {
+	c := doctorCheck{Name: "redis"}
+	if cfg.Redis.URL == "" {
+		c.OK = true
+		c.Detail = "not configured; rate limiting is disabled"
+		return c
+	}
+	opt, err := redis.ParseURL(cfg.Redis.URL)
+	if err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	rdb := redis.NewClient(opt)
+	defer rdb.Close()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	c.OK = true
+	c.Detail = "connected"
+	return c
+}
+
+func checkChromium(cfg *config.Config) doctorCheck {
+	c := doctorCheck{Name: "chromium"}
+	if !cfg.Rod.Enabled {
+		c.OK = true
+		c.Detail = "rod is disabled; browser rendering is unavailable"
+		return c
+	}
+	path, has := launcher.LookPath()
+	if !has {
+		c.Detail = "no local Chromium found; rod will try to download one on first use"
+		return c
+	}
+	c.OK = true
+	c.Detail = path
+	return c
+}
+
+// checkLLM reports whether each LLM provider has credentials. Only the
+// default provider is required; the others fail only when partially
+// configured. Credentials are checked for presence, not validated against
+// the provider.
+func checkLLM(cfg *config.Config) []doctorCheck {
+	providers := []struct {
+		name   string
+		apiKey string
+		model  string
+	}{
+		{"openai", cfg.LLM.OpenAI.APIKey, cfg.LLM.OpenAI.Model},
+		{"anthropic", cfg.LLM.Anthropic.APIKey, cfg.LLM.Anthropic.Model},
+		{"google", cfg.LLM.Google.APIKey, cfg.LLM.Google.Model},
+	}
+
+	defaultProvider := strings.TrimSpace(cfg.LLM.DefaultProvider)
+	var checks []doctorCheck
+	known := false
+	for _, p := range providers {
+		c := doctorCheck{Name: "llm." + p.name}
+		isDefault := p.name == defaultProvider
+		known = known || isDefault
+		switch {
+		case p.apiKey != "" && p.model != "":
+			c.OK = true
+			c.Detail = "credentials configured (model " + p.model + ")"
+		case p.apiKey == "" && p.model == "" && !isDefault:
+			c.OK = true
+			c.Detail = "not configured"
+		case p.apiKey == "":
+			c.Detail = "apiKey is missing"
+		default:
+			c.Detail = "model is missing"
+		}
+		if isDefault {
+			c.Detail += " [default]"
+		}
+		checks = append(checks, c)
+	}
+	if !known {
+		checks = append(checks, doctorCheck{
+			Name:   "llm.defaultProvider",
+			Detail: fmt.Sprintf("%q is not one of openai, anthropic, google", defaultProvider),
+		})
+	}
+	return checks
+}
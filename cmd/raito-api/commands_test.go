@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"raito/internal/config"
+)
+
+func TestCheckLLM(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.DefaultProvider = "openai"
+	cfg.LLM.OpenAI = config.OpenAIConfig{APIKey: "sk-test", Model: "gpt-4o-mini"}
+	cfg.LLM.Anthropic.Model = "claude"
+
+	got := map[string]bool{}
+	for _, c := range checkLLM(cfg) {
+		got[c.Name] = c.OK
+	}
+	want := map[string]bool{
+		"llm.openai":    true,  // default, fully configured
+		"llm.anthropic": false, // model without apiKey
+		"llm.google":    true,  // not configured and not default
+	}
+	for name, ok := range want {
+		if got[name] != ok {
+			t.Fatalf("%s: got ok=%v, want %v (all: %v)", name, got[name], ok, got)
+		}
+	}
+
+	cfg.LLM.DefaultProvider = "bogus"
+	checks := checkLLM(cfg)
+	if last := checks[len(checks)-1]; last.Name != "llm.defaultProvider" || last.OK {
+		t.Fatalf("expected failing defaultProvider check, got %+v", last)
+	}
+}
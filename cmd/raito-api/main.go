@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"log"
 	"log/slog"
@@ -13,12 +14,25 @@ import (
 
 	"raito/internal/bootstrap"
 	"raito/internal/config"
+	"raito/internal/dnscache"
+	"raito/internal/events"
 	server "raito/internal/http"
 	"raito/internal/migrate"
 	"raito/internal/store"
 )
 
 func main() {
+	// Administrative subcommands (create-user, create-api-key, migrate,
+	// doctor) run instead of the server.
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+				log.Fatalf("%s: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
 	configPath := flag.String("config", "config/config.yaml", "path to config file")
 	role := flag.String("role", "all", "process role: api|worker|all")
 	flag.Parse()
@@ -26,8 +40,16 @@ func main() {
 	cfg := config.Load(*configPath)
 
 	if err := cfg.Validate(); err != nil {
-		log.Fatalf("invalid configuration: %v", err)
+		log.Fatalf("invalid configuration %s: %v", *configPath, err)
 	}
+	for _, is := range cfg.Check() {
+		if is.Severity == config.SeverityWarning {
+			log.Printf("config warning: %s", is)
+		}
+	}
+
+	// Scrapes, crawls and outbound fetches share one resolver and cache.
+	dnscache.SetDefault(dnscache.New(dnsOptions(cfg.DNS)))
 
 	// Only API-capable roles run migrations/bootstraps. In Docker Compose we run
 	// migrations in the API container and keep workers migration-free to avoid
@@ -51,6 +73,20 @@ func main() {
 	db.SetConnMaxLifetime(30 * time.Minute)
 
 	st := store.New(db)
+	st.LegacyJobInputs = cfg.Worker.LegacyJobInputs
+
+	// Live job timelines and other cross-replica events.
+	bus, err := events.New(context.Background(), events.Options{
+		Transport: cfg.Events.Transport,
+		RedisURL:  cfg.Redis.URL,
+		DB:        db,
+		DSN:       cfg.Database.DSN,
+	})
+	if err != nil {
+		log.Printf("events: %v; falling back to in-process events", err)
+		bus = events.NewMemory()
+	}
+	events.SetDefault(bus)
 
 	// Ensure initial admin API key if configured
 	if runMigrationsAndBootstrap && cfg.Auth.Enabled && cfg.Auth.InitialAdminKey != "" {
@@ -72,6 +108,9 @@ func main() {
 
 	rootCtx := context.Background()
 
+	// Restore saved /metrics counters before serving, when enabled.
+	server.StartMetricsPersister(rootCtx, cfg, st, *role)
+
 	switch *role {
 	case "api":
 		// API-only: do not start crawl worker.
@@ -94,3 +133,19 @@ func main() {
 		log.Fatalf("invalid role: %s (expected api|worker|all)", *role)
 	}
 }
+
+// dnsOptions converts the dns config block into resolver options; zero
+// values keep the resolver's defaults.
+func dnsOptions(c config.DNSConfig) dnscache.Options {
+	return dnscache.Options{
+		Cache:       c.CacheEnabled,
+		TTL:         time.Duration(c.TTLSeconds) * time.Second,
+		MinTTL:      time.Duration(c.MinTTLSeconds) * time.Second,
+		MaxTTL:      time.Duration(c.MaxTTLSeconds) * time.Second,
+		NegativeTTL: time.Duration(c.NegativeTTLSeconds) * time.Second,
+		MaxEntries:  c.MaxEntries,
+		Nameservers: c.Nameservers,
+		DoHURL:      c.DoHURL,
+		Timeout:     time.Duration(c.TimeoutMs) * time.Millisecond,
+	}
+}
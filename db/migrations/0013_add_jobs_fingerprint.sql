@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS fingerprint TEXT;
+
+-- Only one in-flight job may hold a given fingerprint at a time; completed and
+-- failed jobs keep their fingerprint for debugging but no longer conflict.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_fingerprint_inflight
+    ON jobs(fingerprint)
+    WHERE fingerprint IS NOT NULL AND status IN ('pending', 'running');
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_fingerprint_inflight;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS fingerprint;
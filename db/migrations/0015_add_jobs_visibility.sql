@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS created_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'shared';
+
+ALTER TABLE jobs
+    ADD CONSTRAINT chk_jobs_visibility CHECK (visibility IN ('private', 'shared'));
+
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant_visibility ON jobs(tenant_id, visibility);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_tenant_visibility;
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS chk_jobs_visibility;
+ALTER TABLE jobs DROP COLUMN IF EXISTS visibility;
+ALTER TABLE jobs DROP COLUMN IF EXISTS created_by_user_id;
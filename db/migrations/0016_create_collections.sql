@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS collections (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    default_options JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_collections_tenant_name UNIQUE (tenant_id, name)
+);
+
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS collection_id UUID REFERENCES collections(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_collection_id ON jobs(collection_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_collection_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS collection_id;
+DROP TABLE IF EXISTS collections;
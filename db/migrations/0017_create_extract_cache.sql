@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS extract_cache (
+    cache_key TEXT PRIMARY KEY,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    schema_hash TEXT NOT NULL,
+    prompt_hash TEXT NOT NULL,
+    result JSONB NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_extract_cache_expires_at ON extract_cache(expires_at);
+CREATE INDEX IF NOT EXISTS idx_extract_cache_tenant_url ON extract_cache(tenant_id, url);
+
+-- +goose Down
+DROP TABLE IF EXISTS extract_cache;
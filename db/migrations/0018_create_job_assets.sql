@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS job_assets (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    source_url TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_job_assets_job_source UNIQUE (job_id, source_url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_assets_job_id ON job_assets(job_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_assets;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS previous_job_id UUID REFERENCES jobs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_crawl_root_completed
+    ON jobs(tenant_id, url, completed_at DESC)
+    WHERE type = 'crawl' AND status = 'completed';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_crawl_root_completed;
+ALTER TABLE jobs DROP COLUMN IF EXISTS previous_job_id;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS metrics JSONB;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS metrics;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS request_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS created_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- One-time tokens for the "reveal once" key creation flow. Rows are
+-- deleted when the key is revealed or once they expire.
+CREATE TABLE IF NOT EXISTS api_key_reveals (
+    token_hash TEXT PRIMARY KEY,
+    api_key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    raw_key TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_key_reveals;
+ALTER TABLE api_keys
+    DROP COLUMN IF EXISTS created_by_user_id,
+    DROP COLUMN IF EXISTS request_count,
+    DROP COLUMN IF EXISTS last_used_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS pool TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_pool
+    ON jobs (pool, priority DESC, created_at)
+    WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_pending_pool;
+ALTER TABLE jobs DROP COLUMN IF EXISTS pool;
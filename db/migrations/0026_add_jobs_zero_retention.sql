@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS zero_retention BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS purged_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_zero_retention_unpurged
+    ON jobs (completed_at)
+    WHERE zero_retention AND purged_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_zero_retention_unpurged;
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS purged_at,
+    DROP COLUMN IF EXISTS zero_retention;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS applied_options JSONB;
+
+-- +goose Down
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS applied_options;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tenant_llm_budgets (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    -- NULL caps are unlimited.
+    monthly_token_cap BIGINT,
+    -- monthly_spend_cap_micros is in millionths of a US dollar.
+    monthly_spend_cap_micros BIGINT,
+    updated_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS tenant_llm_usage (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    -- period is the first day (UTC) of the month the usage belongs to.
+    period DATE NOT NULL,
+    calls BIGINT NOT NULL DEFAULT 0,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    spend_micros BIGINT NOT NULL DEFAULT 0,
+    -- notified_percent is the highest budget threshold (80 or 100) that
+    -- tenant admins have been notified about this period.
+    notified_percent INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, period)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS tenant_llm_usage;
+DROP TABLE IF EXISTS tenant_llm_budgets;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS prompt_templates (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    -- kind is the format the template applies to: summary, branding,
+    -- json or extract.
+    kind TEXT NOT NULL,
+    -- Saving a template adds a new version; older versions are kept so
+    -- requests can pin one.
+    version INTEGER NOT NULL,
+    prompt TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name, version)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS prompt_templates;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tenant_transform_hooks (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    timeout_ms INTEGER NOT NULL,
+    -- failure_policy is keep, drop, or fail.
+    failure_policy TEXT NOT NULL,
+    -- signing_secret names a tenant secret used to sign hook requests.
+    signing_secret TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS tenant_transform_hooks;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- type tags what a document holds: 'page' for scraped pages and 'extract'
+-- for per-URL extract results.
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'page';
+
+-- +goose Down
+ALTER TABLE documents DROP COLUMN IF EXISTS type;
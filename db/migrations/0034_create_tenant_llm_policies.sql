@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tenant_llm_policies (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    -- allowed_models is a JSON array of "provider/model", "provider/*" or
+    -- "provider" entries. An empty array allows every configured model.
+    allowed_models JSONB NOT NULL DEFAULT '[]'::jsonb,
+    -- default_provider and default_model apply when a request does not
+    -- choose a provider or model. NULL falls back to the server defaults.
+    default_provider TEXT,
+    default_model TEXT,
+    updated_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS tenant_llm_policies;
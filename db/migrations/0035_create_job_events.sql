@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS job_events (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    -- type is the event kind: a job status ("pending", "running",
+    -- "completed", "failed") or a milestone such as "claimed" or
+    -- "discovery_finished".
+    type TEXT NOT NULL,
+    message TEXT,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_events_job_id ON job_events(job_id, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_events;
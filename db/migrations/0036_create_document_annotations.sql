@@ -0,0 +1,20 @@
+-- +goose Up
+-- document_annotations holds user edits to stored documents. The scraped
+-- content in documents is never modified.
+CREATE TABLE IF NOT EXISTS document_annotations (
+    document_id BIGINT PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    notes TEXT,
+    -- title overrides the scraped metadata title when set.
+    title TEXT,
+    -- excluded documents are left out of downloads by default.
+    excluded BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_annotations_job_id ON document_annotations(job_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS document_annotations;
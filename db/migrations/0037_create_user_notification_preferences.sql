@@ -0,0 +1,16 @@
+-- +goose Up
+-- user_notification_preferences holds each user's subscription to
+-- completion and failure notifications for the jobs they create.
+CREATE TABLE IF NOT EXISTS user_notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    on_completed BOOLEAN NOT NULL DEFAULT FALSE,
+    on_failed BOOLEAN NOT NULL DEFAULT FALSE,
+    -- email notifications go to users.email.
+    email BOOLEAN NOT NULL DEFAULT FALSE,
+    webhook_url TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_notification_preferences;
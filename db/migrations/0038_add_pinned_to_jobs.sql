@@ -0,0 +1,6 @@
+-- +goose Up
+-- Pinned jobs and their documents are exempt from TTL retention cleanup.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS pinned;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tenant_webhook_secrets (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    -- secret_encrypted holds the AES-GCM nonce followed by the ciphertext.
+    secret_encrypted BYTEA NOT NULL,
+    -- The secret replaced by the last rotation keeps signing deliveries
+    -- until previous_expires_at so receivers can switch over.
+    previous_secret_encrypted BYTEA,
+    previous_expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS tenant_webhook_secrets;
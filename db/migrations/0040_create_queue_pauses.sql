@@ -0,0 +1,12 @@
+-- +goose Up
+-- Each row pauses the claiming of pending jobs of one type, or of every
+-- type when job_type is '*'. Running jobs are not affected.
+CREATE TABLE IF NOT EXISTS queue_pauses (
+    job_type TEXT PRIMARY KEY,
+    reason TEXT,
+    paused_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    paused_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS queue_pauses;
@@ -0,0 +1,35 @@
+-- +goose Up
+-- Scrape outcomes per target host in hourly buckets. Every API and worker
+-- process adds its counts, so GET /admin/hosts covers the deployment.
+-- The latency_* columns count requests by duration range: up to 100ms,
+-- 100-250ms, and so on up to latency_over_10s.
+CREATE TABLE IF NOT EXISTS host_stats (
+    host TEXT NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    status_2xx BIGINT NOT NULL DEFAULT 0,
+    status_3xx BIGINT NOT NULL DEFAULT 0,
+    status_4xx BIGINT NOT NULL DEFAULT 0,
+    status_5xx BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0,
+    tls_errors BIGINT NOT NULL DEFAULT 0,
+    timeouts BIGINT NOT NULL DEFAULT 0,
+    dns_errors BIGINT NOT NULL DEFAULT 0,
+    robots_blocked BIGINT NOT NULL DEFAULT 0,
+    latency_ms_sum BIGINT NOT NULL DEFAULT 0,
+    latency_100ms BIGINT NOT NULL DEFAULT 0,
+    latency_250ms BIGINT NOT NULL DEFAULT 0,
+    latency_500ms BIGINT NOT NULL DEFAULT 0,
+    latency_1s BIGINT NOT NULL DEFAULT 0,
+    latency_2500ms BIGINT NOT NULL DEFAULT 0,
+    latency_5s BIGINT NOT NULL DEFAULT 0,
+    latency_10s BIGINT NOT NULL DEFAULT 0,
+    latency_over_10s BIGINT NOT NULL DEFAULT 0,
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (host, bucket)
+);
+
+CREATE INDEX IF NOT EXISTS idx_host_stats_bucket ON host_stats (bucket);
+
+-- +goose Down
+DROP TABLE IF EXISTS host_stats;
@@ -0,0 +1,120 @@
+-- +goose Up
+-- document_changes is the change feed behind GET /v1/documents/changes.
+-- Triggers record document inserts and deletes and annotation edits, so
+-- deletes by retention cleanup and cascading job deletes are included.
+-- The job's tenant and visibility are copied into each row because a
+-- deleted document's job may be gone by the time the feed is read.
+CREATE TABLE IF NOT EXISTS document_changes (
+    seq BIGSERIAL PRIMARY KEY,
+    tenant_id UUID,
+    document_id BIGINT NOT NULL,
+    job_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    change_type TEXT NOT NULL CHECK (change_type IN ('created', 'updated', 'deleted')),
+    visibility TEXT NOT NULL DEFAULT 'shared',
+    created_by_user_id UUID,
+    api_key_id UUID,
+    -- changed_at is the wall-clock time the row was written, not the
+    -- transaction start, so the feed can hold back rows of transactions
+    -- still in flight.
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_changes_tenant_seq ON document_changes (tenant_id, seq);
+CREATE INDEX IF NOT EXISTS idx_document_changes_document_seq ON document_changes (document_id, seq);
+CREATE INDEX IF NOT EXISTS idx_document_changes_changed_at ON document_changes (changed_at);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_document_change(p_document_id BIGINT, p_job_id UUID, p_url TEXT, p_change TEXT)
+RETURNS VOID AS $$
+BEGIN
+    -- Nothing is recorded when the job is gone: documents deleted along
+    -- with their job were recorded by jobs_record_document_deletes.
+    INSERT INTO document_changes (tenant_id, document_id, job_id, url, change_type, visibility, created_by_user_id, api_key_id)
+    SELECT j.tenant_id, p_document_id, j.id, p_url, p_change, j.visibility, j.created_by_user_id, j.api_key_id
+    FROM jobs j
+    WHERE j.id = p_job_id;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION documents_record_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM record_document_change(NEW.id, NEW.job_id, NEW.url, 'created');
+    ELSIF TG_OP = 'UPDATE' THEN
+        PERFORM record_document_change(NEW.id, NEW.job_id, NEW.url, 'updated');
+    ELSE
+        PERFORM record_document_change(OLD.id, OLD.job_id, OLD.url, 'deleted');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION document_annotations_record_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    doc RECORD;
+BEGIN
+    -- Annotations removed along with their document are not an update.
+    SELECT id, job_id, url INTO doc
+    FROM documents
+    WHERE id = COALESCE(NEW.document_id, OLD.document_id);
+    IF FOUND THEN
+        PERFORM record_document_change(doc.id, doc.job_id, doc.url, 'updated');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION jobs_record_document_deletes()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO document_changes (tenant_id, document_id, job_id, url, change_type, visibility, created_by_user_id, api_key_id)
+    SELECT OLD.tenant_id, d.id, OLD.id, d.url, 'deleted', OLD.visibility, OLD.created_by_user_id, OLD.api_key_id
+    FROM documents d
+    WHERE d.job_id = OLD.id
+    ORDER BY d.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS trg_documents_record_change ON documents;
+CREATE TRIGGER trg_documents_record_change
+    AFTER INSERT OR UPDATE OR DELETE ON documents
+    FOR EACH ROW EXECUTE FUNCTION documents_record_change();
+
+DROP TRIGGER IF EXISTS trg_document_annotations_record_change ON document_annotations;
+CREATE TRIGGER trg_document_annotations_record_change
+    AFTER INSERT OR UPDATE OR DELETE ON document_annotations
+    FOR EACH ROW EXECUTE FUNCTION document_annotations_record_change();
+
+DROP TRIGGER IF EXISTS trg_jobs_record_document_deletes ON jobs;
+CREATE TRIGGER trg_jobs_record_document_deletes
+    BEFORE DELETE ON jobs
+    FOR EACH ROW EXECUTE FUNCTION jobs_record_document_deletes();
+
+-- Existing documents start the feed, so a consumer reading from the
+-- beginning receives the whole corpus.
+INSERT INTO document_changes (tenant_id, document_id, job_id, url, change_type, visibility, created_by_user_id, api_key_id, changed_at)
+SELECT j.tenant_id, d.id, d.job_id, d.url, 'created', j.visibility, j.created_by_user_id, j.api_key_id, d.created_at
+FROM documents d
+JOIN jobs j ON j.id = d.job_id
+ORDER BY d.id;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_jobs_record_document_deletes ON jobs;
+DROP TRIGGER IF EXISTS trg_document_annotations_record_change ON document_annotations;
+DROP TRIGGER IF EXISTS trg_documents_record_change ON documents;
+DROP FUNCTION IF EXISTS jobs_record_document_deletes();
+DROP FUNCTION IF EXISTS document_annotations_record_change();
+DROP FUNCTION IF EXISTS documents_record_change();
+DROP FUNCTION IF EXISTS record_document_change(BIGINT, UUID, TEXT, TEXT);
+DROP TABLE IF EXISTS document_changes;
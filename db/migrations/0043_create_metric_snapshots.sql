@@ -0,0 +1,11 @@
+-- +goose Up
+-- Saved /metrics counters and histograms, one row per process instance,
+-- so totals survive restarts when metrics.persist is enabled.
+CREATE TABLE IF NOT EXISTS metric_snapshots (
+    instance TEXT PRIMARY KEY,
+    snapshot JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS metric_snapshots;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Requests to scrape some URLs of a finished crawl again. The crawl job is
+-- queued again and its worker scrapes only these URLs.
+CREATE TABLE IF NOT EXISTS crawl_rescrapes (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    -- urls is the JSON array of URLs to scrape again.
+    urls JSONB NOT NULL,
+    -- mode is "replace" (the new document takes the place of the URL's
+    -- earlier ones) or "version" (earlier documents are kept).
+    mode TEXT NOT NULL,
+    -- status is "pending", "running", "completed" or "failed".
+    status TEXT NOT NULL DEFAULT 'pending',
+    -- results is the JSON array of per-URL outcomes once the rescrape
+    -- has finished.
+    results JSONB,
+    error TEXT,
+    created_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_crawl_rescrapes_job_id ON crawl_rescrapes(job_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS crawl_rescrapes;
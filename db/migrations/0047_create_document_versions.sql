@@ -0,0 +1,27 @@
+-- +goose Up
+-- document_versions is the per-tenant content history of a URL behind
+-- GET /v1/documents/by-url. A version is recorded when a scrape's
+-- markdown differs from the URL's latest version, so repeated scrapes of
+-- an unchanged page only move last_seen_at. Versions outlive the jobs
+-- that recorded them, so the job's visibility is copied into each row.
+CREATE TABLE IF NOT EXISTS document_versions (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    markdown TEXT NOT NULL,
+    title TEXT,
+    status_code INT,
+    job_id UUID REFERENCES jobs(id) ON DELETE SET NULL,
+    visibility TEXT NOT NULL DEFAULT 'shared',
+    created_by_user_id UUID,
+    api_key_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_versions_tenant_url ON document_versions (tenant_id, url, id);
+CREATE INDEX IF NOT EXISTS idx_document_versions_last_seen_at ON document_versions (last_seen_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS document_versions;
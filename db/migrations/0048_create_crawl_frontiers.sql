@@ -0,0 +1,20 @@
+-- +goose Up
+-- The frontier of a paused crawl: the URLs it still has to scrape and the
+-- ones it has already admitted, so a resumed crawl continues where it
+-- stopped instead of discovering the site again.
+CREATE TABLE IF NOT EXISTS crawl_frontiers (
+    job_id UUID PRIMARY KEY REFERENCES jobs(id) ON DELETE CASCADE,
+    -- queued is the JSON array of URLs still to scrape, in order.
+    queued JSONB NOT NULL,
+    -- seen is the JSON array of every URL the crawl has queued, scraped
+    -- or refused, so none is queued twice.
+    seen JSONB NOT NULL,
+    -- admitted counts the URLs charged against the crawl's limit.
+    admitted INTEGER NOT NULL,
+    -- state holds the crawl's counters when it was paused.
+    state JSONB NOT NULL DEFAULT '{}',
+    paused_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS crawl_frontiers;
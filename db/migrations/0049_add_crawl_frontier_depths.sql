@@ -0,0 +1,7 @@
+-- +goose Up
+-- depths maps each queued URL of a paused crawl to its discovery depth,
+-- so the resumed crawl keeps honoring maxDiscoveryDepth.
+ALTER TABLE crawl_frontiers ADD COLUMN IF NOT EXISTS depths JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE crawl_frontiers DROP COLUMN IF EXISTS depths;
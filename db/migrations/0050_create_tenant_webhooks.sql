@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tenant_webhooks (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    -- url receives notifications for the tenant's jobs that do not set a
+    -- webhook of their own.
+    url TEXT NOT NULL,
+    -- events is a JSON array of the notification events delivered, such
+    -- as "job.completed" and "job.failed".
+    events JSONB NOT NULL,
+    updated_by_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS tenant_webhooks;
@@ -18,6 +18,11 @@ UPDATE api_keys
 SET revoked_at = NOW()
 WHERE id = $1 AND revoked_at IS NULL;
 
+-- name: TouchAPIKeyUsage :exec
+UPDATE api_keys
+SET last_used_at = NOW(), request_count = request_count + 1
+WHERE id = $1;
+
 -- name: GetAPIKeyLabelsByIDs :many
 SELECT id, label
 FROM api_keys
@@ -0,0 +1,29 @@
+-- name: InsertCollection :one
+INSERT INTO collections (id, tenant_id, name, description, default_options, created_by_user_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetCollectionByID :one
+SELECT *
+FROM collections
+WHERE id = $1;
+
+-- name: ListCollectionsByTenant :many
+SELECT *
+FROM collections
+WHERE tenant_id = $1
+ORDER BY name ASC;
+
+-- name: UpdateCollection :one
+UPDATE collections
+SET name = $2,
+    description = $3,
+    default_options = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCollection :execrows
+DELETE FROM collections
+WHERE id = $1;
+
@@ -0,0 +1,19 @@
+-- name: UpsertCrawlFrontier :exec
+INSERT INTO crawl_frontiers (job_id, queued, seen, admitted, state, depths)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (job_id) DO UPDATE
+SET queued = EXCLUDED.queued,
+    seen = EXCLUDED.seen,
+    admitted = EXCLUDED.admitted,
+    state = EXCLUDED.state,
+    depths = EXCLUDED.depths,
+    paused_at = NOW();
+
+-- name: GetCrawlFrontier :one
+SELECT job_id, queued, seen, admitted, state, paused_at, depths
+FROM crawl_frontiers
+WHERE job_id = $1;
+
+-- name: DeleteCrawlFrontier :exec
+DELETE FROM crawl_frontiers
+WHERE job_id = $1;
@@ -0,0 +1,38 @@
+-- name: InsertCrawlRescrape :one
+INSERT INTO crawl_rescrapes (id, job_id, urls, mode, created_by_user_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, job_id, urls, mode, status, results, error, created_by_user_id, created_at, completed_at;
+
+-- name: GetCrawlRescrape :one
+SELECT id, job_id, urls, mode, status, results, error, created_by_user_id, created_at, completed_at
+FROM crawl_rescrapes
+WHERE id = $1 AND job_id = $2;
+
+-- name: GetPendingCrawlRescrape :one
+-- Returns the rescrape a queued crawl job should run instead of a full
+-- crawl.
+SELECT id, job_id, urls, mode, status, results, error, created_by_user_id, created_at, completed_at
+FROM crawl_rescrapes
+WHERE job_id = $1 AND status = 'pending'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: UpdateCrawlRescrapeStatus :exec
+UPDATE crawl_rescrapes
+SET status = $2,
+    results = $3,
+    error = $4,
+    completed_at = CASE WHEN $2 IN ('completed', 'failed') THEN NOW() ELSE completed_at END
+WHERE id = $1;
+
+-- name: FailActiveCrawlRescrapes :exec
+UPDATE crawl_rescrapes
+SET status = 'failed',
+    error = $2,
+    completed_at = NOW()
+WHERE job_id = $1 AND status IN ('pending', 'running');
+
+-- name: CountActiveCrawlRescrapes :one
+SELECT COUNT(*)
+FROM crawl_rescrapes
+WHERE job_id = $1 AND status IN ('pending', 'running');
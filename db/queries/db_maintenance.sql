@@ -0,0 +1,25 @@
+-- name: ListTableStats :many
+SELECT relname::text AS table_name,
+       n_live_tup,
+       n_dead_tup,
+       n_mod_since_analyze,
+       seq_scan,
+       COALESCE(idx_scan, 0)::bigint AS idx_scan,
+       pg_total_relation_size(relid) AS total_bytes,
+       last_analyze,
+       last_autoanalyze,
+       last_autovacuum
+FROM pg_stat_user_tables
+WHERE schemaname = current_schema()
+ORDER BY relname;
+
+-- name: ListIndexStats :many
+SELECT s.relname::text AS table_name,
+       s.indexrelname::text AS index_name,
+       s.idx_scan,
+       pg_relation_size(s.indexrelid) AS size_bytes,
+       (i.indisunique OR i.indisprimary)::boolean AS is_unique
+FROM pg_stat_user_indexes s
+JOIN pg_index i ON i.indexrelid = s.indexrelid
+WHERE s.schemaname = current_schema()
+ORDER BY s.relname, s.indexrelname;
@@ -0,0 +1,20 @@
+-- name: UpsertDocumentAnnotation :one
+INSERT INTO document_annotations (document_id, job_id, notes, title, excluded, updated_by_user_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (document_id) DO UPDATE
+SET notes = EXCLUDED.notes,
+    title = EXCLUDED.title,
+    excluded = EXCLUDED.excluded,
+    updated_by_user_id = EXCLUDED.updated_by_user_id,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetDocumentAnnotation :one
+SELECT *
+FROM document_annotations
+WHERE document_id = $1;
+
+-- name: ListDocumentAnnotationsByDocumentIDs :many
+SELECT *
+FROM document_annotations
+WHERE document_id = ANY($1::bigint[]);
@@ -0,0 +1,12 @@
+-- name: CompactDocumentChanges :execrows
+-- Drops changes recorded before $1 that a later change to the same
+-- document supersedes, and deletes recorded before $1. The latest change
+-- of every existing document is kept, so reading the feed from the start
+-- still returns the whole corpus.
+DELETE FROM document_changes c
+WHERE c.changed_at < $1
+  AND (c.change_type = 'deleted'
+       OR EXISTS (
+           SELECT 1 FROM document_changes n
+           WHERE n.document_id = c.document_id AND n.seq > c.seq
+       ));
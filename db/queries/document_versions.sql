@@ -0,0 +1,41 @@
+-- name: GetLatestDocumentVersion :one
+-- Returns the newest version of a URL recorded for the same audience:
+-- shared jobs, or private jobs of the same user and API key.
+SELECT id, tenant_id, url, content_hash, markdown, title, status_code, job_id, visibility, created_by_user_id, api_key_id, created_at, last_seen_at
+FROM document_versions
+WHERE tenant_id = $1
+  AND url = $2
+  AND visibility = $3
+  AND (visibility = 'shared'
+       OR (created_by_user_id IS NOT DISTINCT FROM $4 AND api_key_id IS NOT DISTINCT FROM $5))
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: InsertDocumentVersion :exec
+INSERT INTO document_versions (tenant_id, url, content_hash, markdown, title, status_code, job_id, visibility, created_by_user_id, api_key_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: TouchDocumentVersion :exec
+-- Records that a later scrape found a version's content unchanged.
+UPDATE document_versions
+SET last_seen_at = NOW(),
+    job_id = $2,
+    status_code = $3
+WHERE id = $1;
+
+-- name: PruneDocumentVersions :execrows
+-- Keeps the newest $3 versions of a URL.
+DELETE FROM document_versions
+WHERE tenant_id = $1
+  AND url = $2
+  AND id NOT IN (
+      SELECT id FROM document_versions
+      WHERE tenant_id = $1 AND url = $2
+      ORDER BY id DESC
+      LIMIT $3
+  );
+
+-- name: DeleteExpiredDocumentVersions :execrows
+-- Deletes versions whose content has not been seen since $1.
+DELETE FROM document_versions
+WHERE last_seen_at < $1;
@@ -1,8 +1,55 @@
 -- name: InsertDocument :exec
-INSERT INTO documents (job_id, url, markdown, html, raw_html, metadata, status_code, engine)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+INSERT INTO documents (job_id, url, markdown, html, raw_html, metadata, status_code, engine, type)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
 -- name: GetDocumentsByJobID :many
-SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine FROM documents
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type FROM documents
 WHERE job_id = $1
 ORDER BY id ASC;
+
+-- name: GetJobDocument :one
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type FROM documents
+WHERE id = $1 AND job_id = $2;
+
+-- name: GetDocumentByID :one
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type FROM documents
+WHERE id = $1;
+
+-- name: GetDocumentsByIDs :many
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type FROM documents
+WHERE id = ANY($1::bigint[])
+ORDER BY id ASC;
+
+-- name: SearchJobDocuments :many
+-- Ranks a job's documents against a to_tsquery expression, best match
+-- first. The tsvector is built per call from the first 200000 characters
+-- of markdown, which is cheap enough for the documents of one job.
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type
+FROM documents,
+     to_tsquery('simple', sqlc.arg(query)::text) AS q,
+     to_tsvector('simple', left(coalesce(markdown, ''), 200000)) AS v
+WHERE job_id = sqlc.arg(job_id) AND v @@ q
+ORDER BY ts_rank(v, q) DESC, id ASC
+LIMIT sqlc.arg(max_results);
+
+-- name: ListJobDocumentURLs :many
+-- Returns which of urls have a page document in the job.
+SELECT DISTINCT url
+FROM documents
+WHERE job_id = $1 AND type = 'page' AND url = ANY(sqlc.arg(urls)::text[]);
+
+-- name: GetLatestJobDocumentIDByURL :one
+SELECT id
+FROM documents
+WHERE job_id = $1 AND type = 'page' AND url = ANY(sqlc.arg(urls)::text[])
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: DeleteJobDocumentsByURL :execrows
+DELETE FROM documents
+WHERE job_id = $1 AND type = 'page' AND url = ANY(sqlc.arg(urls)::text[]);
+
+-- name: JobHasPageDocuments :one
+SELECT EXISTS (
+    SELECT 1 FROM documents WHERE job_id = $1 AND type = 'page'
+);
@@ -0,0 +1,19 @@
+-- name: GetExtractCacheEntry :one
+SELECT *
+FROM extract_cache
+WHERE cache_key = $1
+  AND created_at >= $2
+  AND expires_at > NOW();
+
+-- name: UpsertExtractCacheEntry :exec
+INSERT INTO extract_cache (cache_key, tenant_id, url, schema_hash, prompt_hash, result, status_code, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (cache_key) DO UPDATE
+SET result = EXCLUDED.result,
+    status_code = EXCLUDED.status_code,
+    created_at = NOW(),
+    expires_at = EXCLUDED.expires_at;
+
+-- name: DeleteExpiredExtractCacheEntries :execrows
+DELETE FROM extract_cache
+WHERE expires_at < $1;
@@ -0,0 +1,42 @@
+-- name: AddHostStats :exec
+INSERT INTO host_stats (
+    host, bucket, requests, status_2xx, status_3xx, status_4xx, status_5xx,
+    errors, tls_errors, timeouts, dns_errors, robots_blocked, latency_ms_sum,
+    latency_100ms, latency_250ms, latency_500ms, latency_1s, latency_2500ms,
+    latency_5s, latency_10s, latency_over_10s, last_seen_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+ON CONFLICT (host, bucket) DO UPDATE
+SET requests = host_stats.requests + EXCLUDED.requests,
+    status_2xx = host_stats.status_2xx + EXCLUDED.status_2xx,
+    status_3xx = host_stats.status_3xx + EXCLUDED.status_3xx,
+    status_4xx = host_stats.status_4xx + EXCLUDED.status_4xx,
+    status_5xx = host_stats.status_5xx + EXCLUDED.status_5xx,
+    errors = host_stats.errors + EXCLUDED.errors,
+    tls_errors = host_stats.tls_errors + EXCLUDED.tls_errors,
+    timeouts = host_stats.timeouts + EXCLUDED.timeouts,
+    dns_errors = host_stats.dns_errors + EXCLUDED.dns_errors,
+    robots_blocked = host_stats.robots_blocked + EXCLUDED.robots_blocked,
+    latency_ms_sum = host_stats.latency_ms_sum + EXCLUDED.latency_ms_sum,
+    latency_100ms = host_stats.latency_100ms + EXCLUDED.latency_100ms,
+    latency_250ms = host_stats.latency_250ms + EXCLUDED.latency_250ms,
+    latency_500ms = host_stats.latency_500ms + EXCLUDED.latency_500ms,
+    latency_1s = host_stats.latency_1s + EXCLUDED.latency_1s,
+    latency_2500ms = host_stats.latency_2500ms + EXCLUDED.latency_2500ms,
+    latency_5s = host_stats.latency_5s + EXCLUDED.latency_5s,
+    latency_10s = host_stats.latency_10s + EXCLUDED.latency_10s,
+    latency_over_10s = host_stats.latency_over_10s + EXCLUDED.latency_over_10s,
+    last_seen_at = GREATEST(host_stats.last_seen_at, EXCLUDED.last_seen_at);
+
+-- name: DeleteHostStatsBefore :execrows
+DELETE FROM host_stats
+WHERE bucket < $1;
+
+-- name: ListHostStatsSince :many
+SELECT host, bucket, requests, status_2xx, status_3xx, status_4xx, status_5xx,
+       errors, tls_errors, timeouts, dns_errors, robots_blocked, latency_ms_sum,
+       latency_100ms, latency_250ms, latency_500ms, latency_1s, latency_2500ms,
+       latency_5s, latency_10s, latency_over_10s, last_seen_at
+FROM host_stats
+WHERE bucket >= $1
+ORDER BY host, bucket;
@@ -0,0 +1,17 @@
+-- name: InsertJobAsset :one
+INSERT INTO job_assets (id, job_id, source_url, content_type, size_bytes, sha256, data)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (job_id, source_url) DO UPDATE
+SET source_url = EXCLUDED.source_url
+RETURNING id;
+
+-- name: GetJobAsset :one
+SELECT *
+FROM job_assets
+WHERE id = $1 AND job_id = $2;
+
+-- name: ListJobAssetsByJob :many
+SELECT *
+FROM job_assets
+WHERE job_id = $1
+ORDER BY created_at ASC, id ASC;
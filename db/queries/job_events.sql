@@ -0,0 +1,9 @@
+-- name: InsertJobEvent :exec
+INSERT INTO job_events (job_id, type, message, data)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListJobEvents :many
+SELECT *
+FROM job_events
+WHERE job_id = $1
+ORDER BY id ASC;
@@ -0,0 +1,26 @@
+-- name: UpsertJobHeartbeat :exec
+INSERT INTO job_heartbeats (job_id, worker_id, started_at, heartbeat_at, pages_done, pages_total, current_url)
+VALUES ($1, $2, $3, NOW(), $4, $5, $6)
+ON CONFLICT (job_id) DO UPDATE
+SET worker_id = EXCLUDED.worker_id,
+    heartbeat_at = NOW(),
+    pages_done = EXCLUDED.pages_done,
+    pages_total = EXCLUDED.pages_total,
+    current_url = EXCLUDED.current_url;
+
+-- name: DeleteJobHeartbeat :exec
+DELETE FROM job_heartbeats
+WHERE job_id = $1;
+
+-- name: GetJobHeartbeat :one
+SELECT job_id, worker_id, started_at, heartbeat_at, pages_done, pages_total, current_url
+FROM job_heartbeats
+WHERE job_id = $1;
+
+-- name: ListRunningJobs :many
+SELECT j.id, j.type, j.url, j.tenant_id, j.pool, j.updated_at,
+       h.worker_id, h.started_at, h.heartbeat_at, h.pages_done, h.pages_total, h.current_url
+FROM jobs j
+LEFT JOIN job_heartbeats h ON h.job_id = j.id
+WHERE j.status = 'running'
+ORDER BY COALESCE(h.started_at, j.updated_at) ASC, j.id ASC;
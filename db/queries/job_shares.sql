@@ -0,0 +1,31 @@
+-- name: InsertJobShare :one
+INSERT INTO job_shares (id, job_id, created_by_user_id, created_by_api_key_id, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, job_id, created_by_user_id, created_by_api_key_id, expires_at, revoked_at, access_count, last_accessed_at, created_at;
+
+-- name: GetJobShare :one
+SELECT id, job_id, created_by_user_id, created_by_api_key_id, expires_at, revoked_at, access_count, last_accessed_at, created_at
+FROM job_shares
+WHERE id = $1;
+
+-- name: ListJobShares :many
+SELECT id, job_id, created_by_user_id, created_by_api_key_id, expires_at, revoked_at, access_count, last_accessed_at, created_at
+FROM job_shares
+WHERE job_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeJobShare :execrows
+UPDATE job_shares
+SET revoked_at = NOW()
+WHERE id = $1 AND job_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeJobShares :execrows
+UPDATE job_shares
+SET revoked_at = NOW()
+WHERE job_id = $1 AND revoked_at IS NULL;
+
+-- name: TouchJobShare :exec
+UPDATE job_shares
+SET access_count = access_count + 1,
+    last_accessed_at = NOW()
+WHERE id = $1;
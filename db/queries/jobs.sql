@@ -1,30 +1,92 @@
 -- name: InsertJob :one
-INSERT INTO jobs (id, type, status, url, input, sync, priority, tenant_id, api_key_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-RETURNING id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id;
+INSERT INTO jobs (id, type, status, url, input, sync, priority, tenant_id, api_key_id, created_by_user_id, visibility, collection_id, pool, zero_retention, applied_options)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id, created_by_user_id, visibility, collection_id, previous_job_id, metrics, pool, zero_retention, purged_at, applied_options, pinned;
 
 -- name: UpdateJobStatus :exec
 UPDATE jobs
 SET status = $2,
     error = $3,
     updated_at = NOW(),
-    completed_at = CASE WHEN $2 IN ('completed', 'failed') THEN NOW() ELSE completed_at END
+    completed_at = CASE WHEN $2 IN ('completed', 'failed', 'dead_letter') THEN NOW() ELSE completed_at END
 WHERE id = $1;
 
 -- name: GetJobByID :one
-SELECT id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id
+SELECT id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id, created_by_user_id, visibility, collection_id, previous_job_id, metrics, pool, zero_retention, purged_at, applied_options, pinned
 FROM jobs
 WHERE id = $1;
 
 -- name: ListPendingJobs :many
-SELECT id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id
+-- Synchronous jobs that have waited at least sync_boost_after_ms are
+-- claimed as if their priority were sync_boost higher, so callers
+-- waiting on them are less likely to time out behind queued work.
+SELECT id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id, pool
 FROM jobs
 WHERE status = 'pending'
-ORDER BY priority DESC, created_at ASC
+  AND pool = ANY(sqlc.arg(pools)::text[])
+  AND NOT EXISTS (
+      SELECT 1 FROM queue_pauses p
+      WHERE p.job_type IN ('*', jobs.type)
+  )
+ORDER BY priority + CASE
+    WHEN sync AND created_at <= NOW() - sqlc.arg(sync_boost_after_ms)::int * INTERVAL '1 millisecond'
+    THEN sqlc.arg(sync_boost)::int
+    ELSE 0
+  END DESC, created_at ASC
 LIMIT $1;
 
+-- name: SetJobPinned :exec
+UPDATE jobs
+SET pinned = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
 -- name: UpdateJobOutput :exec
 UPDATE jobs
 SET output = $2,
     updated_at = NOW()
 WHERE id = $1;
+
+-- name: RequeueFinishedJob :execrows
+-- Queues a completed or failed job again, e.g. to rescrape some pages of
+-- a crawl. Jobs that are still queued or running are left alone. The
+-- fingerprint is dropped so new requests do not join the requeued job.
+UPDATE jobs
+SET status = 'pending',
+    error = NULL,
+    fingerprint = NULL,
+    updated_at = NOW(),
+    completed_at = NULL
+WHERE id = $1 AND status IN ('completed', 'failed');
+
+-- name: RequeueFailedJob :execrows
+-- Queues a failed or dead-letter job again with its stored input, so an
+-- admin can replay it after fixing the cause. The output of the failed run
+-- is dropped. Purged zero-retention jobs have no input left and are left
+-- alone.
+UPDATE jobs
+SET status = 'pending',
+    error = NULL,
+    output = NULL,
+    fingerprint = NULL,
+    updated_at = NOW(),
+    completed_at = NULL
+WHERE id = $1 AND status IN ('failed', 'dead_letter') AND purged_at IS NULL;
+
+-- name: PauseJob :execrows
+-- Pauses a queued or running job. A running job's worker notices the
+-- status, stops and saves its progress.
+UPDATE jobs
+SET status = 'paused',
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'running');
+
+-- name: ResumeJob :execrows
+-- Queues a paused job again. The fingerprint is dropped, as for
+-- RequeueFinishedJob, since an identical job may have been queued while
+-- this one was paused.
+UPDATE jobs
+SET status = 'pending',
+    fingerprint = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'paused';
@@ -0,0 +1,15 @@
+-- name: DeleteMetricSnapshotsBefore :execrows
+DELETE FROM metric_snapshots
+WHERE updated_at < $1;
+
+-- name: GetMetricSnapshot :one
+SELECT instance, snapshot, updated_at
+FROM metric_snapshots
+WHERE instance = $1;
+
+-- name: UpsertMetricSnapshot :exec
+INSERT INTO metric_snapshots (instance, snapshot, updated_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (instance) DO UPDATE
+SET snapshot = EXCLUDED.snapshot,
+    updated_at = EXCLUDED.updated_at;
@@ -0,0 +1,34 @@
+-- name: InsertPromptTemplateVersion :one
+INSERT INTO prompt_templates (id, tenant_id, name, kind, version, prompt, description, created_by_user_id)
+SELECT $1, $2, $3, $4, COALESCE(MAX(version), 0) + 1, $5, $6, $7
+FROM prompt_templates
+WHERE tenant_id = $2 AND name = $3
+RETURNING id, tenant_id, name, kind, version, prompt, description, created_by_user_id, created_at;
+
+-- name: GetLatestPromptTemplate :one
+SELECT id, tenant_id, name, kind, version, prompt, description, created_by_user_id, created_at
+FROM prompt_templates
+WHERE tenant_id = $1 AND name = $2
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: GetPromptTemplateVersion :one
+SELECT id, tenant_id, name, kind, version, prompt, description, created_by_user_id, created_at
+FROM prompt_templates
+WHERE tenant_id = $1 AND name = $2 AND version = $3;
+
+-- name: ListLatestPromptTemplates :many
+SELECT DISTINCT ON (name) id, tenant_id, name, kind, version, prompt, description, created_by_user_id, created_at
+FROM prompt_templates
+WHERE tenant_id = $1
+ORDER BY name, version DESC;
+
+-- name: ListPromptTemplateVersions :many
+SELECT id, tenant_id, name, kind, version, prompt, description, created_by_user_id, created_at
+FROM prompt_templates
+WHERE tenant_id = $1 AND name = $2
+ORDER BY version DESC;
+
+-- name: DeletePromptTemplate :execrows
+DELETE FROM prompt_templates
+WHERE tenant_id = $1 AND name = $2;
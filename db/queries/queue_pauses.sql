@@ -0,0 +1,27 @@
+-- name: PauseQueue :one
+INSERT INTO queue_pauses (job_type, reason, paused_by_user_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (job_type) DO UPDATE
+SET reason = EXCLUDED.reason,
+    paused_by_user_id = EXCLUDED.paused_by_user_id,
+    paused_at = NOW()
+RETURNING job_type, reason, paused_by_user_id, paused_at;
+
+-- name: ListQueuePauses :many
+SELECT job_type, reason, paused_by_user_id, paused_at
+FROM queue_pauses
+ORDER BY job_type;
+
+-- name: ResumeQueue :execrows
+DELETE FROM queue_pauses
+WHERE job_type = $1;
+
+-- name: ResumeAllQueues :execrows
+DELETE FROM queue_pauses;
+
+-- name: CountPendingJobsByType :many
+SELECT type, COUNT(*) AS pending
+FROM jobs
+WHERE status = 'pending'
+GROUP BY type
+ORDER BY type;
@@ -0,0 +1,74 @@
+-- name: UpsertSCIMUser :exec
+INSERT INTO scim_users (user_id, external_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET external_id = EXCLUDED.external_id,
+    updated_at = NOW();
+
+-- name: GetSCIMUser :one
+SELECT * FROM scim_users
+WHERE user_id = $1;
+
+-- name: CreateSCIMGroup :one
+INSERT INTO scim_groups (id, display_name, external_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetSCIMGroup :one
+SELECT * FROM scim_groups
+WHERE id = $1;
+
+-- name: GetSCIMGroupByDisplayName :one
+SELECT * FROM scim_groups
+WHERE display_name = $1;
+
+-- name: CountSCIMGroups :one
+SELECT COUNT(*) FROM scim_groups;
+
+-- name: ListSCIMGroups :many
+SELECT * FROM scim_groups
+ORDER BY display_name ASC
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateSCIMGroup :one
+UPDATE scim_groups
+SET display_name = $2,
+    external_id = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteSCIMGroup :exec
+DELETE FROM scim_groups
+WHERE id = $1;
+
+-- name: AddSCIMGroupMember :exec
+INSERT INTO scim_group_members (group_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT (group_id, user_id) DO NOTHING;
+
+-- name: RemoveSCIMGroupMember :exec
+DELETE FROM scim_group_members
+WHERE group_id = $1 AND user_id = $2;
+
+-- name: DeleteSCIMGroupMembers :exec
+DELETE FROM scim_group_members
+WHERE group_id = $1;
+
+-- name: DeleteSCIMGroupMembershipsForUser :exec
+DELETE FROM scim_group_members
+WHERE user_id = $1;
+
+-- name: ListSCIMGroupMembers :many
+SELECT m.user_id, u.email
+FROM scim_group_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.group_id = $1
+ORDER BY u.email ASC;
+
+-- name: ListSCIMGroupsForUser :many
+SELECT g.*
+FROM scim_groups g
+JOIN scim_group_members m ON m.group_id = g.id
+WHERE m.user_id = $1
+ORDER BY g.display_name ASC;
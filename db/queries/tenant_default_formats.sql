@@ -0,0 +1,17 @@
+-- name: UpsertTenantDefaultFormats :one
+INSERT INTO tenant_default_formats (tenant_id, formats, updated_by_user_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (tenant_id) DO UPDATE
+SET formats = EXCLUDED.formats,
+    updated_by_user_id = EXCLUDED.updated_by_user_id,
+    updated_at = NOW()
+RETURNING tenant_id, formats, updated_by_user_id, created_at, updated_at;
+
+-- name: GetTenantDefaultFormats :one
+SELECT tenant_id, formats, updated_by_user_id, created_at, updated_at
+FROM tenant_default_formats
+WHERE tenant_id = $1;
+
+-- name: DeleteTenantDefaultFormats :execrows
+DELETE FROM tenant_default_formats
+WHERE tenant_id = $1;
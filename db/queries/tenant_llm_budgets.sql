@@ -0,0 +1,35 @@
+-- name: UpsertTenantLLMBudget :one
+INSERT INTO tenant_llm_budgets (tenant_id, monthly_token_cap, monthly_spend_cap_micros, updated_by_user_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (tenant_id) DO UPDATE
+SET monthly_token_cap = EXCLUDED.monthly_token_cap,
+    monthly_spend_cap_micros = EXCLUDED.monthly_spend_cap_micros,
+    updated_by_user_id = EXCLUDED.updated_by_user_id,
+    updated_at = NOW()
+RETURNING tenant_id, monthly_token_cap, monthly_spend_cap_micros, updated_by_user_id, created_at, updated_at;
+
+-- name: GetTenantLLMBudget :one
+SELECT tenant_id, monthly_token_cap, monthly_spend_cap_micros, updated_by_user_id, created_at, updated_at
+FROM tenant_llm_budgets
+WHERE tenant_id = $1;
+
+-- name: GetTenantLLMUsage :one
+SELECT tenant_id, period, calls, input_tokens, output_tokens, spend_micros, notified_percent, updated_at
+FROM tenant_llm_usage
+WHERE tenant_id = $1 AND period = $2;
+
+-- name: AddTenantLLMUsage :one
+INSERT INTO tenant_llm_usage (tenant_id, period, calls, input_tokens, output_tokens, spend_micros)
+VALUES ($1, $2, 1, $3, $4, $5)
+ON CONFLICT (tenant_id, period) DO UPDATE
+SET calls = tenant_llm_usage.calls + 1,
+    input_tokens = tenant_llm_usage.input_tokens + EXCLUDED.input_tokens,
+    output_tokens = tenant_llm_usage.output_tokens + EXCLUDED.output_tokens,
+    spend_micros = tenant_llm_usage.spend_micros + EXCLUDED.spend_micros,
+    updated_at = NOW()
+RETURNING tenant_id, period, calls, input_tokens, output_tokens, spend_micros, notified_percent, updated_at;
+
+-- name: MarkTenantLLMUsageNotified :execrows
+UPDATE tenant_llm_usage
+SET notified_percent = $3
+WHERE tenant_id = $1 AND period = $2 AND notified_percent < $3;
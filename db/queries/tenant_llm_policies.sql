@@ -0,0 +1,15 @@
+-- name: UpsertTenantLLMPolicy :one
+INSERT INTO tenant_llm_policies (tenant_id, allowed_models, default_provider, default_model, updated_by_user_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tenant_id) DO UPDATE
+SET allowed_models = EXCLUDED.allowed_models,
+    default_provider = EXCLUDED.default_provider,
+    default_model = EXCLUDED.default_model,
+    updated_by_user_id = EXCLUDED.updated_by_user_id,
+    updated_at = NOW()
+RETURNING tenant_id, allowed_models, default_provider, default_model, updated_by_user_id, created_at, updated_at;
+
+-- name: GetTenantLLMPolicy :one
+SELECT tenant_id, allowed_models, default_provider, default_model, updated_by_user_id, created_at, updated_at
+FROM tenant_llm_policies
+WHERE tenant_id = $1;
@@ -0,0 +1,23 @@
+-- name: UpsertTenantSecret :one
+INSERT INTO tenant_secrets (id, tenant_id, name, description, value_encrypted, created_by_user_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (tenant_id, name) DO UPDATE
+SET description = EXCLUDED.description,
+    value_encrypted = EXCLUDED.value_encrypted,
+    updated_at = NOW()
+RETURNING id, tenant_id, name, description, value_encrypted, created_by_user_id, created_at, updated_at;
+
+-- name: GetTenantSecretByName :one
+SELECT id, tenant_id, name, description, value_encrypted, created_by_user_id, created_at, updated_at
+FROM tenant_secrets
+WHERE tenant_id = $1 AND name = $2;
+
+-- name: ListTenantSecrets :many
+SELECT id, tenant_id, name, description, value_encrypted, created_by_user_id, created_at, updated_at
+FROM tenant_secrets
+WHERE tenant_id = $1
+ORDER BY name;
+
+-- name: DeleteTenantSecret :execrows
+DELETE FROM tenant_secrets
+WHERE tenant_id = $1 AND name = $2;
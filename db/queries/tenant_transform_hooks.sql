@@ -0,0 +1,21 @@
+-- name: UpsertTenantTransformHook :one
+INSERT INTO tenant_transform_hooks (tenant_id, url, timeout_ms, failure_policy, signing_secret, enabled, updated_by_user_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (tenant_id) DO UPDATE
+SET url = EXCLUDED.url,
+    timeout_ms = EXCLUDED.timeout_ms,
+    failure_policy = EXCLUDED.failure_policy,
+    signing_secret = EXCLUDED.signing_secret,
+    enabled = EXCLUDED.enabled,
+    updated_by_user_id = EXCLUDED.updated_by_user_id,
+    updated_at = NOW()
+RETURNING tenant_id, url, timeout_ms, failure_policy, signing_secret, enabled, updated_by_user_id, created_at, updated_at;
+
+-- name: GetTenantTransformHook :one
+SELECT tenant_id, url, timeout_ms, failure_policy, signing_secret, enabled, updated_by_user_id, created_at, updated_at
+FROM tenant_transform_hooks
+WHERE tenant_id = $1;
+
+-- name: DeleteTenantTransformHook :execrows
+DELETE FROM tenant_transform_hooks
+WHERE tenant_id = $1;
@@ -0,0 +1,18 @@
+-- name: RotateTenantWebhookSecret :one
+INSERT INTO tenant_webhook_secrets (tenant_id, secret_encrypted)
+VALUES ($1, $2)
+ON CONFLICT (tenant_id) DO UPDATE
+SET previous_secret_encrypted = tenant_webhook_secrets.secret_encrypted,
+    previous_expires_at = $3,
+    secret_encrypted = EXCLUDED.secret_encrypted,
+    rotated_at = NOW()
+RETURNING tenant_id, secret_encrypted, previous_secret_encrypted, previous_expires_at, created_at, rotated_at;
+
+-- name: GetTenantWebhookSecret :one
+SELECT tenant_id, secret_encrypted, previous_secret_encrypted, previous_expires_at, created_at, rotated_at
+FROM tenant_webhook_secrets
+WHERE tenant_id = $1;
+
+-- name: DeleteTenantWebhookSecret :execrows
+DELETE FROM tenant_webhook_secrets
+WHERE tenant_id = $1;
@@ -0,0 +1,18 @@
+-- name: UpsertTenantWebhook :one
+INSERT INTO tenant_webhooks (tenant_id, url, events, updated_by_user_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (tenant_id) DO UPDATE
+SET url = EXCLUDED.url,
+    events = EXCLUDED.events,
+    updated_by_user_id = EXCLUDED.updated_by_user_id,
+    updated_at = NOW()
+RETURNING tenant_id, url, events, updated_by_user_id, created_at, updated_at;
+
+-- name: GetTenantWebhook :one
+SELECT tenant_id, url, events, updated_by_user_id, created_at, updated_at
+FROM tenant_webhooks
+WHERE tenant_id = $1;
+
+-- name: DeleteTenantWebhook :execrows
+DELETE FROM tenant_webhooks
+WHERE tenant_id = $1;
@@ -0,0 +1,15 @@
+-- name: UpsertUserNotificationPreferences :one
+INSERT INTO user_notification_preferences (user_id, on_completed, on_failed, email, webhook_url)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE
+SET on_completed = EXCLUDED.on_completed,
+    on_failed = EXCLUDED.on_failed,
+    email = EXCLUDED.email,
+    webhook_url = EXCLUDED.webhook_url,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetUserNotificationPreferences :one
+SELECT *
+FROM user_notification_preferences
+WHERE user_id = $1;
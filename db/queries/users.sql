@@ -51,3 +51,41 @@ SET
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
+
+-- name: CountSystemAdminUsers :one
+SELECT COUNT(*) FROM users WHERE is_system_admin = TRUE;
+
+-- name: SetUserMustChangePassword :exec
+UPDATE users
+SET
+    must_change_password = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET
+    password_hash = $2,
+    password_version = $3,
+    must_change_password = FALSE,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SCIMUpdateUser :one
+UPDATE users
+SET
+    email = $2,
+    name = $3,
+    is_disabled = $4,
+    disabled_at = $5,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserAuthSubject :exec
+UPDATE users
+SET
+    auth_subject = $2,
+    updated_at = NOW()
+WHERE id = $1;
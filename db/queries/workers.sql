@@ -0,0 +1,51 @@
+-- name: UpsertWorker :exec
+INSERT INTO workers (id, hostname, version, capabilities, started_at, last_heartbeat_at)
+VALUES ($1, $2, $3, $4, NOW(), NOW())
+ON CONFLICT (id) DO UPDATE
+SET hostname = EXCLUDED.hostname,
+    version = EXCLUDED.version,
+    capabilities = EXCLUDED.capabilities,
+    last_heartbeat_at = NOW();
+
+-- name: TouchWorker :execrows
+UPDATE workers
+SET last_heartbeat_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteWorker :exec
+DELETE FROM workers
+WHERE id = $1;
+
+-- name: DeleteWorkersSilentSince :execrows
+DELETE FROM workers
+WHERE last_heartbeat_at < $1;
+
+-- name: ListWorkers :many
+SELECT w.id, w.hostname, w.version, w.capabilities, w.started_at, w.last_heartbeat_at,
+       (SELECT COUNT(*) FROM job_heartbeats h WHERE h.worker_id = w.id) AS running_jobs
+FROM workers w
+ORDER BY w.started_at ASC, w.id ASC;
+
+-- name: FailJobsOfDeadWorkers :many
+-- Moves running jobs whose own heartbeat and whose worker's heartbeat are
+-- both older than the cutoff to the dead-letter queue, then drops their
+-- job heartbeats.
+WITH reaped AS (
+    UPDATE jobs
+    SET status = 'dead_letter',
+        error = 'WORKER_LOST: the worker running this job stopped sending heartbeats',
+        updated_at = NOW(),
+        completed_at = NOW()
+    WHERE status = 'running'
+      AND id IN (
+        SELECT h.job_id
+        FROM job_heartbeats h
+        LEFT JOIN workers w ON w.id = h.worker_id
+        WHERE h.heartbeat_at < $1
+          AND (w.id IS NULL OR w.last_heartbeat_at < $1)
+      )
+    RETURNING id
+)
+DELETE FROM job_heartbeats
+WHERE job_id IN (SELECT id FROM reaped)
+RETURNING job_id;
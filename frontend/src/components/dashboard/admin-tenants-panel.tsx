@@ -68,7 +68,7 @@ interface AdminTenantMember {
   userId: string
   email: string
   name?: string
-  role: "tenant_admin" | "tenant_member"
+  role: "tenant_admin" | "tenant_member" | "tenant_viewer"
   createdAt: string
   updatedAt: string
 }
@@ -108,7 +108,7 @@ export function AdminTenantsPanel() {
   const [userResults, setUserResults] = useState<AdminUserOption[]>([])
   const [userResultsLoading, setUserResultsLoading] = useState(false)
   const [selectedUserId, setSelectedUserId] = useState<string>("")
-  const [selectedRole, setSelectedRole] = useState<"tenant_admin" | "tenant_member">(
+  const [selectedRole, setSelectedRole] = useState<"tenant_admin" | "tenant_member" | "tenant_viewer">(
     "tenant_member"
   )
   const [memberActionLoading, setMemberActionLoading] = useState(false)
@@ -117,12 +117,12 @@ export function AdminTenantsPanel() {
   const [createSlug, setCreateSlug] = useState("")
   const [createName, setCreateName] = useState("")
   const [createDefaultRateLimit, setCreateDefaultRateLimit] = useState("")
-  const [createMembers, setCreateMembers] = useState<Array<{ user: AdminUserOption; role: "tenant_admin" | "tenant_member" }>>([])
+  const [createMembers, setCreateMembers] = useState<Array<{ user: AdminUserOption; role: "tenant_admin" | "tenant_member" | "tenant_viewer" }>>([])
   const [createMemberQuery, setCreateMemberQuery] = useState("")
   const [createUserResults, setCreateUserResults] = useState<AdminUserOption[]>([])
   const [createUserResultsLoading, setCreateUserResultsLoading] = useState(false)
   const [createSelectedUserId, setCreateSelectedUserId] = useState<string>("")
-  const [createSelectedRole, setCreateSelectedRole] = useState<"tenant_admin" | "tenant_member">("tenant_member")
+  const [createSelectedRole, setCreateSelectedRole] = useState<"tenant_admin" | "tenant_member" | "tenant_viewer">("tenant_member")
   const [createLoading, setCreateLoading] = useState(false)
   const [createError, setCreateError] = useState<string | null>(null)
 
@@ -309,7 +309,7 @@ export function AdminTenantsPanel() {
     }
   }
 
-  async function updateMemberRole(userId: string, role: "tenant_admin" | "tenant_member") {
+  async function updateMemberRole(userId: string, role: "tenant_admin" | "tenant_member" | "tenant_viewer") {
     if (!selectedTenant) return
     setMemberActionLoading(true)
     setDetailError(null)
@@ -706,6 +706,7 @@ export function AdminTenantsPanel() {
                               <SelectLabel>Role</SelectLabel>
                               <SelectItem value="tenant_member">Tenant member</SelectItem>
                               <SelectItem value="tenant_admin">Tenant admin</SelectItem>
+                              <SelectItem value="tenant_viewer">Tenant viewer</SelectItem>
                             </SelectGroup>
                           </SelectContent>
                         </Select>
@@ -792,6 +793,7 @@ export function AdminTenantsPanel() {
                                         <SelectLabel>Role</SelectLabel>
                                         <SelectItem value="tenant_member">Tenant member</SelectItem>
                                         <SelectItem value="tenant_admin">Tenant admin</SelectItem>
+                                        <SelectItem value="tenant_viewer">Tenant viewer</SelectItem>
                                       </SelectGroup>
                                     </SelectContent>
                                   </Select>
@@ -949,6 +951,7 @@ export function AdminTenantsPanel() {
                           <SelectLabel>Role</SelectLabel>
                           <SelectItem value="tenant_member">Tenant member</SelectItem>
                           <SelectItem value="tenant_admin">Tenant admin</SelectItem>
+                          <SelectItem value="tenant_viewer">Tenant viewer</SelectItem>
                         </SelectGroup>
                       </SelectContent>
                     </Select>
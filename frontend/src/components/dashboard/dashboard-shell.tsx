@@ -1020,6 +1020,7 @@ function JobsPanel({ activeTenantId, sessionEmail }: JobsPanelProps) {
       case "completed":
         return "outline"
       case "failed":
+      case "dead_letter":
         return "destructive"
       default:
         return "secondary"
@@ -0,0 +1,491 @@
+// Package backup exports an instance's tenants, users, API key metadata,
+// and optionally job data to a tar.gz archive, and restores such archives
+// into a database at the same schema version.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"raito/internal/migrate"
+)
+
+// FormatVersion identifies the archive layout written by Export.
+const FormatVersion = 1
+
+const (
+	manifestName = "manifest.json"
+	configName   = "config.yaml"
+	tablesDir    = "tables/"
+
+	restoreBatchSize = 500
+)
+
+// table describes how one database table is exported and restored.
+type table struct {
+	name string
+	// jobData tables are only included with Options.IncludeJobs.
+	jobData bool
+	// deferred columns reference rows that may not be restored yet (the
+	// users/tenants cycle, or self references). They are inserted as NULL
+	// and set in a second pass keyed by the row's id.
+	deferred []string
+	// serial tables have a BIGSERIAL id whose sequence must be advanced
+	// past restored ids.
+	serial bool
+}
+
+// tables is in restore order: every table comes after the tables its
+// non-deferred foreign keys point to. Tables missing from it must be
+// listed in transientTables.
+var tables = []table{
+	{name: "users", deferred: []string{"default_tenant_id"}},
+	{name: "tenants"},
+	{name: "tenant_members"},
+	{name: "api_keys"},
+	{name: "collections"},
+	{name: "audit_events", serial: true},
+	{name: "tenant_secrets"},
+	{name: "tenant_llm_budgets"},
+	{name: "tenant_llm_usage"},
+	{name: "prompt_templates"},
+	{name: "tenant_transform_hooks"},
+	{name: "tenant_llm_policies"},
+	{name: "user_notification_preferences"},
+	{name: "tenant_webhook_secrets"},
+	{name: "scim_users"},
+	{name: "scim_groups"},
+	{name: "scim_group_members"},
+	{name: "tenant_default_formats"},
+	{name: "tenant_webhooks"},
+	{name: "jobs", jobData: true, deferred: []string{"previous_job_id"}},
+	{name: "documents", jobData: true, serial: true},
+	{name: "job_assets", jobData: true},
+	{name: "job_shares", jobData: true},
+	{name: "job_events", jobData: true, serial: true},
+	{name: "document_annotations", jobData: true},
+	{name: "crawl_rescrapes", jobData: true},
+	{name: "document_versions", jobData: true, serial: true},
+	{name: "crawl_frontiers", jobData: true},
+}
+
+// transientTables are never exported, with the reason why.
+var transientTables = map[string]string{
+	"sessions":         "logins are not carried over; users sign in again",
+	"api_key_reveals":  "one-time reveal tokens expire within minutes",
+	"extract_cache":    "a cache that is refilled on demand",
+	"host_stats":       "short-lived per-host fetch statistics",
+	"document_changes": "restored documents re-enter the feed through its triggers",
+	"job_heartbeats":   "liveness of running jobs, renewed by the workers running them",
+	"workers":          "registrations of running worker processes, renewed by their heartbeats",
+	"queue_pauses":     "operational state of the running deployment; pause queues again after a restore if needed",
+	"metric_snapshots": "per-instance metric counters; a restored instance starts its own",
+}
+
+// Options controls what Export includes.
+type Options struct {
+	// IncludePasswordHashes keeps local users' password hashes. Without
+	// them, restored local users need a password reset before logging in.
+	IncludePasswordHashes bool
+	// IncludeJobs adds jobs and the data recorded for them, such as
+	// documents and job assets.
+	IncludeJobs bool
+	// ConfigPath, when set, adds the config file as config.yaml. It holds
+	// secrets such as LLM API keys and is never applied by Restore.
+	ConfigPath string
+}
+
+// Manifest is stored as manifest.json, the first entry of every archive.
+type Manifest struct {
+	FormatVersion          int              `json:"formatVersion"`
+	CreatedAt              time.Time        `json:"createdAt"`
+	SchemaVersion          int64            `json:"schemaVersion"`
+	IncludesPasswordHashes bool             `json:"includesPasswordHashes"`
+	IncludesJobs           bool             `json:"includesJobs"`
+	IncludesConfig         bool             `json:"includesConfig"`
+	Tables                 map[string]int64 `json:"tables"`
+}
+
+// RestoreResult reports how many rows of each table were inserted and how
+// many were skipped because a row with the same key already existed.
+type RestoreResult struct {
+	Manifest Manifest         `json:"manifest"`
+	Restored map[string]int64 `json:"restored"`
+	Skipped  map[string]int64 `json:"skipped"`
+}
+
+// ErrSchemaMismatch is returned by Restore when the archive was taken at a
+// different schema version than the target database.
+var ErrSchemaMismatch = errors.New("backup schema version does not match database")
+
+// Export writes a gzip-compressed tar archive of the instance to w. All
+// tables are read in a single repeatable-read transaction so the archive
+// is consistent.
+func Export(ctx context.Context, db *sql.DB, w io.Writer, opts Options) (Manifest, error) {
+	version, err := migrate.Version(ctx, db)
+	if err != nil {
+		return Manifest{}, err
+	}
+	m := Manifest{
+		FormatVersion:          FormatVersion,
+		CreatedAt:              time.Now().UTC(),
+		SchemaVersion:          version,
+		IncludesPasswordHashes: opts.IncludePasswordHashes,
+		IncludesJobs:           opts.IncludeJobs,
+		IncludesConfig:         opts.ConfigPath != "",
+		Tables:                 map[string]int64{},
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer tx.Rollback()
+
+	// Table dumps are spooled to temp files because tar headers need each
+	// entry's size up front and job data can be large.
+	type dump struct {
+		name string
+		file *os.File
+	}
+	var dumps []dump
+	defer func() {
+		for _, d := range dumps {
+			d.file.Close()
+			os.Remove(d.file.Name())
+		}
+	}()
+	for _, t := range tables {
+		if t.jobData && !opts.IncludeJobs {
+			continue
+		}
+		f, err := os.CreateTemp("", "raito-backup-"+t.name+"-*.ndjson")
+		if err != nil {
+			return Manifest{}, err
+		}
+		dumps = append(dumps, dump{name: t.name, file: f})
+		n, err := dumpTable(ctx, tx, t, f, opts)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("export %s: %w", t.name, err)
+		}
+		m.Tables[t.name] = n
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := writeEntry(tw, manifestName, int64(len(manifest)), bytes.NewReader(manifest)); err != nil {
+		return Manifest{}, err
+	}
+	if opts.ConfigPath != "" {
+		raw, err := os.ReadFile(opts.ConfigPath)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read config: %w", err)
+		}
+		if err := writeEntry(tw, configName, int64(len(raw)), bytes.NewReader(raw)); err != nil {
+			return Manifest{}, err
+		}
+	}
+	for _, d := range dumps {
+		info, err := d.file.Stat()
+		if err != nil {
+			return Manifest{}, err
+		}
+		if _, err := d.file.Seek(0, io.SeekStart); err != nil {
+			return Manifest{}, err
+		}
+		if err := writeEntry(tw, tablesDir+d.name+".ndjson", info.Size(), d.file); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return Manifest{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return m, nil
+}
+
+// dumpTable writes one JSON object per row of t to w and returns the row
+// count.
+func dumpTable(ctx context.Context, tx *sql.Tx, t table, w io.Writer, opts Options) (int64, error) {
+	expr := "to_jsonb(t)"
+	if t.name == "users" && !opts.IncludePasswordHashes {
+		expr = `to_jsonb(t) || '{"password_hash": null, "password_version": null}'::jsonb`
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT (%s)::text FROM %s t", expr, t.name))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	bw := bufio.NewWriter(w)
+	var n int64
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return 0, err
+		}
+		if _, err := bw.WriteString(line); err != nil {
+			return 0, err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return n, bw.Flush()
+}
+
+func writeEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    size,
+		ModTime: time.Now().UTC(),
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, r)
+	return err
+}
+
+// Restore loads an archive written by Export into db in a single
+// transaction. The database must already be migrated to the archive's
+// schema version. Rows whose key already exists are kept and counted as
+// skipped, so restoring into a fresh instance is the intended use.
+func Restore(ctx context.Context, db *sql.DB, r io.Reader) (RestoreResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("open archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("read archive: %w", err)
+	}
+	if hdr.Name != manifestName {
+		return RestoreResult{}, fmt.Errorf("archive does not start with %s", manifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return RestoreResult{}, fmt.Errorf("decode manifest: %w", err)
+	}
+	if m.FormatVersion != FormatVersion {
+		return RestoreResult{}, fmt.Errorf("unsupported backup format version %d", m.FormatVersion)
+	}
+	version, err := migrate.Version(ctx, db)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	if version != m.SchemaVersion {
+		return RestoreResult{}, fmt.Errorf("%w: backup is at %d, database is at %d; run `raito-api migrate -to %d` first",
+			ErrSchemaMismatch, m.SchemaVersion, version, m.SchemaVersion)
+	}
+
+	res := RestoreResult{Manifest: m, Restored: map[string]int64{}, Skipped: map[string]int64{}}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	defer tx.Rollback()
+
+	next := 0
+	var pending []deferredValue
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return RestoreResult{}, fmt.Errorf("read archive: %w", err)
+		}
+		if !strings.HasPrefix(hdr.Name, tablesDir) {
+			continue // config.yaml is for the operator, not the database
+		}
+		name := strings.TrimSuffix(path.Base(hdr.Name), ".ndjson")
+		idx := tableIndex(name)
+		if idx < 0 {
+			return RestoreResult{}, fmt.Errorf("archive contains unknown table %q", name)
+		}
+		if idx < next {
+			return RestoreResult{}, fmt.Errorf("archive table %q is out of order", name)
+		}
+		next = idx + 1
+
+		t := tables[idx]
+		inserted, total, deferred, err := restoreTable(ctx, tx, t, tr)
+		if err != nil {
+			return RestoreResult{}, fmt.Errorf("restore %s: %w", t.name, err)
+		}
+		res.Restored[t.name] = inserted
+		res.Skipped[t.name] = total - inserted
+		pending = append(pending, deferred...)
+
+		if t.serial {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)",
+				t.name, t.name)); err != nil {
+				return RestoreResult{}, fmt.Errorf("advance %s sequence: %w", t.name, err)
+			}
+		}
+	}
+
+	for _, d := range pending {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"UPDATE %s SET %s = $2 WHERE id = $1 AND %s IS NULL", d.table, d.column, d.column),
+			d.id, d.value); err != nil {
+			return RestoreResult{}, fmt.Errorf("restore %s.%s: %w", d.table, d.column, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return RestoreResult{}, err
+	}
+	return res, nil
+}
+
+// deferredValue is a deferred column value to set once all tables are
+// restored.
+type deferredValue struct {
+	table  string
+	column string
+	id     string
+	value  string
+}
+
+func tableIndex(name string) int {
+	for i, t := range tables {
+		if t.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// restoreTable inserts the NDJSON rows in r into t in batches. It returns
+// the number of inserted rows, the number of rows read, and the deferred
+// column values to apply afterwards.
+func restoreTable(ctx context.Context, tx *sql.Tx, t table, r io.Reader) (int64, int64, []deferredValue, error) {
+	stmt := fmt.Sprintf(
+		"INSERT INTO %s SELECT * FROM jsonb_populate_recordset(NULL::%s, $1::jsonb) ON CONFLICT DO NOTHING",
+		t.name, t.name)
+
+	var (
+		inserted, total int64
+		deferred        []deferredValue
+		batch           []json.RawMessage
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		payload, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, stmt, string(payload))
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		inserted += n
+		batch = batch[:0]
+		return nil
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		row := json.RawMessage(append([]byte(nil), line...))
+		if len(t.deferred) > 0 {
+			var err error
+			var values []deferredValue
+			row, values, err = stripDeferred(t, row)
+			if err != nil {
+				return 0, 0, nil, err
+			}
+			deferred = append(deferred, values...)
+		}
+		batch = append(batch, row)
+		total++
+		if len(batch) >= restoreBatchSize {
+			if err := flush(); err != nil {
+				return 0, 0, nil, err
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 0, 0, nil, err
+	}
+	if err := flush(); err != nil {
+		return 0, 0, nil, err
+	}
+	return inserted, total, deferred, nil
+}
+
+// stripDeferred nulls t's deferred columns in row and returns their values.
+func stripDeferred(t table, row json.RawMessage) (json.RawMessage, []deferredValue, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(row, &fields); err != nil {
+		return nil, nil, err
+	}
+	var id string
+	if err := json.Unmarshal(fields["id"], &id); err != nil {
+		return nil, nil, fmt.Errorf("row without id: %w", err)
+	}
+
+	var values []deferredValue
+	for _, col := range t.deferred {
+		var v *string
+		if raw, ok := fields[col]; ok {
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, nil, fmt.Errorf("column %s: %w", col, err)
+			}
+		}
+		if v == nil {
+			continue
+		}
+		values = append(values, deferredValue{table: t.name, column: col, id: id, value: *v})
+		fields[col] = json.RawMessage("null")
+	}
+	if len(values) == 0 {
+		return row, nil, nil
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, values, nil
+}
@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestStripDeferred(t *testing.T) {
+	users := tables[tableIndex("users")]
+	row := json.RawMessage(`{"id":"u1","email":"a@example.com","default_tenant_id":"t1"}`)
+
+	out, values, err := stripDeferred(users, row)
+	if err != nil {
+		t.Fatalf("stripDeferred: %v", err)
+	}
+	if len(values) != 1 || values[0] != (deferredValue{table: "users", column: "default_tenant_id", id: "u1", value: "t1"}) {
+		t.Fatalf("unexpected deferred values: %+v", values)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(out, &fields); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if fields["default_tenant_id"] != nil || fields["email"] != "a@example.com" {
+		t.Fatalf("unexpected stripped row: %s", out)
+	}
+
+	// Rows without deferred values are passed through untouched.
+	row = json.RawMessage(`{"id":"u2","default_tenant_id":null}`)
+	out, values, err = stripDeferred(users, row)
+	if err != nil || len(values) != 0 || string(out) != string(row) {
+		t.Fatalf("expected passthrough, got %s %+v %v", out, values, err)
+	}
+}
+
+func TestTablesRestoreOrder(t *testing.T) {
+	// Foreign keys that are not deferred must point at earlier tables.
+	deps := map[string][]string{
+		"tenant_members":                {"tenants", "users"},
+		"api_keys":                      {"users", "tenants"},
+		"collections":                   {"tenants", "users"},
+		"jobs":                          {"api_keys", "collections", "users"},
+		"documents":                     {"jobs"},
+		"job_assets":                    {"jobs"},
+		"job_shares":                    {"jobs", "users", "api_keys"},
+		"tenant_secrets":                {"tenants", "users"},
+		"tenant_llm_budgets":            {"tenants", "users"},
+		"tenant_llm_usage":              {"tenants"},
+		"prompt_templates":              {"tenants", "users"},
+		"tenant_transform_hooks":        {"tenants", "users"},
+		"tenant_llm_policies":           {"tenants", "users"},
+		"job_events":                    {"jobs"},
+		"document_annotations":          {"documents", "jobs", "users"},
+		"user_notification_preferences": {"users"},
+		"tenant_webhook_secrets":        {"tenants"},
+		"scim_users":                    {"users"},
+		"scim_group_members":            {"scim_groups", "users"},
+		"tenant_default_formats":        {"tenants", "users"},
+		"crawl_rescrapes":               {"jobs", "users"},
+		"document_versions":             {"tenants", "jobs"},
+		"crawl_frontiers":               {"jobs"},
+		"tenant_webhooks":               {"tenants", "users"},
+	}
+	for child, parents := range deps {
+		for _, parent := range parents {
+			if tableIndex(parent) >= tableIndex(child) {
+				t.Errorf("%s must be restored before %s", parent, child)
+			}
+		}
+	}
+}
+
+// TestTablesCoverMigrations guards against new tables silently missing
+// from backups: every table the migrations create must be exported or
+// listed as transient.
+func TestTablesCoverMigrations(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "db", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no migrations found")
+	}
+
+	create := regexp.MustCompile(`(?i)CREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read %s: %v", f, err)
+		}
+		up, _, _ := strings.Cut(string(raw), "-- +goose Down")
+		for _, m := range create.FindAllStringSubmatch(up, -1) {
+			name := m[1]
+			_, transient := transientTables[name]
+			exported := tableIndex(name) >= 0
+			switch {
+			case !exported && !transient:
+				t.Errorf("%s: table %s is neither in tables nor in transientTables", filepath.Base(f), name)
+			case exported && transient:
+				t.Errorf("%s: table %s is both exported and listed as transient", filepath.Base(f), name)
+			}
+		}
+	}
+}
+
+func TestRestoreRejectsArchiveWithoutManifest(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, tablesDir+"users.ndjson", 2, strings.NewReader("{}")); err != nil {
+		t.Fatalf("writeEntry: %v", err)
+	}
+	tw.Close()
+	gz.Close()
+
+	_, err := Restore(context.Background(), nil, &buf)
+	if err == nil || !strings.Contains(err.Error(), manifestName) {
+		t.Fatalf("expected missing manifest error, got %v", err)
+	}
+}
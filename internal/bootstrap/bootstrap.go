@@ -2,8 +2,11 @@ package bootstrap
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
 	"errors"
+	"log"
 	"strings"
 
 	"golang.org/x/crypto/bcrypt"
@@ -22,15 +25,22 @@ func Run(ctx context.Context, cfg *config.Config, st *store.Store) error {
 	if cfg == nil || st == nil {
 		return nil
 	}
-	if len(cfg.Bootstrap.Users) == 0 && len(cfg.Bootstrap.Tenants) == 0 {
+	initialAdmin := strings.TrimSpace(cfg.Bootstrap.InitialAdmin.Email) != ""
+	if !initialAdmin && len(cfg.Bootstrap.Users) == 0 && len(cfg.Bootstrap.Tenants) == 0 {
 		return nil
 	}
 
 	q := db.New(st.DB)
 
+	if initialAdmin {
+		if err := bootstrapInitialAdmin(ctx, q, cfg.Bootstrap.InitialAdmin); err != nil {
+			return err
+		}
+	}
+
 	// Bootstrap users first so that tenant membership references are valid.
 	for i := range cfg.Bootstrap.Users {
-		if err := bootstrapUser(ctx, q, &cfg.Bootstrap.Users[i]); err != nil {
+		if _, err := bootstrapUser(ctx, q, &cfg.Bootstrap.Users[i]); err != nil {
 			return err
 		}
 	}
@@ -45,10 +55,72 @@ func Run(ctx context.Context, cfg *config.Config, st *store.Store) error {
 	return nil
 }
 
-func bootstrapUser(ctx context.Context, q *db.Queries, u *config.BootstrapUserConfig) error {
+// bootstrapInitialAdmin creates the configured local system admin when no
+// system admin exists yet. Once any admin exists (including this one after
+// the first run) it does nothing.
+func bootstrapInitialAdmin(ctx context.Context, q *db.Queries, a config.InitialAdminConfig) error {
+	count, err := q.CountSystemAdminUsers(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	password := a.Password
+	generated := strings.TrimSpace(password) == ""
+	if generated {
+		password, err = GeneratePassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	created, err := bootstrapUser(ctx, q, &config.BootstrapUserConfig{
+		Email:              a.Email,
+		Name:               a.Name,
+		IsSystemAdmin:      true,
+		Provider:           "local",
+		Password:           password,
+		MustChangePassword: true,
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		log.Printf("bootstrap: initial admin %s not created: a user with that email already exists", a.Email)
+		return nil
+	}
+	if generated {
+		log.Printf("bootstrap: created initial admin %s with one-time password %s (must be changed on first login)", a.Email, password)
+	} else {
+		log.Printf("bootstrap: created initial admin %s (password must be changed on first login)", a.Email)
+	}
+	return nil
+}
+
+// CreateUser creates a single user outside of the bootstrap config, e.g.
+// from the CLI. It reports whether the user was created; an existing user
+// with the same email is left untouched.
+func CreateUser(ctx context.Context, st *store.Store, u config.BootstrapUserConfig) (bool, error) {
+	return bootstrapUser(ctx, db.New(st.DB), &u)
+}
+
+// GeneratePassword returns a random one-time password.
+func GeneratePassword() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// bootstrapUser creates the user described by u unless a user with the same
+// email already exists. It reports whether a new user was created.
+func bootstrapUser(ctx context.Context, q *db.Queries, u *config.BootstrapUserConfig) (bool, error) {
 	email := strings.TrimSpace(strings.ToLower(u.Email))
 	if email == "" {
-		return nil
+		return false, nil
 	}
 
 	provider := strings.ToLower(strings.TrimSpace(u.Provider))
@@ -58,12 +130,12 @@ func bootstrapUser(ctx context.Context, q *db.Queries, u *config.BootstrapUserCo
 
 	_, err := q.GetUserByEmail(ctx, email)
 	if err != nil && err != sql.ErrNoRows {
-		return err
+		return false, err
 	}
 	if err == nil {
 		// User already exists; do not modify existing credentials or flags
 		// via bootstrap to avoid surprising changes.
-		return nil
+		return false, nil
 	}
 
 	userID := uuid.New()
@@ -76,13 +148,13 @@ func bootstrapUser(ctx context.Context, q *db.Queries, u *config.BootstrapUserCo
 	if provider == "local" && strings.TrimSpace(u.Password) != "" {
 		hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
 		if err != nil {
-			return err
+			return false, err
 		}
 		passwordHash = sql.NullString{String: string(hash), Valid: true}
 		passwordVersion = sql.NullInt32{Int32: 1, Valid: true}
 	}
 
-	_, err = q.CreateUser(ctx, db.CreateUserParams{
+	user, err := q.CreateUser(ctx, db.CreateUserParams{
 		ID:              userID,
 		Email:           email,
 		Name:            name,
@@ -96,11 +168,19 @@ func bootstrapUser(ctx context.Context, q *db.Queries, u *config.BootstrapUserCo
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			// Another process created this user concurrently; treat as success.
-			return nil
+			return false, nil
 		}
-		return err
+		return false, err
 	}
-	return nil
+	if u.MustChangePassword && provider == "local" {
+		if err := q.SetUserMustChangePassword(ctx, db.SetUserMustChangePasswordParams{
+			ID:                 user.ID,
+			MustChangePassword: true,
+		}); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
 func bootstrapTenant(ctx context.Context, q *db.Queries, t *config.BootstrapTenantConfig, users []config.BootstrapUserConfig) error {
@@ -171,11 +251,11 @@ func bootstrapTenant(ctx context.Context, q *db.Queries, t *config.BootstrapTena
 		if spec == nil {
 			// Create a minimal OIDC-backed stub.
 			stub := config.BootstrapUserConfig{Email: email, Provider: "oidc"}
-			if err := bootstrapUser(ctx, q, &stub); err != nil {
+			if _, err := bootstrapUser(ctx, q, &stub); err != nil {
 				return db.User{}, err
 			}
 		} else {
-			if err := bootstrapUser(ctx, q, spec); err != nil {
+			if _, err := bootstrapUser(ctx, q, spec); err != nil {
 				return db.User{}, err
 			}
 		}
@@ -208,5 +288,18 @@ func bootstrapTenant(ctx context.Context, q *db.Queries, t *config.BootstrapTena
 		})
 	}
 
+	// Add read-only viewers
+	for _, email := range t.Viewers {
+		u, err := ensureUser(email)
+		if err != nil {
+			return err
+		}
+		_, _ = q.AddTenantMember(ctx, db.AddTenantMemberParams{
+			TenantID: tenant.ID,
+			UserID:   u.ID,
+			Role:     "tenant_viewer",
+		})
+	}
+
 	return nil
 }
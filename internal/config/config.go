@@ -1,18 +1,39 @@
 package config
 
 import (
-	"errors"
-	"fmt"
 	"log"
 	"os"
-	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 type ServerConfig struct {
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
+
+	// ReadTimeoutMs, WriteTimeoutMs and IdleTimeoutMs bound connection-level
+	// I/O. Zero values fall back to built-in defaults.
+	ReadTimeoutMs  int `yaml:"readTimeoutMs"`
+	WriteTimeoutMs int `yaml:"writeTimeoutMs"`
+	IdleTimeoutMs  int `yaml:"idleTimeoutMs"`
+
+	// BodyLimitBytes caps request body size for routes without an override.
+	BodyLimitBytes int `yaml:"bodyLimitBytes"`
+
+	// Concurrency caps the number of concurrent connections served.
+	Concurrency int `yaml:"concurrency"`
+
+	// Routes lists per-route overrides, e.g. a larger body limit for upload
+	// endpoints or a longer timeout for long-polling endpoints.
+	Routes []ServerRouteConfig `yaml:"routes"`
+}
+
+// ServerRouteConfig overrides request limits for routes whose path starts
+// with Path. When several overrides match, the longest Path wins.
+type ServerRouteConfig struct {
+	// Method optionally restricts the override to one HTTP method.
+	Method         string `yaml:"method"`
+	Path           string `yaml:"path"`
+	TimeoutMs      int    `yaml:"timeoutMs"`
+	BodyLimitBytes int    `yaml:"bodyLimitBytes"`
 }
 
 type ScraperConfig struct {
@@ -20,29 +41,151 @@ type ScraperConfig struct {
 	TimeoutMs           int    `yaml:"timeoutMs"`
 	LinksSameDomainOnly bool   `yaml:"linksSameDomainOnly"`
 	LinksMaxPerDocument int    `yaml:"linksMaxPerDocument"`
+	// ImagesMaxPerDocument and ImageMaxBytes bound downloadImages archiving
+	// (defaults 20 images and 5 MiB per image).
+	ImagesMaxPerDocument int   `yaml:"imagesMaxPerDocument"`
+	ImageMaxBytes        int64 `yaml:"imageMaxBytes"`
+	// FetchMaxBytes caps bodies returned by /v1/fetch (default 10 MiB).
+	FetchMaxBytes int64 `yaml:"fetchMaxBytes"`
+	// DocumentMaxDecompressedBytes caps how much the compressed parts of
+	// an uploaded PDF or DOCX may expand to (default 100 MiB).
+	DocumentMaxDecompressedBytes int64 `yaml:"documentMaxDecompressedBytes"`
+	// AllowPrivateNetworks lets /v1/fetch and tenant transform hooks reach
+	// loopback, private and link-local addresses, which are refused by
+	// default.
+	AllowPrivateNetworks bool `yaml:"allowPrivateNetworks"`
+	// FormatMaxBytes caps markdown, html and rawHtml in scrape, crawl and
+	// batch responses. Requests may override the caps with maxFormatBytes
+	// up to FormatMaxBytesLimit.
+	FormatMaxBytes      FormatSizeLimits `yaml:"formatMaxBytes"`
+	FormatMaxBytesLimit FormatSizeLimits `yaml:"formatMaxBytesLimit"`
+	// DefaultFormats are used by scrape, crawl, batch and search requests
+	// that set no formats, unless the tenant has its own defaults
+	// (default ["markdown"]).
+	DefaultFormats []string `yaml:"defaultFormats"`
+}
+
+// FormatSizeLimits holds a byte size per text format; 0 means unlimited.
+type FormatSizeLimits struct {
+	Markdown int64 `yaml:"markdown"`
+	HTML     int64 `yaml:"html"`
+	RawHTML  int64 `yaml:"rawHtml"`
 }
 
 type CrawlerConfig struct {
 	MaxDepthDefault int `yaml:"maxDepthDefault"`
 	MaxPagesDefault int `yaml:"maxPagesDefault"`
+	// DelayMs is the least time between a crawl's requests to one host
+	// for crawls that do not set delay (default 0).
+	DelayMs int `yaml:"delayMs"`
+	// PriorityExpression is the default URL priority expression for crawls
+	// that do not set priorityExpression.
+	PriorityExpression string `yaml:"priorityExpression"`
 }
 
+// RobotsConfig is the identity Raito crawls under and how politely it
+// does so. The robots identity is separate from scraper.userAgent, which
+// may imitate a browser.
 type RobotsConfig struct {
 	Respect bool `yaml:"respect"`
+	// UserAgent is the product token matched against robots.txt
+	// User-agent lines, such as "RaitoBot". Defaults to scraper.userAgent.
+	UserAgent string `yaml:"userAgent"`
+	// ContactURL is appended to the User-Agent header of every request as
+	// "(+url)" so site owners can reach the operator.
+	ContactURL string `yaml:"contactUrl"`
+	// MinCrawlDelayMs is the least time between requests to one host,
+	// whatever robots.txt says (default 0).
+	MinCrawlDelayMs int `yaml:"minCrawlDelayMs"`
+	// MaxCrawlDelayMs caps the robots.txt Crawl-delay honored when Respect
+	// is set (default 10000).
+	MaxCrawlDelayMs int `yaml:"maxCrawlDelayMs"`
+}
+
+// DNSConfig controls how scrapes, crawls and outbound fetches resolve
+// host names. The browser engine resolves names itself.
+type DNSConfig struct {
+	// CacheEnabled keeps lookups and failures in process, so repeated
+	// requests to a host skip the resolver.
+	CacheEnabled bool `yaml:"cacheEnabled"`
+	// TTLSeconds is how long an answer is kept when the resolver does not
+	// report record TTLs (default 60).
+	TTLSeconds int `yaml:"ttlSeconds"`
+	// MinTTLSeconds and MaxTTLSeconds clamp record TTLs from DoHURL
+	// answers (defaults 5 and 3600).
+	MinTTLSeconds int `yaml:"minTtlSeconds"`
+	MaxTTLSeconds int `yaml:"maxTtlSeconds"`
+	// NegativeTTLSeconds is how long a failed lookup is remembered
+	// (default 10).
+	NegativeTTLSeconds int `yaml:"negativeTtlSeconds"`
+	// MaxEntries caps the cached host names (default 10000).
+	MaxEntries int `yaml:"maxEntries"`
+	// Nameservers are "host" or "host:port" DNS servers used instead of
+	// the system resolver.
+	Nameservers []string `yaml:"nameservers"`
+	// DoHURL sends lookups to a DNS-over-HTTPS endpoint instead, such as
+	// "https://cloudflare-dns.com/dns-query".
+	DoHURL string `yaml:"dohUrl"`
+	// TimeoutMs bounds one lookup (default 5000).
+	TimeoutMs int `yaml:"timeoutMs"`
 }
 
 type RodConfig struct {
 	Enabled bool `yaml:"enabled"`
+	// Isolation is "inprocess" (default) or "process". In process mode
+	// every browser scrape and screenshot runs in a child process, so a
+	// Chromium crash or OOM fails one URL instead of the whole worker.
+	Isolation string `yaml:"isolation"`
+	// MaxProcesses caps concurrent browser child processes per server
+	// process in process mode (default 4).
+	MaxProcesses int `yaml:"maxProcesses"`
 }
 
 type DatabaseConfig struct {
-	DSN string `yaml:"dsn"`
+	DSN         string                    `yaml:"dsn"`
+	Maintenance DatabaseMaintenanceConfig `yaml:"maintenance"`
+}
+
+// DatabaseMaintenanceConfig controls the nightly maintenance run that
+// workers schedule: ANALYZE on hot tables plus a table and index health
+// report.
+type DatabaseMaintenanceConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// HourUTC is the hour of day (0-23, UTC) after which the nightly run
+	// starts (default 0).
+	HourUTC int `yaml:"hourUTC"`
+	// Tables overrides the default list of hot tables to ANALYZE.
+	Tables []string `yaml:"tables"`
 }
 
 type RedisConfig struct {
 	URL string `yaml:"url"`
 }
 
+// EventsConfig selects how processes fan out events, such as live job
+// timelines, to every API replica.
+type EventsConfig struct {
+	// Transport is "redis" (pub/sub on redis.url), "postgres"
+	// (LISTEN/NOTIFY on database.dsn) or "memory" (this process only).
+	// When empty, Redis is used if redis.url is set and reachable, and
+	// Postgres otherwise.
+	Transport string `yaml:"transport"`
+}
+
+// MetricsConfig controls the counters served on /metrics.
+type MetricsConfig struct {
+	// Persist saves counters and histograms to Postgres every
+	// PersistIntervalSeconds and restores them at startup, so restarts do
+	// not reset totals.
+	Persist bool `yaml:"persist"`
+	// PersistIntervalSeconds is how often counters are saved (default 60).
+	PersistIntervalSeconds int `yaml:"persistIntervalSeconds"`
+	// Instance names the saved counters of this process. It defaults to
+	// the host name and process role; set a stable name per replica when
+	// host names change between restarts.
+	Instance string `yaml:"instance"`
+}
+
 type LocalAuthConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
@@ -60,6 +203,10 @@ type SessionAuthConfig struct {
 	Secret     string `yaml:"secret"`     // HS256 secret for JWT
 	CookieName string `yaml:"cookieName"` // defaults to "raito_session"
 	TTLMinutes int    `yaml:"ttlMinutes"` // session lifetime; default 1440 (24h)
+
+	// RememberMeDays is the sliding lifetime for sessions created with
+	// rememberMe; default 30.
+	RememberMeDays int `yaml:"rememberMeDays"`
 }
 
 type AuthConfig struct {
@@ -68,6 +215,54 @@ type AuthConfig struct {
 	Local           LocalAuthConfig   `yaml:"local"`
 	OIDC            OIDCAuthConfig    `yaml:"oidc"`
 	Session         SessionAuthConfig `yaml:"session"`
+	ShareLinks      ShareLinksConfig  `yaml:"shareLinks"`
+	Secrets         SecretsConfig     `yaml:"secrets"`
+	SCIM            SCIMConfig        `yaml:"scim"`
+}
+
+// SCIMConfig controls the SCIM 2.0 provisioning API under /scim/v2, which
+// identity providers call with a system admin API key.
+type SCIMConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// GroupMappings turn membership of SCIM groups into tenant
+	// memberships. Tenants named by a mapping are managed by SCIM: members
+	// of no mapped group are removed from them.
+	GroupMappings []SCIMGroupMapping `yaml:"groupMappings"`
+}
+
+// SCIMGroupMapping grants the members of a SCIM group a role in a tenant.
+type SCIMGroupMapping struct {
+	// Group is the group's displayName, matched case-insensitively.
+	Group string `yaml:"group"`
+	// Tenant is the slug of an existing tenant.
+	Tenant string `yaml:"tenant"`
+	// Role is "tenant_admin", "tenant_member" (default) or
+	// "tenant_viewer".
+	Role string `yaml:"role"`
+}
+
+// ShareLinksConfig controls signed public links to job results.
+type ShareLinksConfig struct {
+	// Secret signs share link tokens. When empty, a key derived from
+	// auth.session.secret is used. Changing it invalidates every
+	// outstanding link.
+	Secret string `yaml:"secret"`
+	// BaseURL is the public URL share links are built on, e.g.
+	// https://raito.example.com. When empty, links are returned as paths.
+	BaseURL string `yaml:"baseURL"`
+	// DefaultTTLHours applies when a share request omits ttlHours
+	// (default 72). MaxTTLHours caps requested lifetimes (default 720).
+	DefaultTTLHours int `yaml:"defaultTTLHours"`
+	MaxTTLHours     int `yaml:"maxTTLHours"`
+}
+
+// SecretsConfig controls tenant secrets referenced by scrape and crawl
+// requests for target authentication.
+type SecretsConfig struct {
+	// EncryptionKey encrypts secret values at rest. Tenant secrets are
+	// unavailable until it is set; changing it makes stored values
+	// unreadable.
+	EncryptionKey string `yaml:"encryptionKey"`
 }
 
 type RateLimitConfig struct {
@@ -79,6 +274,61 @@ type WorkerConfig struct {
 	PollIntervalMs          int `yaml:"pollIntervalMs"`
 	MaxConcurrentURLsPerJob int `yaml:"maxConcurrentURLsPerJob"`
 	SyncJobWaitTimeoutMs    int `yaml:"syncJobWaitTimeoutMs"`
+
+	// FallbackPollIntervalMs replaces PollIntervalMs when the events
+	// transport reaches other processes: workers are then woken as soon
+	// as a job is queued and only poll to catch missed wakeups (default
+	// 10000).
+	FallbackPollIntervalMs int `yaml:"fallbackPollIntervalMs"`
+
+	// SyncPriorityBoost is added to the claim priority of synchronous jobs
+	// still pending after SyncBoostAfterMs, so they start before their
+	// callers time out (default 1000; negative disables the boost).
+	// SyncBoostAfterMs defaults to half the sync wait timeout.
+	SyncPriorityBoost int `yaml:"syncPriorityBoost"`
+	SyncBoostAfterMs  int `yaml:"syncBoostAfterMs"`
+
+	// AdaptiveConcurrency replaces the fixed MaxConcurrentURLsPerJob limit
+	// for crawl and batch scrape jobs with a per-host controller when
+	// enabled.
+	AdaptiveConcurrency AdaptiveConcurrencyConfig `yaml:"adaptiveConcurrency"`
+
+	// Pools lists the job pools this worker claims jobs from. Empty serves
+	// only jobRouting.defaultPool.
+	Pools []string `yaml:"pools"`
+
+	// HeartbeatIntervalMs is how often a running job reports its progress
+	// (default 5000).
+	HeartbeatIntervalMs int `yaml:"heartbeatIntervalMs"`
+
+	// LegacyJobInputs stores new jobs' inputs without the versioned
+	// envelope, so workers from releases before it can still run them
+	// during a rolling upgrade. Turn it off once every worker is upgraded.
+	LegacyJobInputs bool `yaml:"legacyJobInputs"`
+}
+
+// JobRoutingConfig assigns new jobs to worker pools so deployments can
+// dedicate isolated workers (e.g. browser-capable or EU-only nodes) to
+// some tenants or requests. A job's pool is, in order: the request's
+// "pool" option (must be listed in AllowedPools), the tenant's entry in
+// TenantPools, BrowserPool for jobs that request browser rendering, and
+// DefaultPool.
+type JobRoutingConfig struct {
+	DefaultPool string `yaml:"defaultPool"`
+	// TenantPools maps tenant IDs to pools.
+	TenantPools  map[string]string `yaml:"tenantPools"`
+	BrowserPool  string            `yaml:"browserPool"`
+	AllowedPools []string          `yaml:"allowedPools"`
+}
+
+// AdaptiveConcurrencyConfig tunes per-host adaptive URL concurrency. Each
+// host starts at InitialPerHost and ramps toward MaxPerHost while responses
+// stay under TargetLatencyMs; 429/5xx responses and errors halve it.
+type AdaptiveConcurrencyConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	InitialPerHost  int  `yaml:"initialPerHost"`
+	MaxPerHost      int  `yaml:"maxPerHost"`
+	TargetLatencyMs int  `yaml:"targetLatencyMs"`
 }
 
 type OpenAIConfig struct {
@@ -102,6 +352,15 @@ type LLMConfig struct {
 	OpenAI          OpenAIConfig    `yaml:"openai"`
 	Anthropic       AnthropicConfig `yaml:"anthropic"`
 	Google          GoogleLLMConfig `yaml:"google"`
+	// Pricing maps model names to their price, used to charge LLM calls
+	// against tenant spend caps. Calls to unlisted models cost nothing.
+	Pricing map[string]LLMModelPricing `yaml:"pricing"`
+}
+
+// LLMModelPricing is the USD price per million tokens for one model.
+type LLMModelPricing struct {
+	InputPerMillion  float64 `yaml:"inputPerMillion"`
+	OutputPerMillion float64 `yaml:"outputPerMillion"`
 }
 
 // SearxngConfig holds provider-specific configuration for SearxNG-based search.
@@ -121,6 +380,22 @@ type SearchConfig struct {
 	Searxng              SearxngConfig `yaml:"searxng"`
 }
 
+// ExtractCacheConfig controls reuse of per-URL extract results. Entries are
+// keyed by tenant, URL, schema hash, and prompt hash.
+type ExtractCacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TTLHours is how long a cached result is kept (default 24).
+	TTLHours int `yaml:"ttlHours"`
+	// DefaultMaxAgeMs is the reuse window applied when a request does not
+	// set maxAge. Zero means any unexpired entry may be reused.
+	DefaultMaxAgeMs int `yaml:"defaultMaxAgeMs"`
+}
+
+// ExtractConfig holds settings specific to /v1/extract.
+type ExtractConfig struct {
+	Cache ExtractCacheConfig `yaml:"cache"`
+}
+
 // JobTTLConfig controls per-job-type retention in days.
 type JobTTLConfig struct {
 	DefaultDays int `yaml:"defaultDays"`
@@ -143,6 +418,77 @@ type RetentionConfig struct {
 	CleanupIntervalMinutes int               `yaml:"cleanupIntervalMinutes"`
 	Jobs                   JobTTLConfig      `yaml:"jobs"`
 	Documents              DocumentTTLConfig `yaml:"documents"`
+	// ZeroRetentionMinutes bounds how long a finished zero-retention job
+	// keeps results that were never fetched (default 60). This sweep runs
+	// even when TTL cleanup is disabled.
+	ZeroRetentionMinutes int `yaml:"zeroRetentionMinutes"`
+	// DocumentChangesDays is how long superseded entries and deletes stay
+	// in the document change feed (GET /v1/documents/changes). The latest
+	// change of each existing document is always kept. 0 keeps everything.
+	DocumentChangesDays int `yaml:"documentChangesDays"`
+	// DocumentVersionsPerURL is how many content versions of each URL a
+	// tenant's history keeps for GET /v1/documents/by-url (default 20).
+	// It applies even when TTL cleanup is disabled.
+	DocumentVersionsPerURL int `yaml:"documentVersionsPerUrl"`
+}
+
+// SMTPConfig is the mail server used for email notifications.
+type SMTPConfig struct {
+	Host string `yaml:"host"`
+	// Port defaults to 587. STARTTLS is used when the server offers it.
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// From is the sender address, e.g. "Raito <raito@example.com>".
+	From string `yaml:"from"`
+}
+
+// NotificationsConfig controls the job notifications users subscribe to
+// via /v1/me/notifications.
+type NotificationsConfig struct {
+	// SMTP delivers email notifications; email is unavailable while
+	// smtp.host is empty.
+	SMTP SMTPConfig `yaml:"smtp"`
+	// WebhookTimeoutMs bounds each personal webhook delivery (default 10000).
+	WebhookTimeoutMs int `yaml:"webhookTimeoutMs"`
+	// AllowPrivateNetworks lets personal webhooks reach loopback, private
+	// and link-local addresses, which are refused by default.
+	AllowPrivateNetworks bool `yaml:"allowPrivateNetworks"`
+	// WebhookSecretGraceHours is how long a rotated-out tenant signing
+	// secret keeps signing deliveries alongside the new one (default 24).
+	WebhookSecretGraceHours int `yaml:"webhookSecretGraceHours"`
+	// WebhookReplayWindowSeconds is the timestamp tolerance used when
+	// verifying signed sample payloads (default 300).
+	WebhookReplayWindowSeconds int `yaml:"webhookReplayWindowSeconds"`
+}
+
+// FormatPluginConfig installs one format plugin. The plugin reads a JSON
+// request describing the scrape result on stdin and writes a JSON reply
+// on stdout.
+type FormatPluginConfig struct {
+	// Name is the format name requests use, e.g. "readability".
+	Name string `yaml:"name"`
+	// Type is "exec" for an executable or "wasm" for a WASI module.
+	Type string `yaml:"type"`
+	// Command is the executable and its arguments (exec plugins).
+	Command []string `yaml:"command"`
+	// Module is the path to the .wasm file (wasm plugins).
+	Module string `yaml:"module"`
+	// TimeoutMs bounds each run (default 10000).
+	TimeoutMs int `yaml:"timeoutMs"`
+	// MaxOutputBytes bounds the reply read from the plugin (default 1 MiB).
+	MaxOutputBytes int `yaml:"maxOutputBytes"`
+	// Env is added to the plugin's environment. Plugins do not inherit the
+	// server's environment.
+	Env map[string]string `yaml:"env"`
+}
+
+// PluginsConfig lists the format plugins installed by the operator.
+type PluginsConfig struct {
+	// WASMRuntime is the command that runs wasm plugins; the module path is
+	// appended, e.g. ["wasmtime", "run"].
+	WASMRuntime []string             `yaml:"wasmRuntime"`
+	Formats     []FormatPluginConfig `yaml:"formats"`
 }
 
 type BootstrapUserConfig struct {
@@ -151,6 +497,20 @@ type BootstrapUserConfig struct {
 	IsSystemAdmin bool   `yaml:"isSystemAdmin"`
 	Provider      string `yaml:"provider"` // local or oidc
 	Password      string `yaml:"password,omitempty"`
+	// MustChangePassword forces a local user to choose a new password on
+	// first login.
+	MustChangePassword bool `yaml:"mustChangePassword,omitempty"`
+}
+
+// InitialAdminConfig describes a local system admin that is created on
+// first run, i.e. only while no system admin user exists yet. The user must
+// change the password on first login, so the configured password is a
+// one-time credential and is exempt from allowPlaintextPasswords. When the
+// password is empty, a random one is generated and logged once.
+type InitialAdminConfig struct {
+	Email    string `yaml:"email"`
+	Name     string `yaml:"name"`
+	Password string `yaml:"password,omitempty"`
 }
 
 type BootstrapTenantConfig struct {
@@ -159,10 +519,13 @@ type BootstrapTenantConfig struct {
 	Type    string   `yaml:"type"` // personal or org
 	Admins  []string `yaml:"admins"`
 	Members []string `yaml:"members"`
+	// Viewers get read-only access to the tenant's jobs and results.
+	Viewers []string `yaml:"viewers"`
 }
 
 type BootstrapConfig struct {
 	AllowPlaintextPasswords bool                    `yaml:"allowPlaintextPasswords"`
+	InitialAdmin            InitialAdminConfig      `yaml:"initialAdmin"`
 	Users                   []BootstrapUserConfig   `yaml:"users"`
 	Tenants                 []BootstrapTenantConfig `yaml:"tenants"`
 }
@@ -173,86 +536,62 @@ type Config struct {
 	Crawler   CrawlerConfig   `yaml:"crawler"`
 	Robots    RobotsConfig    `yaml:"robots"`
 	Rod       RodConfig       `yaml:"rod"`
+	DNS       DNSConfig       `yaml:"dns"`
 	Database  DatabaseConfig  `yaml:"database"`
 	Redis     RedisConfig     `yaml:"redis"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Events    EventsConfig    `yaml:"events"`
 	Auth      AuthConfig      `yaml:"auth"`
 	RateLimit RateLimitConfig `yaml:"ratelimit"`
 	Worker    WorkerConfig    `yaml:"worker"`
 	LLM       LLMConfig       `yaml:"llm"`
 	Search    SearchConfig    `yaml:"search"`
+	Extract   ExtractConfig   `yaml:"extract"`
 	Retention RetentionConfig `yaml:"retention"`
+	Plugins   PluginsConfig   `yaml:"plugins"`
 	Bootstrap BootstrapConfig `yaml:"bootstrap"`
 
+	Notifications NotificationsConfig `yaml:"notifications"`
+
+	JobRouting JobRoutingConfig `yaml:"jobRouting"`
+
 	// Path is the source path this config was loaded from. It is not
 	// loaded from YAML.
 	Path string `yaml:"-"`
 }
 
+// Load reads and decodes the config file at path, logging a warning for
+// every key that does not map to a config field.
 func Load(path string) *Config {
-	f, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		log.Fatalf("failed to open config file: %v", err)
 	}
-	defer f.Close()
 
-	var cfg Config
-	if err := yaml.NewDecoder(f).Decode(&cfg); err != nil {
+	cfg, unknown, err := Parse(data)
+	if err != nil {
 		log.Fatalf("failed to decode config: %v", err)
 	}
+	for _, is := range unknown {
+		log.Printf("config warning: %s", is)
+	}
 
 	cfg.Path = path
-	return &cfg
+	return cfg
 }
 
-// Validate performs basic sanity checks on the loaded configuration.
-// It focuses on LLM defaults so that obviously misconfigured providers
-// fail fast at startup rather than during the first request.
+// Validate runs Check and returns a *ValidationError listing every
+// error-severity issue, so misconfigurations fail fast at startup rather
+// than during the first request.
 func (cfg *Config) Validate() error {
-	if cfg == nil {
-		return errors.New("config is nil")
-	}
-
-	provider := strings.TrimSpace(cfg.LLM.DefaultProvider)
-	if provider == "" {
-		return errors.New("llm.defaultProvider must be set to 'openai', 'anthropic', or 'google'")
-	}
-
-	switch provider {
-	case "openai":
-		if cfg.LLM.OpenAI.APIKey == "" || cfg.LLM.OpenAI.Model == "" {
-			return errors.New("openai llm provider is not fully configured")
-		}
-	case "anthropic":
-		if cfg.LLM.Anthropic.APIKey == "" || cfg.LLM.Anthropic.Model == "" {
-			return errors.New("anthropic llm provider is not fully configured")
-		}
-	case "google":
-		if cfg.LLM.Google.APIKey == "" || cfg.LLM.Google.Model == "" {
-			return errors.New("google llm provider is not fully configured")
-		}
-	default:
-		return fmt.Errorf("unsupported llm.defaultProvider: %s", provider)
-	}
-
-	// Basic auth validation: ensure OIDC config is complete when enabled.
-	if cfg.Auth.OIDC.Enabled {
-		if strings.TrimSpace(cfg.Auth.OIDC.IssuerURL) == "" ||
-			strings.TrimSpace(cfg.Auth.OIDC.ClientID) == "" ||
-			strings.TrimSpace(cfg.Auth.OIDC.ClientSecret) == "" ||
-			strings.TrimSpace(cfg.Auth.OIDC.RedirectURL) == "" {
-			return errors.New("auth.oidc is enabled but issuerURL, clientID, clientSecret, or redirectURL is missing")
+	var errs []Issue
+	for _, is := range cfg.Check() {
+		if is.Severity == SeverityError {
+			errs = append(errs, is)
 		}
 	}
-
-	// Prevent accidental plaintext passwords in bootstrap users unless
-	// explicitly allowed in configuration.
-	if !cfg.Bootstrap.AllowPlaintextPasswords {
-		for _, u := range cfg.Bootstrap.Users {
-			if strings.EqualFold(strings.TrimSpace(u.Provider), "local") && strings.TrimSpace(u.Password) != "" {
-				return errors.New("bootstrap users contain plaintext passwords but bootstrap.allowPlaintextPasswords is false")
-			}
-		}
+	if len(errs) > 0 {
+		return &ValidationError{Issues: errs}
 	}
-
 	return nil
 }
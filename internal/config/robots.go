@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultMaxCrawlDelay caps the robots.txt Crawl-delay honored when
+// robots.maxCrawlDelayMs is unset.
+const DefaultMaxCrawlDelay = 10 * time.Second
+
+// RequestUserAgent returns the User-Agent header sent when scraping,
+// crawling and fetching robots.txt: scraper.userAgent, or robots.userAgent
+// when that is unset, followed by "(+contactUrl)" when one is configured.
+func (cfg *Config) RequestUserAgent() string {
+	if cfg == nil {
+		return ""
+	}
+	ua := cfg.Scraper.UserAgent
+	if ua == "" {
+		ua = cfg.Robots.UserAgent
+	}
+	contact := strings.TrimSpace(cfg.Robots.ContactURL)
+	if ua == "" || contact == "" || strings.Contains(ua, contact) {
+		return ua
+	}
+	return ua + " (+" + contact + ")"
+}
+
+// RobotsAgent returns the token matched against robots.txt User-agent
+// lines: robots.userAgent, or the request user agent when that is unset.
+func (cfg *Config) RobotsAgent() string {
+	if cfg == nil {
+		return ""
+	}
+	if cfg.Robots.UserAgent != "" {
+		return cfg.Robots.UserAgent
+	}
+	return cfg.RequestUserAgent()
+}
+
+// CrawlDelay returns the time to leave between requests to a host whose
+// robots.txt asks for robotsDelay (0 when it sets none): robotsDelay,
+// honored only when robots.respect is set and capped at
+// robots.maxCrawlDelayMs, but never less than robots.minCrawlDelayMs.
+func (cfg *Config) CrawlDelay(robotsDelay time.Duration) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+	delay := time.Duration(0)
+	if cfg.Robots.Respect && robotsDelay > 0 {
+		ceiling := DefaultMaxCrawlDelay
+		if cfg.Robots.MaxCrawlDelayMs > 0 {
+			ceiling = time.Duration(cfg.Robots.MaxCrawlDelayMs) * time.Millisecond
+		}
+		delay = min(robotsDelay, ceiling)
+	}
+	return max(delay, time.Duration(cfg.Robots.MinCrawlDelayMs)*time.Millisecond)
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRobotsIdentity(t *testing.T) {
+	cfg := &Config{}
+	cfg.Scraper.UserAgent = "Mozilla/5.0 (compatible; Raito)"
+	if got := cfg.RobotsAgent(); got != cfg.Scraper.UserAgent {
+		t.Fatalf("expected the robots agent to default to the user agent, got %q", got)
+	}
+
+	cfg.Robots.UserAgent = "RaitoBot"
+	cfg.Robots.ContactURL = "https://example.com/bot"
+	if got := cfg.RequestUserAgent(); got != "Mozilla/5.0 (compatible; Raito) (+https://example.com/bot)" {
+		t.Fatalf("unexpected request user agent %q", got)
+	}
+	if got := cfg.RobotsAgent(); got != "RaitoBot" {
+		t.Fatalf("unexpected robots agent %q", got)
+	}
+
+	cfg.Scraper.UserAgent = ""
+	if got := cfg.RequestUserAgent(); got != "RaitoBot (+https://example.com/bot)" {
+		t.Fatalf("expected the robots token to stand in for an unset user agent, got %q", got)
+	}
+}
+
+func TestCrawlDelay(t *testing.T) {
+	cfg := &Config{}
+	cfg.Robots.MinCrawlDelayMs = 500
+	if got := cfg.CrawlDelay(5 * time.Second); got != 500*time.Millisecond {
+		t.Fatalf("expected robots.txt delays to be ignored without respect, got %s", got)
+	}
+
+	cfg.Robots.Respect = true
+	if got := cfg.CrawlDelay(5 * time.Second); got != 5*time.Second {
+		t.Fatalf("expected the robots.txt delay, got %s", got)
+	}
+	if got := cfg.CrawlDelay(time.Minute); got != DefaultMaxCrawlDelay {
+		t.Fatalf("expected the default ceiling, got %s", got)
+	}
+	if got := cfg.CrawlDelay(0); got != 500*time.Millisecond {
+		t.Fatalf("expected the floor, got %s", got)
+	}
+
+	cfg.Robots.MaxCrawlDelayMs = 2000
+	if got := cfg.CrawlDelay(5 * time.Second); got != 2*time.Second {
+		t.Fatalf("expected the configured ceiling, got %s", got)
+	}
+}
@@ -0,0 +1,505 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	"raito/internal/formats"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a single problem found in a configuration. Path is the dotted
+// YAML key the problem is about, e.g. "auth.oidc.issuerURL".
+type Issue struct {
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// ValidationError reports every error-severity issue found by Validate.
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Issues) == 1 {
+		return e.Issues[0].String()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems:", len(e.Issues))
+	for _, is := range e.Issues {
+		b.WriteString("\n  - ")
+		b.WriteString(is.String())
+	}
+	return b.String()
+}
+
+// Parse decodes YAML config data. Keys that do not map to a config field
+// are returned as warnings rather than silently ignored.
+func Parse(data []byte) (*Config, []Issue, error) {
+	var cfg Config
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&cfg); err != nil {
+		return nil, nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	var issues []Issue
+	if len(root.Content) > 0 {
+		issues = unknownKeys(root.Content[0], reflect.TypeOf(cfg), "")
+	}
+	return &cfg, issues, nil
+}
+
+// Check returns every problem with the configuration. Errors would make
+// the server fail at startup or at request time; warnings are likely
+// mistakes that do not break anything.
+func (cfg *Config) Check() []Issue {
+	if cfg == nil {
+		return []Issue{{Message: "config is nil", Severity: SeverityError}}
+	}
+
+	var issues []Issue
+	errorf := func(path, format string, args ...any) {
+		issues = append(issues, Issue{Path: path, Message: fmt.Sprintf(format, args...), Severity: SeverityError})
+	}
+	warnf := func(path, format string, args ...any) {
+		issues = append(issues, Issue{Path: path, Message: fmt.Sprintf(format, args...), Severity: SeverityWarning})
+	}
+	nonNegative := func(path string, v int) {
+		if v < 0 {
+			errorf(path, "must be >= 0, got %d", v)
+		}
+	}
+
+	// server
+	if cfg.Server.Port < 0 || cfg.Server.Port > 65535 {
+		errorf("server.port", "must be between 0 and 65535, got %d", cfg.Server.Port)
+	}
+	nonNegative("server.readTimeoutMs", cfg.Server.ReadTimeoutMs)
+	nonNegative("server.writeTimeoutMs", cfg.Server.WriteTimeoutMs)
+	nonNegative("server.idleTimeoutMs", cfg.Server.IdleTimeoutMs)
+	nonNegative("server.bodyLimitBytes", cfg.Server.BodyLimitBytes)
+	nonNegative("server.concurrency", cfg.Server.Concurrency)
+	for i, r := range cfg.Server.Routes {
+		path := fmt.Sprintf("server.routes[%d]", i)
+		if !strings.HasPrefix(r.Path, "/") {
+			errorf(path+".path", "must start with /, got %q", r.Path)
+		}
+		nonNegative(path+".timeoutMs", r.TimeoutMs)
+		nonNegative(path+".bodyLimitBytes", r.BodyLimitBytes)
+	}
+
+	// scraper, crawler, worker, ratelimit
+	nonNegative("scraper.timeoutMs", cfg.Scraper.TimeoutMs)
+	nonNegative("scraper.linksMaxPerDocument", cfg.Scraper.LinksMaxPerDocument)
+	nonNegative("scraper.imagesMaxPerDocument", cfg.Scraper.ImagesMaxPerDocument)
+	if cfg.Scraper.ImageMaxBytes < 0 {
+		errorf("scraper.imageMaxBytes", "must be >= 0, got %d", cfg.Scraper.ImageMaxBytes)
+	}
+	if cfg.Scraper.FetchMaxBytes < 0 {
+		errorf("scraper.fetchMaxBytes", "must be >= 0, got %d", cfg.Scraper.FetchMaxBytes)
+	}
+	if cfg.Scraper.DocumentMaxDecompressedBytes < 0 {
+		errorf("scraper.documentMaxDecompressedBytes", "must be >= 0, got %d", cfg.Scraper.DocumentMaxDecompressedBytes)
+	}
+	for _, f := range []struct {
+		name       string
+		cap, limit int64
+	}{
+		{"markdown", cfg.Scraper.FormatMaxBytes.Markdown, cfg.Scraper.FormatMaxBytesLimit.Markdown},
+		{"html", cfg.Scraper.FormatMaxBytes.HTML, cfg.Scraper.FormatMaxBytesLimit.HTML},
+		{"rawHtml", cfg.Scraper.FormatMaxBytes.RawHTML, cfg.Scraper.FormatMaxBytesLimit.RawHTML},
+	} {
+		if f.cap < 0 {
+			errorf("scraper.formatMaxBytes."+f.name, "must be >= 0, got %d", f.cap)
+		}
+		if f.limit < 0 {
+			errorf("scraper.formatMaxBytesLimit."+f.name, "must be >= 0, got %d", f.limit)
+		}
+		if f.limit > 0 && (f.cap == 0 || f.cap > f.limit) {
+			errorf("scraper.formatMaxBytes."+f.name, "must be between 1 and formatMaxBytesLimit.%s (%d), got %d", f.name, f.limit, f.cap)
+		}
+	}
+	plugins := make([]string, 0, len(cfg.Plugins.Formats))
+	for _, p := range cfg.Plugins.Formats {
+		plugins = append(plugins, p.Name)
+	}
+	if _, err := formats.NormalizeDefaultFormats(cfg.Scraper.DefaultFormats, plugins); err != nil {
+		errorf("scraper.defaultFormats", "%v", err)
+	}
+	nonNegative("crawler.maxDepthDefault", cfg.Crawler.MaxDepthDefault)
+	nonNegative("crawler.delayMs", cfg.Crawler.DelayMs)
+	nonNegative("crawler.maxPagesDefault", cfg.Crawler.MaxPagesDefault)
+	nonNegative("ratelimit.defaultPerMinute", cfg.RateLimit.DefaultPerMinute)
+	nonNegative("worker.maxConcurrentJobs", cfg.Worker.MaxConcurrentJobs)
+	nonNegative("worker.pollIntervalMs", cfg.Worker.PollIntervalMs)
+	nonNegative("worker.fallbackPollIntervalMs", cfg.Worker.FallbackPollIntervalMs)
+	nonNegative("worker.maxConcurrentURLsPerJob", cfg.Worker.MaxConcurrentURLsPerJob)
+	nonNegative("worker.syncJobWaitTimeoutMs", cfg.Worker.SyncJobWaitTimeoutMs)
+	nonNegative("worker.syncBoostAfterMs", cfg.Worker.SyncBoostAfterMs)
+	nonNegative("worker.heartbeatIntervalMs", cfg.Worker.HeartbeatIntervalMs)
+	switch cfg.Rod.Isolation {
+	case "", "inprocess", "process":
+	default:
+		errorf("rod.isolation", "must be 'inprocess' or 'process', got %q", cfg.Rod.Isolation)
+	}
+	nonNegative("rod.maxProcesses", cfg.Rod.MaxProcesses)
+	if a := cfg.Worker.AdaptiveConcurrency; a.Enabled && a.MaxPerHost > 0 && a.InitialPerHost > a.MaxPerHost {
+		errorf("worker.adaptiveConcurrency.initialPerHost", "must not exceed maxPerHost (%d), got %d", a.MaxPerHost, a.InitialPerHost)
+	}
+
+	// robots
+	nonNegative("robots.minCrawlDelayMs", cfg.Robots.MinCrawlDelayMs)
+	nonNegative("robots.maxCrawlDelayMs", cfg.Robots.MaxCrawlDelayMs)
+	if r := cfg.Robots; r.MaxCrawlDelayMs > 0 && r.MinCrawlDelayMs > r.MaxCrawlDelayMs {
+		errorf("robots.minCrawlDelayMs", "must not exceed maxCrawlDelayMs (%d), got %d", r.MaxCrawlDelayMs, r.MinCrawlDelayMs)
+	}
+	if r := cfg.Robots; r.ContactURL != "" {
+		if u, err := url.Parse(r.ContactURL); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+			errorf("robots.contactUrl", "must be an http(s) or mailto URL, got %q", r.ContactURL)
+		}
+	}
+	if strings.ContainsAny(cfg.Robots.UserAgent, " /") {
+		warnf("robots.userAgent", "should be a product token without spaces or a version, such as \"RaitoBot\", got %q", cfg.Robots.UserAgent)
+	}
+
+	// dns
+	nonNegative("dns.ttlSeconds", cfg.DNS.TTLSeconds)
+	nonNegative("dns.minTtlSeconds", cfg.DNS.MinTTLSeconds)
+	nonNegative("dns.maxTtlSeconds", cfg.DNS.MaxTTLSeconds)
+	nonNegative("dns.negativeTtlSeconds", cfg.DNS.NegativeTTLSeconds)
+	nonNegative("dns.maxEntries", cfg.DNS.MaxEntries)
+	nonNegative("dns.timeoutMs", cfg.DNS.TimeoutMs)
+	if d := cfg.DNS; d.MaxTTLSeconds > 0 && d.MinTTLSeconds > d.MaxTTLSeconds {
+		errorf("dns.minTtlSeconds", "must not exceed maxTtlSeconds (%d), got %d", d.MaxTTLSeconds, d.MinTTLSeconds)
+	}
+	if d := cfg.DNS; d.DoHURL != "" {
+		if u, err := url.Parse(d.DoHURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			errorf("dns.dohUrl", "must be an https URL, got %q", d.DoHURL)
+		}
+		if len(d.Nameservers) > 0 {
+			warnf("dns.nameservers", "is ignored when dns.dohUrl is set")
+		}
+	}
+	for i, ns := range cfg.DNS.Nameservers {
+		host := ns
+		if h, _, err := net.SplitHostPort(ns); err == nil {
+			host = h
+		}
+		if net.ParseIP(host) == nil {
+			errorf(fmt.Sprintf("dns.nameservers[%d]", i), "must be an IP address with an optional port, got %q", ns)
+		}
+	}
+
+	// auth.shareLinks
+	nonNegative("auth.shareLinks.defaultTTLHours", cfg.Auth.ShareLinks.DefaultTTLHours)
+	nonNegative("auth.shareLinks.maxTTLHours", cfg.Auth.ShareLinks.MaxTTLHours)
+	if s := cfg.Auth.ShareLinks; s.MaxTTLHours > 0 && s.DefaultTTLHours > s.MaxTTLHours {
+		errorf("auth.shareLinks.defaultTTLHours", "must not exceed maxTTLHours (%d), got %d", s.MaxTTLHours, s.DefaultTTLHours)
+	}
+	if raw := strings.TrimSpace(cfg.Auth.ShareLinks.BaseURL); raw != "" {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errorf("auth.shareLinks.baseURL", "must be an absolute http or https URL, got %q", raw)
+		}
+	}
+
+	// retention
+	nonNegative("retention.cleanupIntervalMinutes", cfg.Retention.CleanupIntervalMinutes)
+	nonNegative("retention.zeroRetentionMinutes", cfg.Retention.ZeroRetentionMinutes)
+	nonNegative("retention.documentChangesDays", cfg.Retention.DocumentChangesDays)
+	nonNegative("retention.documentVersionsPerUrl", cfg.Retention.DocumentVersionsPerURL)
+
+	// notifications
+	nonNegative("notifications.webhookTimeoutMs", cfg.Notifications.WebhookTimeoutMs)
+	nonNegative("notifications.webhookSecretGraceHours", cfg.Notifications.WebhookSecretGraceHours)
+	nonNegative("notifications.webhookReplayWindowSeconds", cfg.Notifications.WebhookReplayWindowSeconds)
+	if smtp := cfg.Notifications.SMTP; strings.TrimSpace(smtp.Host) != "" {
+		if smtp.Port < 0 || smtp.Port > 65535 {
+			errorf("notifications.smtp.port", "must be between 0 and 65535, got %d", smtp.Port)
+		}
+		if strings.TrimSpace(smtp.From) == "" {
+			errorf("notifications.smtp.from", "is required when notifications.smtp.host is set")
+		}
+	}
+
+	// jobRouting
+	for tenantID := range cfg.JobRouting.TenantPools {
+		if _, err := uuid.Parse(tenantID); err != nil {
+			errorf("jobRouting.tenantPools", "keys must be tenant IDs (UUIDs), got %q", tenantID)
+		}
+	}
+
+	// database
+	if strings.TrimSpace(cfg.Database.DSN) == "" {
+		errorf("database.dsn", "is required")
+	}
+	if h := cfg.Database.Maintenance.HourUTC; h < 0 || h > 23 {
+		errorf("database.maintenance.hourUTC", "must be between 0 and 23, got %d", h)
+	}
+
+	// metrics
+	nonNegative("metrics.persistIntervalSeconds", cfg.Metrics.PersistIntervalSeconds)
+
+	// events
+	switch transport := strings.TrimSpace(cfg.Events.Transport); transport {
+	case "", "postgres", "memory":
+	case "redis":
+		if strings.TrimSpace(cfg.Redis.URL) == "" {
+			errorf("events.transport", "redis requires redis.url")
+		}
+	default:
+		errorf("events.transport", "unsupported transport %q; use 'redis', 'postgres', or 'memory'", transport)
+	}
+
+	// auth
+	if !cfg.Auth.Enabled {
+		warnf("auth.enabled", "auth is disabled; every /v1 and /admin endpoint is open to anyone who can reach the server")
+	}
+	if cfg.Auth.OIDC.Enabled {
+		for _, f := range []struct{ key, value string }{
+			{"issuerURL", cfg.Auth.OIDC.IssuerURL},
+			{"clientID", cfg.Auth.OIDC.ClientID},
+			{"clientSecret", cfg.Auth.OIDC.ClientSecret},
+			{"redirectURL", cfg.Auth.OIDC.RedirectURL},
+		} {
+			if strings.TrimSpace(f.value) == "" {
+				errorf("auth.oidc."+f.key, "is required when auth.oidc.enabled is true")
+			}
+		}
+	}
+	if (cfg.Auth.Local.Enabled || cfg.Auth.OIDC.Enabled) && strings.TrimSpace(cfg.Auth.Session.Secret) == "" {
+		warnf("auth.session.secret", "is empty, so local and OIDC logins will not issue session cookies; set it to a long random string")
+	}
+	nonNegative("auth.session.ttlMinutes", cfg.Auth.Session.TTLMinutes)
+	for i, m := range cfg.Auth.SCIM.GroupMappings {
+		key := fmt.Sprintf("auth.scim.groupMappings[%d]", i)
+		if strings.TrimSpace(m.Group) == "" {
+			errorf(key+".group", "is required")
+		}
+		if strings.TrimSpace(m.Tenant) == "" {
+			errorf(key+".tenant", "is required")
+		}
+		switch m.Role {
+		case "", "tenant_admin", "tenant_member", "tenant_viewer":
+		default:
+			errorf(key+".role", "unsupported role %q; use 'tenant_admin', 'tenant_member', or 'tenant_viewer'", m.Role)
+		}
+	}
+	if cfg.Auth.SCIM.Enabled && !cfg.Auth.Enabled {
+		warnf("auth.scim.enabled", "has no effect while auth.enabled is false")
+	}
+
+	// llm
+	switch provider := strings.TrimSpace(cfg.LLM.DefaultProvider); provider {
+	case "":
+		errorf("llm.defaultProvider", "must be set to 'openai', 'anthropic', or 'google'")
+	case "openai":
+		llmProviderIssues(errorf, provider, cfg.LLM.OpenAI.APIKey, cfg.LLM.OpenAI.Model)
+	case "anthropic":
+		llmProviderIssues(errorf, provider, cfg.LLM.Anthropic.APIKey, cfg.LLM.Anthropic.Model)
+	case "google":
+		llmProviderIssues(errorf, provider, cfg.LLM.Google.APIKey, cfg.LLM.Google.Model)
+	default:
+		errorf("llm.defaultProvider", "unsupported provider %q; use 'openai', 'anthropic', or 'google'", provider)
+	}
+	for model, price := range cfg.LLM.Pricing {
+		if price.InputPerMillion < 0 || price.OutputPerMillion < 0 {
+			errorf("llm.pricing."+model, "prices must be >= 0")
+		}
+	}
+
+	// search
+	nonNegative("search.maxResults", cfg.Search.MaxResults)
+	nonNegative("search.timeoutMs", cfg.Search.TimeoutMs)
+	nonNegative("search.maxConcurrentScrapes", cfg.Search.MaxConcurrentScrapes)
+	nonNegative("search.searxng.defaultLimit", cfg.Search.Searxng.DefaultLimit)
+	nonNegative("search.searxng.timeoutMs", cfg.Search.Searxng.TimeoutMs)
+	if cfg.Search.Enabled {
+		switch provider := strings.ToLower(strings.TrimSpace(cfg.Search.Provider)); provider {
+		case "", "searxng":
+			if strings.TrimSpace(cfg.Search.Searxng.BaseURL) == "" {
+				errorf("search.searxng.baseURL", "is required when search is enabled with the searxng provider")
+			}
+		default:
+			errorf("search.provider", "unsupported provider %q; only 'searxng' is supported", provider)
+		}
+	}
+
+	// plugins
+	pluginNames := map[string]bool{}
+	hasWASM := false
+	for i, p := range cfg.Plugins.Formats {
+		path := fmt.Sprintf("plugins.formats[%d]", i)
+		name := strings.ToLower(strings.TrimSpace(p.Name))
+		switch {
+		case name == "":
+			errorf(path+".name", "is required")
+		case builtinFormats[name]:
+			errorf(path+".name", "%q is a built-in format", p.Name)
+		case pluginNames[name]:
+			errorf(path+".name", "duplicate plugin %q", p.Name)
+		}
+		pluginNames[name] = true
+		switch p.Type {
+		case "exec":
+			if len(p.Command) == 0 || strings.TrimSpace(p.Command[0]) == "" {
+				errorf(path+".command", "is required for exec plugins")
+			}
+		case "wasm":
+			if strings.TrimSpace(p.Module) == "" {
+				errorf(path+".module", "is required for wasm plugins")
+			}
+			hasWASM = true
+		default:
+			errorf(path+".type", "must be 'exec' or 'wasm', got %q", p.Type)
+		}
+		nonNegative(path+".timeoutMs", p.TimeoutMs)
+		nonNegative(path+".maxOutputBytes", p.MaxOutputBytes)
+	}
+	if hasWASM && len(cfg.Plugins.WASMRuntime) == 0 {
+		errorf("plugins.wasmRuntime", "is required when wasm plugins are installed")
+	}
+
+	// bootstrap
+	if !cfg.Bootstrap.AllowPlaintextPasswords {
+		for i, u := range cfg.Bootstrap.Users {
+			if strings.EqualFold(strings.TrimSpace(u.Provider), "local") && strings.TrimSpace(u.Password) != "" {
+				errorf(fmt.Sprintf("bootstrap.users[%d].password", i), "plaintext passwords require bootstrap.allowPlaintextPasswords: true")
+			}
+		}
+	}
+
+	return issues
+}
+
+// builtinFormats are the lowercased format names plugins may not shadow.
+var builtinFormats = map[string]bool{
+	"markdown": true, "html": true, "rawhtml": true, "links": true, "images": true,
+	"summary": true, "json": true, "branding": true, "screenshot": true, "tables": true,
+	"a11y": true, "performance": true, "auto": true, "structureddata": true,
+}
+
+func llmProviderIssues(errorf func(path, format string, args ...any), provider, apiKey, model string) {
+	if strings.TrimSpace(apiKey) == "" {
+		errorf("llm."+provider+".apiKey", "is required when llm.defaultProvider is %q", provider)
+	}
+	if strings.TrimSpace(model) == "" {
+		errorf("llm."+provider+".model", "is required when llm.defaultProvider is %q", provider)
+	}
+}
+
+// unknownKeys walks a YAML mapping alongside the struct type it decodes
+// into and reports keys that match no field.
+func unknownKeys(node *yaml.Node, t reflect.Type, prefix string) []Issue {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var issues []Issue
+	switch {
+	case node.Kind == yaml.SequenceNode && t.Kind() == reflect.Slice:
+		for i, item := range node.Content {
+			issues = append(issues, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", prefix, i))...)
+		}
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			issues = append(issues, unknownKeys(node.Content[i+1], t.Elem(), joinKey(prefix, node.Content[i].Value))...)
+		}
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			path := joinKey(prefix, key)
+			field, ok := fields[key]
+			if !ok {
+				msg := "unknown key; it is ignored"
+				if suggestion := closestKey(key, fields); suggestion != "" {
+					msg = fmt.Sprintf("unknown key; did you mean %q?", suggestion)
+				}
+				issues = append(issues, Issue{Path: path, Message: msg, Severity: SeverityWarning})
+				continue
+			}
+			issues = append(issues, unknownKeys(node.Content[i+1], field.Type, path)...)
+		}
+	}
+	return issues
+}
+
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// closestKey suggests a known key for a misspelled one: a case-insensitive
+// match, or one within two single-character edits.
+func closestKey(key string, fields map[string]reflect.StructField) string {
+	best, bestDist := "", 3
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
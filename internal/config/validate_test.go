@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse_ShippedConfigsHaveNoUnknownKeys(t *testing.T) {
+	paths, err := filepath.Glob("../../deploy/config/*.yaml")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no shipped configs found: %v", err)
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("read %s: %v", p, err)
+		}
+		_, unknown, err := Parse(data)
+		if err != nil {
+			t.Fatalf("parse %s: %v", p, err)
+		}
+		if len(unknown) > 0 {
+			t.Fatalf("%s has unknown keys: %v", p, unknown)
+		}
+	}
+}
+
+func TestParse_WarnsOnUnknownKeys(t *testing.T) {
+	data := []byte(`
+server:
+  prot: 8080
+auth:
+  oidc:
+    issuerUrl: "https://issuer.example.com"
+server_extra: true
+server2:
+  routes: []
+`)
+	_, unknown, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got := map[string]string{}
+	for _, is := range unknown {
+		if is.Severity != SeverityWarning {
+			t.Fatalf("expected warning, got %+v", is)
+		}
+		got[is.Path] = is.Message
+	}
+	if !strings.Contains(got["server.prot"], `"port"`) {
+		t.Fatalf("expected port suggestion for server.prot, got %q", got["server.prot"])
+	}
+	if !strings.Contains(got["auth.oidc.issuerUrl"], `"issuerURL"`) {
+		t.Fatalf("expected issuerURL suggestion, got %q", got["auth.oidc.issuerUrl"])
+	}
+	if _, ok := got["server_extra"]; !ok {
+		t.Fatalf("expected server_extra to be reported, got %v", unknown)
+	}
+}
+
+func TestValidate_ReportsEveryError(t *testing.T) {
+	cfg := &Config{
+		Database: DatabaseConfig{DSN: "postgres://localhost/raito"},
+		Auth: AuthConfig{
+			Enabled: true,
+			OIDC:    OIDCAuthConfig{Enabled: true, IssuerURL: "https://issuer.example.com"},
+		},
+		LLM: LLMConfig{DefaultProvider: "openai", OpenAI: OpenAIConfig{APIKey: "sk-test"}},
+	}
+	err := cfg.Validate()
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	var paths []string
+	for _, is := range verr.Issues {
+		paths = append(paths, is.Path)
+	}
+	want := []string{"auth.oidc.clientID", "auth.oidc.clientSecret", "auth.oidc.redirectURL", "llm.openai.model"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected issues:\n got %v\nwant %v", paths, want)
+	}
+	if !strings.Contains(err.Error(), "4 problems:") {
+		t.Fatalf("expected summary in error, got %q", err.Error())
+	}
+
+	cfg.Auth.OIDC.Enabled = false
+	cfg.LLM.OpenAI.Model = "gpt-4o-mini"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	cfg.Auth.ShareLinks.BaseURL = "raito.example.com"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "auth.shareLinks.baseURL") {
+		t.Fatalf("expected baseURL without a scheme to be rejected, got %v", err)
+	}
+	cfg.Auth.ShareLinks.BaseURL = "https://raito.example.com"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid baseURL, got %v", err)
+	}
+}
+
+func TestCheck_Plugins(t *testing.T) {
+	cfg := &Config{Plugins: PluginsConfig{Formats: []FormatPluginConfig{
+		{Name: "readability", Type: "exec", Command: []string{"/usr/local/bin/readability"}},
+		{Name: "Readability", Type: "exec", Command: []string{"x"}},
+		{Name: "markdown", Type: "exec", Command: []string{"x"}},
+		{Name: "lang", Type: "wasm", Module: "/plugins/lang.wasm"},
+		{Name: "odd", Type: "python"},
+	}}}
+
+	var paths []string
+	for _, is := range cfg.Check() {
+		if strings.HasPrefix(is.Path, "plugins") {
+			paths = append(paths, is.Path)
+		}
+	}
+	want := []string{"plugins.formats[1].name", "plugins.formats[2].name", "plugins.formats[4].type", "plugins.wasmRuntime"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected plugin issues:\n got %v\nwant %v", paths, want)
+	}
+}
+
+func TestCheck_DNS(t *testing.T) {
+	cfg := &Config{DNS: DNSConfig{
+		MinTTLSeconds: 120,
+		MaxTTLSeconds: 60,
+		Nameservers:   []string{"1.1.1.1", "[2606:4700:4700::1111]:53", "dns.example.com"},
+		DoHURL:        "http://dns.example.com/dns-query",
+	}}
+
+	var paths []string
+	for _, is := range cfg.Check() {
+		if strings.HasPrefix(is.Path, "dns") {
+			paths = append(paths, is.Path)
+		}
+	}
+	want := []string{"dns.minTtlSeconds", "dns.dohUrl", "dns.nameservers", "dns.nameservers[2]"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected dns issues:\n got %v\nwant %v", paths, want)
+	}
+}
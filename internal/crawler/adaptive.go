@@ -0,0 +1,141 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for HostLimiterOptions.
+const (
+	DefaultInitialPerHost = 1
+	DefaultMaxPerHost     = 8
+	DefaultTargetLatency  = 2 * time.Second
+)
+
+// HostLimiterOptions tunes the adaptive per-host concurrency controller.
+type HostLimiterOptions struct {
+	// InitialPerHost is the concurrency a host starts with.
+	InitialPerHost int
+	// MaxPerHost caps how far a healthy host can be ramped up.
+	MaxPerHost int
+	// TargetLatency is the response time below which a host is considered
+	// healthy enough to ramp up.
+	TargetLatency time.Duration
+}
+
+// HostLimiter bounds concurrent requests per host and adapts each host's
+// limit using additive increase / multiplicative decrease: every fast,
+// successful response raises the limit by roughly one slot per window,
+// slow responses hold it, and 429/5xx responses or transport errors halve it.
+// Robust sites are therefore fetched faster over time while small sites that
+// start to struggle are quickly backed off.
+type HostLimiter struct {
+	opts HostLimiterOptions
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	limit    float64
+	inFlight int
+	// changed is closed (and replaced) whenever a slot frees up or the
+	// limit changes so waiters can re-check.
+	changed chan struct{}
+}
+
+// NewHostLimiter constructs a HostLimiter, filling in defaults for unset
+// options.
+func NewHostLimiter(opts HostLimiterOptions) *HostLimiter {
+	if opts.MaxPerHost <= 0 {
+		opts.MaxPerHost = DefaultMaxPerHost
+	}
+	if opts.InitialPerHost <= 0 {
+		opts.InitialPerHost = DefaultInitialPerHost
+	}
+	if opts.InitialPerHost > opts.MaxPerHost {
+		opts.InitialPerHost = opts.MaxPerHost
+	}
+	if opts.TargetLatency <= 0 {
+		opts.TargetLatency = DefaultTargetLatency
+	}
+	return &HostLimiter{opts: opts, hosts: make(map[string]*hostState)}
+}
+
+// HostKey returns the limiter key for a URL (its lowercased host).
+func HostKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+func (l *HostLimiter) state(host string) *hostState {
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostState{limit: float64(l.opts.InitialPerHost), changed: make(chan struct{})}
+		l.hosts[host] = h
+	}
+	return h
+}
+
+// Limit reports the current concurrency limit for a host.
+func (l *HostLimiter) Limit(host string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.state(host).limit)
+}
+
+// Acquire waits for a free slot on host. The returned release function
+// must be called exactly once with the outcome of the request (HTTP status,
+// latency, and transport error) so the limit can adapt.
+func (l *HostLimiter) Acquire(ctx context.Context, host string) (func(status int, latency time.Duration, err error), error) {
+	for {
+		l.mu.Lock()
+		h := l.state(host)
+		if h.inFlight < int(h.limit) {
+			h.inFlight++
+			l.mu.Unlock()
+			var once sync.Once
+			return func(status int, latency time.Duration, err error) {
+				once.Do(func() { l.release(h, status, latency, err) })
+			}, nil
+		}
+		ch := h.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+func (l *HostLimiter) release(h *hostState, status int, latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h.inFlight--
+
+	switch {
+	case err != nil || status == http.StatusTooManyRequests || status >= 500:
+		h.limit /= 2
+		if h.limit < 1 {
+			h.limit = 1
+		}
+	case latency <= l.opts.TargetLatency:
+		h.limit += 1 / h.limit
+		if max := float64(l.opts.MaxPerHost); h.limit > max {
+			h.limit = max
+		}
+	}
+
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
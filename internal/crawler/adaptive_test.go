@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter_RampsUpOnHealthyResponses(t *testing.T) {
+	l := NewHostLimiter(HostLimiterOptions{InitialPerHost: 1, MaxPerHost: 4, TargetLatency: time.Second})
+
+	for i := 0; i < 20; i++ {
+		release, err := l.Acquire(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+		release(200, 10*time.Millisecond, nil)
+	}
+	if got := l.Limit("example.com"); got != 4 {
+		t.Fatalf("expected limit to ramp to max 4, got %d", got)
+	}
+	if got := l.Limit("other.example"); got != 1 {
+		t.Fatalf("expected untouched host to stay at initial limit, got %d", got)
+	}
+}
+
+func TestHostLimiter_BacksOffOnThrottlingAndErrors(t *testing.T) {
+	l := NewHostLimiter(HostLimiterOptions{InitialPerHost: 8, MaxPerHost: 8, TargetLatency: time.Second})
+
+	release, _ := l.Acquire(context.Background(), "example.com")
+	release(429, 0, nil)
+	if got := l.Limit("example.com"); got != 4 {
+		t.Fatalf("expected 429 to halve the limit to 4, got %d", got)
+	}
+
+	release, _ = l.Acquire(context.Background(), "example.com")
+	release(0, 0, errors.New("connection reset"))
+	release, _ = l.Acquire(context.Background(), "example.com")
+	release(503, 0, nil)
+	release, _ = l.Acquire(context.Background(), "example.com")
+	release(502, 0, nil)
+	if got := l.Limit("example.com"); got != 1 {
+		t.Fatalf("expected limit to floor at 1, got %d", got)
+	}
+
+	// Slow but successful responses hold the limit.
+	release, _ = l.Acquire(context.Background(), "example.com")
+	release(200, 5*time.Second, nil)
+	if got := l.Limit("example.com"); got != 1 {
+		t.Fatalf("expected slow response to hold the limit, got %d", got)
+	}
+}
+
+func TestHostLimiter_AcquireBlocksUntilRelease(t *testing.T) {
+	l := NewHostLimiter(HostLimiterOptions{InitialPerHost: 1, MaxPerHost: 1})
+
+	release, err := l.Acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, "example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected second Acquire to wait for a slot, got %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		r, err := l.Acquire(context.Background(), "example.com")
+		if err == nil {
+			r(200, 0, nil)
+		}
+		close(acquired)
+	}()
+	release(200, 0, nil)
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("expected waiter to acquire after release")
+	}
+}
@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"raito/internal/fixtures"
+)
+
+func TestMap_FixtureGolden(t *testing.T) {
+	site := fixtures.NewSite(t)
+
+	for _, c := range []struct {
+		name string
+		opts MapOptions
+	}{
+		{"include", MapOptions{SitemapMode: "include"}},
+		{"sitemap-only", MapOptions{SitemapMode: "only"}},
+		{"robots", MapOptions{SitemapMode: "include", RespectRobots: true, RespectRobotsMeta: true}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			opts := c.opts
+			opts.URL = site.URL
+			opts.Limit = 100
+			opts.Timeout = 5 * time.Second
+			var blocked []string
+			opts.OnRobotsBlocked = func(u string) { blocked = append(blocked, u) }
+
+			res, err := Map(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("Map: %v", err)
+			}
+			type goldenLink struct {
+				URL     string `json:"url"`
+				Title   string `json:"title,omitempty"`
+				Sitemap bool   `json:"sitemap,omitempty"`
+				Depth   int    `json:"depth"`
+			}
+			links := make([]goldenLink, 0, len(res.Links))
+			for _, l := range res.Links {
+				links = append(links, goldenLink{URL: l.URL, Title: l.Title, Sitemap: l.Sitemap, Depth: l.Depth()})
+			}
+			sort.Slice(links, func(i, j int) bool { return links[i].URL < links[j].URL })
+			sort.Strings(blocked)
+			site.GoldenJSON(t, "map/"+c.name+".json", map[string]any{
+				"links":           links,
+				"robotsBlocked":   blocked,
+				"noFollowSkipped": res.NoFollowSkipped,
+				"warning":         res.Warning,
+			})
+		})
+	}
+}
+
+func TestRobotsCrawlDelay_Fixture(t *testing.T) {
+	site := fixtures.NewSite(t)
+	u, _ := url.Parse(site.URL + "/about")
+	if got := RobotsCrawlDelay(context.Background(), http.DefaultClient, u, "raito-test", ""); got != time.Second {
+		t.Fatalf("expected the fixture's 1s Crawl-delay, got %v", got)
+	}
+}
@@ -0,0 +1,380 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	robotstxt "github.com/temoto/robotstxt"
+
+	"raito/internal/dnscache"
+)
+
+// FrontierOptions controls which URLs a Frontier admits. The host, query
+// and robots rules match MapOptions.
+type FrontierOptions struct {
+	// Limit caps how many URLs are ever admitted, seeds included.
+	Limit int
+	// MaxDepth caps the discovery depth of the URLs Add admits: seeds are
+	// at depth 0 and a link is one deeper than the page it was found on.
+	// A negative MaxDepth means no cap.
+	MaxDepth          int
+	IncludeSubdomains bool
+	IgnoreQueryParams bool
+	AllowExternal     bool
+	RespectRobots     bool
+	UserAgent         string
+	RobotsAgent       string
+	Timeout           time.Duration
+	// ExtraRoots are further seed URLs of a multi-seed crawl. URLs on
+	// their hosts are admitted like those on the root's host.
+	ExtraRoots []string
+	// OnRobotsBlocked, when set, is called once with each URL refused
+	// because robots.txt disallows it.
+	OnRobotsBlocked func(rawURL string)
+}
+
+// Frontier is the queue of URLs a crawl still has to scrape. Each URL is
+// admitted once, and pages found while scraping can be added while the
+// crawl runs, which is how SPA crawls follow client-side routes.
+type Frontier struct {
+	base *url.URL
+	opts FrontierOptions
+	// roots holds the crawl root first, then ExtraRoots.
+	roots []frontierRoot
+
+	mu       sync.Mutex
+	seen     map[string]struct{}
+	queue    []string
+	admitted int
+	// depth holds the discovery depth of every URL queued so far.
+	depth map[string]int
+	// pending counts URLs handed out by Next and not yet marked Done.
+	pending int
+	// wake is closed and replaced whenever the queue or pending changes.
+	wake chan struct{}
+}
+
+// frontierRoot is a seed site of the crawl with its robots.txt rules.
+type frontierRoot struct {
+	url    *url.URL
+	robots *robotstxt.RobotsData
+}
+
+// NewFrontier returns an empty frontier for the site rooted at root and
+// any ExtraRoots. Relative URLs resolve against root.
+func NewFrontier(ctx context.Context, root string, opts FrontierOptions) (*Frontier, error) {
+	if root == "" {
+		return nil, errors.New("url is required")
+	}
+
+	f := &Frontier{
+		opts:  opts,
+		seen:  map[string]struct{}{},
+		depth: map[string]int{},
+		wake:  make(chan struct{}),
+	}
+	client := &http.Client{Timeout: opts.Timeout, Transport: dnscache.Transport()}
+	for _, raw := range append([]string{root}, opts.ExtraRoots...) {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "" {
+			u.Scheme = "http"
+		}
+		r := frontierRoot{url: u}
+		if opts.RespectRobots {
+			r.robots, _ = fetchRobots(ctx, client, u, opts.UserAgent)
+		}
+		f.roots = append(f.roots, r)
+	}
+	f.base = f.roots[0].url
+	return f, nil
+}
+
+// rootFor returns the root whose host admits host, if any.
+func (f *Frontier) rootFor(host string) (frontierRoot, bool) {
+	for _, r := range f.roots {
+		if sameHostOrSubdomain(r.url.Hostname(), host, f.opts.IncludeSubdomains) {
+			return r, true
+		}
+	}
+	return frontierRoot{}, false
+}
+
+// NormalizeRoute resolves raw against base and returns the URL to crawl.
+// Fragments are dropped except hash routes ("#/path" or "#!/path"), which
+// client-side routers use as distinct pages.
+func NormalizeRoute(base *url.URL, raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.HasPrefix(strings.ToLower(raw), "javascript:") {
+		return "", false
+	}
+	u, err := base.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	if !strings.HasPrefix(u.Fragment, "/") && !strings.HasPrefix(u.Fragment, "!/") {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+	return u.String(), true
+}
+
+// Seed queues urls at depth 0 without applying the admission rules other
+// than de-duplication. It is meant for the crawl roots and for URLs Map
+// has already filtered.
+func (f *Frontier) Seed(urls ...string) {
+	f.SeedAt(0, urls...)
+}
+
+// SeedAt is Seed for URLs found at the given depth, such as the links Map
+// found on a root page.
+func (f *Frontier) SeedAt(depth int, urls ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range urls {
+		if _, exists := f.seen[u]; exists {
+			continue
+		}
+		f.seen[u] = struct{}{}
+		f.depth[u] = depth
+		f.admitted++
+		f.queue = append(f.queue, u)
+	}
+	f.notify()
+}
+
+// Add queues raw, resolved against the crawl root, at the given depth and
+// reports whether it was admitted. URLs already seen, deeper than
+// MaxDepth, outside the crawl's hosts, disallowed by the robots.txt of
+// their root or past the limit are ignored. Queued URLs are handed out in
+// order, so links found while crawling are scraped breadth first.
+func (f *Frontier) Add(raw string, depth int) bool {
+	if f.opts.MaxDepth >= 0 && depth > f.opts.MaxDepth {
+		return false
+	}
+	normalized, ok := NormalizeRoute(f.base, raw)
+	if !ok {
+		return false
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return false
+	}
+	root, ok := f.rootFor(u.Hostname())
+	if !ok {
+		if !f.opts.AllowExternal {
+			return false
+		}
+		// External URLs are checked against the crawl root's robots.txt.
+		root = f.roots[0]
+	}
+	if f.opts.IgnoreQueryParams {
+		u.RawQuery = ""
+	}
+	key := u.String()
+	// robots.txt rules match the path and query, not the whole URL.
+	blocked := root.robots != nil && !root.robots.FindGroup(robotsAgent(f.opts.RobotsAgent, f.opts.UserAgent)).Test(u.RequestURI())
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.seen[key]; exists {
+		return false
+	}
+	if blocked {
+		// Remember the URL so a link repeated across pages is reported
+		// once.
+		f.seen[key] = struct{}{}
+		if f.opts.OnRobotsBlocked != nil {
+			f.opts.OnRobotsBlocked(key)
+		}
+		return false
+	}
+	if f.opts.Limit > 0 && f.admitted >= f.opts.Limit {
+		return false
+	}
+	f.seen[key] = struct{}{}
+	f.depth[key] = depth
+	f.admitted++
+	f.queue = append(f.queue, key)
+	f.notify()
+	return true
+}
+
+// Depth returns the discovery depth of a URL returned by Next.
+func (f *Frontier) Depth(u string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.depth[u]
+}
+
+// Skip keeps raw from being scraped: it is dropped from the queue if it
+// is still waiting there, and never queued later otherwise. It reports
+// whether a queued URL was dropped; the dropped URL no longer counts
+// towards the limit.
+func (f *Frontier) Skip(raw string) bool {
+	normalized, ok := NormalizeRoute(f.base, raw)
+	if !ok {
+		return false
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return false
+	}
+	if f.opts.IgnoreQueryParams {
+		u.RawQuery = ""
+	}
+	key := u.String()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.seen[key]; !exists {
+		f.seen[key] = struct{}{}
+		return false
+	}
+	for i, q := range f.queue {
+		if q == key {
+			f.queue = append(f.queue[:i], f.queue[i+1:]...)
+			f.admitted--
+			return true
+		}
+	}
+	return false
+}
+
+// InScope reports whether raw, resolved against the crawl root, is on
+// one of the crawl's hosts or may be crawled as an external URL. Unlike
+// Add it ignores robots.txt, the limit and the URLs already seen.
+func (f *Frontier) InScope(raw string) bool {
+	normalized, ok := NormalizeRoute(f.base, raw)
+	if !ok {
+		return false
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return false
+	}
+	if _, ok := f.rootFor(u.Hostname()); ok {
+		return true
+	}
+	return f.opts.AllowExternal
+}
+
+// Next returns the next queued URL. When the queue is empty it waits for
+// a URL to be added, and returns false once every URL handed out has been
+// marked Done without adding more, or when ctx ends. Once ctx has ended
+// it hands out no more URLs, so a paused crawl leaves them queued.
+func (f *Frontier) Next(ctx context.Context) (string, bool) {
+	for {
+		if ctx.Err() != nil {
+			return "", false
+		}
+		f.mu.Lock()
+		if len(f.queue) > 0 {
+			u := f.queue[0]
+			f.queue = f.queue[1:]
+			f.pending++
+			f.mu.Unlock()
+			return u, true
+		}
+		if f.pending == 0 {
+			f.mu.Unlock()
+			return "", false
+		}
+		wake := f.wake
+		f.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", false
+		case <-wake:
+		}
+	}
+}
+
+// Done marks a URL returned by Next as finished. Routes found on that
+// page must be added before calling Done.
+func (f *Frontier) Done() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending--
+	f.notify()
+}
+
+// Len returns how many URLs have been admitted so far.
+func (f *Frontier) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.admitted
+}
+
+// FrontierState is what a frontier needs to continue a paused crawl.
+type FrontierState struct {
+	// Queued holds the URLs still to scrape, in order.
+	Queued []string
+	// Seen holds every URL queued, handed out or refused so far, Queued
+	// included.
+	Seen []string
+	// Admitted counts the URLs charged against the limit.
+	Admitted int
+	// Depths holds the discovery depth of each queued URL.
+	Depths map[string]int
+}
+
+// State returns the frontier's queue and the URLs it has seen. URLs handed
+// out by Next and not yet marked Done are neither queued nor retried, so
+// callers take the state once the crawl has stopped handing out URLs and
+// its pages are done.
+func (f *Frontier) State() FrontierState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	seen := make([]string, 0, len(f.seen))
+	for u := range f.seen {
+		seen = append(seen, u)
+	}
+	sort.Strings(seen)
+	depths := make(map[string]int, len(f.queue))
+	for _, u := range f.queue {
+		depths[u] = f.depth[u]
+	}
+	return FrontierState{
+		Queued:   append([]string(nil), f.queue...),
+		Seen:     seen,
+		Admitted: f.admitted,
+		Depths:   depths,
+	}
+}
+
+// Restore replaces the frontier's queue and seen URLs with state, taken
+// from the same crawl with State.
+func (f *Frontier) Restore(state FrontierState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seen = make(map[string]struct{}, len(state.Seen)+len(state.Queued))
+	for _, u := range state.Seen {
+		f.seen[u] = struct{}{}
+	}
+	for _, u := range state.Queued {
+		f.seen[u] = struct{}{}
+	}
+	f.depth = make(map[string]int, len(state.Queued))
+	for _, u := range state.Queued {
+		f.depth[u] = state.Depths[u]
+	}
+	f.queue = append([]string(nil), state.Queued...)
+	f.admitted = state.Admitted
+	f.notify()
+}
+
+// notify wakes goroutines blocked in Next. f.mu must be held.
+func (f *Frontier) notify() {
+	close(f.wake)
+	f.wake = make(chan struct{})
+}
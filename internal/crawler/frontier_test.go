@@ -0,0 +1,298 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeRoute(t *testing.T) {
+	base, _ := url.Parse("https://app.example.com/dashboard")
+
+	cases := map[string]string{
+		"/settings":               "https://app.example.com/settings",
+		"reports?id=1":            "https://app.example.com/reports?id=1",
+		"#/inbox":                 "https://app.example.com/dashboard#/inbox",
+		"#!/inbox":                "https://app.example.com/dashboard#!/inbox",
+		"/docs#install":           "https://app.example.com/docs",
+		"https://other.example/x": "https://other.example/x",
+		"javascript:void(0)":      "",
+		"mailto:team@example.com": "",
+		"   ":                     "",
+	}
+	for raw, want := range cases {
+		got, ok := NormalizeRoute(base, raw)
+		if want == "" {
+			if ok {
+				t.Fatalf("%q: expected to be rejected, got %q", raw, got)
+			}
+			continue
+		}
+		if !ok || got != want {
+			t.Fatalf("%q: got %q (%v), want %q", raw, got, ok, want)
+		}
+	}
+}
+
+func TestFrontier_AddAndLimit(t *testing.T) {
+	f, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{Limit: 4, IgnoreQueryParams: true})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	f.Seed("https://example.com/", "https://example.com/")
+
+	added := []bool{
+		f.Add("/app/settings?tab=1", 0),
+		f.Add("/app/settings?tab=2", 0),        // same URL once the query is ignored
+		f.Add("https://evil.example/phish", 0), // other host
+		f.Add("https://docs.example.com/", 0),  // subdomains are not included
+		f.Add("#/inbox", 0),
+		f.Add("/app/reports", 0),
+		f.Add("/app/billing", 0), // past the limit
+	}
+	if want := []bool{true, false, false, false, true, true, false}; !reflect.DeepEqual(added, want) {
+		t.Fatalf("unexpected admissions %v, want %v", added, want)
+	}
+	if f.Len() != 4 {
+		t.Fatalf("expected 4 admitted URLs, got %d", f.Len())
+	}
+}
+
+func TestFrontier_MaxDepth(t *testing.T) {
+	f, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	f.Seed("https://example.com/")
+	u, _ := f.Next(context.Background())
+
+	depth := f.Depth(u) + 1
+	if !f.Add("/docs", depth) {
+		t.Fatal("expected a link of the root to be admitted")
+	}
+	docs, _ := f.Next(context.Background())
+	if f.Depth(docs) != 1 {
+		t.Fatalf("expected depth 1, got %d", f.Depth(docs))
+	}
+	if f.Add("/docs/install", f.Depth(docs)+1) {
+		t.Fatal("expected a link past the depth cap to be refused")
+	}
+
+	unlimited, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{MaxDepth: -1})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	if !unlimited.Add("/docs/install", 10) {
+		t.Fatal("expected no depth cap")
+	}
+}
+
+func TestLinksWithin(t *testing.T) {
+	links := []Link{
+		{URL: "https://example.com/sitemap-only", Sitemap: true},
+		{URL: "https://example.com/linked"},
+	}
+	if got := LinksWithin(links, 0); len(got) != 1 || got[0].URL != "https://example.com/sitemap-only" {
+		t.Fatalf("expected only the sitemap URL at depth 0, got %v", got)
+	}
+	if got := LinksWithin(links, 1); len(got) != 2 {
+		t.Fatalf("expected both links at depth 1, got %v", got)
+	}
+	if got := LinksWithin(links, -1); len(got) != 2 {
+		t.Fatalf("expected no cap, got %v", got)
+	}
+}
+
+func TestFrontier_Skip(t *testing.T) {
+	f, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{Limit: 3})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	f.Seed("https://example.com/", "https://example.com/de/", "https://example.com/fr/")
+
+	if !f.Skip("/de/") {
+		t.Fatal("expected the queued URL to be dropped")
+	}
+	if f.Skip("/es/") {
+		t.Fatal("expected an unseen URL not to count as dropped")
+	}
+	if f.Add("/es/", 0) {
+		t.Fatal("expected a skipped URL never to be admitted")
+	}
+	if !f.Add("/about", 0) {
+		t.Fatal("expected the dropped URL to free its place under the limit")
+	}
+
+	var got []string
+	for len(got) < f.Len() {
+		u, _ := f.Next(context.Background())
+		got = append(got, u)
+		f.Done()
+	}
+	if want := []string{"https://example.com/", "https://example.com/fr/", "https://example.com/about"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFrontier_StateAndRestore(t *testing.T) {
+	f, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{Limit: 4, MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	f.Seed("https://example.com/", "https://example.com/a")
+	f.SeedAt(1, "https://example.com/b")
+	if u, _ := f.Next(context.Background()); u != "https://example.com/" {
+		t.Fatalf("unexpected first URL %q", u)
+	}
+	f.Done()
+
+	// A cancelled context hands out nothing, leaving the queue for later.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if u, ok := f.Next(ctx); ok {
+		t.Fatalf("expected no URL after cancel, got %q", u)
+	}
+
+	state := f.State()
+	want := FrontierState{
+		Queued:   []string{"https://example.com/a", "https://example.com/b"},
+		Seen:     []string{"https://example.com/", "https://example.com/a", "https://example.com/b"},
+		Admitted: 3,
+		Depths:   map[string]int{"https://example.com/a": 0, "https://example.com/b": 1},
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Fatalf("got %+v, want %+v", state, want)
+	}
+
+	resumed, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{Limit: 4, MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	resumed.Restore(state)
+	added := []bool{resumed.Add("/", 0), resumed.Add("/c", 0), resumed.Add("/d", 0)}
+	if want := []bool{false, true, false}; !reflect.DeepEqual(added, want) {
+		t.Fatalf("unexpected admissions %v, want %v", added, want)
+	}
+	var got []string
+	var depths []int
+	for {
+		u, ok := resumed.Next(context.Background())
+		if !ok {
+			break
+		}
+		got = append(got, u)
+		depths = append(depths, resumed.Depth(u))
+		resumed.Done()
+	}
+	if want := []int{0, 1, 0}; !reflect.DeepEqual(depths, want) {
+		t.Fatalf("got depths %v, want %v", depths, want)
+	}
+	if want := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFrontier_ExtraRootsAdmitTheirHosts(t *testing.T) {
+	f, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{ExtraRoots: []string{"https://docs.example.org/guide"}})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+
+	added := []bool{
+		f.Add("/pricing", 0),                           // resolves against the first root
+		f.Add("https://docs.example.org/guide/api", 0), // host of an extra root
+		f.Add("https://blog.example.org/", 0),          // subdomains are not included
+		f.Add("https://evil.example/phish", 0),
+	}
+	if want := []bool{true, true, false, false}; !reflect.DeepEqual(added, want) {
+		t.Fatalf("unexpected admissions %v, want %v", added, want)
+	}
+}
+
+func TestFrontier_InScope(t *testing.T) {
+	f, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{Limit: 1, IncludeSubdomains: true})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	f.Seed("https://example.com/")
+
+	// Scope ignores the limit and URLs already queued.
+	in := []bool{
+		f.InScope("https://example.com/"),
+		f.InScope("https://docs.example.com/api"),
+		f.InScope("https://example.org/"),
+		f.InScope("mailto:team@example.com"),
+	}
+	if want := []bool{true, true, false, false}; !reflect.DeepEqual(in, want) {
+		t.Fatalf("unexpected scope %v, want %v", in, want)
+	}
+}
+
+func TestFrontier_ReportsRobotsBlocksOnce(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	var blocked []string
+	f, err := NewFrontier(context.Background(), srv.URL+"/", FrontierOptions{
+		RespectRobots:   true,
+		UserAgent:       "raito-test",
+		OnRobotsBlocked: func(u string) { blocked = append(blocked, u) },
+	})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	if !f.Add("/public", 0) {
+		t.Fatalf("expected an allowed URL to be admitted")
+	}
+	for i := 0; i < 3; i++ {
+		if f.Add("/private/page", 0) {
+			t.Fatalf("expected a disallowed URL to be refused")
+		}
+	}
+	if want := []string{srv.URL + "/private/page"}; !reflect.DeepEqual(blocked, want) {
+		t.Fatalf("got blocks %v, want %v", blocked, want)
+	}
+}
+
+func TestFrontier_NextWaitsForPendingPages(t *testing.T) {
+	f, err := NewFrontier(context.Background(), "https://example.com/", FrontierOptions{})
+	if err != nil {
+		t.Fatalf("NewFrontier: %v", err)
+	}
+	f.Seed("https://example.com/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	root, ok := f.Next(ctx)
+	if !ok || root != "https://example.com/" {
+		t.Fatalf("expected the seed, got %q %v", root, ok)
+	}
+
+	// The root is still being scraped, so Next must wait for the route it
+	// reveals instead of reporting the frontier as exhausted.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		f.Add("/#/about", 0)
+		f.Done()
+	}()
+	route, ok := f.Next(ctx)
+	if !ok || route != "https://example.com/#/about" {
+		t.Fatalf("expected the discovered route, got %q %v", route, ok)
+	}
+	f.Done()
+
+	if u, ok := f.Next(ctx); ok {
+		t.Fatalf("expected the frontier to be exhausted, got %q", u)
+	}
+}
@@ -0,0 +1,89 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Alternate is a language version of a page announced with
+// <link rel="alternate" hreflang="..." href="...">.
+type Alternate struct {
+	// Lang is the lower-cased hreflang value, e.g. "en", "de-at" or
+	// "x-default".
+	Lang string
+	URL  string
+}
+
+// ParseHreflang returns the language alternates a page lists, with their
+// URLs resolved against base. A page usually lists itself too.
+func ParseHreflang(html string, base *url.URL) []Alternate {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil
+	}
+	var out []Alternate
+	seen := map[Alternate]bool{}
+	doc.Find("link[hreflang][href]").Each(func(_ int, sel *goquery.Selection) {
+		if !hasRel(sel.AttrOr("rel", ""), "alternate") {
+			return
+		}
+		lang := strings.ToLower(strings.TrimSpace(sel.AttrOr("hreflang", "")))
+		href, ok := NormalizeRoute(base, sel.AttrOr("href", ""))
+		if lang == "" || !ok {
+			return
+		}
+		a := Alternate{Lang: lang, URL: href}
+		if !seen[a] {
+			seen[a] = true
+			out = append(out, a)
+		}
+	})
+	return out
+}
+
+// LanguageGroup returns an ID shared by a page and its language
+// alternates, derived from the smallest of their URLs so every version
+// that lists the same alternates gets the same ID. It is empty for pages
+// without alternates.
+func LanguageGroup(pageURL string, alts []Alternate) string {
+	if len(alts) == 0 {
+		return ""
+	}
+	first := pageURL
+	for _, a := range alts {
+		if a.URL < first {
+			first = a.URL
+		}
+	}
+	sum := sha256.Sum256([]byte(first))
+	return hex.EncodeToString(sum[:8])
+}
+
+// PageLanguage returns the hreflang the alternates give pageURL, or
+// fallback, such as the <html lang> attribute, when they do not list it.
+func PageLanguage(pageURL string, alts []Alternate, fallback string) string {
+	for _, a := range alts {
+		if a.URL == pageURL && a.Lang != "x-default" {
+			return a.Lang
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(fallback))
+}
+
+// LanguageMatches reports whether lang is one of langs or a regional
+// variant of one: "en-gb" matches "en", but "en" does not match "en-gb".
+// Matching ignores case and treats "_" like "-".
+func LanguageMatches(lang string, langs []string) bool {
+	lang = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+	for _, l := range langs {
+		l = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(l)), "_", "-")
+		if l != "" && (lang == l || strings.HasPrefix(lang, l+"-")) {
+			return true
+		}
+	}
+	return false
+}
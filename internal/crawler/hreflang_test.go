@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseHreflang(t *testing.T) {
+	base, _ := url.Parse("https://example.com/en/pricing")
+	html := `<head>
+<link rel="alternate" hreflang="en" href="/en/pricing">
+<link rel="alternate" hreflang="DE-at" href="https://example.com/de-at/preise#top">
+<link rel="alternate" hreflang="x-default" href="/pricing">
+<link rel="alternate" hreflang="en" href="/en/pricing">
+<link rel="canonical" hreflang="fr" href="/fr/tarifs">
+<link rel="alternate" hreflang="" href="/xx/">
+</head>`
+	want := []Alternate{
+		{Lang: "en", URL: "https://example.com/en/pricing"},
+		{Lang: "de-at", URL: "https://example.com/de-at/preise"},
+		{Lang: "x-default", URL: "https://example.com/pricing"},
+	}
+	if got := ParseHreflang(html, base); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLanguageGroup(t *testing.T) {
+	alts := []Alternate{
+		{Lang: "en", URL: "https://example.com/en/"},
+		{Lang: "de", URL: "https://example.com/de/"},
+	}
+	en := LanguageGroup("https://example.com/en/", alts)
+	de := LanguageGroup("https://example.com/de/", alts)
+	if en == "" || en != de {
+		t.Fatalf("expected alternates to share a group, got %q and %q", en, de)
+	}
+	if other := LanguageGroup("https://example.com/en/about", []Alternate{{Lang: "en", URL: "https://example.com/en/about"}}); other == en {
+		t.Fatalf("expected another page to get another group, got %q", other)
+	}
+	if g := LanguageGroup("https://example.com/", nil); g != "" {
+		t.Fatalf("expected no group without alternates, got %q", g)
+	}
+}
+
+func TestPageLanguage(t *testing.T) {
+	alts := []Alternate{
+		{Lang: "x-default", URL: "https://example.com/"},
+		{Lang: "en-gb", URL: "https://example.com/"},
+	}
+	if got := PageLanguage("https://example.com/", alts, "en"); got != "en-gb" {
+		t.Fatalf("expected the hreflang language, got %q", got)
+	}
+	if got := PageLanguage("https://example.com/about", alts, " FR "); got != "fr" {
+		t.Fatalf("expected the fallback language, got %q", got)
+	}
+}
+
+func TestLanguageMatches(t *testing.T) {
+	cases := []struct {
+		lang string
+		want bool
+	}{
+		{"en", true},
+		{"en-GB", true},
+		{"en_us", true},
+		{"eng", false},
+		{"de", false},
+		{"pt-br", true},
+		{"pt", false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		if got := LanguageMatches(tc.lang, []string{"en", "pt-BR"}); got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.lang, got, tc.want)
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	robotstxt "github.com/temoto/robotstxt"
+
+	"raito/internal/dnscache"
 )
 
 // MapOptions controls how the map operation discovers URLs for a site.
@@ -26,7 +28,21 @@ type MapOptions struct {
 	SitemapMode       string // "only", "include", "skip"
 	Timeout           time.Duration
 	RespectRobots     bool
-	UserAgent         string
+	// UserAgent is sent with every discovery request.
+	UserAgent string
+	// RobotsAgent is matched against robots.txt User-agent lines;
+	// UserAgent when empty.
+	RobotsAgent string
+	// OnSitemap, when set, is called with the number of URLs kept from
+	// the sitemap once it has been read, before HTML discovery.
+	OnSitemap func(count int)
+	// OnRobotsBlocked, when set, is called once with each URL left out
+	// because robots.txt disallows it.
+	OnRobotsBlocked func(rawURL string)
+	// RespectRobotsMeta skips the links of pages that set nofollow in
+	// meta robots tags or X-Robots-Tag headers, and links marked
+	// rel="nofollow".
+	RespectRobotsMeta bool
 }
 
 // Link represents a discovered URL with optional metadata.
@@ -34,12 +50,42 @@ type Link struct {
 	URL         string
 	Title       string
 	Description string
+	// Sitemap reports whether the URL was listed in sitemap.xml.
+	Sitemap bool
+}
+
+// Depth returns the discovery depth of a link Map found: URLs listed in
+// a sitemap are at depth 0 like the root, and links on the root page at
+// depth 1.
+func (l Link) Depth() int {
+	if l.Sitemap {
+		return 0
+	}
+	return 1
+}
+
+// LinksWithin returns the links at most maxDepth deep, or all of them
+// when maxDepth is negative.
+func LinksWithin(links []Link, maxDepth int) []Link {
+	if maxDepth < 0 {
+		return links
+	}
+	out := make([]Link, 0, len(links))
+	for _, l := range links {
+		if l.Depth() <= maxDepth {
+			out = append(out, l)
+		}
+	}
+	return out
 }
 
 // MapResult is the result of a map operation.
 type MapResult struct {
 	Links   []Link
 	Warning string
+	// NoFollowSkipped counts links not followed because of nofollow when
+	// RespectRobotsMeta is set.
+	NoFollowSkipped int
 }
 
 // Map discovers URLs for the given site based on the provided options.
@@ -56,7 +102,7 @@ func Map(ctx context.Context, opts MapOptions) (*MapResult, error) {
 		baseURL.Scheme = "http"
 	}
 
-	client := &http.Client{Timeout: opts.Timeout}
+	client := &http.Client{Timeout: opts.Timeout, Transport: dnscache.Transport()}
 
 	var robotsData *robotstxt.RobotsData
 	if opts.RespectRobots {
@@ -64,9 +110,10 @@ func Map(ctx context.Context, opts MapOptions) (*MapResult, error) {
 	}
 
 	linksSet := make(map[string]Link)
+	blocked := make(map[string]struct{})
 
 	// Helper to add a URL if it passes filters.
-	addLink := func(uStr, title, desc string) {
+	addLinkFrom := func(uStr, title, desc string, fromSitemap bool) {
 		if len(linksSet) >= opts.Limit {
 			return
 		}
@@ -87,8 +134,12 @@ func Map(ctx context.Context, opts MapOptions) (*MapResult, error) {
 
 		// Respect robots.txt if available.
 		if robotsData != nil {
-			grp := robotsData.FindGroup(opts.UserAgent)
-			if !grp.Test(u.String()) {
+			grp := robotsData.FindGroup(robotsAgent(opts.RobotsAgent, opts.UserAgent))
+			if !grp.Test(u.RequestURI()) {
+				if _, seen := blocked[u.String()]; !seen && opts.OnRobotsBlocked != nil {
+					blocked[u.String()] = struct{}{}
+					opts.OnRobotsBlocked(u.String())
+				}
 				return
 			}
 		}
@@ -112,20 +163,28 @@ func Map(ctx context.Context, opts MapOptions) (*MapResult, error) {
 			URL:         finalURL,
 			Title:       strings.TrimSpace(title),
 			Description: strings.TrimSpace(desc),
+			Sitemap:     fromSitemap,
 		}
 	}
+	addSitemapLink := func(uStr, title, desc string) { addLinkFrom(uStr, title, desc, true) }
+	addLink := func(uStr, title, desc string) { addLinkFrom(uStr, title, desc, false) }
 
 	// Sitemap discovery
 	if opts.SitemapMode == "only" || opts.SitemapMode == "include" || opts.SitemapMode == "" {
-		if err := collectFromSitemap(ctx, client, baseURL, addLink); err != nil {
+		if err := collectFromSitemap(ctx, client, baseURL, opts.UserAgent, addSitemapLink); err != nil {
 			// Non-fatal; we still try HTML discovery
 		}
+		if opts.OnSitemap != nil && len(linksSet) > 0 {
+			opts.OnSitemap(len(linksSet))
+		}
 	}
 
 	// HTML discovery from root page
+	noFollowSkipped := 0
 	if opts.SitemapMode == "include" || opts.SitemapMode == "skip" || opts.SitemapMode == "" {
-		if err := collectFromHTML(ctx, client, baseURL, addLink); err != nil {
-			// Non-fatal
+		agent := robotsAgent(opts.RobotsAgent, opts.UserAgent)
+		if n, err := collectFromHTML(ctx, client, baseURL, opts.UserAgent, opts.RespectRobotsMeta, agent, addLink); err == nil {
+			noFollowSkipped = n
 		}
 	}
 
@@ -143,7 +202,7 @@ func Map(ctx context.Context, opts MapOptions) (*MapResult, error) {
 		}
 	}
 
-	return &MapResult{Links: links, Warning: warning}, nil
+	return &MapResult{Links: links, Warning: warning, NoFollowSkipped: noFollowSkipped}, nil
 }
 
 func sameHostOrSubdomain(baseHost, host string, includeSubdomains bool) bool {
@@ -196,8 +255,39 @@ func fetchRobots(ctx context.Context, client *http.Client, base *url.URL, userAg
 	return robotstxt.FromStatusAndBytes(resp.StatusCode, body)
 }
 
+// robotsAgent returns the token matched against robots.txt groups.
+func robotsAgent(agent, userAgent string) string {
+	if agent != "" {
+		return agent
+	}
+	return userAgent
+}
+
+// RobotsAllowed reports whether the robots.txt of target's host lets
+// agent (or userAgent, when agent is empty) fetch target. robots.txt is
+// requested with userAgent. Hosts without a readable robots.txt allow
+// everything, as in Map.
+func RobotsAllowed(ctx context.Context, client *http.Client, target *url.URL, userAgent, agent string) bool {
+	data, err := fetchRobots(ctx, client, target, userAgent)
+	if err != nil || data == nil {
+		return true
+	}
+	return data.TestAgent(target.RequestURI(), robotsAgent(agent, userAgent))
+}
+
+// RobotsCrawlDelay returns the Crawl-delay that target's robots.txt sets
+// for agent (or userAgent, when agent is empty), or 0 when it sets none or
+// cannot be read.
+func RobotsCrawlDelay(ctx context.Context, client *http.Client, target *url.URL, userAgent, agent string) time.Duration {
+	data, err := fetchRobots(ctx, client, target, userAgent)
+	if err != nil || data == nil {
+		return 0
+	}
+	return data.FindGroup(robotsAgent(agent, userAgent)).CrawlDelay
+}
+
 // collectFromSitemap tries the conventional /sitemap.xml location and collects URLs.
-func collectFromSitemap(ctx context.Context, client *http.Client, base *url.URL, add func(url, title, desc string)) error {
+func collectFromSitemap(ctx context.Context, client *http.Client, base *url.URL, userAgent string, add func(url, title, desc string)) error {
 	sitemapURL := &url.URL{
 		Scheme: base.Scheme,
 		Host:   base.Host,
@@ -208,6 +298,9 @@ func collectFromSitemap(ctx context.Context, client *http.Client, base *url.URL,
 	if err != nil {
 		return err
 	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -244,36 +337,56 @@ func collectFromSitemap(ctx context.Context, client *http.Client, base *url.URL,
 	return nil
 }
 
-// collectFromHTML fetches the base URL HTML and extracts links from anchor tags.
-func collectFromHTML(ctx context.Context, client *http.Client, base *url.URL, add func(url, title, desc string)) error {
+// collectFromHTML fetches the base URL HTML and extracts links from anchor
+// tags. With robotsMeta, links of pages that set nofollow for agent and
+// rel="nofollow" links are skipped; the number of skipped links is
+// returned.
+func collectFromHTML(ctx context.Context, client *http.Client, base *url.URL, userAgent string, robotsMeta bool, agent string, add func(url, title, desc string)) (int, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.String(), nil)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return errors.New("non-200 html")
+		return 0, errors.New("non-200 html")
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	if err != nil {
+		return 0, err
 	}
 
+	pageNoFollow := false
+	if robotsMeta {
+		pageNoFollow = ParseRobotsMeta(strings.Join(resp.Header.Values("X-Robots-Tag"), ", "), string(body), agent).NoFollow
+	}
+
+	skipped := 0
 	doc.Find("a[href]").Each(func(_ int, sel *goquery.Selection) {
 		href, ok := sel.Attr("href")
 		if !ok {
 			return
 		}
+		if robotsMeta && (pageNoFollow || hasRel(sel.AttrOr("rel", ""), "nofollow")) {
+			skipped++
+			return
+		}
 		title := strings.TrimSpace(sel.Text())
 		add(href, title, "")
 	})
 
-	return nil
+	return skipped, nil
 }
@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxPacedHosts is the number of hosts a HostPacer tracks before it drops
+// hosts that are no longer waiting.
+const maxPacedHosts = 1024
+
+// HostPacer spaces out the requests made to each host so that consecutive
+// requests start at least that host's crawl delay apart, however many
+// jobs or workers are fetching from it.
+type HostPacer struct {
+	mu sync.Mutex
+	// next is the earliest time the next request to a host may start.
+	next map[string]time.Time
+}
+
+// NewHostPacer returns a pacer with no requests recorded.
+func NewHostPacer() *HostPacer {
+	return &HostPacer{next: make(map[string]time.Time)}
+}
+
+// Wait reserves the next request slot on host and blocks until it starts.
+// The slot after it is delay later. A zero delay returns immediately
+// without reserving anything.
+func (p *HostPacer) Wait(ctx context.Context, host string, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	now := time.Now()
+	if len(p.next) >= maxPacedHosts {
+		// Hosts whose slot has passed need no entry.
+		for h, next := range p.next {
+			if !next.After(now) {
+				delete(p.next, h)
+			}
+		}
+	}
+	start := now
+	if next, ok := p.next[host]; ok && next.After(now) {
+		start = next
+	}
+	p.next[host] = start.Add(delay)
+	p.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
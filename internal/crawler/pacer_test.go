@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHostPacer_SpacesRequestsPerHost(t *testing.T) {
+	p := NewHostPacer()
+	ctx := context.Background()
+	delay := 40 * time.Millisecond
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := p.Wait(ctx, "example.com", delay); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*delay {
+		t.Fatalf("expected three requests to take at least %s, took %s", 2*delay, elapsed)
+	}
+
+	start = time.Now()
+	if err := p.Wait(ctx, "other.example", delay); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > delay/2 {
+		t.Fatalf("expected another host not to wait, took %s", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := p.Wait(cancelled, "example.com", time.Hour); err == nil {
+		t.Fatalf("expected a cancelled wait to fail")
+	}
+}
+
+func TestRobotsCrawlDelay_MatchesRobotsAgent(t *testing.T) {
+	var gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		_, _ = w.Write([]byte("User-agent: raitobot\nCrawl-delay: 5\nDisallow: /private\n\nUser-agent: *\nDisallow:\n"))
+	}))
+	defer srv.Close()
+
+	target, _ := url.Parse(srv.URL + "/private/page")
+	ua := "Mozilla/5.0 (compatible; RaitoBot/1.0) (+https://example.com/bot)"
+	ctx := context.Background()
+
+	if d := RobotsCrawlDelay(ctx, srv.Client(), target, ua, "RaitoBot"); d != 5*time.Second {
+		t.Fatalf("expected the RaitoBot group's delay, got %s", d)
+	}
+	if gotUA != ua {
+		t.Fatalf("expected robots.txt to be requested with the request user agent, got %q", gotUA)
+	}
+	if RobotsAllowed(ctx, srv.Client(), target, ua, "RaitoBot") {
+		t.Fatalf("expected the RaitoBot group to disallow /private")
+	}
+	// Without a robots agent the browser-like user agent falls in the * group.
+	if d := RobotsCrawlDelay(ctx, srv.Client(), target, ua, ""); d != 0 {
+		t.Fatalf("expected no delay for the * group, got %s", d)
+	}
+	if !RobotsAllowed(ctx, srv.Client(), target, ua, "") {
+		t.Fatalf("expected the * group to allow /private")
+	}
+}
@@ -0,0 +1,184 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultPriorityExpression ranks sitemap-listed and includePaths-matching
+// pages first, then shallower pages, and pushes pagination to the back.
+const DefaultPriorityExpression = "3*sitemap + 2*include - depth - 4*paginated"
+
+// priorityVariables are the URL features a priority expression can use.
+var priorityVariables = map[string]bool{
+	"depth":     true, // number of path segments
+	"sitemap":   true, // 1 when the URL was listed in sitemap.xml
+	"include":   true, // 1 when the path matches one of includePaths
+	"paginated": true, // 1 when the URL looks like a pagination page
+	"query":     true, // 1 when the URL has a query string
+}
+
+var paginationRe = regexp.MustCompile(`(?i)(/page/\d+/?$|/p/\d+/?$|[?&](page|p|pg|offset|start)=\d+)`)
+
+// PriorityExpr is a parsed priority expression: a weighted sum of URL
+// features such as "3*sitemap + 2*include - depth - 4*paginated". Higher
+// scores are crawled first.
+type PriorityExpr struct {
+	terms    []priorityTerm
+	constant float64
+}
+
+type priorityTerm struct {
+	weight   float64
+	variable string
+}
+
+// ParsePriorityExpression parses a weighted sum of the variables depth,
+// sitemap, include, paginated, and query. An empty expression yields
+// DefaultPriorityExpression.
+func ParsePriorityExpression(expr string) (*PriorityExpr, error) {
+	if strings.TrimSpace(expr) == "" {
+		expr = DefaultPriorityExpression
+	}
+
+	out := &PriorityExpr{}
+	rest := strings.ReplaceAll(expr, " ", "")
+	for rest != "" {
+		sign := 1.0
+		switch rest[0] {
+		case '+':
+			rest = rest[1:]
+		case '-':
+			sign = -1
+			rest = rest[1:]
+		default:
+			if len(out.terms) > 0 || out.constant != 0 {
+				return nil, fmt.Errorf("invalid priority expression %q: expected + or -", expr)
+			}
+		}
+
+		// Optional numeric weight.
+		i := 0
+		for i < len(rest) && (rest[i] == '.' || unicode.IsDigit(rune(rest[i]))) {
+			i++
+		}
+		weight := 1.0
+		hasNumber := i > 0
+		if hasNumber {
+			w, err := strconv.ParseFloat(rest[:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid priority expression %q: %v", expr, err)
+			}
+			weight = w
+			rest = rest[i:]
+			if strings.HasPrefix(rest, "*") {
+				rest = rest[1:]
+			} else if rest == "" || rest[0] == '+' || rest[0] == '-' {
+				out.constant += sign * weight
+				continue
+			}
+		}
+
+		j := 0
+		for j < len(rest) && unicode.IsLetter(rune(rest[j])) {
+			j++
+		}
+		name := strings.ToLower(rest[:j])
+		if !priorityVariables[name] {
+			return nil, fmt.Errorf("invalid priority expression %q: unknown variable %q", expr, rest[:j])
+		}
+		rest = rest[j:]
+		out.terms = append(out.terms, priorityTerm{weight: sign * weight, variable: name})
+	}
+
+	if len(out.terms) == 0 && out.constant == 0 {
+		return nil, fmt.Errorf("invalid priority expression %q", expr)
+	}
+	return out, nil
+}
+
+// PriorityContext carries the crawl-level inputs used to score URLs.
+type PriorityContext struct {
+	// IncludePaths are regular expressions matched against the URL path.
+	IncludePaths []string
+}
+
+// Prioritize orders links by descending priority score. Ties are broken by
+// shallower depth and then URL so the order is deterministic.
+func (e *PriorityExpr) Prioritize(links []Link, pc PriorityContext) []Link {
+	var include []*regexp.Regexp
+	for _, p := range pc.IncludePaths {
+		if re, err := regexp.Compile(p); err == nil {
+			include = append(include, re)
+		}
+	}
+
+	type scored struct {
+		link  Link
+		score float64
+		depth int
+	}
+	items := make([]scored, 0, len(links))
+	for _, l := range links {
+		vars := urlFeatures(l, include)
+		score := e.constant
+		for _, t := range e.terms {
+			score += t.weight * vars[t.variable]
+		}
+		items = append(items, scored{link: l, score: score, depth: int(vars["depth"])})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].score != items[j].score {
+			return items[i].score > items[j].score
+		}
+		if items[i].depth != items[j].depth {
+			return items[i].depth < items[j].depth
+		}
+		return items[i].link.URL < items[j].link.URL
+	})
+
+	out := make([]Link, len(items))
+	for i, it := range items {
+		out[i] = it.link
+	}
+	return out
+}
+
+func urlFeatures(l Link, include []*regexp.Regexp) map[string]float64 {
+	vars := map[string]float64{}
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return vars
+	}
+
+	depth := 0
+	for _, seg := range strings.Split(u.Path, "/") {
+		if seg != "" {
+			depth++
+		}
+	}
+	vars["depth"] = float64(depth)
+
+	if l.Sitemap {
+		vars["sitemap"] = 1
+	}
+	if u.RawQuery != "" {
+		vars["query"] = 1
+	}
+	if paginationRe.MatchString(u.Path) || paginationRe.MatchString(u.RequestURI()) {
+		vars["paginated"] = 1
+	}
+	for _, re := range include {
+		if re.MatchString(u.Path) {
+			vars["include"] = 1
+			break
+		}
+	}
+	return vars
+}
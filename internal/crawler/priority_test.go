@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrioritize_DefaultExpression(t *testing.T) {
+	expr, err := ParsePriorityExpression("")
+	if err != nil {
+		t.Fatalf("ParsePriorityExpression: %v", err)
+	}
+
+	links := []Link{
+		{URL: "https://example.com/blog/2019/01/02/old-post"},
+		{URL: "https://example.com/blog/page/7"},
+		{URL: "https://example.com/pricing", Sitemap: true},
+		{URL: "https://example.com/about"},
+		{URL: "https://example.com/docs/guide/setup"},
+	}
+	got := expr.Prioritize(links, PriorityContext{IncludePaths: []string{"^/docs/"}})
+
+	var urls []string
+	for _, l := range got {
+		urls = append(urls, l.URL)
+	}
+	want := []string{
+		"https://example.com/pricing",                  // 3*1 - 1 = 2
+		"https://example.com/about",                    // -1
+		"https://example.com/docs/guide/setup",         // 2 - 3 = -1, deeper than /about
+		"https://example.com/blog/2019/01/02/old-post", // -5
+		"https://example.com/blog/page/7",              // -3 - 4 = -7
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Fatalf("unexpected order:\n got %v\nwant %v", urls, want)
+	}
+}
+
+func TestParsePriorityExpression(t *testing.T) {
+	expr, err := ParsePriorityExpression("-depth + 0.5*query + 10")
+	if err != nil {
+		t.Fatalf("ParsePriorityExpression: %v", err)
+	}
+	got := expr.Prioritize([]Link{
+		{URL: "https://example.com/a/b"},
+		{URL: "https://example.com/a"},
+	}, PriorityContext{})
+	if got[0].URL != "https://example.com/a" {
+		t.Fatalf("expected shallower URL first, got %v", got)
+	}
+
+	for _, bad := range []string{"depth * 2", "3*bogus", "sitemap include", "+"} {
+		if _, err := ParsePriorityExpression(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
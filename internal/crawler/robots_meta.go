@@ -0,0 +1,109 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// RobotsMeta holds the indexing directives a page sets for a crawler with
+// <meta name="robots"> tags and X-Robots-Tag headers.
+type RobotsMeta struct {
+	// NoIndex asks crawlers not to keep the page.
+	NoIndex bool
+	// NoFollow asks crawlers not to follow the page's links.
+	NoFollow bool
+}
+
+// robotsTagParams are X-Robots-Tag directives that take a value after a
+// colon, so they cannot be mistaken for a user agent prefix.
+var robotsTagParams = map[string]bool{
+	"unavailable_after": true,
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+}
+
+// apply adds a comma-separated directive list to m.
+func (m *RobotsMeta) apply(directives string) {
+	for _, d := range strings.Split(directives, ",") {
+		switch strings.ToLower(strings.TrimSpace(d)) {
+		case "noindex":
+			m.NoIndex = true
+		case "nofollow":
+			m.NoFollow = true
+		case "none":
+			m.NoIndex = true
+			m.NoFollow = true
+		}
+	}
+}
+
+// ParseRobotsMeta returns the directives of an X-Robots-Tag header value
+// (repeated headers joined with ", ") and a page's HTML that apply to
+// agent. Directives without a user agent, or for "robots" in meta tags,
+// apply to every crawler; others only when they name agent.
+func ParseRobotsMeta(header, html, agent string) RobotsMeta {
+	var m RobotsMeta
+	agent = strings.ToLower(strings.TrimSpace(agent))
+
+	// X-Robots-Tag values may be prefixed with "agent:"; the prefix holds
+	// for the directives that follow until the next prefix.
+	applies := true
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if name, rest, ok := strings.Cut(part, ":"); ok && !robotsTagParams[strings.ToLower(strings.TrimSpace(name))] {
+			name = strings.ToLower(strings.TrimSpace(name))
+			applies = name == agent
+			part = rest
+		}
+		if applies {
+			m.apply(part)
+		}
+	}
+
+	if strings.TrimSpace(html) == "" {
+		return m
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return m
+	}
+	doc.Find("meta[name][content]").Each(func(_ int, sel *goquery.Selection) {
+		name := strings.ToLower(strings.TrimSpace(sel.AttrOr("name", "")))
+		if name == "robots" || (agent != "" && name == agent) {
+			m.apply(sel.AttrOr("content", ""))
+		}
+	})
+	return m
+}
+
+// hasRel reports whether a rel attribute contains want.
+func hasRel(rel, want string) bool {
+	for _, v := range strings.Fields(strings.ToLower(rel)) {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// NoFollowLinks returns the absolute targets of the page's links marked
+// rel="nofollow", resolved against base.
+func NoFollowLinks(html string, base *url.URL) map[string]bool {
+	out := map[string]bool{}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return out
+	}
+	doc.Find("a[href][rel]").Each(func(_ int, sel *goquery.Selection) {
+		if !hasRel(sel.AttrOr("rel", ""), "nofollow") {
+			return
+		}
+		if u, err := base.Parse(strings.TrimSpace(sel.AttrOr("href", ""))); err == nil {
+			out[u.String()] = true
+		}
+	})
+	return out
+}
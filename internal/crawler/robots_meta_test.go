@@ -0,0 +1,87 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseRobotsMeta(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		html   string
+		want   RobotsMeta
+	}{
+		{"none", "", `<html><head><title>x</title></head></html>`, RobotsMeta{}},
+		{"meta robots", "", `<meta name="robots" content="noindex, follow">`, RobotsMeta{NoIndex: true}},
+		{"meta none", "", `<meta name="ROBOTS" content="None">`, RobotsMeta{NoIndex: true, NoFollow: true}},
+		{"meta for agent", "", `<meta name="raitobot" content="nofollow"><meta name="googlebot" content="noindex">`, RobotsMeta{NoFollow: true}},
+		{"header", "noindex, nofollow", "", RobotsMeta{NoIndex: true, NoFollow: true}},
+		{"header other agent", "googlebot: noindex, nofollow", "", RobotsMeta{}},
+		{"header agents", "googlebot: noindex, RaitoBot: nofollow", "", RobotsMeta{NoFollow: true}},
+		{"header params", "unavailable_after: 25 Jun 2010 15:00:00 PST, noindex", "", RobotsMeta{NoIndex: true}},
+	}
+	for _, tc := range cases {
+		if got := ParseRobotsMeta(tc.header, tc.html, "RaitoBot"); got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNoFollowLinks(t *testing.T) {
+	base, _ := url.Parse("https://example.com/docs/")
+	got := NoFollowLinks(`<a href="/login" rel="nofollow noopener">Login</a><a href="intro">Intro</a><a href="https://ads.example/x" rel="sponsored NoFollow">Ad</a>`, base)
+	if len(got) != 2 || !got["https://example.com/login"] || !got["https://ads.example/x"] {
+		t.Fatalf("unexpected nofollow links: %v", got)
+	}
+}
+
+func TestMap_RespectRobotsMeta(t *testing.T) {
+	nofollowPage := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if nofollowPage {
+			w.Header().Set("X-Robots-Tag", "nofollow")
+		}
+		_, _ = w.Write([]byte(`<a href="/a">A</a><a href="/b" rel="nofollow">B</a><a href="/c">C</a>`))
+	}))
+	defer srv.Close()
+
+	mapLinks := func(respect bool) ([]string, int) {
+		res, err := Map(context.Background(), MapOptions{
+			URL:               srv.URL,
+			Limit:             10,
+			SitemapMode:       "skip",
+			Timeout:           5 * time.Second,
+			RespectRobotsMeta: respect,
+		})
+		if err != nil {
+			t.Fatalf("Map: %v", err)
+		}
+		var urls []string
+		for _, l := range res.Links {
+			urls = append(urls, l.URL)
+		}
+		sort.Strings(urls)
+		return urls, res.NoFollowSkipped
+	}
+
+	if urls, skipped := mapLinks(false); len(urls) != 3 || skipped != 0 {
+		t.Fatalf("expected every link without respectRobotsMeta, got %v (%d skipped)", urls, skipped)
+	}
+	if urls, skipped := mapLinks(true); len(urls) != 2 || urls[1] != srv.URL+"/c" || skipped != 1 {
+		t.Fatalf("expected the rel=nofollow link to be skipped, got %v (%d skipped)", urls, skipped)
+	}
+	nofollowPage = true
+	if urls, skipped := mapLinks(true); len(urls) != 0 || skipped != 3 {
+		t.Fatalf("expected no links from a nofollow page, got %v (%d skipped)", urls, skipped)
+	}
+}
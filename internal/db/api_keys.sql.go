@@ -158,7 +158,7 @@ func (q *Queries) AdminRevokeAPIKey(ctx context.Context, id uuid.UUID) (AdminRev
 }
 
 const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
-SELECT id, key_hash, label, is_admin, rate_limit_per_minute, tenant_id, created_at, revoked_at, user_id FROM api_keys
+SELECT id, key_hash, label, is_admin, rate_limit_per_minute, tenant_id, created_at, revoked_at, user_id, last_used_at, request_count, created_by_user_id FROM api_keys
 WHERE key_hash = $1 AND revoked_at IS NULL
 LIMIT 1
 `
@@ -176,6 +176,9 @@ func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey,
 		&i.CreatedAt,
 		&i.RevokedAt,
 		&i.UserID,
+		&i.LastUsedAt,
+		&i.RequestCount,
+		&i.CreatedByUserID,
 	)
 	return i, err
 }
@@ -227,7 +230,7 @@ func (q *Queries) GetAPIKeyLabelsByIDs(ctx context.Context, ids []uuid.UUID) ([]
 const insertAPIKey = `-- name: InsertAPIKey :one
 INSERT INTO api_keys (id, key_hash, label, is_admin, rate_limit_per_minute, tenant_id)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, key_hash, label, is_admin, rate_limit_per_minute, tenant_id, created_at, revoked_at, user_id
+RETURNING id, key_hash, label, is_admin, rate_limit_per_minute, tenant_id, created_at, revoked_at, user_id, last_used_at, request_count, created_by_user_id
 `
 
 type InsertAPIKeyParams struct {
@@ -259,12 +262,15 @@ func (q *Queries) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (Api
 		&i.CreatedAt,
 		&i.RevokedAt,
 		&i.UserID,
+		&i.LastUsedAt,
+		&i.RequestCount,
+		&i.CreatedByUserID,
 	)
 	return i, err
 }
 
 const listAPIKeysByTenant = `-- name: ListAPIKeysByTenant :many
-SELECT id, key_hash, label, is_admin, rate_limit_per_minute, tenant_id, created_at, revoked_at, user_id FROM api_keys
+SELECT id, key_hash, label, is_admin, rate_limit_per_minute, tenant_id, created_at, revoked_at, user_id, last_used_at, request_count, created_by_user_id FROM api_keys
 WHERE tenant_id = $1 AND revoked_at IS NULL
 ORDER BY created_at DESC
 `
@@ -288,6 +294,9 @@ func (q *Queries) ListAPIKeysByTenant(ctx context.Context, tenantID sql.NullStri
 			&i.CreatedAt,
 			&i.RevokedAt,
 			&i.UserID,
+			&i.LastUsedAt,
+			&i.RequestCount,
+			&i.CreatedByUserID,
 		); err != nil {
 			return nil, err
 		}
@@ -312,3 +321,14 @@ func (q *Queries) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, revokeAPIKey, id)
 	return err
 }
+
+const touchAPIKeyUsage = `-- name: TouchAPIKeyUsage :exec
+UPDATE api_keys
+SET last_used_at = NOW(), request_count = request_count + 1
+WHERE id = $1
+`
+
+func (q *Queries) TouchAPIKeyUsage(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, touchAPIKeyUsage, id)
+	return err
+}
@@ -13,8 +13,91 @@ import (
 	"github.com/google/uuid"
 )
 
+const deleteJobDocumentsByURL = `-- name: DeleteJobDocumentsByURL :execrows
+DELETE FROM documents
+WHERE job_id = $1 AND type = 'page' AND url = ANY($2::text[])
+`
+
+type DeleteJobDocumentsByURLParams struct {
+	JobID uuid.UUID
+	Urls  []string
+}
+
+func (q *Queries) DeleteJobDocumentsByURL(ctx context.Context, arg DeleteJobDocumentsByURLParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteJobDocumentsByURL, arg.JobID, arg.Urls)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDocumentByID = `-- name: GetDocumentByID :one
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type FROM documents
+WHERE id = $1
+`
+
+func (q *Queries) GetDocumentByID(ctx context.Context, id int64) (Document, error) {
+	row := q.db.QueryRowContext(ctx, getDocumentByID, id)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Url,
+		&i.Markdown,
+		&i.Html,
+		&i.RawHtml,
+		&i.Metadata,
+		&i.StatusCode,
+		&i.CreatedAt,
+		&i.Engine,
+		&i.Type,
+	)
+	return i, err
+}
+
+const getDocumentsByIDs = `-- name: GetDocumentsByIDs :many
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type FROM documents
+WHERE id = ANY($1::bigint[])
+ORDER BY id ASC
+`
+
+func (q *Queries) GetDocumentsByIDs(ctx context.Context, ids []int64) ([]Document, error) {
+	rows, err := q.db.QueryContext(ctx, getDocumentsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Url,
+			&i.Markdown,
+			&i.Html,
+			&i.RawHtml,
+			&i.Metadata,
+			&i.StatusCode,
+			&i.CreatedAt,
+			&i.Engine,
+			&i.Type,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDocumentsByJobID = `-- name: GetDocumentsByJobID :many
-SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine FROM documents
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type FROM documents
 WHERE job_id = $1
 ORDER BY id ASC
 `
@@ -39,6 +122,7 @@ func (q *Queries) GetDocumentsByJobID(ctx context.Context, jobID uuid.UUID) ([]D
 			&i.StatusCode,
 			&i.CreatedAt,
 			&i.Engine,
+			&i.Type,
 		); err != nil {
 			return nil, err
 		}
@@ -53,9 +137,58 @@ func (q *Queries) GetDocumentsByJobID(ctx context.Context, jobID uuid.UUID) ([]D
 	return items, nil
 }
 
+const getJobDocument = `-- name: GetJobDocument :one
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type FROM documents
+WHERE id = $1 AND job_id = $2
+`
+
+type GetJobDocumentParams struct {
+	ID    int64
+	JobID uuid.UUID
+}
+
+func (q *Queries) GetJobDocument(ctx context.Context, arg GetJobDocumentParams) (Document, error) {
+	row := q.db.QueryRowContext(ctx, getJobDocument, arg.ID, arg.JobID)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Url,
+		&i.Markdown,
+		&i.Html,
+		&i.RawHtml,
+		&i.Metadata,
+		&i.StatusCode,
+		&i.CreatedAt,
+		&i.Engine,
+		&i.Type,
+	)
+	return i, err
+}
+
+const getLatestJobDocumentIDByURL = `-- name: GetLatestJobDocumentIDByURL :one
+SELECT id
+FROM documents
+WHERE job_id = $1 AND type = 'page' AND url = ANY($2::text[])
+ORDER BY id DESC
+LIMIT 1
+`
+
+type GetLatestJobDocumentIDByURLParams struct {
+	JobID uuid.UUID
+	Urls  []string
+}
+
+func (q *Queries) GetLatestJobDocumentIDByURL(ctx context.Context, arg GetLatestJobDocumentIDByURLParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLatestJobDocumentIDByURL, arg.JobID, arg.Urls)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const insertDocument = `-- name: InsertDocument :exec
-INSERT INTO documents (job_id, url, markdown, html, raw_html, metadata, status_code, engine)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO documents (job_id, url, markdown, html, raw_html, metadata, status_code, engine, type)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 `
 
 type InsertDocumentParams struct {
@@ -67,6 +200,7 @@ type InsertDocumentParams struct {
 	Metadata   json.RawMessage
 	StatusCode sql.NullInt32
 	Engine     sql.NullString
+	Type       string
 }
 
 func (q *Queries) InsertDocument(ctx context.Context, arg InsertDocumentParams) error {
@@ -79,6 +213,109 @@ func (q *Queries) InsertDocument(ctx context.Context, arg InsertDocumentParams)
 		arg.Metadata,
 		arg.StatusCode,
 		arg.Engine,
+		arg.Type,
 	)
 	return err
 }
+
+const jobHasPageDocuments = `-- name: JobHasPageDocuments :one
+SELECT EXISTS (
+    SELECT 1 FROM documents WHERE job_id = $1 AND type = 'page'
+)
+`
+
+func (q *Queries) JobHasPageDocuments(ctx context.Context, jobID uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, jobHasPageDocuments, jobID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listJobDocumentURLs = `-- name: ListJobDocumentURLs :many
+SELECT DISTINCT url
+FROM documents
+WHERE job_id = $1 AND type = 'page' AND url = ANY($2::text[])
+`
+
+type ListJobDocumentURLsParams struct {
+	JobID uuid.UUID
+	Urls  []string
+}
+
+// Returns which of urls have a page document in the job.
+func (q *Queries) ListJobDocumentURLs(ctx context.Context, arg ListJobDocumentURLsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listJobDocumentURLs, arg.JobID, arg.Urls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		items = append(items, url)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchJobDocuments = `-- name: SearchJobDocuments :many
+SELECT id, job_id, url, markdown, html, raw_html, metadata, status_code, created_at, engine, type
+FROM documents,
+     to_tsquery('simple', $1::text) AS q,
+     to_tsvector('simple', left(coalesce(markdown, ''), 200000)) AS v
+WHERE job_id = $2 AND v @@ q
+ORDER BY ts_rank(v, q) DESC, id ASC
+LIMIT $3
+`
+
+type SearchJobDocumentsParams struct {
+	Query      string
+	JobID      uuid.UUID
+	MaxResults int32
+}
+
+// Ranks a job's documents against a to_tsquery expression, best match
+// first. The tsvector is built per call from the first 200000 characters
+// of markdown, which is cheap enough for the documents of one job.
+func (q *Queries) SearchJobDocuments(ctx context.Context, arg SearchJobDocumentsParams) ([]Document, error) {
+	rows, err := q.db.QueryContext(ctx, searchJobDocuments, arg.Query, arg.JobID, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Url,
+			&i.Markdown,
+			&i.Html,
+			&i.RawHtml,
+			&i.Metadata,
+			&i.StatusCode,
+			&i.CreatedAt,
+			&i.Engine,
+			&i.Type,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -16,26 +16,36 @@ import (
 )
 
 const getJobByID = `-- name: GetJobByID :one
-SELECT id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id
+SELECT id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id, created_by_user_id, visibility, collection_id, previous_job_id, metrics, pool, zero_retention, purged_at, applied_options, pinned
 FROM jobs
 WHERE id = $1
 `
 
 type GetJobByIDRow struct {
-	ID          uuid.UUID
-	Type        string
-	Status      string
-	Url         string
-	Input       json.RawMessage
-	Error       sql.NullString
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	CompletedAt sql.NullTime
-	Sync        bool
-	Priority    int32
-	Output      pqtype.NullRawMessage
-	TenantID    uuid.NullUUID
-	ApiKeyID    uuid.NullUUID
+	ID              uuid.UUID
+	Type            string
+	Status          string
+	Url             string
+	Input           json.RawMessage
+	Error           sql.NullString
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	CompletedAt     sql.NullTime
+	Sync            bool
+	Priority        int32
+	Output          pqtype.NullRawMessage
+	TenantID        uuid.NullUUID
+	ApiKeyID        uuid.NullUUID
+	CreatedByUserID uuid.NullUUID
+	Visibility      string
+	CollectionID    uuid.NullUUID
+	PreviousJobID   uuid.NullUUID
+	Metrics         pqtype.NullRawMessage
+	Pool            string
+	ZeroRetention   bool
+	PurgedAt        sql.NullTime
+	AppliedOptions  pqtype.NullRawMessage
+	Pinned          bool
 }
 
 func (q *Queries) GetJobByID(ctx context.Context, id uuid.UUID) (GetJobByIDRow, error) {
@@ -56,43 +66,69 @@ func (q *Queries) GetJobByID(ctx context.Context, id uuid.UUID) (GetJobByIDRow,
 		&i.Output,
 		&i.TenantID,
 		&i.ApiKeyID,
+		&i.CreatedByUserID,
+		&i.Visibility,
+		&i.CollectionID,
+		&i.PreviousJobID,
+		&i.Metrics,
+		&i.Pool,
+		&i.ZeroRetention,
+		&i.PurgedAt,
+		&i.AppliedOptions,
+		&i.Pinned,
 	)
 	return i, err
 }
 
 const insertJob = `-- name: InsertJob :one
-INSERT INTO jobs (id, type, status, url, input, sync, priority, tenant_id, api_key_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-RETURNING id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id
+INSERT INTO jobs (id, type, status, url, input, sync, priority, tenant_id, api_key_id, created_by_user_id, visibility, collection_id, pool, zero_retention, applied_options)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id, created_by_user_id, visibility, collection_id, previous_job_id, metrics, pool, zero_retention, purged_at, applied_options, pinned
 `
 
 type InsertJobParams struct {
-	ID       uuid.UUID
-	Type     string
-	Status   string
-	Url      string
-	Input    json.RawMessage
-	Sync     bool
-	Priority int32
-	TenantID uuid.NullUUID
-	ApiKeyID uuid.NullUUID
+	ID              uuid.UUID
+	Type            string
+	Status          string
+	Url             string
+	Input           json.RawMessage
+	Sync            bool
+	Priority        int32
+	TenantID        uuid.NullUUID
+	ApiKeyID        uuid.NullUUID
+	CreatedByUserID uuid.NullUUID
+	Visibility      string
+	CollectionID    uuid.NullUUID
+	Pool            string
+	ZeroRetention   bool
+	AppliedOptions  pqtype.NullRawMessage
 }
 
 type InsertJobRow struct {
-	ID          uuid.UUID
-	Type        string
-	Status      string
-	Url         string
-	Input       json.RawMessage
-	Error       sql.NullString
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	CompletedAt sql.NullTime
-	Sync        bool
-	Priority    int32
-	Output      pqtype.NullRawMessage
-	TenantID    uuid.NullUUID
-	ApiKeyID    uuid.NullUUID
+	ID              uuid.UUID
+	Type            string
+	Status          string
+	Url             string
+	Input           json.RawMessage
+	Error           sql.NullString
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	CompletedAt     sql.NullTime
+	Sync            bool
+	Priority        int32
+	Output          pqtype.NullRawMessage
+	TenantID        uuid.NullUUID
+	ApiKeyID        uuid.NullUUID
+	CreatedByUserID uuid.NullUUID
+	Visibility      string
+	CollectionID    uuid.NullUUID
+	PreviousJobID   uuid.NullUUID
+	Metrics         pqtype.NullRawMessage
+	Pool            string
+	ZeroRetention   bool
+	PurgedAt        sql.NullTime
+	AppliedOptions  pqtype.NullRawMessage
+	Pinned          bool
 }
 
 func (q *Queries) InsertJob(ctx context.Context, arg InsertJobParams) (InsertJobRow, error) {
@@ -106,6 +142,12 @@ func (q *Queries) InsertJob(ctx context.Context, arg InsertJobParams) (InsertJob
 		arg.Priority,
 		arg.TenantID,
 		arg.ApiKeyID,
+		arg.CreatedByUserID,
+		arg.Visibility,
+		arg.CollectionID,
+		arg.Pool,
+		arg.ZeroRetention,
+		arg.AppliedOptions,
 	)
 	var i InsertJobRow
 	err := row.Scan(
@@ -123,18 +165,44 @@ func (q *Queries) InsertJob(ctx context.Context, arg InsertJobParams) (InsertJob
 		&i.Output,
 		&i.TenantID,
 		&i.ApiKeyID,
+		&i.CreatedByUserID,
+		&i.Visibility,
+		&i.CollectionID,
+		&i.PreviousJobID,
+		&i.Metrics,
+		&i.Pool,
+		&i.ZeroRetention,
+		&i.PurgedAt,
+		&i.AppliedOptions,
+		&i.Pinned,
 	)
 	return i, err
 }
 
 const listPendingJobs = `-- name: ListPendingJobs :many
-SELECT id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id
+SELECT id, type, status, url, input, error, created_at, updated_at, completed_at, sync, priority, output, tenant_id, api_key_id, pool
 FROM jobs
 WHERE status = 'pending'
-ORDER BY priority DESC, created_at ASC
+  AND pool = ANY($2::text[])
+  AND NOT EXISTS (
+      SELECT 1 FROM queue_pauses p
+      WHERE p.job_type IN ('*', jobs.type)
+  )
+ORDER BY priority + CASE
+    WHEN sync AND created_at <= NOW() - $3::int * INTERVAL '1 millisecond'
+    THEN $4::int
+    ELSE 0
+  END DESC, created_at ASC
 LIMIT $1
 `
 
+type ListPendingJobsParams struct {
+	Limit            int32
+	Pools            []string
+	SyncBoostAfterMs int32
+	SyncBoost        int32
+}
+
 type ListPendingJobsRow struct {
 	ID          uuid.UUID
 	Type        string
@@ -150,10 +218,19 @@ type ListPendingJobsRow struct {
 	Output      pqtype.NullRawMessage
 	TenantID    uuid.NullUUID
 	ApiKeyID    uuid.NullUUID
+	Pool        string
 }
 
-func (q *Queries) ListPendingJobs(ctx context.Context, limit int32) ([]ListPendingJobsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listPendingJobs, limit)
+// Synchronous jobs that have waited at least sync_boost_after_ms are
+// claimed as if their priority were sync_boost higher, so callers
+// waiting on them are less likely to time out behind queued work.
+func (q *Queries) ListPendingJobs(ctx context.Context, arg ListPendingJobsParams) ([]ListPendingJobsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingJobs,
+		arg.Limit,
+		arg.Pools,
+		arg.SyncBoostAfterMs,
+		arg.SyncBoost,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +253,7 @@ func (q *Queries) ListPendingJobs(ctx context.Context, limit int32) ([]ListPendi
 			&i.Output,
 			&i.TenantID,
 			&i.ApiKeyID,
+			&i.Pool,
 		); err != nil {
 			return nil, err
 		}
@@ -190,6 +268,103 @@ func (q *Queries) ListPendingJobs(ctx context.Context, limit int32) ([]ListPendi
 	return items, nil
 }
 
+const pauseJob = `-- name: PauseJob :execrows
+UPDATE jobs
+SET status = 'paused',
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'running')
+`
+
+// Pauses a queued or running job. A running job's worker notices the
+// status, stops and saves its progress.
+func (q *Queries) PauseJob(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pauseJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const requeueFinishedJob = `-- name: RequeueFinishedJob :execrows
+UPDATE jobs
+SET status = 'pending',
+    error = NULL,
+    fingerprint = NULL,
+    updated_at = NOW(),
+    completed_at = NULL
+WHERE id = $1 AND status IN ('completed', 'failed')
+`
+
+// Queues a completed or failed job again, e.g. to rescrape some pages of
+// a crawl. Jobs that are still queued or running are left alone. The
+// fingerprint is dropped so new requests do not join the requeued job.
+func (q *Queries) RequeueFinishedJob(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requeueFinishedJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const requeueFailedJob = `-- name: RequeueFailedJob :execrows
+UPDATE jobs
+SET status = 'pending',
+    error = NULL,
+    output = NULL,
+    fingerprint = NULL,
+    updated_at = NOW(),
+    completed_at = NULL
+WHERE id = $1 AND status IN ('failed', 'dead_letter') AND purged_at IS NULL
+`
+
+// Queues a failed or dead-letter job again with its stored input, so an
+// admin can replay it after fixing the cause. The output of the failed run
+// is dropped. Purged zero-retention jobs have no input left and are left
+// alone.
+func (q *Queries) RequeueFailedJob(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requeueFailedJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resumeJob = `-- name: ResumeJob :execrows
+UPDATE jobs
+SET status = 'pending',
+    fingerprint = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'paused'
+`
+
+// Queues a paused job again. The fingerprint is dropped, as for
+// RequeueFinishedJob, since an identical job may have been queued while
+// this one was paused.
+func (q *Queries) ResumeJob(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resumeJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setJobPinned = `-- name: SetJobPinned :exec
+UPDATE jobs
+SET pinned = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type SetJobPinnedParams struct {
+	ID     uuid.UUID
+	Pinned bool
+}
+
+func (q *Queries) SetJobPinned(ctx context.Context, arg SetJobPinnedParams) error {
+	_, err := q.db.ExecContext(ctx, setJobPinned, arg.ID, arg.Pinned)
+	return err
+}
+
 const updateJobOutput = `-- name: UpdateJobOutput :exec
 UPDATE jobs
 SET output = $2,
@@ -212,7 +387,7 @@ UPDATE jobs
 SET status = $2,
     error = $3,
     updated_at = NOW(),
-    completed_at = CASE WHEN $2 IN ('completed', 'failed') THEN NOW() ELSE completed_at END
+    completed_at = CASE WHEN $2 IN ('completed', 'failed', 'dead_letter') THEN NOW() ELSE completed_at END
 WHERE id = $1
 `
 
@@ -23,6 +23,17 @@ type ApiKey struct {
 	CreatedAt          time.Time
 	RevokedAt          sql.NullTime
 	UserID             uuid.NullUUID
+	LastUsedAt         sql.NullTime
+	RequestCount       int64
+	CreatedByUserID    uuid.NullUUID
+}
+
+type ApiKeyReveal struct {
+	TokenHash string
+	ApiKeyID  uuid.UUID
+	RawKey    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
 }
 
 type AuditEvent struct {
@@ -39,6 +50,40 @@ type AuditEvent struct {
 	Metadata      json.RawMessage
 }
 
+type Collection struct {
+	ID              uuid.UUID
+	TenantID        uuid.UUID
+	Name            string
+	Description     sql.NullString
+	DefaultOptions  json.RawMessage
+	CreatedByUserID uuid.NullUUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type CrawlFrontier struct {
+	JobID    uuid.UUID
+	Queued   json.RawMessage
+	Seen     json.RawMessage
+	Admitted int32
+	State    json.RawMessage
+	PausedAt time.Time
+	Depths   json.RawMessage
+}
+
+type CrawlRescrape struct {
+	ID              uuid.UUID
+	JobID           uuid.UUID
+	Urls            json.RawMessage
+	Mode            string
+	Status          string
+	Results         pqtype.NullRawMessage
+	Error           sql.NullString
+	CreatedByUserID uuid.NullUUID
+	CreatedAt       time.Time
+	CompletedAt     sql.NullTime
+}
+
 type Document struct {
 	ID         int64
 	JobID      uuid.UUID
@@ -50,23 +95,212 @@ type Document struct {
 	StatusCode sql.NullInt32
 	CreatedAt  time.Time
 	Engine     sql.NullString
+	Type       string
+}
+
+type DocumentAnnotation struct {
+	DocumentID      int64
+	JobID           uuid.UUID
+	Notes           sql.NullString
+	Title           sql.NullString
+	Excluded        bool
+	UpdatedByUserID uuid.NullUUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type DocumentChange struct {
+	Seq             int64
+	TenantID        uuid.NullUUID
+	DocumentID      int64
+	JobID           uuid.UUID
+	Url             string
+	ChangeType      string
+	Visibility      string
+	CreatedByUserID uuid.NullUUID
+	ApiKeyID        uuid.NullUUID
+	ChangedAt       time.Time
+}
+
+type DocumentVersion struct {
+	ID              int64
+	TenantID        uuid.UUID
+	Url             string
+	ContentHash     string
+	Markdown        string
+	Title           sql.NullString
+	StatusCode      sql.NullInt32
+	JobID           uuid.NullUUID
+	Visibility      string
+	CreatedByUserID uuid.NullUUID
+	ApiKeyID        uuid.NullUUID
+	CreatedAt       time.Time
+	LastSeenAt      time.Time
+}
+
+type ExtractCache struct {
+	CacheKey   string
+	TenantID   uuid.NullUUID
+	Url        string
+	SchemaHash string
+	PromptHash string
+	Result     json.RawMessage
+	StatusCode int32
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+type HostStat struct {
+	Host           string
+	Bucket         time.Time
+	Requests       int64
+	Status2xx      int64
+	Status3xx      int64
+	Status4xx      int64
+	Status5xx      int64
+	Errors         int64
+	TlsErrors      int64
+	Timeouts       int64
+	DnsErrors      int64
+	RobotsBlocked  int64
+	LatencyMsSum   int64
+	Latency100ms   int64
+	Latency250ms   int64
+	Latency500ms   int64
+	Latency1s      int64
+	Latency2500ms  int64
+	Latency5s      int64
+	Latency10s     int64
+	LatencyOver10s int64
+	LastSeenAt     time.Time
 }
 
 type Job struct {
+	ID              uuid.UUID
+	Type            string
+	Status          string
+	Url             string
+	Input           json.RawMessage
+	Error           sql.NullString
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	CompletedAt     sql.NullTime
+	Priority        int32
+	Sync            bool
+	Output          pqtype.NullRawMessage
+	TenantID        uuid.NullUUID
+	ApiKeyID        uuid.NullUUID
+	Fingerprint     sql.NullString
+	CreatedByUserID uuid.NullUUID
+	Visibility      string
+	CollectionID    uuid.NullUUID
+	PreviousJobID   uuid.NullUUID
+	Metrics         pqtype.NullRawMessage
+	Pool            string
+	ZeroRetention   bool
+	PurgedAt        sql.NullTime
+	AppliedOptions  pqtype.NullRawMessage
+	Pinned          bool
+}
+
+type JobAsset struct {
 	ID          uuid.UUID
-	Type        string
-	Status      string
-	Url         string
-	Input       json.RawMessage
-	Error       sql.NullString
+	JobID       uuid.UUID
+	SourceUrl   string
+	ContentType string
+	SizeBytes   int64
+	Sha256      string
+	Data        []byte
+	CreatedAt   time.Time
+}
+
+type JobEvent struct {
+	ID        int64
+	JobID     uuid.UUID
+	Type      string
+	Message   sql.NullString
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+type JobHeartbeat struct {
+	JobID       uuid.UUID
+	WorkerID    string
+	StartedAt   time.Time
+	HeartbeatAt time.Time
+	PagesDone   int64
+	PagesTotal  int64
+	CurrentUrl  string
+}
+
+type JobShare struct {
+	ID                uuid.UUID
+	JobID             uuid.UUID
+	CreatedByUserID   uuid.NullUUID
+	CreatedByApiKeyID uuid.NullUUID
+	ExpiresAt         time.Time
+	RevokedAt         sql.NullTime
+	AccessCount       int64
+	LastAccessedAt    sql.NullTime
+	CreatedAt         time.Time
+}
+
+type MetricSnapshot struct {
+	Instance  string
+	Snapshot  json.RawMessage
+	UpdatedAt time.Time
+}
+
+type PromptTemplate struct {
+	ID              uuid.UUID
+	TenantID        uuid.UUID
+	Name            string
+	Kind            string
+	Version         int32
+	Prompt          string
+	Description     string
+	CreatedByUserID uuid.NullUUID
+	CreatedAt       time.Time
+}
+
+type QueuePause struct {
+	JobType        string
+	Reason         sql.NullString
+	PausedByUserID uuid.NullUUID
+	PausedAt       time.Time
+}
+
+type ScimGroup struct {
+	ID          uuid.UUID
+	DisplayName string
+	ExternalID  sql.NullString
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
-	CompletedAt sql.NullTime
-	Priority    int32
-	Sync        bool
-	Output      pqtype.NullRawMessage
-	TenantID    uuid.NullUUID
-	ApiKeyID    uuid.NullUUID
+}
+
+type ScimGroupMember struct {
+	GroupID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ScimUser struct {
+	UserID     uuid.UUID
+	ExternalID sql.NullString
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type Session struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	RememberMe bool
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+	Ip         sql.NullString
+	UserAgent  sql.NullString
 }
 
 type Tenant struct {
@@ -80,6 +314,44 @@ type Tenant struct {
 	DefaultApiKeyRateLimitPerMinute sql.NullInt32
 }
 
+type TenantDefaultFormat struct {
+	TenantID        uuid.UUID
+	Formats         json.RawMessage
+	UpdatedByUserID uuid.NullUUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type TenantLlmBudget struct {
+	TenantID              uuid.UUID
+	MonthlyTokenCap       sql.NullInt64
+	MonthlySpendCapMicros sql.NullInt64
+	UpdatedByUserID       uuid.NullUUID
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+type TenantLlmPolicy struct {
+	TenantID        uuid.UUID
+	AllowedModels   json.RawMessage
+	DefaultProvider sql.NullString
+	DefaultModel    sql.NullString
+	UpdatedByUserID uuid.NullUUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type TenantLlmUsage struct {
+	TenantID        uuid.UUID
+	Period          time.Time
+	Calls           int64
+	InputTokens     int64
+	OutputTokens    int64
+	SpendMicros     int64
+	NotifiedPercent int32
+	UpdatedAt       time.Time
+}
+
 type TenantMember struct {
 	TenantID  uuid.UUID
 	UserID    uuid.UUID
@@ -88,19 +360,80 @@ type TenantMember struct {
 	UpdatedAt time.Time
 }
 
-type User struct {
+type TenantSecret struct {
 	ID              uuid.UUID
-	Email           string
-	Name            sql.NullString
-	AuthProvider    string
-	AuthSubject     sql.NullString
-	IsSystemAdmin   bool
-	PasswordHash    sql.NullString
-	PasswordVersion sql.NullInt32
+	TenantID        uuid.UUID
+	Name            string
+	Description     string
+	ValueEncrypted  []byte
+	CreatedByUserID uuid.NullUUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type TenantTransformHook struct {
+	TenantID        uuid.UUID
+	Url             string
+	TimeoutMs       int32
+	FailurePolicy   string
+	SigningSecret   sql.NullString
+	Enabled         bool
+	UpdatedByUserID uuid.NullUUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type TenantWebhook struct {
+	TenantID        uuid.UUID
+	Url             string
+	Events          json.RawMessage
+	UpdatedByUserID uuid.NullUUID
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
-	DefaultTenantID uuid.NullUUID
-	ThemePreference string
-	IsDisabled      bool
-	DisabledAt      sql.NullTime
+}
+
+type TenantWebhookSecret struct {
+	TenantID                uuid.UUID
+	SecretEncrypted         []byte
+	PreviousSecretEncrypted []byte
+	PreviousExpiresAt       sql.NullTime
+	CreatedAt               time.Time
+	RotatedAt               time.Time
+}
+
+type User struct {
+	ID                 uuid.UUID
+	Email              string
+	Name               sql.NullString
+	AuthProvider       string
+	AuthSubject        sql.NullString
+	IsSystemAdmin      bool
+	PasswordHash       sql.NullString
+	PasswordVersion    sql.NullInt32
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	DefaultTenantID    uuid.NullUUID
+	ThemePreference    string
+	IsDisabled         bool
+	DisabledAt         sql.NullTime
+	MustChangePassword bool
+}
+
+type UserNotificationPreference struct {
+	UserID      uuid.UUID
+	OnCompleted bool
+	OnFailed    bool
+	Email       bool
+	WebhookUrl  sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type Worker struct {
+	ID              string
+	Hostname        string
+	Version         string
+	Capabilities    json.RawMessage
+	StartedAt       time.Time
+	LastHeartbeatAt time.Time
 }
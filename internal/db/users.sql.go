@@ -25,7 +25,7 @@ func (q *Queries) AdminCountUsers(ctx context.Context, dollar_1 interface{}) (in
 }
 
 const adminListUsers = `-- name: AdminListUsers :many
-SELECT id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at FROM users
+SELECT id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password FROM users
 WHERE ($1 = '' OR email ILIKE '%' || $1 || '%' OR name ILIKE '%' || $1 || '%')
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -61,6 +61,7 @@ func (q *Queries) AdminListUsers(ctx context.Context, arg AdminListUsersParams)
 			&i.ThemePreference,
 			&i.IsDisabled,
 			&i.DisabledAt,
+			&i.MustChangePassword,
 		); err != nil {
 			return nil, err
 		}
@@ -82,7 +83,7 @@ SET
     password_version = $3,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at
+RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password
 `
 
 type AdminSetUserPasswordParams struct {
@@ -109,6 +110,7 @@ func (q *Queries) AdminSetUserPassword(ctx context.Context, arg AdminSetUserPass
 		&i.ThemePreference,
 		&i.IsDisabled,
 		&i.DisabledAt,
+		&i.MustChangePassword,
 	)
 	return i, err
 }
@@ -122,7 +124,7 @@ SET
     disabled_at = $5,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at
+RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password
 `
 
 type AdminUpdateUserParams struct {
@@ -157,14 +159,26 @@ func (q *Queries) AdminUpdateUser(ctx context.Context, arg AdminUpdateUserParams
 		&i.ThemePreference,
 		&i.IsDisabled,
 		&i.DisabledAt,
+		&i.MustChangePassword,
 	)
 	return i, err
 }
 
+const countSystemAdminUsers = `-- name: CountSystemAdminUsers :one
+SELECT COUNT(*) FROM users WHERE is_system_admin = TRUE
+`
+
+func (q *Queries) CountSystemAdminUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSystemAdminUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at
+RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password
 `
 
 type CreateUserParams struct {
@@ -205,12 +219,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.ThemePreference,
 		&i.IsDisabled,
 		&i.DisabledAt,
+		&i.MustChangePassword,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at FROM users WHERE email = $1
+SELECT id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -231,12 +246,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.ThemePreference,
 		&i.IsDisabled,
 		&i.DisabledAt,
+		&i.MustChangePassword,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at FROM users WHERE id = $1
+SELECT id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
@@ -257,12 +273,13 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.ThemePreference,
 		&i.IsDisabled,
 		&i.DisabledAt,
+		&i.MustChangePassword,
 	)
 	return i, err
 }
 
 const getUserByProviderSubject = `-- name: GetUserByProviderSubject :one
-SELECT id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at FROM users WHERE auth_provider = $1 AND auth_subject = $2
+SELECT id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password FROM users WHERE auth_provider = $1 AND auth_subject = $2
 `
 
 type GetUserByProviderSubjectParams struct {
@@ -288,6 +305,132 @@ func (q *Queries) GetUserByProviderSubject(ctx context.Context, arg GetUserByPro
 		&i.ThemePreference,
 		&i.IsDisabled,
 		&i.DisabledAt,
+		&i.MustChangePassword,
+	)
+	return i, err
+}
+
+const sCIMUpdateUser = `-- name: SCIMUpdateUser :one
+UPDATE users
+SET
+    email = $2,
+    name = $3,
+    is_disabled = $4,
+    disabled_at = $5,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password
+`
+
+type SCIMUpdateUserParams struct {
+	ID         uuid.UUID
+	Email      string
+	Name       sql.NullString
+	IsDisabled bool
+	DisabledAt sql.NullTime
+}
+
+func (q *Queries) SCIMUpdateUser(ctx context.Context, arg SCIMUpdateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, sCIMUpdateUser,
+		arg.ID,
+		arg.Email,
+		arg.Name,
+		arg.IsDisabled,
+		arg.DisabledAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.AuthProvider,
+		&i.AuthSubject,
+		&i.IsSystemAdmin,
+		&i.PasswordHash,
+		&i.PasswordVersion,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultTenantID,
+		&i.ThemePreference,
+		&i.IsDisabled,
+		&i.DisabledAt,
+		&i.MustChangePassword,
+	)
+	return i, err
+}
+
+const setUserAuthSubject = `-- name: SetUserAuthSubject :exec
+UPDATE users
+SET
+    auth_subject = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserAuthSubjectParams struct {
+	ID          uuid.UUID
+	AuthSubject sql.NullString
+}
+
+func (q *Queries) SetUserAuthSubject(ctx context.Context, arg SetUserAuthSubjectParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAuthSubject, arg.ID, arg.AuthSubject)
+	return err
+}
+
+const setUserMustChangePassword = `-- name: SetUserMustChangePassword :exec
+UPDATE users
+SET
+    must_change_password = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserMustChangePasswordParams struct {
+	ID                 uuid.UUID
+	MustChangePassword bool
+}
+
+func (q *Queries) SetUserMustChangePassword(ctx context.Context, arg SetUserMustChangePasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserMustChangePassword, arg.ID, arg.MustChangePassword)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET
+    password_hash = $2,
+    password_version = $3,
+    must_change_password = FALSE,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password
+`
+
+type UpdateUserPasswordParams struct {
+	ID              uuid.UUID
+	PasswordHash    sql.NullString
+	PasswordVersion sql.NullInt32
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.PasswordHash, arg.PasswordVersion)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.AuthProvider,
+		&i.AuthSubject,
+		&i.IsSystemAdmin,
+		&i.PasswordHash,
+		&i.PasswordVersion,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultTenantID,
+		&i.ThemePreference,
+		&i.IsDisabled,
+		&i.DisabledAt,
+		&i.MustChangePassword,
 	)
 	return i, err
 }
@@ -300,7 +443,7 @@ SET
     default_tenant_id = $4,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at
+RETURNING id, email, name, auth_provider, auth_subject, is_system_admin, password_hash, password_version, created_at, updated_at, default_tenant_id, theme_preference, is_disabled, disabled_at, must_change_password
 `
 
 type UpdateUserProfileParams struct {
@@ -333,6 +476,7 @@ func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfilePa
 		&i.ThemePreference,
 		&i.IsDisabled,
 		&i.DisabledAt,
+		&i.MustChangePassword,
 	)
 	return i, err
 }
@@ -0,0 +1,126 @@
+// Package dedupe finds near-duplicate documents with simhash fingerprints.
+package dedupe
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxDistance is the largest Hamming distance between two
+// fingerprints that still counts as a near duplicate.
+const DefaultMaxDistance = 6
+
+// Fingerprint returns the 64-bit simhash of text, using its words as
+// features so repeated words weigh more. Texts that differ in a few words
+// get fingerprints that differ in a few bits. Empty text has a zero
+// fingerprint.
+func Fingerprint(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, w := range words {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(w))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var fp uint64
+	for i, w := range weights {
+		if w > 0 {
+			fp |= 1 << uint(i)
+		}
+	}
+	return fp
+}
+
+// Distance is the number of bits in which two fingerprints differ.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Document is one input to Clusters.
+type Document struct {
+	ID   int64
+	Text string
+}
+
+// Cluster is a group of near-duplicate documents. DocumentIDs keep the
+// input order, so the first one is the earliest page of the group.
+type Cluster struct {
+	ID          int
+	DocumentIDs []int64
+}
+
+// Clusters groups documents whose fingerprints are within maxDistance
+// bits of each other, directly or through other members. Only groups of
+// two or more documents are returned, numbered from 1 in order of their
+// first document. Documents without text are never clustered.
+func Clusters(docs []Document, maxDistance int) []Cluster {
+	fps := make([]uint64, len(docs))
+	parent := make([]int, len(docs))
+	for i, d := range docs {
+		fps[i] = Fingerprint(d.Text)
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range docs {
+		if fps[i] == 0 {
+			continue
+		}
+		for j := i + 1; j < len(docs); j++ {
+			if fps[j] == 0 || Distance(fps[i], fps[j]) > maxDistance {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri == rj {
+				continue
+			}
+			// Keep the earliest document as the root.
+			if rj < ri {
+				ri, rj = rj, ri
+			}
+			parent[rj] = ri
+		}
+	}
+
+	members := map[int][]int64{}
+	var roots []int
+	for i, d := range docs {
+		r := find(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], d.ID)
+	}
+
+	var out []Cluster
+	for _, r := range roots {
+		if len(members[r]) < 2 {
+			continue
+		}
+		out = append(out, Cluster{ID: len(out) + 1, DocumentIDs: members[r]})
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package dedupe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const article = `Acme Widgets are built to last. Every widget ships with a two year warranty,
+free returns within thirty days, and support from our team in Denver. Our catalog covers
+industrial widgets, home widgets, and custom orders for teams of any size. Read the
+installation guide before mounting a widget, check the compatibility table for your model,
+and contact sales for volume pricing. Subscribe to the newsletter for release notes,
+maintenance tips, and early access to new product lines.`
+
+func TestFingerprint_NearDuplicatesAreClose(t *testing.T) {
+	a := Fingerprint(article)
+	b := Fingerprint(strings.Replace(article, "Denver", "Boston", 1))
+	c := Fingerprint("Quarterly results: revenue grew in every region while costs fell, and the board approved a new buyback program for next year.")
+
+	if d := Distance(a, b); d > DefaultMaxDistance {
+		t.Fatalf("expected a one-word edit to stay within %d bits, got %d", DefaultMaxDistance, d)
+	}
+	if d := Distance(a, c); d <= DefaultMaxDistance {
+		t.Fatalf("expected unrelated text to differ by more than %d bits, got %d", DefaultMaxDistance, d)
+	}
+	if Fingerprint("") != 0 || Fingerprint(" \n ") != 0 {
+		t.Fatalf("expected empty text to have a zero fingerprint")
+	}
+	if Fingerprint("Hello, World") != Fingerprint("hello world") {
+		t.Fatalf("expected case and punctuation to be ignored")
+	}
+}
+
+func TestClusters(t *testing.T) {
+	docs := []Document{
+		{ID: 10, Text: "Quarterly results: revenue grew in every region while costs fell."},
+		{ID: 11, Text: article},
+		{ID: 12, Text: ""},
+		{ID: 13, Text: strings.Replace(article, "Denver", "Boston", 1)},
+		{ID: 14, Text: ""},
+		{ID: 15, Text: article},
+	}
+
+	got := Clusters(docs, DefaultMaxDistance)
+	want := []Cluster{{ID: 1, DocumentIDs: []int64{11, 13, 15}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Clusters = %+v, want %+v", got, want)
+	}
+
+	if got := Clusters(docs[:1], DefaultMaxDistance); got != nil {
+		t.Fatalf("expected no clusters for a single document, got %+v", got)
+	}
+}
@@ -0,0 +1,296 @@
+// Package dnscache resolves host names for outbound connections. It keeps
+// answers and failures in process for a TTL and can send lookups to
+// specific nameservers or a DNS-over-HTTPS endpoint instead of the system
+// resolver.
+package dnscache
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"raito/internal/metrics"
+)
+
+// Defaults used for zero Options fields.
+const (
+	DefaultTTL         = time.Minute
+	DefaultMinTTL      = 5 * time.Second
+	DefaultMaxTTL      = time.Hour
+	DefaultNegativeTTL = 10 * time.Second
+	DefaultMaxEntries  = 10000
+	DefaultTimeout     = 5 * time.Second
+)
+
+// Options configures a Resolver.
+type Options struct {
+	// Cache keeps answers and failures for reuse. Without it every dial
+	// looks its host up again.
+	Cache bool
+	// TTL is how long an answer is kept when the lookup does not report
+	// record TTLs, as with the system resolver and Nameservers.
+	TTL time.Duration
+	// MinTTL and MaxTTL clamp the record TTLs of DNS-over-HTTPS answers.
+	MinTTL time.Duration
+	MaxTTL time.Duration
+	// NegativeTTL is how long a failed lookup is remembered.
+	NegativeTTL time.Duration
+	// MaxEntries caps the number of cached host names.
+	MaxEntries int
+	// Nameservers are DNS servers ("host" or "host:port") queried instead
+	// of the system resolver, in turn.
+	Nameservers []string
+	// DoHURL sends lookups to a DNS-over-HTTPS (RFC 8484) endpoint such
+	// as https://cloudflare-dns.com/dns-query. It takes precedence over
+	// Nameservers.
+	DoHURL string
+	// Timeout bounds a single lookup.
+	Timeout time.Duration
+}
+
+// lookupFunc resolves host. A zero ttl means the source did not report
+// one.
+type lookupFunc func(ctx context.Context, host string) (ips []net.IP, ttl time.Duration, err error)
+
+// Resolver looks host names up for dialing, optionally through a cache.
+// It is safe for concurrent use; concurrent lookups of the same host
+// share one query.
+type Resolver struct {
+	opts   Options
+	lookup lookupFunc
+	// custom is set when lookups do not go to the system resolver.
+	custom bool
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	ips     []net.IP
+	err     error
+	expires time.Time
+	// ready is closed once the lookup filling the entry has finished.
+	ready chan struct{}
+}
+
+// New returns a Resolver for opts, filling in defaults.
+func New(opts Options) *Resolver {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.MinTTL <= 0 {
+		opts.MinTTL = DefaultMinTTL
+	}
+	if opts.MaxTTL <= 0 {
+		opts.MaxTTL = DefaultMaxTTL
+	}
+	if opts.NegativeTTL <= 0 {
+		opts.NegativeTTL = DefaultNegativeTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	r := &Resolver{opts: opts, now: time.Now, entries: map[string]*entry{}}
+	switch {
+	case opts.DoHURL != "":
+		r.lookup = dohLookup(&http.Client{Timeout: opts.Timeout}, opts.DoHURL)
+		r.custom = true
+	case len(opts.Nameservers) > 0:
+		r.lookup = nameserverLookup(opts.Nameservers)
+		r.custom = true
+	default:
+		r.lookup = systemLookup
+	}
+	return r
+}
+
+// LookupIP returns the addresses of host, from the cache when a fresh
+// answer or failure is held.
+func (r *Resolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if !r.opts.Cache {
+		ips, _, err := r.resolve(ctx, host)
+		return ips, err
+	}
+
+	r.mu.Lock()
+	if e, ok := r.entries[host]; ok {
+		select {
+		case <-e.ready:
+			if r.now().Before(e.expires) {
+				r.mu.Unlock()
+				metrics.RecordDNSLookup("hit")
+				return e.ips, e.err
+			}
+		default:
+			// Another caller is looking the host up; share its answer.
+			r.mu.Unlock()
+			select {
+			case <-e.ready:
+				metrics.RecordDNSLookup("hit")
+				return e.ips, e.err
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	e := &entry{ready: make(chan struct{})}
+	r.entries[host] = e
+	r.prune()
+	r.mu.Unlock()
+
+	// The answer is shared with other callers, so the lookup is not cut
+	// short by this caller's cancellation.
+	ips, ttl, err := r.resolve(context.WithoutCancel(ctx), host)
+
+	r.mu.Lock()
+	e.ips, e.err = ips, err
+	switch {
+	case err != nil:
+		e.expires = r.now().Add(r.opts.NegativeTTL)
+	case ttl > 0:
+		e.expires = r.now().Add(min(max(ttl, r.opts.MinTTL), r.opts.MaxTTL))
+	default:
+		e.expires = r.now().Add(r.opts.TTL)
+	}
+	close(e.ready)
+	r.mu.Unlock()
+	return ips, err
+}
+
+// resolve runs one uncached lookup.
+func (r *Resolver) resolve(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+
+	ips, ttl, err := r.lookup(ctx, host)
+	if err == nil && len(ips) == 0 {
+		err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	if err != nil {
+		metrics.RecordDNSLookup("error")
+		return nil, 0, err
+	}
+	metrics.RecordDNSLookup("miss")
+	return ips, ttl, nil
+}
+
+// prune drops expired entries once the cache is over MaxEntries and, if
+// that is not enough, the entry closest to expiring. r.mu must be held.
+func (r *Resolver) prune() {
+	if len(r.entries) <= r.opts.MaxEntries {
+		return
+	}
+	now := r.now()
+	var oldest string
+	var oldestExpires time.Time
+	for host, e := range r.entries {
+		select {
+		case <-e.ready:
+		default:
+			continue
+		}
+		if !now.Before(e.expires) {
+			delete(r.entries, host)
+			continue
+		}
+		if oldest == "" || e.expires.Before(oldestExpires) {
+			oldest, oldestExpires = host, e.expires
+		}
+	}
+	if len(r.entries) > r.opts.MaxEntries && oldest != "" {
+		delete(r.entries, oldest)
+	}
+}
+
+// DialContext connects to address through dialer, resolving its host
+// with r and trying each address in turn. Dialer checks such as a
+// Control hook therefore see the resolved address. Without a cache or
+// custom nameservers it dials address unchanged.
+func (r *Resolver) DialContext(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if (!r.opts.Cache && !r.custom) || net.ParseIP(host) != nil {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	ips, err := r.LookupIP(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var firstErr error
+	for _, ip := range ips {
+		switch {
+		case strings.HasSuffix(network, "4") && ip.To4() == nil,
+			strings.HasSuffix(network, "6") && ip.To4() != nil:
+			continue
+		}
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if firstErr == nil {
+		firstErr = &net.DNSError{Err: "no " + network + " address", Name: host, IsNotFound: true}
+	}
+	return nil, firstErr
+}
+
+var (
+	defaultResolver atomic.Pointer[Resolver]
+	// passthrough is used until SetDefault is called; it neither caches
+	// nor changes the resolver.
+	passthrough = New(Options{})
+
+	sharedTransport = newTransport()
+)
+
+// Default returns the process-wide resolver set with SetDefault.
+func Default() *Resolver {
+	if r := defaultResolver.Load(); r != nil {
+		return r
+	}
+	return passthrough
+}
+
+// SetDefault makes r the process-wide resolver used by Transport and by
+// clients that dial through Default.
+func SetDefault(r *Resolver) {
+	defaultResolver.Store(r)
+}
+
+// Transport returns an http.Transport shared by scrapers and crawlers. It
+// dials through Default, so its clients reuse both connections and
+// cached lookups.
+func Transport() *http.Transport {
+	return sharedTransport
+}
+
+func newTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return Default().DialContext(ctx, dialer, network, address)
+	}
+	return t
+}
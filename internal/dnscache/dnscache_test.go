@@ -0,0 +1,226 @@
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeResolver returns a caching Resolver whose lookups are answered by
+// answer and counted in calls, with a clock the test controls.
+func fakeResolver(opts Options, answer lookupFunc) (*Resolver, *atomic.Int32, *time.Time) {
+	opts.Cache = true
+	r := New(opts)
+	calls := &atomic.Int32{}
+	r.lookup = func(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+		calls.Add(1)
+		return answer(ctx, host)
+	}
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	return r, calls, &now
+}
+
+func TestResolver_CachesAnswersAndFailures(t *testing.T) {
+	r, calls, now := fakeResolver(Options{TTL: time.Minute, NegativeTTL: 10 * time.Second}, func(_ context.Context, host string) ([]net.IP, time.Duration, error) {
+		if host == "missing.test" {
+			return nil, 0, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []net.IP{net.ParseIP("192.0.2.1")}, 0, nil
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		ips, err := r.LookupIP(ctx, "Example.TEST.")
+		if err != nil || len(ips) != 1 || !ips[0].Equal(net.ParseIP("192.0.2.1")) {
+			t.Fatalf("unexpected lookup result %v, %v", ips, err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected one lookup for repeated names, got %d", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.LookupIP(ctx, "missing.test"); err == nil {
+			t.Fatalf("expected the failure to be returned")
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected the failure to be cached, got %d lookups", got)
+	}
+
+	*now = now.Add(15 * time.Second)
+	_, _ = r.LookupIP(ctx, "missing.test")
+	_, _ = r.LookupIP(ctx, "example.test")
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected only the failure to expire after 15s, got %d lookups", got)
+	}
+
+	*now = now.Add(time.Minute)
+	_, _ = r.LookupIP(ctx, "example.test")
+	if got := calls.Load(); got != 4 {
+		t.Fatalf("expected the answer to expire after its TTL, got %d lookups", got)
+	}
+}
+
+func TestResolver_ClampsRecordTTL(t *testing.T) {
+	r, calls, now := fakeResolver(Options{MinTTL: 30 * time.Second, MaxTTL: time.Minute}, func(_ context.Context, host string) ([]net.IP, time.Duration, error) {
+		if host == "short.test" {
+			return []net.IP{net.ParseIP("192.0.2.1")}, time.Second, nil
+		}
+		return []net.IP{net.ParseIP("192.0.2.2")}, time.Hour, nil
+	})
+	ctx := context.Background()
+	_, _ = r.LookupIP(ctx, "short.test")
+	_, _ = r.LookupIP(ctx, "long.test")
+
+	*now = now.Add(20 * time.Second)
+	_, _ = r.LookupIP(ctx, "short.test")
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected a 1s TTL to be raised to the minimum, got %d lookups", got)
+	}
+	*now = now.Add(time.Minute)
+	_, _ = r.LookupIP(ctx, "long.test")
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected a 1h TTL to be capped at the maximum, got %d lookups", got)
+	}
+}
+
+func TestResolver_SharesConcurrentLookups(t *testing.T) {
+	release := make(chan struct{})
+	r, calls, _ := fakeResolver(Options{}, func(context.Context, string) ([]net.IP, time.Duration, error) {
+		<-release
+		return []net.IP{net.ParseIP("192.0.2.1")}, 0, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.LookupIP(context.Background(), "example.test"); err != nil {
+				t.Errorf("LookupIP: %v", err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected concurrent lookups to share one query, got %d", got)
+	}
+}
+
+func TestResolver_PrunesToMaxEntries(t *testing.T) {
+	r, _, _ := fakeResolver(Options{MaxEntries: 2}, func(context.Context, string) ([]net.IP, time.Duration, error) {
+		return []net.IP{net.ParseIP("192.0.2.1")}, 0, nil
+	})
+	for _, host := range []string{"a.test", "b.test", "c.test", "d.test"} {
+		_, _ = r.LookupIP(context.Background(), host)
+	}
+	if len(r.entries) != 2 {
+		t.Fatalf("expected the cache to stay at its cap, got %d entries", len(r.entries))
+	}
+}
+
+func TestResolver_DialContextUsesResolvedAddress(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	r, _, _ := fakeResolver(Options{}, func(context.Context, string) ([]net.IP, time.Duration, error) {
+		// The first address refuses connections; the dial moves on.
+		return []net.IP{net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.1")}, 0, nil
+	})
+	var dialed []string
+	dialer := &net.Dialer{Timeout: time.Second}
+	dialer.Control = func(_, address string, _ syscall.RawConn) error {
+		dialed = append(dialed, address)
+		if address == net.JoinHostPort("127.0.0.2", port) {
+			return errors.New("blocked")
+		}
+		return nil
+	}
+	conn, err := r.DialContext(context.Background(), dialer, "tcp", net.JoinHostPort("example.test", port))
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	conn.Close()
+	if len(dialed) != 2 || dialed[1] != net.JoinHostPort("127.0.0.1", port) {
+		t.Fatalf("expected the dialer to see resolved addresses, got %v", dialed)
+	}
+}
+
+func TestDoHLookup(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "bad content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		var query dnsmessage.Message
+		if err := query.Unpack(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q := query.Questions[0]
+		reply := dnsmessage.Message{
+			Header:    dnsmessage.Header{Response: true, RecursionAvailable: true},
+			Questions: query.Questions,
+		}
+		switch {
+		case q.Name.String() == "missing.test.":
+			reply.RCode = dnsmessage.RCodeNameError
+		case q.Type == dnsmessage.TypeA:
+			reply.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 300},
+				Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+			}}
+		case q.Type == dnsmessage.TypeAAAA:
+			reply.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeAAAA, Class: dnsmessage.ClassINET, TTL: 120},
+				Body:   &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}},
+			}}
+		}
+		packed, _ := reply.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(packed)
+	}))
+	defer srv.Close()
+
+	lookup := dohLookup(srv.Client(), srv.URL)
+	ips, ttl, err := lookup(context.Background(), "example.test")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if len(ips) != 2 || !ips[0].Equal(net.ParseIP("192.0.2.1")) || !ips[1].Equal(net.ParseIP("2001:db8::1")) {
+		t.Fatalf("unexpected addresses %v", ips)
+	}
+	if ttl != 120*time.Second {
+		t.Fatalf("expected the lowest record TTL, got %s", ttl)
+	}
+
+	_, _, err = lookup(context.Background(), "missing.test")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("expected a not-found error, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package dnscache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxDoHResponseBytes bounds a DNS-over-HTTPS reply; DNS messages are at
+// most 64 KiB.
+const maxDoHResponseBytes = 64 << 10
+
+func systemLookup(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, 0, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, a := range addrs {
+		ips = append(ips, a.IP)
+	}
+	return ips, 0, nil
+}
+
+// nameserverLookup queries servers with Go's resolver, moving on to the
+// next server each time the resolver dials, so a retry after a failed
+// query goes to another server.
+func nameserverLookup(servers []string) lookupFunc {
+	addrs := make([]string, len(servers))
+	for i, s := range servers {
+		if _, _, err := net.SplitHostPort(s); err != nil {
+			s = net.JoinHostPort(s, "53")
+		}
+		addrs[i] = s
+	}
+	var next atomic.Uint64
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			addr := addrs[(next.Add(1)-1)%uint64(len(addrs))]
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+	return func(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, 0, err
+		}
+		ips := make([]net.IP, 0, len(addrs))
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+		return ips, 0, nil
+	}
+}
+
+// dohLookup queries endpoint for A and AAAA records over DNS-over-HTTPS.
+// The TTL returned is the lowest record TTL in the answers.
+func dohLookup(client *http.Client, endpoint string) lookupFunc {
+	return func(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+		var ips []net.IP
+		var ttl time.Duration
+		var firstErr error
+		for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+			got, gotTTL, err := dohQuery(ctx, client, endpoint, host, qtype)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			ips = append(ips, got...)
+			if len(got) > 0 && (ttl == 0 || gotTTL < ttl) {
+				ttl = gotTTL
+			}
+		}
+		if len(ips) == 0 && firstErr != nil {
+			return nil, 0, firstErr
+		}
+		return ips, ttl, nil
+	}
+}
+
+func dohQuery(ctx context.Context, client *http.Client, endpoint, host string, qtype dnsmessage.Type) ([]net.IP, time.Duration, error) {
+	name, err := dnsmessage.NewName(host + ".")
+	if err != nil {
+		return nil, 0, &net.DNSError{Err: err.Error(), Name: host}
+	}
+	// RFC 8484 asks for ID 0 so responses can be cached by HTTP caches.
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(packed))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, &net.DNSError{Err: err.Error(), Name: host, IsTemporary: true}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, &net.DNSError{Err: fmt.Sprintf("DNS-over-HTTPS server returned %d", resp.StatusCode), Name: host, IsTemporary: true}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDoHResponseBytes))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var reply dnsmessage.Message
+	if err := reply.Unpack(body); err != nil {
+		return nil, 0, &net.DNSError{Err: "invalid DNS-over-HTTPS reply: " + err.Error(), Name: host}
+	}
+	switch reply.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, 0, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	default:
+		return nil, 0, &net.DNSError{Err: "server failure: " + reply.RCode.String(), Name: host, IsTemporary: true}
+	}
+
+	var ips []net.IP
+	var ttl time.Duration
+	for _, ans := range reply.Answers {
+		var ip net.IP
+		switch b := ans.Body.(type) {
+		case *dnsmessage.AResource:
+			ip = net.IP(append([]byte(nil), b.A[:]...))
+		case *dnsmessage.AAAAResource:
+			ip = net.IP(append([]byte(nil), b.AAAA[:]...))
+		default:
+			// CNAMEs are followed by the server; their targets' records
+			// are in the same answer.
+			continue
+		}
+		ips = append(ips, ip)
+		if recTTL := time.Duration(ans.Header.TTL) * time.Second; ttl == 0 || recTTL < ttl {
+			ttl = recTTL
+		}
+	}
+	return ips, ttl, nil
+}
@@ -0,0 +1,155 @@
+// Package events fans out small messages to every API and worker
+// process, such as "job X has a new timeline event". Messages are
+// wakeups, not a durable log: subscribers re-read the state they care
+// about from Postgres, and a message may be dropped when a subscriber is
+// slow or a transport reconnects.
+//
+// Three transports are available: Redis pub/sub, Postgres LISTEN/NOTIFY,
+// and an in-process bus that only reaches the same process.
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// Message is one published message.
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// Bus publishes messages to, and receives them from, every process
+// connected to the same transport.
+type Bus interface {
+	// Publish sends payload to the subscribers of topic in every process,
+	// including this one.
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe returns the messages published to topic until ctx is
+	// done, when the channel is closed.
+	Subscribe(ctx context.Context, topic string) <-chan Message
+	// Close stops receiving messages and releases the transport.
+	Close() error
+}
+
+// ErrPayloadTooLarge is returned by Publish when a payload exceeds what
+// the transport can carry.
+var ErrPayloadTooLarge = errors.New("events: payload too large")
+
+// subscriberBuffer is how many undelivered messages a subscriber holds
+// before further messages to it are dropped.
+const subscriberBuffer = 16
+
+// JobTopic is the topic of a job's timeline; a message is published for
+// every event recorded for the job.
+func JobTopic(jobID uuid.UUID) string {
+	return "job:" + jobID.String()
+}
+
+// JobQueuedTopic wakes workers when a job becomes pending. The payload is
+// the job's pool, or empty when any pool may have work.
+const JobQueuedTopic = "jobs:queued"
+
+var defaultBus atomic.Pointer[Bus]
+
+// SetDefault sets the bus used by Publish and Subscribe. Until it is
+// called they use an in-process bus.
+func SetDefault(b Bus) {
+	defaultBus.Store(&b)
+}
+
+var fallbackOnce sync.Once
+var fallbackBus Bus
+
+// Default returns the bus set by SetDefault, or an in-process bus.
+func Default() Bus {
+	if b := defaultBus.Load(); b != nil && *b != nil {
+		return *b
+	}
+	fallbackOnce.Do(func() { fallbackBus = NewMemory() })
+	return fallbackBus
+}
+
+// Shared reports whether the default bus reaches other processes. When it
+// does not, a process waiting on work done by another one must poll.
+func Shared() bool {
+	_, local := Default().(*memoryBus)
+	return !local
+}
+
+// Publish publishes payload to topic on the default bus.
+func Publish(ctx context.Context, topic string, payload []byte) error {
+	return Default().Publish(ctx, topic, payload)
+}
+
+// Subscribe subscribes to topic on the default bus.
+func Subscribe(ctx context.Context, topic string) <-chan Message {
+	return Default().Subscribe(ctx, topic)
+}
+
+// hub delivers messages received by a transport to the subscribers in
+// this process.
+type hub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan Message]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[string]map[chan Message]struct{})}
+}
+
+func (h *hub) subscribe(ctx context.Context, topic string) <-chan Message {
+	ch := make(chan Message, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs[topic] == nil {
+		h.subs[topic] = make(map[chan Message]struct{})
+	}
+	h.subs[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		// closeAll may have closed the channel already.
+		if _, ok := h.subs[topic][ch]; !ok {
+			return
+		}
+		delete(h.subs[topic], ch)
+		if len(h.subs[topic]) == 0 {
+			delete(h.subs, topic)
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// dispatch delivers m to its topic's subscribers, dropping it for any
+// subscriber whose buffer is full.
+func (h *hub) dispatch(m Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[m.Topic] {
+		select {
+		case ch <- m:
+		default:
+		}
+	}
+}
+
+// closeAll closes every subscription.
+func (h *hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for topic, subs := range h.subs {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(h.subs, topic)
+	}
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func receive(t *testing.T, ch <-chan Message) (Message, bool) {
+	t.Helper()
+	select {
+	case m, ok := <-ch:
+		return m, ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a message")
+		return Message{}, false
+	}
+}
+
+func TestMemoryBus(t *testing.T) {
+	bus := NewMemory()
+	ctx, cancel := context.WithCancel(context.Background())
+	topic := JobTopic(uuid.New())
+
+	msgs := bus.Subscribe(ctx, topic)
+	other := bus.Subscribe(ctx, "other")
+
+	if err := bus.Publish(context.Background(), topic, []byte(`{"type":"claimed"}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	m, ok := receive(t, msgs)
+	if !ok || m.Topic != topic || string(m.Payload) != `{"type":"claimed"}` {
+		t.Fatalf("unexpected message: %+v (open=%v)", m, ok)
+	}
+	select {
+	case m := <-other:
+		t.Fatalf("expected no message on another topic, got %+v", m)
+	default:
+	}
+
+	cancel()
+	if _, ok := receive(t, msgs); ok {
+		t.Fatal("expected the subscription to close with its context")
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestMemoryBus_DropsForSlowSubscribers(t *testing.T) {
+	bus := NewMemory()
+	defer bus.Close()
+	msgs := bus.Subscribe(context.Background(), "t")
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		_ = bus.Publish(context.Background(), "t", nil)
+	}
+	if len(msgs) != subscriberBuffer {
+		t.Fatalf("expected %d buffered messages, got %d", subscriberBuffer, len(msgs))
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	data, err := encodeMessage("job:1", []byte("hi"))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	m, ok := decodeMessage(data)
+	if !ok || m.Topic != "job:1" || string(m.Payload) != "hi" {
+		t.Fatalf("unexpected round trip: %+v", m)
+	}
+	if _, ok := decodeMessage([]byte(`{"p":"aGk="}`)); ok {
+		t.Fatal("expected a message without a topic to be ignored")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if bus, err := New(context.Background(), Options{Transport: TransportMemory}); err != nil || bus == nil {
+		t.Fatalf("memory transport: %v", err)
+	}
+	if _, err := New(context.Background(), Options{Transport: "kafka"}); err == nil {
+		t.Fatal("expected an unknown transport to fail")
+	}
+	if _, err := New(context.Background(), Options{Transport: TransportRedis}); err == nil {
+		t.Fatal("expected the redis transport to need a URL")
+	}
+	if _, err := New(context.Background(), Options{}); err == nil {
+		t.Fatal("expected the default transport to need a database")
+	}
+}
+
+// sharedBus stands in for a bus that reaches other processes.
+type sharedBus struct{ Bus }
+
+func TestShared(t *testing.T) {
+	if Shared() {
+		t.Fatal("expected the in-process fallback bus not to be shared")
+	}
+	SetDefault(sharedBus{NewMemory()})
+	defer SetDefault(NewMemory())
+	if !Shared() {
+		t.Fatal("expected a cross-process bus to be shared")
+	}
+}
@@ -0,0 +1,28 @@
+package events
+
+import "context"
+
+// memoryBus delivers messages within this process only.
+type memoryBus struct {
+	hub *hub
+}
+
+// NewMemory returns a bus that only reaches subscribers in this process,
+// for single-process deployments and tests.
+func NewMemory() Bus {
+	return &memoryBus{hub: newHub()}
+}
+
+func (b *memoryBus) Publish(_ context.Context, topic string, payload []byte) error {
+	b.hub.dispatch(Message{Topic: topic, Payload: payload})
+	return nil
+}
+
+func (b *memoryBus) Subscribe(ctx context.Context, topic string) <-chan Message {
+	return b.hub.subscribe(ctx, topic)
+}
+
+func (b *memoryBus) Close() error {
+	b.hub.closeAll()
+	return nil
+}
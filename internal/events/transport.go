@@ -0,0 +1,254 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// Transports accepted by New.
+const (
+	TransportRedis    = "redis"
+	TransportPostgres = "postgres"
+	TransportMemory   = "memory"
+)
+
+// Options selects and configures the transport built by New.
+type Options struct {
+	// Transport is TransportRedis, TransportPostgres or TransportMemory.
+	// When empty, Redis is used if RedisURL is set and reachable, and
+	// Postgres otherwise.
+	Transport string
+	RedisURL  string
+	// DB publishes Postgres notifications; DSN opens the dedicated
+	// connection that listens for them.
+	DB  *sql.DB
+	DSN string
+}
+
+// New builds the bus for opts.
+func New(ctx context.Context, opts Options) (Bus, error) {
+	switch strings.TrimSpace(opts.Transport) {
+	case TransportRedis:
+		return NewRedis(ctx, opts.RedisURL)
+	case TransportPostgres:
+		return NewPostgres(ctx, opts.DB, opts.DSN)
+	case TransportMemory:
+		return NewMemory(), nil
+	case "":
+		if opts.RedisURL != "" {
+			bus, err := NewRedis(ctx, opts.RedisURL)
+			if err == nil {
+				return bus, nil
+			}
+			pg, pgErr := NewPostgres(ctx, opts.DB, opts.DSN)
+			if pgErr != nil {
+				return nil, errors.Join(err, pgErr)
+			}
+			return pg, nil
+		}
+		return NewPostgres(ctx, opts.DB, opts.DSN)
+	default:
+		return nil, fmt.Errorf("events: unknown transport %q", opts.Transport)
+	}
+}
+
+// envelope is how a Message travels on the one Redis or Postgres channel
+// every process listens on.
+type envelope struct {
+	Topic   string `json:"t"`
+	Payload []byte `json:"p,omitempty"`
+}
+
+func encodeMessage(topic string, payload []byte) ([]byte, error) {
+	return json.Marshal(envelope{Topic: topic, Payload: payload})
+}
+
+func decodeMessage(data []byte) (Message, bool) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Topic == "" {
+		return Message{}, false
+	}
+	return Message{Topic: env.Topic, Payload: env.Payload}, true
+}
+
+// redisChannel is the Redis pub/sub channel all messages travel on.
+const redisChannel = "raito:events"
+
+// redisBus fans out messages with Redis pub/sub. The client reconnects
+// and resubscribes on its own after connection errors.
+type redisBus struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+	hub    *hub
+	done   chan struct{}
+}
+
+// NewRedis connects to the Redis server at url and subscribes to the
+// events channel.
+func NewRedis(ctx context.Context, url string) (Bus, error) {
+	if strings.TrimSpace(url) == "" {
+		return nil, errors.New("events: redis transport needs redis.url")
+	}
+	opt, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("events: redis url: %w", err)
+	}
+	client := redis.NewClient(opt)
+	pubsub := client.Subscribe(ctx, redisChannel)
+	// Wait for the subscription to be confirmed, so a bad address fails
+	// here rather than silently dropping messages.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		_ = client.Close()
+		return nil, fmt.Errorf("events: redis subscribe: %w", err)
+	}
+
+	b := &redisBus{client: client, pubsub: pubsub, hub: newHub(), done: make(chan struct{})}
+	go b.run()
+	return b, nil
+}
+
+func (b *redisBus) run() {
+	defer close(b.done)
+	for msg := range b.pubsub.Channel() {
+		if m, ok := decodeMessage([]byte(msg.Payload)); ok {
+			b.hub.dispatch(m)
+		}
+	}
+}
+
+func (b *redisBus) Publish(ctx context.Context, topic string, payload []byte) error {
+	data, err := encodeMessage(topic, payload)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, redisChannel, data).Err()
+}
+
+func (b *redisBus) Subscribe(ctx context.Context, topic string) <-chan Message {
+	return b.hub.subscribe(ctx, topic)
+}
+
+func (b *redisBus) Close() error {
+	err := b.pubsub.Close()
+	<-b.done
+	b.hub.closeAll()
+	return errors.Join(err, b.client.Close())
+}
+
+const (
+	// pgChannel is the Postgres notification channel all messages travel
+	// on.
+	pgChannel = "raito_events"
+	// pgMaxPayload keeps encoded messages under the 8000-byte NOTIFY
+	// payload limit.
+	pgMaxPayload = 7900
+	// pgMaxReconnectDelay caps the wait between attempts to re-open a
+	// lost listening connection.
+	pgMaxReconnectDelay = 30 * time.Second
+)
+
+// pgBus fans out messages with Postgres LISTEN/NOTIFY. Notifications are
+// published on the shared pool and received on one dedicated connection,
+// which is re-opened when it drops.
+type pgBus struct {
+	db     *sql.DB
+	dsn    string
+	hub    *hub
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPostgres listens for notifications on a dedicated connection to dsn
+// and publishes through db.
+func NewPostgres(ctx context.Context, db *sql.DB, dsn string) (Bus, error) {
+	if db == nil || strings.TrimSpace(dsn) == "" {
+		return nil, errors.New("events: postgres transport needs database.dsn")
+	}
+	conn, err := pgListen(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	listenCtx, cancel := context.WithCancel(context.Background())
+	b := &pgBus{db: db, dsn: dsn, hub: newHub(), cancel: cancel, done: make(chan struct{})}
+	go b.listen(listenCtx, conn)
+	return b, nil
+}
+
+func pgListen(ctx context.Context, dsn string) (*pgx.Conn, error) {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("events: postgres connect: %w", err)
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pgChannel); err != nil {
+		_ = conn.Close(context.Background())
+		return nil, fmt.Errorf("events: postgres listen: %w", err)
+	}
+	return conn, nil
+}
+
+func (b *pgBus) listen(ctx context.Context, conn *pgx.Conn) {
+	defer close(b.done)
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err == nil {
+			if m, ok := decodeMessage([]byte(n.Payload)); ok {
+				b.hub.dispatch(m)
+			}
+			continue
+		}
+		_ = conn.Close(context.Background())
+		if conn = b.reconnect(ctx); conn == nil {
+			return
+		}
+	}
+}
+
+// reconnect re-opens the listening connection with backoff. It returns
+// nil once ctx is done.
+func (b *pgBus) reconnect(ctx context.Context) *pgx.Conn {
+	delay := time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		if conn, err := pgListen(ctx, b.dsn); err == nil {
+			return conn
+		}
+		delay = min(delay*2, pgMaxReconnectDelay)
+	}
+}
+
+func (b *pgBus) Publish(ctx context.Context, topic string, payload []byte) error {
+	data, err := encodeMessage(topic, payload)
+	if err != nil {
+		return err
+	}
+	if len(data) > pgMaxPayload {
+		return ErrPayloadTooLarge
+	}
+	_, err = b.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", pgChannel, string(data))
+	return err
+}
+
+func (b *pgBus) Subscribe(ctx context.Context, topic string) <-chan Message {
+	return b.hub.subscribe(ctx, topic)
+}
+
+func (b *pgBus) Close() error {
+	b.cancel()
+	<-b.done
+	b.hub.closeAll()
+	return nil
+}
@@ -0,0 +1,197 @@
+package extract
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// presets are the built-in extraction schemas selectable with
+// schemaPreset. They are plain JSON Schema so they pass the same
+// validation as caller-provided schemas.
+var presets = map[string]string{
+	"product": `{
+		"type": "object",
+		"description": "A product offered for sale on the page.",
+		"properties": {
+			"name": {"type": "string", "description": "Product name as shown to shoppers."},
+			"brand": {"type": "string"},
+			"sku": {"type": "string", "description": "Seller SKU or model number."},
+			"gtin": {"type": "string", "description": "GTIN, UPC, EAN or ISBN when present."},
+			"description": {"type": "string"},
+			"price": {"type": "number", "description": "Current selling price without currency symbol."},
+			"currency": {"type": "string", "description": "ISO 4217 currency code, e.g. USD."},
+			"originalPrice": {"type": "number", "description": "Price before any discount."},
+			"availability": {"type": "string", "enum": ["in_stock", "out_of_stock", "preorder", "backorder", "discontinued", "unknown"]},
+			"condition": {"type": "string", "enum": ["new", "used", "refurbished", "unknown"]},
+			"category": {"type": "string"},
+			"images": {"type": "array", "items": {"type": "string", "description": "Absolute image URL."}},
+			"rating": {
+				"type": "object",
+				"properties": {
+					"value": {"type": "number"},
+					"scale": {"type": "number", "description": "Best possible rating, e.g. 5."},
+					"count": {"type": "integer", "description": "Number of ratings or reviews."}
+				}
+			},
+			"variants": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"sku": {"type": "string"},
+						"price": {"type": "number"},
+						"availability": {"type": "string"}
+					}
+				}
+			}
+		},
+		"required": ["name"]
+	}`,
+	"article": `{
+		"type": "object",
+		"description": "A news article, blog post or other editorial content.",
+		"properties": {
+			"headline": {"type": "string"},
+			"subheadline": {"type": "string"},
+			"authors": {"type": "array", "items": {"type": "string"}},
+			"publishedAt": {"type": "string", "description": "Publication date in ISO 8601."},
+			"updatedAt": {"type": "string", "description": "Last update date in ISO 8601."},
+			"publisher": {"type": "string"},
+			"section": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"summary": {"type": "string", "description": "Two or three sentence summary of the article."},
+			"body": {"type": "string", "description": "Main article text without navigation, ads or comments."},
+			"image": {"type": "string", "description": "Absolute URL of the lead image."},
+			"language": {"type": "string", "description": "BCP 47 language tag."}
+		},
+		"required": ["headline"]
+	}`,
+	"job-posting": `{
+		"type": "object",
+		"description": "A job opening.",
+		"properties": {
+			"title": {"type": "string"},
+			"company": {"type": "string"},
+			"locations": {"type": "array", "items": {"type": "string"}},
+			"remote": {"type": "string", "enum": ["onsite", "hybrid", "remote", "unknown"]},
+			"employmentType": {"type": "string", "enum": ["full_time", "part_time", "contract", "temporary", "internship", "unknown"]},
+			"department": {"type": "string"},
+			"seniority": {"type": "string"},
+			"salary": {
+				"type": "object",
+				"properties": {
+					"min": {"type": "number"},
+					"max": {"type": "number"},
+					"currency": {"type": "string", "description": "ISO 4217 currency code."},
+					"period": {"type": "string", "enum": ["hour", "day", "week", "month", "year"]}
+				}
+			},
+			"postedAt": {"type": "string", "description": "Posting date in ISO 8601."},
+			"validThrough": {"type": "string", "description": "Application deadline in ISO 8601."},
+			"description": {"type": "string"},
+			"requirements": {"type": "array", "items": {"type": "string"}},
+			"benefits": {"type": "array", "items": {"type": "string"}},
+			"applyUrl": {"type": "string", "description": "Absolute URL to apply."}
+		},
+		"required": ["title"]
+	}`,
+	"event": `{
+		"type": "object",
+		"description": "An event such as a concert, conference, meetup or webinar.",
+		"properties": {
+			"name": {"type": "string"},
+			"description": {"type": "string"},
+			"startDate": {"type": "string", "description": "Start date and time in ISO 8601, with offset when known."},
+			"endDate": {"type": "string", "description": "End date and time in ISO 8601."},
+			"timezone": {"type": "string", "description": "IANA time zone, e.g. Europe/Berlin."},
+			"attendanceMode": {"type": "string", "enum": ["in_person", "online", "mixed", "unknown"]},
+			"status": {"type": "string", "enum": ["scheduled", "cancelled", "postponed", "rescheduled", "unknown"]},
+			"venue": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"address": {"type": "string"},
+					"city": {"type": "string"},
+					"country": {"type": "string"}
+				}
+			},
+			"organizer": {"type": "string"},
+			"performers": {"type": "array", "items": {"type": "string"}},
+			"offers": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"price": {"type": "number"},
+						"currency": {"type": "string"},
+						"url": {"type": "string"}
+					}
+				}
+			},
+			"url": {"type": "string", "description": "Absolute URL of the event page or registration."}
+		},
+		"required": ["name", "startDate"]
+	}`,
+	"company-profile": `{
+		"type": "object",
+		"description": "A company or organization described by the page.",
+		"properties": {
+			"name": {"type": "string"},
+			"legalName": {"type": "string"},
+			"description": {"type": "string", "description": "What the company does, in one or two sentences."},
+			"website": {"type": "string"},
+			"industry": {"type": "string"},
+			"foundedYear": {"type": "integer"},
+			"headquarters": {"type": "string"},
+			"employeeCount": {"type": "string", "description": "Headcount or range as stated, e.g. 51-200."},
+			"products": {"type": "array", "items": {"type": "string"}},
+			"leadership": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"title": {"type": "string"}
+					}
+				}
+			},
+			"contact": {
+				"type": "object",
+				"properties": {
+					"email": {"type": "string"},
+					"phone": {"type": "string"},
+					"address": {"type": "string"}
+				}
+			},
+			"socialProfiles": {"type": "array", "items": {"type": "string", "description": "Absolute profile URL."}}
+		},
+		"required": ["name"]
+	}`,
+}
+
+// Preset returns a fresh copy of the built-in schema named name, so
+// callers may modify it.
+func Preset(name string) (map[string]any, bool) {
+	raw, ok := presets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, false
+	}
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return nil, false
+	}
+	return schema, true
+}
+
+// PresetNames returns the built-in schema names in sorted order.
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
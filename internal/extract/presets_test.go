@@ -0,0 +1,58 @@
+package extract
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPresets_AreValidObjectSchemas(t *testing.T) {
+	want := []string{"article", "company-profile", "event", "job-posting", "product"}
+	if got := PresetNames(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("PresetNames() = %v, want %v", got, want)
+	}
+
+	for _, name := range PresetNames() {
+		schema, ok := Preset(name)
+		if !ok {
+			t.Fatalf("%s: preset does not parse", name)
+		}
+		if schema["type"] != "object" {
+			t.Fatalf("%s: expected an object schema, got %v", name, schema["type"])
+		}
+		props, ok := schema["properties"].(map[string]any)
+		if !ok || len(props) == 0 {
+			t.Fatalf("%s: expected properties", name)
+		}
+		required, _ := schema["required"].([]any)
+		if len(required) == 0 {
+			t.Fatalf("%s: expected at least one required property", name)
+		}
+		for _, r := range required {
+			if _, ok := props[r.(string)]; !ok {
+				t.Fatalf("%s: required property %q is not defined", name, r)
+			}
+		}
+		for prop, def := range props {
+			if m, ok := def.(map[string]any); !ok || m["type"] == nil {
+				t.Fatalf("%s: property %q has no type", name, prop)
+			}
+		}
+	}
+}
+
+func TestPreset_ReturnsCopies(t *testing.T) {
+	a, ok := Preset(" Product ")
+	if !ok {
+		t.Fatalf("expected preset lookup to ignore case and spaces")
+	}
+	a["properties"].(map[string]any)["name"] = "changed"
+
+	b, _ := Preset("product")
+	if _, ok := b["properties"].(map[string]any)["name"].(map[string]any); !ok {
+		t.Fatalf("expected a fresh copy of the preset")
+	}
+
+	if _, ok := Preset("recipe"); ok {
+		t.Fatalf("expected unknown presets to be rejected")
+	}
+}
@@ -0,0 +1,344 @@
+package extract
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ConfidenceField is the extra LLM field that asks the model to rate its
+// own answer per top-level key of the extracted JSON.
+const ConfidenceField = "fieldConfidence"
+
+// ConfidenceFieldDescription describes ConfidenceField to the model.
+const ConfidenceFieldDescription = "Object mapping each top-level key of json to a number from 0 to 1: how confident you are that the value is correct and stated on the page."
+
+const (
+	// maxProvenanceFields bounds how many leaf values get an entry, so
+	// long lists do not bloat the result.
+	maxProvenanceFields = 200
+	// snippetContext is the number of characters kept on each side of a
+	// match in FieldProvenance.Snippet.
+	snippetContext = 60
+)
+
+// Match kinds reported in FieldProvenance.Match, with the heuristic
+// confidence each one carries.
+const (
+	MatchExact      = "exact"
+	MatchNormalized = "normalized"
+	MatchNumber     = "number"
+	MatchPartial    = "partial"
+	MatchNone       = "none"
+)
+
+var matchConfidence = map[string]float64{
+	MatchExact:      0.9,
+	MatchNumber:     0.85,
+	MatchNormalized: 0.75,
+	MatchPartial:    0.5,
+	MatchNone:       0.2,
+}
+
+// FieldProvenance describes how well one extracted value is supported by
+// the page markdown.
+type FieldProvenance struct {
+	// Confidence is between 0 and 1. With a model-reported confidence it
+	// is the mean of that and the heuristic; otherwise the heuristic.
+	Confidence float64 `json:"confidence"`
+	// ModelConfidence is the model's own rating of the top-level key the
+	// value belongs to, when it gave one.
+	ModelConfidence *float64 `json:"modelConfidence,omitempty"`
+	// Match is how the value was found in the markdown.
+	Match string `json:"match"`
+	// Snippet is the markdown around the match.
+	Snippet string `json:"snippet,omitempty"`
+	// Offset and Length locate the match in the markdown, in characters.
+	Offset *int `json:"offset,omitempty"`
+	Length int  `json:"length,omitempty"`
+}
+
+// Provenance scores every leaf value of extracted against markdown. Keys
+// are paths such as "price", "rating.value" or "variants[0].sku". Empty
+// values are skipped. modelConfidence is the model's ConfidenceField
+// reply and may be nil.
+func Provenance(extracted map[string]any, markdown string, modelConfidence map[string]any) map[string]FieldProvenance {
+	doc := newSearchText(markdown)
+	out := map[string]FieldProvenance{}
+
+	keys := make([]string, 0, len(extracted))
+	for k := range extracted {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		model, hasModel := confidenceValue(modelConfidence[key])
+		walkLeaves(key, extracted[key], func(path string, value any) bool {
+			if len(out) >= maxProvenanceFields {
+				return false
+			}
+			p := doc.locate(value)
+			p.Confidence = matchConfidence[p.Match]
+			if hasModel {
+				m := model
+				p.ModelConfidence = &m
+				p.Confidence = (model + p.Confidence) / 2
+			}
+			p.Confidence = math.Round(p.Confidence*100) / 100
+			out[path] = p
+			return true
+		})
+	}
+	return out
+}
+
+// confidenceValue reads a model-reported confidence, clamped to [0, 1].
+func confidenceValue(v any) (float64, bool) {
+	var f float64
+	switch n := v.(type) {
+	case float64:
+		f = n
+	case int:
+		f = float64(n)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, false
+		}
+		f = parsed
+	default:
+		return 0, false
+	}
+	if math.IsNaN(f) {
+		return 0, false
+	}
+	// Some models answer in percent.
+	if f > 1 && f <= 100 {
+		f /= 100
+	}
+	return math.Max(0, math.Min(1, f)), true
+}
+
+// walkLeaves calls fn for each non-empty scalar under value, stopping when
+// fn returns false.
+func walkLeaves(path string, value any, fn func(path string, value any) bool) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !walkLeaves(path+"."+k, v[k], fn) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		for i, item := range v {
+			if !walkLeaves(path+"["+strconv.Itoa(i)+"]", item, fn) {
+				return false
+			}
+		}
+		return true
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return true
+		}
+	}
+	return fn(path, value)
+}
+
+// searchText is markdown prepared for matching. folded is the text
+// lowercased with whitespace runs and markdown emphasis removed; pos maps
+// each folded rune back to its rune index in the original.
+type searchText struct {
+	original string
+	runes    []rune
+	folded   string
+	pos      []int
+}
+
+func newSearchText(markdown string) *searchText {
+	t := &searchText{original: markdown, runes: []rune(markdown)}
+	var b strings.Builder
+	space := true
+	for i, r := range t.runes {
+		switch {
+		case unicode.IsSpace(r):
+			if space {
+				continue
+			}
+			space = true
+			b.WriteRune(' ')
+		case r == '*' || r == '_' || r == '`':
+			continue
+		default:
+			space = false
+			b.WriteRune(unicode.ToLower(r))
+		}
+		t.pos = append(t.pos, i)
+	}
+	t.folded = b.String()
+	return t
+}
+
+// fold applies the searchText normalization to a value.
+func fold(s string) string {
+	return newSearchText(strings.TrimSpace(s)).folded
+}
+
+// locate finds value in the text and fills in the match fields.
+func (t *searchText) locate(value any) FieldProvenance {
+	switch v := value.(type) {
+	case string:
+		return t.locateString(strings.TrimSpace(v))
+	case float64:
+		for _, form := range numberForms(v) {
+			if i := indexNumber(t.original, form); i >= 0 {
+				return t.provenance(MatchNumber, utf8.RuneCountInString(t.original[:i]), utf8.RuneCountInString(form))
+			}
+		}
+	case bool:
+		// Booleans are inferred rather than quoted, so they carry no
+		// location.
+	default:
+		return t.locateString(strings.TrimSpace(toString(v)))
+	}
+	return FieldProvenance{Match: MatchNone}
+}
+
+func (t *searchText) locateString(s string) FieldProvenance {
+	if s == "" {
+		return FieldProvenance{Match: MatchNone}
+	}
+	if i := strings.Index(t.original, s); i >= 0 {
+		return t.provenance(MatchExact, utf8.RuneCountInString(t.original[:i]), utf8.RuneCountInString(s))
+	}
+	needle := fold(s)
+	if start, end, ok := t.findFolded(needle); ok {
+		return t.provenance(MatchNormalized, start, end-start)
+	}
+
+	// Partial: at least half of the value's significant words appear;
+	// the first one found anchors the snippet.
+	words := strings.Fields(needle)
+	var significant, found int
+	first, firstEnd := -1, -1
+	for _, w := range words {
+		if utf8.RuneCountInString(w) < 3 {
+			continue
+		}
+		significant++
+		if start, end, ok := t.findFolded(w); ok {
+			found++
+			if first < 0 {
+				first, firstEnd = start, end
+			}
+		}
+	}
+	if significant > 1 && found*2 >= significant {
+		return t.provenance(MatchPartial, first, firstEnd-first)
+	}
+	return FieldProvenance{Match: MatchNone}
+}
+
+// findFolded returns the original rune range of needle in the folded text.
+func (t *searchText) findFolded(needle string) (int, int, bool) {
+	if needle == "" {
+		return 0, 0, false
+	}
+	i := strings.Index(t.folded, needle)
+	if i < 0 {
+		return 0, 0, false
+	}
+	startRune := utf8.RuneCountInString(t.folded[:i])
+	endRune := startRune + utf8.RuneCountInString(needle) - 1
+	return t.pos[startRune], t.pos[endRune] + 1, true
+}
+
+func (t *searchText) provenance(match string, offset, length int) FieldProvenance {
+	from := max(0, offset-snippetContext)
+	to := min(len(t.runes), offset+length+snippetContext)
+	snippet := strings.Join(strings.Fields(string(t.runes[from:to])), " ")
+	return FieldProvenance{
+		Match:   match,
+		Snippet: snippet,
+		Offset:  &offset,
+		Length:  length,
+	}
+}
+
+// numberForms returns the ways n is commonly written on a page.
+func numberForms(n float64) []string {
+	if n == math.Trunc(n) && math.Abs(n) < 1e15 {
+		i := int64(n)
+		plain := strconv.FormatInt(i, 10)
+		forms := []string{plain}
+		if grouped := groupThousands(plain, ','); grouped != plain {
+			forms = append(forms, grouped, groupThousands(plain, '.'))
+		}
+		return forms
+	}
+	plain := strconv.FormatFloat(n, 'f', -1, 64)
+	forms := []string{plain, strings.Replace(plain, ".", ",", 1)}
+	if fixed := strconv.FormatFloat(n, 'f', 2, 64); fixed != plain {
+		forms = append(forms, fixed, strings.Replace(fixed, ".", ",", 1))
+	}
+	return forms
+}
+
+// groupThousands inserts sep between groups of three digits.
+func groupThousands(digits string, sep byte) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, d := range []byte(digits) {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(sep)
+		}
+		b.WriteByte(d)
+	}
+	return sign + b.String()
+}
+
+// indexNumber is strings.Index for a number, skipping matches that are
+// part of a longer number.
+func indexNumber(s, form string) int {
+	for from := 0; from <= len(s)-len(form); {
+		i := strings.Index(s[from:], form)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		end := i + len(form)
+		if (i == 0 || !isDigit(s[i-1])) && (end == len(s) || !isDigit(s[end])) {
+			return i
+		}
+		from = i + 1
+	}
+	return -1
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+func toString(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case int:
+		return strconv.Itoa(s)
+	case int64:
+		return strconv.FormatInt(s, 10)
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package extract
+
+import (
+	"strings"
+	"testing"
+)
+
+const productMarkdown = `# Acme **Trail** Runner
+
+Lightweight running shoe for rough terrain.
+
+Price: $1,299.00 — was 1499
+
+Brand: ACME   Outdoor`
+
+func TestProvenance_Matches(t *testing.T) {
+	extracted := map[string]any{
+		"name":          "Acme Trail Runner",
+		"description":   "Lightweight running shoe for rough terrain.",
+		"price":         float64(1299),
+		"originalPrice": float64(1499),
+		"brand":         "acme outdoor",
+		"category":      "Trail running shoes for hiking",
+		"color":         "Magenta",
+		"inStock":       true,
+		"sku":           "",
+		"rating":        map[string]any{"count": float64(12)},
+		"tags":          []any{"running", nil},
+	}
+	got := Provenance(extracted, productMarkdown, map[string]any{"name": 0.5, "price": "95"})
+
+	cases := map[string]string{
+		"name":          MatchNormalized,
+		"description":   MatchExact,
+		"price":         MatchNumber,
+		"originalPrice": MatchNumber,
+		"brand":         MatchNormalized,
+		"category":      MatchPartial,
+		"color":         MatchNone,
+		"inStock":       MatchNone,
+		"rating.count":  MatchNone,
+		"tags[0]":       MatchExact,
+	}
+	for path, want := range cases {
+		p, ok := got[path]
+		if !ok {
+			t.Fatalf("%s: missing provenance", path)
+		}
+		if p.Match != want {
+			t.Fatalf("%s: match = %q, want %q", path, p.Match, want)
+		}
+	}
+	if _, ok := got["sku"]; ok {
+		t.Fatalf("expected empty values to be skipped")
+	}
+	if len(got) != len(cases) {
+		t.Fatalf("expected %d entries, got %d", len(cases), len(got))
+	}
+
+	desc := got["description"]
+	if desc.Confidence != 0.9 || desc.ModelConfidence != nil || desc.Offset == nil {
+		t.Fatalf("unexpected description provenance: %+v", desc)
+	}
+	if runes := []rune(productMarkdown); string(runes[*desc.Offset:*desc.Offset+desc.Length]) != extracted["description"] {
+		t.Fatalf("offset does not point at the value: %+v", desc)
+	}
+
+	name := got["name"]
+	if name.ModelConfidence == nil || *name.ModelConfidence != 0.5 || name.Confidence != 0.63 {
+		t.Fatalf("expected the model confidence to be averaged in, got %+v", name)
+	}
+	if !strings.Contains(name.Snippet, "Acme **Trail** Runner") {
+		t.Fatalf("unexpected snippet %q", name.Snippet)
+	}
+
+	price := got["price"]
+	if price.ModelConfidence == nil || *price.ModelConfidence != 0.95 || price.Confidence != 0.9 {
+		t.Fatalf("expected a percent model confidence to be scaled, got %+v", price)
+	}
+}
+
+func TestProvenance_NumbersNeedWholeMatches(t *testing.T) {
+	got := Provenance(map[string]any{"count": float64(5)}, "Ships in 15 days", nil)
+	if got["count"].Match != MatchNone {
+		t.Fatalf("expected 5 not to match inside 15, got %+v", got["count"])
+	}
+}
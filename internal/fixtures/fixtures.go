@@ -0,0 +1,170 @@
+// Package fixtures serves a small website for tests and compares test
+// output with golden files, so scraper, crawler and services behavior can
+// be pinned down without reaching the internet.
+//
+// The fixture site lives in site/. Every page is served at its path
+// without the .html extension, and {{BASE}} in any file is replaced with
+// the server's URL. robots.txt points at sitemap.xml, a plain urlset, and
+// sitemap-index.xml is a sitemap index over it. On top of the files, the
+// site has:
+//
+//   - /redirect, a 301 to /about, and /redirect-chain, a 302 to /redirect
+//   - /slow, which answers after 1.5s, or after ?ms= milliseconds
+//   - /error, which answers 500
+//   - /spa/ and everything under it, which serve the same client-side app
+//
+// Everything else is a 404.
+package fixtures
+
+import (
+	"embed"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowPageDelay is how long /slow takes without ?ms=.
+const slowPageDelay = 1500 * time.Millisecond
+
+//go:embed site
+var siteFiles embed.FS
+
+// Site is a running fixture site.
+type Site struct {
+	// URL is the site's base URL, without a trailing slash.
+	URL string
+	// Requests records the path of every request, in order.
+	Requests *RequestLog
+
+	server *httptest.Server
+}
+
+// NewSite starts the fixture site and stops it when the test ends.
+func NewSite(t testing.TB) *Site {
+	t.Helper()
+	s := &Site{Requests: &RequestLog{}}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.URL = s.server.URL
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// Normalize replaces the site's URL, which changes with every run, in
+// output with http://fixtures.test so it can be compared with a golden
+// file. URLs that lost the port, as the markdown converter's do, are
+// replaced too.
+func (s *Site) Normalize(output string) string {
+	output = strings.ReplaceAll(output, s.URL, "http://fixtures.test")
+	if u, err := url.Parse(s.URL); err == nil {
+		output = strings.ReplaceAll(output, "http://"+u.Hostname(), "http://fixtures.test")
+	}
+	return output
+}
+
+func (s *Site) serve(w http.ResponseWriter, r *http.Request) {
+	s.Requests.add(r.URL.Path)
+
+	switch r.URL.Path {
+	case "/redirect":
+		http.Redirect(w, r, "/about", http.StatusMovedPermanently)
+		return
+	case "/redirect-chain":
+		http.Redirect(w, r, "/redirect", http.StatusFound)
+		return
+	case "/error":
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	case "/slow":
+		delay := slowPageDelay
+		if ms, err := strconv.Atoi(r.URL.Query().Get("ms")); err == nil && ms >= 0 {
+			delay = time.Duration(ms) * time.Millisecond
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+
+	name, ok := siteFile(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := fs.ReadFile(siteFiles, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if strings.HasPrefix(contentType, "text/") || path.Ext(name) == ".xml" {
+		body = []byte(strings.ReplaceAll(string(body), "{{BASE}}", s.URL))
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(body)
+}
+
+// siteFile returns the file in site/ served at urlPath.
+func siteFile(urlPath string) (string, bool) {
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "spa" || strings.HasPrefix(name, "spa/") {
+		return "site/spa/index.html", true
+	}
+	var candidates []string
+	if name == "" || strings.HasSuffix(urlPath, "/") {
+		candidates = append(candidates, path.Join(name, "index.html"))
+	} else if ext := path.Ext(name); ext == ".html" {
+		// Pages are only served without their extension.
+		return "", false
+	} else if ext != "" {
+		candidates = append(candidates, name)
+	} else {
+		candidates = append(candidates, name+".html")
+	}
+	for _, c := range candidates {
+		if info, err := fs.Stat(siteFiles, "site/"+c); err == nil && !info.IsDir() {
+			return "site/" + c, true
+		}
+	}
+	return "", false
+}
+
+// RequestLog records the paths a fixture site was asked for.
+type RequestLog struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (l *RequestLog) add(p string) {
+	l.mu.Lock()
+	l.paths = append(l.paths, p)
+	l.mu.Unlock()
+}
+
+// Paths returns the requested paths in order.
+func (l *RequestLog) Paths() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.paths...)
+}
+
+// Count returns how often p was requested.
+func (l *RequestLog) Count(p string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, q := range l.paths {
+		if q == p {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package fixtures
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSite(t *testing.T) {
+	site := NewSite(t)
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	get := func(p string) (*http.Response, string) {
+		t.Helper()
+		resp, err := client.Get(site.URL + p)
+		if err != nil {
+			t.Fatalf("GET %s: %v", p, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	cases := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/", 200, "<title>Fixture Home</title>"},
+		{"/about", 200, `"url": "` + site.URL + `/"`},
+		{"/blog/", 200, "<title>Blog</title>"},
+		{"/blog/post-1", 200, "First Post"},
+		{"/spa/settings", 200, `<div id="app"></div>`},
+		{"/robots.txt", 200, "Sitemap: " + site.URL + "/sitemap.xml"},
+		{"/sitemap.xml", 200, "<loc>" + site.URL + "/about</loc>"},
+		{"/sitemap-index.xml", 200, "<loc>" + site.URL + "/sitemap.xml</loc>"},
+		{"/redirect", 301, ""},
+		{"/error", 500, ""},
+		{"/missing", 404, ""},
+		{"/about.html", 404, ""},
+	}
+	for _, c := range cases {
+		resp, body := get(c.path)
+		if resp.StatusCode != c.status {
+			t.Fatalf("GET %s: expected %d, got %d", c.path, c.status, resp.StatusCode)
+		}
+		if !strings.Contains(body, c.contains) {
+			t.Fatalf("GET %s: expected %q in %q", c.path, c.contains, body)
+		}
+	}
+	if resp, _ := get("/redirect-chain"); resp.Header.Get("Location") != "/redirect" {
+		t.Fatalf("expected /redirect-chain to redirect to /redirect, got %q", resp.Header.Get("Location"))
+	}
+	if resp, _ := get("/images/logo.png"); resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a png, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	start := time.Now()
+	if resp, _ := get("/slow?ms=50"); resp.StatusCode != 200 || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("expected /slow to take 50ms, took %s", time.Since(start))
+	}
+
+	if got := site.Requests.Count("/redirect"); got != 1 {
+		t.Fatalf("expected one request for /redirect, got %d", got)
+	}
+	if got := site.Normalize(site.URL + "/about"); got != "http://fixtures.test/about" {
+		t.Fatalf("unexpected normalized URL %q", got)
+	}
+}
@@ -0,0 +1,77 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"raito/internal/textdiff"
+)
+
+// UpdateEnv names the environment variable that makes Golden rewrite the
+// golden files with the current output instead of comparing against
+// them:
+//
+//	RAITO_UPDATE_GOLDEN=1 go test ./internal/...
+const UpdateEnv = "RAITO_UPDATE_GOLDEN"
+
+// Golden compares got with testdata/golden/<name> in the package under
+// test and fails the test with a unified diff when they differ.
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	file := filepath.Join("testdata", "golden", filepath.FromSlash(name))
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("golden %s: %v (run with %s=1 to create it)", name, err, UpdateEnv)
+	}
+	if string(want) == string(got) {
+		return
+	}
+	ops := textdiff.Lines(textdiff.SplitLines(string(want)), textdiff.SplitLines(string(got)))
+	diff := textdiff.Unified(ops, file, "got", 3)
+	if diff == "" {
+		// Only line endings or the final newline differ.
+		diff = "(whitespace only)"
+	}
+	t.Fatalf("output differs from golden %s (run with %s=1 to update it):\n%s", name, UpdateEnv, diff)
+}
+
+// GoldenJSON compares v, as indented JSON, with a golden file like
+// Golden.
+func GoldenJSON(t testing.TB, name string, v any) {
+	t.Helper()
+	Golden(t, name, indentedJSON(t, name, v))
+}
+
+// Golden compares got, with the site's URL normalized, with a golden
+// file like the package-level Golden.
+func (s *Site) Golden(t testing.TB, name, got string) {
+	t.Helper()
+	Golden(t, name, []byte(s.Normalize(got)))
+}
+
+// GoldenJSON compares v, as indented JSON with the site's URL
+// normalized, with a golden file like the package-level Golden.
+func (s *Site) GoldenJSON(t testing.TB, name string, v any) {
+	t.Helper()
+	s.Golden(t, name, string(indentedJSON(t, name, v)))
+}
+
+func indentedJSON(t testing.TB, name string, v any) []byte {
+	t.Helper()
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("golden %s: %v", name, err)
+	}
+	return append(got, '\n')
+}
@@ -21,6 +21,8 @@ const (
 	FormatJSON       Format = "json"
 	FormatBranding   Format = "branding"
 	FormatScreenshot Format = "screenshot"
+	FormatTables     Format = "tables"
+	FormatClassify   Format = "classify"
 )
 
 // HasFormat reports whether the given Firecrawl-style formats array
@@ -82,3 +84,55 @@ func ValidateFormatsForEndpoint(endpoint string, formats []any) error {
 
 	return nil
 }
+
+// defaultableFormats maps the lower-cased names of formats that need no
+// options to their canonical names. Only these may be used as default
+// formats; json and classify need per-request options.
+var defaultableFormats = map[string]string{
+	"markdown":       string(FormatMarkdown),
+	"html":           string(FormatHTML),
+	"rawhtml":        string(FormatRawHTML),
+	"links":          string(FormatLinks),
+	"images":         string(FormatImages),
+	"summary":        string(FormatSummary),
+	"branding":       string(FormatBranding),
+	"screenshot":     string(FormatScreenshot),
+	"tables":         string(FormatTables),
+	"structureddata": "structuredData",
+	"a11y":           "a11y",
+	"performance":    "performance",
+	"auto":           "auto",
+}
+
+// NormalizeDefaultFormats checks a list of default format names and
+// returns their canonical spelling without duplicates. plugins lists the
+// installed format plugin names, which are also accepted.
+func NormalizeDefaultFormats(names []string, plugins []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, n := range names {
+		key := strings.ToLower(strings.TrimSpace(n))
+		name, ok := defaultableFormats[key]
+		if !ok {
+			for _, p := range plugins {
+				if strings.EqualFold(strings.TrimSpace(p), key) {
+					name, ok = p, true
+					break
+				}
+			}
+		}
+		switch {
+		case key == "metadata":
+			return nil, fmt.Errorf("metadata is always included and is not a format")
+		case key == "json" || key == "classify":
+			return nil, fmt.Errorf("format %q needs per-request options and cannot be a default", n)
+		case !ok:
+			return nil, fmt.Errorf("unsupported format %q", n)
+		}
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
@@ -36,3 +36,25 @@ func TestValidateFormatsForEndpoint_OtherEndpointNoRestriction(t *testing.T) {
 		t.Fatalf("expected no restriction for non-search endpoint, got %v", err)
 	}
 }
+
+func TestNormalizeDefaultFormats(t *testing.T) {
+	got, err := NormalizeDefaultFormats([]string{"Markdown", " links ", "RAWHTML", "markdown", "Readability"}, []string{"readability"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"markdown", "links", "rawHtml", "readability"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	for _, bad := range []string{"json", "metadata", "pdf"} {
+		if _, err := NormalizeDefaultFormats([]string{bad}, nil); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"raito/internal/config"
 	"raito/internal/db"
 	"raito/internal/jobs"
+	"raito/internal/migrate"
 	"raito/internal/store"
 )
 
@@ -40,6 +41,9 @@ type AdminJob struct {
 	TenantID    string          `json:"tenantId,omitempty"`
 	Error       string          `json:"error,omitempty"`
 	Output      json.RawMessage `json:"output,omitempty"`
+	// Input is the stored request, as written by the API that queued the
+	// job. Only dead-letter listings include it.
+	Input json.RawMessage `json:"input,omitempty"`
 }
 
 type adminJobResponse struct {
@@ -53,26 +57,40 @@ type adminJobsResponse struct {
 }
 
 type adminRetentionResponse struct {
-	Success          bool             `json:"success"`
-	JobsDeleted      map[string]int64 `json:"jobsDeleted"`
-	DocumentsDeleted int64            `json:"documentsDeleted"`
+	Success             bool             `json:"success"`
+	JobsDeleted         map[string]int64 `json:"jobsDeleted"`
+	DocumentsDeleted    int64            `json:"documentsDeleted"`
+	SessionsDeleted     int64            `json:"sessionsDeleted"`
+	ExtractCacheDeleted int64            `json:"extractCacheDeleted"`
+	ZeroRetentionPurged int64            `json:"zeroRetentionPurged"`
+}
+
+type adminSchemaResponse struct {
+	Success bool           `json:"success"`
+	Schema  migrate.Status `json:"schema"`
 }
 
 // registerAdminRoutes registers admin-only endpoints under /admin.
 func registerAdminRoutes(group fiber.Router) {
 	group.Post("/api-keys", adminCreateAPIKeyHandler)
+	group.Post("/api-keys/bulk", adminBulkCreateAPIKeysHandler)
 	group.Get("/api-keys", adminListAPIKeysHandler)
 	group.Delete("/api-keys/:id", adminRevokeAPIKeyHandler)
 	group.Get("/usage", adminUsageHandler)
 	group.Get("/audit", adminListAuditEventsHandler)
 	group.Get("/system-settings", adminGetSystemSettingsHandler)
 	group.Patch("/system-settings", adminUpdateSystemSettingsHandler)
+	group.Get("/system-settings/validate", adminValidateSystemSettingsHandler)
+	group.Post("/system-settings/validate", adminValidateSystemSettingsHandler)
 
 	group.Post("/users", adminCreateUserHandler)
+	group.Post("/users/import", adminImportUsersHandler)
 	group.Get("/users", adminListUsersHandler)
 	group.Get("/users/:id", adminGetUserHandler)
 	group.Patch("/users/:id", adminUpdateUserHandler)
 	group.Post("/users/:id/reset-password", adminResetUserPasswordHandler)
+	group.Get("/users/:id/sessions", adminListUserSessionsHandler)
+	group.Delete("/users/:id/sessions", adminRevokeUserSessionsHandler)
 
 	group.Post("/tenants", adminCreateTenantHandler)
 	group.Get("/tenants", adminListTenantsHandler)
@@ -82,10 +100,28 @@ func registerAdminRoutes(group fiber.Router) {
 	group.Post("/tenants/:id/members", adminAddTenantMemberHandler)
 	group.Patch("/tenants/:id/members/:userID", adminUpdateTenantMemberHandler)
 	group.Delete("/tenants/:id/members/:userID", adminRemoveTenantMemberHandler)
+	group.Get("/tenants/:id/llm-budget", adminGetTenantLLMBudgetHandler)
+	group.Put("/tenants/:id/llm-budget", adminPutTenantLLMBudgetHandler)
+	group.Get("/tenants/:id/llm-policy", adminGetTenantLLMPolicyHandler)
+	group.Put("/tenants/:id/llm-policy", adminPutTenantLLMPolicyHandler)
 
+	group.Get("/jobs/running", adminListRunningJobsHandler)
+	group.Get("/jobs/dead-letter", adminListDeadLetterJobsHandler)
 	group.Get("/jobs/:id", adminGetJobHandler)
+	group.Post("/jobs/:id/requeue", adminRequeueJobHandler)
 	group.Get("/jobs", adminListJobsHandler)
 	group.Post("/retention/cleanup", adminRetentionCleanupHandler)
+	group.Get("/db/maintenance", adminDBMaintenanceHandler)
+	group.Post("/db/maintenance/run", adminRunDBMaintenanceHandler)
+	group.Get("/workers", adminListWorkersHandler)
+	group.Get("/hosts", adminListHostsHandler)
+	group.Get("/queue", adminGetQueueHandler)
+	group.Post("/queue/pause", adminPauseQueueHandler)
+	group.Post("/queue/resume", adminResumeQueueHandler)
+	group.Get("/schema", adminSchemaStatusHandler)
+	group.Get("/plugins", adminListPluginsHandler)
+	group.Get("/backup", adminBackupHandler)
+	group.Post("/restore", adminRestoreHandler)
 }
 
 // adminCreateAPIKeyHandler creates a new user API key and returns the raw key once.
@@ -142,9 +178,32 @@ func adminRetentionCleanupHandler(c *fiber.Ctx) error {
 	stats := jobs.CleanupExpiredData(c.Context(), cfg, st)
 
 	return c.Status(fiber.StatusOK).JSON(adminRetentionResponse{
-		Success:          true,
-		JobsDeleted:      stats.JobsDeleted,
-		DocumentsDeleted: stats.DocumentsDeleted,
+		Success:             true,
+		JobsDeleted:         stats.JobsDeleted,
+		DocumentsDeleted:    stats.DocumentsDeleted,
+		SessionsDeleted:     stats.SessionsDeleted,
+		ExtractCacheDeleted: stats.ExtractCacheDeleted,
+		ZeroRetentionPurged: stats.ZeroRetentionPurged,
+	})
+}
+
+// adminSchemaStatusHandler reports the database schema version and any
+// migrations shipped with this binary that have not been applied.
+func adminSchemaStatusHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	status, err := migrate.GetStatus(c.Context(), st.DB)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "INTERNAL_ERROR",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(adminSchemaResponse{
+		Success: true,
+		Schema:  status,
 	})
 }
 
@@ -188,16 +247,23 @@ func adminGetJobHandler(c *fiber.Ctx) error {
 
 // adminListJobsHandler lists recent jobs with optional filters.
 func adminListJobsHandler(c *fiber.Ctx) error {
-	st := c.Locals("store").(*store.Store)
-
-	jobType := c.Query("type")
-	status := c.Query("status")
+	filter, ok, err := parseAdminJobListFilter(c)
+	if !ok {
+		return err
+	}
+	filter.Status = c.Query("status")
+	return sendAdminJobs(c, filter, false)
+}
 
+// parseAdminJobListFilter reads the type, tenantId, sync, limit, and
+// offset query parameters of the admin job lists. When ok is false the
+// error response has been sent and err is its result.
+func parseAdminJobListFilter(c *fiber.Ctx) (filter store.JobListFilter, ok bool, err error) {
 	var tenantID *uuid.UUID
 	if v := c.Query("tenantId"); v != "" {
 		id, err := uuid.Parse(v)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			return filter, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 				Success: false,
 				Code:    "BAD_REQUEST",
 				Error:   "invalid tenantId value",
@@ -210,7 +276,7 @@ func adminListJobsHandler(c *fiber.Ctx) error {
 	if syncStr := c.Query("sync"); syncStr != "" {
 		val, err := strconv.ParseBool(syncStr)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			return filter, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 				Success: false,
 				Code:    "BAD_REQUEST",
 				Error:   "invalid sync value; expected true or false",
@@ -223,7 +289,7 @@ func adminListJobsHandler(c *fiber.Ctx) error {
 	if v := c.Query("limit"); v != "" {
 		n, err := strconv.Atoi(v)
 		if err != nil || n <= 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			return filter, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 				Success: false,
 				Code:    "BAD_REQUEST",
 				Error:   "invalid limit value",
@@ -239,7 +305,7 @@ func adminListJobsHandler(c *fiber.Ctx) error {
 	if v := c.Query("offset"); v != "" {
 		n, err := strconv.Atoi(v)
 		if err != nil || n < 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			return filter, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 				Success: false,
 				Code:    "BAD_REQUEST",
 				Error:   "invalid offset value",
@@ -248,14 +314,21 @@ func adminListJobsHandler(c *fiber.Ctx) error {
 		offset = n
 	}
 
-	jobs, err := st.ListJobs(c.Context(), store.JobListFilter{
-		Type:     jobType,
-		Status:   status,
+	return store.JobListFilter{
+		Type:     c.Query("type"),
 		Sync:     syncFilter,
 		TenantID: tenantID,
 		Limit:    int32(limit),
 		Offset:   int32(offset),
-	})
+	}, true, nil
+}
+
+// sendAdminJobs responds with the jobs matching filter. includeInput adds
+// each job's stored input.
+func sendAdminJobs(c *fiber.Ctx, filter store.JobListFilter, includeInput bool) error {
+	st := c.Locals("store").(*store.Store)
+
+	jobs, err := st.ListJobs(c.Context(), filter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Success: false,
@@ -266,7 +339,11 @@ func adminListJobsHandler(c *fiber.Ctx) error {
 
 	out := make([]AdminJob, 0, len(jobs))
 	for _, job := range jobs {
-		out = append(out, marshalAdminJob(job, false))
+		item := marshalAdminJob(job, false)
+		if includeInput {
+			item.Input = job.Input
+		}
+		out = append(out, item)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(adminJobsResponse{
@@ -0,0 +1,266 @@
+package http
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/sqlc-dev/pqtype"
+
+	"raito/internal/config"
+)
+
+// Sources reported for each entry in appliedOptions.
+const (
+	optionSourceRequest    = "request"
+	optionSourceCollection = "collection"
+	optionSourceTenant     = "tenant"
+	optionSourceConfig     = "config"
+	optionSourceDefault    = "default"
+	optionSourceDerived    = "derived"
+)
+
+// AppliedOption is the resolved value of one option and where it came
+// from: "request" (the request body), "collection" (the collection's
+// defaultOptions), "tenant" (tenant settings), "config" (server
+// configuration), "default" (built-in default) or "derived" (implied by
+// other options).
+type AppliedOption struct {
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// AppliedOptions maps option names, written as request JSON paths such as
+// "scrapeOptions.auth", to their resolved values. Header values and
+// secrets are never echoed; only header names and secret names are.
+type AppliedOptions map[string]AppliedOption
+
+// optionResolver builds AppliedOptions. Collection defaults are decoded
+// into the same request struct as the body, so a field that is set on the
+// struct but absent from the raw body came from the collection.
+type optionResolver struct {
+	body map[string]any
+	out  AppliedOptions
+}
+
+func newOptionResolver(body []byte) *optionResolver {
+	var m map[string]any
+	_ = json.Unmarshal(body, &m)
+	return &optionResolver{body: m, out: AppliedOptions{}}
+}
+
+func (r *optionResolver) inBody(path string) bool {
+	var cur any = r.body
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return false
+		}
+		if cur, ok = m[part]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// set records value when isSet, attributed to the request or its
+// collection. Otherwise fallback is recorded with fallbackSource; an
+// empty fallbackSource leaves the option out.
+func (r *optionResolver) set(path string, value any, isSet bool, fallback any, fallbackSource string) {
+	if isSet {
+		source := optionSourceCollection
+		if r.inBody(path) {
+			source = optionSourceRequest
+		}
+		r.out[path] = AppliedOption{Value: value, Source: source}
+		return
+	}
+	if fallbackSource != "" {
+		r.out[path] = AppliedOption{Value: fallback, Source: fallbackSource}
+	}
+}
+
+// setFormatsSource attributes formats filled in by applyDefaultFormats,
+// which set would otherwise credit to the collection.
+func (o AppliedOptions) setFormatsSource(source string) {
+	if source == "" {
+		return
+	}
+	if opt, ok := o["formats"]; ok {
+		opt.Source = source
+		o["formats"] = opt
+	}
+}
+
+func (r *optionResolver) setBool(path string, value *bool, fallback bool) {
+	if value != nil {
+		r.set(path, *value, true, nil, "")
+		return
+	}
+	r.set(path, nil, false, fallback, optionSourceDefault)
+}
+
+// setRetention records zeroDataRetention, which may also be requested as
+// storeInCache: false.
+func (r *optionResolver) setRetention(zeroDataRetention, storeInCache *bool) {
+	switch {
+	case zeroDataRetention != nil:
+		r.set("zeroDataRetention", *zeroDataRetention, true, nil, "")
+	case storeInCache != nil:
+		source := optionSourceCollection
+		if r.inBody("storeInCache") {
+			source = optionSourceRequest
+		}
+		r.out["zeroDataRetention"] = AppliedOption{Value: !*storeInCache, Source: source}
+	default:
+		r.set("zeroDataRetention", nil, false, false, optionSourceDefault)
+	}
+}
+
+// setRequestTarget records the headers, auth and location sent to the
+// target site under prefix ("" or "scrapeOptions.").
+func (r *optionResolver) setRequestTarget(prefix string, headers map[string]string, auth *TargetAuth, loc *LocationOptions) {
+	if len(headers) > 0 {
+		names := make([]string, 0, len(headers))
+		for k := range headers {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		r.set(prefix+"headers", names, true, nil, "")
+	}
+	if auth != nil {
+		r.set(prefix+"auth", map[string]string{"type": auth.Type, "secret": auth.Secret}, true, nil, "")
+	}
+	if loc != nil {
+		r.set(prefix+"location", loc, true, nil, "")
+	}
+}
+
+// resolveScrapeOptions reports the options a scrape runs with. It expects
+// a request that has already passed validateScrapeSubmission.
+func resolveScrapeOptions(cfg *config.Config, req *ScrapeRequest, body []byte) AppliedOptions {
+	r := newOptionResolver(body)
+
+	r.set("formats", req.Formats, len(req.Formats) > 0, builtinDefaultFormats, optionSourceDefault)
+
+	timeoutSet := req.Timeout != nil && *req.Timeout > 0
+	var timeout any
+	if timeoutSet {
+		timeout = *req.Timeout
+	}
+	r.set("timeout", timeout, timeoutSet, cfg.Scraper.TimeoutMs, optionSourceConfig)
+
+	hasScreenshot, _ := getScreenshotFormatConfig(req.Formats)
+	switch {
+	case hasScreenshot || len(req.Actions) > 0:
+		r.out["engine"] = AppliedOption{Value: "browser", Source: optionSourceDerived}
+	case req.UseBrowser != nil && *req.UseBrowser && !cfg.Rod.Enabled:
+		// The browser was requested but rod is disabled, so the HTTP
+		// engine is used instead.
+		r.out["engine"] = AppliedOption{Value: "http", Source: optionSourceConfig}
+	case req.UseBrowser != nil:
+		engine := "http"
+		if *req.UseBrowser {
+			engine = "browser"
+		}
+		source := optionSourceCollection
+		if r.inBody("useBrowser") {
+			source = optionSourceRequest
+		}
+		r.out["engine"] = AppliedOption{Value: engine, Source: source}
+	default:
+		r.out["engine"] = AppliedOption{Value: "http", Source: optionSourceDefault}
+	}
+
+	r.set("method", req.Method, req.Method != "", "GET", optionSourceDefault)
+	r.set("userAgent", nil, false, cfg.RequestUserAgent(), optionSourceConfig)
+	r.setRequestTarget("", req.Headers, req.Auth, req.Location)
+
+	r.setBool("dedupe", req.Dedupe, false)
+	r.setBool("downloadImages", req.DownloadImages, false)
+	r.set("visibility", req.Visibility, req.Visibility != "", "shared", optionSourceDefault)
+	r.set("collectionId", req.CollectionID, req.CollectionID != "", nil, "")
+	r.setRetention(req.ZeroDataRetention, req.StoreInCache)
+
+	return r.out
+}
+
+// resolveCrawlOptions reports the options a crawl runs with, mirroring
+// the defaults applied by runCrawlJob.
+func resolveCrawlOptions(cfg *config.Config, req *CrawlRequest, body []byte) AppliedOptions {
+	r := newOptionResolver(body)
+
+	limitSet := req.Limit != nil && *req.Limit > 0
+	var limit any
+	if limitSet {
+		limit = *req.Limit
+	}
+	r.set("limit", limit, limitSet, cfg.Crawler.MaxPagesDefault, optionSourceConfig)
+
+	if req.CrawlEntireDomain != nil && *req.CrawlEntireDomain {
+		r.set("crawlEntireDomain", true, true, nil, "")
+		r.out["allowSubdomains"] = AppliedOption{Value: true, Source: optionSourceDerived}
+	} else {
+		r.setBool("allowSubdomains", req.AllowSubdomains, false)
+	}
+	r.setBool("ignoreQueryParameters", req.IgnoreQueryParams, true)
+	r.setBool("allowExternalLinks", req.AllowExternalLinks, false)
+	r.set("sitemap", req.Sitemap, req.Sitemap != "", "include", optionSourceDefault)
+	if req.MaxDiscoveryDepth != nil && *req.MaxDiscoveryDepth >= 0 {
+		r.set("maxDiscoveryDepth", *req.MaxDiscoveryDepth, true, nil, "")
+	} else if cfg.Crawler.MaxDepthDefault > 0 {
+		r.set("maxDiscoveryDepth", nil, false, cfg.Crawler.MaxDepthDefault, optionSourceConfig)
+	}
+	if req.Delay != nil {
+		r.set("delay", *req.Delay, true, nil, "")
+	} else if cfg.Crawler.DelayMs > 0 {
+		r.set("delay", nil, false, float64(cfg.Crawler.DelayMs)/1000, optionSourceConfig)
+	}
+	r.setBool("spa", req.SPA, false)
+	r.setBool("respectRobotsMeta", req.RespectRobotsMeta, false)
+	if len(req.Languages) > 0 {
+		r.set("languages", req.Languages, true, nil, "")
+	}
+
+	if req.PriorityExpression != "" {
+		r.set("priorityExpression", req.PriorityExpression, true, nil, "")
+	} else if cfg.Crawler.PriorityExpression != "" {
+		r.set("priorityExpression", nil, false, cfg.Crawler.PriorityExpression, optionSourceConfig)
+	}
+
+	maxConcurrency := urlConcurrency(cfg)
+	if req.MaxConcurrency != nil && *req.MaxConcurrency > 0 && *req.MaxConcurrency < maxConcurrency {
+		r.set("maxConcurrency", *req.MaxConcurrency, true, nil, "")
+	} else {
+		r.set("maxConcurrency", nil, false, maxConcurrency, optionSourceConfig)
+	}
+
+	r.set("formats", req.Formats, len(req.Formats) > 0, builtinDefaultFormats, optionSourceDefault)
+	r.set("timeout", nil, false, cfg.Scraper.TimeoutMs, optionSourceConfig)
+	r.set("userAgent", nil, false, cfg.RequestUserAgent(), optionSourceConfig)
+	r.set("respectRobots", nil, false, cfg.Robots.Respect, optionSourceConfig)
+	if req.ScrapeOptions != nil {
+		r.setRequestTarget("scrapeOptions.", req.ScrapeOptions.Headers, req.ScrapeOptions.Auth, req.ScrapeOptions.Location)
+	}
+
+	r.setBool("incremental", req.Incremental, false)
+	r.setBool("downloadImages", req.DownloadImages, false)
+	r.set("visibility", req.Visibility, req.Visibility != "", "shared", optionSourceDefault)
+	r.set("collectionId", req.CollectionID, req.CollectionID != "", nil, "")
+	r.setRetention(req.ZeroDataRetention, req.StoreInCache)
+
+	return r.out
+}
+
+// decodeAppliedOptions reads the applied_options column of a job. Jobs
+// created before it existed have none.
+func decodeAppliedOptions(raw pqtype.NullRawMessage) AppliedOptions {
+	if !raw.Valid {
+		return nil
+	}
+	var out AppliedOptions
+	if err := json.Unmarshal(raw.RawMessage, &out); err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"testing"
+
+	"raito/internal/config"
+)
+
+func TestResolveScrapeOptions_Sources(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scraper.TimeoutMs = 30000
+	cfg.Scraper.UserAgent = "raito-test"
+
+	body := []byte(`{"url":"https://example.com","formats":["html"],"headers":{"X-Token":"s3cret"},"collectionId":"c1"}`)
+	var req ScrapeRequest
+	// Simulate applyCollectionDefaults: collection defaults first, then the body.
+	_ = json.Unmarshal([]byte(`{"formats":["markdown"],"visibility":"private","dedupe":true}`), &req)
+	_ = json.Unmarshal(body, &req)
+
+	got := resolveScrapeOptions(cfg, &req, body)
+
+	want := map[string]string{
+		"formats":           optionSourceRequest,
+		"headers":           optionSourceRequest,
+		"collectionId":      optionSourceRequest,
+		"visibility":        optionSourceCollection,
+		"dedupe":            optionSourceCollection,
+		"timeout":           optionSourceConfig,
+		"userAgent":         optionSourceConfig,
+		"method":            optionSourceDefault,
+		"engine":            optionSourceDefault,
+		"zeroDataRetention": optionSourceDefault,
+	}
+	for name, source := range want {
+		if got[name].Source != source {
+			t.Fatalf("%s: expected source %q, got %+v", name, source, got[name])
+		}
+	}
+	if got["timeout"].Value != 30000 || got["visibility"].Value != "private" {
+		t.Fatalf("unexpected values: %+v", got)
+	}
+	if names, ok := got["headers"].Value.([]string); !ok || len(names) != 1 || names[0] != "X-Token" {
+		t.Fatalf("expected only header names, got %+v", got["headers"])
+	}
+	if _, ok := got["auth"]; ok {
+		t.Fatalf("expected unset auth to be omitted")
+	}
+}
+
+func TestResolveScrapeOptions_DerivedEngine(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Rod.Enabled = true
+	req := ScrapeRequest{URL: "https://example.com", Formats: []any{"screenshot"}}
+	storeInCache := false
+	req.StoreInCache = &storeInCache
+
+	got := resolveScrapeOptions(cfg, &req, []byte(`{"url":"https://example.com","formats":["screenshot"],"storeInCache":false}`))
+	if got["engine"].Value != "browser" || got["engine"].Source != optionSourceDerived {
+		t.Fatalf("expected derived browser engine, got %+v", got["engine"])
+	}
+	if got["zeroDataRetention"].Value != true || got["zeroDataRetention"].Source != optionSourceRequest {
+		t.Fatalf("expected zeroDataRetention from storeInCache, got %+v", got["zeroDataRetention"])
+	}
+}
+
+func TestResolveCrawlOptions_Sources(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Crawler.MaxPagesDefault = 50
+	cfg.Worker.MaxConcurrentURLsPerJob = 8
+
+	body := []byte(`{"url":"https://example.com","crawlEntireDomain":true,"maxConcurrency":4,"scrapeOptions":{"auth":{"type":"bearer","secret":"docs"}}}`)
+	var req CrawlRequest
+	_ = json.Unmarshal(body, &req)
+
+	got := resolveCrawlOptions(cfg, &req, body)
+	checks := []struct {
+		name   string
+		value  any
+		source string
+	}{
+		{"limit", 50, optionSourceConfig},
+		{"allowSubdomains", true, optionSourceDerived},
+		{"ignoreQueryParameters", true, optionSourceDefault},
+		{"sitemap", "include", optionSourceDefault},
+		{"maxConcurrency", 4, optionSourceRequest},
+	}
+	for _, c := range checks {
+		if got[c.name].Value != c.value || got[c.name].Source != c.source {
+			t.Fatalf("%s: expected %v from %s, got %+v", c.name, c.value, c.source, got[c.name])
+		}
+	}
+	auth, ok := got["scrapeOptions.auth"]
+	if !ok || auth.Source != optionSourceRequest {
+		t.Fatalf("expected scrapeOptions.auth from request, got %+v", auth)
+	}
+}
@@ -11,6 +11,23 @@ import (
 	"raito/internal/store"
 )
 
+// Tenant member roles. Viewers may read the tenant's jobs and results but
+// not change anything; see tenantViewerMiddleware.
+const (
+	roleTenantAdmin  = "tenant_admin"
+	roleTenantMember = "tenant_member"
+	roleTenantViewer = "tenant_viewer"
+)
+
+// isTenantRole reports whether role is a known tenant member role.
+func isTenantRole(role string) bool {
+	switch role {
+	case roleTenantAdmin, roleTenantMember, roleTenantViewer:
+		return true
+	}
+	return false
+}
+
 // RequireSystemAdmin ensures the principal is a system/global admin.
 func RequireSystemAdmin(c *fiber.Ctx, p Principal) error {
 	if !p.IsSystemAdmin {
@@ -85,7 +102,7 @@ func RequireTenantAdmin(c *fiber.Ctx, p Principal, tenantID string) error {
 		})
 	}
 
-	if member.Role != "tenant_admin" {
+	if member.Role != roleTenantAdmin {
 		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Success: false,
 			Code:    "FORBIDDEN",
@@ -96,8 +113,9 @@ func RequireTenantAdmin(c *fiber.Ctx, p Principal, tenantID string) error {
 	return nil
 }
 
-// RequireTenantMemberOrAdmin ensures the principal is a member (or
-// admin) of the given tenant. System admins are always allowed.
+// RequireTenantMemberOrAdmin ensures the principal belongs to the given
+// tenant in any role, viewers included, so callers must only use it to
+// guard reads. System admins are always allowed.
 func RequireTenantMemberOrAdmin(c *fiber.Ctx, p Principal, tenantID string) error {
 	if p.IsSystemAdmin {
 		return nil
@@ -158,7 +176,7 @@ func RequireTenantMemberOrAdmin(c *fiber.Ctx, p Principal, tenantID string) erro
 		})
 	}
 
-	if member.Role != "tenant_admin" && member.Role != "tenant_member" {
+	if !isTenantRole(member.Role) {
 		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Success: false,
 			Code:    "FORBIDDEN",
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/llm"
+	"raito/internal/metrics"
+	"raito/internal/scrapeutil"
+	"raito/internal/store"
+)
+
+const (
+	// maxClassifyLabels bounds the candidate labels of a classify format.
+	maxClassifyLabels = 50
+	// maxClassifyLabelLength bounds one candidate label.
+	maxClassifyLabelLength = 100
+)
+
+// validateClassifyFormat checks the labels of a classify format, if one
+// was requested.
+func validateClassifyFormat(formats []any) error {
+	wantClassify, labels := scrapeutil.GetClassifyFormatConfig(formats)
+	if !wantClassify {
+		return nil
+	}
+	if len(labels) == 0 {
+		return fmt.Errorf("classify format requires at least one label")
+	}
+	if len(labels) > maxClassifyLabels {
+		return fmt.Errorf("classify format accepts at most %d labels", maxClassifyLabels)
+	}
+	for _, l := range labels {
+		if len(l) > maxClassifyLabelLength {
+			return fmt.Errorf("classify labels must be at most %d characters", maxClassifyLabelLength)
+		}
+	}
+	return nil
+}
+
+// pageClassifier assigns classify-format labels to pages. It asks the
+// LLM when one is available and falls back to term matching when it is
+// not or the call fails, so classification never fails a page.
+type pageClassifier struct {
+	labels   []string
+	client   llm.Client
+	provider llm.Provider
+	model    string
+	timeout  time.Duration
+}
+
+func newPageClassifier(ctx context.Context, cfg *config.Config, st *store.Store, tenantID *uuid.UUID, labels []string, timeout time.Duration) *pageClassifier {
+	pc := &pageClassifier{labels: labels, timeout: timeout}
+	if client, provider, model, err := newLLMClient(ctx, cfg, st, tenantID, "", ""); err == nil {
+		pc.client, pc.provider, pc.model = client, provider, model
+	}
+	return pc
+}
+
+// Classify returns the labels that apply to the page, in the order the
+// LLM ranked them or by descending term score for the fallback.
+func (pc *pageClassifier) Classify(ctx context.Context, url, markdown string) []string {
+	if pc.client != nil {
+		llmCtx, cancel := context.WithTimeout(ctx, pc.timeout)
+		res, err := pc.client.ExtractFields(llmCtx, llm.ExtractRequest{
+			URL:      url,
+			Markdown: markdown,
+			Fields: []llm.FieldSpec{{
+				Name:        "categories",
+				Description: "Array of the labels that describe the page, chosen only from this list and spelled exactly as given: " + strings.Join(pc.labels, "; ") + ". Use an empty array if none apply.",
+				Type:        "array",
+			}},
+			Timeout: pc.timeout,
+			Strict:  false,
+		})
+		cancel()
+		if err == nil {
+			metrics.RecordLLMExtract(string(pc.provider), pc.model, true)
+			return matchClassifyLabels(res.Fields["categories"], pc.labels)
+		}
+		metrics.RecordLLMExtract(string(pc.provider), pc.model, false)
+	}
+	return classifyByTerms(markdown, pc.labels)
+}
+
+// matchClassifyLabels keeps the LLM answers that name a candidate label,
+// compared case-insensitively, and returns them spelled as requested.
+func matchClassifyLabels(v any, labels []string) []string {
+	var answers []string
+	switch t := v.(type) {
+	case string:
+		answers = []string{t}
+	case []any:
+		for _, a := range t {
+			if s, ok := a.(string); ok {
+				answers = append(answers, s)
+			}
+		}
+	}
+
+	canonical := make(map[string]string, len(labels))
+	for _, l := range labels {
+		canonical[strings.ToLower(l)] = l
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, a := range answers {
+		l, ok := canonical[strings.ToLower(strings.TrimSpace(a))]
+		if !ok || seen[l] {
+			continue
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+	return out
+}
+
+// classifyByTerms scores each label by how often its words occur in the
+// page and returns those scoring at least half of the best label. Words
+// are compared lowercased with a trailing "s" removed.
+func classifyByTerms(markdown string, labels []string) []string {
+	counts := map[string]int{}
+	for _, w := range classifyTerms(markdown) {
+		counts[w]++
+	}
+
+	type scored struct {
+		label string
+		score float64
+	}
+	var results []scored
+	best := 0.0
+	for _, l := range labels {
+		terms := classifyTerms(l)
+		if len(terms) == 0 {
+			continue
+		}
+		score := 0.0
+		for _, t := range terms {
+			score += math.Log1p(float64(counts[t]))
+		}
+		score /= float64(len(terms))
+		if score > 0 {
+			results = append(results, scored{label: l, score: score})
+		}
+		if score > best {
+			best = score
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	var out []string
+	for _, r := range results {
+		if r.score >= best/2 {
+			out = append(out, r.label)
+		}
+	}
+	return out
+}
+
+// classifyTerms splits text into normalized words of at least three
+// characters.
+func classifyTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if len(w) > 3 {
+			w = strings.TrimSuffix(w, "s")
+		}
+		if len(w) >= 3 {
+			out = append(out, w)
+		}
+	}
+	return out
+}
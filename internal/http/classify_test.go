@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateClassifyFormat(t *testing.T) {
+	tooMany := make([]any, maxClassifyLabels+1)
+	for i := range tooMany {
+		tooMany[i] = "label"
+	}
+	cases := []struct {
+		formats []any
+		wantErr string
+	}{
+		{[]any{"markdown"}, ""},
+		{[]any{map[string]any{"type": "classify", "labels": []any{"Docs"}}}, ""},
+		{[]any{"classify"}, "at least one label"},
+		{[]any{map[string]any{"type": "classify", "labels": tooMany}}, "at most"},
+		{[]any{map[string]any{"type": "classify", "labels": []any{strings.Repeat("x", maxClassifyLabelLength+1)}}}, "characters"},
+	}
+	for i, tc := range cases {
+		err := validateClassifyFormat(tc.formats)
+		if tc.wantErr == "" && err != nil || tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Fatalf("case %d: err = %v, want %q", i, err, tc.wantErr)
+		}
+	}
+}
+
+func TestMatchClassifyLabels(t *testing.T) {
+	labels := []string{"Pricing", "Docs", "Blog"}
+	got := matchClassifyLabels([]any{"docs", "Careers", " Pricing ", "Docs", 3}, labels)
+	if want := []string{"Docs", "Pricing"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("matchClassifyLabels = %v, want %v", got, want)
+	}
+	if got := matchClassifyLabels("blog", labels); !reflect.DeepEqual(got, []string{"Blog"}) {
+		t.Fatalf("expected a single string answer to match, got %v", got)
+	}
+	if got := matchClassifyLabels(nil, labels); got != nil {
+		t.Fatalf("expected no labels for a missing answer, got %v", got)
+	}
+}
+
+func TestClassifyByTerms(t *testing.T) {
+	page := "# Pricing\n\nCompare our plans. Pricing is per seat; plans include support. See the API docs."
+	got := classifyByTerms(page, []string{"Pricing plans", "Careers", "API docs", "Support"})
+	if len(got) == 0 || got[0] != "Pricing plans" {
+		t.Fatalf("expected pricing to rank first, got %v", got)
+	}
+	for _, l := range got {
+		if l == "Careers" {
+			t.Fatalf("expected labels absent from the page to be skipped, got %v", got)
+		}
+	}
+	if got := classifyByTerms("nothing relevant here", []string{"Pricing"}); got != nil {
+		t.Fatalf("expected no labels, got %v", got)
+	}
+}
+
+func TestPageClassifier_UsesLLMAndFallsBack(t *testing.T) {
+	labels := []string{"Pricing", "Careers"}
+	client := &fakeLLM{
+		fieldsByURL: map[string]map[string]any{
+			"https://example.com/a": {"categories": []any{"careers", "Unknown"}},
+		},
+		errByURL: map[string]error{
+			"https://example.com/b": errors.New("provider down"),
+		},
+	}
+	pc := &pageClassifier{labels: labels, client: client, timeout: time.Second}
+
+	if got := pc.Classify(context.Background(), "https://example.com/a", "pricing pricing"); !reflect.DeepEqual(got, []string{"Careers"}) {
+		t.Fatalf("expected the LLM answer, got %v", got)
+	}
+	if got := pc.Classify(context.Background(), "https://example.com/b", "Our pricing page"); !reflect.DeepEqual(got, []string{"Pricing"}) {
+		t.Fatalf("expected the term fallback after an LLM error, got %v", got)
+	}
+
+	noLLM := &pageClassifier{labels: labels, timeout: time.Second}
+	if got := noLLM.Classify(context.Background(), "https://example.com/c", "We are hiring: careers at Example"); !reflect.DeepEqual(got, []string{"Careers"}) {
+		t.Fatalf("expected the term fallback without an LLM, got %v", got)
+	}
+}
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerV2Routes mounts Firecrawl v2 aliases for the scraping endpoints.
+// Each alias runs the v1 handler with its request and response bodies
+// rewritten so off-the-shelf Firecrawl SDKs work unchanged.
+func registerV2Routes(group fiber.Router) {
+	group.Post("/scrape", v2Compat(scrapeHandler, v2ScrapeRequest, nil))
+	group.Post("/map", v2Compat(mapHandler, v2MapRequest, nil))
+	group.Post("/crawl", v2Compat(crawlHandler, v2CrawlRequest, v2StartResponse))
+	group.Get("/crawl/:id", largeResponse(v2Compat(crawlStatusHandler, nil, v2ScrapeStatusResponse))...)
+	group.Post("/extract", v2Compat(extractHandler, v2ExtractRequest, v2StartResponse))
+	group.Get("/extract/:id", largeResponse(v2Compat(extractStatusHandler, nil, v2ExtractStatusResponse))...)
+	group.Post("/batch/scrape", v2Compat(batchScrapeHandler, v2ScrapeRequest, v2StartResponse))
+	group.Get("/batch/scrape/:id", largeResponse(v2Compat(batchScrapeStatusHandler, nil, v2ScrapeStatusResponse))...)
+	group.Post("/search", v2Compat(searchHandler, v2SearchRequest, v2SearchResponse))
+	group.Get("/team/concurrency-check", v2ConcurrencyCheckHandler)
+}
+
+// v2BodyShim rewrites a decoded JSON object in place.
+type v2BodyShim func(body map[string]any)
+
+// v2Compat wraps h so the JSON request body is passed through reqShim
+// before h parses it and the JSON response body through respShim after.
+// Bodies that are not JSON objects are left untouched so the v1 handler
+// reports malformed input exactly as it would on /v1.
+func v2Compat(h fiber.Handler, reqShim, respShim v2BodyShim) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if reqShim != nil {
+			if body, ok := decodeJSONObject(c.Body()); ok {
+				reqShim(body)
+				if raw, err := json.Marshal(body); err == nil {
+					c.Request().SetBody(raw)
+				}
+			}
+		}
+
+		if err := h(c); err != nil {
+			return err
+		}
+
+		if respShim != nil && strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+			if body, ok := decodeJSONObject(c.Response().Body()); ok {
+				respShim(body)
+				if raw, err := json.Marshal(body); err == nil {
+					c.Response().SetBodyRaw(raw)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// decodeJSONObject decodes raw as a JSON object, keeping numbers as
+// json.Number so re-encoding does not change their representation.
+func decodeJSONObject(raw []byte) (map[string]any, bool) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var body map[string]any
+	if err := dec.Decode(&body); err != nil || body == nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// v2ScrapeRequest handles /v2/scrape and /v2/batch/scrape, where some SDKs
+// nest page options under scrapeOptions instead of sending them top level.
+func v2ScrapeRequest(body map[string]any) {
+	if opts, ok := body["scrapeOptions"].(map[string]any); ok {
+		for k, v := range opts {
+			if _, exists := body[k]; !exists {
+				body[k] = v
+			}
+		}
+		delete(body, "scrapeOptions")
+	}
+	normalizeV2Formats(body)
+}
+
+// v2CrawlRequest maps v1-era crawl field names onto their v2 equivalents
+// and lifts scrapeOptions.formats to the top-level formats crawls read.
+func v2CrawlRequest(body map[string]any) {
+	renameV2Field(body, "maxDepth", "maxDiscoveryDepth")
+	renameV2Field(body, "allowBackwardLinks", "crawlEntireDomain")
+	if ignore, ok := body["ignoreSitemap"].(bool); ok {
+		if _, exists := body["sitemap"]; !exists {
+			body["sitemap"] = "include"
+			if ignore {
+				body["sitemap"] = "skip"
+			}
+		}
+		delete(body, "ignoreSitemap")
+	}
+
+	if opts, ok := body["scrapeOptions"].(map[string]any); ok {
+		if _, exists := body["formats"]; !exists {
+			if formats, ok := opts["formats"]; ok {
+				body["formats"] = formats
+			}
+		}
+		normalizeV2Formats(opts)
+	}
+	normalizeV2Formats(body)
+}
+
+// v2MapRequest maps the v1-era ignoreSitemap/sitemapOnly flags onto the
+// v2 sitemap mode.
+func v2MapRequest(body map[string]any) {
+	_, hasMode := body["sitemap"]
+	if only, ok := body["sitemapOnly"].(bool); ok {
+		if only && !hasMode {
+			body["sitemap"] = "only"
+			hasMode = true
+		}
+		delete(body, "sitemapOnly")
+	}
+	if ignore, ok := body["ignoreSitemap"].(bool); ok {
+		if ignore && !hasMode {
+			body["sitemap"] = "skip"
+		}
+		delete(body, "ignoreSitemap")
+	}
+}
+
+func v2ExtractRequest(body map[string]any) {
+	if opts, ok := body["scrapeOptions"].(map[string]any); ok {
+		normalizeV2Formats(opts)
+	}
+}
+
+func v2SearchRequest(body map[string]any) {
+	// v2 accepts categories as {"type": "github"} objects.
+	if cats, ok := body["categories"].([]any); ok {
+		for i, c := range cats {
+			if obj, ok := c.(map[string]any); ok {
+				if t, ok := obj["type"].(string); ok {
+					cats[i] = t
+				}
+			}
+		}
+	}
+	if opts, ok := body["scrapeOptions"].(map[string]any); ok {
+		normalizeV2Formats(opts)
+	}
+}
+
+// normalizeV2Formats collapses format objects that carry nothing but a
+// type ({"type": "markdown"}) into the plain string form. Objects with
+// options (json schemas, screenshot settings) are kept as objects.
+func normalizeV2Formats(body map[string]any) {
+	formats, ok := body["formats"].([]any)
+	if !ok {
+		return
+	}
+	for i, f := range formats {
+		obj, ok := f.(map[string]any)
+		if !ok || len(obj) != 1 {
+			continue
+		}
+		if t, ok := obj["type"].(string); ok {
+			formats[i] = t
+		}
+	}
+}
+
+func renameV2Field(body map[string]any, from, to string) {
+	v, ok := body[from]
+	if !ok {
+		return
+	}
+	if _, exists := body[to]; !exists {
+		body[to] = v
+	}
+	delete(body, from)
+}
+
+// v2StartResponse points the status URL returned by async job creation
+// at the /v2 alias the client called.
+func v2StartResponse(body map[string]any) {
+	if u, ok := body["url"].(string); ok {
+		body["url"] = strings.Replace(u, "/v1/", "/v2/", 1)
+	}
+}
+
+// v2ScrapeStatusResponse reshapes crawl and batch scrape status for v2
+// clients, which expect "scraping" while work is in progress and a
+// completed page count alongside total.
+func v2ScrapeStatusResponse(body map[string]any) {
+	if ok, _ := body["success"].(bool); !ok {
+		return
+	}
+	switch body["status"] {
+	case string(CrawlStatusPending), string(CrawlStatusRunning):
+		body["status"] = "scraping"
+	}
+	if _, ok := body["completed"]; !ok {
+		done := 0
+		if data, ok := body["data"].([]any); ok {
+			done = len(data)
+		}
+		body["completed"] = done
+	}
+	if _, ok := body["total"]; !ok {
+		body["total"] = body["completed"]
+	}
+	if _, ok := body["creditsUsed"]; !ok {
+		body["creditsUsed"] = 0
+	}
+	if _, ok := body["data"]; !ok {
+		body["data"] = []any{}
+	}
+}
+
+// v2ExtractStatusResponse reports in-progress extract jobs as
+// "processing", the status v2 clients poll on.
+func v2ExtractStatusResponse(body map[string]any) {
+	switch body["status"] {
+	case string(ExtractStatusPending), string(ExtractStatusRunning):
+		body["status"] = "processing"
+	}
+}
+
+// v2SearchResponse flattens scraped documents into their web result, as
+// v2 search returns documents in place of plain results when
+// scrapeOptions are set.
+func v2SearchResponse(body map[string]any) {
+	data, ok := body["data"].(map[string]any)
+	if !ok {
+		return
+	}
+	for _, source := range []string{"web", "news", "images"} {
+		results, ok := data[source].([]any)
+		if !ok {
+			continue
+		}
+		for _, r := range results {
+			entry, ok := r.(map[string]any)
+			if !ok {
+				continue
+			}
+			doc, ok := entry["document"].(map[string]any)
+			if !ok {
+				continue
+			}
+			delete(entry, "document")
+			for k, v := range doc {
+				if _, exists := entry[k]; !exists {
+					entry[k] = v
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestV2Compat_CrawlRequestShim(t *testing.T) {
+	app := fiber.New()
+
+	var got CrawlRequest
+	app.Post("/v2/crawl", v2Compat(func(c *fiber.Ctx) error {
+		if err := c.BodyParser(&got); err != nil {
+			t.Fatalf("BodyParser: %v", err)
+		}
+		return c.JSON(CrawlResponse{Success: true, ID: "abc", URL: "http://example.com/v1/crawl/abc"})
+	}, v2CrawlRequest, v2StartResponse))
+
+	body := `{"url":"https://example.com","maxDepth":2,"allowBackwardLinks":true,"ignoreSitemap":true,
+		"integration":"sdk","scrapeOptions":{"formats":[{"type":"markdown"},{"type":"json","prompt":"p"}]}}`
+	req := httptest.NewRequest("POST", "/v2/crawl", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+
+	if got.MaxDiscoveryDepth == nil || *got.MaxDiscoveryDepth != 2 {
+		t.Fatalf("expected maxDepth mapped to maxDiscoveryDepth, got %v", got.MaxDiscoveryDepth)
+	}
+	if got.CrawlEntireDomain == nil || !*got.CrawlEntireDomain {
+		t.Fatalf("expected allowBackwardLinks mapped to crawlEntireDomain")
+	}
+	if got.Sitemap != "skip" {
+		t.Fatalf("expected ignoreSitemap mapped to sitemap=skip, got %q", got.Sitemap)
+	}
+	if len(got.Formats) != 2 || got.Formats[0] != "markdown" {
+		t.Fatalf("expected scrapeOptions.formats lifted and normalized, got %#v", got.Formats)
+	}
+
+	var out map[string]any
+	raw, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if out["url"] != "http://example.com/v2/crawl/abc" {
+		t.Fatalf("expected status url rewritten to /v2, got %v", out["url"])
+	}
+}
+
+func TestV2Compat_ScrapeRequestFlattensScrapeOptions(t *testing.T) {
+	body := map[string]any{
+		"url":             "https://example.com",
+		"onlyMainContent": false,
+		"scrapeOptions":   map[string]any{"onlyMainContent": true, "waitFor": 500},
+	}
+	v2ScrapeRequest(body)
+
+	if _, ok := body["scrapeOptions"]; ok {
+		t.Fatalf("expected scrapeOptions to be removed")
+	}
+	if body["onlyMainContent"] != false {
+		t.Fatalf("top-level fields must win over scrapeOptions, got %v", body["onlyMainContent"])
+	}
+	if body["waitFor"] != 500 {
+		t.Fatalf("expected waitFor copied from scrapeOptions, got %v", body["waitFor"])
+	}
+}
+
+func TestV2Compat_StatusResponses(t *testing.T) {
+	crawl := map[string]any{
+		"success": true,
+		"status":  "running",
+		"total":   json.Number("2"),
+		"data":    []any{map[string]any{}, map[string]any{}},
+	}
+	v2ScrapeStatusResponse(crawl)
+	if crawl["status"] != "scraping" || crawl["completed"] != 2 || crawl["creditsUsed"] != 0 {
+		t.Fatalf("unexpected crawl status shim: %#v", crawl)
+	}
+
+	failed := map[string]any{"success": false, "error": "nope"}
+	v2ScrapeStatusResponse(failed)
+	if _, ok := failed["completed"]; ok {
+		t.Fatalf("error responses must be left untouched: %#v", failed)
+	}
+
+	extract := map[string]any{"success": true, "status": "pending"}
+	v2ExtractStatusResponse(extract)
+	if extract["status"] != "processing" {
+		t.Fatalf("expected pending extract reported as processing, got %v", extract["status"])
+	}
+
+	search := map[string]any{"success": true, "data": map[string]any{"web": []any{
+		map[string]any{"url": "https://a", "title": "A", "document": map[string]any{"markdown": "# A", "url": "https://a/"}},
+	}}}
+	v2SearchResponse(search)
+	entry := search["data"].(map[string]any)["web"].([]any)[0].(map[string]any)
+	if entry["markdown"] != "# A" || entry["url"] != "https://a" {
+		t.Fatalf("expected document flattened into result, got %#v", entry)
+	}
+	if _, ok := entry["document"]; ok {
+		t.Fatalf("expected document key removed")
+	}
+}
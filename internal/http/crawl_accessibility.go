@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/model"
+)
+
+// maxAccessibilityRuleURLs caps the example pages listed per rule.
+const maxAccessibilityRuleURLs = 10
+
+// CrawlAccessibilitySummary aggregates the a11y audits of a crawl's
+// pages. It is stored as job output under "accessibility".
+type CrawlAccessibilitySummary struct {
+	PagesAudited        int `json:"pagesAudited"`
+	PagesWithViolations int `json:"pagesWithViolations"`
+	// Violations counts offending elements across all pages.
+	Violations int `json:"violations"`
+	// Impacts counts offending elements by impact.
+	Impacts map[string]int           `json:"impacts"`
+	Rules   []CrawlAccessibilityRule `json:"rules"`
+}
+
+// CrawlAccessibilityRule is one rule violated somewhere in the crawl.
+type CrawlAccessibilityRule struct {
+	ID     string `json:"id"`
+	Impact string `json:"impact"`
+	Help   string `json:"help"`
+	Pages  int    `json:"pages"`
+	Nodes  int    `json:"nodes"`
+	// URLs lists up to ten pages that violate the rule.
+	URLs []string `json:"urls"`
+}
+
+var accessibilityImpactOrder = map[string]int{"critical": 0, "serious": 1, "moderate": 2, "minor": 3}
+
+// summarizeCrawlAccessibility totals the a11y reports stored with a
+// crawl's documents by rule, most severe and widespread first.
+func summarizeCrawlAccessibility(ctx context.Context, st crawlDocStore, jobID uuid.UUID) (*CrawlAccessibilitySummary, error) {
+	_, docs, err := st.GetCrawlJobAndDocuments(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	docs = currentCrawlDocuments(docs)
+
+	summary := &CrawlAccessibilitySummary{Impacts: map[string]int{}, Rules: []CrawlAccessibilityRule{}}
+	rules := map[string]*CrawlAccessibilityRule{}
+	for _, d := range docs {
+		var md model.Metadata
+		if err := json.Unmarshal(d.Metadata, &md); err != nil || md.Accessibility == nil {
+			continue
+		}
+		summary.PagesAudited++
+		if len(md.Accessibility.Violations) > 0 {
+			summary.PagesWithViolations++
+		}
+		for _, v := range md.Accessibility.Violations {
+			summary.Violations += v.Nodes
+			summary.Impacts[v.Impact] += v.Nodes
+			r, ok := rules[v.ID]
+			if !ok {
+				r = &CrawlAccessibilityRule{ID: v.ID, Impact: v.Impact, Help: v.Help, URLs: []string{}}
+				rules[v.ID] = r
+			}
+			r.Pages++
+			r.Nodes += v.Nodes
+			if len(r.URLs) < maxAccessibilityRuleURLs {
+				r.URLs = append(r.URLs, d.Url)
+			}
+		}
+	}
+
+	for _, r := range rules {
+		summary.Rules = append(summary.Rules, *r)
+	}
+	sort.Slice(summary.Rules, func(i, j int) bool {
+		a, b := summary.Rules[i], summary.Rules[j]
+		if accessibilityImpactOrder[a.Impact] != accessibilityImpactOrder[b.Impact] {
+			return accessibilityImpactOrder[a.Impact] < accessibilityImpactOrder[b.Impact]
+		}
+		if a.Pages != b.Pages {
+			return a.Pages > b.Pages
+		}
+		return a.ID < b.ID
+	})
+	return summary, nil
+}
+
+// jobAccessibilitySummary decodes the a11y summary from a job's output;
+// it is nil for crawls without the a11y format.
+func jobAccessibilitySummary(job db.Job) *CrawlAccessibilitySummary {
+	if !job.Output.Valid {
+		return nil
+	}
+	var out struct {
+		Accessibility *CrawlAccessibilitySummary `json:"accessibility"`
+	}
+	if err := json.Unmarshal(job.Output.RawMessage, &out); err != nil {
+		return nil
+	}
+	return out.Accessibility
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/model"
+)
+
+func TestSummarizeCrawlAccessibility(t *testing.T) {
+	jobID := uuid.New()
+	imageAlt := model.AccessibilityViolation{ID: "image-alt", Impact: "critical", Help: "Add an alt attribute", Nodes: 2}
+	contrast := model.AccessibilityViolation{ID: "color-contrast", Impact: "serious", Help: "Raise the contrast", Nodes: 5}
+	headings := model.AccessibilityViolation{ID: "heading-order", Impact: "moderate", Help: "Do not skip levels", Nodes: 1}
+	st := &fakeCrawlDocStore{
+		jobs: map[uuid.UUID]db.Job{jobID: {ID: jobID}},
+		docs: map[uuid.UUID][]db.Document{
+			jobID: {
+				metadataDoc(t, 1, "https://example.com/", model.Metadata{Accessibility: &model.AccessibilityReport{Violations: []model.AccessibilityViolation{contrast, headings}}}),
+				metadataDoc(t, 2, "https://example.com/a", model.Metadata{Accessibility: &model.AccessibilityReport{Violations: []model.AccessibilityViolation{imageAlt, contrast}}}),
+				metadataDoc(t, 3, "https://example.com/b", model.Metadata{Accessibility: &model.AccessibilityReport{Violations: []model.AccessibilityViolation{}}}),
+				// Pages whose audit failed are not counted.
+				metadataDoc(t, 4, "https://example.com/c", model.Metadata{}),
+			},
+		},
+	}
+
+	summary, err := summarizeCrawlAccessibility(context.Background(), st, jobID)
+	if err != nil {
+		t.Fatalf("summarizeCrawlAccessibility: %v", err)
+	}
+	if summary.PagesAudited != 3 || summary.PagesWithViolations != 2 || summary.Violations != 13 {
+		t.Fatalf("unexpected totals %+v", summary)
+	}
+	if summary.Impacts["serious"] != 10 || summary.Impacts["critical"] != 2 {
+		t.Fatalf("unexpected impacts %v", summary.Impacts)
+	}
+	var ids []string
+	for _, r := range summary.Rules {
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != 3 || ids[0] != "image-alt" || ids[1] != "color-contrast" || ids[2] != "heading-order" {
+		t.Fatalf("unexpected rule order %v", ids)
+	}
+	if r := summary.Rules[1]; r.Pages != 2 || r.Nodes != 10 || len(r.URLs) != 2 {
+		t.Fatalf("unexpected color-contrast rule %+v", r)
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/model"
+)
+
+// fakeCrawlDocStore serves crawl jobs and their documents from memory. It
+// satisfies both crawlDocStore and incrementalStore.
+type fakeCrawlDocStore struct {
+	latest uuid.UUID
+	jobs   map[uuid.UUID]db.Job
+	docs   map[uuid.UUID][]db.Document
+}
+
+func (f *fakeCrawlDocStore) FindPreviousCrawlJob(_ context.Context, _ uuid.NullUUID, _ string, _ uuid.UUID) (uuid.UUID, error) {
+	if f.latest == uuid.Nil {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	return f.latest, nil
+}
+
+func (f *fakeCrawlDocStore) GetCrawlJobAndDocuments(_ context.Context, id uuid.UUID) (db.Job, []db.Document, error) {
+	job, ok := f.jobs[id]
+	if !ok {
+		return db.Job{}, nil, sql.ErrNoRows
+	}
+	return job, f.docs[id], nil
+}
+
+// metadataDoc returns a stored document of url whose metadata is md, with
+// the source URL filled in.
+func metadataDoc(t *testing.T, id int64, url string, md model.Metadata) db.Document {
+	t.Helper()
+	md.SourceURL = url
+	raw, err := json.Marshal(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db.Document{ID: id, Url: url, Metadata: raw}
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/dedupe"
+)
+
+// CrawlDuplicateSummary reports the near-duplicate pages of a completed
+// crawl. It is stored as job output under "duplicates".
+type CrawlDuplicateSummary struct {
+	// Clusters is the number of groups of near-identical pages.
+	Clusters int `json:"clusters"`
+	// Duplicates counts the pages that repeat the first page of their
+	// group, i.e. the pages dropped by excludeDuplicates downloads.
+	Duplicates int                     `json:"duplicates"`
+	Groups     []CrawlDuplicateCluster `json:"groups,omitempty"`
+}
+
+// CrawlDuplicateCluster is one group of near-identical pages. The first
+// document is the earliest page stored for the group.
+type CrawlDuplicateCluster struct {
+	ID          int      `json:"id"`
+	DocumentIDs []int64  `json:"documentIds"`
+	URLs        []string `json:"urls"`
+}
+
+// crawlDocStore is the subset of the store used by the passes over a
+// finished crawl's stored documents.
+type crawlDocStore interface {
+	GetCrawlJobAndDocuments(ctx context.Context, id uuid.UUID) (db.Job, []db.Document, error)
+}
+
+// detectDuplicates clusters the stored documents of a crawl by simhash of
+// their markdown.
+func detectDuplicates(ctx context.Context, st crawlDocStore, jobID uuid.UUID) (*CrawlDuplicateSummary, error) {
+	_, docs, err := st.GetCrawlJobAndDocuments(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	docs = currentCrawlDocuments(docs)
+
+	inputs := make([]dedupe.Document, 0, len(docs))
+	urls := make(map[int64]string, len(docs))
+	for _, d := range docs {
+		inputs = append(inputs, dedupe.Document{ID: d.ID, Text: d.Markdown.String})
+		urls[d.ID] = d.Url
+	}
+
+	summary := &CrawlDuplicateSummary{}
+	for _, cl := range dedupe.Clusters(inputs, dedupe.DefaultMaxDistance) {
+		group := CrawlDuplicateCluster{ID: cl.ID, DocumentIDs: cl.DocumentIDs}
+		for _, id := range cl.DocumentIDs {
+			group.URLs = append(group.URLs, urls[id])
+		}
+		summary.Groups = append(summary.Groups, group)
+		summary.Duplicates += len(cl.DocumentIDs) - 1
+	}
+	summary.Clusters = len(summary.Groups)
+	return summary, nil
+}
+
+// jobDuplicateSummary decodes the duplicate summary from a job's output;
+// it is nil for jobs that did not run the duplicate pass.
+func jobDuplicateSummary(job db.Job) *CrawlDuplicateSummary {
+	if !job.Output.Valid {
+		return nil
+	}
+	var out struct {
+		Duplicates *CrawlDuplicateSummary `json:"duplicates"`
+	}
+	if err := json.Unmarshal(job.Output.RawMessage, &out); err != nil {
+		return nil
+	}
+	return out.Duplicates
+}
+
+// duplicateClusterIDs maps each clustered document to its cluster ID.
+func (s *CrawlDuplicateSummary) duplicateClusterIDs() map[int64]int {
+	if s == nil {
+		return nil
+	}
+	ids := map[int64]int{}
+	for _, g := range s.Groups {
+		for _, id := range g.DocumentIDs {
+			ids[id] = g.ID
+		}
+	}
+	return ids
+}
+
+// withoutDuplicateDocuments keeps the first document of each cluster and
+// every unclustered document.
+func withoutDuplicateDocuments(docs []db.Document, summary *CrawlDuplicateSummary) []db.Document {
+	if summary == nil {
+		return docs
+	}
+	dropped := map[int64]bool{}
+	for _, g := range summary.Groups {
+		for _, id := range g.DocumentIDs[1:] {
+			dropped[id] = true
+		}
+	}
+	out := make([]db.Document, 0, len(docs))
+	for _, d := range docs {
+		if !dropped[d.ID] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
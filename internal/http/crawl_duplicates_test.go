@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"raito/internal/db"
+)
+
+const duplicatePage = `Acme Widgets are built to last. Every widget ships with a two year warranty,
+free returns within thirty days, and support from our team in Denver. Our catalog covers
+industrial widgets, home widgets, and custom orders for teams of any size.`
+
+func duplicateDoc(id int64, url, markdown string) db.Document {
+	return db.Document{ID: id, Url: url, Markdown: sql.NullString{String: markdown, Valid: markdown != ""}}
+}
+
+func TestDetectDuplicates(t *testing.T) {
+	jobID := uuid.New()
+	st := &fakeCrawlDocStore{
+		jobs: map[uuid.UUID]db.Job{jobID: {ID: jobID}},
+		docs: map[uuid.UUID][]db.Document{
+			jobID: {
+				duplicateDoc(1, "https://example.com/a", duplicatePage),
+				duplicateDoc(2, "https://example.com/b", "Release notes for version two: faster exports and a new dashboard."),
+				duplicateDoc(3, "https://example.com/a?ref=nav", strings.Replace(duplicatePage, "Denver", "Boston", 1)),
+			},
+		},
+	}
+
+	summary, err := detectDuplicates(context.Background(), st, jobID)
+	if err != nil {
+		t.Fatalf("detectDuplicates: %v", err)
+	}
+	want := &CrawlDuplicateSummary{
+		Clusters:   1,
+		Duplicates: 1,
+		Groups: []CrawlDuplicateCluster{{
+			ID:          1,
+			DocumentIDs: []int64{1, 3},
+			URLs:        []string{"https://example.com/a", "https://example.com/a?ref=nav"},
+		}},
+	}
+	if !reflect.DeepEqual(summary, want) {
+		t.Fatalf("summary = %+v, want %+v", summary, want)
+	}
+	if ids := summary.duplicateClusterIDs(); !reflect.DeepEqual(ids, map[int64]int{1: 1, 3: 1}) {
+		t.Fatalf("unexpected cluster IDs: %v", ids)
+	}
+
+	docs := st.docs[jobID]
+	kept := withoutDuplicateDocuments(docs, summary)
+	if len(kept) != 2 || kept[0].ID != 1 || kept[1].ID != 2 {
+		t.Fatalf("expected the first page of the cluster and the unique page, got %+v", kept)
+	}
+}
+
+func TestJobDuplicateSummary(t *testing.T) {
+	raw, _ := json.Marshal(map[string]any{
+		"duplicates": CrawlDuplicateSummary{Clusters: 1, Duplicates: 2, Groups: []CrawlDuplicateCluster{{ID: 1, DocumentIDs: []int64{4, 5, 6}}}},
+	})
+	job := db.Job{Output: pqtype.NullRawMessage{RawMessage: raw, Valid: true}}
+	summary := jobDuplicateSummary(job)
+	if summary == nil || summary.Duplicates != 2 || len(summary.Groups) != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	if jobDuplicateSummary(db.Job{}) != nil {
+		t.Fatalf("expected no summary for a job without output")
+	}
+	var none *CrawlDuplicateSummary
+	if none.duplicateClusterIDs() != nil {
+		t.Fatalf("expected no cluster IDs without a summary")
+	}
+	docs := []db.Document{{ID: 1}}
+	if got := withoutDuplicateDocuments(docs, nil); len(got) != 1 {
+		t.Fatalf("expected documents to be kept without a summary, got %d", len(got))
+	}
+}
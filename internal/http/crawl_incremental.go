@@ -0,0 +1,183 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/scraper"
+)
+
+// maxIncrementalChain bounds how many earlier crawls are walked (via
+// previous_job_id) when building an incremental baseline. Incremental
+// crawls only store new and changed pages, so older pages live in earlier
+// jobs of the chain.
+const maxIncrementalChain = 20
+
+// CrawlIncrementalSummary reports how an incremental crawl compared to its
+// baseline. It is stored as job output under "incremental".
+type CrawlIncrementalSummary struct {
+	PreviousJobID string   `json:"previousJobId,omitempty"`
+	New           int      `json:"new"`
+	Changed       int      `json:"changed"`
+	Unchanged     int      `json:"unchanged"`
+	UnchangedURLs []string `json:"unchangedUrls,omitempty"`
+}
+
+// incrementalStore is the subset of the store used to load a baseline.
+type incrementalStore interface {
+	FindPreviousCrawlJob(ctx context.Context, tenantID uuid.NullUUID, rootURL string, excludeID uuid.UUID) (uuid.UUID, error)
+	GetCrawlJobAndDocuments(ctx context.Context, id uuid.UUID) (db.Job, []db.Document, error)
+}
+
+type incrementalPage struct {
+	etag         string
+	lastModified string
+	hash         string
+}
+
+// incrementalBaseline holds the last known version of each page of a
+// crawl root and records how freshly scraped pages compare to it.
+type incrementalBaseline struct {
+	previousJobID uuid.UUID
+	pages         map[string]incrementalPage
+
+	mu        sync.Mutex
+	summary   CrawlIncrementalSummary
+	unchanged []string
+}
+
+// loadIncrementalBaseline builds the baseline from the most recent
+// completed crawl of rootURL in the tenant and the crawls it was built on.
+// When there is no earlier crawl the baseline is empty and every page is
+// treated as new.
+func loadIncrementalBaseline(ctx context.Context, st incrementalStore, tenantID uuid.NullUUID, rootURL string, jobID uuid.UUID) (*incrementalBaseline, error) {
+	b := &incrementalBaseline{pages: make(map[string]incrementalPage)}
+
+	prevID, err := st.FindPreviousCrawlJob(ctx, tenantID, rootURL, jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return b, nil
+		}
+		return nil, err
+	}
+	b.previousJobID = prevID
+
+	next := prevID
+	for i := 0; i < maxIncrementalChain && next != uuid.Nil; i++ {
+		job, docs, err := st.GetCrawlJobAndDocuments(ctx, next)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+			return nil, err
+		}
+		b.addDocuments(docs)
+		next = uuid.Nil
+		if job.PreviousJobID.Valid {
+			next = job.PreviousJobID.UUID
+		}
+	}
+
+	return b, nil
+}
+
+// addDocuments adds pages that are not yet known; callers add newer jobs
+// first so the latest version of each page wins.
+func (b *incrementalBaseline) addDocuments(docs []db.Document) {
+	for _, d := range docs {
+		if _, ok := b.pages[d.Url]; ok {
+			continue
+		}
+		var md struct {
+			ETag         string `json:"etag"`
+			LastModified string `json:"lastModified"`
+		}
+		_ = json.Unmarshal(d.Metadata, &md)
+		b.pages[d.Url] = incrementalPage{
+			etag:         md.ETag,
+			lastModified: md.LastModified,
+			hash:         contentHash(d.Markdown.String),
+		}
+	}
+}
+
+// requestHeaders returns base plus conditional request headers for pages
+// whose previous version carried validators.
+func (b *incrementalBaseline) requestHeaders(url string, base map[string]string) map[string]string {
+	page, ok := b.pages[url]
+	if !ok || (page.etag == "" && page.lastModified == "") {
+		return base
+	}
+	headers := make(map[string]string, len(base)+2)
+	for k, v := range base {
+		headers[k] = v
+	}
+	if page.etag != "" {
+		headers["If-None-Match"] = page.etag
+	}
+	if page.lastModified != "" {
+		headers["If-Modified-Since"] = page.lastModified
+	}
+	return headers
+}
+
+// record classifies a scraped page as new, changed, or unchanged and
+// reports whether it should be stored. A 304 response or identical
+// markdown counts as unchanged.
+func (b *incrementalBaseline) record(url string, res *scraper.Result) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	page, known := b.pages[url]
+	switch {
+	case !known:
+		b.summary.New++
+		return true
+	case res.Status == http.StatusNotModified || page.hash == contentHash(res.Markdown):
+		b.summary.Unchanged++
+		b.unchanged = append(b.unchanged, url)
+		return false
+	default:
+		b.summary.Changed++
+		return true
+	}
+}
+
+// result returns the comparison summary for the job output.
+func (b *incrementalBaseline) result() CrawlIncrementalSummary {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := b.summary
+	if b.previousJobID != uuid.Nil {
+		out.PreviousJobID = b.previousJobID.String()
+	}
+	out.UnchangedURLs = append([]string(nil), b.unchanged...)
+	return out
+}
+
+// restore adds the comparison summary of an earlier run of the same
+// crawl, saved when it was paused.
+func (b *incrementalBaseline) restore(sum CrawlIncrementalSummary) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.summary.New += sum.New
+	b.summary.Changed += sum.Changed
+	b.summary.Unchanged += sum.Unchanged
+	b.unchanged = append(b.unchanged, sum.UnchangedURLs...)
+}
+
+func contentHash(markdown string) string {
+	sum := sha256.Sum256([]byte(markdown))
+	return hex.EncodeToString(sum[:])
+}
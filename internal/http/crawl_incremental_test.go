@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/scraper"
+)
+
+func incrementalDoc(t *testing.T, url, markdown, etag string) db.Document {
+	t.Helper()
+	meta, err := json.Marshal(map[string]any{"sourceURL": url, "etag": etag})
+	if err != nil {
+		t.Fatalf("marshal metadata: %v", err)
+	}
+	return db.Document{
+		Url:      url,
+		Markdown: sql.NullString{String: markdown, Valid: true},
+		Metadata: meta,
+	}
+}
+
+func TestIncrementalBaseline_ClassifiesPagesAcrossChain(t *testing.T) {
+	firstID := uuid.New()
+	secondID := uuid.New()
+	st := &fakeCrawlDocStore{
+		latest: secondID,
+		jobs: map[uuid.UUID]db.Job{
+			firstID:  {ID: firstID},
+			secondID: {ID: secondID, PreviousJobID: uuid.NullUUID{UUID: firstID, Valid: true}},
+		},
+		docs: map[uuid.UUID][]db.Document{
+			// The first full crawl stored both pages; the second incremental
+			// crawl only stored the page that changed.
+			firstID: {
+				incrementalDoc(t, "https://example.com/", "home v1", ""),
+				incrementalDoc(t, "https://example.com/about", "about", `"about-etag"`),
+			},
+			secondID: {
+				incrementalDoc(t, "https://example.com/", "home v2", ""),
+			},
+		},
+	}
+
+	b, err := loadIncrementalBaseline(context.Background(), st, uuid.NullUUID{}, "https://example.com/", uuid.New())
+	if err != nil {
+		t.Fatalf("loadIncrementalBaseline: %v", err)
+	}
+
+	headers := b.requestHeaders("https://example.com/about", map[string]string{"Accept-Language": "en"})
+	if headers["If-None-Match"] != `"about-etag"` || headers["Accept-Language"] != "en" {
+		t.Fatalf("expected conditional headers for known page, got %#v", headers)
+	}
+
+	if b.record("https://example.com/", &scraper.Result{Status: 200, Markdown: "home v2"}) {
+		t.Fatalf("expected page matching the newest stored version to be unchanged")
+	}
+	if b.record("https://example.com/about", &scraper.Result{Status: 304}) {
+		t.Fatalf("expected 304 to be treated as unchanged")
+	}
+	if !b.record("https://example.com/pricing", &scraper.Result{Status: 200, Markdown: "new"}) {
+		t.Fatalf("expected unknown page to be stored")
+	}
+
+	res := b.result()
+	if res.PreviousJobID != secondID.String() {
+		t.Fatalf("expected previous job %s, got %q", secondID, res.PreviousJobID)
+	}
+	if res.New != 1 || res.Changed != 0 || res.Unchanged != 2 {
+		t.Fatalf("unexpected summary: %#v", res)
+	}
+}
+
+func TestIncrementalBaseline_NoPreviousCrawl(t *testing.T) {
+	b, err := loadIncrementalBaseline(context.Background(), &fakeCrawlDocStore{}, uuid.NullUUID{}, "https://example.com/", uuid.New())
+	if err != nil {
+		t.Fatalf("loadIncrementalBaseline: %v", err)
+	}
+	if !b.record("https://example.com/", &scraper.Result{Status: 200, Markdown: "home"}) {
+		t.Fatalf("expected every page to be new without a baseline")
+	}
+	if res := b.result(); res.PreviousJobID != "" || res.New != 1 {
+		t.Fatalf("unexpected summary: %#v", res)
+	}
+}
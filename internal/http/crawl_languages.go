@@ -0,0 +1,101 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"raito/internal/crawler"
+	"raito/internal/scraper"
+	"raito/internal/scrapeutil"
+)
+
+// maxCrawlLanguages bounds the languages one crawl may select.
+const maxCrawlLanguages = 20
+
+// languageTagPattern matches the BCP 47 tags accepted in languages: a
+// primary language followed by optional subtags, such as "en", "pt-br"
+// or "zh-hant-tw".
+var languageTagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{1,8})*$`)
+
+// CrawlLanguageSummary reports what a crawl run with languages left out.
+type CrawlLanguageSummary struct {
+	Languages []string `json:"languages"`
+	// SkippedPages counts pages in other languages that were scraped
+	// before their language was known but not stored.
+	SkippedPages int `json:"skippedPages"`
+	// SkippedAlternates counts queued language alternates that were
+	// dropped without being scraped.
+	SkippedAlternates int `json:"skippedAlternates"`
+}
+
+// normalizeCrawlLanguages validates the languages of a crawl and
+// rewrites them lower-cased, with "_" as "-" and without repeats.
+func normalizeCrawlLanguages(req *CrawlRequest) error {
+	if len(req.Languages) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var langs []string
+	for _, l := range req.Languages {
+		l = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(l)), "_", "-")
+		if !languageTagPattern.MatchString(l) {
+			return fmt.Errorf("invalid language %q", l)
+		}
+		if !seen[l] {
+			seen[l] = true
+			langs = append(langs, l)
+		}
+	}
+	if len(langs) > maxCrawlLanguages {
+		return fmt.Errorf("too many languages; maximum is %d", maxCrawlLanguages)
+	}
+	req.Languages = langs
+	return nil
+}
+
+// pageAlternates returns the URL a scraped page's hreflang annotations
+// know it by and the language alternates they list.
+func pageAlternates(res *scraper.Result) (string, []crawler.Alternate) {
+	base, err := url.Parse(res.URL)
+	if err != nil {
+		return res.URL, nil
+	}
+	pageURL, ok := crawler.NormalizeRoute(base, res.URL)
+	if !ok {
+		pageURL = res.URL
+	}
+	return pageURL, crawler.ParseHreflang(res.RawHTML, base)
+}
+
+// languageSkips returns the alternates of a page that a crawl restricted
+// to langs should not scrape: those whose every hreflang is another
+// language. URLs only listed as x-default are kept, since their language
+// is unknown until they are scraped.
+func languageSkips(alts []crawler.Alternate, langs []string) []string {
+	wanted := map[string]bool{}
+	var order []string
+	for _, a := range alts {
+		if a.Lang == "x-default" {
+			continue
+		}
+		if _, ok := wanted[a.URL]; !ok {
+			order = append(order, a.URL)
+		}
+		wanted[a.URL] = wanted[a.URL] || crawler.LanguageMatches(a.Lang, langs)
+	}
+	var out []string
+	for _, u := range order {
+		if !wanted[u] {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// pageLanguage returns the language of a scraped page: the hreflang its
+// alternates give it, or its <html lang>.
+func pageLanguage(res *scraper.Result, pageURL string, alts []crawler.Alternate) string {
+	return crawler.PageLanguage(pageURL, alts, scrapeutil.ToString(res.Metadata["language"]))
+}
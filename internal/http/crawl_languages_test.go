@@ -0,0 +1,51 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"raito/internal/crawler"
+	"raito/internal/scraper"
+)
+
+func TestNormalizeCrawlLanguages(t *testing.T) {
+	req := CrawlRequest{Languages: []string{" EN ", "pt_BR", "en"}}
+	if err := normalizeCrawlLanguages(&req); err != nil {
+		t.Fatalf("normalizeCrawlLanguages: %v", err)
+	}
+	if want := []string{"en", "pt-br"}; !reflect.DeepEqual(req.Languages, want) {
+		t.Fatalf("got %v, want %v", req.Languages, want)
+	}
+
+	for _, bad := range [][]string{{""}, {"english!"}, {"e"}} {
+		if err := normalizeCrawlLanguages(&CrawlRequest{Languages: bad}); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestCrawlLanguageSkips(t *testing.T) {
+	res := &scraper.Result{
+		URL: "https://example.com/de/",
+		RawHTML: `<html lang="de"><head>
+<link rel="alternate" hreflang="de" href="/de/">
+<link rel="alternate" hreflang="en" href="/en/">
+<link rel="alternate" hreflang="en-gb" href="/uk/">
+<link rel="alternate" hreflang="fr" href="/fr/">
+<link rel="alternate" hreflang="x-default" href="/">
+</head></html>`,
+		Metadata: map[string]any{"language": "de"},
+	}
+	pageURL, alts := pageAlternates(res)
+	if lang := pageLanguage(res, pageURL, alts); lang != "de" {
+		t.Fatalf("expected the page to be German, got %q", lang)
+	}
+	if crawler.LanguageGroup(pageURL, alts) == "" {
+		t.Fatal("expected a language group")
+	}
+
+	got := languageSkips(alts, []string{"en"})
+	if want := []string{"https://example.com/de/", "https://example.com/fr/"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/crawler"
+	"raito/internal/db"
+	"raito/internal/events"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+// crawlPausePollInterval is how often a running crawl re-reads its status,
+// in case the wakeup for a pause was dropped.
+const crawlPausePollInterval = 5 * time.Second
+
+// crawlPauseState holds the counters of a paused crawl, saved with its
+// frontier so the resumed crawl reports the whole run.
+type crawlPauseState struct {
+	// Scraped counts the pages handled so far, stored or skipped.
+	Scraped           int32                    `json:"scraped"`
+	NoIndexSkipped    int32                    `json:"noindexSkipped,omitempty"`
+	NoFollowSkipped   int32                    `json:"nofollowSkipped,omitempty"`
+	LanguageSkipped   int32                    `json:"languageSkipped,omitempty"`
+	AlternatesSkipped int32                    `json:"alternatesSkipped,omitempty"`
+	Incremental       *CrawlIncrementalSummary `json:"incremental,omitempty"`
+	// FetchErrors counts the pages that could not be fetched, by kind.
+	FetchErrors map[string]int `json:"fetchErrors,omitempty"`
+	// ElapsedMs is how long the crawl ran before it was paused.
+	ElapsedMs int64 `json:"elapsedMs,omitempty"`
+}
+
+// savedCrawlFrontier is what a paused crawl left for its resume.
+type savedCrawlFrontier struct {
+	frontier crawler.FrontierState
+	state    crawlPauseState
+}
+
+// loadCrawlFrontier returns the frontier a paused crawl saved, or nil when
+// the crawl has not been paused.
+func loadCrawlFrontier(ctx context.Context, st *store.Store, jobID uuid.UUID) (*savedCrawlFrontier, error) {
+	row, err := db.New(st.DB).GetCrawlFrontier(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	saved := &savedCrawlFrontier{frontier: crawler.FrontierState{Admitted: int(row.Admitted)}}
+	if err := json.Unmarshal(row.Queued, &saved.frontier.Queued); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(row.Seen, &saved.frontier.Seen); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(row.Depths, &saved.frontier.Depths); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(row.State, &saved.state); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// saveCrawlFrontier stores what a paused crawl needs to continue.
+func saveCrawlFrontier(ctx context.Context, st *store.Store, jobID uuid.UUID, frontier crawler.FrontierState, state crawlPauseState) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return st.SaveCrawlFrontier(ctx, jobID, frontier.Queued, frontier.Seen, frontier.Admitted, frontier.Depths, raw)
+}
+
+// crawlStatusStore is the subset of the store watchCrawlPause reads.
+type crawlStatusStore interface {
+	GetJobByID(ctx context.Context, id uuid.UUID) (db.Job, error)
+}
+
+// watchCrawlPause calls pause once the crawl's status is paused, until ctx
+// ends. It re-reads the status on every event of the job's timeline, which
+// POST /v1/crawl/:id/pause records, and every crawlPausePollInterval.
+func watchCrawlPause(ctx context.Context, st crawlStatusStore, jobID uuid.UUID, pause func()) {
+	wakeups := events.Subscribe(ctx, events.JobTopic(jobID))
+	ticker := time.NewTicker(crawlPausePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-wakeups:
+		case <-ticker.C:
+		}
+		job, err := st.GetJobByID(ctx, jobID)
+		if err == nil && job.Status == string(jobs.StatusPaused) {
+			pause()
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/events"
+	"raito/internal/jobs"
+)
+
+type fakeCrawlStatusStore struct {
+	status atomic.Value
+}
+
+func (s *fakeCrawlStatusStore) GetJobByID(ctx context.Context, id uuid.UUID) (db.Job, error) {
+	return db.Job{ID: id, Status: s.status.Load().(string)}, nil
+}
+
+func TestWatchCrawlPause(t *testing.T) {
+	jobID := uuid.New()
+	st := &fakeCrawlStatusStore{}
+	st.status.Store(string(jobs.StatusRunning))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	paused := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchCrawlPause(ctx, st, jobID, func() { close(paused) })
+	}()
+
+	// Unrelated timeline events leave the crawl running.
+	time.Sleep(20 * time.Millisecond)
+	_ = events.Publish(ctx, events.JobTopic(jobID), []byte(`{"type":"discovery_finished"}`))
+	select {
+	case <-paused:
+		t.Fatal("expected the crawl to keep running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	st.status.Store(string(jobs.StatusPaused))
+	_ = events.Publish(ctx, events.JobTopic(jobID), []byte(`{"type":"paused"}`))
+	select {
+	case <-paused:
+	case <-ctx.Done():
+		t.Fatal("expected the pause to be noticed")
+	}
+	<-done
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"math"
+	"time"
+
+	"raito/internal/db"
+)
+
+// crawlProgress derives a running crawl's progress from its worker
+// heartbeat. total is the expected page count, first estimated from
+// sitemaps and then refined once discovery finishes, and is 0 while
+// unknown. eta is nil until pages have been fetched and a total is known;
+// it assumes the rate so far continues.
+func crawlProgress(hb db.JobHeartbeat, now time.Time) (completed, total int, eta *int64) {
+	completed = int(hb.PagesDone)
+	total = int(hb.PagesTotal)
+	if total > 0 && total < completed {
+		total = completed
+	}
+	elapsed := hb.HeartbeatAt.Sub(hb.StartedAt)
+	if total == 0 || completed == 0 || elapsed <= 0 {
+		return completed, total, nil
+	}
+
+	perPage := elapsed / time.Duration(completed)
+	finishAt := hb.HeartbeatAt.Add(perPage * time.Duration(total-completed))
+	secs := int64(math.Ceil(finishAt.Sub(now).Seconds()))
+	if secs < 0 {
+		secs = 0
+	}
+	return completed, total, &secs
+}
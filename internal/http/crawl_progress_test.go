@@ -0,0 +1,49 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"raito/internal/db"
+)
+
+func TestCrawlProgress(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	hb := db.JobHeartbeat{
+		StartedAt:   start,
+		HeartbeatAt: start.Add(20 * time.Second),
+		PagesDone:   10,
+		PagesTotal:  40,
+	}
+
+	completed, total, eta := crawlProgress(hb, start.Add(25*time.Second))
+	if completed != 10 || total != 40 {
+		t.Fatalf("completed/total = %d/%d, want 10/40", completed, total)
+	}
+	// 2s per page for 30 pages after the heartbeat, 5s of which have passed.
+	if eta == nil || *eta != 55 {
+		t.Fatalf("eta = %v, want 55", eta)
+	}
+
+	if _, _, eta := crawlProgress(hb, start.Add(time.Hour)); eta == nil || *eta != 0 {
+		t.Fatalf("expected an overdue estimate to clamp to 0, got %v", eta)
+	}
+
+	unknown := hb
+	unknown.PagesTotal = 0
+	if _, total, eta := crawlProgress(unknown, start); total != 0 || eta != nil {
+		t.Fatalf("expected no total or eta without an estimate, got %d, %v", total, eta)
+	}
+
+	starting := hb
+	starting.PagesDone = 0
+	if _, total, eta := crawlProgress(starting, start); total != 40 || eta != nil {
+		t.Fatalf("expected a total but no eta before the first page, got %d, %v", total, eta)
+	}
+
+	over := hb
+	over.PagesDone = 45
+	if _, total, _ := crawlProgress(over, start); total != 45 {
+		t.Fatalf("expected total to be at least completed, got %d", total)
+	}
+}
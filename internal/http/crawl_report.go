@@ -0,0 +1,333 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/publicsuffix"
+
+	"raito/internal/db"
+	"raito/internal/model"
+)
+
+// maxCrawlReportHosts caps the domains and hosts listed in a report.
+const maxCrawlReportHosts = 10
+
+// CrawlReport summarizes a completed crawl. It is stored as job output
+// under "report" and rendered as report.md and report.html in the
+// crawl's download.
+type CrawlReport struct {
+	// Pages counts the stored pages the report covers.
+	Pages      int   `json:"pages"`
+	DurationMs int64 `json:"durationMs"`
+	// StatusCodes counts pages by HTTP status code.
+	StatusCodes map[string]int `json:"statusCodes"`
+	// ContentTypes counts pages by media type, without parameters.
+	ContentTypes map[string]int `json:"contentTypes"`
+	// AverageWordCount is the mean number of words in the pages' markdown.
+	AverageWordCount int `json:"averageWordCount"`
+	// Domains groups pages by registrable domain and Hosts by full host
+	// name, most pages first.
+	Domains []CrawlReportHost `json:"domains"`
+	Hosts   []CrawlReportHost `json:"hosts"`
+	// FailedPages counts pages that could not be fetched at all, and
+	// FetchErrors breaks them down into "dns", "tls", "timeout" and
+	// "error".
+	FailedPages int            `json:"failedPages"`
+	FetchErrors map[string]int `json:"fetchErrors"`
+	// HTTPErrors counts stored pages that answered with a 4xx or 5xx
+	// status, by status class.
+	HTTPErrors map[string]int `json:"httpErrors"`
+}
+
+// CrawlReportHost is one domain or host of a crawl report.
+type CrawlReportHost struct {
+	Host  string `json:"host"`
+	Pages int    `json:"pages"`
+}
+
+// crawlFetchErrors counts the pages a running crawl failed to fetch, by
+// kind of error.
+type crawlFetchErrors struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newCrawlFetchErrors(counts map[string]int) *crawlFetchErrors {
+	e := &crawlFetchErrors{counts: map[string]int{}}
+	for k, n := range counts {
+		e.counts[k] = n
+	}
+	return e
+}
+
+func (e *crawlFetchErrors) add(err error) {
+	kind := classifyHostError(err)
+	e.mu.Lock()
+	e.counts[kind]++
+	e.mu.Unlock()
+}
+
+func (e *crawlFetchErrors) snapshot() map[string]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make(map[string]int, len(e.counts))
+	for k, n := range e.counts {
+		out[k] = n
+	}
+	return out
+}
+
+// summarizeCrawlReport builds the report of a crawl from its stored
+// documents. The duration and fetch errors of the run are taken from the
+// report already in output, so a rescrape refreshes the rest.
+func summarizeCrawlReport(ctx context.Context, st crawlDocStore, jobID uuid.UUID, output map[string]any) (*CrawlReport, error) {
+	var run CrawlReport
+	if prev, ok := output["report"]; ok {
+		if raw, err := json.Marshal(prev); err == nil {
+			_ = json.Unmarshal(raw, &run)
+		}
+	}
+	_, docs, err := st.GetCrawlJobAndDocuments(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCrawlReport(currentCrawlDocuments(docs), run.DurationMs, run.FetchErrors), nil
+}
+
+// buildCrawlReport totals docs into a report for a crawl that ran for
+// durationMs and failed to fetch the pages in fetchErrors.
+func buildCrawlReport(docs []db.Document, durationMs int64, fetchErrors map[string]int) *CrawlReport {
+	report := &CrawlReport{
+		Pages:        len(docs),
+		DurationMs:   durationMs,
+		StatusCodes:  map[string]int{},
+		ContentTypes: map[string]int{},
+		Domains:      []CrawlReportHost{},
+		Hosts:        []CrawlReportHost{},
+		FetchErrors:  map[string]int{},
+		HTTPErrors:   map[string]int{},
+	}
+	for kind, n := range fetchErrors {
+		report.FetchErrors[kind] = n
+		report.FailedPages += n
+	}
+
+	domains := map[string]int{}
+	hosts := map[string]int{}
+	words := 0
+	for _, d := range docs {
+		var md model.Metadata
+		_ = json.Unmarshal(d.Metadata, &md)
+
+		status := md.StatusCode
+		if d.StatusCode.Valid {
+			status = int(d.StatusCode.Int32)
+		}
+		if status > 0 {
+			report.StatusCodes[strconv.Itoa(status)]++
+			if status >= 400 && status < 600 {
+				report.HTTPErrors[strconv.Itoa(status/100)+"xx"]++
+			}
+		}
+
+		contentType := "unknown"
+		if ct := md.Headers["content-type"]; ct != "" {
+			ct, _, _ = strings.Cut(ct, ";")
+			contentType = strings.ToLower(strings.TrimSpace(ct))
+		}
+		report.ContentTypes[contentType]++
+
+		if d.Markdown.Valid {
+			words += len(strings.Fields(d.Markdown.String))
+		}
+
+		if u, err := url.Parse(d.Url); err == nil && u.Hostname() != "" {
+			host := strings.ToLower(u.Hostname())
+			hosts[host]++
+			domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+			if err != nil {
+				domain = host
+			}
+			domains[domain]++
+		}
+	}
+	if len(docs) > 0 {
+		report.AverageWordCount = (words + len(docs)/2) / len(docs)
+	}
+	report.Domains = topCrawlReportHosts(domains)
+	report.Hosts = topCrawlReportHosts(hosts)
+	return report
+}
+
+// topCrawlReportHosts returns the hosts with the most pages, then by name.
+func topCrawlReportHosts(counts map[string]int) []CrawlReportHost {
+	out := make([]CrawlReportHost, 0, len(counts))
+	for h, n := range counts {
+		out = append(out, CrawlReportHost{Host: h, Pages: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Pages != out[j].Pages {
+			return out[i].Pages > out[j].Pages
+		}
+		return out[i].Host < out[j].Host
+	})
+	if len(out) > maxCrawlReportHosts {
+		out = out[:maxCrawlReportHosts]
+	}
+	return out
+}
+
+// jobCrawlReport returns the report stored with a crawl job, or nil.
+func jobCrawlReport(job db.Job) *CrawlReport {
+	if !job.Output.Valid {
+		return nil
+	}
+	var out struct {
+		Report *CrawlReport `json:"report"`
+	}
+	if err := json.Unmarshal(job.Output.RawMessage, &out); err != nil {
+		return nil
+	}
+	return out.Report
+}
+
+// crawlReportSection is one table of a rendered report.
+type crawlReportSection struct {
+	Title  string
+	Header string
+	Rows   []crawlReportRow
+}
+
+type crawlReportRow struct {
+	Label string
+	Pages int
+}
+
+// sections lists the report's tables in the order they are rendered.
+// Empty tables are left out.
+func (r *CrawlReport) sections() []crawlReportSection {
+	hostRows := func(hosts []CrawlReportHost) []crawlReportRow {
+		rows := make([]crawlReportRow, 0, len(hosts))
+		for _, h := range hosts {
+			rows = append(rows, crawlReportRow{Label: h.Host, Pages: h.Pages})
+		}
+		return rows
+	}
+	errs := map[string]int{}
+	for kind, n := range r.FetchErrors {
+		errs["fetch: "+kind] += n
+	}
+	for class, n := range r.HTTPErrors {
+		errs["http: "+class] += n
+	}
+
+	all := []crawlReportSection{
+		{Title: "Status codes", Header: "Status", Rows: crawlReportRows(r.StatusCodes, true)},
+		{Title: "Content types", Header: "Content type", Rows: crawlReportRows(r.ContentTypes, false)},
+		{Title: "Top domains", Header: "Domain", Rows: hostRows(r.Domains)},
+		{Title: "Top hosts", Header: "Host", Rows: hostRows(r.Hosts)},
+		{Title: "Errors", Header: "Error", Rows: crawlReportRows(errs, false)},
+	}
+	var out []crawlReportSection
+	for _, s := range all {
+		if len(s.Rows) > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// crawlReportRows sorts counts by label, or by count and then label.
+func crawlReportRows(counts map[string]int, byLabel bool) []crawlReportRow {
+	rows := make([]crawlReportRow, 0, len(counts))
+	for l, n := range counts {
+		rows = append(rows, crawlReportRow{Label: l, Pages: n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !byLabel && rows[i].Pages != rows[j].Pages {
+			return rows[i].Pages > rows[j].Pages
+		}
+		return rows[i].Label < rows[j].Label
+	})
+	return rows
+}
+
+// duration formats the report's duration to the second.
+func (r *CrawlReport) duration() string {
+	d := time.Duration(r.DurationMs) * time.Millisecond
+	if d < time.Second {
+		return d.String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// renderCrawlReportMarkdown renders the report of the crawl of rootURL
+// as markdown.
+func renderCrawlReportMarkdown(rootURL string, r *CrawlReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Crawl report: %s\n\n", rootURL)
+	fmt.Fprintf(&b, "- Pages: %d\n", r.Pages)
+	fmt.Fprintf(&b, "- Duration: %s\n", r.duration())
+	fmt.Fprintf(&b, "- Average word count: %d\n", r.AverageWordCount)
+	fmt.Fprintf(&b, "- Failed pages: %d\n", r.FailedPages)
+	for _, s := range r.sections() {
+		fmt.Fprintf(&b, "\n## %s\n\n| %s | Pages |\n| --- | ---: |\n", s.Title, s.Header)
+		for _, row := range s.Rows {
+			fmt.Fprintf(&b, "| %s | %d |\n", strings.ReplaceAll(row.Label, "|", `\|`), row.Pages)
+		}
+	}
+	return b.String()
+}
+
+var crawlReportHTML = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Crawl report: {{.URL}}</title>
+<style>
+body { font-family: sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #ccc; padding: 0.25rem 0.75rem; text-align: left; }
+td.n { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Crawl report: {{.URL}}</h1>
+<ul>
+<li>Pages: {{.Report.Pages}}</li>
+<li>Duration: {{.Duration}}</li>
+<li>Average word count: {{.Report.AverageWordCount}}</li>
+<li>Failed pages: {{.Report.FailedPages}}</li>
+</ul>
+{{range .Sections}}<h2>{{.Title}}</h2>
+<table>
+<tr><th>{{.Header}}</th><th>Pages</th></tr>
+{{range .Rows}}<tr><td>{{.Label}}</td><td class="n">{{.Pages}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// renderCrawlReportHTML renders the report of the crawl of rootURL as a
+// standalone HTML page.
+func renderCrawlReportHTML(rootURL string, r *CrawlReport) (string, error) {
+	var buf bytes.Buffer
+	err := crawlReportHTML.Execute(&buf, struct {
+		URL      string
+		Report   *CrawlReport
+		Duration string
+		Sections []crawlReportSection
+	}{rootURL, r, r.duration(), r.sections()})
+	return buf.String(), err
+}
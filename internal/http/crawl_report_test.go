@@ -0,0 +1,106 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/model"
+)
+
+func reportDoc(t *testing.T, id int64, url string, status int32, contentType, markdown string) db.Document {
+	t.Helper()
+	doc := metadataDoc(t, id, url, model.Metadata{Headers: map[string]string{"content-type": contentType}})
+	doc.Markdown = sql.NullString{String: markdown, Valid: true}
+	doc.StatusCode = sql.NullInt32{Int32: status, Valid: true}
+	return doc
+}
+
+func TestSummarizeCrawlReport(t *testing.T) {
+	jobID := uuid.New()
+	st := &fakeCrawlDocStore{
+		jobs: map[uuid.UUID]db.Job{jobID: {ID: jobID}},
+		docs: map[uuid.UUID][]db.Document{
+			jobID: {
+				reportDoc(t, 1, "https://www.example.com/", 200, "text/html; charset=utf-8", "one two three four"),
+				reportDoc(t, 2, "https://www.example.com/a", 200, "text/html", "one two"),
+				reportDoc(t, 3, "https://blog.example.com/b", 404, "text/html", "gone"),
+				reportDoc(t, 4, "https://other.org/c.pdf", 500, "application/pdf", "x y z"),
+			},
+		},
+	}
+	// The run's duration and fetch errors come from the report in output.
+	output := map[string]any{"report": CrawlReport{DurationMs: 65000, FetchErrors: map[string]int{"timeout": 2, "dns": 1}}}
+
+	report, err := summarizeCrawlReport(context.Background(), st, jobID, output)
+	if err != nil {
+		t.Fatalf("summarizeCrawlReport: %v", err)
+	}
+	if report.Pages != 4 || report.DurationMs != 65000 || report.AverageWordCount != 3 {
+		t.Fatalf("unexpected totals %+v", report)
+	}
+	if report.StatusCodes["200"] != 2 || report.StatusCodes["404"] != 1 || report.StatusCodes["500"] != 1 {
+		t.Fatalf("unexpected status codes %v", report.StatusCodes)
+	}
+	if report.ContentTypes["text/html"] != 3 || report.ContentTypes["application/pdf"] != 1 {
+		t.Fatalf("unexpected content types %v", report.ContentTypes)
+	}
+	if len(report.Domains) != 2 || report.Domains[0] != (CrawlReportHost{Host: "example.com", Pages: 3}) {
+		t.Fatalf("unexpected domains %v", report.Domains)
+	}
+	if len(report.Hosts) != 3 || report.Hosts[0] != (CrawlReportHost{Host: "www.example.com", Pages: 2}) || report.Hosts[1].Host != "blog.example.com" {
+		t.Fatalf("unexpected hosts %v", report.Hosts)
+	}
+	if report.FailedPages != 3 || report.FetchErrors["timeout"] != 2 {
+		t.Fatalf("unexpected fetch errors %d %v", report.FailedPages, report.FetchErrors)
+	}
+	if report.HTTPErrors["4xx"] != 1 || report.HTTPErrors["5xx"] != 1 {
+		t.Fatalf("unexpected http errors %v", report.HTTPErrors)
+	}
+}
+
+func TestCrawlFetchErrors(t *testing.T) {
+	e := newCrawlFetchErrors(map[string]int{"dns": 1})
+	e.add(errors.New("net::ERR_NAME_NOT_RESOLVED"))
+	e.add(errors.New("connection reset"))
+	got := e.snapshot()
+	if got["dns"] != 2 || got["error"] != 1 {
+		t.Fatalf("unexpected counts %v", got)
+	}
+}
+
+func TestRenderCrawlReport(t *testing.T) {
+	report := buildCrawlReport([]db.Document{
+		reportDoc(t, 1, "https://example.com/", 200, "text/html", "hello world"),
+	}, 1500, map[string]int{"tls": 1})
+
+	md := renderCrawlReportMarkdown("https://example.com", report)
+	for _, want := range []string{
+		"# Crawl report: https://example.com",
+		"- Duration: 2s",
+		"- Failed pages: 1",
+		"| 200 | 1 |",
+		"| example.com | 1 |",
+		"| fetch: tls | 1 |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	page, err := renderCrawlReportHTML("https://example.com/?a=<b>", report)
+	if err != nil {
+		t.Fatalf("renderCrawlReportHTML: %v", err)
+	}
+	if !strings.Contains(page, "<h2>Status codes</h2>") || !strings.Contains(page, `<td class="n">1</td>`) {
+		t.Fatalf("unexpected html:\n%s", page)
+	}
+	if strings.Contains(page, "<b>") {
+		t.Fatalf("url not escaped:\n%s", page)
+	}
+}
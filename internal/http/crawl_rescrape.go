@@ -0,0 +1,260 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"raito/internal/config"
+	"raito/internal/crawler"
+	"raito/internal/db"
+	"raito/internal/jobs"
+	"raito/internal/metrics"
+	"raito/internal/model"
+	"raito/internal/store"
+)
+
+const (
+	// crawlRescrapeReplace stores the new document in place of the URL's
+	// earlier documents in the crawl.
+	crawlRescrapeReplace = "replace"
+	// crawlRescrapeVersion keeps the earlier documents; the new one points
+	// at the latest of them with previousDocumentId.
+	crawlRescrapeVersion = "version"
+
+	maxCrawlRescrapeURLs = 100
+)
+
+// Outcomes of a URL in a rescrape.
+const (
+	rescrapeURLScraped = "scraped"
+	// rescrapeURLDropped means the transform hook dropped the page, or it
+	// set noindex in a crawl that honors robots meta, so the crawl's
+	// earlier documents were kept.
+	rescrapeURLDropped = "dropped"
+	rescrapeURLFailed  = "failed"
+)
+
+// CrawlRescrapeResult is the outcome of one URL of a rescrape.
+type CrawlRescrapeResult struct {
+	URL string `json:"url"`
+	// Status is "scraped", "dropped" or "failed".
+	Status     string `json:"status"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// crawlScope returns a frontier that only answers which URLs a crawl
+// with req's options may scrape; nothing is queued on it.
+func crawlScope(ctx context.Context, cfg *config.Config, req CrawlRequest) (*crawler.Frontier, error) {
+	seeds := crawlSeeds(req)
+	if len(seeds) == 0 {
+		return nil, errors.New("url is required")
+	}
+	_, discovery := crawlDiscoveryOptions(cfg, req)
+	return crawler.NewFrontier(ctx, seeds[0], crawler.FrontierOptions{
+		IncludeSubdomains: discovery.IncludeSubdomains,
+		AllowExternal:     discovery.AllowExternal,
+		ExtraRoots:        seeds[1:],
+	})
+}
+
+// currentCrawlDocuments drops the documents a versioned rescrape has
+// replaced, so crawl-wide reports count every page once.
+func currentCrawlDocuments(docs []db.Document) []db.Document {
+	replaced := map[int64]bool{}
+	for _, d := range docs {
+		var md model.Metadata
+		if json.Unmarshal(d.Metadata, &md) == nil && md.PreviousDocumentID != 0 {
+			replaced[md.PreviousDocumentID] = true
+		}
+	}
+	if len(replaced) == 0 {
+		return docs
+	}
+	current := make([]db.Document, 0, len(docs)-len(replaced))
+	for _, d := range docs {
+		if !replaced[d.ID] {
+			current = append(current, d)
+		}
+	}
+	return current
+}
+
+// runCrawlRescrape scrapes the URLs of a rescrape again with the crawl's
+// options and stores them in the crawl, then refreshes the crawl-wide
+// reports. The crawl completes again as long as it still has documents.
+func runCrawlRescrape(ctx context.Context, cfg *config.Config, st *store.Store, job db.Job, req CrawlRequest, rescrape db.CrawlRescrape) {
+	q := db.New(st.DB)
+	finish := func(status string, results []CrawlRescrapeResult, errMsg string) {
+		params := db.UpdateCrawlRescrapeStatusParams{
+			ID:     rescrape.ID,
+			Status: status,
+			Error:  sql.NullString{String: errMsg, Valid: errMsg != ""},
+		}
+		if results != nil {
+			if raw, err := json.Marshal(results); err == nil {
+				params.Results = pqtype.NullRawMessage{RawMessage: raw, Valid: true}
+			}
+		}
+		_ = q.UpdateCrawlRescrapeStatus(context.Background(), params)
+	}
+	// failJob ends the job after a rescrape that failed. The crawl keeps
+	// its earlier documents, so it only fails when it has none.
+	failJob := func(msg string, results []CrawlRescrapeResult) {
+		finish(string(jobs.StatusFailed), results, msg)
+		if ok, err := q.JobHasPageDocuments(context.Background(), job.ID); err == nil && ok {
+			_ = st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusCompleted), nil)
+			return
+		}
+		_ = st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusFailed), &msg)
+	}
+
+	var urls []string
+	if err := json.Unmarshal(rescrape.Urls, &urls); err != nil {
+		failJob("invalid rescrape urls: "+err.Error(), nil)
+		return
+	}
+	_ = q.UpdateCrawlRescrapeStatus(ctx, db.UpdateCrawlRescrapeStatusParams{ID: rescrape.ID, Status: string(jobs.StatusRunning)})
+
+	pages, err := newCrawlPageScraper(ctx, cfg, st, job.ID, req)
+	if err != nil {
+		failJob(err.Error(), nil)
+		return
+	}
+
+	// A transform hook with the fail policy stops the rescrape like it
+	// stops a crawl.
+	ctx, failRescrape := context.WithCancelCause(ctx)
+	defer failRescrape(nil)
+
+	maxPerJob := urlConcurrency(cfg)
+	if req.MaxConcurrency != nil && *req.MaxConcurrency > 0 && *req.MaxConcurrency < maxPerJob {
+		maxPerJob = *req.MaxConcurrency
+	}
+	metrics.JobRuntimeFrom(ctx).SetPagesTotal(len(urls))
+
+	results := make([]CrawlRescrapeResult, len(urls))
+	sem := make(chan struct{}, maxPerJob)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		results[i] = CrawlRescrapeResult{URL: u, Status: rescrapeURLFailed}
+		select {
+		case <-ctx.Done():
+			results[i].Error = context.Cause(ctx).Error()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(r *CrawlRescrapeResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res, err := pages.fetch(ctx, r.URL, nil)
+			if err != nil {
+				r.Error = err.Error()
+				return
+			}
+			r.StatusCode = res.Status
+			if pages.pageRobotsMeta(res).NoIndex {
+				r.Status = rescrapeURLDropped
+				r.Error = "page sets noindex"
+				return
+			}
+
+			pg, keep, err := pages.page(ctx, res)
+			if err != nil {
+				r.Error = err.Error()
+				failRescrape(err)
+				return
+			}
+			if !keep {
+				r.Status = rescrapeURLDropped
+				return
+			}
+			if err := storeRescrapedPage(ctx, cfg, st, job.ID, rescrape.Mode, r.URL, &pg); err != nil {
+				r.Error = err.Error()
+				return
+			}
+			r.Status = rescrapeURLScraped
+		}(&results[i])
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		msg := jobCancelMessage(ctx)
+		if errors.Is(context.Cause(ctx), errTransformHookFailed) {
+			failJob(msg, results)
+			return
+		}
+		finish(string(jobs.StatusFailed), results, msg)
+		_ = st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	scraped := 0
+	for _, r := range results {
+		if r.Status == rescrapeURLScraped {
+			scraped++
+		}
+	}
+	if scraped == 0 {
+		failJob("no pages successfully scraped", results)
+		return
+	}
+
+	// The incremental summary of the original crawl is kept; the reports
+	// computed from documents are refreshed.
+	output := map[string]any{}
+	if len(job.Output.RawMessage) > 0 {
+		_ = json.Unmarshal(job.Output.RawMessage, &output)
+	}
+	summarizeCrawl(ctx, st, job.ID, req, output)
+	if len(output) > 0 {
+		if raw, err := json.Marshal(output); err == nil {
+			_ = st.SetJobOutput(context.Background(), job.ID, raw)
+		}
+	}
+
+	finish(string(jobs.StatusCompleted), results, "")
+	_ = st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusCompleted), nil)
+}
+
+// storeRescrapedPage stores a rescraped page in the crawl. Earlier
+// documents are matched by the requested URL and the URL the page was
+// finally served from, which differ after redirects.
+func storeRescrapedPage(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, mode, requested string, pg *crawlPage) error {
+	matches := []string{requested}
+	if pg.url != requested {
+		matches = append(matches, pg.url)
+	}
+
+	if mode == crawlRescrapeVersion {
+		prev, err := db.New(st.DB).GetLatestJobDocumentIDByURL(ctx, db.GetLatestJobDocumentIDByURLParams{JobID: jobID, Urls: matches})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		pg.md.PreviousDocumentID = prev
+	}
+	metaBytes, err := json.Marshal(pg.md)
+	if err != nil {
+		return err
+	}
+
+	if mode == crawlRescrapeVersion {
+		err = st.AddDocument(ctx, jobID, pg.url, &pg.markdown, &pg.html, &pg.raw, metaBytes, &pg.statusCode, &pg.engine)
+	} else {
+		err = st.ReplaceDocument(ctx, jobID, matches, pg.url, &pg.markdown, &pg.html, &pg.raw, metaBytes, &pg.statusCode, &pg.engine)
+	}
+	if err != nil {
+		return err
+	}
+	recordDocumentVersion(ctx, cfg, st, jobID, pg.url, pg.markdown, pg.md.Title, pg.statusCode)
+	return nil
+}
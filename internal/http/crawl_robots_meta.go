@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/url"
+
+	"raito/internal/crawler"
+	"raito/internal/scraper"
+)
+
+// CrawlRobotsMetaSummary counts what a crawl run with respectRobotsMeta
+// left out.
+type CrawlRobotsMetaSummary struct {
+	// NoIndexSkipped counts pages that were not stored because they set
+	// noindex.
+	NoIndexSkipped int `json:"noindexSkipped"`
+	// NoFollowSkipped counts links that were not followed because their
+	// page set nofollow or they were marked rel="nofollow".
+	NoFollowSkipped int `json:"nofollowSkipped"`
+}
+
+// pageRobotsMeta returns the robots directives a scraped page sets for
+// the crawler, or none when the crawl does not honor them. Only the HTTP
+// engine captures the X-Robots-Tag header; meta tags are always read.
+func (p *crawlPageScraper) pageRobotsMeta(res *scraper.Result) crawler.RobotsMeta {
+	if !p.robotsMeta {
+		return crawler.RobotsMeta{}
+	}
+	return crawler.ParseRobotsMeta(res.Headers["x-robots-tag"], res.RawHTML, p.cfg.RobotsAgent())
+}
+
+// followLinks returns the links and client-side routes of a rendered page
+// that the crawl may follow, and how many were skipped because of
+// nofollow.
+func (p *crawlPageScraper) followLinks(res *scraper.Result, meta crawler.RobotsMeta) ([]string, int) {
+	links := make([]string, 0, len(res.Links)+len(res.Routes))
+	links = append(links, res.Links...)
+	links = append(links, res.Routes...)
+	if !p.robotsMeta {
+		return links, 0
+	}
+	if meta.NoFollow {
+		return nil, len(links)
+	}
+
+	base, err := url.Parse(res.URL)
+	if err != nil {
+		return links, 0
+	}
+	noFollow := crawler.NoFollowLinks(res.RawHTML, base)
+	if len(noFollow) == 0 {
+		return links, 0
+	}
+	kept := links[:0]
+	for _, l := range links {
+		if !noFollow[l] {
+			kept = append(kept, l)
+		}
+	}
+	return kept, len(links) - len(kept)
+}
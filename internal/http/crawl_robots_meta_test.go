@@ -0,0 +1,39 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"raito/internal/config"
+	"raito/internal/crawler"
+	"raito/internal/scraper"
+)
+
+func TestCrawlPageFollowLinks(t *testing.T) {
+	res := &scraper.Result{
+		URL:     "https://example.com/",
+		RawHTML: `<a href="/a">A</a><a href="/login" rel="nofollow">Login</a>`,
+		Links:   []string{"https://example.com/a", "https://example.com/login"},
+		Routes:  []string{"https://example.com/#/settings"},
+	}
+
+	off := &crawlPageScraper{cfg: &config.Config{}}
+	if links, skipped := off.followLinks(res, crawler.RobotsMeta{NoFollow: true}); len(links) != 3 || skipped != 0 {
+		t.Fatalf("expected every link without respectRobotsMeta, got %v (%d skipped)", links, skipped)
+	}
+
+	on := &crawlPageScraper{cfg: &config.Config{}, robotsMeta: true}
+	links, skipped := on.followLinks(res, on.pageRobotsMeta(res))
+	if want := []string{"https://example.com/a", "https://example.com/#/settings"}; !reflect.DeepEqual(links, want) || skipped != 1 {
+		t.Fatalf("got %v (%d skipped), want %v", links, skipped, want)
+	}
+
+	res.Headers = map[string]string{"x-robots-tag": "noindex, nofollow"}
+	meta := on.pageRobotsMeta(res)
+	if !meta.NoIndex || !meta.NoFollow {
+		t.Fatalf("expected the X-Robots-Tag header to apply, got %+v", meta)
+	}
+	if links, skipped := on.followLinks(res, meta); len(links) != 0 || skipped != 3 {
+		t.Fatalf("expected no links from a nofollow page, got %v (%d skipped)", links, skipped)
+	}
+}
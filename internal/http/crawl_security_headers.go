@@ -0,0 +1,346 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/model"
+	"raito/internal/store"
+)
+
+const (
+	// maxSecurityHeaderURLs caps the example pages listed per header and
+	// per issue.
+	maxSecurityHeaderURLs = 10
+	// maxSecurityHeaderValues caps the distinct values listed per header.
+	maxSecurityHeaderValues = 5
+	// minHSTSMaxAge is 180 days, the lowest max-age generally accepted
+	// for HSTS.
+	minHSTSMaxAge = 180 * 24 * 60 * 60
+)
+
+// securityHeaders are the headers whose coverage the report tracks.
+var securityHeaders = []string{
+	"content-security-policy",
+	"strict-transport-security",
+	"x-frame-options",
+	"x-content-type-options",
+	"referrer-policy",
+	"permissions-policy",
+	"cross-origin-opener-policy",
+	"cross-origin-resource-policy",
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// CrawlSecurityHeadersResponse is the body of
+// GET /v1/crawl/:id/security-headers.
+type CrawlSecurityHeadersResponse struct {
+	Success bool   `json:"success"`
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	CrawlSecurityHeadersReport
+}
+
+// CrawlSecurityHeadersReport summarizes the security headers captured
+// with a crawl's documents.
+type CrawlSecurityHeadersReport struct {
+	// PagesChecked counts pages with captured response headers.
+	PagesChecked int `json:"pagesChecked"`
+	// PagesWithoutHeaders counts pages scraped without captured headers,
+	// such as pages rendered by the browser engine.
+	PagesWithoutHeaders int                    `json:"pagesWithoutHeaders"`
+	Headers             []SecurityHeaderReport `json:"headers"`
+	Issues              []SecurityHeaderIssue  `json:"issues"`
+}
+
+// SecurityHeaderReport is the coverage of one header across the crawl.
+// Strict-Transport-Security only applies to, and is only counted for,
+// HTTPS pages.
+type SecurityHeaderReport struct {
+	Header   string  `json:"header"`
+	Present  int     `json:"present"`
+	Missing  int     `json:"missing"`
+	Coverage float64 `json:"coverage"`
+	// Values lists the most common values, most frequent first.
+	Values []SecurityHeaderValue `json:"values"`
+	// MissingURLs lists up to ten pages without the header.
+	MissingURLs []string `json:"missingUrls"`
+}
+
+// SecurityHeaderValue is one distinct header value and the pages that
+// sent it.
+type SecurityHeaderValue struct {
+	Value string `json:"value"`
+	Pages int    `json:"pages"`
+}
+
+// SecurityHeaderIssue is a weak or risky header configuration found on
+// one or more pages.
+type SecurityHeaderIssue struct {
+	Code     string `json:"code"`
+	Header   string `json:"header"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Pages    int    `json:"pages"`
+	// URLs lists up to ten affected pages.
+	URLs []string `json:"urls"`
+}
+
+var securityIssueSeverityOrder = map[string]int{"warning": 0, "info": 1}
+
+// securityHeadersReport builds the security header report from the
+// headers stored in each document's metadata.
+func securityHeadersReport(docs []db.Document) CrawlSecurityHeadersReport {
+	report := CrawlSecurityHeadersReport{Headers: []SecurityHeaderReport{}, Issues: []SecurityHeaderIssue{}}
+	coverage := make(map[string]*SecurityHeaderReport, len(securityHeaders))
+	values := make(map[string]map[string]int, len(securityHeaders))
+	for _, name := range securityHeaders {
+		coverage[name] = &SecurityHeaderReport{Header: name, Values: []SecurityHeaderValue{}, MissingURLs: []string{}}
+		values[name] = map[string]int{}
+	}
+	issues := map[string]*SecurityHeaderIssue{}
+	addIssue := func(code, header, severity, message, pageURL string) {
+		is, ok := issues[code]
+		if !ok {
+			is = &SecurityHeaderIssue{Code: code, Header: header, Severity: severity, Message: message, URLs: []string{}}
+			issues[code] = is
+		}
+		is.Pages++
+		if len(is.URLs) < maxSecurityHeaderURLs {
+			is.URLs = append(is.URLs, pageURL)
+		}
+	}
+
+	for _, d := range docs {
+		var md model.Metadata
+		if err := json.Unmarshal(d.Metadata, &md); err != nil || len(md.Headers) == 0 {
+			report.PagesWithoutHeaders++
+			continue
+		}
+		report.PagesChecked++
+		https := false
+		if u, err := url.Parse(d.Url); err == nil {
+			https = strings.EqualFold(u.Scheme, "https")
+		}
+
+		for _, name := range securityHeaders {
+			if name == "strict-transport-security" && !https {
+				continue
+			}
+			h := coverage[name]
+			if v, ok := md.Headers[name]; ok {
+				h.Present++
+				values[name][v]++
+				continue
+			}
+			h.Missing++
+			if len(h.MissingURLs) < maxSecurityHeaderURLs {
+				h.MissingURLs = append(h.MissingURLs, d.Url)
+			}
+		}
+
+		for _, is := range checkSecurityHeaders(md.Headers, https) {
+			addIssue(is.Code, is.Header, is.Severity, is.Message, d.Url)
+		}
+	}
+
+	for _, name := range securityHeaders {
+		h := coverage[name]
+		if total := h.Present + h.Missing; total > 0 {
+			h.Coverage = float64(h.Present) / float64(total)
+		}
+		for v, n := range values[name] {
+			h.Values = append(h.Values, SecurityHeaderValue{Value: v, Pages: n})
+		}
+		sort.Slice(h.Values, func(i, j int) bool {
+			if h.Values[i].Pages != h.Values[j].Pages {
+				return h.Values[i].Pages > h.Values[j].Pages
+			}
+			return h.Values[i].Value < h.Values[j].Value
+		})
+		if len(h.Values) > maxSecurityHeaderValues {
+			h.Values = h.Values[:maxSecurityHeaderValues]
+		}
+		report.Headers = append(report.Headers, *h)
+	}
+
+	for _, is := range issues {
+		report.Issues = append(report.Issues, *is)
+	}
+	sort.Slice(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if securityIssueSeverityOrder[a.Severity] != securityIssueSeverityOrder[b.Severity] {
+			return securityIssueSeverityOrder[a.Severity] < securityIssueSeverityOrder[b.Severity]
+		}
+		if a.Pages != b.Pages {
+			return a.Pages > b.Pages
+		}
+		return a.Code < b.Code
+	})
+	return report
+}
+
+// checkSecurityHeaders returns the issues with one page's headers. Pages
+// and URLs are left unset.
+func checkSecurityHeaders(headers map[string]string, https bool) []SecurityHeaderIssue {
+	var out []SecurityHeaderIssue
+	add := func(code, header, severity, message string) {
+		out = append(out, SecurityHeaderIssue{Code: code, Header: header, Severity: severity, Message: message})
+	}
+
+	csp := parseCSP(headers["content-security-policy"])
+	if https {
+		if v, ok := headers["strict-transport-security"]; ok {
+			if maxAge, ok := hstsMaxAge(v); !ok || maxAge < minHSTSMaxAge {
+				add("hsts_short_max_age", "strict-transport-security", "warning",
+					fmt.Sprintf("max-age is below %d seconds (180 days)", minHSTSMaxAge))
+			}
+		}
+	}
+
+	// script-src falls back to default-src when absent.
+	scripts, ok := csp["script-src"]
+	if !ok {
+		scripts = csp["default-src"]
+	}
+	for _, src := range scripts {
+		switch src {
+		case "'unsafe-inline'":
+			add("csp_unsafe_inline", "content-security-policy", "warning", "script-src allows 'unsafe-inline'")
+		case "'unsafe-eval'":
+			add("csp_unsafe_eval", "content-security-policy", "warning", "script-src allows 'unsafe-eval'")
+		}
+	}
+
+	if _, ok := csp["frame-ancestors"]; !ok {
+		if _, ok := headers["x-frame-options"]; !ok {
+			add("missing_frame_protection", "x-frame-options", "warning",
+				"neither X-Frame-Options nor CSP frame-ancestors restricts framing")
+		}
+	}
+
+	if v, ok := headers["x-content-type-options"]; ok && !strings.EqualFold(strings.TrimSpace(v), "nosniff") {
+		add("invalid_content_type_options", "x-content-type-options", "warning", "X-Content-Type-Options should be nosniff")
+	}
+
+	if v, ok := headers["referrer-policy"]; ok {
+		// The last recognized token wins; unsafe-url leaks full URLs.
+		tokens := strings.Split(v, ",")
+		if strings.EqualFold(strings.TrimSpace(tokens[len(tokens)-1]), "unsafe-url") {
+			add("unsafe_referrer_policy", "referrer-policy", "warning", "Referrer-Policy unsafe-url sends full URLs to other origins")
+		}
+	}
+
+	if v := headers["server"]; versionPattern.MatchString(v) {
+		add("server_version_disclosed", "server", "info", "Server header discloses a software version")
+	}
+	if _, ok := headers["x-powered-by"]; ok {
+		add("powered_by_disclosed", "x-powered-by", "info", "X-Powered-By discloses the server framework")
+	}
+	return out
+}
+
+// parseCSP splits a Content-Security-Policy into its directives. Names
+// are lower-cased; the first occurrence of a directive wins.
+func parseCSP(policy string) map[string][]string {
+	out := map[string][]string{}
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, ok := out[name]; ok {
+			continue
+		}
+		sources := make([]string, 0, len(fields)-1)
+		for _, f := range fields[1:] {
+			sources = append(sources, strings.ToLower(f))
+		}
+		out[name] = sources
+	}
+	return out
+}
+
+// hstsMaxAge returns the max-age directive of a Strict-Transport-Security
+// value.
+func hstsMaxAge(v string) (int, bool) {
+	for _, directive := range strings.Split(v, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+		n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`))
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// crawlSecurityHeadersHandler reports security header coverage across the
+// pages a crawl has stored so far.
+func crawlSecurityHeadersHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid crawl id",
+		})
+	}
+
+	job, docs, err := st.GetCrawlJobAndDocuments(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "crawl job not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "CRAWL_JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "crawl job not found",
+		})
+	}
+	if job.Type != "crawl" {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "crawl job not found",
+		})
+	}
+
+	return c.JSON(CrawlSecurityHeadersResponse{
+		Success:                    true,
+		ID:                         job.ID.String(),
+		Status:                     job.Status,
+		CrawlSecurityHeadersReport: securityHeadersReport(docs),
+	})
+}
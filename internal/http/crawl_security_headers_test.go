@@ -0,0 +1,64 @@
+package http
+
+import (
+	"testing"
+
+	"raito/internal/db"
+	"raito/internal/model"
+)
+
+func TestSecurityHeadersReport(t *testing.T) {
+	docs := []db.Document{
+		metadataDoc(t, 1, "https://example.com/", model.Metadata{Headers: map[string]string{
+			"strict-transport-security": "max-age=31536000; includeSubDomains",
+			"content-security-policy":   "default-src 'self'; frame-ancestors 'none'",
+			"x-content-type-options":    "nosniff",
+			"server":                    "nginx",
+		}}),
+		metadataDoc(t, 2, "https://example.com/a", model.Metadata{Headers: map[string]string{
+			"strict-transport-security": "max-age=3600",
+			"content-security-policy":   "default-src 'self'; script-src 'self' 'unsafe-inline'",
+			"server":                    "nginx/1.25.3",
+			"x-powered-by":              "Express",
+		}}),
+		// HSTS is not expected over plain HTTP.
+		metadataDoc(t, 3, "http://example.com/b", model.Metadata{Headers: map[string]string{
+			"x-frame-options": "DENY",
+		}}),
+		// Browser-rendered pages have no captured headers.
+		metadataDoc(t, 4, "https://example.com/c", model.Metadata{}),
+	}
+
+	report := securityHeadersReport(docs)
+	if report.PagesChecked != 3 || report.PagesWithoutHeaders != 1 {
+		t.Fatalf("unexpected page counts %+v", report)
+	}
+
+	byHeader := map[string]SecurityHeaderReport{}
+	for _, h := range report.Headers {
+		byHeader[h.Header] = h
+	}
+	if h := byHeader["strict-transport-security"]; h.Present != 2 || h.Missing != 0 || h.Coverage != 1 {
+		t.Fatalf("unexpected HSTS coverage %+v", h)
+	}
+	if h := byHeader["x-frame-options"]; h.Present != 1 || h.Missing != 2 || len(h.MissingURLs) != 2 {
+		t.Fatalf("unexpected X-Frame-Options coverage %+v", h)
+	}
+
+	byCode := map[string]SecurityHeaderIssue{}
+	for _, is := range report.Issues {
+		byCode[is.Code] = is
+	}
+	for _, code := range []string{"hsts_short_max_age", "csp_unsafe_inline", "server_version_disclosed", "powered_by_disclosed"} {
+		if is, ok := byCode[code]; !ok || is.Pages != 1 || is.URLs[0] != "https://example.com/a" {
+			t.Fatalf("expected %s on /a, got %+v", code, byCode[code])
+		}
+	}
+	// frame-ancestors on / and X-Frame-Options on /b both protect against framing.
+	if is := byCode["missing_frame_protection"]; is.Pages != 1 {
+		t.Fatalf("unexpected missing_frame_protection %+v", is)
+	}
+	if report.Issues[len(report.Issues)-1].Severity != "info" {
+		t.Fatalf("expected info issues last, got %+v", report.Issues)
+	}
+}
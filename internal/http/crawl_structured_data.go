@@ -0,0 +1,217 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+	"raito/internal/structured"
+)
+
+// CrawlStructuredDataSummary totals the structured data validation of a
+// crawl. It is returned with the crawl status.
+type CrawlStructuredDataSummary struct {
+	PagesChecked  int `json:"pagesChecked"`
+	PagesWithData int `json:"pagesWithData"`
+	Items         int `json:"items"`
+	Errors        int `json:"errors"`
+	Warnings      int `json:"warnings"`
+}
+
+// CrawlStructuredDataReport is the per-page structured data validation of
+// a completed crawl. It is stored as job output under "structuredData".
+type CrawlStructuredDataReport struct {
+	CrawlStructuredDataSummary
+	Pages []CrawlStructuredDataPage `json:"pages"`
+}
+
+// CrawlStructuredDataPage lists the structured data types found on one
+// page and their issues.
+type CrawlStructuredDataPage struct {
+	DocumentID int64              `json:"documentId"`
+	URL        string             `json:"url"`
+	Types      []string           `json:"types"`
+	Errors     int                `json:"errors"`
+	Warnings   int                `json:"warnings"`
+	Issues     []structured.Issue `json:"issues"`
+}
+
+// validateCrawlStructuredData checks the JSON-LD and microdata in the raw
+// HTML of every stored page of a crawl.
+func validateCrawlStructuredData(ctx context.Context, st crawlDocStore, jobID uuid.UUID) (*CrawlStructuredDataReport, error) {
+	_, docs, err := st.GetCrawlJobAndDocuments(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	docs = currentCrawlDocuments(docs)
+
+	report := &CrawlStructuredDataReport{Pages: make([]CrawlStructuredDataPage, 0, len(docs))}
+	for _, d := range docs {
+		html := d.RawHtml.String
+		if html == "" {
+			html = d.Html.String
+		}
+		r := structured.Validate(html)
+		page := CrawlStructuredDataPage{
+			DocumentID: d.ID,
+			URL:        d.Url,
+			Types:      r.Types(),
+			Errors:     r.Errors(),
+			Warnings:   r.Warnings(),
+			Issues:     r.Issues,
+		}
+		if page.Types == nil {
+			page.Types = []string{}
+		}
+		report.PagesChecked++
+		if len(r.Items) > 0 {
+			report.PagesWithData++
+		}
+		report.Items += len(r.Items)
+		report.Errors += page.Errors
+		report.Warnings += page.Warnings
+		report.Pages = append(report.Pages, page)
+	}
+	return report, nil
+}
+
+// jobStructuredDataReport decodes the structured data report from a job's
+// output; it is nil for crawls that did not request it.
+func jobStructuredDataReport(job db.Job) *CrawlStructuredDataReport {
+	if !job.Output.Valid {
+		return nil
+	}
+	var out struct {
+		StructuredData *CrawlStructuredDataReport `json:"structuredData"`
+	}
+	if err := json.Unmarshal(job.Output.RawMessage, &out); err != nil {
+		return nil
+	}
+	return out.StructuredData
+}
+
+// structuredDataCSV writes one row per issue, and one row without an
+// issue for pages that have none, so every crawled page appears.
+func structuredDataCSV(report *CrawlStructuredDataReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"url", "document_id", "types", "source", "item_type", "property", "severity", "code", "message"})
+	for _, p := range report.Pages {
+		base := []string{p.URL, strconv.FormatInt(p.DocumentID, 10), strings.Join(p.Types, " ")}
+		if len(p.Issues) == 0 {
+			_ = w.Write(append(base, "", "", "", "", "", ""))
+			continue
+		}
+		for _, is := range p.Issues {
+			_ = w.Write(append(append([]string{}, base...), is.Source, is.Type, is.Property, is.Severity, is.Code, is.Message))
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// crawlStructuredDataHandler downloads a crawl's structured data report
+// as JSON (default) or CSV (?format=csv).
+func crawlStructuredDataHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid crawl id",
+		})
+	}
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "csv" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "format must be json or csv",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "crawl job not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "CRAWL_JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "crawl job not found",
+		})
+	}
+	if job.Type != "crawl" {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "crawl job not found",
+		})
+	}
+	if job.Status != "completed" {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_NOT_COMPLETED",
+			Error:   "job is not completed yet",
+		})
+	}
+
+	report := jobStructuredDataReport(job)
+	if report == nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "STRUCTURED_DATA_NOT_AVAILABLE",
+			Error:   "this crawl was not run with structuredData: true",
+		})
+	}
+
+	filenameBase := buildDownloadBaseName("structured-data", job.Url, job.CreatedAt, job.ID)
+	if format == "csv" {
+		body, err := structuredDataCSV(report)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Success: false,
+				Code:    "STRUCTURED_DATA_EXPORT_FAILED",
+				Error:   err.Error(),
+			})
+		}
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, contentDisposition(filenameBase+".csv"))
+		return c.Send(body)
+	}
+
+	raw, err := json.Marshal(report)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "STRUCTURED_DATA_EXPORT_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	return sendJSONDownload(c, filenameBase+".json", raw)
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"raito/internal/db"
+)
+
+func TestValidateCrawlStructuredData(t *testing.T) {
+	jobID := uuid.New()
+	product := `<script type="application/ld+json">{"@context":"https://schema.org","@type":"Product","name":"Widget"}</script>`
+	st := &fakeCrawlDocStore{
+		jobs: map[uuid.UUID]db.Job{jobID: {ID: jobID}},
+		docs: map[uuid.UUID][]db.Document{
+			jobID: {
+				{ID: 1, Url: "https://example.com/widget", RawHtml: sql.NullString{String: product, Valid: true}},
+				{ID: 2, Url: "https://example.com/about", RawHtml: sql.NullString{String: "<p>About</p>", Valid: true}},
+			},
+		},
+	}
+
+	report, err := validateCrawlStructuredData(context.Background(), st, jobID)
+	if err != nil {
+		t.Fatalf("validateCrawlStructuredData: %v", err)
+	}
+	if report.PagesChecked != 2 || report.PagesWithData != 1 || report.Items != 1 || report.Errors == 0 {
+		t.Fatalf("unexpected summary %+v", report.CrawlStructuredDataSummary)
+	}
+	if got := report.Pages[0].Types; len(got) != 1 || got[0] != "Product" {
+		t.Fatalf("unexpected types %v", got)
+	}
+
+	raw, _ := json.Marshal(map[string]any{"structuredData": report})
+	job := db.Job{Output: pqtype.NullRawMessage{RawMessage: raw, Valid: true}}
+	decoded := jobStructuredDataReport(job)
+	if decoded == nil || decoded.Errors != report.Errors || len(decoded.Pages) != 2 {
+		t.Fatalf("unexpected decoded report %+v", decoded)
+	}
+	if jobStructuredDataReport(db.Job{}) != nil {
+		t.Fatalf("expected no report without output")
+	}
+
+	body, err := structuredDataCSV(report)
+	if err != nil {
+		t.Fatalf("structuredDataCSV: %v", err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	// Header, one row per issue of the product page, and one for the page
+	// without structured data.
+	if want := 1 + len(report.Pages[0].Issues) + 1; len(rows) != want {
+		t.Fatalf("expected %d rows, got %d:\n%s", want, len(rows), body)
+	}
+	if last := rows[len(rows)-1]; last[0] != "https://example.com/about" || last[6] != "" {
+		t.Fatalf("unexpected row for page without issues: %v", last)
+	}
+}
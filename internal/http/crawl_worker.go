@@ -2,8 +2,11 @@ package http
 
 import (
 	"context"
+	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -13,10 +16,13 @@ import (
 	"raito/internal/config"
 	"raito/internal/crawler"
 	"raito/internal/db"
+	"raito/internal/extract"
+	"raito/internal/jobinput"
 	"raito/internal/jobs"
 	"raito/internal/llm"
 	"raito/internal/metrics"
 	"raito/internal/model"
+	"raito/internal/plugins"
 	"raito/internal/scraper"
 	"raito/internal/scrapeutil"
 	"raito/internal/services"
@@ -33,10 +39,14 @@ func StartCrawlWorker(ctx context.Context, cfg *config.Config, st *store.Store)
 		Extract:     NewExtractJobExecutor(cfg, st),
 		BatchScrape: NewBatchScrapeJobExecutor(cfg, st),
 		Scrape:      NewScrapeJobExecutor(cfg, st),
+		Research:    NewResearchJobExecutor(cfg, st),
+		Summarize:   NewSummarizeJobExecutor(cfg, st),
 	}
 
 	runner := jobs.NewRunner(cfg, st, execs)
 	go runner.Start(ctx)
+
+	startHostStatsFlusher(ctx, st)
 }
 
 // crawlJobExecutor implements jobs.CrawlJobExecutor using the existing
@@ -52,9 +62,9 @@ func NewCrawlJobExecutor(cfg *config.Config, st *store.Store) jobs.CrawlJobExecu
 
 func (e *crawlJobExecutor) ExecuteCrawlJob(ctx context.Context, job db.Job) {
 	var req CrawlRequest
-	if err := json.Unmarshal(job.Input, &req); err != nil {
+	if err := jobinput.Decode(job.Type, job.Input, &req); err != nil {
 		msg := "invalid crawl job input: " + err.Error()
-		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusFailed), &msg)
+		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusDeadLetter), &msg)
 		return
 	}
 
@@ -63,9 +73,26 @@ func (e *crawlJobExecutor) ExecuteCrawlJob(ctx context.Context, job db.Job) {
 		req.URL = job.Url
 	}
 
+	// A crawl queued again for a rescrape only scrapes the rescrape's URLs.
+	rescrape, err := db.New(e.st.DB).GetPendingCrawlRescrape(ctx, job.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		msg := "CRAWL_RESCRAPE_LOOKUP_FAILED: " + err.Error()
+		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
 	// Mark job running before we start work.
 	_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusRunning), nil)
 
+	if job.TenantID.Valid {
+		ctx = context.WithValue(ctx, "tenant_id", job.TenantID.UUID)
+	}
+
+	if rescrape.ID != uuid.Nil {
+		runCrawlRescrape(ctx, e.cfg, e.st, job, req, rescrape)
+		return
+	}
+
 	// Let the job inherit the worker context; per-request timeouts are
 	// applied inside runCrawlJob for HTTP and LLM.
 	runCrawlJob(ctx, e.cfg, e.st, job.ID, req)
@@ -84,9 +111,9 @@ func NewScrapeJobExecutor(cfg *config.Config, st *store.Store) jobs.ScrapeJobExe
 
 func (e *scrapeJobExecutor) ExecuteScrapeJob(ctx context.Context, job db.Job) {
 	var req ScrapeRequest
-	if err := json.Unmarshal(job.Input, &req); err != nil {
+	if err := jobinput.Decode(job.Type, job.Input, &req); err != nil {
 		msg := "SCRAPE_FAILED: invalid scrape job input: " + err.Error()
-		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusFailed), &msg)
+		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusDeadLetter), &msg)
 		return
 	}
 
@@ -94,9 +121,19 @@ func (e *scrapeJobExecutor) ExecuteScrapeJob(ctx context.Context, job db.Job) {
 		req.URL = job.Url
 	}
 
+	if job.TenantID.Valid {
+		ctx = context.WithValue(ctx, "tenant_id", job.TenantID.UUID)
+	}
+
 	_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusRunning), nil)
 
-	runScrapeJob(ctx, e.cfg, e.st, job.ID, req)
+	// A sync caller has been waiting since the job was enqueued, so its
+	// timeout counts from then.
+	start := time.Now()
+	if job.Sync {
+		start = job.CreatedAt
+	}
+	runScrapeJob(ctx, e.cfg, e.st, job.ID, req, start)
 }
 
 // mapJobExecutor implements jobs.MapJobExecutor using the existing
@@ -112,9 +149,9 @@ func NewMapJobExecutor(cfg *config.Config, st *store.Store) jobs.MapJobExecutor
 
 func (e *mapJobExecutor) ExecuteMapJob(ctx context.Context, job db.Job) {
 	var req MapRequest
-	if err := json.Unmarshal(job.Input, &req); err != nil {
+	if err := jobinput.Decode(job.Type, job.Input, &req); err != nil {
 		msg := "MAP_FAILED: invalid map job input: " + err.Error()
-		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusFailed), &msg)
+		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusDeadLetter), &msg)
 		return
 	}
 
@@ -133,6 +170,23 @@ type jobStore interface {
 	SetJobOutput(ctx context.Context, id uuid.UUID, output json.RawMessage) error
 }
 
+// jobEventStore is implemented by stores that keep a job event timeline.
+type jobEventStore interface {
+	AddJobEvent(ctx context.Context, jobID uuid.UUID, eventType, message string, data map[string]any) error
+}
+
+// recordDiscoveryFinished adds a discovery_finished event to the job's
+// timeline when st keeps one.
+func recordDiscoveryFinished(ctx context.Context, st any, jobID uuid.UUID, discovered, queued int) {
+	es, ok := st.(jobEventStore)
+	if !ok {
+		return
+	}
+	_ = es.AddJobEvent(ctx, jobID, store.JobEventDiscoveryFinished,
+		fmt.Sprintf("discovered %d URLs, processing %d", discovered, queued),
+		map[string]any{"discovered": discovered, "queued": queued})
+}
+
 // extractJobExecutor implements jobs.ExtractJobExecutor using the existing
 // extract job implementation in this package.
 type extractJobExecutor struct {
@@ -146,9 +200,9 @@ func NewExtractJobExecutor(cfg *config.Config, st jobStore) jobs.ExtractJobExecu
 
 func (e *extractJobExecutor) ExecuteExtractJob(ctx context.Context, job db.Job) {
 	var req ExtractRequest
-	if err := json.Unmarshal(job.Input, &req); err != nil {
+	if err := jobinput.Decode(job.Type, job.Input, &req); err != nil {
 		msg := "EXTRACT_FAILED: invalid extract job input: " + err.Error()
-		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusFailed), &msg)
+		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusDeadLetter), &msg)
 		return
 	}
 
@@ -158,9 +212,67 @@ func (e *extractJobExecutor) ExecuteExtractJob(ctx context.Context, job db.Job)
 
 	_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusRunning), nil)
 
+	if job.TenantID.Valid {
+		ctx = context.WithValue(ctx, "tenant_id", job.TenantID.UUID)
+	}
+
 	runExtractJob(ctx, e.cfg, e.st, job.ID, req)
 }
 
+// researchJobExecutor implements jobs.ResearchJobExecutor.
+type researchJobExecutor struct {
+	cfg *config.Config
+	st  *store.Store
+}
+
+func NewResearchJobExecutor(cfg *config.Config, st *store.Store) jobs.ResearchJobExecutor {
+	return &researchJobExecutor{cfg: cfg, st: st}
+}
+
+func (e *researchJobExecutor) ExecuteResearchJob(ctx context.Context, job db.Job) {
+	var req ResearchRequest
+	if err := jobinput.Decode(job.Type, job.Input, &req); err != nil {
+		msg := "RESEARCH_FAILED: invalid research job input: " + err.Error()
+		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusDeadLetter), &msg)
+		return
+	}
+
+	_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusRunning), nil)
+
+	if job.TenantID.Valid {
+		ctx = context.WithValue(ctx, "tenant_id", job.TenantID.UUID)
+	}
+
+	runResearchJob(ctx, e.cfg, e.st, job.ID, req)
+}
+
+// summarizeJobExecutor implements jobs.SummarizeJobExecutor.
+type summarizeJobExecutor struct {
+	cfg *config.Config
+	st  *store.Store
+}
+
+func NewSummarizeJobExecutor(cfg *config.Config, st *store.Store) jobs.SummarizeJobExecutor {
+	return &summarizeJobExecutor{cfg: cfg, st: st}
+}
+
+func (e *summarizeJobExecutor) ExecuteSummarizeJob(ctx context.Context, job db.Job) {
+	var req SummarizeRequest
+	if err := jobinput.Decode(job.Type, job.Input, &req); err != nil {
+		msg := "SUMMARIZE_FAILED: invalid summarize job input: " + err.Error()
+		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusDeadLetter), &msg)
+		return
+	}
+
+	_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusRunning), nil)
+
+	if job.TenantID.Valid {
+		ctx = context.WithValue(ctx, "tenant_id", job.TenantID.UUID)
+	}
+
+	runSummarizeJob(ctx, e.cfg, e.st, job.ID, req)
+}
+
 // batchScrapeJobExecutor implements jobs.BatchScrapeJobExecutor using the
 // existing batch scrape job implementation in this package.
 type batchScrapeJobExecutor struct {
@@ -174,9 +286,9 @@ func NewBatchScrapeJobExecutor(cfg *config.Config, st *store.Store) jobs.BatchSc
 
 func (e *batchScrapeJobExecutor) ExecuteBatchScrapeJob(ctx context.Context, job db.Job) {
 	var req BatchScrapeRequest
-	if err := json.Unmarshal(job.Input, &req); err != nil {
+	if err := jobinput.Decode(job.Type, job.Input, &req); err != nil {
 		msg := "BATCH_SCRAPE_FAILED: invalid batch scrape job input: " + err.Error()
-		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusFailed), &msg)
+		_ = e.st.UpdateCrawlJobStatus(context.Background(), job.ID, string(jobs.StatusDeadLetter), &msg)
 		return
 	}
 
@@ -185,10 +297,36 @@ func (e *batchScrapeJobExecutor) ExecuteBatchScrapeJob(ctx context.Context, job
 	runBatchScrapeJob(ctx, e.cfg, e.st, job.ID, req)
 }
 
-// runCrawlJob performs the actual crawl for a single job ID using the
-// provided crawl request options.
-func runCrawlJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, req CrawlRequest) {
-	// Derive discovery options from request and config.
+// crawlCandidateFactor is how many more URLs than the crawl limit are
+// discovered before prioritization trims the list back to the limit.
+const crawlCandidateFactor = 4
+
+// crawlMaxDepth returns how many links deep a crawl follows from its
+// seeds: maxDiscoveryDepth, else crawler.maxDepthDefault. It is -1, no
+// cap, when neither is set.
+func crawlMaxDepth(cfg *config.Config, req CrawlRequest) int {
+	if req.MaxDiscoveryDepth != nil && *req.MaxDiscoveryDepth >= 0 {
+		return *req.MaxDiscoveryDepth
+	}
+	if cfg.Crawler.MaxDepthDefault > 0 {
+		return cfg.Crawler.MaxDepthDefault
+	}
+	return -1
+}
+
+// crawlDelay returns the least time between a crawl's requests to one
+// host: delay, else crawler.delayMs.
+func crawlDelay(cfg *config.Config, req CrawlRequest) time.Duration {
+	if req.Delay != nil && *req.Delay >= 0 {
+		return time.Duration(*req.Delay * float64(time.Second))
+	}
+	return time.Duration(cfg.Crawler.DelayMs) * time.Millisecond
+}
+
+// crawlDiscoveryOptions derives a crawl's page limit and the map options
+// every seed is discovered with from the request and config. URL is left
+// for the caller to set per seed.
+func crawlDiscoveryOptions(cfg *config.Config, req CrawlRequest) (int, crawler.MapOptions) {
 	limit := cfg.Crawler.MaxPagesDefault
 	if req.Limit != nil && *req.Limit > 0 {
 		limit = *req.Limit
@@ -219,101 +357,539 @@ func runCrawlJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID
 		sitemapMode = "include"
 	}
 
-	timeout := time.Duration(cfg.Scraper.TimeoutMs) * time.Millisecond
-
-	// Discover URLs
-	mapRes, err := crawler.Map(ctx, crawler.MapOptions{
-		URL:               req.URL,
-		Limit:             limit,
-		Search:            "",
+	return limit, crawler.MapOptions{
+		Limit:             limit * crawlCandidateFactor,
 		IncludeSubdomains: includeSubdomains,
 		IgnoreQueryParams: ignoreQueryParams,
 		AllowExternal:     allowExternal,
 		SitemapMode:       sitemapMode,
-		Timeout:           timeout,
+		Timeout:           time.Duration(cfg.Scraper.TimeoutMs) * time.Millisecond,
 		RespectRobots:     cfg.Robots.Respect,
-		UserAgent:         cfg.Scraper.UserAgent,
-	})
-	if err != nil {
-		msg := err.Error()
-		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-		return
+		UserAgent:         cfg.RequestUserAgent(),
+		RobotsAgent:       cfg.RobotsAgent(),
+		OnRobotsBlocked:   recordHostRobotsBlock,
+		RespectRobotsMeta: req.RespectRobotsMeta != nil && *req.RespectRobotsMeta,
+	}
+}
+
+// crawlPageScraper scrapes single pages with a crawl's options and turns
+// them into documents. Full crawls and rescrapes share it.
+type crawlPageScraper struct {
+	cfg     *config.Config
+	st      *store.Store
+	jobID   uuid.UUID
+	req     CrawlRequest
+	timeout time.Duration
+	spa     bool
+	// robotsMeta honors noindex and nofollow directives of pages.
+	robotsMeta bool
+	scraper    scraper.Scraper
+	limiter    *crawler.HostLimiter
+	// pacer spaces the crawl's own requests to each host by delay, on top
+	// of the process-wide robots.txt Crawl-delay.
+	pacer   *crawler.HostPacer
+	delay   time.Duration
+	headers map[string]string
+	// authHeaders carry the crawl's target auth. They are only sent to
+	// authHosts, the hosts of the crawl's seeds.
+	authHeaders map[string]string
+	authHosts   map[string]bool
+	locOpts     *scraper.LocationOptions
+	hook        *transformHook
+
+	downloadImages  bool
+	wantA11y        bool
+	wantPerformance bool
+	wantAuto        bool
+	wantSummary     bool
+	summaryPrompt   string
+	hasJSON         bool
+	jsonPrompt      string
+	jsonSchema      map[string]any
+	wantBranding    bool
+	brandingPrompt  string
+	pluginRegistry  *plugins.Registry
+	pluginFormats   []plugins.Invocation
+	classifier      *pageClassifier
+
+	llmClient  llm.Client
+	provider   llm.Provider
+	modelName  string
+	llmTimeout time.Duration
+}
+
+// crawlPage is a scraped page ready to be stored.
+type crawlPage struct {
+	url        string
+	markdown   string
+	html       string
+	raw        string
+	statusCode int32
+	engine     string
+	md         model.Metadata
+}
+
+// newCrawlPageScraper prepares the scraper, formats and transform hook of
+// a crawl. Errors are the job's failure message.
+func newCrawlPageScraper(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, req CrawlRequest) (*crawlPageScraper, error) {
+	p := &crawlPageScraper{
+		cfg:            cfg,
+		st:             st,
+		jobID:          jobID,
+		req:            req,
+		timeout:        time.Duration(cfg.Scraper.TimeoutMs) * time.Millisecond,
+		limiter:        sharedHostLimiter(cfg),
+		pacer:          crawler.NewHostPacer(),
+		delay:          crawlDelay(cfg, req),
+		downloadImages: req.DownloadImages != nil && *req.DownloadImages,
+		robotsMeta:     req.RespectRobotsMeta != nil && *req.RespectRobotsMeta,
 	}
 
-	urls := make([]string, 0, len(mapRes.Links)+1)
-	urls = append(urls, req.URL)
-	for _, l := range mapRes.Links {
-		urls = append(urls, l.URL)
+	// SPA crawls render every page in the browser and queue the
+	// client-side routes each page reveals.
+	p.spa = req.SPA != nil && *req.SPA
+	if p.spa && !cfg.Rod.Enabled {
+		return nil, errors.New("SPA_CRAWL_NOT_AVAILABLE: spa crawls require browser scraping, but rod is disabled in server configuration")
+	}
+
+	// The a11y and performance formats load every page in the browser
+	// again after it is scraped.
+	p.wantA11y = scrapeutil.WantsFormat(req.Formats, "a11y")
+	if p.wantA11y && !cfg.Rod.Enabled {
+		return nil, errors.New("A11Y_NOT_AVAILABLE: a11y format requires browser scraping, but rod is disabled in server configuration")
+	}
+	p.wantPerformance = scrapeutil.WantsFormat(req.Formats, "performance")
+	if p.wantPerformance && !cfg.Rod.Enabled {
+		return nil, errors.New("PERFORMANCE_NOT_AVAILABLE: performance format requires browser scraping, but rod is disabled in server configuration")
 	}
 
 	// Determine whether we should compute summaries and/or json/branding for this crawl.
-	wantSummary := scrapeutil.WantsFormat(req.Formats, "summary")
-	hasJSON, jsonPrompt, jsonSchema := scrapeutil.GetJSONFormatConfig(req.Formats)
-	wantBranding, brandingPrompt := scrapeutil.GetBrandingFormatConfig(req.Formats)
-	wantLLM := wantSummary || hasJSON || wantBranding
-
-	var (
-		llmClient  llm.Client
-		provider   llm.Provider
-		modelName  string
-		llmTimeout time.Duration
-	)
+	p.wantSummary, p.summaryPrompt = scrapeutil.GetSummaryFormatConfig(req.Formats)
+	p.hasJSON, p.jsonPrompt, p.jsonSchema = scrapeutil.GetJSONFormatConfig(req.Formats)
+	p.wantBranding, p.brandingPrompt = scrapeutil.GetBrandingFormatConfig(req.Formats)
+	wantLLM := p.wantSummary || p.hasJSON || p.wantBranding
+	wantClassify, classifyLabels := scrapeutil.GetClassifyFormatConfig(req.Formats)
+	p.pluginRegistry = plugins.NewRegistry(cfg.Plugins)
+	p.pluginFormats = p.pluginRegistry.Requested(req.Formats)
+	p.wantAuto = scrapeutil.WantsFormat(req.Formats, services.FormatAuto)
+
+	// Use a Firecrawl-style default branding prompt if the user did not
+	// provide one in the formats array.
+	if p.wantBranding && p.brandingPrompt == "" {
+		p.brandingPrompt = "You are a brand design expert analyzing a website. Analyze the page and return a single JSON object describing the brand, matching this structure as closely as possible: " +
+			"{colorScheme?: 'light'|'dark', colors?: {primary?: string, secondary?: string, accent?: string, background?: string, textPrimary?: string, textSecondary?: string, link?: string, success?: string, warning?: string, error?: string}, " +
+			"typography?: {fontFamilies?: {primary?: string, heading?: string, code?: string}, fontStacks?: {primary?: string[], heading?: string[], body?: string[], paragraph?: string[]}, fontSizes?: {h1?: string, h2?: string, h3?: string, body?: string, small?: string}}, " +
+			"spacing?: {baseUnit?: number, borderRadius?: string}, components?: {buttonPrimary?: {background?: string, textColor?: string, borderColor?: string, borderRadius?: string}, buttonSecondary?: {...}}, " +
+			"images?: {logo?: string|null, favicon?: string|null, ogImage?: string|null}, personality?: {tone?: string, energy?: string, targetAudience?: string}}. " +
+			"Only include fields you can infer with reasonable confidence."
+	}
+
 	if wantLLM {
 		var err error
-		llmClient, provider, modelName, err = llm.NewClientFromConfig(cfg, "", "")
+		p.llmClient, p.provider, p.modelName, err = newLLMClient(ctx, cfg, st, tenantIDFromContext(ctx), "", "")
 		if err != nil {
-			msg := err.Error()
-			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-			return
+			return nil, errors.New(llmClientErrorMessage(err))
 		}
-		llmTimeout = timeout
+		p.llmTimeout = p.timeout
 	}
 
-	s := scraper.NewHTTPScraper(timeout)
+	// Classification does not need the LLM, so it is set up separately and
+	// never fails the crawl.
+	if wantClassify {
+		p.classifier = newPageClassifier(ctx, cfg, st, tenantIDFromContext(ctx), classifyLabels, p.timeout)
+	}
+
+	p.scraper = scraper.NewHTTPScraper(p.timeout)
+	if p.spa {
+		p.scraper = scraper.NewBrowserScraper(p.timeout, browserOptions(cfg))
+	}
 
 	// Derive per-page scrape headers if provided at the crawl level.
-	scrapeHeaders := map[string]string{}
+	p.headers = map[string]string{}
 	if req.ScrapeOptions != nil {
 		for k, v := range req.ScrapeOptions.Headers {
-			scrapeHeaders[k] = v
+			p.headers[k] = v
+		}
+		if req.ScrapeOptions.Auth != nil {
+			authHeaders, err := targetAuthHeaders(ctx, cfg, db.New(st.DB), tenantIDFromContext(ctx), req.ScrapeOptions.Auth)
+			if err != nil {
+				_, code := targetAuthStatus(err)
+				return nil, errors.New(code + ": " + err.Error())
+			}
+			p.authHeaders = authHeaders
+			p.authHosts = crawlAuthHosts(req)
 		}
 	}
 
-	var locOpts *scraper.LocationOptions
 	if req.ScrapeOptions != nil && req.ScrapeOptions.Location != nil {
 		loc := req.ScrapeOptions.Location
-		locOpts = &scraper.LocationOptions{
+		p.locOpts = &scraper.LocationOptions{
 			Country:   loc.Country,
 			Languages: loc.Languages,
 		}
 	}
 
-	maxPerJob := cfg.Worker.MaxConcurrentURLsPerJob
-	if maxPerJob <= 0 {
-		maxPerJob = 1
+	hook, err := loadTransformHook(ctx, cfg, db.New(st.DB), tenantIDFromContext(ctx))
+	if err != nil {
+		return nil, errors.New("TRANSFORM_HOOK_FAILED: " + err.Error())
+	}
+	p.hook = hook
+	return p, nil
+}
+
+// crawlAuthHosts returns the hosts of the crawl's seeds, the only hosts
+// its target auth is sent to. Links to other hosts, which
+// allowExternalLinks and allowSubdomains let a crawl follow, are fetched
+// without it.
+func crawlAuthHosts(req CrawlRequest) map[string]bool {
+	hosts := map[string]bool{}
+	for _, seed := range crawlSeeds(req) {
+		if h := crawler.HostKey(seed); h != "" {
+			hosts[h] = true
+		}
+	}
+	return hosts
+}
+
+// fetch scrapes u with the crawl's headers, or with headers when it is
+// not nil.
+func (p *crawlPageScraper) fetch(ctx context.Context, u string, headers map[string]string) (*scraper.Result, error) {
+	if headers == nil {
+		headers = p.headers
+	}
+	// Build per-request scraper.Request using shared helpers so
+	// headers and Accept-Language behavior are consistent.
+	sReq := scraper.BuildRequestFromOptions(scraper.RequestOptions{
+		URL:       u,
+		Headers:   headers,
+		TimeoutMs: int(p.timeout.Milliseconds()),
+		UserAgent: p.cfg.RequestUserAgent(),
+		Location:  p.locOpts,
+	})
+	if p.authHosts[crawler.HostKey(u)] {
+		sReq.AuthHeaders = p.authHeaders
+	}
+	sReq.DiscoverRoutes = p.spa
+
+	if err := p.pacer.Wait(ctx, crawler.HostKey(u), p.delay); err != nil {
+		return nil, err
+	}
+	done, err := acquireHost(ctx, p.cfg, p.limiter, u)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.scraper.Scrape(ctx, sReq)
+	if err != nil {
+		done(0, err)
+		return nil, err
+	}
+	done(res.Status, nil)
+	return res, nil
+}
+
+// page computes the requested formats of a scraped page and runs the
+// transform hook. It returns false when the hook dropped the page, and an
+// error when the hook failed under the fail policy.
+func (p *crawlPageScraper) page(ctx context.Context, res *scraper.Result) (crawlPage, bool, error) {
+	md := model.Metadata{
+		Title:        scrapeutil.ToString(res.Metadata["title"]),
+		Description:  scrapeutil.ToString(res.Metadata["description"]),
+		SourceURL:    scrapeutil.ToString(res.Metadata["sourceURL"]),
+		StatusCode:   res.Status,
+		ETag:         res.ETag,
+		LastModified: res.LastModified,
+		Headers:      res.Headers,
+	}
+	pageURL, alts := pageAlternates(res)
+	md.Language = pageLanguage(res, pageURL, alts)
+	md.LanguageGroup = crawler.LanguageGroup(pageURL, alts)
+	if p.wantAuto {
+		md.AutoFormats = services.AutoFormats(res.Headers["content-type"], res.RawHTML)
+	}
+
+	// Plugin failures leave the page without that plugin's
+	// output, like LLM formats.
+	if len(p.pluginFormats) > 0 {
+		md.Plugins, _ = p.pluginRegistry.RunAll(ctx, p.req.Formats, res)
+	}
+
+	if p.classifier != nil {
+		md.Categories = p.classifier.Classify(ctx, md.SourceURL, res.Markdown)
+	}
+
+	// Browser failures leave the page without a report, like
+	// LLM formats.
+	if p.wantA11y {
+		md.Accessibility, _ = scraper.AuditBrowserAccessibility(ctx, browserOptions(p.cfg), res.URL, p.timeout)
+	}
+	if p.wantPerformance {
+		md.Performance, _ = scraper.MeasureBrowserPerformance(ctx, browserOptions(p.cfg), res.URL, p.timeout)
+	}
+
+	if p.wantSummary {
+		fieldSpecs := []llm.FieldSpec{{
+			Name:        "summary",
+			Description: "Short natural-language summary of the page content.",
+			Type:        "string",
+		}}
+		if v, ok := p.extractField(ctx, md.SourceURL, res.Markdown, fieldSpecs, p.summaryPrompt); ok {
+			if s, ok2 := v.(string); ok2 {
+				md.Summary = s
+			}
+		}
+	}
+
+	if p.hasJSON {
+		desc := "Arbitrary JSON object extracted from the page content."
+		if len(p.jsonSchema) > 0 {
+			if schemaBytes, err := json.Marshal(p.jsonSchema); err == nil {
+				desc = desc + " Schema: " + string(schemaBytes)
+			}
+		}
+
+		fieldSpecs := []llm.FieldSpec{{
+			Name:        "json",
+			Description: desc,
+			Type:        "object",
+		}}
+		if v, ok := p.extractField(ctx, md.SourceURL, res.Markdown, fieldSpecs, p.jsonPrompt); ok {
+			if m, ok2 := v.(map[string]any); ok2 {
+				md.JSON = m
+			} else {
+				md.JSON = map[string]any{"_value": v}
+			}
+		}
+	}
+
+	if p.wantBranding {
+		descBranding := "Brand identity and design system information (colors, typography, logo, components, personality, etc.) extracted from the page, following Firecrawl's BrandingProfile conventions."
+
+		fieldSpecs := []llm.FieldSpec{{
+			Name:        "branding",
+			Description: descBranding,
+			Type:        "object",
+		}}
+		if v, ok := p.extractField(ctx, md.SourceURL, res.Markdown, fieldSpecs, p.brandingPrompt); ok {
+			if m, ok := v.(map[string]any); ok {
+				scrapeutil.NormalizeBrandingImages(m)
+				md.Branding = m
+			} else {
+				md.Branding = map[string]any{"_value": v}
+			}
+		}
+	}
+
+	pg := crawlPage{
+		url:        res.URL,
+		markdown:   res.Markdown,
+		html:       res.HTML,
+		raw:        res.RawHTML,
+		statusCode: int32(res.Status),
+		engine:     res.Engine,
+	}
+
+	if p.downloadImages {
+		pg.markdown = archiveImages(ctx, p.cfg, p.st, p.jobID, res.URL, pg.markdown, scraper.ExtractImages(pg.html, res.URL))
+	}
+
+	keep, err := p.hook.applyToPage(ctx, p.jobID, "crawl", &pg.markdown, &pg.html, &pg.raw, pg.engine, &md)
+	if err != nil || !keep {
+		return crawlPage{}, keep, err
+	}
+	pg.md = md
+	return pg, true, nil
+}
+
+// extractField runs one LLM field extraction for a page and returns the
+// field's value. LLM failures leave the page without the field.
+func (p *crawlPageScraper) extractField(ctx context.Context, sourceURL, markdown string, fields []llm.FieldSpec, prompt string) (any, bool) {
+	llmCtx, llmCancel := context.WithTimeout(ctx, p.llmTimeout)
+	llmRes, err := p.llmClient.ExtractFields(llmCtx, llm.ExtractRequest{
+		URL:      sourceURL,
+		Markdown: markdown,
+		Fields:   fields,
+		Prompt:   prompt,
+		Timeout:  p.llmTimeout,
+		Strict:   false,
+	})
+	llmCancel()
+	if err != nil {
+		metrics.RecordLLMExtract(string(p.provider), p.modelName, false)
+		return nil, false
+	}
+	metrics.RecordLLMExtract(string(p.provider), p.modelName, true)
+	v, ok := llmRes.Fields[fields[0].Name]
+	return v, ok
+}
+
+// runCrawlJob performs the actual crawl for a single job ID using the
+// provided crawl request options.
+func runCrawlJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, req CrawlRequest) {
+	// Derive discovery options from request and config.
+	limit, discovery := crawlDiscoveryOptions(cfg, req)
+
+	pages, err := newCrawlPageScraper(ctx, cfg, st, jobID, req)
+	if err != nil {
+		msg := err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
 	}
+
+	// Incremental crawls compare pages against the latest completed crawl
+	// of the same root in the tenant and only store new or changed pages.
+	var baseline *incrementalBaseline
+	if req.Incremental != nil && *req.Incremental {
+		var tenantID uuid.NullUUID
+		if tid, ok := ctx.Value("tenant_id").(uuid.UUID); ok {
+			tenantID = uuid.NullUUID{UUID: tid, Valid: true}
+		}
+		var err error
+		baseline, err = loadIncrementalBaseline(ctx, st, tenantID, req.URL, jobID)
+		if err != nil {
+			msg := "INCREMENTAL_BASELINE_FAILED: " + err.Error()
+			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+			return
+		}
+		if baseline.previousJobID != uuid.Nil {
+			_ = st.SetJobPreviousJob(ctx, jobID, baseline.previousJobID)
+		}
+	}
+
+	priorityExpr := req.PriorityExpression
+	if priorityExpr == "" {
+		priorityExpr = cfg.Crawler.PriorityExpression
+	}
+	priority, err := crawler.ParsePriorityExpression(priorityExpr)
+	if err != nil {
+		msg := "INVALID_PRIORITY_EXPRESSION: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	seeds := crawlSeeds(req)
+	if len(seeds) == 0 {
+		msg := "url is required"
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	// noIndexSkipped and noFollowSkipped count what respectRobotsMeta left
+	// out, for the crawl's summary.
+	var noIndexSkipped, noFollowSkipped int32
+	// languageSkipped and alternatesSkipped count the pages and queued
+	// alternates in languages the crawl did not select.
+	var languageSkipped, alternatesSkipped int32
+	// fetchErrors counts the pages that could not be fetched, and elapsed
+	// how long earlier runs of a resumed crawl took, for the crawl's report.
+	fetchErrors := newCrawlFetchErrors(nil)
+	var elapsed time.Duration
+	started := time.Now()
+
+	// A paused crawl continues from its saved frontier instead of
+	// discovering the site again.
+	saved, err := loadCrawlFrontier(ctx, st, jobID)
+	if err != nil {
+		msg := "CRAWL_RESUME_FAILED: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	maxDepth := crawlMaxDepth(cfg, req)
+	var links []crawler.Link
+	if saved == nil {
+		var skipped int
+		links, skipped, err = discoverCrawlURLs(ctx, st, jobID, req, seeds, limit, maxDepth, discovery, priority)
+		if err != nil {
+			msg := err.Error()
+			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+			return
+		}
+		noFollowSkipped = int32(skipped)
+	}
+
+	frontier, err := crawler.NewFrontier(ctx, seeds[0], crawler.FrontierOptions{
+		// The seeds plus limit pages, as for the discovered URLs above.
+		Limit:             limit + len(seeds),
+		IncludeSubdomains: discovery.IncludeSubdomains,
+		IgnoreQueryParams: discovery.IgnoreQueryParams,
+		AllowExternal:     discovery.AllowExternal,
+		MaxDepth:          maxDepth,
+		RespectRobots:     cfg.Robots.Respect,
+		UserAgent:         cfg.RequestUserAgent(),
+		RobotsAgent:       cfg.RobotsAgent(),
+		Timeout:           pages.timeout,
+		ExtraRoots:        seeds[1:],
+		OnRobotsBlocked:   recordHostRobotsBlock,
+	})
+	if err != nil {
+		msg := err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	var successCount int32
+	if saved != nil {
+		frontier.Restore(saved.frontier)
+		successCount = saved.state.Scraped
+		noIndexSkipped = saved.state.NoIndexSkipped
+		noFollowSkipped = saved.state.NoFollowSkipped
+		languageSkipped = saved.state.LanguageSkipped
+		alternatesSkipped = saved.state.AlternatesSkipped
+		fetchErrors = newCrawlFetchErrors(saved.state.FetchErrors)
+		elapsed = time.Duration(saved.state.ElapsedMs) * time.Millisecond
+		if baseline != nil && saved.state.Incremental != nil {
+			baseline.restore(*saved.state.Incremental)
+		}
+	} else {
+		frontier.Seed(seeds...)
+		for _, l := range links {
+			frontier.SeedAt(l.Depth(), l.URL)
+		}
+	}
+
+	// A transform hook with the fail policy stops the crawl by cancelling
+	// ctx with the hook error as its cause.
+	ctx, failCrawl := context.WithCancelCause(ctx)
+	defer failCrawl(nil)
+
+	maxPerJob := urlConcurrency(cfg)
 	// Allow per-crawl overrides of URL concurrency, but never exceed the
 	// global worker limit.
 	if req.MaxConcurrency != nil && *req.MaxConcurrency > 0 && *req.MaxConcurrency < maxPerJob {
 		maxPerJob = *req.MaxConcurrency
 	}
 
-	var successCount int32
+	metrics.JobRuntimeFrom(ctx).SetPagesTotal(frontier.Len())
+
+	// Pausing the crawl stops it from handing out more URLs; the pages in
+	// flight finish and the frontier is saved for the resume.
+	nextCtx, pause := context.WithCancel(ctx)
+	defer pause()
+	var paused atomic.Bool
+	go watchCrawlPause(nextCtx, st, jobID, func() {
+		paused.Store(true)
+		pause()
+	})
+
 	sem := make(chan struct{}, maxPerJob)
 	// Use a channel to wait for all URL scrapes to finish.
 	doneCh := make(chan struct{})
 
 	go func() {
-		for _, u := range urls {
+		for {
 			select {
 			case <-ctx.Done():
 				return
 			case sem <- struct{}{}:
 			}
 
-			u := u
+			// Next waits while pages in flight may still add routes.
+			u, ok := frontier.Next(nextCtx)
+			if !ok {
+				<-sem
+				break
+			}
+
 			go func() {
 				defer func() { <-sem }()
+				defer frontier.Done()
 
 				select {
 				case <-ctx.Done():
@@ -321,152 +897,76 @@ func runCrawlJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID
 				default:
 				}
 
-				// Build per-request scraper.Request using shared helpers so
-				// headers and Accept-Language behavior are consistent.
-				sReq := scraper.BuildRequestFromOptions(scraper.RequestOptions{
-					URL:       u,
-					Headers:   scrapeHeaders,
-					TimeoutMs: int(timeout.Milliseconds()),
-					UserAgent: cfg.Scraper.UserAgent,
-					Location:  locOpts,
-				})
-
-				res, err := s.Scrape(ctx, sReq)
+				var pageHeaders map[string]string
+				if baseline != nil {
+					pageHeaders = baseline.requestHeaders(u, pages.headers)
+				}
+				res, err := pages.fetch(ctx, u, pageHeaders)
 				if err != nil {
+					if ctx.Err() == nil {
+						fetchErrors.add(err)
+					}
 					return
 				}
 
-				engine := res.Engine
-				md := model.Metadata{
-					Title:       scrapeutil.ToString(res.Metadata["title"]),
-					Description: scrapeutil.ToString(res.Metadata["description"]),
-					SourceURL:   scrapeutil.ToString(res.Metadata["sourceURL"]),
-					StatusCode:  res.Status,
-				}
-
-				if wantSummary {
-					fieldSpecs := []llm.FieldSpec{{
-						Name:        "summary",
-						Description: "Short natural-language summary of the page content.",
-						Type:        "string",
-					}}
-
-					llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
-					llmRes, err := llmClient.ExtractFields(llmCtx, llm.ExtractRequest{
-						URL:      md.SourceURL,
-						Markdown: res.Markdown,
-						Fields:   fieldSpecs,
-						Prompt:   "",
-						Timeout:  llmTimeout,
-						Strict:   false,
-					})
-					llmCancel()
-					if err != nil {
-						metrics.RecordLLMExtract(string(provider), modelName, false)
-					} else {
-						metrics.RecordLLMExtract(string(provider), modelName, true)
-						if v, ok := llmRes.Fields["summary"]; ok {
-							if s, ok2 := v.(string); ok2 {
-								md.Summary = s
-							}
-						}
-					}
+				// Links found on the page are queued one level deeper, so
+				// the crawl reaches pages discovery missed, breadth first
+				// and up to its depth and page limits.
+				robots := pages.pageRobotsMeta(res)
+				links, skipped := pages.followLinks(res, robots)
+				atomic.AddInt32(&noFollowSkipped, int32(skipped))
+				depth := frontier.Depth(u) + 1
+				for _, l := range links {
+					frontier.Add(l, depth)
 				}
-
-				if hasJSON {
-					desc := "Arbitrary JSON object extracted from the page content."
-					if len(jsonSchema) > 0 {
-						if schemaBytes, err := json.Marshal(jsonSchema); err == nil {
-							desc = desc + " Schema: " + string(schemaBytes)
+				metrics.JobRuntimeFrom(ctx).SetPagesTotal(frontier.Len())
+				// Alternates in other languages are dropped before they are
+				// scraped; pages found some other way are only skipped once
+				// their own language is known.
+				if len(req.Languages) > 0 {
+					pageURL, alts := pageAlternates(res)
+					for _, alt := range languageSkips(alts, req.Languages) {
+						if frontier.Skip(alt) {
+							atomic.AddInt32(&alternatesSkipped, 1)
 						}
 					}
-
-					fieldSpecs := []llm.FieldSpec{{
-						Name:        "json",
-						Description: desc,
-						Type:        "object",
-					}}
-
-					llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
-					llmRes, err := llmClient.ExtractFields(llmCtx, llm.ExtractRequest{
-						URL:      md.SourceURL,
-						Markdown: res.Markdown,
-						Fields:   fieldSpecs,
-						Prompt:   jsonPrompt,
-						Timeout:  llmTimeout,
-						Strict:   false,
-					})
-					llmCancel()
-					if err != nil {
-						metrics.RecordLLMExtract(string(provider), modelName, false)
-					} else {
-						metrics.RecordLLMExtract(string(provider), modelName, true)
-						if v, ok := llmRes.Fields["json"]; ok {
-							if m, ok2 := v.(map[string]any); ok2 {
-								md.JSON = m
-							} else {
-								md.JSON = map[string]any{"_value": v}
-							}
-						}
+					if lang := pageLanguage(res, pageURL, alts); lang != "" && !crawler.LanguageMatches(lang, req.Languages) {
+						atomic.AddInt32(&languageSkipped, 1)
+						atomic.AddInt32(&successCount, 1)
+						return
 					}
 				}
+				if robots.NoIndex {
+					atomic.AddInt32(&noIndexSkipped, 1)
+					atomic.AddInt32(&successCount, 1)
+					return
+				}
 
-				if wantBranding {
-					// Use a Firecrawl-style default prompt if the user did not
-					// provide one in the formats array.
-					if brandingPrompt == "" {
-						brandingPrompt = "You are a brand design expert analyzing a website. Analyze the page and return a single JSON object describing the brand, matching this structure as closely as possible: " +
-							"{colorScheme?: 'light'|'dark', colors?: {primary?: string, secondary?: string, accent?: string, background?: string, textPrimary?: string, textSecondary?: string, link?: string, success?: string, warning?: string, error?: string}, " +
-							"typography?: {fontFamilies?: {primary?: string, heading?: string, code?: string}, fontStacks?: {primary?: string[], heading?: string[], body?: string[], paragraph?: string[]}, fontSizes?: {h1?: string, h2?: string, h3?: string, body?: string, small?: string}}, " +
-							"spacing?: {baseUnit?: number, borderRadius?: string}, components?: {buttonPrimary?: {background?: string, textColor?: string, borderColor?: string, borderRadius?: string}, buttonSecondary?: {...}}, " +
-							"images?: {logo?: string|null, favicon?: string|null, ogImage?: string|null}, personality?: {tone?: string, energy?: string, targetAudience?: string}}. " +
-							"Only include fields you can infer with reasonable confidence."
-					}
-
-					descBranding := "Brand identity and design system information (colors, typography, logo, components, personality, etc.) extracted from the page, following Firecrawl's BrandingProfile conventions."
-
-					fieldSpecs := []llm.FieldSpec{{
-						Name:        "branding",
-						Description: descBranding,
-						Type:        "object",
-					}}
-
-					llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
-					llmRes, err := llmClient.ExtractFields(llmCtx, llm.ExtractRequest{
-						URL:      md.SourceURL,
-						Markdown: res.Markdown,
-						Fields:   fieldSpecs,
-						Prompt:   brandingPrompt,
-						Timeout:  llmTimeout,
-						Strict:   false,
-					})
-					llmCancel()
-					if err != nil {
-						metrics.RecordLLMExtract(string(provider), modelName, false)
-					} else {
-						metrics.RecordLLMExtract(string(provider), modelName, true)
-						if v, ok := llmRes.Fields["branding"]; ok {
-							if m, ok := v.(map[string]any); ok {
-								scrapeutil.NormalizeBrandingImages(m)
-								md.Branding = m
-							} else {
-								md.Branding = map[string]any{"_value": v}
-							}
-						}
-					}
+				if baseline != nil && !baseline.record(u, res) {
+					// Unchanged since the baseline; the earlier job keeps the document.
+					atomic.AddInt32(&successCount, 1)
+					return
 				}
 
-				metaBytes, err := json.Marshal(md)
+				pg, keep, err := pages.page(ctx, res)
 				if err != nil {
+					failCrawl(err)
+					return
+				}
+				if !keep {
+					// Dropped by the transform hook.
+					atomic.AddInt32(&successCount, 1)
 					return
 				}
 
-				statusCode := int32(res.Status)
-				markdown := res.Markdown
-				html := res.HTML
-				raw := res.RawHTML
+				metaBytes, err := json.Marshal(pg.md)
+				if err != nil {
+					return
+				}
 
-				_ = st.AddDocument(ctx, jobID, res.URL, &markdown, &html, &raw, metaBytes, &statusCode, &engine)
+				if err := st.AddDocument(ctx, jobID, pg.url, &pg.markdown, &pg.html, &pg.raw, metaBytes, &pg.statusCode, &pg.engine); err == nil {
+					recordDocumentVersion(ctx, cfg, st, jobID, pg.url, pg.markdown, pg.md.Title, pg.statusCode)
+				}
 				atomic.AddInt32(&successCount, 1)
 			}()
 		}
@@ -478,21 +978,145 @@ func runCrawlJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID
 		close(doneCh)
 	}()
 
-	select {
-	case <-ctx.Done():
-		msg := ctx.Err().Error()
-		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-		return
-	case <-doneCh:
+	select {
+	case <-ctx.Done():
+		msg := jobCancelMessage(ctx)
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	case <-doneCh:
+	}
+
+	if paused.Load() {
+		state := crawlPauseState{
+			Scraped:           atomic.LoadInt32(&successCount),
+			NoIndexSkipped:    atomic.LoadInt32(&noIndexSkipped),
+			NoFollowSkipped:   atomic.LoadInt32(&noFollowSkipped),
+			LanguageSkipped:   atomic.LoadInt32(&languageSkipped),
+			AlternatesSkipped: atomic.LoadInt32(&alternatesSkipped),
+			FetchErrors:       fetchErrors.snapshot(),
+			ElapsedMs:         (elapsed + time.Since(started)).Milliseconds(),
+		}
+		if baseline != nil {
+			sum := baseline.result()
+			state.Incremental = &sum
+		}
+		if err := saveCrawlFrontier(context.Background(), st, jobID, frontier.State(), state); err != nil {
+			msg := "CRAWL_PAUSE_FAILED: " + err.Error()
+			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		}
+		return
+	}
+	if saved != nil {
+		_ = db.New(st.DB).DeleteCrawlFrontier(context.Background(), jobID)
+	}
+
+	if atomic.LoadInt32(&successCount) == 0 {
+		msg := "no pages successfully scraped"
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	output := map[string]any{}
+	if baseline != nil {
+		output["incremental"] = baseline.result()
+	}
+	if pages.robotsMeta {
+		output["robotsMeta"] = CrawlRobotsMetaSummary{
+			NoIndexSkipped:  int(atomic.LoadInt32(&noIndexSkipped)),
+			NoFollowSkipped: int(atomic.LoadInt32(&noFollowSkipped)),
+		}
+	}
+	if len(req.Languages) > 0 {
+		output["languages"] = CrawlLanguageSummary{
+			Languages:         req.Languages,
+			SkippedPages:      int(atomic.LoadInt32(&languageSkipped)),
+			SkippedAlternates: int(atomic.LoadInt32(&alternatesSkipped)),
+		}
+	}
+	// summarizeCrawl fills in the rest of the report from the documents.
+	output["report"] = CrawlReport{
+		DurationMs:  (elapsed + time.Since(started)).Milliseconds(),
+		FetchErrors: fetchErrors.snapshot(),
+	}
+	summarizeCrawl(ctx, st, jobID, req, output)
+	if len(output) > 0 {
+		if raw, err := json.Marshal(output); err == nil {
+			_ = st.SetJobOutput(context.Background(), jobID, raw)
+		}
+	}
+
+	_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusCompleted), nil)
+}
+
+// discoverCrawlURLs maps every seed with its own host rules and returns
+// the most valuable links found, up to limit and at most maxDepth deep,
+// which the crawl queues after its seeds. It also returns how many links
+// discovery skipped because of nofollow. The candidates share one limit,
+// and more are discovered than the limit so prioritization can pick the
+// most valuable pages rather than whichever were found first.
+func discoverCrawlURLs(ctx context.Context, st *store.Store, jobID uuid.UUID, req CrawlRequest, seeds []string, limit, maxDepth int, discovery crawler.MapOptions, priority *crawler.PriorityExpr) ([]crawler.Link, int, error) {
+	var candidates []crawler.Link
+	noFollowSkipped := 0
+	seen := map[string]bool{}
+	for _, seed := range seeds {
+		seen[seed] = true
+	}
+	// Sitemaps give an early estimate of the crawl's size, published as
+	// the expected page total before HTML discovery finishes. It is
+	// replaced by the exact count once the queue is built.
+	sitemapPages := 0
+	for _, seed := range seeds {
+		opts := discovery
+		opts.URL = seed
+		opts.OnSitemap = func(count int) {
+			sitemapPages += count
+			metrics.JobRuntimeFrom(ctx).SetPagesTotal(min(sitemapPages, limit) + len(seeds))
+		}
+		mapRes, err := crawler.Map(ctx, opts)
+		if err != nil {
+			if len(seeds) > 1 {
+				return nil, 0, fmt.Errorf("%s: %w", seed, err)
+			}
+			return nil, 0, err
+		}
+		noFollowSkipped += mapRes.NoFollowSkipped
+		for _, l := range crawler.LinksWithin(mapRes.Links, maxDepth) {
+			if !seen[l.URL] {
+				seen[l.URL] = true
+				candidates = append(candidates, l)
+			}
+		}
 	}
 
-	if atomic.LoadInt32(&successCount) == 0 {
-		msg := "no pages successfully scraped"
-		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-		return
+	links := priority.Prioritize(candidates, crawler.PriorityContext{IncludePaths: req.IncludePaths})
+	if len(links) > limit {
+		links = links[:limit]
 	}
 
-	_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusCompleted), nil)
+	recordDiscoveryFinished(ctx, st, jobID, len(candidates), len(seeds)+len(links))
+	return links, noFollowSkipped, nil
+}
+
+// summarizeCrawl adds the crawl-wide reports computed from a crawl's
+// stored documents to output. The passes are best effort; a failure
+// leaves the crawl without that report.
+func summarizeCrawl(ctx context.Context, st *store.Store, jobID uuid.UUID, req CrawlRequest, output map[string]any) {
+	if report, err := summarizeCrawlReport(ctx, st, jobID, output); err == nil {
+		output["report"] = report
+	}
+	if duplicates, err := detectDuplicates(ctx, st, jobID); err == nil {
+		output["duplicates"] = duplicates
+	}
+	if scrapeutil.WantsFormat(req.Formats, "a11y") {
+		if summary, err := summarizeCrawlAccessibility(ctx, st, jobID); err == nil {
+			output["accessibility"] = summary
+		}
+	}
+	if req.StructuredData != nil && *req.StructuredData {
+		if report, err := validateCrawlStructuredData(ctx, st, jobID); err == nil {
+			output["structuredData"] = report
+		}
+	}
 }
 
 // runMapJob performs a map operation for a map job and stores the
@@ -542,7 +1166,9 @@ func runMapJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID u
 		SitemapMode:       sitemapMode,
 		Timeout:           time.Duration(timeoutMs) * time.Millisecond,
 		RespectRobots:     cfg.Robots.Respect,
-		UserAgent:         cfg.Scraper.UserAgent,
+		UserAgent:         cfg.RequestUserAgent(),
+		RobotsAgent:       cfg.RobotsAgent(),
+		OnRobotsBlocked:   recordHostRobotsBlock,
 	})
 	if err != nil {
 		msg := "MAP_FAILED: " + err.Error()
@@ -589,6 +1215,8 @@ type extractDeps struct {
 	provider  llm.Provider
 	modelName string
 	timeout   time.Duration
+	// mapLinks discovers pages for wildcard extract URLs; nil uses crawler.Map.
+	mapLinks func(ctx context.Context, opts crawler.MapOptions) (*crawler.MapResult, error)
 }
 
 // newExtractDeps constructs extractDeps from global config and request-level
@@ -610,6 +1238,7 @@ var newExtractDeps = func(cfg *config.Config, req ExtractRequest) (*extractDeps,
 		provider:  provider,
 		modelName: modelName,
 		timeout:   time.Duration(timeoutMs) * time.Millisecond,
+		mapLinks:  crawler.Map,
 	}, nil
 }
 
@@ -629,6 +1258,18 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 		return
 	}
 
+	// Apply the tenant's LLM policy: default provider/model and allowlist.
+	if s, ok := st.(*store.Store); ok {
+		provider, model, err := resolveTenantLLM(ctx, cfg, db.New(s.DB), tenantIDFromContext(ctx), req.Provider, req.Model)
+		if err != nil {
+			metrics.RecordExtractJob(req.Provider, req.Model, "failed")
+			msg := llmClientErrorMessage(err)
+			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+			return
+		}
+		req.Provider, req.Model = provider, model
+	}
+
 	// Use the scraper timeout for both scraping and LLM operations.
 	// The concrete scraper and LLM client are constructed via newExtractDeps
 	// so tests can inject fakes.
@@ -641,6 +1282,39 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 	}
 	llmTimeout := deps.timeout
 
+	// Charge extract calls to the tenant's LLM budget and fail before any
+	// scraping when it is already used up.
+	if s, ok := st.(*store.Store); ok {
+		if tid := tenantIDFromContext(ctx); tid != nil {
+			q := db.New(s.DB)
+			if err := checkLLMBudget(ctx, q, *tid); errors.Is(err, errLLMBudgetExceeded) {
+				metrics.RecordExtractJob(string(deps.provider), deps.modelName, "failed")
+				msg := "LLM_BUDGET_EXCEEDED: " + err.Error()
+				_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+				return
+			}
+			deps.client = withLLMBudget(cfg, q, tid, deps.client, deps.modelName)
+		}
+	}
+
+	// Expand wildcard entries (e.g. https://example.com/blog/*) into the
+	// pages discovered under that prefix.
+	urls, wildcard, err := expandExtractURLs(ctx, cfg, deps, req)
+	if err != nil {
+		msg := "EXTRACT_DISCOVERY_FAILED: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	if len(urls) == 0 {
+		msg := "EXTRACT_DISCOVERY_FAILED: no pages discovered for wildcard urls"
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	if wildcard {
+		recordDiscoveryFinished(ctx, st, jobID, len(urls), len(urls))
+	}
+	metrics.JobRuntimeFrom(ctx).SetPagesTotal(len(urls))
+
 	ignoreInvalid := false
 	if req.IgnoreInvalidURLs != nil {
 		ignoreInvalid = *req.IgnoreInvalidURLs
@@ -689,14 +1363,46 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 		}
 	}
 
+	if req.Mode == extractModeMerge {
+		runMergedExtract(ctx, cfg, st, jobID, req, deps, urls, mergeExtractOptions{
+			prompt:        buildPrompt(req.Prompt),
+			headers:       baseHeaders,
+			location:      locOpts,
+			ignoreInvalid: ignoreInvalid,
+			showSources:   showSources,
+		})
+		return
+	}
+
+	cache := newExtractCache(ctx, cfg, st, req, buildPrompt(req.Prompt))
+
 	for _, u := range urls {
+		if cached, statusCode, ok := cache.get(ctx, u); ok {
+			results = append(results, map[string]any{
+				"url":     u,
+				"success": true,
+				"json":    cached,
+				"cached":  true,
+			})
+			storeExtractDocument(ctx, st, jobID, u, nil, cached, statusCode)
+			if showSources {
+				sources = append(sources, map[string]any{
+					"url":        u,
+					"statusCode": statusCode,
+					"error":      "",
+				})
+			}
+			successCount++
+			continue
+		}
+
 		// Scrape the URL first using shared RequestOptions to ensure
 		// consistent headers and Accept-Language behavior.
 		sReq := scraper.BuildRequestFromOptions(scraper.RequestOptions{
 			URL:       u,
 			Headers:   baseHeaders,
 			TimeoutMs: int(deps.timeout.Milliseconds()),
-			UserAgent: cfg.Scraper.UserAgent,
+			UserAgent: cfg.RequestUserAgent(),
 			Location:  locOpts,
 		})
 
@@ -733,6 +1439,10 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 			Name:        "json",
 			Description: desc,
 			Type:        "object",
+		}, {
+			Name:        extract.ConfidenceField,
+			Description: extract.ConfidenceFieldDescription,
+			Type:        "object",
 		}}
 
 		llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
@@ -747,6 +1457,13 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 		llmCancel()
 		if err != nil {
 			metrics.RecordLLMExtract(string(provider), modelName, false)
+			if errors.Is(err, errLLMBudgetExceeded) {
+				// Every remaining URL would fail the same way.
+				metrics.RecordExtractJob(string(provider), modelName, "failed")
+				msg := "LLM_BUDGET_EXCEEDED: " + err.Error()
+				_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+				return
+			}
 			if ignoreInvalid {
 				results = append(results, map[string]any{
 					"url":     u,
@@ -771,6 +1488,8 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 
 		metrics.RecordLLMExtract(string(provider), modelName, true)
 
+		modelConfidence, _ := llmRes.Fields[extract.ConfidenceField].(map[string]any)
+
 		var jsonValue map[string]any
 		if v, ok := llmRes.Fields["json"]; ok {
 			if m, ok := v.(map[string]any); ok {
@@ -780,7 +1499,12 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 			}
 		} else if len(llmRes.Fields) > 0 {
 			// Fallback: ensure we still return something useful.
-			jsonValue = llmRes.Fields
+			jsonValue = make(map[string]any, len(llmRes.Fields))
+			for k, v := range llmRes.Fields {
+				if k != extract.ConfidenceField {
+					jsonValue[k] = v
+				}
+			}
 		}
 
 		if jsonValue == nil || len(jsonValue) == 0 {
@@ -805,10 +1529,14 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 			return
 		}
 
+		cache.put(ctx, u, jsonValue, res.Status)
+		storeExtractDocument(ctx, st, jobID, u, &res.Markdown, jsonValue, res.Status)
+
 		results = append(results, map[string]any{
-			"url":     u,
-			"success": true,
-			"json":    jsonValue,
+			"url":        u,
+			"success":    true,
+			"json":       jsonValue,
+			"provenance": extract.Provenance(jsonValue, res.Markdown, modelConfidence),
 		})
 		if showSources {
 			sources = append(sources, map[string]any{
@@ -839,6 +1567,18 @@ func runExtractJob(ctx context.Context, cfg *config.Config, st jobStore, jobID u
 	payload := map[string]any{
 		"results": results,
 	}
+	if wildcard {
+		// Wildcard extracts span many pages of one site, so also return a
+		// single object merged across the successful per-page results.
+		values := make([]map[string]any, 0, successCount)
+		for _, r := range results {
+			if v, ok := r["json"].(map[string]any); ok {
+				values = append(values, v)
+			}
+		}
+		payload["data"] = mergeExtractResults(values)
+		payload["urls"] = urls
+	}
 	if showSources && len(sources) > 0 {
 		payload["sources"] = sources
 	}
@@ -887,11 +1627,27 @@ func runBatchScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store,
 
 	timeout := time.Duration(cfg.Scraper.TimeoutMs) * time.Millisecond
 	s := scraper.NewHTTPScraper(timeout)
+	pluginRegistry := plugins.NewRegistry(cfg.Plugins)
+	pluginFormats := pluginRegistry.Requested(req.Formats)
+	wantAuto := scrapeutil.WantsFormat(req.Formats, services.FormatAuto)
 
-	maxPerJob := cfg.Worker.MaxConcurrentURLsPerJob
-	if maxPerJob <= 0 {
-		maxPerJob = 1
+	var classifier *pageClassifier
+	if wantClassify, labels := scrapeutil.GetClassifyFormatConfig(req.Formats); wantClassify {
+		classifier = newPageClassifier(ctx, cfg, st, tenantIDFromContext(ctx), labels, timeout)
+	}
+
+	hook, err := loadTransformHook(ctx, cfg, db.New(st.DB), tenantIDFromContext(ctx))
+	if err != nil {
+		msg := "TRANSFORM_HOOK_FAILED: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
 	}
+	ctx, failBatch := context.WithCancelCause(ctx)
+	defer failBatch(nil)
+
+	maxPerJob := urlConcurrency(cfg)
+	limiter := sharedHostLimiter(cfg)
+	metrics.JobRuntimeFrom(ctx).SetPagesTotal(len(req.URLs))
 
 	var successCount int32
 	sem := make(chan struct{}, maxPerJob)
@@ -915,15 +1671,21 @@ func runBatchScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store,
 				default:
 				}
 
+				done, err := acquireHost(ctx, cfg, limiter, u)
+				if err != nil {
+					return
+				}
 				res, err := s.Scrape(ctx, scraper.Request{
 					URL:       u,
 					Headers:   map[string]string{},
 					Timeout:   timeout,
-					UserAgent: cfg.Scraper.UserAgent,
+					UserAgent: cfg.RequestUserAgent(),
 				})
 				if err != nil {
+					done(0, err)
 					return
 				}
+				done(res.Status, nil)
 
 				engine := res.Engine
 				md := model.Metadata{
@@ -931,11 +1693,16 @@ func runBatchScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store,
 					Description: scrapeutil.ToString(res.Metadata["description"]),
 					SourceURL:   scrapeutil.ToString(res.Metadata["sourceURL"]),
 					StatusCode:  res.Status,
+					Headers:     res.Headers,
 				}
-
-				metaBytes, err := json.Marshal(md)
-				if err != nil {
-					return
+				if wantAuto {
+					md.AutoFormats = services.AutoFormats(res.Headers["content-type"], res.RawHTML)
+				}
+				if len(pluginFormats) > 0 {
+					md.Plugins, _ = pluginRegistry.RunAll(ctx, req.Formats, res)
+				}
+				if classifier != nil {
+					md.Categories = classifier.Classify(ctx, md.SourceURL, res.Markdown)
 				}
 
 				statusCode := int32(res.Status)
@@ -943,7 +1710,24 @@ func runBatchScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store,
 				html := res.HTML
 				raw := res.RawHTML
 
-				_ = st.AddDocument(ctx, jobID, res.URL, &markdown, &html, &raw, metaBytes, &statusCode, &engine)
+				keep, err := hook.applyToPage(ctx, jobID, "batch_scrape", &markdown, &html, &raw, engine, &md)
+				if err != nil {
+					failBatch(err)
+					return
+				}
+				if !keep {
+					atomic.AddInt32(&successCount, 1)
+					return
+				}
+
+				metaBytes, err := json.Marshal(md)
+				if err != nil {
+					return
+				}
+
+				if err := st.AddDocument(ctx, jobID, res.URL, &markdown, &html, &raw, metaBytes, &statusCode, &engine); err == nil {
+					recordDocumentVersion(ctx, cfg, st, jobID, res.URL, markdown, md.Title, statusCode)
+				}
 				atomic.AddInt32(&successCount, 1)
 			}()
 		}
@@ -956,7 +1740,7 @@ func runBatchScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store,
 
 	select {
 	case <-ctx.Done():
-		msg := ctx.Err().Error()
+		msg := jobCancelMessage(ctx)
 		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
 		return
 	case <-doneCh:
@@ -972,8 +1756,29 @@ func runBatchScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store,
 }
 
 // runScrapeJob performs a single-page scrape for a scrape job and stores
-// the resulting Document into the job's output field.
-func runScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, req ScrapeRequest) {
+// the resulting Document into the job's output field. The request timeout
+// is a budget counted from start and shared by every stage; formats that
+// do not fit in it are listed in metadata.skippedFormats.
+func runScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, req ScrapeRequest, start time.Time) {
+	metrics.JobRuntimeFrom(ctx).SetPagesTotal(1)
+
+	// Fail before scraping when LLM formats were requested but the tenant's
+	// LLM budget is already used up.
+	if tid := tenantIDFromContext(ctx); tid != nil && wantsLLMFormat(req.Formats) {
+		if err := checkLLMBudget(ctx, db.New(st.DB), *tid); errors.Is(err, errLLMBudgetExceeded) {
+			msg := "LLM_BUDGET_EXCEEDED: " + err.Error()
+			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+			return
+		}
+	}
+
+	hook, err := loadTransformHook(ctx, cfg, db.New(st.DB), tenantIDFromContext(ctx))
+	if err != nil {
+		msg := "TRANSFORM_HOOK_FAILED: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
 	// Derive timeout from request and config.
 	timeoutMs := cfg.Scraper.TimeoutMs
 	if req.Timeout != nil && *req.Timeout > 0 {
@@ -982,31 +1787,40 @@ func runScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store, jobI
 
 	// Determine whether screenshot format was requested and its options.
 	hasScreenshot, screenshotFullPage := getScreenshotFormatConfig(req.Formats)
+	budget := newScrapeBudget(start, time.Duration(timeoutMs)*time.Millisecond, scrapeStages(req, hasScreenshot, false, false))
 
 	// Choose scraper engine: HTTP by default, rod when requested and enabled.
 	useBrowser := false
 	if req.UseBrowser != nil {
 		useBrowser = *req.UseBrowser
 	}
-	if hasScreenshot {
-		// Screenshot always uses the browser engine.
+	if hasScreenshot || len(req.Actions) > 0 {
+		// Screenshots and actions always use the browser engine.
 		useBrowser = true
 	}
 
+	// The fetch starts here; everything before it is setup.
+	fetchTimeout, _ := budget.start(scrapeStageFetch)
+
 	var engine scraper.Scraper
 	if useBrowser {
 		if !cfg.Rod.Enabled {
+			if len(req.Actions) > 0 {
+				msg := "ACTIONS_NOT_AVAILABLE: actions require browser scraping, but rod is disabled in server configuration"
+				_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+				return
+			}
 			if hasScreenshot {
 				msg := "SCREENSHOT_NOT_AVAILABLE: screenshot format requires browser scraping, but rod is disabled in server configuration"
 				_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
 				return
 			}
-			engine = scraper.NewHTTPScraper(time.Duration(timeoutMs) * time.Millisecond)
+			engine = scraper.NewHTTPScraper(fetchTimeout)
 		} else {
-			engine = scraper.NewRodScraper(time.Duration(timeoutMs) * time.Millisecond)
+			engine = scraper.NewBrowserScraper(fetchTimeout, browserOptions(cfg))
 		}
 	} else {
-		engine = scraper.NewHTTPScraper(time.Duration(timeoutMs) * time.Millisecond)
+		engine = scraper.NewHTTPScraper(fetchTimeout)
 	}
 
 	headers := map[string]string{}
@@ -1021,18 +1835,37 @@ func runScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store, jobI
 			headers["Accept-Language"] = req.Location.Country
 		}
 	}
-
-	scrapeReq := scraper.Request{
-		URL:       req.URL,
-		Headers:   headers,
-		Timeout:   time.Duration(timeoutMs) * time.Millisecond,
-		UserAgent: cfg.Scraper.UserAgent,
+	var authHeaders map[string]string
+	if req.Auth != nil {
+		var err error
+		authHeaders, err = targetAuthHeaders(ctx, cfg, db.New(st.DB), tenantIDFromContext(ctx), req.Auth)
+		if err != nil {
+			_, code := targetAuthStatus(err)
+			msg := code + ": " + err.Error()
+			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+			return
+		}
 	}
 
-	scrapeCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	scrapeReq := scraper.Request{
+		URL:         req.URL,
+		Headers:     headers,
+		AuthHeaders: authHeaders,
+		Timeout:     fetchTimeout,
+		UserAgent:   cfg.RequestUserAgent(),
+		Method:      req.Method,
+		Body:        req.Body,
+		ContentType: req.ContentType,
+		Actions:     scraperActions(req.Actions),
+	}
+
+	scrapeCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
 	defer cancel()
 
+	fetchStart := time.Now()
 	res, err := engine.Scrape(scrapeCtx, scrapeReq)
+	capturedAt := time.Now()
+	recordHostScrape(req.URL, scrapeStatus(res), time.Since(fetchStart), err)
 	if err != nil {
 		msg := "SCRAPE_FAILED: " + err.Error()
 		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
@@ -1058,171 +1891,240 @@ func runScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store, jobI
 	doc := (*Document)(svcRes.Document)
 	md := doc.Metadata
 
+	// Plugin formats installed by the operator.
+	pluginOut, err := plugins.NewRegistry(cfg.Plugins).RunAll(ctx, req.Formats, res)
+	if err != nil {
+		msg := "PLUGIN_FAILED: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	if len(pluginOut) > 0 {
+		doc.Plugins = pluginOut
+	}
+
 	// Optional screenshot format using the browser engine when requested.
-	if hasScreenshot {
-		screenshotCtx, screenshotCancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	var screenshot []byte
+	if shotTimeout, ok := budget.start(scrapeStageScreenshot); hasScreenshot && ok {
+		screenshotCtx, screenshotCancel := context.WithTimeout(ctx, shotTimeout)
 		defer screenshotCancel()
 
-		shot, err := scraper.CaptureScreenshot(screenshotCtx, res.URL, time.Duration(timeoutMs)*time.Millisecond, screenshotFullPage)
-		if err != nil {
+		shot, err := scraper.CaptureBrowserScreenshot(screenshotCtx, browserOptions(cfg), res.URL, shotTimeout, screenshotFullPage)
+		if err != nil && !budget.expired(screenshotCtx, scrapeStageScreenshot, err) {
 			msg := "SCREENSHOT_FAILED: " + err.Error()
 			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
 			return
 		}
-
-		doc.Screenshot = base64.StdEncoding.EncodeToString(shot)
+		if err == nil {
+			screenshot = shot
+			doc.Screenshot = base64.StdEncoding.EncodeToString(shot)
+		}
 	}
 
-	// Optional summary format using the configured LLM provider when requested.
-	if scrapeutil.WantsFormat(req.Formats, "summary") {
-		client, provider, modelName, err := llm.NewClientFromConfig(cfg, "", "")
-		if err != nil {
-			msg := "LLM_NOT_CONFIGURED: " + err.Error()
-			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-			return
+	// Optional classify format; it falls back to term matching instead of
+	// failing the job when the LLM is unavailable.
+	if wantClassify, labels := scrapeutil.GetClassifyFormatConfig(req.Formats); wantClassify {
+		if classifyTimeout, ok := budget.start(scrapeStageClassify); ok {
+			classifier := newPageClassifier(ctx, cfg, st, tenantIDFromContext(ctx), labels, classifyTimeout)
+			doc.Metadata.Categories = classifier.Classify(ctx, req.URL, res.Markdown)
 		}
+	}
 
-		fieldSpecs := []llm.FieldSpec{{
-			Name:        "summary",
-			Description: "Short natural-language summary of the page content.",
-			Type:        "string",
-		}}
-
-		llmTimeout := time.Duration(timeoutMs) * time.Millisecond
-		llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
-		defer llmCancel()
-
-		llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
-			URL:      req.URL,
-			Markdown: res.Markdown,
-			Fields:   fieldSpecs,
-			Prompt:   "",
-			Timeout:  llmTimeout,
-			Strict:   false,
-		})
-		if err != nil {
-			metrics.RecordLLMExtract(string(provider), modelName, false)
-			msg := "SUMMARY_FAILED: " + err.Error()
-			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-			return
-		}
+	// Optional summary format using the configured LLM provider when requested.
+	if wantSummary, summaryPrompt := scrapeutil.GetSummaryFormatConfig(req.Formats); wantSummary {
+		if llmTimeout, ok := budget.start(scrapeStageSummary); ok {
+			client, provider, modelName, err := newLLMClient(ctx, cfg, st, tenantIDFromContext(ctx), "", "")
+			if err != nil {
+				msg := llmClientErrorMessage(err)
+				_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+				return
+			}
 
-		metrics.RecordLLMExtract(string(provider), modelName, true)
+			fieldSpecs := []llm.FieldSpec{{
+				Name:        "summary",
+				Description: "Short natural-language summary of the page content.",
+				Type:        "string",
+			}}
+
+			llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
+			defer llmCancel()
+
+			llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
+				URL:      req.URL,
+				Markdown: res.Markdown,
+				Fields:   fieldSpecs,
+				Prompt:   summaryPrompt,
+				Timeout:  llmTimeout,
+				Strict:   false,
+			})
+			if err != nil {
+				metrics.RecordLLMExtract(string(provider), modelName, false)
+				if !budget.expired(llmCtx, scrapeStageSummary, err) {
+					_, code := llmFailure(err, "SUMMARY_FAILED")
+					msg := code + ": " + err.Error()
+					_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+					return
+				}
+			} else {
+				metrics.RecordLLMExtract(string(provider), modelName, true)
 
-		if v, ok := llmRes.Fields["summary"]; ok {
-			if s, ok := v.(string); ok {
-				doc.Summary = s
+				if v, ok := llmRes.Fields["summary"]; ok {
+					if s, ok := v.(string); ok {
+						doc.Summary = s
+					}
+				}
 			}
 		}
 	}
 
 	// Optional json format using the configured LLM provider when requested.
 	if hasJSON, jsonPrompt, jsonSchema := scrapeutil.GetJSONFormatConfig(req.Formats); hasJSON {
-		client, provider, modelName, err := llm.NewClientFromConfig(cfg, "", "")
-		if err != nil {
-			msg := "LLM_NOT_CONFIGURED: " + err.Error()
-			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-			return
-		}
+		if llmTimeout, ok := budget.start(scrapeStageJSON); ok {
+			client, provider, modelName, err := newLLMClient(ctx, cfg, st, tenantIDFromContext(ctx), "", "")
+			if err != nil {
+				msg := llmClientErrorMessage(err)
+				_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+				return
+			}
 
-		desc := "Arbitrary JSON object extracted from the page content."
-		if len(jsonSchema) > 0 {
-			if schemaBytes, err := json.Marshal(jsonSchema); err == nil {
-				desc = desc + " Schema: " + string(schemaBytes)
+			desc := "Arbitrary JSON object extracted from the page content."
+			if len(jsonSchema) > 0 {
+				if schemaBytes, err := json.Marshal(jsonSchema); err == nil {
+					desc = desc + " Schema: " + string(schemaBytes)
+				}
+			}
+
+			fieldSpecs := []llm.FieldSpec{{
+				Name:        "json",
+				Description: desc,
+				Type:        "object",
+			}}
+
+			llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
+			defer llmCancel()
+
+			llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
+				URL:      md.SourceURL,
+				Markdown: res.Markdown,
+				Fields:   fieldSpecs,
+				Prompt:   jsonPrompt,
+				Timeout:  llmTimeout,
+				Strict:   false,
+			})
+			if err != nil {
+				metrics.RecordLLMExtract(string(provider), modelName, false)
+				if !budget.expired(llmCtx, scrapeStageJSON, err) {
+					_, code := llmFailure(err, "JSON_EXTRACT_FAILED")
+					msg := code + ": " + err.Error()
+					_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+					return
+				}
+			} else {
+				metrics.RecordLLMExtract(string(provider), modelName, true)
+
+				if v, ok := llmRes.Fields["json"]; ok {
+					if m, ok := v.(map[string]any); ok {
+						doc.JSON = m
+					} else {
+						doc.JSON = map[string]any{"_value": v}
+					}
+				}
 			}
 		}
+	}
 
-		fieldSpecs := []llm.FieldSpec{{
-			Name:        "json",
-			Description: desc,
-			Type:        "object",
-		}}
+	// Optional branding format using the configured LLM provider when requested.
+	if hasBranding, brandingPrompt := scrapeutil.GetBrandingFormatConfig(req.Formats); hasBranding {
+		if llmTimeout, ok := budget.start(scrapeStageBranding); ok {
+			client, provider, modelName, err := newLLMClient(ctx, cfg, st, tenantIDFromContext(ctx), "", "")
+			if err != nil {
+				msg := llmClientErrorMessage(err)
+				_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+				return
+			}
 
-		llmTimeout := time.Duration(timeoutMs) * time.Millisecond
-		llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
-		defer llmCancel()
+			if brandingPrompt == "" {
+				brandingPrompt = "You are a brand design expert analyzing a website. Analyze the page and return a single JSON object describing the brand, matching this structure as closely as possible: " +
+					"{colorScheme?: 'light'|'dark', colors?: {primary?: string, secondary?: string, accent?: string, background?: string, textPrimary?: string, textSecondary?: string, link?: string, success?: string, warning?: string, error?: string}, " +
+					"typography?: {fontFamilies?: {primary?: string, heading?: string, code?: string}, fontStacks?: {primary?: string[], heading?: string[], body?: string[], paragraph?: string[]}, fontSizes?: {h1?: string, h2?: string, h3?: string, body?: string, small?: string}}, " +
+					"spacing?: {baseUnit?: number, borderRadius?: string}, components?: {buttonPrimary?: {background?: string, textColor?: string, borderColor?: string, borderRadius?: string}, buttonSecondary?: {...}}, " +
+					"images?: {logo?: string|null, favicon?: string|null, ogImage?: string|null}, personality?: {tone?: string, energy?: string, targetAudience?: string}}. " +
+					"Only include fields you can infer with reasonable confidence."
+			}
 
-		llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
-			URL:      md.SourceURL,
-			Markdown: res.Markdown,
-			Fields:   fieldSpecs,
-			Prompt:   jsonPrompt,
-			Timeout:  llmTimeout,
-			Strict:   false,
-		})
-		if err != nil {
-			metrics.RecordLLMExtract(string(provider), modelName, false)
-			msg := "JSON_EXTRACT_FAILED: " + err.Error()
-			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-			return
-		}
+			descBranding := "Brand identity and design system information (colors, typography, logo, components, personality, etc.) extracted from the page, following Firecrawl's BrandingProfile conventions."
 
-		metrics.RecordLLMExtract(string(provider), modelName, true)
+			fieldSpecs := []llm.FieldSpec{{
+				Name:        "branding",
+				Description: descBranding,
+				Type:        "object",
+			}}
 
-		if v, ok := llmRes.Fields["json"]; ok {
-			if m, ok := v.(map[string]any); ok {
-				doc.JSON = m
+			llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
+			defer llmCancel()
+
+			llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
+				URL:      req.URL,
+				Markdown: res.Markdown,
+				Fields:   fieldSpecs,
+				Prompt:   brandingPrompt,
+				Timeout:  llmTimeout,
+				Strict:   false,
+			})
+			if err != nil {
+				metrics.RecordLLMExtract(string(provider), modelName, false)
+				if !budget.expired(llmCtx, scrapeStageBranding, err) {
+					_, code := llmFailure(err, "BRANDING_FAILED")
+					msg := code + ": " + err.Error()
+					_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+					return
+				}
 			} else {
-				doc.JSON = map[string]any{"_value": v}
+				metrics.RecordLLMExtract(string(provider), modelName, true)
+
+				if v, ok := llmRes.Fields["branding"]; ok {
+					if m, ok := v.(map[string]any); ok {
+						scrapeutil.NormalizeBrandingImages(m)
+						doc.Branding = m
+					} else {
+						doc.Branding = map[string]any{"_value": v}
+					}
+				}
 			}
 		}
 	}
 
-	// Optional branding format using the configured LLM provider when requested.
-	if hasBranding, brandingPrompt := scrapeutil.GetBrandingFormatConfig(req.Formats); hasBranding {
-		client, provider, modelName, err := llm.NewClientFromConfig(cfg, "", "")
-		if err != nil {
-			msg := "LLM_NOT_CONFIGURED: " + err.Error()
-			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
-			return
-		}
-
-		if brandingPrompt == "" {
-			brandingPrompt = "You are a brand design expert analyzing a website. Analyze the page and return a single JSON object describing the brand, matching this structure as closely as possible: " +
-				"{colorScheme?: 'light'|'dark', colors?: {primary?: string, secondary?: string, accent?: string, background?: string, textPrimary?: string, textSecondary?: string, link?: string, success?: string, warning?: string, error?: string}, " +
-				"typography?: {fontFamilies?: {primary?: string, heading?: string, code?: string}, fontStacks?: {primary?: string[], heading?: string[], body?: string[], paragraph?: string[]}, fontSizes?: {h1?: string, h2?: string, h3?: string, body?: string, small?: string}}, " +
-				"spacing?: {baseUnit?: number, borderRadius?: string}, components?: {buttonPrimary?: {background?: string, textColor?: string, borderColor?: string, borderRadius?: string}, buttonSecondary?: {...}}, " +
-				"images?: {logo?: string|null, favicon?: string|null, ogImage?: string|null}, personality?: {tone?: string, energy?: string, targetAudience?: string}}. " +
-				"Only include fields you can infer with reasonable confidence."
+	if req.DownloadImages != nil && *req.DownloadImages && doc.Markdown != "" {
+		if imagesTimeout, ok := budget.start(scrapeStageImages); ok {
+			imagesCtx, imagesCancel := context.WithTimeout(ctx, imagesTimeout)
+			defer imagesCancel()
+			doc.Markdown = archiveImages(imagesCtx, cfg, st, jobID, res.URL, doc.Markdown, scraper.ExtractImages(res.HTML, res.URL))
 		}
+	}
+	doc.Metadata.SkippedFormats = budget.skipped
 
-		descBranding := "Brand identity and design system information (colors, typography, logo, components, personality, etc.) extracted from the page, following Firecrawl's BrandingProfile conventions."
-
-		fieldSpecs := []llm.FieldSpec{{
-			Name:        "branding",
-			Description: descBranding,
-			Type:        "object",
-		}}
-
-		llmTimeout := time.Duration(timeoutMs) * time.Millisecond
-		llmCtx, llmCancel := context.WithTimeout(ctx, llmTimeout)
-		defer llmCancel()
+	transformed, keep, err := hook.apply(ctx, jobID, "scrape", *doc)
+	if err != nil {
+		msg := "TRANSFORM_HOOK_FAILED: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	if !keep {
+		msg := "TRANSFORM_HOOK_DROPPED: " + errTransformHookDropped.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	doc = &transformed
 
-		llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
-			URL:      req.URL,
-			Markdown: res.Markdown,
-			Fields:   fieldSpecs,
-			Prompt:   brandingPrompt,
-			Timeout:  llmTimeout,
-			Strict:   false,
-		})
+	// Evidence is recorded after the transform hook so the hook cannot
+	// change it.
+	if req.Evidence != nil && *req.Evidence {
+		ev, err := recordScrapeEvidence(context.Background(), st, jobID, res, screenshot, capturedAt)
 		if err != nil {
-			metrics.RecordLLMExtract(string(provider), modelName, false)
-			msg := "BRANDING_FAILED: " + err.Error()
+			msg := "EVIDENCE_FAILED: " + err.Error()
 			_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
 			return
 		}
-
-		metrics.RecordLLMExtract(string(provider), modelName, true)
-
-		if v, ok := llmRes.Fields["branding"]; ok {
-			if m, ok := v.(map[string]any); ok {
-				scrapeutil.NormalizeBrandingImages(m)
-				doc.Branding = m
-			} else {
-				doc.Branding = map[string]any{"_value": v}
-			}
-		}
+		doc.Evidence = ev
 	}
 
 	output, err := json.Marshal(doc)
@@ -1237,6 +2139,7 @@ func runScrapeJob(ctx context.Context, cfg *config.Config, st *store.Store, jobI
 		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
 		return
 	}
+	recordDocumentVersion(context.Background(), cfg, st, jobID, res.URL, doc.Markdown, doc.Metadata.Title, int32(res.Status))
 
 	_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusCompleted), nil)
 }
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -10,8 +11,11 @@ import (
 	"github.com/google/uuid"
 
 	"raito/internal/config"
+	"raito/internal/crawler"
+	"raito/internal/db"
 	"raito/internal/llm"
 	"raito/internal/scraper"
+	"raito/internal/store"
 )
 
 type fakeJobStore struct {
@@ -169,6 +173,10 @@ func TestRunExtractJob_SingleURLSuccess(t *testing.T) {
 	if ok, _ := res0["success"].(bool); !ok {
 		t.Fatalf("expected success=true, got %#v", res0["success"])
 	}
+	provenance := readMap(t, res0["provenance"])
+	if title := readMap(t, provenance["title"]); title["match"] != "none" || title["confidence"] != 0.2 {
+		t.Fatalf("unexpected provenance for title: %#v", title)
+	}
 }
 
 func TestRunExtractJob_MixedIgnoreInvalidFalse(t *testing.T) {
@@ -516,3 +524,340 @@ func TestRunExtractJob_EmptyResultHandling(t *testing.T) {
 		t.Fatalf("expected EXTRACT_EMPTY_RESULT count=1, got %v", failedByCode["EXTRACT_EMPTY_RESULT"])
 	}
 }
+
+func TestRunExtractJob_WildcardExpandsAndMerges(t *testing.T) {
+	cfg := newTestConfig()
+	st := &fakeJobStore{}
+
+	pageA := "https://example.com/blog/a"
+	pageB := "https://example.com/blog/b"
+	fakeScr := &fakeScraper{
+		byURL: map[string]*scraper.Result{
+			pageA: {URL: pageA, Markdown: "A", Status: 200},
+			pageB: {URL: pageB, Markdown: "B", Status: 200},
+		},
+		errByURL: map[string]error{},
+	}
+	fakeLLMClient := &fakeLLM{
+		fieldsByURL: map[string]map[string]any{
+			pageA: {"json": map[string]any{"company": "Example", "posts": []any{"a"}}},
+			pageB: {"json": map[string]any{"company": "", "posts": []any{"b"}}},
+		},
+		errByURL: map[string]error{},
+	}
+
+	deps := &extractDeps{
+		scraper:   fakeScr,
+		client:    fakeLLMClient,
+		provider:  llm.Provider("test"),
+		modelName: "test-model",
+		timeout:   time.Second,
+		mapLinks: func(_ context.Context, opts crawler.MapOptions) (*crawler.MapResult, error) {
+			if opts.URL != "https://example.com/blog/" {
+				return nil, fmt.Errorf("unexpected map url %q", opts.URL)
+			}
+			return &crawler.MapResult{Links: []crawler.Link{
+				{URL: pageA},
+				{URL: "https://example.com/about"},
+				{URL: pageB},
+			}}, nil
+		},
+	}
+	reset := withFakeDeps(t, deps)
+	defer reset()
+
+	req := ExtractRequest{
+		URLs:   []string{"https://example.com/blog/*"},
+		Schema: map[string]any{"type": "object"},
+	}
+
+	runExtractJob(context.Background(), cfg, st, uuid.New(), req)
+
+	if st.lastStatus != "completed" {
+		t.Fatalf("expected status completed, got %q (err=%v)", st.lastStatus, st.lastError)
+	}
+
+	out := decodeOutput(t, st.output)
+	if results := readArray(t, out, "results"); len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	data := readMap(t, out["data"])
+	if data["company"] != "Example" {
+		t.Fatalf("expected merged company, got %#v", data["company"])
+	}
+	if posts := readArray(t, data, "posts"); len(posts) != 2 {
+		t.Fatalf("expected merged posts from both pages, got %#v", posts)
+	}
+}
+
+// fakeEventJobStore records the job events added during a run.
+type fakeEventJobStore struct {
+	fakeJobStore
+	events []string
+	data   []map[string]any
+}
+
+func (f *fakeEventJobStore) AddJobEvent(_ context.Context, _ uuid.UUID, eventType, _ string, data map[string]any) error {
+	f.events = append(f.events, eventType)
+	f.data = append(f.data, data)
+	return nil
+}
+
+func TestRunExtractJob_WildcardRecordsDiscoveryEvent(t *testing.T) {
+	cfg := newTestConfig()
+	st := &fakeEventJobStore{}
+
+	page := "https://example.com/docs/a"
+	deps := &extractDeps{
+		scraper: &fakeScraper{
+			byURL:    map[string]*scraper.Result{page: {URL: page, Markdown: "A", Status: 200}},
+			errByURL: map[string]error{},
+		},
+		client: &fakeLLM{
+			fieldsByURL: map[string]map[string]any{page: {"json": map[string]any{"title": "A"}}},
+			errByURL:    map[string]error{},
+		},
+		provider:  llm.Provider("test"),
+		modelName: "test-model",
+		timeout:   time.Second,
+		mapLinks: func(_ context.Context, _ crawler.MapOptions) (*crawler.MapResult, error) {
+			return &crawler.MapResult{Links: []crawler.Link{{URL: page}}}, nil
+		},
+	}
+	reset := withFakeDeps(t, deps)
+	defer reset()
+
+	runExtractJob(context.Background(), cfg, st, uuid.New(), ExtractRequest{
+		URLs:   []string{"https://example.com/docs/*"},
+		Schema: map[string]any{"type": "object"},
+	})
+
+	if st.lastStatus != "completed" {
+		t.Fatalf("expected status completed, got %q (err=%v)", st.lastStatus, st.lastError)
+	}
+	if len(st.events) != 1 || st.events[0] != store.JobEventDiscoveryFinished {
+		t.Fatalf("expected one discovery_finished event, got %v", st.events)
+	}
+	if st.data[0]["queued"] != 1 {
+		t.Fatalf("expected queued=1, got %#v", st.data[0])
+	}
+}
+
+func TestRunExtractJob_MergeModeWithFieldSources(t *testing.T) {
+	cfg := newTestConfig()
+	st := &fakeJobStore{}
+
+	pageA := "https://example.com/a"
+	pageB := "https://example.com/b"
+	fakeScr := &fakeScraper{
+		byURL: map[string]*scraper.Result{
+			pageA: {URL: pageA, Markdown: "Founded 1999", Status: 200},
+			pageB: {URL: pageB, Markdown: "CEO Jane", Status: 200},
+		},
+		errByURL: map[string]error{},
+	}
+	// Merge mode issues a single LLM call keyed by the first page URL.
+	fakeLLMClient := &fakeLLM{
+		fieldsByURL: map[string]map[string]any{
+			pageA: {
+				"json": map[string]any{"founded": "1999", "ceo": "Jane"},
+				"fieldSources": map[string]any{
+					"founded": []any{pageA},
+					"ceo":     []any{pageB, "https://unknown.example.com"},
+				},
+			},
+		},
+		errByURL: map[string]error{},
+	}
+
+	deps := &extractDeps{
+		scraper:   fakeScr,
+		client:    fakeLLMClient,
+		provider:  llm.Provider("test"),
+		modelName: "test-model",
+		timeout:   time.Second,
+	}
+	reset := withFakeDeps(t, deps)
+	defer reset()
+
+	showSources := true
+	req := ExtractRequest{
+		URLs:        []string{pageA, pageB},
+		Schema:      map[string]any{"type": "object"},
+		Mode:        extractModeMerge,
+		ShowSources: &showSources,
+	}
+
+	runExtractJob(context.Background(), cfg, st, uuid.New(), req)
+
+	if st.lastStatus != "completed" {
+		t.Fatalf("expected status completed, got %q (err=%v)", st.lastStatus, st.lastError)
+	}
+
+	out := decodeOutput(t, st.output)
+	data := readMap(t, out["data"])
+	if data["ceo"] != "Jane" || data["founded"] != "1999" {
+		t.Fatalf("unexpected merged data: %#v", data)
+	}
+
+	fieldSources := readMap(t, out["fieldSources"])
+	ceo := readArray(t, fieldSources, "ceo")
+	if len(ceo) != 1 || ceo[0] != pageB {
+		t.Fatalf("expected ceo attributed to %s only, got %#v", pageB, ceo)
+	}
+}
+
+// fakeCachingJobStore adds an in-memory extract cache to fakeJobStore.
+type fakeCachingJobStore struct {
+	fakeJobStore
+	entries map[string]db.ExtractCache
+}
+
+func (f *fakeCachingJobStore) GetExtractCacheEntry(_ context.Context, key string, notBefore time.Time) (db.ExtractCache, error) {
+	entry, ok := f.entries[key]
+	if !ok || entry.CreatedAt.Before(notBefore) || !entry.ExpiresAt.After(time.Now()) {
+		return db.ExtractCache{}, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func (f *fakeCachingJobStore) PutExtractCacheEntry(_ context.Context, params db.UpsertExtractCacheEntryParams) error {
+	f.entries[params.CacheKey] = db.ExtractCache{
+		CacheKey:   params.CacheKey,
+		TenantID:   params.TenantID,
+		Url:        params.Url,
+		SchemaHash: params.SchemaHash,
+		PromptHash: params.PromptHash,
+		Result:     params.Result,
+		StatusCode: params.StatusCode,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  params.ExpiresAt,
+	}
+	return nil
+}
+
+func TestRunExtractJob_ReusesCachedResults(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Extract.Cache.Enabled = true
+	st := &fakeCachingJobStore{entries: map[string]db.ExtractCache{}}
+
+	url := "https://example.com"
+	fakeScr := &fakeScraper{
+		byURL:    map[string]*scraper.Result{url: {URL: url, Markdown: "Hello", Status: 200}},
+		errByURL: map[string]error{},
+	}
+	fakeLLMClient := &fakeLLM{
+		fieldsByURL: map[string]map[string]any{url: {"json": map[string]any{"title": "Example"}}},
+		errByURL:    map[string]error{},
+	}
+	deps := &extractDeps{
+		scraper:   fakeScr,
+		client:    fakeLLMClient,
+		provider:  llm.Provider("test"),
+		modelName: "test-model",
+		timeout:   time.Second,
+	}
+	reset := withFakeDeps(t, deps)
+	defer reset()
+
+	req := ExtractRequest{
+		URLs:   []string{url},
+		Schema: map[string]any{"type": "object"},
+	}
+
+	runExtractJob(context.Background(), cfg, st, uuid.New(), req)
+	if st.lastStatus != "completed" || len(st.entries) != 1 {
+		t.Fatalf("expected completed job and one cache entry, got %q / %d", st.lastStatus, len(st.entries))
+	}
+
+	// The page can no longer be scraped, so only a cache hit can succeed.
+	fakeScr.errByURL[url] = fmt.Errorf("unreachable")
+
+	runExtractJob(context.Background(), cfg, st, uuid.New(), req)
+	if st.lastStatus != "completed" {
+		t.Fatalf("expected cached job to complete, got %q (err=%v)", st.lastStatus, st.lastError)
+	}
+	res0 := readMap(t, readArray(t, decodeOutput(t, st.output), "results")[0])
+	if cached, _ := res0["cached"].(bool); !cached {
+		t.Fatalf("expected cached=true, got %#v", res0)
+	}
+	if readMap(t, res0["json"])["title"] != "Example" {
+		t.Fatalf("unexpected cached json: %#v", res0["json"])
+	}
+
+	// A different prompt must not reuse the entry.
+	withPrompt := req
+	withPrompt.Prompt = "Only the title"
+	runExtractJob(context.Background(), cfg, st, uuid.New(), withPrompt)
+	if st.lastStatus != "failed" {
+		t.Fatalf("expected prompt change to bypass cache, got %q", st.lastStatus)
+	}
+
+	// maxAge=0 forces a fresh extract.
+	zero := int64(0)
+	fresh := req
+	fresh.MaxAge = &zero
+	runExtractJob(context.Background(), cfg, st, uuid.New(), fresh)
+	if st.lastStatus != "failed" {
+		t.Fatalf("expected maxAge=0 to bypass cache, got %q", st.lastStatus)
+	}
+}
+
+type fakeDocumentJobStore struct {
+	fakeJobStore
+	docs []fakeExtractDocument
+}
+
+type fakeExtractDocument struct {
+	url      string
+	markdown *string
+	metadata map[string]any
+}
+
+func (f *fakeDocumentJobStore) AddExtractDocument(_ context.Context, _ uuid.UUID, url string, markdown *string, metadata json.RawMessage, _ *int32) error {
+	var md map[string]any
+	_ = json.Unmarshal(metadata, &md)
+	f.docs = append(f.docs, fakeExtractDocument{url: url, markdown: markdown, metadata: md})
+	return nil
+}
+
+func TestRunExtractJob_StoresResultsAsDocuments(t *testing.T) {
+	st := &fakeDocumentJobStore{}
+
+	okURL, badURL := "https://ok.com", "https://bad.com"
+	deps := &extractDeps{
+		scraper: &fakeScraper{
+			byURL:    map[string]*scraper.Result{okURL: {URL: okURL, Markdown: "# OK", Status: 200}},
+			errByURL: map[string]error{badURL: fmt.Errorf("timeout")},
+		},
+		client: &fakeLLM{
+			fieldsByURL: map[string]map[string]any{okURL: {"json": map[string]any{"title": "OK"}}},
+		},
+		provider:  llm.Provider("test"),
+		modelName: "test-model",
+		timeout:   time.Second,
+	}
+	reset := withFakeDeps(t, deps)
+	defer reset()
+
+	ignore := true
+	runExtractJob(context.Background(), newTestConfig(), st, uuid.New(), ExtractRequest{
+		URLs:              []string{okURL, badURL},
+		Schema:            map[string]any{"type": "object"},
+		IgnoreInvalidURLs: &ignore,
+	})
+	if st.lastStatus != "completed" {
+		t.Fatalf("expected completed job, got %q", st.lastStatus)
+	}
+	if len(st.docs) != 1 {
+		t.Fatalf("expected one document for the successful URL, got %d", len(st.docs))
+	}
+	doc := st.docs[0]
+	if doc.url != okURL || doc.markdown == nil || *doc.markdown != "# OK" {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+	if readMap(t, doc.metadata["json"])["title"] != "OK" || doc.metadata["sourceURL"] != okURL {
+		t.Fatalf("expected the extracted JSON in the metadata, got %#v", doc.metadata)
+	}
+}
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/formats"
+	"raito/internal/store"
+)
+
+// builtinDefaultFormats is used when neither the tenant nor the server
+// configures default formats.
+var builtinDefaultFormats = []string{"markdown"}
+
+// DefaultFormatsResponse describes the formats used by a tenant's requests
+// that set none, and where they come from: "tenant", "config" or
+// "default".
+type DefaultFormatsResponse struct {
+	Success   bool     `json:"success"`
+	Code      string   `json:"code,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Formats   []string `json:"formats,omitempty"`
+	Source    string   `json:"source,omitempty"`
+	UpdatedAt string   `json:"updatedAt,omitempty"`
+}
+
+// DefaultFormatsRequest replaces a tenant's default formats.
+type DefaultFormatsRequest struct {
+	Formats []string `json:"formats"`
+}
+
+func pluginFormatNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Plugins.Formats))
+	for _, p := range cfg.Plugins.Formats {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// resolveDefaultFormats returns the default formats for tenantID and their
+// source. Tenant defaults win over scraper.defaultFormats, which wins over
+// markdown only. q may be nil when no database is available.
+func resolveDefaultFormats(ctx context.Context, cfg *config.Config, q *db.Queries, tenantID *uuid.UUID) ([]string, string, error) {
+	if q != nil && tenantID != nil {
+		row, err := q.GetTenantDefaultFormats(ctx, *tenantID)
+		switch {
+		case err == nil:
+			var names []string
+			if err := json.Unmarshal(row.Formats, &names); err != nil {
+				return nil, "", err
+			}
+			if len(names) > 0 {
+				return names, optionSourceTenant, nil
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return nil, "", err
+		}
+	}
+	if cfg != nil && len(cfg.Scraper.DefaultFormats) > 0 {
+		// Validated at startup, so only the spelling is normalized here.
+		if names, err := formats.NormalizeDefaultFormats(cfg.Scraper.DefaultFormats, pluginFormatNames(cfg)); err == nil {
+			return names, optionSourceConfig, nil
+		}
+	}
+	return builtinDefaultFormats, optionSourceDefault, nil
+}
+
+// applyDefaultFormats fills an empty formats array with the caller's
+// default formats before the request is validated and stored, so the
+// worker, job status and downloads all see the same formats. It returns
+// the source of the defaults, or "" when the request set formats.
+func applyDefaultFormats(c *fiber.Ctx, target *[]any) (string, error) {
+	if len(*target) > 0 {
+		return "", nil
+	}
+	cfg, _ := c.Locals("config").(*config.Config)
+	var q *db.Queries
+	if st, ok := c.Locals("store").(*store.Store); ok && st != nil && st.DB != nil {
+		q = db.New(st.DB)
+	}
+	var tenantID *uuid.UUID
+	if p, ok := c.Locals("principal").(Principal); ok {
+		tenantID = p.TenantID
+	}
+
+	names, source, err := resolveDefaultFormats(c.Context(), cfg, q, tenantID)
+	if err != nil {
+		return "", err
+	}
+	out := make([]any, 0, len(names))
+	for _, n := range names {
+		out = append(out, n)
+	}
+	*target = out
+	return source, nil
+}
+
+// defaultFormatsLookupFailed writes the response for a failed default
+// formats lookup.
+func defaultFormatsLookupFailed(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+		Success: false,
+		Code:    "DEFAULT_FORMATS_LOOKUP_FAILED",
+		Error:   err.Error(),
+	})
+}
+
+// tenantGetDefaultFormatsHandler returns the formats the tenant's requests
+// get when they set none.
+func tenantGetDefaultFormatsHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, false)
+	if !ok {
+		return err
+	}
+	return writeDefaultFormats(c, tenantID)
+}
+
+// tenantPutDefaultFormatsHandler sets the tenant's default formats.
+func tenantPutDefaultFormatsHandler(c *fiber.Ctx) error {
+	p, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	var req DefaultFormatsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DefaultFormatsResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if len(req.Formats) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(DefaultFormatsResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Missing required field 'formats'",
+		})
+	}
+	cfg := c.Locals("config").(*config.Config)
+	names, err := formats.NormalizeDefaultFormats(req.Formats, pluginFormatNames(cfg))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DefaultFormatsResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+	raw, err := json.Marshal(names)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(DefaultFormatsResponse{
+			Success: false,
+			Code:    "DEFAULT_FORMATS_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	st := c.Locals("store").(*store.Store)
+	params := db.UpsertTenantDefaultFormatsParams{TenantID: tenantID, Formats: raw}
+	if p.UserID != nil {
+		params.UpdatedByUserID = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+	if _, err := db.New(st.DB).UpsertTenantDefaultFormats(c.Context(), params); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(DefaultFormatsResponse{
+			Success: false,
+			Code:    "DEFAULT_FORMATS_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.default_formats.set", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+		Metadata:     map[string]any{"formats": names},
+	})
+	return writeDefaultFormats(c, tenantID)
+}
+
+// tenantDeleteDefaultFormatsHandler removes the tenant's default formats,
+// so its requests fall back to the server defaults.
+func tenantDeleteDefaultFormatsHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	n, err := db.New(st.DB).DeleteTenantDefaultFormats(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(DefaultFormatsResponse{
+			Success: false,
+			Code:    "DEFAULT_FORMATS_DELETE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if n > 0 {
+		recordAuditEvent(c, st, "tenant.default_formats.delete", auditEventOptions{
+			TenantID:     &tenantID,
+			ResourceType: "tenant",
+			ResourceID:   tenantID.String(),
+		})
+	}
+	return writeDefaultFormats(c, tenantID)
+}
+
+func writeDefaultFormats(c *fiber.Ctx, tenantID uuid.UUID) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+
+	names, source, err := resolveDefaultFormats(c.Context(), cfg, q, &tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(DefaultFormatsResponse{
+			Success: false,
+			Code:    "DEFAULT_FORMATS_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	resp := DefaultFormatsResponse{Success: true, Formats: names, Source: source}
+	if source == optionSourceTenant {
+		if row, err := q.GetTenantDefaultFormats(c.Context(), tenantID); err == nil {
+			resp.UpdatedAt = row.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+)
+
+func TestResolveDefaultFormats_ConfigAndBuiltin(t *testing.T) {
+	names, source, err := resolveDefaultFormats(context.Background(), &config.Config{}, nil, nil)
+	if err != nil || source != optionSourceDefault || len(names) != 1 || names[0] != "markdown" {
+		t.Fatalf("builtin: got %v %q %v", names, source, err)
+	}
+
+	cfg := &config.Config{Scraper: config.ScraperConfig{DefaultFormats: []string{"markdown", "LINKS"}}}
+	names, source, err = resolveDefaultFormats(context.Background(), cfg, nil, nil)
+	if err != nil || source != optionSourceConfig || len(names) != 2 || names[1] != "links" {
+		t.Fatalf("config: got %v %q %v", names, source, err)
+	}
+}
+
+func TestApplyDefaultFormats_FillsOnlyEmptyFormats(t *testing.T) {
+	cfg := &config.Config{Scraper: config.ScraperConfig{DefaultFormats: []string{"markdown", "links"}}}
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		var req ScrapeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return err
+		}
+		source, err := applyDefaultFormats(c, &req.Formats)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"formats": req.Formats, "source": source})
+	})
+
+	for body, want := range map[string]string{
+		`{"url":"https://example.com"}`:                    `{"formats":["markdown","links"],"source":"config"}`,
+		`{"url":"https://example.com","formats":["html"]}`: `{"formats":["html"],"source":""}`,
+	} {
+		req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("app.Test error: %v", err)
+		}
+		var got map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		raw, _ := json.Marshal(got)
+		if string(raw) != want {
+			t.Fatalf("body %s: got %s, want %s", body, raw, want)
+		}
+	}
+}
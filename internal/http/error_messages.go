@@ -0,0 +1,361 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultErrorLanguage is the language handlers write error text in.
+const defaultErrorLanguage = "en"
+
+// errorLanguages are the languages error text can be translated to.
+var errorLanguages = []string{"en", "de", "es", "fr"}
+
+// errorMessage is a catalog entry: a stable key for an English error
+// text, the parameters taken from it, and its translations. Translations
+// use {name} placeholders for the named groups of the pattern.
+type errorMessage struct {
+	key     string
+	pattern *regexp.Regexp
+	text    map[string]string
+}
+
+// errorCatalog lists the user-facing error texts clients can localize.
+// Entries are tried in order, so more specific patterns come first.
+var errorCatalog = []errorMessage{
+	{
+		key:     "request.malformed_json",
+		pattern: regexp.MustCompile(`^Bad request, malformed JSON$`),
+		text: map[string]string{
+			"de": "Ungültige Anfrage: fehlerhaftes JSON",
+			"es": "Solicitud incorrecta: JSON mal formado",
+			"fr": "Requête invalide : JSON mal formé",
+		},
+	},
+	{
+		key:     "validation.missing_one_of",
+		pattern: regexp.MustCompile(`^Missing required field '(?P<field>[^']+)' or '(?P<other>[^']+)'$`),
+		text: map[string]string{
+			"de": "Pflichtfeld '{field}' oder '{other}' fehlt",
+			"es": "Falta el campo obligatorio '{field}' o '{other}'",
+			"fr": "Champ obligatoire '{field}' ou '{other}' manquant",
+		},
+	},
+	{
+		key:     "validation.missing_field",
+		pattern: regexp.MustCompile(`^Missing required field '(?P<field>[^']+)'$`),
+		text: map[string]string{
+			"de": "Pflichtfeld '{field}' fehlt",
+			"es": "Falta el campo obligatorio '{field}'",
+			"fr": "Champ obligatoire '{field}' manquant",
+		},
+	},
+	{
+		key:     "validation.field_required",
+		pattern: regexp.MustCompile(`^(?P<field>[A-Za-z.]+(?: id)?) is required$`),
+		text: map[string]string{
+			"de": "{field} ist erforderlich",
+			"es": "{field} es obligatorio",
+			"fr": "{field} est obligatoire",
+		},
+	},
+	{
+		key:     "validation.mutually_exclusive",
+		pattern: regexp.MustCompile(`^'(?P<field>[^']+)' and '(?P<other>[^']+)' cannot both be set$`),
+		text: map[string]string{
+			"de": "'{field}' und '{other}' können nicht beide gesetzt werden",
+			"es": "'{field}' y '{other}' no pueden indicarse a la vez",
+			"fr": "'{field}' et '{other}' ne peuvent pas être définis ensemble",
+		},
+	},
+	{
+		key:     "validation.invalid_id",
+		pattern: regexp.MustCompile(`^invalid (?P<field>[a-z]+(?: id|Id|ID))$`),
+		text: map[string]string{
+			"de": "Ungültige ID: {field}",
+			"es": "Identificador no válido: {field}",
+			"fr": "Identifiant invalide : {field}",
+		},
+	},
+	{
+		key:     "validation.invalid_uuid",
+		pattern: regexp.MustCompile(`^(?P<field>[A-Za-z.]+) must be a valid UUID$`),
+		text: map[string]string{
+			"de": "{field} muss eine gültige UUID sein",
+			"es": "{field} debe ser un UUID válido",
+			"fr": "{field} doit être un UUID valide",
+		},
+	},
+	{
+		key:     "validation.invalid_number",
+		pattern: regexp.MustCompile(`^invalid (?P<field>[A-Za-z]+) value$`),
+		text: map[string]string{
+			"de": "Ungültiger Wert für {field}",
+			"es": "Valor no válido para {field}",
+			"fr": "Valeur invalide pour {field}",
+		},
+	},
+	{
+		key:     "validation.invalid_boolean",
+		pattern: regexp.MustCompile(`^invalid (?P<field>[A-Za-z]+) value; expected true or false$`),
+		text: map[string]string{
+			"de": "Ungültiger Wert für {field}; erwartet wird true oder false",
+			"es": "Valor no válido para {field}; se esperaba true o false",
+			"fr": "Valeur invalide pour {field} ; true ou false attendu",
+		},
+	},
+	{
+		key:     "validation.positive_integer",
+		pattern: regexp.MustCompile(`^(?P<field>[A-Za-z.]+) must be a positive integer$`),
+		text: map[string]string{
+			"de": "{field} muss eine positive ganze Zahl sein",
+			"es": "{field} debe ser un número entero positivo",
+			"fr": "{field} doit être un entier positif",
+		},
+	},
+	{
+		key:     "validation.out_of_range",
+		pattern: regexp.MustCompile(`^(?P<field>[A-Za-z.]+) must be between (?P<min>\d+) and (?P<max>\d+)$`),
+		text: map[string]string{
+			"de": "{field} muss zwischen {min} und {max} liegen",
+			"es": "{field} debe estar entre {min} y {max}",
+			"fr": "{field} doit être compris entre {min} et {max}",
+		},
+	},
+	{
+		key:     "validation.invalid_tenant_role",
+		pattern: regexp.MustCompile(`^role must be 'tenant_admin', 'tenant_member', or 'tenant_viewer'$`),
+		text: map[string]string{
+			"de": "role muss 'tenant_admin', 'tenant_member' oder 'tenant_viewer' sein",
+			"es": "role debe ser 'tenant_admin', 'tenant_member' o 'tenant_viewer'",
+			"fr": "role doit être 'tenant_admin', 'tenant_member' ou 'tenant_viewer'",
+		},
+	},
+	{
+		key:     "tenant.context_required",
+		pattern: regexp.MustCompile(`^tenant context is required (?:to|for) `),
+		text: map[string]string{
+			"de": "Für diese Anfrage ist ein Mandantenkontext erforderlich",
+			"es": "Esta solicitud requiere un contexto de inquilino",
+			"fr": "Cette requête nécessite un contexte de locataire",
+		},
+	},
+	{
+		key:     "resource.not_found",
+		pattern: regexp.MustCompile(`^(?P<resource>[a-z ]+) not found$`),
+		text: map[string]string{
+			"de": "Nicht gefunden: {resource}",
+			"es": "No encontrado: {resource}",
+			"fr": "Introuvable : {resource}",
+		},
+	},
+	{
+		key:     "auth.unauthenticated",
+		pattern: regexp.MustCompile(`^(?:Missing or invalid authentication \(API key or session\)|authentication is required|User context is not available for this request|Principal (?:not found in context|is not available for this request))$`),
+		text: map[string]string{
+			"de": "Authentifizierung erforderlich",
+			"es": "Se requiere autenticación",
+			"fr": "Authentification requise",
+		},
+	},
+	{
+		key:     "auth.invalid_api_key",
+		pattern: regexp.MustCompile(`^(?:Invalid or revoked API key|Invalid API key format)$`),
+		text: map[string]string{
+			"de": "Ungültiger oder widerrufener API-Schlüssel",
+			"es": "Clave de API no válida o revocada",
+			"fr": "Clé API invalide ou révoquée",
+		},
+	},
+	{
+		key:     "auth.session_expired",
+		pattern: regexp.MustCompile(`^Session has expired or been revoked$`),
+		text: map[string]string{
+			"de": "Die Sitzung ist abgelaufen oder wurde widerrufen",
+			"es": "La sesión ha caducado o se ha revocado",
+			"fr": "La session a expiré ou a été révoquée",
+		},
+	},
+	{
+		key:     "auth.invalid_credentials",
+		pattern: regexp.MustCompile(`^invalid email or password$`),
+		text: map[string]string{
+			"de": "E-Mail-Adresse oder Passwort ist falsch",
+			"es": "Correo electrónico o contraseña incorrectos",
+			"fr": "Adresse e-mail ou mot de passe incorrect",
+		},
+	},
+	{
+		key:     "auth.user_disabled",
+		pattern: regexp.MustCompile(`^(?i:user account is disabled)$`),
+		text: map[string]string{
+			"de": "Das Benutzerkonto ist deaktiviert",
+			"es": "La cuenta de usuario está desactivada",
+			"fr": "Le compte utilisateur est désactivé",
+		},
+	},
+	{
+		key:     "auth.admin_required",
+		pattern: regexp.MustCompile(`^Admin privileges required$`),
+		text: map[string]string{
+			"de": "Administratorrechte erforderlich",
+			"es": "Se requieren privilegios de administrador",
+			"fr": "Privilèges d'administrateur requis",
+		},
+	},
+	{
+		key:     "auth.tenant_admin_required",
+		pattern: regexp.MustCompile(`^Tenant admin privileges required$`),
+		text: map[string]string{
+			"de": "Mandanten-Administratorrechte erforderlich",
+			"es": "Se requieren privilegios de administrador del inquilino",
+			"fr": "Privilèges d'administrateur du locataire requis",
+		},
+	},
+	{
+		key:     "auth.tenant_membership_required",
+		pattern: regexp.MustCompile(`^(?:Tenant membership required|You are not a member of the requested tenant)$`),
+		text: map[string]string{
+			"de": "Sie sind kein Mitglied dieses Mandanten",
+			"es": "No es miembro de este inquilino",
+			"fr": "Vous n'êtes pas membre de ce locataire",
+		},
+	},
+	{
+		key:     "auth.tenant_read_only",
+		pattern: regexp.MustCompile(`^Your role in this tenant is read-only$`),
+		text: map[string]string{
+			"de": "Ihre Rolle in diesem Mandanten erlaubt nur Lesezugriff",
+			"es": "Su rol en este inquilino es de solo lectura",
+			"fr": "Votre rôle dans ce locataire est en lecture seule",
+		},
+	},
+}
+
+// errorCodeKey is the message key of an error text missing from the
+// catalog, derived from its code: BAD_REQUEST becomes errors.bad_request.
+func errorCodeKey(code string) string {
+	if code == "" {
+		return "errors.unknown"
+	}
+	return "errors." + strings.ToLower(code)
+}
+
+// lookupErrorMessage returns the message key and parameters of an error
+// text and its translation to lang. text is the original when the error
+// is not in the catalog or has no translation.
+func lookupErrorMessage(code, text, lang string) (string, map[string]string, string) {
+	for _, m := range errorCatalog {
+		match := m.pattern.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		var params map[string]string
+		for i, name := range m.pattern.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = match[i]
+		}
+		tmpl, ok := m.text[lang]
+		if !ok {
+			return m.key, params, text
+		}
+		for name, value := range params {
+			tmpl = strings.ReplaceAll(tmpl, "{"+name+"}", value)
+		}
+		return m.key, params, tmpl
+	}
+	return errorCodeKey(code), nil, text
+}
+
+// negotiateErrorLanguage picks the supported language the client prefers
+// most from an Accept-Language header, or English.
+func negotiateErrorLanguage(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if q <= 0 || primary == "" {
+			continue
+		}
+		for _, lang := range errorLanguages {
+			if primary == lang {
+				candidates = append(candidates, candidate{lang: lang, q: q})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return defaultErrorLanguage
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// localizeErrorsMiddleware adds messageKey and messageParams to
+// ErrorResponse bodies and translates their error text to the language
+// of the Accept-Language header, so clients can localize errors without
+// matching English text.
+func localizeErrorsMiddleware(c *fiber.Ctx) error {
+	err := c.Next()
+	localizeErrorResponse(c)
+	return err
+}
+
+func localizeErrorResponse(c *fiber.Ctx) {
+	resp := c.Response()
+	if resp.StatusCode() < fiber.StatusBadRequest || len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 {
+		return
+	}
+	if !strings.HasPrefix(string(resp.Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return
+	}
+
+	// Only bodies that are exactly an ErrorResponse are rewritten; other
+	// failure shapes keep their fields.
+	var body ErrorResponse
+	dec := json.NewDecoder(bytes.NewReader(resp.Body()))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil || body.Success || body.Error == "" || body.MessageKey != "" {
+		return
+	}
+
+	lang := negotiateErrorLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	key, params, text := lookupErrorMessage(body.Code, body.Error, lang)
+	body.MessageKey = key
+	body.MessageParams = params
+	translated := text != body.Error
+	body.Error = text
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	resp.SetBody(data)
+	c.Vary(fiber.HeaderAcceptLanguage)
+	if translated {
+		c.Set(fiber.HeaderContentLanguage, lang)
+	}
+}
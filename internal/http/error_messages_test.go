@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestNegotiateErrorLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                          "en",
+		"fr-CH, fr;q=0.9, en;q=0.8": "fr",
+		"ja, de;q=0.5":              "de",
+		"en-US;q=0.4, es-MX;q=0.7":  "es",
+		"pt-BR, ja":                 "en",
+		"de;q=0, fr;q=0.1":          "fr",
+		"es;q=oops, de;q=0.2":       "de",
+	}
+	for header, want := range cases {
+		if got := negotiateErrorLanguage(header); got != want {
+			t.Errorf("negotiateErrorLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestLookupErrorMessage(t *testing.T) {
+	key, params, text := lookupErrorMessage("BAD_REQUEST", "Missing required field 'url'", "es")
+	if key != "validation.missing_field" || params["field"] != "url" || text != "Falta el campo obligatorio 'url'" {
+		t.Fatalf("got %q %v %q", key, params, text)
+	}
+
+	key, params, text = lookupErrorMessage("BAD_REQUEST", "maxSources must be between 1 and 20", "de")
+	if key != "validation.out_of_range" || params["min"] != "1" || params["max"] != "20" || text != "maxSources muss zwischen 1 und 20 liegen" {
+		t.Fatalf("got %q %v %q", key, params, text)
+	}
+
+	key, params, text = lookupErrorMessage("BAD_REQUEST", "Missing required field 'schema' or 'schemaPreset'", "en")
+	if key != "validation.missing_one_of" || params["other"] != "schemaPreset" || text != "Missing required field 'schema' or 'schemaPreset'" {
+		t.Fatalf("got %q %v %q", key, params, text)
+	}
+
+	// Texts missing from the catalog keep their wording and get a key
+	// derived from the code.
+	key, params, text = lookupErrorMessage("SCRAPE_FAILED", "upstream returned 502", "fr")
+	if key != "errors.scrape_failed" || params != nil || text != "upstream returned 502" {
+		t.Fatalf("got %q %v %q", key, params, text)
+	}
+}
+
+func TestLocalizeErrorsMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(localizeErrorsMiddleware)
+	app.Get("/bad", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid tenant id",
+		})
+	})
+	app.Get("/other", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"success": false, "error": "busy", "retryAfter": 5})
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/bad", nil)
+	req.Header.Set(fiber.HeaderAcceptLanguage, "fr-FR,fr;q=0.9")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	var body ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.MessageKey != "validation.invalid_id" || body.MessageParams["field"] != "tenant id" {
+		t.Fatalf("unexpected key or params: %+v", body)
+	}
+	if body.Error != "Identifiant invalide : tenant id" || body.Code != "BAD_REQUEST" {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	if got := resp.Header.Get(fiber.HeaderContentLanguage); got != "fr" {
+		t.Fatalf("expected Content-Language fr, got %q", got)
+	}
+
+	// Other failure shapes are left alone.
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/other", nil), -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	if string(raw) != `{"error":"busy","retryAfter":5,"success":false}` {
+		t.Fatalf("unexpected body: %s", raw)
+	}
+}
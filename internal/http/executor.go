@@ -2,6 +2,8 @@ package http
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +13,9 @@ import (
 
 	"github.com/google/uuid"
 	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/events"
+	"raito/internal/jobs"
 	"raito/internal/store"
 )
 
@@ -18,6 +23,7 @@ import (
 // like scrape/map/extract/crawl, backed by the jobs table.
 type WorkExecutor interface {
 	Scrape(ctx context.Context, req *ScrapeRequest) (*ScrapeResponse, error)
+	ScrapeAsync(ctx context.Context, req *ScrapeRequest) (uuid.UUID, error)
 	Map(ctx context.Context, req *MapRequest) (*MapResponse, error)
 	Extract(ctx context.Context, req *ExtractRequest) (*ExtractResponse, error)
 }
@@ -72,40 +78,15 @@ func (e *JobQueueExecutor) Scrape(ctx context.Context, req *ScrapeRequest) (*Scr
 		defer cancel()
 	}
 
-	// Generate a job ID (prefer uuidv7 when available).
-	jobID := func() uuid.UUID {
-		if id, err := uuid.NewV7(); err == nil {
-			return id
-		}
-		return uuid.New()
-	}()
-
-	// Enqueue the scrape job as a high-priority, synchronous job.
-	var tenantID *uuid.UUID
-	if val := ctx.Value("tenant_id"); val != nil {
-		if tid, ok := val.(uuid.UUID); ok {
-			tenantID = &tid
-		}
-	}
-	var apiKeyID *uuid.UUID
-	if val := ctx.Value("api_key_id"); val != nil {
-		if kid, ok := val.(uuid.UUID); ok {
-			apiKeyID = &kid
-		}
-	}
-	if _, err := e.st.CreateJob(waitCtx, jobID, "scrape", req.URL, req, true, 100, tenantID, apiKeyID); err != nil {
+	jobID, err := e.enqueueScrape(waitCtx, req, true)
+	if err != nil {
 		return nil, err
 	}
 
-	e.logInfo("scrape_enqueued",
-		"scrape_id", jobID.String(),
-		"url", req.URL,
-		"has_formats", len(req.Formats) > 0,
-	)
-
-	// Poll for job completion until it is completed/failed or the
-	// context times out.
-	pollInterval := 100 * time.Millisecond
+	// Wait for the job to complete or fail, or for the context to time
+	// out.
+	wake, stopWaking := jobWakeups(waitCtx, jobID)
+	defer stopWaking()
 	lastStatus := ""
 
 	for {
@@ -139,7 +120,7 @@ func (e *JobQueueExecutor) Scrape(ctx context.Context, req *ScrapeRequest) (*Scr
 				}, nil
 			}
 			return nil, waitCtx.Err()
-		case <-time.After(pollInterval):
+		case <-wake:
 		}
 
 		job, err := e.st.GetJobByID(waitCtx, jobID)
@@ -149,7 +130,7 @@ func (e *JobQueueExecutor) Scrape(ctx context.Context, req *ScrapeRequest) (*Scr
 
 		lastStatus = job.Status
 
-		switch job.Status {
+		switch jobs.PublicStatus(job.Status) {
 		case "pending", "running":
 			// Still in progress; continue polling.
 			continue
@@ -159,7 +140,9 @@ func (e *JobQueueExecutor) Scrape(ctx context.Context, req *ScrapeRequest) (*Scr
 			}
 
 			var doc Document
-			if err := json.Unmarshal(job.Output.RawMessage, &doc); err != nil {
+			err := json.Unmarshal(job.Output.RawMessage, &doc)
+			e.purgeZeroRetention(ctx, job)
+			if err != nil {
 				return nil, err
 			}
 
@@ -169,8 +152,9 @@ func (e *JobQueueExecutor) Scrape(ctx context.Context, req *ScrapeRequest) (*Scr
 			)
 
 			return &ScrapeResponse{
-				Success: true,
-				Data:    &doc,
+				Success:  true,
+				Data:     &doc,
+				ScrapeID: jobID.String(),
 			}, nil
 		case "failed":
 			code := "SCRAPE_FAILED"
@@ -192,6 +176,7 @@ func (e *JobQueueExecutor) Scrape(ctx context.Context, req *ScrapeRequest) (*Scr
 				"code", code,
 				"error", msg,
 			)
+			e.purgeZeroRetention(ctx, job)
 
 			return &ScrapeResponse{
 				Success: false,
@@ -204,6 +189,212 @@ func (e *JobQueueExecutor) Scrape(ctx context.Context, req *ScrapeRequest) (*Scr
 	}
 }
 
+// ScrapeAsync enqueues a scrape job without waiting for it, returning
+// the job ID. The worker stores the result like any other scrape job, so
+// callers read it through the jobs API.
+func (e *JobQueueExecutor) ScrapeAsync(ctx context.Context, req *ScrapeRequest) (uuid.UUID, error) {
+	if req == nil {
+		return uuid.Nil, errors.New("nil scrape request")
+	}
+	return e.enqueueScrape(ctx, req, false)
+}
+
+// enqueueScrape creates the job for a scrape request, or joins an
+// identical in-flight job when the request asks for dedupe. Synchronous
+// jobs run ahead of asynchronous ones.
+func (e *JobQueueExecutor) enqueueScrape(ctx context.Context, req *ScrapeRequest, sync bool) (uuid.UUID, error) {
+	// Generate a job ID (prefer uuidv7 when available).
+	jobID := func() uuid.UUID {
+		if id, err := uuid.NewV7(); err == nil {
+			return id
+		}
+		return uuid.New()
+	}()
+
+	var tenantID *uuid.UUID
+	if val := ctx.Value("tenant_id"); val != nil {
+		if tid, ok := val.(uuid.UUID); ok {
+			tenantID = &tid
+		}
+	}
+	var apiKeyID *uuid.UUID
+	if val := ctx.Value("api_key_id"); val != nil {
+		if kid, ok := val.(uuid.UUID); ok {
+			apiKeyID = &kid
+		}
+	}
+	var userID *uuid.UUID
+	if val := ctx.Value("user_id"); val != nil {
+		if uid, ok := val.(uuid.UUID); ok {
+			userID = &uid
+		}
+	}
+	pool, err := jobs.RoutePool(e.cfg, jobs.RouteRequest{
+		TenantID:     tenantID,
+		Requested:    req.Pool,
+		NeedsBrowser: req.UseBrowser != nil && *req.UseBrowser,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	priority := int32(10)
+	if sync {
+		priority = 100
+	}
+	jobParams := store.CreateJobParams{
+		ID:            jobID,
+		Type:          "scrape",
+		URL:           req.URL,
+		Input:         req,
+		Sync:          sync,
+		Priority:      priority,
+		TenantID:      tenantID,
+		APIKeyID:      apiKeyID,
+		UserID:        userID,
+		Visibility:    req.Visibility,
+		CollectionID:  parseCollectionID(req.CollectionID),
+		Pool:          pool,
+		ZeroRetention: zeroRetentionRequested(req.ZeroDataRetention, req.StoreInCache),
+	}
+	if applied, ok := ctx.Value("applied_options").(AppliedOptions); ok {
+		jobParams.AppliedOptions = applied
+	}
+	// Private and zero-retention scrapes are never coalesced so their
+	// results are not shared with other members of the tenant.
+	if req.Dedupe != nil && *req.Dedupe && req.Visibility != "private" && !jobParams.ZeroRetention {
+		fingerprint, err := scrapeFingerprint(req, tenantID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		job, created, err := e.st.CreateOrJoinJob(ctx, jobParams, fingerprint)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		jobID = job.ID
+		if created {
+			e.logInfo("scrape_enqueued",
+				"scrape_id", jobID.String(),
+				"url", req.URL,
+				"has_formats", len(req.Formats) > 0,
+				"async", !sync,
+				"dedupe", true,
+			)
+		} else {
+			e.logInfo("scrape_deduped",
+				"scrape_id", jobID.String(),
+				"url", req.URL,
+			)
+		}
+	} else {
+		if _, err := e.st.CreateJob(ctx, jobParams); err != nil {
+			return uuid.Nil, err
+		}
+
+		e.logInfo("scrape_enqueued",
+			"scrape_id", jobID.String(),
+			"url", req.URL,
+			"has_formats", len(req.Formats) > 0,
+			"async", !sync,
+		)
+	}
+
+	return jobID, nil
+}
+
+// Jobs are checked every jobWaitPollInterval while waiting for them,
+// unless shared events report their changes, when jobWaitFallbackInterval
+// only catches missed events.
+const (
+	jobWaitPollInterval     = 100 * time.Millisecond
+	jobWaitFallbackInterval = time.Second
+)
+
+// jobWakeups returns a channel that receives whenever the job may have
+// changed: right away, on every event on its timeline, and on a poll
+// interval as a fallback. stop releases the subscription.
+func jobWakeups(ctx context.Context, jobID uuid.UUID) (wake <-chan struct{}, stop func()) {
+	ctx, stop = context.WithCancel(ctx)
+	ch := make(chan struct{}, 1)
+	ch <- struct{}{}
+
+	interval := jobWaitPollInterval
+	if events.Shared() {
+		interval = jobWaitFallbackInterval
+	}
+	msgs := events.Subscribe(ctx, events.JobTopic(jobID))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-msgs:
+				if !ok {
+					// The bus was closed; keep polling.
+					msgs = nil
+				}
+			case <-ticker.C:
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return ch, stop
+}
+
+// purgeZeroRetention deletes a finished zero-retention job's data once its
+// result has been read for the waiting caller.
+func (e *JobQueueExecutor) purgeZeroRetention(ctx context.Context, job db.Job) {
+	if !job.ZeroRetention {
+		return
+	}
+	if err := e.st.PurgeJobData(ctx, job.ID); err != nil {
+		e.logInfo("zero_retention_purge_failed",
+			"job_id", job.ID.String(),
+			"error", err.Error(),
+		)
+	}
+}
+
+// parseCollectionID returns the collection referenced by a request, which
+// handlers have already validated against the caller's tenant.
+func parseCollectionID(raw string) *uuid.UUID {
+	if raw == "" {
+		return nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// scrapeFingerprint derives a stable identifier for a scrape request so
+// that identical concurrent requests from the same tenant can share one job.
+// The dedupe and async flags are excluded from the hash, so waiting and
+// fire-and-forget callers share a job too.
+func scrapeFingerprint(req *ScrapeRequest, tenantID *uuid.UUID) (string, error) {
+	clone := *req
+	clone.Dedupe = nil
+	clone.Async = nil
+	payload, err := json.Marshal(clone)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte("scrape\n"))
+	if tenantID != nil {
+		h.Write([]byte(tenantID.String()))
+	}
+	h.Write([]byte("\n"))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // Map enqueues a map job and waits for completion, returning a
 // MapResponse that mirrors the direct HTTP implementation.
 func (e *JobQueueExecutor) Map(ctx context.Context, req *MapRequest) (*MapResponse, error) {
@@ -253,8 +444,32 @@ func (e *JobQueueExecutor) Map(ctx context.Context, req *MapRequest) (*MapRespon
 			apiKeyID = &kid
 		}
 	}
+	var userID *uuid.UUID
+	if val := ctx.Value("user_id"); val != nil {
+		if uid, ok := val.(uuid.UUID); ok {
+			userID = &uid
+		}
+	}
+
+	pool, err := jobs.RoutePool(e.cfg, jobs.RouteRequest{TenantID: tenantID, Requested: req.Pool})
+	if err != nil {
+		return nil, err
+	}
 
-	if _, err := e.st.CreateJob(waitCtx, jobID, "map", req.URL, req, true, 100, tenantID, apiKeyID); err != nil {
+	if _, err := e.st.CreateJob(waitCtx, store.CreateJobParams{
+		ID:           jobID,
+		Type:         "map",
+		URL:          req.URL,
+		Input:        req,
+		Sync:         true,
+		Priority:     100,
+		TenantID:     tenantID,
+		APIKeyID:     apiKeyID,
+		UserID:       userID,
+		Visibility:   req.Visibility,
+		CollectionID: parseCollectionID(req.CollectionID),
+		Pool:         pool,
+	}); err != nil {
 		return nil, err
 	}
 
@@ -264,7 +479,8 @@ func (e *JobQueueExecutor) Map(ctx context.Context, req *MapRequest) (*MapRespon
 		"limit", req.Limit,
 	)
 
-	pollInterval := 100 * time.Millisecond
+	wake, stopWaking := jobWakeups(waitCtx, jobID)
+	defer stopWaking()
 	lastStatus := ""
 
 	for {
@@ -299,7 +515,7 @@ func (e *JobQueueExecutor) Map(ctx context.Context, req *MapRequest) (*MapRespon
 				}, nil
 			}
 			return nil, waitCtx.Err()
-		case <-time.After(pollInterval):
+		case <-wake:
 		}
 
 		job, err := e.st.GetJobByID(waitCtx, jobID)
@@ -309,7 +525,7 @@ func (e *JobQueueExecutor) Map(ctx context.Context, req *MapRequest) (*MapRespon
 
 		lastStatus = job.Status
 
-		switch job.Status {
+		switch jobs.PublicStatus(job.Status) {
 		case "pending", "running":
 			continue
 		case "completed":
@@ -411,12 +627,41 @@ func (e *JobQueueExecutor) Extract(ctx context.Context, req *ExtractRequest) (*E
 			apiKeyID = &kid
 		}
 	}
+	var userID *uuid.UUID
+	if val := ctx.Value("user_id"); val != nil {
+		if uid, ok := val.(uuid.UUID); ok {
+			userID = &uid
+		}
+	}
+
+	pool, err := jobs.RoutePool(e.cfg, jobs.RouteRequest{
+		TenantID:     tenantID,
+		Requested:    req.Pool,
+		NeedsBrowser: scrapeOptionsUseBrowser(req.ScrapeOptions),
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	if _, err := e.st.CreateJob(waitCtx, jobID, "extract", primaryURL, req, true, 100, tenantID, apiKeyID); err != nil {
+	if _, err := e.st.CreateJob(waitCtx, store.CreateJobParams{
+		ID:           jobID,
+		Type:         "extract",
+		URL:          primaryURL,
+		Input:        req,
+		Sync:         true,
+		Priority:     100,
+		TenantID:     tenantID,
+		APIKeyID:     apiKeyID,
+		UserID:       userID,
+		Visibility:   req.Visibility,
+		CollectionID: parseCollectionID(req.CollectionID),
+		Pool:         pool,
+	}); err != nil {
 		return nil, err
 	}
 
-	pollInterval := 100 * time.Millisecond
+	wake, stopWaking := jobWakeups(waitCtx, jobID)
+	defer stopWaking()
 	lastStatus := ""
 
 	for {
@@ -437,7 +682,7 @@ func (e *JobQueueExecutor) Extract(ctx context.Context, req *ExtractRequest) (*E
 				}, nil
 			}
 			return nil, waitCtx.Err()
-		case <-time.After(pollInterval):
+		case <-wake:
 		}
 
 		job, err := e.st.GetJobByID(waitCtx, jobID)
@@ -447,7 +692,7 @@ func (e *JobQueueExecutor) Extract(ctx context.Context, req *ExtractRequest) (*E
 
 		lastStatus = job.Status
 
-		switch job.Status {
+		switch jobs.PublicStatus(job.Status) {
 		case "pending", "running":
 			continue
 		case "completed":
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/events"
+)
+
+func TestScrapeFingerprint_StableAndScoped(t *testing.T) {
+	yes := true
+	tenantA := uuid.New()
+	tenantB := uuid.New()
+
+	a := &ScrapeRequest{URL: "https://example.com", Formats: []any{"markdown"}, Headers: map[string]string{"X-A": "1", "X-B": "2"}, Dedupe: &yes}
+	b := &ScrapeRequest{URL: "https://example.com", Formats: []any{"markdown"}, Headers: map[string]string{"X-B": "2", "X-A": "1"}}
+
+	fa, err := scrapeFingerprint(a, &tenantA)
+	if err != nil {
+		t.Fatalf("fingerprint error: %v", err)
+	}
+	fb, err := scrapeFingerprint(b, &tenantA)
+	if err != nil {
+		t.Fatalf("fingerprint error: %v", err)
+	}
+	if fa != fb {
+		t.Fatalf("expected identical fingerprints, got %q and %q", fa, fb)
+	}
+
+	// Async callers join the same job as waiting ones.
+	async := *a
+	async.Async = &yes
+	if fasync, _ := scrapeFingerprint(&async, &tenantA); fasync != fa {
+		t.Fatalf("expected async to leave the fingerprint unchanged")
+	}
+
+	other, _ := scrapeFingerprint(b, &tenantB)
+	if other == fa {
+		t.Fatalf("expected different fingerprint for a different tenant")
+	}
+
+	c := &ScrapeRequest{URL: "https://example.com", Formats: []any{"html"}}
+	fc, _ := scrapeFingerprint(c, &tenantA)
+	if fc == fa {
+		t.Fatalf("expected different fingerprint for different formats")
+	}
+}
+
+func TestJobWakeups(t *testing.T) {
+	jobID := uuid.New()
+	wake, stop := jobWakeups(context.Background(), jobID)
+	defer stop()
+
+	// The first check is immediate.
+	select {
+	case <-wake:
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("expected an immediate wakeup")
+	}
+
+	// An event on the job's timeline wakes the waiter before the poll.
+	// In-process events poll every jobWaitPollInterval, so time the
+	// event-driven wakeup against that.
+	start := time.Now()
+	_ = events.Publish(context.Background(), events.JobTopic(jobID), []byte(`{"type":"completed"}`))
+	select {
+	case <-wake:
+	case <-time.After(time.Second):
+		t.Fatal("expected a wakeup after the job event")
+	}
+	if elapsed := time.Since(start); elapsed >= jobWaitPollInterval {
+		t.Fatalf("expected the event to wake the waiter before the next poll, took %v", elapsed)
+	}
+}
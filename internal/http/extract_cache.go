@@ -0,0 +1,140 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+)
+
+// defaultExtractCacheTTL is used when extract.cache.ttlHours is unset.
+const defaultExtractCacheTTL = 24 * time.Hour
+
+// extractCacheStore is the optional part of jobStore used for caching
+// per-URL extract results. *store.Store implements it; stores that do not
+// simply run without a cache.
+type extractCacheStore interface {
+	GetExtractCacheEntry(ctx context.Context, key string, notBefore time.Time) (db.ExtractCache, error)
+	PutExtractCacheEntry(ctx context.Context, params db.UpsertExtractCacheEntryParams) error
+}
+
+// extractCache looks up and stores per-URL extract results for one job.
+// Entries are keyed by tenant, URL, schema hash, and prompt hash so that
+// changing the schema or prompt always produces a fresh extraction.
+type extractCache struct {
+	store      extractCacheStore
+	tenantID   uuid.NullUUID
+	schemaHash string
+	promptHash string
+	notBefore  time.Time
+	ttl        time.Duration
+}
+
+// newExtractCache returns the cache for an extract job, or nil when caching
+// is disabled, the store does not support it, the request set maxAge to 0,
+// or the request asked for zero data retention.
+func newExtractCache(ctx context.Context, cfg *config.Config, st jobStore, req ExtractRequest, prompt string) *extractCache {
+	if cfg == nil || !cfg.Extract.Cache.Enabled {
+		return nil
+	}
+	if zeroRetentionRequested(req.ZeroDataRetention, req.StoreInCache) {
+		return nil
+	}
+	cs, ok := st.(extractCacheStore)
+	if !ok {
+		return nil
+	}
+
+	maxAgeMs := int64(cfg.Extract.Cache.DefaultMaxAgeMs)
+	if req.MaxAge != nil {
+		if *req.MaxAge <= 0 {
+			return nil
+		}
+		maxAgeMs = *req.MaxAge
+	}
+
+	ttl := defaultExtractCacheTTL
+	if cfg.Extract.Cache.TTLHours > 0 {
+		ttl = time.Duration(cfg.Extract.Cache.TTLHours) * time.Hour
+	}
+
+	// A zero notBefore accepts any unexpired entry.
+	var notBefore time.Time
+	if maxAgeMs > 0 {
+		notBefore = time.Now().UTC().Add(-time.Duration(maxAgeMs) * time.Millisecond)
+	}
+
+	var tenantID uuid.NullUUID
+	if val := ctx.Value("tenant_id"); val != nil {
+		if tid, ok := val.(uuid.UUID); ok {
+			tenantID = uuid.NullUUID{UUID: tid, Valid: true}
+		}
+	}
+
+	schemaBytes, _ := json.Marshal(req.Schema)
+	return &extractCache{
+		store:      cs,
+		tenantID:   tenantID,
+		schemaHash: hashString(string(schemaBytes)),
+		promptHash: hashString(prompt),
+		notBefore:  notBefore,
+		ttl:        ttl,
+	}
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *extractCache) key(url string) string {
+	tenant := ""
+	if c.tenantID.Valid {
+		tenant = c.tenantID.UUID.String()
+	}
+	return hashString(tenant + "\n" + url + "\n" + c.schemaHash + "\n" + c.promptHash)
+}
+
+// get returns the cached extraction and scrape status code for url.
+func (c *extractCache) get(ctx context.Context, url string) (map[string]any, int, bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+	entry, err := c.store.GetExtractCacheEntry(ctx, c.key(url), c.notBefore)
+	if err != nil {
+		return nil, 0, false
+	}
+	var value map[string]any
+	if err := json.Unmarshal(entry.Result, &value); err != nil || len(value) == 0 {
+		return nil, 0, false
+	}
+	return value, int(entry.StatusCode), true
+}
+
+// put stores a successful extraction for url. Failures are ignored since
+// the cache is only an optimisation.
+func (c *extractCache) put(ctx context.Context, url string, value map[string]any, statusCode int) {
+	if c == nil {
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	_ = c.store.PutExtractCacheEntry(ctx, db.UpsertExtractCacheEntryParams{
+		CacheKey:   c.key(url),
+		TenantID:   c.tenantID,
+		Url:        url,
+		SchemaHash: c.schemaHash,
+		PromptHash: c.promptHash,
+		Result:     raw,
+		StatusCode: int32(statusCode),
+		ExpiresAt:  time.Now().UTC().Add(c.ttl),
+	})
+}
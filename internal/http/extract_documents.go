@@ -0,0 +1,37 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"raito/internal/model"
+)
+
+// extractDocumentStore is the optional part of jobStore that persists
+// extract results as documents. *store.Store implements it.
+type extractDocumentStore interface {
+	AddExtractDocument(ctx context.Context, jobID uuid.UUID, url string, markdown *string, metadata json.RawMessage, statusCode *int32) error
+}
+
+// storeExtractDocument saves one extract result as an extract-type
+// document, with the extracted JSON in its metadata, so downloads,
+// retention and search see it. jobs.output stays the primary result, so
+// failures are ignored.
+func storeExtractDocument(ctx context.Context, st jobStore, jobID uuid.UUID, url string, markdown *string, value map[string]any, statusCode int) {
+	ds, ok := st.(extractDocumentStore)
+	if !ok {
+		return
+	}
+	meta, err := json.Marshal(model.Metadata{
+		SourceURL:  url,
+		StatusCode: statusCode,
+		JSON:       value,
+	})
+	if err != nil {
+		return
+	}
+	sc := int32(statusCode)
+	_ = ds.AddExtractDocument(ctx, jobID, url, markdown, meta, &sc)
+}
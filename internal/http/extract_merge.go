@@ -0,0 +1,270 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/jobs"
+	"raito/internal/llm"
+	"raito/internal/metrics"
+	"raito/internal/scraper"
+)
+
+// Extract modes accepted in ExtractRequest.Mode.
+const (
+	extractModePerURL = "perUrl"
+	extractModeMerge  = "merge"
+)
+
+// Budget for the combined markdown sent to the LLM in merge mode. Each page
+// gets an equal share so one long page cannot crowd out the others.
+const mergeExtractMaxChars = 120000
+
+// validateExtractMode checks the optional extract mode.
+func validateExtractMode(mode string) error {
+	switch mode {
+	case "", extractModePerURL, extractModeMerge:
+		return nil
+	default:
+		return fmt.Errorf("invalid mode %q; expected %s or %s", mode, extractModePerURL, extractModeMerge)
+	}
+}
+
+// mergeExtractOptions carries the request-derived settings runExtractJob
+// has already resolved.
+type mergeExtractOptions struct {
+	prompt        string
+	headers       map[string]string
+	location      *scraper.LocationOptions
+	ignoreInvalid bool
+	showSources   bool
+}
+
+// mergedPage is a successfully scraped page used as merge-mode input.
+type mergedPage struct {
+	url        string
+	markdown   string
+	statusCode int
+}
+
+// runMergedExtract scrapes every URL and asks the LLM for one object that
+// consolidates information across all pages, optionally with per-field
+// source attribution.
+func runMergedExtract(ctx context.Context, cfg *config.Config, st jobStore, jobID uuid.UUID, req ExtractRequest, deps *extractDeps, urls []string, opts mergeExtractOptions) {
+	provider := deps.provider
+	modelName := deps.modelName
+
+	results := make([]map[string]any, 0, len(urls))
+	sources := make([]map[string]any, 0, len(urls))
+	failedByCode := make(map[string]int)
+	pages := make([]mergedPage, 0, len(urls))
+
+	for _, u := range urls {
+		sReq := scraper.BuildRequestFromOptions(scraper.RequestOptions{
+			URL:       u,
+			Headers:   opts.headers,
+			TimeoutMs: int(deps.timeout.Milliseconds()),
+			UserAgent: cfg.RequestUserAgent(),
+			Location:  opts.location,
+		})
+
+		res, err := deps.scraper.Scrape(ctx, sReq)
+		if err != nil || res == nil {
+			errMsg := "SCRAPE_FAILED: empty response"
+			if err != nil {
+				errMsg = "SCRAPE_FAILED: " + err.Error()
+			}
+			if !opts.ignoreInvalid {
+				_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &errMsg)
+				return
+			}
+			results = append(results, map[string]any{"url": u, "success": false, "error": errMsg})
+			failedByCode["SCRAPE_FAILED"]++
+			if opts.showSources {
+				sources = append(sources, map[string]any{"url": u, "statusCode": 0, "error": errMsg})
+			}
+			continue
+		}
+
+		pages = append(pages, mergedPage{url: u, markdown: res.Markdown, statusCode: res.Status})
+		results = append(results, map[string]any{"url": u, "success": true})
+		if opts.showSources {
+			sources = append(sources, map[string]any{"url": u, "statusCode": res.Status, "error": ""})
+		}
+	}
+
+	if len(pages) == 0 {
+		metrics.RecordExtractJob(string(provider), modelName, "failed")
+		msg := "EXTRACT_EMPTY_RESULT: no URLs could be scraped"
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	desc := "Single JSON object consolidating information from all of the pages below."
+	if schemaBytes, err := json.Marshal(req.Schema); err == nil {
+		desc = desc + " Schema: " + string(schemaBytes)
+	}
+	fieldSpecs := []llm.FieldSpec{{
+		Name:        "json",
+		Description: desc,
+		Type:        "object",
+	}}
+	if opts.showSources {
+		fieldSpecs = append(fieldSpecs, llm.FieldSpec{
+			Name:        "fieldSources",
+			Description: "Object mapping each top-level key of json to the array of page URLs (from the 'Source:' headings) the value was taken from.",
+			Type:        "object",
+		})
+	}
+
+	prompt := "The markdown contains several pages, each introduced by a 'Source: <url>' heading. Combine them into one answer; prefer the most complete and specific values and do not repeat list items."
+	if opts.prompt != "" {
+		prompt = opts.prompt + "\n\n" + prompt
+	}
+
+	llmCtx, llmCancel := context.WithTimeout(ctx, deps.timeout)
+	llmRes, err := deps.client.ExtractFields(llmCtx, llm.ExtractRequest{
+		URL:      pages[0].url,
+		Markdown: combineMergedPages(pages, mergeExtractMaxChars),
+		Fields:   fieldSpecs,
+		Prompt:   prompt,
+		Timeout:  deps.timeout,
+		Strict:   false,
+	})
+	llmCancel()
+	if err != nil {
+		metrics.RecordLLMExtract(string(provider), modelName, false)
+		metrics.RecordExtractJob(string(provider), modelName, "failed")
+		code := "EXTRACT_FAILED"
+		if errors.Is(err, errLLMBudgetExceeded) {
+			code = "LLM_BUDGET_EXCEEDED"
+		}
+		msg := code + ": " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	metrics.RecordLLMExtract(string(provider), modelName, true)
+
+	var data map[string]any
+	if v, ok := llmRes.Fields["json"]; ok {
+		if m, ok := v.(map[string]any); ok {
+			data = m
+		} else {
+			data = map[string]any{"_value": v}
+		}
+	}
+	if len(data) == 0 {
+		metrics.RecordExtractJob(string(provider), modelName, "failed")
+		msg := "EXTRACT_EMPTY_RESULT: LLM did not return any fields"
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	payload := map[string]any{
+		"mode":    extractModeMerge,
+		"data":    data,
+		"results": results,
+	}
+	if opts.showSources {
+		if len(sources) > 0 {
+			payload["sources"] = sources
+		}
+		payload["fieldSources"] = normalizeFieldSources(llmRes.Fields["fieldSources"], data, pages)
+	}
+
+	summary := map[string]any{
+		"total":   len(results),
+		"success": len(pages),
+		"failed":  len(results) - len(pages),
+	}
+	if len(failedByCode) > 0 {
+		summary["failedByCode"] = failedByCode
+	}
+	payload["summary"] = summary
+
+	output, err := json.Marshal(payload)
+	if err != nil {
+		metrics.RecordExtractJob(string(provider), modelName, "failed")
+		msg := "EXTRACT_FAILED: failed to marshal extract result: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+	if err := st.SetJobOutput(context.Background(), jobID, output); err != nil {
+		metrics.RecordExtractJob(string(provider), modelName, "failed")
+		msg := "EXTRACT_FAILED: failed to persist extract result: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	// The merged object is stored as one document under the first page.
+	storeExtractDocument(ctx, st, jobID, pages[0].url, nil, data, pages[0].statusCode)
+
+	metrics.RecordExtractJob(string(provider), modelName, "completed")
+	metrics.RecordExtractResults(string(provider), len(pages), len(results)-len(pages))
+	_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusCompleted), nil)
+}
+
+// combineMergedPages joins page markdown under "Source:" headings, giving
+// each page an equal share of maxChars.
+func combineMergedPages(pages []mergedPage, maxChars int) string {
+	perPage := maxChars
+	if len(pages) > 0 {
+		perPage = maxChars / len(pages)
+	}
+
+	var b strings.Builder
+	for i, p := range pages {
+		if i > 0 {
+			b.WriteString("\n\n---\n\n")
+		}
+		b.WriteString("## Source: ")
+		b.WriteString(p.url)
+		b.WriteString("\n\n")
+		md := p.markdown
+		if len(md) > perPage {
+			md = md[:perPage]
+		}
+		b.WriteString(md)
+	}
+	return b.String()
+}
+
+// normalizeFieldSources keeps only attribution entries that refer to keys
+// present in data and to pages that were actually extracted.
+func normalizeFieldSources(raw any, data map[string]any, pages []mergedPage) map[string][]string {
+	known := make(map[string]struct{}, len(pages))
+	for _, p := range pages {
+		known[p.url] = struct{}{}
+	}
+
+	out := make(map[string][]string)
+	m, _ := raw.(map[string]any)
+	for field, v := range m {
+		if _, ok := data[field]; !ok {
+			continue
+		}
+		var urls []string
+		switch t := v.(type) {
+		case string:
+			urls = []string{t}
+		case []any:
+			for _, item := range t {
+				if s, ok := item.(string); ok {
+					urls = append(urls, s)
+				}
+			}
+		}
+		for _, u := range urls {
+			if _, ok := known[u]; ok {
+				out[field] = append(out[field], u)
+			}
+		}
+	}
+	return out
+}
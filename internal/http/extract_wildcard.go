@@ -0,0 +1,193 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/crawler"
+)
+
+// defaultExtractWildcardLimit bounds how many pages a single wildcard URL
+// (e.g. https://example.com/blog/*) expands to when the request does not
+// set `limit`.
+const defaultExtractWildcardLimit = 10
+
+// isWildcardURL reports whether an extract URL asks for site discovery,
+// i.e. it ends in "/*".
+func isWildcardURL(u string) bool {
+	return strings.HasSuffix(u, "/*")
+}
+
+// wildcardBase strips the trailing "*" from a wildcard URL, leaving the
+// prefix that discovered pages must share.
+func wildcardBase(u string) string {
+	return strings.TrimSuffix(u, "*")
+}
+
+// extractWildcardLimit returns the per-wildcard page limit for a request,
+// capped by crawler.maxPagesDefault when configured.
+func extractWildcardLimit(cfg *config.Config, req ExtractRequest) int {
+	limit := defaultExtractWildcardLimit
+	if req.Limit != nil && *req.Limit > 0 {
+		limit = *req.Limit
+	}
+	if cfg != nil && cfg.Crawler.MaxPagesDefault > 0 && limit > cfg.Crawler.MaxPagesDefault {
+		limit = cfg.Crawler.MaxPagesDefault
+	}
+	return limit
+}
+
+// expandExtractURLs replaces wildcard entries with pages discovered by a
+// map pass over the site, keeping only pages under the wildcard prefix.
+// Plain URLs are passed through unchanged. The boolean result reports
+// whether any wildcard was expanded.
+func expandExtractURLs(ctx context.Context, cfg *config.Config, deps *extractDeps, req ExtractRequest) ([]string, bool, error) {
+	mapLinks := crawler.Map
+	if deps != nil && deps.mapLinks != nil {
+		mapLinks = deps.mapLinks
+	}
+
+	limit := extractWildcardLimit(cfg, req)
+	timeout := 30 * time.Second
+	if deps != nil && deps.timeout > 0 {
+		timeout = deps.timeout
+	}
+
+	seen := make(map[string]struct{}, len(req.URLs))
+	out := make([]string, 0, len(req.URLs))
+	add := func(u string) {
+		if _, ok := seen[u]; ok {
+			return
+		}
+		seen[u] = struct{}{}
+		out = append(out, u)
+	}
+
+	expanded := false
+	for _, u := range req.URLs {
+		if !isWildcardURL(u) {
+			add(u)
+			continue
+		}
+		expanded = true
+
+		base := wildcardBase(u)
+		parsedBase, err := url.Parse(base)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid wildcard url %q: %w", u, err)
+		}
+
+		mapCtx, cancel := context.WithTimeout(ctx, timeout)
+		res, err := mapLinks(mapCtx, crawler.MapOptions{
+			URL: base,
+			// Discover more links than needed since only those under the
+			// wildcard prefix are kept.
+			Limit:             limit * 5,
+			IgnoreQueryParams: true,
+			SitemapMode:       "include",
+			Timeout:           timeout,
+			RespectRobots:     cfg != nil && cfg.Robots.Respect,
+			UserAgent:         cfg.RequestUserAgent(),
+			RobotsAgent:       cfg.RobotsAgent(),
+			OnRobotsBlocked:   recordHostRobotsBlock,
+		})
+		cancel()
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to discover pages for %q: %w", u, err)
+		}
+
+		count := 0
+		for _, link := range res.Links {
+			if count >= limit {
+				break
+			}
+			parsed, err := url.Parse(link.URL)
+			if err != nil || !strings.EqualFold(parsed.Host, parsedBase.Host) {
+				continue
+			}
+			if !strings.HasPrefix(parsed.Path, parsedBase.Path) && strings.TrimSuffix(parsedBase.Path, "/") != parsed.Path {
+				continue
+			}
+			before := len(out)
+			add(link.URL)
+			if len(out) > before {
+				count++
+			}
+		}
+	}
+
+	return out, expanded, nil
+}
+
+// mergeExtractResults combines per-page extraction objects into a single
+// object. Scalars keep the first non-empty value, arrays are concatenated
+// without duplicates, and nested objects are merged recursively.
+func mergeExtractResults(values []map[string]any) map[string]any {
+	merged := map[string]any{}
+	for _, v := range values {
+		mergeExtractValue(merged, v)
+	}
+	return merged
+}
+
+func mergeExtractValue(dst, src map[string]any) {
+	for k, v := range src {
+		if isEmptyExtractValue(v) {
+			if _, ok := dst[k]; !ok {
+				dst[k] = v
+			}
+			continue
+		}
+
+		existing, ok := dst[k]
+		if !ok || isEmptyExtractValue(existing) {
+			dst[k] = v
+			continue
+		}
+
+		switch ev := existing.(type) {
+		case []any:
+			if sv, ok := v.([]any); ok {
+				for _, item := range sv {
+					if !containsExtractValue(ev, item) {
+						ev = append(ev, item)
+					}
+				}
+				dst[k] = ev
+			}
+		case map[string]any:
+			if sv, ok := v.(map[string]any); ok {
+				mergeExtractValue(ev, sv)
+			}
+		}
+	}
+}
+
+func isEmptyExtractValue(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(t) == ""
+	case []any:
+		return len(t) == 0
+	case map[string]any:
+		return len(t) == 0
+	default:
+		return false
+	}
+}
+
+func containsExtractValue(list []any, v any) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDB is an in-memory database/sql driver for handlers that go through
+// the generated queries. Queries are keyed by their sqlc name; a query with
+// no configured rows returns none, so :one queries fail with
+// sql.ErrNoRows. Every query is recorded with its arguments.
+type fakeDB struct {
+	mu    sync.Mutex
+	rows  map[string][]any
+	calls []fakeDBCall
+}
+
+// fakeDBCall is one query run against a fakeDB.
+type fakeDBCall struct {
+	Name string
+	Args []driver.Value
+}
+
+// newFakeDB returns a fakeDB and a *sql.DB backed by it. rows maps a
+// query name to its result rows: model structs, whose fields are returned
+// in declaration order like the generated Scan calls expect, or []any.
+func newFakeDB(t *testing.T, rows map[string][]any) (*fakeDB, *sql.DB) {
+	t.Helper()
+	f := &fakeDB{rows: rows}
+	conn := sql.OpenDB(f)
+	t.Cleanup(func() { _ = conn.Close() })
+	return f, conn
+}
+
+// ran returns the names of the queries run so far, in order.
+func (f *fakeDB) ran() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(f.calls))
+	for _, call := range f.calls {
+		names = append(names, call.Name)
+	}
+	return names
+}
+
+// call returns the first call of the named query.
+func (f *fakeDB) call(name string) (fakeDBCall, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, call := range f.calls {
+		if call.Name == name {
+			return call, true
+		}
+	}
+	return fakeDBCall{}, false
+}
+
+func (f *fakeDB) record(query string, args []driver.Value) []any {
+	name := strings.TrimSpace(strings.SplitN(query, "\n", 2)[0])
+	if rest, ok := strings.CutPrefix(name, "-- name: "); ok {
+		name = strings.Fields(rest)[0]
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, fakeDBCall{Name: name, Args: args})
+	return f.rows[name]
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{f} }
+
+type fakeDriver struct{ f *fakeDB }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d.f}, nil }
+
+type fakeConn struct{ f *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.f, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	f     *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(len(s.f.record(s.query, args))), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows := &fakeRows{}
+	for _, row := range s.f.record(s.query, args) {
+		rows.values = append(rows.values, fakeRowValues(row))
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+	next   int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.values) == 0 {
+		return nil
+	}
+	return make([]string, len(r.values[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+// fakeRowValues flattens a model struct, or a []any, into driver values.
+func fakeRowValues(row any) []driver.Value {
+	if values, ok := row.([]any); ok {
+		out := make([]driver.Value, 0, len(values))
+		for _, v := range values {
+			out = append(out, fakeDriverValue(v))
+		}
+		return out
+	}
+	rv := reflect.ValueOf(row)
+	out := make([]driver.Value, 0, rv.NumField())
+	for i := range rv.NumField() {
+		out = append(out, fakeDriverValue(rv.Field(i).Interface()))
+	}
+	return out
+}
+
+func fakeDriverValue(v any) driver.Value {
+	switch v := v.(type) {
+	case driver.Valuer:
+		out, err := v.Value()
+		if err != nil {
+			panic(err)
+		}
+		return out
+	case json.RawMessage:
+		return []byte(v)
+	case time.Time, string, bool, []byte, int64, float64, nil:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	}
+	panic("fakeDB: unsupported value type " + rv.Type().String())
+}
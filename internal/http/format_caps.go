@@ -0,0 +1,115 @@
+package http
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"raito/internal/config"
+)
+
+// FormatByteLimits overrides the server's per-format size caps for one
+// request. Unset formats keep the configured cap; 0 removes it, which is
+// only allowed when scraper.formatMaxBytesLimit does not bound the format.
+type FormatByteLimits struct {
+	Markdown *int64 `json:"markdown,omitempty"`
+	HTML     *int64 `json:"html,omitempty"`
+	RawHTML  *int64 `json:"rawHtml,omitempty"`
+}
+
+// validateFormatByteLimits checks per-request caps against the bounds set
+// in scraper.formatMaxBytesLimit.
+func validateFormatByteLimits(cfg *config.Config, req *FormatByteLimits) error {
+	if req == nil {
+		return nil
+	}
+	limits := cfg.Scraper.FormatMaxBytesLimit
+	for _, f := range []struct {
+		name  string
+		value *int64
+		limit int64
+	}{
+		{"markdown", req.Markdown, limits.Markdown},
+		{"html", req.HTML, limits.HTML},
+		{"rawHtml", req.RawHTML, limits.RawHTML},
+	} {
+		if f.value == nil {
+			continue
+		}
+		if *f.value < 0 {
+			return fmt.Errorf("maxFormatBytes.%s must be >= 0", f.name)
+		}
+		if f.limit > 0 && (*f.value == 0 || *f.value > f.limit) {
+			return fmt.Errorf("maxFormatBytes.%s must be between 1 and %d", f.name, f.limit)
+		}
+	}
+	return nil
+}
+
+// formatSizeCaps resolves the caps for a request: the configured caps,
+// replaced by any the request sets.
+func formatSizeCaps(cfg *config.Config, req *FormatByteLimits) config.FormatSizeLimits {
+	var caps config.FormatSizeLimits
+	if cfg != nil {
+		caps = cfg.Scraper.FormatMaxBytes
+	}
+	if req == nil {
+		return caps
+	}
+	if req.Markdown != nil {
+		caps.Markdown = *req.Markdown
+	}
+	if req.HTML != nil {
+		caps.HTML = *req.HTML
+	}
+	if req.RawHTML != nil {
+		caps.RawHTML = *req.RawHTML
+	}
+	return caps
+}
+
+// capDocumentFormats truncates markdown, html and rawHtml that exceed
+// caps, appends a marker to each truncated format, and flags the document
+// metadata.
+func capDocumentFormats(doc *Document, caps config.FormatSizeLimits) {
+	if doc == nil {
+		return
+	}
+	var truncated []string
+	if s, ok := truncateFormat(doc.Markdown, caps.Markdown, "\n\n[Truncated by Raito: markdown exceeded %d bytes]"); ok {
+		doc.Markdown = s
+		truncated = append(truncated, "markdown")
+	}
+	if s, ok := truncateFormat(doc.HTML, caps.HTML, "\n<!-- Truncated by Raito: html exceeded %d bytes -->"); ok {
+		doc.HTML = s
+		truncated = append(truncated, "html")
+	}
+	if s, ok := truncateFormat(doc.RawHTML, caps.RawHTML, "\n<!-- Truncated by Raito: rawHtml exceeded %d bytes -->"); ok {
+		doc.RawHTML = s
+		truncated = append(truncated, "rawHtml")
+	}
+	if len(truncated) > 0 {
+		doc.Metadata.Truncated = true
+		doc.Metadata.TruncatedFormats = truncated
+	}
+}
+
+// capDocuments applies capDocumentFormats to every document in docs.
+func capDocuments(docs []Document, caps config.FormatSizeLimits) {
+	for i := range docs {
+		capDocumentFormats(&docs[i], caps)
+	}
+}
+
+// truncateFormat cuts s to at most max bytes without splitting a UTF-8
+// sequence and appends marker, formatted with max. It reports false when
+// s fits or max is 0.
+func truncateFormat(s string, max int64, marker string) (string, bool) {
+	if max <= 0 || int64(len(s)) <= max {
+		return s, false
+	}
+	cut := int(max)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + fmt.Sprintf(marker, max), true
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"strings"
+	"testing"
+
+	"raito/internal/config"
+)
+
+func TestCapDocumentFormats(t *testing.T) {
+	doc := &Document{
+		Markdown: "héllo world",
+		HTML:     "<p>short</p>",
+		RawHTML:  strings.Repeat("x", 20),
+	}
+	capDocumentFormats(doc, config.FormatSizeLimits{Markdown: 2, RawHTML: 10})
+
+	// The cap falls inside "é", so the cut backs off to a rune boundary.
+	if !strings.HasPrefix(doc.Markdown, "h\n\n[Truncated by Raito") {
+		t.Fatalf("unexpected markdown %q", doc.Markdown)
+	}
+	if doc.HTML != "<p>short</p>" {
+		t.Fatalf("expected uncapped html to be untouched, got %q", doc.HTML)
+	}
+	if !strings.HasPrefix(doc.RawHTML, strings.Repeat("x", 10)+"\n<!-- Truncated by Raito") {
+		t.Fatalf("unexpected rawHtml %q", doc.RawHTML)
+	}
+	if !doc.Metadata.Truncated || strings.Join(doc.Metadata.TruncatedFormats, ",") != "markdown,rawHtml" {
+		t.Fatalf("unexpected metadata: %+v", doc.Metadata)
+	}
+
+	small := &Document{Markdown: "ok"}
+	capDocumentFormats(small, config.FormatSizeLimits{Markdown: 2})
+	if small.Markdown != "ok" || small.Metadata.Truncated {
+		t.Fatalf("expected document within caps to be untouched: %+v", small)
+	}
+}
+
+func TestFormatByteLimits(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scraper.FormatMaxBytes = config.FormatSizeLimits{Markdown: 100, HTML: 100}
+	cfg.Scraper.FormatMaxBytesLimit = config.FormatSizeLimits{Markdown: 1000}
+
+	n := func(v int64) *int64 { return &v }
+	if err := validateFormatByteLimits(cfg, &FormatByteLimits{Markdown: n(1000), HTML: n(0)}); err != nil {
+		t.Fatalf("expected limits within bounds to be valid, got %v", err)
+	}
+	for _, req := range []*FormatByteLimits{{Markdown: n(1001)}, {Markdown: n(0)}, {RawHTML: n(-1)}} {
+		if err := validateFormatByteLimits(cfg, req); err == nil {
+			t.Fatalf("expected %+v to be rejected", req)
+		}
+	}
+
+	caps := formatSizeCaps(cfg, &FormatByteLimits{Markdown: n(500), HTML: n(0)})
+	if caps != (config.FormatSizeLimits{Markdown: 500}) {
+		t.Fatalf("unexpected caps %+v", caps)
+	}
+}
@@ -1,6 +1,21 @@
 package http
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+
+	"raito/internal/config"
+	"raito/internal/scraper"
+	"raito/internal/scrapeutil"
+)
+
+// browserOptions maps the rod config onto scraper.BrowserOptions.
+func browserOptions(cfg *config.Config) scraper.BrowserOptions {
+	return scraper.BrowserOptions{
+		Isolation:    cfg.Rod.Isolation,
+		MaxProcesses: cfg.Rod.MaxProcesses,
+	}
+}
 
 // getScreenshotFormatConfig scans formats for a screenshot entry and returns
 // whether it was requested along with a fullPage flag. It supports both simple
@@ -34,3 +49,57 @@ func getScreenshotFormatConfig(formats []interface{}) (bool, bool) {
 
 	return false, false
 }
+
+// validateScrapeSubmission checks the method, body and actions options of
+// a scrape request and normalizes the method to upper case. POST bodies
+// are sent by the HTTP engine, while actions need the browser engine, so
+// the two cannot be combined. Evidence needs a stored job, so it cannot be
+// combined with zero data retention.
+func validateScrapeSubmission(req *ScrapeRequest) error {
+	req.Method = strings.ToUpper(strings.TrimSpace(req.Method))
+	switch req.Method {
+	case "", "GET":
+		if req.Body != "" {
+			return fmt.Errorf("field 'body' requires method POST")
+		}
+	case "POST":
+		hasScreenshot, _ := getScreenshotFormatConfig(req.Formats)
+		browserFormat := scrapeutil.WantsFormat(req.Formats, "a11y") || scrapeutil.WantsFormat(req.Formats, "performance")
+		if len(req.Actions) > 0 || hasScreenshot || browserFormat || (req.UseBrowser != nil && *req.UseBrowser) {
+			return fmt.Errorf("method POST is only supported by the HTTP engine; use a fillForm action to submit forms in the browser")
+		}
+	default:
+		return fmt.Errorf("unsupported method %q; expected GET or POST", req.Method)
+	}
+
+	for i, a := range req.Actions {
+		if a.Type != scraper.ActionFillForm {
+			return fmt.Errorf("actions[%d]: unsupported type %q; expected %q", i, a.Type, scraper.ActionFillForm)
+		}
+		if len(a.Fields) == 0 {
+			return fmt.Errorf("actions[%d]: fillForm requires at least one field", i)
+		}
+	}
+	if req.Evidence != nil && *req.Evidence && zeroRetentionRequested(req.ZeroDataRetention, req.StoreInCache) {
+		return fmt.Errorf("evidence requires the scrape to be stored; it cannot be combined with zeroDataRetention or storeInCache: false")
+	}
+	return validateTargetAuth(req.Auth)
+}
+
+// scraperActions converts API actions into scraper actions; fillForm
+// submits the form unless submit is explicitly false.
+func scraperActions(actions []ScrapeAction) []scraper.Action {
+	if len(actions) == 0 {
+		return nil
+	}
+	out := make([]scraper.Action, 0, len(actions))
+	for _, a := range actions {
+		out = append(out, scraper.Action{
+			Type:     a.Type,
+			Selector: a.Selector,
+			Fields:   a.Fields,
+			Submit:   a.Submit == nil || *a.Submit,
+		})
+	}
+	return out
+}
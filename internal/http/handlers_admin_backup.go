@@ -0,0 +1,141 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/backup"
+	"raito/internal/config"
+	"raito/internal/store"
+)
+
+type adminRestoreResponse struct {
+	Success bool                  `json:"success"`
+	Result  *backup.RestoreResult `json:"result,omitempty"`
+}
+
+// adminBackupHandler streams a tar.gz backup of the instance. Query
+// parameters includeJobs, includePasswordHashes, and includeConfig opt in
+// to the larger or more sensitive parts of the archive.
+func adminBackupHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	opts := backup.Options{
+		IncludeJobs:           c.QueryBool("includeJobs"),
+		IncludePasswordHashes: c.QueryBool("includePasswordHashes"),
+	}
+	if c.QueryBool("includeConfig") {
+		if cfg.Path == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "config path is not set on the server",
+			})
+		}
+		opts.ConfigPath = cfg.Path
+	}
+
+	// Spool to a temp file so export errors can still be reported as JSON
+	// and large archives are not held in memory.
+	f, err := os.CreateTemp("", "raito-backup-*.tar.gz")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "INTERNAL_ERROR",
+			Error:   err.Error(),
+		})
+	}
+	// Unlinking early is safe: the open handle keeps the data readable
+	// until the response stream closes it.
+	_ = os.Remove(f.Name())
+
+	manifest, err := backup.Export(c.Context(), st.DB, f, opts)
+	if err == nil {
+		_, err = f.Seek(0, 0)
+	}
+	var size int64
+	if err == nil {
+		var info os.FileInfo
+		if info, err = f.Stat(); err == nil {
+			size = info.Size()
+		}
+	}
+	if err != nil {
+		f.Close()
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BACKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "admin.backup.export", auditEventOptions{
+		ResourceType: "instance",
+		Metadata: map[string]any{
+			"schemaVersion":          manifest.SchemaVersion,
+			"includesJobs":           manifest.IncludesJobs,
+			"includesPasswordHashes": manifest.IncludesPasswordHashes,
+			"includesConfig":         manifest.IncludesConfig,
+			"bytes":                  size,
+		},
+	})
+
+	filename := fmt.Sprintf("raito-backup-%s.tar.gz", manifest.CreatedAt.Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, "application/gzip")
+	c.Set(fiber.HeaderContentDisposition, contentDisposition(filename))
+	return c.SendStream(f, int(size))
+}
+
+// adminRestoreHandler restores a backup archive sent as the request body.
+// Large archives may need a server.routes bodyLimitBytes override for
+// POST /admin/restore, or the `raito-api restore` subcommand.
+func adminRestoreHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	body := c.Body()
+	if len(body) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "request body must be a backup archive",
+		})
+	}
+
+	start := time.Now()
+	res, err := backup.Restore(c.Context(), st.DB, bytes.NewReader(body))
+	if err != nil {
+		if errors.Is(err, backup.ErrSchemaMismatch) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Success: false,
+				Code:    "SCHEMA_VERSION_MISMATCH",
+				Error:   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "RESTORE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "admin.backup.restore", auditEventOptions{
+		ResourceType: "instance",
+		Metadata: map[string]any{
+			"schemaVersion": res.Manifest.SchemaVersion,
+			"backupCreated": res.Manifest.CreatedAt,
+			"restored":      res.Restored,
+			"durationMs":    time.Since(start).Milliseconds(),
+		},
+	})
+
+	return c.Status(fiber.StatusOK).JSON(adminRestoreResponse{
+		Success: true,
+		Result:  &res,
+	})
+}
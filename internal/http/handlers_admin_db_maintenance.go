@@ -0,0 +1,75 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+type adminDBMaintenanceResponse struct {
+	Success bool `json:"success"`
+	// Enabled reports whether workers run maintenance nightly.
+	Enabled bool                     `json:"enabled"`
+	HourUTC int                      `json:"hourUTC"`
+	Tables  []string                 `json:"tables"`
+	Report  jobs.DBMaintenanceReport `json:"report"`
+}
+
+// adminDBMaintenanceHandler returns the current table and index health
+// report with recommendations. It only reads statistics.
+func adminDBMaintenanceHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	report, err := jobs.BuildDBMaintenanceReport(c.Context(), st)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "DB_MAINTENANCE_REPORT_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(dbMaintenanceResponse(cfg, report))
+}
+
+// adminRunDBMaintenanceHandler runs maintenance now instead of waiting
+// for the nightly run.
+func adminRunDBMaintenanceHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	report, err := jobs.RunDBMaintenance(c.Context(), cfg, st)
+	if errors.Is(err, jobs.ErrDBMaintenanceBusy) {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Success: false,
+			Code:    "DB_MAINTENANCE_RUNNING",
+			Error:   err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "DB_MAINTENANCE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "db.maintenance.run", auditEventOptions{
+		Metadata: map[string]any{"analyzed": report.Analyzed},
+	})
+	return c.Status(fiber.StatusOK).JSON(dbMaintenanceResponse(cfg, report))
+}
+
+func dbMaintenanceResponse(cfg *config.Config, report jobs.DBMaintenanceReport) adminDBMaintenanceResponse {
+	return adminDBMaintenanceResponse{
+		Success: true,
+		Enabled: cfg.Database.Maintenance.Enabled,
+		HourUTC: cfg.Database.Maintenance.HourUTC,
+		Tables:  jobs.MaintenanceTables(cfg),
+		Report:  report,
+	}
+}
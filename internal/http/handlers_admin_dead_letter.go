@@ -0,0 +1,107 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+// adminListDeadLetterJobsHandler implements GET /admin/jobs/dead-letter.
+// It takes the filters of GET /admin/jobs except status, and includes each
+// job's stored input so admins can see why it could not run.
+func adminListDeadLetterJobsHandler(c *fiber.Ctx) error {
+	filter, ok, err := parseAdminJobListFilter(c)
+	if !ok {
+		return err
+	}
+	filter.Status = string(jobs.StatusDeadLetter)
+	return sendAdminJobs(c, filter, true)
+}
+
+// adminRequeueJobHandler implements POST /admin/jobs/:id/requeue. A failed
+// or dead-letter job is queued again with its stored input, e.g. after the
+// configuration that made it fail has been fixed.
+func adminRequeueJobHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid job id",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "job not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	// Zero-retention jobs have no input left to run once purged.
+	if job.PurgedAt.Valid {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_PURGED",
+			Error:   "job data was purged and cannot be requeued",
+		})
+	}
+
+	if err := st.RequeueFailedJob(c.Context(), jobID, job.Error.String); err != nil {
+		if errors.Is(err, store.ErrJobNotFailed) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Success: false,
+				Code:    "JOB_NOT_FAILED",
+				Error:   "only failed and dead-letter jobs can be requeued",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_REQUEUE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	var tenantID *uuid.UUID
+	if job.TenantID.Valid {
+		tenantID = &job.TenantID.UUID
+	}
+	recordAuditEvent(c, st, "job.requeue", auditEventOptions{
+		TenantID:     tenantID,
+		ResourceType: "job",
+		ResourceID:   job.ID.String(),
+		Metadata: map[string]any{
+			"previousStatus": job.Status,
+			"previousError":  job.Error.String,
+		},
+	})
+
+	job, err = st.GetJobByID(c.Context(), jobID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(adminJobResponse{
+		Success: true,
+		Job:     marshalAdminJob(job, false),
+	})
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+func TestAdminDeadLetter_BadRequests(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("store", &store.Store{})
+		return c.Next()
+	})
+	app.Get("/admin/jobs/dead-letter", adminListDeadLetterJobsHandler)
+	app.Post("/admin/jobs/:id/requeue", adminRequeueJobHandler)
+
+	for _, tc := range []struct {
+		method, path string
+	}{
+		{http.MethodGet, "/admin/jobs/dead-letter?limit=0"},
+		{http.MethodGet, "/admin/jobs/dead-letter?tenantId=nope"},
+		{http.MethodPost, "/admin/jobs/not-a-uuid/requeue"},
+	} {
+		resp, err := app.Test(httptest.NewRequest(tc.method, tc.path, nil), -1)
+		if err != nil {
+			t.Fatalf("app.Test error: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s %s: expected 400, got %d", tc.method, tc.path, resp.StatusCode)
+		}
+	}
+}
+
+// TestAdminRequeueJob_DropsPreviousRun ensures a replayed job starts over
+// instead of adding to the documents of the run that failed.
+func TestAdminRequeueJob_DropsPreviousRun(t *testing.T) {
+	jobID := uuid.New()
+	fake, conn := newFakeDB(t, map[string][]any{
+		"GetJobByID": {db.GetJobByIDRow{
+			ID:     jobID,
+			Type:   "crawl",
+			Status: "dead_letter",
+			Url:    "https://example.com",
+			Input:  json.RawMessage(`{"url":"https://example.com"}`),
+			Error:  sql.NullString{String: "WORKER_LOST", Valid: true},
+		}},
+		"RequeueFailedJob": {[]any{}},
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("store", &store.Store{DB: conn})
+		return c.Next()
+	})
+	app.Post("/admin/jobs/:id/requeue", adminRequeueJobHandler)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/admin/jobs/"+jobID.String()+"/requeue", nil), -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	ran := strings.Join(fake.ran(), "\n")
+	want := strings.Join([]string{
+		"RequeueFailedJob",
+		"DELETE FROM documents WHERE job_id = $1",
+		"DELETE FROM job_assets WHERE job_id = $1",
+		"DeleteCrawlFrontier",
+		"InsertJobEvent",
+	}, "\n")
+	if !strings.Contains(ran, want) {
+		t.Fatalf("expected the previous run to be dropped with the requeue, got:\n%s", ran)
+	}
+}
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// AdminHostStats summarises the scrape outcomes of one target host over
+// the requested window.
+type AdminHostStats struct {
+	Host     string `json:"host"`
+	Requests int64  `json:"requests"`
+	// Statuses counts responses by status class ("2xx" to "5xx").
+	Statuses map[string]int64 `json:"statuses"`
+	// Errors counts requests that got no response; TLSFailures,
+	// Timeouts and DNSFailures are the classified part of them.
+	Errors        int64 `json:"errors"`
+	TLSFailures   int64 `json:"tlsFailures"`
+	Timeouts      int64 `json:"timeouts"`
+	DNSFailures   int64 `json:"dnsFailures"`
+	RobotsBlocked int64 `json:"robotsBlocked"`
+	// SuccessRate is the share of requests answered with a 2xx or 3xx
+	// status, or 0 when there were none.
+	SuccessRate float64          `json:"successRate"`
+	LatencyMs   AdminHostLatency `json:"latencyMs"`
+	LastSeenAt  time.Time        `json:"lastSeenAt"`
+}
+
+// AdminHostLatency holds request latency in milliseconds. Percentiles are
+// estimated from the latency buckets kept per host.
+type AdminHostLatency struct {
+	Avg int64 `json:"avg"`
+	P50 int64 `json:"p50"`
+	P90 int64 `json:"p90"`
+	P99 int64 `json:"p99"`
+}
+
+type adminHostsResponse struct {
+	Success     bool             `json:"success"`
+	WindowHours int              `json:"windowHours"`
+	Hosts       []AdminHostStats `json:"hosts"`
+}
+
+// adminListHostsHandler lists per-host scrape statistics for the last
+// windowHours hours (default 24, at most 168), worst success rate first
+// unless sort asks for requests, errors or p90 latency.
+func adminListHostsHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	windowHours := 24
+	if v := c.Query("windowHours"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid windowHours value",
+			})
+		}
+		windowHours = min(n, int(hostStatsRetention/time.Hour))
+	}
+
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid limit value",
+			})
+		}
+		if n > 500 {
+			n = 500
+		}
+		limit = n
+	}
+
+	sortBy := c.Query("sort", "successRate")
+	switch sortBy {
+	case "successRate", "requests", "errors", "p90":
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "sort must be one of successRate, requests, errors, p90",
+		})
+	}
+
+	since := time.Now().UTC().Truncate(time.Hour).Add(-time.Duration(windowHours-1) * time.Hour)
+	rows, err := db.New(st.DB).ListHostStatsSince(c.Context(), since)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "HOST_STATS_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	hosts := aggregateHostStats(rows, strings.ToLower(strings.TrimSpace(c.Query("host"))))
+	sortHostStats(hosts, sortBy)
+	if len(hosts) > limit {
+		hosts = hosts[:limit]
+	}
+
+	return c.Status(fiber.StatusOK).JSON(adminHostsResponse{
+		Success:     true,
+		WindowHours: windowHours,
+		Hosts:       hosts,
+	})
+}
+
+// aggregateHostStats sums hourly rows per host, keeping hosts whose name
+// contains filter.
+func aggregateHostStats(rows []db.HostStat, filter string) []AdminHostStats {
+	type total struct {
+		row     db.HostStat
+		latency [len(hostLatencyBoundsMs) + 1]int64
+	}
+	byHost := map[string]*total{}
+	var order []string
+	for _, r := range rows {
+		if filter != "" && !strings.Contains(r.Host, filter) {
+			continue
+		}
+		t, ok := byHost[r.Host]
+		if !ok {
+			t = &total{row: db.HostStat{Host: r.Host}}
+			byHost[r.Host] = t
+			order = append(order, r.Host)
+		}
+		t.row.Requests += r.Requests
+		t.row.Status2xx += r.Status2xx
+		t.row.Status3xx += r.Status3xx
+		t.row.Status4xx += r.Status4xx
+		t.row.Status5xx += r.Status5xx
+		t.row.Errors += r.Errors
+		t.row.TlsErrors += r.TlsErrors
+		t.row.Timeouts += r.Timeouts
+		t.row.DnsErrors += r.DnsErrors
+		t.row.RobotsBlocked += r.RobotsBlocked
+		t.row.LatencyMsSum += r.LatencyMsSum
+		for i, n := range []int64{r.Latency100ms, r.Latency250ms, r.Latency500ms, r.Latency1s,
+			r.Latency2500ms, r.Latency5s, r.Latency10s, r.LatencyOver10s} {
+			t.latency[i] += n
+		}
+		if r.LastSeenAt.After(t.row.LastSeenAt) {
+			t.row.LastSeenAt = r.LastSeenAt
+		}
+	}
+
+	out := make([]AdminHostStats, 0, len(order))
+	for _, host := range order {
+		t := byHost[host]
+		s := AdminHostStats{
+			Host:     host,
+			Requests: t.row.Requests,
+			Statuses: map[string]int64{
+				"2xx": t.row.Status2xx,
+				"3xx": t.row.Status3xx,
+				"4xx": t.row.Status4xx,
+				"5xx": t.row.Status5xx,
+			},
+			Errors:        t.row.Errors,
+			TLSFailures:   t.row.TlsErrors,
+			Timeouts:      t.row.Timeouts,
+			DNSFailures:   t.row.DnsErrors,
+			RobotsBlocked: t.row.RobotsBlocked,
+			LatencyMs: AdminHostLatency{
+				P50: latencyPercentile(t.latency, 0.50),
+				P90: latencyPercentile(t.latency, 0.90),
+				P99: latencyPercentile(t.latency, 0.99),
+			},
+			LastSeenAt: t.row.LastSeenAt,
+		}
+		if t.row.Requests > 0 {
+			s.SuccessRate = float64(t.row.Status2xx+t.row.Status3xx) / float64(t.row.Requests)
+			s.LatencyMs.Avg = t.row.LatencyMsSum / t.row.Requests
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// sortHostStats orders hosts by sortBy, worst first, breaking ties by
+// request count and then host name. Hosts with only robots blocks sort
+// last when ordering by success rate.
+func sortHostStats(hosts []AdminHostStats, sortBy string) {
+	sort.SliceStable(hosts, func(i, j int) bool {
+		a, b := hosts[i], hosts[j]
+		switch sortBy {
+		case "successRate":
+			if (a.Requests == 0) != (b.Requests == 0) {
+				return b.Requests == 0
+			}
+			if a.SuccessRate != b.SuccessRate {
+				return a.SuccessRate < b.SuccessRate
+			}
+		case "errors":
+			if a.Errors != b.Errors {
+				return a.Errors > b.Errors
+			}
+		case "p90":
+			if a.LatencyMs.P90 != b.LatencyMs.P90 {
+				return a.LatencyMs.P90 > b.LatencyMs.P90
+			}
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Host < b.Host
+	})
+}
+
+// latencyPercentile estimates the p-th quantile in milliseconds from
+// counts per hostLatencyBoundsMs bucket, interpolating within a bucket.
+// Requests slower than the last bound are reported at that bound.
+func latencyPercentile(counts [len(hostLatencyBoundsMs) + 1]int64, p float64) int64 {
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := p * float64(total)
+	var seen int64
+	for i, n := range counts {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+		if i == len(hostLatencyBoundsMs) {
+			break
+		}
+		var lower int64
+		if i > 0 {
+			lower = hostLatencyBoundsMs[i-1]
+		}
+		upper := hostLatencyBoundsMs[i]
+		return lower + int64(float64(upper-lower)*(rank-float64(seen))/float64(n))
+	}
+	return hostLatencyBoundsMs[len(hostLatencyBoundsMs)-1]
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"raito/internal/db"
+)
+
+func TestClassifyHostError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{&net.DNSError{Err: "no such host", Name: "missing.test", IsNotFound: true}, "dns"},
+		{fmt.Errorf("get: %w", x509.UnknownAuthorityError{}), "tls"},
+		{fmt.Errorf("scrape: %w", context.DeadlineExceeded), "timeout"},
+		{errors.New("navigation failed: net::ERR_CERT_DATE_INVALID"), "tls"},
+		{errors.New("navigation failed: net::ERR_NAME_NOT_RESOLVED"), "dns"},
+		{errors.New("connection refused"), "error"},
+	}
+	for _, tc := range cases {
+		if got := classifyHostError(tc.err); got != tc.want {
+			t.Errorf("%v: got %q, want %q", tc.err, got, tc.want)
+		}
+	}
+
+	if got := hostOutcome(503, nil); got != "5xx" {
+		t.Errorf("503: got %q, want 5xx", got)
+	}
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	// 50 requests up to 100ms, 40 in 100-250ms, 10 slower than 10s.
+	counts := [len(hostLatencyBoundsMs) + 1]int64{50, 40, 0, 0, 0, 0, 0, 10}
+	if got := latencyPercentile(counts, 0.5); got != 100 {
+		t.Errorf("p50: got %d, want 100", got)
+	}
+	if got := latencyPercentile(counts, 0.7); got != 175 {
+		t.Errorf("p70: got %d, want 175", got)
+	}
+	if got := latencyPercentile(counts, 0.99); got != 10000 {
+		t.Errorf("p99: got %d, want 10000", got)
+	}
+	if got := latencyPercentile([len(hostLatencyBoundsMs) + 1]int64{}, 0.9); got != 0 {
+		t.Errorf("empty: got %d, want 0", got)
+	}
+}
+
+func TestAggregateHostStats(t *testing.T) {
+	hour := time.Now().UTC().Truncate(time.Hour)
+	rows := []db.HostStat{
+		{Host: "good.example", Bucket: hour.Add(-time.Hour), Requests: 10, Status2xx: 10, LatencyMsSum: 500, Latency100ms: 10, LastSeenAt: hour.Add(-30 * time.Minute)},
+		{Host: "good.example", Bucket: hour, Requests: 10, Status2xx: 9, Status3xx: 1, LatencyMsSum: 1500, Latency100ms: 5, Latency250ms: 5, LastSeenAt: hour.Add(time.Minute)},
+		{Host: "bad.example", Bucket: hour, Requests: 4, Status2xx: 1, Status5xx: 1, Errors: 2, TlsErrors: 2, LatencyMsSum: 4000, Latency1s: 4, LastSeenAt: hour},
+		{Host: "blocked.example", Bucket: hour, RobotsBlocked: 3, LastSeenAt: hour},
+	}
+
+	hosts := aggregateHostStats(rows, "")
+	sortHostStats(hosts, "successRate")
+	if len(hosts) != 3 || hosts[0].Host != "bad.example" || hosts[1].Host != "good.example" || hosts[2].Host != "blocked.example" {
+		t.Fatalf("unexpected order: %+v", hosts)
+	}
+
+	bad, good := hosts[0], hosts[1]
+	if bad.SuccessRate != 0.25 || bad.TLSFailures != 2 || bad.Statuses["5xx"] != 1 {
+		t.Errorf("unexpected bad host stats: %+v", bad)
+	}
+	if good.Requests != 20 || good.SuccessRate != 1 || good.LatencyMs.Avg != 100 {
+		t.Errorf("unexpected good host stats: %+v", good)
+	}
+	if !good.LastSeenAt.Equal(hour.Add(time.Minute)) {
+		t.Errorf("expected the latest last seen time, got %s", good.LastSeenAt)
+	}
+	if hosts[2].RobotsBlocked != 3 {
+		t.Errorf("expected robots blocks to be kept, got %+v", hosts[2])
+	}
+
+	if filtered := aggregateHostStats(rows, "good"); len(filtered) != 1 || filtered[0].Host != "good.example" {
+		t.Fatalf("unexpected filter result: %+v", filtered)
+	}
+}
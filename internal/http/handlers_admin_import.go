@@ -0,0 +1,553 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// maxBulkAPIKeys caps how many keys one bulk create request may ask for.
+const maxBulkAPIKeys = 500
+
+// maxUserImportRows caps the data rows of one CSV user import. Every new
+// user costs a bcrypt hash, so larger files should be split.
+const maxUserImportRows = 500
+
+type adminBulkAPIKeyItem struct {
+	Label string `json:"label"`
+	// Tenant is a tenant id or slug; empty creates a user key without a
+	// tenant, like POST /admin/api-keys.
+	Tenant             string `json:"tenant,omitempty"`
+	RateLimitPerMinute *int   `json:"rateLimitPerMinute,omitempty"`
+}
+
+type adminBulkAPIKeysRequest struct {
+	Keys []adminBulkAPIKeyItem `json:"keys"`
+}
+
+// adminBulkAPIKeyResult reports the outcome of one requested key. Key is
+// only present on success and is not shown again.
+type adminBulkAPIKeyResult struct {
+	Index    int    `json:"index"`
+	Label    string `json:"label"`
+	TenantID string `json:"tenantId,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type adminBulkAPIKeysResponse struct {
+	Success bool                    `json:"success"`
+	Code    string                  `json:"code,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+	Created int                     `json:"created"`
+	Failed  int                     `json:"failed"`
+	Results []adminBulkAPIKeyResult `json:"results"`
+}
+
+// lookupTenantRef finds a tenant by id or, when ref is not a UUID, by slug.
+func lookupTenantRef(ctx context.Context, q *db.Queries, ref string) (db.Tenant, error) {
+	ref = strings.TrimSpace(ref)
+	if id, err := uuid.Parse(ref); err == nil {
+		return q.GetTenantByID(ctx, id)
+	}
+	return q.GetTenantBySlug(ctx, strings.ToLower(ref))
+}
+
+// tenantRefCache memoizes lookupTenantRef for the rows of one bulk request,
+// which typically name the same few tenants over and over.
+type tenantRefCache struct {
+	q       *db.Queries
+	tenants map[string]db.Tenant
+}
+
+func newTenantRefCache(q *db.Queries) *tenantRefCache {
+	return &tenantRefCache{q: q, tenants: map[string]db.Tenant{}}
+}
+
+func (tc *tenantRefCache) lookup(ctx context.Context, ref string) (db.Tenant, error) {
+	key := strings.ToLower(strings.TrimSpace(ref))
+	if t, ok := tc.tenants[key]; ok {
+		return t, nil
+	}
+	t, err := lookupTenantRef(ctx, tc.q, ref)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Tenant{}, fmt.Errorf("tenant %q not found", ref)
+		}
+		return db.Tenant{}, err
+	}
+	tc.tenants[key] = t
+	return t, nil
+}
+
+// adminBulkCreateAPIKeysHandler implements POST /admin/api-keys/bulk. Each
+// entry is created independently, so one bad entry does not block the
+// rest; the response reports every entry in request order.
+func adminBulkCreateAPIKeysHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	p, _ := c.Locals("principal").(Principal)
+
+	var req adminBulkAPIKeysRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(adminBulkAPIKeysResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if len(req.Keys) == 0 || len(req.Keys) > maxBulkAPIKeys {
+		return c.Status(fiber.StatusBadRequest).JSON(adminBulkAPIKeysResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   fmt.Sprintf("keys must contain between 1 and %d entries", maxBulkAPIKeys),
+		})
+	}
+
+	tenants := newTenantRefCache(db.New(st.DB))
+	resp := adminBulkAPIKeysResponse{
+		Success: true,
+		Results: make([]adminBulkAPIKeyResult, 0, len(req.Keys)),
+	}
+	for i, item := range req.Keys {
+		result := adminBulkAPIKeyResult{Index: i, Label: strings.TrimSpace(item.Label)}
+		fail := func(code, msg string) {
+			result.Code, result.Error = code, msg
+			resp.Failed++
+			resp.Results = append(resp.Results, result)
+		}
+
+		if result.Label == "" {
+			fail("BAD_REQUEST", "label is required")
+			continue
+		}
+		if item.RateLimitPerMinute != nil && *item.RateLimitPerMinute <= 0 {
+			fail("BAD_REQUEST", "rateLimitPerMinute must be positive")
+			continue
+		}
+
+		rateLimit := item.RateLimitPerMinute
+		var tenantID *string
+		if strings.TrimSpace(item.Tenant) != "" {
+			tenant, err := tenants.lookup(c.Context(), item.Tenant)
+			if err != nil {
+				fail("TENANT_NOT_FOUND", err.Error())
+				continue
+			}
+			id := tenant.ID.String()
+			tenantID = &id
+			result.TenantID = id
+			// Same fallback as tenant key creation.
+			if rateLimit == nil && tenant.DefaultApiKeyRateLimitPerMinute.Valid {
+				v := int(tenant.DefaultApiKeyRateLimitPerMinute.Int32)
+				rateLimit = &v
+			}
+		}
+
+		raw, key, err := st.CreateRandomAPIKey(c.Context(), result.Label, false, rateLimit, tenantID)
+		if err != nil {
+			fail("API_KEY_CREATE_FAILED", err.Error())
+			continue
+		}
+		if p.UserID != nil {
+			_ = st.SetAPIKeyCreatedBy(c.Context(), key.ID, *p.UserID)
+		}
+
+		result.ID = key.ID.String()
+		result.Key = raw
+		resp.Created++
+		resp.Results = append(resp.Results, result)
+	}
+
+	recordAuditEvent(c, st, "admin.api_key.bulk_create", auditEventOptions{
+		ResourceType: "api_key",
+		Metadata: map[string]any{
+			"requested": len(req.Keys),
+			"created":   resp.Created,
+			"failed":    resp.Failed,
+		},
+	})
+
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// userImportColumns maps normalized CSV header names to row fields.
+var userImportColumns = map[string]string{
+	"email":         "email",
+	"name":          "name",
+	"password":      "password",
+	"tenant":        "tenant",
+	"role":          "role",
+	"issystemadmin": "isSystemAdmin",
+}
+
+// userImportRow is one validated data row of a user import CSV. Err is set
+// when the row itself is invalid; such rows are reported, not imported.
+type userImportRow struct {
+	Line          int
+	Email         string
+	Name          string
+	Password      string
+	Tenant        string
+	Role          string
+	IsSystemAdmin bool
+	Err           string
+}
+
+// parseUserImportCSV reads a user import CSV. The first record is a header
+// naming the columns in any order: email (required), name, password,
+// tenant, role and isSystemAdmin. Header names are case-insensitive and
+// may use underscores or spaces. Errors in the file as a whole fail the
+// import; problems with a single row are recorded on that row.
+func parseUserImportCSV(r io.Reader) ([]userImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %v", err)
+	}
+
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, h := range header {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		name := strings.ToLower(strings.TrimSpace(h))
+		name = strings.NewReplacer("_", "", " ", "", "-", "").Replace(name)
+		field, ok := userImportColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", strings.TrimSpace(h))
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("duplicate column %q", strings.TrimSpace(h))
+		}
+		seen[field] = true
+		columns[i] = field
+	}
+	if !seen["email"] {
+		return nil, fmt.Errorf("missing required column \"email\"")
+	}
+
+	var rows []userImportRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %v", err)
+		}
+		if len(rows) == maxUserImportRows {
+			return nil, fmt.Errorf("csv has more than %d rows", maxUserImportRows)
+		}
+
+		line, _ := cr.FieldPos(0)
+		values := map[string]string{}
+		for i, v := range record {
+			if i < len(columns) {
+				values[columns[i]] = strings.TrimSpace(v)
+			}
+		}
+		rows = append(rows, newUserImportRow(line, values))
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("csv has no data rows")
+	}
+	return rows, nil
+}
+
+func newUserImportRow(line int, values map[string]string) userImportRow {
+	row := userImportRow{
+		Line:     line,
+		Email:    strings.ToLower(values["email"]),
+		Name:     values["name"],
+		Password: values["password"],
+		Tenant:   values["tenant"],
+		Role:     strings.ToLower(values["role"]),
+	}
+
+	if row.Email == "" || !strings.Contains(row.Email, "@") {
+		row.Err = "email is required"
+		return row
+	}
+	if v := values["isSystemAdmin"]; v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			row.Err = "invalid isSystemAdmin value; expected true or false"
+			return row
+		}
+		row.IsSystemAdmin = b
+	}
+	if row.Tenant == "" {
+		if row.Role != "" {
+			row.Err = "role requires a tenant"
+		}
+		return row
+	}
+	if row.Role == "" {
+		row.Role = "tenant_member"
+	}
+	if !isTenantRole(row.Role) {
+		row.Err = "role must be 'tenant_admin', 'tenant_member', or 'tenant_viewer'"
+	}
+	return row
+}
+
+// adminUserImportResult reports the outcome of one CSV row. Status is
+// "created" for a new user, "existing" when a user with the email already
+// existed, or "failed".
+type adminUserImportResult struct {
+	Line     int    `json:"line"`
+	Email    string `json:"email"`
+	Status   string `json:"status"`
+	UserID   string `json:"userId,omitempty"`
+	TenantID string `json:"tenantId,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type adminUserImportResponse struct {
+	Success  bool                    `json:"success"`
+	Code     string                  `json:"code,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+	DryRun   bool                    `json:"dryRun,omitempty"`
+	Created  int                     `json:"created"`
+	Existing int                     `json:"existing"`
+	Failed   int                     `json:"failed"`
+	Results  []adminUserImportResult `json:"results,omitempty"`
+}
+
+// adminImportUsersHandler implements POST /admin/users/import. The CSV is
+// sent as the request body or as the multipart file field "file". Each row
+// creates the user when the email is new (with a personal tenant, as
+// POST /admin/users does) and, when the row names a tenant, adds the user
+// to it. Rows are imported one transaction each, so a failed row does not
+// undo the others. ?dryRun=true validates the file and reports what would
+// happen without writing anything.
+func adminImportUsersHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	dryRun := false
+	if v := c.Query("dryRun"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(adminUserImportResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid dryRun value; expected true or false",
+			})
+		}
+		dryRun = parsed
+	}
+
+	var body io.Reader = bytes.NewReader(c.Body())
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(adminUserImportResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "Unable to read uploaded file",
+			})
+		}
+		defer f.Close()
+		body = f
+	}
+
+	rows, err := parseUserImportCSV(body)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(adminUserImportResponse{
+			Success: false,
+			Code:    "INVALID_CSV",
+			Error:   err.Error(),
+		})
+	}
+
+	tenants := newTenantRefCache(db.New(st.DB))
+	// In a dry run nothing is written, so remember the emails that would
+	// have been created to report later rows for them as existing.
+	pending := map[string]bool{}
+
+	resp := adminUserImportResponse{
+		Success: true,
+		DryRun:  dryRun,
+		Results: make([]adminUserImportResult, 0, len(rows)),
+	}
+	for _, row := range rows {
+		result := importUserRow(c, st, tenants, row, dryRun, pending)
+		switch result.Status {
+		case "created":
+			resp.Created++
+		case "existing":
+			resp.Existing++
+		default:
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	if !dryRun {
+		recordAuditEvent(c, st, "admin.user.import", auditEventOptions{
+			ResourceType: "user",
+			Metadata: map[string]any{
+				"rows":     len(rows),
+				"created":  resp.Created,
+				"existing": resp.Existing,
+				"failed":   resp.Failed,
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// importUserRow imports one CSV row and reports its outcome.
+func importUserRow(c *fiber.Ctx, st *store.Store, tenants *tenantRefCache, row userImportRow, dryRun bool, pending map[string]bool) adminUserImportResult {
+	ctx := c.Context()
+	result := adminUserImportResult{Line: row.Line, Email: row.Email}
+	fail := func(msg string) adminUserImportResult {
+		result.Status, result.Error = "failed", msg
+		return result
+	}
+	if row.Err != "" {
+		return fail(row.Err)
+	}
+
+	var tenant db.Tenant
+	if row.Tenant != "" {
+		t, err := tenants.lookup(ctx, row.Tenant)
+		if err != nil {
+			return fail(err.Error())
+		}
+		tenant = t
+		result.TenantID = t.ID.String()
+		result.Role = row.Role
+	}
+
+	user, err := db.New(st.DB).GetUserByEmail(ctx, row.Email)
+	exists := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fail(err.Error())
+	}
+	if !exists && !pending[row.Email] && row.Password == "" {
+		return fail("password is required for new users")
+	}
+
+	if dryRun {
+		result.Status = "existing"
+		if exists {
+			result.UserID = user.ID.String()
+		} else if !pending[row.Email] {
+			result.Status = "created"
+			pending[row.Email] = true
+		}
+		return result
+	}
+
+	var hash []byte
+	if !exists {
+		hash, err = bcrypt.GenerateFromPassword([]byte(row.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return fail("password hashing failed")
+		}
+	}
+
+	tx, err := st.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fail(err.Error())
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	q := db.New(tx)
+
+	var personalTenantID uuid.UUID
+	if !exists {
+		var nameVal sql.NullString
+		if row.Name != "" {
+			nameVal = sql.NullString{String: row.Name, Valid: true}
+		}
+		user, err = q.CreateUser(ctx, db.CreateUserParams{
+			ID:              uuid.New(),
+			Email:           row.Email,
+			Name:            nameVal,
+			AuthProvider:    "local",
+			AuthSubject:     sql.NullString{},
+			IsSystemAdmin:   row.IsSystemAdmin,
+			PasswordHash:    sql.NullString{String: string(hash), Valid: true},
+			PasswordVersion: sql.NullInt32{Int32: 1, Valid: true},
+		})
+		if err != nil {
+			return fail(err.Error())
+		}
+		if personalTenantID, err = createPersonalTenant(ctx, q, user); err != nil {
+			return fail(err.Error())
+		}
+	}
+
+	if row.Tenant != "" {
+		// AddTenantMember keeps the role of an existing membership; report
+		// the role the user actually has.
+		member, err := q.AddTenantMember(ctx, db.AddTenantMemberParams{
+			TenantID: tenant.ID,
+			UserID:   user.ID,
+			Role:     row.Role,
+		})
+		if err != nil {
+			return fail(fmt.Sprintf("tenant member create failed: %v", err))
+		}
+		result.Role = member.Role
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fail(err.Error())
+	}
+
+	result.UserID = user.ID.String()
+	result.Status = "existing"
+	if !exists {
+		result.Status = "created"
+		recordAuditEvent(c, st, "admin.user.create", auditEventOptions{
+			TenantID:     &personalTenantID,
+			ResourceType: "user",
+			ResourceID:   user.ID.String(),
+			Metadata: map[string]any{
+				"email":         user.Email,
+				"isSystemAdmin": user.IsSystemAdmin,
+				"source":        "import",
+			},
+		})
+	}
+	if row.Tenant != "" {
+		recordAuditEvent(c, st, "admin.tenant.member.add", auditEventOptions{
+			TenantID:     &tenant.ID,
+			ResourceType: "tenant_member",
+			ResourceID:   user.ID.String(),
+			Metadata: map[string]any{
+				"role":   result.Role,
+				"source": "import",
+			},
+		})
+	}
+	return result
+}
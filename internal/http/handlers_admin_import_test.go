@@ -0,0 +1,127 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/store"
+)
+
+func TestParseUserImportCSV(t *testing.T) {
+	csvData := "\ufeffEmail,Name,Password,Tenant,Role,is_system_admin\n" +
+		"Alice@Example.com,Alice,secret,acme,,\n" +
+		"bob@example.com,Bob,,acme,TENANT_ADMIN,false\n" +
+		"\n" +
+		"not-an-email,,,,,\n" +
+		"carol@example.com,,,,tenant_admin,\n" +
+		"dave@example.com,,pw,acme,owner,\n" +
+		"erin@example.com,,pw,,,maybe\n" +
+		"frank@example.com\n"
+
+	rows, err := parseUserImportCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("parseUserImportCSV: %v", err)
+	}
+	if len(rows) != 7 {
+		t.Fatalf("expected 7 rows, got %d", len(rows))
+	}
+
+	alice := rows[0]
+	if alice.Line != 2 || alice.Email != "alice@example.com" || alice.Role != "tenant_member" || alice.Err != "" {
+		t.Fatalf("unexpected first row: %+v", alice)
+	}
+	if rows[1].Role != "tenant_admin" || rows[1].Password != "" || rows[1].Err != "" {
+		t.Fatalf("unexpected second row: %+v", rows[1])
+	}
+	if rows[2].Line != 5 {
+		t.Fatalf("expected blank lines to keep line numbers, got %d", rows[2].Line)
+	}
+
+	wantErr := []string{"", "", "email is required", "role requires a tenant", "role must be", "invalid isSystemAdmin", ""}
+	for i, want := range wantErr {
+		if want == "" && rows[i].Err != "" || !strings.HasPrefix(rows[i].Err, want) {
+			t.Fatalf("row %d: err = %q, want prefix %q", i, rows[i].Err, want)
+		}
+	}
+	if rows[6].Email != "frank@example.com" || rows[6].Tenant != "" {
+		t.Fatalf("expected short records to leave missing columns empty: %+v", rows[6])
+	}
+}
+
+func TestParseUserImportCSV_FileErrors(t *testing.T) {
+	cases := map[string]string{
+		"":                              "csv is empty",
+		"email,team\na@example.com,x\n": "unknown column",
+		"email,Email\n":                 "duplicate column",
+		"name,tenant\nA,acme\n":         "missing required column",
+		"email\n":                       "no data rows",
+	}
+	for input, want := range cases {
+		_, err := parseUserImportCSV(strings.NewReader(input))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: expected error containing %q, got %v", input, want, err)
+		}
+	}
+
+	big := "email\n" + strings.Repeat("a@example.com\n", maxUserImportRows+1)
+	if _, err := parseUserImportCSV(strings.NewReader(big)); err == nil {
+		t.Fatalf("expected an error for more than %d rows", maxUserImportRows)
+	}
+}
+
+func TestAdminImportUsers_RejectsInvalidCSV(t *testing.T) {
+	app := fiber.New()
+	app.Post("/admin/users/import", func(c *fiber.Ctx) error {
+		c.Locals("store", &store.Store{})
+		return adminImportUsersHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/import", strings.NewReader("name\nAlice\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	var body adminUserImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != "INVALID_CSV" {
+		t.Fatalf("expected INVALID_CSV, got %q", body.Code)
+	}
+}
+
+func TestAdminBulkCreateAPIKeys_RejectsEmptyAndOversizedLists(t *testing.T) {
+	app := fiber.New()
+	app.Post("/admin/api-keys/bulk", func(c *fiber.Ctx) error {
+		c.Locals("store", &store.Store{})
+		return adminBulkCreateAPIKeysHandler(c)
+	})
+
+	items := make([]adminBulkAPIKeyItem, maxBulkAPIKeys+1)
+	for i := range items {
+		items[i].Label = "key"
+	}
+	oversized, _ := json.Marshal(adminBulkAPIKeysRequest{Keys: items})
+
+	for _, payload := range [][]byte{[]byte(`{"keys":[]}`), oversized} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/api-keys/bulk", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("app.Test error: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/plugins"
+)
+
+// PluginItem describes an installed format plugin.
+type PluginItem struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type PluginsResponse struct {
+	Success bool         `json:"success"`
+	Plugins []PluginItem `json:"plugins"`
+}
+
+// adminListPluginsHandler implements GET /admin/plugins, listing the
+// format plugins installed under plugins.formats.
+func adminListPluginsHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+
+	items := []PluginItem{}
+	for _, p := range plugins.NewRegistry(cfg.Plugins).Plugins() {
+		items = append(items, PluginItem{Name: p.Name, Type: p.Type})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(PluginsResponse{
+		Success: true,
+		Plugins: items,
+	})
+}
@@ -0,0 +1,229 @@
+package http
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+// AdminQueuePause is one paused job type; JobType "*" pauses every type.
+type AdminQueuePause struct {
+	JobType        string    `json:"jobType"`
+	Reason         string    `json:"reason,omitempty"`
+	PausedByUserID string    `json:"pausedByUserId,omitempty"`
+	PausedAt       time.Time `json:"pausedAt"`
+}
+
+type adminQueueResponse struct {
+	Success bool `json:"success"`
+	// Paused is true when every job type is paused.
+	Paused bool `json:"paused"`
+	// PausedTypes lists the job types workers currently skip.
+	PausedTypes []string          `json:"pausedTypes"`
+	Pauses      []AdminQueuePause `json:"pauses"`
+	// Pending counts queued jobs by type.
+	Pending map[string]int64 `json:"pending"`
+}
+
+// adminQueueRequest selects job types to pause or resume; no types means
+// all of them.
+type adminQueueRequest struct {
+	Types  []string `json:"types"`
+	Reason string   `json:"reason"`
+}
+
+// adminGetQueueHandler implements GET /admin/queue.
+func adminGetQueueHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	return sendAdminQueueState(c, db.New(st.DB))
+}
+
+// adminPauseQueueHandler implements POST /admin/queue/pause. Workers stop
+// claiming pending jobs of the paused types; running jobs finish normally.
+func adminPauseQueueHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	req, types, ok, err := parseAdminQueueRequest(c)
+	if !ok {
+		return err
+	}
+
+	p, _ := c.Locals("principal").(Principal)
+	pausedBy := uuid.NullUUID{}
+	if p.UserID != nil {
+		pausedBy = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+	reason := strings.TrimSpace(req.Reason)
+
+	q := db.New(st.DB)
+	for _, t := range types {
+		if _, err := q.PauseQueue(c.Context(), db.PauseQueueParams{
+			JobType:        t,
+			Reason:         sql.NullString{String: reason, Valid: reason != ""},
+			PausedByUserID: pausedBy,
+		}); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Success: false,
+				Code:    "QUEUE_PAUSE_FAILED",
+				Error:   err.Error(),
+			})
+		}
+	}
+
+	recordAuditEvent(c, st, "queue.pause", auditEventOptions{
+		Metadata: map[string]any{"types": types, "reason": reason},
+	})
+	return sendAdminQueueState(c, q)
+}
+
+// adminResumeQueueHandler implements POST /admin/queue/resume. Without
+// types it lifts every pause; otherwise only the pauses of those types, so
+// a type stays paused while "*" is.
+func adminResumeQueueHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	req, types, ok, err := parseAdminQueueRequest(c)
+	if !ok {
+		return err
+	}
+
+	q := db.New(st.DB)
+	if len(req.Types) == 0 {
+		_, err = q.ResumeAllQueues(c.Context())
+	} else {
+		for _, t := range types {
+			if _, err = q.ResumeQueue(c.Context(), t); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "QUEUE_RESUME_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	store.PublishJobQueued(c.Context(), "")
+
+	recordAuditEvent(c, st, "queue.resume", auditEventOptions{
+		Metadata: map[string]any{"types": types},
+	})
+	return sendAdminQueueState(c, q)
+}
+
+// parseAdminQueueRequest decodes an optional body and validates its job
+// types. An empty selection is returned as jobs.QueuePauseAll. When ok is
+// false the error response has been sent and err is its result.
+func parseAdminQueueRequest(c *fiber.Ctx) (req adminQueueRequest, types []string, ok bool, err error) {
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return req, nil, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST_INVALID_JSON",
+				Error:   "Bad request, malformed JSON",
+			})
+		}
+	}
+	types, err = normalizeQueueTypes(req.Types)
+	if err != nil {
+		return req, nil, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+	return req, types, true, nil
+}
+
+// normalizeQueueTypes validates and de-duplicates job types; no types
+// means every type.
+func normalizeQueueTypes(in []string) ([]string, error) {
+	if len(in) == 0 {
+		return []string{jobs.QueuePauseAll}, nil
+	}
+	seen := map[string]bool{}
+	out := make([]string, 0, len(in))
+	for _, t := range in {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != jobs.QueuePauseAll && !jobs.IsType(t) {
+			return nil, fmt.Errorf("unknown job type %q; expected one of %s, or %q for all", t, strings.Join(jobs.Types, ", "), jobs.QueuePauseAll)
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// pausedJobTypes expands queue pauses into the job types they cover.
+func pausedJobTypes(pauses []db.QueuePause) (all bool, types []string) {
+	paused := map[string]bool{}
+	for _, p := range pauses {
+		if p.JobType == jobs.QueuePauseAll {
+			all = true
+		}
+		paused[p.JobType] = true
+	}
+	types = []string{}
+	for _, t := range jobs.Types {
+		if all || paused[t] {
+			types = append(types, t)
+		}
+	}
+	return all, types
+}
+
+func sendAdminQueueState(c *fiber.Ctx, q *db.Queries) error {
+	pauses, err := q.ListQueuePauses(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "QUEUE_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	pending, err := q.CountPendingJobsByType(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "QUEUE_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	resp := adminQueueResponse{
+		Success: true,
+		Pauses:  make([]AdminQueuePause, 0, len(pauses)),
+		Pending: make(map[string]int64, len(jobs.Types)),
+	}
+	resp.Paused, resp.PausedTypes = pausedJobTypes(pauses)
+	for _, p := range pauses {
+		item := AdminQueuePause{
+			JobType:  p.JobType,
+			Reason:   p.Reason.String,
+			PausedAt: p.PausedAt,
+		}
+		if p.PausedByUserID.Valid {
+			item.PausedByUserID = p.PausedByUserID.UUID.String()
+		}
+		resp.Pauses = append(resp.Pauses, item)
+	}
+	for _, t := range jobs.Types {
+		resp.Pending[t] = 0
+	}
+	for _, row := range pending {
+		resp.Pending[row.Type] = row.Pending
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
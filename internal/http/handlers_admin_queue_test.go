@@ -0,0 +1,37 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"raito/internal/db"
+	"raito/internal/jobs"
+)
+
+func TestNormalizeQueueTypes(t *testing.T) {
+	got, err := normalizeQueueTypes(nil)
+	if err != nil || !reflect.DeepEqual(got, []string{"*"}) {
+		t.Fatalf("expected no types to mean all, got %v, %v", got, err)
+	}
+	got, err = normalizeQueueTypes([]string{" Crawl", "batch_scrape", "crawl"})
+	if err != nil || !reflect.DeepEqual(got, []string{"crawl", "batch_scrape"}) {
+		t.Fatalf("unexpected types %v, %v", got, err)
+	}
+	if _, err := normalizeQueueTypes([]string{"crawl", "render"}); err == nil {
+		t.Fatalf("expected unknown job type to be rejected")
+	}
+}
+
+func TestPausedJobTypes(t *testing.T) {
+	all, types := pausedJobTypes([]db.QueuePause{{JobType: "extract"}, {JobType: "crawl"}})
+	if all || !reflect.DeepEqual(types, []string{"crawl", "extract"}) {
+		t.Fatalf("unexpected paused types %v (all=%v)", types, all)
+	}
+	all, types = pausedJobTypes([]db.QueuePause{{JobType: "*"}})
+	if !all || !reflect.DeepEqual(types, jobs.Types) {
+		t.Fatalf("expected every type to be paused, got %v (all=%v)", types, all)
+	}
+	if _, types := pausedJobTypes(nil); len(types) != 0 {
+		t.Fatalf("expected nothing paused, got %v", types)
+	}
+}
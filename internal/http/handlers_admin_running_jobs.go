@@ -0,0 +1,92 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+// AdminRunningJob is an in-flight job as last reported by its worker.
+type AdminRunningJob struct {
+	ID              string     `json:"id"`
+	Type            string     `json:"type"`
+	URL             string     `json:"url"`
+	TenantID        string     `json:"tenantId,omitempty"`
+	Pool            string     `json:"pool"`
+	WorkerID        string     `json:"workerId,omitempty"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	LastHeartbeatAt *time.Time `json:"lastHeartbeatAt,omitempty"`
+	PagesDone       int64      `json:"pagesDone"`
+	// PagesTotal is omitted when the job does not know its size up front.
+	PagesTotal int64  `json:"pagesTotal,omitempty"`
+	CurrentURL string `json:"currentUrl,omitempty"`
+	// Stale is set when the job has no heartbeat or its worker has not
+	// reported for several heartbeat intervals.
+	Stale bool `json:"stale"`
+}
+
+type adminRunningJobsResponse struct {
+	Success bool              `json:"success"`
+	Jobs    []AdminRunningJob `json:"jobs"`
+}
+
+// adminListRunningJobsHandler lists jobs in the running state with the
+// worker, start time, and progress from their latest heartbeat.
+func adminListRunningJobsHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	rows, err := db.New(st.DB).ListRunningJobs(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_LIST_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	staleBefore := time.Now().Add(-jobs.DeadAfter(cfg))
+
+	out := make([]AdminRunningJob, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, marshalRunningJob(row, staleBefore))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(adminRunningJobsResponse{
+		Success: true,
+		Jobs:    out,
+	})
+}
+
+// marshalRunningJob converts a running job row into an AdminRunningJob,
+// marking it stale when its last heartbeat is older than staleBefore.
+func marshalRunningJob(row db.ListRunningJobsRow, staleBefore time.Time) AdminRunningJob {
+	job := AdminRunningJob{
+		ID:         row.ID.String(),
+		Type:       row.Type,
+		URL:        row.Url,
+		Pool:       row.Pool,
+		WorkerID:   row.WorkerID.String,
+		PagesDone:  row.PagesDone.Int64,
+		PagesTotal: row.PagesTotal.Int64,
+		CurrentURL: row.CurrentUrl.String,
+		Stale:      !row.HeartbeatAt.Valid || row.HeartbeatAt.Time.Before(staleBefore),
+	}
+	if row.TenantID.Valid {
+		job.TenantID = row.TenantID.UUID.String()
+	}
+	if row.StartedAt.Valid {
+		t := row.StartedAt.Time
+		job.StartedAt = &t
+	}
+	if row.HeartbeatAt.Valid {
+		t := row.HeartbeatAt.Time
+		job.LastHeartbeatAt = &t
+	}
+	return job
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+)
+
+func TestMarshalRunningJob_Stale(t *testing.T) {
+	now := time.Now()
+	staleBefore := now.Add(-15 * time.Second)
+
+	fresh := marshalRunningJob(db.ListRunningJobsRow{
+		ID:          uuid.New(),
+		Type:        "crawl",
+		Pool:        "default",
+		WorkerID:    sql.NullString{String: "node-1-42", Valid: true},
+		StartedAt:   sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+		HeartbeatAt: sql.NullTime{Time: now, Valid: true},
+		PagesDone:   sql.NullInt64{Int64: 3, Valid: true},
+		PagesTotal:  sql.NullInt64{Int64: 10, Valid: true},
+		CurrentUrl:  sql.NullString{String: "https://example.com/docs", Valid: true},
+	}, staleBefore)
+	if fresh.Stale {
+		t.Fatalf("expected fresh heartbeat not to be stale")
+	}
+	if fresh.WorkerID != "node-1-42" || fresh.PagesDone != 3 || fresh.PagesTotal != 10 || fresh.CurrentURL != "https://example.com/docs" {
+		t.Fatalf("unexpected progress: %+v", fresh)
+	}
+
+	old := marshalRunningJob(db.ListRunningJobsRow{
+		ID:          uuid.New(),
+		HeartbeatAt: sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+	}, staleBefore)
+	if !old.Stale {
+		t.Fatalf("expected old heartbeat to be stale")
+	}
+
+	missing := marshalRunningJob(db.ListRunningJobsRow{ID: uuid.New()}, staleBefore)
+	if !missing.Stale || missing.StartedAt != nil || missing.LastHeartbeatAt != nil {
+		t.Fatalf("expected job without heartbeat to be stale with no times: %+v", missing)
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+type AdminSession struct {
+	ID         string    `json:"id"`
+	RememberMe bool      `json:"rememberMe"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+}
+
+type adminSessionsResponse struct {
+	Success  bool           `json:"success"`
+	Sessions []AdminSession `json:"sessions"`
+}
+
+type adminRevokeSessionsResponse struct {
+	Success bool  `json:"success"`
+	Revoked int64 `json:"revoked"`
+}
+
+// adminListUserSessionsHandler lists a user's active browser sessions.
+func adminListUserSessionsHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid user id",
+		})
+	}
+
+	rows, err := q.ListActiveSessionsForUser(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "INTERNAL_ERROR",
+			Error:   err.Error(),
+		})
+	}
+
+	sessions := make([]AdminSession, 0, len(rows))
+	for _, s := range rows {
+		sessions = append(sessions, AdminSession{
+			ID:         s.ID.String(),
+			RememberMe: s.RememberMe,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			IP:         s.Ip.String,
+			UserAgent:  s.UserAgent.String,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(adminSessionsResponse{
+		Success:  true,
+		Sessions: sessions,
+	})
+}
+
+// adminRevokeUserSessionsHandler force-logs-out a user by revoking all of
+// their server-side sessions.
+func adminRevokeUserSessionsHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid user id",
+		})
+	}
+
+	revoked, err := q.RevokeSessionsForUser(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "INTERNAL_ERROR",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "admin.user.sessions.revoke", auditEventOptions{
+		ResourceType: "user",
+		ResourceID:   userID.String(),
+		Metadata: map[string]any{
+			"revoked": revoked,
+		},
+	})
+
+	return c.Status(fiber.StatusOK).JSON(adminRevokeSessionsResponse{
+		Success: true,
+		Revoked: revoked,
+	})
+}
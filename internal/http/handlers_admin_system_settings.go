@@ -24,6 +24,7 @@ type adminSystemSettingsSecrets struct {
 }
 
 type adminSystemSettingsConfig struct {
+	Server    adminServerConfig    `json:"server"`
 	Scraper   adminScraperConfig   `json:"scraper"`
 	Crawler   adminCrawlerConfig   `json:"crawler"`
 	Robots    adminRobotsConfig    `json:"robots"`
@@ -44,6 +45,28 @@ type adminSystemSettingsResponse struct {
 	Notes      []string                   `json:"notes,omitempty"`
 }
 
+type adminValidateSystemSettingsResponse struct {
+	Success bool           `json:"success"`
+	Valid   bool           `json:"valid"`
+	Issues  []config.Issue `json:"issues"`
+}
+
+type adminServerConfig struct {
+	ReadTimeoutMs  int                      `json:"readTimeoutMs"`
+	WriteTimeoutMs int                      `json:"writeTimeoutMs"`
+	IdleTimeoutMs  int                      `json:"idleTimeoutMs"`
+	BodyLimitBytes int                      `json:"bodyLimitBytes"`
+	Concurrency    int                      `json:"concurrency"`
+	Routes         []adminServerRouteConfig `json:"routes"`
+}
+
+type adminServerRouteConfig struct {
+	Method         string `json:"method,omitempty"`
+	Path           string `json:"path"`
+	TimeoutMs      int    `json:"timeoutMs"`
+	BodyLimitBytes int    `json:"bodyLimitBytes"`
+}
+
 type adminScraperConfig struct {
 	UserAgent           string `json:"userAgent"`
 	TimeoutMs           int    `json:"timeoutMs"`
@@ -54,10 +77,15 @@ type adminScraperConfig struct {
 type adminCrawlerConfig struct {
 	MaxDepthDefault int `json:"maxDepthDefault"`
 	MaxPagesDefault int `json:"maxPagesDefault"`
+	DelayMs         int `json:"delayMs"`
 }
 
 type adminRobotsConfig struct {
-	Respect bool `json:"respect"`
+	Respect         bool   `json:"respect"`
+	UserAgent       string `json:"userAgent"`
+	ContactURL      string `json:"contactUrl"`
+	MinCrawlDelayMs int    `json:"minCrawlDelayMs"`
+	MaxCrawlDelayMs int    `json:"maxCrawlDelayMs"`
 }
 
 type adminRodConfig struct {
@@ -141,6 +169,7 @@ type adminGoogleLLMConfig struct {
 }
 
 type systemSettingsPatchRequest struct {
+	Server    *serverConfigPatch    `json:"server,omitempty"`
 	Scraper   *scraperConfigPatch   `json:"scraper,omitempty"`
 	Crawler   *crawlerConfigPatch   `json:"crawler,omitempty"`
 	Robots    *robotsConfigPatch    `json:"robots,omitempty"`
@@ -153,6 +182,15 @@ type systemSettingsPatchRequest struct {
 	LLM    *llmConfigPatch    `json:"llm,omitempty"`
 }
 
+type serverConfigPatch struct {
+	ReadTimeoutMs  *int                      `json:"readTimeoutMs,omitempty"`
+	WriteTimeoutMs *int                      `json:"writeTimeoutMs,omitempty"`
+	IdleTimeoutMs  *int                      `json:"idleTimeoutMs,omitempty"`
+	BodyLimitBytes *int                      `json:"bodyLimitBytes,omitempty"`
+	Concurrency    *int                      `json:"concurrency,omitempty"`
+	Routes         *[]adminServerRouteConfig `json:"routes,omitempty"`
+}
+
 type scraperConfigPatch struct {
 	UserAgent           *string `json:"userAgent,omitempty"`
 	TimeoutMs           *int    `json:"timeoutMs,omitempty"`
@@ -163,10 +201,15 @@ type scraperConfigPatch struct {
 type crawlerConfigPatch struct {
 	MaxDepthDefault *int `json:"maxDepthDefault,omitempty"`
 	MaxPagesDefault *int `json:"maxPagesDefault,omitempty"`
+	DelayMs         *int `json:"delayMs,omitempty"`
 }
 
 type robotsConfigPatch struct {
-	Respect *bool `json:"respect,omitempty"`
+	Respect         *bool   `json:"respect,omitempty"`
+	UserAgent       *string `json:"userAgent,omitempty"`
+	ContactURL      *string `json:"contactUrl,omitempty"`
+	MinCrawlDelayMs *int    `json:"minCrawlDelayMs,omitempty"`
+	MaxCrawlDelayMs *int    `json:"maxCrawlDelayMs,omitempty"`
 }
 
 type rodConfigPatch struct {
@@ -289,7 +332,7 @@ func adminUpdateSystemSettingsHandler(c *fiber.Ctx) error {
 	next := *cfg
 	applySystemSettingsPatch(&next, &req)
 
-	if err := validateSystemSettings(&next); err != nil {
+	if err := next.Validate(); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Success: false,
 			Code:    "BAD_REQUEST",
@@ -322,7 +365,25 @@ func adminUpdateSystemSettingsHandler(c *fiber.Ctx) error {
 }
 
 func redactedSystemSettingsConfig(cfg *config.Config) adminSystemSettingsConfig {
+	routes := make([]adminServerRouteConfig, 0, len(cfg.Server.Routes))
+	for _, r := range cfg.Server.Routes {
+		routes = append(routes, adminServerRouteConfig{
+			Method:         r.Method,
+			Path:           r.Path,
+			TimeoutMs:      r.TimeoutMs,
+			BodyLimitBytes: r.BodyLimitBytes,
+		})
+	}
+
 	c := adminSystemSettingsConfig{
+		Server: adminServerConfig{
+			ReadTimeoutMs:  cfg.Server.ReadTimeoutMs,
+			WriteTimeoutMs: cfg.Server.WriteTimeoutMs,
+			IdleTimeoutMs:  cfg.Server.IdleTimeoutMs,
+			BodyLimitBytes: cfg.Server.BodyLimitBytes,
+			Concurrency:    cfg.Server.Concurrency,
+			Routes:         routes,
+		},
 		Scraper: adminScraperConfig{
 			UserAgent:           cfg.Scraper.UserAgent,
 			TimeoutMs:           cfg.Scraper.TimeoutMs,
@@ -332,9 +393,14 @@ func redactedSystemSettingsConfig(cfg *config.Config) adminSystemSettingsConfig
 		Crawler: adminCrawlerConfig{
 			MaxDepthDefault: cfg.Crawler.MaxDepthDefault,
 			MaxPagesDefault: cfg.Crawler.MaxPagesDefault,
+			DelayMs:         cfg.Crawler.DelayMs,
 		},
 		Robots: adminRobotsConfig{
-			Respect: cfg.Robots.Respect,
+			Respect:         cfg.Robots.Respect,
+			UserAgent:       cfg.Robots.UserAgent,
+			ContactURL:      cfg.Robots.ContactURL,
+			MinCrawlDelayMs: cfg.Robots.MinCrawlDelayMs,
+			MaxCrawlDelayMs: cfg.Robots.MaxCrawlDelayMs,
 		},
 		Rod: adminRodConfig{
 			Enabled: cfg.Rod.Enabled,
@@ -429,6 +495,36 @@ func applySystemSettingsPatch(cfg *config.Config, req *systemSettingsPatchReques
 		return
 	}
 
+	if req.Server != nil {
+		if req.Server.ReadTimeoutMs != nil {
+			cfg.Server.ReadTimeoutMs = *req.Server.ReadTimeoutMs
+		}
+		if req.Server.WriteTimeoutMs != nil {
+			cfg.Server.WriteTimeoutMs = *req.Server.WriteTimeoutMs
+		}
+		if req.Server.IdleTimeoutMs != nil {
+			cfg.Server.IdleTimeoutMs = *req.Server.IdleTimeoutMs
+		}
+		if req.Server.BodyLimitBytes != nil {
+			cfg.Server.BodyLimitBytes = *req.Server.BodyLimitBytes
+		}
+		if req.Server.Concurrency != nil {
+			cfg.Server.Concurrency = *req.Server.Concurrency
+		}
+		if req.Server.Routes != nil {
+			routes := make([]config.ServerRouteConfig, 0, len(*req.Server.Routes))
+			for _, r := range *req.Server.Routes {
+				routes = append(routes, config.ServerRouteConfig{
+					Method:         strings.ToUpper(strings.TrimSpace(r.Method)),
+					Path:           strings.TrimSpace(r.Path),
+					TimeoutMs:      r.TimeoutMs,
+					BodyLimitBytes: r.BodyLimitBytes,
+				})
+			}
+			cfg.Server.Routes = routes
+		}
+	}
+
 	if req.Scraper != nil {
 		if req.Scraper.UserAgent != nil {
 			cfg.Scraper.UserAgent = *req.Scraper.UserAgent
@@ -451,12 +547,27 @@ func applySystemSettingsPatch(cfg *config.Config, req *systemSettingsPatchReques
 		if req.Crawler.MaxPagesDefault != nil {
 			cfg.Crawler.MaxPagesDefault = *req.Crawler.MaxPagesDefault
 		}
+		if req.Crawler.DelayMs != nil {
+			cfg.Crawler.DelayMs = *req.Crawler.DelayMs
+		}
 	}
 
 	if req.Robots != nil {
 		if req.Robots.Respect != nil {
 			cfg.Robots.Respect = *req.Robots.Respect
 		}
+		if req.Robots.UserAgent != nil {
+			cfg.Robots.UserAgent = strings.TrimSpace(*req.Robots.UserAgent)
+		}
+		if req.Robots.ContactURL != nil {
+			cfg.Robots.ContactURL = strings.TrimSpace(*req.Robots.ContactURL)
+		}
+		if req.Robots.MinCrawlDelayMs != nil {
+			cfg.Robots.MinCrawlDelayMs = *req.Robots.MinCrawlDelayMs
+		}
+		if req.Robots.MaxCrawlDelayMs != nil {
+			cfg.Robots.MaxCrawlDelayMs = *req.Robots.MaxCrawlDelayMs
+		}
 	}
 
 	if req.Rod != nil {
@@ -592,53 +703,69 @@ func applySystemSettingsPatch(cfg *config.Config, req *systemSettingsPatchReques
 	}
 }
 
-func validateSystemSettings(cfg *config.Config) error {
-	if cfg.Scraper.TimeoutMs < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "scraper.timeoutMs must be >= 0")
-	}
-	if cfg.Scraper.LinksMaxPerDocument < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "scraper.linksMaxPerDocument must be >= 0")
-	}
-	if cfg.Crawler.MaxDepthDefault < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "crawler.maxDepthDefault must be >= 0")
-	}
-	if cfg.Crawler.MaxPagesDefault < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "crawler.maxPagesDefault must be >= 0")
-	}
-	if cfg.RateLimit.DefaultPerMinute < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "ratelimit.defaultPerMinute must be >= 0")
-	}
-	if cfg.Worker.MaxConcurrentJobs < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "worker.maxConcurrentJobs must be >= 0")
-	}
-	if cfg.Worker.PollIntervalMs < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "worker.pollIntervalMs must be >= 0")
-	}
-	if cfg.Worker.MaxConcurrentURLsPerJob < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "worker.maxConcurrentUrlsPerJob must be >= 0")
-	}
-	if cfg.Worker.SyncJobWaitTimeoutMs < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "worker.syncJobWaitTimeoutMs must be >= 0")
-	}
-	if cfg.Auth.Session.TTLMinutes < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "auth.session.ttlMinutes must be >= 0")
-	}
-	if cfg.Search.MaxResults < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "search.maxResults must be >= 0")
-	}
-	if cfg.Search.TimeoutMs < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "search.timeoutMs must be >= 0")
-	}
-	if cfg.Search.MaxConcurrentScrapes < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "search.maxConcurrentScrapes must be >= 0")
+// adminValidateSystemSettingsHandler checks the config file on disk
+// (GET) or the result of applying a settings patch (POST) without saving
+// anything, so the UI can show problems before a save.
+func adminValidateSystemSettingsHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+
+	var issues []config.Issue
+	if c.Method() == fiber.MethodPost {
+		var req systemSettingsPatchRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST_INVALID_JSON",
+				Error:   "Bad request, malformed JSON",
+			})
+		}
+		next := *cfg
+		applySystemSettingsPatch(&next, &req)
+		issues = next.Check()
+	} else {
+		target := cfg
+		if strings.TrimSpace(cfg.Path) != "" {
+			data, err := os.ReadFile(cfg.Path)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+					Success: false,
+					Code:    "SYSTEM_SETTINGS_UNAVAILABLE",
+					Error:   err.Error(),
+				})
+			}
+			parsed, unknown, err := config.Parse(data)
+			if err != nil {
+				return c.Status(fiber.StatusOK).JSON(adminValidateSystemSettingsResponse{
+					Success: true,
+					Valid:   false,
+					Issues: []config.Issue{{
+						Message:  "config file is not valid YAML: " + err.Error(),
+						Severity: config.SeverityError,
+					}},
+				})
+			}
+			issues = unknown
+			target = parsed
+		}
+		issues = append(issues, target.Check()...)
 	}
-	if cfg.Search.Searxng.DefaultLimit < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "search.searxng.defaultLimit must be >= 0")
+
+	valid := true
+	for _, is := range issues {
+		if is.Severity == config.SeverityError {
+			valid = false
+			break
+		}
 	}
-	if cfg.Search.Searxng.TimeoutMs < 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "search.searxng.timeoutMs must be >= 0")
+	if issues == nil {
+		issues = []config.Issue{}
 	}
-	return nil
+
+	return c.Status(fiber.StatusOK).JSON(adminValidateSystemSettingsResponse{
+		Success: true,
+		Valid:   valid,
+		Issues:  issues,
+	})
 }
 
 func writeConfigYAMLAtomic(path string, cfg *config.Config) error {
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+)
+
+func TestValidateSystemSettings_Patch(t *testing.T) {
+	cfg := &config.Config{
+		Auth:     config.AuthConfig{Enabled: true},
+		Database: config.DatabaseConfig{DSN: "postgres://localhost/raito"},
+		LLM: config.LLMConfig{
+			DefaultProvider: "openai",
+			OpenAI:          config.OpenAIConfig{APIKey: "sk-test", Model: "gpt-4o-mini"},
+		},
+	}
+	app := fiber.New()
+	app.Post("/admin/system-settings/validate", func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		return adminValidateSystemSettingsHandler(c)
+	})
+
+	body := `{"worker":{"maxConcurrentJobs":-1},"search":{"enabled":true}}`
+	req := httptest.NewRequest(http.MethodPost, "/admin/system-settings/validate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var out adminValidateSystemSettingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Valid {
+		t.Fatalf("expected invalid settings, got %+v", out)
+	}
+	paths := map[string]bool{}
+	for _, is := range out.Issues {
+		paths[is.Path] = true
+	}
+	if !paths["worker.maxConcurrentJobs"] || !paths["search.searxng.baseURL"] {
+		t.Fatalf("expected worker and search issues, got %+v", out.Issues)
+	}
+	if cfg.Worker.MaxConcurrentJobs != 0 || cfg.Search.Enabled {
+		t.Fatalf("validate must not modify the running config")
+	}
+}
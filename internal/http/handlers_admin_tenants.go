@@ -157,11 +157,11 @@ func adminCreateTenantHandler(c *fiber.Ctx) error {
 		if role == "" {
 			role = "tenant_member"
 		}
-		if role != "tenant_admin" && role != "tenant_member" {
+		if !isTenantRole(role) {
 			return c.Status(fiber.StatusBadRequest).JSON(AdminTenantResponse{
 				Success: false,
 				Code:    "BAD_REQUEST",
-				Error:   "member role must be 'tenant_admin' or 'tenant_member'",
+				Error:   "member role must be 'tenant_admin', 'tenant_member', or 'tenant_viewer'",
 			})
 		}
 		if _, err := q.AddTenantMember(c.Context(), db.AddTenantMemberParams{
@@ -598,11 +598,11 @@ func adminAddTenantMemberHandler(c *fiber.Ctx) error {
 	}
 
 	role := strings.ToLower(strings.TrimSpace(req.Role))
-	if role != "tenant_admin" && role != "tenant_member" {
+	if !isTenantRole(role) {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Success: false,
 			Code:    "BAD_REQUEST",
-			Error:   "role must be 'tenant_admin' or 'tenant_member'",
+			Error:   "role must be 'tenant_admin', 'tenant_member', or 'tenant_viewer'",
 		})
 	}
 
@@ -667,11 +667,11 @@ func adminUpdateTenantMemberHandler(c *fiber.Ctx) error {
 	}
 
 	role := strings.ToLower(strings.TrimSpace(req.Role))
-	if role != "tenant_admin" && role != "tenant_member" {
+	if !isTenantRole(role) {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Success: false,
 			Code:    "BAD_REQUEST",
-			Error:   "role must be 'tenant_admin' or 'tenant_member'",
+			Error:   "role must be 'tenant_admin', 'tenant_member', or 'tenant_viewer'",
 		})
 	}
 
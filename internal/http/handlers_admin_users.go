@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -168,33 +169,12 @@ func adminCreateUserHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	tenantID := uuid.New()
-	slug := generatePersonalTenantSlug(email, tenantID)
-	_, err = q.CreateTenant(c.Context(), db.CreateTenantParams{
-		ID:          tenantID,
-		Slug:        slug,
-		Name:        email,
-		Type:        "personal",
-		OwnerUserID: uuid.NullUUID{UUID: userID, Valid: true},
-	})
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Success: false,
-			Code:    "INTERNAL_ERROR",
-			Error:   fmt.Sprintf("personal tenant create failed: %v", err),
-		})
-	}
-
-	_, err = q.AddTenantMember(c.Context(), db.AddTenantMemberParams{
-		TenantID: tenantID,
-		UserID:   userID,
-		Role:     "tenant_admin",
-	})
+	tenantID, err := createPersonalTenant(c.Context(), q, user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Success: false,
 			Code:    "INTERNAL_ERROR",
-			Error:   fmt.Sprintf("tenant member create failed: %v", err),
+			Error:   err.Error(),
 		})
 	}
 
@@ -222,6 +202,32 @@ func adminCreateUserHandler(c *fiber.Ctx) error {
 	})
 }
 
+// createPersonalTenant creates the personal tenant of a newly created user
+// and makes the user its tenant admin.
+func createPersonalTenant(ctx context.Context, q *db.Queries, user db.User) (uuid.UUID, error) {
+	tenantID := uuid.New()
+	_, err := q.CreateTenant(ctx, db.CreateTenantParams{
+		ID:          tenantID,
+		Slug:        generatePersonalTenantSlug(user.Email, tenantID),
+		Name:        user.Email,
+		Type:        "personal",
+		OwnerUserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("personal tenant create failed: %v", err)
+	}
+
+	_, err = q.AddTenantMember(ctx, db.AddTenantMemberParams{
+		TenantID: tenantID,
+		UserID:   user.ID,
+		Role:     "tenant_admin",
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("tenant member create failed: %v", err)
+	}
+	return tenantID, nil
+}
+
 type adminResetPasswordRequest struct {
 	Password string `json:"password"`
 }
@@ -532,6 +538,12 @@ func adminUpdateUserHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Privilege changes invalidate existing sessions so the new admin bit or
+	// disabled state takes effect on a freshly issued session.
+	if updated.IsSystemAdmin != user.IsSystemAdmin || (updated.IsDisabled && !user.IsDisabled) {
+		_, _ = q.RevokeSessionsForUser(c.Context(), userID)
+	}
+
 	recordAuditEvent(c, st, "admin.user.update", auditEventOptions{
 		ResourceType: "user",
 		ResourceID:   updated.ID.String(),
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+// AdminWorker is a registered worker process and its last heartbeat.
+type AdminWorker struct {
+	ID              string                  `json:"id"`
+	Hostname        string                  `json:"hostname"`
+	Version         string                  `json:"version"`
+	Capabilities    jobs.WorkerCapabilities `json:"capabilities"`
+	StartedAt       time.Time               `json:"startedAt"`
+	LastHeartbeatAt time.Time               `json:"lastHeartbeatAt"`
+	RunningJobs     int64                   `json:"runningJobs"`
+	// Alive is false once the worker has missed several heartbeats; its
+	// running jobs are then failed with WORKER_LOST.
+	Alive bool `json:"alive"`
+}
+
+type adminWorkersResponse struct {
+	Success bool          `json:"success"`
+	Workers []AdminWorker `json:"workers"`
+}
+
+// adminListWorkersHandler lists registered workers so operators can see
+// fleet health and spot dead workers.
+func adminListWorkersHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	rows, err := db.New(st.DB).ListWorkers(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "WORKER_LIST_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	deadBefore := time.Now().Add(-jobs.DeadAfter(cfg))
+	out := make([]AdminWorker, 0, len(rows))
+	for _, row := range rows {
+		w := AdminWorker{
+			ID:              row.ID,
+			Hostname:        row.Hostname,
+			Version:         row.Version,
+			StartedAt:       row.StartedAt,
+			LastHeartbeatAt: row.LastHeartbeatAt,
+			RunningJobs:     row.RunningJobs,
+			Alive:           !row.LastHeartbeatAt.Before(deadBefore),
+		}
+		_ = json.Unmarshal(row.Capabilities, &w.Capabilities)
+		out = append(out, w)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(adminWorkersResponse{
+		Success: true,
+		Workers: out,
+	})
+}
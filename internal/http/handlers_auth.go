@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"database/sql"
 	"strings"
 	"time"
@@ -16,11 +17,15 @@ import (
 	"raito/internal/store"
 )
 
-const oidcStateCookieName = "raito_oidc_state"
+const (
+	oidcStateCookieName      = "raito_oidc_state"
+	oidcRememberMeCookieName = "raito_oidc_remember"
+)
 
 type LocalLoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	RememberMe bool   `json:"rememberMe,omitempty"`
 }
 
 type LocalLoginResponse struct {
@@ -28,6 +33,9 @@ type LocalLoginResponse struct {
 	Code       string `json:"code,omitempty"`
 	Error      string `json:"error,omitempty"`
 	FirstLogin bool   `json:"firstLogin,omitempty"`
+	// MustChangePassword tells the client to send the user to the password
+	// change flow (POST /v1/me/password) before anything else.
+	MustChangePassword bool `json:"mustChangePassword,omitempty"`
 }
 
 type OIDCLoginResponse struct {
@@ -98,53 +106,29 @@ func loginHandler(c *fiber.Ctx) error {
 	}
 
 	// Issue a browser session cookie for UI clients.
-	var defaultTenantID *uuid.UUID
-	{
-		q := db.New(st.DB)
-		if res.User.DefaultTenantID.Valid {
-			id := res.User.DefaultTenantID.UUID
-			// Ensure the user is a member of this tenant (defense-in-depth).
-			if _, err := q.GetTenantMember(c.Context(), db.GetTenantMemberParams{
-				TenantID: id,
-				UserID:   res.User.ID,
-			}); err == nil {
-				defaultTenantID = &id
-			}
-		}
-		if defaultTenantID == nil {
-			personalTenants, err := q.ListPersonalTenantsForUser(c.Context(), uuid.NullUUID{UUID: res.User.ID, Valid: true})
-			if err == nil && len(personalTenants) > 0 {
-				id := personalTenants[0].ID
-				defaultTenantID = &id
-			}
-		}
-	}
-	_ = issueSessionCookie(c, cfg, res.User.ID, defaultTenantID, res.User.IsSystemAdmin)
+	defaultTenantID := resolveDefaultTenant(c.Context(), db.New(st.DB), res.User)
+	_ = issueSessionCookie(c, cfg, res.User.ID, defaultTenantID, res.User.IsSystemAdmin, req.RememberMe)
 
 	return c.Status(fiber.StatusOK).JSON(LocalLoginResponse{
-		Success:    true,
-		FirstLogin: res.FirstLogin,
+		Success:            true,
+		FirstLogin:         res.FirstLogin,
+		MustChangePassword: res.User.MustChangePassword,
 	})
 }
 
 func logoutHandler(c *fiber.Ctx) error {
-	// Stateless placeholder for now; when a session/JWT mechanism is
-	// introduced, this can be extended to revoke tokens or clear
-	// server-side sessions.
-	// Clear session cookie for browser clients.
 	cfg := c.Locals("config").(*config.Config)
-	name := cfg.Auth.Session.CookieName
-	if name == "" {
-		name = "raito_session"
+
+	// Revoke the server-side session record (if any) so the token cannot be
+	// replayed, then clear the cookie for browser clients.
+	if st := sessionStore(c); st != nil {
+		if claims, err := parseSessionFromRequest(c, cfg); err == nil && claims.ID != "" {
+			if sessionID, err := uuid.Parse(claims.ID); err == nil {
+				_ = db.New(st.DB).RevokeSession(c.Context(), sessionID)
+			}
+		}
 	}
-	c.Cookie(&fiber.Cookie{
-		Name:     name,
-		Value:    "",
-		Expires:  time.Now().Add(-1 * time.Hour),
-		HTTPOnly: true,
-		Secure:   true,
-		SameSite: "Lax",
-	})
+	clearSessionCookie(c, cfg)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -192,6 +176,17 @@ func oidcLoginStartHandler(c *fiber.Ctx) error {
 		SameSite: "Lax",
 	})
 
+	if c.Query("rememberMe") == "true" {
+		c.Cookie(&fiber.Cookie{
+			Name:     oidcRememberMeCookieName,
+			Value:    "1",
+			Expires:  time.Now().Add(10 * time.Minute),
+			HTTPOnly: true,
+			Secure:   true,
+			SameSite: "Lax",
+		})
+	}
+
 	authURL := oauthCfg.AuthCodeURL(state, oauth2.AccessTypeOnline)
 	return c.Redirect(authURL, fiber.StatusFound)
 }
@@ -229,15 +224,19 @@ func oidcCallbackHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Clear the state cookie.
-	c.Cookie(&fiber.Cookie{
-		Name:     oidcStateCookieName,
-		Value:    "",
-		Expires:  time.Now().Add(-1 * time.Hour),
-		HTTPOnly: true,
-		Secure:   true,
-		SameSite: "Lax",
-	})
+	rememberMe := c.Cookies(oidcRememberMeCookieName) == "1"
+
+	// Clear the state and remember-me cookies.
+	for _, name := range []string{oidcStateCookieName, oidcRememberMeCookieName} {
+		c.Cookie(&fiber.Cookie{
+			Name:     name,
+			Value:    "",
+			Expires:  time.Now().Add(-1 * time.Hour),
+			HTTPOnly: true,
+			Secure:   true,
+			SameSite: "Lax",
+		})
+	}
 
 	authSvc := services.NewAuthService(cfg, st)
 	res, err := authSvc.LoginOIDC(c.Context(), code, state)
@@ -283,27 +282,8 @@ func oidcCallbackHandler(c *fiber.Ctx) error {
 	}
 
 	// Issue a browser session cookie for UI clients.
-	var defaultTenantID *uuid.UUID
-	{
-		q := db.New(st.DB)
-		if res.User.DefaultTenantID.Valid {
-			id := res.User.DefaultTenantID.UUID
-			if _, err := q.GetTenantMember(c.Context(), db.GetTenantMemberParams{
-				TenantID: id,
-				UserID:   res.User.ID,
-			}); err == nil {
-				defaultTenantID = &id
-			}
-		}
-		if defaultTenantID == nil {
-			personalTenants, err := q.ListPersonalTenantsForUser(c.Context(), uuid.NullUUID{UUID: res.User.ID, Valid: true})
-			if err == nil && len(personalTenants) > 0 {
-				id := personalTenants[0].ID
-				defaultTenantID = &id
-			}
-		}
-	}
-	_ = issueSessionCookie(c, cfg, res.User.ID, defaultTenantID, res.User.IsSystemAdmin)
+	defaultTenantID := resolveDefaultTenant(c.Context(), db.New(st.DB), res.User)
+	_ = issueSessionCookie(c, cfg, res.User.ID, defaultTenantID, res.User.IsSystemAdmin, rememberMe)
 
 	// Browser-based OIDC flows should land back on the dashboard instead of
 	// stopping on a JSON response.
@@ -319,3 +299,25 @@ func oidcCallbackHandler(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(OIDCLoginResponse{Success: true, FirstLogin: res.FirstLogin})
 }
+
+// resolveDefaultTenant picks the tenant a new session or user-bound API key
+// starts in: the user's sticky default tenant (users.default_tenant_id)
+// while they are still a member of it, otherwise their personal tenant.
+func resolveDefaultTenant(ctx context.Context, q *db.Queries, user db.User) *uuid.UUID {
+	if user.DefaultTenantID.Valid {
+		id := user.DefaultTenantID.UUID
+		// Ensure the user is still a member of this tenant (defense-in-depth).
+		if _, err := q.GetTenantMember(ctx, db.GetTenantMemberParams{
+			TenantID: id,
+			UserID:   user.ID,
+		}); err == nil {
+			return &id
+		}
+	}
+	personalTenants, err := q.ListPersonalTenantsForUser(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err == nil && len(personalTenants) > 0 {
+		id := personalTenants[0].ID
+		return &id
+	}
+	return nil
+}
@@ -2,13 +2,15 @@ package http
 
 import (
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"raito/internal/config"
+	"raito/internal/jobinput"
+	"raito/internal/jobs"
 	"raito/internal/services"
 	"raito/internal/store"
 )
@@ -25,6 +27,18 @@ func batchScrapeHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	collectionID, code, msg := applyCollectionDefaults(c, reqBody.CollectionID, &reqBody)
+	if code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(BatchScrapeResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
+	if _, err := applyDefaultFormats(c, &reqBody.Formats); err != nil {
+		return defaultFormatsLookupFailed(c, err)
+	}
+
 	if len(reqBody.URLs) == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(BatchScrapeResponse{
 			Success: false,
@@ -42,6 +56,40 @@ func batchScrapeHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := validateJobVisibility(reqBody.Visibility); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(BatchScrapeResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobPool(c, reqBody.Pool); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(BatchScrapeResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateClassifyFormat(reqBody.Formats); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(BatchScrapeResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if cfg, ok := c.Locals("config").(*config.Config); ok {
+		if err := validateFormatByteLimits(cfg, reqBody.MaxFormatBytes); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(BatchScrapeResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   err.Error(),
+			})
+		}
+	}
+
 	// Generate a batch scrape job ID (uuidv7 preferred)
 	id := func() uuid.UUID {
 		if id, err := uuid.NewV7(); err == nil {
@@ -56,6 +104,7 @@ func batchScrapeHandler(c *fiber.Ctx) error {
 
 	var tenantID *uuid.UUID
 	var apiKeyID *uuid.UUID
+	var userID *uuid.UUID
 	if val := c.Locals("principal"); val != nil {
 		if p, ok := val.(Principal); ok {
 			if p.TenantID != nil {
@@ -64,15 +113,21 @@ func batchScrapeHandler(c *fiber.Ctx) error {
 			if p.APIKeyID != nil {
 				apiKeyID = p.APIKeyID
 			}
+			userID = p.UserID
 		}
 	}
 
 	if err := svc.Enqueue(c.Context(), &services.BatchScrapeEnqueueRequest{
-		ID:         id,
-		PrimaryURL: primaryURL,
-		Body:       reqBody,
-		TenantID:   tenantID,
-		APIKeyID:   apiKeyID,
+		ID:            id,
+		PrimaryURL:    primaryURL,
+		Body:          reqBody,
+		TenantID:      tenantID,
+		APIKeyID:      apiKeyID,
+		UserID:        userID,
+		Visibility:    reqBody.Visibility,
+		CollectionID:  collectionID,
+		Pool:          routeJobPool(c, jobs.RouteRequest{TenantID: tenantID, Requested: reqBody.Pool}),
+		ZeroRetention: zeroRetentionRequested(reqBody.ZeroDataRetention, reqBody.StoreInCache),
 	}); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(BatchScrapeResponse{
 			Success: false,
@@ -105,6 +160,10 @@ func batchScrapeHandler(c *fiber.Ctx) error {
 }
 
 func batchScrapeStatusHandler(c *fiber.Ctx) error {
+	var cfg *config.Config
+	if val := c.Locals("config"); val != nil {
+		cfg, _ = val.(*config.Config)
+	}
 	st := c.Locals("store").(*store.Store)
 
 	idParam := c.Params("id")
@@ -133,21 +192,19 @@ func batchScrapeStatusHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce tenant scoping for non-admin callers.
-	if val := c.Locals("principal"); val != nil {
-		if p, ok := val.(Principal); ok && !p.IsSystemAdmin && job.TenantID.Valid && p.TenantID != nil && job.TenantID.UUID != *p.TenantID {
-			return c.Status(fiber.StatusNotFound).JSON(BatchScrapeResponse{
-				Success: false,
-				Code:    "NOT_FOUND",
-				Error:   "batch scrape job not found",
-			})
-		}
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) {
+		return c.Status(fiber.StatusNotFound).JSON(BatchScrapeResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "batch scrape job not found",
+		})
 	}
 
 	resp := BatchScrapeResponse{
 		Success: true,
 		ID:      job.ID.String(),
-		Status:  BatchScrapeStatus(job.Status),
+		Status:  BatchScrapeStatus(jobs.PublicStatus(job.Status)),
 		Total:   len(docs),
 	}
 
@@ -174,19 +231,23 @@ func batchScrapeStatusHandler(c *fiber.Ctx) error {
 	if job.Status == "completed" {
 		// Decode the original batch request to determine requested formats.
 		var originalReq BatchScrapeRequest
-		_ = json.Unmarshal(job.Input, &originalReq)
+		_ = jobinput.Decode(job.Type, job.Input, &originalReq)
 
+		annotations, _ := st.DocumentAnnotations(c.Context(), docs)
 		docSvc := services.NewJobDocumentService()
 		mapped := docSvc.BuildDocuments(docs, services.JobDocumentFormatOptions{
 			Formats:        originalReq.Formats,
 			IncludeSummary: false,
 			IncludeJSON:    false,
+			Annotations:    annotations,
+			Category:       c.Query("category"),
 		})
 
 		outDocs := make([]Document, 0, len(mapped))
 		for _, d := range mapped {
 			outDocs = append(outDocs, Document(d))
 		}
+		capDocuments(outDocs, formatSizeCaps(cfg, originalReq.MaxFormatBytes))
 		resp.Data = outDocs
 	}
 
@@ -194,5 +255,10 @@ func batchScrapeStatusHandler(c *fiber.Ctx) error {
 		resp.Error = job.Error.String
 	}
 
+	if job.PurgedAt.Valid {
+		resp.Warning = zeroRetentionPurgedWarning
+	}
+	purgeDeliveredJob(c, st, job)
+
 	return c.Status(http.StatusOK).JSON(resp)
 }
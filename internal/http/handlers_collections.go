@@ -0,0 +1,605 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/model"
+	"raito/internal/services"
+	"raito/internal/store"
+)
+
+type CollectionItem struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description,omitempty"`
+	DefaultOptions map[string]any `json:"defaultOptions,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+type CollectionRequest struct {
+	Name           *string        `json:"name,omitempty"`
+	Description    *string        `json:"description,omitempty"`
+	DefaultOptions map[string]any `json:"defaultOptions,omitempty"`
+}
+
+type CollectionResponse struct {
+	Success    bool            `json:"success"`
+	Code       string          `json:"code,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Collection *CollectionItem `json:"collection,omitempty"`
+}
+
+type ListCollectionsResponse struct {
+	Success     bool             `json:"success"`
+	Code        string           `json:"code,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Collections []CollectionItem `json:"collections,omitempty"`
+}
+
+type CollectionDocument struct {
+	ID         int64          `json:"id"`
+	JobID      string         `json:"jobId"`
+	Type       string         `json:"type"` // "page" or "extract"
+	URL        string         `json:"url"`
+	Markdown   string         `json:"markdown,omitempty"`
+	HTML       string         `json:"html,omitempty"`
+	RawHTML    string         `json:"rawHtml,omitempty"`
+	StatusCode int            `json:"statusCode,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+
+	Annotation *model.DocumentAnnotation `json:"annotation,omitempty"`
+}
+
+type CollectionDocumentsResponse struct {
+	Success   bool                 `json:"success"`
+	Code      string               `json:"code,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Documents []CollectionDocument `json:"documents,omitempty"`
+}
+
+func collectionItemFromDB(col db.Collection) CollectionItem {
+	item := CollectionItem{
+		ID:        col.ID.String(),
+		Name:      col.Name,
+		CreatedAt: col.CreatedAt,
+		UpdatedAt: col.UpdatedAt,
+	}
+	if col.Description.Valid {
+		item.Description = col.Description.String
+	}
+	if len(col.DefaultOptions) > 0 {
+		var opts map[string]any
+		if err := json.Unmarshal(col.DefaultOptions, &opts); err == nil && len(opts) > 0 {
+			item.DefaultOptions = opts
+		}
+	}
+	return item
+}
+
+// collectionPrincipal returns the principal for collection endpoints, which
+// are always scoped to the active tenant. When ok is false an error
+// response has already been written.
+func collectionPrincipal(c *fiber.Ctx) (Principal, bool) {
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		_ = c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+		return p, false
+	}
+	if p.TenantID == nil {
+		_ = c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required for collections",
+		})
+		return p, false
+	}
+	return p, true
+}
+
+// loadTenantCollection looks up the collection in the :id route param and
+// ensures it belongs to the principal's active tenant. When ok is false an
+// error response has already been written.
+func loadTenantCollection(c *fiber.Ctx, st *store.Store, p Principal) (db.Collection, bool) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		_ = c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid collection id",
+		})
+		return db.Collection{}, false
+	}
+
+	col, err := db.New(st.DB).GetCollectionByID(c.Context(), id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		_ = c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "COLLECTION_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+		return db.Collection{}, false
+	}
+	if err != nil || col.TenantID != *p.TenantID {
+		_ = c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "collection not found",
+		})
+		return db.Collection{}, false
+	}
+	return col, true
+}
+
+// canManageCollection reports whether the principal may update or delete
+// the collection: its creator, tenant admins, and system admins.
+func canManageCollection(c *fiber.Ctx, st *store.Store, p Principal, col db.Collection) bool {
+	if col.CreatedByUserID.Valid && p.UserID != nil && col.CreatedByUserID.UUID == *p.UserID {
+		return true
+	}
+	return jobViewerFor(c, st, p) == nil
+}
+
+// collectionsListHandler lists collections in the active tenant.
+func collectionsListHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	p, ok := collectionPrincipal(c)
+	if !ok {
+		return nil
+	}
+
+	rows, err := db.New(st.DB).ListCollectionsByTenant(c.Context(), *p.TenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ListCollectionsResponse{
+			Success: false,
+			Code:    "COLLECTION_LIST_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	items := make([]CollectionItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, collectionItemFromDB(row))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ListCollectionsResponse{
+		Success:     true,
+		Collections: items,
+	})
+}
+
+// collectionCreateHandler creates a collection in the active tenant. Any
+// tenant member may create collections.
+func collectionCreateHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	p, ok := collectionPrincipal(c)
+	if !ok {
+		return nil
+	}
+
+	var req CollectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CollectionResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	name := ""
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+	}
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(CollectionResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "name is required",
+		})
+	}
+
+	defaults, err := json.Marshal(req.DefaultOptions)
+	if err != nil || req.DefaultOptions == nil {
+		defaults = json.RawMessage(`{}`)
+	}
+
+	var description sql.NullString
+	if req.Description != nil && strings.TrimSpace(*req.Description) != "" {
+		description = sql.NullString{String: strings.TrimSpace(*req.Description), Valid: true}
+	}
+
+	col, err := db.New(st.DB).InsertCollection(c.Context(), db.InsertCollectionParams{
+		ID:              uuid.New(),
+		TenantID:        *p.TenantID,
+		Name:            name,
+		Description:     description,
+		DefaultOptions:  defaults,
+		CreatedByUserID: uuid.NullUUID{UUID: *p.UserID, Valid: true},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "uq_collections_tenant_name") {
+			return c.Status(fiber.StatusConflict).JSON(CollectionResponse{
+				Success: false,
+				Code:    "COLLECTION_EXISTS",
+				Error:   "a collection with this name already exists",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CollectionResponse{
+			Success: false,
+			Code:    "COLLECTION_CREATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "collection.create", auditEventOptions{
+		TenantID:     p.TenantID,
+		ResourceType: "collection",
+		ResourceID:   col.ID.String(),
+		Metadata:     map[string]any{"name": col.Name},
+	})
+
+	item := collectionItemFromDB(col)
+	return c.Status(fiber.StatusCreated).JSON(CollectionResponse{
+		Success:    true,
+		Collection: &item,
+	})
+}
+
+// collectionDetailHandler returns a single collection in the active tenant.
+func collectionDetailHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	p, ok := collectionPrincipal(c)
+	if !ok {
+		return nil
+	}
+
+	col, ok := loadTenantCollection(c, st, p)
+	if !ok {
+		return nil
+	}
+
+	item := collectionItemFromDB(col)
+	return c.Status(fiber.StatusOK).JSON(CollectionResponse{
+		Success:    true,
+		Collection: &item,
+	})
+}
+
+// collectionUpdateHandler updates a collection's name, description, or
+// default options. Omitted fields are left unchanged.
+func collectionUpdateHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	p, ok := collectionPrincipal(c)
+	if !ok {
+		return nil
+	}
+
+	col, ok := loadTenantCollection(c, st, p)
+	if !ok {
+		return nil
+	}
+	if !canManageCollection(c, st, p, col) {
+		return c.Status(fiber.StatusForbidden).JSON(CollectionResponse{
+			Success: false,
+			Code:    "FORBIDDEN",
+			Error:   "only the collection creator or a tenant admin can modify this collection",
+		})
+	}
+
+	var req CollectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CollectionResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	params := db.UpdateCollectionParams{
+		ID:             col.ID,
+		Name:           col.Name,
+		Description:    col.Description,
+		DefaultOptions: col.DefaultOptions,
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(CollectionResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "name cannot be empty",
+			})
+		}
+		params.Name = name
+	}
+	if req.Description != nil {
+		desc := strings.TrimSpace(*req.Description)
+		params.Description = sql.NullString{String: desc, Valid: desc != ""}
+	}
+	if req.DefaultOptions != nil {
+		defaults, err := json.Marshal(req.DefaultOptions)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(CollectionResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid defaultOptions",
+			})
+		}
+		params.DefaultOptions = defaults
+	}
+
+	updated, err := db.New(st.DB).UpdateCollection(c.Context(), params)
+	if err != nil {
+		if strings.Contains(err.Error(), "uq_collections_tenant_name") {
+			return c.Status(fiber.StatusConflict).JSON(CollectionResponse{
+				Success: false,
+				Code:    "COLLECTION_EXISTS",
+				Error:   "a collection with this name already exists",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CollectionResponse{
+			Success: false,
+			Code:    "COLLECTION_UPDATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "collection.update", auditEventOptions{
+		TenantID:     p.TenantID,
+		ResourceType: "collection",
+		ResourceID:   updated.ID.String(),
+	})
+
+	item := collectionItemFromDB(updated)
+	return c.Status(fiber.StatusOK).JSON(CollectionResponse{
+		Success:    true,
+		Collection: &item,
+	})
+}
+
+// collectionDeleteHandler deletes a collection. Its jobs and documents are
+// kept and simply become unassigned.
+func collectionDeleteHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	p, ok := collectionPrincipal(c)
+	if !ok {
+		return nil
+	}
+
+	col, ok := loadTenantCollection(c, st, p)
+	if !ok {
+		return nil
+	}
+	if !canManageCollection(c, st, p, col) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Success: false,
+			Code:    "FORBIDDEN",
+			Error:   "only the collection creator or a tenant admin can delete this collection",
+		})
+	}
+
+	if _, err := db.New(st.DB).DeleteCollection(c.Context(), col.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "COLLECTION_DELETE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "collection.delete", auditEventOptions{
+		TenantID:     p.TenantID,
+		ResourceType: "collection",
+		ResourceID:   col.ID.String(),
+		Metadata:     map[string]any{"name": col.Name},
+	})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true})
+}
+
+// collectionJobsHandler lists the jobs assigned to a collection, applying
+// the same visibility rules as /v1/jobs.
+func collectionJobsHandler(c *fiber.Ctx) error {
+	var cfg *config.Config
+	if val := c.Locals("config"); val != nil {
+		cfg, _ = val.(*config.Config)
+	}
+	st := c.Locals("store").(*store.Store)
+	p, ok := collectionPrincipal(c)
+	if !ok {
+		return nil
+	}
+
+	col, ok := loadTenantCollection(c, st, p)
+	if !ok {
+		return nil
+	}
+
+	limit, offset, ok := collectionPaging(c)
+	if !ok {
+		return nil
+	}
+
+	jobs, err := st.ListJobs(c.Context(), store.JobListFilter{
+		Type:         c.Query("type"),
+		Status:       c.Query("status"),
+		TenantID:     p.TenantID,
+		CollectionID: &col.ID,
+		VisibleTo:    jobViewerFor(c, st, p),
+		Limit:        int32(limit),
+		Offset:       int32(offset),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ListJobsResponse{
+			Success: false,
+			Code:    "JOB_LIST_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ListJobsResponse{
+		Success: true,
+		Jobs:    buildJobItems(c, cfg, st, jobs),
+	})
+}
+
+// collectionDocumentsHandler aggregates documents across all jobs in a
+// collection, newest first.
+func collectionDocumentsHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	p, ok := collectionPrincipal(c)
+	if !ok {
+		return nil
+	}
+
+	col, ok := loadTenantCollection(c, st, p)
+	if !ok {
+		return nil
+	}
+
+	limit, offset, ok := collectionPaging(c)
+	if !ok {
+		return nil
+	}
+
+	docs, err := st.ListCollectionDocuments(c.Context(), col.ID, jobViewerFor(c, st, p), c.Query("category"), int32(limit), int32(offset))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(CollectionDocumentsResponse{
+			Success: false,
+			Code:    "COLLECTION_DOCUMENTS_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	annotations, _ := st.DocumentAnnotations(c.Context(), docs)
+
+	items := make([]CollectionDocument, 0, len(docs))
+	for _, d := range docs {
+		item := CollectionDocument{
+			ID:        d.ID,
+			JobID:     d.JobID.String(),
+			Type:      d.Type,
+			URL:       d.Url,
+			CreatedAt: d.CreatedAt,
+		}
+		if d.Markdown.Valid {
+			item.Markdown = d.Markdown.String
+		}
+		if d.Html.Valid {
+			item.HTML = d.Html.String
+		}
+		if d.RawHtml.Valid {
+			item.RawHTML = d.RawHtml.String
+		}
+		if d.StatusCode.Valid {
+			item.StatusCode = int(d.StatusCode.Int32)
+		}
+		if len(d.Metadata) > 0 {
+			_ = json.Unmarshal(d.Metadata, &item.Metadata)
+		}
+		if a, ok := annotations[d.ID]; ok {
+			item.Annotation = services.DocumentAnnotation(a)
+			if a.Title.Valid {
+				if item.Metadata == nil {
+					item.Metadata = map[string]any{}
+				}
+				item.Metadata["title"] = a.Title.String
+			}
+		}
+		items = append(items, item)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(CollectionDocumentsResponse{
+		Success:   true,
+		Documents: items,
+	})
+}
+
+// collectionPaging parses limit/offset query params, writing a 400 response
+// and returning ok=false when they are invalid.
+func collectionPaging(c *fiber.Ctx) (limit, offset int, ok bool) {
+	limit = 50
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			_ = c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid limit value",
+			})
+			return 0, 0, false
+		}
+		if n > 500 {
+			n = 500
+		}
+		limit = n
+	}
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			_ = c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid offset value",
+			})
+			return 0, 0, false
+		}
+		offset = n
+	}
+	return limit, offset, true
+}
+
+// applyCollectionDefaults resolves the collection referenced by a job
+// request and fills unset request fields from its default options. The
+// raw request body is decoded again over the defaults so explicit request
+// values always win. It returns an error code and message when the
+// collection is invalid or not in the caller's tenant.
+func applyCollectionDefaults(c *fiber.Ctx, collectionID string, target any) (*uuid.UUID, string, string) {
+	if collectionID == "" {
+		return nil, "", ""
+	}
+	id, err := uuid.Parse(collectionID)
+	if err != nil {
+		return nil, "BAD_REQUEST", "invalid collectionId"
+	}
+
+	st, ok := c.Locals("store").(*store.Store)
+	if !ok || st == nil || st.DB == nil {
+		return nil, "BAD_REQUEST", "collections are not available"
+	}
+	var tenantID *uuid.UUID
+	if p, ok := c.Locals("principal").(Principal); ok {
+		tenantID = p.TenantID
+	}
+
+	col, err := db.New(st.DB).GetCollectionByID(c.Context(), id)
+	if err != nil || tenantID == nil || col.TenantID != *tenantID {
+		return nil, "COLLECTION_NOT_FOUND", "collection not found"
+	}
+
+	if len(col.DefaultOptions) > 0 && string(col.DefaultOptions) != "{}" {
+		if err := json.Unmarshal(col.DefaultOptions, target); err != nil {
+			return nil, "BAD_REQUEST", "collection defaultOptions do not match this request type: " + err.Error()
+		}
+		if err := json.Unmarshal(c.Body(), target); err != nil {
+			return nil, "BAD_REQUEST_INVALID_JSON", "Bad request, malformed JSON"
+		}
+	}
+
+	return &col.ID, "", ""
+}
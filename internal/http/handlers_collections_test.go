@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/store"
+)
+
+func newTestAppWithCollectionHandlers(p *Principal) *fiber.App {
+	app := fiber.New()
+	st := &store.Store{}
+
+	setup := func(c *fiber.Ctx) error {
+		c.Locals("store", st)
+		if p != nil {
+			c.Locals("principal", *p)
+		}
+		return c.Next()
+	}
+
+	app.Get("/v1/collections", setup, collectionsListHandler)
+	app.Post("/v1/collections", setup, collectionCreateHandler)
+	app.Get("/v1/collections/:id", setup, collectionDetailHandler)
+	return app
+}
+
+func TestCollections_Unauthenticated(t *testing.T) {
+	app := newTestAppWithCollectionHandlers(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/collections", nil)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestCollections_MissingTenant(t *testing.T) {
+	uid := uuid.New()
+	app := newTestAppWithCollectionHandlers(&Principal{UserID: &uid})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/collections", nil)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestCollectionCreate_RequiresName(t *testing.T) {
+	uid := uuid.New()
+	tid := uuid.New()
+	app := newTestAppWithCollectionHandlers(&Principal{UserID: &uid, TenantID: &tid})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/collections", strings.NewReader(`{"description":"no name"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestCollectionDetail_InvalidID(t *testing.T) {
+	uid := uuid.New()
+	tid := uuid.New()
+	app := newTestAppWithCollectionHandlers(&Principal{UserID: &uid, TenantID: &tid})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/collections/not-a-uuid", nil)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
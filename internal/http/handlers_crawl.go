@@ -4,16 +4,70 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
 	"raito/internal/config"
+	"raito/internal/crawler"
+	"raito/internal/db"
+	"raito/internal/jobinput"
+	"raito/internal/jobs"
+	"raito/internal/scrapeutil"
 	"raito/internal/services"
 	"raito/internal/store"
 )
 
+// maxCrawlSeeds bounds the start URLs of one crawl.
+const maxCrawlSeeds = 20
+
+// maxCrawlDelaySeconds bounds the delay a crawl may set between requests
+// to one host.
+const maxCrawlDelaySeconds = 60
+
+// crawlSeeds returns the start URLs of a crawl: url followed by urls,
+// trimmed and without repeats.
+func crawlSeeds(req CrawlRequest) []string {
+	seen := map[string]bool{}
+	var seeds []string
+	for _, u := range append([]string{req.URL}, req.URLs...) {
+		u = strings.TrimSpace(u)
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		seeds = append(seeds, u)
+	}
+	return seeds
+}
+
+// normalizeCrawlSeeds validates the start URLs of a crawl and rewrites
+// the request so url is the first seed and urls holds the rest. The first
+// seed names the job and keys incremental baselines.
+func normalizeCrawlSeeds(req *CrawlRequest) error {
+	seeds := crawlSeeds(*req)
+	if len(seeds) == 0 {
+		return fmt.Errorf("Missing required field 'url'")
+	}
+	if len(seeds) > maxCrawlSeeds {
+		return fmt.Errorf("too many start urls; maximum is %d", maxCrawlSeeds)
+	}
+	for _, s := range seeds {
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid start url %q", s)
+		}
+	}
+	req.URL = seeds[0]
+	req.URLs = seeds[1:]
+	return nil
+}
+
 func crawlHandler(c *fiber.Ctx) error {
 	var reqBody CrawlRequest
 	if err := c.BodyParser(&reqBody); err != nil {
@@ -24,7 +78,20 @@ func crawlHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	if reqBody.URL == "" {
+	collectionID, code, msg := applyCollectionDefaults(c, reqBody.CollectionID, &reqBody)
+	if code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
+	formatsSource, err := applyDefaultFormats(c, &reqBody.Formats)
+	if err != nil {
+		return defaultFormatsLookupFailed(c, err)
+	}
+
+	if reqBody.URL == "" && len(reqBody.URLs) == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
 			Success: false,
 			Code:    "BAD_REQUEST",
@@ -32,9 +99,140 @@ func crawlHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := normalizeCrawlSeeds(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobVisibility(reqBody.Visibility); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobPool(c, reqBody.Pool); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateClassifyFormat(reqBody.Formats); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if reqBody.MaxDiscoveryDepth != nil && *reqBody.MaxDiscoveryDepth < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "maxDiscoveryDepth must not be negative",
+		})
+	}
+
+	if reqBody.Delay != nil && (*reqBody.Delay < 0 || *reqBody.Delay > maxCrawlDelaySeconds) {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   fmt.Sprintf("delay must be between 0 and %d seconds", maxCrawlDelaySeconds),
+		})
+	}
+
+	if reqBody.Webhook != "" {
+		webhook, ok := parseWebhookURL(reqBody.Webhook)
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "webhook must be an absolute http or https URL",
+			})
+		}
+		reqBody.Webhook = webhook
+	}
+
+	if err := normalizeCrawlLanguages(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if _, err := crawler.ParsePriorityExpression(reqBody.PriorityExpression); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
 	cfg := c.Locals("config").(*config.Config)
 	st := c.Locals("store").(*store.Store)
 
+	if err := validateFormatByteLimits(cfg, reqBody.MaxFormatBytes); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if reqBody.SPA != nil && *reqBody.SPA && !cfg.Rod.Enabled {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "SPA_CRAWL_NOT_AVAILABLE",
+			Error:   "spa crawls require browser scraping, but rod is disabled in server configuration",
+		})
+	}
+
+	if scrapeutil.WantsFormat(reqBody.Formats, "a11y") && !cfg.Rod.Enabled {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "A11Y_NOT_AVAILABLE",
+			Error:   "a11y format requires browser scraping, but rod is disabled in server configuration",
+		})
+	}
+
+	if scrapeutil.WantsFormat(reqBody.Formats, "performance") && !cfg.Rod.Enabled {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+			Success: false,
+			Code:    "PERFORMANCE_NOT_AVAILABLE",
+			Error:   "performance format requires browser scraping, but rod is disabled in server configuration",
+		})
+	}
+
+	// Check the secret exists now; the worker resolves it again per job.
+	if reqBody.ScrapeOptions != nil && reqBody.ScrapeOptions.Auth != nil {
+		var tenantID *uuid.UUID
+		if p, ok := c.Locals("principal").(Principal); ok {
+			tenantID = p.TenantID
+		}
+		if err := validateTargetAuth(reqBody.ScrapeOptions.Auth); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(CrawlResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   err.Error(),
+			})
+		}
+		if _, err := targetAuthHeaders(c.Context(), cfg, db.New(st.DB), tenantID, reqBody.ScrapeOptions.Auth); err != nil {
+			status, code := targetAuthStatus(err)
+			return c.Status(status).JSON(CrawlResponse{
+				Success: false,
+				Code:    code,
+				Error:   err.Error(),
+			})
+		}
+	}
+
 	// Generate a crawl job ID (uuidv7 preferred)
 	id := func() uuid.UUID {
 		if id, err := uuid.NewV7(); err == nil {
@@ -47,6 +245,7 @@ func crawlHandler(c *fiber.Ctx) error {
 
 	var tenantID *uuid.UUID
 	var apiKeyID *uuid.UUID
+	var userID *uuid.UUID
 	if val := c.Locals("principal"); val != nil {
 		if p, ok := val.(Principal); ok {
 			if p.TenantID != nil {
@@ -55,15 +254,38 @@ func crawlHandler(c *fiber.Ctx) error {
 			if p.APIKeyID != nil {
 				apiKeyID = p.APIKeyID
 			}
+			userID = p.UserID
 		}
 	}
 
+	if err := resolveFormatTemplates(c.Context(), db.New(st.DB), tenantID, reqBody.Formats); err != nil {
+		status, code := promptTemplateStatus(err)
+		return c.Status(status).JSON(CrawlResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
+	applied := resolveCrawlOptions(cfg, &reqBody, c.Body())
+	applied.setFormatsSource(formatsSource)
+
 	if err := svc.Enqueue(c.Context(), &services.CrawlEnqueueRequest{
-		ID:       id,
-		URL:      reqBody.URL,
-		Body:     reqBody,
-		TenantID: tenantID,
-		APIKeyID: apiKeyID,
+		ID:           id,
+		URL:          reqBody.URL,
+		Body:         reqBody,
+		TenantID:     tenantID,
+		APIKeyID:     apiKeyID,
+		UserID:       userID,
+		Visibility:   reqBody.Visibility,
+		CollectionID: collectionID,
+		Pool: routeJobPool(c, jobs.RouteRequest{
+			TenantID:     tenantID,
+			Requested:    reqBody.Pool,
+			NeedsBrowser: scrapeOptionsUseBrowser(reqBody.ScrapeOptions),
+		}),
+		ZeroRetention:  zeroRetentionRequested(reqBody.ZeroDataRetention, reqBody.StoreInCache),
+		AppliedOptions: applied,
 	}); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(CrawlResponse{
 			Success: false,
@@ -94,13 +316,18 @@ func crawlHandler(c *fiber.Ctx) error {
 	host := c.Hostname()
 
 	return c.Status(http.StatusOK).JSON(CrawlResponse{
-		Success: true,
-		ID:      id.String(),
-		URL:     protocol + "://" + host + "/v1/crawl/" + id.String(),
+		Success:        true,
+		ID:             id.String(),
+		URL:            protocol + "://" + host + "/v1/crawl/" + id.String(),
+		AppliedOptions: applied,
 	})
 }
 
 func crawlStatusHandler(c *fiber.Ctx) error {
+	var cfg *config.Config
+	if val := c.Locals("config"); val != nil {
+		cfg, _ = val.(*config.Config)
+	}
 	st := c.Locals("store").(*store.Store)
 
 	idParam := c.Params("id")
@@ -129,22 +356,40 @@ func crawlStatusHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce tenant scoping for non-admin callers.
-	if val := c.Locals("principal"); val != nil {
-		if p, ok := val.(Principal); ok && !p.IsSystemAdmin && job.TenantID.Valid && p.TenantID != nil && job.TenantID.UUID != *p.TenantID {
-			return c.Status(fiber.StatusNotFound).JSON(CrawlResponse{
-				Success: false,
-				Code:    "NOT_FOUND",
-				Error:   "crawl job not found",
-			})
-		}
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) {
+		return c.Status(fiber.StatusNotFound).JSON(CrawlResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "crawl job not found",
+		})
 	}
 
 	resp := CrawlResponse{
-		Success: true,
-		ID:      job.ID.String(),
-		Status:  CrawlStatus(job.Status),
-		Total:   len(docs),
+		Success:        true,
+		ID:             job.ID.String(),
+		Status:         CrawlStatus(jobs.PublicStatus(job.Status)),
+		Total:          len(docs),
+		Completed:      len(docs),
+		AppliedOptions: decodeAppliedOptions(job.AppliedOptions),
+	}
+
+	// Running crawls report live progress from the worker heartbeat.
+	if job.Status == "running" {
+		if hb, err := db.New(st.DB).GetJobHeartbeat(c.Context(), job.ID); err == nil {
+			completed, total, eta := crawlProgress(hb, time.Now())
+			resp.Completed, resp.ETASeconds = completed, eta
+			if total > 0 {
+				resp.Total = total
+			}
+		}
+	}
+
+	// Paused crawls count the pages still queued in their saved frontier.
+	if job.Status == "paused" {
+		if saved, err := loadCrawlFrontier(c.Context(), st, job.ID); err == nil && saved != nil {
+			resp.Total = len(docs) + len(saved.frontier.Queued)
+		}
 	}
 
 	// Job-level logs for crawl completion/failure.
@@ -170,25 +415,56 @@ func crawlStatusHandler(c *fiber.Ctx) error {
 	if job.Status == "completed" {
 		// Decode the original crawl request to determine requested formats.
 		var originalReq CrawlRequest
-		_ = json.Unmarshal(job.Input, &originalReq)
+		_ = jobinput.Decode(job.Type, job.Input, &originalReq)
 
+		annotations, _ := st.DocumentAnnotations(c.Context(), docs)
+		duplicates := jobDuplicateSummary(job)
 		docSvc := services.NewJobDocumentService()
 		mapped := docSvc.BuildDocuments(docs, services.JobDocumentFormatOptions{
-			Formats:        originalReq.Formats,
-			IncludeSummary: true,
-			IncludeJSON:    true,
+			Formats:           originalReq.Formats,
+			IncludeSummary:    true,
+			IncludeJSON:       true,
+			Annotations:       annotations,
+			Category:          c.Query("category"),
+			DuplicateClusters: duplicates.duplicateClusterIDs(),
 		})
 
 		outDocs := make([]Document, 0, len(mapped))
 		for _, d := range mapped {
 			outDocs = append(outDocs, Document(d))
 		}
+		capDocuments(outDocs, formatSizeCaps(cfg, originalReq.MaxFormatBytes))
 		resp.Data = outDocs
+
+		if job.Output.Valid {
+			var out struct {
+				Incremental *CrawlIncrementalSummary `json:"incremental"`
+				RobotsMeta  *CrawlRobotsMetaSummary  `json:"robotsMeta"`
+				Languages   *CrawlLanguageSummary    `json:"languages"`
+				Report      *CrawlReport             `json:"report"`
+			}
+			if err := json.Unmarshal(job.Output.RawMessage, &out); err == nil {
+				resp.Incremental = out.Incremental
+				resp.RobotsMeta = out.RobotsMeta
+				resp.Languages = out.Languages
+				resp.Report = out.Report
+			}
+		}
+		resp.Duplicates = duplicates
+		resp.Accessibility = jobAccessibilitySummary(job)
+		if report := jobStructuredDataReport(job); report != nil {
+			resp.StructuredData = &report.CrawlStructuredDataSummary
+		}
 	}
 
 	if job.Error.Valid {
 		resp.Error = job.Error.String
 	}
 
+	if job.PurgedAt.Valid {
+		resp.Warning = zeroRetentionPurgedWarning
+	}
+	purgeDeliveredJob(c, st, job)
+
 	return c.Status(http.StatusOK).JSON(resp)
 }
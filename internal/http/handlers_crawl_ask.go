@@ -0,0 +1,474 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/llm"
+	"raito/internal/metrics"
+	"raito/internal/store"
+)
+
+const (
+	// defaultAskSources is how many of the best-matching documents are
+	// searched for passages when the request does not set maxSources.
+	defaultAskSources = 8
+	maxAskSources     = 20
+	// askPassageChars is the target size of a passage.
+	askPassageChars = 1500
+	// askContextChars bounds the passages sent to the LLM.
+	askContextChars = 24000
+	// maxAskPassagesPerDocument keeps one long page from filling the
+	// context.
+	maxAskPassagesPerDocument = 4
+	maxAskQueryTerms          = 32
+	askExcerptChars           = 300
+)
+
+// askStopWords are dropped from questions before the full-text search,
+// since every term is OR-ed into the query.
+var askStopWords = map[string]bool{
+	"a": true, "about": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "could": true, "do": true, "does": true, "for": true,
+	"from": true, "has": true, "have": true, "how": true, "i": true, "in": true, "is": true,
+	"it": true, "its": true, "me": true, "my": true, "of": true, "on": true, "or": true,
+	"should": true, "so": true, "tell": true, "that": true, "the": true, "their": true,
+	"there": true, "these": true, "this": true, "to": true, "us": true, "was": true,
+	"we": true, "were": true, "what": true, "when": true, "where": true, "which": true,
+	"who": true, "why": true, "will": true, "with": true, "would": true, "you": true, "your": true,
+}
+
+// CrawlAskRequest is the body of POST /v1/crawl/:id/ask.
+type CrawlAskRequest struct {
+	Question string `json:"question"`
+	// MaxSources is how many of the best-matching documents are searched
+	// for passages.
+	MaxSources   *int   `json:"maxSources,omitempty"`
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	Model        string `json:"model,omitempty"`
+}
+
+// CrawlAskResponse is the body of POST /v1/crawl/:id/ask.
+type CrawlAskResponse struct {
+	Success bool `json:"success"`
+	// Found is false when no document matches the question or the
+	// matching passages do not answer it.
+	Found     bool          `json:"found"`
+	Answer    string        `json:"answer"`
+	Citations []AskCitation `json:"citations"`
+	Code      string        `json:"code,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// AskCitation is a document an answer cites. Index is the number the
+// answer refers to it by, as in "[1]".
+type AskCitation struct {
+	Index      int    `json:"index"`
+	DocumentID int64  `json:"documentId"`
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+	// Excerpt is the start of the best-matching passage of the document.
+	Excerpt string `json:"excerpt"`
+}
+
+// askPassage is a piece of a matched document scored against the
+// question.
+type askPassage struct {
+	doc   int
+	text  string
+	score float64
+}
+
+// askSource is a matched document with the passages sent to the LLM.
+type askSource struct {
+	documentID int64
+	url        string
+	title      string
+	passages   []string
+}
+
+// askTokens lowercases s and splits it into letter and digit runs.
+func askTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// askQueryTerms returns the distinct search terms of a question, without
+// stop words and single letters.
+func askQueryTerms(question string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, t := range askTokens(question) {
+		if askStopWords[t] || seen[t] || (len([]rune(t)) < 2 && !unicode.IsNumber([]rune(t)[0])) {
+			continue
+		}
+		seen[t] = true
+		terms = append(terms, t)
+		if len(terms) == maxAskQueryTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// rankAskPassages splits docs into passages and scores each against
+// terms with BM25, best first. Passages that match no term are dropped.
+func rankAskPassages(docs []db.Document, terms []string) []askPassage {
+	var passages []askPassage
+	var counts []map[string]int
+	var lengths []int
+	df := map[string]int{}
+	totalLen := 0
+	for i, d := range docs {
+		for _, text := range splitSummarizeMarkdown(strings.TrimSpace(d.Markdown.String), askPassageChars) {
+			tokens := askTokens(text)
+			tf := map[string]int{}
+			for _, t := range tokens {
+				tf[t]++
+			}
+			for _, term := range terms {
+				if tf[term] > 0 {
+					df[term]++
+				}
+			}
+			passages = append(passages, askPassage{doc: i, text: text})
+			counts = append(counts, tf)
+			lengths = append(lengths, len(tokens))
+			totalLen += len(tokens)
+		}
+	}
+	if len(passages) == 0 {
+		return nil
+	}
+
+	const k1, b = 1.2, 0.75
+	n := float64(len(passages))
+	avgLen := float64(totalLen) / n
+	ranked := passages[:0]
+	for i, p := range passages {
+		for _, term := range terms {
+			tf := float64(counts[i][term])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			p.score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(lengths[i])/avgLen))
+		}
+		if p.score > 0 {
+			ranked = append(ranked, p)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	return ranked
+}
+
+// selectAskSources picks the best passages within askContextChars and
+// groups them by document, ordered by each document's best passage.
+func selectAskSources(docs []db.Document, annotations map[int64]db.DocumentAnnotation, ranked []askPassage) []askSource {
+	var sources []askSource
+	byDoc := map[int]int{}
+	used := 0
+	for _, p := range ranked {
+		if used+len(p.text) > askContextChars {
+			continue
+		}
+		idx, ok := byDoc[p.doc]
+		if !ok {
+			d := docs[p.doc]
+			idx = len(sources)
+			byDoc[p.doc] = idx
+			title := documentTitle(d)
+			if a, ok := annotations[d.ID]; ok && a.Title.Valid {
+				title = a.Title.String
+			}
+			sources = append(sources, askSource{documentID: d.ID, url: d.Url, title: title})
+		}
+		if len(sources[idx].passages) == maxAskPassagesPerDocument {
+			continue
+		}
+		sources[idx].passages = append(sources[idx].passages, p.text)
+		used += len(p.text)
+	}
+	return sources
+}
+
+// askContext renders sources for the LLM, numbering them from 1.
+func askContext(sources []askSource) string {
+	var b strings.Builder
+	for i, s := range sources {
+		if i > 0 {
+			b.WriteString("\n\n---\n\n")
+		}
+		fmt.Fprintf(&b, "## [%d] Source: %s\n", i+1, s.url)
+		if s.title != "" {
+			fmt.Fprintf(&b, "Title: %s\n", s.title)
+		}
+		b.WriteString("\n")
+		b.WriteString(strings.Join(s.passages, "\n\n[...]\n\n"))
+	}
+	return b.String()
+}
+
+var askCitationRef = regexp.MustCompile(`\[(\d+)\]`)
+
+// askCitations maps the source numbers the LLM cited, or else those
+// referenced as "[n]" in the answer, to citations.
+func askCitations(raw any, answer string, sources []askSource) []AskCitation {
+	var indexes []int
+	if list, ok := raw.([]any); ok {
+		for _, v := range list {
+			switch n := v.(type) {
+			case float64:
+				indexes = append(indexes, int(n))
+			case string:
+				if i, err := strconv.Atoi(strings.Trim(n, "[] ")); err == nil {
+					indexes = append(indexes, i)
+				}
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		for _, m := range askCitationRef.FindAllStringSubmatch(answer, -1) {
+			if i, err := strconv.Atoi(m[1]); err == nil {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+
+	citations := []AskCitation{}
+	seen := map[int]bool{}
+	for _, i := range indexes {
+		if i < 1 || i > len(sources) || seen[i] {
+			continue
+		}
+		seen[i] = true
+		s := sources[i-1]
+		excerpt := s.passages[0]
+		if len(excerpt) > askExcerptChars {
+			cut := askExcerptChars
+			for cut > 0 && !utf8.RuneStart(excerpt[cut]) {
+				cut--
+			}
+			excerpt = strings.TrimSpace(excerpt[:cut]) + "…"
+		}
+		citations = append(citations, AskCitation{
+			Index:      i,
+			DocumentID: s.documentID,
+			URL:        s.url,
+			Title:      s.title,
+			Excerpt:    excerpt,
+		})
+	}
+	sort.Slice(citations, func(i, j int) bool { return citations[i].Index < citations[j].Index })
+	return citations
+}
+
+// crawlAskHandler implements POST /v1/crawl/:id/ask. It answers a question
+// from the documents of a completed crawl: a full-text search picks the
+// best-matching documents, BM25 picks their most relevant passages, and
+// the LLM writes an answer citing them.
+func crawlAskHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid crawl id",
+		})
+	}
+
+	var reqBody CrawlAskRequest
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	reqBody.Question = strings.TrimSpace(reqBody.Question)
+	if reqBody.Question == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Missing required field 'question'",
+		})
+	}
+	maxSources := defaultAskSources
+	if reqBody.MaxSources != nil {
+		if *reqBody.MaxSources <= 0 || *reqBody.MaxSources > maxAskSources {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   fmt.Sprintf("maxSources must be between 1 and %d", maxAskSources),
+			})
+		}
+		maxSources = *reqBody.MaxSources
+	}
+	terms := askQueryTerms(reqBody.Question)
+	if len(terms) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "question has no searchable terms",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "crawl job not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "CRAWL_JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "crawl job not found",
+		})
+	}
+	var tenantID *uuid.UUID
+	if p, ok := c.Locals("principal").(Principal); ok {
+		tenantID = p.TenantID
+	}
+	if job.Type != "crawl" {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "crawl job not found",
+		})
+	}
+	if job.Status != "completed" {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_NOT_COMPLETED",
+			Error:   "job is not completed yet",
+		})
+	}
+
+	// Fetch extra documents so that excluded ones can be dropped without
+	// leaving fewer than maxSources.
+	docs, err := db.New(st.DB).SearchJobDocuments(c.Context(), db.SearchJobDocumentsParams{
+		Query:      strings.Join(terms, " | "),
+		JobID:      job.ID,
+		MaxResults: int32(maxSources * 2),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "CRAWL_ASK_SEARCH_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	annotations, err := st.DocumentAnnotations(c.Context(), docs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "CRAWL_ASK_SEARCH_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	docs = withoutExcludedDocuments(docs, annotations)
+	if len(docs) > maxSources {
+		docs = docs[:maxSources]
+	}
+
+	sources := selectAskSources(docs, annotations, rankAskPassages(docs, terms))
+	if len(sources) == 0 {
+		return c.JSON(CrawlAskResponse{Success: true, Citations: []AskCitation{}})
+	}
+
+	client, provider, modelName, err := newLLMClient(c.Context(), cfg, st, tenantID, reqBody.Provider, reqBody.Model)
+	if err != nil {
+		status, code := llmClientFailure(err)
+		return c.Status(status).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+	c.Locals("llm_provider", string(provider))
+	c.Locals("llm_model", modelName)
+
+	timeoutMs := cfg.Scraper.TimeoutMs
+	if timeoutMs <= 0 {
+		timeoutMs = 30000
+	}
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+
+	prompt := "Answer the question using only the numbered sources below. Cite the sources each statement relies on with their numbers in brackets, like [1]. If the sources do not answer the question, say so and set found to false.\n\nQuestion: " + reqBody.Question
+	if reqBody.SystemPrompt != "" {
+		prompt = reqBody.SystemPrompt + "\n\n" + prompt
+	}
+	llmCtx, cancel := context.WithTimeout(c.Context(), timeout)
+	defer cancel()
+	res, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
+		URL:      job.Url,
+		Markdown: askContext(sources),
+		Fields: []llm.FieldSpec{
+			{Name: "answer", Description: "The answer in markdown, citing sources as [n].", Type: "string"},
+			{Name: "citations", Description: "Numbers of the sources the answer cites.", Type: "array"},
+			{Name: "found", Description: "Whether the sources answer the question.", Type: "boolean"},
+		},
+		Prompt:  prompt,
+		Timeout: timeout,
+		Strict:  false,
+	})
+	if err != nil {
+		metrics.RecordLLMExtract(string(provider), modelName, false)
+		status, code := llmFailure(err, "CRAWL_ASK_FAILED")
+		return c.Status(status).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+	metrics.RecordLLMExtract(string(provider), modelName, true)
+
+	answer, _ := res.Fields["answer"].(string)
+	answer = strings.TrimSpace(answer)
+	found := answer != ""
+	if v, ok := res.Fields["found"].(bool); ok {
+		found = found && v
+	}
+	citations := []AskCitation{}
+	if found {
+		citations = askCitations(res.Fields["citations"], answer, sources)
+	}
+	return c.JSON(CrawlAskResponse{
+		Success:   true,
+		Found:     found,
+		Answer:    answer,
+		Citations: citations,
+	})
+}
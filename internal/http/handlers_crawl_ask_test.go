@@ -0,0 +1,68 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"raito/internal/db"
+)
+
+func TestAskQueryTerms(t *testing.T) {
+	got := askQueryTerms("What is the price of the Pro plan in 2024? Is it the same Pro price for a team?")
+	want := []string{"price", "pro", "plan", "2024", "same", "team"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if terms := askQueryTerms("What is it?"); len(terms) != 0 {
+		t.Fatalf("expected only stop words to be dropped, got %v", terms)
+	}
+}
+
+func TestRankAskPassages(t *testing.T) {
+	docs := []db.Document{
+		{ID: 1, Url: "https://a.example/about", Markdown: sql.NullString{String: "# About\n\nWe are a small team building tools.", Valid: true}},
+		{
+			ID:       2,
+			Url:      "https://a.example/pricing",
+			Metadata: json.RawMessage(`{"title":"Pricing"}`),
+			Markdown: sql.NullString{String: "# Pricing\n\nThe Pro plan costs $20 per month.\n\n" + strings.Repeat("Unrelated filler text. ", 100), Valid: true},
+		},
+	}
+	terms := askQueryTerms("How much does the Pro plan cost?")
+	ranked := rankAskPassages(docs, terms)
+	if len(ranked) == 0 || ranked[0].doc != 1 || !strings.Contains(ranked[0].text, "Pro plan") {
+		t.Fatalf("expected the pricing passage first, got %+v", ranked)
+	}
+
+	annotations := map[int64]db.DocumentAnnotation{2: {DocumentID: 2, Title: sql.NullString{String: "Plans", Valid: true}}}
+	sources := selectAskSources(docs, annotations, ranked)
+	if len(sources) != 1 || sources[0].documentID != 2 || sources[0].title != "Plans" {
+		t.Fatalf("unexpected sources: %+v", sources)
+	}
+	if ctx := askContext(sources); !strings.HasPrefix(ctx, "## [1] Source: https://a.example/pricing\nTitle: Plans\n") {
+		t.Fatalf("unexpected context: %q", ctx)
+	}
+}
+
+func TestAskCitations(t *testing.T) {
+	sources := []askSource{
+		{documentID: 1, url: "https://a.example/one", passages: []string{"first"}},
+		{documentID: 2, url: "https://a.example/two", passages: []string{strings.Repeat("é", 400)}},
+	}
+
+	citations := askCitations([]any{float64(2), "[1]", float64(2), float64(9)}, "", sources)
+	if len(citations) != 2 || citations[0].Index != 1 || citations[1].Index != 2 || citations[1].DocumentID != 2 {
+		t.Fatalf("unexpected citations: %+v", citations)
+	}
+	if ex := citations[1].Excerpt; len(ex) > askExcerptChars+len("…") || !strings.HasSuffix(ex, "…") {
+		t.Fatalf("expected a cut excerpt, got %d bytes", len(ex))
+	}
+
+	fromAnswer := askCitations(nil, "It costs $20 [2], billed monthly [2].", sources)
+	if len(fromAnswer) != 1 || fromAnswer[0].URL != "https://a.example/two" {
+		t.Fatalf("expected citations parsed from the answer, got %+v", fromAnswer)
+	}
+}
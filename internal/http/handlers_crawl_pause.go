@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// crawlPauseHandler implements POST /v1/crawl/:id/pause. A queued crawl is
+// held back; a running one stops handing out URLs, lets the pages in
+// flight finish and saves its frontier so it can be resumed.
+func crawlPauseHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	job, ok, err := crawlJobParam(c, st)
+	if !ok {
+		return err
+	}
+
+	// A rescrape runs to completion; only full crawls save a frontier.
+	active, err := db.New(st.DB).CountActiveCrawlRescrapes(c.Context(), job.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(CrawlResponse{
+			Success: false,
+			Code:    "CRAWL_PAUSE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if active > 0 {
+		return c.Status(fiber.StatusConflict).JSON(CrawlResponse{
+			Success: false,
+			Code:    "CRAWL_PAUSE_NOT_AVAILABLE",
+			Error:   "crawl rescrapes cannot be paused",
+		})
+	}
+
+	if err := st.PauseJob(c.Context(), job.ID); err != nil {
+		if errors.Is(err, store.ErrJobNotActive) {
+			return c.Status(fiber.StatusConflict).JSON(CrawlResponse{
+				Success: false,
+				Code:    "JOB_NOT_ACTIVE",
+				Error:   "crawl is not queued or running",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CrawlResponse{
+			Success: false,
+			Code:    "CRAWL_PAUSE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordCrawlPauseAudit(c, st, job, "crawl.pause")
+	return c.Status(fiber.StatusOK).JSON(CrawlResponse{
+		Success: true,
+		ID:      job.ID.String(),
+		URL:     job.Url,
+		Status:  CrawlStatusPaused,
+	})
+}
+
+// crawlResumeHandler implements POST /v1/crawl/:id/resume. The crawl is
+// queued again and continues from its saved frontier.
+func crawlResumeHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	job, ok, err := crawlJobParam(c, st)
+	if !ok {
+		return err
+	}
+
+	// The worker of a crawl paused while running keeps its heartbeat until
+	// the frontier is saved; resuming earlier would start a second run.
+	if _, err := db.New(st.DB).GetJobHeartbeat(c.Context(), job.ID); err == nil {
+		return c.Status(fiber.StatusConflict).JSON(CrawlResponse{
+			Success: false,
+			Code:    "CRAWL_PAUSING",
+			Error:   "crawl is still stopping; try again shortly",
+		})
+	}
+
+	if err := st.ResumeJob(c.Context(), job.ID); err != nil {
+		if errors.Is(err, store.ErrJobNotPaused) {
+			return c.Status(fiber.StatusConflict).JSON(CrawlResponse{
+				Success: false,
+				Code:    "JOB_NOT_PAUSED",
+				Error:   "crawl is not paused",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CrawlResponse{
+			Success: false,
+			Code:    "CRAWL_RESUME_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordCrawlPauseAudit(c, st, job, "crawl.resume")
+	return c.Status(fiber.StatusOK).JSON(CrawlResponse{
+		Success: true,
+		ID:      job.ID.String(),
+		URL:     job.Url,
+		Status:  CrawlStatusPending,
+	})
+}
+
+func recordCrawlPauseAudit(c *fiber.Ctx, st *store.Store, job db.Job, action string) {
+	var tenantID *uuid.UUID
+	if job.TenantID.Valid {
+		tenantID = &job.TenantID.UUID
+	}
+	recordAuditEvent(c, st, action, auditEventOptions{
+		TenantID:     tenantID,
+		ResourceType: "job",
+		ResourceID:   job.ID.String(),
+	})
+}
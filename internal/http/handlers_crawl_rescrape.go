@@ -0,0 +1,318 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/jobinput"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+// CrawlRescrapeRequest is the body of POST /v1/crawl/:id/rescrape.
+type CrawlRescrapeRequest struct {
+	URLs []string `json:"urls"`
+	// Mode is "replace" (the default) or "version".
+	Mode string `json:"mode,omitempty"`
+}
+
+// CrawlRescrapeResponse describes a rescrape of some URLs of a crawl.
+type CrawlRescrapeResponse struct {
+	Success bool   `json:"success"`
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+	ID      string `json:"id,omitempty"`
+	// URL is where the rescrape's status and results can be polled.
+	URL   string `json:"url,omitempty"`
+	JobID string `json:"jobId,omitempty"`
+	// Status is "pending", "running", "completed" or "failed".
+	Status      string                `json:"status,omitempty"`
+	Mode        string                `json:"mode,omitempty"`
+	URLs        []string              `json:"urls,omitempty"`
+	Results     []CrawlRescrapeResult `json:"results,omitempty"`
+	CreatedAt   string                `json:"createdAt,omitempty"`
+	CompletedAt string                `json:"completedAt,omitempty"`
+}
+
+// normalizeRescrapeRequest trims and de-duplicates the requested URLs and
+// checks their count and the mode.
+func normalizeRescrapeRequest(req *CrawlRescrapeRequest) error {
+	seen := map[string]bool{}
+	var urls []string
+	for _, u := range req.URLs {
+		u = strings.TrimSpace(u)
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("Missing required field 'urls'")
+	}
+	if len(urls) > maxCrawlRescrapeURLs {
+		return fmt.Errorf("too many urls; maximum is %d", maxCrawlRescrapeURLs)
+	}
+	req.URLs = urls
+
+	switch strings.ToLower(strings.TrimSpace(req.Mode)) {
+	case "", crawlRescrapeReplace:
+		req.Mode = crawlRescrapeReplace
+	case crawlRescrapeVersion:
+		req.Mode = crawlRescrapeVersion
+	default:
+		return fmt.Errorf("mode must be %q or %q", crawlRescrapeReplace, crawlRescrapeVersion)
+	}
+	return nil
+}
+
+// rescrapeOutOfScope returns the first of urls the crawl may not scrape:
+// URLs with a document in the crawl are always allowed, others must be
+// absolute http(s) URLs on the crawl's hosts.
+func rescrapeOutOfScope(urls []string, stored map[string]bool, inScope func(string) bool) string {
+	for _, raw := range urls {
+		if stored[raw] {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || !inScope(raw) {
+			return raw
+		}
+	}
+	return ""
+}
+
+// crawlJobParam resolves the crawl job named by the :id parameter of
+// rescrape, pause and resume requests. When the caller may not see it, it
+// writes the error response and returns ok=false.
+func crawlJobParam(c *fiber.Ctx, st *store.Store) (db.Job, bool, error) {
+	notFound := func() (db.Job, bool, error) {
+		return db.Job{}, false, c.Status(fiber.StatusNotFound).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "crawl job not found",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return db.Job{}, false, c.Status(fiber.StatusBadRequest).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid crawl id",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFound()
+		}
+		return db.Job{}, false, c.Status(fiber.StatusInternalServerError).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "CRAWL_JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) || job.Type != "crawl" {
+		return notFound()
+	}
+	return job, true, nil
+}
+
+// crawlRescrapeHandler queues some URLs of a finished crawl to be scraped
+// again with the crawl's options. The crawl job goes back to pending and
+// completes again once they are stored.
+func crawlRescrapeHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	var reqBody CrawlRescrapeRequest
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if err := normalizeRescrapeRequest(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	job, ok, err := crawlJobParam(c, st)
+	if !ok {
+		return err
+	}
+
+	// Zero-retention crawls keep nothing to rescrape into.
+	if job.ZeroRetention || job.PurgedAt.Valid {
+		return c.Status(fiber.StatusConflict).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "CRAWL_RESCRAPE_NOT_AVAILABLE",
+			Error:   "zero-retention crawls cannot be rescraped",
+		})
+	}
+	if job.Status != "completed" && job.Status != "failed" {
+		return c.Status(fiber.StatusConflict).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "JOB_NOT_FINISHED",
+			Error:   "crawl is still queued or running",
+		})
+	}
+
+	var crawlReq CrawlRequest
+	if err := jobinput.Decode(job.Type, job.Input, &crawlReq); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "CRAWL_RESCRAPE_FAILED",
+			Error:   "invalid crawl job input: " + err.Error(),
+		})
+	}
+	if crawlReq.URL == "" {
+		crawlReq.URL = job.Url
+	}
+
+	known, err := db.New(st.DB).ListJobDocumentURLs(c.Context(), db.ListJobDocumentURLsParams{JobID: job.ID, Urls: reqBody.URLs})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "CRAWL_RESCRAPE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	stored := make(map[string]bool, len(known))
+	for _, u := range known {
+		stored[u] = true
+	}
+	scope, err := crawlScope(c.Context(), cfg, crawlReq)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "CRAWL_RESCRAPE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if u := rescrapeOutOfScope(reqBody.URLs, stored, scope.InScope); u != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   fmt.Sprintf("url %q is not part of this crawl", u),
+		})
+	}
+
+	var userID *uuid.UUID
+	if p, ok := c.Locals("principal").(Principal); ok {
+		userID = p.UserID
+	}
+	rescrape, err := st.QueueCrawlRescrape(c.Context(), job.ID, reqBody.URLs, reqBody.Mode, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrJobNotFinished) {
+			return c.Status(fiber.StatusConflict).JSON(CrawlRescrapeResponse{
+				Success: false,
+				Code:    "JOB_NOT_FINISHED",
+				Error:   "crawl is still queued or running",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "CRAWL_RESCRAPE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	var tenantID *uuid.UUID
+	if job.TenantID.Valid {
+		tenantID = &job.TenantID.UUID
+	}
+	recordAuditEvent(c, st, "crawl.rescrape", auditEventOptions{
+		TenantID:     tenantID,
+		ResourceType: "job",
+		ResourceID:   job.ID.String(),
+		Metadata:     map[string]any{"urls": len(reqBody.URLs), "mode": reqBody.Mode},
+	})
+
+	return c.Status(fiber.StatusAccepted).JSON(rescrapeResponse(c, job, rescrape))
+}
+
+// crawlRescrapeStatusHandler returns a rescrape with its per-URL results
+// once it has finished.
+func crawlRescrapeStatusHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	job, ok, err := crawlJobParam(c, st)
+	if !ok {
+		return err
+	}
+	rescrapeID, err := uuid.Parse(c.Params("rescrapeId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid rescrape id",
+		})
+	}
+
+	rescrape, err := db.New(st.DB).GetCrawlRescrape(c.Context(), db.GetCrawlRescrapeParams{ID: rescrapeID, JobID: job.ID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(CrawlRescrapeResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "rescrape not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CrawlRescrapeResponse{
+			Success: false,
+			Code:    "CRAWL_RESCRAPE_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	return c.JSON(rescrapeResponse(c, job, rescrape))
+}
+
+func rescrapeResponse(c *fiber.Ctx, job db.Job, r db.CrawlRescrape) CrawlRescrapeResponse {
+	resp := CrawlRescrapeResponse{
+		Success:   true,
+		ID:        r.ID.String(),
+		URL:       c.Protocol() + "://" + c.Hostname() + "/v1/crawl/" + job.ID.String() + "/rescrape/" + r.ID.String(),
+		JobID:     job.ID.String(),
+		Status:    r.Status,
+		Mode:      r.Mode,
+		Error:     r.Error.String,
+		CreatedAt: r.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	_ = json.Unmarshal(r.Urls, &resp.URLs)
+	if r.Results.Valid {
+		_ = json.Unmarshal(r.Results.RawMessage, &resp.Results)
+	}
+	if r.CompletedAt.Valid {
+		resp.CompletedAt = r.CompletedAt.Time.UTC().Format(time.RFC3339)
+	}
+
+	// A rescrape whose crawl job ended without running it, e.g. because
+	// its worker was lost, will not run anymore.
+	if (r.Status == "pending" || r.Status == "running") && (job.Status == "completed" || jobs.PublicStatus(job.Status) == "failed") {
+		resp.Status = "failed"
+		resp.Error = "the crawl job ended before the rescrape ran"
+		if job.Error.Valid {
+			resp.Error = job.Error.String
+		}
+	}
+	return resp
+}
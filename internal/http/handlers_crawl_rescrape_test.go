@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"raito/internal/config"
+	"raito/internal/db"
+)
+
+func TestNormalizeRescrapeRequest(t *testing.T) {
+	req := CrawlRescrapeRequest{URLs: []string{" https://example.com/a ", "https://example.com/a", "", "https://example.com/b"}, Mode: "Version"}
+	if err := normalizeRescrapeRequest(&req); err != nil {
+		t.Fatalf("normalizeRescrapeRequest: %v", err)
+	}
+	if want := []string{"https://example.com/a", "https://example.com/b"}; !reflect.DeepEqual(req.URLs, want) {
+		t.Fatalf("urls = %v, want %v", req.URLs, want)
+	}
+	if req.Mode != crawlRescrapeVersion {
+		t.Fatalf("expected version mode, got %q", req.Mode)
+	}
+
+	defaults := CrawlRescrapeRequest{URLs: []string{"https://example.com/"}}
+	if err := normalizeRescrapeRequest(&defaults); err != nil || defaults.Mode != crawlRescrapeReplace {
+		t.Fatalf("expected replace by default, got %q (%v)", defaults.Mode, err)
+	}
+
+	tooMany := make([]string, maxCrawlRescrapeURLs+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	for _, bad := range []CrawlRescrapeRequest{
+		{},
+		{URLs: []string{" "}},
+		{URLs: tooMany},
+		{URLs: []string{"https://example.com/"}, Mode: "append"},
+	} {
+		if err := normalizeRescrapeRequest(&bad); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestRescrapeOutOfScope(t *testing.T) {
+	cfg := &config.Config{}
+	scope, err := crawlScope(context.Background(), cfg, CrawlRequest{URL: "https://example.com/docs", URLs: []string{"https://blog.example.org/"}})
+	if err != nil {
+		t.Fatalf("crawlScope: %v", err)
+	}
+	stored := map[string]bool{"https://cdn.example.net/page": true}
+
+	ok := []string{
+		"https://example.com/pricing",
+		"https://blog.example.org/post",
+		// Redirected pages are stored under hosts the crawl did not start on.
+		"https://cdn.example.net/page",
+	}
+	if u := rescrapeOutOfScope(ok, stored, scope.InScope); u != "" {
+		t.Fatalf("expected every url in scope, got %q", u)
+	}
+	for _, bad := range []string{"https://evil.example/", "/relative", "ftp://example.com/file"} {
+		if u := rescrapeOutOfScope([]string{"https://example.com/", bad}, stored, scope.InScope); u != bad {
+			t.Fatalf("expected %q to be out of scope, got %q", bad, u)
+		}
+	}
+
+	allowExternal := true
+	external, err := crawlScope(context.Background(), cfg, CrawlRequest{URL: "https://example.com/", AllowExternalLinks: &allowExternal})
+	if err != nil {
+		t.Fatalf("crawlScope: %v", err)
+	}
+	if u := rescrapeOutOfScope([]string{"https://other.example/"}, nil, external.InScope); u != "" {
+		t.Fatalf("expected external links to be allowed, got %q", u)
+	}
+}
+
+func TestCurrentCrawlDocuments(t *testing.T) {
+	meta := func(prev int64) json.RawMessage {
+		raw, _ := json.Marshal(map[string]any{"statusCode": 200, "previousDocumentId": prev})
+		return raw
+	}
+	docs := []db.Document{
+		{ID: 1, Url: "https://example.com/a", Metadata: meta(0)},
+		{ID: 2, Url: "https://example.com/b", Metadata: meta(0)},
+		{ID: 3, Url: "https://example.com/a", Metadata: meta(1)},
+		{ID: 4, Url: "https://example.com/a", Metadata: meta(3)},
+	}
+	var ids []int64
+	for _, d := range currentCrawlDocuments(docs) {
+		ids = append(ids, d.ID)
+	}
+	if want := []int64{2, 4}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("current documents = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"raito/internal/config"
+)
+
+func TestNormalizeCrawlSeeds(t *testing.T) {
+	req := CrawlRequest{
+		URL:  "https://example.com",
+		URLs: []string{" https://docs.example.com/guide ", "https://example.com", "", "https://blog.example.com"},
+	}
+	if err := normalizeCrawlSeeds(&req); err != nil {
+		t.Fatalf("normalizeCrawlSeeds: %v", err)
+	}
+	if req.URL != "https://example.com" {
+		t.Fatalf("expected url to stay the first seed, got %q", req.URL)
+	}
+	if want := []string{"https://docs.example.com/guide", "https://blog.example.com"}; !reflect.DeepEqual(req.URLs, want) {
+		t.Fatalf("urls = %v, want %v", req.URLs, want)
+	}
+
+	onlyURLs := CrawlRequest{URLs: []string{"https://a.example.com", "https://b.example.com"}}
+	if err := normalizeCrawlSeeds(&onlyURLs); err != nil {
+		t.Fatalf("normalizeCrawlSeeds: %v", err)
+	}
+	if onlyURLs.URL != "https://a.example.com" || !reflect.DeepEqual(onlyURLs.URLs, []string{"https://b.example.com"}) {
+		t.Fatalf("expected the first of urls to become url, got %+v", onlyURLs)
+	}
+}
+
+func TestNormalizeCrawlSeeds_Rejects(t *testing.T) {
+	tooMany := make([]string, maxCrawlSeeds+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	cases := []struct {
+		req     CrawlRequest
+		wantErr string
+	}{
+		{CrawlRequest{URLs: []string{" "}}, "Missing required field"},
+		{CrawlRequest{URLs: tooMany}, "too many start urls"},
+		{CrawlRequest{URL: "https://example.com", URLs: []string{"example.org"}}, "invalid start url"},
+		{CrawlRequest{URL: "ftp://example.com"}, "invalid start url"},
+	}
+	for i, tc := range cases {
+		err := normalizeCrawlSeeds(&tc.req)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("case %d: err = %v, want %q", i, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCrawlMaxDepth(t *testing.T) {
+	cfg := &config.Config{}
+	depth := func(n int) *int { return &n }
+
+	if got := crawlMaxDepth(cfg, CrawlRequest{}); got != -1 {
+		t.Fatalf("expected no cap without a default, got %d", got)
+	}
+	cfg.Crawler.MaxDepthDefault = 3
+	if got := crawlMaxDepth(cfg, CrawlRequest{}); got != 3 {
+		t.Fatalf("expected the configured default, got %d", got)
+	}
+	if got := crawlMaxDepth(cfg, CrawlRequest{MaxDiscoveryDepth: depth(0)}); got != 0 {
+		t.Fatalf("expected maxDiscoveryDepth 0 to crawl only depth 0, got %d", got)
+	}
+	if got := crawlMaxDepth(cfg, CrawlRequest{MaxDiscoveryDepth: depth(5)}); got != 5 {
+		t.Fatalf("expected the request to override the default, got %d", got)
+	}
+}
+
+func TestCrawlDelay(t *testing.T) {
+	cfg := &config.Config{}
+	delay := func(n float64) *float64 { return &n }
+
+	if got := crawlDelay(cfg, CrawlRequest{}); got != 0 {
+		t.Fatalf("expected no delay without a default, got %v", got)
+	}
+	cfg.Crawler.DelayMs = 500
+	if got := crawlDelay(cfg, CrawlRequest{}); got != 500*time.Millisecond {
+		t.Fatalf("expected the configured default, got %v", got)
+	}
+	if got := crawlDelay(cfg, CrawlRequest{Delay: delay(1.5)}); got != 1500*time.Millisecond {
+		t.Fatalf("expected the request's delay in seconds, got %v", got)
+	}
+	if got := crawlDelay(cfg, CrawlRequest{Delay: delay(0)}); got != 0 {
+		t.Fatalf("expected delay 0 to override the default, got %v", got)
+	}
+}
@@ -0,0 +1,219 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/model"
+	"raito/internal/services"
+	"raito/internal/store"
+)
+
+// maxAnnotationNotesLength bounds the notes stored on one document.
+const maxAnnotationNotesLength = 10000
+
+// maxAnnotationTitleLength bounds a corrected document title.
+const maxAnnotationTitleLength = 500
+
+// DocumentAnnotationRequest updates a document's annotation. Omitted
+// fields are left unchanged; an empty notes or title clears it.
+type DocumentAnnotationRequest struct {
+	Notes    *string `json:"notes,omitempty"`
+	Title    *string `json:"title,omitempty"`
+	Excluded *bool   `json:"excluded,omitempty"`
+}
+
+// AnnotatedDocument identifies a document and carries its annotation.
+type AnnotatedDocument struct {
+	ID         int64                     `json:"id"`
+	JobID      string                    `json:"jobId"`
+	URL        string                    `json:"url"`
+	Annotation *model.DocumentAnnotation `json:"annotation"`
+}
+
+type DocumentAnnotationResponse struct {
+	Success  bool               `json:"success"`
+	Code     string             `json:"code,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Document *AnnotatedDocument `json:"document,omitempty"`
+}
+
+// applyDocumentAnnotation merges a PATCH request into the current
+// annotation values.
+func applyDocumentAnnotation(current db.DocumentAnnotation, req DocumentAnnotationRequest) (db.DocumentAnnotation, error) {
+	next := current
+	if req.Notes != nil {
+		notes := strings.TrimSpace(*req.Notes)
+		if len(notes) > maxAnnotationNotesLength {
+			return current, errors.New("notes must be at most " + strconv.Itoa(maxAnnotationNotesLength) + " characters")
+		}
+		next.Notes = sql.NullString{String: notes, Valid: notes != ""}
+	}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if len(title) > maxAnnotationTitleLength {
+			return current, errors.New("title must be at most " + strconv.Itoa(maxAnnotationTitleLength) + " characters")
+		}
+		next.Title = sql.NullString{String: title, Valid: title != ""}
+	}
+	if req.Excluded != nil {
+		next.Excluded = *req.Excluded
+	}
+	return next, nil
+}
+
+// withoutExcludedDocuments drops documents annotated as excluded.
+func withoutExcludedDocuments(docs []db.Document, annotations map[int64]db.DocumentAnnotation) []db.Document {
+	out := make([]db.Document, 0, len(docs))
+	for _, d := range docs {
+		if a, ok := annotations[d.ID]; ok && a.Excluded {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// jobDocumentAnnotateHandler implements PATCH /v1/jobs/:id/documents/:docId.
+// Anyone who can see the job may annotate its documents.
+func jobDocumentAnnotateHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	if p.TenantID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to annotate documents",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid job id",
+		})
+	}
+	docID, err := strconv.ParseInt(c.Params("docId"), 10, 64)
+	if err != nil || docID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid document id",
+		})
+	}
+
+	var req DocumentAnnotationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if req.Notes == nil && req.Title == nil && req.Excluded == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "at least one of notes, title, or excluded is required",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil || !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID || !jobViewerFor(c, st, p).CanSee(job) {
+		return c.Status(fiber.StatusNotFound).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "job not found",
+		})
+	}
+
+	doc, err := q.GetJobDocument(c.Context(), db.GetJobDocumentParams{ID: docID, JobID: jobID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(DocumentAnnotationResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "document not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "DOCUMENT_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	current, err := q.GetDocumentAnnotation(c.Context(), doc.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusInternalServerError).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "DOCUMENT_ANNOTATION_UPDATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	next, err := applyDocumentAnnotation(current, req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	saved, err := q.UpsertDocumentAnnotation(c.Context(), db.UpsertDocumentAnnotationParams{
+		DocumentID:      doc.ID,
+		JobID:           jobID,
+		Notes:           next.Notes,
+		Title:           next.Title,
+		Excluded:        next.Excluded,
+		UpdatedByUserID: uuid.NullUUID{UUID: *p.UserID, Valid: true},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(DocumentAnnotationResponse{
+			Success: false,
+			Code:    "DOCUMENT_ANNOTATION_UPDATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "job.document.annotate", auditEventOptions{
+		TenantID:     p.TenantID,
+		ResourceType: "document",
+		ResourceID:   strconv.FormatInt(doc.ID, 10),
+		Metadata: map[string]any{
+			"jobId":    jobID.String(),
+			"excluded": saved.Excluded,
+			"title":    saved.Title.Valid,
+			"notes":    saved.Notes.Valid,
+		},
+	})
+
+	return c.Status(fiber.StatusOK).JSON(DocumentAnnotationResponse{
+		Success: true,
+		Document: &AnnotatedDocument{
+			ID:         doc.ID,
+			JobID:      jobID.String(),
+			URL:        doc.Url,
+			Annotation: services.DocumentAnnotation(saved),
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"raito/internal/db"
+)
+
+func TestApplyDocumentAnnotation_MergesPatch(t *testing.T) {
+	current := db.DocumentAnnotation{
+		Notes: sql.NullString{String: "check pricing", Valid: true},
+		Title: sql.NullString{String: "Pricing", Valid: true},
+	}
+	notes, excluded := "", true
+
+	next, err := applyDocumentAnnotation(current, DocumentAnnotationRequest{Notes: &notes, Excluded: &excluded})
+	if err != nil {
+		t.Fatalf("applyDocumentAnnotation: %v", err)
+	}
+	if next.Notes.Valid {
+		t.Fatalf("expected empty notes to clear them, got %+v", next.Notes)
+	}
+	if !next.Title.Valid || next.Title.String != "Pricing" {
+		t.Fatalf("expected the omitted title to be kept, got %+v", next.Title)
+	}
+	if !next.Excluded {
+		t.Fatalf("expected excluded to be set")
+	}
+
+	long := strings.Repeat("x", maxAnnotationTitleLength+1)
+	if _, err := applyDocumentAnnotation(current, DocumentAnnotationRequest{Title: &long}); err == nil {
+		t.Fatalf("expected an error for an overlong title")
+	}
+}
+
+func TestWithoutExcludedDocuments(t *testing.T) {
+	docs := []db.Document{{ID: 1}, {ID: 2}, {ID: 3}}
+	annotations := map[int64]db.DocumentAnnotation{
+		2: {DocumentID: 2, Excluded: true},
+		3: {DocumentID: 3, Notes: sql.NullString{String: "keep", Valid: true}},
+	}
+
+	kept := withoutExcludedDocuments(docs, annotations)
+	if len(kept) != 2 || kept[0].ID != 1 || kept[1].ID != 3 {
+		t.Fatalf("expected documents 1 and 3, got %+v", kept)
+	}
+}
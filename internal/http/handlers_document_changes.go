@@ -0,0 +1,176 @@
+package http
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/db"
+	"raito/internal/services"
+	"raito/internal/store"
+)
+
+const (
+	defaultDocumentChangesLimit = 100
+	maxDocumentChangesLimit     = 1000
+)
+
+// DocumentChange is one entry of the document change feed. Created and
+// updated entries both mean the document should be (re)indexed; deleted
+// entries mean it is gone.
+type DocumentChange struct {
+	// Cursor resumes the feed after this entry.
+	Cursor     string    `json:"cursor"`
+	Type       string    `json:"type"` // "created", "updated" or "deleted"
+	DocumentID int64     `json:"documentId"`
+	JobID      string    `json:"jobId"`
+	URL        string    `json:"url"`
+	ChangedAt  time.Time `json:"changedAt"`
+	// Document is the document's current content when content=true and
+	// the document still exists.
+	Document *CollectionDocument `json:"document,omitempty"`
+}
+
+type DocumentChangesResponse struct {
+	Success bool             `json:"success"`
+	Code    string           `json:"code,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Changes []DocumentChange `json:"changes"`
+	// NextCursor is passed as since to fetch the following changes. It is
+	// the request's cursor when no changes were returned.
+	NextCursor string `json:"nextCursor"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// documentChangesHandler returns the change feed of the active tenant's
+// documents after the since cursor, oldest first. Without since the feed
+// starts from the beginning, which covers every existing document.
+func documentChangesHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	p, ok := c.Locals("principal").(Principal)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(DocumentChangesResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "authentication is required",
+		})
+	}
+	if p.TenantID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentChangesResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to read document changes",
+		})
+	}
+
+	var since int64
+	if v := c.Query("since"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(DocumentChangesResponse{
+				Success: false,
+				Code:    "INVALID_CURSOR",
+				Error:   "since must be a cursor returned by this endpoint",
+			})
+		}
+		since = n
+	}
+
+	limit := defaultDocumentChangesLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(DocumentChangesResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid limit value",
+			})
+		}
+		limit = min(n, maxDocumentChangesLimit)
+	}
+	withContent := c.QueryBool("content", false)
+
+	// One extra row tells whether more changes are ready.
+	entries, err := st.ListDocumentChanges(c.Context(), *p.TenantID, jobViewerFor(c, st, p), since, int32(limit+1), withContent)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(DocumentChangesResponse{
+			Success: false,
+			Code:    "DOCUMENT_CHANGES_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[:limit]
+	}
+
+	var annotations map[int64]db.DocumentAnnotation
+	if withContent {
+		var docs []db.Document
+		for _, e := range entries {
+			if e.Document != nil {
+				docs = append(docs, *e.Document)
+			}
+		}
+		annotations, _ = st.DocumentAnnotations(c.Context(), docs)
+	}
+
+	changes := make([]DocumentChange, 0, len(entries))
+	next := since
+	for _, e := range entries {
+		changes = append(changes, documentChangeFromEntry(e, annotations))
+		next = e.Seq
+	}
+
+	return c.Status(fiber.StatusOK).JSON(DocumentChangesResponse{
+		Success:    true,
+		Changes:    changes,
+		NextCursor: strconv.FormatInt(next, 10),
+		HasMore:    hasMore,
+	})
+}
+
+// documentChangeFromEntry converts a feed row into its API form, applying
+// any annotation title override to the document's metadata.
+func documentChangeFromEntry(e store.DocumentChangeEntry, annotations map[int64]db.DocumentAnnotation) DocumentChange {
+	change := DocumentChange{
+		Cursor:     strconv.FormatInt(e.Seq, 10),
+		Type:       e.ChangeType,
+		DocumentID: e.DocumentID,
+		JobID:      e.JobID.String(),
+		URL:        e.Url,
+		ChangedAt:  e.ChangedAt,
+	}
+	d := e.Document
+	if d == nil {
+		return change
+	}
+	doc := &CollectionDocument{
+		ID:        d.ID,
+		JobID:     d.JobID.String(),
+		Type:      d.Type,
+		URL:       d.Url,
+		Markdown:  d.Markdown.String,
+		CreatedAt: d.CreatedAt,
+	}
+	if d.StatusCode.Valid {
+		doc.StatusCode = int(d.StatusCode.Int32)
+	}
+	if len(d.Metadata) > 0 {
+		_ = json.Unmarshal(d.Metadata, &doc.Metadata)
+	}
+	if a, ok := annotations[d.ID]; ok {
+		doc.Annotation = services.DocumentAnnotation(a)
+		if a.Title.Valid {
+			if doc.Metadata == nil {
+				doc.Metadata = map[string]any{}
+			}
+			doc.Metadata["title"] = a.Title.String
+		}
+	}
+	change.Document = doc
+	return change
+}
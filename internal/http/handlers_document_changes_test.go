@@ -0,0 +1,61 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+func TestDocumentChangeFromEntry(t *testing.T) {
+	jobID := uuid.New()
+	changedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	deleted := documentChangeFromEntry(store.DocumentChangeEntry{DocumentChange: db.DocumentChange{
+		Seq:        41,
+		DocumentID: 7,
+		JobID:      jobID,
+		Url:        "https://example.com/old",
+		ChangeType: "deleted",
+		ChangedAt:  changedAt,
+	}}, nil)
+	if deleted.Cursor != "41" || deleted.Type != "deleted" || deleted.JobID != jobID.String() || deleted.Document != nil {
+		t.Fatalf("unexpected deleted change: %+v", deleted)
+	}
+
+	entry := store.DocumentChangeEntry{
+		DocumentChange: db.DocumentChange{
+			Seq:        42,
+			DocumentID: 8,
+			JobID:      jobID,
+			Url:        "https://example.com/pricing",
+			ChangeType: "updated",
+			ChangedAt:  changedAt,
+		},
+		Document: &db.Document{
+			ID:         8,
+			JobID:      jobID,
+			Url:        "https://example.com/pricing",
+			Markdown:   sql.NullString{String: "# Pricing", Valid: true},
+			Metadata:   json.RawMessage(`{"title":"Pricing"}`),
+			StatusCode: sql.NullInt32{Int32: 200, Valid: true},
+			Type:       "page",
+		},
+	}
+	annotations := map[int64]db.DocumentAnnotation{
+		8: {DocumentID: 8, Title: sql.NullString{String: "Plans", Valid: true}, Excluded: true},
+	}
+	updated := documentChangeFromEntry(entry, annotations)
+	doc := updated.Document
+	if doc == nil || doc.Markdown != "# Pricing" || doc.StatusCode != 200 {
+		t.Fatalf("expected document content, got %+v", doc)
+	}
+	if doc.Metadata["title"] != "Plans" || doc.Annotation == nil || !doc.Annotation.Excluded {
+		t.Fatalf("expected the annotation to apply, got %+v", doc)
+	}
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/db"
+	"raito/internal/store"
+	"raito/internal/textdiff"
+)
+
+const (
+	defaultDocumentDiffContext = 3
+	maxDocumentDiffContext     = 20
+)
+
+var (
+	// markdownHeadingRe matches ATX headings such as "## Pricing".
+	markdownHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	// markdownLinkRe matches the target of inline links and images.
+	markdownLinkRe = regexp.MustCompile(`\]\(\s*<?([^\s)>]+)>?(?:\s+"[^"]*")?\s*\)`)
+)
+
+// DocumentDiffSide identifies one of the two documents of a diff.
+type DocumentDiffSide struct {
+	ID        int64     `json:"id"`
+	JobID     string    `json:"jobId"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DocumentDiffSummary describes the structural changes between two
+// documents' markdown.
+type DocumentDiffSummary struct {
+	LinesAdded      int      `json:"linesAdded"`
+	LinesRemoved    int      `json:"linesRemoved"`
+	HeadingsAdded   []string `json:"headingsAdded"`
+	HeadingsRemoved []string `json:"headingsRemoved"`
+	LinksAdded      []string `json:"linksAdded"`
+	LinksRemoved    []string `json:"linksRemoved"`
+	TitleChanged    bool     `json:"titleChanged"`
+}
+
+type DocumentDiffResponse struct {
+	Success bool              `json:"success"`
+	Code    string            `json:"code,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	A       *DocumentDiffSide `json:"a,omitempty"`
+	B       *DocumentDiffSide `json:"b,omitempty"`
+	// Identical is true when both documents have the same markdown.
+	Identical bool `json:"identical"`
+	// Diff is a unified diff from a's markdown to b's; empty when
+	// identical.
+	Diff    string               `json:"diff"`
+	Summary *DocumentDiffSummary `json:"summary,omitempty"`
+}
+
+// markdownHeadings returns the headings of a markdown document, with their
+// level markers ("## Pricing"), skipping fenced code blocks.
+func markdownHeadings(lines []string) []string {
+	var out []string
+	fenced := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		if m := markdownHeadingRe.FindStringSubmatch(trimmed); m != nil && m[2] != "" {
+			out = append(out, m[1]+" "+m[2])
+		}
+	}
+	return out
+}
+
+// markdownLinks returns the distinct link targets of a markdown document
+// in order of first appearance.
+func markdownLinks(markdown string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range markdownLinkRe.FindAllStringSubmatch(markdown, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			out = append(out, m[1])
+		}
+	}
+	return out
+}
+
+// listDelta returns the entries of b not in a and of a not in b, counting
+// repeated entries: a heading that appears twice in b and once in a is
+// reported as added once.
+func listDelta(a, b []string) (added, removed []string) {
+	counts := map[string]int{}
+	for _, s := range a {
+		counts[s]++
+	}
+	added = []string{}
+	for _, s := range b {
+		if counts[s] > 0 {
+			counts[s]--
+			continue
+		}
+		added = append(added, s)
+	}
+	counts = map[string]int{}
+	for _, s := range b {
+		counts[s]++
+	}
+	removed = []string{}
+	for _, s := range a {
+		if counts[s] > 0 {
+			counts[s]--
+			continue
+		}
+		removed = append(removed, s)
+	}
+	return added, removed
+}
+
+// documentTitle returns the title recorded in a document's metadata.
+func documentTitle(doc db.Document) string {
+	var meta map[string]any
+	if err := json.Unmarshal(doc.Metadata, &meta); err != nil {
+		return ""
+	}
+	title, _ := meta["title"].(string)
+	return title
+}
+
+func documentDiffSide(doc db.Document) *DocumentDiffSide {
+	return &DocumentDiffSide{
+		ID:        doc.ID,
+		JobID:     doc.JobID.String(),
+		URL:       doc.Url,
+		Title:     documentTitle(doc),
+		CreatedAt: doc.CreatedAt,
+	}
+}
+
+// diffMarkdown compares two markdown texts line by line, returning a
+// unified diff between the files named aName and bName and a summary
+// whose TitleChanged is left to the caller.
+func diffMarkdown(a, b, aName, bName string, contextLines int) (string, *DocumentDiffSummary) {
+	aLines := textdiff.SplitLines(a)
+	bLines := textdiff.SplitLines(b)
+	ops := textdiff.Lines(aLines, bLines)
+
+	summary := &DocumentDiffSummary{}
+	summary.LinesAdded, summary.LinesRemoved = textdiff.Stats(ops)
+	summary.HeadingsAdded, summary.HeadingsRemoved = listDelta(markdownHeadings(aLines), markdownHeadings(bLines))
+	summary.LinksAdded, summary.LinksRemoved = listDelta(markdownLinks(a), markdownLinks(b))
+	return textdiff.Unified(ops, aName, bName, contextLines), summary
+}
+
+// diffDocuments compares two documents' markdown line by line.
+func diffDocuments(a, b db.Document, contextLines int) DocumentDiffResponse {
+	diff, summary := diffMarkdown(a.Markdown.String, b.Markdown.String, "a/"+strconv.FormatInt(a.ID, 10), "b/"+strconv.FormatInt(b.ID, 10), contextLines)
+	summary.TitleChanged = documentTitle(a) != documentTitle(b)
+
+	return DocumentDiffResponse{
+		Success:   true,
+		A:         documentDiffSide(a),
+		B:         documentDiffSide(b),
+		Identical: summary.LinesAdded == 0 && summary.LinesRemoved == 0,
+		Diff:      diff,
+		Summary:   summary,
+	}
+}
+
+// documentDiffHandler implements GET /v1/documents/diff?a=<docID>&b=<docID>.
+// Both documents must belong to jobs the caller can see; they need not be
+// from the same job, so a page can be compared across crawls.
+func documentDiffHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(DocumentDiffResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	if p.TenantID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentDiffResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to view documents",
+		})
+	}
+
+	var ids [2]int64
+	for i, name := range []string{"a", "b"} {
+		id, err := strconv.ParseInt(c.Query(name), 10, 64)
+		if err != nil || id <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(DocumentDiffResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "query parameters 'a' and 'b' must be document ids",
+			})
+		}
+		ids[i] = id
+	}
+
+	contextLines := defaultDocumentDiffContext
+	if raw := c.Query("context"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > maxDocumentDiffContext {
+			return c.Status(fiber.StatusBadRequest).JSON(DocumentDiffResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "context must be an integer between 0 and " + strconv.Itoa(maxDocumentDiffContext),
+			})
+		}
+		contextLines = n
+	}
+
+	var docs [2]db.Document
+	for i, id := range ids {
+		doc, err := q.GetDocumentByID(c.Context(), id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusInternalServerError).JSON(DocumentDiffResponse{
+				Success: false,
+				Code:    "DOCUMENT_LOOKUP_FAILED",
+				Error:   err.Error(),
+			})
+		}
+		visible := err == nil
+		if visible {
+			job, jobErr := st.GetJobByID(c.Context(), doc.JobID)
+			visible = jobErr == nil && job.TenantID.Valid && job.TenantID.UUID == *p.TenantID && jobViewerFor(c, st, p).CanSee(job)
+		}
+		if !visible {
+			return c.Status(fiber.StatusNotFound).JSON(DocumentDiffResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "document " + strconv.FormatInt(id, 10) + " not found",
+			})
+		}
+		docs[i] = doc
+	}
+
+	return c.Status(fiber.StatusOK).JSON(diffDocuments(docs[0], docs[1], contextLines))
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+func TestDiffDocuments_Summary(t *testing.T) {
+	a := db.Document{
+		ID:       1,
+		Url:      "https://example.com/",
+		Metadata: json.RawMessage(`{"title":"Home"}`),
+		Markdown: sql.NullString{Valid: true, String: "# Home\n\nSee [docs](https://example.com/docs).\n\n## Pricing\n\n```\n# not a heading\n```\n"},
+	}
+	b := db.Document{
+		ID:       2,
+		Url:      "https://example.com/",
+		Metadata: json.RawMessage(`{"title":"Home page"}`),
+		Markdown: sql.NullString{Valid: true, String: "# Home\n\nSee [docs](https://example.com/docs) and [blog](https://example.com/blog \"Blog\").\n\n## Plans\n\n```\n# not a heading\n```\n"},
+	}
+
+	resp := diffDocuments(a, b, 3)
+	if resp.Identical || !strings.HasPrefix(resp.Diff, "--- a/1\n+++ b/2\n@@ ") {
+		t.Fatalf("unexpected diff: %q", resp.Diff)
+	}
+	s := resp.Summary
+	if s.LinesAdded != 2 || s.LinesRemoved != 2 || !s.TitleChanged {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if len(s.HeadingsAdded) != 1 || s.HeadingsAdded[0] != "## Plans" || len(s.HeadingsRemoved) != 1 || s.HeadingsRemoved[0] != "## Pricing" {
+		t.Fatalf("unexpected headings: %+v", s)
+	}
+	if len(s.LinksAdded) != 1 || s.LinksAdded[0] != "https://example.com/blog" || len(s.LinksRemoved) != 0 {
+		t.Fatalf("unexpected links: %+v", s)
+	}
+
+	same := diffDocuments(a, a, 3)
+	if !same.Identical || same.Diff != "" || same.Summary.TitleChanged {
+		t.Fatalf("expected identical documents, got %+v", same)
+	}
+}
+
+func TestDocumentDiffHandler_Validation(t *testing.T) {
+	uid := uuid.New()
+	tid := uuid.New()
+	app := fiber.New()
+	app.Get("/v1/documents/diff", func(c *fiber.Ctx) error {
+		c.Locals("store", &store.Store{})
+		c.Locals("principal", Principal{UserID: &uid, TenantID: &tid})
+		return c.Next()
+	}, documentDiffHandler)
+
+	for _, target := range []string{
+		"/v1/documents/diff?a=1",
+		"/v1/documents/diff?a=x&b=2",
+		"/v1/documents/diff?a=1&b=2&context=99",
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), -1)
+		if err != nil {
+			t.Fatalf("app.Test error: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, resp.StatusCode)
+		}
+	}
+}
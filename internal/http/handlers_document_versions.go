@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+// DocumentVersion is one version of a URL's content in a tenant's
+// history.
+type DocumentVersion struct {
+	ID          int64  `json:"id"`
+	URL         string `json:"url"`
+	ContentHash string `json:"contentHash"`
+	Title       string `json:"title,omitempty"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	// JobID is the latest job that scraped this content, while it exists.
+	JobID string `json:"jobId,omitempty"`
+	// FirstSeenAt is when the content was first scraped; LastSeenAt is
+	// when a scrape last found it unchanged.
+	FirstSeenAt time.Time `json:"firstSeenAt"`
+	LastSeenAt  time.Time `json:"lastSeenAt"`
+	// Markdown is only returned for the latest version.
+	Markdown string `json:"markdown,omitempty"`
+	// Diff is a unified diff from the previous version's markdown; it is
+	// unset for the oldest version of the history.
+	Diff    string               `json:"diff,omitempty"`
+	Summary *DocumentDiffSummary `json:"summary,omitempty"`
+}
+
+type DocumentByURLResponse struct {
+	Success bool             `json:"success"`
+	Code    string           `json:"code,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	URL     string           `json:"url,omitempty"`
+	Latest  *DocumentVersion `json:"latest,omitempty"`
+	// History lists the URL's versions newest first when history=true.
+	History []DocumentVersion `json:"history,omitempty"`
+}
+
+// recordDocumentVersion adds a stored page to its tenant's history of the
+// URL. The history is best effort, so failures never fail the job.
+func recordDocumentVersion(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, url, markdown, title string, statusCode int32) {
+	_ = st.RecordDocumentVersion(ctx, jobID, url, markdown, title, statusCode, jobs.DocumentVersionsPerURL(cfg))
+}
+
+func documentVersionFromRow(v db.DocumentVersion) DocumentVersion {
+	out := DocumentVersion{
+		ID:          v.ID,
+		URL:         v.Url,
+		ContentHash: v.ContentHash,
+		Title:       v.Title.String,
+		StatusCode:  int(v.StatusCode.Int32),
+		FirstSeenAt: v.CreatedAt,
+		LastSeenAt:  v.LastSeenAt,
+	}
+	if v.JobID.Valid {
+		out.JobID = v.JobID.UUID.String()
+	}
+	return out
+}
+
+// documentVersionTimeline converts versions, newest first, into their API
+// form, diffing each version against the one before it.
+func documentVersionTimeline(versions []db.DocumentVersion, contextLines int) []DocumentVersion {
+	out := make([]DocumentVersion, len(versions))
+	for i, v := range versions {
+		out[i] = documentVersionFromRow(v)
+		if i+1 == len(versions) {
+			continue
+		}
+		prev := versions[i+1]
+		diff, summary := diffMarkdown(prev.Markdown, v.Markdown, "a/"+strconv.FormatInt(prev.ID, 10), "b/"+strconv.FormatInt(v.ID, 10), contextLines)
+		summary.TitleChanged = prev.Title.String != v.Title.String
+		out[i].Diff = diff
+		out[i].Summary = summary
+	}
+	return out
+}
+
+// documentByURLHandler implements GET /v1/documents/by-url?url=<url>. It
+// returns the latest content the active tenant scraped from the URL and,
+// with history=true, every kept version with diffs between them. Versions
+// of private jobs are only visible to their creators.
+func documentByURLHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	p, ok := c.Locals("principal").(Principal)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(DocumentByURLResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "authentication is required",
+		})
+	}
+	if p.TenantID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentByURLResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to view documents",
+		})
+	}
+
+	url := strings.TrimSpace(c.Query("url"))
+	if url == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(DocumentByURLResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Missing required field 'url'",
+		})
+	}
+	withHistory := c.QueryBool("history", false)
+
+	contextLines := defaultDocumentDiffContext
+	if raw := c.Query("context"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > maxDocumentDiffContext {
+			return c.Status(fiber.StatusBadRequest).JSON(DocumentByURLResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "context must be an integer between 0 and " + strconv.Itoa(maxDocumentDiffContext),
+			})
+		}
+		contextLines = n
+	}
+
+	limit := int32(1)
+	if withHistory {
+		limit = int32(jobs.DocumentVersionsPerURL(cfg))
+	}
+	versions, err := st.ListDocumentVersions(c.Context(), *p.TenantID, jobViewerFor(c, st, p), url, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(DocumentByURLResponse{
+			Success: false,
+			Code:    "DOCUMENT_VERSIONS_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if len(versions) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(DocumentByURLResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "no documents found for url",
+		})
+	}
+
+	latest := documentVersionFromRow(versions[0])
+	latest.Markdown = versions[0].Markdown
+	resp := DocumentByURLResponse{
+		Success: true,
+		URL:     url,
+		Latest:  &latest,
+	}
+	if withHistory {
+		resp.History = documentVersionTimeline(versions, contextLines)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
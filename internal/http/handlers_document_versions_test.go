@@ -0,0 +1,40 @@
+package http
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"raito/internal/db"
+)
+
+func TestDocumentVersionTimeline(t *testing.T) {
+	versions := []db.DocumentVersion{
+		{ID: 9, Url: "https://example.com/pricing", Markdown: "# Pricing\n\n## Enterprise\n", Title: sql.NullString{String: "Pricing", Valid: true}},
+		{ID: 4, Url: "https://example.com/pricing", Markdown: "# Pricing\n", Title: sql.NullString{String: "Pricing", Valid: true}},
+		{ID: 2, Url: "https://example.com/pricing", Markdown: "# Prices\n", Title: sql.NullString{String: "Prices", Valid: true}},
+	}
+	timeline := documentVersionTimeline(versions, 3)
+	if len(timeline) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(timeline))
+	}
+
+	newest := timeline[0]
+	if !strings.HasPrefix(newest.Diff, "--- a/4\n+++ b/9\n") || newest.Summary == nil {
+		t.Fatalf("unexpected diff of newest version: %q", newest.Diff)
+	}
+	if len(newest.Summary.HeadingsAdded) != 1 || newest.Summary.HeadingsAdded[0] != "## Enterprise" || newest.Summary.TitleChanged {
+		t.Fatalf("unexpected summary: %+v", newest.Summary)
+	}
+	if !timeline[1].Summary.TitleChanged || timeline[1].Summary.LinesAdded != 1 || timeline[1].Summary.LinesRemoved != 1 {
+		t.Fatalf("unexpected summary: %+v", timeline[1].Summary)
+	}
+	if oldest := timeline[2]; oldest.Diff != "" || oldest.Summary != nil {
+		t.Fatalf("expected no diff for the oldest version, got %+v", oldest)
+	}
+	for _, v := range timeline {
+		if v.Markdown != "" {
+			t.Fatalf("expected timeline entries without markdown, got %q", v.Markdown)
+		}
+	}
+}
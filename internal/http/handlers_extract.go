@@ -12,6 +12,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/extract"
+	"raito/internal/jobs"
 	"raito/internal/services"
 	"raito/internal/store"
 )
@@ -28,6 +32,15 @@ func extractHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	collectionID, code, msg := applyCollectionDefaults(c, reqBody.CollectionID, &reqBody)
+	if code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
+
 	// Require at least one URL via urls
 	urls := reqBody.URLs
 	if len(urls) == 0 {
@@ -38,44 +51,72 @@ func extractHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Normalize and validate URLs early to avoid enqueuing obviously
-	// invalid work. Only http/https URLs with a host are accepted.
-	for i, raw := range urls {
-		u := strings.TrimSpace(raw)
-		if u == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
-				Success: false,
-				Code:    "BAD_REQUEST_INVALID_URL",
-				Error:   fmt.Sprintf("Invalid URL at index %d", i),
-			})
-		}
+	if code, msg := normalizeExtractURLs(urls); code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
 
-		parsed, err := url.Parse(u)
-		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+	if err := validateExtractMode(reqBody.Mode); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if reqBody.ScrapeOptions != nil && reqBody.ScrapeOptions.Auth != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "scrapeOptions.auth is only supported by scrape and crawl",
+		})
+	}
+
+	if reqBody.Limit != nil && *reqBody.Limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "limit must be a positive integer",
+		})
+	}
+
+	if reqBody.MaxAge != nil && *reqBody.MaxAge < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "maxAge must be zero or a positive number of milliseconds",
+		})
+	}
+
+	// A schemaPreset stands in for a hand-written schema.
+	if reqBody.SchemaPreset != "" {
+		if len(reqBody.Schema) > 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
 				Success: false,
-				Code:    "BAD_REQUEST_INVALID_URL",
-				Error:   fmt.Sprintf("Invalid URL at index %d", i),
+				Code:    "BAD_REQUEST",
+				Error:   "'schema' and 'schemaPreset' cannot both be set",
 			})
 		}
-		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		schema, ok := extract.Preset(reqBody.SchemaPreset)
+		if !ok {
 			return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
 				Success: false,
-				Code:    "BAD_REQUEST_INVALID_URL",
-				Error:   fmt.Sprintf("Unsupported URL scheme at index %d", i),
+				Code:    "INVALID_SCHEMA_PRESET",
+				Error:   fmt.Sprintf("Unknown schemaPreset %q (available: %s)", reqBody.SchemaPreset, strings.Join(extract.PresetNames(), ", ")),
 			})
 		}
-
-		urls[i] = u
+		reqBody.Schema = schema
 	}
-	reqBody.URLs = urls
 
 	// Require a JSON schema; legacy fields mode is no longer supported.
 	if len(reqBody.Schema) == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
 			Success: false,
 			Code:    "BAD_REQUEST",
-			Error:   "Missing required field 'schema'",
+			Error:   "Missing required field 'schema' or 'schemaPreset'",
 		})
 	}
 
@@ -87,6 +128,22 @@ func extractHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := validateJobVisibility(reqBody.Visibility); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobPool(c, reqBody.Pool); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ExtractResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
 	st := c.Locals("store").(*store.Store)
 
 	// Generate an extract job ID (uuidv7 preferred)
@@ -103,6 +160,7 @@ func extractHandler(c *fiber.Ctx) error {
 
 	var tenantID *uuid.UUID
 	var apiKeyID *uuid.UUID
+	var userID *uuid.UUID
 	if val := c.Locals("principal"); val != nil {
 		if p, ok := val.(Principal); ok {
 			if p.TenantID != nil {
@@ -111,15 +169,58 @@ func extractHandler(c *fiber.Ctx) error {
 			if p.APIKeyID != nil {
 				apiKeyID = p.APIKeyID
 			}
+			userID = p.UserID
+		}
+	}
+
+	if err := resolveExtractTemplate(c.Context(), db.New(st.DB), tenantID, &reqBody); err != nil {
+		status, code := promptTemplateStatus(err)
+		return c.Status(status).JSON(ExtractResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
+	// Extract is entirely LLM-driven, so it is rejected up front once the
+	// tenant's monthly LLM budget is used up.
+	if tenantID != nil {
+		if err := checkLLMBudget(c.Context(), db.New(st.DB), *tenantID); errors.Is(err, errLLMBudgetExceeded) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(ExtractResponse{
+				Success: false,
+				Code:    "LLM_BUDGET_EXCEEDED",
+				Error:   err.Error(),
+			})
 		}
 	}
 
+	// Reject models outside the tenant's allowlist before queueing. The
+	// worker applies the policy again, including the tenant's default model.
+	cfg := c.Locals("config").(*config.Config)
+	if _, _, err := resolveTenantLLM(c.Context(), cfg, db.New(st.DB), tenantID, reqBody.Provider, reqBody.Model); err != nil {
+		status, code := llmClientFailure(err)
+		return c.Status(status).JSON(ExtractResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
 	if err := svc.Enqueue(c.Context(), &services.ExtractRequest{
-		ID:         id,
-		Body:       reqBody,
-		PrimaryURL: primaryURL,
-		TenantID:   tenantID,
-		APIKeyID:   apiKeyID,
+		ID:           id,
+		Body:         reqBody,
+		PrimaryURL:   primaryURL,
+		TenantID:     tenantID,
+		APIKeyID:     apiKeyID,
+		UserID:       userID,
+		Visibility:   reqBody.Visibility,
+		CollectionID: collectionID,
+		Pool: routeJobPool(c, jobs.RouteRequest{
+			TenantID:     tenantID,
+			Requested:    reqBody.Pool,
+			NeedsBrowser: scrapeOptionsUseBrowser(reqBody.ScrapeOptions),
+		}),
+		ZeroRetention: zeroRetentionRequested(reqBody.ZeroDataRetention, reqBody.StoreInCache),
 	}); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(ExtractResponse{
 			Success: false,
@@ -153,6 +254,32 @@ func extractHandler(c *fiber.Ctx) error {
 	})
 }
 
+// ExtractSchemaPreset is a built-in extraction schema.
+type ExtractSchemaPreset struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+}
+
+type ExtractSchemaPresetsResponse struct {
+	Success bool                  `json:"success"`
+	Presets []ExtractSchemaPreset `json:"presets"`
+}
+
+// extractSchemaPresetsHandler lists the schemas selectable with
+// schemaPreset.
+func extractSchemaPresetsHandler(c *fiber.Ctx) error {
+	names := extract.PresetNames()
+	presets := make([]ExtractSchemaPreset, 0, len(names))
+	for _, name := range names {
+		schema, _ := extract.Preset(name)
+		presets = append(presets, ExtractSchemaPreset{Name: name, Schema: schema})
+	}
+	return c.Status(fiber.StatusOK).JSON(ExtractSchemaPresetsResponse{
+		Success: true,
+		Presets: presets,
+	})
+}
+
 func validateExtractSchema(schema map[string]interface{}) (string, string) {
 	if len(schema) == 0 {
 		return "INVALID_SCHEMA", "Schema must be a non-empty JSON object"
@@ -215,25 +342,22 @@ func extractStatusHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce tenant scoping: if the job is associated with a tenant,
-	// non-admin callers must match that tenant.
-	if val := c.Locals("principal"); val != nil {
-		if p, ok := val.(Principal); ok && !p.IsSystemAdmin && job.TenantID.Valid && p.TenantID != nil && job.TenantID.UUID != *p.TenantID {
-			return c.Status(fiber.StatusNotFound).JSON(ExtractStatusResponse{
-				Success: false,
-				Status:  ExtractStatusFailed,
-				Code:    "NOT_FOUND",
-				Error:   "extract job not found",
-			})
-		}
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) {
+		return c.Status(fiber.StatusNotFound).JSON(ExtractStatusResponse{
+			Success: false,
+			Status:  ExtractStatusFailed,
+			Code:    "NOT_FOUND",
+			Error:   "extract job not found",
+		})
 	}
 
 	resp := ExtractStatusResponse{
 		Success: true,
-		Status:  ExtractJobStatus(job.Status),
+		Status:  ExtractJobStatus(jobs.PublicStatus(job.Status)),
 	}
 
-	switch job.Status {
+	switch jobs.PublicStatus(job.Status) {
 	case "completed":
 		if job.Output.Valid && len(job.Output.RawMessage) > 0 {
 			var data map[string]interface{}
@@ -264,5 +388,42 @@ func extractStatusHandler(c *fiber.Ctx) error {
 		resp.Error = msg
 	}
 
+	if job.PurgedAt.Valid {
+		resp.Warning = zeroRetentionPurgedWarning
+	}
+	purgeDeliveredJob(c, st, job)
+
 	return c.Status(http.StatusOK).JSON(resp)
 }
+
+// normalizeExtractURLs validates extract URLs in place, returning an error
+// code and message for the first invalid one. Only http/https URLs with a
+// host are accepted; wildcard entries ("example.com/blog/*") ask the worker
+// to discover pages under the prefix and bare wildcard hosts default to
+// https.
+func normalizeExtractURLs(urls []string) (string, string) {
+	for i, raw := range urls {
+		u := strings.TrimSpace(raw)
+		if u == "" {
+			return "BAD_REQUEST_INVALID_URL", fmt.Sprintf("Invalid URL at index %d", i)
+		}
+
+		if isWildcardURL(u) && !strings.Contains(u, "://") {
+			u = "https://" + u
+		}
+		if strings.Contains(strings.TrimSuffix(u, "/*"), "*") {
+			return "BAD_REQUEST_INVALID_URL", fmt.Sprintf("Wildcards are only supported as a trailing '/*' (index %d)", i)
+		}
+
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return "BAD_REQUEST_INVALID_URL", fmt.Sprintf("Invalid URL at index %d", i)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return "BAD_REQUEST_INVALID_URL", fmt.Sprintf("Unsupported URL scheme at index %d", i)
+		}
+
+		urls[i] = u
+	}
+	return "", ""
+}
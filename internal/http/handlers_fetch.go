@@ -0,0 +1,204 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/crawler"
+	"raito/internal/scraper"
+)
+
+// FetchRequest is the body of POST /v1/fetch. GET /v1/fetch takes url
+// and raw as query parameters instead.
+type FetchRequest struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Timeout *int              `json:"timeout,omitempty"`
+	// Raw returns the upstream body as the response body instead of a
+	// JSON envelope.
+	Raw bool `json:"raw,omitempty"`
+}
+
+// FetchData is the unprocessed upstream response. Text bodies are
+// returned as-is; anything that is not valid UTF-8 is base64-encoded.
+type FetchData struct {
+	URL        string            `json:"url"`
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	Encoding   string            `json:"encoding"`
+	Truncated  bool              `json:"truncated,omitempty"`
+}
+
+type FetchResponse struct {
+	Success bool       `json:"success"`
+	Data    *FetchData `json:"data,omitempty"`
+	Code    string     `json:"code,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// fetchPassthroughHeaders are the upstream headers copied onto raw-mode
+// responses. Content-Disposition is not among them: raw bodies are always
+// served as attachments.
+var fetchPassthroughHeaders = []string{
+	fiber.HeaderContentType,
+	fiber.HeaderContentLanguage,
+	fiber.HeaderLastModified,
+	fiber.HeaderETag,
+}
+
+// fetchHandler performs a policy-checked GET of an arbitrary URL and
+// returns the status, headers and body without any markdown conversion.
+// The fetch refuses non-public addresses (unless
+// scraper.allowPrivateNetworks is set), honors robots.txt when
+// robots.respect is on, and shares the per-host limiter with crawls.
+func fetchHandler(c *fiber.Ctx) error {
+	var reqBody FetchRequest
+	if c.Method() == fiber.MethodGet {
+		reqBody.URL = c.Query("url")
+		reqBody.Raw = c.QueryBool("raw")
+	} else if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(FetchResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	// Raw bodies come from an arbitrary site but are served from the API's
+	// origin, so a link to GET /v1/fetch?raw=true must not run that site's
+	// scripts with a browser session. Only API keys may use raw mode.
+	if p, ok := c.Locals("principal").(Principal); ok && reqBody.Raw && p.APIKeyID == nil {
+		return c.Status(fiber.StatusForbidden).JSON(FetchResponse{
+			Success: false,
+			Code:    "RAW_FETCH_REQUIRES_API_KEY",
+			Error:   "raw mode is only available to API keys",
+		})
+	}
+
+	target, err := url.Parse(strings.TrimSpace(reqBody.URL))
+	if reqBody.URL == "" || err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(FetchResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Field 'url' must be an absolute http or https URL",
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+
+	timeoutMs := cfg.Scraper.TimeoutMs
+	if reqBody.Timeout != nil && *reqBody.Timeout > 0 {
+		timeoutMs = *reqBody.Timeout
+	}
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+	defer cancel()
+
+	client := scraper.NewPublicHTTPClient(timeout, cfg.Scraper.AllowPrivateNetworks)
+
+	if cfg.Robots.Respect && !crawler.RobotsAllowed(ctx, client, target, cfg.RequestUserAgent(), cfg.RobotsAgent()) {
+		recordHostRobotsBlock(target.String())
+		return c.Status(fiber.StatusForbidden).JSON(FetchResponse{
+			Success: false,
+			Code:    "ROBOTS_DISALLOWED",
+			Error:   "robots.txt disallows fetching this URL",
+		})
+	}
+
+	done, err := acquireHost(ctx, cfg, sharedHostLimiter(cfg), target.String())
+	if err != nil {
+		return c.Status(http.StatusGatewayTimeout).JSON(FetchResponse{
+			Success: false,
+			Code:    "FETCH_TIMEOUT",
+			Error:   err.Error(),
+		})
+	}
+
+	res, err := scraper.Fetch(ctx, client, scraper.FetchRequest{
+		URL:       target.String(),
+		Headers:   reqBody.Headers,
+		UserAgent: cfg.RequestUserAgent(),
+		MaxBytes:  cfg.Scraper.FetchMaxBytes,
+	})
+	if err != nil {
+		done(0, err)
+		status, code := fiber.StatusBadGateway, "FETCH_FAILED"
+		switch {
+		case errors.Is(err, scraper.ErrBlockedAddress):
+			status, code = fiber.StatusForbidden, "FETCH_BLOCKED"
+		case errors.Is(err, context.DeadlineExceeded):
+			status, code = http.StatusGatewayTimeout, "FETCH_TIMEOUT"
+		}
+		return c.Status(status).JSON(FetchResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+	done(res.Status, nil)
+
+	if reqBody.Raw {
+		for _, h := range fetchPassthroughHeaders {
+			if v := res.Header.Get(h); v != "" {
+				c.Set(h, v)
+			}
+		}
+		c.Set(fiber.HeaderContentDisposition, contentDisposition(fetchFilename(res.URL)))
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		c.Set(fiber.HeaderContentSecurityPolicy, "sandbox")
+		c.Set("X-Raito-Final-Url", res.URL)
+		if res.Truncated {
+			c.Set("X-Raito-Truncated", "true")
+		}
+		return c.Status(res.Status).Send(res.Body)
+	}
+
+	data := &FetchData{
+		URL:        res.URL,
+		StatusCode: res.Status,
+		Headers:    make(map[string]string, len(res.Header)),
+		Truncated:  res.Truncated,
+	}
+	for k, v := range res.Header {
+		data.Headers[k] = strings.Join(v, ", ")
+	}
+	if utf8.Valid(res.Body) {
+		data.Body, data.Encoding = string(res.Body), "utf-8"
+	} else {
+		data.Body, data.Encoding = base64.StdEncoding.EncodeToString(res.Body), "base64"
+	}
+
+	return c.Status(http.StatusOK).JSON(FetchResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// fetchFilename names a raw fetch download after the last path segment of
+// its final URL.
+func fetchFilename(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "fetch"
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		name = u.Hostname()
+	}
+	if name == "" {
+		return "fetch"
+	}
+	return sanitizeFilename(name)
+}
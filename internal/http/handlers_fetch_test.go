@@ -0,0 +1,127 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+)
+
+func newFetchTestApp(cfg *config.Config) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		return c.Next()
+	})
+	app.Get("/v1/fetch", fetchHandler)
+	app.Post("/v1/fetch", fetchHandler)
+	return app
+}
+
+func TestFetchHandler(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = io.WriteString(w, "User-agent: *\nDisallow: /private\n")
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte{0xff, 0xfe, 0x00})
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("X-Upstream", "yes")
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = io.WriteString(w, "<p>not converted</p>")
+		}
+	}))
+	defer upstream.Close()
+
+	cfg := &config.Config{}
+	cfg.Scraper.TimeoutMs = 5000
+	cfg.Scraper.AllowPrivateNetworks = true
+	cfg.Robots.Respect = true
+	app := newFetchTestApp(cfg)
+
+	get := func(target string, raw bool) (*http.Response, FetchResponse) {
+		t.Helper()
+		q := url.Values{"url": {target}}
+		if raw {
+			q.Set("raw", "true")
+		}
+		resp, err := app.Test(httptest.NewRequest("GET", "/v1/fetch?"+q.Encode(), nil), 10000)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		var out FetchResponse
+		if !raw {
+			raw, _ := io.ReadAll(resp.Body)
+			if err := json.Unmarshal(raw, &out); err != nil {
+				t.Fatalf("decode %q: %v", raw, err)
+			}
+		}
+		return resp, out
+	}
+
+	resp, out := get(upstream.URL+"/page", false)
+	if resp.StatusCode != fiber.StatusOK || !out.Success || out.Data == nil {
+		t.Fatalf("expected success, got %d %+v", resp.StatusCode, out)
+	}
+	if out.Data.StatusCode != http.StatusAccepted || out.Data.Body != "<p>not converted</p>" || out.Data.Encoding != "utf-8" {
+		t.Fatalf("expected upstream response passed through, got %+v", out.Data)
+	}
+	if out.Data.Headers["X-Upstream"] != "yes" {
+		t.Fatalf("expected upstream headers, got %v", out.Data.Headers)
+	}
+
+	if _, out := get(upstream.URL+"/binary", false); out.Data == nil || out.Data.Encoding != "base64" || out.Data.Body != "//4A" {
+		t.Fatalf("expected binary body base64-encoded, got %+v", out.Data)
+	}
+
+	resp, _ = get(upstream.URL+"/page", true)
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusAccepted || string(body) != "<p>not converted</p>" || resp.Header.Get("Content-Type") != "text/plain" {
+		t.Fatalf("expected raw passthrough, got %d %q %v", resp.StatusCode, body, resp.Header)
+	}
+	if resp.Header.Get("Content-Disposition") != `attachment; filename="page"` || resp.Header.Get("X-Content-Type-Options") != "nosniff" || resp.Header.Get("Content-Security-Policy") != "sandbox" {
+		t.Fatalf("expected raw body served as a sandboxed attachment, got %v", resp.Header)
+	}
+
+	if resp, out := get(upstream.URL+"/private/doc", false); resp.StatusCode != fiber.StatusForbidden || out.Code != "ROBOTS_DISALLOWED" {
+		t.Fatalf("expected robots.txt to block, got %d %+v", resp.StatusCode, out)
+	}
+
+	cfg.Scraper.AllowPrivateNetworks = false
+	if resp, out := get(upstream.URL+"/page", false); resp.StatusCode != fiber.StatusForbidden || out.Code != "FETCH_BLOCKED" {
+		t.Fatalf("expected loopback fetch blocked, got %d %+v", resp.StatusCode, out)
+	}
+
+	if resp, out := get("file:///etc/passwd", false); resp.StatusCode != fiber.StatusBadRequest || out.Success {
+		t.Fatalf("expected non-http URL rejected, got %d %+v", resp.StatusCode, out)
+	}
+}
+
+func TestFetchHandler_RawRequiresAPIKey(t *testing.T) {
+	cfg := &config.Config{}
+	uid := uuid.New()
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		c.Locals("principal", Principal{UserID: &uid})
+		return c.Next()
+	})
+	app.Get("/v1/fetch", fetchHandler)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/v1/fetch?url=https%3A%2F%2Fexample.com%2F&raw=true", nil), -1)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Fatalf("expected raw mode refused for a session, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"archive/zip"
+	"database/sql"
+	"errors"
+	"mime"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// jobAssetHandler serves a single archived asset (e.g. an image saved by
+// downloadImages) for a job visible to the caller in the active tenant.
+func jobAssetHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	if p.TenantID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to view job assets",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid job id",
+		})
+	}
+	assetID, err := uuid.Parse(c.Params("assetId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid asset id",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil || !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID || !jobViewerFor(c, st, p).CanSee(job) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "job not found",
+		})
+	}
+
+	asset, err := st.GetJobAsset(c.Context(), jobID, assetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "ASSET_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	// Assets are immutable once stored, so clients may cache them freely.
+	c.Set(fiber.HeaderContentType, asset.ContentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=31536000, immutable")
+	c.Set(fiber.HeaderETag, `"`+asset.Sha256+`"`)
+	return c.Send(asset.Data)
+}
+
+// zipWriteAssets writes job assets under assets/ in the zip and returns a
+// map from each asset's API URL to its path inside the archive.
+func zipWriteAssets(zw *zip.Writer, jobID uuid.UUID, assets []db.JobAsset) map[string]string {
+	paths := make(map[string]string, len(assets))
+	for _, a := range assets {
+		name := "assets/" + a.ID.String() + assetExtension(a.ContentType)
+		if err := zipWriteFile(zw, name, a.Data); err != nil {
+			continue
+		}
+		paths[jobAssetURL(jobID, a.ID)] = name
+	}
+	return paths
+}
+
+// rewriteAssetLinks points archived asset URLs in markdown at their files
+// inside the zip. relPrefix is the path from the markdown file back to the
+// archive root (e.g. "../" for files under docs/).
+func rewriteAssetLinks(markdown string, paths map[string]string, relPrefix string) string {
+	if len(paths) == 0 || markdown == "" {
+		return markdown
+	}
+	urls := make([]string, 0, len(paths))
+	for u := range paths {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	pairs := make([]string, 0, len(paths)*2)
+	for _, u := range urls {
+		pairs = append(pairs, u, relPrefix+paths[u])
+	}
+	return strings.NewReplacer(pairs...).Replace(markdown)
+}
+
+func assetExtension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "image/svg+xml":
+		return ".svg"
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
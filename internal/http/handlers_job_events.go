@@ -0,0 +1,128 @@
+package http
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// JobEventItem is one entry of a job's timeline.
+type JobEventItem struct {
+	// Type is a job status ("pending", "running", "completed", "failed")
+	// or a milestone: "enqueued", "claimed" or "discovery_finished".
+	Type      string         `json:"type"`
+	Message   string         `json:"message,omitempty"`
+	Data      map[string]any `json:"data,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	// ElapsedMs is the time from job creation to this event.
+	ElapsedMs int64 `json:"elapsedMs"`
+	// DurationMs is the time from this event to the next one; it is
+	// omitted on the latest event.
+	DurationMs *int64 `json:"durationMs,omitempty"`
+}
+
+type JobEventsResponse struct {
+	Success bool           `json:"success"`
+	Code    string         `json:"code,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	JobID   string         `json:"jobId,omitempty"`
+	Status  string         `json:"status,omitempty"`
+	Events  []JobEventItem `json:"events,omitempty"`
+}
+
+// jobEventItems converts a job's stored events, oldest first, into
+// timeline entries with elapsed and per-step durations.
+func jobEventItems(createdAt time.Time, events []db.JobEvent) []JobEventItem {
+	items := make([]JobEventItem, 0, len(events))
+	for i, ev := range events {
+		item := JobEventItem{
+			Type:      ev.Type,
+			Message:   ev.Message.String,
+			CreatedAt: ev.CreatedAt,
+			ElapsedMs: ev.CreatedAt.Sub(createdAt).Milliseconds(),
+		}
+		var data map[string]any
+		if err := json.Unmarshal(ev.Data, &data); err == nil && len(data) > 0 {
+			item.Data = data
+		}
+		if i+1 < len(events) {
+			d := events[i+1].CreatedAt.Sub(ev.CreatedAt).Milliseconds()
+			item.DurationMs = &d
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// jobEventsHandler implements GET /v1/jobs/:id/events, the timeline of a
+// job's state transitions and milestones.
+func jobEventsHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	job, ok, err := timelineJob(c, st)
+	if !ok {
+		return err
+	}
+
+	events, err := st.ListJobEvents(c.Context(), job.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(JobEventsResponse{
+			Success: false,
+			Code:    "JOB_EVENTS_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(JobEventsResponse{
+		Success: true,
+		JobID:   job.ID.String(),
+		Status:  job.Status,
+		Events:  jobEventItems(job.CreatedAt, events),
+	})
+}
+
+// timelineJob resolves the job of a timeline request. When the caller
+// may not see it, it writes the error response and returns ok=false.
+func timelineJob(c *fiber.Ctx, st *store.Store) (db.Job, bool, error) {
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return db.Job{}, false, c.Status(fiber.StatusUnauthorized).JSON(JobEventsResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	if p.TenantID == nil {
+		return db.Job{}, false, c.Status(fiber.StatusBadRequest).JSON(JobEventsResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to view jobs",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return db.Job{}, false, c.Status(fiber.StatusBadRequest).JSON(JobEventsResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid job id",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil || !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID || !jobViewerFor(c, st, p).CanSee(job) {
+		return db.Job{}, false, c.Status(fiber.StatusNotFound).JSON(JobEventsResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "job not found",
+		})
+	}
+	return job, true, nil
+}
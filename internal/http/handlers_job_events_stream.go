@@ -0,0 +1,133 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/events"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+const (
+	// jobEventsStreamPoll is how often a stream re-reads the timeline
+	// without a wakeup, covering messages the event bus dropped. It is
+	// also the keep-alive interval.
+	jobEventsStreamPoll = 5 * time.Second
+	// jobEventsStreamRetryMs is the reconnect delay suggested to clients.
+	jobEventsStreamRetryMs = 1000
+	// jobEventsStreamMargin is how long before the server write timeout a
+	// stream ends, so clients reconnect instead of seeing a cut response.
+	jobEventsStreamMargin = 5 * time.Second
+	// minJobEventsStream is the shortest stream, for very short write
+	// timeouts.
+	minJobEventsStream = 5 * time.Second
+)
+
+// jobEventsStreamHandler implements GET /v1/jobs/:id/events/stream, the
+// job timeline as server-sent events. Each timeline entry is a
+// "job_event" event whose id is its position, so clients resume with
+// Last-Event-ID. An "end" event follows the job's final status. Streams
+// end shortly before the server write timeout; EventSource clients
+// reconnect on their own.
+func jobEventsStreamHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	job, ok, err := timelineJob(c, st)
+	if !ok {
+		return err
+	}
+
+	sent := 0
+	if n, err := strconv.Atoi(c.Get("Last-Event-ID")); err == nil && n > 0 {
+		sent = n
+	}
+
+	var sc config.ServerConfig
+	if cfg, ok := c.Locals("config").(*config.Config); ok && cfg != nil {
+		sc = cfg.Server
+	}
+	duration := max(serverWriteTimeout(sc)-jobEventsStreamMargin, minJobEventsStream)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The stream outlives the handler, so it cannot use the request
+	// context.
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	wake := events.Subscribe(ctx, events.JobTopic(job.ID))
+	jobID, createdAt := job.ID, job.CreatedAt
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		fmt.Fprintf(w, "retry: %d\n\n", jobEventsStreamRetryMs)
+		poll := time.NewTicker(jobEventsStreamPoll)
+		defer poll.Stop()
+
+		for {
+			if timeline, err := st.ListJobEvents(ctx, jobID); err == nil {
+				var done bool
+				sent, done = writeJobEventFrames(w, createdAt, timeline, sent)
+				if done {
+					_, _ = io.WriteString(w, "event: end\ndata: {}\n\n")
+					_ = w.Flush()
+					return
+				}
+			}
+			// A failed flush means the client went away.
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-wake:
+				if !ok {
+					return
+				}
+			case <-poll.C:
+				_, _ = io.WriteString(w, ": keep-alive\n\n")
+			}
+		}
+	})
+	return nil
+}
+
+// writeJobEventFrames writes the timeline entries after the first sent
+// ones as "job_event" events and returns the new count of sent entries.
+// done reports whether the latest status in the timeline is final.
+func writeJobEventFrames(w io.Writer, createdAt time.Time, timeline []db.JobEvent, sent int) (int, bool) {
+	items := jobEventItems(createdAt, timeline)
+	for i := sent; i < len(items); i++ {
+		// The next entry may not exist yet when this one is sent.
+		items[i].DurationMs = nil
+		data, err := json.Marshal(items[i])
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(w, "id: %d\nevent: job_event\ndata: %s\n\n", i+1, data)
+	}
+
+	done := false
+	for _, ev := range timeline {
+		switch jobs.Status(ev.Type) {
+		case jobs.StatusCompleted, jobs.StatusFailed, jobs.StatusDeadLetter:
+			done = true
+		case jobs.StatusPending, jobs.StatusRunning:
+			done = false
+		}
+	}
+	return max(sent, len(items)), done
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"raito/internal/db"
+)
+
+func TestWriteJobEventFrames(t *testing.T) {
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	timeline := []db.JobEvent{
+		{ID: 1, Type: "enqueued", CreatedAt: created, Data: json.RawMessage(`{}`)},
+		{ID: 2, Type: "claimed", CreatedAt: created.Add(time.Second), Data: json.RawMessage(`{"workerId":"w1"}`)},
+		{ID: 3, Type: "running", CreatedAt: created.Add(2 * time.Second), Data: json.RawMessage(`{}`)},
+	}
+
+	var buf bytes.Buffer
+	sent, done := writeJobEventFrames(&buf, created, timeline, 1)
+	if sent != 3 || done {
+		t.Fatalf("expected 3 sent and not done, got %d, %v", sent, done)
+	}
+	out := buf.String()
+	if strings.Contains(out, `"type":"enqueued"`) || !strings.HasPrefix(out, "id: 2\nevent: job_event\ndata: {\"type\":\"claimed\"") {
+		t.Fatalf("expected frames after the first sent entry, got %q", out)
+	}
+	if strings.Contains(out, "durationMs") {
+		t.Fatalf("expected streamed entries without durations, got %q", out)
+	}
+
+	buf.Reset()
+	timeline = append(timeline, db.JobEvent{ID: 4, Type: "completed", CreatedAt: created.Add(5 * time.Second), Data: json.RawMessage(`{}`)})
+	sent, done = writeJobEventFrames(&buf, created, timeline, sent)
+	if sent != 4 || !done || !strings.HasPrefix(buf.String(), "id: 4\n") {
+		t.Fatalf("expected the final status to end the stream, got %d, %v, %q", sent, done, buf.String())
+	}
+
+	// A retried job is running again after a failure.
+	retried := append(timeline[:3:3], db.JobEvent{ID: 5, Type: "failed"}, db.JobEvent{ID: 6, Type: "pending"})
+	if _, done := writeJobEventFrames(&bytes.Buffer{}, created, retried, 0); done {
+		t.Fatal("expected a job that left its final status to keep streaming")
+	}
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"raito/internal/db"
+)
+
+func TestJobEventItems_ComputesElapsedAndDurations(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []db.JobEvent{
+		{Type: "enqueued", Data: json.RawMessage(`{"pool":"default","priority":0}`), CreatedAt: created},
+		{Type: "claimed", Data: json.RawMessage(`{"workerId":"w1"}`), CreatedAt: created.Add(1500 * time.Millisecond)},
+		{Type: "running", Data: json.RawMessage(`{}`), CreatedAt: created.Add(2 * time.Second)},
+		{Type: "failed", Message: sql.NullString{String: "SCRAPE_FAILED: boom", Valid: true}, Data: json.RawMessage(`{}`), CreatedAt: created.Add(10 * time.Second)},
+	}
+
+	items := jobEventItems(created, events)
+	if len(items) != 4 {
+		t.Fatalf("expected 4 items, got %d", len(items))
+	}
+	if items[1].ElapsedMs != 1500 || items[1].DurationMs == nil || *items[1].DurationMs != 500 {
+		t.Fatalf("unexpected claimed timing: %+v", items[1])
+	}
+	if items[1].Data["workerId"] != "w1" {
+		t.Fatalf("expected data to be decoded, got %#v", items[1].Data)
+	}
+	if items[2].Data != nil {
+		t.Fatalf("expected empty data to be omitted, got %#v", items[2].Data)
+	}
+	last := items[3]
+	if last.ElapsedMs != 10000 || last.DurationMs != nil || last.Message != "SCRAPE_FAILED: boom" {
+		t.Fatalf("unexpected last event: %+v", last)
+	}
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// jobResultChunkSize is how much markdown is written between flushes when
+// streaming a result.
+const jobResultChunkSize = 32 << 10
+
+// jobResultHandler implements GET /v1/jobs/:id/result, a finished job's
+// stored output. By default it is the output as JSON under "data", copied
+// from the database without decoding it. Clients that send
+// "Accept: text/markdown" get the markdown of a scrape or summarize job
+// streamed as the body instead, so a huge page is not escaped into a JSON
+// string first. Like downloads, it leaves zero-retention output to the
+// retention sweeper.
+func jobResultHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	job, ok, err := timelineJob(c, st)
+	if !ok {
+		return err
+	}
+
+	c.Vary(fiber.HeaderAccept)
+	accept := c.Accepts(fiber.MIMEApplicationJSON, "text/markdown")
+	if accept == "" {
+		return c.Status(fiber.StatusNotAcceptable).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_ACCEPTABLE",
+			Error:   "results are available as application/json or text/markdown",
+		})
+	}
+
+	if job.Status != "completed" {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_NOT_COMPLETED",
+			Error:   "job is not completed yet",
+		})
+	}
+	if !job.Output.Valid || len(job.Output.RawMessage) == 0 {
+		msg := "this job has no stored output; use GET /v1/jobs/:id/download for its documents"
+		if job.PurgedAt.Valid {
+			msg = zeroRetentionPurgedWarning
+		}
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NO_RESULT_AVAILABLE",
+			Error:   msg,
+		})
+	}
+
+	if accept == "text/markdown" {
+		markdown, ok := jobResultMarkdown(job)
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Success: false,
+				Code:    "NO_MARKDOWN_AVAILABLE",
+				Error:   "no markdown output is available for this job",
+			})
+		}
+		c.Set(fiber.HeaderContentType, "text/markdown; charset=utf-8")
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			writeMarkdownChunks(w, markdown)
+		})
+		return nil
+	}
+
+	// The envelope is written around the stored output so the output is
+	// not decoded and re-encoded.
+	prefix, err := json.Marshal(struct {
+		Success bool   `json:"success"`
+		ID      string `json:"id"`
+		Type    string `json:"type"`
+	}{true, job.ID.String(), job.Type})
+	if err != nil {
+		return err
+	}
+	output := job.Output.RawMessage
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		_, _ = w.Write(prefix[:len(prefix)-1])
+		_, _ = io.WriteString(w, `,"data":`)
+		_, _ = w.Write(output)
+		_, _ = io.WriteString(w, "}")
+		_ = w.Flush()
+	})
+	return nil
+}
+
+// jobResultMarkdown returns the markdown of a scrape or summarize job's
+// output, reporting false when there is none.
+func jobResultMarkdown(job db.Job) (string, bool) {
+	switch job.Type {
+	case "scrape":
+		// Like downloads, accept both a bare document and a ScrapeResponse
+		// envelope.
+		var out struct {
+			Markdown string `json:"markdown"`
+			Data     *struct {
+				Markdown string `json:"markdown"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(job.Output.RawMessage, &out); err != nil {
+			return "", false
+		}
+		if out.Markdown == "" && out.Data != nil {
+			out.Markdown = out.Data.Markdown
+		}
+		return out.Markdown, out.Markdown != ""
+	case "summarize":
+		var out summarizeOutput
+		if err := json.Unmarshal(job.Output.RawMessage, &out); err != nil || out.Summary == "" {
+			return "", false
+		}
+		return summarizeMarkdown(out), true
+	default:
+		return "", false
+	}
+}
+
+// writeMarkdownChunks writes markdown in jobResultChunkSize pieces,
+// flushing each so the client receives a chunked response. It stops when
+// a flush fails, which means the client went away.
+func writeMarkdownChunks(w *bufio.Writer, markdown string) {
+	for len(markdown) > 0 {
+		n := min(len(markdown), jobResultChunkSize)
+		_, _ = io.WriteString(w, markdown[:n])
+		markdown = markdown[n:]
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
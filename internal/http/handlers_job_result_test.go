@@ -0,0 +1,75 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+func TestJobResultMarkdown(t *testing.T) {
+	job := func(typ, output string) db.Job {
+		return db.Job{Type: typ, Output: pqtype.NullRawMessage{RawMessage: json.RawMessage(output), Valid: true}}
+	}
+
+	for _, tc := range []struct {
+		name, typ, output, want string
+		ok                      bool
+	}{
+		{"document", "scrape", `{"markdown":"# Title","html":"<h1>Title</h1>"}`, "# Title", true},
+		{"envelope", "scrape", `{"success":true,"data":{"markdown":"# Title"}}`, "# Title", true},
+		{"no markdown", "scrape", `{"html":"<h1>Title</h1>"}`, "", false},
+		{"summary", "summarize", `{"summary":"Short."}`, "Short.\n", true},
+		{"other type", "extract", `{"markdown":"# Title"}`, "", false},
+	} {
+		got, ok := jobResultMarkdown(job(tc.typ, tc.output))
+		if got != tc.want || ok != tc.ok {
+			t.Fatalf("%s: got %q, %v; want %q, %v", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestWriteMarkdownChunks(t *testing.T) {
+	markdown := strings.Repeat("a", 2*jobResultChunkSize+10)
+
+	var buf bytes.Buffer
+	w := bufio.NewWriterSize(&buf, 64)
+	writeMarkdownChunks(w, markdown)
+	if buf.String() != markdown {
+		t.Fatalf("expected %d bytes, got %d", len(markdown), buf.Len())
+	}
+	if w.Buffered() != 0 {
+		t.Fatalf("expected the writer to be flushed, %d bytes buffered", w.Buffered())
+	}
+}
+
+func TestJobResultHandler_BadRequests(t *testing.T) {
+	uid, tid := uuid.New(), uuid.New()
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("store", &store.Store{})
+		c.Locals("principal", Principal{UserID: &uid, TenantID: &tid})
+		return c.Next()
+	})
+	app.Get("/v1/jobs/:id/result", jobResultHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/jobs/not-a-uuid/result", nil)
+	req.Header.Set("Accept", "text/markdown")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
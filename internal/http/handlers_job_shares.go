@@ -0,0 +1,524 @@
+package http
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/jobinput"
+	"raito/internal/services"
+	"raito/internal/store"
+)
+
+const (
+	defaultShareTTL = 72 * time.Hour
+	maxShareTTL     = 720 * time.Hour
+)
+
+// JobShareItem describes a public share link for a job's results.
+type JobShareItem struct {
+	ID string `json:"id"`
+	// URL and DownloadURL are omitted once the link is revoked or expired.
+	URL            string     `json:"url,omitempty"`
+	DownloadURL    string     `json:"downloadUrl,omitempty"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	AccessCount    int64      `json:"accessCount"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type jobShareCreateRequest struct {
+	// TTLHours is how long the link stays valid; defaults to
+	// auth.shareLinks.defaultTTLHours.
+	TTLHours *int `json:"ttlHours,omitempty"`
+}
+
+type JobShareResponse struct {
+	Success bool          `json:"success"`
+	Code    string        `json:"code,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Share   *JobShareItem `json:"share,omitempty"`
+}
+
+type JobSharesResponse struct {
+	Success bool           `json:"success"`
+	Code    string         `json:"code,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Shares  []JobShareItem `json:"shares,omitempty"`
+	Revoked int64          `json:"revoked,omitempty"`
+}
+
+// SharedJob is the read-only view of a job exposed through a share link.
+type SharedJob struct {
+	ID          string     `json:"id"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	URL         string     `json:"url"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+type SharedJobResponse struct {
+	Success   bool       `json:"success"`
+	Code      string     `json:"code,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Job       *SharedJob `json:"job,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Data holds the job's documents (crawl, batch scrape); Output holds
+	// the stored result of jobs without documents (scrape, map, extract).
+	Data   []Document      `json:"data,omitempty"`
+	Output json.RawMessage `json:"output,omitempty"`
+}
+
+// shareLinkInfo labels the share link key derived from the session secret,
+// so share tokens and session tokens are never signed with the same key.
+const shareLinkInfo = "raito share links"
+
+// shareLinkSecret returns the key used to sign share tokens, or "" when
+// share links are not configured.
+func shareLinkSecret(cfg *config.Config) string {
+	if cfg.Auth.ShareLinks.Secret != "" {
+		return cfg.Auth.ShareLinks.Secret
+	}
+	if cfg.Auth.Session.Secret == "" {
+		return ""
+	}
+	key, err := hkdf.Key(sha256.New, []byte(cfg.Auth.Session.Secret), nil, shareLinkInfo, sha256.Size)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(key)
+}
+
+// shareTTL resolves the lifetime of a new share link. The default is
+// clamped to the configured maximum; explicit requests above it fail.
+func shareTTL(cfg *config.Config, requestedHours *int) (time.Duration, error) {
+	ttl := defaultShareTTL
+	if cfg.Auth.ShareLinks.DefaultTTLHours > 0 {
+		ttl = time.Duration(cfg.Auth.ShareLinks.DefaultTTLHours) * time.Hour
+	}
+	limit := maxShareTTL
+	if cfg.Auth.ShareLinks.MaxTTLHours > 0 {
+		limit = time.Duration(cfg.Auth.ShareLinks.MaxTTLHours) * time.Hour
+	}
+	if requestedHours == nil {
+		return min(ttl, limit), nil
+	}
+	if *requestedHours <= 0 {
+		return 0, errors.New("ttlHours must be positive")
+	}
+	ttl = time.Duration(*requestedHours) * time.Hour
+	if ttl > limit {
+		return 0, fmt.Errorf("ttlHours exceeds the maximum of %d hours", int(limit/time.Hour))
+	}
+	return ttl, nil
+}
+
+// signShareToken builds the token for a share link. It binds the share to
+// its job and expiry, so neither can be altered without the secret.
+func signShareToken(secret string, shareID, jobID uuid.UUID, expiresAt time.Time) string {
+	exp := strconv.FormatInt(expiresAt.Unix(), 10)
+	return shareID.String() + "." + exp + "." + shareSignature(secret, shareID, jobID, exp)
+}
+
+func shareSignature(secret string, shareID, jobID uuid.UUID, exp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(shareID.String() + "\n" + jobID.String() + "\n" + exp))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseShareToken splits a token into its share ID, expiry, and signature.
+func parseShareToken(token string) (uuid.UUID, int64, string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return uuid.Nil, 0, "", false
+	}
+	shareID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, 0, "", false
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return uuid.Nil, 0, "", false
+	}
+	return shareID, exp, parts[2], true
+}
+
+// verifyShareToken checks a token against its stored share and reports
+// whether it grants access now.
+func verifyShareToken(secret, token string, share db.JobShare, now time.Time) bool {
+	if secret == "" {
+		return false
+	}
+	shareID, exp, sig, ok := parseShareToken(token)
+	if !ok || shareID != share.ID || exp != share.ExpiresAt.Unix() {
+		return false
+	}
+	want := shareSignature(secret, share.ID, share.JobID, strconv.FormatInt(exp, 10))
+	if !hmac.Equal([]byte(sig), []byte(want)) {
+		return false
+	}
+	return !share.RevokedAt.Valid && now.Before(share.ExpiresAt)
+}
+
+// jobShareItemFromDB converts a share row. Links are built on
+// auth.shareLinks.baseURL, never on the request's Host header, and are
+// returned as paths when no base URL is configured.
+func jobShareItemFromDB(cfg *config.Config, secret string, share db.JobShare) JobShareItem {
+	item := JobShareItem{
+		ID:          share.ID.String(),
+		ExpiresAt:   share.ExpiresAt,
+		AccessCount: share.AccessCount,
+		CreatedAt:   share.CreatedAt,
+	}
+	if share.RevokedAt.Valid {
+		t := share.RevokedAt.Time
+		item.RevokedAt = &t
+	}
+	if share.LastAccessedAt.Valid {
+		t := share.LastAccessedAt.Time
+		item.LastAccessedAt = &t
+	}
+	if !share.RevokedAt.Valid && time.Now().Before(share.ExpiresAt) {
+		base := strings.TrimRight(strings.TrimSpace(cfg.Auth.ShareLinks.BaseURL), "/") + "/share/" + signShareToken(secret, share.ID, share.JobID, share.ExpiresAt)
+		item.URL = base
+		item.DownloadURL = base + "/download"
+	}
+	return item
+}
+
+// loadShareableJob resolves :id to a job the caller can see in their
+// active tenant, writing the error response when it cannot.
+func loadShareableJob(c *fiber.Ctx, st *store.Store) (db.Job, Principal, bool, error) {
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok {
+		return db.Job{}, p, false, c.Status(fiber.StatusUnauthorized).JSON(JobShareResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "Principal is not available for this request",
+		})
+	}
+	if p.TenantID == nil {
+		return db.Job{}, p, false, c.Status(fiber.StatusBadRequest).JSON(JobShareResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to share jobs",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return db.Job{}, p, false, c.Status(fiber.StatusBadRequest).JSON(JobShareResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid job id",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil || !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID || !jobViewerFor(c, st, p).CanSee(job) {
+		return db.Job{}, p, false, c.Status(fiber.StatusNotFound).JSON(JobShareResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "job not found",
+		})
+	}
+	return job, p, true, nil
+}
+
+// jobShareCreateHandler creates a signed, expiring link that gives
+// unauthenticated read-only access to a job's results and download.
+func jobShareCreateHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	job, p, ok, err := loadShareableJob(c, st)
+	if !ok {
+		return err
+	}
+
+	secret := shareLinkSecret(cfg)
+	if secret == "" {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(JobShareResponse{
+			Success: false,
+			Code:    "SHARE_LINKS_NOT_CONFIGURED",
+			Error:   "share links require auth.shareLinks.secret or auth.session.secret",
+		})
+	}
+	if job.ZeroRetention {
+		return c.Status(fiber.StatusConflict).JSON(JobShareResponse{
+			Success: false,
+			Code:    "ZERO_RETENTION_JOB",
+			Error:   "zero-retention jobs do not keep results to share",
+		})
+	}
+
+	var req jobShareCreateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(JobShareResponse{
+				Success: false,
+				Code:    "BAD_REQUEST_INVALID_JSON",
+				Error:   "Bad request, malformed JSON",
+			})
+		}
+	}
+	ttl, err := shareTTL(cfg, req.TTLHours)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobShareResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	// Tokens carry the expiry in whole seconds, so store it that way too.
+	expiresAt := time.Now().UTC().Add(ttl).Truncate(time.Second)
+	params := db.InsertJobShareParams{
+		ID:        uuid.New(),
+		JobID:     job.ID,
+		ExpiresAt: expiresAt,
+	}
+	if p.UserID != nil {
+		params.CreatedByUserID = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+	if p.APIKeyID != nil {
+		params.CreatedByApiKeyID = uuid.NullUUID{UUID: *p.APIKeyID, Valid: true}
+	}
+	share, err := db.New(st.DB).InsertJobShare(c.Context(), params)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(JobShareResponse{
+			Success: false,
+			Code:    "JOB_SHARE_CREATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "job.share.create", auditEventOptions{
+		TenantID:     p.TenantID,
+		ResourceType: "job",
+		ResourceID:   job.ID.String(),
+		Metadata:     map[string]any{"shareId": share.ID.String(), "expiresAt": share.ExpiresAt},
+	})
+
+	item := jobShareItemFromDB(cfg, secret, share)
+	return c.Status(fiber.StatusCreated).JSON(JobShareResponse{
+		Success: true,
+		Share:   &item,
+	})
+}
+
+// jobSharesListHandler lists every share link created for a job.
+func jobSharesListHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	job, _, ok, err := loadShareableJob(c, st)
+	if !ok {
+		return err
+	}
+
+	shares, err := db.New(st.DB).ListJobShares(c.Context(), job.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(JobSharesResponse{
+			Success: false,
+			Code:    "JOB_SHARE_LIST_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	secret := shareLinkSecret(cfg)
+	items := make([]JobShareItem, 0, len(shares))
+	for _, share := range shares {
+		items = append(items, jobShareItemFromDB(cfg, secret, share))
+	}
+	return c.Status(fiber.StatusOK).JSON(JobSharesResponse{
+		Success: true,
+		Shares:  items,
+	})
+}
+
+// jobShareRevokeHandler revokes one share link (/share/:shareId) or, when
+// no share ID is given, every active link for the job.
+func jobShareRevokeHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	job, p, ok, err := loadShareableJob(c, st)
+	if !ok {
+		return err
+	}
+
+	q := db.New(st.DB)
+	var revoked int64
+	var shareID string
+	if raw := c.Params("shareId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(JobSharesResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid share id",
+			})
+		}
+		shareID = id.String()
+		revoked, err = q.RevokeJobShare(c.Context(), db.RevokeJobShareParams{ID: id, JobID: job.ID})
+		if err == nil && revoked == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(JobSharesResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "share link not found or already revoked",
+			})
+		}
+	} else {
+		revoked, err = q.RevokeJobShares(c.Context(), job.ID)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(JobSharesResponse{
+			Success: false,
+			Code:    "JOB_SHARE_REVOKE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	metadata := map[string]any{"revoked": revoked}
+	if shareID != "" {
+		metadata["shareId"] = shareID
+	}
+	recordAuditEvent(c, st, "job.share.revoke", auditEventOptions{
+		TenantID:     p.TenantID,
+		ResourceType: "job",
+		ResourceID:   job.ID.String(),
+		Metadata:     metadata,
+	})
+
+	return c.Status(fiber.StatusOK).JSON(JobSharesResponse{
+		Success: true,
+		Revoked: revoked,
+	})
+}
+
+// resolveShareToken looks up the share behind :token and its job,
+// writing a 404 for unknown, tampered, expired, or revoked links.
+func resolveShareToken(c *fiber.Ctx, st *store.Store) (db.JobShare, db.Job, []db.Document, bool, error) {
+	cfg := c.Locals("config").(*config.Config)
+	notFound := func() error {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "share link not found or expired",
+		})
+	}
+
+	token := c.Params("token")
+	shareID, _, _, ok := parseShareToken(token)
+	if !ok {
+		return db.JobShare{}, db.Job{}, nil, false, notFound()
+	}
+
+	q := db.New(st.DB)
+	share, err := q.GetJobShare(c.Context(), shareID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.JobShare{}, db.Job{}, nil, false, notFound()
+		}
+		return db.JobShare{}, db.Job{}, nil, false, c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "JOB_SHARE_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if !verifyShareToken(shareLinkSecret(cfg), token, share, time.Now()) {
+		return db.JobShare{}, db.Job{}, nil, false, notFound()
+	}
+
+	job, docs, err := st.GetCrawlJobAndDocuments(c.Context(), share.JobID)
+	if err != nil {
+		return db.JobShare{}, db.Job{}, nil, false, notFound()
+	}
+
+	_ = q.TouchJobShare(c.Context(), share.ID)
+	return share, job, docs, true, nil
+}
+
+// sharedJobHandler serves a job's results to anyone holding a valid
+// share link.
+func sharedJobHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	share, job, docs, ok, err := resolveShareToken(c, st)
+	if !ok {
+		return err
+	}
+
+	resp := SharedJobResponse{
+		Success: true,
+		Job: &SharedJob{
+			ID:        job.ID.String(),
+			Type:      job.Type,
+			Status:    job.Status,
+			URL:       job.Url,
+			CreatedAt: job.CreatedAt,
+		},
+		ExpiresAt: &share.ExpiresAt,
+	}
+	if job.CompletedAt.Valid {
+		t := job.CompletedAt.Time
+		resp.Job.CompletedAt = &t
+	}
+
+	if job.Status == "completed" {
+		if len(docs) > 0 {
+			var input struct {
+				Formats        []any             `json:"formats"`
+				MaxFormatBytes *FormatByteLimits `json:"maxFormatBytes"`
+			}
+			_ = jobinput.Decode(job.Type, job.Input, &input)
+			// Shares are exports: excluded documents are left out.
+			annotations, _ := st.DocumentAnnotations(c.Context(), docs)
+			docs = withoutExcludedDocuments(docs, annotations)
+			mapped := services.NewJobDocumentService().BuildDocuments(docs, services.JobDocumentFormatOptions{
+				Formats:        input.Formats,
+				IncludeSummary: true,
+				IncludeJSON:    true,
+			})
+			resp.Data = make([]Document, 0, len(mapped))
+			for _, d := range mapped {
+				resp.Data = append(resp.Data, Document(d))
+			}
+			cfg, _ := c.Locals("config").(*config.Config)
+			capDocuments(resp.Data, formatSizeCaps(cfg, input.MaxFormatBytes))
+		} else if job.Output.Valid {
+			resp.Output = job.Output.RawMessage
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// sharedJobDownloadHandler serves the same download as
+// /v1/jobs/:id/download to holders of a valid share link.
+func sharedJobDownloadHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	_, job, docs, ok, err := resolveShareToken(c, st)
+	if !ok {
+		return err
+	}
+	return sendJobDownload(c, st, job, docs, jobDownloadOptions{})
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+func TestShareToken_Verify(t *testing.T) {
+	now := time.Now().UTC()
+	share := db.JobShare{
+		ID:        uuid.New(),
+		JobID:     uuid.New(),
+		ExpiresAt: now.Add(time.Hour).Truncate(time.Second),
+	}
+	token := signShareToken("secret", share.ID, share.JobID, share.ExpiresAt)
+
+	if !verifyShareToken("secret", token, share, now) {
+		t.Fatalf("expected freshly signed token to verify")
+	}
+	if verifyShareToken("other-secret", token, share, now) {
+		t.Fatalf("token must not verify with a different secret")
+	}
+	if verifyShareToken("", token, share, now) {
+		t.Fatalf("token must not verify without a secret")
+	}
+
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + "9999999999" + "." + parts[2]
+	if verifyShareToken("secret", forged, share, now) {
+		t.Fatalf("token with altered expiry must not verify")
+	}
+
+	otherJob := share
+	otherJob.JobID = uuid.New()
+	if verifyShareToken("secret", token, otherJob, now) {
+		t.Fatalf("token must be bound to its job")
+	}
+
+	if verifyShareToken("secret", token, share, share.ExpiresAt.Add(time.Second)) {
+		t.Fatalf("expired token must not verify")
+	}
+
+	revoked := share
+	revoked.RevokedAt = sql.NullTime{Time: now, Valid: true}
+	if verifyShareToken("secret", token, revoked, now) {
+		t.Fatalf("revoked token must not verify")
+	}
+
+	if _, _, _, ok := parseShareToken("not-a-token"); ok {
+		t.Fatalf("expected malformed token to be rejected")
+	}
+}
+
+func TestShareTTL(t *testing.T) {
+	cfg := &config.Config{}
+	if ttl, err := shareTTL(cfg, nil); err != nil || ttl != defaultShareTTL {
+		t.Fatalf("expected default TTL, got %v (%v)", ttl, err)
+	}
+
+	hours := 2
+	if ttl, err := shareTTL(cfg, &hours); err != nil || ttl != 2*time.Hour {
+		t.Fatalf("expected requested TTL, got %v (%v)", ttl, err)
+	}
+
+	cfg.Auth.ShareLinks.MaxTTLHours = 1
+	if ttl, err := shareTTL(cfg, nil); err != nil || ttl != time.Hour {
+		t.Fatalf("expected default TTL clamped to maximum, got %v (%v)", ttl, err)
+	}
+	if _, err := shareTTL(cfg, &hours); err == nil {
+		t.Fatalf("expected TTL above maximum to be rejected")
+	}
+
+	zero := 0
+	if _, err := shareTTL(cfg, &zero); err == nil {
+		t.Fatalf("expected non-positive TTL to be rejected")
+	}
+}
+
+func TestShareLinkSecret_DerivedFromSessionSecret(t *testing.T) {
+	cfg := &config.Config{}
+	if got := shareLinkSecret(cfg); got != "" {
+		t.Fatalf("expected no secret when nothing is configured, got %q", got)
+	}
+
+	cfg.Auth.Session.Secret = "session-secret"
+	derived := shareLinkSecret(cfg)
+	if derived == "" || derived == cfg.Auth.Session.Secret {
+		t.Fatalf("expected a key derived from the session secret, got %q", derived)
+	}
+	if again := shareLinkSecret(cfg); again != derived {
+		t.Fatalf("expected derivation to be stable, got %q and %q", derived, again)
+	}
+
+	cfg.Auth.ShareLinks.Secret = "share-secret"
+	if got := shareLinkSecret(cfg); got != "share-secret" {
+		t.Fatalf("expected configured share secret, got %q", got)
+	}
+}
+
+func TestJobShareItemFromDB_URL(t *testing.T) {
+	share := db.JobShare{
+		ID:        uuid.New(),
+		JobID:     uuid.New(),
+		ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second),
+	}
+	token := signShareToken("secret", share.ID, share.JobID, share.ExpiresAt)
+
+	cfg := &config.Config{}
+	item := jobShareItemFromDB(cfg, "secret", share)
+	if item.URL != "/share/"+token || item.DownloadURL != "/share/"+token+"/download" {
+		t.Fatalf("expected relative links without a base URL, got %q and %q", item.URL, item.DownloadURL)
+	}
+
+	cfg.Auth.ShareLinks.BaseURL = "https://raito.example.com/"
+	item = jobShareItemFromDB(cfg, "secret", share)
+	if item.URL != "https://raito.example.com/share/"+token {
+		t.Fatalf("expected link on the configured base URL, got %q", item.URL)
+	}
+
+	share.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if item := jobShareItemFromDB(cfg, "secret", share); item.URL != "" || item.DownloadURL != "" {
+		t.Fatalf("expected no links for a revoked share, got %q and %q", item.URL, item.DownloadURL)
+	}
+}
+
+// Test that share links ignore the Host header of the creating request.
+func TestJobShareCreateHandler_IgnoresHostHeader(t *testing.T) {
+	tenantID := uuid.New()
+	userID := uuid.New()
+	jobID := uuid.New()
+	share := db.JobShare{
+		ID:        uuid.New(),
+		JobID:     jobID,
+		ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second),
+		CreatedAt: time.Now(),
+	}
+	_, conn := newFakeDB(t, map[string][]any{
+		"GetJobByID": {db.GetJobByIDRow{
+			ID:       jobID,
+			Type:     "scrape",
+			Status:   "completed",
+			TenantID: uuid.NullUUID{UUID: tenantID, Valid: true},
+		}},
+		"InsertJobShare": {share},
+	})
+
+	cfg := &config.Config{}
+	cfg.Auth.Session.Secret = "session-secret"
+	cfg.Auth.ShareLinks.BaseURL = "https://raito.example.com"
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		c.Locals("store", &store.Store{DB: conn})
+		c.Locals("principal", Principal{UserID: &userID, TenantID: &tenantID, IsSystemAdmin: true})
+		return c.Next()
+	})
+	app.Post("/v1/jobs/:id/share", jobShareCreateHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/jobs/"+jobID.String()+"/share", nil)
+	req.Host = "attacker.example.net"
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	var body JobShareResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Share == nil || !strings.HasPrefix(body.Share.URL, "https://raito.example.com/share/") {
+		t.Fatalf("expected link on the configured base URL, got %+v", body.Share)
+	}
+}
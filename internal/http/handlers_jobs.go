@@ -2,7 +2,9 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,39 +12,64 @@ import (
 
 	"raito/internal/config"
 	"raito/internal/db"
+	"raito/internal/jobinput"
+	"raito/internal/jobs"
+	"raito/internal/metrics"
 	"raito/internal/store"
 )
 
 type JobItem struct {
-	ID          string     `json:"id"`
-	Type        string     `json:"type"`
-	Status      string     `json:"status"`
-	URL         string     `json:"url"`
-	Sync        bool       `json:"sync"`
-	Priority    int32      `json:"priority"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	CompletedAt *time.Time `json:"completedAt,omitempty"`
-	APIKeyID    string     `json:"apiKeyId,omitempty"`
-	APIKeyLabel string     `json:"apiKeyLabel,omitempty"`
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	Status       string     `json:"status"`
+	URL          string     `json:"url"`
+	Sync         bool       `json:"sync"`
+	Priority     int32      `json:"priority"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+	APIKeyID     string     `json:"apiKeyId,omitempty"`
+	APIKeyLabel  string     `json:"apiKeyLabel,omitempty"`
+	Visibility   string     `json:"visibility"`
+	CollectionID string     `json:"collectionId,omitempty"`
+	// Pinned jobs are exempt from retention cleanup and have no ExpiresAt.
+	Pinned bool `json:"pinned"`
 }
 
 type JobDetailItem struct {
-	ID          string     `json:"id"`
-	Type        string     `json:"type"`
-	Status      string     `json:"status"`
-	URL         string     `json:"url"`
-	Formats     []string   `json:"formats,omitempty"`
-	Sync        bool       `json:"sync"`
-	Priority    int32      `json:"priority"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	CompletedAt *time.Time `json:"completedAt,omitempty"`
-	Error       string     `json:"error,omitempty"`
-	APIKeyID    string     `json:"apiKeyId,omitempty"`
-	APIKeyLabel string     `json:"apiKeyLabel,omitempty"`
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	Status       string     `json:"status"`
+	URL          string     `json:"url"`
+	Formats      []string   `json:"formats,omitempty"`
+	Sync         bool       `json:"sync"`
+	Priority     int32      `json:"priority"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	APIKeyID     string     `json:"apiKeyId,omitempty"`
+	APIKeyLabel  string     `json:"apiKeyLabel,omitempty"`
+	Visibility   string     `json:"visibility"`
+	CollectionID string     `json:"collectionId,omitempty"`
+	// PreviousJobID links an incremental crawl to the crawl it was
+	// compared against.
+	PreviousJobID string `json:"previousJobId,omitempty"`
+	// Pool is the worker pool the job was routed to.
+	Pool string `json:"pool,omitempty"`
+	// Metrics reports the job's resource usage once it has finished.
+	Metrics *metrics.JobRuntimeStats `json:"metrics,omitempty"`
+	// ZeroRetention jobs keep no inputs, outputs, or documents after their
+	// results are delivered; PurgedAt records when that happened.
+	ZeroRetention bool       `json:"zeroRetention,omitempty"`
+	PurgedAt      *time.Time `json:"purgedAt,omitempty"`
+	// AppliedOptions lists the options the job was submitted with after
+	// defaults were resolved, and where each came from.
+	AppliedOptions AppliedOptions `json:"appliedOptions,omitempty"`
+	// Pinned jobs are exempt from retention cleanup and have no ExpiresAt.
+	Pinned bool `json:"pinned"`
 }
 
 type ListJobsResponse struct {
@@ -135,13 +162,42 @@ func jobsListHandler(c *fiber.Ctx) error {
 		offset = n
 	}
 
+	var pinnedFilter *bool
+	if v := c.Query("pinned"); v != "" {
+		val, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ListJobsResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid pinned value; expected true or false",
+			})
+		}
+		pinnedFilter = &val
+	}
+
+	var collectionID *uuid.UUID
+	if v := c.Query("collectionId"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ListJobsResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid collectionId value",
+			})
+		}
+		collectionID = &id
+	}
+
 	jobs, err := st.ListJobs(c.Context(), store.JobListFilter{
-		Type:     jobType,
-		Status:   status,
-		Sync:     syncFilter,
-		TenantID: tenantID,
-		Limit:    int32(limit),
-		Offset:   int32(offset),
+		Type:         jobType,
+		Status:       status,
+		Sync:         syncFilter,
+		TenantID:     tenantID,
+		CollectionID: collectionID,
+		Pinned:       pinnedFilter,
+		VisibleTo:    jobViewerFor(c, st, p),
+		Limit:        int32(limit),
+		Offset:       int32(offset),
 	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ListJobsResponse{
@@ -151,6 +207,17 @@ func jobsListHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	items := buildJobItems(c, cfg, st, jobs)
+
+	return c.Status(fiber.StatusOK).JSON(ListJobsResponse{
+		Success: true,
+		Jobs:    items,
+	})
+}
+
+// buildJobItems converts job rows into API list items, resolving API key
+// labels in a single lookup.
+func buildJobItems(c *fiber.Ctx, cfg *config.Config, st *store.Store, jobs []db.Job) []JobItem {
 	apiKeyLabels := map[uuid.UUID]string{}
 	{
 		ids := make([]uuid.UUID, 0, len(jobs))
@@ -183,7 +250,7 @@ func jobsListHandler(c *fiber.Ctx) error {
 			t := job.CompletedAt.Time
 			completedAt = &t
 		}
-		expiresAt := computeJobExpiresAt(cfg, job.Type, job.CreatedAt)
+		expiresAt := computeJobExpiresAt(cfg, job)
 
 		var apiKeyLabel string
 		var apiKeyID string
@@ -192,25 +259,24 @@ func jobsListHandler(c *fiber.Ctx) error {
 			apiKeyLabel = apiKeyLabels[job.ApiKeyID.UUID]
 		}
 		items = append(items, JobItem{
-			ID:          job.ID.String(),
-			Type:        job.Type,
-			Status:      job.Status,
-			URL:         job.Url,
-			Sync:        job.Sync,
-			Priority:    job.Priority,
-			CreatedAt:   job.CreatedAt,
-			ExpiresAt:   expiresAt,
-			UpdatedAt:   job.UpdatedAt,
-			CompletedAt: completedAt,
-			APIKeyID:    apiKeyID,
-			APIKeyLabel: apiKeyLabel,
+			ID:           job.ID.String(),
+			Type:         job.Type,
+			Status:       job.Status,
+			URL:          job.Url,
+			Sync:         job.Sync,
+			Priority:     job.Priority,
+			CreatedAt:    job.CreatedAt,
+			ExpiresAt:    expiresAt,
+			UpdatedAt:    job.UpdatedAt,
+			CompletedAt:  completedAt,
+			APIKeyID:     apiKeyID,
+			APIKeyLabel:  apiKeyLabel,
+			Visibility:   job.Visibility,
+			CollectionID: nullUUIDString(job.CollectionID),
+			Pinned:       job.Pinned,
 		})
 	}
-
-	return c.Status(fiber.StatusOK).JSON(ListJobsResponse{
-		Success: true,
-		Jobs:    items,
-	})
+	return items
 }
 
 // jobDetailHandler returns details for a single job visible to the current principal.
@@ -261,8 +327,8 @@ func jobDetailHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce active-tenant scoping for all callers.
-	if !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID {
+	// Enforce active-tenant scoping and job visibility for all callers.
+	if !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID || !jobViewerFor(c, st, p).CanSee(job) {
 		return c.Status(fiber.StatusNotFound).JSON(JobDetailResponse{
 			Success: false,
 			Code:    "NOT_FOUND",
@@ -292,25 +358,42 @@ func jobDetailHandler(c *fiber.Ctx) error {
 		}
 	}
 
-	expiresAt := computeJobExpiresAt(cfg, job.Type, job.CreatedAt)
+	expiresAt := computeJobExpiresAt(cfg, job)
 	formats := formatsFromJobInput(job.Type, job.Input)
 
 	detail := &JobDetailItem{
-		ID:          job.ID.String(),
-		Type:        job.Type,
-		Status:      job.Status,
-		URL:         job.Url,
-		Formats:     formats,
-		Sync:        job.Sync,
-		Priority:    job.Priority,
-		CreatedAt:   job.CreatedAt,
-		ExpiresAt:   expiresAt,
-		UpdatedAt:   job.UpdatedAt,
-		CompletedAt: completedAt,
-		Error:       errMsg,
-		APIKeyID:    apiKeyID,
-		APIKeyLabel: apiKeyLabel,
+		ID:            job.ID.String(),
+		Type:          job.Type,
+		Status:        job.Status,
+		URL:           job.Url,
+		Formats:       formats,
+		Sync:          job.Sync,
+		Priority:      job.Priority,
+		CreatedAt:     job.CreatedAt,
+		ExpiresAt:     expiresAt,
+		UpdatedAt:     job.UpdatedAt,
+		CompletedAt:   completedAt,
+		Error:         errMsg,
+		APIKeyID:      apiKeyID,
+		APIKeyLabel:   apiKeyLabel,
+		Visibility:    job.Visibility,
+		CollectionID:  nullUUIDString(job.CollectionID),
+		PreviousJobID: nullUUIDString(job.PreviousJobID),
+		Pool:          job.Pool,
+		ZeroRetention: job.ZeroRetention,
+		Pinned:        job.Pinned,
+	}
+	if job.PurgedAt.Valid {
+		t := job.PurgedAt.Time
+		detail.PurgedAt = &t
+	}
+	if job.Metrics.Valid {
+		var stats metrics.JobRuntimeStats
+		if err := json.Unmarshal(job.Metrics.RawMessage, &stats); err == nil {
+			detail.Metrics = &stats
+		}
 	}
+	detail.AppliedOptions = decodeAppliedOptions(job.AppliedOptions)
 
 	return c.Status(fiber.StatusOK).JSON(JobDetailResponse{
 		Success: true,
@@ -318,11 +401,97 @@ func jobDetailHandler(c *fiber.Ctx) error {
 	})
 }
 
+func nullUUIDString(id uuid.NullUUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return id.UUID.String()
+}
+
+// validateJobVisibility checks the optional visibility field accepted by
+// job-creating endpoints.
+func validateJobVisibility(visibility string) error {
+	switch visibility {
+	case "", "shared", "private":
+		return nil
+	default:
+		return fmt.Errorf("invalid visibility %q; expected shared or private", visibility)
+	}
+}
+
+// validateJobPool checks the optional pool field accepted by job-creating
+// endpoints against jobRouting.allowedPools.
+func validateJobPool(c *fiber.Ctx, pool string) error {
+	if pool == "" {
+		return nil
+	}
+	cfg, _ := c.Locals("config").(*config.Config)
+	if cfg == nil {
+		return fmt.Errorf("invalid pool %q", pool)
+	}
+	if _, err := jobs.RoutePool(cfg, jobs.RouteRequest{Requested: pool}); err != nil {
+		return fmt.Errorf("invalid pool %q; allowed pools: %s", pool, strings.Join(cfg.JobRouting.AllowedPools, ", "))
+	}
+	return nil
+}
+
+// routeJobPool resolves the worker pool for a job enqueued by an async
+// handler. The requested pool has already passed validateJobPool.
+func routeJobPool(c *fiber.Ctx, r jobs.RouteRequest) string {
+	cfg, _ := c.Locals("config").(*config.Config)
+	if cfg == nil {
+		return ""
+	}
+	pool, _ := jobs.RoutePool(cfg, r)
+	return pool
+}
+
+// scrapeOptionsUseBrowser reports whether per-page options ask for browser
+// rendering, which routes the job to jobRouting.browserPool.
+func scrapeOptionsUseBrowser(o *ScrapeOptions) bool {
+	return o != nil && o.UseBrowser != nil && *o.UseBrowser
+}
+
+// jobViewerFor returns the visibility filter for the principal within its
+// active tenant. System admins and tenant admins see every job, so nil is
+// returned for them; everyone else sees shared jobs plus private jobs they
+// created with their user or API key.
+func jobViewerFor(c *fiber.Ctx, st *store.Store, p Principal) *store.JobViewer {
+	if p.IsSystemAdmin {
+		return nil
+	}
+	if p.UserID != nil && p.TenantID != nil && st != nil && st.DB != nil {
+		q := db.New(st.DB)
+		member, err := q.GetTenantMember(c.Context(), db.GetTenantMemberParams{
+			TenantID: *p.TenantID,
+			UserID:   *p.UserID,
+		})
+		if err == nil && member.Role == "tenant_admin" {
+			return nil
+		}
+	}
+	return &store.JobViewer{UserID: p.UserID, APIKeyID: p.APIKeyID}
+}
+
+// jobHiddenFrom reports whether a per-type status route must answer 404 for
+// the job: the caller is not a system admin and the job belongs to another
+// tenant or is private to someone else.
+func jobHiddenFrom(c *fiber.Ctx, st *store.Store, job db.Job) bool {
+	p, ok := c.Locals("principal").(Principal)
+	if !ok || p.IsSystemAdmin {
+		return false
+	}
+	if job.TenantID.Valid && p.TenantID != nil && job.TenantID.UUID != *p.TenantID {
+		return true
+	}
+	return !jobViewerFor(c, st, p).CanSee(job)
+}
+
 func formatsFromJobInput(jobType string, input []byte) []string {
 	switch jobType {
 	case "scrape":
 		var req ScrapeRequest
-		if err := json.Unmarshal(input, &req); err != nil {
+		if err := jobinput.Decode(jobType, input, &req); err != nil {
 			return nil
 		}
 		formats := scrapeFormatNames(req.Formats)
@@ -332,7 +501,7 @@ func formatsFromJobInput(jobType string, input []byte) []string {
 		return formats
 	case "crawl":
 		var req CrawlRequest
-		if err := json.Unmarshal(input, &req); err != nil {
+		if err := jobinput.Decode(jobType, input, &req); err != nil {
 			return nil
 		}
 		formats := scrapeFormatNames(req.Formats)
@@ -342,7 +511,7 @@ func formatsFromJobInput(jobType string, input []byte) []string {
 		return formats
 	case "batch_scrape", "batch":
 		var req BatchScrapeRequest
-		if err := json.Unmarshal(input, &req); err != nil {
+		if err := jobinput.Decode(jobType, input, &req); err != nil {
 			return nil
 		}
 		formats := scrapeFormatNames(req.Formats)
@@ -352,7 +521,7 @@ func formatsFromJobInput(jobType string, input []byte) []string {
 		return formats
 	case "extract":
 		var req ExtractRequest
-		if err := json.Unmarshal(input, &req); err != nil {
+		if err := jobinput.Decode(jobType, input, &req); err != nil {
 			return nil
 		}
 		if req.ScrapeOptions == nil {
@@ -365,14 +534,14 @@ func formatsFromJobInput(jobType string, input []byte) []string {
 	}
 }
 
-func computeJobExpiresAt(cfg *config.Config, jobType string, createdAt time.Time) *time.Time {
-	if cfg == nil {
+func computeJobExpiresAt(cfg *config.Config, job db.Job) *time.Time {
+	if cfg == nil || job.Pinned {
 		return nil
 	}
 
 	ttl := cfg.Retention.Jobs
 	days := ttl.DefaultDays
-	switch jobType {
+	switch job.Type {
 	case "scrape":
 		if ttl.ScrapeDays > 0 {
 			days = ttl.ScrapeDays
@@ -381,7 +550,7 @@ func computeJobExpiresAt(cfg *config.Config, jobType string, createdAt time.Time
 		if ttl.MapDays > 0 {
 			days = ttl.MapDays
 		}
-	case "extract":
+	case "extract", "research", "summarize":
 		if ttl.ExtractDays > 0 {
 			days = ttl.ExtractDays
 		}
@@ -395,6 +564,6 @@ func computeJobExpiresAt(cfg *config.Config, jobType string, createdAt time.Time
 		return nil
 	}
 
-	expiresAt := createdAt.AddDate(0, 0, days)
+	expiresAt := job.CreatedAt.AddDate(0, 0, days)
 	return &expiresAt
 }
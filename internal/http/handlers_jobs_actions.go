@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,6 +19,10 @@ import (
 	"github.com/google/uuid"
 
 	"raito/internal/db"
+	"raito/internal/jobinput"
+	"raito/internal/model"
+	"raito/internal/scraper"
+	"raito/internal/services"
 	"raito/internal/store"
 )
 
@@ -68,8 +74,8 @@ func jobDeleteHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce active-tenant scoping for all callers.
-	if !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID {
+	// Enforce active-tenant scoping and job visibility for all callers.
+	if !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID || !jobViewerFor(c, st, p).CanSee(job) {
 		return c.Status(fiber.StatusNotFound).JSON(JobDeleteResponse{
 			Success: false,
 			Code:    "NOT_FOUND",
@@ -96,6 +102,101 @@ func jobDeleteHandler(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(JobDeleteResponse{Success: true})
 }
 
+// JobUpdateRequest is the body of PATCH /v1/jobs/:id.
+type JobUpdateRequest struct {
+	// Pinned exempts the job and its documents from retention cleanup.
+	Pinned *bool `json:"pinned"`
+}
+
+// jobUpdateHandler changes mutable job attributes (currently only the
+// pinned flag) and returns the updated job detail.
+func jobUpdateHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(JobDetailResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	if p.TenantID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobDetailResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to update jobs",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobDetailResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid job id",
+		})
+	}
+
+	var req JobUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobDetailResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if req.Pinned == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobDetailResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "pinned is required",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil || !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID || !jobViewerFor(c, st, p).CanSee(job) {
+		return c.Status(fiber.StatusNotFound).JSON(JobDetailResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "job not found",
+		})
+	}
+
+	// Zero-retention jobs are purged as soon as they finish; pinning
+	// them would contradict the caller's own retention choice.
+	if *req.Pinned && job.ZeroRetention {
+		return c.Status(fiber.StatusBadRequest).JSON(JobDetailResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "zero-retention jobs cannot be pinned",
+		})
+	}
+
+	if job.Pinned != *req.Pinned {
+		if err := st.SetJobPinned(c.Context(), jobID, *req.Pinned); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(JobDetailResponse{
+				Success: false,
+				Code:    "JOB_UPDATE_FAILED",
+				Error:   err.Error(),
+			})
+		}
+		action := "job.unpin"
+		if *req.Pinned {
+			action = "job.pin"
+		}
+		recordAuditEvent(c, st, action, auditEventOptions{
+			TenantID:     p.TenantID,
+			ResourceType: "job",
+			ResourceID:   jobID.String(),
+		})
+	}
+
+	return jobDetailHandler(c)
+}
+
 func jobDownloadHandler(c *fiber.Ctx) error {
 	st := c.Locals("store").(*store.Store)
 
@@ -145,8 +246,8 @@ func jobDownloadHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce active-tenant scoping for all callers.
-	if !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID {
+	// Enforce active-tenant scoping and job visibility for all callers.
+	if !job.TenantID.Valid || job.TenantID.UUID != *p.TenantID || !jobViewerFor(c, st, p).CanSee(job) {
 		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
 			Success: false,
 			Code:    "NOT_FOUND",
@@ -154,6 +255,44 @@ func jobDownloadHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	var opts jobDownloadOptions
+	for _, flag := range []struct {
+		name   string
+		target *bool
+	}{
+		{"includeExcluded", &opts.IncludeExcluded},
+		{"excludeDuplicates", &opts.ExcludeDuplicates},
+	} {
+		v := c.Query(flag.name)
+		if v == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid " + flag.name + " value; expected true or false",
+			})
+		}
+		*flag.target = parsed
+	}
+
+	return sendJobDownload(c, st, job, docs, opts)
+}
+
+// jobDownloadOptions selects which stored documents a download includes.
+type jobDownloadOptions struct {
+	// IncludeExcluded keeps documents annotated as excluded.
+	IncludeExcluded bool
+	// ExcludeDuplicates keeps only the first page of each near-duplicate
+	// cluster of a crawl.
+	ExcludeDuplicates bool
+}
+
+// sendJobDownload writes the download for a job whose access has already
+// been checked, picking a single file or zip based on type and formats.
+func sendJobDownload(c *fiber.Ctx, st *store.Store, job db.Job, docs []db.Document, opts jobDownloadOptions) error {
 	if job.Status != "completed" {
 		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
 			Success: false,
@@ -162,6 +301,30 @@ func jobDownloadHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	if opts.ExcludeDuplicates {
+		docs = withoutDuplicateDocuments(docs, jobDuplicateSummary(job))
+	}
+
+	if !opts.IncludeExcluded && len(docs) > 0 {
+		annotations, err := st.DocumentAnnotations(c.Context(), docs)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Success: false,
+				Code:    "JOB_LOOKUP_FAILED",
+				Error:   err.Error(),
+			})
+		}
+		kept := withoutExcludedDocuments(docs, annotations)
+		if len(kept) == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Success: false,
+				Code:    "NO_DOWNLOAD_AVAILABLE",
+				Error:   "every document of this job is excluded",
+			})
+		}
+		docs = kept
+	}
+
 	apiKeyLabel := ""
 	if job.ApiKeyID.Valid {
 		q := db.New(st.DB)
@@ -173,16 +336,21 @@ func jobDownloadHandler(c *fiber.Ctx) error {
 
 	filenameBase := buildDownloadBaseName(job.Type, job.Url, job.CreatedAt, job.ID)
 
+	// Images archived via downloadImages are bundled into the zip.
+	assets, _ := st.ListJobAssets(c.Context(), job.ID)
+
 	switch job.Type {
 	case "scrape":
-		return sendScrapeDownload(c, filenameBase, job, docs, apiKeyLabel)
+		return sendScrapeDownload(c, filenameBase, job, docs, assets, apiKeyLabel)
 	case "batch_scrape", "batch":
-		return sendDocumentsDownload(c, filenameBase, job, docs, true)
+		return sendDocumentsDownload(c, filenameBase, job, docs, assets, true)
+	case "summarize":
+		return sendSummarizeDownload(c, filenameBase, job)
 	default:
 		// For crawl/map/extract (and anything else): zip when documents exist,
 		// otherwise fall back to job output JSON if present.
 		if len(docs) > 0 {
-			return sendDocumentsDownload(c, filenameBase, job, docs, false)
+			return sendDocumentsDownload(c, filenameBase, job, docs, assets, false)
 		}
 		if job.Output.Valid && len(job.Output.RawMessage) > 0 {
 			return sendJSONDownload(c, filenameBase+".json", job.Output.RawMessage)
@@ -201,9 +369,9 @@ func sendJSONDownload(c *fiber.Ctx, filename string, raw json.RawMessage) error
 	return c.Send(raw)
 }
 
-func sendScrapeDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs []db.Document, apiKeyLabel string) error {
+func sendScrapeDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs []db.Document, assets []db.JobAsset, apiKeyLabel string) error {
 	formats := scrapeFormatNamesFromJob(job)
-	markdownOnly := len(formats) == 0 || (len(formats) == 1 && formats[0] == "markdown")
+	markdownOnly := len(assets) == 0 && (len(formats) == 0 || (len(formats) == 1 && formats[0] == "markdown"))
 
 	var outputDoc *Document
 	if job.Output.Valid && len(job.Output.RawMessage) > 0 {
@@ -216,7 +384,7 @@ func sendScrapeDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs []db
 			var d Document
 			if err := json.Unmarshal(job.Output.RawMessage, &d); err == nil {
 				// Treat any non-empty document payload as a valid scrape output.
-				if d.Markdown != "" || d.HTML != "" || d.RawHTML != "" || d.Summary != "" || len(d.JSON) > 0 || len(d.Branding) > 0 || d.Screenshot != "" {
+				if d.Markdown != "" || d.HTML != "" || d.RawHTML != "" || d.Summary != "" || len(d.JSON) > 0 || len(d.Branding) > 0 || len(d.Tables) > 0 || len(d.StructuredData) > 0 || d.Screenshot != "" {
 					outputDoc = &d
 				}
 			}
@@ -246,17 +414,24 @@ func sendScrapeDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs []db
 		return c.SendString(markdown)
 	}
 
-	return sendScrapeZipDownload(c, filenameBase+".zip", job, docs, outputDoc, formats)
+	return sendScrapeZipDownload(c, filenameBase+".zip", job, docs, assets, outputDoc, formats)
 }
 
-func sendDocumentsDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs []db.Document, alwaysZip bool) error {
+func sendDocumentsDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs []db.Document, assets []db.JobAsset, alwaysZip bool) error {
 	formats := formatsFromJobInput(job.Type, job.Input)
 	if len(formats) == 0 {
 		formats = []string{"markdown"}
 	}
 
+	// A crawl's report is bundled with its pages, so its download is
+	// always a zip.
+	report := jobCrawlReport(job)
+	if report != nil {
+		alwaysZip = true
+	}
+
 	// If there's only a single document and only markdown was requested, prefer a single file.
-	if !alwaysZip && len(docs) == 1 && len(formats) == 1 && formats[0] == "markdown" && docs[0].Markdown.Valid {
+	if !alwaysZip && len(assets) == 0 && len(docs) == 1 && docs[0].Type != store.DocumentTypeExtract && len(formats) == 1 && formats[0] == "markdown" && docs[0].Markdown.Valid {
 		filename := filenameBase + ".md"
 		c.Set(fiber.HeaderContentType, "text/markdown; charset=utf-8")
 		c.Set(fiber.HeaderContentDisposition, contentDisposition(filename))
@@ -269,14 +444,27 @@ func sendDocumentsDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs [
 	zw := zip.NewWriter(&buf)
 
 	wrote := false
+	assetPaths := zipWriteAssets(zw, job.ID, assets)
+
+	if report != nil {
+		_ = zipWriteFile(zw, "report.md", []byte(renderCrawlReportMarkdown(job.Url, report)))
+		if page, err := renderCrawlReportHTML(job.Url, report); err == nil {
+			_ = zipWriteFile(zw, "report.html", []byte(page))
+		}
+		wrote = true
+	}
 
 	for i, doc := range docs {
 		prefix := fmt.Sprintf("docs/%03d-%s", i+1, buildDocSlug(doc.Url))
+		// Extract results always include their JSON, whatever the formats.
+		if doc.Type == store.DocumentTypeExtract && zipWriteExtractJSON(zw, prefix+".json", doc.Metadata) {
+			wrote = true
+		}
 		for _, f := range formats {
 			switch strings.ToLower(f) {
 			case "markdown":
 				if doc.Markdown.Valid {
-					_ = zipWriteFile(zw, prefix+".md", []byte(doc.Markdown.String))
+					_ = zipWriteFile(zw, prefix+".md", []byte(rewriteAssetLinks(doc.Markdown.String, assetPaths, "../")))
 					wrote = true
 				}
 			case "html":
@@ -289,6 +477,14 @@ func sendDocumentsDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs [
 					_ = zipWriteFile(zw, prefix+".raw.html", []byte(doc.RawHtml.String))
 					wrote = true
 				}
+			case "tables":
+				tables := scraper.ExtractTables(doc.RawHtml.String)
+				if len(tables) == 0 {
+					tables = scraper.ExtractTables(doc.Html.String)
+				}
+				if zipWriteTables(zw, prefix+".tables.json", prefix+".table", tables) {
+					wrote = true
+				}
 			default:
 				// other formats aren't currently persisted per-document in the DB
 			}
@@ -316,7 +512,7 @@ func sendDocumentsDownload(c *fiber.Ctx, filenameBase string, job db.Job, docs [
 	return c.Send(buf.Bytes())
 }
 
-func sendScrapeZipDownload(c *fiber.Ctx, filename string, job db.Job, docs []db.Document, outputDoc *Document, formats []string) error {
+func sendScrapeZipDownload(c *fiber.Ctx, filename string, job db.Job, docs []db.Document, assets []db.JobAsset, outputDoc *Document, formats []string) error {
 	var doc Document
 	if outputDoc != nil {
 		doc = *outputDoc
@@ -340,6 +536,20 @@ func sendScrapeZipDownload(c *fiber.Ctx, filename string, job db.Job, docs []db.
 	zw := zip.NewWriter(&buf)
 
 	wroteFormatFile := false
+	assetPaths := zipWriteAssets(zw, job.ID, assets)
+	if len(formats) == 0 {
+		formats = []string{"markdown"}
+	}
+	// The auto format stands for the formats it picked for the page.
+	if i := slices.Index(formats, services.FormatAuto); i >= 0 {
+		expanded := slices.Delete(slices.Clone(formats), i, i+1)
+		for _, f := range doc.Metadata.AutoFormats {
+			if f = strings.ToLower(f); !slices.Contains(expanded, f) {
+				expanded = append(expanded, f)
+			}
+		}
+		formats = expanded
+	}
 
 	// Include metadata whenever available (it’s useful context and small).
 	if metaJSON, err := json.MarshalIndent(doc.Metadata, "", "  "); err == nil && len(metaJSON) > 0 {
@@ -351,7 +561,7 @@ func sendScrapeZipDownload(c *fiber.Ctx, filename string, job db.Job, docs []db.
 		switch f {
 		case "markdown":
 			if doc.Markdown != "" {
-				_ = zipWriteFile(zw, "scrape.md", []byte(doc.Markdown))
+				_ = zipWriteFile(zw, "scrape.md", []byte(rewriteAssetLinks(doc.Markdown, assetPaths, "")))
 				wroteFormatFile = true
 			}
 		case "html":
@@ -393,6 +603,24 @@ func sendScrapeZipDownload(c *fiber.Ctx, filename string, job db.Job, docs []db.
 				_ = zipWriteFile(zw, "branding.json", b)
 				wroteFormatFile = true
 			}
+		case "tables":
+			tables := doc.Tables
+			if len(tables) == 0 {
+				if doc.RawHTML != "" {
+					tables = scraper.ExtractTables(doc.RawHTML)
+				} else {
+					tables = scraper.ExtractTables(doc.HTML)
+				}
+			}
+			if zipWriteTables(zw, "tables.json", "tables/table", tables) {
+				wroteFormatFile = true
+			}
+		case "structureddata":
+			if len(doc.StructuredData) > 0 {
+				b, _ := json.MarshalIndent(doc.StructuredData, "", "  ")
+				_ = zipWriteFile(zw, "structured-data.json", b)
+				wroteFormatFile = true
+			}
 		case "screenshot":
 			if doc.Screenshot != "" {
 				if raw, err := base64.StdEncoding.DecodeString(doc.Screenshot); err == nil && len(raw) > 0 {
@@ -435,9 +663,36 @@ func zipWriteFile(zw *zip.Writer, name string, data []byte) error {
 	return err
 }
 
+// zipWriteTables writes parsed tables as one JSON file plus a CSV per table
+// named <csvPrefix>-001.csv, <csvPrefix>-002.csv, and so on.
+func zipWriteTables(zw *zip.Writer, jsonName, csvPrefix string, tables []model.Table) bool {
+	if len(tables) == 0 {
+		return false
+	}
+	b, _ := json.MarshalIndent(tables, "", "  ")
+	_ = zipWriteFile(zw, jsonName, b)
+	for i, t := range tables {
+		if data, err := scraper.TableCSV(t); err == nil {
+			_ = zipWriteFile(zw, fmt.Sprintf("%s-%03d.csv", csvPrefix, i+1), data)
+		}
+	}
+	return true
+}
+
+// zipWriteExtractJSON writes the extracted JSON held in an extract
+// document's metadata.
+func zipWriteExtractJSON(zw *zip.Writer, name string, metadata json.RawMessage) bool {
+	var md model.Metadata
+	if err := json.Unmarshal(metadata, &md); err != nil || len(md.JSON) == 0 {
+		return false
+	}
+	b, _ := json.MarshalIndent(md.JSON, "", "  ")
+	return zipWriteFile(zw, name, b) == nil
+}
+
 func scrapeFormatNamesFromJob(job db.Job) []string {
 	var req ScrapeRequest
-	if err := json.Unmarshal(job.Input, &req); err != nil {
+	if err := jobinput.Decode(job.Type, job.Input, &req); err != nil {
 		return nil
 	}
 	return scrapeFormatNames(req.Formats)
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/store"
+)
+
+type JobSearchHit struct {
+	JobID     string    `json:"jobId"`
+	JobType   string    `json:"jobType"`
+	URL       string    `json:"url"`
+	Source    string    `json:"source"`
+	Snippet   string    `json:"snippet,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type JobSearchResponse struct {
+	Success bool           `json:"success"`
+	Code    string         `json:"code,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Results []JobSearchHit `json:"results,omitempty"`
+}
+
+// jobsSearchHandler searches the active tenant's historical job results
+// (crawl/batch documents and completed scrape/extract outputs) by URL
+// pattern and free text so callers can reuse pages fetched earlier.
+func jobsSearchHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(JobSearchResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	if p.TenantID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobSearchResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to search jobs",
+		})
+	}
+
+	urlPattern := strings.TrimSpace(c.Query("url"))
+	text := strings.TrimSpace(c.Query("q"))
+	if urlPattern == "" && text == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(JobSearchResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "at least one of url or q is required",
+		})
+	}
+
+	limit := 20
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(JobSearchResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid limit value",
+			})
+		}
+		if n > 200 {
+			n = 200
+		}
+		limit = n
+	}
+
+	offset := 0
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(JobSearchResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid offset value",
+			})
+		}
+		offset = n
+	}
+
+	hits, err := st.SearchJobResults(c.Context(), store.JobSearchFilter{
+		TenantID:   *p.TenantID,
+		URLPattern: urlPattern,
+		Text:       text,
+		Type:       c.Query("type"),
+		VisibleTo:  jobViewerFor(c, st, p),
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(JobSearchResponse{
+			Success: false,
+			Code:    "JOB_SEARCH_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	results := make([]JobSearchHit, 0, len(hits))
+	for _, h := range hits {
+		results = append(results, JobSearchHit{
+			JobID:     h.JobID.String(),
+			JobType:   h.JobType,
+			URL:       h.URL,
+			Source:    h.Source,
+			Snippet:   h.Snippet,
+			CreatedAt: h.CreatedAt,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(JobSearchResponse{
+		Success: true,
+		Results: results,
+	})
+}
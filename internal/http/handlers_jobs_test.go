@@ -3,11 +3,17 @@ package http
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/jobinput"
 	"raito/internal/store"
 )
 
@@ -94,3 +100,179 @@ func TestJobDetail_InvalidID(t *testing.T) {
 		t.Fatalf("expected 400, got %d", resp.StatusCode)
 	}
 }
+
+func TestJobsSearch_RequiresQuery(t *testing.T) {
+	app := fiber.New()
+	st := &store.Store{}
+
+	app.Get("/v1/jobs/search", func(c *fiber.Ctx) error {
+		c.Locals("store", st)
+		uid := uuid.New()
+		tid := uuid.New()
+		c.Locals("principal", Principal{UserID: &uid, TenantID: &tid})
+		return jobsSearchHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/jobs/search", nil)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestJobViewerFor_PrivateJobs(t *testing.T) {
+	app := fiber.New()
+	owner := uuid.New()
+	other := uuid.New()
+	tid := uuid.New()
+
+	var ownerView, otherView, adminView *store.JobViewer
+	app.Get("/", func(c *fiber.Ctx) error {
+		ownerView = jobViewerFor(c, &store.Store{}, Principal{UserID: &owner, TenantID: &tid})
+		otherView = jobViewerFor(c, &store.Store{}, Principal{UserID: &other, TenantID: &tid})
+		adminView = jobViewerFor(c, &store.Store{}, Principal{UserID: &other, TenantID: &tid, IsSystemAdmin: true})
+		return nil
+	})
+	if _, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil), -1); err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+
+	job := db.Job{
+		Visibility:      "private",
+		CreatedByUserID: uuid.NullUUID{UUID: owner, Valid: true},
+	}
+	if !ownerView.CanSee(job) {
+		t.Fatalf("expected owner to see private job")
+	}
+	if otherView.CanSee(job) {
+		t.Fatalf("expected other tenant member not to see private job")
+	}
+	if !adminView.CanSee(job) {
+		t.Fatalf("expected system admin to see private job")
+	}
+
+	job.Visibility = "shared"
+	if !otherView.CanSee(job) {
+		t.Fatalf("expected shared job to be visible to tenant members")
+	}
+}
+
+func TestValidateJobVisibility(t *testing.T) {
+	for _, v := range []string{"", "shared", "private"} {
+		if err := validateJobVisibility(v); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", v, err)
+		}
+	}
+	if err := validateJobVisibility("public"); err == nil {
+		t.Fatalf("expected error for invalid visibility")
+	}
+}
+
+func TestJobUpdate_RequiresPinned(t *testing.T) {
+	app := fiber.New()
+	st := &store.Store{}
+
+	app.Patch("/v1/jobs/:id", func(c *fiber.Ctx) error {
+		c.Locals("store", st)
+		userID := uuid.New()
+		tenantID := uuid.New()
+		c.Locals("principal", Principal{UserID: &userID, TenantID: &tenantID})
+		return jobUpdateHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/jobs/"+uuid.New().String(), strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestComputeJobExpiresAt_Pinned(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Retention.Jobs.DefaultDays = 7
+	job := db.Job{Type: "crawl", CreatedAt: time.Now()}
+
+	if computeJobExpiresAt(cfg, job) == nil {
+		t.Fatalf("expected an expiry for an unpinned job")
+	}
+	job.Pinned = true
+	if got := computeJobExpiresAt(cfg, job); got != nil {
+		t.Fatalf("expected no expiry for a pinned job, got %v", got)
+	}
+}
+
+func TestFormatsFromJobInput_Versions(t *testing.T) {
+	enveloped, err := jobinput.Encode(ScrapeRequest{URL: "https://example.com", Formats: []any{"html", map[string]any{"type": "json"}}})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if got := formatsFromJobInput("scrape", enveloped); !reflect.DeepEqual(got, []string{"html", "json"}) {
+		t.Fatalf("unexpected formats %v", got)
+	}
+	// Inputs stored before the envelope existed.
+	if got := formatsFromJobInput("crawl", []byte(`{"url":"https://example.com","formats":["links"]}`)); !reflect.DeepEqual(got, []string{"links"}) {
+		t.Fatalf("unexpected formats %v", got)
+	}
+	if got := formatsFromJobInput("extract", []byte(`{"url":"https://example.com","fields":[{"name":"title"}],"scrapeOptions":{"formats":["markdown"]}}`)); !reflect.DeepEqual(got, []string{"markdown"}) {
+		t.Fatalf("unexpected formats %v", got)
+	}
+}
+
+func TestJobHiddenFrom_PrivateCrawl(t *testing.T) {
+	owner, member, tenantID := uuid.New(), uuid.New(), uuid.New()
+	job := db.Job{
+		ID:              uuid.New(),
+		Type:            "crawl",
+		TenantID:        uuid.NullUUID{UUID: tenantID, Valid: true},
+		CreatedByUserID: uuid.NullUUID{UUID: owner, Valid: true},
+		Visibility:      "private",
+	}
+
+	var p Principal
+	app := fiber.New()
+	app.Get("/v1/crawl/:id", func(c *fiber.Ctx) error {
+		c.Locals("principal", p)
+		if jobHiddenFrom(c, &store.Store{}, job) {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	otherTenant := uuid.New()
+	for _, tc := range []struct {
+		name string
+		p    Principal
+		want int
+	}{
+		{"owner", Principal{UserID: &owner, TenantID: &tenantID}, http.StatusOK},
+		{"other member", Principal{UserID: &member, TenantID: &tenantID}, http.StatusNotFound},
+		{"other tenant", Principal{UserID: &owner, TenantID: &otherTenant}, http.StatusNotFound},
+		{"system admin", Principal{UserID: &member, TenantID: &tenantID, IsSystemAdmin: true}, http.StatusOK},
+	} {
+		p = tc.p
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/crawl/"+job.ID.String(), nil), -1)
+		if err != nil {
+			t.Fatalf("app.Test error: %v", err)
+		}
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.want, resp.StatusCode)
+		}
+	}
+
+	job.Visibility = "shared"
+	p = Principal{UserID: &member, TenantID: &tenantID}
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/crawl/"+job.ID.String(), nil), -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected a shared crawl to be visible to members, got %d", resp.StatusCode)
+	}
+}
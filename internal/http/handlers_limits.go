@@ -0,0 +1,228 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/jobs"
+	"raito/internal/store"
+)
+
+// defaultWorkerMaxConcurrentJobs mirrors the job runner's default for
+// worker.maxConcurrentJobs.
+const defaultWorkerMaxConcurrentJobs = 4
+
+// LimitsResponse is the body of GET /v1/limits: the limits that apply to
+// the caller, so clients can pace themselves instead of discovering them
+// through 429s.
+type LimitsResponse struct {
+	Success     bool              `json:"success"`
+	Code        string            `json:"code,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	TenantID    string            `json:"tenantId,omitempty"`
+	RateLimit   LimitsRateLimit   `json:"rateLimit"`
+	Concurrency LimitsConcurrency `json:"concurrency"`
+	Crawl       LimitsCrawl       `json:"crawl"`
+	// LLMBudget is the tenant's monthly LLM budget and usage; it is omitted
+	// for callers without a tenant.
+	LLMBudget *LLMBudgetItem  `json:"llmBudget,omitempty"`
+	Retention LimitsRetention `json:"retention"`
+}
+
+// LimitsRateLimit is the caller's per-minute request limit. Remaining and
+// ResetAt are only set while the limit is enforced.
+type LimitsRateLimit struct {
+	Enabled   bool `json:"enabled"`
+	PerMinute int  `json:"perMinute"`
+	// Source is "apiKey" when the key has its own limit, else "default".
+	Source    string `json:"source"`
+	Used      int64  `json:"used"`
+	Remaining int64  `json:"remaining"`
+	ResetAt   string `json:"resetAt,omitempty"`
+}
+
+// LimitsConcurrency reports job concurrency caps and the caller's active
+// jobs. Caps are per worker process.
+type LimitsConcurrency struct {
+	ActiveJobs              int64 `json:"activeJobs"`
+	PendingJobs             int64 `json:"pendingJobs"`
+	RunningJobs             int64 `json:"runningJobs"`
+	MaxConcurrentJobs       int   `json:"maxConcurrentJobs"`
+	MaxConcurrentURLsPerJob int   `json:"maxConcurrentUrlsPerJob"`
+	AdaptiveConcurrency     bool  `json:"adaptiveConcurrency"`
+	SearchConcurrentScrapes int   `json:"searchConcurrentScrapes,omitempty"`
+}
+
+// LimitsCrawl holds the defaults applied to crawls that omit them, and the
+// request body size limit.
+type LimitsCrawl struct {
+	MaxDepthDefault int `json:"maxDepthDefault"`
+	MaxPagesDefault int `json:"maxPagesDefault"`
+	BodyLimitBytes  int `json:"bodyLimitBytes"`
+}
+
+// LimitsRetention reports how long job results are kept, in days by job
+// type. Zero means results are kept indefinitely.
+type LimitsRetention struct {
+	Enabled                   bool           `json:"enabled"`
+	JobDays                   map[string]int `json:"jobDays"`
+	DocumentDays              int            `json:"documentDays"`
+	ZeroRetentionGraceMinutes int            `json:"zeroRetentionGraceMinutes"`
+}
+
+// limitsRetention reports retention.* with per-type overrides resolved.
+func limitsRetention(cfg *config.Config) LimitsRetention {
+	ttl := cfg.Retention.Jobs
+	days := func(specific int) int {
+		if specific > 0 {
+			return specific
+		}
+		return ttl.DefaultDays
+	}
+	out := LimitsRetention{
+		Enabled: cfg.Retention.Enabled,
+		JobDays: map[string]int{
+			"scrape":       days(ttl.ScrapeDays),
+			"map":          days(ttl.MapDays),
+			"extract":      days(ttl.ExtractDays),
+			"crawl":        days(ttl.CrawlDays),
+			"batch_scrape": days(0),
+			"research":     days(ttl.ExtractDays),
+			"summarize":    days(ttl.ExtractDays),
+		},
+		DocumentDays:              cfg.Retention.Documents.DefaultDays,
+		ZeroRetentionGraceMinutes: int(jobs.ZeroRetentionGrace(cfg) / time.Minute),
+	}
+	if !out.Enabled {
+		for k := range out.JobDays {
+			out.JobDays[k] = 0
+		}
+		out.DocumentDays = 0
+	}
+	return out
+}
+
+// limitsConcurrency reports the configured concurrency caps.
+func limitsConcurrency(cfg *config.Config) LimitsConcurrency {
+	maxJobs := cfg.Worker.MaxConcurrentJobs
+	if maxJobs <= 0 {
+		maxJobs = defaultWorkerMaxConcurrentJobs
+	}
+	out := LimitsConcurrency{
+		MaxConcurrentJobs:       maxJobs,
+		MaxConcurrentURLsPerJob: urlConcurrency(cfg),
+		AdaptiveConcurrency:     cfg.Worker.AdaptiveConcurrency.Enabled,
+	}
+	if cfg.Search.Enabled {
+		out.SearchConcurrentScrapes = cfg.Search.MaxConcurrentScrapes
+	}
+	return out
+}
+
+// limitsRateLimit reports the caller's rate limit and its use in the
+// current window, as recorded by rateLimitMiddleware.
+func limitsRateLimit(cfg *config.Config, c *fiber.Ctx, now time.Time) LimitsRateLimit {
+	_, limit, source := rateLimitBucket(cfg, c)
+	out := LimitsRateLimit{PerMinute: limit, Source: source}
+	count, ok := c.Locals("rateLimitCount").(int64)
+	if !ok {
+		return out
+	}
+	out.Enabled = true
+	out.Used = count
+	out.Remaining = max(int64(limit)-count, 0)
+	out.ResetAt = now.UTC().Truncate(time.Minute).Add(time.Minute).Format(time.RFC3339)
+	return out
+}
+
+// countActiveJobs returns the tenant's pending and running jobs, or the
+// API key's for callers without a tenant.
+func countActiveJobs(ctx context.Context, st *store.Store, p Principal) (pending, running int64, err error) {
+	var where string
+	var arg any
+	switch {
+	case p.TenantID != nil:
+		where, arg = "tenant_id = $1", *p.TenantID
+	case p.APIKeyID != nil:
+		where, arg = "api_key_id = $1", *p.APIKeyID
+	default:
+		return 0, 0, nil
+	}
+	row := st.DB.QueryRowContext(ctx,
+		`SELECT COUNT(*) FILTER (WHERE status = 'pending'), COUNT(*) FILTER (WHERE status = 'running')
+		   FROM jobs WHERE `+where, arg)
+	err = row.Scan(&pending, &running)
+	return pending, running, err
+}
+
+// limitsHandler implements GET /v1/limits.
+func limitsHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	p, _ := c.Locals("principal").(Principal)
+	resp := LimitsResponse{
+		Success:     true,
+		RateLimit:   limitsRateLimit(cfg, c, time.Now()),
+		Concurrency: limitsConcurrency(cfg),
+		Crawl: LimitsCrawl{
+			MaxDepthDefault: cfg.Crawler.MaxDepthDefault,
+			MaxPagesDefault: cfg.Crawler.MaxPagesDefault,
+			BodyLimitBytes:  serverBodyLimit(cfg.Server),
+		},
+		Retention: limitsRetention(cfg),
+	}
+
+	pending, running, err := countActiveJobs(c.Context(), st, p)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(LimitsResponse{
+			Success: false,
+			Code:    "LIMITS_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	resp.Concurrency.PendingJobs = pending
+	resp.Concurrency.RunningJobs = running
+	resp.Concurrency.ActiveJobs = pending + running
+
+	if p.TenantID != nil {
+		resp.TenantID = p.TenantID.String()
+		budget, err := loadLLMBudget(c, db.New(st.DB), *p.TenantID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(LimitsResponse{
+				Success: false,
+				Code:    "LIMITS_LOOKUP_FAILED",
+				Error:   err.Error(),
+			})
+		}
+		resp.LLMBudget = &budget
+	}
+
+	return c.JSON(resp)
+}
+
+// v2ConcurrencyCheckHandler implements Firecrawl's
+// GET /v2/team/concurrency-check.
+func v2ConcurrencyCheckHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	p, _ := c.Locals("principal").(Principal)
+	_, running, err := countActiveJobs(c.Context(), st, p)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "LIMITS_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"success":        true,
+		"concurrency":    running,
+		"maxConcurrency": limitsConcurrency(cfg).MaxConcurrentJobs,
+	})
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+)
+
+func TestLimitsRateLimit(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.RateLimit.DefaultPerMinute = 60
+	now := time.Date(2026, 3, 4, 10, 15, 42, 0, time.UTC)
+
+	app := fiber.New()
+	var got []LimitsRateLimit
+	app.Get("/", func(c *fiber.Ctx) error {
+		got = append(got, limitsRateLimit(cfg, c, now))
+		c.Locals("apiKey", db.ApiKey{ID: uuid.New(), RateLimitPerMinute: sql.NullInt32{Int32: 10, Valid: true}})
+		c.Locals("rateLimitCount", int64(4))
+		got = append(got, limitsRateLimit(cfg, c, now))
+		return nil
+	})
+	if _, err := app.Test(httptest.NewRequest("GET", "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got[0].Enabled || got[0].PerMinute != 60 || got[0].Source != "default" || got[0].ResetAt != "" {
+		t.Fatalf("unexpected unenforced limit %+v", got[0])
+	}
+	want := LimitsRateLimit{Enabled: true, PerMinute: 10, Source: "apiKey", Used: 4, Remaining: 6, ResetAt: "2026-03-04T10:16:00Z"}
+	if got[1] != want {
+		t.Fatalf("expected %+v, got %+v", want, got[1])
+	}
+}
+
+func TestLimitsRetention(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Retention.Jobs.DefaultDays = 30
+	cfg.Retention.Jobs.CrawlDays = 7
+	cfg.Retention.Documents.DefaultDays = 14
+
+	if r := limitsRetention(cfg); r.Enabled || r.JobDays["crawl"] != 0 || r.DocumentDays != 0 {
+		t.Fatalf("expected no expiry while retention is disabled, got %+v", r)
+	}
+
+	cfg.Retention.Enabled = true
+	r := limitsRetention(cfg)
+	if r.JobDays["crawl"] != 7 || r.JobDays["scrape"] != 30 || r.JobDays["batch_scrape"] != 30 || r.DocumentDays != 14 {
+		t.Fatalf("unexpected retention %+v", r)
+	}
+	if r.ZeroRetentionGraceMinutes != 60 {
+		t.Fatalf("expected default zero-retention grace, got %d", r.ZeroRetentionGraceMinutes)
+	}
+}
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// LLMBudgetItem reports a tenant's monthly LLM caps and its usage in the
+// current month (UTC). Nil caps are unlimited.
+type LLMBudgetItem struct {
+	TenantID           string   `json:"tenantId"`
+	MonthlyTokenCap    *int64   `json:"monthlyTokenCap"`
+	MonthlySpendCapUSD *float64 `json:"monthlySpendCapUsd"`
+	Period             string   `json:"period"`
+	Calls              int64    `json:"calls"`
+	InputTokens        int64    `json:"inputTokens"`
+	OutputTokens       int64    `json:"outputTokens"`
+	SpendUSD           float64  `json:"spendUsd"`
+	PercentUsed        float64  `json:"percentUsed"`
+	Exceeded           bool     `json:"exceeded"`
+	// NotifiedPercent is the highest threshold (80 or 100) tenant admins
+	// have been notified about this month, or 0.
+	NotifiedPercent int    `json:"notifiedPercent"`
+	UpdatedAt       string `json:"updatedAt,omitempty"`
+}
+
+type LLMBudgetResponse struct {
+	Success bool           `json:"success"`
+	Code    string         `json:"code,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Budget  *LLMBudgetItem `json:"budget,omitempty"`
+}
+
+// LLMBudgetRequest replaces a tenant's caps; omitted or null caps are
+// unlimited.
+type LLMBudgetRequest struct {
+	MonthlyTokenCap    *int64   `json:"monthlyTokenCap"`
+	MonthlySpendCapUSD *float64 `json:"monthlySpendCapUsd"`
+}
+
+func llmBudgetItem(tenantID uuid.UUID, budget db.TenantLlmBudget, usage db.TenantLlmUsage, period time.Time) LLMBudgetItem {
+	pct := llmBudgetPercent(budget, usage)
+	item := LLMBudgetItem{
+		TenantID:           tenantID.String(),
+		MonthlyTokenCap:    nullInt64Ptr(budget.MonthlyTokenCap),
+		MonthlySpendCapUSD: spendCapUSD(budget.MonthlySpendCapMicros),
+		Period:             period.Format("2006-01"),
+		Calls:              usage.Calls,
+		InputTokens:        usage.InputTokens,
+		OutputTokens:       usage.OutputTokens,
+		SpendUSD:           float64(usage.SpendMicros) / 1e6,
+		PercentUsed:        math.Round(pct*100) / 100,
+		Exceeded:           pct >= 100,
+		NotifiedPercent:    int(usage.NotifiedPercent),
+	}
+	if !budget.UpdatedAt.IsZero() {
+		item.UpdatedAt = budget.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return item
+}
+
+// loadLLMBudget reads a tenant's budget and current-month usage; missing
+// rows read as no caps and no usage.
+func loadLLMBudget(c *fiber.Ctx, q *db.Queries, tenantID uuid.UUID) (LLMBudgetItem, error) {
+	budget, err := q.GetTenantLLMBudget(c.Context(), tenantID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return LLMBudgetItem{}, err
+	}
+	period := llmBudgetPeriod(time.Now())
+	usage, err := q.GetTenantLLMUsage(c.Context(), db.GetTenantLLMUsageParams{TenantID: tenantID, Period: period})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return LLMBudgetItem{}, err
+	}
+	return llmBudgetItem(tenantID, budget, usage, period), nil
+}
+
+// tenantLLMBudgetHandler implements GET /v1/tenants/:id/llm-budget for
+// members of the tenant.
+func tenantLLMBudgetHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, false)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	item, err := loadLLMBudget(c, db.New(st.DB), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "LLM_BUDGET_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(LLMBudgetResponse{
+		Success: true,
+		Budget:  &item,
+	})
+}
+
+// adminGetTenantLLMBudgetHandler implements GET /admin/tenants/:id/llm-budget.
+func adminGetTenantLLMBudgetHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid tenant id",
+		})
+	}
+
+	item, err := loadLLMBudget(c, db.New(st.DB), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "LLM_BUDGET_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(LLMBudgetResponse{
+		Success: true,
+		Budget:  &item,
+	})
+}
+
+// adminPutTenantLLMBudgetHandler implements PUT /admin/tenants/:id/llm-budget,
+// replacing the tenant's monthly token and spend caps.
+func adminPutTenantLLMBudgetHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	p, _ := c.Locals("principal").(Principal)
+
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid tenant id",
+		})
+	}
+
+	var req LLMBudgetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if req.MonthlyTokenCap != nil && *req.MonthlyTokenCap < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "monthlyTokenCap must be >= 0",
+		})
+	}
+	if req.MonthlySpendCapUSD != nil && (*req.MonthlySpendCapUSD < 0 || math.IsNaN(*req.MonthlySpendCapUSD)) {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "monthlySpendCapUsd must be >= 0",
+		})
+	}
+
+	if _, err := q.GetTenantByID(c.Context(), tenantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(LLMBudgetResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "tenant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "TENANT_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	params := db.UpsertTenantLLMBudgetParams{TenantID: tenantID}
+	if req.MonthlyTokenCap != nil {
+		params.MonthlyTokenCap = sql.NullInt64{Int64: *req.MonthlyTokenCap, Valid: true}
+	}
+	if req.MonthlySpendCapUSD != nil {
+		params.MonthlySpendCapMicros = sql.NullInt64{Int64: int64(math.Round(*req.MonthlySpendCapUSD * 1e6)), Valid: true}
+	}
+	if p.UserID != nil {
+		params.UpdatedByUserID = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+	if _, err := q.UpsertTenantLLMBudget(c.Context(), params); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "LLM_BUDGET_UPDATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	item, err := loadLLMBudget(c, q, tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMBudgetResponse{
+			Success: false,
+			Code:    "LLM_BUDGET_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "admin.tenant.llm_budget.set", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+		Metadata: map[string]any{
+			"monthlyTokenCap":    item.MonthlyTokenCap,
+			"monthlySpendCapUsd": item.MonthlySpendCapUSD,
+		},
+	})
+
+	return c.Status(fiber.StatusOK).JSON(LLMBudgetResponse{
+		Success: true,
+		Budget:  &item,
+	})
+}
@@ -0,0 +1,240 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/llm"
+	"raito/internal/store"
+)
+
+// LLMPolicyItem reports which LLM providers and models a tenant may use.
+// An empty AllowedModels allows every configured model.
+type LLMPolicyItem struct {
+	TenantID      string   `json:"tenantId"`
+	AllowedModels []string `json:"allowedModels"`
+	// DefaultProvider and DefaultModel apply when a request does not set
+	// provider or model; empty falls back to the server defaults.
+	DefaultProvider string `json:"defaultProvider,omitempty"`
+	DefaultModel    string `json:"defaultModel,omitempty"`
+	// EffectiveProvider and EffectiveModel are what a request without
+	// overrides uses.
+	EffectiveProvider string `json:"effectiveProvider"`
+	EffectiveModel    string `json:"effectiveModel"`
+	UpdatedAt         string `json:"updatedAt,omitempty"`
+}
+
+type LLMPolicyResponse struct {
+	Success bool           `json:"success"`
+	Code    string         `json:"code,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Policy  *LLMPolicyItem `json:"policy,omitempty"`
+}
+
+// LLMPolicyRequest replaces a tenant's LLM policy. Allowlist entries are
+// "provider/model", "provider/*" or "provider".
+type LLMPolicyRequest struct {
+	AllowedModels   []string `json:"allowedModels"`
+	DefaultProvider string   `json:"defaultProvider"`
+	DefaultModel    string   `json:"defaultModel"`
+}
+
+func llmPolicyItem(cfg *config.Config, tenantID uuid.UUID, policy db.TenantLlmPolicy) LLMPolicyItem {
+	item := LLMPolicyItem{
+		TenantID:        tenantID.String(),
+		AllowedModels:   llmPolicyModels(policy.AllowedModels),
+		DefaultProvider: policy.DefaultProvider.String,
+		DefaultModel:    policy.DefaultModel.String,
+	}
+	if item.AllowedModels == nil {
+		item.AllowedModels = []string{}
+	}
+	provider, model := llm.ResolveModel(cfg, item.DefaultProvider, item.DefaultModel)
+	item.EffectiveProvider, item.EffectiveModel = string(provider), model
+	if !policy.UpdatedAt.IsZero() {
+		item.UpdatedAt = policy.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return item
+}
+
+// loadLLMPolicy reads a tenant's LLM policy; a missing row reads as no
+// restrictions.
+func loadLLMPolicy(c *fiber.Ctx, q *db.Queries, tenantID uuid.UUID) (LLMPolicyItem, error) {
+	cfg := c.Locals("config").(*config.Config)
+	policy, err := q.GetTenantLLMPolicy(c.Context(), tenantID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return LLMPolicyItem{}, err
+	}
+	return llmPolicyItem(cfg, tenantID, policy), nil
+}
+
+// tenantLLMPolicyHandler implements GET /v1/tenants/:id/llm-policy for
+// members of the tenant.
+func tenantLLMPolicyHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, false)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	item, err := loadLLMPolicy(c, db.New(st.DB), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "LLM_POLICY_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(LLMPolicyResponse{
+		Success: true,
+		Policy:  &item,
+	})
+}
+
+// adminGetTenantLLMPolicyHandler implements GET /admin/tenants/:id/llm-policy.
+func adminGetTenantLLMPolicyHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid tenant id",
+		})
+	}
+
+	item, err := loadLLMPolicy(c, db.New(st.DB), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "LLM_POLICY_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(LLMPolicyResponse{
+		Success: true,
+		Policy:  &item,
+	})
+}
+
+// adminPutTenantLLMPolicyHandler implements PUT /admin/tenants/:id/llm-policy,
+// replacing the tenant's model allowlist and defaults.
+func adminPutTenantLLMPolicyHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	p, _ := c.Locals("principal").(Principal)
+
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid tenant id",
+		})
+	}
+
+	var req LLMPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	allowed := make([]string, 0, len(req.AllowedModels))
+	for _, entry := range req.AllowedModels {
+		normalized, err := normalizeLLMPolicyEntry(entry)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(LLMPolicyResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "allowedModels: " + err.Error(),
+			})
+		}
+		allowed = append(allowed, normalized)
+	}
+
+	defaultProvider := strings.ToLower(strings.TrimSpace(req.DefaultProvider))
+	defaultModel := strings.TrimSpace(req.DefaultModel)
+	if defaultProvider != "" && !validLLMProvider(defaultProvider) {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "unsupported defaultProvider: " + req.DefaultProvider,
+		})
+	}
+	// The defaults must themselves be allowed, or requests without
+	// overrides would always fail.
+	provider, model := llm.ResolveModel(cfg, defaultProvider, defaultModel)
+	if !llmModelAllowed(allowed, string(provider), model) {
+		return c.Status(fiber.StatusBadRequest).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "the default model " + string(provider) + "/" + model + " is not in allowedModels",
+		})
+	}
+
+	if _, err := q.GetTenantByID(c.Context(), tenantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(LLMPolicyResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "tenant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "TENANT_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	rawAllowed, _ := json.Marshal(allowed)
+	params := db.UpsertTenantLLMPolicyParams{
+		TenantID:        tenantID,
+		AllowedModels:   rawAllowed,
+		DefaultProvider: sql.NullString{String: defaultProvider, Valid: defaultProvider != ""},
+		DefaultModel:    sql.NullString{String: defaultModel, Valid: defaultModel != ""},
+	}
+	if p.UserID != nil {
+		params.UpdatedByUserID = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+	policy, err := q.UpsertTenantLLMPolicy(c.Context(), params)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(LLMPolicyResponse{
+			Success: false,
+			Code:    "LLM_POLICY_UPDATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	item := llmPolicyItem(cfg, tenantID, policy)
+
+	recordAuditEvent(c, st, "admin.tenant.llm_policy.set", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+		Metadata: map[string]any{
+			"allowedModels":   item.AllowedModels,
+			"defaultProvider": item.DefaultProvider,
+			"defaultModel":    item.DefaultModel,
+		},
+	})
+
+	return c.Status(fiber.StatusOK).JSON(LLMPolicyResponse{
+		Success: true,
+		Policy:  &item,
+	})
+}
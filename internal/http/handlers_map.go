@@ -23,6 +23,16 @@ func mapHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	_, code, msg := applyCollectionDefaults(c, reqBody.CollectionID, &reqBody)
+	if code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(MapResponse{
+			Success: false,
+			Links:   []MapLink{},
+			Code:    code,
+			Error:   msg,
+		})
+	}
+
 	if reqBody.URL == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(MapResponse{
 			Success: false,
@@ -32,6 +42,24 @@ func mapHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := validateJobVisibility(reqBody.Visibility); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(MapResponse{
+			Success: false,
+			Links:   []MapLink{},
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobPool(c, reqBody.Pool); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(MapResponse{
+			Success: false,
+			Links:   []MapLink{},
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
 	cfg := c.Locals("config").(*config.Config)
 
 	// Derive timeout from request and config
@@ -44,7 +72,7 @@ func mapHandler(c *fiber.Ctx) error {
 	// nodes remain lightweight and workers perform discovery.
 	if execVal := c.Locals("executor"); execVal != nil {
 		if exec, ok := execVal.(WorkExecutor); ok && exec != nil {
-			baseCtx := context.Background()
+			baseCtx := c.UserContext()
 			if val := c.Locals("principal"); val != nil {
 				if p, ok := val.(Principal); ok {
 					if p.TenantID != nil {
@@ -53,6 +81,9 @@ func mapHandler(c *fiber.Ctx) error {
 					if p.APIKeyID != nil {
 						baseCtx = context.WithValue(baseCtx, "api_key_id", *p.APIKeyID)
 					}
+					if p.UserID != nil {
+						baseCtx = context.WithValue(baseCtx, "user_id", *p.UserID)
+					}
 				}
 			}
 
@@ -10,6 +10,7 @@ import (
 
 	"raito/internal/config"
 	"raito/internal/db"
+	"raito/internal/services"
 	"raito/internal/store"
 )
 
@@ -29,6 +30,9 @@ type MeUser struct {
 	IsSystemAdmin   bool    `json:"isSystemAdmin"`
 	DefaultTenantID *string `json:"defaultTenantId,omitempty"`
 	ThemePreference string  `json:"themePreference,omitempty"`
+	// MustChangePassword is set while the user still has to replace a
+	// one-time password; other endpoints reject their session until then.
+	MustChangePassword bool `json:"mustChangePassword,omitempty"`
 }
 
 type MeTenantBrief struct {
@@ -78,12 +82,13 @@ func meHandler(c *fiber.Ctx) error {
 	}
 
 	meUser := &MeUser{
-		ID:              user.ID.String(),
-		Email:           user.Email,
-		Name:            name,
-		IsSystemAdmin:   user.IsSystemAdmin,
-		DefaultTenantID: defaultTenantID,
-		ThemePreference: user.ThemePreference,
+		ID:                 user.ID.String(),
+		Email:              user.Email,
+		Name:               name,
+		IsSystemAdmin:      user.IsSystemAdmin,
+		DefaultTenantID:    defaultTenantID,
+		ThemePreference:    user.ThemePreference,
+		MustChangePassword: user.MustChangePassword,
 	}
 
 	// Try to locate the user's personal tenant by owner_user_id/type.
@@ -245,3 +250,193 @@ func updateMeHandler(c *fiber.Ctx) error {
 	_ = updated
 	return meHandler(c)
 }
+
+type SetDefaultTenantRequest struct {
+	// TenantID is the tenant to start new sessions and user-bound API keys
+	// in; null or "" clears it so the personal tenant is used.
+	TenantID *string `json:"tenantId"`
+}
+
+type SetDefaultTenantResponse struct {
+	Success         bool    `json:"success"`
+	Code            string  `json:"code,omitempty"`
+	Error           string  `json:"error,omitempty"`
+	DefaultTenantID *string `json:"defaultTenantId"`
+}
+
+// setDefaultTenantHandler persists the caller's sticky default tenant
+// (users.default_tenant_id). Login flows and user-bound API keys without a
+// tenant start in it, falling back to the personal tenant.
+func setDefaultTenantHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(SetDefaultTenantResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	var req SetDefaultTenantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(SetDefaultTenantResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	next := uuid.NullUUID{}
+	if req.TenantID != nil && *req.TenantID != "" {
+		id, err := uuid.Parse(*req.TenantID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(SetDefaultTenantResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "tenantId must be a valid UUID",
+			})
+		}
+		next = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	q := db.New(st.DB)
+	if next.Valid {
+		if _, err := q.GetTenantMember(c.Context(), db.GetTenantMemberParams{
+			TenantID: next.UUID,
+			UserID:   *p.UserID,
+		}); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return c.Status(fiber.StatusForbidden).JSON(SetDefaultTenantResponse{
+					Success: false,
+					Code:    "FORBIDDEN",
+					Error:   "You are not a member of the requested tenant",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(SetDefaultTenantResponse{
+				Success: false,
+				Code:    "INTERNAL_ERROR",
+				Error:   err.Error(),
+			})
+		}
+	}
+
+	user, err := q.GetUserByID(c.Context(), *p.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(SetDefaultTenantResponse{
+			Success: false,
+			Code:    "INTERNAL_ERROR",
+			Error:   err.Error(),
+		})
+	}
+
+	if _, err := q.UpdateUserProfile(c.Context(), db.UpdateUserProfileParams{
+		ID:              user.ID,
+		Name:            user.Name,
+		ThemePreference: user.ThemePreference,
+		DefaultTenantID: next,
+	}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(SetDefaultTenantResponse{
+			Success: false,
+			Code:    "INTERNAL_ERROR",
+			Error:   err.Error(),
+		})
+	}
+
+	var out *string
+	if next.Valid {
+		id := next.UUID.String()
+		out = &id
+	}
+	return c.Status(fiber.StatusOK).JSON(SetDefaultTenantResponse{
+		Success:         true,
+		DefaultTenantID: out,
+	})
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// changePasswordHandler lets a local user replace their password. It is the
+// only API a session may use while a forced password change is pending.
+func changePasswordHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	var req ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "currentPassword and newPassword are required",
+		})
+	}
+	if len(req.NewPassword) < services.MinPasswordLength {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   services.ErrPasswordTooShort.Error(),
+		})
+	}
+
+	authSvc := services.NewAuthService(cfg, st)
+	if _, err := authSvc.ChangeLocalPassword(c.Context(), *p.UserID, req.CurrentPassword, req.NewPassword); err != nil {
+		switch err {
+		case services.ErrInvalidCredentials:
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+				Success: false,
+				Code:    "INVALID_CREDENTIALS",
+				Error:   "current password is incorrect",
+			})
+		case services.ErrAuthProviderMismatch:
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "AUTH_PROVIDER_MISMATCH",
+				Error:   "password can only be changed for local users",
+			})
+		case services.ErrUserDisabled:
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Success: false,
+				Code:    "USER_DISABLED",
+				Error:   "user account is disabled",
+			})
+		case services.ErrPasswordTooShort, services.ErrPasswordUnchanged:
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   err.Error(),
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Success: false,
+				Code:    "INTERNAL_ERROR",
+				Error:   err.Error(),
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+	})
+}
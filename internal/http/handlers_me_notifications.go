@@ -0,0 +1,170 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/notify"
+	"raito/internal/store"
+)
+
+// NotificationPreferences selects which of the caller's own jobs trigger
+// a notification and where it is delivered.
+type NotificationPreferences struct {
+	OnCompleted bool `json:"onCompleted"`
+	OnFailed    bool `json:"onFailed"`
+	// Email sends notifications to the user's account email.
+	Email      bool   `json:"email"`
+	WebhookURL string `json:"webhookUrl,omitempty"`
+}
+
+type NotificationPreferencesResponse struct {
+	Success     bool                     `json:"success"`
+	Code        string                   `json:"code,omitempty"`
+	Error       string                   `json:"error,omitempty"`
+	Preferences *NotificationPreferences `json:"preferences,omitempty"`
+	// EmailAvailable reports whether the server can send email.
+	EmailAvailable bool `json:"emailAvailable"`
+}
+
+// getNotificationPreferencesHandler returns the caller's job notification
+// preferences; users who never saved any get everything disabled.
+func getNotificationPreferencesHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(NotificationPreferencesResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	q := db.New(st.DB)
+	prefs := &NotificationPreferences{}
+	row, err := q.GetUserNotificationPreferences(c.Context(), *p.UserID)
+	switch {
+	case err == nil:
+		prefs = notificationPreferencesFromRow(row)
+	case !errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusInternalServerError).JSON(NotificationPreferencesResponse{
+			Success: false,
+			Code:    "INTERNAL_ERROR",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(NotificationPreferencesResponse{
+		Success:        true,
+		Preferences:    prefs,
+		EmailAvailable: notify.EmailAvailable(cfg.Notifications),
+	})
+}
+
+// updateNotificationPreferencesHandler replaces the caller's job
+// notification preferences.
+func updateNotificationPreferencesHandler(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(NotificationPreferencesResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	var req NotificationPreferences
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(NotificationPreferencesResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	emailAvailable := notify.EmailAvailable(cfg.Notifications)
+	if err := validateNotificationPreferences(&req, emailAvailable); err != nil {
+		code := "BAD_REQUEST"
+		if errors.Is(err, errEmailNotificationsUnavailable) {
+			code = "EMAIL_NOTIFICATIONS_UNAVAILABLE"
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(NotificationPreferencesResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
+	q := db.New(st.DB)
+	row, err := q.UpsertUserNotificationPreferences(c.Context(), db.UpsertUserNotificationPreferencesParams{
+		UserID:      *p.UserID,
+		OnCompleted: req.OnCompleted,
+		OnFailed:    req.OnFailed,
+		Email:       req.Email,
+		WebhookUrl:  sql.NullString{String: req.WebhookURL, Valid: req.WebhookURL != ""},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(NotificationPreferencesResponse{
+			Success: false,
+			Code:    "INTERNAL_ERROR",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(NotificationPreferencesResponse{
+		Success:        true,
+		Preferences:    notificationPreferencesFromRow(row),
+		EmailAvailable: emailAvailable,
+	})
+}
+
+var errEmailNotificationsUnavailable = errors.New("email notifications are not configured on this server")
+
+// validateNotificationPreferences trims the webhook URL and checks that it
+// is an absolute http(s) URL, and that email is only enabled when the
+// server can send it.
+func validateNotificationPreferences(prefs *NotificationPreferences, emailAvailable bool) error {
+	prefs.WebhookURL = strings.TrimSpace(prefs.WebhookURL)
+	if prefs.WebhookURL != "" {
+		if _, ok := parseWebhookURL(prefs.WebhookURL); !ok {
+			return errors.New("webhookUrl must be an absolute http(s) URL")
+		}
+	}
+	if prefs.Email && !emailAvailable {
+		return errEmailNotificationsUnavailable
+	}
+	return nil
+}
+
+// parseWebhookURL trims raw and reports whether it is an absolute http(s)
+// URL that webhook notifications can be posted to.
+func parseWebhookURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return raw, true
+}
+
+func notificationPreferencesFromRow(row db.UserNotificationPreference) *NotificationPreferences {
+	return &NotificationPreferences{
+		OnCompleted: row.OnCompleted,
+		OnFailed:    row.OnFailed,
+		Email:       row.Email,
+		WebhookURL:  row.WebhookUrl.String,
+	}
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/store"
+)
+
+func TestSetDefaultTenant_Unauthenticated(t *testing.T) {
+	app := fiber.New()
+	st := &store.Store{}
+
+	app.Patch("/v1/me/default-tenant", func(c *fiber.Ctx) error {
+		c.Locals("store", st)
+		return setDefaultTenantHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/me/default-tenant", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestSetDefaultTenant_InvalidTenantID(t *testing.T) {
+	app := fiber.New()
+	st := &store.Store{}
+
+	app.Patch("/v1/me/default-tenant", func(c *fiber.Ctx) error {
+		c.Locals("store", st)
+		id := uuid.New()
+		c.Locals("principal", Principal{UserID: &id})
+		return setDefaultTenantHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/me/default-tenant", bytes.NewBufferString(`{"tenantId":"not-a-uuid"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestChangePassword_RejectsShortPassword(t *testing.T) {
+	app := fiber.New()
+	st := &store.Store{}
+	cfg := &config.Config{}
+
+	app.Post("/v1/me/password", func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		c.Locals("store", st)
+		id := uuid.New()
+		c.Locals("principal", Principal{UserID: &id})
+		return changePasswordHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/me/password", bytes.NewBufferString(`{"currentPassword":"one-time","newPassword":"short"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestPasswordChangeAllowedPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/auth/session":    true,
+		"/v1/me":           true,
+		"/v1/me/password":  true,
+		"/v1/me/password/": true,
+		"/v1/scrape":       false,
+		"/admin/users":     false,
+	} {
+		if got := passwordChangeAllowedPath(path); got != want {
+			t.Fatalf("passwordChangeAllowedPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestValidateNotificationPreferences(t *testing.T) {
+	prefs := &NotificationPreferences{OnFailed: true, WebhookURL: "  https://hooks.example.com/raito "}
+	if err := validateNotificationPreferences(prefs, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prefs.WebhookURL != "https://hooks.example.com/raito" {
+		t.Fatalf("expected the webhook URL to be trimmed, got %q", prefs.WebhookURL)
+	}
+
+	for _, raw := range []string{"hooks.example.com", "ftp://hooks.example.com", "https://"} {
+		if err := validateNotificationPreferences(&NotificationPreferences{WebhookURL: raw}, true); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+
+	if err := validateNotificationPreferences(&NotificationPreferences{Email: true}, false); !errors.Is(err, errEmailNotificationsUnavailable) {
+		t.Fatalf("expected email to be refused without a mail server, got %v", err)
+	}
+	if err := validateNotificationPreferences(&NotificationPreferences{Email: true}, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUpdateNotificationPreferences_Unauthenticated(t *testing.T) {
+	app := fiber.New()
+	app.Put("/v1/me/notifications", func(c *fiber.Ctx) error {
+		c.Locals("config", &config.Config{})
+		c.Locals("store", &store.Store{})
+		return updateNotificationPreferencesHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/me/notifications", bytes.NewBufferString(`{"onFailed":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/scraper"
+	"raito/internal/scrapeutil"
+	"raito/internal/services"
+)
+
+// parseUnsupportedFormats need a live page or an LLM call and are not
+// offered for uploaded files.
+var parseUnsupportedFormats = []string{"screenshot", "json", "summary", "branding", "classify", "a11y", "performance"}
+
+// parseHandler converts an uploaded HTML, PDF, or DOCX file into a
+// Document using the same markdown and format pipeline as scrape, for
+// content that is not reachable over the network.
+//
+// The multipart form takes the file in "file", an optional "formats"
+// field (a JSON array or a comma-separated list), and an optional "url"
+// used as sourceURL and to resolve relative links.
+func parseHandler(c *fiber.Ctx) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Missing required multipart file field 'file'",
+		})
+	}
+
+	formats, err := parseFormatsField(c.FormValue("formats"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+	for _, name := range parseUnsupportedFormats {
+		if scrapeutil.WantsFormat(formats, name) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "UNSUPPORTED_FORMAT",
+				Error:   "format '" + name + "' is not supported for uploaded files",
+			})
+		}
+	}
+
+	sourceURL := strings.TrimSpace(c.FormValue("url"))
+	if sourceURL == "" {
+		sourceURL = "file:///" + url.PathEscape(filepath.Base(fh.Filename))
+	} else if u, err := url.Parse(sourceURL); err != nil || u.Scheme == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Field 'url' must be an absolute URL",
+		})
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Unable to read uploaded file",
+		})
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Unable to read uploaded file",
+		})
+	}
+
+	kind := scraper.DetectDocumentKind(fh.Filename, fh.Header.Get("Content-Type"), data)
+	if kind == "" {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(ErrorResponse{
+			Success: false,
+			Code:    "UNSUPPORTED_FILE_TYPE",
+			Error:   scraper.ErrUnsupportedDocument.Error(),
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+	res, err := scraper.ParseDocument(kind, sourceURL, data, cfg.Scraper.DocumentMaxDecompressedBytes)
+	if err != nil {
+		status, code := fiber.StatusUnprocessableEntity, "PARSE_FAILED"
+		switch {
+		case errors.Is(err, scraper.ErrUnsupportedDocument):
+			status = fiber.StatusUnsupportedMediaType
+		case errors.Is(err, scraper.ErrDocumentTooLarge):
+			status, code = fiber.StatusRequestEntityTooLarge, "DOCUMENT_TOO_LARGE"
+		}
+		return c.Status(status).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
+	svcRes, err := services.NewScrapeService(cfg).Scrape(c.Context(), &services.ScrapeRequest{
+		Result:  res,
+		Formats: formats,
+	})
+	if err != nil || svcRes == nil || svcRes.Document == nil {
+		msg := "empty parse document"
+		if err != nil {
+			msg = err.Error()
+		}
+		return c.Status(http.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "PARSE_FAILED",
+			Error:   msg,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(ScrapeResponse{
+		Success: true,
+		Data:    svcRes.Document,
+	})
+}
+
+// parseFormatsField accepts the formats form field either as a JSON array
+// (same shape as the scrape API) or as a comma-separated list of names.
+func parseFormatsField(raw string) ([]any, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(raw, "[") {
+		var formats []any
+		if err := json.Unmarshal([]byte(raw), &formats); err != nil {
+			return nil, errors.New("Field 'formats' must be a JSON array or a comma-separated list")
+		}
+		return formats, nil
+	}
+	var formats []any
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			formats = append(formats, name)
+		}
+	}
+	return formats, nil
+}
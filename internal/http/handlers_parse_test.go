@@ -0,0 +1,94 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+)
+
+func newParseTestApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", &config.Config{})
+		return c.Next()
+	})
+	app.Post("/v1/parse", parseHandler)
+	return app
+}
+
+func parseUpload(t *testing.T, app *fiber.App, filename, content string, fields map[string]string) (int, ScrapeResponse) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if filename != "" {
+		fw, err := mw.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatalf("CreateFormFile: %v", err)
+		}
+		_, _ = fw.Write([]byte(content))
+	}
+	for k, v := range fields {
+		_ = mw.WriteField(k, v)
+	}
+	_ = mw.Close()
+
+	req := httptest.NewRequest("POST", "/v1/parse", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	var out ScrapeResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("decode response %q: %v", raw, err)
+	}
+	return resp.StatusCode, out
+}
+
+func TestParseHandler_HTMLUpload(t *testing.T) {
+	app := newParseTestApp()
+	html := `<html><head><title>Offline page</title></head><body><h1>Hello</h1><a href="/next">Next</a></body></html>`
+
+	status, out := parseUpload(t, app, "page.html", html, map[string]string{
+		"formats": "markdown,links",
+		"url":     "https://intranet.example.com/docs/page",
+	})
+	if status != fiber.StatusOK || !out.Success || out.Data == nil {
+		t.Fatalf("expected success, got %d %+v", status, out)
+	}
+	if !strings.Contains(out.Data.Markdown, "# Hello") {
+		t.Fatalf("expected markdown, got %q", out.Data.Markdown)
+	}
+	if out.Data.HTML != "" {
+		t.Fatalf("expected html omitted when not requested")
+	}
+	if len(out.Data.Links) != 1 || out.Data.Links[0] != "https://intranet.example.com/next" {
+		t.Fatalf("expected links resolved against url field, got %v", out.Data.Links)
+	}
+	if out.Data.Metadata.Title != "Offline page" || out.Data.Engine != "parse" {
+		t.Fatalf("unexpected metadata/engine: %+v %q", out.Data.Metadata, out.Data.Engine)
+	}
+}
+
+func TestParseHandler_Errors(t *testing.T) {
+	app := newParseTestApp()
+
+	if status, out := parseUpload(t, app, "", "", nil); status != fiber.StatusBadRequest || out.Success {
+		t.Fatalf("expected missing file rejected, got %d %+v", status, out)
+	}
+	if status, out := parseUpload(t, app, "notes.txt", "plain text", nil); status != fiber.StatusUnsupportedMediaType || out.Code != "UNSUPPORTED_FILE_TYPE" {
+		t.Fatalf("expected unsupported type, got %d %+v", status, out)
+	}
+	if status, out := parseUpload(t, app, "doc.pdf", "%PDF-1.4 garbage", map[string]string{"formats": `["markdown",{"type":"json"}]`}); status != fiber.StatusBadRequest || out.Code != "UNSUPPORTED_FORMAT" {
+		t.Fatalf("expected json format rejected, got %d %+v", status, out)
+	}
+}
@@ -0,0 +1,314 @@
+package http
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/crawler"
+	"raito/internal/llm"
+	"raito/internal/scrapeutil"
+)
+
+// Cost model used by job previews. Credits follow Firecrawl's scheme of
+// one credit per page plus four for each LLM call; the timings are rough
+// per-page averages.
+const (
+	previewCreditsPerPage     = 1
+	previewCreditsPerLLMCall  = 4
+	previewHTTPPageSeconds    = 2.0
+	previewBrowserPageSeconds = 6.0
+	previewLLMCallSeconds     = 5.0
+	// maxPreviewSampleURLs caps the discovered URLs listed in a preview.
+	maxPreviewSampleURLs = 10
+)
+
+// JobPreviewResponse is the body of POST /v1/crawl/preview and
+// POST /v1/extract/preview: what a job would cost, estimated from
+// discovery alone, without scraping any page or calling the LLM.
+type JobPreviewResponse struct {
+	Success bool   `json:"success"`
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Type    string `json:"type,omitempty"`
+	// EstimatedPages is the number of pages the job would scrape.
+	EstimatedPages int `json:"estimatedPages"`
+	// DiscoveredURLs counts the URLs discovery found before the limit was
+	// applied.
+	DiscoveredURLs int `json:"discoveredUrls"`
+	Limit          int `json:"limit,omitempty"`
+	// LLMFormats lists the requested formats computed by the LLM for every
+	// page.
+	LLMFormats              []string `json:"llmFormats,omitempty"`
+	EstimatedLLMCalls       int      `json:"estimatedLlmCalls"`
+	EstimatedCredits        int      `json:"estimatedCredits"`
+	EstimatedRuntimeSeconds int      `json:"estimatedRuntimeSeconds"`
+	// SampleURLs lists up to ten of the pages the job would scrape.
+	SampleURLs     []string       `json:"sampleUrls"`
+	AppliedOptions AppliedOptions `json:"appliedOptions,omitempty"`
+	Warning        string         `json:"warning,omitempty"`
+}
+
+// mapLinksFunc discovers a site's pages; crawler.Map outside tests.
+type mapLinksFunc func(ctx context.Context, opts crawler.MapOptions) (*crawler.MapResult, error)
+
+// crawlLLMFormats returns the requested crawl formats that cost one LLM
+// call per page. classify only calls the LLM when one is configured.
+func crawlLLMFormats(cfg *config.Config, formats []any) []string {
+	var out []string
+	if ok, _ := scrapeutil.GetSummaryFormatConfig(formats); ok {
+		out = append(out, "summary")
+	}
+	if ok, _, _ := scrapeutil.GetJSONFormatConfig(formats); ok {
+		out = append(out, "json")
+	}
+	if ok, _ := scrapeutil.GetBrandingFormatConfig(formats); ok {
+		out = append(out, "branding")
+	}
+	if ok, _ := scrapeutil.GetClassifyFormatConfig(formats); ok && llmConfigured(cfg) {
+		out = append(out, "classify")
+	}
+	return out
+}
+
+// llmConfigured reports whether the default LLM provider can be used.
+func llmConfigured(cfg *config.Config) bool {
+	_, _, _, err := llm.NewClientFromConfig(cfg, "", "")
+	return err == nil
+}
+
+// previewEstimate fills in the credits and runtime of a preview whose
+// pages and LLM calls are known. Pages are scraped in rounds of
+// concurrency pages, each taking pageSeconds plus the page's share of the
+// LLM calls; discovery is the time discovery already took.
+func previewEstimate(resp *JobPreviewResponse, concurrency int, pageSeconds float64, discovery time.Duration) {
+	concurrency = max(concurrency, 1)
+	resp.EstimatedCredits = resp.EstimatedPages*previewCreditsPerPage + resp.EstimatedLLMCalls*previewCreditsPerLLMCall
+
+	rounds := math.Ceil(float64(resp.EstimatedPages) / float64(concurrency))
+	seconds := discovery.Seconds() + rounds*pageSeconds
+	if resp.EstimatedPages > 0 {
+		llmPerPage := float64(resp.EstimatedLLMCalls) / float64(resp.EstimatedPages)
+		seconds += rounds * llmPerPage * previewLLMCallSeconds
+	} else {
+		seconds += float64(resp.EstimatedLLMCalls) * previewLLMCallSeconds
+	}
+	resp.EstimatedRuntimeSeconds = int(math.Ceil(seconds))
+}
+
+// previewCrawl runs a crawl's discovery pass, as runCrawlJob does, and
+// estimates the job from the pages it would queue.
+func previewCrawl(ctx context.Context, cfg *config.Config, req CrawlRequest, mapLinks mapLinksFunc) (JobPreviewResponse, error) {
+	start := time.Now()
+	limit, discovery := crawlDiscoveryOptions(cfg, req)
+	seeds := crawlSeeds(req)
+
+	resp := JobPreviewResponse{Success: true, Type: "crawl", Limit: limit, SampleURLs: []string{}}
+	seen := map[string]bool{}
+	for _, seed := range seeds {
+		seen[seed] = true
+	}
+	maxDepth := crawlMaxDepth(cfg, req)
+	var candidates []string
+	for _, seed := range seeds {
+		opts := discovery
+		opts.URL = seed
+		res, err := mapLinks(ctx, opts)
+		if err != nil {
+			return resp, err
+		}
+		if res.Warning != "" && resp.Warning == "" {
+			resp.Warning = res.Warning
+		}
+		for _, l := range crawler.LinksWithin(res.Links, maxDepth) {
+			if !seen[l.URL] {
+				seen[l.URL] = true
+				candidates = append(candidates, l.URL)
+			}
+		}
+	}
+	resp.DiscoveredURLs = len(candidates)
+	resp.EstimatedPages = len(seeds) + min(len(candidates), limit)
+	if req.SPA != nil && *req.SPA && resp.EstimatedPages < limit+len(seeds) {
+		resp.EstimatedPages = limit + len(seeds)
+		resp.Warning = "spa crawls discover client-side routes while scraping; the estimate assumes the limit is reached"
+	}
+	for _, u := range append(seeds, candidates...) {
+		if len(resp.SampleURLs) >= maxPreviewSampleURLs {
+			break
+		}
+		resp.SampleURLs = append(resp.SampleURLs, u)
+	}
+
+	resp.LLMFormats = crawlLLMFormats(cfg, req.Formats)
+	resp.EstimatedLLMCalls = resp.EstimatedPages * len(resp.LLMFormats)
+
+	pageSeconds := previewHTTPPageSeconds
+	if (req.SPA != nil && *req.SPA) || scrapeOptionsUseBrowser(req.ScrapeOptions) {
+		pageSeconds = previewBrowserPageSeconds
+	}
+	// a11y and performance load every page in the browser again.
+	for _, f := range []string{"a11y", "performance"} {
+		if scrapeutil.WantsFormat(req.Formats, f) {
+			pageSeconds += previewBrowserPageSeconds
+		}
+	}
+	concurrency := urlConcurrency(cfg)
+	if req.MaxConcurrency != nil && *req.MaxConcurrency > 0 && *req.MaxConcurrency < concurrency {
+		concurrency = *req.MaxConcurrency
+	}
+	previewEstimate(&resp, concurrency, pageSeconds, time.Since(start))
+	return resp, nil
+}
+
+// previewExtract expands an extract's wildcard URLs, as runExtractJob
+// does, and estimates the job: pages are scraped one at a time and the
+// LLM is called once per page, or once in merge mode.
+func previewExtract(ctx context.Context, cfg *config.Config, req ExtractRequest, mapLinks mapLinksFunc) (JobPreviewResponse, error) {
+	start := time.Now()
+	resp := JobPreviewResponse{Success: true, Type: "extract", SampleURLs: []string{}}
+
+	urls, wildcard, err := expandExtractURLs(ctx, cfg, &extractDeps{mapLinks: mapLinks}, req)
+	if err != nil {
+		return resp, err
+	}
+	if wildcard {
+		resp.Limit = extractWildcardLimit(cfg, req)
+	}
+	resp.DiscoveredURLs = len(urls)
+	resp.EstimatedPages = len(urls)
+	resp.SampleURLs = append(resp.SampleURLs, urls[:min(len(urls), maxPreviewSampleURLs)]...)
+
+	resp.EstimatedLLMCalls = len(urls)
+	if req.Mode == extractModeMerge && len(urls) > 0 {
+		resp.EstimatedLLMCalls = 1
+	}
+	previewEstimate(&resp, 1, previewHTTPPageSeconds, time.Since(start))
+	return resp, nil
+}
+
+// crawlPreviewHandler implements POST /v1/crawl/preview. It accepts a crawl
+// request body and reports the pages, credits and runtime the crawl is
+// expected to take, without enqueuing it.
+func crawlPreviewHandler(c *fiber.Ctx) error {
+	var reqBody CrawlRequest
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	if _, code, msg := applyCollectionDefaults(c, reqBody.CollectionID, &reqBody); code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
+	formatsSource, err := applyDefaultFormats(c, &reqBody.Formats)
+	if err != nil {
+		return defaultFormatsLookupFailed(c, err)
+	}
+
+	if err := normalizeCrawlSeeds(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateClassifyFormat(reqBody.Formats); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+
+	resp, err := previewCrawl(c.UserContext(), cfg, reqBody, crawler.Map)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "PREVIEW_DISCOVERY_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	resp.AppliedOptions = resolveCrawlOptions(cfg, &reqBody, c.Body())
+	resp.AppliedOptions.setFormatsSource(formatsSource)
+	return c.JSON(resp)
+}
+
+// extractPreviewHandler implements POST /v1/extract/preview. It accepts an
+// extract request body and reports the pages, LLM calls, credits and
+// runtime the extract is expected to take, without enqueuing it. The
+// schema is not required.
+func extractPreviewHandler(c *fiber.Ctx) error {
+	var reqBody ExtractRequest
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	if _, code, msg := applyCollectionDefaults(c, reqBody.CollectionID, &reqBody); code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
+
+	if len(reqBody.URLs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Missing required field 'urls'",
+		})
+	}
+
+	if code, msg := normalizeExtractURLs(reqBody.URLs); code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
+
+	if err := validateExtractMode(reqBody.Mode); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if reqBody.Limit != nil && *reqBody.Limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "limit must be a positive integer",
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+
+	resp, err := previewExtract(c.UserContext(), cfg, reqBody, crawler.Map)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(JobPreviewResponse{
+			Success: false,
+			Code:    "PREVIEW_DISCOVERY_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	return c.JSON(resp)
+}
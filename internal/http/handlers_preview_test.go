@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"raito/internal/config"
+	"raito/internal/crawler"
+)
+
+func TestPreviewCrawl_EstimatesFromDiscovery(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Crawler.MaxPagesDefault = 100
+	cfg.Worker.MaxConcurrentURLsPerJob = 2
+
+	var mapped []crawler.MapOptions
+	mapLinks := func(_ context.Context, opts crawler.MapOptions) (*crawler.MapResult, error) {
+		mapped = append(mapped, opts)
+		res := &crawler.MapResult{Links: []crawler.Link{{URL: opts.URL}}}
+		for i := 0; i < 6; i++ {
+			res.Links = append(res.Links, crawler.Link{URL: fmt.Sprintf("%spage-%d", opts.URL, i)})
+		}
+		return res, nil
+	}
+
+	limit := 4
+	req := CrawlRequest{
+		URL:     "https://example.com/",
+		Limit:   &limit,
+		Formats: []any{"markdown", "summary", map[string]any{"type": "json", "prompt": "p"}},
+	}
+	resp, err := previewCrawl(context.Background(), cfg, req, mapLinks)
+	if err != nil {
+		t.Fatalf("previewCrawl: %v", err)
+	}
+
+	if len(mapped) != 1 || mapped[0].URL != "https://example.com/" || mapped[0].Limit != limit*crawlCandidateFactor {
+		t.Fatalf("unexpected map calls: %+v", mapped)
+	}
+	// The seed plus the limit; the seed is not counted as discovered.
+	if resp.DiscoveredURLs != 6 || resp.EstimatedPages != 5 || resp.Limit != 4 {
+		t.Fatalf("unexpected pages: %+v", resp)
+	}
+	if len(resp.LLMFormats) != 2 || resp.EstimatedLLMCalls != 10 {
+		t.Fatalf("unexpected llm estimate: %+v", resp)
+	}
+	if resp.EstimatedCredits != 5+10*previewCreditsPerLLMCall {
+		t.Fatalf("credits = %d", resp.EstimatedCredits)
+	}
+	// Three rounds of two pages, each with two LLM calls.
+	want := int(3 * (previewHTTPPageSeconds + 2*previewLLMCallSeconds))
+	if resp.EstimatedRuntimeSeconds < want || resp.EstimatedRuntimeSeconds > want+1 {
+		t.Fatalf("runtime = %d, want about %d", resp.EstimatedRuntimeSeconds, want)
+	}
+	if len(resp.SampleURLs) != 7 || resp.SampleURLs[0] != "https://example.com/" {
+		t.Fatalf("unexpected sample urls: %v", resp.SampleURLs)
+	}
+}
+
+func TestPreviewExtract_MergeModeUsesOneLLMCall(t *testing.T) {
+	cfg := &config.Config{}
+	mapLinks := func(_ context.Context, opts crawler.MapOptions) (*crawler.MapResult, error) {
+		return &crawler.MapResult{Links: []crawler.Link{
+			{URL: "https://example.com/blog/a"},
+			{URL: "https://example.com/blog/b"},
+			{URL: "https://example.com/about"},
+		}}, nil
+	}
+
+	req := ExtractRequest{URLs: []string{"https://example.com/blog/*", "https://other.com/"}}
+	resp, err := previewExtract(context.Background(), cfg, req, mapLinks)
+	if err != nil {
+		t.Fatalf("previewExtract: %v", err)
+	}
+	if resp.EstimatedPages != 3 || resp.EstimatedLLMCalls != 3 || resp.Limit != defaultExtractWildcardLimit {
+		t.Fatalf("unexpected per-url preview: %+v", resp)
+	}
+
+	req.Mode = extractModeMerge
+	resp, err = previewExtract(context.Background(), cfg, req, mapLinks)
+	if err != nil {
+		t.Fatalf("previewExtract: %v", err)
+	}
+	if resp.EstimatedPages != 3 || resp.EstimatedLLMCalls != 1 || resp.EstimatedCredits != 3+previewCreditsPerLLMCall {
+		t.Fatalf("unexpected merge preview: %+v", resp)
+	}
+}
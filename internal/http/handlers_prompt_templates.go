@@ -0,0 +1,424 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/llm"
+	"raito/internal/metrics"
+	"raito/internal/scraper"
+	"raito/internal/store"
+)
+
+type PromptTemplateItem struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Version     int    `json:"version"`
+	Prompt      string `json:"prompt"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type PromptTemplatesResponse struct {
+	Success   bool                 `json:"success"`
+	Code      string               `json:"code,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Templates []PromptTemplateItem `json:"templates,omitempty"`
+}
+
+type PromptTemplateRequest struct {
+	Kind        string `json:"kind"`
+	Prompt      string `json:"prompt"`
+	Description string `json:"description,omitempty"`
+}
+
+type PromptTemplateResponse struct {
+	Success  bool                `json:"success"`
+	Code     string              `json:"code,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Template *PromptTemplateItem `json:"template,omitempty"`
+}
+
+// PromptTemplateTestRequest runs a template against markdown, or against
+// the markdown of url when markdown is empty.
+type PromptTemplateTestRequest struct {
+	Version  int            `json:"version,omitempty"`
+	URL      string         `json:"url,omitempty"`
+	Markdown string         `json:"markdown,omitempty"`
+	Schema   map[string]any `json:"schema,omitempty"`
+}
+
+type PromptTemplateTestResponse struct {
+	Success  bool                `json:"success"`
+	Code     string              `json:"code,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Template *PromptTemplateItem `json:"template,omitempty"`
+	Output   map[string]any      `json:"output,omitempty"`
+	Usage    *llm.Usage          `json:"usage,omitempty"`
+}
+
+func promptTemplateItem(t db.PromptTemplate) PromptTemplateItem {
+	return PromptTemplateItem{
+		Name:        t.Name,
+		Kind:        t.Kind,
+		Version:     int(t.Version),
+		Prompt:      t.Prompt,
+		Description: t.Description,
+		CreatedAt:   t.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// tenantListPromptTemplatesHandler lists the latest version of each of a
+// tenant's prompt templates.
+func tenantListPromptTemplatesHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, false)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	rows, err := db.New(st.DB).ListLatestPromptTemplates(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(PromptTemplatesResponse{
+			Success: false,
+			Code:    "PROMPT_TEMPLATE_LIST_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	items := make([]PromptTemplateItem, 0, len(rows))
+	for _, t := range rows {
+		items = append(items, promptTemplateItem(t))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(PromptTemplatesResponse{
+		Success:   true,
+		Templates: items,
+	})
+}
+
+// tenantGetPromptTemplateVersionsHandler lists every version of one
+// template, newest first.
+func tenantGetPromptTemplateVersionsHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, false)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	rows, err := db.New(st.DB).ListPromptTemplateVersions(c.Context(), db.ListPromptTemplateVersionsParams{
+		TenantID: tenantID,
+		Name:     c.Params("name"),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(PromptTemplatesResponse{
+			Success: false,
+			Code:    "PROMPT_TEMPLATE_LIST_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(PromptTemplatesResponse{
+			Success: false,
+			Code:    "PROMPT_TEMPLATE_NOT_FOUND",
+			Error:   "prompt template not found",
+		})
+	}
+
+	items := make([]PromptTemplateItem, 0, len(rows))
+	for _, t := range rows {
+		items = append(items, promptTemplateItem(t))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(PromptTemplatesResponse{
+		Success:   true,
+		Templates: items,
+	})
+}
+
+// tenantPutPromptTemplateHandler saves a new version of a prompt template.
+// A template keeps the kind it was created with.
+func tenantPutPromptTemplateHandler(c *fiber.Ctx) error {
+	p, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	name := c.Params("name")
+	if !secretNamePattern.MatchString(name) {
+		return c.Status(fiber.StatusBadRequest).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "template names must be 1-64 characters of letters, digits, '.', '_' or '-'",
+		})
+	}
+
+	var req PromptTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	req.Kind = strings.ToLower(strings.TrimSpace(req.Kind))
+	if !promptTemplateKinds[req.Kind] {
+		return c.Status(fiber.StatusBadRequest).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "kind must be one of summary, branding, json, extract",
+		})
+	}
+	if strings.TrimSpace(req.Prompt) == "" || len(req.Prompt) > maxPromptTemplateLength {
+		return c.Status(fiber.StatusBadRequest).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "prompt is required and must be at most 32 KiB",
+		})
+	}
+
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+
+	existing, err := lookupPromptTemplate(c.Context(), q, tenantID, name, 0)
+	switch {
+	case err == nil && existing.Kind != req.Kind:
+		return c.Status(fiber.StatusConflict).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "CONFLICT",
+			Error:   "template " + name + " is a " + existing.Kind + " template; delete it to change its kind",
+		})
+	case err != nil && !errors.Is(err, errPromptTemplateNotFound):
+		return c.Status(fiber.StatusInternalServerError).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "PROMPT_TEMPLATE_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	var createdBy uuid.NullUUID
+	if p.UserID != nil {
+		createdBy = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+
+	row, err := q.InsertPromptTemplateVersion(c.Context(), db.InsertPromptTemplateVersionParams{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		Name:            name,
+		Kind:            req.Kind,
+		Prompt:          req.Prompt,
+		Description:     req.Description,
+		CreatedByUserID: createdBy,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "PROMPT_TEMPLATE_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.prompt_template.save", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "prompt_template",
+		ResourceID:   name,
+		Metadata: map[string]any{
+			"kind":    row.Kind,
+			"version": row.Version,
+		},
+	})
+
+	item := promptTemplateItem(row)
+	return c.Status(fiber.StatusOK).JSON(PromptTemplateResponse{
+		Success:  true,
+		Template: &item,
+	})
+}
+
+// tenantDeletePromptTemplateHandler removes a template and all of its
+// versions. Queued jobs keep the prompt they were submitted with.
+func tenantDeletePromptTemplateHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	name := c.Params("name")
+	st := c.Locals("store").(*store.Store)
+	n, err := db.New(st.DB).DeletePromptTemplate(c.Context(), db.DeletePromptTemplateParams{
+		TenantID: tenantID,
+		Name:     name,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "PROMPT_TEMPLATE_DELETE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if n == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(PromptTemplateResponse{
+			Success: false,
+			Code:    "PROMPT_TEMPLATE_NOT_FOUND",
+			Error:   "prompt template not found",
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.prompt_template.delete", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "prompt_template",
+		ResourceID:   name,
+	})
+
+	return c.Status(fiber.StatusOK).JSON(PromptTemplateResponse{Success: true})
+}
+
+// promptTemplateFields returns the LLM fields requested for a template of
+// the given kind, matching what the scrape and extract workers ask for.
+func promptTemplateFields(kind string, schema map[string]any) []llm.FieldSpec {
+	switch kind {
+	case promptKindSummary:
+		return []llm.FieldSpec{{
+			Name:        "summary",
+			Description: "Short natural-language summary of the page content.",
+			Type:        "string",
+		}}
+	case promptKindBranding:
+		return []llm.FieldSpec{{
+			Name:        "branding",
+			Description: "Brand identity and design system information (colors, typography, logo, components, personality, etc.) extracted from the page.",
+			Type:        "object",
+		}}
+	default:
+		desc := "Arbitrary JSON object extracted from the page content."
+		if len(schema) > 0 {
+			if schemaBytes, err := json.Marshal(schema); err == nil {
+				desc = desc + " Schema: " + string(schemaBytes)
+			}
+		}
+		return []llm.FieldSpec{{
+			Name:        "json",
+			Description: desc,
+			Type:        "object",
+		}}
+	}
+}
+
+// tenantTestPromptTemplateHandler runs a template once against sample
+// content and returns the LLM output without creating a job. The call is
+// charged to the tenant's LLM budget.
+func tenantTestPromptTemplateHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	var req PromptTemplateTestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(PromptTemplateTestResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if req.Markdown == "" && req.URL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(PromptTemplateTestResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "markdown or url is required",
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	tpl, err := lookupPromptTemplate(c.Context(), db.New(st.DB), tenantID, c.Params("name"), req.Version)
+	if err != nil {
+		status, code := promptTemplateStatus(err)
+		if errors.Is(err, errPromptTemplateNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(PromptTemplateTestResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+	item := promptTemplateItem(tpl)
+
+	timeout := time.Duration(cfg.Scraper.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	markdown := req.Markdown
+	pageURL := req.URL
+	if markdown == "" {
+		scrapeCtx, cancel := context.WithTimeout(c.Context(), timeout)
+		defer cancel()
+		res, err := scraper.NewHTTPScraper(timeout).Scrape(scrapeCtx, scraper.Request{
+			URL:       req.URL,
+			UserAgent: cfg.RequestUserAgent(),
+			Timeout:   timeout,
+		})
+		if err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(PromptTemplateTestResponse{
+				Success:  false,
+				Code:     "SCRAPE_FAILED",
+				Error:    err.Error(),
+				Template: &item,
+			})
+		}
+		markdown = res.Markdown
+	}
+
+	client, provider, modelName, err := newLLMClient(c.Context(), cfg, st, &tenantID, "", "")
+	if err != nil {
+		status, code := llmClientFailure(err)
+		return c.Status(status).JSON(PromptTemplateTestResponse{
+			Success:  false,
+			Code:     code,
+			Error:    err.Error(),
+			Template: &item,
+		})
+	}
+	c.Locals("llm_provider", string(provider))
+	c.Locals("llm_model", modelName)
+
+	llmCtx, llmCancel := context.WithTimeout(c.Context(), timeout)
+	defer llmCancel()
+	llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
+		URL:      pageURL,
+		Markdown: markdown,
+		Fields:   promptTemplateFields(tpl.Kind, req.Schema),
+		Prompt:   tpl.Prompt,
+		Timeout:  timeout,
+		Strict:   false,
+	})
+	if err != nil {
+		metrics.RecordLLMExtract(string(provider), modelName, false)
+		status, code := llmFailure(err, "PROMPT_TEMPLATE_TEST_FAILED")
+		return c.Status(status).JSON(PromptTemplateTestResponse{
+			Success:  false,
+			Code:     code,
+			Error:    err.Error(),
+			Template: &item,
+		})
+	}
+	metrics.RecordLLMExtract(string(provider), modelName, true)
+
+	return c.Status(fiber.StatusOK).JSON(PromptTemplateTestResponse{
+		Success:  true,
+		Template: &item,
+		Output:   llmRes.Fields,
+		Usage:    &llmRes.Usage,
+	})
+}
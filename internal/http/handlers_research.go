@@ -0,0 +1,511 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/extract"
+	"raito/internal/jobs"
+	"raito/internal/services"
+	"raito/internal/store"
+)
+
+const (
+	// defaultResearchLimit is how many search results a research job
+	// scrapes when the request does not set limit.
+	defaultResearchLimit = 5
+	// maxResearchLimit bounds limit, since every page is sent to the LLM
+	// in a single merged call.
+	maxResearchLimit = 10
+)
+
+// ResearchRequest is the body of POST /v1/research: a question answered
+// by searching the web, scraping the top results, and extracting one
+// schema-shaped answer from them.
+type ResearchRequest struct {
+	Query        string         `json:"query"`
+	Schema       map[string]any `json:"schema,omitempty"`
+	SchemaPreset string         `json:"schemaPreset,omitempty"`
+	// Prompt adds instructions to the extraction; the query is always
+	// included.
+	Prompt       string `json:"prompt,omitempty"`
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	Model        string `json:"model,omitempty"`
+	// Limit is how many of the top search results are scraped.
+	Limit        *int     `json:"limit,omitempty"`
+	Sites        []string `json:"sites,omitempty"`
+	ExcludeSites []string `json:"excludeSites,omitempty"`
+	Country      string   `json:"country,omitempty"`
+	Location     string   `json:"location,omitempty"`
+	TBS          string   `json:"tbs,omitempty"`
+	Visibility   string   `json:"visibility,omitempty"`
+	Pool         string   `json:"pool,omitempty"`
+
+	// ZeroDataRetention (or storeInCache: false) returns results to the
+	// caller without keeping inputs, outputs, or documents once they have
+	// been delivered.
+	ZeroDataRetention *bool `json:"zeroDataRetention,omitempty"`
+	StoreInCache      *bool `json:"storeInCache,omitempty"`
+}
+
+// Research job stages reported by GET /v1/research/:id.
+const (
+	researchStageQueued     = "queued"
+	researchStageSearching  = "searching"
+	researchStageScraping   = "scraping"
+	researchStageExtracting = "extracting"
+	researchStageCompleted  = "completed"
+	researchStageFailed     = "failed"
+)
+
+// ResearchStatusResponse is the body of GET /v1/research/:id.
+type ResearchStatusResponse struct {
+	Success bool   `json:"success"`
+	ID      string `json:"id,omitempty"`
+	Status  string `json:"status,omitempty"`
+	// Stage is queued, searching, scraping, extracting, completed or
+	// failed.
+	Stage    string            `json:"stage,omitempty"`
+	Query    string            `json:"query,omitempty"`
+	Progress *ResearchProgress `json:"progress,omitempty"`
+	// SearchResults are the search hits, in rank order, once the search
+	// has run. Selected marks the pages chosen for extraction.
+	SearchResults []ResearchSearchResult `json:"searchResults,omitempty"`
+	Data          map[string]any         `json:"data,omitempty"`
+	// Sources are the selected pages with their scrape outcome.
+	Sources []ResearchSource `json:"sources,omitempty"`
+	// FieldSources maps each top-level answer field to the pages it was
+	// taken from.
+	FieldSources map[string]any `json:"fieldSources,omitempty"`
+	Summary      map[string]any `json:"summary,omitempty"`
+	Code         string         `json:"code,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// ResearchProgress counts the selected pages scraped so far.
+type ResearchProgress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// ResearchSearchResult is one search hit of a research job.
+type ResearchSearchResult struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Selected    bool   `json:"selected"`
+}
+
+// ResearchSource is a page a research answer was extracted from.
+type ResearchSource struct {
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// researchLimit returns how many search results a research job scrapes.
+func researchLimit(req ResearchRequest) int {
+	if req.Limit != nil && *req.Limit > 0 {
+		return min(*req.Limit, maxResearchLimit)
+	}
+	return defaultResearchLimit
+}
+
+// selectResearchResults marks the first limit distinct http(s) results as
+// selected and returns all results plus the selected URLs.
+func selectResearchResults(web []services.SearchWebResult, limit int) ([]ResearchSearchResult, []string) {
+	out := make([]ResearchSearchResult, 0, len(web))
+	var selected []string
+	seen := map[string]bool{}
+	for _, r := range web {
+		res := ResearchSearchResult{URL: r.URL, Title: r.Title, Description: r.Description}
+		u, err := url.Parse(r.URL)
+		valid := err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+		if valid && !seen[r.URL] && len(selected) < limit {
+			res.Selected = true
+			selected = append(selected, r.URL)
+		}
+		seen[r.URL] = true
+		out = append(out, res)
+	}
+	return out, selected
+}
+
+// researchExtractRequest builds the merged extract a research job runs
+// over its selected pages.
+func researchExtractRequest(req ResearchRequest, urls []string) ExtractRequest {
+	prompt := "Answer this question using the pages: " + req.Query
+	if req.Prompt != "" {
+		prompt += "\n\n" + req.Prompt
+	}
+	ignoreInvalid, showSources := true, true
+	return ExtractRequest{
+		URLs:              urls,
+		Schema:            req.Schema,
+		Prompt:            prompt,
+		SystemPrompt:      req.SystemPrompt,
+		Provider:          req.Provider,
+		Model:             req.Model,
+		Mode:              extractModeMerge,
+		IgnoreInvalidURLs: &ignoreInvalid,
+		ShowSources:       &showSources,
+		ZeroDataRetention: req.ZeroDataRetention,
+		StoreInCache:      req.StoreInCache,
+	}
+}
+
+// runResearchJob searches for the query, records the results it selected
+// as a search_finished event, then scrapes them and extracts one merged,
+// source-attributed answer as a merge-mode extract does.
+func runResearchJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, req ResearchRequest) {
+	if !cfg.Search.Enabled {
+		msg := "SEARCH_DISABLED: search is disabled in server configuration"
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	limit := researchLimit(req)
+	res, err := services.NewSearchService(cfg).Search(ctx, &services.SearchRequest{
+		Query:             req.Query,
+		Limit:             limit,
+		Country:           req.Country,
+		Location:          req.Location,
+		TBS:               req.TBS,
+		IgnoreInvalidURLs: true,
+		Sites:             req.Sites,
+		ExcludeSites:      req.ExcludeSites,
+	})
+	if err != nil {
+		msg := "SEARCH_FAILED: " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	results, urls := selectResearchResults(res.Web, limit)
+	_ = st.AddJobEvent(ctx, jobID, store.JobEventSearchFinished,
+		fmt.Sprintf("found %d results, selected %d", len(results), len(urls)),
+		map[string]any{"results": results})
+	if len(urls) == 0 {
+		msg := "RESEARCH_NO_RESULTS: search returned no pages for the query"
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	runExtractJob(ctx, cfg, st, jobID, researchExtractRequest(req, urls))
+}
+
+// researchHandler implements POST /v1/research, which enqueues a research
+// job.
+func researchHandler(c *fiber.Ctx) error {
+	var reqBody ResearchRequest
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	reqBody.Query = strings.TrimSpace(reqBody.Query)
+	if reqBody.Query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Missing required field 'query'",
+		})
+	}
+
+	if reqBody.Limit != nil && (*reqBody.Limit <= 0 || *reqBody.Limit > maxResearchLimit) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   fmt.Sprintf("limit must be between 1 and %d", maxResearchLimit),
+		})
+	}
+
+	if reqBody.SchemaPreset != "" {
+		if len(reqBody.Schema) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "'schema' and 'schemaPreset' cannot both be set",
+			})
+		}
+		schema, ok := extract.Preset(reqBody.SchemaPreset)
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "INVALID_SCHEMA_PRESET",
+				Error:   fmt.Sprintf("Unknown schemaPreset %q (available: %s)", reqBody.SchemaPreset, strings.Join(extract.PresetNames(), ", ")),
+			})
+		}
+		reqBody.Schema = schema
+	}
+	if len(reqBody.Schema) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Missing required field 'schema' or 'schemaPreset'",
+		})
+	}
+	if code, msg := validateExtractSchema(reqBody.Schema); code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
+
+	if _, _, _, err := searchFilters(SearchRequest{Sites: reqBody.Sites, ExcludeSites: reqBody.ExcludeSites}); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobVisibility(reqBody.Visibility); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobPool(c, reqBody.Pool); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	if !cfg.Search.Enabled {
+		return c.Status(http.StatusServiceUnavailable).JSON(ErrorResponse{
+			Success: false,
+			Code:    "SEARCH_DISABLED",
+			Error:   "search is disabled in server configuration",
+		})
+	}
+
+	var tenantID, apiKeyID, userID *uuid.UUID
+	if p, ok := c.Locals("principal").(Principal); ok {
+		tenantID, apiKeyID, userID = p.TenantID, p.APIKeyID, p.UserID
+	}
+
+	// Research ends in an LLM call, so it is rejected up front once the
+	// tenant's monthly LLM budget is used up, or when the model is outside
+	// the tenant's allowlist.
+	if tenantID != nil {
+		if err := checkLLMBudget(c.Context(), db.New(st.DB), *tenantID); errors.Is(err, errLLMBudgetExceeded) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
+				Success: false,
+				Code:    "LLM_BUDGET_EXCEEDED",
+				Error:   err.Error(),
+			})
+		}
+	}
+	if _, _, err := resolveTenantLLM(c.Context(), cfg, db.New(st.DB), tenantID, reqBody.Provider, reqBody.Model); err != nil {
+		status, code := llmClientFailure(err)
+		return c.Status(status).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
+	id := func() uuid.UUID {
+		if id, err := uuid.NewV7(); err == nil {
+			return id
+		}
+		return uuid.New()
+	}()
+
+	if err := services.NewResearchService(st).Enqueue(c.Context(), &services.ResearchRequest{
+		ID:         id,
+		Body:       reqBody,
+		Query:      reqBody.Query,
+		TenantID:   tenantID,
+		APIKeyID:   apiKeyID,
+		UserID:     userID,
+		Visibility: reqBody.Visibility,
+		Pool: routeJobPool(c, jobs.RouteRequest{
+			TenantID:  tenantID,
+			Requested: reqBody.Pool,
+		}),
+		ZeroRetention: zeroRetentionRequested(reqBody.ZeroDataRetention, reqBody.StoreInCache),
+	}); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "RESEARCH_JOB_CREATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"id":      id.String(),
+		"url":     c.Protocol() + "://" + c.Hostname() + "/v1/research/" + id.String(),
+	})
+}
+
+// researchStatus fills in resp from a research job's search_finished
+// event, heartbeat and output. hb is nil when the job has no heartbeat.
+func researchStatus(resp *ResearchStatusResponse, job db.Job, events []db.JobEvent, hb *db.JobHeartbeat) error {
+	searched := false
+	for _, e := range events {
+		if e.Type != store.JobEventSearchFinished {
+			continue
+		}
+		searched = true
+		var data struct {
+			Results []ResearchSearchResult `json:"results"`
+		}
+		if err := json.Unmarshal(e.Data, &data); err == nil {
+			resp.SearchResults = data.Results
+		}
+	}
+
+	switch jobs.PublicStatus(job.Status) {
+	case string(jobs.StatusPending):
+		resp.Stage = researchStageQueued
+	case string(jobs.StatusRunning):
+		resp.Stage = researchStageSearching
+		if searched {
+			resp.Stage = researchStageScraping
+			if hb != nil {
+				completed, total, _ := crawlProgress(*hb, time.Now())
+				resp.Progress = &ResearchProgress{Completed: completed, Total: total}
+				if total > 0 && completed >= total {
+					resp.Stage = researchStageExtracting
+				}
+			}
+		}
+	case string(jobs.StatusCompleted):
+		resp.Stage = researchStageCompleted
+	case string(jobs.StatusFailed):
+		resp.Stage = researchStageFailed
+		resp.Code, resp.Error = "RESEARCH_FAILED", "research job failed"
+		if job.Error.Valid {
+			resp.Error = job.Error.String
+			if code, msg, ok := strings.Cut(job.Error.String, ":"); ok && strings.TrimSpace(code) != "" {
+				resp.Code, resp.Error = strings.TrimSpace(code), strings.TrimSpace(msg)
+			}
+		}
+	}
+
+	if job.Status != string(jobs.StatusCompleted) || !job.Output.Valid || len(job.Output.RawMessage) == 0 {
+		return nil
+	}
+	var out struct {
+		Data         map[string]any   `json:"data"`
+		Sources      []ResearchSource `json:"sources"`
+		FieldSources map[string]any   `json:"fieldSources"`
+		Summary      map[string]any   `json:"summary"`
+	}
+	if err := json.Unmarshal(job.Output.RawMessage, &out); err != nil {
+		return err
+	}
+	titles := map[string]string{}
+	for _, r := range resp.SearchResults {
+		titles[r.URL] = r.Title
+	}
+	for i := range out.Sources {
+		out.Sources[i].Title = titles[out.Sources[i].URL]
+	}
+	resp.Data, resp.Sources, resp.FieldSources, resp.Summary = out.Data, out.Sources, out.FieldSources, out.Summary
+	return nil
+}
+
+// researchStatusHandler implements GET /v1/research/:id.
+func researchStatusHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResearchStatusResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid research id",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(ResearchStatusResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "research job not found",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(ResearchStatusResponse{
+			Success: false,
+			Code:    "RESEARCH_JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) {
+		return c.Status(fiber.StatusNotFound).JSON(ResearchStatusResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "research job not found",
+		})
+	}
+	if job.Type != "research" {
+		return c.Status(fiber.StatusNotFound).JSON(ResearchStatusResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "research job not found",
+		})
+	}
+
+	events, err := st.ListJobEvents(c.Context(), job.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(ResearchStatusResponse{
+			Success: false,
+			Code:    "RESEARCH_JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	var hb *db.JobHeartbeat
+	if job.Status == string(jobs.StatusRunning) {
+		if row, err := db.New(st.DB).GetJobHeartbeat(c.Context(), job.ID); err == nil {
+			hb = &row
+		}
+	}
+
+	resp := ResearchStatusResponse{
+		Success: true,
+		ID:      job.ID.String(),
+		Status:  job.Status,
+		Query:   job.Url,
+	}
+	if err := researchStatus(&resp, job, events, hb); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(ResearchStatusResponse{
+			Success: false,
+			Code:    "RESEARCH_RESULT_DECODE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sqlc-dev/pqtype"
+
+	"raito/internal/db"
+	"raito/internal/services"
+	"raito/internal/store"
+)
+
+func TestSelectResearchResults(t *testing.T) {
+	web := []services.SearchWebResult{
+		{URL: "https://a.example/", Title: "A"},
+		{URL: "ftp://files.example/x"},
+		{URL: "https://a.example/"},
+		{URL: "https://b.example/", Title: "B"},
+		{URL: "https://c.example/"},
+	}
+	results, urls := selectResearchResults(web, 2)
+	if len(results) != 5 {
+		t.Fatalf("expected every result to be reported, got %d", len(results))
+	}
+	if len(urls) != 2 || urls[0] != "https://a.example/" || urls[1] != "https://b.example/" {
+		t.Fatalf("unexpected selection: %v", urls)
+	}
+	for i, want := range []bool{true, false, false, true, false} {
+		if results[i].Selected != want {
+			t.Fatalf("result %d selected = %v, want %v", i, results[i].Selected, want)
+		}
+	}
+}
+
+func TestResearchStatus_Stages(t *testing.T) {
+	searchEvent := db.JobEvent{
+		Type: store.JobEventSearchFinished,
+		Data: json.RawMessage(`{"results":[{"url":"https://a.example/","title":"A","selected":true},{"url":"https://b.example/","title":"B","selected":false}]}`),
+	}
+	now := time.Now()
+	hb := &db.JobHeartbeat{StartedAt: now.Add(-10 * time.Second), HeartbeatAt: now, PagesDone: 1, PagesTotal: 1}
+
+	cases := []struct {
+		name   string
+		status string
+		events []db.JobEvent
+		hb     *db.JobHeartbeat
+		want   string
+	}{
+		{"pending", "pending", nil, nil, researchStageQueued},
+		{"searching", "running", nil, nil, researchStageSearching},
+		{"scraping", "running", []db.JobEvent{searchEvent}, &db.JobHeartbeat{StartedAt: now, HeartbeatAt: now, PagesTotal: 1}, researchStageScraping},
+		{"extracting", "running", []db.JobEvent{searchEvent}, hb, researchStageExtracting},
+	}
+	for _, tc := range cases {
+		var resp ResearchStatusResponse
+		if err := researchStatus(&resp, db.Job{Status: tc.status}, tc.events, tc.hb); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if resp.Stage != tc.want {
+			t.Fatalf("%s: stage = %q, want %q", tc.name, resp.Stage, tc.want)
+		}
+	}
+
+	output := `{"mode":"merge","data":{"answer":"42"},"sources":[{"url":"https://a.example/","statusCode":200,"error":""}],"fieldSources":{"answer":["https://a.example/"]},"summary":{"total":1}}`
+	job := db.Job{Status: "completed", Output: pqtype.NullRawMessage{RawMessage: json.RawMessage(output), Valid: true}}
+	var resp ResearchStatusResponse
+	if err := researchStatus(&resp, job, []db.JobEvent{searchEvent}, nil); err != nil {
+		t.Fatalf("completed: %v", err)
+	}
+	if resp.Stage != researchStageCompleted || resp.Data["answer"] != "42" || len(resp.SearchResults) != 2 {
+		t.Fatalf("unexpected completed status: %+v", resp)
+	}
+	if len(resp.Sources) != 1 || resp.Sources[0].Title != "A" || resp.Sources[0].StatusCode != 200 {
+		t.Fatalf("unexpected sources: %+v", resp.Sources)
+	}
+
+	failed := db.Job{Status: "failed", Error: sql.NullString{String: "SEARCH_FAILED: provider down", Valid: true}}
+	resp = ResearchStatusResponse{}
+	if err := researchStatus(&resp, failed, nil, nil); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if resp.Stage != researchStageFailed || resp.Code != "SEARCH_FAILED" || resp.Error != "provider down" {
+		t.Fatalf("unexpected failed status: %+v", resp)
+	}
+}
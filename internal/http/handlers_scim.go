@@ -0,0 +1,792 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// registerSCIMRoutes registers the SCIM 2.0 provisioning API. The group
+// requires a system admin principal.
+func registerSCIMRoutes(group fiber.Router) {
+	group.Get("/ServiceProviderConfig", scimServiceProviderConfigHandler)
+	group.Get("/ResourceTypes", scimResourceTypesHandler)
+	group.Get("/Users", scimListUsersHandler)
+	group.Post("/Users", scimCreateUserHandler)
+	group.Get("/Users/:id", scimGetUserHandler)
+	group.Put("/Users/:id", scimReplaceUserHandler)
+	group.Patch("/Users/:id", scimPatchUserHandler)
+	group.Delete("/Users/:id", scimDeleteUserHandler)
+	group.Get("/Groups", scimListGroupsHandler)
+	group.Post("/Groups", scimCreateGroupHandler)
+	group.Get("/Groups/:id", scimGetGroupHandler)
+	group.Put("/Groups/:id", scimReplaceGroupHandler)
+	group.Patch("/Groups/:id", scimPatchGroupHandler)
+	group.Delete("/Groups/:id", scimDeleteGroupHandler)
+}
+
+// scimEnabledMiddleware hides the SCIM API unless auth.scim.enabled is set.
+func scimEnabledMiddleware(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*config.Config)
+	if !cfg.Auth.SCIM.Enabled {
+		return scimError(c, fiber.StatusNotFound, "", "SCIM provisioning is not enabled")
+	}
+	return c.Next()
+}
+
+func scimJSON(c *fiber.Ctx, status int, v any) error {
+	return c.Status(status).JSON(v, scimContentType)
+}
+
+func scimError(c *fiber.Ctx, status int, scimType, detail string) error {
+	return scimJSON(c, status, scimErrorResponse{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// scimBody decodes a request body; IdPs send application/scim+json, which
+// BodyParser does not accept.
+func scimBody(c *fiber.Ctx, v any) error {
+	if err := json.Unmarshal(c.Body(), v); err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidSyntax", "malformed JSON: "+err.Error())
+	}
+	return nil
+}
+
+// scimPage returns the 1-based start index and page size of a list request.
+func scimPage(c *fiber.Ctx) (int, int) {
+	start := c.QueryInt("startIndex", 1)
+	if start < 1 {
+		start = 1
+	}
+	count := c.QueryInt("count", scimDefaultCount)
+	if count < 0 {
+		count = 0
+	}
+	return start, min(count, scimMaxCount)
+}
+
+func scimTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func scimLocation(c *fiber.Ctx, resource string, id uuid.UUID) string {
+	return c.BaseURL() + "/scim/v2/" + resource + "/" + id.String()
+}
+
+func scimServiceProviderConfigHandler(c *fiber.Ctx) error {
+	return scimJSON(c, fiber.StatusOK, fiber.Map{
+		"schemas":               []string{scimSPConfigSchema},
+		"patch":                 fiber.Map{"supported": true},
+		"bulk":                  fiber.Map{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":                fiber.Map{"supported": true, "maxResults": scimMaxCount},
+		"changePassword":        fiber.Map{"supported": false},
+		"sort":                  fiber.Map{"supported": false},
+		"etag":                  fiber.Map{"supported": false},
+		"authenticationSchemes": []fiber.Map{{"type": "oauthbearertoken", "name": "Bearer token", "description": "A Raito system admin API key"}},
+		"meta":                  scimMeta{ResourceType: "ServiceProviderConfig"},
+	})
+}
+
+func scimResourceTypesHandler(c *fiber.Ctx) error {
+	types := []any{
+		fiber.Map{"schemas": []string{scimResourceTypeURN}, "id": "User", "name": "User", "endpoint": "/Users", "schema": scimUserSchema},
+		fiber.Map{"schemas": []string{scimResourceTypeURN}, "id": "Group", "name": "Group", "endpoint": "/Groups", "schema": scimGroupSchema},
+	}
+	return scimJSON(c, fiber.StatusOK, scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: int64(len(types)),
+		StartIndex:   1,
+		ItemsPerPage: len(types),
+		Resources:    types,
+	})
+}
+
+// scimUserResource renders a stored user with its SCIM external ID and
+// groups.
+func scimUserResource(ctx context.Context, c *fiber.Ctx, q *db.Queries, u db.User) (scimUser, error) {
+	var externalID sql.NullString
+	if su, err := q.GetSCIMUser(ctx, u.ID); err == nil {
+		externalID = su.ExternalID
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return scimUser{}, err
+	}
+	groups, err := q.ListSCIMGroupsForUser(ctx, u.ID)
+	if err != nil {
+		return scimUser{}, err
+	}
+
+	res := scimUser{
+		Schemas:     []string{scimUserSchema},
+		ID:          u.ID.String(),
+		ExternalID:  externalID.String,
+		UserName:    u.Email,
+		DisplayName: u.Name.String,
+		Emails:      []scimEmail{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      !u.IsDisabled,
+		Meta: scimMeta{
+			ResourceType: "User",
+			Created:      scimTime(u.CreatedAt),
+			LastModified: scimTime(u.UpdatedAt),
+			Location:     scimLocation(c, "Users", u.ID),
+		},
+	}
+	if u.Name.Valid && u.Name.String != "" {
+		res.Name = &scimName{Formatted: u.Name.String}
+	}
+	for _, g := range groups {
+		res.Groups = append(res.Groups, scimRef{Value: g.ID.String(), Display: g.DisplayName})
+	}
+	return res, nil
+}
+
+func scimGroupResource(ctx context.Context, c *fiber.Ctx, q *db.Queries, g db.ScimGroup) (scimGroup, error) {
+	members, err := q.ListSCIMGroupMembers(ctx, g.ID)
+	if err != nil {
+		return scimGroup{}, err
+	}
+	res := scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          g.ID.String(),
+		ExternalID:  g.ExternalID.String,
+		DisplayName: g.DisplayName,
+		Members:     make([]scimRef, 0, len(members)),
+		Meta: scimMeta{
+			ResourceType: "Group",
+			Created:      scimTime(g.CreatedAt),
+			LastModified: scimTime(g.UpdatedAt),
+			Location:     scimLocation(c, "Groups", g.ID),
+		},
+	}
+	for _, m := range members {
+		res.Members = append(res.Members, scimRef{Value: m.UserID.String(), Display: m.Email})
+	}
+	return res, nil
+}
+
+// scimLoadUser resolves the user named by the :id parameter. When there
+// is none, it writes the error response and returns ok=false.
+func scimLoadUser(c *fiber.Ctx, q *db.Queries) (db.User, bool, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return db.User{}, false, scimError(c, fiber.StatusNotFound, "", "user not found")
+	}
+	u, err := q.GetUserByID(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.User{}, false, scimError(c, fiber.StatusNotFound, "", "user not found")
+	}
+	if err != nil {
+		return db.User{}, false, scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return u, true, nil
+}
+
+func scimLoadGroup(c *fiber.Ctx, q *db.Queries) (db.ScimGroup, bool, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return db.ScimGroup{}, false, scimError(c, fiber.StatusNotFound, "", "group not found")
+	}
+	g, err := q.GetSCIMGroup(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.ScimGroup{}, false, scimError(c, fiber.StatusNotFound, "", "group not found")
+	}
+	if err != nil {
+		return db.ScimGroup{}, false, scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return g, true, nil
+}
+
+func scimListUsersHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	start, count := scimPage(c)
+
+	var users []db.User
+	var total int64
+	if filter := c.Query("filter"); filter != "" {
+		attr, value, err := parseSCIMFilter(filter)
+		if err != nil || attr != "username" {
+			return scimError(c, fiber.StatusBadRequest, "invalidFilter", "only 'userName eq \"value\"' filters are supported")
+		}
+		u, err := q.GetUserByEmail(c.Context(), strings.ToLower(strings.TrimSpace(value)))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+		if err == nil {
+			total = 1
+			if start == 1 && count > 0 {
+				users = []db.User{u}
+			}
+		}
+	} else {
+		var err error
+		if total, err = q.AdminCountUsers(c.Context(), ""); err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+		users, err = q.AdminListUsers(c.Context(), db.AdminListUsersParams{Column1: "", Limit: int32(count), Offset: int32(start - 1)})
+		if err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+	}
+
+	resources := make([]any, 0, len(users))
+	for _, u := range users {
+		res, err := scimUserResource(c.Context(), c, q, u)
+		if err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+		resources = append(resources, res)
+	}
+	return scimJSON(c, fiber.StatusOK, scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func scimGetUserHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	u, ok, err := scimLoadUser(c, q)
+	if !ok {
+		return err
+	}
+	res, err := scimUserResource(c.Context(), c, q, u)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return scimJSON(c, fiber.StatusOK, res)
+}
+
+// scimCreateUserHandler provisions a user with a personal tenant. The
+// user signs in with OIDC.
+func scimCreateUserHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	var req scimUserRequest
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+	attrs := req.attrs()
+	email := attrs.email()
+	if !strings.Contains(email, "@") {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", "userName must be an email address")
+	}
+
+	tx, err := st.DB.BeginTx(c.Context(), nil)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	q := db.New(tx)
+
+	if _, err := q.GetUserByEmail(c.Context(), email); err == nil {
+		return scimError(c, fiber.StatusConflict, "uniqueness", "a user with this userName already exists")
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+
+	var name sql.NullString
+	if n := attrs.name(); n != "" {
+		name = sql.NullString{String: n, Valid: true}
+	}
+	user, err := q.CreateUser(c.Context(), db.CreateUserParams{
+		ID:           uuid.New(),
+		Email:        email,
+		Name:         name,
+		AuthProvider: scimProvider,
+	})
+	if err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", err.Error())
+	}
+	if !attrs.Active {
+		user, err = q.SCIMUpdateUser(c.Context(), db.SCIMUpdateUserParams{
+			ID:         user.ID,
+			Email:      user.Email,
+			Name:       user.Name,
+			IsDisabled: true,
+			DisabledAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+	}
+	if err := q.UpsertSCIMUser(c.Context(), db.UpsertSCIMUserParams{UserID: user.ID, ExternalID: scimNullString(attrs.ExternalID)}); err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	tenantID, err := createPersonalTenant(c.Context(), q, user)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	if err := tx.Commit(); err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+
+	recordAuditEvent(c, st, "scim.user.create", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "user",
+		ResourceID:   user.ID.String(),
+		Metadata:     map[string]any{"email": user.Email, "active": !user.IsDisabled},
+	})
+
+	res, err := scimUserResource(c.Context(), c, db.New(st.DB), user)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return scimJSON(c, fiber.StatusCreated, res)
+}
+
+func scimReplaceUserHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	u, ok, err := scimLoadUser(c, q)
+	if !ok {
+		return err
+	}
+	var req scimUserRequest
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+	return scimSaveUser(c, st, u, req.attrs())
+}
+
+func scimPatchUserHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	u, ok, err := scimLoadUser(c, q)
+	if !ok {
+		return err
+	}
+	var req scimPatchRequest
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+
+	var externalID sql.NullString
+	if su, err := q.GetSCIMUser(c.Context(), u.ID); err == nil {
+		externalID = su.ExternalID
+	}
+	attrs := scimUserAttrsOf(u, externalID)
+	if err := applyUserPatch(&attrs, req.Operations); err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", err.Error())
+	}
+	return scimSaveUser(c, st, u, attrs)
+}
+
+// scimSaveUser stores the attributes of a PUT or PATCH. Deactivating a
+// user revokes their sessions.
+func scimSaveUser(c *fiber.Ctx, st *store.Store, u db.User, attrs scimUserAttrs) error {
+	q := db.New(st.DB)
+
+	email := attrs.email()
+	if !strings.Contains(email, "@") {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", "userName must be an email address")
+	}
+	if email != u.Email {
+		if _, err := q.GetUserByEmail(c.Context(), email); err == nil {
+			return scimError(c, fiber.StatusConflict, "uniqueness", "a user with this userName already exists")
+		}
+	}
+
+	disabledAt := u.DisabledAt
+	if attrs.Active {
+		disabledAt = sql.NullTime{}
+	} else if !u.IsDisabled {
+		disabledAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+	var name sql.NullString
+	if n := attrs.name(); n != "" {
+		name = sql.NullString{String: n, Valid: true}
+	}
+	updated, err := q.SCIMUpdateUser(c.Context(), db.SCIMUpdateUserParams{
+		ID:         u.ID,
+		Email:      email,
+		Name:       name,
+		IsDisabled: !attrs.Active,
+		DisabledAt: disabledAt,
+	})
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	if err := q.UpsertSCIMUser(c.Context(), db.UpsertSCIMUserParams{UserID: u.ID, ExternalID: scimNullString(attrs.ExternalID)}); err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	if updated.IsDisabled && !u.IsDisabled {
+		_, _ = q.RevokeSessionsForUser(c.Context(), u.ID)
+	}
+
+	recordAuditEvent(c, st, "scim.user.update", auditEventOptions{
+		ResourceType: "user",
+		ResourceID:   updated.ID.String(),
+		Metadata:     map[string]any{"email": updated.Email, "active": !updated.IsDisabled},
+	})
+
+	res, err := scimUserResource(c.Context(), c, q, updated)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return scimJSON(c, fiber.StatusOK, res)
+}
+
+// scimDeleteUserHandler deprovisions a user. Users own jobs and audit
+// history, so they are disabled rather than deleted: their sessions are
+// revoked and they leave every SCIM group and SCIM-managed tenant.
+func scimDeleteUserHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	cfg := c.Locals("config").(*config.Config)
+	q := db.New(st.DB)
+	u, ok, err := scimLoadUser(c, q)
+	if !ok {
+		return err
+	}
+
+	disabledAt := u.DisabledAt
+	if !u.IsDisabled {
+		disabledAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+	if _, err := q.SCIMUpdateUser(c.Context(), db.SCIMUpdateUserParams{
+		ID:         u.ID,
+		Email:      u.Email,
+		Name:       u.Name,
+		IsDisabled: true,
+		DisabledAt: disabledAt,
+	}); err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	_, _ = q.RevokeSessionsForUser(c.Context(), u.ID)
+	if err := q.DeleteSCIMGroupMembershipsForUser(c.Context(), u.ID); err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	if err := syncSCIMTenants(c.Context(), q, cfg.Auth.SCIM.GroupMappings, u.ID); err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+
+	recordAuditEvent(c, st, "scim.user.delete", auditEventOptions{
+		ResourceType: "user",
+		ResourceID:   u.ID.String(),
+		Metadata:     map[string]any{"email": u.Email},
+	})
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func scimListGroupsHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	start, count := scimPage(c)
+
+	var groups []db.ScimGroup
+	var total int64
+	if filter := c.Query("filter"); filter != "" {
+		attr, value, err := parseSCIMFilter(filter)
+		if err != nil || attr != "displayname" {
+			return scimError(c, fiber.StatusBadRequest, "invalidFilter", "only 'displayName eq \"value\"' filters are supported")
+		}
+		g, err := q.GetSCIMGroupByDisplayName(c.Context(), value)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+		if err == nil {
+			total = 1
+			if start == 1 && count > 0 {
+				groups = []db.ScimGroup{g}
+			}
+		}
+	} else {
+		var err error
+		if total, err = q.CountSCIMGroups(c.Context()); err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+		groups, err = q.ListSCIMGroups(c.Context(), db.ListSCIMGroupsParams{Limit: int32(count), Offset: int32(start - 1)})
+		if err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+	}
+
+	// Members are left out of lists, as IdPs read them per group.
+	resources := make([]any, 0, len(groups))
+	for _, g := range groups {
+		resources = append(resources, scimGroup{
+			Schemas:     []string{scimGroupSchema},
+			ID:          g.ID.String(),
+			ExternalID:  g.ExternalID.String,
+			DisplayName: g.DisplayName,
+			Members:     []scimRef{},
+			Meta: scimMeta{
+				ResourceType: "Group",
+				Created:      scimTime(g.CreatedAt),
+				LastModified: scimTime(g.UpdatedAt),
+				Location:     scimLocation(c, "Groups", g.ID),
+			},
+		})
+	}
+	return scimJSON(c, fiber.StatusOK, scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func scimGetGroupHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	g, ok, err := scimLoadGroup(c, q)
+	if !ok {
+		return err
+	}
+	res, err := scimGroupResource(c.Context(), c, q, g)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return scimJSON(c, fiber.StatusOK, res)
+}
+
+type scimGroupRequest struct {
+	DisplayName string          `json:"displayName"`
+	ExternalID  string          `json:"externalId"`
+	Members     json.RawMessage `json:"members"`
+}
+
+// state converts a POST or PUT body.
+func (r scimGroupRequest) state() (scimGroupState, error) {
+	g := scimGroupState{
+		DisplayName: r.DisplayName,
+		ExternalID:  r.ExternalID,
+		Members:     make(map[uuid.UUID]bool),
+	}
+	if len(r.Members) > 0 && string(r.Members) != "null" {
+		ids, err := scimMemberIDs(r.Members)
+		if err != nil {
+			return g, err
+		}
+		for _, id := range ids {
+			g.Members[id] = true
+		}
+	}
+	return g, nil
+}
+
+func scimCreateGroupHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+
+	var req scimGroupRequest
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+	state, err := req.state()
+	if err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", err.Error())
+	}
+	if strings.TrimSpace(state.DisplayName) == "" {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	if _, err := q.GetSCIMGroupByDisplayName(c.Context(), state.DisplayName); err == nil {
+		return scimError(c, fiber.StatusConflict, "uniqueness", "a group with this displayName already exists")
+	}
+
+	g, err := q.CreateSCIMGroup(c.Context(), db.CreateSCIMGroupParams{
+		ID:          uuid.New(),
+		DisplayName: state.DisplayName,
+		ExternalID:  scimNullString(state.ExternalID),
+	})
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return scimSaveGroup(c, st, g, nil, state, fiber.StatusCreated, "scim.group.create")
+}
+
+func scimReplaceGroupHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	g, ok, err := scimLoadGroup(c, q)
+	if !ok {
+		return err
+	}
+	var req scimGroupRequest
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+	state, err := req.state()
+	if err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", err.Error())
+	}
+	current, err := scimGroupMemberIDs(c.Context(), q, g.ID)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return scimSaveGroup(c, st, g, current, state, fiber.StatusOK, "scim.group.update")
+}
+
+func scimPatchGroupHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+	g, ok, err := scimLoadGroup(c, q)
+	if !ok {
+		return err
+	}
+	var req scimPatchRequest
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+	current, err := scimGroupMemberIDs(c.Context(), q, g.ID)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+
+	state := scimGroupState{
+		DisplayName: g.DisplayName,
+		ExternalID:  g.ExternalID.String,
+		Members:     make(map[uuid.UUID]bool, len(current)),
+	}
+	for id := range current {
+		state.Members[id] = true
+	}
+	if err := applyGroupPatch(&state, req.Operations); err != nil {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", err.Error())
+	}
+	return scimSaveGroup(c, st, g, current, state, fiber.StatusOK, "scim.group.update")
+}
+
+func scimGroupMemberIDs(ctx context.Context, q *db.Queries, groupID uuid.UUID) (map[uuid.UUID]bool, error) {
+	members, err := q.ListSCIMGroupMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		ids[m.UserID] = true
+	}
+	return ids, nil
+}
+
+// scimSaveGroup stores a group's new name and members, then syncs the
+// tenant memberships of every user whose groups may have changed: all
+// old and new members when the name changed, otherwise those added or
+// removed.
+func scimSaveGroup(c *fiber.Ctx, st *store.Store, g db.ScimGroup, current map[uuid.UUID]bool, state scimGroupState, status int, action string) error {
+	cfg := c.Locals("config").(*config.Config)
+	q := db.New(st.DB)
+
+	if strings.TrimSpace(state.DisplayName) == "" {
+		return scimError(c, fiber.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	for id := range state.Members {
+		if current[id] {
+			continue
+		}
+		if _, err := q.GetUserByID(c.Context(), id); err != nil {
+			return scimError(c, fiber.StatusBadRequest, "invalidValue", fmt.Sprintf("member %s is not a known user", id))
+		}
+	}
+
+	renamed := state.DisplayName != g.DisplayName
+	if renamed || state.ExternalID != g.ExternalID.String {
+		if renamed {
+			if other, err := q.GetSCIMGroupByDisplayName(c.Context(), state.DisplayName); err == nil && other.ID != g.ID {
+				return scimError(c, fiber.StatusConflict, "uniqueness", "a group with this displayName already exists")
+			}
+		}
+		var err error
+		g, err = q.UpdateSCIMGroup(c.Context(), db.UpdateSCIMGroupParams{
+			ID:          g.ID,
+			DisplayName: state.DisplayName,
+			ExternalID:  scimNullString(state.ExternalID),
+		})
+		if err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+	}
+
+	affected := make(map[uuid.UUID]bool)
+	for id := range state.Members {
+		if !current[id] {
+			if err := q.AddSCIMGroupMember(c.Context(), db.AddSCIMGroupMemberParams{GroupID: g.ID, UserID: id}); err != nil {
+				return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+			}
+			affected[id] = true
+		} else if renamed {
+			affected[id] = true
+		}
+	}
+	for id := range current {
+		if !state.Members[id] {
+			if err := q.RemoveSCIMGroupMember(c.Context(), db.RemoveSCIMGroupMemberParams{GroupID: g.ID, UserID: id}); err != nil {
+				return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+			}
+			affected[id] = true
+		}
+	}
+	for id := range affected {
+		if err := syncSCIMTenants(c.Context(), q, cfg.Auth.SCIM.GroupMappings, id); err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+	}
+
+	recordAuditEvent(c, st, action, auditEventOptions{
+		ResourceType: "scim_group",
+		ResourceID:   g.ID.String(),
+		Metadata:     map[string]any{"displayName": g.DisplayName, "members": len(state.Members)},
+	})
+
+	res, err := scimGroupResource(c.Context(), c, q, g)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	return scimJSON(c, status, res)
+}
+
+// scimDeleteGroupHandler deletes a group; its members lose the tenant
+// memberships it granted.
+func scimDeleteGroupHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+	cfg := c.Locals("config").(*config.Config)
+	q := db.New(st.DB)
+	g, ok, err := scimLoadGroup(c, q)
+	if !ok {
+		return err
+	}
+	members, err := scimGroupMemberIDs(c.Context(), q, g.ID)
+	if err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	if err := q.DeleteSCIMGroup(c.Context(), g.ID); err != nil {
+		return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+	}
+	for id := range members {
+		if err := syncSCIMTenants(c.Context(), q, cfg.Auth.SCIM.GroupMappings, id); err != nil {
+			return scimError(c, fiber.StatusInternalServerError, "", err.Error())
+		}
+	}
+
+	recordAuditEvent(c, st, "scim.group.delete", auditEventOptions{
+		ResourceType: "scim_group",
+		ResourceID:   g.ID.String(),
+		Metadata:     map[string]any{"displayName": g.DisplayName},
+	})
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func scimNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -10,18 +10,24 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 
 	"raito/internal/config"
+	"raito/internal/db"
 	"raito/internal/llm"
 	"raito/internal/metrics"
+	"raito/internal/plugins"
 	"raito/internal/scraper"
 	"raito/internal/scrapeutil"
 	"raito/internal/services"
+	"raito/internal/store"
 )
 
 // scrapeHandler implements a minimal Firecrawl v2-compatible scrape endpoint.
 // It currently supports basic HTML pages via the HTTP scraper.
 func scrapeHandler(c *fiber.Ctx) error {
+	start := time.Now()
+
 	var reqBody ScrapeRequest
 	if err := c.BodyParser(&reqBody); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
@@ -31,6 +37,19 @@ func scrapeHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	_, code, msg := applyCollectionDefaults(c, reqBody.CollectionID, &reqBody)
+	if code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   msg,
+		})
+	}
+	formatsSource, err := applyDefaultFormats(c, &reqBody.Formats)
+	if err != nil {
+		return defaultFormatsLookupFailed(c, err)
+	}
+
 	if reqBody.URL == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Success: false,
@@ -39,8 +58,120 @@ func scrapeHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := validateJobVisibility(reqBody.Visibility); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobPool(c, reqBody.Pool); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateScrapeSubmission(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateClassifyFormat(reqBody.Formats); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
 	cfg := c.Locals("config").(*config.Config)
 
+	if err := validateFormatByteLimits(cfg, reqBody.MaxFormatBytes); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if len(reqBody.Actions) > 0 && !cfg.Rod.Enabled {
+		return c.Status(http.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "ACTIONS_NOT_AVAILABLE",
+			Error:   "actions require browser scraping, but rod is disabled in server configuration",
+		})
+	}
+
+	wantA11y := scrapeutil.WantsFormat(reqBody.Formats, "a11y")
+	if wantA11y && !cfg.Rod.Enabled {
+		return c.Status(http.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "A11Y_NOT_AVAILABLE",
+			Error:   "a11y format requires browser scraping, but rod is disabled in server configuration",
+		})
+	}
+	wantPerformance := scrapeutil.WantsFormat(reqBody.Formats, "performance")
+	if wantPerformance && !cfg.Rod.Enabled {
+		return c.Status(http.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "PERFORMANCE_NOT_AVAILABLE",
+			Error:   "performance format requires browser scraping, but rod is disabled in server configuration",
+		})
+	}
+
+	st := c.Locals("store").(*store.Store)
+	var tenantID *uuid.UUID
+	if p, ok := c.Locals("principal").(Principal); ok {
+		tenantID = p.TenantID
+	}
+
+	if err := resolveFormatTemplates(c.Context(), db.New(st.DB), tenantID, reqBody.Formats); err != nil {
+		status, code := promptTemplateStatus(err)
+		return c.Status(status).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
+	applied := resolveScrapeOptions(cfg, &reqBody, c.Body())
+	applied.setFormatsSource(formatsSource)
+
+	// LLM formats fail fast once the tenant's monthly LLM budget is used
+	// up; scrapes without them are unaffected.
+	if tenantID != nil && wantsLLMFormat(reqBody.Formats) {
+		if err := checkLLMBudget(c.Context(), db.New(st.DB), *tenantID); errors.Is(err, errLLMBudgetExceeded) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
+				Success: false,
+				Code:    "LLM_BUDGET_EXCEEDED",
+				Error:   err.Error(),
+			})
+		}
+	}
+
+	// Resolve auth up front so a missing secret is reported to the caller
+	// rather than as a failed job. Queued jobs resolve it again on the
+	// worker, since only the secret name is stored with the job.
+	var authHeaders map[string]string
+	if reqBody.Auth != nil {
+		var err error
+		authHeaders, err = targetAuthHeaders(c.Context(), cfg, db.New(st.DB), tenantID, reqBody.Auth)
+		if err != nil {
+			status, code := targetAuthStatus(err)
+			return c.Status(status).JSON(ErrorResponse{
+				Success: false,
+				Code:    code,
+				Error:   err.Error(),
+			})
+		}
+	}
+
 	timeoutMs := cfg.Scraper.TimeoutMs
 	if reqBody.Timeout != nil && *reqBody.Timeout > 0 {
 		timeoutMs = *reqBody.Timeout
@@ -51,7 +182,7 @@ func scrapeHandler(c *fiber.Ctx) error {
 	// workers perform the browser/LLM work.
 	if execVal := c.Locals("executor"); execVal != nil {
 		if exec, ok := execVal.(WorkExecutor); ok && exec != nil {
-			baseCtx := context.Background()
+			baseCtx := context.WithValue(c.UserContext(), "applied_options", applied)
 			if val := c.Locals("principal"); val != nil {
 				if p, ok := val.(Principal); ok {
 					if p.TenantID != nil {
@@ -60,9 +191,16 @@ func scrapeHandler(c *fiber.Ctx) error {
 					if p.APIKeyID != nil {
 						baseCtx = context.WithValue(baseCtx, "api_key_id", *p.APIKeyID)
 					}
+					if p.UserID != nil {
+						baseCtx = context.WithValue(baseCtx, "user_id", *p.UserID)
+					}
 				}
 			}
 
+			if reqBody.Async != nil && *reqBody.Async {
+				return enqueueAsyncScrape(baseCtx, c, exec, &reqBody, applied)
+			}
+
 			ctx, cancel := context.WithTimeout(baseCtx, time.Duration(timeoutMs)*time.Millisecond)
 			defer cancel()
 
@@ -93,25 +231,54 @@ func scrapeHandler(c *fiber.Ctx) error {
 				if res.Code == "SCRAPE_TIMEOUT" || res.Code == "JOB_NOT_STARTED" {
 					status = http.StatusGatewayTimeout
 				}
+				if res.Code == "LLM_BUDGET_EXCEEDED" {
+					status = http.StatusTooManyRequests
+				}
+				if res.Code == "TRANSFORM_HOOK_DROPPED" {
+					status = http.StatusUnprocessableEntity
+				}
 			}
 
+			capDocumentFormats(res.Data, formatSizeCaps(cfg, reqBody.MaxFormatBytes))
+			res.AppliedOptions = applied
 			return c.Status(status).JSON(res)
 		}
 	}
 
+	if reqBody.Async != nil && *reqBody.Async {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "ASYNC_NOT_AVAILABLE",
+			Error:   "async scrapes require the job queue",
+		})
+	}
+
+	hook, err := loadTransformHook(c.Context(), cfg, db.New(st.DB), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "TRANSFORM_HOOK_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
 	// Determine whether screenshot format was requested and its options.
 	hasScreenshot, screenshotFullPage := getScreenshotFormatConfig(reqBody.Formats)
+	budget := newScrapeBudget(start, time.Duration(timeoutMs)*time.Millisecond, scrapeStages(reqBody, hasScreenshot, wantA11y, wantPerformance))
 
 	// Choose scraper engine: HTTP by default, rod when requested and enabled.
 	useBrowser := false
 	if reqBody.UseBrowser != nil {
 		useBrowser = *reqBody.UseBrowser
 	}
-	if hasScreenshot {
-		// Screenshot always uses the browser engine.
+	if hasScreenshot || len(reqBody.Actions) > 0 {
+		// Screenshots and actions always use the browser engine.
 		useBrowser = true
 	}
 
+	// The fetch starts here; everything before it is setup.
+	fetchTimeout, _ := budget.start(scrapeStageFetch)
+
 	var engine scraper.Scraper
 	if useBrowser {
 		if !cfg.Rod.Enabled {
@@ -122,15 +289,15 @@ func scrapeHandler(c *fiber.Ctx) error {
 					Error:   "screenshot format requires browser scraping, but rod is disabled in server configuration",
 				})
 			}
-			engine = scraper.NewHTTPScraper(time.Duration(timeoutMs) * time.Millisecond)
+			engine = scraper.NewHTTPScraper(fetchTimeout)
 		} else {
 			// When rod is enabled, always use a locally managed headless browser
 			// via RodScraper. The browser pool / BrowserURL support has been
 			// removed for now to simplify deployment.
-			engine = scraper.NewRodScraper(time.Duration(timeoutMs) * time.Millisecond)
+			engine = scraper.NewBrowserScraper(fetchTimeout, browserOptions(cfg))
 		}
 	} else {
-		engine = scraper.NewHTTPScraper(time.Duration(timeoutMs) * time.Millisecond)
+		engine = scraper.NewHTTPScraper(fetchTimeout)
 	}
 
 	var locOpts *scraper.LocationOptions
@@ -142,17 +309,25 @@ func scrapeHandler(c *fiber.Ctx) error {
 	}
 
 	scrapeReq := scraper.BuildRequestFromOptions(scraper.RequestOptions{
-		URL:       reqBody.URL,
-		Headers:   reqBody.Headers,
-		TimeoutMs: timeoutMs,
-		UserAgent: cfg.Scraper.UserAgent,
-		Location:  locOpts,
+		URL:         reqBody.URL,
+		Headers:     reqBody.Headers,
+		AuthHeaders: authHeaders,
+		TimeoutMs:   int(fetchTimeout / time.Millisecond),
+		UserAgent:   cfg.RequestUserAgent(),
+		Location:    locOpts,
+
+		Method:      reqBody.Method,
+		Body:        reqBody.Body,
+		ContentType: reqBody.ContentType,
+		Actions:     scraperActions(reqBody.Actions),
 	})
 
-	ctx, cancel := context.WithTimeout(c.Context(), time.Duration(timeoutMs)*time.Millisecond)
+	ctx, cancel := context.WithTimeout(c.Context(), fetchTimeout)
 	defer cancel()
 
+	fetchStart := time.Now()
 	res, err := engine.Scrape(ctx, scrapeReq)
+	recordHostScrape(reqBody.URL, scrapeStatus(res), time.Since(fetchStart), err)
 	if err != nil {
 		status := fiber.StatusBadGateway
 		if errors.Is(err, context.DeadlineExceeded) {
@@ -191,13 +366,26 @@ func scrapeHandler(c *fiber.Ctx) error {
 
 	doc := svcRes.Document
 
+	// Plugin formats installed by the operator.
+	pluginOut, err := plugins.NewRegistry(cfg.Plugins).RunAll(c.Context(), reqBody.Formats, res)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(ErrorResponse{
+			Success: false,
+			Code:    "PLUGIN_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if len(pluginOut) > 0 {
+		doc.Plugins = pluginOut
+	}
+
 	// Optional screenshot format using the browser engine when requested.
-	if hasScreenshot {
-		screenshotCtx, screenshotCancel := context.WithTimeout(c.Context(), time.Duration(timeoutMs)*time.Millisecond)
+	if shotTimeout, ok := budget.start(scrapeStageScreenshot); hasScreenshot && ok {
+		screenshotCtx, screenshotCancel := context.WithTimeout(c.Context(), shotTimeout)
 		defer screenshotCancel()
 
-		shot, err := scraper.CaptureScreenshot(screenshotCtx, res.URL, time.Duration(timeoutMs)*time.Millisecond, screenshotFullPage)
-		if err != nil {
+		shot, err := scraper.CaptureBrowserScreenshot(screenshotCtx, browserOptions(cfg), res.URL, shotTimeout, screenshotFullPage)
+		if err != nil && !budget.expired(screenshotCtx, scrapeStageScreenshot, err) {
 			status := fiber.StatusBadGateway
 			if errors.Is(err, context.DeadlineExceeded) {
 				status = http.StatusGatewayTimeout
@@ -208,219 +396,317 @@ func scrapeHandler(c *fiber.Ctx) error {
 				Error:   err.Error(),
 			})
 		}
-
-		doc.Screenshot = base64.StdEncoding.EncodeToString(shot)
+		if err == nil {
+			doc.Screenshot = base64.StdEncoding.EncodeToString(shot)
+		}
 	}
 
-	// Optional summary format using the configured LLM provider when requested.
-	if scrapeutil.WantsFormat(reqBody.Formats, "summary") {
-		client, provider, modelName, err := llm.NewClientFromConfig(cfg, "", "")
-		if err != nil {
-			return c.Status(http.StatusInternalServerError).JSON(ErrorResponse{
+	// Optional accessibility audit of the rendered page.
+	if auditTimeout, ok := budget.start(scrapeStageA11y); wantA11y && ok {
+		auditCtx, auditCancel := context.WithTimeout(c.Context(), auditTimeout)
+		defer auditCancel()
+
+		report, err := scraper.AuditBrowserAccessibility(auditCtx, browserOptions(cfg), res.URL, auditTimeout)
+		if err != nil && !budget.expired(auditCtx, scrapeStageA11y, err) {
+			status := fiber.StatusBadGateway
+			if errors.Is(err, context.DeadlineExceeded) {
+				status = http.StatusGatewayTimeout
+			}
+			return c.Status(status).JSON(ErrorResponse{
 				Success: false,
-				Code:    "LLM_NOT_CONFIGURED",
+				Code:    "A11Y_AUDIT_FAILED",
 				Error:   err.Error(),
 			})
 		}
-
-		// Expose LLM info to logging middleware via locals.
-		c.Locals("llm_provider", string(provider))
-		c.Locals("llm_model", modelName)
-
-		fieldSpecs := []llm.FieldSpec{
-			{
-				Name:        "summary",
-				Description: "Short natural-language summary of the page content.",
-				Type:        "string",
-			},
+		if err == nil {
+			doc.Metadata.Accessibility = report
 		}
+	}
 
-		llmTimeout := time.Duration(timeoutMs) * time.Millisecond
-		llmCtx, llmCancel := context.WithTimeout(c.Context(), llmTimeout)
-		defer llmCancel()
-
-		llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
-			URL:      reqBody.URL,
-			Markdown: res.Markdown,
-			Fields:   fieldSpecs,
-			Prompt:   "",
-			Timeout:  llmTimeout,
-			Strict:   false,
-		})
-		if err != nil {
-			metrics.RecordLLMExtract(string(provider), modelName, false)
+	// Optional performance measurement in a fresh browser.
+	if perfTimeout, ok := budget.start(scrapeStagePerformance); wantPerformance && ok {
+		perfCtx, perfCancel := context.WithTimeout(c.Context(), perfTimeout)
+		defer perfCancel()
+
+		report, err := scraper.MeasureBrowserPerformance(perfCtx, browserOptions(cfg), res.URL, perfTimeout)
+		if err != nil && !budget.expired(perfCtx, scrapeStagePerformance, err) {
 			status := fiber.StatusBadGateway
 			if errors.Is(err, context.DeadlineExceeded) {
 				status = http.StatusGatewayTimeout
 			}
 			return c.Status(status).JSON(ErrorResponse{
 				Success: false,
-				Code:    "SUMMARY_FAILED",
+				Code:    "PERFORMANCE_FAILED",
 				Error:   err.Error(),
 			})
 		}
+		if err == nil {
+			doc.Metadata.Performance = report
+		}
+	}
+
+	// Optional classify format; it falls back to term matching instead of
+	// failing the scrape when the LLM is unavailable.
+	if wantClassify, labels := scrapeutil.GetClassifyFormatConfig(reqBody.Formats); wantClassify {
+		if classifyTimeout, ok := budget.start(scrapeStageClassify); ok {
+			classifier := newPageClassifier(c.Context(), cfg, st, tenantID, labels, classifyTimeout)
+			doc.Metadata.Categories = classifier.Classify(c.Context(), reqBody.URL, res.Markdown)
+		}
+	}
+
+	// Optional summary format using the configured LLM provider when requested.
+	if wantSummary, summaryPrompt := scrapeutil.GetSummaryFormatConfig(reqBody.Formats); wantSummary {
+		if llmTimeout, ok := budget.start(scrapeStageSummary); ok {
+			client, provider, modelName, err := newLLMClient(c.Context(), cfg, st, tenantID, "", "")
+			if err != nil {
+				status, code := llmClientFailure(err)
+				return c.Status(status).JSON(ErrorResponse{
+					Success: false,
+					Code:    code,
+					Error:   err.Error(),
+				})
+			}
 
-		metrics.RecordLLMExtract(string(provider), modelName, true)
+			// Expose LLM info to logging middleware via locals.
+			c.Locals("llm_provider", string(provider))
+			c.Locals("llm_model", modelName)
 
-		if v, ok := llmRes.Fields["summary"]; ok {
-			if s, ok := v.(string); ok {
-				doc.Summary = s
+			fieldSpecs := []llm.FieldSpec{
+				{
+					Name:        "summary",
+					Description: "Short natural-language summary of the page content.",
+					Type:        "string",
+				},
+			}
+
+			llmCtx, llmCancel := context.WithTimeout(c.Context(), llmTimeout)
+			defer llmCancel()
+
+			llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
+				URL:      reqBody.URL,
+				Markdown: res.Markdown,
+				Fields:   fieldSpecs,
+				Prompt:   summaryPrompt,
+				Timeout:  llmTimeout,
+				Strict:   false,
+			})
+			if err != nil {
+				metrics.RecordLLMExtract(string(provider), modelName, false)
+				if !budget.expired(llmCtx, scrapeStageSummary, err) {
+					status, code := llmFailure(err, "SUMMARY_FAILED")
+					return c.Status(status).JSON(ErrorResponse{
+						Success: false,
+						Code:    code,
+						Error:   err.Error(),
+					})
+				}
 			} else {
-				doc.Summary = fmt.Sprint(v)
+				metrics.RecordLLMExtract(string(provider), modelName, true)
+
+				if v, ok := llmRes.Fields["summary"]; ok {
+					if s, ok := v.(string); ok {
+						doc.Summary = s
+					} else {
+						doc.Summary = fmt.Sprint(v)
+					}
+				}
 			}
 		}
 	}
 
 	// Optional json format using the configured LLM provider when requested.
 	if hasJSON, jsonPrompt, jsonSchema := scrapeutil.GetJSONFormatConfig(reqBody.Formats); hasJSON {
-		client, provider, modelName, err := llm.NewClientFromConfig(cfg, "", "")
-		if err != nil {
-			return c.Status(http.StatusInternalServerError).JSON(ErrorResponse{
-				Success: false,
-				Code:    "LLM_NOT_CONFIGURED",
-				Error:   err.Error(),
-			})
-		}
+		if llmTimeout, ok := budget.start(scrapeStageJSON); ok {
+			client, provider, modelName, err := newLLMClient(c.Context(), cfg, st, tenantID, "", "")
+			if err != nil {
+				status, code := llmClientFailure(err)
+				return c.Status(status).JSON(ErrorResponse{
+					Success: false,
+					Code:    code,
+					Error:   err.Error(),
+				})
+			}
 
-		// Expose LLM info to logging middleware via locals (may override previous LLM info).
-		c.Locals("llm_provider", string(provider))
-		c.Locals("llm_model", modelName)
+			// Expose LLM info to logging middleware via locals (may override previous LLM info).
+			c.Locals("llm_provider", string(provider))
+			c.Locals("llm_model", modelName)
 
-		desc := "Arbitrary JSON object extracted from the page content."
-		if len(jsonSchema) > 0 {
-			if schemaBytes, err := json.Marshal(jsonSchema); err == nil {
-				desc = desc + " Schema: " + string(schemaBytes)
+			desc := "Arbitrary JSON object extracted from the page content."
+			if len(jsonSchema) > 0 {
+				if schemaBytes, err := json.Marshal(jsonSchema); err == nil {
+					desc = desc + " Schema: " + string(schemaBytes)
+				}
 			}
-		}
-
-		fieldSpecs := []llm.FieldSpec{
-			{
-				Name:        "json",
-				Description: desc,
-				Type:        "object",
-			},
-		}
 
-		llmTimeout := time.Duration(timeoutMs) * time.Millisecond
-		llmCtx, llmCancel := context.WithTimeout(c.Context(), llmTimeout)
-		defer llmCancel()
-
-		llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
-			URL:      reqBody.URL,
-			Markdown: res.Markdown,
-			Fields:   fieldSpecs,
-			Prompt:   jsonPrompt,
-			Timeout:  llmTimeout,
-			Strict:   false,
-		})
-		if err != nil {
-			metrics.RecordLLMExtract(string(provider), modelName, false)
-			status := fiber.StatusBadGateway
-			if errors.Is(err, context.DeadlineExceeded) {
-				status = http.StatusGatewayTimeout
+			fieldSpecs := []llm.FieldSpec{
+				{
+					Name:        "json",
+					Description: desc,
+					Type:        "object",
+				},
 			}
-			return c.Status(status).JSON(ErrorResponse{
-				Success: false,
-				Code:    "JSON_EXTRACT_FAILED",
-				Error:   err.Error(),
-			})
-		}
 
-		metrics.RecordLLMExtract(string(provider), modelName, true)
+			llmCtx, llmCancel := context.WithTimeout(c.Context(), llmTimeout)
+			defer llmCancel()
 
-		if v, ok := llmRes.Fields["json"]; ok {
-			// v is expected to be a nested map[string]any representing structured JSON.
-			if m, ok := v.(map[string]any); ok {
-				doc.JSON = m
+			llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
+				URL:      reqBody.URL,
+				Markdown: res.Markdown,
+				Fields:   fieldSpecs,
+				Prompt:   jsonPrompt,
+				Timeout:  llmTimeout,
+				Strict:   false,
+			})
+			if err != nil {
+				metrics.RecordLLMExtract(string(provider), modelName, false)
+				if !budget.expired(llmCtx, scrapeStageJSON, err) {
+					status, code := llmFailure(err, "JSON_EXTRACT_FAILED")
+					return c.Status(status).JSON(ErrorResponse{
+						Success: false,
+						Code:    code,
+						Error:   err.Error(),
+					})
+				}
 			} else {
-				// If the LLM returns a non-object, still expose it as best-effort.
-				// The client can decide how to interpret this.
-				// We wrap it into a single-field object for consistency.
-				doc.JSON = map[string]any{"_value": v}
+				metrics.RecordLLMExtract(string(provider), modelName, true)
+
+				if v, ok := llmRes.Fields["json"]; ok {
+					// v is expected to be a nested map[string]any representing structured JSON.
+					if m, ok := v.(map[string]any); ok {
+						doc.JSON = m
+					} else {
+						// If the LLM returns a non-object, still expose it as best-effort.
+						// The client can decide how to interpret this.
+						// We wrap it into a single-field object for consistency.
+						doc.JSON = map[string]any{"_value": v}
+					}
+				}
 			}
 		}
 	}
 
 	// Optional branding format using the configured LLM provider when requested.
 	if hasBranding, brandingPrompt := scrapeutil.GetBrandingFormatConfig(reqBody.Formats); hasBranding {
-		client, provider, modelName, err := llm.NewClientFromConfig(cfg, "", "")
-		if err != nil {
-			return c.Status(http.StatusInternalServerError).JSON(ErrorResponse{
-				Success: false,
-				Code:    "LLM_NOT_CONFIGURED",
-				Error:   err.Error(),
-			})
-		}
+		if llmTimeout, ok := budget.start(scrapeStageBranding); ok {
+			client, provider, modelName, err := newLLMClient(c.Context(), cfg, st, tenantID, "", "")
+			if err != nil {
+				status, code := llmClientFailure(err)
+				return c.Status(status).JSON(ErrorResponse{
+					Success: false,
+					Code:    code,
+					Error:   err.Error(),
+				})
+			}
 
-		// Expose LLM info to logging middleware via locals (may override previous LLM info).
-		c.Locals("llm_provider", string(provider))
-		c.Locals("llm_model", modelName)
-
-		// Default branding prompt modeled after Firecrawl's BrandingProfile,
-		// asking for a structured object with keys like colorScheme, colors,
-		// typography, spacing, components, images, fonts, tone, and personality.
-		if brandingPrompt == "" {
-			brandingPrompt = "You are a brand design expert analyzing a website. Analyze the page and return a single JSON object describing the brand, matching this structure as closely as possible: " +
-				"{colorScheme?: 'light'|'dark', colors?: {primary?: string, secondary?: string, accent?: string, background?: string, textPrimary?: string, textSecondary?: string, link?: string, success?: string, warning?: string, error?: string}, " +
-				"typography?: {fontFamilies?: {primary?: string, heading?: string, code?: string}, fontStacks?: {primary?: string[], heading?: string[], body?: string[], paragraph?: string[]}, fontSizes?: {h1?: string, h2?: string, h3?: string, body?: string, small?: string}}, " +
-				"spacing?: {baseUnit?: number, borderRadius?: string}, components?: {buttonPrimary?: {background?: string, textColor?: string, borderColor?: string, borderRadius?: string}, buttonSecondary?: {...}}, " +
-				"images?: {logo?: string|null, favicon?: string|null, ogImage?: string|null}, personality?: {tone?: string, energy?: string, targetAudience?: string}}. " +
-				"Only include fields you can infer with reasonable confidence."
-		}
+			// Expose LLM info to logging middleware via locals (may override previous LLM info).
+			c.Locals("llm_provider", string(provider))
+			c.Locals("llm_model", modelName)
+
+			// Default branding prompt modeled after Firecrawl's BrandingProfile,
+			// asking for a structured object with keys like colorScheme, colors,
+			// typography, spacing, components, images, fonts, tone, and personality.
+			if brandingPrompt == "" {
+				brandingPrompt = "You are a brand design expert analyzing a website. Analyze the page and return a single JSON object describing the brand, matching this structure as closely as possible: " +
+					"{colorScheme?: 'light'|'dark', colors?: {primary?: string, secondary?: string, accent?: string, background?: string, textPrimary?: string, textSecondary?: string, link?: string, success?: string, warning?: string, error?: string}, " +
+					"typography?: {fontFamilies?: {primary?: string, heading?: string, code?: string}, fontStacks?: {primary?: string[], heading?: string[], body?: string[], paragraph?: string[]}, fontSizes?: {h1?: string, h2?: string, h3?: string, body?: string, small?: string}}, " +
+					"spacing?: {baseUnit?: number, borderRadius?: string}, components?: {buttonPrimary?: {background?: string, textColor?: string, borderColor?: string, borderRadius?: string}, buttonSecondary?: {...}}, " +
+					"images?: {logo?: string|null, favicon?: string|null, ogImage?: string|null}, personality?: {tone?: string, energy?: string, targetAudience?: string}}. " +
+					"Only include fields you can infer with reasonable confidence."
+			}
 
-		descBranding := "Brand identity and design system information (colors, typography, logo, components, personality, etc.) extracted from the page, following Firecrawl's BrandingProfile conventions."
+			descBranding := "Brand identity and design system information (colors, typography, logo, components, personality, etc.) extracted from the page, following Firecrawl's BrandingProfile conventions."
 
-		fieldSpecs := []llm.FieldSpec{
-			{
-				Name:        "branding",
-				Description: descBranding,
-				Type:        "object",
-			},
-		}
+			fieldSpecs := []llm.FieldSpec{
+				{
+					Name:        "branding",
+					Description: descBranding,
+					Type:        "object",
+				},
+			}
 
-		llmTimeout := time.Duration(timeoutMs) * time.Millisecond
-		llmCtx, llmCancel := context.WithTimeout(c.Context(), llmTimeout)
-		defer llmCancel()
-
-		llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
-			URL:      reqBody.URL,
-			Markdown: res.Markdown,
-			Fields:   fieldSpecs,
-			Prompt:   brandingPrompt,
-			Timeout:  llmTimeout,
-			Strict:   false,
-		})
+			llmCtx, llmCancel := context.WithTimeout(c.Context(), llmTimeout)
+			defer llmCancel()
 
-		if err != nil {
-			metrics.RecordLLMExtract(string(provider), modelName, false)
-			status := fiber.StatusBadGateway
-			if errors.Is(err, context.DeadlineExceeded) {
-				status = http.StatusGatewayTimeout
-			}
-			return c.Status(status).JSON(ErrorResponse{
-				Success: false,
-				Code:    "BRANDING_FAILED",
-				Error:   err.Error(),
+			llmRes, err := client.ExtractFields(llmCtx, llm.ExtractRequest{
+				URL:      reqBody.URL,
+				Markdown: res.Markdown,
+				Fields:   fieldSpecs,
+				Prompt:   brandingPrompt,
+				Timeout:  llmTimeout,
+				Strict:   false,
 			})
-		}
-
-		metrics.RecordLLMExtract(string(provider), modelName, true)
 
-		if v, ok := llmRes.Fields["branding"]; ok {
-			if m, ok := v.(map[string]any); ok {
-				scrapeutil.NormalizeBrandingImages(m)
-				doc.Branding = m
+			if err != nil {
+				metrics.RecordLLMExtract(string(provider), modelName, false)
+				if !budget.expired(llmCtx, scrapeStageBranding, err) {
+					status, code := llmFailure(err, "BRANDING_FAILED")
+					return c.Status(status).JSON(ErrorResponse{
+						Success: false,
+						Code:    code,
+						Error:   err.Error(),
+					})
+				}
 			} else {
-				doc.Branding = map[string]any{"_value": v}
+				metrics.RecordLLMExtract(string(provider), modelName, true)
+
+				if v, ok := llmRes.Fields["branding"]; ok {
+					if m, ok := v.(map[string]any); ok {
+						scrapeutil.NormalizeBrandingImages(m)
+						doc.Branding = m
+					} else {
+						doc.Branding = map[string]any{"_value": v}
+					}
+				}
 			}
 		}
 	}
 
+	doc.Metadata.SkippedFormats = budget.skipped
+
+	transformed, keep, err := hook.apply(c.Context(), uuid.Nil, "scrape", *doc)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(ErrorResponse{
+			Success: false,
+			Code:    "TRANSFORM_HOOK_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if !keep {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(ErrorResponse{
+			Success: false,
+			Code:    "TRANSFORM_HOOK_DROPPED",
+			Error:   errTransformHookDropped.Error(),
+		})
+	}
+	doc = &transformed
+	capDocumentFormats(doc, formatSizeCaps(cfg, reqBody.MaxFormatBytes))
+
 	response := ScrapeResponse{
 
-		Success: true,
-		Data:    doc,
+		Success:        true,
+		Data:           doc,
+		AppliedOptions: applied,
 	}
 
 	return c.Status(http.StatusOK).JSON(response)
 }
+
+// enqueueAsyncScrape queues an async scrape and responds with its job ID
+// and the /v1/jobs URL to check on it.
+func enqueueAsyncScrape(ctx context.Context, c *fiber.Ctx, exec WorkExecutor, reqBody *ScrapeRequest, applied AppliedOptions) error {
+	id, err := exec.ScrapeAsync(ctx, reqBody)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ScrapeResponse{
+			Success: false,
+			Code:    "SCRAPE_JOB_CREATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ScrapeResponse{
+		Success:        true,
+		ID:             id.String(),
+		URL:            c.Protocol() + "://" + c.Hostname() + "/v1/jobs/" + id.String(),
+		AppliedOptions: applied,
+	})
+}
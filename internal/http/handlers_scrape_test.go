@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestValidateScrapeSubmission(t *testing.T) {
+	yes := true
+	cases := []struct {
+		name    string
+		req     ScrapeRequest
+		wantErr bool
+	}{
+		{"default get", ScrapeRequest{URL: "https://a"}, false},
+		{"post form", ScrapeRequest{Method: "post", Body: "q=x"}, false},
+		{"body without post", ScrapeRequest{Body: "q=x"}, true},
+		{"unsupported method", ScrapeRequest{Method: "DELETE"}, true},
+		{"post with browser", ScrapeRequest{Method: "POST", UseBrowser: &yes}, true},
+		{"post with screenshot", ScrapeRequest{Method: "POST", Formats: []any{"screenshot"}}, true},
+		{"post with a11y", ScrapeRequest{Method: "POST", Formats: []any{"a11y"}}, true},
+		{"post with performance", ScrapeRequest{Method: "POST", Formats: []any{map[string]any{"type": "performance"}}}, true},
+		{"fill form", ScrapeRequest{Actions: []ScrapeAction{{Type: "fillForm", Fields: map[string]string{"q": "x"}}}}, false},
+		{"fill form without fields", ScrapeRequest{Actions: []ScrapeAction{{Type: "fillForm"}}}, true},
+		{"unknown action", ScrapeRequest{Actions: []ScrapeAction{{Type: "click"}}}, true},
+		{"post with actions", ScrapeRequest{Method: "POST", Actions: []ScrapeAction{{Type: "fillForm", Fields: map[string]string{"q": "x"}}}}, true},
+		{"evidence", ScrapeRequest{Evidence: &yes}, false},
+		{"evidence with zero retention", ScrapeRequest{Evidence: &yes, ZeroDataRetention: &yes}, true},
+	}
+	for _, tc := range cases {
+		err := validateScrapeSubmission(&tc.req)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v, wantErr=%v", tc.name, err, tc.wantErr)
+		}
+	}
+
+	req := ScrapeRequest{Method: "post", Body: "q=x"}
+	_ = validateScrapeSubmission(&req)
+	if req.Method != "POST" {
+		t.Fatalf("expected method normalized to POST, got %q", req.Method)
+	}
+
+	no := false
+	actions := scraperActions([]ScrapeAction{{Type: "fillForm"}, {Type: "fillForm", Submit: &no}})
+	if !actions[0].Submit || actions[1].Submit {
+		t.Fatalf("expected submit to default to true, got %+v", actions)
+	}
+}
+
+// asyncExecutor records the scrape it is asked to enqueue.
+type asyncExecutor struct {
+	WorkExecutor
+	id  uuid.UUID
+	err error
+	got *ScrapeRequest
+}
+
+func (e *asyncExecutor) ScrapeAsync(ctx context.Context, req *ScrapeRequest) (uuid.UUID, error) {
+	e.got = req
+	return e.id, e.err
+}
+
+func TestEnqueueAsyncScrape(t *testing.T) {
+	yes := true
+	exec := &asyncExecutor{id: uuid.New()}
+	app := fiber.New()
+	app.Post("/v1/scrape", func(c *fiber.Ctx) error {
+		return enqueueAsyncScrape(context.Background(), c, exec, &ScrapeRequest{URL: "https://example.com", Async: &yes}, nil)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "http://raito.test/v1/scrape", nil), -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var body ScrapeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !body.Success || body.ID != exec.id.String() || body.URL != "http://raito.test/v1/jobs/"+exec.id.String() || body.Data != nil {
+		t.Fatalf("unexpected response %+v", body)
+	}
+	if exec.got == nil || exec.got.URL != "https://example.com" {
+		t.Fatalf("expected the request to be enqueued, got %+v", exec.got)
+	}
+
+	exec.err = errors.New("db down")
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "http://raito.test/v1/scrape", nil), -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("expected 500 when enqueueing fails, got %d", resp.StatusCode)
+	}
+}
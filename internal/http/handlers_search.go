@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -61,6 +62,19 @@ func searchHandler(c *fiber.Ctx) error {
 		}
 	}
 
+	sites, excludeSites, categories, err := searchFilters(reqBody)
+	if err != nil {
+		code := "BAD_REQUEST"
+		if errors.Is(err, errUnsupportedCategory) {
+			code = "UNSUPPORTED_CATEGORY"
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
 	// Derive limit from request and config defaults.
 	limit := cfg.Search.MaxResults
 	if limit <= 0 {
@@ -103,6 +117,26 @@ func searchHandler(c *fiber.Ctx) error {
 			})
 		}
 	}
+	// Default formats apply too, limited to the ones search supports.
+	if reqBody.ScrapeOptions != nil && len(reqBody.ScrapeOptions.Formats) == 0 {
+		var defaults []any
+		if _, err := applyDefaultFormats(c, &defaults); err != nil {
+			return defaultFormatsLookupFailed(c, err)
+		}
+		for _, f := range defaults {
+			if formats.ValidateFormatsForEndpoint("search", []any{f}) == nil {
+				reqBody.ScrapeOptions.Formats = append(reqBody.ScrapeOptions.Formats, f)
+			}
+		}
+	}
+
+	if reqBody.ScrapeOptions != nil && reqBody.ScrapeOptions.Auth != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "scrapeOptions.auth is only supported by scrape and crawl",
+		})
+	}
 
 	hasScrape := reqBody.ScrapeOptions != nil
 
@@ -122,6 +156,9 @@ func searchHandler(c *fiber.Ctx) error {
 			TBS:               reqBody.TBS,
 			TimeoutMs:         timeoutMs,
 			IgnoreInvalidURLs: ignoreInvalid,
+			Sites:             sites,
+			ExcludeSites:      excludeSites,
+			Categories:        categories,
 		})
 		if err != nil {
 			status := http.StatusBadGateway
@@ -141,6 +178,7 @@ func searchHandler(c *fiber.Ctx) error {
 				Title:       r.Title,
 				Description: r.Description,
 				URL:         r.URL,
+				Category:    r.Category,
 			})
 		}
 
@@ -204,6 +242,9 @@ func searchHandler(c *fiber.Ctx) error {
 		TBS:              reqBody.TBS,
 		Timeout:          time.Duration(timeoutMs) * time.Millisecond,
 		IgnoreInvalidURL: ignoreInvalid,
+		Sites:            sites,
+		ExcludeSites:     excludeSites,
+		Categories:       categories,
 	}
 
 	results, err := provider.Search(ctx, searchReq)
@@ -265,9 +306,12 @@ func searchHandler(c *fiber.Ctx) error {
 	web := make([]SearchWebResult, 0, len(scraped.Web))
 	for _, r := range scraped.Web {
 		entry := SearchWebResult{
-			Title:       r.Title,
-			Description: r.Description,
-			URL:         r.URL,
+			Title:            r.Title,
+			Description:      r.Description,
+			URL:              r.URL,
+			Category:         r.Category,
+			Error:            r.Error,
+			ScrapeDurationMs: r.ScrapeDurationMs,
 		}
 		if r.Document != nil {
 			entry.Document = (*Document)(r.Document)
@@ -306,6 +350,11 @@ func searchHandler(c *fiber.Ctx) error {
 		providerName = "searxng"
 	}
 	metrics.RecordSearch(providerName, hasScrape, len(web), scrapedCount)
+	for _, r := range scraped.Web {
+		if r.Document != nil || r.Error != "" {
+			metrics.RecordSearchScrape(providerName, r.Error == "", r.ScrapeDurationMs)
+		}
+	}
 
 	if loggerVal := c.Locals("logger"); loggerVal != nil {
 		if lg, ok := loggerVal.(interface{ Info(msg string, args ...any) }); ok {
@@ -336,3 +385,44 @@ func searchHandler(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(resp)
 }
+
+var errUnsupportedCategory = errors.New("unsupported category")
+
+// searchFilters validates and normalizes the sites, excludeSites and
+// categories of a search request.
+func searchFilters(req SearchRequest) (sites, excludeSites, categories []string, err error) {
+	for _, raw := range req.Categories {
+		name, ok := search.NormalizeCategory(raw)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("%w %q; supported categories: %s", errUnsupportedCategory, raw, strings.Join(search.CategoryNames(), ", "))
+		}
+		if !slices.Contains(categories, name) {
+			categories = append(categories, name)
+		}
+	}
+	for _, raw := range req.Sites {
+		host, err := search.NormalizeSite(raw)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("sites: %w", err)
+		}
+		if !slices.Contains(sites, host) {
+			sites = append(sites, host)
+		}
+	}
+	for _, raw := range req.ExcludeSites {
+		host, err := search.NormalizeSite(raw)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("excludeSites: %w", err)
+		}
+		if slices.Contains(sites, host) {
+			return nil, nil, nil, fmt.Errorf("site %q is both included and excluded", host)
+		}
+		if !slices.Contains(excludeSites, host) {
+			excludeSites = append(excludeSites, host)
+		}
+	}
+	if len(sites)+len(excludeSites) > search.MaxSiteFilters {
+		return nil, nil, nil, fmt.Errorf("at most %d sites and excludeSites combined are allowed", search.MaxSiteFilters)
+	}
+	return sites, excludeSites, categories, nil
+}
@@ -0,0 +1,796 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/jobs"
+	"raito/internal/llm"
+	"raito/internal/metrics"
+	"raito/internal/services"
+	"raito/internal/store"
+)
+
+const (
+	// defaultSummarizeChunkChars is the markdown sent to the LLM per call
+	// when the request does not set maxChunkChars.
+	defaultSummarizeChunkChars = 60000
+	minSummarizeChunkChars     = 2000
+	maxSummarizeChunkChars     = 200000
+	// maxSummarizeDocumentIDs bounds documentIds in one request.
+	maxSummarizeDocumentIDs = 500
+)
+
+// summarizeSourceTypes are the job types whose documents can be
+// summarized by job ID.
+var summarizeSourceTypes = map[string]bool{"crawl": true, "batch_scrape": true}
+
+// SummarizeRequest is the body of POST /v1/summarize: the documents of a
+// completed crawl or batch scrape job, or an explicit set of documents,
+// condensed into one report.
+type SummarizeRequest struct {
+	JobID       string  `json:"jobId,omitempty"`
+	DocumentIDs []int64 `json:"documentIds,omitempty"`
+	// Prompt steers the report, for example its audience or focus.
+	Prompt       string `json:"prompt,omitempty"`
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	Model        string `json:"model,omitempty"`
+	// MaxChunkChars is the most markdown sent to the LLM in one call.
+	MaxChunkChars *int   `json:"maxChunkChars,omitempty"`
+	Visibility    string `json:"visibility,omitempty"`
+	Pool          string `json:"pool,omitempty"`
+}
+
+// Summarize job stages reported by GET /v1/summarize/:id.
+const (
+	summarizeStageQueued      = "queued"
+	summarizeStageSummarizing = "summarizing"
+	summarizeStageCombining   = "combining"
+	summarizeStageCompleted   = "completed"
+	summarizeStageFailed      = "failed"
+)
+
+// SummarizeStatusResponse is the body of GET /v1/summarize/:id.
+type SummarizeStatusResponse struct {
+	Success bool   `json:"success"`
+	ID      string `json:"id,omitempty"`
+	Status  string `json:"status,omitempty"`
+	// Stage is queued, summarizing, combining, completed or failed.
+	Stage    string             `json:"stage,omitempty"`
+	Progress *SummarizeProgress `json:"progress,omitempty"`
+	// Summary is the markdown report once the job has completed.
+	Summary string            `json:"summary,omitempty"`
+	Sources []SummarizeSource `json:"sources,omitempty"`
+	Stats   *SummarizeStats   `json:"stats,omitempty"`
+	Code    string            `json:"code,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// SummarizeProgress counts the chunks summarized so far.
+type SummarizeProgress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// SummarizeSource is a document a summary was written from.
+type SummarizeSource struct {
+	DocumentID int64  `json:"documentId"`
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+}
+
+// SummarizeStats describes the work behind a summary.
+type SummarizeStats struct {
+	Documents int `json:"documents"`
+	Chunks    int `json:"chunks"`
+	LLMCalls  int `json:"llmCalls"`
+}
+
+// summarizeOutput is the stored output of a summarize job.
+type summarizeOutput struct {
+	Summary string            `json:"summary"`
+	Sources []SummarizeSource `json:"sources"`
+	Stats   SummarizeStats    `json:"stats"`
+}
+
+// summarizeDocument is a source document with the markdown to summarize.
+type summarizeDocument struct {
+	SummarizeSource
+	markdown string
+}
+
+// summarizeChunkChars returns the per-call markdown budget of a request.
+func summarizeChunkChars(req SummarizeRequest) int {
+	if req.MaxChunkChars != nil && *req.MaxChunkChars > 0 {
+		return *req.MaxChunkChars
+	}
+	return defaultSummarizeChunkChars
+}
+
+// loadSummarizeDocuments resolves the documents a summarize request names
+// within tenantID, skipping excluded documents and those without
+// markdown. It returns them with the URL the job is listed under, or an
+// error code and error. A nil viewer skips visibility checks.
+func loadSummarizeDocuments(ctx context.Context, st *store.Store, tenantID uuid.UUID, viewer *store.JobViewer, req SummarizeRequest) ([]summarizeDocument, string, string, error) {
+	q := db.New(st.DB)
+	canSee := func(job db.Job) bool {
+		return job.TenantID.Valid && job.TenantID.UUID == tenantID && viewer.CanSee(job)
+	}
+
+	var docs []db.Document
+	var sourceURL string
+	if req.JobID != "" {
+		jobID, err := uuid.Parse(req.JobID)
+		if err != nil {
+			return nil, "", "BAD_REQUEST", errors.New("invalid jobId")
+		}
+		job, err := st.GetJobByID(ctx, jobID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && !canSee(job)) {
+			return nil, "", "NOT_FOUND", errors.New("source job not found")
+		}
+		if err != nil {
+			return nil, "", "SUMMARIZE_SOURCE_LOOKUP_FAILED", err
+		}
+		if !summarizeSourceTypes[job.Type] {
+			return nil, "", "BAD_REQUEST", fmt.Errorf("%s jobs cannot be summarized; use a crawl or batch scrape job", job.Type)
+		}
+		if job.Status != string(jobs.StatusCompleted) {
+			return nil, "", "JOB_NOT_COMPLETED", errors.New("source job is not completed yet")
+		}
+		docs, err = q.GetDocumentsByJobID(ctx, job.ID)
+		if err != nil {
+			return nil, "", "SUMMARIZE_SOURCE_LOOKUP_FAILED", err
+		}
+		sourceURL = job.Url
+	} else {
+		var err error
+		docs, err = q.GetDocumentsByIDs(ctx, req.DocumentIDs)
+		if err != nil {
+			return nil, "", "SUMMARIZE_SOURCE_LOOKUP_FAILED", err
+		}
+		found := make(map[int64]bool, len(docs))
+		visible := map[uuid.UUID]bool{}
+		for _, d := range docs {
+			found[d.ID] = true
+			ok, checked := visible[d.JobID]
+			if !checked {
+				job, err := st.GetJobByID(ctx, d.JobID)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return nil, "", "SUMMARIZE_SOURCE_LOOKUP_FAILED", err
+				}
+				ok = err == nil && canSee(job)
+				visible[d.JobID] = ok
+			}
+			if !ok {
+				found[d.ID] = false
+			}
+		}
+		for _, id := range req.DocumentIDs {
+			if !found[id] {
+				return nil, "", "NOT_FOUND", fmt.Errorf("document %d not found", id)
+			}
+		}
+		if len(docs) > 0 {
+			sourceURL = docs[0].Url
+		}
+	}
+
+	annotations, err := st.DocumentAnnotations(ctx, docs)
+	if err != nil {
+		return nil, "", "SUMMARIZE_SOURCE_LOOKUP_FAILED", err
+	}
+	out := make([]summarizeDocument, 0, len(docs))
+	for _, d := range withoutExcludedDocuments(docs, annotations) {
+		md := strings.TrimSpace(d.Markdown.String)
+		if md == "" {
+			continue
+		}
+		doc := summarizeDocument{SummarizeSource: SummarizeSource{DocumentID: d.ID, URL: d.Url, Title: documentTitle(d)}, markdown: md}
+		if a, ok := annotations[d.ID]; ok && a.Title.Valid {
+			doc.Title = a.Title.String
+		}
+		out = append(out, doc)
+	}
+	if len(out) == 0 {
+		return nil, "", "SUMMARIZE_NO_CONTENT", errors.New("no document with markdown content to summarize")
+	}
+	return out, sourceURL, "", nil
+}
+
+// chunkSummarizeDocuments packs documents under "Source:" headings into
+// chunks of at most maxChars, splitting documents that do not fit in one
+// chunk at paragraph breaks.
+func chunkSummarizeDocuments(docs []summarizeDocument, maxChars int) []string {
+	const sep = "\n\n---\n\n"
+	var chunks []string
+	var cur strings.Builder
+	add := func(section string) {
+		if cur.Len() > 0 && cur.Len()+len(sep)+len(section) > maxChars {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString(sep)
+		}
+		cur.WriteString(section)
+	}
+
+	for _, d := range docs {
+		header := "## Source: " + d.URL + "\n\n"
+		if len(header)+len(d.markdown) <= maxChars {
+			add(header + d.markdown)
+			continue
+		}
+		// Leave room for the "(part i of n)" suffix.
+		limit := max(maxChars-len(header)-32, maxChars/2)
+		parts := splitSummarizeMarkdown(d.markdown, limit)
+		for i, p := range parts {
+			add(fmt.Sprintf("## Source: %s (part %d of %d)\n\n%s", d.URL, i+1, len(parts), p))
+		}
+	}
+	if cur.Len() > 0 {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+// splitSummarizeMarkdown cuts md into parts of at most limit bytes,
+// preferring paragraph and then line breaks in the second half of each
+// part.
+func splitSummarizeMarkdown(md string, limit int) []string {
+	var parts []string
+	for len(md) > limit {
+		cut := strings.LastIndex(md[:limit], "\n\n")
+		if cut < limit/2 {
+			cut = strings.LastIndex(md[:limit], "\n")
+		}
+		if cut < limit/2 {
+			cut = limit
+			for cut > 0 && !utf8.RuneStart(md[cut]) {
+				cut--
+			}
+		}
+		parts = append(parts, strings.TrimSpace(md[:cut]))
+		md = strings.TrimLeft(md[cut:], "\n")
+	}
+	if strings.TrimSpace(md) != "" {
+		parts = append(parts, strings.TrimSpace(md))
+	}
+	return parts
+}
+
+// groupSummaryNotes splits notes into groups of at most maxChars for the
+// next reduce round. Every group holds at least two notes so each round
+// shrinks the set; a group over the limit is cut down by joinSummaryNotes.
+func groupSummaryNotes(notes []string, maxChars int) [][]string {
+	var groups [][]string
+	var cur []string
+	size := 0
+	for _, n := range notes {
+		if len(cur) >= 2 && size+len(n) > maxChars {
+			groups = append(groups, cur)
+			cur, size = nil, 0
+		}
+		cur = append(cur, n)
+		size += len(n)
+	}
+	if len(cur) == 1 && len(groups) > 0 {
+		groups[len(groups)-1] = append(groups[len(groups)-1], cur[0])
+	} else if len(cur) > 0 {
+		groups = append(groups, cur)
+	}
+	return groups
+}
+
+// joinSummaryNotes joins notes for one LLM call, giving each an equal
+// share of maxChars when together they do not fit.
+func joinSummaryNotes(notes []string, maxChars int) string {
+	const sep = "\n\n---\n\n"
+	seps := len(sep) * (len(notes) - 1)
+	total := seps
+	for _, n := range notes {
+		total += len(n)
+	}
+	perNote := 0
+	if total > maxChars {
+		perNote = max((maxChars-seps)/len(notes), 1)
+	}
+
+	var b strings.Builder
+	for i, n := range notes {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		if perNote > 0 && len(n) > perNote {
+			cut := perNote
+			for cut > 0 && !utf8.RuneStart(n[cut]) {
+				cut--
+			}
+			n = n[:cut]
+		}
+		b.WriteString(n)
+	}
+	return b.String()
+}
+
+const (
+	summarizeNotesPrompt  = "The markdown contains pages, each introduced by a 'Source: <url>' heading. Write dense markdown notes of their key facts, figures, and conclusions. Keep the source URL next to each fact and leave out navigation and boilerplate."
+	summarizeMergePrompt  = "The markdown contains notes taken from several pages. Condense them into one set of markdown notes without losing distinct facts or their source URLs, and merge duplicates."
+	summarizeReportPrompt = "Write one consolidated report in markdown from the content below. Open with a short overview, then organize the findings by theme rather than by page, and cite source URLs in brackets after the facts they support."
+)
+
+// summarizer runs the map-reduce LLM calls of one summarize job.
+type summarizer struct {
+	client       llm.Client
+	provider     string
+	model        string
+	timeout      time.Duration
+	maxChars     int
+	url          string
+	prompt       string
+	systemPrompt string
+	calls        int
+}
+
+// summarize condenses chunks into one markdown report. A single chunk is
+// reported on directly; otherwise each chunk is turned into notes, which
+// are merged in rounds until they fit one final call.
+func (s *summarizer) summarize(ctx context.Context, chunks []string) (string, error) {
+	rt := metrics.JobRuntimeFrom(ctx)
+	rt.SetPagesTotal(len(chunks))
+	if len(chunks) == 1 {
+		report, err := s.call(ctx, chunks[0], s.reportPrompt())
+		rt.AddPage(int64(len(chunks[0])))
+		return report, err
+	}
+
+	notes := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		n, err := s.call(ctx, chunk, summarizeNotesPrompt)
+		if err != nil {
+			return "", err
+		}
+		rt.AddPage(int64(len(chunk)))
+		notes = append(notes, n)
+	}
+
+	for {
+		groups := groupSummaryNotes(notes, s.maxChars)
+		if len(groups) == 1 {
+			return s.call(ctx, joinSummaryNotes(groups[0], s.maxChars), s.reportPrompt())
+		}
+		merged := make([]string, 0, len(groups))
+		for _, g := range groups {
+			n, err := s.call(ctx, joinSummaryNotes(g, s.maxChars), summarizeMergePrompt)
+			if err != nil {
+				return "", err
+			}
+			merged = append(merged, n)
+		}
+		notes = merged
+	}
+}
+
+func (s *summarizer) reportPrompt() string {
+	if s.prompt == "" {
+		return summarizeReportPrompt
+	}
+	return summarizeReportPrompt + "\n\n" + s.prompt
+}
+
+// call asks the LLM for a markdown summary of markdown.
+func (s *summarizer) call(ctx context.Context, markdown, prompt string) (string, error) {
+	if s.systemPrompt != "" {
+		prompt = s.systemPrompt + "\n\n" + prompt
+	}
+	llmCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	s.calls++
+	res, err := s.client.ExtractFields(llmCtx, llm.ExtractRequest{
+		URL:      s.url,
+		Markdown: markdown,
+		Fields: []llm.FieldSpec{{
+			Name:        "summary",
+			Description: "The requested summary as markdown.",
+			Type:        "string",
+		}},
+		Prompt:  prompt,
+		Timeout: s.timeout,
+		Strict:  false,
+	})
+	metrics.RecordLLMExtract(s.provider, s.model, err == nil)
+	if err != nil {
+		return "", err
+	}
+	var summary string
+	switch v := res.Fields["summary"].(type) {
+	case nil:
+	case string:
+		summary = v
+	default:
+		summary = fmt.Sprint(v)
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", errors.New("LLM returned an empty summary")
+	}
+	return summary, nil
+}
+
+// runSummarizeJob loads the requested documents, summarizes them with
+// map-reduce LLM calls, and stores the report as the job output.
+func runSummarizeJob(ctx context.Context, cfg *config.Config, st *store.Store, jobID uuid.UUID, req SummarizeRequest) {
+	fail := func(code string, err error) {
+		msg := code + ": " + err.Error()
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+	}
+
+	tenantID := tenantIDFromContext(ctx)
+	if tenantID == nil {
+		fail("SUMMARIZE_FAILED", errors.New("summarize jobs require a tenant"))
+		return
+	}
+	docs, sourceURL, code, err := loadSummarizeDocuments(ctx, st, *tenantID, nil, req)
+	if err != nil {
+		fail(code, err)
+		return
+	}
+
+	if err := checkLLMBudget(ctx, db.New(st.DB), *tenantID); errors.Is(err, errLLMBudgetExceeded) {
+		fail("LLM_BUDGET_EXCEEDED", err)
+		return
+	}
+	client, provider, model, err := newLLMClient(ctx, cfg, st, tenantID, req.Provider, req.Model)
+	if err != nil {
+		msg := llmClientErrorMessage(err)
+		_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusFailed), &msg)
+		return
+	}
+
+	timeoutMs := cfg.Scraper.TimeoutMs
+	if timeoutMs <= 0 {
+		timeoutMs = 30000
+	}
+	maxChars := summarizeChunkChars(req)
+	chunks := chunkSummarizeDocuments(docs, maxChars)
+	s := &summarizer{
+		client:       client,
+		provider:     string(provider),
+		model:        model,
+		timeout:      time.Duration(timeoutMs) * time.Millisecond,
+		maxChars:     maxChars,
+		url:          sourceURL,
+		prompt:       req.Prompt,
+		systemPrompt: req.SystemPrompt,
+	}
+	report, err := s.summarize(ctx, chunks)
+	if err != nil {
+		code := "SUMMARIZE_FAILED"
+		if errors.Is(err, errLLMBudgetExceeded) {
+			code = "LLM_BUDGET_EXCEEDED"
+		}
+		fail(code, err)
+		return
+	}
+
+	out := summarizeOutput{
+		Summary: report,
+		Sources: make([]SummarizeSource, 0, len(docs)),
+		Stats:   SummarizeStats{Documents: len(docs), Chunks: len(chunks), LLMCalls: s.calls},
+	}
+	for _, d := range docs {
+		out.Sources = append(out.Sources, d.SummarizeSource)
+	}
+	output, err := json.Marshal(out)
+	if err != nil {
+		fail("SUMMARIZE_FAILED", fmt.Errorf("failed to marshal summary: %w", err))
+		return
+	}
+	if err := st.SetJobOutput(context.Background(), jobID, output); err != nil {
+		fail("SUMMARIZE_FAILED", fmt.Errorf("failed to persist summary: %w", err))
+		return
+	}
+	_ = st.UpdateCrawlJobStatus(context.Background(), jobID, string(jobs.StatusCompleted), nil)
+}
+
+// summarizeMarkdown renders a summarize job's output as a markdown file
+// with the report followed by its sources.
+func summarizeMarkdown(out summarizeOutput) string {
+	var b strings.Builder
+	b.WriteString(out.Summary)
+	if len(out.Sources) == 0 {
+		b.WriteString("\n")
+		return b.String()
+	}
+	b.WriteString("\n\n## Sources\n\n")
+	for _, s := range out.Sources {
+		if s.Title != "" {
+			fmt.Fprintf(&b, "- [%s](%s)\n", s.Title, s.URL)
+		} else {
+			fmt.Fprintf(&b, "- <%s>\n", s.URL)
+		}
+	}
+	return b.String()
+}
+
+// sendSummarizeDownload writes a completed summarize job as a markdown
+// file.
+func sendSummarizeDownload(c *fiber.Ctx, filenameBase string, job db.Job) error {
+	var out summarizeOutput
+	if !job.Output.Valid || json.Unmarshal(job.Output.RawMessage, &out) != nil || out.Summary == "" {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Success: false,
+			Code:    "NO_DOWNLOAD_AVAILABLE",
+			Error:   "no downloadable output is available for this job",
+		})
+	}
+	c.Set(fiber.HeaderContentType, "text/markdown; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, contentDisposition(filenameBase+".md"))
+	return c.SendString(summarizeMarkdown(out))
+}
+
+// summarizeHandler implements POST /v1/summarize, which enqueues a
+// summarize job.
+func summarizeHandler(c *fiber.Ctx) error {
+	var reqBody SummarizeRequest
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+
+	reqBody.JobID = strings.TrimSpace(reqBody.JobID)
+	switch {
+	case reqBody.JobID == "" && len(reqBody.DocumentIDs) == 0:
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "Missing required field 'jobId' or 'documentIds'",
+		})
+	case reqBody.JobID != "" && len(reqBody.DocumentIDs) > 0:
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "'jobId' and 'documentIds' cannot both be set",
+		})
+	}
+
+	if len(reqBody.DocumentIDs) > 0 {
+		seen := map[int64]bool{}
+		ids := reqBody.DocumentIDs[:0]
+		for _, id := range reqBody.DocumentIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		reqBody.DocumentIDs = ids
+		if len(ids) > maxSummarizeDocumentIDs {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   fmt.Sprintf("documentIds accepts at most %d documents", maxSummarizeDocumentIDs),
+			})
+		}
+	}
+
+	if n := reqBody.MaxChunkChars; n != nil && (*n < minSummarizeChunkChars || *n > maxSummarizeChunkChars) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   fmt.Sprintf("maxChunkChars must be between %d and %d", minSummarizeChunkChars, maxSummarizeChunkChars),
+		})
+	}
+
+	if err := validateJobVisibility(reqBody.Visibility); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := validateJobPool(c, reqBody.Pool); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+
+	p, ok := c.Locals("principal").(Principal)
+	if !ok || p.TenantID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "tenant context is required to summarize documents",
+		})
+	}
+
+	// The sources are checked now so that a bad request fails here rather
+	// than in the worker, which loads them again when the job runs.
+	_, sourceURL, code, err := loadSummarizeDocuments(c.Context(), st, *p.TenantID, jobViewerFor(c, st, p), reqBody)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch code {
+		case "NOT_FOUND":
+			status = fiber.StatusNotFound
+		case "JOB_NOT_COMPLETED":
+			status = fiber.StatusConflict
+		case "SUMMARIZE_SOURCE_LOOKUP_FAILED":
+			status = fiber.StatusInternalServerError
+		}
+		return c.Status(status).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
+	if err := checkLLMBudget(c.Context(), db.New(st.DB), *p.TenantID); errors.Is(err, errLLMBudgetExceeded) {
+		return c.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
+			Success: false,
+			Code:    "LLM_BUDGET_EXCEEDED",
+			Error:   err.Error(),
+		})
+	}
+	if _, _, err := resolveTenantLLM(c.Context(), cfg, db.New(st.DB), p.TenantID, reqBody.Provider, reqBody.Model); err != nil {
+		status, code := llmClientFailure(err)
+		return c.Status(status).JSON(ErrorResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+
+	id := func() uuid.UUID {
+		if id, err := uuid.NewV7(); err == nil {
+			return id
+		}
+		return uuid.New()
+	}()
+
+	if err := services.NewSummarizeService(st).Enqueue(c.Context(), &services.SummarizeRequest{
+		ID:         id,
+		Body:       reqBody,
+		URL:        sourceURL,
+		TenantID:   p.TenantID,
+		APIKeyID:   p.APIKeyID,
+		UserID:     p.UserID,
+		Visibility: reqBody.Visibility,
+		Pool: routeJobPool(c, jobs.RouteRequest{
+			TenantID:  p.TenantID,
+			Requested: reqBody.Pool,
+		}),
+	}); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(ErrorResponse{
+			Success: false,
+			Code:    "SUMMARIZE_JOB_CREATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"id":      id.String(),
+		"url":     c.Protocol() + "://" + c.Hostname() + "/v1/summarize/" + id.String(),
+	})
+}
+
+// summarizeStatus fills in resp from a summarize job's heartbeat and
+// output. hb is nil when the job has no heartbeat.
+func summarizeStatus(resp *SummarizeStatusResponse, job db.Job, hb *db.JobHeartbeat) error {
+	switch jobs.PublicStatus(job.Status) {
+	case string(jobs.StatusPending):
+		resp.Stage = summarizeStageQueued
+	case string(jobs.StatusRunning):
+		resp.Stage = summarizeStageSummarizing
+		if hb != nil {
+			completed, total, _ := crawlProgress(*hb, time.Now())
+			resp.Progress = &SummarizeProgress{Completed: completed, Total: total}
+			if total > 0 && completed >= total {
+				resp.Stage = summarizeStageCombining
+			}
+		}
+	case string(jobs.StatusCompleted):
+		resp.Stage = summarizeStageCompleted
+	case string(jobs.StatusFailed):
+		resp.Stage = summarizeStageFailed
+		resp.Code, resp.Error = "SUMMARIZE_FAILED", "summarize job failed"
+		if job.Error.Valid {
+			resp.Error = job.Error.String
+			if code, msg, ok := strings.Cut(job.Error.String, ":"); ok && strings.TrimSpace(code) != "" {
+				resp.Code, resp.Error = strings.TrimSpace(code), strings.TrimSpace(msg)
+			}
+		}
+	}
+
+	if job.Status != string(jobs.StatusCompleted) || !job.Output.Valid || len(job.Output.RawMessage) == 0 {
+		return nil
+	}
+	var out summarizeOutput
+	if err := json.Unmarshal(job.Output.RawMessage, &out); err != nil {
+		return err
+	}
+	resp.Summary, resp.Sources, resp.Stats = out.Summary, out.Sources, &out.Stats
+	return nil
+}
+
+// summarizeStatusHandler implements GET /v1/summarize/:id.
+func summarizeStatusHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(SummarizeStatusResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid summarize id",
+		})
+	}
+
+	job, err := st.GetJobByID(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(SummarizeStatusResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "summarize job not found",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(SummarizeStatusResponse{
+			Success: false,
+			Code:    "SUMMARIZE_JOB_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	// Enforce tenant scoping and job visibility for non-admin callers.
+	if jobHiddenFrom(c, st, job) || job.Type != "summarize" {
+		return c.Status(fiber.StatusNotFound).JSON(SummarizeStatusResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "summarize job not found",
+		})
+	}
+
+	var hb *db.JobHeartbeat
+	if job.Status == string(jobs.StatusRunning) {
+		if row, err := db.New(st.DB).GetJobHeartbeat(c.Context(), job.ID); err == nil {
+			hb = &row
+		}
+	}
+
+	resp := SummarizeStatusResponse{
+		Success: true,
+		ID:      job.ID.String(),
+		Status:  job.Status,
+	}
+	if err := summarizeStatus(&resp, job, hb); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(SummarizeStatusResponse{
+			Success: false,
+			Code:    "SUMMARIZE_RESULT_DECODE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	return c.JSON(resp)
+}
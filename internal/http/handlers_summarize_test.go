@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sqlc-dev/pqtype"
+
+	"raito/internal/db"
+	"raito/internal/llm"
+)
+
+func TestChunkSummarizeDocuments(t *testing.T) {
+	long := strings.Repeat("a", 300) + "\n\n" + strings.Repeat("b", 300) + "\n\n" + strings.Repeat("c", 300)
+	docs := []summarizeDocument{
+		{SummarizeSource: SummarizeSource{URL: "https://a.example/one"}, markdown: "first page"},
+		{SummarizeSource: SummarizeSource{URL: "https://a.example/two"}, markdown: "second page"},
+		{SummarizeSource: SummarizeSource{URL: "https://a.example/long"}, markdown: long},
+	}
+
+	chunks := chunkSummarizeDocuments(docs, 700)
+	if len(chunks) < 2 {
+		t.Fatalf("expected the long page to be split, got %d chunks", len(chunks))
+	}
+	if !strings.Contains(chunks[0], "## Source: https://a.example/one") || !strings.Contains(chunks[0], "## Source: https://a.example/two") {
+		t.Fatalf("expected short pages to share the first chunk, got %q", chunks[0])
+	}
+	all := strings.Join(chunks, "\n")
+	for _, want := range []string{"(part 1 of ", strings.Repeat("a", 300), strings.Repeat("c", 300)} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("expected chunks to contain %q", want)
+		}
+	}
+	for i, c := range chunks {
+		if len(c) > 700 {
+			t.Fatalf("chunk %d is %d bytes, over the limit", i, len(c))
+		}
+	}
+
+	parts := splitSummarizeMarkdown("héllo wörld", 5)
+	if strings.Join(parts, "") != "héllo wörld" {
+		t.Fatalf("expected the text to be kept, got %q", parts)
+	}
+	for _, p := range parts {
+		if !utf8.ValidString(p) || len(p) > 5 {
+			t.Fatalf("expected rune-safe parts of at most 5 bytes, got %q", parts)
+		}
+	}
+}
+
+func TestGroupSummaryNotes(t *testing.T) {
+	notes := []string{strings.Repeat("x", 40), strings.Repeat("y", 40), strings.Repeat("z", 40)}
+	if groups := groupSummaryNotes(notes, 200); len(groups) != 1 || len(groups[0]) != 3 {
+		t.Fatalf("expected one group when the notes fit, got %v", groups)
+	}
+	groups := groupSummaryNotes(notes, 50)
+	if len(groups) != 1 || len(groups[0]) != 3 {
+		t.Fatalf("expected a lone trailing note to join the last group, got %d groups", len(groups))
+	}
+	groups = groupSummaryNotes(append(notes, strings.Repeat("w", 40)), 50)
+	if len(groups) != 2 || len(groups[0]) != 2 || len(groups[1]) != 2 {
+		t.Fatalf("expected two groups of two, got %v", groups)
+	}
+	if joined := joinSummaryNotes(notes, 60); len(joined) > 60 {
+		t.Fatalf("expected notes to be cut to fit, got %d bytes", len(joined))
+	}
+}
+
+// fakeSummarizeLLM answers every call with a fixed summary and records
+// the markdown it was sent.
+type fakeSummarizeLLM struct {
+	inputs []string
+}
+
+func (f *fakeSummarizeLLM) ExtractFields(_ context.Context, req llm.ExtractRequest) (llm.ExtractResult, error) {
+	f.inputs = append(f.inputs, req.Markdown)
+	return llm.ExtractResult{Fields: map[string]any{"summary": strings.Repeat("n", 30)}}, nil
+}
+
+func TestSummarizerMapReduce(t *testing.T) {
+	client := &fakeSummarizeLLM{}
+	s := &summarizer{client: client, timeout: time.Second, maxChars: 80}
+	chunks := []string{"one", "two", "three", "four", "five"}
+
+	report, err := s.summarize(context.Background(), chunks)
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	if report == "" {
+		t.Fatal("expected a report")
+	}
+	// Five map calls, then notes of 30 bytes merge two or three at a time
+	// until they fit one final call.
+	if s.calls != len(client.inputs) || s.calls < len(chunks)+2 {
+		t.Fatalf("unexpected call count %d (%d inputs)", s.calls, len(client.inputs))
+	}
+	for i, in := range client.inputs {
+		if len(in) > 80 {
+			t.Fatalf("call %d sent %d bytes, over the limit", i, len(in))
+		}
+	}
+
+	single := &summarizer{client: &fakeSummarizeLLM{}, timeout: time.Second, maxChars: 80}
+	if _, err := single.summarize(context.Background(), []string{"only"}); err != nil || single.calls != 1 {
+		t.Fatalf("expected one call for a single chunk, got %d (%v)", single.calls, err)
+	}
+}
+
+func TestSummarizeStatus(t *testing.T) {
+	output := `{"summary":"# Report","sources":[{"documentId":7,"url":"https://a.example/","title":"A"}],"stats":{"documents":1,"chunks":1,"llmCalls":1}}`
+	job := db.Job{Status: "completed", Output: pqtype.NullRawMessage{RawMessage: json.RawMessage(output), Valid: true}}
+	var resp SummarizeStatusResponse
+	if err := summarizeStatus(&resp, job, nil); err != nil {
+		t.Fatalf("completed: %v", err)
+	}
+	if resp.Stage != summarizeStageCompleted || resp.Summary != "# Report" || len(resp.Sources) != 1 || resp.Stats.LLMCalls != 1 {
+		t.Fatalf("unexpected completed status: %+v", resp)
+	}
+
+	failed := db.Job{Status: "failed", Error: sql.NullString{String: "SUMMARIZE_NO_CONTENT: nothing to summarize", Valid: true}}
+	resp = SummarizeStatusResponse{}
+	if err := summarizeStatus(&resp, failed, nil); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if resp.Stage != summarizeStageFailed || resp.Code != "SUMMARIZE_NO_CONTENT" || resp.Error != "nothing to summarize" {
+		t.Fatalf("unexpected failed status: %+v", resp)
+	}
+
+	md := summarizeMarkdown(summarizeOutput{Summary: "# Report", Sources: []SummarizeSource{{URL: "https://a.example/", Title: "A"}, {URL: "https://b.example/"}}})
+	if !strings.Contains(md, "## Sources") || !strings.Contains(md, "- [A](https://a.example/)") || !strings.Contains(md, "- <https://b.example/>") {
+		t.Fatalf("unexpected markdown: %q", md)
+	}
+}
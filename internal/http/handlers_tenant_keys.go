@@ -2,6 +2,8 @@ package http
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,10 +14,14 @@ import (
 )
 
 type TenantAPIKeyItem struct {
-	ID        string `json:"id"`
-	Label     string `json:"label"`
-	IsAdmin   bool   `json:"isAdmin"`
-	CreatedAt string `json:"createdAt"`
+	ID             string `json:"id"`
+	Label          string `json:"label"`
+	IsAdmin        bool   `json:"isAdmin"`
+	CreatedAt      string `json:"createdAt"`
+	LastUsedAt     string `json:"lastUsedAt,omitempty"`
+	RequestCount   int64  `json:"requestCount"`
+	CreatedBy      string `json:"createdBy,omitempty"`
+	CreatedByEmail string `json:"createdByEmail,omitempty"`
 }
 
 type TenantAPIKeysResponse struct {
@@ -28,15 +34,51 @@ type TenantAPIKeysResponse struct {
 type TenantCreateAPIKeyRequest struct {
 	Label              string `json:"label"`
 	RateLimitPerMinute *int   `json:"rateLimitPerMinute,omitempty"`
+	// RevealOnce withholds the raw key from the response and returns a
+	// one-time reveal token instead (see tenantRevealAPIKeyHandler).
+	RevealOnce bool `json:"revealOnce,omitempty"`
 }
 
 type TenantCreateAPIKeyResponse struct {
+	Success         bool   `json:"success"`
+	Code            string `json:"code,omitempty"`
+	Error           string `json:"error,omitempty"`
+	ID              string `json:"id,omitempty"`
+	Key             string `json:"key,omitempty"`
+	RevealToken     string `json:"revealToken,omitempty"`
+	RevealExpiresAt string `json:"revealExpiresAt,omitempty"`
+}
+
+type TenantRevokeAPIKeysRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type TenantRevokeAPIKeysResponse struct {
+	Success  bool     `json:"success"`
+	Code     string   `json:"code,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Revoked  []string `json:"revoked,omitempty"`
+	NotFound []string `json:"notFound,omitempty"`
+}
+
+type TenantRevealAPIKeyRequest struct {
+	Token string `json:"token"`
+}
+
+type TenantRevealAPIKeyResponse struct {
 	Success bool   `json:"success"`
 	Code    string `json:"code,omitempty"`
 	Error   string `json:"error,omitempty"`
+	ID      string `json:"id,omitempty"`
 	Key     string `json:"key,omitempty"`
 }
 
+// apiKeyRevealTTL bounds how long a reveal-once token stays valid.
+const apiKeyRevealTTL = 15 * time.Minute
+
+// maxBulkRevokeKeys caps how many keys one bulk revoke request may name.
+const maxBulkRevokeKeys = 100
+
 // tenantCreateAPIKeyHandler creates a tenant-scoped API key for the given tenant.
 // System admins and tenant admins are allowed.
 func tenantCreateAPIKeyHandler(c *fiber.Ctx) error {
@@ -53,21 +95,9 @@ func tenantCreateAPIKeyHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	rawID := c.Params("id")
-	tenantID, err := uuid.Parse(rawID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(TenantCreateAPIKeyResponse{
-			Success: false,
-			Code:    "BAD_REQUEST",
-			Error:   "invalid tenant id",
-		})
-	}
-
-	// Enforce tenant admin (or system admin) for this tenant.
-	if !p.IsSystemAdmin {
-		if err := RequireTenantAdmin(c, p, tenantID.String()); err != nil {
-			return err
-		}
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
 	}
 
 	var req TenantCreateAPIKeyRequest
@@ -98,7 +128,7 @@ func tenantCreateAPIKeyHandler(c *fiber.Ctx) error {
 		}
 	}
 
-	raw, _, err := st.CreateRandomAPIKey(c.Context(), req.Label, false, rateLimit, func() *string {
+	raw, key, err := st.CreateRandomAPIKey(c.Context(), req.Label, false, rateLimit, func() *string {
 		s := tenantID.String()
 		return &s
 	}())
@@ -109,9 +139,30 @@ func tenantCreateAPIKeyHandler(c *fiber.Ctx) error {
 			Error:   err.Error(),
 		})
 	}
+	_ = st.SetAPIKeyCreatedBy(c.Context(), key.ID, *p.UserID)
+
+	if req.RevealOnce {
+		token, expiresAt, err := st.CreateAPIKeyReveal(c.Context(), key.ID, raw, apiKeyRevealTTL)
+		if err != nil {
+			// Don't strand the caller with a key they can never see.
+			_ = q.RevokeAPIKey(c.Context(), key.ID)
+			return c.Status(fiber.StatusInternalServerError).JSON(TenantCreateAPIKeyResponse{
+				Success: false,
+				Code:    "API_KEY_CREATE_FAILED",
+				Error:   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(TenantCreateAPIKeyResponse{
+			Success:         true,
+			ID:              key.ID.String(),
+			RevealToken:     token,
+			RevealExpiresAt: expiresAt.Format(time.RFC3339),
+		})
+	}
 
 	return c.Status(fiber.StatusOK).JSON(TenantCreateAPIKeyResponse{
 		Success: true,
+		ID:      key.ID.String(),
 		Key:     raw,
 	})
 }
@@ -131,21 +182,9 @@ func tenantListAPIKeysHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	rawID := c.Params("id")
-	tenantID, err := uuid.Parse(rawID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(TenantAPIKeysResponse{
-			Success: false,
-			Code:    "BAD_REQUEST",
-			Error:   "invalid tenant id",
-		})
-	}
-
-	// Enforce tenant admin (or system admin) for this tenant.
-	if !p.IsSystemAdmin {
-		if err := RequireTenantAdmin(c, p, tenantID.String()); err != nil {
-			return err
-		}
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
 	}
 
 	rows, err := q.ListAPIKeysByTenant(c.Context(), sql.NullString{String: tenantID.String(), Valid: true})
@@ -157,13 +196,30 @@ func tenantListAPIKeysHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	creatorEmails := make(map[uuid.UUID]string)
 	items := make([]TenantAPIKeyItem, 0, len(rows))
 	for _, k := range rows {
 		item := TenantAPIKeyItem{
-			ID:        k.ID.String(),
-			Label:     k.Label,
-			IsAdmin:   k.IsAdmin,
-			CreatedAt: k.CreatedAt.UTC().Format(time.RFC3339),
+			ID:           k.ID.String(),
+			Label:        k.Label,
+			IsAdmin:      k.IsAdmin,
+			CreatedAt:    k.CreatedAt.UTC().Format(time.RFC3339),
+			RequestCount: k.RequestCount,
+		}
+		if k.LastUsedAt.Valid {
+			item.LastUsedAt = k.LastUsedAt.Time.UTC().Format(time.RFC3339)
+		}
+		if k.CreatedByUserID.Valid {
+			uid := k.CreatedByUserID.UUID
+			email, ok := creatorEmails[uid]
+			if !ok {
+				if u, err := q.GetUserByID(c.Context(), uid); err == nil {
+					email = u.Email
+				}
+				creatorEmails[uid] = email
+			}
+			item.CreatedBy = uid.String()
+			item.CreatedByEmail = email
 		}
 		items = append(items, item)
 	}
@@ -189,14 +245,9 @@ func tenantRevokeAPIKeyHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	rawTenantID := c.Params("id")
-	tenantID, err := uuid.Parse(rawTenantID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Code:    "BAD_REQUEST",
-			Error:   "invalid tenant id",
-		})
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
 	}
 
 	rawKeyID := c.Params("keyID")
@@ -209,13 +260,6 @@ func tenantRevokeAPIKeyHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce tenant admin (or system admin) for this tenant.
-	if !p.IsSystemAdmin {
-		if err := RequireTenantAdmin(c, p, tenantID.String()); err != nil {
-			return err
-		}
-	}
-
 	// Ensure key belongs to this tenant before revoking.
 	rows, err := q.ListAPIKeysByTenant(c.Context(), sql.NullString{String: tenantID.String(), Valid: true})
 	if err != nil {
@@ -251,3 +295,142 @@ func tenantRevokeAPIKeyHandler(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true})
 }
+
+// tenantRevokeAPIKeysHandler revokes several tenant API keys at once.
+// Keys that are unknown, already revoked, or belong to another tenant are
+// reported in notFound.
+func tenantRevokeAPIKeysHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(TenantRevokeAPIKeysResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	var req TenantRevokeAPIKeysRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantRevokeAPIKeysResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if len(req.IDs) == 0 || len(req.IDs) > maxBulkRevokeKeys {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantRevokeAPIKeysResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   fmt.Sprintf("ids must contain between 1 and %d key ids", maxBulkRevokeKeys),
+		})
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(TenantRevokeAPIKeysResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "invalid key id: " + raw,
+			})
+		}
+		ids = append(ids, id)
+	}
+
+	revokedIDs, err := st.RevokeTenantAPIKeys(c.Context(), tenantID.String(), ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantRevokeAPIKeysResponse{
+			Success: false,
+			Code:    "API_KEY_REVOKE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	revokedSet := make(map[uuid.UUID]bool, len(revokedIDs))
+	revoked := make([]string, 0, len(revokedIDs))
+	for _, id := range revokedIDs {
+		revokedSet[id] = true
+		revoked = append(revoked, id.String())
+	}
+	var notFound []string
+	for _, id := range ids {
+		if !revokedSet[id] {
+			notFound = append(notFound, id.String())
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(TenantRevokeAPIKeysResponse{
+		Success:  true,
+		Revoked:  revoked,
+		NotFound: notFound,
+	})
+}
+
+// tenantRevealAPIKeyHandler exchanges a one-time reveal token from a
+// revealOnce key creation for the raw key. The token is consumed by the
+// first successful call.
+func tenantRevealAPIKeyHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.UserID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(TenantRevealAPIKeyResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	var req TenantRevealAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantRevealAPIKeyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantRevealAPIKeyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "token is required",
+		})
+	}
+
+	reveal, err := st.ConsumeAPIKeyReveal(c.Context(), req.Token, tenantID.String())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(TenantRevealAPIKeyResponse{
+				Success: false,
+				Code:    "NOT_FOUND",
+				Error:   "reveal token is invalid, expired, or already used",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantRevealAPIKeyResponse{
+			Success: false,
+			Code:    "API_KEY_REVEAL_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(TenantRevealAPIKeyResponse{
+		Success: true,
+		ID:      reveal.ApiKeyID.String(),
+		Key:     reveal.RawKey,
+	})
+}
@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
 	"raito/internal/config"
+	"raito/internal/db"
 	"raito/internal/store"
 )
 
@@ -181,3 +183,107 @@ func TestTenantRevokeAPIKey_InvalidIDs(t *testing.T) {
 		t.Fatalf("expected 400, got %d", resp.StatusCode)
 	}
 }
+
+// TestTenantRevokeAPIKeys_Validation ensures bulk revoke validates ids before DB usage.
+func TestTenantRevokeAPIKeys_Validation(t *testing.T) {
+	app := fiber.New()
+	st := &store.Store{}
+	cfg := &config.Config{}
+
+	app.Post("/v1/tenants/:id/api-keys/revoke", func(c *fiber.Ctx) error {
+		c.Locals("store", st)
+		c.Locals("config", cfg)
+		id := uuid.New()
+		p := Principal{UserID: &id, IsSystemAdmin: true}
+		c.Locals("principal", p)
+		return tenantRevokeAPIKeysHandler(c)
+	})
+
+	tenantID := uuid.New().String()
+	for _, body := range []string{`{}`, `{"ids":[]}`, `{"ids":["not-a-uuid"]}`} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/tenants/"+tenantID+"/api-keys/revoke", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("app.Test error: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("body %s: expected 400, got %d", body, resp.StatusCode)
+		}
+	}
+}
+
+// TestTenantRevealAPIKey_MissingToken ensures the reveal token is required.
+func TestTenantRevealAPIKey_MissingToken(t *testing.T) {
+	app := fiber.New()
+	st := &store.Store{}
+	cfg := &config.Config{}
+
+	app.Post("/v1/tenants/:id/api-keys/reveal", func(c *fiber.Ctx) error {
+		c.Locals("store", st)
+		c.Locals("config", cfg)
+		id := uuid.New()
+		p := Principal{UserID: &id, IsSystemAdmin: true}
+		c.Locals("principal", p)
+		return tenantRevealAPIKeyHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/tenants/"+uuid.New().String()+"/api-keys/reveal", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+// TestTenantAPIKeyHandlers_RequireTenantAdmin ensures tenant members are
+// refused before any key is created, listed, revoked, or revealed.
+func TestTenantAPIKeyHandlers_RequireTenantAdmin(t *testing.T) {
+	tenantID := uuid.New()
+	userID := uuid.New()
+	keyID := uuid.New()
+
+	routes := []struct {
+		method, path, body string
+		handler            fiber.Handler
+	}{
+		{http.MethodPost, "/v1/tenants/:id/api-keys", `{"label":"ci"}`, tenantCreateAPIKeyHandler},
+		{http.MethodGet, "/v1/tenants/:id/api-keys", "", tenantListAPIKeysHandler},
+		{http.MethodPost, "/v1/tenants/:id/api-keys/revoke", `{"ids":["` + keyID.String() + `"]}`, tenantRevokeAPIKeysHandler},
+		{http.MethodPost, "/v1/tenants/:id/api-keys/reveal", `{"token":"reveal-token"}`, tenantRevealAPIKeyHandler},
+		{http.MethodDelete, "/v1/tenants/:id/api-keys/:keyID", "", tenantRevokeAPIKeyHandler},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			fake, conn := newFakeDB(t, map[string][]any{
+				"GetTenantMember": {db.TenantMember{TenantID: tenantID, UserID: userID, Role: roleTenantMember}},
+			})
+			st := &store.Store{DB: conn}
+
+			app := fiber.New()
+			app.Add(route.method, route.path, func(c *fiber.Ctx) error {
+				c.Locals("store", st)
+				c.Locals("config", &config.Config{})
+				c.Locals("principal", Principal{UserID: &userID, TenantID: &tenantID, TenantRole: roleTenantMember})
+				return route.handler(c)
+			})
+
+			path := strings.NewReplacer(":id", tenantID.String(), ":keyID", keyID.String()).Replace(route.path)
+			req := httptest.NewRequest(route.method, path, strings.NewReader(route.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("app.Test error: %v", err)
+			}
+			if resp.StatusCode != http.StatusForbidden {
+				t.Fatalf("expected 403, got %d", resp.StatusCode)
+			}
+			if ran := fake.ran(); len(ran) != 1 || ran[0] != "GetTenantMember" {
+				t.Fatalf("expected only the membership lookup, got %v", ran)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/store"
+)
+
+// TenantSecretItem describes a stored secret. Values are write-only and
+// are never returned by the API.
+type TenantSecretItem struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
+
+type TenantSecretsResponse struct {
+	Success bool               `json:"success"`
+	Code    string             `json:"code,omitempty"`
+	Error   string             `json:"error,omitempty"`
+	Secrets []TenantSecretItem `json:"secrets,omitempty"`
+}
+
+type TenantSecretRequest struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+type TenantSecretResponse struct {
+	Success bool              `json:"success"`
+	Code    string            `json:"code,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Secret  *TenantSecretItem `json:"secret,omitempty"`
+}
+
+func tenantSecretItem(s db.TenantSecret) TenantSecretItem {
+	return TenantSecretItem{
+		Name:        s.Name,
+		Description: s.Description,
+		CreatedAt:   s.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   s.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// tenantRouteAccess resolves the tenant from the route and checks that the
+// caller is an admin of it, or a member when adminOnly is false. When ok is
+// false the error response has already been written.
+func tenantRouteAccess(c *fiber.Ctx, adminOnly bool) (p Principal, tenantID uuid.UUID, ok bool, err error) {
+	p, hasPrincipal := c.Locals("principal").(Principal)
+	if !hasPrincipal || (p.UserID == nil && !p.IsSystemAdmin) {
+		return p, tenantID, false, c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Success: false,
+			Code:    "UNAUTHENTICATED",
+			Error:   "User context is not available for this request",
+		})
+	}
+
+	tenantID, err = uuid.Parse(c.Params("id"))
+	if err != nil {
+		return p, tenantID, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "invalid tenant id",
+		})
+	}
+
+	check := RequireTenantAdmin
+	if !adminOnly {
+		check = RequireTenantMemberOrAdmin
+	}
+	if err := check(c, p, tenantID.String()); err != nil {
+		return p, tenantID, false, err
+	}
+	// The Require* helpers write their own error response and return nil.
+	if status := c.Response().StatusCode(); status != fiber.StatusOK {
+		return p, tenantID, false, nil
+	}
+
+	return p, tenantID, true, nil
+}
+
+// tenantListSecretsHandler lists a tenant's secret names and descriptions.
+func tenantListSecretsHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	rows, err := db.New(st.DB).ListTenantSecrets(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantSecretsResponse{
+			Success: false,
+			Code:    "SECRET_LIST_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	items := make([]TenantSecretItem, 0, len(rows))
+	for _, s := range rows {
+		items = append(items, tenantSecretItem(s))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(TenantSecretsResponse{
+		Success: true,
+		Secrets: items,
+	})
+}
+
+// tenantPutSecretHandler creates or replaces a tenant secret. The value is
+// encrypted with auth.secrets.encryptionKey before it is stored.
+func tenantPutSecretHandler(c *fiber.Ctx) error {
+	p, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	name := c.Params("name")
+	if !secretNamePattern.MatchString(name) {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantSecretResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "secret names must be 1-64 characters of letters, digits, '.', '_' or '-'",
+		})
+	}
+
+	var req TenantSecretRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantSecretResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if req.Value == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantSecretResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "value is required",
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+	sealed, err := sealSecret(cfg, tenantID, name, req.Value)
+	if err != nil {
+		if errors.Is(err, errSecretsNotConfigured) {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(TenantSecretResponse{
+				Success: false,
+				Code:    "SECRETS_NOT_CONFIGURED",
+				Error:   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantSecretResponse{
+			Success: false,
+			Code:    "SECRET_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	var createdBy uuid.NullUUID
+	if p.UserID != nil {
+		createdBy = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+
+	st := c.Locals("store").(*store.Store)
+	row, err := db.New(st.DB).UpsertTenantSecret(c.Context(), db.UpsertTenantSecretParams{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		Name:            name,
+		Description:     req.Description,
+		ValueEncrypted:  sealed,
+		CreatedByUserID: createdBy,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantSecretResponse{
+			Success: false,
+			Code:    "SECRET_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.secret.set", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant_secret",
+		ResourceID:   name,
+	})
+
+	item := tenantSecretItem(row)
+	return c.Status(fiber.StatusOK).JSON(TenantSecretResponse{
+		Success: true,
+		Secret:  &item,
+	})
+}
+
+// tenantDeleteSecretHandler removes a tenant secret. Jobs that still
+// reference it fail when they next fetch a page.
+func tenantDeleteSecretHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	name := c.Params("name")
+	st := c.Locals("store").(*store.Store)
+	n, err := db.New(st.DB).DeleteTenantSecret(c.Context(), db.DeleteTenantSecretParams{
+		TenantID: tenantID,
+		Name:     name,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantSecretResponse{
+			Success: false,
+			Code:    "SECRET_DELETE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if n == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(TenantSecretResponse{
+			Success: false,
+			Code:    "SECRET_NOT_FOUND",
+			Error:   "secret not found",
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.secret.delete", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant_secret",
+		ResourceID:   name,
+	})
+
+	return c.Status(fiber.StatusOK).JSON(TenantSecretResponse{Success: true})
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/notify"
+	"raito/internal/store"
+)
+
+// TenantWebhookItem describes a tenant's default webhook.
+type TenantWebhookItem struct {
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	UpdatedAt string   `json:"updatedAt"`
+}
+
+type TenantWebhookResponse struct {
+	Success bool               `json:"success"`
+	Code    string             `json:"code,omitempty"`
+	Error   string             `json:"error,omitempty"`
+	Webhook *TenantWebhookItem `json:"webhook,omitempty"`
+}
+
+// TenantWebhookRequest replaces a tenant's default webhook. Events
+// defaults to every notification event.
+type TenantWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+func tenantWebhookItem(row db.TenantWebhook) TenantWebhookItem {
+	var events []string
+	_ = json.Unmarshal(row.Events, &events)
+	return TenantWebhookItem{
+		URL:       row.Url,
+		Events:    events,
+		UpdatedAt: row.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// validateTenantWebhookRequest checks req and fills in defaults.
+func validateTenantWebhookRequest(req *TenantWebhookRequest) error {
+	u, ok := parseWebhookURL(req.URL)
+	if !ok {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	req.URL = u
+
+	if len(req.Events) == 0 {
+		req.Events = slices.Clone(notify.Events)
+		return nil
+	}
+	events := make([]string, 0, len(req.Events))
+	for _, ev := range req.Events {
+		ev = strings.ToLower(strings.TrimSpace(ev))
+		if !slices.Contains(notify.Events, ev) {
+			return errors.New("events must be one or more of " + strings.Join(notify.Events, ", "))
+		}
+		if !slices.Contains(events, ev) {
+			events = append(events, ev)
+		}
+	}
+	req.Events = events
+	return nil
+}
+
+// tenantGetWebhookHandler returns the tenant's default webhook.
+func tenantGetWebhookHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, false)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	row, err := db.New(st.DB).GetTenantWebhook(c.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(TenantWebhookResponse{
+				Success: false,
+				Code:    "WEBHOOK_NOT_FOUND",
+				Error:   "default webhook not configured",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookResponse{
+			Success: false,
+			Code:    "WEBHOOK_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	item := tenantWebhookItem(row)
+	return c.Status(fiber.StatusOK).JSON(TenantWebhookResponse{
+		Success: true,
+		Webhook: &item,
+	})
+}
+
+// tenantPutWebhookHandler creates or replaces the tenant's default
+// webhook. Jobs read it when they finish, so queued and running jobs
+// notify the new URL.
+func tenantPutWebhookHandler(c *fiber.Ctx) error {
+	p, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	var req TenantWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantWebhookResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if err := validateTenantWebhookRequest(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantWebhookResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+	events, err := json.Marshal(req.Events)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookResponse{
+			Success: false,
+			Code:    "WEBHOOK_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	st := c.Locals("store").(*store.Store)
+	params := db.UpsertTenantWebhookParams{
+		TenantID: tenantID,
+		Url:      req.URL,
+		Events:   events,
+	}
+	if p.UserID != nil {
+		params.UpdatedByUserID = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+	row, err := db.New(st.DB).UpsertTenantWebhook(c.Context(), params)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookResponse{
+			Success: false,
+			Code:    "WEBHOOK_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.webhook.set", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+		Metadata: map[string]any{
+			"url":    row.Url,
+			"events": req.Events,
+		},
+	})
+
+	item := tenantWebhookItem(row)
+	return c.Status(fiber.StatusOK).JSON(TenantWebhookResponse{
+		Success: true,
+		Webhook: &item,
+	})
+}
+
+// tenantDeleteWebhookHandler removes the tenant's default webhook.
+func tenantDeleteWebhookHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	n, err := db.New(st.DB).DeleteTenantWebhook(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookResponse{
+			Success: false,
+			Code:    "WEBHOOK_DELETE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if n == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(TenantWebhookResponse{
+			Success: false,
+			Code:    "WEBHOOK_NOT_FOUND",
+			Error:   "default webhook not configured",
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.webhook.delete", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+	})
+
+	return c.Status(fiber.StatusOK).JSON(TenantWebhookResponse{Success: true})
+}
@@ -0,0 +1,272 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/notify"
+	"raito/internal/secrets"
+	"raito/internal/store"
+)
+
+// TenantWebhookSecretItem describes a tenant's webhook signing secret.
+// Secret is only set in the response to a rotation.
+type TenantWebhookSecretItem struct {
+	Configured bool       `json:"configured"`
+	Secret     string     `json:"secret,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	RotatedAt  *time.Time `json:"rotatedAt,omitempty"`
+	// PreviousExpiresAt is when the secret replaced by the last rotation
+	// stops signing deliveries.
+	PreviousExpiresAt *time.Time `json:"previousExpiresAt,omitempty"`
+}
+
+type TenantWebhookSecretResponse struct {
+	Success       bool                     `json:"success"`
+	Code          string                   `json:"code,omitempty"`
+	Error         string                   `json:"error,omitempty"`
+	WebhookSecret *TenantWebhookSecretItem `json:"webhookSecret,omitempty"`
+}
+
+// TenantWebhookVerifyRequest is a sample delivery to check against the
+// tenant's signing secret. Without a signature, the server signs the
+// payload instead so receivers can test their verification code.
+type TenantWebhookVerifyRequest struct {
+	Payload   string `json:"payload"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+type TenantWebhookVerifyResponse struct {
+	Success   bool   `json:"success"`
+	Code      string `json:"code,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Valid     bool   `json:"valid"`
+	Reason    string `json:"reason,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	// ReplayWindowSeconds is the timestamp tolerance receivers should
+	// apply.
+	ReplayWindowSeconds int `json:"replayWindowSeconds"`
+}
+
+func tenantWebhookSecretItem(row db.TenantWebhookSecret, now time.Time) *TenantWebhookSecretItem {
+	created := row.CreatedAt.UTC()
+	rotated := row.RotatedAt.UTC()
+	item := &TenantWebhookSecretItem{Configured: true, CreatedAt: &created, RotatedAt: &rotated}
+	if row.PreviousExpiresAt.Valid && now.Before(row.PreviousExpiresAt.Time) {
+		t := row.PreviousExpiresAt.Time.UTC()
+		item.PreviousExpiresAt = &t
+	}
+	return item
+}
+
+// tenantGetWebhookSecretHandler reports whether the tenant signs webhook
+// deliveries. The secret itself is never returned here.
+func tenantGetWebhookSecretHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	row, err := db.New(st.DB).GetTenantWebhookSecret(c.Context(), tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusOK).JSON(TenantWebhookSecretResponse{
+			Success:       true,
+			WebhookSecret: &TenantWebhookSecretItem{},
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookSecretResponse{
+			Success: false,
+			Code:    "WEBHOOK_SECRET_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(TenantWebhookSecretResponse{
+		Success:       true,
+		WebhookSecret: tenantWebhookSecretItem(row, time.Now()),
+	})
+}
+
+// tenantRotateWebhookSecretHandler generates a new signing secret and
+// returns it once. The replaced secret keeps signing deliveries for
+// notifications.webhookSecretGraceHours.
+func tenantRotateWebhookSecretHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+	secret, err := notify.NewSigningSecret()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookSecretResponse{
+			Success: false,
+			Code:    "WEBHOOK_SECRET_ROTATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	sealed, err := secrets.Seal(cfg, tenantID, notify.SigningSecretName, secret)
+	if err != nil {
+		if errors.Is(err, secrets.ErrNotConfigured) {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(TenantWebhookSecretResponse{
+				Success: false,
+				Code:    "SECRETS_NOT_CONFIGURED",
+				Error:   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookSecretResponse{
+			Success: false,
+			Code:    "WEBHOOK_SECRET_ROTATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	st := c.Locals("store").(*store.Store)
+	row, err := db.New(st.DB).RotateTenantWebhookSecret(c.Context(), db.RotateTenantWebhookSecretParams{
+		TenantID:          tenantID,
+		SecretEncrypted:   sealed,
+		PreviousExpiresAt: sql.NullTime{Time: now.Add(notify.SecretGrace(cfg.Notifications)), Valid: true},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookSecretResponse{
+			Success: false,
+			Code:    "WEBHOOK_SECRET_ROTATE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.webhook_secret.rotate", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+	})
+
+	item := tenantWebhookSecretItem(row, now)
+	item.Secret = secret
+	return c.Status(fiber.StatusOK).JSON(TenantWebhookSecretResponse{
+		Success:       true,
+		WebhookSecret: item,
+	})
+}
+
+// tenantDeleteWebhookSecretHandler removes the tenant's signing secret;
+// later deliveries are sent unsigned.
+func tenantDeleteWebhookSecretHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	n, err := db.New(st.DB).DeleteTenantWebhookSecret(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TenantWebhookSecretResponse{
+			Success: false,
+			Code:    "WEBHOOK_SECRET_DELETE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if n == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(TenantWebhookSecretResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "tenant has no webhook signing secret",
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.webhook_secret.delete", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+	})
+
+	return c.Status(fiber.StatusOK).JSON(TenantWebhookSecretResponse{
+		Success:       true,
+		WebhookSecret: &TenantWebhookSecretItem{},
+	})
+}
+
+// tenantVerifyWebhookSignatureHandler checks a sample payload against the
+// tenant's signing secrets, applying the same replay window
+// receivers should use. Without a signature it returns the timestamp and
+// signature a delivery of the payload would carry now.
+func tenantVerifyWebhookSignatureHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	var req TenantWebhookVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantWebhookVerifyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if req.Signature != "" && req.Timestamp == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(TenantWebhookVerifyResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   "timestamp is required with signature",
+		})
+	}
+
+	cfg := c.Locals("config").(*config.Config)
+	st := c.Locals("store").(*store.Store)
+	now := time.Now()
+	signing, err := notify.TenantSigningSecrets(c.Context(), cfg, db.New(st.DB), tenantID, now)
+	if err != nil {
+		status, code := fiber.StatusInternalServerError, "WEBHOOK_SECRET_UNAVAILABLE"
+		if errors.Is(err, secrets.ErrNotConfigured) {
+			status, code = fiber.StatusServiceUnavailable, "SECRETS_NOT_CONFIGURED"
+		}
+		return c.Status(status).JSON(TenantWebhookVerifyResponse{
+			Success: false,
+			Code:    code,
+			Error:   err.Error(),
+		})
+	}
+	if len(signing) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(TenantWebhookVerifyResponse{
+			Success: false,
+			Code:    "NOT_FOUND",
+			Error:   "tenant has no webhook signing secret",
+		})
+	}
+
+	window := notify.ReplayWindow(cfg.Notifications)
+	resp := TenantWebhookVerifyResponse{
+		Success:             true,
+		ReplayWindowSeconds: int(window / time.Second),
+	}
+	if req.Signature == "" {
+		resp.Valid = true
+		resp.Timestamp = now.Unix()
+		resp.Signature = notify.SignatureHeader(signing[:1], resp.Timestamp, []byte(req.Payload))
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+
+	resp.Timestamp = req.Timestamp
+	for _, secret := range signing {
+		err = notify.Verify(secret, req.Signature, req.Timestamp, []byte(req.Payload), now, window)
+		if err == nil {
+			resp.Valid = true
+			break
+		}
+	}
+	if !resp.Valid {
+		resp.Reason = err.Error()
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
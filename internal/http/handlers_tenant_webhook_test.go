@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+	"raito/internal/store"
+)
+
+func TestValidateTenantWebhookRequest(t *testing.T) {
+	req := TenantWebhookRequest{URL: " https://hooks.example.com/raito "}
+	if err := validateTenantWebhookRequest(&req); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if req.URL != "https://hooks.example.com/raito" || !reflect.DeepEqual(req.Events, []string{"job.completed", "job.failed"}) {
+		t.Fatalf("expected the trimmed URL and every event, got %+v", req)
+	}
+
+	req = TenantWebhookRequest{URL: "https://hooks.example.com/raito", Events: []string{"JOB.FAILED", "job.failed"}}
+	if err := validateTenantWebhookRequest(&req); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !reflect.DeepEqual(req.Events, []string{"job.failed"}) {
+		t.Fatalf("expected events to be normalized, got %v", req.Events)
+	}
+
+	bad := []TenantWebhookRequest{
+		{},
+		{URL: "ftp://example.com"},
+		{URL: "/relative"},
+		{URL: "https://example.com", Events: []string{"job.started"}},
+	}
+	for _, r := range bad {
+		if err := validateTenantWebhookRequest(&r); err == nil {
+			t.Fatalf("expected %+v to be rejected", r)
+		}
+	}
+}
+
+func TestTenantWebhook_Unauthenticated(t *testing.T) {
+	app := fiber.New()
+	app.Put("/v1/tenants/:id/webhook", func(c *fiber.Ctx) error {
+		c.Locals("store", &store.Store{})
+		c.Locals("config", &config.Config{})
+		return tenantPutWebhookHandler(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/tenants/00000000-0000-0000-0000-000000000000/webhook", strings.NewReader(`{"url":"https://hooks.example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+}
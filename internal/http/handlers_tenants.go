@@ -159,8 +159,13 @@ func selectTenantHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Re-issue session cookie with new tenant ID when sessions are enabled.
-	_ = issueSessionCookie(c, cfg, *p.UserID, &tid, p.IsSystemAdmin)
+	// Re-issue (and rotate) the session cookie with the new tenant ID when
+	// sessions are enabled, keeping the remember-me choice.
+	rememberMe := false
+	if claims, ok := c.Locals("session").(*sessionClaims); ok && claims != nil {
+		rememberMe = claims.RememberMe
+	}
+	_ = issueSessionCookie(c, cfg, *p.UserID, &tid, p.IsSystemAdmin, rememberMe)
 
 	return c.Status(fiber.StatusOK).JSON(SelectTenantResponse{
 		Success: true,
@@ -416,21 +421,9 @@ func tenantAddMemberHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	rawID := c.Params("id")
-	tenantID, err := uuid.Parse(rawID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Code:    "BAD_REQUEST",
-			Error:   "invalid tenant id",
-		})
-	}
-
-	// Enforce tenant admin (or system admin) for this tenant.
-	if !p.IsSystemAdmin {
-		if err := RequireTenantAdmin(c, p, tenantID.String()); err != nil {
-			return err
-		}
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
 	}
 
 	var req TenantMemberRequest
@@ -452,11 +445,11 @@ func tenantAddMemberHandler(c *fiber.Ctx) error {
 	}
 
 	role := strings.ToLower(strings.TrimSpace(req.Role))
-	if role != "tenant_admin" && role != "tenant_member" {
+	if !isTenantRole(role) {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Success: false,
 			Code:    "BAD_REQUEST",
-			Error:   "role must be 'tenant_admin' or 'tenant_member'",
+			Error:   "role must be 'tenant_admin', 'tenant_member', or 'tenant_viewer'",
 		})
 	}
 
@@ -490,14 +483,9 @@ func tenantUpdateMemberHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	rawID := c.Params("id")
-	tenantID, err := uuid.Parse(rawID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Code:    "BAD_REQUEST",
-			Error:   "invalid tenant id",
-		})
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
 	}
 
 	rawUserID := c.Params("userID")
@@ -510,13 +498,6 @@ func tenantUpdateMemberHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce tenant admin (or system admin) for this tenant.
-	if !p.IsSystemAdmin {
-		if err := RequireTenantAdmin(c, p, tenantID.String()); err != nil {
-			return err
-		}
-	}
-
 	var req TenantMemberRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
@@ -527,11 +508,11 @@ func tenantUpdateMemberHandler(c *fiber.Ctx) error {
 	}
 
 	role := strings.ToLower(strings.TrimSpace(req.Role))
-	if role != "tenant_admin" && role != "tenant_member" {
+	if !isTenantRole(role) {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Success: false,
 			Code:    "BAD_REQUEST",
-			Error:   "role must be 'tenant_admin' or 'tenant_member'",
+			Error:   "role must be 'tenant_admin', 'tenant_member', or 'tenant_viewer'",
 		})
 	}
 
@@ -572,14 +553,9 @@ func tenantRemoveMemberHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	rawID := c.Params("id")
-	tenantID, err := uuid.Parse(rawID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Code:    "BAD_REQUEST",
-			Error:   "invalid tenant id",
-		})
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
 	}
 
 	rawUserID := c.Params("userID")
@@ -592,13 +568,6 @@ func tenantRemoveMemberHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Enforce tenant admin (or system admin) for this tenant.
-	if !p.IsSystemAdmin {
-		if err := RequireTenantAdmin(c, p, tenantID.String()); err != nil {
-			return err
-		}
-	}
-
 	if err := q.RemoveTenantMember(c.Context(), db.RemoveTenantMemberParams{
 		TenantID: tenantID,
 		UserID:   userID,
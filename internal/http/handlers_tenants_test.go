@@ -3,12 +3,14 @@ package http
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
 	"raito/internal/config"
+	"raito/internal/db"
 	"raito/internal/store"
 )
 
@@ -119,3 +121,50 @@ func TestTenantAddMember_InvalidTenantID(t *testing.T) {
 		t.Fatalf("expected 400, got %d", resp.StatusCode)
 	}
 }
+
+// TestTenantMemberHandlers_RequireTenantAdmin ensures tenant members cannot
+// add, change, or remove members.
+func TestTenantMemberHandlers_RequireTenantAdmin(t *testing.T) {
+	tenantID := uuid.New()
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	routes := []struct {
+		method, path, body string
+		handler            fiber.Handler
+	}{
+		{http.MethodPost, "/v1/tenants/:id/members", `{"userId":"` + otherID.String() + `","role":"tenant_admin"}`, tenantAddMemberHandler},
+		{http.MethodPatch, "/v1/tenants/:id/members/:userID", `{"role":"tenant_admin"}`, tenantUpdateMemberHandler},
+		{http.MethodDelete, "/v1/tenants/:id/members/:userID", "", tenantRemoveMemberHandler},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			fake, conn := newFakeDB(t, map[string][]any{
+				"GetTenantMember": {db.TenantMember{TenantID: tenantID, UserID: userID, Role: roleTenantMember}},
+			})
+			st := &store.Store{DB: conn}
+
+			app := fiber.New()
+			app.Add(route.method, route.path, func(c *fiber.Ctx) error {
+				c.Locals("store", st)
+				c.Locals("config", &config.Config{})
+				c.Locals("principal", Principal{UserID: &userID, TenantID: &tenantID, TenantRole: roleTenantMember})
+				return route.handler(c)
+			})
+
+			path := strings.NewReplacer(":id", tenantID.String(), ":userID", otherID.String()).Replace(route.path)
+			req := httptest.NewRequest(route.method, path, strings.NewReader(route.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("app.Test error: %v", err)
+			}
+			if resp.StatusCode != http.StatusForbidden {
+				t.Fatalf("expected 403, got %d", resp.StatusCode)
+			}
+			if ran := fake.ran(); len(ran) != 1 || ran[0] != "GetTenantMember" {
+				t.Fatalf("expected only the membership lookup, got %v", ran)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/scraper"
+	"raito/internal/store"
+)
+
+// TransformHookItem describes a tenant's transform hook.
+type TransformHookItem struct {
+	URL           string `json:"url"`
+	TimeoutMs     int    `json:"timeoutMs"`
+	FailurePolicy string `json:"failurePolicy"`
+	SigningSecret string `json:"signingSecret,omitempty"`
+	Enabled       bool   `json:"enabled"`
+	UpdatedAt     string `json:"updatedAt"`
+}
+
+type TransformHookResponse struct {
+	Success bool               `json:"success"`
+	Code    string             `json:"code,omitempty"`
+	Error   string             `json:"error,omitempty"`
+	Hook    *TransformHookItem `json:"hook,omitempty"`
+}
+
+// TransformHookRequest replaces a tenant's transform hook.
+type TransformHookRequest struct {
+	URL           string `json:"url"`
+	TimeoutMs     *int   `json:"timeoutMs,omitempty"`
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+	// SigningSecret names a tenant secret used to sign hook requests.
+	SigningSecret string `json:"signingSecret,omitempty"`
+	Enabled       *bool  `json:"enabled,omitempty"`
+}
+
+func transformHookItem(h db.TenantTransformHook) TransformHookItem {
+	return TransformHookItem{
+		URL:           h.Url,
+		TimeoutMs:     int(h.TimeoutMs),
+		FailurePolicy: h.FailurePolicy,
+		SigningSecret: h.SigningSecret.String,
+		Enabled:       h.Enabled,
+		UpdatedAt:     h.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// validateTransformHookRequest checks req and fills in defaults.
+func validateTransformHookRequest(req *TransformHookRequest) error {
+	u, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	req.URL = u.String()
+
+	if req.TimeoutMs == nil {
+		ms := int(defaultTransformHookTimeout.Milliseconds())
+		req.TimeoutMs = &ms
+	}
+	if *req.TimeoutMs <= 0 || *req.TimeoutMs > int(maxTransformHookTimeout.Milliseconds()) {
+		return errors.New("timeoutMs must be between 1 and 30000")
+	}
+
+	req.FailurePolicy = strings.ToLower(strings.TrimSpace(req.FailurePolicy))
+	if req.FailurePolicy == "" {
+		req.FailurePolicy = transformPolicyKeep
+	}
+	if !validTransformPolicy(req.FailurePolicy) {
+		return errors.New("failurePolicy must be one of keep, drop, fail")
+	}
+
+	if req.SigningSecret != "" && !secretNamePattern.MatchString(req.SigningSecret) {
+		return errors.New("signingSecret must name a tenant secret")
+	}
+	return nil
+}
+
+// tenantGetTransformHookHandler returns the tenant's transform hook.
+func tenantGetTransformHookHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, false)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	row, err := db.New(st.DB).GetTenantTransformHook(c.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(TransformHookResponse{
+				Success: false,
+				Code:    "TRANSFORM_HOOK_NOT_FOUND",
+				Error:   "transform hook not configured",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(TransformHookResponse{
+			Success: false,
+			Code:    "TRANSFORM_HOOK_LOOKUP_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	item := transformHookItem(row)
+	return c.Status(fiber.StatusOK).JSON(TransformHookResponse{
+		Success: true,
+		Hook:    &item,
+	})
+}
+
+// tenantPutTransformHookHandler creates or replaces the tenant's transform
+// hook. Jobs already running keep the hook they loaded when they started.
+func tenantPutTransformHookHandler(c *fiber.Ctx) error {
+	p, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	var req TransformHookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(TransformHookResponse{
+			Success: false,
+			Code:    "BAD_REQUEST_INVALID_JSON",
+			Error:   "Bad request, malformed JSON",
+		})
+	}
+	if err := validateTransformHookRequest(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(TransformHookResponse{
+			Success: false,
+			Code:    "BAD_REQUEST",
+			Error:   err.Error(),
+		})
+	}
+	cfg := c.Locals("config").(*config.Config)
+	if !cfg.Scraper.AllowPrivateNetworks {
+		u, _ := url.Parse(req.URL)
+		if err := scraper.CheckPublicHost(c.Context(), u.Hostname()); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(TransformHookResponse{
+				Success: false,
+				Code:    "BAD_REQUEST",
+				Error:   "url must point at a public address: " + err.Error(),
+			})
+		}
+	}
+
+	st := c.Locals("store").(*store.Store)
+	q := db.New(st.DB)
+
+	params := db.UpsertTenantTransformHookParams{
+		TenantID:      tenantID,
+		Url:           req.URL,
+		TimeoutMs:     int32(*req.TimeoutMs),
+		FailurePolicy: req.FailurePolicy,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+	}
+	if req.SigningSecret != "" {
+		// Only check that the secret exists; it is decrypted when a job
+		// loads the hook.
+		if _, err := q.GetTenantSecretByName(c.Context(), db.GetTenantSecretByNameParams{TenantID: tenantID, Name: req.SigningSecret}); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return c.Status(fiber.StatusBadRequest).JSON(TransformHookResponse{
+					Success: false,
+					Code:    "SECRET_NOT_FOUND",
+					Error:   "signingSecret " + req.SigningSecret + " does not exist",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(TransformHookResponse{
+				Success: false,
+				Code:    "TRANSFORM_HOOK_SAVE_FAILED",
+				Error:   err.Error(),
+			})
+		}
+		params.SigningSecret = sql.NullString{String: req.SigningSecret, Valid: true}
+	}
+	if p.UserID != nil {
+		params.UpdatedByUserID = uuid.NullUUID{UUID: *p.UserID, Valid: true}
+	}
+
+	row, err := q.UpsertTenantTransformHook(c.Context(), params)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TransformHookResponse{
+			Success: false,
+			Code:    "TRANSFORM_HOOK_SAVE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.transform_hook.set", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+		Metadata: map[string]any{
+			"url":           row.Url,
+			"failurePolicy": row.FailurePolicy,
+			"enabled":       row.Enabled,
+		},
+	})
+
+	item := transformHookItem(row)
+	return c.Status(fiber.StatusOK).JSON(TransformHookResponse{
+		Success: true,
+		Hook:    &item,
+	})
+}
+
+// tenantDeleteTransformHookHandler removes the tenant's transform hook.
+func tenantDeleteTransformHookHandler(c *fiber.Ctx) error {
+	_, tenantID, ok, err := tenantRouteAccess(c, true)
+	if !ok {
+		return err
+	}
+
+	st := c.Locals("store").(*store.Store)
+	n, err := db.New(st.DB).DeleteTenantTransformHook(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(TransformHookResponse{
+			Success: false,
+			Code:    "TRANSFORM_HOOK_DELETE_FAILED",
+			Error:   err.Error(),
+		})
+	}
+	if n == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(TransformHookResponse{
+			Success: false,
+			Code:    "TRANSFORM_HOOK_NOT_FOUND",
+			Error:   "transform hook not configured",
+		})
+	}
+
+	recordAuditEvent(c, st, "tenant.transform_hook.delete", auditEventOptions{
+		TenantID:     &tenantID,
+		ResourceType: "tenant",
+		ResourceID:   tenantID.String(),
+	})
+
+	return c.Status(fiber.StatusOK).JSON(TransformHookResponse{Success: true})
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/crawler"
+	"raito/internal/dnscache"
+)
+
+// robotsDelayTTL is how long a host's robots.txt Crawl-delay is cached.
+const robotsDelayTTL = time.Hour
+
+var (
+	hostLimiterOnce sync.Once
+	hostLimiter     *crawler.HostLimiter
+
+	hostPacer = crawler.NewHostPacer()
+
+	robotsDelaysMu sync.Mutex
+	robotsDelays   = map[string]robotsDelay{}
+)
+
+type robotsDelay struct {
+	delay     time.Duration
+	fetchedAt time.Time
+}
+
+// sharedHostLimiter returns the process-wide adaptive per-host limiter, or
+// nil when adaptive concurrency is disabled. Per-host limits are shared
+// across jobs so concurrent crawls of the same site back off together.
+func sharedHostLimiter(cfg *config.Config) *crawler.HostLimiter {
+	ac := cfg.Worker.AdaptiveConcurrency
+	if !ac.Enabled {
+		return nil
+	}
+	hostLimiterOnce.Do(func() {
+		hostLimiter = crawler.NewHostLimiter(crawler.HostLimiterOptions{
+			InitialPerHost: ac.InitialPerHost,
+			MaxPerHost:     ac.MaxPerHost,
+			TargetLatency:  time.Duration(ac.TargetLatencyMs) * time.Millisecond,
+		})
+	})
+	return hostLimiter
+}
+
+// urlConcurrency returns how many URLs a crawl or batch scrape job may
+// process at once. With adaptive concurrency enabled the job ceiling is
+// maxPerHost and the per-host limiter decides the effective concurrency.
+func urlConcurrency(cfg *config.Config) int {
+	if ac := cfg.Worker.AdaptiveConcurrency; ac.Enabled {
+		if ac.MaxPerHost > 0 {
+			return ac.MaxPerHost
+		}
+		return crawler.DefaultMaxPerHost
+	}
+	if cfg.Worker.MaxConcurrentURLsPerJob > 0 {
+		return cfg.Worker.MaxConcurrentURLsPerJob
+	}
+	return 1
+}
+
+// hostCrawlDelay returns the time to leave between requests to target's
+// host: its robots.txt Crawl-delay, cached for robotsDelayTTL, within the
+// robots.minCrawlDelayMs and robots.maxCrawlDelayMs bounds.
+func hostCrawlDelay(ctx context.Context, cfg *config.Config, target string) time.Duration {
+	if !cfg.Robots.Respect {
+		return cfg.CrawlDelay(0)
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return cfg.CrawlDelay(0)
+	}
+	host := crawler.HostKey(target)
+
+	robotsDelaysMu.Lock()
+	cached, ok := robotsDelays[host]
+	robotsDelaysMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < robotsDelayTTL {
+		return cfg.CrawlDelay(cached.delay)
+	}
+
+	client := &http.Client{Timeout: time.Duration(cfg.Scraper.TimeoutMs) * time.Millisecond, Transport: dnscache.Transport()}
+	delay := crawler.RobotsCrawlDelay(ctx, client, u, cfg.RequestUserAgent(), cfg.RobotsAgent())
+	if ctx.Err() == nil {
+		robotsDelaysMu.Lock()
+		robotsDelays[host] = robotsDelay{delay: delay, fetchedAt: time.Now()}
+		robotsDelaysMu.Unlock()
+	}
+	return cfg.CrawlDelay(delay)
+}
+
+// acquireHost waits for url's host to be due under its crawl delay and
+// then for a per-host slot. The returned function records the scrape
+// outcome in the host statistics and reports it back to the limiter when
+// adaptive concurrency is enabled.
+func acquireHost(ctx context.Context, cfg *config.Config, limiter *crawler.HostLimiter, url string) (func(status int, err error), error) {
+	if err := hostPacer.Wait(ctx, crawler.HostKey(url), hostCrawlDelay(ctx, cfg, url)); err != nil {
+		return nil, err
+	}
+	if limiter == nil {
+		start := time.Now()
+		return func(status int, err error) {
+			recordHostScrape(url, status, time.Since(start), err)
+		}, nil
+	}
+	release, err := limiter.Acquire(ctx, crawler.HostKey(url))
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	return func(status int, err error) {
+		latency := time.Since(start)
+		recordHostScrape(url, status, latency, err)
+		release(status, latency, err)
+	}, nil
+}
@@ -0,0 +1,304 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"raito/internal/crawler"
+	"raito/internal/db"
+	"raito/internal/metrics"
+	"raito/internal/scraper"
+	"raito/internal/store"
+)
+
+const (
+	// hostStatsFlushInterval is how often recorded outcomes are added to
+	// the host_stats table.
+	hostStatsFlushInterval = 30 * time.Second
+	// hostStatsRetention is how long hourly host_stats buckets are kept.
+	hostStatsRetention = 7 * 24 * time.Hour
+	// maxPendingHostStats caps the (host, hour) entries held between
+	// flushes, so an unreachable database cannot grow them without bound.
+	maxPendingHostStats = 10000
+)
+
+// hostLatencyBoundsMs are the upper bounds of the host_stats latency_*
+// columns in milliseconds; a last column counts slower requests.
+var hostLatencyBoundsMs = [...]int64{100, 250, 500, 1000, 2500, 5000, 10000}
+
+type hostStatsKey struct {
+	host   string
+	bucket time.Time
+}
+
+// hostStatsDelta holds the outcomes recorded for one host and hour since
+// the last flush.
+type hostStatsDelta struct {
+	requests      int64
+	statuses      [4]int64 // 2xx, 3xx, 4xx, 5xx
+	errors        int64
+	tlsErrors     int64
+	timeouts      int64
+	dnsErrors     int64
+	robotsBlocked int64
+	latencyMsSum  int64
+	latency       [len(hostLatencyBoundsMs) + 1]int64
+	lastSeen      time.Time
+}
+
+func (d *hostStatsDelta) add(o *hostStatsDelta) {
+	d.requests += o.requests
+	for i := range d.statuses {
+		d.statuses[i] += o.statuses[i]
+	}
+	d.errors += o.errors
+	d.tlsErrors += o.tlsErrors
+	d.timeouts += o.timeouts
+	d.dnsErrors += o.dnsErrors
+	d.robotsBlocked += o.robotsBlocked
+	d.latencyMsSum += o.latencyMsSum
+	for i := range d.latency {
+		d.latency[i] += o.latency[i]
+	}
+	if o.lastSeen.After(d.lastSeen) {
+		d.lastSeen = o.lastSeen
+	}
+}
+
+var (
+	hostStatsOnce sync.Once
+	// hostStatsActive is set once a flusher runs; outcomes are only kept
+	// for the table from then on.
+	hostStatsActive atomic.Bool
+
+	hostStatsMu     sync.Mutex
+	hostStatsDeltas = map[hostStatsKey]*hostStatsDelta{}
+)
+
+// hostStatsEntry returns the pending delta for host in the hour of now,
+// or nil when maxPendingHostStats entries are already pending.
+// hostStatsMu must be held.
+func hostStatsEntry(host string, now time.Time) *hostStatsDelta {
+	key := hostStatsKey{host: host, bucket: now.UTC().Truncate(time.Hour)}
+	d, ok := hostStatsDeltas[key]
+	if !ok {
+		if len(hostStatsDeltas) >= maxPendingHostStats {
+			return nil
+		}
+		d = &hostStatsDelta{}
+		hostStatsDeltas[key] = d
+	}
+	d.lastSeen = now
+	return d
+}
+
+// recordHostScrape records the outcome of a request to rawURL's host: the
+// response status, or err when no response was received.
+func recordHostScrape(rawURL string, status int, latency time.Duration, err error) {
+	host := crawler.HostKey(rawURL)
+	if host == "" {
+		return
+	}
+	outcome := hostOutcome(status, err)
+	metrics.RecordHostRequest(host, outcome, latency)
+	if !hostStatsActive.Load() {
+		return
+	}
+
+	hostStatsMu.Lock()
+	defer hostStatsMu.Unlock()
+	d := hostStatsEntry(host, time.Now())
+	if d == nil {
+		return
+	}
+	d.requests++
+	switch outcome {
+	case "2xx", "3xx", "4xx", "5xx":
+		d.statuses[outcome[0]-'2']++
+	case "tls":
+		d.errors++
+		d.tlsErrors++
+	case "timeout":
+		d.errors++
+		d.timeouts++
+	case "dns":
+		d.errors++
+		d.dnsErrors++
+	case "error":
+		d.errors++
+	}
+	ms := latency.Milliseconds()
+	d.latencyMsSum += ms
+	i := 0
+	for i < len(hostLatencyBoundsMs) && ms > hostLatencyBoundsMs[i] {
+		i++
+	}
+	d.latency[i]++
+}
+
+// recordHostRobotsBlock records a URL skipped because its host's
+// robots.txt disallows it.
+func recordHostRobotsBlock(rawURL string) {
+	host := crawler.HostKey(rawURL)
+	if host == "" {
+		return
+	}
+	metrics.RecordHostRobotsBlock(host)
+	if !hostStatsActive.Load() {
+		return
+	}
+
+	hostStatsMu.Lock()
+	defer hostStatsMu.Unlock()
+	if d := hostStatsEntry(host, time.Now()); d != nil {
+		d.robotsBlocked++
+	}
+}
+
+// hostOutcome labels a request outcome: the status class, or the kind of
+// error when the request failed.
+func hostOutcome(status int, err error) string {
+	if err != nil {
+		return classifyHostError(err)
+	}
+	if status >= 200 && status < 600 {
+		return string(rune('0'+status/100)) + "xx"
+	}
+	return "other"
+}
+
+// classifyHostError sorts a failed request into "tls", "timeout", "dns"
+// or "error". Browser engines only report errors as text, so the message
+// is checked when the error chain has no typed cause.
+func classifyHostError(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return "tls"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "tls:"), strings.Contains(msg, "x509:"),
+		strings.Contains(msg, "err_cert_"), strings.Contains(msg, "err_ssl_"):
+		return "tls"
+	case strings.Contains(msg, "no such host"), strings.Contains(msg, "err_name_not_resolved"):
+		return "dns"
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "deadline exceeded"),
+		strings.Contains(msg, "err_timed_out"):
+		return "timeout"
+	}
+	return "error"
+}
+
+// startHostStatsFlusher starts recording host outcomes for the host_stats
+// table and adds them to it every hostStatsFlushInterval. Only the first
+// call in a process starts a flusher.
+func startHostStatsFlusher(ctx context.Context, st *store.Store) {
+	if st == nil || st.DB == nil {
+		return
+	}
+	hostStatsOnce.Do(func() {
+		hostStatsActive.Store(true)
+		go runHostStatsFlusher(ctx, db.New(st.DB))
+	})
+}
+
+func runHostStatsFlusher(ctx context.Context, q *db.Queries) {
+	ticker := time.NewTicker(hostStatsFlushInterval)
+	defer ticker.Stop()
+
+	var lastPrune time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			flushHostStats(context.Background(), q)
+			return
+		case <-ticker.C:
+		}
+		flushHostStats(ctx, q)
+		if now := time.Now(); now.Sub(lastPrune) >= time.Hour {
+			_, _ = q.DeleteHostStatsBefore(ctx, now.UTC().Add(-hostStatsRetention))
+			lastPrune = now
+		}
+	}
+}
+
+// flushHostStats adds the pending deltas to host_stats. Deltas that fail
+// to write are kept for the next flush.
+func flushHostStats(ctx context.Context, q *db.Queries) {
+	hostStatsMu.Lock()
+	pending := hostStatsDeltas
+	hostStatsDeltas = map[hostStatsKey]*hostStatsDelta{}
+	hostStatsMu.Unlock()
+
+	for key, d := range pending {
+		err := q.AddHostStats(ctx, db.AddHostStatsParams{
+			Host:           key.host,
+			Bucket:         key.bucket,
+			Requests:       d.requests,
+			Status2xx:      d.statuses[0],
+			Status3xx:      d.statuses[1],
+			Status4xx:      d.statuses[2],
+			Status5xx:      d.statuses[3],
+			Errors:         d.errors,
+			TlsErrors:      d.tlsErrors,
+			Timeouts:       d.timeouts,
+			DnsErrors:      d.dnsErrors,
+			RobotsBlocked:  d.robotsBlocked,
+			LatencyMsSum:   d.latencyMsSum,
+			Latency100ms:   d.latency[0],
+			Latency250ms:   d.latency[1],
+			Latency500ms:   d.latency[2],
+			Latency1s:      d.latency[3],
+			Latency2500ms:  d.latency[4],
+			Latency5s:      d.latency[5],
+			Latency10s:     d.latency[6],
+			LatencyOver10s: d.latency[7],
+			LastSeenAt:     d.lastSeen,
+		})
+		if err == nil {
+			delete(pending, key)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	hostStatsMu.Lock()
+	defer hostStatsMu.Unlock()
+	for key, d := range pending {
+		if cur, ok := hostStatsDeltas[key]; ok {
+			cur.add(d)
+		} else if len(hostStatsDeltas) < maxPendingHostStats {
+			hostStatsDeltas[key] = d
+		}
+	}
+}
+
+// scrapeStatus returns the response status of res, or 0 when the scrape
+// failed without one.
+func scrapeStatus(res *scraper.Result) int {
+	if res == nil {
+		return 0
+	}
+	return res.Status
+}
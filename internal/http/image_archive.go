@@ -0,0 +1,170 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+)
+
+// Defaults for downloadImages when scraper.imagesMaxPerDocument and
+// scraper.imageMaxBytes are unset.
+const (
+	defaultImagesMaxPerDocument = 20
+	defaultImageMaxBytes        = 5 << 20
+)
+
+// assetStore persists archived binary assets for a job.
+type assetStore interface {
+	PutJobAsset(ctx context.Context, jobID uuid.UUID, sourceURL, contentType string, data []byte) (uuid.UUID, error)
+}
+
+// markdownImageRe matches the target of a markdown image link, e.g. the
+// "src" in ![alt](src "title").
+var markdownImageRe = regexp.MustCompile(`(!\[[^\]]*\]\()(<[^>]*>|[^)\s]+)`)
+
+// jobAssetURL is the stable API path an archived asset is served from.
+func jobAssetURL(jobID, assetID uuid.UUID) string {
+	return fmt.Sprintf("/v1/jobs/%s/assets/%s", jobID, assetID)
+}
+
+// fetchImage downloads a single image, refusing non-image responses and
+// bodies larger than maxBytes. Tests can override it.
+var fetchImage = func(ctx context.Context, cfg *config.Config, imageURL string, maxBytes int64) ([]byte, string, error) {
+	timeout := time.Duration(cfg.Scraper.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if ua := cfg.RequestUserAgent(); ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", maxBytes)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", maxBytes)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("not an image (%s)", contentType)
+	}
+	return data, contentType, nil
+}
+
+// archiveImages downloads the images referenced by a page, stores them as
+// job assets, and returns the markdown with image links rewritten to the
+// stable asset URLs. Images referenced from the markdown are archived
+// first, then any other page images, up to the configured count. Images
+// that fail to download keep their original links.
+func archiveImages(ctx context.Context, cfg *config.Config, st assetStore, jobID uuid.UUID, pageURL, markdown string, images []string) string {
+	maxCount := cfg.Scraper.ImagesMaxPerDocument
+	if maxCount <= 0 {
+		maxCount = defaultImagesMaxPerDocument
+	}
+	maxBytes := cfg.Scraper.ImageMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultImageMaxBytes
+	}
+
+	base, _ := url.Parse(pageURL)
+	resolve := func(src string) string {
+		src = strings.Trim(strings.TrimSpace(src), "<>")
+		u, err := url.Parse(src)
+		if err != nil {
+			return ""
+		}
+		if base != nil && !u.IsAbs() {
+			u = base.ResolveReference(u)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return ""
+		}
+		u.Fragment = ""
+		return u.String()
+	}
+
+	candidates := make([]string, 0, len(images))
+	seen := make(map[string]struct{})
+	addCandidate := func(u string) {
+		if u == "" {
+			return
+		}
+		if _, ok := seen[u]; ok {
+			return
+		}
+		seen[u] = struct{}{}
+		candidates = append(candidates, u)
+	}
+	for _, m := range markdownImageRe.FindAllStringSubmatch(markdown, -1) {
+		addCandidate(resolve(m[2]))
+	}
+	for _, img := range images {
+		addCandidate(resolve(img))
+	}
+
+	archived := make(map[string]string)
+	for _, src := range candidates {
+		if len(archived) >= maxCount {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		data, contentType, err := fetchImage(ctx, cfg, src, maxBytes)
+		if err != nil {
+			continue
+		}
+		assetID, err := st.PutJobAsset(ctx, jobID, src, contentType, data)
+		if err != nil {
+			continue
+		}
+		archived[src] = jobAssetURL(jobID, assetID)
+	}
+
+	if len(archived) == 0 {
+		return markdown
+	}
+
+	return markdownImageRe.ReplaceAllStringFunc(markdown, func(match string) string {
+		parts := markdownImageRe.FindStringSubmatch(match)
+		if target, ok := archived[resolve(parts[2])]; ok {
+			return parts[1] + target
+		}
+		return match
+	})
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+)
+
+type fakeAssetStore struct {
+	ids map[string]uuid.UUID
+}
+
+func (f *fakeAssetStore) PutJobAsset(_ context.Context, _ uuid.UUID, sourceURL, _ string, _ []byte) (uuid.UUID, error) {
+	if id, ok := f.ids[sourceURL]; ok {
+		return id, nil
+	}
+	id := uuid.New()
+	f.ids[sourceURL] = id
+	return id, nil
+}
+
+func TestArchiveImages_RewritesMarkdownWithinLimits(t *testing.T) {
+	orig := fetchImage
+	defer func() { fetchImage = orig }()
+
+	var fetched []string
+	fetchImage = func(_ context.Context, _ *config.Config, imageURL string, _ int64) ([]byte, string, error) {
+		fetched = append(fetched, imageURL)
+		if strings.HasSuffix(imageURL, "/broken.png") {
+			return nil, "", fmt.Errorf("unexpected status 404")
+		}
+		return []byte("png"), "image/png", nil
+	}
+
+	cfg := &config.Config{Scraper: config.ScraperConfig{ImagesMaxPerDocument: 2}}
+	st := &fakeAssetStore{ids: map[string]uuid.UUID{}}
+	jobID := uuid.New()
+
+	markdown := "![logo](/img/logo.png)\n\n![gone](broken.png)\n\n![chart](https://cdn.example.com/chart.png \"Chart\")\n\n![logo again](/img/logo.png)"
+	images := []string{"https://example.com/img/extra.png"}
+
+	out := archiveImages(context.Background(), cfg, st, jobID, "https://example.com/blog/post", markdown, images)
+
+	logoURL := jobAssetURL(jobID, st.ids["https://example.com/img/logo.png"])
+	chartURL := jobAssetURL(jobID, st.ids["https://cdn.example.com/chart.png"])
+	if strings.Count(out, "]("+logoURL+")") != 2 {
+		t.Fatalf("expected both logo links rewritten to %s, got:\n%s", logoURL, out)
+	}
+	if !strings.Contains(out, "]("+chartURL+" \"Chart\")") {
+		t.Fatalf("expected chart link rewritten with title preserved, got:\n%s", out)
+	}
+	if !strings.Contains(out, "](broken.png)") {
+		t.Fatalf("expected failed image to keep its original link, got:\n%s", out)
+	}
+	if _, ok := st.ids["https://example.com/img/extra.png"]; ok {
+		t.Fatalf("expected imagesMaxPerDocument=2 to stop before the extra page image")
+	}
+	if len(fetched) != 3 {
+		t.Fatalf("expected 3 fetch attempts (logo, broken, chart), got %v", fetched)
+	}
+}
+
+func TestRewriteAssetLinks(t *testing.T) {
+	jobID := uuid.New()
+	assetID := uuid.New()
+	paths := map[string]string{jobAssetURL(jobID, assetID): "assets/" + assetID.String() + ".png"}
+
+	md := "![a](" + jobAssetURL(jobID, assetID) + ")"
+	got := rewriteAssetLinks(md, paths, "../")
+	want := "![a](../assets/" + assetID.String() + ".png)"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
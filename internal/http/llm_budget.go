@@ -0,0 +1,258 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/llm"
+	"raito/internal/scrapeutil"
+	"raito/internal/store"
+)
+
+// errLLMBudgetExceeded is returned for LLM calls made by a tenant that has
+// used up its monthly token or spend cap.
+var errLLMBudgetExceeded = errors.New("monthly LLM budget exceeded for this tenant")
+
+// llmBudgetThresholds are the usage percentages at which tenant admins are
+// notified, highest first.
+var llmBudgetThresholds = []int32{100, 80}
+
+// llmBudgetQuerier is the subset of db.Queries used to enforce budgets.
+type llmBudgetQuerier interface {
+	GetTenantLLMBudget(ctx context.Context, tenantID uuid.UUID) (db.TenantLlmBudget, error)
+	GetTenantLLMUsage(ctx context.Context, arg db.GetTenantLLMUsageParams) (db.TenantLlmUsage, error)
+	AddTenantLLMUsage(ctx context.Context, arg db.AddTenantLLMUsageParams) (db.TenantLlmUsage, error)
+	MarkTenantLLMUsageNotified(ctx context.Context, arg db.MarkTenantLLMUsageNotifiedParams) (int64, error)
+	InsertAuditEvent(ctx context.Context, arg db.InsertAuditEventParams) (db.AuditEvent, error)
+}
+
+// llmBudgetPeriod returns the first day (UTC) of the month containing t,
+// which keys tenant_llm_usage rows.
+func llmBudgetPeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// llmBudgetPercent returns how much of the tightest cap usage has consumed,
+// or 0 when the budget has no caps.
+func llmBudgetPercent(budget db.TenantLlmBudget, usage db.TenantLlmUsage) float64 {
+	var pct float64
+	if budget.MonthlyTokenCap.Valid {
+		pct = math.Max(pct, capPercent(usage.InputTokens+usage.OutputTokens, budget.MonthlyTokenCap.Int64))
+	}
+	if budget.MonthlySpendCapMicros.Valid {
+		pct = math.Max(pct, capPercent(usage.SpendMicros, budget.MonthlySpendCapMicros.Int64))
+	}
+	return pct
+}
+
+func capPercent(used, limit int64) float64 {
+	if limit <= 0 {
+		return 100
+	}
+	return float64(used) * 100 / float64(limit)
+}
+
+// llmSpendMicros prices usage with llm.pricing, in millionths of a USD.
+func llmSpendMicros(cfg *config.Config, model string, usage llm.Usage) int64 {
+	price, ok := cfg.LLM.Pricing[model]
+	if !ok {
+		return 0
+	}
+	// Prices are per million tokens, so tokens*price is already in micros.
+	return int64(math.Ceil(float64(usage.InputTokens)*price.InputPerMillion + float64(usage.OutputTokens)*price.OutputPerMillion))
+}
+
+// checkLLMBudget returns errLLMBudgetExceeded when the tenant has reached
+// any of its monthly caps. Tenants without a budget are unlimited.
+func checkLLMBudget(ctx context.Context, q llmBudgetQuerier, tenantID uuid.UUID) error {
+	budget, err := q.GetTenantLLMBudget(ctx, tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !budget.MonthlyTokenCap.Valid && !budget.MonthlySpendCapMicros.Valid {
+		return nil
+	}
+	usage, err := q.GetTenantLLMUsage(ctx, db.GetTenantLLMUsageParams{
+		TenantID: tenantID,
+		Period:   llmBudgetPeriod(time.Now()),
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if llmBudgetPercent(budget, usage) >= 100 {
+		return errLLMBudgetExceeded
+	}
+	return nil
+}
+
+// recordLLMUsage adds one call to the tenant's monthly usage and notifies
+// tenant admins the first time usage crosses 80% and 100% of a cap.
+func recordLLMUsage(ctx context.Context, cfg *config.Config, q llmBudgetQuerier, tenantID uuid.UUID, model string, usage llm.Usage) error {
+	period := llmBudgetPeriod(time.Now())
+	row, err := q.AddTenantLLMUsage(ctx, db.AddTenantLLMUsageParams{
+		TenantID:     tenantID,
+		Period:       period,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		SpendMicros:  llmSpendMicros(cfg, model, usage),
+	})
+	if err != nil {
+		return err
+	}
+
+	budget, err := q.GetTenantLLMBudget(ctx, tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pct := llmBudgetPercent(budget, row)
+	for _, threshold := range llmBudgetThresholds {
+		if pct < float64(threshold) || row.NotifiedPercent >= threshold {
+			continue
+		}
+		// The conditional update lets exactly one concurrent caller send
+		// the notification for a threshold.
+		n, err := q.MarkTenantLLMUsageNotified(ctx, db.MarkTenantLLMUsageNotifiedParams{
+			TenantID:        tenantID,
+			Period:          period,
+			NotifiedPercent: threshold,
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		notifyLLMBudgetThreshold(ctx, q, tenantID, threshold, budget, row)
+		break
+	}
+	return nil
+}
+
+// notifyLLMBudgetThreshold notifies tenant admins that usage crossed a
+// threshold: it records a tenant.llm_budget.threshold audit event for the
+// tenant and logs a warning. GET /v1/tenants/:id/llm-budget reports the
+// same threshold as notifiedPercent.
+func notifyLLMBudgetThreshold(ctx context.Context, q llmBudgetQuerier, tenantID uuid.UUID, threshold int32, budget db.TenantLlmBudget, usage db.TenantLlmUsage) {
+	meta := map[string]any{
+		"threshold":    threshold,
+		"period":       usage.Period.Format("2006-01"),
+		"tokens":       usage.InputTokens + usage.OutputTokens,
+		"spendMicros":  usage.SpendMicros,
+		"tokenCap":     nullInt64Ptr(budget.MonthlyTokenCap),
+		"spendCapUsd":  spendCapUSD(budget.MonthlySpendCapMicros),
+		"budgetHalted": threshold >= 100,
+	}
+	raw, _ := json.Marshal(meta)
+	_, _ = q.InsertAuditEvent(ctx, db.InsertAuditEventParams{
+		Action:       "tenant.llm_budget.threshold",
+		TenantID:     uuid.NullUUID{UUID: tenantID, Valid: true},
+		ResourceType: sql.NullString{String: "tenant", Valid: true},
+		ResourceID:   sql.NullString{String: tenantID.String(), Valid: true},
+		Metadata:     raw,
+	})
+	slog.Warn(fmt.Sprintf("tenant LLM budget reached %d%%", threshold),
+		"tenant_id", tenantID.String(),
+		"period", meta["period"],
+		"tokens", meta["tokens"],
+		"spend_micros", usage.SpendMicros,
+	)
+}
+
+func nullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
+
+func spendCapUSD(v sql.NullInt64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	usd := float64(v.Int64) / 1e6
+	return &usd
+}
+
+// budgetedLLMClient enforces a tenant's LLM budget around another client:
+// calls fail fast with errLLMBudgetExceeded once a cap is reached, and the
+// tokens of every successful call are charged to the tenant.
+type budgetedLLMClient struct {
+	inner    llm.Client
+	cfg      *config.Config
+	q        llmBudgetQuerier
+	tenantID uuid.UUID
+	model    string
+}
+
+func (b *budgetedLLMClient) ExtractFields(ctx context.Context, req llm.ExtractRequest) (llm.ExtractResult, error) {
+	if err := checkLLMBudget(ctx, b.q, b.tenantID); err != nil {
+		return llm.ExtractResult{}, err
+	}
+	res, err := b.inner.ExtractFields(ctx, req)
+	if err != nil {
+		return res, err
+	}
+	// Usage is recorded even if the request context has expired so that
+	// completed calls are never free.
+	_ = recordLLMUsage(context.WithoutCancel(ctx), b.cfg, b.q, b.tenantID, b.model, res.Usage)
+	return res, nil
+}
+
+// withLLMBudget wraps client in the tenant's budget. Requests without a
+// tenant are not budgeted.
+func withLLMBudget(cfg *config.Config, q llmBudgetQuerier, tenantID *uuid.UUID, client llm.Client, model string) llm.Client {
+	if tenantID == nil || q == nil {
+		return client
+	}
+	return &budgetedLLMClient{inner: client, cfg: cfg, q: q, tenantID: *tenantID, model: model}
+}
+
+// newLLMClient is llm.NewClientFromConfig with the tenant's LLM policy
+// and budget applied.
+func newLLMClient(ctx context.Context, cfg *config.Config, st *store.Store, tenantID *uuid.UUID, providerOverride, modelOverride string) (llm.Client, llm.Provider, string, error) {
+	q := db.New(st.DB)
+	providerOverride, modelOverride, err := resolveTenantLLM(ctx, cfg, q, tenantID, providerOverride, modelOverride)
+	if err != nil {
+		return nil, "", "", err
+	}
+	client, provider, model, err := llm.NewClientFromConfig(cfg, providerOverride, modelOverride)
+	if err != nil {
+		return nil, provider, model, err
+	}
+	return withLLMBudget(cfg, q, tenantID, client, model), provider, model, nil
+}
+
+// wantsLLMFormat reports whether formats include one computed by the LLM.
+func wantsLLMFormat(formats []any) bool {
+	hasJSON, _, _ := scrapeutil.GetJSONFormatConfig(formats)
+	hasBranding, _ := scrapeutil.GetBrandingFormatConfig(formats)
+	return hasJSON || hasBranding || scrapeutil.WantsFormat(formats, "summary")
+}
+
+// llmFailure maps an LLM call error to an HTTP status and error code,
+// using code for ordinary provider failures.
+func llmFailure(err error, code string) (int, string) {
+	switch {
+	case errors.Is(err, errLLMBudgetExceeded):
+		return fiber.StatusTooManyRequests, "LLM_BUDGET_EXCEEDED"
+	case errors.Is(err, context.DeadlineExceeded):
+		return fiber.StatusGatewayTimeout, code
+	default:
+		return fiber.StatusBadGateway, code
+	}
+}
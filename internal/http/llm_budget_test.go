@@ -0,0 +1,162 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/llm"
+)
+
+type fakeBudgetQuerier struct {
+	budget *db.TenantLlmBudget
+	usage  db.TenantLlmUsage
+	events []db.InsertAuditEventParams
+}
+
+func (f *fakeBudgetQuerier) GetTenantLLMBudget(_ context.Context, _ uuid.UUID) (db.TenantLlmBudget, error) {
+	if f.budget == nil {
+		return db.TenantLlmBudget{}, sql.ErrNoRows
+	}
+	return *f.budget, nil
+}
+
+func (f *fakeBudgetQuerier) GetTenantLLMUsage(_ context.Context, _ db.GetTenantLLMUsageParams) (db.TenantLlmUsage, error) {
+	return f.usage, nil
+}
+
+func (f *fakeBudgetQuerier) AddTenantLLMUsage(_ context.Context, arg db.AddTenantLLMUsageParams) (db.TenantLlmUsage, error) {
+	f.usage.Period = arg.Period
+	f.usage.Calls++
+	f.usage.InputTokens += arg.InputTokens
+	f.usage.OutputTokens += arg.OutputTokens
+	f.usage.SpendMicros += arg.SpendMicros
+	return f.usage, nil
+}
+
+func (f *fakeBudgetQuerier) MarkTenantLLMUsageNotified(_ context.Context, arg db.MarkTenantLLMUsageNotifiedParams) (int64, error) {
+	if f.usage.NotifiedPercent >= arg.NotifiedPercent {
+		return 0, nil
+	}
+	f.usage.NotifiedPercent = arg.NotifiedPercent
+	return 1, nil
+}
+
+func (f *fakeBudgetQuerier) InsertAuditEvent(_ context.Context, arg db.InsertAuditEventParams) (db.AuditEvent, error) {
+	f.events = append(f.events, arg)
+	return db.AuditEvent{}, nil
+}
+
+type fakeUsageClient struct {
+	calls int
+	usage llm.Usage
+}
+
+func (f *fakeUsageClient) ExtractFields(_ context.Context, _ llm.ExtractRequest) (llm.ExtractResult, error) {
+	f.calls++
+	return llm.ExtractResult{Fields: map[string]any{"summary": "ok"}, Usage: f.usage}, nil
+}
+
+func TestBudgetedLLMClient_TokenCap(t *testing.T) {
+	cfg := &config.Config{}
+	tenant := uuid.New()
+	q := &fakeBudgetQuerier{budget: &db.TenantLlmBudget{
+		TenantID:        tenant,
+		MonthlyTokenCap: sql.NullInt64{Int64: 1000, Valid: true},
+	}}
+	inner := &fakeUsageClient{usage: llm.Usage{InputTokens: 300, OutputTokens: 150}}
+	client := withLLMBudget(cfg, q, &tenant, inner, "gpt-test")
+
+	// 450 tokens: below 80%.
+	if _, err := client.ExtractFields(context.Background(), llm.ExtractRequest{}); err != nil {
+		t.Fatalf("call 1: %v", err)
+	}
+	if len(q.events) != 0 {
+		t.Fatalf("expected no notification below 80%%, got %d", len(q.events))
+	}
+
+	// 900 tokens: crosses 80%.
+	if _, err := client.ExtractFields(context.Background(), llm.ExtractRequest{}); err != nil {
+		t.Fatalf("call 2: %v", err)
+	}
+	if len(q.events) != 1 || q.usage.NotifiedPercent != 80 {
+		t.Fatalf("expected one 80%% notification, got %d events (notified %d)", len(q.events), q.usage.NotifiedPercent)
+	}
+
+	// 1350 tokens: crosses 100%; the call itself was allowed.
+	if _, err := client.ExtractFields(context.Background(), llm.ExtractRequest{}); err != nil {
+		t.Fatalf("call 3: %v", err)
+	}
+	if len(q.events) != 2 || q.usage.NotifiedPercent != 100 {
+		t.Fatalf("expected a 100%% notification, got %d events (notified %d)", len(q.events), q.usage.NotifiedPercent)
+	}
+	if q.events[1].Action != "tenant.llm_budget.threshold" || !q.events[1].TenantID.Valid || q.events[1].TenantID.UUID != tenant {
+		t.Fatalf("unexpected audit event: %+v", q.events[1])
+	}
+
+	// Over the cap: fail fast without calling the provider.
+	if _, err := client.ExtractFields(context.Background(), llm.ExtractRequest{}); !errors.Is(err, errLLMBudgetExceeded) {
+		t.Fatalf("expected errLLMBudgetExceeded, got %v", err)
+	}
+	if inner.calls != 3 || len(q.events) != 2 {
+		t.Fatalf("expected no provider call or notification once exceeded, got %d calls, %d events", inner.calls, len(q.events))
+	}
+	if status, code := llmFailure(errLLMBudgetExceeded, "SUMMARY_FAILED"); status != 429 || code != "LLM_BUDGET_EXCEEDED" {
+		t.Fatalf("llmFailure = %d %s", status, code)
+	}
+}
+
+func TestBudgetedLLMClient_SpendCap(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Pricing = map[string]config.LLMModelPricing{
+		"gpt-test": {InputPerMillion: 2, OutputPerMillion: 8},
+	}
+	tenant := uuid.New()
+	q := &fakeBudgetQuerier{budget: &db.TenantLlmBudget{
+		TenantID:              tenant,
+		MonthlySpendCapMicros: sql.NullInt64{Int64: 10000, Valid: true}, // $0.01
+	}}
+	inner := &fakeUsageClient{usage: llm.Usage{InputTokens: 1000, OutputTokens: 1000}}
+	client := withLLMBudget(cfg, q, &tenant, inner, "gpt-test")
+
+	// 1000*2 + 1000*8 = 10000 micros, exactly the cap.
+	if _, err := client.ExtractFields(context.Background(), llm.ExtractRequest{}); err != nil {
+		t.Fatalf("call 1: %v", err)
+	}
+	if q.usage.SpendMicros != 10000 {
+		t.Fatalf("expected 10000 micros spent, got %d", q.usage.SpendMicros)
+	}
+	if len(q.events) != 1 || q.usage.NotifiedPercent != 100 {
+		t.Fatalf("expected a single 100%% notification, got %d events (notified %d)", len(q.events), q.usage.NotifiedPercent)
+	}
+	if _, err := client.ExtractFields(context.Background(), llm.ExtractRequest{}); !errors.Is(err, errLLMBudgetExceeded) {
+		t.Fatalf("expected errLLMBudgetExceeded, got %v", err)
+	}
+
+	// Unpriced models are free.
+	if got := llmSpendMicros(cfg, "other", llm.Usage{InputTokens: 1000}); got != 0 {
+		t.Fatalf("expected unpriced model to cost nothing, got %d", got)
+	}
+}
+
+func TestWithLLMBudget_Unbudgeted(t *testing.T) {
+	inner := &fakeUsageClient{}
+	if got := withLLMBudget(&config.Config{}, &fakeBudgetQuerier{}, nil, inner, "m"); got != llm.Client(inner) {
+		t.Fatalf("expected requests without a tenant to be unbudgeted")
+	}
+
+	tenant := uuid.New()
+	if err := checkLLMBudget(context.Background(), &fakeBudgetQuerier{}, tenant); err != nil {
+		t.Fatalf("expected tenants without a budget to be unlimited, got %v", err)
+	}
+
+	if got := llmBudgetPeriod(time.Date(2026, 3, 31, 23, 0, 0, 0, time.FixedZone("x", -5*3600))); !got.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected UTC month start, got %v", got)
+	}
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/llm"
+)
+
+// llmModelNotAllowedError is returned when a tenant requests, or defaults
+// to, a provider/model outside its allowlist.
+type llmModelNotAllowedError struct {
+	Provider string
+	Model    string
+	Allowed  []string
+}
+
+func (e *llmModelNotAllowedError) Error() string {
+	return fmt.Sprintf("model %s/%s is not allowed for this tenant; allowed models: %s",
+		e.Provider, e.Model, strings.Join(e.Allowed, ", "))
+}
+
+// llmPolicyQuerier is the subset of db.Queries used to apply tenant LLM
+// policies.
+type llmPolicyQuerier interface {
+	GetTenantLLMPolicy(ctx context.Context, tenantID uuid.UUID) (db.TenantLlmPolicy, error)
+}
+
+// llmPolicyModels decodes a policy's allowed_models column.
+func llmPolicyModels(raw json.RawMessage) []string {
+	var models []string
+	_ = json.Unmarshal(raw, &models)
+	return models
+}
+
+// normalizeLLMPolicyEntry validates one allowlist entry: "provider/model",
+// "provider/*" or "provider". The provider must be one NewClientFromConfig
+// supports.
+func normalizeLLMPolicyEntry(entry string) (string, error) {
+	entry = strings.TrimSpace(entry)
+	provider, model, _ := strings.Cut(entry, "/")
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	model = strings.TrimSpace(model)
+	if !validLLMProvider(provider) {
+		return "", fmt.Errorf("unsupported llm provider in %q", entry)
+	}
+	if model == "" || model == "*" {
+		return provider + "/*", nil
+	}
+	return provider + "/" + model, nil
+}
+
+func validLLMProvider(name string) bool {
+	switch llm.Provider(name) {
+	case llm.ProviderOpenAI, llm.ProviderAnthropic, llm.ProviderGoogle:
+		return true
+	}
+	return false
+}
+
+// llmModelAllowed reports whether provider/model matches an allowlist
+// entry. An empty allowlist allows everything.
+func llmModelAllowed(allowed []string, provider, model string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, entry := range allowed {
+		p, m, _ := strings.Cut(entry, "/")
+		if p == provider && (m == "*" || m == "" || m == model) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTenantLLM applies a tenant's LLM policy to the provider and model
+// overrides of a request. Empty overrides take the tenant defaults, and the
+// resulting provider/model must be on the allowlist. The returned values
+// are the overrides to pass to llm.NewClientFromConfig. Requests without a
+// tenant, and tenants without a policy, are returned unchanged.
+func resolveTenantLLM(ctx context.Context, cfg *config.Config, q llmPolicyQuerier, tenantID *uuid.UUID, providerOverride, modelOverride string) (string, string, error) {
+	if tenantID == nil || q == nil {
+		return providerOverride, modelOverride, nil
+	}
+	policy, err := q.GetTenantLLMPolicy(ctx, *tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return providerOverride, modelOverride, nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	if providerOverride == "" && policy.DefaultProvider.Valid {
+		providerOverride = policy.DefaultProvider.String
+	}
+	// The default model belongs to the default provider, so it only
+	// applies when the request did not pick another provider.
+	defaultProvider, _ := llm.ResolveModel(cfg, policy.DefaultProvider.String, "")
+	if modelOverride == "" && policy.DefaultModel.Valid {
+		if provider, _ := llm.ResolveModel(cfg, providerOverride, ""); provider == defaultProvider {
+			modelOverride = policy.DefaultModel.String
+		}
+	}
+
+	provider, model := llm.ResolveModel(cfg, providerOverride, modelOverride)
+	allowed := llmPolicyModels(policy.AllowedModels)
+	if !llmModelAllowed(allowed, string(provider), model) {
+		return "", "", &llmModelNotAllowedError{Provider: string(provider), Model: model, Allowed: allowed}
+	}
+	return providerOverride, modelOverride, nil
+}
+
+// llmClientFailure maps an error from building an LLM client to an HTTP
+// status and error code.
+func llmClientFailure(err error) (int, string) {
+	var notAllowed *llmModelNotAllowedError
+	if errors.As(err, &notAllowed) {
+		return fiber.StatusForbidden, "LLM_MODEL_NOT_ALLOWED"
+	}
+	return fiber.StatusInternalServerError, "LLM_NOT_CONFIGURED"
+}
+
+// llmClientErrorMessage formats an error from building an LLM client as a
+// "CODE: message" job error.
+func llmClientErrorMessage(err error) string {
+	_, code := llmClientFailure(err)
+	return code + ": " + err.Error()
+}
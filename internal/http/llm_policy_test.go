@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+)
+
+type fakePolicyQuerier struct {
+	policy *db.TenantLlmPolicy
+}
+
+func (f *fakePolicyQuerier) GetTenantLLMPolicy(_ context.Context, _ uuid.UUID) (db.TenantLlmPolicy, error) {
+	if f.policy == nil {
+		return db.TenantLlmPolicy{}, sql.ErrNoRows
+	}
+	return *f.policy, nil
+}
+
+func TestResolveTenantLLM(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.DefaultProvider = "openai"
+	cfg.LLM.OpenAI.Model = "gpt-4o"
+	cfg.LLM.Anthropic.Model = "claude-default"
+
+	tenantID := uuid.New()
+	allowed, _ := json.Marshal([]string{"openai/gpt-4o-mini", "anthropic/*"})
+	q := &fakePolicyQuerier{policy: &db.TenantLlmPolicy{
+		AllowedModels: allowed,
+		DefaultModel:  sql.NullString{String: "gpt-4o-mini", Valid: true},
+	}}
+
+	// No overrides: the tenant default model replaces the server default.
+	provider, model, err := resolveTenantLLM(context.Background(), cfg, q, &tenantID, "", "")
+	if err != nil || provider != "" || model != "gpt-4o-mini" {
+		t.Fatalf("expected the tenant default model, got %q %q %v", provider, model, err)
+	}
+
+	// Another provider keeps its own default model.
+	if _, model, err := resolveTenantLLM(context.Background(), cfg, q, &tenantID, "anthropic", ""); err != nil || model != "" {
+		t.Fatalf("expected anthropic with its configured model, got %q %v", model, err)
+	}
+
+	_, _, err = resolveTenantLLM(context.Background(), cfg, q, &tenantID, "openai", "gpt-4o")
+	var notAllowed *llmModelNotAllowedError
+	if !errors.As(err, &notAllowed) {
+		t.Fatalf("expected a disallowed model error, got %v", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "openai/gpt-4o ") || !strings.Contains(msg, "anthropic/*") {
+		t.Fatalf("expected the error to name the model and the allowlist, got %q", msg)
+	}
+	if status, code := llmClientFailure(err); status != 403 || code != "LLM_MODEL_NOT_ALLOWED" {
+		t.Fatalf("unexpected mapping %d %s", status, code)
+	}
+
+	// Tenants without a policy and requests without a tenant are untouched.
+	if p, m, err := resolveTenantLLM(context.Background(), cfg, &fakePolicyQuerier{}, &tenantID, "google", "x"); err != nil || p != "google" || m != "x" {
+		t.Fatalf("expected overrides to pass through, got %q %q %v", p, m, err)
+	}
+	if _, _, err := resolveTenantLLM(context.Background(), cfg, q, nil, "openai", "gpt-4o"); err != nil {
+		t.Fatalf("expected no policy without a tenant, got %v", err)
+	}
+}
+
+func TestNormalizeLLMPolicyEntry(t *testing.T) {
+	for in, want := range map[string]string{
+		"OpenAI/gpt-4o": "openai/gpt-4o",
+		"anthropic":     "anthropic/*",
+		"google/*":      "google/*",
+	} {
+		if got, err := normalizeLLMPolicyEntry(in); err != nil || got != want {
+			t.Fatalf("normalizeLLMPolicyEntry(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := normalizeLLMPolicyEntry("mistral/large"); err == nil {
+		t.Fatalf("expected unsupported providers to be rejected")
+	}
+}
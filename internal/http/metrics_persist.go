@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/metrics"
+	"raito/internal/store"
+)
+
+const (
+	// defaultMetricsPersistInterval is how often counters are saved when
+	// metrics.persistIntervalSeconds is unset.
+	defaultMetricsPersistInterval = 60 * time.Second
+	// metricSnapshotsRetention is how long snapshots of instances that
+	// stopped saving are kept.
+	metricSnapshotsRetention = 30 * 24 * time.Hour
+)
+
+// StartMetricsPersister restores this process's saved /metrics counters
+// and saves them every metrics.persistIntervalSeconds when
+// metrics.persist is enabled. role is the process role ("api", "worker"
+// or "all") and is part of the default instance name.
+func StartMetricsPersister(ctx context.Context, cfg *config.Config, st *store.Store, role string) {
+	if cfg == nil || !cfg.Metrics.Persist || st == nil || st.DB == nil {
+		return
+	}
+	q := db.New(st.DB)
+	instance := metricsInstance(cfg.Metrics, role)
+
+	_, _ = q.DeleteMetricSnapshotsBefore(ctx, time.Now().Add(-metricSnapshotsRetention))
+	if snap, err := q.GetMetricSnapshot(ctx, instance); err == nil {
+		_ = metrics.Restore(snap.Snapshot)
+	}
+
+	interval := defaultMetricsPersistInterval
+	if s := cfg.Metrics.PersistIntervalSeconds; s > 0 {
+		interval = time.Duration(s) * time.Second
+	}
+	go runMetricsPersister(ctx, q, instance, interval)
+}
+
+func runMetricsPersister(ctx context.Context, q *db.Queries, instance string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			saveMetricSnapshot(context.Background(), q, instance)
+			return
+		case <-ticker.C:
+		}
+		saveMetricSnapshot(ctx, q, instance)
+	}
+}
+
+func saveMetricSnapshot(ctx context.Context, q *db.Queries, instance string) {
+	data, err := metrics.Snapshot()
+	if err != nil {
+		return
+	}
+	_ = q.UpsertMetricSnapshot(ctx, db.UpsertMetricSnapshotParams{Instance: instance, Snapshot: data})
+}
+
+// metricsInstance returns the name counters are saved under: the
+// configured instance, or the host name and role.
+func metricsInstance(c config.MetricsConfig, role string) string {
+	if name := strings.TrimSpace(c.Instance); name != "" {
+		return name
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "raito"
+	}
+	return host + "-" + role
+}
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"os"
+	"testing"
+
+	"raito/internal/config"
+)
+
+func TestMetricsInstance(t *testing.T) {
+	if got := metricsInstance(config.MetricsConfig{Instance: " api-0 "}, "api"); got != "api-0" {
+		t.Fatalf("expected the configured instance, got %q", got)
+	}
+	host, _ := os.Hostname()
+	if got := metricsInstance(config.MetricsConfig{}, "worker"); host != "" && got != host+"-worker" {
+		t.Fatalf("expected host name and role, got %q", got)
+	}
+}
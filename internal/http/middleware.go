@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -14,6 +15,10 @@ import (
 	"raito/internal/store"
 )
 
+// onBehalfOfTenantHeader names the tenant, by id or slug, that an admin
+// API key acts for.
+const onBehalfOfTenantHeader = "X-Raito-Tenant"
+
 // authMiddleware validates either an API key (Authorization: Bearer
 // raito_...) or a browser session cookie (JWT) and attaches a Principal
 // to the context. API keys remain the primary mechanism for automation.
@@ -84,12 +89,81 @@ func authMiddleware(cfg *config.Config, st *store.Store) fiber.Handler {
 					})
 				}
 				p.IsSystemAdmin = user.IsSystemAdmin
+
+				// User-bound keys without a tenant start in the user's
+				// default tenant, like browser sessions do.
+				if p.TenantID == nil {
+					p.TenantID = resolveDefaultTenant(c.Context(), q, user)
+				}
 			}
 
+			// Admin keys may act on behalf of any tenant for one request,
+			// so a central integration does not need a key per tenant.
+			var onBehalfOf *db.Tenant
+			if ref := strings.TrimSpace(c.Get(onBehalfOfTenantHeader)); ref != "" {
+				if !apiKey.IsAdmin || !p.IsSystemAdmin {
+					return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+						Success: false,
+						Code:    "FORBIDDEN",
+						Error:   onBehalfOfTenantHeader + " requires an admin API key",
+					})
+				}
+				if q == nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+						Success: false,
+						Code:    "INTERNAL_ERROR",
+						Error:   "store not available in context",
+					})
+				}
+				tenant, err := lookupTenantRef(c.Context(), q, ref)
+				if err != nil {
+					if err == sql.ErrNoRows {
+						return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+							Success: false,
+							Code:    "TENANT_NOT_FOUND",
+							Error:   fmt.Sprintf("%s names an unknown tenant: %q", onBehalfOfTenantHeader, ref),
+						})
+					}
+					return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+						Success: false,
+						Code:    "TENANT_LOOKUP_FAILED",
+						Error:   err.Error(),
+					})
+				}
+				p.TenantID = &tenant.ID
+				onBehalfOf = &tenant
+			}
+
+			// Usage tracking is best-effort and never fails the request.
+			_ = st.TouchAPIKeyUsage(c.Context(), apiKey.ID)
+
+			p.TenantRole = tenantRoleFor(c.Context(), q, p)
 			c.Locals("principal", p)
+
+			if onBehalfOf != nil {
+				recordAuditEvent(c, st, "api_key.on_behalf_of", auditEventOptions{
+					TenantID:     &onBehalfOf.ID,
+					ResourceType: "tenant",
+					ResourceID:   onBehalfOf.ID.String(),
+					Metadata: map[string]any{
+						"tenantSlug": onBehalfOf.Slug,
+						"method":     c.Method(),
+						"path":       c.Path(),
+					},
+				})
+			}
 			return c.Next()
 		}
 
+		// Sessions always act in their selected tenant.
+		if c.Get(onBehalfOfTenantHeader) != "" {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Success: false,
+				Code:    "FORBIDDEN",
+				Error:   onBehalfOfTenantHeader + " requires an admin API key",
+			})
+		}
+
 		// Otherwise, try browser session cookie.
 		claims, err := parseSessionFromRequest(c, cfg)
 		if err != nil {
@@ -114,6 +188,18 @@ func authMiddleware(cfg *config.Config, st *store.Store) fiber.Handler {
 		}
 		p.IsSystemAdmin = claims.IsSystemAdmin
 
+		// Reject revoked/expired server-side sessions and slide expiry.
+		if q != nil {
+			if err := validateSessionRecord(c.Context(), c, cfg, q, claims); err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+					Success: false,
+					Code:    "UNAUTHENTICATED",
+					Error:   "Session has expired or been revoked",
+				})
+			}
+		}
+		c.Locals("session", claims)
+
 		// Validate the user exists and is not disabled, and prefer DB for admin bit.
 		if q != nil && p.UserID != nil {
 			user, err := q.GetUserByID(c.Context(), *p.UserID)
@@ -132,43 +218,131 @@ func authMiddleware(cfg *config.Config, st *store.Store) fiber.Handler {
 				})
 			}
 			p.IsSystemAdmin = user.IsSystemAdmin
+
+			// Until a one-time password is replaced, the session may only
+			// inspect itself and change the password.
+			if user.MustChangePassword && !passwordChangeAllowedPath(c.Path()) {
+				return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+					Success: false,
+					Code:    "PASSWORD_CHANGE_REQUIRED",
+					Error:   "You must change your password before continuing",
+				})
+			}
 		}
 
+		p.TenantRole = tenantRoleFor(c.Context(), q, p)
 		c.Locals("principal", p)
 		return c.Next()
 	}
 }
 
-// rateLimitMiddleware enforces a simple per-minute fixed-window rate limit
-// per API key using Redis. For browser sessions, it falls back to a
-// per-user limit keyed by user ID when available.
-func rateLimitMiddleware(cfg *config.Config, rdb *redis.Client) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		if !cfg.Auth.Enabled || cfg.RateLimit.DefaultPerMinute <= 0 {
-			return c.Next()
-		}
+// tenantRoleFor returns the principal's member role in its active tenant,
+// or "" for principals without a user or tenant and for non-members.
+func tenantRoleFor(ctx context.Context, q *db.Queries, p Principal) string {
+	if q == nil || p.UserID == nil || p.TenantID == nil {
+		return ""
+	}
+	member, err := q.GetTenantMember(ctx, db.GetTenantMemberParams{
+		TenantID: *p.TenantID,
+		UserID:   *p.UserID,
+	})
+	if err != nil {
+		return ""
+	}
+	return member.Role
+}
 
-		limit := cfg.RateLimit.DefaultPerMinute
-		var bucketID string
+// tenantViewerMiddleware makes the API read-only for principals whose role
+// in the active tenant is tenant_viewer: they may read jobs and results and
+// manage their own user settings, but not start jobs or change anything.
+func tenantViewerMiddleware(c *fiber.Ctx) error {
+	val := c.Locals("principal")
+	p, ok := val.(Principal)
+	if !ok || p.IsSystemAdmin || p.TenantRole != roleTenantViewer {
+		return c.Next()
+	}
+	if viewerAllowedRequest(c.Method(), c.Path()) {
+		return c.Next()
+	}
+	return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+		Success: false,
+		Code:    "FORBIDDEN",
+		Error:   "Your role in this tenant is read-only",
+	})
+}
 
-		if val := c.Locals("apiKey"); val != nil {
-			if apiKey, ok := val.(db.ApiKey); ok {
-				if apiKey.RateLimitPerMinute.Valid && apiKey.RateLimitPerMinute.Int32 > 0 {
-					limit = int(apiKey.RateLimitPerMinute.Int32)
-				}
-				bucketID = apiKey.ID.String()
+// viewerAllowedRequest reports whether a tenant viewer may make the
+// request. Reads are allowed except /v1/fetch, which fetches remote pages
+// on the caller's behalf; the only writes allowed are to the caller's own
+// /v1/me settings and switching tenants.
+func viewerAllowedRequest(method, path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	if path == "/v1/me" || strings.HasPrefix(path, "/v1/me/") {
+		return true
+	}
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead:
+		return path != "/v1/fetch"
+	case fiber.MethodPost:
+		parts := strings.Split(path, "/")
+		return len(parts) == 5 && parts[1] == "v1" && parts[2] == "tenants" && parts[4] == "select"
+	}
+	return false
+}
+
+// passwordChangeAllowedPath reports whether a session with a pending forced
+// password change may access path.
+func passwordChangeAllowedPath(path string) bool {
+	switch strings.TrimSuffix(path, "/") {
+	case "/auth/session", "/v1/me", "/v1/me/password":
+		return true
+	}
+	return false
+}
+
+// rateLimitBucket returns the rate limit bucket and per-minute limit for
+// the request: the API key's own limit, or rateLimit.defaultPerMinute. For
+// browser sessions, it falls back to a per-user bucket keyed by user ID
+// when available, and for anonymous callers (public share links) to a
+// per-client-IP bucket.
+func rateLimitBucket(cfg *config.Config, c *fiber.Ctx) (bucketID string, limit int, source string) {
+	limit = cfg.RateLimit.DefaultPerMinute
+	source = "default"
+
+	if val := c.Locals("apiKey"); val != nil {
+		if apiKey, ok := val.(db.ApiKey); ok {
+			if apiKey.RateLimitPerMinute.Valid && apiKey.RateLimitPerMinute.Int32 > 0 {
+				limit = int(apiKey.RateLimitPerMinute.Int32)
+				source = "apiKey"
 			}
+			bucketID = apiKey.ID.String()
 		}
+	}
 
-		if bucketID == "" {
-			// Fall back to per-user bucket for session-based access.
-			if val := c.Locals("principal"); val != nil {
-				if p, ok := val.(Principal); ok && p.UserID != nil {
-					bucketID = p.UserID.String()
-				}
+	if bucketID == "" {
+		// Fall back to per-user bucket for session-based access.
+		if val := c.Locals("principal"); val != nil {
+			if p, ok := val.(Principal); ok && p.UserID != nil {
+				bucketID = p.UserID.String()
 			}
 		}
+	}
+	if bucketID == "" {
+		bucketID = "ip:" + c.IP()
+	}
+	return bucketID, limit, source
+}
+
+// rateLimitMiddleware enforces a simple per-minute fixed-window rate limit
+// per rateLimitBucket using Redis. The request count in the current
+// window is left in c.Locals("rateLimitCount").
+func rateLimitMiddleware(cfg *config.Config, rdb *redis.Client) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !cfg.Auth.Enabled || cfg.RateLimit.DefaultPerMinute <= 0 {
+			return c.Next()
+		}
 
+		bucketID, limit, _ := rateLimitBucket(cfg, c)
 		if bucketID == "" || limit <= 0 {
 			return c.Next()
 		}
@@ -199,6 +373,7 @@ func rateLimitMiddleware(cfg *config.Config, rdb *redis.Client) fiber.Handler {
 			})
 		}
 
+		c.Locals("rateLimitCount", count)
 		return c.Next()
 	}
 }
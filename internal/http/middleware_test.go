@@ -1,8 +1,10 @@
 package http
 
 import (
+	"database/sql"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/google/uuid"
 
 	"raito/internal/config"
+	"raito/internal/db"
 	"raito/internal/store"
 )
 
@@ -103,3 +106,288 @@ func TestAuthMiddleware_SessionMissing(t *testing.T) {
 		t.Fatalf("expected 401, got %d", resp.StatusCode)
 	}
 }
+
+// Test that sessions cannot act on behalf of another tenant.
+func TestAuthMiddleware_OnBehalfOfRequiresAdminKey(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.Enabled = true
+	cfg.Auth.Session.Secret = "test-secret"
+	cfg.Auth.Session.CookieName = "raito_session_test_mw"
+
+	app := fiber.New()
+	app.Use(authMiddleware(cfg, &store.Store{}))
+	app.Get("/protected", func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set(onBehalfOfTenantHeader, "acme")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	}
+}
+
+// Test that anonymous callers, such as share link visitors, are rate
+// limited per client IP rather than skipped.
+func TestRateLimitBucket_AnonymousUsesClientIP(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.RateLimit.DefaultPerMinute = 60
+	userID := uuid.New()
+
+	var got []string
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		bucketID, _, _ := rateLimitBucket(cfg, c)
+		got = append(got, bucketID)
+		c.Locals("principal", Principal{UserID: &userID})
+		bucketID, _, _ = rateLimitBucket(cfg, c)
+		got = append(got, bucketID)
+		return c.SendStatus(http.StatusOK)
+	})
+	if _, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil), -1); err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if len(got) != 2 || !strings.HasPrefix(got[0], "ip:") || got[1] != userID.String() {
+		t.Fatalf("unexpected buckets: %v", got)
+	}
+}
+
+// Test that an admin API key acts in the tenant named by X-Raito-Tenant for
+// one request, and that the switch is audited.
+func TestAuthMiddleware_OnBehalfOfAdminKey(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.Enabled = true
+	homeTenantID := uuid.New()
+	userID := uuid.New()
+	tenant := db.Tenant{ID: uuid.New(), Slug: "acme", Name: "Acme", Type: "org"}
+	fake, conn := newFakeDB(t, map[string][]any{
+		"GetAPIKeyByHash": {db.ApiKey{
+			ID:       uuid.New(),
+			IsAdmin:  true,
+			TenantID: sql.NullString{String: homeTenantID.String(), Valid: true},
+			UserID:   uuid.NullUUID{UUID: userID, Valid: true},
+		}},
+		"GetUserByID":     {db.User{ID: userID, IsSystemAdmin: true}},
+		"GetTenantBySlug": {tenant},
+	})
+
+	var got Principal
+	app := fiber.New()
+	app.Use(authMiddleware(cfg, &store.Store{DB: conn}))
+	app.Get("/protected", func(c *fiber.Ctx) error {
+		got = c.Locals("principal").(Principal)
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer raito_admin")
+	req.Header.Set(onBehalfOfTenantHeader, "acme")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got.TenantID == nil || *got.TenantID != tenant.ID {
+		t.Fatalf("expected principal in tenant %s, got %v", tenant.ID, got.TenantID)
+	}
+
+	call, ok := fake.call("InsertAuditEvent")
+	if !ok {
+		t.Fatalf("expected an audit event, ran %v", fake.ran())
+	}
+	var action, tenantArg any
+	for _, arg := range call.Args {
+		if arg == "api_key.on_behalf_of" {
+			action = arg
+		}
+		if arg == tenant.ID.String() {
+			tenantArg = arg
+		}
+	}
+	if action == nil || tenantArg == nil {
+		t.Fatalf("expected api_key.on_behalf_of audit for tenant %s, got %v", tenant.ID, call.Args)
+	}
+}
+
+// Test that a non-admin API key cannot switch tenants with X-Raito-Tenant.
+func TestAuthMiddleware_OnBehalfOfNonAdminKey(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.Enabled = true
+	homeTenantID := uuid.New()
+	userID := uuid.New()
+	fake, conn := newFakeDB(t, map[string][]any{
+		"GetAPIKeyByHash": {db.ApiKey{
+			ID:       uuid.New(),
+			TenantID: sql.NullString{String: homeTenantID.String(), Valid: true},
+			UserID:   uuid.NullUUID{UUID: userID, Valid: true},
+		}},
+		"GetUserByID":     {db.User{ID: userID}},
+		"GetTenantBySlug": {db.Tenant{ID: uuid.New(), Slug: "acme"}},
+	})
+
+	reached := false
+	app := fiber.New()
+	app.Use(authMiddleware(cfg, &store.Store{DB: conn}))
+	app.Get("/protected", func(c *fiber.Ctx) error {
+		reached = true
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer raito_member")
+	req.Header.Set(onBehalfOfTenantHeader, "acme")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	}
+	if reached {
+		t.Fatalf("handler must not run for a rejected tenant switch")
+	}
+	for _, name := range fake.ran() {
+		if name == "GetTenantBySlug" || name == "GetTenantByID" || name == "InsertAuditEvent" {
+			t.Fatalf("expected no tenant lookup or audit, ran %v", fake.ran())
+		}
+	}
+}
+
+// Test that routeLimitsMiddleware applies the most specific body limit.
+func TestRouteLimitsMiddleware_BodyLimitOverride(t *testing.T) {
+	sc := config.ServerConfig{
+		BodyLimitBytes: 16,
+		Routes: []config.ServerRouteConfig{
+			{Path: "/v1/batch", BodyLimitBytes: 64},
+		},
+	}
+
+	app := fiber.New(serverFiberConfig(sc))
+	app.Use(routeLimitsMiddleware(sc))
+	app.Post("/*", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	body := strings.Repeat("x", 32)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/scrape", strings.NewReader(body))
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for default route, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/batch/scrape", strings.NewReader(body))
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for overridden route, got %d", resp.StatusCode)
+	}
+}
+
+// Test that largeResponse compresses bodies and honours If-None-Match.
+func TestLargeResponse_CompressionAndETag(t *testing.T) {
+	app := fiber.New()
+	payload := strings.Repeat(`{"markdown":"hello world"},`, 200)
+	app.Get("/status", largeResponse(func(c *fiber.Ctx) error {
+		c.Type("json")
+		return c.SendString(payload)
+	})...)
+
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip content encoding, got %q", resp.Header.Get("Content-Encoding"))
+	}
+	tag := resp.Header.Get("ETag")
+	if tag == "" {
+		t.Fatalf("expected ETag header")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/status", nil)
+	req.Header.Set("If-None-Match", tag)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test error: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", resp.StatusCode)
+	}
+}
+
+func TestViewerAllowedRequest(t *testing.T) {
+	cases := []struct {
+		method, path string
+		want         bool
+	}{
+		{fiber.MethodGet, "/v1/jobs", true},
+		{fiber.MethodGet, "/v1/jobs/abc/download", true},
+		{fiber.MethodGet, "/v2/crawl/abc", true},
+		{fiber.MethodGet, "/v1/fetch", false},
+		{fiber.MethodPost, "/v1/crawl", false},
+		{fiber.MethodPost, "/v2/scrape", false},
+		{fiber.MethodDelete, "/v1/jobs/abc", false},
+		{fiber.MethodPatch, "/v1/jobs/abc/documents/1", false},
+		{fiber.MethodPost, "/v1/tenants/abc/api-keys", false},
+		{fiber.MethodPost, "/v1/tenants/abc/select", true},
+		{fiber.MethodPatch, "/v1/me", true},
+		{fiber.MethodPut, "/v1/me/notifications", true},
+	}
+	for _, tc := range cases {
+		if got := viewerAllowedRequest(tc.method, tc.path); got != tc.want {
+			t.Fatalf("viewerAllowedRequest(%s %s) = %v, want %v", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestTenantViewerMiddleware(t *testing.T) {
+	newApp := func(p Principal) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("principal", p)
+			return c.Next()
+		})
+		app.Use(tenantViewerMiddleware)
+		app.All("/v1/*", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+		return app
+	}
+
+	status := func(app *fiber.App, method, path string) int {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil), -1)
+		if err != nil {
+			t.Fatalf("app.Test error: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	viewer := newApp(Principal{TenantRole: roleTenantViewer})
+	if got := status(viewer, http.MethodPost, "/v1/crawl"); got != http.StatusForbidden {
+		t.Fatalf("expected viewers to be refused, got %d", got)
+	}
+	if got := status(viewer, http.MethodGet, "/v1/jobs"); got != http.StatusOK {
+		t.Fatalf("expected viewers to read jobs, got %d", got)
+	}
+
+	member := newApp(Principal{TenantRole: roleTenantMember})
+	if got := status(member, http.MethodPost, "/v1/crawl"); got != http.StatusOK {
+		t.Fatalf("expected members to be unaffected, got %d", got)
+	}
+	admin := newApp(Principal{IsSystemAdmin: true, TenantRole: roleTenantViewer})
+	if got := status(admin, http.MethodPost, "/v1/crawl"); got != http.StatusOK {
+		t.Fatalf("expected system admins to be unaffected, got %d", got)
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+)
+
+// Prompt template kinds. Summary, branding and json templates are used by
+// the formats of the same name; extract templates supply the system prompt
+// of /v1/extract.
+const (
+	promptKindSummary  = "summary"
+	promptKindBranding = "branding"
+	promptKindJSON     = "json"
+	promptKindExtract  = "extract"
+)
+
+var promptTemplateKinds = map[string]bool{
+	promptKindSummary:  true,
+	promptKindBranding: true,
+	promptKindJSON:     true,
+	promptKindExtract:  true,
+}
+
+// maxPromptTemplateLength bounds the stored prompt text in bytes.
+const maxPromptTemplateLength = 32 * 1024
+
+var (
+	errPromptTemplateNotFound = errors.New("prompt template not found")
+	errPromptTemplateNoTenant = errors.New("prompt templates require a tenant-scoped API key or session")
+	errPromptTemplateInvalid  = errors.New("invalid prompt template reference")
+)
+
+// promptTemplateGetter is the subset of db.Queries used to resolve
+// template references.
+type promptTemplateGetter interface {
+	GetLatestPromptTemplate(ctx context.Context, arg db.GetLatestPromptTemplateParams) (db.PromptTemplate, error)
+	GetPromptTemplateVersion(ctx context.Context, arg db.GetPromptTemplateVersionParams) (db.PromptTemplate, error)
+}
+
+// lookupPromptTemplate returns the given version of a tenant's template,
+// or the latest one when version is 0.
+func lookupPromptTemplate(ctx context.Context, q promptTemplateGetter, tenantID uuid.UUID, name string, version int) (db.PromptTemplate, error) {
+	var (
+		tpl db.PromptTemplate
+		err error
+	)
+	if version > 0 {
+		tpl, err = q.GetPromptTemplateVersion(ctx, db.GetPromptTemplateVersionParams{TenantID: tenantID, Name: name, Version: int32(version)})
+	} else {
+		tpl, err = q.GetLatestPromptTemplate(ctx, db.GetLatestPromptTemplateParams{TenantID: tenantID, Name: name})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		if version > 0 {
+			return tpl, fmt.Errorf("%w: %q version %d", errPromptTemplateNotFound, name, version)
+		}
+		return tpl, fmt.Errorf("%w: %q", errPromptTemplateNotFound, name)
+	}
+	return tpl, err
+}
+
+// applyPromptTemplate looks up a template reference for kind and returns
+// the template prompt followed by any prompt given in the request.
+func applyPromptTemplate(ctx context.Context, q promptTemplateGetter, tenantID *uuid.UUID, kind, name string, version int, prompt string) (string, db.PromptTemplate, error) {
+	if tenantID == nil {
+		return "", db.PromptTemplate{}, errPromptTemplateNoTenant
+	}
+	tpl, err := lookupPromptTemplate(ctx, q, *tenantID, name, version)
+	if err != nil {
+		return "", tpl, err
+	}
+	if tpl.Kind != kind {
+		return "", tpl, fmt.Errorf("%w: %q is a %s template and cannot be used for %s", errPromptTemplateInvalid, name, tpl.Kind, kind)
+	}
+	if prompt == "" {
+		return tpl.Prompt, tpl, nil
+	}
+	return tpl.Prompt + "\n\n" + prompt, tpl, nil
+}
+
+// resolveFormatTemplates replaces template references in summary, branding
+// and json formats ({"type": "summary", "template": "name"}) with the
+// template's prompt. The resolved version is written back as
+// templateVersion so that queued jobs run with the version that was current
+// when they were submitted.
+func resolveFormatTemplates(ctx context.Context, q promptTemplateGetter, tenantID *uuid.UUID, formats []any) error {
+	for _, f := range formats {
+		m, ok := f.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := m["template"].(string)
+		if name == "" {
+			continue
+		}
+		kind, _ := m["type"].(string)
+		kind = strings.ToLower(kind)
+		if kind != promptKindSummary && kind != promptKindBranding && kind != promptKindJSON {
+			return fmt.Errorf("%w: templates are only supported by the summary, branding and json formats, not %q", errPromptTemplateInvalid, kind)
+		}
+		version := 0
+		if v, ok := m["templateVersion"].(float64); ok {
+			version = int(v)
+		}
+		prompt, _ := m["prompt"].(string)
+		resolved, tpl, err := applyPromptTemplate(ctx, q, tenantID, kind, name, version, prompt)
+		if err != nil {
+			return err
+		}
+		m["prompt"] = resolved
+		m["templateVersion"] = int(tpl.Version)
+	}
+	return nil
+}
+
+// resolveExtractTemplate applies an extract request's template to its
+// system prompt.
+func resolveExtractTemplate(ctx context.Context, q promptTemplateGetter, tenantID *uuid.UUID, req *ExtractRequest) error {
+	if req.Template == "" {
+		return nil
+	}
+	resolved, tpl, err := applyPromptTemplate(ctx, q, tenantID, promptKindExtract, req.Template, req.TemplateVersion, req.SystemPrompt)
+	if err != nil {
+		return err
+	}
+	req.SystemPrompt = resolved
+	req.TemplateVersion = int(tpl.Version)
+	return nil
+}
+
+// promptTemplateStatus maps a template resolution error to an HTTP status
+// and error code.
+func promptTemplateStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, errPromptTemplateNotFound):
+		return fiber.StatusBadRequest, "PROMPT_TEMPLATE_NOT_FOUND"
+	case errors.Is(err, errPromptTemplateNoTenant):
+		return fiber.StatusBadRequest, "TENANT_REQUIRED"
+	case errors.Is(err, errPromptTemplateInvalid):
+		return fiber.StatusBadRequest, "BAD_REQUEST"
+	default:
+		return fiber.StatusInternalServerError, "PROMPT_TEMPLATE_LOOKUP_FAILED"
+	}
+}
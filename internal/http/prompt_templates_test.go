@@ -0,0 +1,106 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+)
+
+type fakePromptTemplates struct {
+	versions []db.PromptTemplate
+}
+
+func (f *fakePromptTemplates) GetLatestPromptTemplate(_ context.Context, arg db.GetLatestPromptTemplateParams) (db.PromptTemplate, error) {
+	var latest *db.PromptTemplate
+	for i, t := range f.versions {
+		if t.TenantID == arg.TenantID && t.Name == arg.Name && (latest == nil || t.Version > latest.Version) {
+			latest = &f.versions[i]
+		}
+	}
+	if latest == nil {
+		return db.PromptTemplate{}, sql.ErrNoRows
+	}
+	return *latest, nil
+}
+
+func (f *fakePromptTemplates) GetPromptTemplateVersion(_ context.Context, arg db.GetPromptTemplateVersionParams) (db.PromptTemplate, error) {
+	for _, t := range f.versions {
+		if t.TenantID == arg.TenantID && t.Name == arg.Name && t.Version == arg.Version {
+			return t, nil
+		}
+	}
+	return db.PromptTemplate{}, sql.ErrNoRows
+}
+
+func TestResolveFormatTemplates(t *testing.T) {
+	tenant := uuid.New()
+	q := &fakePromptTemplates{versions: []db.PromptTemplate{
+		{TenantID: tenant, Name: "brief", Kind: promptKindSummary, Version: 1, Prompt: "v1"},
+		{TenantID: tenant, Name: "brief", Kind: promptKindSummary, Version: 2, Prompt: "v2"},
+		{TenantID: tenant, Name: "facts", Kind: promptKindExtract, Version: 1, Prompt: "extract facts"},
+	}}
+
+	formats := []any{
+		"markdown",
+		map[string]any{"type": "summary", "template": "brief"},
+		map[string]any{"type": "summary", "template": "brief", "templateVersion": float64(1), "prompt": "Be brief."},
+	}
+	if err := resolveFormatTemplates(context.Background(), q, &tenant, formats); err != nil {
+		t.Fatalf("resolveFormatTemplates: %v", err)
+	}
+	latest := formats[1].(map[string]any)
+	if latest["prompt"] != "v2" || latest["templateVersion"] != 2 {
+		t.Fatalf("expected latest version, got %+v", latest)
+	}
+	pinned := formats[2].(map[string]any)
+	if pinned["prompt"] != "v1\n\nBe brief." || pinned["templateVersion"] != 1 {
+		t.Fatalf("expected pinned version with appended prompt, got %+v", pinned)
+	}
+
+	cases := []struct {
+		name     string
+		tenantID *uuid.UUID
+		format   map[string]any
+		want     error
+	}{
+		{"unknown", &tenant, map[string]any{"type": "summary", "template": "missing"}, errPromptTemplateNotFound},
+		{"missing version", &tenant, map[string]any{"type": "summary", "template": "brief", "templateVersion": float64(9)}, errPromptTemplateNotFound},
+		{"kind mismatch", &tenant, map[string]any{"type": "summary", "template": "facts"}, errPromptTemplateInvalid},
+		{"unsupported format", &tenant, map[string]any{"type": "markdown", "template": "brief"}, errPromptTemplateInvalid},
+		{"no tenant", nil, map[string]any{"type": "summary", "template": "brief"}, errPromptTemplateNoTenant},
+	}
+	for _, tc := range cases {
+		err := resolveFormatTemplates(context.Background(), q, tc.tenantID, []any{tc.format})
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+		if status, _ := promptTemplateStatus(err); status != 400 {
+			t.Fatalf("%s: expected 400, got %d", tc.name, status)
+		}
+	}
+}
+
+func TestResolveExtractTemplate(t *testing.T) {
+	tenant := uuid.New()
+	q := &fakePromptTemplates{versions: []db.PromptTemplate{
+		{TenantID: tenant, Name: "facts", Kind: promptKindExtract, Version: 3, Prompt: "extract facts"},
+	}}
+
+	req := &ExtractRequest{Template: "facts", SystemPrompt: "Prices in EUR."}
+	if err := resolveExtractTemplate(context.Background(), q, &tenant, req); err != nil {
+		t.Fatalf("resolveExtractTemplate: %v", err)
+	}
+	if req.SystemPrompt != "extract facts\n\nPrices in EUR." || req.TemplateVersion != 3 {
+		t.Fatalf("unexpected request after resolving: %+v", req)
+	}
+
+	plain := &ExtractRequest{SystemPrompt: "unchanged"}
+	if err := resolveExtractTemplate(context.Background(), q, nil, plain); err != nil || plain.SystemPrompt != "unchanged" {
+		t.Fatalf("expected requests without a template to be untouched, got %q, %v", plain.SystemPrompt, err)
+	}
+}
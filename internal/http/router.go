@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/gofiber/fiber/v2/middleware/etag"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"raito/internal/config"
@@ -22,11 +24,14 @@ type Server struct {
 }
 
 func NewServer(cfg *config.Config, st *store.Store, logger *slog.Logger) *Server {
-	app := fiber.New()
+	app := fiber.New(serverFiberConfig(cfg.Server))
 
 	// Construct a job queue-backed executor for heavy operations
 	exec := NewJobQueueExecutor(cfg, st, logger)
 
+	// Per-host outcomes of synchronous scrapes and fetches.
+	startHostStatsFlusher(context.Background(), st)
+
 	// Inject config, store, and executor into context for handlers
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("config", cfg)
@@ -35,6 +40,9 @@ func NewServer(cfg *config.Config, st *store.Store, logger *slog.Logger) *Server
 		return c.Next()
 	})
 
+	// Per-route body limits and timeouts (server.routes in config)
+	app.Use(routeLimitsMiddleware(cfg.Server))
+
 	// Request logging + metrics middleware
 	app.Use(func(c *fiber.Ctx) error {
 		start := time.Now()
@@ -56,7 +64,9 @@ func NewServer(cfg *config.Config, st *store.Store, logger *slog.Logger) *Server
 		method := c.Method()
 		path := c.Path()
 
-		metrics.RecordRequest(method, path, status, latency.Milliseconds())
+		// Label metrics with the matched route template, e.g.
+		// "/v1/jobs/:id", so IDs in paths do not create new series.
+		metrics.RecordRequest(method, c.Route().Path, status, latency.Milliseconds())
 
 		if logger != nil {
 			attrs := []any{
@@ -78,6 +88,9 @@ func NewServer(cfg *config.Config, st *store.Store, logger *slog.Logger) *Server
 		return err
 	})
 
+	// Message keys and Accept-Language translations for error responses.
+	app.Use(localizeErrorsMiddleware)
+
 	// Redis client for rate limiting and health checks
 	var rdb *redis.Client
 	if cfg.Auth.Enabled && cfg.Redis.URL != "" {
@@ -148,25 +161,88 @@ func NewServer(cfg *config.Config, st *store.Store, logger *slog.Logger) *Server
 		rateMw = func(c *fiber.Ctx) error { return c.Next() }
 	}
 
+	// Public, read-only job results behind signed share links. Callers are
+	// anonymous, so they are rate limited per client IP.
+	app.Get("/share/:token", rateMw, sharedJobHandler)
+	app.Get("/share/:token/download", rateMw, sharedJobDownloadHandler)
+
 	// Session inspection endpoint for browser clients (auth required)
 	app.Get("/auth/session", authMw, rateMw, meHandler)
 
-	v1 := app.Group("/v1", authMw, rateMw)
+	v1 := app.Group("/v1", authMw, rateMw, tenantViewerMiddleware)
 	v1.Get("/tenants", listTenantsHandler)
 	v1.Get("/tenants/:id/usage", tenantUsageHandler)
+	v1.Get("/tenants/:id/llm-budget", tenantLLMBudgetHandler)
+	v1.Get("/tenants/:id/llm-policy", tenantLLMPolicyHandler)
 	v1.Post("/tenants/:id/select", selectTenantHandler)
-	v1.Get("/jobs", jobsListHandler)
+	v1.Get("/jobs", largeResponse(jobsListHandler)...)
+	v1.Get("/jobs/search", jobsSearchHandler)
 	v1.Get("/jobs/:id", jobDetailHandler)
+	v1.Patch("/jobs/:id", jobUpdateHandler)
 	v1.Delete("/jobs/:id", jobDeleteHandler)
-	v1.Get("/jobs/:id/download", jobDownloadHandler)
+	v1.Get("/jobs/:id/download", largeResponse(jobDownloadHandler)...)
+	// Not wrapped in largeResponse: its ETag and compression would buffer
+	// the streamed body.
+	v1.Get("/jobs/:id/result", jobResultHandler)
+	v1.Get("/jobs/:id/events", jobEventsHandler)
+	v1.Get("/jobs/:id/events/stream", jobEventsStreamHandler)
+	v1.Patch("/jobs/:id/documents/:docId", jobDocumentAnnotateHandler)
+	v1.Get("/documents/diff", largeResponse(documentDiffHandler)...)
+	v1.Get("/documents/changes", largeResponse(documentChangesHandler)...)
+	v1.Get("/documents/by-url", largeResponse(documentByURLHandler)...)
+	v1.Get("/jobs/:id/assets/:assetId", jobAssetHandler)
+	v1.Get("/jobs/:id/evidence/verify", jobEvidenceVerifyHandler)
+	v1.Post("/jobs/:id/share", jobShareCreateHandler)
+	v1.Get("/jobs/:id/shares", jobSharesListHandler)
+	v1.Delete("/jobs/:id/share", jobShareRevokeHandler)
+	v1.Delete("/jobs/:id/share/:shareId", jobShareRevokeHandler)
+	v1.Get("/collections", collectionsListHandler)
+	v1.Post("/collections", collectionCreateHandler)
+	v1.Get("/collections/:id", collectionDetailHandler)
+	v1.Patch("/collections/:id", collectionUpdateHandler)
+	v1.Delete("/collections/:id", collectionDeleteHandler)
+	v1.Get("/collections/:id/jobs", largeResponse(collectionJobsHandler)...)
+	v1.Get("/collections/:id/documents", largeResponse(collectionDocumentsHandler)...)
 	v1.Post("/tenants/:id/api-keys", tenantCreateAPIKeyHandler)
 	v1.Get("/tenants/:id/api-keys", tenantListAPIKeysHandler)
+	v1.Post("/tenants/:id/api-keys/revoke", tenantRevokeAPIKeysHandler)
+	v1.Post("/tenants/:id/api-keys/reveal", tenantRevealAPIKeyHandler)
 	v1.Delete("/tenants/:id/api-keys/:keyID", tenantRevokeAPIKeyHandler)
+	v1.Get("/tenants/:id/secrets", tenantListSecretsHandler)
+	v1.Put("/tenants/:id/secrets/:name", tenantPutSecretHandler)
+	v1.Delete("/tenants/:id/secrets/:name", tenantDeleteSecretHandler)
+	v1.Get("/tenants/:id/webhook-secret", tenantGetWebhookSecretHandler)
+	v1.Delete("/tenants/:id/webhook-secret", tenantDeleteWebhookSecretHandler)
+	v1.Post("/tenants/:id/webhook-secret/rotate", tenantRotateWebhookSecretHandler)
+	v1.Post("/tenants/:id/webhook-secret/verify", tenantVerifyWebhookSignatureHandler)
+	v1.Get("/tenants/:id/webhook", tenantGetWebhookHandler)
+	v1.Put("/tenants/:id/webhook", tenantPutWebhookHandler)
+	v1.Delete("/tenants/:id/webhook", tenantDeleteWebhookHandler)
+	v1.Get("/tenants/:id/prompt-templates", tenantListPromptTemplatesHandler)
+	v1.Get("/tenants/:id/prompt-templates/:name", tenantGetPromptTemplateVersionsHandler)
+	v1.Put("/tenants/:id/prompt-templates/:name", tenantPutPromptTemplateHandler)
+	v1.Delete("/tenants/:id/prompt-templates/:name", tenantDeletePromptTemplateHandler)
+	v1.Post("/tenants/:id/prompt-templates/:name/test", tenantTestPromptTemplateHandler)
+	v1.Get("/tenants/:id/transform-hook", tenantGetTransformHookHandler)
+	v1.Put("/tenants/:id/transform-hook", tenantPutTransformHookHandler)
+	v1.Delete("/tenants/:id/transform-hook", tenantDeleteTransformHookHandler)
+	v1.Get("/tenants/:id/default-formats", tenantGetDefaultFormatsHandler)
+	v1.Put("/tenants/:id/default-formats", tenantPutDefaultFormatsHandler)
+	v1.Delete("/tenants/:id/default-formats", tenantDeleteDefaultFormatsHandler)
 	registerV1Routes(v1)
 
+	// Firecrawl v2 aliases so upstream SDKs can target Raito unchanged.
+	v2 := app.Group("/v2", authMw, rateMw, tenantViewerMiddleware)
+	registerV2Routes(v2)
+
 	admin := app.Group("/admin", authMw, adminOnlyMiddleware)
 	registerAdminRoutes(admin)
 
+	// SCIM 2.0 provisioning for identity providers, authenticated with a
+	// system admin API key.
+	scim := app.Group("/scim/v2", scimEnabledMiddleware, authMw, adminOnlyMiddleware)
+	registerSCIMRoutes(scim)
+
 	// Serve the embedded frontend (if compiled with -tags embedwebui).
 	// This must be registered last so API routes take precedence.
 	registerWebUIRoutes(app)
@@ -184,16 +260,52 @@ func (s *Server) Listen() error {
 	return s.app.Listen(addr)
 }
 
+// largeResponse wraps a GET handler that can return large result sets with
+// gzip/brotli compression and ETag/If-None-Match support, so polling clients
+// get a 304 instead of re-downloading unchanged JSON. The ETag is weak since
+// it is computed over the uncompressed body. Errors are localized before
+// compression, which hides them from the app-wide localizeErrorsMiddleware.
+func largeResponse(h fiber.Handler) []fiber.Handler {
+	return []fiber.Handler{
+		compress.New(compress.Config{Level: compress.LevelBestSpeed}),
+		etag.New(etag.Config{Weak: true}),
+		localizeErrorsMiddleware,
+		h,
+	}
+}
+
 func registerV1Routes(group fiber.Router) {
 	group.Post("/scrape", scrapeHandler)
 	group.Post("/map", mapHandler)
 	group.Post("/crawl", crawlHandler)
-	group.Get("/crawl/:id", crawlStatusHandler)
+	group.Post("/crawl/preview", crawlPreviewHandler)
+	group.Get("/crawl/:id", largeResponse(crawlStatusHandler)...)
+	group.Get("/crawl/:id/structured-data", largeResponse(crawlStructuredDataHandler)...)
+	group.Get("/crawl/:id/security-headers", largeResponse(crawlSecurityHeadersHandler)...)
+	group.Post("/crawl/:id/ask", crawlAskHandler)
+	group.Post("/crawl/:id/rescrape", crawlRescrapeHandler)
+	group.Post("/crawl/:id/pause", crawlPauseHandler)
+	group.Post("/crawl/:id/resume", crawlResumeHandler)
+	group.Get("/crawl/:id/rescrape/:rescrapeId", crawlRescrapeStatusHandler)
 	group.Post("/extract", extractHandler)
-	group.Get("/extract/:id", extractStatusHandler)
+	group.Post("/extract/preview", extractPreviewHandler)
+	group.Get("/extract/schema-presets", extractSchemaPresetsHandler)
+	group.Get("/extract/:id", largeResponse(extractStatusHandler)...)
 	group.Post("/batch/scrape", batchScrapeHandler)
-	group.Get("/batch/scrape/:id", batchScrapeStatusHandler)
+	group.Get("/batch/scrape/:id", largeResponse(batchScrapeStatusHandler)...)
 	group.Post("/search", searchHandler)
+	group.Post("/research", researchHandler)
+	group.Get("/research/:id", largeResponse(researchStatusHandler)...)
+	group.Post("/summarize", summarizeHandler)
+	group.Get("/summarize/:id", largeResponse(summarizeStatusHandler)...)
+	group.Post("/parse", parseHandler)
+	group.Get("/fetch", fetchHandler)
+	group.Post("/fetch", fetchHandler)
+	group.Get("/limits", limitsHandler)
 	group.Get("/me", meHandler)
 	group.Patch("/me", updateMeHandler)
+	group.Patch("/me/default-tenant", setDefaultTenantHandler)
+	group.Post("/me/password", changePasswordHandler)
+	group.Get("/me/notifications", getNotificationPreferencesHandler)
+	group.Put("/me/notifications", updateNotificationPreferencesHandler)
 }
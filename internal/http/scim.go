@@ -0,0 +1,503 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+)
+
+// SCIM schema and message URNs (RFC 7643, RFC 7644).
+const (
+	scimUserSchema      = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema     = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema      = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema     = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimSPConfigSchema  = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimResourceTypeURN = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	scimContentType     = "application/scim+json"
+)
+
+// Paging limits for SCIM list requests.
+const (
+	scimDefaultCount = 100
+	scimMaxCount     = 200
+)
+
+// scimProvider is the auth provider of users created over SCIM. They
+// sign in with OIDC; the first login links their OIDC subject.
+const scimProvider = "oidc"
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type scimUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *scimName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []scimEmail `json:"emails,omitempty"`
+	Active      bool        `json:"active"`
+	Groups      []scimRef   `json:"groups,omitempty"`
+	Meta        scimMeta    `json:"meta"`
+}
+
+type scimGroup struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id"`
+	ExternalID  string    `json:"externalId,omitempty"`
+	DisplayName string    `json:"displayName"`
+	Members     []scimRef `json:"members"`
+	Meta        scimMeta  `json:"meta"`
+}
+
+type scimListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int64    `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+type scimErrorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// scimBool is a boolean that also accepts the strings "true" and
+// "false", which some IdPs send in PATCH requests.
+type scimBool bool
+
+func (b *scimBool) UnmarshalJSON(data []byte) error {
+	var v bool
+	if err := json.Unmarshal(data, &v); err == nil {
+		*b = scimBool(v)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("expected a boolean")
+	}
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true":
+		*b = true
+	case "false":
+		*b = false
+	default:
+		return fmt.Errorf("expected a boolean, got %q", s)
+	}
+	return nil
+}
+
+// scimUserRequest is the body of a user POST or PUT.
+type scimUserRequest struct {
+	UserName    string      `json:"userName"`
+	ExternalID  string      `json:"externalId"`
+	DisplayName string      `json:"displayName"`
+	Name        *scimName   `json:"name"`
+	Emails      []scimEmail `json:"emails"`
+	Active      *scimBool   `json:"active"`
+}
+
+// scimUserAttrs are the user attributes Raito keeps.
+type scimUserAttrs struct {
+	UserName    string
+	DisplayName string
+	GivenName   string
+	FamilyName  string
+	Formatted   string
+	ExternalID  string
+	Active      bool
+}
+
+// attrs converts a POST or PUT body; absent attributes are cleared and
+// users are active unless active is false.
+func (r scimUserRequest) attrs() scimUserAttrs {
+	a := scimUserAttrs{
+		UserName:    r.UserName,
+		DisplayName: r.DisplayName,
+		ExternalID:  r.ExternalID,
+		Active:      r.Active == nil || bool(*r.Active),
+	}
+	if r.Name != nil {
+		a.GivenName = r.Name.GivenName
+		a.FamilyName = r.Name.FamilyName
+		a.Formatted = r.Name.Formatted
+	}
+	// Raito identifies users by email, so a userName that is not one
+	// falls back to the primary email.
+	if !strings.Contains(a.UserName, "@") {
+		for _, e := range r.Emails {
+			if e.Primary || !strings.Contains(a.UserName, "@") {
+				a.UserName = e.Value
+			}
+		}
+	}
+	return a
+}
+
+// email is the user's normalized email address.
+func (a scimUserAttrs) email() string {
+	return strings.ToLower(strings.TrimSpace(a.UserName))
+}
+
+// name is the single display name Raito stores.
+func (a scimUserAttrs) name() string {
+	if full := strings.TrimSpace(a.GivenName + " " + a.FamilyName); full != "" {
+		return full
+	}
+	if s := strings.TrimSpace(a.DisplayName); s != "" {
+		return s
+	}
+	return strings.TrimSpace(a.Formatted)
+}
+
+// scimUserAttrsOf returns the current attributes of a stored user.
+func scimUserAttrsOf(u db.User, externalID sql.NullString) scimUserAttrs {
+	return scimUserAttrs{
+		UserName:    u.Email,
+		DisplayName: u.Name.String,
+		ExternalID:  externalID.String,
+		Active:      !u.IsDisabled,
+	}
+}
+
+type scimPatchRequest struct {
+	Operations []scimPatchOp `json:"Operations"`
+}
+
+type scimPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// errSCIMInvalidValue marks PATCH values of the wrong type.
+var errSCIMInvalidValue = errors.New("invalid value")
+
+// applyUserPatch applies PATCH operations to a. Attributes Raito does not
+// keep, such as phone numbers or addresses, are ignored.
+func applyUserPatch(a *scimUserAttrs, ops []scimPatchOp) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path == "" {
+				var values map[string]json.RawMessage
+				if err := json.Unmarshal(op.Value, &values); err != nil {
+					return fmt.Errorf("%w: value must be an object when path is empty", errSCIMInvalidValue)
+				}
+				for k, v := range values {
+					if strings.EqualFold(k, "name") {
+						var sub map[string]json.RawMessage
+						if err := json.Unmarshal(v, &sub); err != nil {
+							return fmt.Errorf("%w: name must be an object", errSCIMInvalidValue)
+						}
+						for sk, sv := range sub {
+							if err := setUserAttr(a, "name."+sk, sv); err != nil {
+								return err
+							}
+						}
+						continue
+					}
+					if err := setUserAttr(a, k, v); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := setUserAttr(a, op.Path, op.Value); err != nil {
+				return err
+			}
+		case "remove":
+			// Removing active has no meaning; other attributes are cleared.
+			if !strings.EqualFold(op.Path, "active") {
+				_ = setUserAttr(a, op.Path, json.RawMessage(`""`))
+			}
+		default:
+			return fmt.Errorf("%w: unsupported op %q", errSCIMInvalidValue, op.Op)
+		}
+	}
+	return nil
+}
+
+func setUserAttr(a *scimUserAttrs, path string, value json.RawMessage) error {
+	var target *string
+	switch strings.ToLower(path) {
+	case "active":
+		var b scimBool
+		if err := json.Unmarshal(value, &b); err != nil {
+			return fmt.Errorf("%w: active: %v", errSCIMInvalidValue, err)
+		}
+		a.Active = bool(b)
+		return nil
+	case "username":
+		target = &a.UserName
+	case "displayname":
+		target = &a.DisplayName
+	case "externalid":
+		target = &a.ExternalID
+	case "name.givenname":
+		target = &a.GivenName
+	case "name.familyname":
+		target = &a.FamilyName
+	case "name.formatted":
+		target = &a.Formatted
+	default:
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return fmt.Errorf("%w: %s must be a string", errSCIMInvalidValue, path)
+	}
+	*target = s
+	return nil
+}
+
+// scimGroupState is a group as PATCH operations change it.
+type scimGroupState struct {
+	DisplayName string
+	ExternalID  string
+	Members     map[uuid.UUID]bool
+}
+
+// scimMemberFilterRe matches a member value filter such as
+// members[value eq "2819c223-7f76-453a-919d-413861904646"].
+var scimMemberFilterRe = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]+)"\s*\]$`)
+
+// applyGroupPatch applies PATCH operations to g.
+func applyGroupPatch(g *scimGroupState, ops []scimPatchOp) error {
+	for _, op := range ops {
+		kind := strings.ToLower(op.Op)
+		if kind != "add" && kind != "replace" && kind != "remove" {
+			return fmt.Errorf("%w: unsupported op %q", errSCIMInvalidValue, op.Op)
+		}
+
+		if m := scimMemberFilterRe.FindStringSubmatch(op.Path); m != nil {
+			if kind != "remove" {
+				return fmt.Errorf("%w: filtered member paths only support remove", errSCIMInvalidValue)
+			}
+			id, err := uuid.Parse(m[1])
+			if err != nil {
+				return fmt.Errorf("%w: member %q is not a user id", errSCIMInvalidValue, m[1])
+			}
+			delete(g.Members, id)
+			continue
+		}
+
+		switch strings.ToLower(op.Path) {
+		case "":
+			if kind == "remove" {
+				return fmt.Errorf("%w: remove requires a path", errSCIMInvalidValue)
+			}
+			var values map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return fmt.Errorf("%w: value must be an object when path is empty", errSCIMInvalidValue)
+			}
+			for k, v := range values {
+				if err := applyGroupPatch(g, []scimPatchOp{{Op: op.Op, Path: k, Value: v}}); err != nil {
+					return err
+				}
+			}
+		case "displayname":
+			if kind == "remove" {
+				return fmt.Errorf("%w: displayName is required", errSCIMInvalidValue)
+			}
+			if err := json.Unmarshal(op.Value, &g.DisplayName); err != nil {
+				return fmt.Errorf("%w: displayName must be a string", errSCIMInvalidValue)
+			}
+		case "externalid":
+			if kind == "remove" {
+				g.ExternalID = ""
+				continue
+			}
+			if err := json.Unmarshal(op.Value, &g.ExternalID); err != nil {
+				return fmt.Errorf("%w: externalId must be a string", errSCIMInvalidValue)
+			}
+		case "members":
+			var ids []uuid.UUID
+			if len(op.Value) > 0 && string(op.Value) != "null" {
+				var err error
+				if ids, err = scimMemberIDs(op.Value); err != nil {
+					return err
+				}
+			}
+			switch {
+			case kind == "replace":
+				g.Members = make(map[uuid.UUID]bool, len(ids))
+				fallthrough
+			case kind == "add":
+				for _, id := range ids {
+					g.Members[id] = true
+				}
+			case len(ids) == 0:
+				g.Members = make(map[uuid.UUID]bool)
+			default:
+				for _, id := range ids {
+					delete(g.Members, id)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// scimMemberIDs parses a members value: a list of {"value": id} objects.
+func scimMemberIDs(raw json.RawMessage) ([]uuid.UUID, error) {
+	var refs []scimRef
+	if err := json.Unmarshal(raw, &refs); err != nil {
+		var one scimRef
+		if err := json.Unmarshal(raw, &one); err != nil {
+			return nil, fmt.Errorf("%w: members must be a list of {\"value\": id}", errSCIMInvalidValue)
+		}
+		refs = []scimRef{one}
+	}
+	ids := make([]uuid.UUID, 0, len(refs))
+	for _, r := range refs {
+		id, err := uuid.Parse(r.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: member %q is not a user id", errSCIMInvalidValue, r.Value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// scimFilterRe matches the only filter form IdPs need for lookups:
+// attribute eq "value".
+var scimFilterRe = regexp.MustCompile(`(?i)^\s*([a-z.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// parseSCIMFilter parses an equality filter and returns the attribute,
+// lower-cased, and the value.
+func parseSCIMFilter(filter string) (string, string, error) {
+	m := scimFilterRe.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", fmt.Errorf("unsupported filter %q; only 'attribute eq \"value\"' is supported", filter)
+	}
+	var value string
+	if err := json.Unmarshal([]byte(`"`+m[2]+`"`), &value); err != nil {
+		return "", "", fmt.Errorf("invalid filter value %q", m[2])
+	}
+	return strings.ToLower(m[1]), value, nil
+}
+
+// scimRoleRank orders tenant roles so the highest mapped role wins.
+func scimRoleRank(role string) int {
+	switch role {
+	case roleTenantAdmin:
+		return 3
+	case roleTenantMember:
+		return 2
+	case roleTenantViewer:
+		return 1
+	}
+	return 0
+}
+
+// scimTenantRoles returns the role each tenant slug should give a user in
+// the named groups, and every tenant slug the mappings manage.
+func scimTenantRoles(mappings []config.SCIMGroupMapping, groups []string) (map[string]string, []string) {
+	roles := make(map[string]string)
+	var managed []string
+	seen := make(map[string]bool)
+	for _, m := range mappings {
+		slug := strings.TrimSpace(m.Tenant)
+		if slug == "" {
+			continue
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			managed = append(managed, slug)
+		}
+		role := m.Role
+		if role == "" {
+			role = roleTenantMember
+		}
+		for _, g := range groups {
+			if strings.EqualFold(strings.TrimSpace(g), strings.TrimSpace(m.Group)) && scimRoleRank(role) > scimRoleRank(roles[slug]) {
+				roles[slug] = role
+			}
+		}
+	}
+	return roles, managed
+}
+
+// syncSCIMTenants brings userID's memberships of SCIM-managed tenants in
+// line with its groups: it adds missing memberships, corrects roles, and
+// removes memberships no group grants. Mapped tenants that do not exist
+// are skipped.
+func syncSCIMTenants(ctx context.Context, q *db.Queries, mappings []config.SCIMGroupMapping, userID uuid.UUID) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+	groups, err := q.ListSCIMGroupsForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.DisplayName)
+	}
+	roles, managed := scimTenantRoles(mappings, names)
+
+	for _, slug := range managed {
+		tenant, err := q.GetTenantBySlug(ctx, slug)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		member, err := q.GetTenantMember(ctx, db.GetTenantMemberParams{TenantID: tenant.ID, UserID: userID})
+		exists := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		role, want := roles[slug]
+		switch {
+		case want && !exists:
+			_, err = q.AddTenantMember(ctx, db.AddTenantMemberParams{TenantID: tenant.ID, UserID: userID, Role: role})
+		case want && member.Role != role:
+			_, err = q.UpdateTenantMemberRole(ctx, db.UpdateTenantMemberRoleParams{TenantID: tenant.ID, UserID: userID, Role: role})
+		case !want && exists:
+			err = q.RemoveTenantMember(ctx, db.RemoveTenantMemberParams{TenantID: tenant.ID, UserID: userID})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+)
+
+func scimOps(t *testing.T, raw string) []scimPatchOp {
+	t.Helper()
+	var req scimPatchRequest
+	if err := json.Unmarshal([]byte(raw), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return req.Operations
+}
+
+func TestParseSCIMFilter(t *testing.T) {
+	attr, value, err := parseSCIMFilter(`userName eq "Jane@Example.com"`)
+	if err != nil || attr != "username" || value != "Jane@Example.com" {
+		t.Fatalf("got %q %q %v", attr, value, err)
+	}
+	_, value, err = parseSCIMFilter(`displayName eq "Team \"A\""`)
+	if err != nil || value != `Team "A"` {
+		t.Fatalf("escaped value: got %q %v", value, err)
+	}
+	for _, bad := range []string{`userName co "jane"`, `userName eq jane`, `userName eq "a" and active eq "true"`} {
+		if _, _, err := parseSCIMFilter(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestSCIMUserRequest_Attrs(t *testing.T) {
+	var req scimUserRequest
+	body := `{"userName":"jdoe","name":{"givenName":"Jane","familyName":"Doe"},
+		"emails":[{"value":"other@example.com"},{"value":"Jane@Example.com","primary":true}]}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	a := req.attrs()
+	if a.email() != "jane@example.com" {
+		t.Fatalf("expected primary email, got %q", a.email())
+	}
+	if a.name() != "Jane Doe" {
+		t.Fatalf("expected name from given and family names, got %q", a.name())
+	}
+	if !a.Active {
+		t.Fatalf("expected users to be active by default")
+	}
+}
+
+func TestApplyUserPatch(t *testing.T) {
+	a := scimUserAttrs{UserName: "jane@example.com", Active: true}
+
+	// Okta sends a path-less replace with a boolean.
+	if err := applyUserPatch(&a, scimOps(t, `{"Operations":[{"op":"replace","value":{"active":false,"name":{"givenName":"Jane"}}}]}`)); err != nil {
+		t.Fatalf("okta patch: %v", err)
+	}
+	if a.Active || a.GivenName != "Jane" {
+		t.Fatalf("unexpected attrs after okta patch: %+v", a)
+	}
+
+	// Entra ID sends capitalized ops and string booleans.
+	if err := applyUserPatch(&a, scimOps(t, `{"Operations":[{"op":"Replace","path":"active","value":"True"},{"op":"Add","path":"displayName","value":"Jane D."}]}`)); err != nil {
+		t.Fatalf("entra patch: %v", err)
+	}
+	if !a.Active || a.DisplayName != "Jane D." {
+		t.Fatalf("unexpected attrs after entra patch: %+v", a)
+	}
+
+	if err := applyUserPatch(&a, scimOps(t, `{"Operations":[{"op":"replace","path":"active","value":"maybe"}]}`)); err == nil {
+		t.Fatalf("expected invalid boolean to be rejected")
+	}
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	g := scimGroupState{DisplayName: "eng", Members: map[uuid.UUID]bool{alice: true, bob: true}}
+
+	ops := `{"Operations":[
+		{"op":"add","path":"members","value":[{"value":"` + carol.String() + `"}]},
+		{"op":"remove","path":"members[value eq \"` + alice.String() + `\"]"},
+		{"op":"replace","path":"displayName","value":"engineering"}]}`
+	if err := applyGroupPatch(&g, scimOps(t, ops)); err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	if g.DisplayName != "engineering" || g.Members[alice] || !g.Members[bob] || !g.Members[carol] {
+		t.Fatalf("unexpected group after patch: %+v", g)
+	}
+
+	if err := applyGroupPatch(&g, scimOps(t, `{"Operations":[{"op":"remove","path":"members"}]}`)); err != nil {
+		t.Fatalf("remove all: %v", err)
+	}
+	if len(g.Members) != 0 {
+		t.Fatalf("expected members to be cleared, got %v", g.Members)
+	}
+
+	if err := applyGroupPatch(&g, scimOps(t, `{"Operations":[{"op":"add","path":"members","value":[{"value":"not-a-uuid"}]}]}`)); err == nil {
+		t.Fatalf("expected invalid member id to be rejected")
+	}
+}
+
+func TestSCIMTenantRoles(t *testing.T) {
+	mappings := []config.SCIMGroupMapping{
+		{Group: "Engineering", Tenant: "eng"},
+		{Group: "Eng Leads", Tenant: "eng", Role: roleTenantAdmin},
+		{Group: "Auditors", Tenant: "finance", Role: roleTenantViewer},
+	}
+
+	roles, managed := scimTenantRoles(mappings, []string{"engineering", "Eng Leads"})
+	if roles["eng"] != roleTenantAdmin {
+		t.Fatalf("expected highest role to win, got %q", roles["eng"])
+	}
+	if _, ok := roles["finance"]; ok {
+		t.Fatalf("expected no finance role, got %v", roles)
+	}
+	if !slices.Equal(managed, []string{"eng", "finance"}) {
+		t.Fatalf("unexpected managed tenants: %v", managed)
+	}
+
+	roles, _ = scimTenantRoles(mappings, []string{"Engineering"})
+	if roles["eng"] != roleTenantMember {
+		t.Fatalf("expected default member role, got %q", roles["eng"])
+	}
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"raito/internal/scrapeutil"
+)
+
+// Stages of a single-page scrape that share the request's timeout, with
+// the least time each needs to be worth starting.
+const (
+	scrapeStageFetch       = "fetch"
+	scrapeStageScreenshot  = "screenshot"
+	scrapeStageA11y        = "a11y"
+	scrapeStagePerformance = "performance"
+	scrapeStageClassify    = "classify"
+	scrapeStageSummary     = "summary"
+	scrapeStageJSON        = "json"
+	scrapeStageBranding    = "branding"
+	scrapeStageImages      = "downloadImages"
+)
+
+var scrapeStageMinimums = map[string]time.Duration{
+	scrapeStageFetch:       time.Second,
+	scrapeStageScreenshot:  3 * time.Second,
+	scrapeStageA11y:        3 * time.Second,
+	scrapeStagePerformance: 3 * time.Second,
+	scrapeStageClassify:    2 * time.Second,
+	scrapeStageSummary:     2 * time.Second,
+	scrapeStageJSON:        2 * time.Second,
+	scrapeStageBranding:    2 * time.Second,
+	scrapeStageImages:      time.Second,
+}
+
+// scrapeBudgetMargin is kept back from a scrape's timeout for storing the
+// result and returning it to the caller.
+const scrapeBudgetMargin = 250 * time.Millisecond
+
+// scrapeBudget divides one scrape timeout across the stages the request
+// asked for, so that the fetch, screenshot and each LLM call together
+// finish within it. The fetch may use all the time not reserved for the
+// minimums of the stages after it; each later stage gets an even share of
+// what is left, and a stage that cannot get its minimum is skipped. Time a
+// stage does not use goes to the stages after it.
+type scrapeBudget struct {
+	deadline time.Time
+	// pending lists the stages not yet started, in order.
+	pending []string
+	// skipped lists the stages that were skipped or ran out of time.
+	skipped []string
+}
+
+// newScrapeBudget returns a budget of timeout, counted from start, for
+// stages run in the given order.
+func newScrapeBudget(start time.Time, timeout time.Duration, stages []string) *scrapeBudget {
+	return &scrapeBudget{
+		deadline: start.Add(timeout - scrapeBudgetMargin),
+		pending:  stages,
+	}
+}
+
+// scrapeStages lists the budgeted stages of req in the order a scrape
+// runs them; the fetch always comes first.
+func scrapeStages(req ScrapeRequest, hasScreenshot, wantA11y, wantPerformance bool) []string {
+	stages := []string{scrapeStageFetch}
+	if hasScreenshot {
+		stages = append(stages, scrapeStageScreenshot)
+	}
+	if wantA11y {
+		stages = append(stages, scrapeStageA11y)
+	}
+	if wantPerformance {
+		stages = append(stages, scrapeStagePerformance)
+	}
+	for _, f := range []string{scrapeStageClassify, scrapeStageSummary, scrapeStageJSON, scrapeStageBranding} {
+		if scrapeutil.WantsFormat(req.Formats, f) {
+			stages = append(stages, f)
+		}
+	}
+	if req.DownloadImages != nil && *req.DownloadImages {
+		stages = append(stages, scrapeStageImages)
+	}
+	return stages
+}
+
+// start begins stage and returns its timeout, or false when the stage
+// should be skipped because its minimum no longer fits. Stages must be
+// started in the order they were listed.
+func (b *scrapeBudget) start(stage string) (time.Duration, bool) {
+	idx := -1
+	for i, s := range b.pending {
+		if s == stage {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		// Not a budgeted stage; give it what is left.
+		return max(time.Until(b.deadline), 0), true
+	}
+	later := b.pending[idx+1:]
+	b.pending = later
+
+	remaining := time.Until(b.deadline)
+	minimum := scrapeStageMinimums[stage]
+	if stage == scrapeStageFetch {
+		// The fetch is the scrape; without it there is nothing to return.
+		var reserve time.Duration
+		for _, s := range later {
+			reserve += scrapeStageMinimums[s]
+		}
+		return max(remaining-reserve, minimum), true
+	}
+	if remaining < minimum {
+		b.skip(stage)
+		return 0, false
+	}
+	return max(remaining/time.Duration(len(later)+1), minimum), true
+}
+
+// expired reports whether err means stage, run under stageCtx, ran out of
+// its share of the budget. The stage is then recorded as skipped and the
+// scrape returns without it rather than failing.
+func (b *scrapeBudget) expired(stageCtx context.Context, stage string, err error) bool {
+	if err == nil || (!errors.Is(err, context.DeadlineExceeded) && !errors.Is(stageCtx.Err(), context.DeadlineExceeded)) {
+		return false
+	}
+	b.skip(stage)
+	return true
+}
+
+func (b *scrapeBudget) skip(stage string) {
+	b.skipped = append(b.skipped, stage)
+}
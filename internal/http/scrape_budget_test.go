@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScrapeBudget_ReservesLaterStages(t *testing.T) {
+	stages := []string{scrapeStageFetch, scrapeStageScreenshot, scrapeStageSummary}
+	b := newScrapeBudget(time.Now(), 10*time.Second, stages)
+
+	fetch, ok := b.start(scrapeStageFetch)
+	if !ok {
+		t.Fatalf("expected the fetch to always run")
+	}
+	// 10s less the margin, less the screenshot and summary minimums.
+	if fetch > 5*time.Second || fetch < 4*time.Second {
+		t.Fatalf("expected the fetch to leave room for later stages, got %s", fetch)
+	}
+
+	shot, ok := b.start(scrapeStageScreenshot)
+	if !ok {
+		t.Fatalf("expected the screenshot to fit")
+	}
+	if shot < scrapeStageMinimums[scrapeStageScreenshot] || shot > 5*time.Second {
+		t.Fatalf("expected the screenshot to get half of what is left, got %s", shot)
+	}
+	if len(b.skipped) != 0 {
+		t.Fatalf("expected nothing skipped, got %v", b.skipped)
+	}
+}
+
+func TestScrapeBudget_SkipsStagesThatDoNotFit(t *testing.T) {
+	stages := []string{scrapeStageFetch, scrapeStageScreenshot, scrapeStageJSON}
+	b := newScrapeBudget(time.Now().Add(-9*time.Second), 10*time.Second, stages)
+
+	if fetch, ok := b.start(scrapeStageFetch); !ok || fetch != scrapeStageMinimums[scrapeStageFetch] {
+		t.Fatalf("expected the fetch to get its minimum, got %s", fetch)
+	}
+	if _, ok := b.start(scrapeStageScreenshot); ok {
+		t.Fatalf("expected the screenshot to be skipped")
+	}
+	if _, ok := b.start(scrapeStageJSON); ok {
+		t.Fatalf("expected json to be skipped")
+	}
+	if len(b.skipped) != 2 || b.skipped[0] != scrapeStageScreenshot || b.skipped[1] != scrapeStageJSON {
+		t.Fatalf("unexpected skipped stages %v", b.skipped)
+	}
+}
+
+func TestScrapeBudget_Expired(t *testing.T) {
+	b := newScrapeBudget(time.Now(), 10*time.Second, []string{scrapeStageFetch, scrapeStageSummary})
+
+	if b.expired(context.Background(), scrapeStageSummary, errors.New("provider error")) {
+		t.Fatalf("expected other errors not to count as running out of time")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	if !b.expired(ctx, scrapeStageSummary, errors.New("request canceled")) {
+		t.Fatalf("expected an error after the stage deadline to count as running out of time")
+	}
+	if len(b.skipped) != 1 || b.skipped[0] != scrapeStageSummary {
+		t.Fatalf("unexpected skipped stages %v", b.skipped)
+	}
+}
+
+func TestScrapeStages(t *testing.T) {
+	download := true
+	req := ScrapeRequest{
+		Formats:        []any{"markdown", "summary", map[string]any{"type": "json"}},
+		DownloadImages: &download,
+	}
+	got := scrapeStages(req, true, false, true)
+	want := []string{scrapeStageFetch, scrapeStageScreenshot, scrapeStagePerformance, scrapeStageSummary, scrapeStageJSON, scrapeStageImages}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/model"
+	"raito/internal/scraper"
+	"raito/internal/store"
+)
+
+// Evidence copies are stored as job assets under these source names,
+// which cannot collide with the URLs of archived images.
+const (
+	evidenceRawHTMLSource    = "evidence:rawHtml"
+	evidenceScreenshotSource = "evidence:screenshot"
+	evidenceAlgorithm        = "sha256"
+)
+
+// evidenceHash is the hex SHA-256 of data.
+func evidenceHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordScrapeEvidence stores the raw HTML of res, and screenshot when it
+// is not empty, as assets of the job and returns the evidence record for
+// the scraped document.
+func recordScrapeEvidence(ctx context.Context, st assetStore, jobID uuid.UUID, res *scraper.Result, screenshot []byte, capturedAt time.Time) (*model.Evidence, error) {
+	raw := []byte(res.RawHTML)
+	rawID, err := st.PutJobAsset(ctx, jobID, evidenceRawHTMLSource, "text/html; charset=utf-8", raw)
+	if err != nil {
+		return nil, err
+	}
+	ev := &model.Evidence{
+		Algorithm:      evidenceAlgorithm,
+		ContentHash:    evidenceHash(raw),
+		URL:            res.URL,
+		StatusCode:     res.Status,
+		Headers:        res.Headers,
+		CapturedAt:     capturedAt.UTC(),
+		RawHTMLAssetID: rawID.String(),
+	}
+	if len(screenshot) > 0 {
+		shotID, err := st.PutJobAsset(ctx, jobID, evidenceScreenshotSource, "image/png", screenshot)
+		if err != nil {
+			return nil, err
+		}
+		ev.ScreenshotHash = evidenceHash(screenshot)
+		ev.ScreenshotAssetID = shotID.String()
+	}
+	return ev, nil
+}
+
+// EvidenceCheck is the result of re-hashing one stored copy.
+type EvidenceCheck struct {
+	AssetID   string `json:"assetId"`
+	Expected  string `json:"expected"`
+	Computed  string `json:"computed,omitempty"`
+	SizeBytes int64  `json:"sizeBytes,omitempty"`
+	Verified  bool   `json:"verified"`
+	Error     string `json:"error,omitempty"`
+}
+
+// EvidenceVerification reports whether a scrape's stored copies still
+// match the hashes recorded when they were captured.
+type EvidenceVerification struct {
+	Verified   bool           `json:"verified"`
+	Algorithm  string         `json:"algorithm"`
+	URL        string         `json:"url"`
+	CapturedAt time.Time      `json:"capturedAt"`
+	VerifiedAt time.Time      `json:"verifiedAt"`
+	RawHTML    EvidenceCheck  `json:"rawHtml"`
+	Screenshot *EvidenceCheck `json:"screenshot,omitempty"`
+}
+
+type EvidenceVerifyResponse struct {
+	Success bool                  `json:"success"`
+	Code    string                `json:"code,omitempty"`
+	Error   string                `json:"error,omitempty"`
+	JobID   string                `json:"jobId,omitempty"`
+	Data    *EvidenceVerification `json:"data,omitempty"`
+}
+
+// evidenceAssetGetter reads a job's stored assets.
+type evidenceAssetGetter interface {
+	GetJobAsset(ctx context.Context, jobID, assetID uuid.UUID) (db.JobAsset, error)
+}
+
+// verifyEvidence re-hashes the stored copies named by ev.
+func verifyEvidence(ctx context.Context, st evidenceAssetGetter, jobID uuid.UUID, ev *model.Evidence) EvidenceVerification {
+	out := EvidenceVerification{
+		Algorithm:  ev.Algorithm,
+		URL:        ev.URL,
+		CapturedAt: ev.CapturedAt,
+		VerifiedAt: time.Now().UTC(),
+		RawHTML:    checkEvidenceAsset(ctx, st, jobID, ev.RawHTMLAssetID, ev.ContentHash),
+	}
+	out.Verified = out.RawHTML.Verified
+	if ev.ScreenshotAssetID != "" {
+		shot := checkEvidenceAsset(ctx, st, jobID, ev.ScreenshotAssetID, ev.ScreenshotHash)
+		out.Screenshot = &shot
+		out.Verified = out.Verified && shot.Verified
+	}
+	return out
+}
+
+func checkEvidenceAsset(ctx context.Context, st evidenceAssetGetter, jobID uuid.UUID, assetID, expected string) EvidenceCheck {
+	check := EvidenceCheck{AssetID: assetID, Expected: expected}
+	id, err := uuid.Parse(assetID)
+	if err != nil {
+		check.Error = "invalid asset id"
+		return check
+	}
+	asset, err := st.GetJobAsset(ctx, jobID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			check.Error = "stored copy not found"
+		} else {
+			check.Error = err.Error()
+		}
+		return check
+	}
+	check.Computed = evidenceHash(asset.Data)
+	check.SizeBytes = int64(len(asset.Data))
+	check.Verified = expected != "" && check.Computed == expected
+	return check
+}
+
+// jobEvidenceVerifyHandler implements GET /v1/jobs/:id/evidence/verify.
+// It re-computes the hashes of a scrape's stored raw HTML and screenshot
+// and compares them with the evidence recorded at capture time.
+func jobEvidenceVerifyHandler(c *fiber.Ctx) error {
+	st := c.Locals("store").(*store.Store)
+
+	job, ok, err := timelineJob(c, st)
+	if !ok {
+		return err
+	}
+
+	var doc Document
+	if !job.Output.Valid || json.Unmarshal(job.Output.RawMessage, &doc) != nil || doc.Evidence == nil {
+		return c.Status(fiber.StatusNotFound).JSON(EvidenceVerifyResponse{
+			Success: false,
+			Code:    "EVIDENCE_NOT_FOUND",
+			Error:   "job has no recorded evidence; scrape with evidence: true",
+		})
+	}
+
+	res := verifyEvidence(c.Context(), st, job.ID, doc.Evidence)
+	return c.Status(fiber.StatusOK).JSON(EvidenceVerifyResponse{
+		Success: true,
+		JobID:   job.ID.String(),
+		Data:    &res,
+	})
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/scraper"
+)
+
+type memoryEvidenceStore struct {
+	assets map[uuid.UUID]db.JobAsset
+}
+
+func (m *memoryEvidenceStore) PutJobAsset(_ context.Context, jobID uuid.UUID, sourceURL, contentType string, data []byte) (uuid.UUID, error) {
+	id := uuid.New()
+	m.assets[id] = db.JobAsset{ID: id, JobID: jobID, SourceUrl: sourceURL, ContentType: contentType, Sha256: evidenceHash(data), Data: data}
+	return id, nil
+}
+
+func (m *memoryEvidenceStore) GetJobAsset(_ context.Context, jobID, assetID uuid.UUID) (db.JobAsset, error) {
+	a, ok := m.assets[assetID]
+	if !ok || a.JobID != jobID {
+		return db.JobAsset{}, sql.ErrNoRows
+	}
+	return a, nil
+}
+
+func TestScrapeEvidence_RecordAndVerify(t *testing.T) {
+	st := &memoryEvidenceStore{assets: map[uuid.UUID]db.JobAsset{}}
+	jobID := uuid.New()
+	res := &scraper.Result{
+		URL:     "https://example.com/terms",
+		RawHTML: "<html><body>Terms v2</body></html>",
+		Status:  200,
+		Headers: map[string]string{"content-type": "text/html"},
+	}
+	capturedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	ev, err := recordScrapeEvidence(context.Background(), st, jobID, res, []byte("png"), capturedAt)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if ev.Algorithm != "sha256" || ev.ContentHash != evidenceHash([]byte(res.RawHTML)) {
+		t.Fatalf("unexpected hash: %+v", ev)
+	}
+	if ev.Headers["content-type"] != "text/html" || ev.StatusCode != 200 || !ev.CapturedAt.Equal(capturedAt) {
+		t.Fatalf("unexpected evidence: %+v", ev)
+	}
+	if ev.ScreenshotAssetID == "" || ev.ScreenshotHash != evidenceHash([]byte("png")) {
+		t.Fatalf("expected screenshot evidence, got %+v", ev)
+	}
+
+	got := verifyEvidence(context.Background(), st, jobID, ev)
+	if !got.Verified || !got.RawHTML.Verified || got.Screenshot == nil || !got.Screenshot.Verified {
+		t.Fatalf("expected verified evidence, got %+v", got)
+	}
+
+	// A changed stored copy no longer matches the recorded hash.
+	rawID := uuid.MustParse(ev.RawHTMLAssetID)
+	a := st.assets[rawID]
+	a.Data = []byte("<html><body>Terms v3</body></html>")
+	st.assets[rawID] = a
+	got = verifyEvidence(context.Background(), st, jobID, ev)
+	if got.Verified || got.RawHTML.Verified || got.RawHTML.Computed == ev.ContentHash {
+		t.Fatalf("expected tampered copy to fail, got %+v", got)
+	}
+
+	// Assets of another job are not found.
+	got = verifyEvidence(context.Background(), st, uuid.New(), ev)
+	if got.Verified || got.RawHTML.Error == "" {
+		t.Fatalf("expected missing copy to fail, got %+v", got)
+	}
+}
+
+func TestScrapeEvidence_WithoutScreenshot(t *testing.T) {
+	st := &memoryEvidenceStore{assets: map[uuid.UUID]db.JobAsset{}}
+	jobID := uuid.New()
+	ev, err := recordScrapeEvidence(context.Background(), st, jobID, &scraper.Result{URL: "https://example.com", RawHTML: "<p>x</p>"}, nil, time.Now())
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if ev.ScreenshotAssetID != "" || len(st.assets) != 1 {
+		t.Fatalf("expected only the raw HTML to be stored, got %+v", ev)
+	}
+	if got := verifyEvidence(context.Background(), st, jobID, ev); !got.Verified || got.Screenshot != nil {
+		t.Fatalf("unexpected verification: %+v", got)
+	}
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"raito/internal/config"
+)
+
+// Defaults applied when the corresponding server.* config value is zero.
+const (
+	defaultServerReadTimeout  = 30 * time.Second
+	defaultServerWriteTimeout = 60 * time.Second
+	defaultServerIdleTimeout  = 120 * time.Second
+	defaultServerBodyLimit    = 4 * 1024 * 1024
+	defaultServerConcurrency  = 256 * 1024
+)
+
+// serverFiberConfig builds the Fiber app config from server settings. The
+// global body limit is raised to the largest per-route override so that
+// routeLimitsMiddleware can enforce the tighter limits per route.
+func serverFiberConfig(sc config.ServerConfig) fiber.Config {
+	readTimeout := defaultServerReadTimeout
+	if sc.ReadTimeoutMs > 0 {
+		readTimeout = time.Duration(sc.ReadTimeoutMs) * time.Millisecond
+	}
+	writeTimeout := serverWriteTimeout(sc)
+	idleTimeout := defaultServerIdleTimeout
+	if sc.IdleTimeoutMs > 0 {
+		idleTimeout = time.Duration(sc.IdleTimeoutMs) * time.Millisecond
+	}
+	concurrency := defaultServerConcurrency
+	if sc.Concurrency > 0 {
+		concurrency = sc.Concurrency
+	}
+
+	bodyLimit := serverBodyLimit(sc)
+	for _, r := range sc.Routes {
+		if r.BodyLimitBytes > bodyLimit {
+			bodyLimit = r.BodyLimitBytes
+		}
+	}
+
+	return fiber.Config{
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
+		BodyLimit:    bodyLimit,
+		Concurrency:  concurrency,
+	}
+}
+
+func serverBodyLimit(sc config.ServerConfig) int {
+	if sc.BodyLimitBytes > 0 {
+		return sc.BodyLimitBytes
+	}
+	return defaultServerBodyLimit
+}
+
+// matchRouteOverride returns the most specific route override for the
+// given method and path, or nil when none applies.
+func matchRouteOverride(routes []config.ServerRouteConfig, method, path string) *config.ServerRouteConfig {
+	var best *config.ServerRouteConfig
+	for i := range routes {
+		r := &routes[i]
+		if r.Path == "" || !strings.HasPrefix(path, r.Path) {
+			continue
+		}
+		if r.Method != "" && !strings.EqualFold(r.Method, method) {
+			continue
+		}
+		if best == nil || len(r.Path) > len(best.Path) {
+			best = r
+		}
+	}
+	return best
+}
+
+// serverWriteTimeout is the time the server allows for writing one
+// response.
+func serverWriteTimeout(sc config.ServerConfig) time.Duration {
+	if sc.WriteTimeoutMs > 0 {
+		return time.Duration(sc.WriteTimeoutMs) * time.Millisecond
+	}
+	return defaultServerWriteTimeout
+}
+
+// routeLimitsMiddleware enforces per-route body limits and attaches the
+// per-route timeout (if any) as a deadline on the request's user context.
+// Handlers that delegate to the job executor derive their context from it.
+func routeLimitsMiddleware(sc config.ServerConfig) fiber.Handler {
+	globalLimit := serverBodyLimit(sc)
+
+	return func(c *fiber.Ctx) error {
+		override := matchRouteOverride(sc.Routes, c.Method(), c.Path())
+
+		limit := globalLimit
+		if override != nil && override.BodyLimitBytes > 0 {
+			limit = override.BodyLimitBytes
+		}
+		if len(c.Body()) > limit {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(ErrorResponse{
+				Success: false,
+				Code:    "PAYLOAD_TOO_LARGE",
+				Error:   "request body exceeds the configured limit for this route",
+			})
+		}
+
+		if override != nil && override.TimeoutMs > 0 {
+			ctx, cancel := context.WithTimeout(c.UserContext(), time.Duration(override.TimeoutMs)*time.Millisecond)
+			defer cancel()
+			c.SetUserContext(ctx)
+		}
+
+		return c.Next()
+	}
+}
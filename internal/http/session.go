@@ -1,6 +1,8 @@
 package http
 
 import (
+	"context"
+	"database/sql"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -8,39 +10,73 @@ import (
 	"github.com/google/uuid"
 
 	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/store"
 )
 
-// sessionClaims are the JWT claims we use for browser sessions.
+// sessionClaims are the JWT claims we use for browser sessions. When a
+// server-side session record exists, its ID is carried in the standard
+// `jti` claim so the session can be revoked before the token expires.
 type sessionClaims struct {
 	UserID        string `json:"uid"`
 	TenantID      string `json:"tid,omitempty"`
 	IsSystemAdmin bool   `json:"is_admin"`
+	RememberMe    bool   `json:"rm,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func issueSessionCookie(c *fiber.Ctx, cfg *config.Config, userID uuid.UUID, tenantID *uuid.UUID, isSystemAdmin bool) error {
-	// If no session secret is configured, skip issuing a cookie (API-key only).
-	secret := cfg.Auth.Session.Secret
-	if secret == "" {
-		return nil
+func sessionCookieName(cfg *config.Config) string {
+	if cfg.Auth.Session.CookieName == "" {
+		return "raito_session"
 	}
+	return cfg.Auth.Session.CookieName
+}
 
-	name := cfg.Auth.Session.CookieName
-	if name == "" {
-		name = "raito_session"
+// sessionTTL returns the sliding lifetime for a session. Remember-me
+// sessions use auth.session.rememberMeDays (default 30 days).
+func sessionTTL(cfg *config.Config, rememberMe bool) time.Duration {
+	if rememberMe {
+		days := cfg.Auth.Session.RememberMeDays
+		if days <= 0 {
+			days = 30
+		}
+		return time.Duration(days) * 24 * time.Hour
 	}
 
 	ttlMinutes := cfg.Auth.Session.TTLMinutes
 	if ttlMinutes <= 0 {
 		ttlMinutes = 1440 // default 24h
 	}
+	return time.Duration(ttlMinutes) * time.Minute
+}
+
+// sessionStore returns the store from the request context when it is
+// backed by a database; session records are skipped otherwise.
+func sessionStore(c *fiber.Ctx) *store.Store {
+	st, ok := c.Locals("store").(*store.Store)
+	if !ok || st == nil || st.DB == nil {
+		return nil
+	}
+	return st
+}
+
+// issueSessionCookie creates a new server-side session record and sets a
+// signed cookie referencing it. Any session carried by the incoming request
+// is revoked first, so every issuance (login, tenant switch) rotates the
+// session ID.
+func issueSessionCookie(c *fiber.Ctx, cfg *config.Config, userID uuid.UUID, tenantID *uuid.UUID, isSystemAdmin bool, rememberMe bool) error {
+	// If no session secret is configured, skip issuing a cookie (API-key only).
+	if cfg.Auth.Session.Secret == "" {
+		return nil
+	}
 
 	now := time.Now().UTC()
-	expiresAt := now.Add(time.Duration(ttlMinutes) * time.Minute)
+	expiresAt := now.Add(sessionTTL(cfg, rememberMe))
 
 	claims := sessionClaims{
 		UserID:        userID.String(),
 		IsSystemAdmin: isSystemAdmin,
+		RememberMe:    rememberMe,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
@@ -50,16 +86,43 @@ func issueSessionCookie(c *fiber.Ctx, cfg *config.Config, userID uuid.UUID, tena
 		claims.TenantID = tenantID.String()
 	}
 
+	if st := sessionStore(c); st != nil {
+		q := db.New(st.DB)
+		if prev, err := parseSessionFromRequest(c, cfg); err == nil && prev.ID != "" {
+			if prevID, err := uuid.Parse(prev.ID); err == nil {
+				_ = q.RevokeSession(c.Context(), prevID)
+			}
+		}
+
+		sessionID := uuid.New()
+		if _, err := q.InsertSession(c.Context(), db.InsertSessionParams{
+			ID:         sessionID,
+			UserID:     userID,
+			RememberMe: rememberMe,
+			ExpiresAt:  expiresAt,
+			Ip:         sql.NullString{String: c.IP(), Valid: c.IP() != ""},
+			UserAgent:  sql.NullString{String: c.Get("User-Agent"), Valid: c.Get("User-Agent") != ""},
+		}); err != nil {
+			return err
+		}
+		claims.ID = sessionID.String()
+	}
+
+	return writeSessionCookie(c, cfg, &claims)
+}
+
+// writeSessionCookie signs the claims and sets the session cookie.
+func writeSessionCookie(c *fiber.Ctx, cfg *config.Config, claims *sessionClaims) error {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString([]byte(secret))
+	signed, err := token.SignedString([]byte(cfg.Auth.Session.Secret))
 	if err != nil {
 		return err
 	}
 
 	c.Cookie(&fiber.Cookie{
-		Name:     name,
+		Name:     sessionCookieName(cfg),
 		Value:    signed,
-		Expires:  expiresAt,
+		Expires:  claims.ExpiresAt.Time,
 		HTTPOnly: true,
 		Secure:   true,
 		SameSite: "Lax",
@@ -68,18 +131,25 @@ func issueSessionCookie(c *fiber.Ctx, cfg *config.Config, userID uuid.UUID, tena
 	return nil
 }
 
+// clearSessionCookie expires the session cookie on the client.
+func clearSessionCookie(c *fiber.Ctx, cfg *config.Config) {
+	c.Cookie(&fiber.Cookie{
+		Name:     sessionCookieName(cfg),
+		Value:    "",
+		Expires:  time.Now().Add(-1 * time.Hour),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Lax",
+	})
+}
+
 func parseSessionFromRequest(c *fiber.Ctx, cfg *config.Config) (*sessionClaims, error) {
 	secret := cfg.Auth.Session.Secret
 	if secret == "" {
 		return nil, fiber.ErrUnauthorized
 	}
 
-	name := cfg.Auth.Session.CookieName
-	if name == "" {
-		name = "raito_session"
-	}
-
-	cookie := c.Cookies(name)
+	cookie := c.Cookies(sessionCookieName(cfg))
 	if cookie == "" {
 		return nil, fiber.ErrUnauthorized
 	}
@@ -101,3 +171,40 @@ func parseSessionFromRequest(c *fiber.Ctx, cfg *config.Config) (*sessionClaims,
 
 	return claims, nil
 }
+
+// validateSessionRecord checks that the session referenced by the claims is
+// still active and slides its expiration forward once more than half of the
+// lifetime has elapsed, re-issuing the cookie with the new expiry.
+func validateSessionRecord(ctx context.Context, c *fiber.Ctx, cfg *config.Config, q *db.Queries, claims *sessionClaims) error {
+	if claims.ID == "" {
+		return fiber.ErrUnauthorized
+	}
+	sessionID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+
+	sess, err := q.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	now := time.Now().UTC()
+	if sess.RevokedAt.Valid || !sess.ExpiresAt.After(now) {
+		return fiber.ErrUnauthorized
+	}
+	if claims.UserID != sess.UserID.String() {
+		return fiber.ErrUnauthorized
+	}
+
+	ttl := sessionTTL(cfg, sess.RememberMe)
+	if sess.ExpiresAt.Sub(now) < ttl/2 {
+		expiresAt := now.Add(ttl)
+		if err := q.TouchSession(ctx, db.TouchSessionParams{ID: sessionID, ExpiresAt: expiresAt}); err == nil {
+			next := *claims
+			next.ExpiresAt = jwt.NewNumericDate(expiresAt)
+			_ = writeSessionCookie(c, cfg, &next)
+		}
+	}
+
+	return nil
+}
@@ -23,7 +23,7 @@ func TestIssueAndParseSessionCookie_RoundTrip(t *testing.T) {
 	tenantID := uuid.New()
 
 	app.Get("/set", func(c *fiber.Ctx) error {
-		if err := issueSessionCookie(c, cfg, userID, &tenantID, true); err != nil {
+		if err := issueSessionCookie(c, cfg, userID, &tenantID, true, false); err != nil {
 			t.Fatalf("issueSessionCookie error: %v", err)
 		}
 		return c.SendStatus(http.StatusOK)
@@ -77,3 +77,22 @@ func TestIssueAndParseSessionCookie_RoundTrip(t *testing.T) {
 		t.Fatalf("expected 200 from /get, got %d", resp2.StatusCode)
 	}
 }
+
+func TestSessionTTL_RememberMe(t *testing.T) {
+	cfg := &config.Config{}
+	if got := sessionTTL(cfg, false); got != 24*time.Hour {
+		t.Fatalf("expected default ttl 24h, got %s", got)
+	}
+	if got := sessionTTL(cfg, true); got != 30*24*time.Hour {
+		t.Fatalf("expected default remember-me ttl 30d, got %s", got)
+	}
+
+	cfg.Auth.Session.TTLMinutes = 30
+	cfg.Auth.Session.RememberMeDays = 7
+	if got := sessionTTL(cfg, false); got != 30*time.Minute {
+		t.Fatalf("expected ttl 30m, got %s", got)
+	}
+	if got := sessionTTL(cfg, true); got != 7*24*time.Hour {
+		t.Fatalf("expected remember-me ttl 7d, got %s", got)
+	}
+}
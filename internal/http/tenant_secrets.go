@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/secrets"
+)
+
+var (
+	// errSecretsNotConfigured is returned when auth.secrets.encryptionKey
+	// is empty.
+	errSecretsNotConfigured = secrets.ErrNotConfigured
+	// errSecretNotFound is returned when a request references a secret
+	// the tenant does not have.
+	errSecretNotFound = errors.New("secret not found")
+	// errSecretNoTenant is returned when the caller has no tenant to
+	// resolve secrets against.
+	errSecretNoTenant = errors.New("auth requires a tenant-scoped API key or user")
+)
+
+// secretNamePattern restricts secret names to identifiers that are safe
+// in URLs and logs.
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// sealSecret encrypts a tenant secret value for storage.
+func sealSecret(cfg *config.Config, tenantID uuid.UUID, name, value string) ([]byte, error) {
+	return secrets.Seal(cfg, tenantID, name, value)
+}
+
+// openSecret reverses sealSecret.
+func openSecret(cfg *config.Config, tenantID uuid.UUID, name string, sealed []byte) (string, error) {
+	return secrets.Open(cfg, tenantID, name, sealed)
+}
+
+// validateTargetAuth checks the shape of a TargetAuth reference.
+func validateTargetAuth(auth *TargetAuth) error {
+	if auth == nil {
+		return nil
+	}
+	if !secretNamePattern.MatchString(auth.Secret) {
+		return errors.New("auth.secret must name a tenant secret")
+	}
+	switch auth.Type {
+	case "bearer", "basic":
+	case "header":
+		if http.CanonicalHeaderKey(strings.TrimSpace(auth.Header)) == "" {
+			return errors.New("auth.header is required for type header")
+		}
+	default:
+		return fmt.Errorf("unsupported auth.type %q; expected bearer, basic, or header", auth.Type)
+	}
+	return nil
+}
+
+// secretGetter is the part of db.Queries used to resolve secrets.
+type secretGetter interface {
+	GetTenantSecretByName(ctx context.Context, arg db.GetTenantSecretByNameParams) (db.TenantSecret, error)
+}
+
+// targetAuthHeaders resolves auth against the tenant's secrets and returns
+// the headers to add to outgoing requests. It runs at fetch time so that
+// only the secret's name is ever stored with the job.
+func targetAuthHeaders(ctx context.Context, cfg *config.Config, q secretGetter, tenantID *uuid.UUID, auth *TargetAuth) (map[string]string, error) {
+	if auth == nil {
+		return nil, nil
+	}
+	if err := validateTargetAuth(auth); err != nil {
+		return nil, err
+	}
+	value, err := tenantSecretValue(ctx, cfg, q, tenantID, auth.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch auth.Type {
+	case "bearer":
+		return map[string]string{"Authorization": "Bearer " + value}, nil
+	case "basic":
+		creds := value
+		if auth.Username != "" {
+			creds = auth.Username + ":" + value
+		}
+		return map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))}, nil
+	default:
+		return map[string]string{http.CanonicalHeaderKey(strings.TrimSpace(auth.Header)): value}, nil
+	}
+}
+
+// tenantSecretValue decrypts the tenant's secret called name.
+func tenantSecretValue(ctx context.Context, cfg *config.Config, q secretGetter, tenantID *uuid.UUID, name string) (string, error) {
+	if tenantID == nil {
+		return "", errSecretNoTenant
+	}
+	if err := secrets.Check(cfg); err != nil {
+		return "", err
+	}
+
+	row, err := q.GetTenantSecretByName(ctx, db.GetTenantSecretByNameParams{TenantID: *tenantID, Name: name})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%w: %q", errSecretNotFound, name)
+		}
+		return "", err
+	}
+	return openSecret(cfg, *tenantID, row.Name, row.ValueEncrypted)
+}
+
+// targetAuthStatus maps a targetAuthHeaders error to an HTTP status and
+// error code.
+func targetAuthStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, errSecretsNotConfigured):
+		return http.StatusServiceUnavailable, "SECRETS_NOT_CONFIGURED"
+	case errors.Is(err, errSecretNotFound):
+		return http.StatusBadRequest, "SECRET_NOT_FOUND"
+	case errors.Is(err, errSecretNoTenant):
+		return http.StatusBadRequest, "TENANT_REQUIRED"
+	default:
+		return http.StatusInternalServerError, "SECRET_UNAVAILABLE"
+	}
+}
+
+// tenantIDFromContext returns the tenant stored on a job or request
+// context under "tenant_id".
+func tenantIDFromContext(ctx context.Context) *uuid.UUID {
+	if tid, ok := ctx.Value("tenant_id").(uuid.UUID); ok {
+		return &tid
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/model"
+	"raito/internal/scraper"
+)
+
+// Transform hook failure policies decide what happens to a document when
+// the hook errors, times out, or returns an invalid reply.
+const (
+	// transformPolicyKeep stores the document unchanged.
+	transformPolicyKeep = "keep"
+	// transformPolicyDrop discards the document.
+	transformPolicyDrop = "drop"
+	// transformPolicyFail fails the job.
+	transformPolicyFail = "fail"
+)
+
+const (
+	defaultTransformHookTimeout = 5 * time.Second
+	maxTransformHookTimeout     = 30 * time.Second
+	// maxTransformHookResponseBytes bounds the reply read from a hook.
+	maxTransformHookResponseBytes = 32 << 20
+)
+
+var (
+	// errTransformHookFailed is returned when a hook with the fail policy
+	// cannot transform a document.
+	errTransformHookFailed = errors.New("transform hook failed")
+	// errTransformHookDropped is returned for single-page scrapes whose
+	// document was discarded by the hook.
+	errTransformHookDropped = errors.New("document dropped by transform hook")
+)
+
+// transformHookQuerier is the subset of db.Queries used to load a
+// tenant's transform hook and its signing secret.
+type transformHookQuerier interface {
+	secretGetter
+	GetTenantTransformHook(ctx context.Context, tenantID uuid.UUID) (db.TenantTransformHook, error)
+}
+
+// transformHookRequest is the body POSTed to a transform hook.
+type transformHookRequest struct {
+	JobID    string   `json:"jobId,omitempty"`
+	JobType  string   `json:"jobType"`
+	TenantID string   `json:"tenantId"`
+	URL      string   `json:"url"`
+	Document Document `json:"document"`
+}
+
+// transformHookReply is a hook's answer. A reply without a document
+// leaves the document unchanged.
+type transformHookReply struct {
+	Document *Document `json:"document,omitempty"`
+	Drop     bool      `json:"drop,omitempty"`
+}
+
+// transformHook calls a tenant's transform endpoint with each document a
+// job produces, before the document is persisted.
+type transformHook struct {
+	url        string
+	timeout    time.Duration
+	policy     string
+	tenantID   uuid.UUID
+	signingKey string
+	client     *http.Client
+}
+
+// loadTransformHook returns the tenant's enabled transform hook, or nil
+// when the tenant has none. The signing secret is decrypted once here so
+// that it is not looked up for every page of a crawl.
+func loadTransformHook(ctx context.Context, cfg *config.Config, q transformHookQuerier, tenantID *uuid.UUID) (*transformHook, error) {
+	if tenantID == nil || q == nil {
+		return nil, nil
+	}
+	row, err := q.GetTenantTransformHook(ctx, *tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !row.Enabled {
+		return nil, nil
+	}
+
+	hook := &transformHook{
+		url:      row.Url,
+		timeout:  time.Duration(row.TimeoutMs) * time.Millisecond,
+		policy:   row.FailurePolicy,
+		tenantID: *tenantID,
+	}
+	if hook.timeout <= 0 || hook.timeout > maxTransformHookTimeout {
+		hook.timeout = defaultTransformHookTimeout
+	}
+	// Hook URLs are tenant input, so they get the same private network
+	// protection as /v1/fetch.
+	hook.client = scraper.NewPublicHTTPClient(hook.timeout, cfg.Scraper.AllowPrivateNetworks)
+	if row.SigningSecret.Valid && row.SigningSecret.String != "" {
+		key, err := tenantSecretValue(ctx, cfg, q, tenantID, row.SigningSecret.String)
+		if err != nil {
+			return nil, fmt.Errorf("transform hook signing secret: %w", err)
+		}
+		hook.signingKey = key
+	}
+	return hook, nil
+}
+
+// signTransformHookBody returns the X-Raito-Signature value for body.
+func signTransformHookBody(key string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// call POSTs one document to the hook. It returns the replacement
+// document, or nil to keep the original, and whether the hook asked for
+// the document to be dropped.
+func (h *transformHook) call(ctx context.Context, jobID uuid.UUID, jobType string, doc Document) (*Document, bool, error) {
+	payload := transformHookRequest{
+		JobType:  jobType,
+		TenantID: h.tenantID.String(),
+		URL:      doc.Metadata.SourceURL,
+		Document: doc,
+	}
+	if jobID != uuid.Nil {
+		payload.JobID = jobID.String()
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, false, err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(callCtx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.signingKey != "" {
+		req.Header.Set("X-Raito-Signature", signTransformHookBody(h.signingKey, body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, false, fmt.Errorf("hook returned HTTP %d", resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxTransformHookResponseBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(raw) > maxTransformHookResponseBytes {
+		return nil, false, errors.New("hook reply exceeds 32 MiB")
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, false, nil
+	}
+	var reply transformHookReply
+	if err := json.Unmarshal(raw, &reply); err != nil {
+		return nil, false, fmt.Errorf("invalid hook reply: %w", err)
+	}
+	return reply.Document, reply.Drop, nil
+}
+
+// apply runs doc through the hook and returns the document to persist,
+// with false when it should be discarded instead. Hook failures follow
+// the failure policy; under the fail policy the returned error wraps
+// errTransformHookFailed. A nil hook returns doc unchanged.
+func (h *transformHook) apply(ctx context.Context, jobID uuid.UUID, jobType string, doc Document) (Document, bool, error) {
+	if h == nil {
+		return doc, true, nil
+	}
+
+	out, drop, err := h.call(ctx, jobID, jobType, doc)
+	if err != nil {
+		slog.Warn("transform hook failed",
+			"tenant_id", h.tenantID.String(),
+			"job_id", jobID.String(),
+			"url", doc.Metadata.SourceURL,
+			"policy", h.policy,
+			"error", err.Error(),
+		)
+		switch h.policy {
+		case transformPolicyDrop:
+			return doc, false, nil
+		case transformPolicyFail:
+			return doc, false, fmt.Errorf("%w: %v", errTransformHookFailed, err)
+		default:
+			return doc, true, nil
+		}
+	}
+	if drop {
+		return doc, false, nil
+	}
+	if out == nil {
+		return doc, true, nil
+	}
+	return *out, true, nil
+}
+
+// applyToPage runs a crawled or batch-scraped page through the hook. Pages
+// are stored as separate content columns plus metadata, and LLM and plugin
+// fields live in the metadata, so the hook's top-level summary, json,
+// branding and plugins are copied there.
+func (h *transformHook) applyToPage(ctx context.Context, jobID uuid.UUID, jobType string, markdown, html, rawHTML *string, engine string, md *model.Metadata) (bool, error) {
+	if h == nil {
+		return true, nil
+	}
+	doc := Document{
+		Markdown: *markdown,
+		HTML:     *html,
+		RawHTML:  *rawHTML,
+		Summary:  md.Summary,
+		JSON:     md.JSON,
+		Branding: md.Branding,
+		Plugins:  md.Plugins,
+		Engine:   engine,
+		Metadata: *md,
+	}
+	out, keep, err := h.apply(ctx, jobID, jobType, doc)
+	if err != nil || !keep {
+		return false, err
+	}
+	*markdown = out.Markdown
+	*html = out.HTML
+	*rawHTML = out.RawHTML
+	*md = out.Metadata
+	md.Summary = out.Summary
+	md.JSON = out.JSON
+	md.Branding = out.Branding
+	md.Plugins = out.Plugins
+	return true, nil
+}
+
+// validTransformPolicy reports whether policy is a known failure policy.
+func validTransformPolicy(policy string) bool {
+	switch policy {
+	case transformPolicyKeep, transformPolicyDrop, transformPolicyFail:
+		return true
+	}
+	return false
+}
+
+// jobCancelMessage returns the failure message for a crawl or batch job
+// whose context ended early.
+func jobCancelMessage(ctx context.Context) string {
+	if cause := context.Cause(ctx); errors.Is(cause, errTransformHookFailed) {
+		return "TRANSFORM_HOOK_FAILED: " + cause.Error()
+	}
+	return ctx.Err().Error()
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/model"
+	"raito/internal/store"
+)
+
+func newTestTransformHook(t *testing.T, policy string, handler http.HandlerFunc) *transformHook {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &transformHook{
+		url:      srv.URL,
+		timeout:  time.Second,
+		policy:   policy,
+		tenantID: uuid.New(),
+		client:   srv.Client(),
+	}
+}
+
+func TestTransformHook_Apply(t *testing.T) {
+	jobID := uuid.New()
+	doc := Document{Markdown: "raw", Metadata: model.Metadata{SourceURL: "https://example.com"}}
+
+	var got transformHookRequest
+	var signature string
+	hook := newTestTransformHook(t, transformPolicyKeep, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		signature = r.Header.Get("X-Raito-Signature")
+		if want := signTransformHookBody("s3cret", body); signature != want {
+			t.Errorf("signature = %q, want %q", signature, want)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"document": map[string]any{"markdown": "clean", "metadata": map[string]any{"sourceURL": "https://example.com", "statusCode": 200}},
+		})
+	})
+	hook.signingKey = "s3cret"
+
+	out, keep, err := hook.apply(context.Background(), jobID, "scrape", doc)
+	if err != nil || !keep {
+		t.Fatalf("apply: keep=%v err=%v", keep, err)
+	}
+	if out.Markdown != "clean" {
+		t.Fatalf("expected the hook's document, got %+v", out)
+	}
+	if got.JobID != jobID.String() || got.JobType != "scrape" || got.URL != "https://example.com" || got.Document.Markdown != "raw" {
+		t.Fatalf("unexpected hook payload: %+v", got)
+	}
+	if signature == "" {
+		t.Fatalf("expected a signature header")
+	}
+}
+
+func TestTransformHook_Replies(t *testing.T) {
+	doc := Document{Markdown: "raw"}
+
+	unchanged := newTestTransformHook(t, transformPolicyKeep, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if out, keep, err := unchanged.apply(context.Background(), uuid.Nil, "scrape", doc); err != nil || !keep || out.Markdown != "raw" {
+		t.Fatalf("204: expected the document unchanged, got %+v keep=%v err=%v", out, keep, err)
+	}
+
+	dropped := newTestTransformHook(t, transformPolicyKeep, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"drop": true}`))
+	})
+	if _, keep, err := dropped.apply(context.Background(), uuid.Nil, "crawl", doc); err != nil || keep {
+		t.Fatalf("drop: expected the document to be discarded, got keep=%v err=%v", keep, err)
+	}
+
+	var nilHook *transformHook
+	if out, keep, err := nilHook.apply(context.Background(), uuid.Nil, "scrape", doc); err != nil || !keep || out.Markdown != "raw" {
+		t.Fatalf("nil hook: expected the document unchanged")
+	}
+}
+
+func TestTransformHook_FailurePolicies(t *testing.T) {
+	doc := Document{Markdown: "raw"}
+	failing := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	keep := newTestTransformHook(t, transformPolicyKeep, failing)
+	if out, ok, err := keep.apply(context.Background(), uuid.Nil, "crawl", doc); err != nil || !ok || out.Markdown != "raw" {
+		t.Fatalf("keep: expected the original document, got ok=%v err=%v", ok, err)
+	}
+
+	drop := newTestTransformHook(t, transformPolicyDrop, failing)
+	if _, ok, err := drop.apply(context.Background(), uuid.Nil, "crawl", doc); err != nil || ok {
+		t.Fatalf("drop: expected the document to be discarded, got ok=%v err=%v", ok, err)
+	}
+
+	fail := newTestTransformHook(t, transformPolicyFail, failing)
+	if _, _, err := fail.apply(context.Background(), uuid.Nil, "crawl", doc); !errors.Is(err, errTransformHookFailed) {
+		t.Fatalf("fail: expected errTransformHookFailed, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	_, _, err := fail.apply(ctx, uuid.Nil, "crawl", doc)
+	cancel(err)
+	if msg := jobCancelMessage(ctx); !strings.HasPrefix(msg, "TRANSFORM_HOOK_FAILED: ") {
+		t.Fatalf("unexpected job failure message %q", msg)
+	}
+}
+
+func TestTransformHook_ApplyToPage(t *testing.T) {
+	hook := newTestTransformHook(t, transformPolicyKeep, func(w http.ResponseWriter, r *http.Request) {
+		var req transformHookRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		req.Document.Markdown = "clean"
+		req.Document.Summary = "edited"
+		req.Document.Metadata.Title = "Title"
+		_ = json.NewEncoder(w).Encode(transformHookReply{Document: &req.Document})
+	})
+
+	markdown, html, raw := "raw", "<p>raw</p>", ""
+	md := model.Metadata{SourceURL: "https://example.com/a", Summary: "original"}
+	keep, err := hook.applyToPage(context.Background(), uuid.New(), "crawl", &markdown, &html, &raw, "http", &md)
+	if err != nil || !keep {
+		t.Fatalf("applyToPage: keep=%v err=%v", keep, err)
+	}
+	if markdown != "clean" || html != "<p>raw</p>" || md.Title != "Title" || md.Summary != "edited" {
+		t.Fatalf("unexpected page after transform: markdown=%q html=%q md=%+v", markdown, html, md)
+	}
+}
+
+func TestValidateTransformHookRequest(t *testing.T) {
+	req := TransformHookRequest{URL: "https://hooks.example.com/transform"}
+	if err := validateTransformHookRequest(&req); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if req.FailurePolicy != transformPolicyKeep || req.TimeoutMs == nil || *req.TimeoutMs != 5000 {
+		t.Fatalf("expected defaults, got %+v", req)
+	}
+
+	bad := []TransformHookRequest{
+		{URL: "ftp://example.com"},
+		{URL: "/relative"},
+		{URL: "https://example.com", FailurePolicy: "retry"},
+		{URL: "https://example.com", TimeoutMs: new(int)},
+		{URL: "https://example.com", SigningSecret: "has space"},
+	}
+	for _, r := range bad {
+		if err := validateTransformHookRequest(&r); err == nil {
+			t.Fatalf("expected %+v to be rejected", r)
+		}
+	}
+}
+
+func TestLoadTransformHook_RefusesPrivateAddresses(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	tenantID := uuid.New()
+	_, conn := newFakeDB(t, map[string][]any{
+		"GetTenantTransformHook": {db.TenantTransformHook{TenantID: tenantID, Url: srv.URL, TimeoutMs: 1000, FailurePolicy: transformPolicyFail, Enabled: true}},
+	})
+	doc := Document{Markdown: "raw", Metadata: model.Metadata{SourceURL: "https://example.com"}}
+
+	cfg := &config.Config{}
+	hook, err := loadTransformHook(context.Background(), cfg, db.New(conn), &tenantID)
+	if err != nil || hook == nil {
+		t.Fatalf("loadTransformHook: hook=%v err=%v", hook, err)
+	}
+	if _, _, err := hook.apply(context.Background(), uuid.Nil, "crawl", doc); !errors.Is(err, errTransformHookFailed) || calls != 0 {
+		t.Fatalf("expected the loopback hook to be refused, got err=%v calls=%d", err, calls)
+	}
+
+	cfg.Scraper.AllowPrivateNetworks = true
+	hook, err = loadTransformHook(context.Background(), cfg, db.New(conn), &tenantID)
+	if err != nil {
+		t.Fatalf("loadTransformHook: %v", err)
+	}
+	if _, ok, err := hook.apply(context.Background(), uuid.Nil, "crawl", doc); err != nil || !ok || calls != 1 {
+		t.Fatalf("expected allowPrivateNetworks to reach the hook, got ok=%v err=%v calls=%d", ok, err, calls)
+	}
+}
+
+func TestTenantPutTransformHook_RejectsPrivateHosts(t *testing.T) {
+	userID := uuid.New()
+	app := fiber.New()
+	app.Put("/v1/tenants/:id/transform-hook", func(c *fiber.Ctx) error {
+		c.Locals("store", &store.Store{})
+		c.Locals("config", &config.Config{})
+		c.Locals("principal", Principal{UserID: &userID, IsSystemAdmin: true})
+		return tenantPutTransformHookHandler(c)
+	})
+
+	for _, hookURL := range []string{"http://127.0.0.1:9000/hook", "http://169.254.169.254/latest/meta-data", "http://[::1]/hook", "http://10.0.0.5/hook"} {
+		body := strings.NewReader(`{"url":"` + hookURL + `"}`)
+		req := httptest.NewRequest(http.MethodPut, "/v1/tenants/"+uuid.NewString()+"/transform-hook", body)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("app.Test error: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", hookURL, resp.StatusCode)
+		}
+	}
+}
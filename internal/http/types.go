@@ -30,9 +30,79 @@ type ScrapeRequest struct {
 	Origin              string            `json:"origin,omitempty"`
 	UseBrowser          *bool             `json:"useBrowser,omitempty"`
 
+	// Method, Body and ContentType submit a request body with the HTTP
+	// engine, e.g. POSTing a search form. Method defaults to GET.
+	Method      string `json:"method,omitempty"`
+	Body        string `json:"body,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	// Actions run in the browser before the page is captured; setting
+	// any action selects the browser engine.
+	Actions []ScrapeAction `json:"actions,omitempty"`
+	// Auth adds credentials from a stored tenant secret at fetch time.
+	Auth *TargetAuth `json:"auth,omitempty"`
+
 	// Advanced scrape options (Phase 10)
 	Location    *LocationOptions `json:"location,omitempty"`
 	Integration string           `json:"integration,omitempty"`
+
+	// Dedupe coalesces identical concurrent scrapes (same URL and options
+	// within a tenant) onto a single in-flight job when true.
+	Dedupe *bool `json:"dedupe,omitempty"`
+
+	// Async enqueues the scrape and returns its job ID right away instead
+	// of waiting for the document; the result is read from /v1/jobs/:id.
+	Async *bool `json:"async,omitempty"`
+
+	// DownloadImages archives referenced images as job assets and rewrites
+	// markdown image links to their stable /v1/jobs/:id/assets URLs.
+	DownloadImages *bool `json:"downloadImages,omitempty"`
+
+	// Evidence keeps proof of what was captured: a hash of the raw HTML,
+	// the response headers, and the capture time, with the raw HTML (and
+	// the screenshot, when requested) stored as job assets. GET
+	// /v1/jobs/:id/evidence/verify re-checks the hashes.
+	Evidence *bool `json:"evidence,omitempty"`
+
+	// Visibility is "shared" (default, whole tenant) or "private"
+	// (only the creating user or API key).
+	Visibility string `json:"visibility,omitempty"`
+	// CollectionID assigns the job to a tenant collection whose default
+	// options fill any fields not set on the request.
+	CollectionID string `json:"collectionId,omitempty"`
+	// Pool routes the job to a dedicated worker pool; it must be listed
+	// in jobRouting.allowedPools.
+	Pool string `json:"pool,omitempty"`
+	// ZeroDataRetention (or storeInCache: false) returns results to the
+	// caller without keeping inputs, outputs, or documents once they have
+	// been delivered.
+	ZeroDataRetention *bool `json:"zeroDataRetention,omitempty"`
+	StoreInCache      *bool `json:"storeInCache,omitempty"`
+
+	// MaxFormatBytes overrides scraper.formatMaxBytes for this request.
+	MaxFormatBytes *FormatByteLimits `json:"maxFormatBytes,omitempty"`
+}
+
+// TargetAuth authenticates requests to the scraped site with a tenant
+// secret, so credentials never appear in request payloads or job rows.
+// Type is "bearer" (Authorization: Bearer <secret>), "basic"
+// (Authorization: Basic; the secret is the password for username, or
+// "user:password" when username is empty) or "header" (the secret is
+// sent as-is in the named header).
+type TargetAuth struct {
+	Type     string `json:"type"`
+	Secret   string `json:"secret"`
+	Username string `json:"username,omitempty"`
+	Header   string `json:"header,omitempty"`
+}
+
+// ScrapeAction is a browser interaction. The only supported type is
+// "fillForm", which sets the named fields of the form matched by
+// selector (default "form") and submits it unless submit is false.
+type ScrapeAction struct {
+	Type     string            `json:"type"`
+	Selector string            `json:"selector,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Submit   *bool             `json:"submit,omitempty"`
 }
 
 // LocationOptions describes geo-related options for scraping.
@@ -54,6 +124,10 @@ type ErrorResponse struct {
 	Code    string `json:"code,omitempty"`
 	Error   string `json:"error"`
 	Details any    `json:"details,omitempty"`
+	// MessageKey and MessageParams identify the error text for clients
+	// that localize it; localizeErrorsMiddleware fills them in.
+	MessageKey    string            `json:"messageKey,omitempty"`
+	MessageParams map[string]string `json:"messageParams,omitempty"`
 }
 
 // ScrapeResponse matches Firecrawl v2's ScrapeResponse union shape.
@@ -64,6 +138,14 @@ type ScrapeResponse struct {
 	ScrapeID string    `json:"scrape_id,omitempty"`
 	Code     string    `json:"code,omitempty"`
 	Error    string    `json:"error,omitempty"`
+
+	// ID and URL are set instead of Data for async scrapes: the queued
+	// job and where to check on it.
+	ID  string `json:"id,omitempty"`
+	URL string `json:"url,omitempty"`
+
+	// AppliedOptions lists the resolved options and where each came from.
+	AppliedOptions AppliedOptions `json:"appliedOptions,omitempty"`
 }
 
 // MapRequest shape is based on Firecrawl's MapRequest.
@@ -77,6 +159,9 @@ type MapRequest struct {
 	Sitemap           string `json:"sitemap,omitempty"`
 	Limit             *int   `json:"limit,omitempty"`
 	Timeout           *int   `json:"timeout,omitempty"`
+	Visibility        string `json:"visibility,omitempty"`
+	CollectionID      string `json:"collectionId,omitempty"`
+	Pool              string `json:"pool,omitempty"`
 }
 
 type MapLink struct {
@@ -97,7 +182,10 @@ type MapResponse struct {
 // For now, formats are provided at the top level and control which
 // fields are included in crawl documents when retrieved.
 type CrawlRequest struct {
-	URL                string   `json:"url"`
+	URL string `json:"url"`
+	// URLs are further start URLs that share the crawl's frontier, limit
+	// and de-duplication, e.g. several subdomains or site sections.
+	URLs               []string `json:"urls,omitempty"`
 	Origin             string   `json:"origin,omitempty"`
 	IncludePaths       []string `json:"includePaths,omitempty"`
 	ExcludePaths       []string `json:"excludePaths,omitempty"`
@@ -110,7 +198,7 @@ type CrawlRequest struct {
 	DeduplicateSimilar bool     `json:"deduplicateSimilarURLs,omitempty"`
 	IgnoreQueryParams  *bool    `json:"ignoreQueryParameters,omitempty"`
 	RegexOnFullURL     *bool    `json:"regexOnFullURL,omitempty"`
-	Delay              *int     `json:"delay,omitempty"`
+	Delay              *float64 `json:"delay,omitempty"`
 	Webhook            string   `json:"webhook,omitempty"`
 	Formats            []any    `json:"formats,omitempty"`
 
@@ -118,6 +206,42 @@ type CrawlRequest struct {
 	CrawlEntireDomain *bool          `json:"crawlEntireDomain,omitempty"`
 	MaxConcurrency    *int           `json:"maxConcurrency,omitempty"`
 	ScrapeOptions     *ScrapeOptions `json:"scrapeOptions,omitempty"`
+	DownloadImages    *bool          `json:"downloadImages,omitempty"`
+	// Incremental only scrapes pages that are new or changed since the
+	// latest completed crawl of the same URL in the tenant.
+	Incremental *bool `json:"incremental,omitempty"`
+	// PriorityExpression ranks discovered URLs so limited crawls scrape the
+	// most valuable pages first (see crawler.ParsePriorityExpression).
+	PriorityExpression string `json:"priorityExpression,omitempty"`
+	// SPA renders pages in the browser and follows client-side routes
+	// (History API navigation, hash routes and router links) that never
+	// appear in raw HTML or sitemaps.
+	SPA *bool `json:"spa,omitempty"`
+	// StructuredData validates each page's JSON-LD and microdata against
+	// schema.org once the crawl completes.
+	StructuredData *bool `json:"structuredData,omitempty"`
+	// RespectRobotsMeta honors noindex and nofollow from meta robots tags
+	// and X-Robots-Tag headers: noindex pages are not stored, and links
+	// of nofollow pages or marked rel="nofollow" are not followed.
+	RespectRobotsMeta *bool `json:"respectRobotsMeta,omitempty"`
+	// Languages restricts the crawl to pages in these languages (BCP 47
+	// tags such as "en" or "de-at"; "en" also matches "en-gb"). Pages are
+	// matched by their hreflang annotations or <html lang>, and language
+	// alternates outside the list are not scraped.
+	Languages []string `json:"languages,omitempty"`
+
+	Visibility   string `json:"visibility,omitempty"`
+	CollectionID string `json:"collectionId,omitempty"`
+	Pool         string `json:"pool,omitempty"`
+
+	// ZeroDataRetention (or storeInCache: false) returns results to the
+	// caller without keeping inputs, outputs, or documents once they have
+	// been delivered.
+	ZeroDataRetention *bool `json:"zeroDataRetention,omitempty"`
+	StoreInCache      *bool `json:"storeInCache,omitempty"`
+
+	// MaxFormatBytes overrides scraper.formatMaxBytes for this request.
+	MaxFormatBytes *FormatByteLimits `json:"maxFormatBytes,omitempty"`
 }
 
 // ScrapeOptions captures per-page scrape configuration that can be
@@ -143,6 +267,9 @@ type ScrapeOptions struct {
 
 	MaxAge  *int64   `json:"maxAge,omitempty"`
 	Parsers []string `json:"parsers,omitempty"`
+
+	// Auth adds credentials from a stored tenant secret to every page.
+	Auth *TargetAuth `json:"auth,omitempty"`
 }
 
 type CrawlStatus string
@@ -164,9 +291,12 @@ type ExtractField struct {
 type ExtractRequest struct {
 	URLs               []string       `json:"urls"`
 	Schema             map[string]any `json:"schema,omitempty"`
+	SchemaPreset       string         `json:"schemaPreset,omitempty"` // built-in schema used when schema is omitted, e.g. "product"
 	Prompt             string         `json:"prompt,omitempty"`
 	SystemPrompt       string         `json:"systemPrompt,omitempty"`
-	Provider           string         `json:"provider,omitempty"` // openai, anthropic, google
+	Template           string         `json:"template,omitempty"`        // tenant "extract" prompt template prepended to systemPrompt
+	TemplateVersion    int            `json:"templateVersion,omitempty"` // pins a template version; 0 uses the latest
+	Provider           string         `json:"provider,omitempty"`        // openai, anthropic, google
 	Model              string         `json:"model,omitempty"`
 	Strict             bool           `json:"strict,omitempty"`
 	IgnoreInvalidURLs  *bool          `json:"ignoreInvalidURLs,omitempty"`
@@ -175,6 +305,18 @@ type ExtractRequest struct {
 	ShowSources        *bool          `json:"showSources,omitempty"`
 	ScrapeOptions      *ScrapeOptions `json:"scrapeOptions,omitempty"`
 	Integration        string         `json:"integration,omitempty"`
+	Limit              *int           `json:"limit,omitempty"` // max pages per wildcard URL ("https://host/path/*")
+	Mode               string         `json:"mode,omitempty"`  // "perUrl" (default) or "merge"
+	Visibility         string         `json:"visibility,omitempty"`
+	CollectionID       string         `json:"collectionId,omitempty"`
+	Pool               string         `json:"pool,omitempty"`
+	MaxAge             *int64         `json:"maxAge,omitempty"` // ms; reuse cached per-URL results no older than this, 0 forces a fresh extract
+
+	// ZeroDataRetention (or storeInCache: false) returns results to the
+	// caller without keeping inputs, outputs, or documents once they have
+	// been delivered.
+	ZeroDataRetention *bool `json:"zeroDataRetention,omitempty"`
+	StoreInCache      *bool `json:"storeInCache,omitempty"`
 }
 
 type ExtractResult struct {
@@ -208,6 +350,7 @@ type ExtractStatusResponse struct {
 	CreditsUsed int              `json:"creditsUsed,omitempty"`
 	Code        string           `json:"code,omitempty"`
 	Error       string           `json:"error,omitempty"`
+	Warning     string           `json:"warning,omitempty"`
 }
 
 const (
@@ -215,6 +358,7 @@ const (
 	CrawlStatusRunning   CrawlStatus = "running"
 	CrawlStatusCompleted CrawlStatus = "completed"
 	CrawlStatusFailed    CrawlStatus = "failed"
+	CrawlStatusPaused    CrawlStatus = "paused"
 )
 
 type CrawlResponse struct {
@@ -223,17 +367,52 @@ type CrawlResponse struct {
 	URL         string      `json:"url,omitempty"`
 	Status      CrawlStatus `json:"status,omitempty"`
 	Total       int         `json:"total,omitempty"`
+	Completed   int         `json:"completed,omitempty"`
 	CreditsUsed int         `json:"creditsUsed,omitempty"`
 	ExpiresAt   string      `json:"expiresAt,omitempty"`
 	Data        []Document  `json:"data,omitempty"`
 	Code        string      `json:"code,omitempty"`
 	Error       string      `json:"error,omitempty"`
 	Warning     string      `json:"warning,omitempty"`
+
+	Incremental *CrawlIncrementalSummary `json:"incremental,omitempty"`
+	// Duplicates groups near-identical pages once the crawl completes.
+	Duplicates *CrawlDuplicateSummary `json:"duplicates,omitempty"`
+	// StructuredData totals the structured data issues of crawls run with
+	// structuredData; the per-page report is at /crawl/:id/structured-data.
+	StructuredData *CrawlStructuredDataSummary `json:"structuredData,omitempty"`
+	// Accessibility aggregates the a11y format's violations across pages.
+	Accessibility *CrawlAccessibilitySummary `json:"accessibility,omitempty"`
+	// RobotsMeta counts what crawls with respectRobotsMeta left out.
+	RobotsMeta *CrawlRobotsMetaSummary `json:"robotsMeta,omitempty"`
+	// Languages reports what crawls with languages left out.
+	Languages *CrawlLanguageSummary `json:"languages,omitempty"`
+	// Report summarizes a completed crawl; the download renders it as
+	// report.md and report.html.
+	Report *CrawlReport `json:"report,omitempty"`
+	// ETASeconds estimates the time left for a running crawl. Total is
+	// an estimate too until the crawl completes.
+	ETASeconds *int64 `json:"etaSeconds,omitempty"`
+
+	// AppliedOptions lists the resolved options and where each came from.
+	AppliedOptions AppliedOptions `json:"appliedOptions,omitempty"`
 }
 
 type BatchScrapeRequest struct {
-	URLs    []string `json:"urls"`
-	Formats []any    `json:"formats,omitempty"`
+	URLs         []string `json:"urls"`
+	Formats      []any    `json:"formats,omitempty"`
+	Visibility   string   `json:"visibility,omitempty"`
+	CollectionID string   `json:"collectionId,omitempty"`
+	Pool         string   `json:"pool,omitempty"`
+
+	// ZeroDataRetention (or storeInCache: false) returns results to the
+	// caller without keeping inputs, outputs, or documents once they have
+	// been delivered.
+	ZeroDataRetention *bool `json:"zeroDataRetention,omitempty"`
+	StoreInCache      *bool `json:"storeInCache,omitempty"`
+
+	// MaxFormatBytes overrides scraper.formatMaxBytes for this request.
+	MaxFormatBytes *FormatByteLimits `json:"maxFormatBytes,omitempty"`
 }
 
 type BatchScrapeStatus string
@@ -264,6 +443,8 @@ type SearchRequest struct {
 	Query             string         `json:"query"`
 	Sources           []string       `json:"sources,omitempty"`
 	Categories        []string       `json:"categories,omitempty"`
+	Sites             []string       `json:"sites,omitempty"`
+	ExcludeSites      []string       `json:"excludeSites,omitempty"`
 	Limit             *int           `json:"limit,omitempty"`
 	Country           string         `json:"country,omitempty"`
 	Location          string         `json:"location,omitempty"`
@@ -280,12 +461,20 @@ type SearchWebResult struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description,omitempty"`
 	URL         string    `json:"url"`
+	Category    string    `json:"category,omitempty"`
 	Document    *Document `json:"document,omitempty"`
 
 	// Lightweight metadata about the scraped page is
 	// exposed at the top level for convenience.
 	Metadata Metadata `json:"metadata,omitempty"`
 	Engine   string   `json:"engine,omitempty"`
+
+	// Error explains a failed scrape ("SCRAPE_TIMEOUT: ..." or
+	// "SCRAPE_FAILED: ..."); the result is still returned unless
+	// ignoreInvalidURLs is set.
+	Error string `json:"error,omitempty"`
+	// ScrapeDurationMs is how long scraping this result took.
+	ScrapeDurationMs int64 `json:"scrapeDurationMs,omitempty"`
 }
 
 // SearchData groups results per source type. v1 only populates
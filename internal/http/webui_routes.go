@@ -41,8 +41,10 @@ func registerWebUIRoutes(app *fiber.App) {
 		// Don't hijack API routes; let Fiber return a proper 404 for unknown endpoints.
 		switch {
 		case strings.HasPrefix(requestPath, "/v1/"),
+			strings.HasPrefix(requestPath, "/v2/"),
 			strings.HasPrefix(requestPath, "/admin/"),
 			strings.HasPrefix(requestPath, "/auth/"),
+			strings.HasPrefix(requestPath, "/share/"),
 			requestPath == "/healthz",
 			requestPath == "/metrics":
 			return fiber.ErrNotFound
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/jobs"
+)
+
+// zeroRetentionPurgedWarning is returned when a zero-retention job's
+// results were already delivered and deleted.
+const zeroRetentionPurgedWarning = "Results were deleted after delivery because the job used zero data retention."
+
+// zeroRetentionRequested reports whether a request opted into zero data
+// retention via zeroDataRetention: true or Firecrawl's storeInCache: false.
+func zeroRetentionRequested(zeroDataRetention, storeInCache *bool) bool {
+	if zeroDataRetention != nil && *zeroDataRetention {
+		return true
+	}
+	return storeInCache != nil && !*storeInCache
+}
+
+// jobPurger is the part of the store used to delete zero-retention data.
+type jobPurger interface {
+	PurgeJobData(ctx context.Context, id uuid.UUID) error
+}
+
+// purgeDeliveredJob deletes a finished zero-retention job's data once its
+// results have been read into the response being sent. Purge failures are
+// logged and left for the retention sweep to retry.
+func purgeDeliveredJob(c *fiber.Ctx, st jobPurger, job db.Job) {
+	if !job.ZeroRetention || job.PurgedAt.Valid {
+		return
+	}
+	if status := jobs.PublicStatus(job.Status); status != "completed" && status != "failed" {
+		return
+	}
+	if err := st.PurgeJobData(c.Context(), job.ID); err != nil {
+		if loggerVal := c.Locals("logger"); loggerVal != nil {
+			if lg, ok := loggerVal.(interface{ Warn(msg string, args ...any) }); ok {
+				lg.Warn("zero_retention_purge_failed", "job_id", job.ID.String(), "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+)
+
+type fakePurger struct {
+	purged []uuid.UUID
+}
+
+func (f *fakePurger) PurgeJobData(_ context.Context, id uuid.UUID) error {
+	f.purged = append(f.purged, id)
+	return nil
+}
+
+func TestZeroRetentionRequested(t *testing.T) {
+	yes, no := true, false
+	cases := []struct {
+		name         string
+		zdr, inCache *bool
+		want         bool
+	}{
+		{"unset", nil, nil, false},
+		{"zeroDataRetention", &yes, nil, true},
+		{"storeInCache false", nil, &no, true},
+		{"storeInCache true", nil, &yes, false},
+		{"explicitly off", &no, &yes, false},
+	}
+	for _, tc := range cases {
+		if got := zeroRetentionRequested(tc.zdr, tc.inCache); got != tc.want {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPurgeDeliveredJob(t *testing.T) {
+	finished := db.Job{ID: uuid.New(), Status: "completed", ZeroRetention: true}
+	cases := []struct {
+		name string
+		job  db.Job
+		want bool
+	}{
+		{"finished zero-retention job", finished, true},
+		{"failed zero-retention job", db.Job{ID: uuid.New(), Status: "failed", ZeroRetention: true}, true},
+		{"still running", db.Job{ID: uuid.New(), Status: "running", ZeroRetention: true}, false},
+		{"regular job", db.Job{ID: uuid.New(), Status: "completed"}, false},
+		{"already purged", db.Job{ID: uuid.New(), Status: "completed", ZeroRetention: true,
+			PurgedAt: sql.NullTime{Time: time.Now(), Valid: true}}, false},
+	}
+
+	for _, tc := range cases {
+		purger := &fakePurger{}
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			purgeDeliveredJob(c, purger, tc.job)
+			return c.SendStatus(fiber.StatusOK)
+		})
+		if _, err := app.Test(httptest.NewRequest("GET", "/", nil)); err != nil {
+			t.Fatalf("%s: app.Test: %v", tc.name, err)
+		}
+		if got := len(purger.purged) == 1; got != tc.want {
+			t.Fatalf("%s: purged=%v, want %v", tc.name, purger.purged, tc.want)
+		}
+	}
+}
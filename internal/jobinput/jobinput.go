@@ -0,0 +1,148 @@
+// Package jobinput versions the request payloads stored in jobs.input, so
+// workers can run jobs enqueued by an older API after a request field is
+// renamed or reshaped.
+//
+// New inputs are stored in an envelope,
+//
+//	{"inputVersion": 1, "input": {...}}
+//
+// and inputs written before the envelope existed are version 0. Decode
+// runs the migrations from the stored version up to Version before
+// unmarshalling, so callers always see the current request shape.
+// Changing the shape of a stored request means bumping Version and adding
+// the migration that rewrites older inputs.
+package jobinput
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Version is the input version Encode writes.
+const Version = 1
+
+// A Migration rewrites a job's decoded input from one version to the
+// next in place.
+type Migration func(jobType string, input map[string]any)
+
+// migrations[i] upgrades an input from version i to i+1.
+var migrations = [Version]Migration{
+	migrateV0,
+}
+
+type envelope struct {
+	Version *int            `json:"inputVersion"`
+	Input   json.RawMessage `json:"input"`
+}
+
+// Encode marshals input in an envelope of the current Version.
+func Encode(input any) (json.RawMessage, error) {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	v := Version
+	return json.Marshal(envelope{Version: &v, Input: raw})
+}
+
+// Decode migrates a stored job input to the current Version and
+// unmarshals it into v.
+func Decode(jobType string, raw []byte, v any) error {
+	input, err := Migrate(jobType, raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(input, v)
+}
+
+// Migrate returns a stored job input at the current Version, without its
+// envelope. Inputs from a newer version than this build knows are an
+// error rather than being guessed at.
+func Migrate(jobType string, raw []byte) (json.RawMessage, error) {
+	version, input := unwrap(raw)
+	if version > Version {
+		return nil, fmt.Errorf("job input version %d is newer than the supported version %d", version, Version)
+	}
+	if version == Version {
+		return input, nil
+	}
+	body, ok := decodeObject(input)
+	if !ok {
+		// Leave malformed input for the caller's unmarshal to report.
+		return input, nil
+	}
+	for ; version < Version; version++ {
+		migrations[version](jobType, body)
+	}
+	return json.Marshal(body)
+}
+
+// unwrap splits raw into its version and input. Anything that is not an
+// envelope is a version 0 input.
+func unwrap(raw []byte) (int, json.RawMessage) {
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil || env.Version == nil || len(env.Input) == 0 {
+		return 0, raw
+	}
+	return *env.Version, env.Input
+}
+
+// decodeObject decodes raw as a JSON object, keeping numbers as
+// json.Number so re-encoding does not change their representation.
+func decodeObject(raw []byte) (map[string]any, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var body map[string]any
+	if err := dec.Decode(&body); err != nil || body == nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// migrateV0 upgrades inputs from before the envelope. Extract jobs could
+// then name a single "url" instead of "urls" and list the "fields" to
+// extract instead of giving a JSON "schema".
+func migrateV0(jobType string, input map[string]any) {
+	if jobType != "extract" {
+		return
+	}
+	if u, ok := input["url"].(string); ok && u != "" {
+		if _, ok := input["urls"]; !ok {
+			input["urls"] = []any{u}
+		}
+	}
+	delete(input, "url")
+
+	fields, ok := input["fields"].([]any)
+	delete(input, "fields")
+	if !ok || len(fields) == 0 {
+		return
+	}
+	if _, ok := input["schema"]; ok {
+		return
+	}
+	props := make(map[string]any, len(fields))
+	for _, f := range fields {
+		field, ok := f.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := field["name"].(string)
+		if name == "" {
+			continue
+		}
+		typ, _ := field["type"].(string)
+		if typ == "" {
+			typ = "string"
+		}
+		prop := map[string]any{"type": typ}
+		if desc, _ := field["description"].(string); desc != "" {
+			prop["description"] = desc
+		}
+		props[name] = prop
+	}
+	if len(props) > 0 {
+		input["schema"] = map[string]any{"type": "object", "properties": props}
+	}
+}
@@ -0,0 +1,102 @@
+package jobinput
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type crawlInput struct {
+	URL   string   `json:"url"`
+	Limit int      `json:"limit"`
+	Delay *float64 `json:"delay"`
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	delay := 0.5
+	raw, err := Encode(crawlInput{URL: "https://example.com", Limit: 10, Delay: &delay})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !strings.HasPrefix(string(raw), `{"inputVersion":1,"input":{`) {
+		t.Fatalf("expected an envelope, got %s", raw)
+	}
+
+	var got crawlInput
+	if err := Decode("crawl", raw, &got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.URL != "https://example.com" || got.Limit != 10 || got.Delay == nil || *got.Delay != 0.5 {
+		t.Fatalf("unexpected input %+v", got)
+	}
+}
+
+func TestDecode_UnversionedInput(t *testing.T) {
+	var got crawlInput
+	if err := Decode("crawl", []byte(`{"url":"https://example.com","limit":3}`), &got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.URL != "https://example.com" || got.Limit != 3 {
+		t.Fatalf("unexpected input %+v", got)
+	}
+
+	// Purged jobs keep an empty object.
+	if err := Decode("crawl", []byte(`{}`), &crawlInput{}); err != nil {
+		t.Fatalf("Decode of a purged input: %v", err)
+	}
+	if err := Decode("crawl", []byte(`[1]`), &crawlInput{}); err == nil {
+		t.Fatal("expected malformed input to fail to decode")
+	}
+}
+
+func TestMigrate_LegacyExtract(t *testing.T) {
+	raw := []byte(`{
+		"url": "https://example.com/product",
+		"fields": [
+			{"name": "title", "description": "Product title"},
+			{"name": "price", "type": "number"}
+		],
+		"prompt": "Extract the product"
+	}`)
+	got, err := Migrate("extract", raw)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	var input map[string]any
+	if err := json.Unmarshal(got, &input); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := map[string]any{
+		"urls": []any{"https://example.com/product"},
+		"schema": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"title": map[string]any{"type": "string", "description": "Product title"},
+				"price": map[string]any{"type": "number"},
+			},
+		},
+		"prompt": "Extract the product",
+	}
+	if !reflect.DeepEqual(input, want) {
+		t.Fatalf("unexpected migrated input:\n got %v\nwant %v", input, want)
+	}
+
+	// A current extract input, and other job types, pass through.
+	current := `{"urls":["https://example.com"],"schema":{"type":"object"},"url":"ignored"}`
+	got, _ = Migrate("extract", []byte(current))
+	if strings.Contains(string(got), "ignored") || !strings.Contains(string(got), `"urls":["https://example.com"]`) {
+		t.Fatalf("unexpected migrated input %s", got)
+	}
+	got, _ = Migrate("scrape", []byte(`{"url":"https://example.com","fields":{"a":"b"}}`))
+	if !strings.Contains(string(got), `"url":"https://example.com"`) || !strings.Contains(string(got), `"fields"`) {
+		t.Fatalf("expected scrape input to be left alone, got %s", got)
+	}
+}
+
+func TestMigrate_NewerVersion(t *testing.T) {
+	_, err := Migrate("crawl", []byte(`{"inputVersion":99,"input":{"url":"https://example.com"}}`))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected a newer-version error, got %v", err)
+	}
+}
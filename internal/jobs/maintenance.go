@@ -0,0 +1,295 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/metrics"
+	"raito/internal/store"
+)
+
+// defaultMaintenanceTables are the tables with the most write churn,
+// analyzed nightly unless database.maintenance.tables overrides them.
+var defaultMaintenanceTables = []string{
+	"jobs",
+	"documents",
+	"job_events",
+	"job_heartbeats",
+	"job_assets",
+	"audit_events",
+	"sessions",
+	"extract_cache",
+}
+
+// dbMaintenanceCheckInterval is how often workers check whether the
+// nightly maintenance run is due.
+const dbMaintenanceCheckInterval = 15 * time.Minute
+
+// Thresholds for maintenance recommendations.
+const (
+	vacuumMinDeadTuples    = 10000
+	vacuumMinDeadRatio     = 0.2
+	staleStatsMinModified  = 1000
+	staleStatsMinRatio     = 0.2
+	unusedIndexMinBytes    = 1 << 20
+	seqScanMinLiveTuples   = 10000
+	seqScanMinScans        = 1000
+	seqScanToIndexScanRate = 10
+)
+
+// ErrDBMaintenanceBusy is returned when another process is already
+// running database maintenance.
+var ErrDBMaintenanceBusy = errors.New("database maintenance is already running")
+
+// DBTableReport describes a table's size, churn, and access pattern.
+type DBTableReport struct {
+	Table                string     `json:"table"`
+	LiveTuples           int64      `json:"liveTuples"`
+	DeadTuples           int64      `json:"deadTuples"`
+	DeadRatio            float64    `json:"deadRatio"`
+	ModifiedSinceAnalyze int64      `json:"modifiedSinceAnalyze"`
+	SeqScans             int64      `json:"seqScans"`
+	IndexScans           int64      `json:"indexScans"`
+	TotalBytes           int64      `json:"totalBytes"`
+	LastAnalyze          *time.Time `json:"lastAnalyze,omitempty"`
+	LastAutoanalyze      *time.Time `json:"lastAutoanalyze,omitempty"`
+	LastAutovacuum       *time.Time `json:"lastAutovacuum,omitempty"`
+}
+
+// DBIndexReport describes an index's size and how often it is used.
+type DBIndexReport struct {
+	Table     string `json:"table"`
+	Index     string `json:"index"`
+	Scans     int64  `json:"scans"`
+	SizeBytes int64  `json:"sizeBytes"`
+	Unique    bool   `json:"unique"`
+}
+
+// DBRecommendation is a suggested action for an operator. Kind is
+// "vacuum", "analyze", "unused_index", or "missing_index".
+type DBRecommendation struct {
+	Kind    string `json:"kind"`
+	Table   string `json:"table"`
+	Index   string `json:"index,omitempty"`
+	Message string `json:"message"`
+}
+
+// DBMaintenanceReport is the table and index health report produced by
+// database maintenance.
+type DBMaintenanceReport struct {
+	GeneratedAt     time.Time          `json:"generatedAt"`
+	Analyzed        []string           `json:"analyzed,omitempty"`
+	Tables          []DBTableReport    `json:"tables"`
+	Indexes         []DBIndexReport    `json:"indexes"`
+	Recommendations []DBRecommendation `json:"recommendations"`
+}
+
+// MaintenanceTables returns the tables analyzed by maintenance runs.
+func MaintenanceTables(cfg *config.Config) []string {
+	if len(cfg.Database.Maintenance.Tables) > 0 {
+		return cfg.Database.Maintenance.Tables
+	}
+	return defaultMaintenanceTables
+}
+
+// BuildDBMaintenanceReport reads table and index statistics and derives
+// recommendations from them without changing anything.
+func BuildDBMaintenanceReport(ctx context.Context, st *store.Store) (DBMaintenanceReport, error) {
+	q := db.New(st.DB)
+	tableRows, err := q.ListTableStats(ctx)
+	if err != nil {
+		return DBMaintenanceReport{}, err
+	}
+	indexRows, err := q.ListIndexStats(ctx)
+	if err != nil {
+		return DBMaintenanceReport{}, err
+	}
+
+	report := DBMaintenanceReport{
+		GeneratedAt: time.Now().UTC(),
+		Tables:      make([]DBTableReport, 0, len(tableRows)),
+		Indexes:     make([]DBIndexReport, 0, len(indexRows)),
+	}
+	for _, r := range tableRows {
+		t := DBTableReport{
+			Table:                r.TableName,
+			LiveTuples:           r.NLiveTup,
+			DeadTuples:           r.NDeadTup,
+			ModifiedSinceAnalyze: r.NModSinceAnalyze,
+			SeqScans:             r.SeqScan,
+			IndexScans:           r.IdxScan,
+			TotalBytes:           r.TotalBytes,
+			LastAnalyze:          nullTimePtr(r.LastAnalyze.Time, r.LastAnalyze.Valid),
+			LastAutoanalyze:      nullTimePtr(r.LastAutoanalyze.Time, r.LastAutoanalyze.Valid),
+			LastAutovacuum:       nullTimePtr(r.LastAutovacuum.Time, r.LastAutovacuum.Valid),
+		}
+		if total := r.NLiveTup + r.NDeadTup; total > 0 {
+			t.DeadRatio = float64(r.NDeadTup) / float64(total)
+		}
+		report.Tables = append(report.Tables, t)
+	}
+	for _, r := range indexRows {
+		report.Indexes = append(report.Indexes, DBIndexReport{
+			Table:     r.TableName,
+			Index:     r.IndexName,
+			Scans:     r.IdxScan,
+			SizeBytes: r.SizeBytes,
+			Unique:    r.IsUnique,
+		})
+	}
+	report.Recommendations = dbRecommendations(report.Tables, report.Indexes)
+	return report, nil
+}
+
+// RunDBMaintenance analyzes the hot tables that exist, reports table and
+// index statistics to metrics, and returns the resulting report. It
+// returns ErrDBMaintenanceBusy when another process holds the
+// maintenance lock.
+func RunDBMaintenance(ctx context.Context, cfg *config.Config, st *store.Store) (DBMaintenanceReport, error) {
+	started := time.Now()
+
+	existing, err := db.New(st.DB).ListTableStats(ctx)
+	if err != nil {
+		return DBMaintenanceReport{}, err
+	}
+	present := make(map[string]bool, len(existing))
+	for _, r := range existing {
+		present[r.TableName] = true
+	}
+	var tables []string
+	for _, t := range MaintenanceTables(cfg) {
+		if present[t] {
+			tables = append(tables, t)
+		}
+	}
+
+	locked, err := st.AnalyzeTables(ctx, tables)
+	if err != nil {
+		return DBMaintenanceReport{}, err
+	}
+	if !locked {
+		return DBMaintenanceReport{}, ErrDBMaintenanceBusy
+	}
+
+	report, err := BuildDBMaintenanceReport(ctx, st)
+	if err != nil {
+		return DBMaintenanceReport{}, err
+	}
+	report.Analyzed = tables
+	recordDBMaintenanceMetrics(report, time.Since(started))
+	return report, nil
+}
+
+// runDBMaintenanceIfDue runs maintenance once per day after the
+// configured hour. The last run is read from the tables' last ANALYZE
+// time, so restarts and other workers do not repeat it.
+func runDBMaintenanceIfDue(ctx context.Context, cfg *config.Config, st *store.Store, now time.Time) error {
+	rows, err := db.New(st.DB).ListTableStats(ctx)
+	if err != nil {
+		return err
+	}
+	hot := make(map[string]bool)
+	for _, t := range MaintenanceTables(cfg) {
+		hot[t] = true
+	}
+	var lastRun time.Time
+	for _, r := range rows {
+		if hot[r.TableName] && r.LastAnalyze.Valid && r.LastAnalyze.Time.After(lastRun) {
+			lastRun = r.LastAnalyze.Time
+		}
+	}
+	if !maintenanceDue(now, lastRun, cfg.Database.Maintenance.HourUTC) {
+		return nil
+	}
+	_, err = RunDBMaintenance(ctx, cfg, st)
+	if errors.Is(err, ErrDBMaintenanceBusy) {
+		return nil
+	}
+	return err
+}
+
+// maintenanceDue reports whether no run has happened since the most
+// recent occurrence of hourUTC.
+func maintenanceDue(now, lastRun time.Time, hourUTC int) bool {
+	now = now.UTC()
+	slot := time.Date(now.Year(), now.Month(), now.Day(), hourUTC, 0, 0, 0, time.UTC)
+	if now.Before(slot) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	return lastRun.Before(slot)
+}
+
+// dbRecommendations flags tables with many dead rows or stale planner
+// statistics, large indexes that are never scanned, and large tables
+// that are mostly read with sequential scans.
+func dbRecommendations(tables []DBTableReport, indexes []DBIndexReport) []DBRecommendation {
+	out := []DBRecommendation{}
+	for _, t := range tables {
+		if t.DeadTuples >= vacuumMinDeadTuples && t.DeadRatio >= vacuumMinDeadRatio {
+			out = append(out, DBRecommendation{
+				Kind:    "vacuum",
+				Table:   t.Table,
+				Message: fmt.Sprintf("%.0f%% of rows in %s are dead; run VACUUM (ANALYZE) %s or make autovacuum more aggressive for it", t.DeadRatio*100, t.Table, t.Table),
+			})
+		}
+		if t.ModifiedSinceAnalyze >= staleStatsMinModified && float64(t.ModifiedSinceAnalyze) >= staleStatsMinRatio*float64(t.LiveTuples) {
+			out = append(out, DBRecommendation{
+				Kind:    "analyze",
+				Table:   t.Table,
+				Message: fmt.Sprintf("%d rows in %s changed since it was last analyzed; run ANALYZE %s or add it to database.maintenance.tables", t.ModifiedSinceAnalyze, t.Table, t.Table),
+			})
+		}
+		if t.LiveTuples >= seqScanMinLiveTuples && t.SeqScans >= seqScanMinScans && t.SeqScans > seqScanToIndexScanRate*t.IndexScans {
+			out = append(out, DBRecommendation{
+				Kind:    "missing_index",
+				Table:   t.Table,
+				Message: fmt.Sprintf("%s is mostly read with sequential scans (%d sequential, %d index); check slow queries on it for a missing index", t.Table, t.SeqScans, t.IndexScans),
+			})
+		}
+	}
+	for _, ix := range indexes {
+		if ix.Scans == 0 && !ix.Unique && ix.SizeBytes >= unusedIndexMinBytes {
+			out = append(out, DBRecommendation{
+				Kind:    "unused_index",
+				Table:   ix.Table,
+				Index:   ix.Index,
+				Message: fmt.Sprintf("index %s on %s (%d MiB) has not been scanned since statistics were reset; consider dropping it", ix.Index, ix.Table, ix.SizeBytes>>20),
+			})
+		}
+	}
+	return out
+}
+
+func recordDBMaintenanceMetrics(report DBMaintenanceReport, duration time.Duration) {
+	tables := make([]metrics.DBTableStat, 0, len(report.Tables))
+	for _, t := range report.Tables {
+		tables = append(tables, metrics.DBTableStat{
+			Table:      t.Table,
+			LiveTuples: t.LiveTuples,
+			DeadTuples: t.DeadTuples,
+			TotalBytes: t.TotalBytes,
+		})
+	}
+	indexes := make([]metrics.DBIndexStat, 0, len(report.Indexes))
+	for _, ix := range report.Indexes {
+		indexes = append(indexes, metrics.DBIndexStat{
+			Table:     ix.Table,
+			Index:     ix.Index,
+			Scans:     ix.Scans,
+			SizeBytes: ix.SizeBytes,
+		})
+	}
+	metrics.RecordDBMaintenance(report.GeneratedAt, duration, tables, indexes)
+}
+
+func nullTimePtr(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
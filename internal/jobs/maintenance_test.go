@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceDue(t *testing.T) {
+	now := time.Date(2026, 3, 10, 4, 30, 0, 0, time.UTC)
+
+	if !maintenanceDue(now, time.Time{}, 3) {
+		t.Fatalf("expected a first run to be due")
+	}
+	if maintenanceDue(now, time.Date(2026, 3, 10, 3, 5, 0, 0, time.UTC), 3) {
+		t.Fatalf("expected no run after today's run")
+	}
+	if !maintenanceDue(now, time.Date(2026, 3, 9, 3, 5, 0, 0, time.UTC), 3) {
+		t.Fatalf("expected a run once today's hour has passed")
+	}
+	// Before today's hour, yesterday's run is still current.
+	if maintenanceDue(now, time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC), 5) {
+		t.Fatalf("expected no run before the scheduled hour")
+	}
+}
+
+func TestDBRecommendations(t *testing.T) {
+	tables := []DBTableReport{
+		{Table: "documents", LiveTuples: 40000, DeadTuples: 20000, DeadRatio: 20000.0 / 60000},
+		{Table: "jobs", LiveTuples: 5000, ModifiedSinceAnalyze: 2000},
+		{Table: "job_events", LiveTuples: 50000, SeqScans: 5000, IndexScans: 10},
+		{Table: "users", LiveTuples: 10, DeadTuples: 5, DeadRatio: 0.33},
+	}
+	indexes := []DBIndexReport{
+		{Table: "documents", Index: "idx_documents_unused", SizeBytes: 8 << 20},
+		{Table: "documents", Index: "documents_pkey", SizeBytes: 8 << 20, Unique: true},
+		{Table: "jobs", Index: "idx_jobs_small", SizeBytes: 8 << 10},
+	}
+
+	got := map[string]string{}
+	for _, r := range dbRecommendations(tables, indexes) {
+		got[r.Kind] = r.Table + "/" + r.Index
+	}
+	want := map[string]string{
+		"vacuum":        "documents/",
+		"analyze":       "jobs/",
+		"missing_index": "job_events/",
+		"unused_index":  "documents/idx_documents_unused",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected recommendations: %v", got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("expected %s recommendation for %s, got %v", k, v, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/jobinput"
+	"raito/internal/notify"
+	"raito/internal/store"
+)
+
+// notify sends a finished job's notifications: to the job's webhook, and
+// to its creator according to their /v1/me/notifications preferences.
+// Delivery errors are recorded on the job's timeline.
+func (r *Runner) notify(jobID uuid.UUID) {
+	ctx := context.Background()
+	job, err := r.store.GetJobByID(ctx, jobID)
+	if err != nil {
+		return
+	}
+	q := db.New(r.store.DB)
+	r.notifyJobWebhook(ctx, q, job)
+	r.notifyOwner(ctx, q, job)
+}
+
+// notifyJobWebhook posts the job's notification to the webhook set on its
+// request, else to its tenant's default webhook when that subscribes to
+// the event.
+func (r *Runner) notifyJobWebhook(ctx context.Context, q *db.Queries, job db.Job) {
+	ev, ok := jobNotificationEvent(job)
+	if !ok {
+		return
+	}
+
+	url, events, err := jobWebhook(ctx, q, job)
+	if err == nil && (url == "" || !slices.Contains(events, ev.Event)) {
+		return
+	}
+	var signing []string
+	if err == nil && job.TenantID.Valid {
+		signing, err = notify.TenantSigningSecrets(ctx, r.cfg, q, job.TenantID.UUID, time.Now())
+	}
+	if err == nil {
+		err = r.notifier.SendWebhook(ctx, url, ev, signing...)
+	}
+	if err != nil {
+		_ = r.store.AddJobEvent(ctx, job.ID, store.JobEventNotificationFailed, err.Error(), map[string]any{"channel": "job_webhook"})
+	}
+}
+
+// jobWebhook returns where a job's webhook notifications go and the events
+// they cover: the request's webhook, which receives every event, else the
+// tenant's default webhook. The URL is empty when there is neither.
+func jobWebhook(ctx context.Context, q *db.Queries, job db.Job) (string, []string, error) {
+	if url := requestWebhook(job); url != "" {
+		return url, notify.Events, nil
+	}
+	if !job.TenantID.Valid {
+		return "", nil, nil
+	}
+	row, err := q.GetTenantWebhook(ctx, job.TenantID.UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	var events []string
+	if err := json.Unmarshal(row.Events, &events); err != nil {
+		return "", nil, err
+	}
+	return row.Url, events, nil
+}
+
+// requestWebhook returns the webhook URL set on the job's request, such
+// as a crawl's "webhook".
+func requestWebhook(job db.Job) string {
+	var input struct {
+		Webhook string `json:"webhook"`
+	}
+	if err := jobinput.Decode(job.Type, job.Input, &input); err != nil {
+		return ""
+	}
+	return input.Webhook
+}
+
+// notifyOwner sends the job creator's completion or failure notifications
+// according to their preferences.
+func (r *Runner) notifyOwner(ctx context.Context, q *db.Queries, job db.Job) {
+	if !job.CreatedByUserID.Valid {
+		return
+	}
+	prefs, err := q.GetUserNotificationPreferences(ctx, job.CreatedByUserID.UUID)
+	if err != nil {
+		return
+	}
+	ev, ok := notificationEvent(job, prefs)
+	if !ok {
+		return
+	}
+
+	if prefs.WebhookUrl.Valid && prefs.WebhookUrl.String != "" {
+		var signing []string
+		if job.TenantID.Valid {
+			signing, err = notify.TenantSigningSecrets(ctx, r.cfg, q, job.TenantID.UUID, time.Now())
+		}
+		if err == nil {
+			err = r.notifier.SendWebhook(ctx, prefs.WebhookUrl.String, ev, signing...)
+		}
+		if err != nil {
+			_ = r.store.AddJobEvent(ctx, job.ID, store.JobEventNotificationFailed, err.Error(), map[string]any{"channel": "webhook"})
+		}
+	}
+	if prefs.Email && notify.EmailAvailable(r.cfg.Notifications) {
+		user, err := q.GetUserByID(ctx, job.CreatedByUserID.UUID)
+		if err == nil {
+			err = r.notifier.SendEmail(user.Email, ev)
+		}
+		if err != nil {
+			_ = r.store.AddJobEvent(ctx, job.ID, store.JobEventNotificationFailed, err.Error(), map[string]any{"channel": "email"})
+		}
+	}
+}
+
+// notificationEvent builds the notification for a finished job, reporting
+// false when the preferences do not ask for one.
+func notificationEvent(job db.Job, prefs db.UserNotificationPreference) (notify.Event, bool) {
+	ev, ok := jobNotificationEvent(job)
+	if !ok {
+		return notify.Event{}, false
+	}
+	switch ev.Event {
+	case notify.EventJobCompleted:
+		ok = prefs.OnCompleted
+	case notify.EventJobFailed:
+		ok = prefs.OnFailed
+	}
+	if !ok {
+		return notify.Event{}, false
+	}
+	return ev, true
+}
+
+// jobNotificationEvent builds the notification for a finished job,
+// reporting false for unfinished jobs. Synchronous jobs are skipped since
+// their caller already waits for the result.
+func jobNotificationEvent(job db.Job) (notify.Event, bool) {
+	if job.Sync {
+		return notify.Event{}, false
+	}
+
+	ev := notify.Event{
+		JobID:     job.ID.String(),
+		JobType:   job.Type,
+		Status:    PublicStatus(job.Status),
+		URL:       job.Url,
+		CreatedAt: job.CreatedAt,
+	}
+	switch Status(job.Status) {
+	case StatusCompleted:
+		ev.Event = notify.EventJobCompleted
+	case StatusFailed, StatusDeadLetter:
+		ev.Event = notify.EventJobFailed
+		ev.Error = job.Error.String
+	default:
+		return notify.Event{}, false
+	}
+	if job.CompletedAt.Valid {
+		t := job.CompletedAt.Time.UTC()
+		ev.CompletedAt = &t
+	} else {
+		t := time.Now().UTC()
+		ev.CompletedAt = &t
+	}
+	return ev, true
+}
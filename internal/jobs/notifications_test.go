@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/db"
+	"raito/internal/jobinput"
+	"raito/internal/notify"
+)
+
+func TestNotificationEvent(t *testing.T) {
+	finished := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	job := db.Job{
+		ID:          uuid.New(),
+		Type:        "crawl",
+		Url:         "https://example.com",
+		CompletedAt: sql.NullTime{Time: finished, Valid: true},
+	}
+	both := db.UserNotificationPreference{OnCompleted: true, OnFailed: true}
+
+	job.Status = string(StatusCompleted)
+	ev, ok := notificationEvent(job, both)
+	if !ok || ev.Event != notify.EventJobCompleted || ev.CompletedAt == nil || !ev.CompletedAt.Equal(finished) {
+		t.Fatalf("unexpected completion event: %+v, %v", ev, ok)
+	}
+	if _, ok := notificationEvent(job, db.UserNotificationPreference{OnFailed: true}); ok {
+		t.Fatalf("expected no event when completions are not subscribed")
+	}
+
+	job.Status = string(StatusFailed)
+	job.Error = sql.NullString{String: "CRAWL_FAILED: boom", Valid: true}
+	ev, ok = notificationEvent(job, both)
+	if !ok || ev.Event != notify.EventJobFailed || ev.Error != "CRAWL_FAILED: boom" {
+		t.Fatalf("unexpected failure event: %+v, %v", ev, ok)
+	}
+
+	// Dead-letter jobs are reported as failed.
+	job.Status = string(StatusDeadLetter)
+	ev, ok = notificationEvent(job, both)
+	if !ok || ev.Event != notify.EventJobFailed || ev.Status != string(StatusFailed) {
+		t.Fatalf("unexpected dead-letter event: %+v, %v", ev, ok)
+	}
+
+	job.Status = string(StatusRunning)
+	if _, ok := notificationEvent(job, both); ok {
+		t.Fatalf("expected no event for an unfinished job")
+	}
+
+	job.Status = string(StatusCompleted)
+	job.Sync = true
+	if _, ok := notificationEvent(job, both); ok {
+		t.Fatalf("expected no event for a synchronous job")
+	}
+}
+
+func TestRequestWebhook(t *testing.T) {
+	input, err := jobinput.Encode(map[string]any{"url": "https://example.com", "webhook": "https://hooks.example.com/crawl"})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	job := db.Job{Type: "crawl", Input: input}
+	if got := requestWebhook(job); got != "https://hooks.example.com/crawl" {
+		t.Fatalf("unexpected request webhook %q", got)
+	}
+
+	// Inputs stored before the envelope existed.
+	job.Input = []byte(`{"url":"https://example.com","webhook":"https://hooks.example.com/old"}`)
+	if got := requestWebhook(job); got != "https://hooks.example.com/old" {
+		t.Fatalf("unexpected request webhook %q", got)
+	}
+
+	job.Input = []byte(`{"url":"https://example.com"}`)
+	if got := requestWebhook(job); got != "" {
+		t.Fatalf("expected no request webhook, got %q", got)
+	}
+}
@@ -0,0 +1,17 @@
+package jobs
+
+// Types lists the job types workers execute.
+var Types = []string{"scrape", "map", "crawl", "extract", "batch_scrape", "research", "summarize"}
+
+// QueuePauseAll is the queue_pauses job type that pauses every type.
+const QueuePauseAll = "*"
+
+// IsType reports whether t is one of Types.
+func IsType(t string) bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"raito/internal/db"
+	"raito/internal/metrics"
+)
+
+// queueMetricsInterval is how often the runner refreshes the pending job
+// gauge from the jobs table.
+const queueMetricsInterval = 15 * time.Second
+
+// recordPendingJobs reports the pending jobs of every type, across all
+// workers and pools.
+func (r *Runner) recordPendingJobs(ctx context.Context) {
+	rows, err := db.New(r.store.DB).CountPendingJobsByType(ctx)
+	if err != nil {
+		return
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Pending
+	}
+	metrics.SetJobsPending(counts)
+}
+
+// startJobMetrics records a claimed job's queue wait and counts it as
+// running. The returned function records its execution time and final
+// status once the executor returns.
+func (r *Runner) startJobMetrics(job db.Job) func() {
+	started := time.Now()
+	tenant := ""
+	if job.TenantID.Valid {
+		tenant = job.TenantID.UUID.String()
+	}
+	metrics.RecordJobQueueWait(job.Type, tenant, started.Sub(job.CreatedAt))
+	metrics.AddJobsRunning(job.Type, 1)
+
+	return func() {
+		metrics.AddJobsRunning(job.Type, -1)
+		status := "unknown"
+		if final, err := r.store.GetJobByID(context.Background(), job.ID); err == nil {
+			status = final.Status
+		}
+		metrics.RecordJobExecution(job.Type, tenant, status, time.Since(started))
+	}
+}
@@ -11,8 +11,63 @@ import (
 
 // RetentionStats captures the number of records deleted by TTL cleanup.
 type RetentionStats struct {
-	DocumentsDeleted int64            `json:"documentsDeleted"`
-	JobsDeleted      map[string]int64 `json:"jobsDeleted"`
+	DocumentsDeleted    int64            `json:"documentsDeleted"`
+	JobsDeleted         map[string]int64 `json:"jobsDeleted"`
+	SessionsDeleted     int64            `json:"sessionsDeleted"`
+	ExtractCacheDeleted int64            `json:"extractCacheDeleted"`
+	ZeroRetentionPurged int64            `json:"zeroRetentionPurged"`
+	// DocumentChangesCompacted counts change feed entries removed.
+	DocumentChangesCompacted int64 `json:"documentChangesCompacted"`
+	// DocumentVersionsDeleted counts URL history versions not seen
+	// within the documents TTL.
+	DocumentVersionsDeleted int64 `json:"documentVersionsDeleted"`
+}
+
+// defaultZeroRetentionGrace is used when retention.zeroRetentionMinutes
+// is unset.
+const defaultZeroRetentionGrace = time.Hour
+
+// zeroRetentionSweepInterval is how often the worker purges expired
+// zero-retention jobs.
+const zeroRetentionSweepInterval = time.Minute
+
+// defaultDocumentVersionsPerURL is used when
+// retention.documentVersionsPerUrl is unset.
+const defaultDocumentVersionsPerURL = 20
+
+// DocumentVersionsPerURL returns how many content versions of each URL a
+// tenant's history keeps.
+func DocumentVersionsPerURL(cfg *config.Config) int {
+	if cfg.Retention.DocumentVersionsPerURL > 0 {
+		return cfg.Retention.DocumentVersionsPerURL
+	}
+	return defaultDocumentVersionsPerURL
+}
+
+// ZeroRetentionGrace returns how long a finished zero-retention job may
+// hold undelivered results before they are purged.
+func ZeroRetentionGrace(cfg *config.Config) time.Duration {
+	if cfg.Retention.ZeroRetentionMinutes > 0 {
+		return time.Duration(cfg.Retention.ZeroRetentionMinutes) * time.Minute
+	}
+	return defaultZeroRetentionGrace
+}
+
+// PurgeZeroRetentionJobs deletes the data of zero-retention jobs whose
+// results were not fetched within the grace period, returning the number
+// of jobs purged.
+func PurgeZeroRetentionJobs(ctx context.Context, cfg *config.Config, st *store.Store) int64 {
+	ids, err := st.ListUnpurgedZeroRetentionJobs(ctx, time.Now().UTC().Add(-ZeroRetentionGrace(cfg)))
+	if err != nil {
+		return 0
+	}
+	var n int64
+	for _, id := range ids {
+		if err := st.PurgeJobData(ctx, id); err == nil {
+			n++
+		}
+	}
+	return n
 }
 
 // CleanupExpiredData deletes old jobs and documents based on retention
@@ -28,6 +83,9 @@ func CleanupExpiredData(ctx context.Context, cfg *config.Config, st *store.Store
 			stats.DocumentsDeleted += n
 			metrics.RecordRetentionDocuments(n)
 		}
+		if n, err := st.DeleteExpiredDocumentVersions(ctx, cutoff); err == nil {
+			stats.DocumentVersionsDeleted = n
+		}
 	}
 
 	// Jobs TTL per job type, falling back to defaultDays when specific
@@ -58,6 +116,28 @@ func CleanupExpiredData(ctx context.Context, cfg *config.Config, st *store.Store
 	applyJobTTL("extract", effectiveDays(jobTTL.ExtractDays))
 	applyJobTTL("crawl", effectiveDays(jobTTL.CrawlDays))
 	applyJobTTL("batch_scrape", effectiveDays(0))
+	applyJobTTL("research", effectiveDays(jobTTL.ExtractDays))
+	applyJobTTL("summarize", effectiveDays(jobTTL.ExtractDays))
+
+	// Browser sessions are kept for a week past expiry so recent logins can
+	// still be inspected, then removed.
+	if n, err := st.DeleteExpiredSessions(ctx, now.AddDate(0, 0, -7)); err == nil {
+		stats.SessionsDeleted = n
+	}
+
+	if n, err := st.DeleteExpiredExtractCache(ctx, now); err == nil {
+		stats.ExtractCacheDeleted = n
+	}
+
+	stats.ZeroRetentionPurged = PurgeZeroRetentionJobs(ctx, cfg, st)
+
+	// Compacted after the deletes above so their feed entries are kept
+	// for the full window.
+	if days := cfg.Retention.DocumentChangesDays; days > 0 {
+		if n, err := st.CompactDocumentChanges(ctx, now.AddDate(0, 0, -days)); err == nil {
+			stats.DocumentChangesCompacted = n
+		}
+	}
 
 	return stats
 }
@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+)
+
+// DefaultPool is the pool jobs are routed to, and workers serve, when
+// jobRouting.defaultPool is not set.
+const DefaultPool = "default"
+
+// ErrPoolNotAllowed is returned by RoutePool when a request names a pool
+// that is not listed in jobRouting.allowedPools.
+var ErrPoolNotAllowed = errors.New("pool is not in jobRouting.allowedPools")
+
+// RouteRequest describes a job being enqueued for pool routing.
+type RouteRequest struct {
+	TenantID *uuid.UUID
+	// Requested is the request's explicit "pool" option, if any.
+	Requested string
+	// NeedsBrowser is set when the job asks for browser rendering.
+	NeedsBrowser bool
+}
+
+// RoutePool picks the worker pool for a new job (see
+// config.JobRoutingConfig for the precedence rules).
+func RoutePool(cfg *config.Config, r RouteRequest) (string, error) {
+	routing := cfg.JobRouting
+
+	if requested := strings.TrimSpace(r.Requested); requested != "" {
+		for _, p := range routing.AllowedPools {
+			if p == requested {
+				return requested, nil
+			}
+		}
+		return "", ErrPoolNotAllowed
+	}
+	if r.TenantID != nil {
+		if p := routing.TenantPools[r.TenantID.String()]; p != "" {
+			return p, nil
+		}
+	}
+	if r.NeedsBrowser && routing.BrowserPool != "" {
+		return routing.BrowserPool, nil
+	}
+	return defaultPool(cfg), nil
+}
+
+// WorkerPools returns the pools this process claims jobs from.
+func WorkerPools(cfg *config.Config) []string {
+	var pools []string
+	for _, p := range cfg.Worker.Pools {
+		if p = strings.TrimSpace(p); p != "" {
+			pools = append(pools, p)
+		}
+	}
+	if len(pools) == 0 {
+		return []string{defaultPool(cfg)}
+	}
+	return pools
+}
+
+func defaultPool(cfg *config.Config) string {
+	if p := strings.TrimSpace(cfg.JobRouting.DefaultPool); p != "" {
+		return p
+	}
+	return DefaultPool
+}
@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+)
+
+func TestRoutePool_Precedence(t *testing.T) {
+	euTenant := uuid.New()
+	otherTenant := uuid.New()
+	cfg := &config.Config{
+		JobRouting: config.JobRoutingConfig{
+			TenantPools:  map[string]string{euTenant.String(): "eu"},
+			BrowserPool:  "browser",
+			AllowedPools: []string{"eu", "gpu"},
+		},
+	}
+
+	cases := []struct {
+		name string
+		req  RouteRequest
+		want string
+	}{
+		{"default", RouteRequest{}, DefaultPool},
+		{"unmapped tenant", RouteRequest{TenantID: &otherTenant}, DefaultPool},
+		{"browser", RouteRequest{TenantID: &otherTenant, NeedsBrowser: true}, "browser"},
+		{"tenant beats browser", RouteRequest{TenantID: &euTenant, NeedsBrowser: true}, "eu"},
+		{"requested beats tenant", RouteRequest{TenantID: &euTenant, Requested: "gpu"}, "gpu"},
+	}
+	for _, tc := range cases {
+		got, err := RoutePool(cfg, tc.req)
+		if err != nil {
+			t.Fatalf("%s: RoutePool: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got pool %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := RoutePool(cfg, RouteRequest{Requested: "browser"}); !errors.Is(err, ErrPoolNotAllowed) {
+		t.Fatalf("expected ErrPoolNotAllowed for unlisted pool, got %v", err)
+	}
+}
+
+func TestWorkerPools(t *testing.T) {
+	cfg := &config.Config{}
+	if got := WorkerPools(cfg); !reflect.DeepEqual(got, []string{DefaultPool}) {
+		t.Fatalf("expected default pool, got %v", got)
+	}
+
+	cfg.JobRouting.DefaultPool = "us"
+	if got := WorkerPools(cfg); !reflect.DeepEqual(got, []string{"us"}) {
+		t.Fatalf("expected configured default pool, got %v", got)
+	}
+
+	cfg.Worker.Pools = []string{" eu ", "", "browser"}
+	if got := WorkerPools(cfg); !reflect.DeepEqual(got, []string{"eu", "browser"}) {
+		t.Fatalf("unexpected worker pools: %v", got)
+	}
+}
@@ -2,10 +2,17 @@ package jobs
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
+
 	"raito/internal/config"
 	"raito/internal/db"
+	"raito/internal/events"
+	"raito/internal/metrics"
+	"raito/internal/notify"
 	"raito/internal/store"
 )
 
@@ -35,6 +42,18 @@ type ScrapeJobExecutor interface {
 	ExecuteScrapeJob(ctx context.Context, job db.Job)
 }
 
+// ResearchJobExecutor executes a single research job: a search whose top
+// results are scraped and merged into one extracted answer.
+type ResearchJobExecutor interface {
+	ExecuteResearchJob(ctx context.Context, job db.Job)
+}
+
+// SummarizeJobExecutor executes a single summarize job: stored documents
+// condensed into one report with map-reduce LLM calls.
+type SummarizeJobExecutor interface {
+	ExecuteSummarizeJob(ctx context.Context, job db.Job)
+}
+
 // Executors groups the concrete executors for each job type.
 type Executors struct {
 	Map         MapJobExecutor
@@ -42,15 +61,20 @@ type Executors struct {
 	Extract     ExtractJobExecutor
 	BatchScrape BatchScrapeJobExecutor
 	Scrape      ScrapeJobExecutor
+	Research    ResearchJobExecutor
+	Summarize   SummarizeJobExecutor
 }
 
 // Runner is responsible for polling the jobs table and dispatching
 // work to job-type-specific executors. It encapsulates concurrency
-// limits, polling intervals, and periodic retention cleanup.
+// limits, polling intervals, periodic retention cleanup, and nightly
+// database maintenance.
 type Runner struct {
 	cfg       *config.Config
 	store     *store.Store
 	executors Executors
+	workerID  string
+	notifier  *notify.Notifier
 }
 
 // NewRunner constructs a Runner with the given configuration, store,
@@ -61,27 +85,34 @@ func NewRunner(cfg *config.Config, st *store.Store, execs Executors) *Runner {
 		cfg:       cfg,
 		store:     st,
 		executors: execs,
+		workerID:  newWorkerID(),
+		notifier:  notify.New(cfg.Notifications),
 	}
 }
 
 // Start launches the worker loop in the current goroutine. Callers
 // typically run this in its own goroutine and keep the process alive.
 func (r *Runner) Start(ctx context.Context) {
-	pollInterval := time.Duration(r.cfg.Worker.PollIntervalMs) * time.Millisecond
-	if pollInterval <= 0 {
-		pollInterval = 2 * time.Second
-	}
+	pollInterval := PollInterval(r.cfg, events.Shared())
 
 	maxJobs := r.cfg.Worker.MaxConcurrentJobs
 	if maxJobs <= 0 {
 		maxJobs = 4
 	}
 
+	pools := WorkerPools(r.cfg)
+	go r.runRegistry(ctx, pools, maxJobs)
+
+	// Queued jobs and jobs finishing here wake the loop before the next
+	// poll.
+	wake := make(chan struct{}, 1)
+	go watchQueuedJobs(ctx, pools, wake)
+
 	sem := make(chan struct{}, maxJobs)
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
-	var lastCleanup time.Time
+	var lastCleanup, lastZeroRetentionSweep, lastMaintenanceCheck, lastQueueMetrics time.Time
 	cleanupInterval := time.Duration(r.cfg.Retention.CleanupIntervalMinutes) * time.Minute
 	if cleanupInterval <= 0 {
 		cleanupInterval = time.Hour
@@ -92,6 +123,7 @@ func (r *Runner) Start(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+		case <-wake:
 		}
 
 		// Periodically run TTL cleanup for jobs/documents.
@@ -103,13 +135,35 @@ func (r *Runner) Start(ctx context.Context) {
 			}
 		}
 
+		// Undelivered zero-retention results are purged on a short cycle
+		// regardless of TTL cleanup settings.
+		if now := time.Now().UTC(); now.Sub(lastZeroRetentionSweep) >= zeroRetentionSweepInterval {
+			_ = PurgeZeroRetentionJobs(ctx, r.cfg, r.store)
+			lastZeroRetentionSweep = now
+		}
+
+		// Nightly database maintenance; the check is cheap, but there is
+		// no need to make it on every poll.
+		if r.cfg.Database.Maintenance.Enabled {
+			if now := time.Now().UTC(); now.Sub(lastMaintenanceCheck) >= dbMaintenanceCheckInterval {
+				_ = runDBMaintenanceIfDue(ctx, r.cfg, r.store, now)
+				lastMaintenanceCheck = now
+			}
+		}
+
+		// Queue depth for /metrics.
+		if now := time.Now(); now.Sub(lastQueueMetrics) >= queueMetricsInterval {
+			r.recordPendingJobs(ctx)
+			lastQueueMetrics = now
+		}
+
 		// Determine how many new jobs we can start based on current concurrency.
 		capacity := maxJobs - len(sem)
 		if capacity <= 0 {
 			continue
 		}
 
-		jobs, err := r.store.ListPendingJobs(ctx, int32(capacity))
+		jobs, err := r.store.ListPendingJobs(ctx, int32(capacity), pools, SyncPriorityBoost(r.cfg))
 		if err != nil {
 			// TODO: add logging once structured logging is available here.
 			continue
@@ -119,14 +173,96 @@ func (r *Runner) Start(ctx context.Context) {
 			job := job
 			sem <- struct{}{}
 			go func() {
-				defer func() { <-sem }()
+				defer func() {
+					<-sem
+					signal(wake)
+				}()
 				r.dispatchJob(ctx, job)
 			}()
 		}
 	}
 }
 
+// PollInterval returns how often the worker looks for pending jobs
+// without being woken: worker.pollIntervalMs (default 2s), or, when
+// shared events wake workers as jobs are queued,
+// worker.fallbackPollIntervalMs (default 10s).
+func PollInterval(cfg *config.Config, shared bool) time.Duration {
+	if shared {
+		if cfg.Worker.FallbackPollIntervalMs > 0 {
+			return time.Duration(cfg.Worker.FallbackPollIntervalMs) * time.Millisecond
+		}
+		return 10 * time.Second
+	}
+	if cfg.Worker.PollIntervalMs > 0 {
+		return time.Duration(cfg.Worker.PollIntervalMs) * time.Millisecond
+	}
+	return 2 * time.Second
+}
+
+// watchQueuedJobs signals wake whenever a job is queued in one of pools.
+func watchQueuedJobs(ctx context.Context, pools []string, wake chan<- struct{}) {
+	for m := range events.Subscribe(ctx, events.JobQueuedTopic) {
+		if pool := string(m.Payload); pool == "" || slices.Contains(pools, pool) {
+			signal(wake)
+		}
+	}
+}
+
+// signal leaves a wakeup on ch unless one is already pending.
+func signal(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// SyncPriorityBoost returns how much and after how long the claim
+// priority of a pending synchronous job is raised. The boost starts
+// halfway through the time callers wait for sync jobs.
+func SyncPriorityBoost(cfg *config.Config) store.SyncBoost {
+	if cfg.Worker.SyncPriorityBoost < 0 {
+		return store.SyncBoost{}
+	}
+	boost := store.SyncBoost{Priority: 1000}
+	if cfg.Worker.SyncPriorityBoost > 0 {
+		boost.Priority = int32(cfg.Worker.SyncPriorityBoost)
+	}
+	after := cfg.Worker.SyncBoostAfterMs
+	if after <= 0 {
+		wait := cfg.Worker.SyncJobWaitTimeoutMs
+		if wait <= 0 {
+			wait = cfg.Scraper.TimeoutMs
+		}
+		after = wait / 2
+	}
+	boost.After = time.Duration(after) * time.Millisecond
+	return boost
+}
+
 func (r *Runner) dispatchJob(ctx context.Context, job db.Job) {
+	// Notify the job's creator once the executor has settled the job's
+	// final status. Delivery runs in the background so slow endpoints do
+	// not hold a job slot.
+	defer func() { go r.notify(job.ID) }()
+
+	// Queue wait, execution time, and running count for /metrics.
+	defer r.startJobMetrics(job)()
+
+	// Measure the job's resource usage; executors, scrapers, and LLM
+	// clients report into the runtime attached to the context.
+	rt := metrics.NewJobRuntime()
+	defer r.recordJobRuntime(job, rt)
+	ctx = metrics.WithJobRuntime(ctx, rt)
+
+	stopHeartbeat := r.startHeartbeat(ctx, job.ID, rt)
+	defer stopHeartbeat()
+
+	_ = r.store.AddJobEvent(ctx, job.ID, store.JobEventClaimed, "", map[string]any{
+		"workerId": r.workerID,
+		"queuedMs": time.Since(job.CreatedAt).Milliseconds(),
+	})
+
 	// Delegate to the appropriate executor based on the job type.
 	switch job.Type {
 	case "crawl":
@@ -154,9 +290,74 @@ func (r *Runner) dispatchJob(ctx context.Context, job db.Job) {
 			r.executors.BatchScrape.ExecuteBatchScrapeJob(ctx, job)
 			return
 		}
+	case "research":
+		if r.executors.Research != nil {
+			r.executors.Research.ExecuteResearchJob(ctx, job)
+			return
+		}
+	case "summarize":
+		if r.executors.Summarize != nil {
+			r.executors.Summarize.ExecuteSummarizeJob(ctx, job)
+			return
+		}
 	}
 
-	// Unknown or unconfigured job type; mark as failed.
+	// Unknown or unconfigured job type; dead-letter it so it can be
+	// requeued once a worker handles the type.
 	msg := "UNKNOWN_JOB_TYPE: " + job.Type
-	_ = r.store.UpdateCrawlJobStatus(context.Background(), job.ID, string(StatusFailed), &msg)
+	_ = r.store.UpdateCrawlJobStatus(context.Background(), job.ID, string(StatusDeadLetter), &msg)
+}
+
+// recordJobRuntime persists a finished job's runtime metrics and observes
+// them in the per-job-type histograms.
+func (r *Runner) recordJobRuntime(job db.Job, rt *metrics.JobRuntime) {
+	stats := rt.Stats()
+	metrics.RecordJobRuntime(job.Type, stats)
+	if raw, err := json.Marshal(stats); err == nil {
+		_ = r.store.SetJobMetrics(context.Background(), job.ID, raw)
+	}
+}
+
+// startHeartbeat reports the job's progress under this worker's ID until
+// the returned stop function is called, which also removes the heartbeat.
+func (r *Runner) startHeartbeat(ctx context.Context, jobID uuid.UUID, rt *metrics.JobRuntime) func() {
+	interval := HeartbeatInterval(r.cfg)
+
+	q := db.New(r.store.DB)
+	beat := func() {
+		p := rt.Progress()
+		_ = q.UpsertJobHeartbeat(context.Background(), db.UpsertJobHeartbeatParams{
+			JobID:      jobID,
+			WorkerID:   r.workerID,
+			StartedAt:  p.StartedAt,
+			PagesDone:  p.PagesDone,
+			PagesTotal: p.PagesTotal,
+			CurrentUrl: p.CurrentURL,
+		})
+	}
+	beat()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				beat()
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		_ = q.DeleteJobHeartbeat(context.Background(), jobID)
+	}
 }
@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/store"
+)
+
+func TestSyncPriorityBoost(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Worker.SyncJobWaitTimeoutMs = 60000
+	if got := SyncPriorityBoost(cfg); got != (store.SyncBoost{Priority: 1000, After: 30 * time.Second}) {
+		t.Fatalf("unexpected default boost %+v", got)
+	}
+
+	// Without a sync wait timeout, callers wait for the scrape timeout.
+	cfg.Worker.SyncJobWaitTimeoutMs = 0
+	cfg.Scraper.TimeoutMs = 20000
+	if got := SyncPriorityBoost(cfg); got.After != 10*time.Second {
+		t.Fatalf("expected boost after 10s, got %v", got.After)
+	}
+
+	cfg.Worker.SyncPriorityBoost = 50
+	cfg.Worker.SyncBoostAfterMs = 2000
+	if got := SyncPriorityBoost(cfg); got != (store.SyncBoost{Priority: 50, After: 2 * time.Second}) {
+		t.Fatalf("unexpected configured boost %+v", got)
+	}
+
+	cfg.Worker.SyncPriorityBoost = -1
+	if got := SyncPriorityBoost(cfg); got != (store.SyncBoost{}) {
+		t.Fatalf("expected no boost, got %+v", got)
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	cfg := &config.Config{}
+	if got := PollInterval(cfg, false); got != 2*time.Second {
+		t.Fatalf("unexpected default poll interval %v", got)
+	}
+	if got := PollInterval(cfg, true); got != 10*time.Second {
+		t.Fatalf("unexpected default fallback poll interval %v", got)
+	}
+	cfg.Worker.PollIntervalMs = 500
+	cfg.Worker.FallbackPollIntervalMs = 30000
+	if got := PollInterval(cfg, false); got != 500*time.Millisecond {
+		t.Fatalf("expected the configured poll interval, got %v", got)
+	}
+	if got := PollInterval(cfg, true); got != 30*time.Second {
+		t.Fatalf("expected the configured fallback poll interval, got %v", got)
+	}
+}
+
+func TestWatchQueuedJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wake := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchQueuedJobs(ctx, []string{"default", "eu"}, wake)
+	}()
+
+	// Wait for the subscription before publishing.
+	publish := func(pool string) bool {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			store.PublishJobQueued(ctx, pool)
+			select {
+			case <-wake:
+				return true
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		return false
+	}
+	if !publish("eu") {
+		t.Fatal("expected a job queued in a served pool to wake the worker")
+	}
+	if !publish("") {
+		t.Fatal("expected a wakeup for every pool to wake the worker")
+	}
+	store.PublishJobQueued(ctx, "gpu")
+	select {
+	case <-wake:
+		t.Fatal("expected a job queued in another pool not to wake the worker")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	<-done
+}
@@ -14,4 +14,24 @@ const (
 	StatusRunning   Status = "running"
 	StatusCompleted Status = "completed"
 	StatusFailed    Status = "failed"
+	// StatusPaused is a crawl stopped with POST /v1/crawl/:id/pause; it
+	// is queued again when resumed.
+	StatusPaused Status = "paused"
+	// StatusDeadLetter is a job that could not run rather than failing on
+	// its own: its input could not be read, no executor handles its type,
+	// or its worker was lost. It stays put until an admin requeues it with
+	// POST /admin/jobs/:id/requeue. Jobs are not retried automatically, so
+	// a job that fails on its own ends in StatusFailed instead.
+	StatusDeadLetter Status = "dead_letter"
 )
+
+// PublicStatus returns the status reported by the per-endpoint status
+// routes and to synchronous callers, which only know "failed": dead-letter
+// jobs are reported as failed there. The jobs and admin APIs show the
+// stored status.
+func PublicStatus(status string) string {
+	if status == string(StatusDeadLetter) {
+		return string(StatusFailed)
+	}
+	return status
+}
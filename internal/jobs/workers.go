@@ -0,0 +1,125 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/db"
+)
+
+// deadWorkerRetention is how long a silent worker stays listed in
+// GET /admin/workers before its row is pruned.
+const deadWorkerRetention = 24 * time.Hour
+
+// workerLostMessage is the error FailJobsOfDeadWorkers stores on the jobs
+// it dead-letters.
+const workerLostMessage = "WORKER_LOST: the worker running this job stopped sending heartbeats"
+
+// WorkerCapabilities is stored with a worker's registration.
+type WorkerCapabilities struct {
+	Rod               bool     `json:"rod"`
+	Pools             []string `json:"pools"`
+	MaxConcurrentJobs int      `json:"maxConcurrentJobs"`
+}
+
+// HeartbeatInterval is how often workers and running jobs report in.
+func HeartbeatInterval(cfg *config.Config) time.Duration {
+	if cfg.Worker.HeartbeatIntervalMs > 0 {
+		return time.Duration(cfg.Worker.HeartbeatIntervalMs) * time.Millisecond
+	}
+	return 5 * time.Second
+}
+
+// DeadAfter is how long a worker or job may go without a heartbeat before
+// it is considered dead.
+func DeadAfter(cfg *config.Config) time.Duration {
+	return 3 * HeartbeatInterval(cfg)
+}
+
+// newWorkerID identifies this worker process. The random suffix keeps a
+// restarted container (same hostname and PID) from taking over the
+// identity, and so the orphaned jobs, of its previous run.
+func newWorkerID() string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", hostname(), os.Getpid(), hex.EncodeToString(suffix))
+}
+
+func hostname() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "unknown"
+	}
+	return host
+}
+
+// buildVersion reports the module version or VCS revision the binary was
+// built from.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && len(s.Value) >= 12 {
+			return s.Value[:12]
+		}
+	}
+	return "dev"
+}
+
+// runRegistry registers this worker, refreshes its heartbeat, and fails
+// jobs left running by dead workers until ctx is done.
+func (r *Runner) runRegistry(ctx context.Context, pools []string, maxJobs int) {
+	q := db.New(r.store.DB)
+
+	caps, _ := json.Marshal(WorkerCapabilities{
+		Rod:               r.cfg.Rod.Enabled,
+		Pools:             pools,
+		MaxConcurrentJobs: maxJobs,
+	})
+	register := func() {
+		_ = q.UpsertWorker(context.Background(), db.UpsertWorkerParams{
+			ID:           r.workerID,
+			Hostname:     hostname(),
+			Version:      buildVersion(),
+			Capabilities: caps,
+		})
+	}
+	register()
+
+	ticker := time.NewTicker(HeartbeatInterval(r.cfg))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = q.DeleteWorker(context.Background(), r.workerID)
+			return
+		case <-ticker.C:
+		}
+
+		// Re-register if the row was pruned while this worker was stalled.
+		if n, err := q.TouchWorker(ctx, r.workerID); err == nil && n == 0 {
+			register()
+		}
+
+		now := time.Now()
+		// The reaper bypasses UpdateCrawlJobStatus, so record the lost jobs
+		// on their timelines here.
+		lost, _ := q.FailJobsOfDeadWorkers(ctx, now.Add(-DeadAfter(r.cfg)))
+		for _, jobID := range lost {
+			_ = r.store.AddJobEvent(ctx, jobID, string(StatusDeadLetter), workerLostMessage, nil)
+		}
+		_, _ = q.DeleteWorkersSilentSince(ctx, now.Add(-deadWorkerRetention))
+	}
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"raito/internal/config"
+)
+
+func TestDeadAfter(t *testing.T) {
+	cfg := &config.Config{}
+	if got := DeadAfter(cfg); got != 15*time.Second {
+		t.Fatalf("expected default dead-after of 15s, got %v", got)
+	}
+
+	cfg.Worker.HeartbeatIntervalMs = 1000
+	if got := DeadAfter(cfg); got != 3*time.Second {
+		t.Fatalf("expected dead-after of 3s, got %v", got)
+	}
+}
+
+func TestNewWorkerIDIsUniquePerRun(t *testing.T) {
+	a, b := newWorkerID(), newWorkerID()
+	if a == b {
+		t.Fatalf("expected distinct worker IDs, got %q twice", a)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"raito/internal/config"
+	"raito/internal/metrics"
 )
 
 // Provider represents a logical LLM provider.
@@ -46,6 +47,19 @@ type ExtractRequest struct {
 // ExtractResult is the structured output from the LLM.
 type ExtractResult struct {
 	Fields map[string]any
+	Usage  Usage
+}
+
+// Usage is the token usage reported by the provider for one call. It is
+// zero when the provider does not report usage.
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// Total returns the combined input and output token count.
+func (u Usage) Total() int64 {
+	return u.InputTokens + u.OutputTokens
 }
 
 // Client is the abstraction used by the HTTP layer.
@@ -77,23 +91,36 @@ func parseJSONFields(content string) (map[string]any, error) {
 	return fields, nil
 }
 
-// NewClientFromConfig constructs a Client based on global config and optional
-// per-request provider/model overrides.
-func NewClientFromConfig(cfg *config.Config, providerOverride, modelOverride string) (Client, Provider, string, error) {
+// ResolveModel returns the provider and model NewClientFromConfig would
+// use for the given overrides. The model is empty for unknown providers.
+func ResolveModel(cfg *config.Config, providerOverride, modelOverride string) (Provider, string) {
 	providerName := cfg.LLM.DefaultProvider
 	if providerOverride != "" {
 		providerName = providerOverride
 	}
-
 	prov := Provider(providerName)
+	if modelOverride != "" {
+		return prov, modelOverride
+	}
+	switch prov {
+	case ProviderOpenAI:
+		return prov, cfg.LLM.OpenAI.Model
+	case ProviderAnthropic:
+		return prov, cfg.LLM.Anthropic.Model
+	case ProviderGoogle:
+		return prov, cfg.LLM.Google.Model
+	}
+	return prov, ""
+}
+
+// NewClientFromConfig constructs a Client based on global config and optional
+// per-request provider/model overrides.
+func NewClientFromConfig(cfg *config.Config, providerOverride, modelOverride string) (Client, Provider, string, error) {
+	prov, model := ResolveModel(cfg, providerOverride, modelOverride)
 
 	switch prov {
 	case ProviderOpenAI:
 		openaiCfg := cfg.LLM.OpenAI
-		model := openaiCfg.Model
-		if modelOverride != "" {
-			model = modelOverride
-		}
 		if openaiCfg.APIKey == "" || model == "" {
 			return nil, prov, model, errors.New("openai llm provider is not fully configured")
 		}
@@ -105,10 +132,6 @@ func NewClientFromConfig(cfg *config.Config, providerOverride, modelOverride str
 		}, prov, model, nil
 	case ProviderAnthropic:
 		anthCfg := cfg.LLM.Anthropic
-		model := anthCfg.Model
-		if modelOverride != "" {
-			model = modelOverride
-		}
 		if anthCfg.APIKey == "" || model == "" {
 			return nil, prov, model, errors.New("anthropic llm provider is not fully configured")
 		}
@@ -119,10 +142,6 @@ func NewClientFromConfig(cfg *config.Config, providerOverride, modelOverride str
 		}, prov, model, nil
 	case ProviderGoogle:
 		googleCfg := cfg.LLM.Google
-		model := googleCfg.Model
-		if modelOverride != "" {
-			model = modelOverride
-		}
 		if googleCfg.APIKey == "" || model == "" {
 			return nil, prov, model, errors.New("google llm provider is not fully configured")
 		}
@@ -132,7 +151,7 @@ func NewClientFromConfig(cfg *config.Config, providerOverride, modelOverride str
 			http:   &http.Client{Timeout: 30 * time.Second},
 		}, prov, model, nil
 	default:
-		return nil, prov, "", fmt.Errorf("unsupported llm provider: %s", providerName)
+		return nil, prov, "", fmt.Errorf("unsupported llm provider: %s", prov)
 	}
 }
 
@@ -179,6 +198,10 @@ type openAIChatResponse struct {
 	Choices []struct {
 		Message openAIChatMessage `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+	} `json:"usage"`
 }
 
 // anthropicMessagesRequest & response are minimal shapes for Anthropic's Messages API.
@@ -201,6 +224,10 @@ type anthropicTextContent struct {
 
 type anthropicMessagesResponse struct {
 	Content []anthropicTextContent `json:"content"`
+	Usage   struct {
+		InputTokens  int64 `json:"input_tokens"`
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
 }
 
 // googleGenerateContentRequest & response are minimal shapes for Gemini's generateContent.
@@ -220,9 +247,15 @@ type googleGenerateContentResponse struct {
 	Candidates []struct {
 		Content googleContent `json:"content"`
 	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int64 `json:"promptTokenCount"`
+		CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
 }
 
 func (c *openAIClient) ExtractFields(ctx context.Context, req ExtractRequest) (ExtractResult, error) {
+	metrics.JobRuntimeFrom(ctx).AddLLMCall()
+
 	// Build a simple, JSON-focused prompt.
 	fieldJSON, _ := json.Marshal(req.Fields)
 	userContent := fmt.Sprintf("You are a JSON-only extractor. Given markdown content from URL %s and the following field definitions, extract a JSON object with exactly those keys. Fields: %s\n\nMarkdown:\n%s", req.URL, string(fieldJSON), req.Markdown)
@@ -286,11 +319,13 @@ func (c *openAIClient) ExtractFields(ctx context.Context, req ExtractRequest) (E
 		fields = map[string]any{"_raw": content}
 	}
 
-	return ExtractResult{Fields: fields}, nil
+	return ExtractResult{Fields: fields, Usage: Usage{InputTokens: parsed.Usage.PromptTokens, OutputTokens: parsed.Usage.CompletionTokens}}, nil
 }
 
 // ExtractFields for anthropicClient uses Anthropic's Messages API.
 func (c *anthropicClient) ExtractFields(ctx context.Context, req ExtractRequest) (ExtractResult, error) {
+	metrics.JobRuntimeFrom(ctx).AddLLMCall()
+
 	fieldJSON, _ := json.Marshal(req.Fields)
 	userContent := fmt.Sprintf("You are a JSON-only extractor. Given markdown content from URL %s and the following field definitions, extract a JSON object with exactly those keys. Fields: %s\n\nMarkdown:\n%s", req.URL, string(fieldJSON), req.Markdown)
 	if req.Prompt != "" {
@@ -353,11 +388,13 @@ func (c *anthropicClient) ExtractFields(ctx context.Context, req ExtractRequest)
 		fields = map[string]any{"_raw": content}
 	}
 
-	return ExtractResult{Fields: fields}, nil
+	return ExtractResult{Fields: fields, Usage: Usage{InputTokens: parsed.Usage.InputTokens, OutputTokens: parsed.Usage.OutputTokens}}, nil
 }
 
 // ExtractFields for googleClient uses Gemini's generateContent API.
 func (c *googleClient) ExtractFields(ctx context.Context, req ExtractRequest) (ExtractResult, error) {
+	metrics.JobRuntimeFrom(ctx).AddLLMCall()
+
 	fieldJSON, _ := json.Marshal(req.Fields)
 	userContent := fmt.Sprintf("You are a JSON-only extractor. Given markdown content from URL %s and the following field definitions, extract a JSON object with exactly those keys. Fields: %s\n\nMarkdown:\n%s", req.URL, string(fieldJSON), req.Markdown)
 	if req.Prompt != "" {
@@ -419,5 +456,5 @@ func (c *googleClient) ExtractFields(ctx context.Context, req ExtractRequest) (E
 		fields = map[string]any{"_raw": content}
 	}
 
-	return ExtractResult{Fields: fields}, nil
+	return ExtractResult{Fields: fields, Usage: Usage{InputTokens: parsed.UsageMetadata.PromptTokenCount, OutputTokens: parsed.UsageMetadata.CandidatesTokenCount}}, nil
 }
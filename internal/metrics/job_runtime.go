@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// JobRuntime accumulates resource usage and live progress for a single
+// job while it runs. The worker attaches one to the job context; scrapers
+// and LLM clients report into it via JobRuntimeFrom. All methods are safe
+// for concurrent use and are no-ops on a nil receiver, so code outside a
+// job can call them unconditionally.
+type JobRuntime struct {
+	start        time.Time
+	pages        atomic.Int64
+	pagesTotal   atomic.Int64
+	bytes        atomic.Int64
+	llmCalls     atomic.Int64
+	browserNanos atomic.Int64
+	currentURL   atomic.Pointer[string]
+}
+
+// JobRuntimeStats is the persisted summary of a job's resource usage.
+type JobRuntimeStats struct {
+	WallTimeMs      int64   `json:"wallTimeMs"`
+	Pages           int64   `json:"pages"`
+	PagesPerSecond  float64 `json:"pagesPerSecond"`
+	BytesDownloaded int64   `json:"bytesDownloaded"`
+	LLMCalls        int64   `json:"llmCalls"`
+	BrowserTimeMs   int64   `json:"browserTimeMs"`
+}
+
+// JobProgress is a point-in-time view of a running job, reported in
+// worker heartbeats.
+type JobProgress struct {
+	StartedAt time.Time
+	PagesDone int64
+	// PagesTotal is 0 when the job does not know its size up front.
+	PagesTotal int64
+	CurrentURL string
+}
+
+type jobRuntimeKey struct{}
+
+// NewJobRuntime starts measuring a job's wall time.
+func NewJobRuntime() *JobRuntime {
+	return &JobRuntime{start: time.Now()}
+}
+
+// WithJobRuntime returns a context that carries rt.
+func WithJobRuntime(ctx context.Context, rt *JobRuntime) context.Context {
+	return context.WithValue(ctx, jobRuntimeKey{}, rt)
+}
+
+// JobRuntimeFrom returns the JobRuntime attached to ctx, or nil.
+func JobRuntimeFrom(ctx context.Context) *JobRuntime {
+	rt, _ := ctx.Value(jobRuntimeKey{}).(*JobRuntime)
+	return rt
+}
+
+// AddPage records a fetched page and its downloaded size in bytes.
+func (r *JobRuntime) AddPage(bytes int64) {
+	if r == nil {
+		return
+	}
+	r.pages.Add(1)
+	r.bytes.Add(bytes)
+}
+
+// SetPagesTotal records how many pages the job expects to fetch.
+func (r *JobRuntime) SetPagesTotal(n int) {
+	if r == nil {
+		return
+	}
+	r.pagesTotal.Store(int64(n))
+}
+
+// SetCurrentURL records the URL the job is fetching now.
+func (r *JobRuntime) SetCurrentURL(u string) {
+	if r == nil {
+		return
+	}
+	r.currentURL.Store(&u)
+}
+
+// AddLLMCall records one LLM request.
+func (r *JobRuntime) AddLLMCall() {
+	if r == nil {
+		return
+	}
+	r.llmCalls.Add(1)
+}
+
+// AddBrowserTime records time spent driving a headless browser.
+func (r *JobRuntime) AddBrowserTime(d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.browserNanos.Add(int64(d))
+}
+
+// Stats returns the usage recorded so far.
+func (r *JobRuntime) Stats() JobRuntimeStats {
+	if r == nil {
+		return JobRuntimeStats{}
+	}
+	wall := time.Since(r.start)
+	s := JobRuntimeStats{
+		WallTimeMs:      wall.Milliseconds(),
+		Pages:           r.pages.Load(),
+		BytesDownloaded: r.bytes.Load(),
+		LLMCalls:        r.llmCalls.Load(),
+		BrowserTimeMs:   time.Duration(r.browserNanos.Load()).Milliseconds(),
+	}
+	if secs := wall.Seconds(); secs > 0 {
+		s.PagesPerSecond = float64(s.Pages) / secs
+	}
+	return s
+}
+
+// Progress returns the job's live progress counters.
+func (r *JobRuntime) Progress() JobProgress {
+	if r == nil {
+		return JobProgress{}
+	}
+	p := JobProgress{
+		StartedAt:  r.start,
+		PagesDone:  r.pages.Load(),
+		PagesTotal: r.pagesTotal.Load(),
+	}
+	if u := r.currentURL.Load(); u != nil {
+		p.CurrentURL = *u
+	}
+	return p
+}
@@ -5,79 +5,145 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
-// Simple Prometheus-style metrics for HTTP requests.
-// This is intentionally minimal and in-memory only.
-
-var (
-	mu             sync.RWMutex
-	requestsTotal  = make(map[reqKey]int64)
-	latencyMsSum   = make(map[latKey]int64)
-	latencyMsCount = make(map[latKey]int64)
-	llmExtracts    = make(map[llmKey]int64)
-
-	retentionJobsDeleted      = make(map[string]int64)
-	retentionDocumentsDeleted int64
-
-	searchRequestsTotal       = make(map[searchKey]int64)
-	searchResultsTotal        = make(map[string]int64)
-	searchScrapedResultsTotal = make(map[string]int64)
-
-	extractJobsTotal         = make(map[extractJobKey]int64)
-	extractResultsTotal      = make(map[extractResultKey]int64)
-	extractFailureCodesTotal = make(map[extractFailureCodeKey]int64)
+// Prometheus-style metrics kept in process. Every family is a counterVec
+// or histogramVec; labels that take values from requests or targets
+// (paths, hosts, models) are capped by a labelGuard so the series stay
+// bounded. Counters and histograms can be saved with Snapshot and loaded
+// with Restore so totals survive restarts.
+
+// Cardinality caps for labels whose values are not fixed by the code.
+// Values past a cap are reported as "other".
+const (
+	// MaxPathSeries caps distinct HTTP path labels. Paths are route
+	// templates such as "/v1/jobs/:id", so the cap only matters if a
+	// caller records raw paths.
+	MaxPathSeries = 300
+	// MaxHostSeries caps the hosts reported with their own per-host series.
+	MaxHostSeries = 200
+	// MaxModelSeries caps distinct LLM model labels.
+	MaxModelSeries = 50
+	// MaxTenantSeries caps distinct tenant labels on job queue metrics.
+	MaxTenantSeries = 100
 )
 
-type reqKey struct {
-	Method string
-	Path   string
-	Status int
-}
-
-type latKey struct {
-	Method string
-	Path   string
-}
-
-type llmKey struct {
-	Provider string
-	Model    string
-	Success  string
-}
-
-type searchKey struct {
-	Provider string
-	Scrape   string
-}
+var (
+	mu sync.RWMutex
+
+	// families lists every family in export order; counters and
+	// histograms list them by kind for Snapshot and Restore.
+	families   []interface{ write(*strings.Builder) }
+	counters   []*counterVec
+	histograms []*histogramVec
+
+	pathGuard   = newLabelGuard(MaxPathSeries)
+	hostGuard   = newLabelGuard(MaxHostSeries)
+	modelGuard  = newLabelGuard(MaxModelSeries)
+	tenantGuard = newLabelGuard(MaxTenantSeries)
+
+	requestsTotal = newCounter("raito_http_requests_total", "Total HTTP requests",
+		"method", "path", "status").guard("path", pathGuard)
+	latencyMsSum = newCounter("raito_http_request_duration_ms_sum", "Total request duration in milliseconds",
+		"method", "path").guard("path", pathGuard)
+	latencyMsCount = newCounter("raito_http_request_duration_ms_count", "Request count for latency metric",
+		"method", "path").guard("path", pathGuard)
+
+	llmExtracts = newCounter("raito_llm_extract_requests_total", "Total LLM extract requests",
+		"provider", "model", "success").guard("model", modelGuard)
+
+	searchRequestsTotal = newCounter("raito_search_requests_total", "Total search requests by provider and scrape mode",
+		"provider", "scrape")
+	searchResultsTotal = newCounter("raito_search_results_total", "Total search results returned by provider",
+		"provider")
+	searchScrapedResultsTotal = newCounter("raito_search_scraped_results_total", "Total search results with scraped documents",
+		"provider")
+	searchScrapeDurations = newHistogram("raito_search_scrape_duration_seconds", "Time to scrape one search result in seconds",
+		[]float64{0.25, 0.5, 1, 2.5, 5, 10, 30, 60}, "provider", "outcome")
+
+	extractJobsTotal = newCounter("raito_extract_jobs_total", "Total extract jobs by provider, model, and status",
+		"provider", "model", "status").guard("model", modelGuard)
+	extractResultsTotal = newCounter("raito_extract_results_total", "Total extract results by provider and outcome",
+		"provider", "outcome")
+	extractFailureCodesTotal = newCounter("raito_extract_failures_by_code_total", "Total extract failures by provider and error code",
+		"provider", "code")
+
+	// jobRuntimeHistograms are the per-job runtime histograms, in the
+	// order of the values RecordJobRuntime observes.
+	jobRuntimeHistograms = []*histogramVec{
+		newHistogram("raito_job_duration_seconds", "Job wall time in seconds",
+			[]float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600}, "job_type"),
+		newHistogram("raito_job_pages", "Pages fetched per job",
+			[]float64{1, 10, 50, 100, 500, 1000, 5000}, "job_type"),
+		newHistogram("raito_job_bytes_downloaded", "Bytes downloaded per job",
+			[]float64{1e4, 1e5, 1e6, 1e7, 1e8, 1e9}, "job_type"),
+		newHistogram("raito_job_llm_calls", "LLM calls per job",
+			[]float64{0, 1, 5, 10, 50, 100, 500}, "job_type"),
+		newHistogram("raito_job_browser_seconds", "Headless browser time per job in seconds",
+			[]float64{0, 1, 5, 30, 60, 300, 900}, "job_type"),
+	}
 
-type extractJobKey struct {
-	Provider string
-	Model    string
-	Status   string
-}
+	jobQueueWait = newHistogram("raito_job_queue_wait_seconds", "Time from job creation until a worker starts it, in seconds",
+		jobQueueBuckets, "job_type", "tenant").guard("tenant", tenantGuard)
+	jobExecution = newHistogram("raito_job_execution_seconds", "Time from a worker starting a job until its final status, in seconds",
+		jobQueueBuckets, "job_type", "tenant", "status").guard("tenant", tenantGuard)
+	jobsPending = newGauge("raito_jobs_pending", "Jobs waiting in the queue by job type, across all workers",
+		"job_type")
+	jobsRunning = newGauge("raito_jobs_running", "Jobs running in this process by job type",
+		"job_type")
+
+	retentionJobsDeleted = newCounter("raito_retention_jobs_deleted_total", "Total jobs deleted by TTL",
+		"job_type")
+	retentionDocumentsDeleted = newCounter("raito_retention_documents_deleted_total", "Total documents deleted by TTL")
+
+	dnsLookupsTotal = newCounter("raito_dns_lookups_total", "Total DNS lookups for outbound connections by result",
+		"result")
+
+	hostRequestsTotal = newCounter("raito_host_requests_total", "Total scrape requests by target host and outcome",
+		"host", "outcome").guard("host", hostGuard)
+	hostDurations = newHistogram("raito_host_request_duration_seconds", "Scrape request latency by target host in seconds",
+		[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10}, "host").guard("host", hostGuard)
+	hostRobotsBlocked = newCounter("raito_host_robots_blocked_total", "Total URLs skipped because robots.txt disallows them, by host",
+		"host").guard("host", hostGuard)
+
+	dbTableStats           []DBTableStat
+	dbIndexStats           []DBIndexStat
+	dbMaintenanceLastRun   time.Time
+	dbMaintenanceDurationS float64
+)
 
-type extractResultKey struct {
-	Provider string
-	Outcome  string
+// jobQueueBuckets are the upper bounds, in seconds, of the job queue
+// wait and execution histograms.
+var jobQueueBuckets = []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900, 1800, 3600, 21600}
+
+// DBTableStat is a table's size and tuple counts as last reported by
+// database maintenance.
+type DBTableStat struct {
+	Table      string
+	LiveTuples int64
+	DeadTuples int64
+	TotalBytes int64
 }
 
-type extractFailureCodeKey struct {
-	Provider string
-	Code     string
+// DBIndexStat is an index's size and scan count as last reported by
+// database maintenance.
+type DBIndexStat struct {
+	Table     string
+	Index     string
+	Scans     int64
+	SizeBytes int64
 }
 
-// RecordRequest increments request counter and records latency.
+// RecordRequest increments request counter and records latency. path
+// should be the route template (e.g. "/v1/jobs/:id"), not the raw path.
 func RecordRequest(method, path string, status int, latencyMs int64) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	rk := reqKey{Method: method, Path: path, Status: status}
-	requestsTotal[rk]++
-
-	lk := latKey{Method: method, Path: path}
-	latencyMsSum[lk] += latencyMs
-	latencyMsCount[lk]++
+	requestsTotal.add(1, method, path, fmt.Sprint(status))
+	latencyMsSum.add(latencyMs, method, path)
+	latencyMsCount.add(1, method, path)
 }
 
 // RecordLLMExtract increments LLM extract counters.
@@ -89,8 +155,7 @@ func RecordLLMExtract(provider, model string, success bool) {
 	if success {
 		s = "true"
 	}
-	key := llmKey{Provider: provider, Model: model, Success: s}
-	llmExtracts[key]++
+	llmExtracts.add(1, provider, model, s)
 }
 
 // RecordRetentionJobs increments the counter of jobs deleted by TTL for
@@ -101,7 +166,7 @@ func RecordRetentionJobs(jobType string, deleted int64) {
 	}
 	mu.Lock()
 	defer mu.Unlock()
-	retentionJobsDeleted[jobType] += deleted
+	retentionJobsDeleted.add(deleted, jobType)
 }
 
 // RecordRetentionDocuments increments the counter of documents deleted
@@ -112,7 +177,7 @@ func RecordRetentionDocuments(deleted int64) {
 	}
 	mu.Lock()
 	defer mu.Unlock()
-	retentionDocumentsDeleted += deleted
+	retentionDocumentsDeleted.add(deleted)
 }
 
 // RecordSearch records basic metrics for search requests, including
@@ -126,26 +191,35 @@ func RecordSearch(provider string, withScrape bool, results int, scraped int) {
 	if withScrape {
 		scrapeFlag = "true"
 	}
-
-	key := searchKey{Provider: provider, Scrape: scrapeFlag}
-	searchRequestsTotal[key]++
+	searchRequestsTotal.add(1, provider, scrapeFlag)
 
 	if results > 0 {
-		searchResultsTotal[provider] += int64(results)
+		searchResultsTotal.add(int64(results), provider)
 	}
 	if scraped > 0 {
-		searchScrapedResultsTotal[provider] += int64(scraped)
+		searchScrapedResultsTotal.add(int64(scraped), provider)
 	}
 }
 
+// RecordSearchScrape records how long scraping one search result took,
+// by provider and outcome (success/error).
+func RecordSearchScrape(provider string, success bool, durationMs int64) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	outcome := "error"
+	if success {
+		outcome = "success"
+	}
+	searchScrapeDurations.observe(float64(durationMs)/1000, provider, outcome)
+}
+
 // RecordExtractJob increments counters for extract jobs keyed by
 // provider, model, and status (e.g., completed/failed).
 func RecordExtractJob(provider, model, status string) {
 	mu.Lock()
 	defer mu.Unlock()
-
-	key := extractJobKey{Provider: provider, Model: model, Status: status}
-	extractJobsTotal[key]++
+	extractJobsTotal.add(1, provider, model, status)
 }
 
 // RecordExtractResults increments counters for extracted results by
@@ -158,12 +232,10 @@ func RecordExtractResults(provider string, success, failed int) {
 	defer mu.Unlock()
 
 	if success > 0 {
-		key := extractResultKey{Provider: provider, Outcome: "success"}
-		extractResultsTotal[key] += int64(success)
+		extractResultsTotal.add(int64(success), provider, "success")
 	}
 	if failed > 0 {
-		key := extractResultKey{Provider: provider, Outcome: "failed"}
-		extractResultsTotal[key] += int64(failed)
+		extractResultsTotal.add(int64(failed), provider, "failed")
 	}
 }
 
@@ -175,220 +247,163 @@ func RecordExtractFailureCode(provider, code string, count int) {
 	}
 	mu.Lock()
 	defer mu.Unlock()
-
-	key := extractFailureCodeKey{Provider: provider, Code: code}
-	extractFailureCodesTotal[key] += int64(count)
+	extractFailureCodesTotal.add(int64(count), provider, code)
 }
 
-// Export returns Prometheus-style metrics text.
-func Export() string {
-	mu.RLock()
-	defer mu.RUnlock()
-
-	var b strings.Builder
-
-	b.WriteString("# HELP raito_http_requests_total Total HTTP requests\n")
-	b.WriteString("# TYPE raito_http_requests_total counter\n")
-
-	// Sort keys for stable output
-	var reqKeys []reqKey
-	for k := range requestsTotal {
-		reqKeys = append(reqKeys, k)
-	}
-	sort.Slice(reqKeys, func(i, j int) bool {
-		if reqKeys[i].Method != reqKeys[j].Method {
-			return reqKeys[i].Method < reqKeys[j].Method
-		}
-		if reqKeys[i].Path != reqKeys[j].Path {
-			return reqKeys[i].Path < reqKeys[j].Path
-		}
-		return reqKeys[i].Status < reqKeys[j].Status
-	})
+// RecordJobRuntime observes a finished job's resource usage in the
+// per-job-type runtime histograms.
+func RecordJobRuntime(jobType string, s JobRuntimeStats) {
+	mu.Lock()
+	defer mu.Unlock()
 
-	for _, k := range reqKeys {
-		v := requestsTotal[k]
-		fmt.Fprintf(&b, "raito_http_requests_total{method=\"%s\",path=\"%s\",status=\"%d\"} %d\n",
-			k.Method, k.Path, k.Status, v)
+	values := []float64{
+		float64(s.WallTimeMs) / 1000,
+		float64(s.Pages),
+		float64(s.BytesDownloaded),
+		float64(s.LLMCalls),
+		float64(s.BrowserTimeMs) / 1000,
 	}
-
-	b.WriteString("# HELP raito_http_request_duration_ms_sum Total request duration in milliseconds\n")
-	b.WriteString("# TYPE raito_http_request_duration_ms_sum counter\n")
-	b.WriteString("# HELP raito_http_request_duration_ms_count Request count for latency metric\n")
-	b.WriteString("# TYPE raito_http_request_duration_ms_count counter\n")
-
-	var latKeys []latKey
-	for k := range latencyMsSum {
-		latKeys = append(latKeys, k)
+	for i, h := range jobRuntimeHistograms {
+		h.observe(values[i], jobType)
 	}
-	sort.Slice(latKeys, func(i, j int) bool {
-		if latKeys[i].Method != latKeys[j].Method {
-			return latKeys[i].Method < latKeys[j].Method
-		}
-		return latKeys[i].Path < latKeys[j].Path
-	})
-
-	for _, k := range latKeys {
-		sum := latencyMsSum[k]
-		cnt := latencyMsCount[k]
-		fmt.Fprintf(&b, "raito_http_request_duration_ms_sum{method=\"%s\",path=\"%s\"} %d\n",
-			k.Method, k.Path, sum)
-		fmt.Fprintf(&b, "raito_http_request_duration_ms_count{method=\"%s\",path=\"%s\"} %d\n",
-			k.Method, k.Path, cnt)
-	}
-
-	// LLM extract metrics
-	b.WriteString("# HELP raito_llm_extract_requests_total Total LLM extract requests\n")
-	b.WriteString("# TYPE raito_llm_extract_requests_total counter\n")
+}
 
-	var llmKeys []llmKey
-	for k := range llmExtracts {
-		llmKeys = append(llmKeys, k)
+// jobTenantLabel is the tenant label of a job; jobs without a tenant
+// are reported as "none".
+func jobTenantLabel(tenant string) string {
+	if tenant == "" {
+		return "none"
 	}
-	sort.Slice(llmKeys, func(i, j int) bool {
-		if llmKeys[i].Provider != llmKeys[j].Provider {
-			return llmKeys[i].Provider < llmKeys[j].Provider
-		}
-		if llmKeys[i].Model != llmKeys[j].Model {
-			return llmKeys[i].Model < llmKeys[j].Model
-		}
-		return llmKeys[i].Success < llmKeys[j].Success
-	})
+	return tenant
+}
 
-	for _, k := range llmKeys {
-		v := llmExtracts[k]
-		fmt.Fprintf(&b, "raito_llm_extract_requests_total{provider=\"%s\",model=\"%s\",success=\"%s\"} %d\n",
-			k.Provider, k.Model, k.Success, v)
-	}
+// RecordJobQueueWait observes how long a job waited between creation and
+// a worker starting it.
+func RecordJobQueueWait(jobType, tenant string, wait time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	jobQueueWait.observe(max(wait.Seconds(), 0), jobType, jobTenantLabel(tenant))
+}
 
-	// Search metrics
-	b.WriteString("# HELP raito_search_requests_total Total search requests by provider and scrape mode\n")
-	b.WriteString("# TYPE raito_search_requests_total counter\n")
+// RecordJobExecution observes how long a job ran, from a worker starting
+// it until its final status (e.g. completed or failed). The count of the
+// histogram by status is the job throughput.
+func RecordJobExecution(jobType, tenant, status string, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	jobExecution.observe(d.Seconds(), jobType, jobTenantLabel(tenant), status)
+}
 
-	var searchKeys []searchKey
-	for k := range searchRequestsTotal {
-		searchKeys = append(searchKeys, k)
+// SetJobsPending replaces the pending job counts by job type; types not
+// in counts are reported as 0.
+func SetJobsPending(counts map[string]int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	jobsPending.zero()
+	for jobType, n := range counts {
+		jobsPending.set(n, jobType)
 	}
-	sort.Slice(searchKeys, func(i, j int) bool {
-		if searchKeys[i].Provider != searchKeys[j].Provider {
-			return searchKeys[i].Provider < searchKeys[j].Provider
-		}
-		return searchKeys[i].Scrape < searchKeys[j].Scrape
-	})
+}
 
-	for _, k := range searchKeys {
-		v := searchRequestsTotal[k]
-		fmt.Fprintf(&b, "raito_search_requests_total{provider=\"%s\",scrape=\"%s\"} %d\n",
-			k.Provider, k.Scrape, v)
-	}
+// AddJobsRunning adds delta to the running job count of a job type in
+// this process.
+func AddJobsRunning(jobType string, delta int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	jobsRunning.add(delta, jobType)
+}
 
-	b.WriteString("# HELP raito_search_results_total Total search results returned by provider\n")
-	b.WriteString("# TYPE raito_search_results_total counter\n")
+// RecordDNSLookup increments the DNS lookup counter for a result: "hit"
+// when served from the cache, "miss" when resolved, or "error".
+func RecordDNSLookup(result string) {
+	mu.Lock()
+	defer mu.Unlock()
+	dnsLookupsTotal.add(1, result)
+}
 
-	var searchProviders []string
-	for p := range searchResultsTotal {
-		searchProviders = append(searchProviders, p)
-	}
-	sort.Strings(searchProviders)
-	for _, p := range searchProviders {
-		v := searchResultsTotal[p]
-		fmt.Fprintf(&b, "raito_search_results_total{provider=\"%s\"} %d\n", p, v)
-	}
+// RecordHostRequest records a scrape of host with its outcome: a status
+// class such as "2xx", or an error kind such as "tls" or "timeout".
+func RecordHostRequest(host, outcome string, latency time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
 
-	b.WriteString("# HELP raito_search_scraped_results_total Total search results with scraped documents\n")
-	b.WriteString("# TYPE raito_search_scraped_results_total counter\n")
+	hostRequestsTotal.add(1, host, outcome)
+	hostDurations.observe(latency.Seconds(), host)
+}
 
-	var scrapedProviders []string
-	for p := range searchScrapedResultsTotal {
-		scrapedProviders = append(scrapedProviders, p)
-	}
-	sort.Strings(scrapedProviders)
-	for _, p := range scrapedProviders {
-		v := searchScrapedResultsTotal[p]
-		fmt.Fprintf(&b, "raito_search_scraped_results_total{provider=\"%s\"} %d\n", p, v)
-	}
+// RecordHostRobotsBlock records a URL on host skipped because robots.txt
+// disallows it.
+func RecordHostRobotsBlock(host string) {
+	mu.Lock()
+	defer mu.Unlock()
+	hostRobotsBlocked.add(1, host)
+}
 
-	// Extract metrics
-	b.WriteString("# HELP raito_extract_jobs_total Total extract jobs by provider, model, and status\n")
-	b.WriteString("# TYPE raito_extract_jobs_total counter\n")
+// RecordDBMaintenance replaces the reported table and index statistics
+// with those from a maintenance run that finished at the given time.
+func RecordDBMaintenance(at time.Time, duration time.Duration, tables []DBTableStat, indexes []DBIndexStat) {
+	mu.Lock()
+	defer mu.Unlock()
 
-	var extractJobKeys []extractJobKey
-	for k := range extractJobsTotal {
-		extractJobKeys = append(extractJobKeys, k)
-	}
-	sort.Slice(extractJobKeys, func(i, j int) bool {
-		if extractJobKeys[i].Provider != extractJobKeys[j].Provider {
-			return extractJobKeys[i].Provider < extractJobKeys[j].Provider
-		}
-		if extractJobKeys[i].Model != extractJobKeys[j].Model {
-			return extractJobKeys[i].Model < extractJobKeys[j].Model
+	dbTableStats = append([]DBTableStat(nil), tables...)
+	dbIndexStats = append([]DBIndexStat(nil), indexes...)
+	sort.Slice(dbTableStats, func(i, j int) bool { return dbTableStats[i].Table < dbTableStats[j].Table })
+	sort.Slice(dbIndexStats, func(i, j int) bool {
+		if dbIndexStats[i].Table != dbIndexStats[j].Table {
+			return dbIndexStats[i].Table < dbIndexStats[j].Table
 		}
-		return extractJobKeys[i].Status < extractJobKeys[j].Status
+		return dbIndexStats[i].Index < dbIndexStats[j].Index
 	})
+	dbMaintenanceLastRun = at
+	dbMaintenanceDurationS = duration.Seconds()
+}
 
-	for _, k := range extractJobKeys {
-		v := extractJobsTotal[k]
-		fmt.Fprintf(&b, "raito_extract_jobs_total{provider=\"%s\",model=\"%s\",status=\"%s\"} %d\n",
-			k.Provider, k.Model, k.Status, v)
-	}
-
-	b.WriteString("# HELP raito_extract_results_total Total extract results by provider and outcome\n")
-	b.WriteString("# TYPE raito_extract_results_total counter\n")
-
-	var extractResultKeys []extractResultKey
-	for k := range extractResultsTotal {
-		extractResultKeys = append(extractResultKeys, k)
-	}
-	sort.Slice(extractResultKeys, func(i, j int) bool {
-		if extractResultKeys[i].Provider != extractResultKeys[j].Provider {
-			return extractResultKeys[i].Provider < extractResultKeys[j].Provider
-		}
-		return extractResultKeys[i].Outcome < extractResultKeys[j].Outcome
-	})
+// Export returns Prometheus-style metrics text.
+func Export() string {
+	mu.RLock()
+	defer mu.RUnlock()
 
-	for _, k := range extractResultKeys {
-		v := extractResultsTotal[k]
-		fmt.Fprintf(&b, "raito_extract_results_total{provider=\"%s\",outcome=\"%s\"} %d\n",
-			k.Provider, k.Outcome, v)
+	var b strings.Builder
+	for _, f := range families {
+		f.write(&b)
 	}
 
-	b.WriteString("# HELP raito_extract_failures_by_code_total Total extract failures by provider and error code\n")
-	b.WriteString("# TYPE raito_extract_failures_by_code_total counter\n")
-
-	var extractFailureCodeKeys []extractFailureCodeKey
-	for k := range extractFailureCodesTotal {
-		extractFailureCodeKeys = append(extractFailureCodeKeys, k)
-	}
-	sort.Slice(extractFailureCodeKeys, func(i, j int) bool {
-		if extractFailureCodeKeys[i].Provider != extractFailureCodeKeys[j].Provider {
-			return extractFailureCodeKeys[i].Provider < extractFailureCodeKeys[j].Provider
+	// Database maintenance metrics, only present once a run has finished
+	// in this process.
+	if !dbMaintenanceLastRun.IsZero() {
+		b.WriteString("# HELP raito_db_maintenance_last_run_timestamp_seconds Unix time of the last database maintenance run\n")
+		b.WriteString("# TYPE raito_db_maintenance_last_run_timestamp_seconds gauge\n")
+		fmt.Fprintf(&b, "raito_db_maintenance_last_run_timestamp_seconds %d\n", dbMaintenanceLastRun.Unix())
+		b.WriteString("# HELP raito_db_maintenance_duration_seconds Duration of the last database maintenance run\n")
+		b.WriteString("# TYPE raito_db_maintenance_duration_seconds gauge\n")
+		fmt.Fprintf(&b, "raito_db_maintenance_duration_seconds %g\n", dbMaintenanceDurationS)
+
+		b.WriteString("# HELP raito_db_table_live_tuples Estimated live rows per table\n")
+		b.WriteString("# TYPE raito_db_table_live_tuples gauge\n")
+		for _, t := range dbTableStats {
+			fmt.Fprintf(&b, "raito_db_table_live_tuples{table=\"%s\"} %d\n", t.Table, t.LiveTuples)
+		}
+		b.WriteString("# HELP raito_db_table_dead_tuples Estimated dead rows per table\n")
+		b.WriteString("# TYPE raito_db_table_dead_tuples gauge\n")
+		for _, t := range dbTableStats {
+			fmt.Fprintf(&b, "raito_db_table_dead_tuples{table=\"%s\"} %d\n", t.Table, t.DeadTuples)
+		}
+		b.WriteString("# HELP raito_db_table_size_bytes Table size including indexes and TOAST\n")
+		b.WriteString("# TYPE raito_db_table_size_bytes gauge\n")
+		for _, t := range dbTableStats {
+			fmt.Fprintf(&b, "raito_db_table_size_bytes{table=\"%s\"} %d\n", t.Table, t.TotalBytes)
+		}
+		b.WriteString("# HELP raito_db_index_scans Index scans since statistics were reset\n")
+		b.WriteString("# TYPE raito_db_index_scans gauge\n")
+		for _, ix := range dbIndexStats {
+			fmt.Fprintf(&b, "raito_db_index_scans{table=\"%s\",index=\"%s\"} %d\n", ix.Table, ix.Index, ix.Scans)
+		}
+		b.WriteString("# HELP raito_db_index_size_bytes Index size\n")
+		b.WriteString("# TYPE raito_db_index_size_bytes gauge\n")
+		for _, ix := range dbIndexStats {
+			fmt.Fprintf(&b, "raito_db_index_size_bytes{table=\"%s\",index=\"%s\"} %d\n", ix.Table, ix.Index, ix.SizeBytes)
 		}
-		return extractFailureCodeKeys[i].Code < extractFailureCodeKeys[j].Code
-	})
-
-	for _, k := range extractFailureCodeKeys {
-		v := extractFailureCodesTotal[k]
-		fmt.Fprintf(&b, "raito_extract_failures_by_code_total{provider=\"%s\",code=\"%s\"} %d\n",
-			k.Provider, k.Code, v)
-	}
-
-	// Retention metrics
-	b.WriteString("# HELP raito_retention_jobs_deleted_total Total jobs deleted by TTL\n")
-	b.WriteString("# TYPE raito_retention_jobs_deleted_total counter\n")
-
-	// Sort job types for stable output
-	var jobTypes []string
-	for t := range retentionJobsDeleted {
-		jobTypes = append(jobTypes, t)
-	}
-	sort.Strings(jobTypes)
-	for _, t := range jobTypes {
-		v := retentionJobsDeleted[t]
-		fmt.Fprintf(&b, "raito_retention_jobs_deleted_total{job_type=\"%s\"} %d\n", t, v)
 	}
 
-	b.WriteString("# HELP raito_retention_documents_deleted_total Total documents deleted by TTL\n")
-	b.WriteString("# TYPE raito_retention_documents_deleted_total counter\n")
-	fmt.Fprintf(&b, "raito_retention_documents_deleted_total %d\n", retentionDocumentsDeleted)
-
 	return b.String()
 }
@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRecordRequestAndExport(t *testing.T) {
@@ -56,3 +59,178 @@ func TestRecordExtractMetrics(t *testing.T) {
 		t.Fatalf("expected extract_failures_by_code_total for openai/EXTRACT_FAILED, got:\n%s", out)
 	}
 }
+
+func TestJobRuntimeAndHistograms(t *testing.T) {
+	rt := NewJobRuntime()
+	ctx := WithJobRuntime(context.Background(), rt)
+	JobRuntimeFrom(ctx).AddPage(2048)
+	JobRuntimeFrom(ctx).AddPage(1024)
+	JobRuntimeFrom(ctx).AddLLMCall()
+	JobRuntimeFrom(ctx).AddBrowserTime(1500 * time.Millisecond)
+
+	// Calls outside a job context are no-ops.
+	JobRuntimeFrom(context.Background()).AddPage(1)
+
+	JobRuntimeFrom(ctx).SetPagesTotal(5)
+	JobRuntimeFrom(ctx).SetCurrentURL("https://example.com/b")
+	if p := rt.Progress(); p.PagesDone != 2 || p.PagesTotal != 5 || p.CurrentURL != "https://example.com/b" {
+		t.Fatalf("unexpected progress: %#v", p)
+	}
+
+	stats := rt.Stats()
+	if stats.Pages != 2 || stats.BytesDownloaded != 3072 || stats.LLMCalls != 1 || stats.BrowserTimeMs != 1500 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+
+	RecordJobRuntime("crawl", stats)
+	out := Export()
+	if !strings.Contains(out, "# TYPE raito_job_pages histogram") {
+		t.Fatalf("expected raito_job_pages histogram, got:\n%s", out)
+	}
+	if !strings.Contains(out, "raito_job_pages_bucket{job_type=\"crawl\",le=\"10\"} 1") {
+		t.Fatalf("expected crawl pages bucket le=10, got:\n%s", out)
+	}
+	if !strings.Contains(out, "raito_job_bytes_downloaded_sum{job_type=\"crawl\"} 3072") {
+		t.Fatalf("expected bytes downloaded sum, got:\n%s", out)
+	}
+}
+
+func TestRecordDBMaintenance(t *testing.T) {
+	RecordDBMaintenance(time.Unix(1700000000, 0), 2*time.Second,
+		[]DBTableStat{{Table: "jobs", LiveTuples: 10, DeadTuples: 2, TotalBytes: 8192}},
+		[]DBIndexStat{{Table: "jobs", Index: "jobs_pkey", Scans: 7, SizeBytes: 16384}},
+	)
+
+	out := Export()
+	for _, want := range []string{
+		"raito_db_maintenance_last_run_timestamp_seconds 1700000000",
+		"raito_db_table_dead_tuples{table=\"jobs\"} 2",
+		"raito_db_index_scans{table=\"jobs\",index=\"jobs_pkey\"} 7",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in export, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRecordHostMetrics(t *testing.T) {
+	RecordHostRequest("hosttest.example", "2xx", 80*time.Millisecond)
+	RecordHostRequest("hosttest.example", "tls", 3*time.Second)
+	RecordHostRobotsBlock("hosttest.example")
+
+	out := Export()
+	for _, want := range []string{
+		"raito_host_requests_total{host=\"hosttest.example\",outcome=\"2xx\"} 1",
+		"raito_host_requests_total{host=\"hosttest.example\",outcome=\"tls\"} 1",
+		"raito_host_request_duration_seconds_bucket{host=\"hosttest.example\",le=\"0.1\"} 1",
+		"raito_host_request_duration_seconds_count{host=\"hosttest.example\"} 2",
+		"raito_host_robots_blocked_total{host=\"hosttest.example\"} 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in export, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRecordHostRequest_FoldsHostsPastCap(t *testing.T) {
+	for i := 0; i <= MaxHostSeries; i++ {
+		RecordHostRequest(fmt.Sprintf("cap%d.example", i), "2xx", time.Millisecond)
+	}
+	out := Export()
+	if !strings.Contains(out, "raito_host_requests_total{host=\"other\",outcome=\"2xx\"}") {
+		t.Fatalf("expected hosts past the cap to be reported as other, got:\n%s", out)
+	}
+	if strings.Contains(out, fmt.Sprintf("host=\"cap%d.example\"", MaxHostSeries)) {
+		t.Fatalf("expected no series for a host past the cap")
+	}
+}
+
+func TestRecordLLMExtract_FoldsModelsPastCap(t *testing.T) {
+	for i := 0; i <= MaxModelSeries; i++ {
+		RecordLLMExtract("capprovider", fmt.Sprintf("model-%d", i), true)
+	}
+	out := Export()
+	if !strings.Contains(out, "raito_llm_extract_requests_total{provider=\"capprovider\",model=\"other\",success=\"true\"}") {
+		t.Fatalf("expected models past the cap to be reported as other, got:\n%s", out)
+	}
+	if strings.Contains(out, fmt.Sprintf("model=\"model-%d\"", MaxModelSeries)) {
+		t.Fatalf("expected no series for a model past the cap")
+	}
+}
+
+func TestExport_EscapesLabelValues(t *testing.T) {
+	RecordDNSLookup("we\"ird\\result")
+
+	out := Export()
+	if !strings.Contains(out, `raito_dns_lookups_total{result="we\"ird\\result"} 1`) {
+		t.Fatalf("expected an escaped label value, got:\n%s", out)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	RecordDNSLookup("snapshot-test")
+	RecordDNSLookup("snapshot-test")
+	RecordSearchScrape("snapshot-provider", true, 400)
+
+	data, err := Snapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	// Restoring into a process that already counted the same series adds
+	// to it, as after a restart that saw traffic before the restore.
+	if err := Restore(data); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	out := Export()
+	for _, want := range []string{
+		"raito_dns_lookups_total{result=\"snapshot-test\"} 4",
+		"raito_search_scrape_duration_seconds_bucket{provider=\"snapshot-provider\",outcome=\"success\",le=\"0.5\"} 2",
+		"raito_search_scrape_duration_seconds_count{provider=\"snapshot-provider\",outcome=\"success\"} 2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in export, got:\n%s", want, out)
+		}
+	}
+
+	stale := `{"counters":[{"name":"raito_removed_total","labels":[],"value":3},{"name":"raito_dns_lookups_total","labels":["a","b"],"value":3}],
+		"histograms":[{"name":"raito_host_request_duration_seconds","labels":["old.example"],"buckets":[1],"counts":[1],"sum":1,"count":1}]}`
+	if err := Restore([]byte(stale)); err != nil {
+		t.Fatalf("restore stale snapshot: %v", err)
+	}
+	if out := Export(); strings.Contains(out, "raito_removed_total") || strings.Contains(out, "old.example") {
+		t.Fatalf("expected unknown or mismatched series to be skipped, got:\n%s", out)
+	}
+}
+
+func TestJobQueueMetrics(t *testing.T) {
+	RecordJobQueueWait("queuetest", "", 3*time.Second)
+	RecordJobExecution("queuetest", "t1", "completed", 20*time.Second)
+	SetJobsPending(map[string]int64{"queuetest": 4, "queuetest2": 1})
+	SetJobsPending(map[string]int64{"queuetest": 2})
+	AddJobsRunning("queuetest", 1)
+	AddJobsRunning("queuetest", 1)
+	AddJobsRunning("queuetest", -1)
+
+	out := Export()
+	for _, want := range []string{
+		"raito_job_queue_wait_seconds_bucket{job_type=\"queuetest\",tenant=\"none\",le=\"5\"} 1",
+		"raito_job_execution_seconds_count{job_type=\"queuetest\",tenant=\"t1\",status=\"completed\"} 1",
+		"# TYPE raito_jobs_pending gauge",
+		"raito_jobs_pending{job_type=\"queuetest\"} 2",
+		"raito_jobs_pending{job_type=\"queuetest2\"} 0",
+		"raito_jobs_running{job_type=\"queuetest\"} 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in export, got:\n%s", want, out)
+		}
+	}
+
+	data, err := Snapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if strings.Contains(string(data), "raito_jobs_pending") {
+		t.Fatalf("expected gauges to be left out of snapshots")
+	}
+}
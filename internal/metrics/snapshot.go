@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// snapshot is the saved form of every counter and histogram series.
+// Gauges are not saved; they are reported again by their sources.
+type snapshot struct {
+	Counters   []counterSample   `json:"counters"`
+	Histograms []histogramSample `json:"histograms"`
+}
+
+type counterSample struct {
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+	Value  int64    `json:"value"`
+}
+
+type histogramSample struct {
+	Name    string    `json:"name"`
+	Labels  []string  `json:"labels"`
+	Buckets []float64 `json:"buckets"`
+	Counts  []int64   `json:"counts"`
+	Sum     float64   `json:"sum"`
+	Count   int64     `json:"count"`
+}
+
+// Snapshot returns the current counter and histogram values as JSON.
+func Snapshot() ([]byte, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var s snapshot
+	for _, c := range counters {
+		for _, k := range c.keys() {
+			s.Counters = append(s.Counters, counterSample{Name: c.name, Labels: splitKey(k, len(c.labels)), Value: *c.series[k]})
+		}
+	}
+	for _, h := range histograms {
+		for _, k := range h.keys() {
+			v := h.series[k]
+			s.Histograms = append(s.Histograms, histogramSample{
+				Name:    h.name,
+				Labels:  splitKey(k, len(h.labels)),
+				Buckets: v.buckets,
+				Counts:  v.counts,
+				Sum:     v.sum,
+				Count:   v.count,
+			})
+		}
+	}
+	return json.Marshal(s)
+}
+
+// Restore adds the values in a Snapshot to the current ones. Series of
+// unknown metrics, with a different label set, or with different
+// histogram buckets are skipped, so snapshots from older versions load
+// cleanly.
+func Restore(data []byte) error {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	byName := make(map[string]*counterVec, len(counters))
+	for _, c := range counters {
+		byName[c.name] = c
+	}
+	for _, sample := range s.Counters {
+		c, ok := byName[sample.Name]
+		if !ok || len(sample.Labels) != len(c.labels) || sample.Value <= 0 {
+			continue
+		}
+		c.add(sample.Value, sample.Labels...)
+	}
+
+	histByName := make(map[string]*histogramVec, len(histograms))
+	for _, h := range histograms {
+		histByName[h.name] = h
+	}
+	for _, sample := range s.Histograms {
+		h, ok := histByName[sample.Name]
+		if !ok || len(sample.Labels) != len(h.labels) || !slices.Equal(sample.Buckets, h.buckets) || len(sample.Counts) != len(h.buckets) {
+			continue
+		}
+		v := h.with(sample.Labels...)
+		for i, n := range sample.Counts {
+			v.counts[i] += n
+		}
+		v.sum += sample.Sum
+		v.count += sample.Count
+	}
+	return nil
+}
+
+// splitKey returns the label values of a series key.
+func splitKey(key string, labels int) []string {
+	if labels == 0 {
+		return []string{}
+	}
+	return strings.Split(key, labelSep)
+}
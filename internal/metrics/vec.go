@@ -0,0 +1,299 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// otherLabel is the label value that series past a cardinality cap are
+// folded into.
+const otherLabel = "other"
+
+// MaxSeriesPerMetric caps the series of any one metric. Once a metric has
+// this many, further label combinations are counted in a single series
+// whose labels are all "other".
+const MaxSeriesPerMetric = 2000
+
+// labelSep joins label values into series keys. It sorts before every
+// printable byte, so sorting keys sorts series label by label.
+const labelSep = "\x00"
+
+// labelGuard caps the distinct values of one label; values past max are
+// reported as "other". Metrics sharing a label share its guard, so their
+// series line up.
+type labelGuard struct {
+	max  int
+	seen map[string]struct{}
+}
+
+func newLabelGuard(max int) *labelGuard {
+	return &labelGuard{max: max, seen: make(map[string]struct{})}
+}
+
+// value returns the label value to report for v. mu must be held.
+func (g *labelGuard) value(v string) string {
+	if v == otherLabel {
+		return v
+	}
+	if _, ok := g.seen[v]; ok {
+		return v
+	}
+	if len(g.seen) >= g.max {
+		return otherLabel
+	}
+	g.seen[v] = struct{}{}
+	return v
+}
+
+// vec is one metric family: its series keyed by label values. Every
+// method expects mu to be held.
+type vec[T any] struct {
+	name   string
+	help   string
+	labels []string
+	// guards holds an optional guard per label.
+	guards []*labelGuard
+	series map[string]*T
+	newT   func() *T
+}
+
+// guard attaches g to the named label.
+func (v *vec[T]) guard(label string, g *labelGuard) *vec[T] {
+	for i, l := range v.labels {
+		if l == label {
+			v.guards[i] = g
+		}
+	}
+	return v
+}
+
+// with returns the series for the given label values, creating it when
+// needed. Guarded labels and MaxSeriesPerMetric are applied first.
+func (v *vec[T]) with(values ...string) *T {
+	for i, g := range v.guards {
+		if g != nil {
+			values[i] = g.value(values[i])
+		}
+	}
+	key := strings.Join(values, labelSep)
+	if s, ok := v.series[key]; ok {
+		return s
+	}
+	if len(v.series) >= MaxSeriesPerMetric {
+		for i := range values {
+			values[i] = otherLabel
+		}
+		key = strings.Join(values, labelSep)
+		if s, ok := v.series[key]; ok {
+			return s
+		}
+	}
+	s := v.newT()
+	v.series[key] = s
+	return s
+}
+
+// keys returns the series keys in label order.
+func (v *vec[T]) keys() []string {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelPairs renders a series key as `a="x",b="y"`.
+func (v *vec[T]) labelPairs(key string) string {
+	if len(v.labels) == 0 {
+		return ""
+	}
+	values := strings.Split(key, labelSep)
+	pairs := make([]string, len(v.labels))
+	for i, l := range v.labels {
+		pairs[i] = fmt.Sprintf("%s=\"%s\"", l, escapeLabel(values[i]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v *vec[T]) writeHeader(b *strings.Builder, typ string) {
+	fmt.Fprintf(b, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", v.name, typ)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value for the Prometheus text format.
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// counterVec is a counter family.
+type counterVec struct {
+	vec[int64]
+}
+
+// newCounter registers a counter family. A counter without labels starts
+// with its single series at zero, so it is always exported.
+func newCounter(name, help string, labels ...string) *counterVec {
+	c := &counterVec{vec[int64]{
+		name:   name,
+		help:   help,
+		labels: labels,
+		guards: make([]*labelGuard, len(labels)),
+		series: make(map[string]*int64),
+		newT:   func() *int64 { return new(int64) },
+	}}
+	if len(labels) == 0 {
+		c.with()
+	}
+	counters = append(counters, c)
+	families = append(families, c)
+	return c
+}
+
+func (c *counterVec) guard(label string, g *labelGuard) *counterVec {
+	c.vec.guard(label, g)
+	return c
+}
+
+// add adds n to the series for values.
+func (c *counterVec) add(n int64, values ...string) {
+	*c.with(values...) += n
+}
+
+func (c *counterVec) write(b *strings.Builder) {
+	c.writeHeader(b, "counter")
+	for _, k := range c.keys() {
+		if labels := c.labelPairs(k); labels != "" {
+			fmt.Fprintf(b, "%s{%s} %d\n", c.name, labels, *c.series[k])
+		} else {
+			fmt.Fprintf(b, "%s %d\n", c.name, *c.series[k])
+		}
+	}
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []int64
+	sum     float64
+	count   int64
+}
+
+func (h *histogram) observe(v float64) {
+	for i, le := range h.buckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// histogramVec is a histogram family with fixed bucket upper bounds.
+type histogramVec struct {
+	vec[histogram]
+	buckets []float64
+}
+
+// newHistogram registers a histogram family.
+func newHistogram(name, help string, buckets []float64, labels ...string) *histogramVec {
+	h := &histogramVec{
+		vec: vec[histogram]{
+			name:   name,
+			help:   help,
+			labels: labels,
+			guards: make([]*labelGuard, len(labels)),
+			series: make(map[string]*histogram),
+			newT: func() *histogram {
+				return &histogram{buckets: buckets, counts: make([]int64, len(buckets))}
+			},
+		},
+		buckets: buckets,
+	}
+	histograms = append(histograms, h)
+	families = append(families, h)
+	return h
+}
+
+func (h *histogramVec) guard(label string, g *labelGuard) *histogramVec {
+	h.vec.guard(label, g)
+	return h
+}
+
+// observe records v in the series for values.
+func (h *histogramVec) observe(v float64, values ...string) {
+	h.with(values...).observe(v)
+}
+
+func (h *histogramVec) write(b *strings.Builder) {
+	h.writeHeader(b, "histogram")
+	for _, k := range h.keys() {
+		s := h.series[k]
+		labels := h.labelPairs(k)
+		prefix := labels
+		if prefix != "" {
+			prefix += ","
+		}
+		for i, le := range s.buckets {
+			fmt.Fprintf(b, "%s_bucket{%sle=\"%g\"} %d\n", h.name, prefix, le, s.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%sle=\"+Inf\"} %d\n", h.name, prefix, s.count)
+		if labels != "" {
+			fmt.Fprintf(b, "%s_sum{%s} %g\n", h.name, labels, s.sum)
+			fmt.Fprintf(b, "%s_count{%s} %d\n", h.name, labels, s.count)
+		} else {
+			fmt.Fprintf(b, "%s_sum %g\n", h.name, s.sum)
+			fmt.Fprintf(b, "%s_count %d\n", h.name, s.count)
+		}
+	}
+}
+
+// gaugeVec is a gauge family. Gauges describe current state, so they are
+// not saved by Snapshot.
+type gaugeVec struct {
+	vec[int64]
+}
+
+// newGauge registers a gauge family.
+func newGauge(name, help string, labels ...string) *gaugeVec {
+	g := &gaugeVec{vec[int64]{
+		name:   name,
+		help:   help,
+		labels: labels,
+		guards: make([]*labelGuard, len(labels)),
+		series: make(map[string]*int64),
+		newT:   func() *int64 { return new(int64) },
+	}}
+	families = append(families, g)
+	return g
+}
+
+// set sets the series for values to n.
+func (g *gaugeVec) set(n int64, values ...string) {
+	*g.with(values...) = n
+}
+
+// add adds n, which may be negative, to the series for values.
+func (g *gaugeVec) add(n int64, values ...string) {
+	*g.with(values...) += n
+}
+
+// zero sets every series to 0, keeping them exported.
+func (g *gaugeVec) zero() {
+	for _, v := range g.series {
+		*v = 0
+	}
+}
+
+func (g *gaugeVec) write(b *strings.Builder) {
+	g.writeHeader(b, "gauge")
+	for _, k := range g.keys() {
+		if labels := g.labelPairs(k); labels != "" {
+			fmt.Fprintf(b, "%s{%s} %d\n", g.name, labels, *g.series[k])
+		} else {
+			fmt.Fprintf(b, "%s %d\n", g.name, *g.series[k])
+		}
+	}
+}
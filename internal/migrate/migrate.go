@@ -1,8 +1,11 @@
 package migrate
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"math"
+	"path/filepath"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -12,11 +15,128 @@ import (
 // Run applies all pending migrations in db/migrations using goose.
 // It opens and closes its own DB handle so it is independent of the app store.
 func Run(dsn string) error {
-	db, err := sql.Open("pgx", dsn)
+	db, err := open(dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := goose.Up(db, migrationsDir); err != nil {
+		return fmt.Errorf("goose up: %w", err)
+	}
+
+	return nil
+}
+
+// To migrates the schema to the given version, applying up migrations when
+// the database is behind it and down migrations when it is ahead.
+func To(dsn string, version int64) error {
+	db, err := open(dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	current, err := goose.GetDBVersion(db)
+	if err != nil {
+		return fmt.Errorf("get db version: %w", err)
+	}
+	if version >= current {
+		if err := goose.UpTo(db, migrationsDir, version); err != nil {
+			return fmt.Errorf("goose up-to %d: %w", version, err)
+		}
+		return nil
+	}
+	if err := goose.DownTo(db, migrationsDir, version); err != nil {
+		return fmt.Errorf("goose down-to %d: %w", version, err)
+	}
+	return nil
+}
+
+// Down rolls back the most recently applied migration.
+func Down(dsn string) error {
+	db, err := open(dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := goose.Down(db, migrationsDir); err != nil {
+		return fmt.Errorf("goose down: %w", err)
+	}
+	return nil
+}
+
+// Migration identifies a single migration file.
+type Migration struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+}
+
+// Status describes a database's schema version relative to the migrations
+// shipped with this binary. Ahead is set when the database has been
+// migrated by a newer release; roll it back with that release's binary
+// before downgrading.
+type Status struct {
+	CurrentVersion int64       `json:"currentVersion"`
+	LatestVersion  int64       `json:"latestVersion"`
+	Pending        []Migration `json:"pending"`
+	Ahead          bool        `json:"ahead"`
+}
+
+// GetStatus reports the schema status of db.
+func GetStatus(ctx context.Context, db *sql.DB) (Status, error) {
+	if err := goose.SetDialect("postgres"); err != nil {
+		return Status{}, fmt.Errorf("set dialect: %w", err)
+	}
+	current, err := goose.GetDBVersionContext(ctx, db)
+	if err != nil {
+		return Status{}, fmt.Errorf("get db version: %w", err)
+	}
+	migrations, err := goose.CollectMigrations(migrationsDir, 0, math.MaxInt64)
+	if err != nil {
+		return Status{}, fmt.Errorf("collect migrations: %w", err)
+	}
+
+	st := Status{CurrentVersion: current, Pending: []Migration{}}
+	for _, m := range migrations {
+		if m.Version > st.LatestVersion {
+			st.LatestVersion = m.Version
+		}
+		if m.Version > current {
+			st.Pending = append(st.Pending, Migration{Version: m.Version, Name: filepath.Base(m.Source)})
+		}
+	}
+	st.Ahead = current > st.LatestVersion
+	return st, nil
+}
+
+// Version returns the schema version db is migrated to.
+func Version(ctx context.Context, db *sql.DB) (int64, error) {
+	if err := goose.SetDialect("postgres"); err != nil {
+		return 0, fmt.Errorf("set dialect: %w", err)
+	}
+	return goose.GetDBVersionContext(ctx, db)
+}
+
+// StatusFor opens dsn and reports its schema status.
+func StatusFor(dsn string) (Status, error) {
+	db, err := open(dsn)
 	if err != nil {
-		return fmt.Errorf("open db: %w", err)
+		return Status{}, err
 	}
 	defer db.Close()
+	return GetStatus(context.Background(), db)
+}
+
+const migrationsDir = "db/migrations"
+
+// open connects to dsn and waits for the database to accept connections.
+func open(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
 
 	// On fresh docker-compose startup, Postgres may not be ready immediately.
 	// Do a short retry loop to avoid failing hard on initial connection refusal.
@@ -27,7 +147,8 @@ func Run(dsn string) error {
 		}
 		if time.Now().After(deadline) {
 			if err := db.Ping(); err != nil {
-				return fmt.Errorf("db not ready: %w", err)
+				db.Close()
+				return nil, fmt.Errorf("db not ready: %w", err)
 			}
 			break
 		}
@@ -35,13 +156,8 @@ func Run(dsn string) error {
 	}
 
 	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("set dialect: %w", err)
-	}
-
-	migrationsDir := "db/migrations"
-	if err := goose.Up(db, migrationsDir); err != nil {
-		return fmt.Errorf("goose up: %w", err)
+		db.Close()
+		return nil, fmt.Errorf("set dialect: %w", err)
 	}
-
-	return nil
+	return db, nil
 }
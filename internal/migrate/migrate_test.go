@@ -0,0 +1,43 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMigrationsHaveDownSections guards rollbacks: every migration must be
+// reversible so `raito-api migrate down` and `migrate -to N` can undo it.
+func TestMigrationsHaveDownSections(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", migrationsDir, "*.sql"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no migrations found")
+	}
+
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read %s: %v", f, err)
+		}
+		_, down, ok := strings.Cut(string(raw), "-- +goose Down")
+		if !ok {
+			t.Errorf("%s: missing -- +goose Down section", filepath.Base(f))
+			continue
+		}
+		hasStatement := false
+		for _, line := range strings.Split(down, "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "--") {
+				hasStatement = true
+				break
+			}
+		}
+		if !hasStatement {
+			t.Errorf("%s: empty -- +goose Down section", filepath.Base(f))
+		}
+	}
+}
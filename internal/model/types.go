@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // Metadata is a trimmed version of Firecrawl's metadata block.
 type Metadata struct {
 	Title         string         `json:"title,omitempty"`
@@ -15,9 +17,38 @@ type Metadata struct {
 	OgSiteName    string         `json:"ogSiteName,omitempty"`
 	SourceURL     string         `json:"sourceURL,omitempty"`
 	StatusCode    int            `json:"statusCode"`
+	ETag          string         `json:"etag,omitempty"`
+	LastModified  string         `json:"lastModified,omitempty"`
 	Summary       string         `json:"summary,omitempty"`
 	JSON          map[string]any `json:"json,omitempty"`
 	Branding      map[string]any `json:"branding,omitempty"`
+	// Headers holds selected response headers, keyed by lower-case name
+	// (see scraper.CapturedHeaders).
+	Headers map[string]string `json:"headers,omitempty"`
+	// LanguageGroup is shared by a crawled page and the language versions
+	// it lists with hreflang annotations.
+	LanguageGroup string `json:"languageGroup,omitempty"`
+	// Plugins holds the output of plugin formats, keyed by format name.
+	Plugins map[string]any `json:"plugins,omitempty"`
+	// Categories holds the labels assigned by the classify format.
+	Categories []string `json:"categories,omitempty"`
+	// Truncated is set when markdown, html or rawHtml exceeded its size
+	// cap; TruncatedFormats names the formats that were cut.
+	Truncated        bool     `json:"truncated,omitempty"`
+	TruncatedFormats []string `json:"truncatedFormats,omitempty"`
+	// SkippedFormats lists the formats left out because the request's
+	// timeout ran out before they could be computed.
+	SkippedFormats []string `json:"skippedFormats,omitempty"`
+	// Accessibility holds the audit results of the a11y format.
+	Accessibility *AccessibilityReport `json:"accessibility,omitempty"`
+	// Performance holds the page timings and weight measured by the
+	// performance format.
+	Performance *PerformanceReport `json:"performance,omitempty"`
+	// AutoFormats lists the formats the auto format picked for the page.
+	AutoFormats []string `json:"autoFormats,omitempty"`
+	// PreviousDocumentID is the crawl document this one is a newer
+	// version of, set by rescrapes in version mode.
+	PreviousDocumentID int64 `json:"previousDocumentId,omitempty"`
 }
 
 // LinkMetadata captures additional information about an outbound link.
@@ -30,6 +61,9 @@ type LinkMetadata struct {
 // Document is a reduced version of Firecrawl's Document type
 // sufficient for scrape/map/crawl responses.
 type Document struct {
+	// ID identifies a stored document, e.g. for annotations; it is unset
+	// for documents that are not stored.
+	ID           int64          `json:"id,omitempty"`
 	Markdown     string         `json:"markdown,omitempty"`
 	HTML         string         `json:"html,omitempty"`
 	RawHTML      string         `json:"rawHtml,omitempty"`
@@ -40,6 +74,120 @@ type Document struct {
 	Summary      string         `json:"summary,omitempty"`
 	JSON         map[string]any `json:"json,omitempty"`
 	Branding     map[string]any `json:"branding,omitempty"`
+	Tables       []Table        `json:"tables,omitempty"`
+	Plugins      map[string]any `json:"plugins,omitempty"`
 	Engine       string         `json:"engine,omitempty"`
 	Metadata     Metadata       `json:"metadata"`
+	// Annotation holds user edits to a stored document.
+	Annotation *DocumentAnnotation `json:"annotation,omitempty"`
+	// DuplicateCluster identifies the group of near-identical pages a
+	// crawled document belongs to; it is unset for unique pages.
+	DuplicateCluster int `json:"duplicateCluster,omitempty"`
+	// StructuredData holds the page's JSON-LD nodes and microdata items
+	// for the structuredData format.
+	StructuredData []map[string]any `json:"structuredData,omitempty"`
+	// Evidence records what was captured when the scrape requested
+	// evidence: true.
+	Evidence *Evidence `json:"evidence,omitempty"`
+}
+
+// Evidence is a record of a captured page for compliance use: the hash
+// of the raw HTML as received, the response headers, and when it was
+// captured. The raw HTML, and the screenshot when one was taken, are kept
+// as job assets so the hashes can be checked later.
+type Evidence struct {
+	// Algorithm names the hash function, currently always "sha256".
+	Algorithm   string            `json:"algorithm"`
+	ContentHash string            `json:"contentHash"`
+	URL         string            `json:"url"`
+	StatusCode  int               `json:"statusCode,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	CapturedAt  time.Time         `json:"capturedAt"`
+	// RawHTMLAssetID and ScreenshotAssetID identify the stored copies.
+	RawHTMLAssetID    string `json:"rawHtmlAssetId"`
+	ScreenshotHash    string `json:"screenshotHash,omitempty"`
+	ScreenshotAssetID string `json:"screenshotAssetId,omitempty"`
+}
+
+// DocumentAnnotation holds a user's notes and corrections for a stored
+// document. It is kept apart from the scraped content.
+type DocumentAnnotation struct {
+	Notes string `json:"notes,omitempty"`
+	// Title, when set, replaces the scraped metadata title.
+	Title string `json:"title,omitempty"`
+	// Excluded documents are left out of downloads by default.
+	Excluded  bool      `json:"excluded"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Table is an HTML table parsed into a header row and data rows.
+type Table struct {
+	Caption string     `json:"caption,omitempty"`
+	Headers []string   `json:"headers,omitempty"`
+	Rows    [][]string `json:"rows"`
+}
+
+// AccessibilityReport lists the accessibility rules a rendered page
+// violates, most severe first.
+type AccessibilityReport struct {
+	Violations []AccessibilityViolation `json:"violations"`
+}
+
+// AccessibilityViolation is one failed rule. IDs and impacts follow
+// axe-core ("critical", "serious", "moderate", "minor"); Targets holds CSS
+// selectors for the first few offending elements.
+type AccessibilityViolation struct {
+	ID          string   `json:"id"`
+	Impact      string   `json:"impact"`
+	Description string   `json:"description"`
+	Help        string   `json:"help"`
+	Nodes       int      `json:"nodes"`
+	Targets     []string `json:"targets,omitempty"`
+}
+
+// PerformanceReport describes how a page loaded in the browser engine.
+type PerformanceReport struct {
+	Timings PerformanceTimings `json:"timings"`
+	// LargestContentfulPaintSource is "observer" when the browser reported
+	// largest contentful paint, or "estimate" when it was approximated from
+	// first contentful paint and image downloads.
+	LargestContentfulPaintSource string                  `json:"largestContentfulPaintSource,omitempty"`
+	Document                     PerformanceResourceStat `json:"document"`
+	Resources                    PerformanceResources    `json:"resources"`
+	// PageWeightBytes is the transfer size of the document and all of its
+	// resources.
+	PageWeightBytes int64 `json:"pageWeightBytes"`
+}
+
+// PerformanceTimings are milliseconds from the start of navigation, except
+// the phase durations DNS, connect, TLS and download.
+type PerformanceTimings struct {
+	DNSMs                    float64 `json:"dnsMs"`
+	ConnectMs                float64 `json:"connectMs"`
+	TLSMs                    float64 `json:"tlsMs"`
+	TTFBMs                   float64 `json:"ttfbMs"`
+	DownloadMs               float64 `json:"downloadMs"`
+	DOMInteractiveMs         float64 `json:"domInteractiveMs"`
+	DOMContentLoadedMs       float64 `json:"domContentLoadedMs"`
+	LoadMs                   float64 `json:"loadMs"`
+	FirstPaintMs             float64 `json:"firstPaintMs"`
+	FirstContentfulPaintMs   float64 `json:"firstContentfulPaintMs"`
+	LargestContentfulPaintMs float64 `json:"largestContentfulPaintMs"`
+}
+
+// PerformanceResources totals the subresources a page loaded. ByType is
+// keyed by "script", "stylesheet", "image", "font", "xhr", "media", or
+// "other".
+type PerformanceResources struct {
+	Count         int                                `json:"count"`
+	TransferBytes int64                              `json:"transferBytes"`
+	DecodedBytes  int64                              `json:"decodedBytes"`
+	ByType        map[string]PerformanceResourceStat `json:"byType"`
+}
+
+// PerformanceResourceStat is the count and size of a group of resources.
+type PerformanceResourceStat struct {
+	Count         int   `json:"count,omitempty"`
+	TransferBytes int64 `json:"transferBytes"`
+	DecodedBytes  int64 `json:"decodedBytes"`
 }
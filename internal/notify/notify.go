@@ -0,0 +1,160 @@
+// Package notify delivers job notifications: to the webhook set on a job's
+// request or its tenant's default webhook, and to users who subscribe to
+// their own jobs by email and personal webhook. Webhook deliveries are
+// signed when the job's tenant has a signing secret.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/scraper"
+)
+
+// Notification event names.
+const (
+	EventJobCompleted = "job.completed"
+	EventJobFailed    = "job.failed"
+)
+
+// Events lists the notification events a webhook can subscribe to.
+var Events = []string{EventJobCompleted, EventJobFailed}
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	defaultSMTPPort       = 587
+)
+
+// Event is the payload of a job notification. Webhooks receive it as the
+// JSON request body.
+type Event struct {
+	Event       string     `json:"event"`
+	JobID       string     `json:"jobId"`
+	JobType     string     `json:"jobType"`
+	Status      string     `json:"status"`
+	URL         string     `json:"url,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Notifier sends notifications with the configured mail server and an
+// HTTP client that refuses non-public addresses unless allowed.
+type Notifier struct {
+	cfg      config.NotificationsConfig
+	client   *http.Client
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// New returns a Notifier for cfg.
+func New(cfg config.NotificationsConfig) *Notifier {
+	timeout := defaultWebhookTimeout
+	if cfg.WebhookTimeoutMs > 0 {
+		timeout = time.Duration(cfg.WebhookTimeoutMs) * time.Millisecond
+	}
+	return &Notifier{
+		cfg:      cfg,
+		client:   scraper.NewPublicHTTPClient(timeout, cfg.AllowPrivateNetworks),
+		sendMail: smtp.SendMail,
+	}
+}
+
+// EmailAvailable reports whether a mail server is configured.
+func EmailAvailable(cfg config.NotificationsConfig) bool {
+	return strings.TrimSpace(cfg.SMTP.Host) != ""
+}
+
+// SendWebhook posts ev to url and fails unless the endpoint answers with
+// a 2xx status. With signing secrets, the delivery carries a timestamp
+// and one signature per secret.
+func (n *Notifier) SendWebhook(ctx context.Context, url string, ev Event, signingSecrets ...string) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "raito-notify")
+	req.Header.Set("X-Raito-Event", ev.Event)
+	if len(signingSecrets) > 0 {
+		ts := time.Now().Unix()
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+		req.Header.Set(HeaderSignature, SignatureHeader(signingSecrets, ts, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SendEmail mails ev to the given address.
+func (n *Notifier) SendEmail(to string, ev Event) error {
+	if !EmailAvailable(n.cfg) {
+		return fmt.Errorf("email notifications are not configured")
+	}
+	smtpCfg := n.cfg.SMTP
+	from, err := mail.ParseAddress(smtpCfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid notifications.smtp.from: %w", err)
+	}
+	port := smtpCfg.Port
+	if port <= 0 {
+		port = defaultSMTPPort
+	}
+
+	var auth smtp.Auth
+	if smtpCfg.Username != "" {
+		auth = smtp.PlainAuth("", smtpCfg.Username, smtpCfg.Password, smtpCfg.Host)
+	}
+	addr := net.JoinHostPort(smtpCfg.Host, strconv.Itoa(port))
+	return n.sendMail(addr, auth, from.Address, []string{to}, emailMessage(from.String(), to, ev))
+}
+
+// emailMessage renders ev as a plain-text message.
+func emailMessage(from, to string, ev Event) []byte {
+	outcome := "completed"
+	if ev.Event == EventJobFailed {
+		outcome = "failed"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: Raito %s job %s %s\r\n", ev.JobType, ev.JobID, outcome)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "Your %s job %s %s.\r\n\r\n", ev.JobType, ev.JobID, outcome)
+	if ev.URL != "" {
+		fmt.Fprintf(&b, "URL: %s\r\n", ev.URL)
+	}
+	if ev.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\r\n", ev.Error)
+	}
+	fmt.Fprintf(&b, "Created: %s\r\n", ev.CreatedAt.UTC().Format(time.RFC3339))
+	if ev.CompletedAt != nil {
+		fmt.Fprintf(&b, "Finished: %s\r\n", ev.CompletedAt.UTC().Format(time.RFC3339))
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"raito/internal/config"
+)
+
+func TestSendWebhook(t *testing.T) {
+	var got Event
+	var eventHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventHeader = r.Header.Get("X-Raito-Event")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		if got.JobID == "fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	n := New(config.NotificationsConfig{AllowPrivateNetworks: true})
+	ev := Event{Event: EventJobCompleted, JobID: "job-1", JobType: "crawl", Status: "completed", CreatedAt: time.Now()}
+	if err := n.SendWebhook(context.Background(), srv.URL, ev); err != nil {
+		t.Fatalf("SendWebhook: %v", err)
+	}
+	if got.JobID != "job-1" || eventHeader != EventJobCompleted {
+		t.Fatalf("unexpected delivery: %+v, header %q", got, eventHeader)
+	}
+
+	ev.JobID = "fail"
+	if err := n.SendWebhook(context.Background(), srv.URL, ev); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected a status error, got %v", err)
+	}
+}
+
+func TestSendWebhook_RefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	n := New(config.NotificationsConfig{})
+	if err := n.SendWebhook(context.Background(), srv.URL, Event{Event: EventJobFailed}); err == nil {
+		t.Fatalf("expected loopback delivery to be refused")
+	}
+}
+
+func TestSendEmail(t *testing.T) {
+	n := New(config.NotificationsConfig{})
+	if err := n.SendEmail("user@example.com", Event{}); err == nil {
+		t.Fatalf("expected an error without a mail server")
+	}
+
+	n = New(config.NotificationsConfig{SMTP: config.SMTPConfig{Host: "mail.example.com", From: "Raito <raito@example.com>"}})
+	var addr, from string
+	var msg []byte
+	n.sendMail = func(a string, _ smtp.Auth, f string, to []string, m []byte) error {
+		addr, from, msg = a, f, m
+		return nil
+	}
+	ev := Event{Event: EventJobFailed, JobID: "job-1", JobType: "crawl", Status: "failed", Error: "CRAWL_FAILED: boom", CreatedAt: time.Now()}
+	if err := n.SendEmail("user@example.com", ev); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+	if addr != "mail.example.com:587" || from != "raito@example.com" {
+		t.Fatalf("unexpected envelope: addr %q from %q", addr, from)
+	}
+	body := string(msg)
+	for _, want := range []string{"Subject: Raito crawl job job-1 failed", "To: user@example.com", "Error: CRAWL_FAILED: boom"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("message missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+	"raito/internal/db"
+	"raito/internal/secrets"
+)
+
+// Signature headers sent with webhook deliveries of tenants that have a
+// signing secret.
+const (
+	HeaderSignature = "X-Raito-Signature"
+	HeaderTimestamp = "X-Raito-Timestamp"
+)
+
+// SigningSecretName is the name tenant signing secrets are sealed under.
+const SigningSecretName = "webhook-signing"
+
+const (
+	signingSecretPrefix        = "whsec_"
+	defaultSecretGrace         = 24 * time.Hour
+	defaultReplayWindow        = 5 * time.Minute
+	signatureVersionPrefix     = "v1="
+	signingSecretRandomByteLen = 32
+)
+
+var (
+	// ErrSignatureMismatch is returned when no signature in the header
+	// matches the payload.
+	ErrSignatureMismatch = errors.New("signature does not match the payload")
+	// ErrTimestampOutsideWindow is returned for deliveries older or newer
+	// than the replay window allows.
+	ErrTimestampOutsideWindow = errors.New("timestamp is outside the replay window")
+)
+
+// NewSigningSecret returns a random webhook signing secret.
+func NewSigningSecret() (string, error) {
+	b := make([]byte, signingSecretRandomByteLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return signingSecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeader formats one "v1=" signature per secret, comma
+// separated, so receivers keep verifying while a secret is rotated.
+func SignatureHeader(secrets []string, timestamp int64, body []byte) string {
+	parts := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		parts = append(parts, signatureVersionPrefix+Sign(s, timestamp, body))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Verify checks that header carries a signature of body made with secret
+// and that timestamp is within window of now. It is the check receivers
+// are expected to perform.
+func Verify(secret, header string, timestamp int64, body []byte, now time.Time, window time.Duration) error {
+	if window <= 0 {
+		window = defaultReplayWindow
+	}
+	if d := now.Sub(time.Unix(timestamp, 0)); d > window || d < -window {
+		return ErrTimestampOutsideWindow
+	}
+	want := Sign(secret, timestamp, body)
+	for _, part := range strings.Split(header, ",") {
+		sig, ok := strings.CutPrefix(strings.TrimSpace(part), signatureVersionPrefix)
+		if ok && hmac.Equal([]byte(sig), []byte(want)) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// ReplayWindow returns notifications.webhookReplayWindowSeconds as a
+// duration, defaulting to five minutes.
+func ReplayWindow(cfg config.NotificationsConfig) time.Duration {
+	if cfg.WebhookReplayWindowSeconds > 0 {
+		return time.Duration(cfg.WebhookReplayWindowSeconds) * time.Second
+	}
+	return defaultReplayWindow
+}
+
+// SecretGrace returns notifications.webhookSecretGraceHours as a
+// duration, defaulting to 24 hours.
+func SecretGrace(cfg config.NotificationsConfig) time.Duration {
+	if cfg.WebhookSecretGraceHours > 0 {
+		return time.Duration(cfg.WebhookSecretGraceHours) * time.Hour
+	}
+	return defaultSecretGrace
+}
+
+// TenantSigningSecrets returns the tenant's current signing secret and,
+// until it expires, the one it replaced. Tenants without a secret get
+// nil, and their deliveries are sent unsigned.
+func TenantSigningSecrets(ctx context.Context, cfg *config.Config, q *db.Queries, tenantID uuid.UUID, now time.Time) ([]string, error) {
+	row, err := q.GetTenantWebhookSecret(ctx, tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	current, err := secrets.Open(cfg, tenantID, SigningSecretName, row.SecretEncrypted)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{current}
+	if len(row.PreviousSecretEncrypted) > 0 && row.PreviousExpiresAt.Valid && now.Before(row.PreviousExpiresAt.Time) {
+		if previous, err := secrets.Open(cfg, tenantID, SigningSecretName, row.PreviousSecretEncrypted); err == nil {
+			out = append(out, previous)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"raito/internal/config"
+)
+
+func TestVerify(t *testing.T) {
+	secret, err := NewSigningSecret()
+	if err != nil {
+		t.Fatalf("NewSigningSecret: %v", err)
+	}
+	if !strings.HasPrefix(secret, "whsec_") {
+		t.Fatalf("unexpected secret format %q", secret)
+	}
+
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"event":"job.completed"}`)
+	header := SignatureHeader([]string{"old", secret}, now.Unix(), body)
+
+	if err := Verify(secret, header, now.Unix(), body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Fatalf("expected a valid signature, got %v", err)
+	}
+	if err := Verify(secret, header, now.Unix(), []byte(`{}`), now, 5*time.Minute); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected a mismatch for a modified body, got %v", err)
+	}
+	if err := Verify("other", header, now.Unix(), body, now, 5*time.Minute); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected a mismatch for another secret, got %v", err)
+	}
+	if err := Verify(secret, header, now.Unix(), body, now.Add(6*time.Minute), 5*time.Minute); !errors.Is(err, ErrTimestampOutsideWindow) {
+		t.Fatalf("expected a replayed delivery to be rejected, got %v", err)
+	}
+}
+
+func TestSendWebhook_Signed(t *testing.T) {
+	var body []byte
+	var sig, ts string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		sig = r.Header.Get(HeaderSignature)
+		ts = r.Header.Get(HeaderTimestamp)
+	}))
+	defer srv.Close()
+
+	n := New(config.NotificationsConfig{AllowPrivateNetworks: true})
+	if err := n.SendWebhook(context.Background(), srv.URL, Event{Event: EventJobCompleted, JobID: "job-1"}); err != nil {
+		t.Fatalf("SendWebhook: %v", err)
+	}
+	if sig != "" || ts != "" {
+		t.Fatalf("expected an unsigned delivery without secrets, got %q %q", sig, ts)
+	}
+
+	if err := n.SendWebhook(context.Background(), srv.URL, Event{Event: EventJobCompleted, JobID: "job-1"}, "current", "previous"); err != nil {
+		t.Fatalf("SendWebhook: %v", err)
+	}
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp header %q", ts)
+	}
+	if strings.Count(sig, "v1=") != 2 {
+		t.Fatalf("expected one signature per secret, got %q", sig)
+	}
+	for _, secret := range []string{"current", "previous"} {
+		if err := Verify(secret, sig, timestamp, body, time.Now(), 0); err != nil {
+			t.Fatalf("Verify with %s: %v", secret, err)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Package plugins runs operator-installed format plugins. A plugin is an
+// executable, or a WASI module run by a configured runtime, that reads a
+// JSON Request on stdin and writes a JSON Reply on stdout. Its fields are
+// returned under the document's plugins.<format> key.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/scraper"
+)
+
+const (
+	defaultTimeout        = 10 * time.Second
+	defaultMaxOutputBytes = 1 << 20
+	// maxStderrBytes bounds the stderr tail included in error messages.
+	maxStderrBytes = 4 << 10
+)
+
+// Request is the JSON document written to a plugin's stdin.
+type Request struct {
+	// Format is the requested format name.
+	Format string `json:"format"`
+	// Options holds the format object from the request, e.g.
+	// {"type": "readability", "minScore": 20}.
+	Options map[string]any `json:"options,omitempty"`
+	Result  Result         `json:"result"`
+}
+
+// Result is the scrape result given to plugins.
+type Result struct {
+	URL          string         `json:"url"`
+	StatusCode   int            `json:"statusCode"`
+	Engine       string         `json:"engine,omitempty"`
+	Markdown     string         `json:"markdown"`
+	HTML         string         `json:"html"`
+	RawHTML      string         `json:"rawHtml"`
+	Links        []string       `json:"links,omitempty"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+	ETag         string         `json:"etag,omitempty"`
+	LastModified string         `json:"lastModified,omitempty"`
+}
+
+// Reply is the JSON document a plugin writes to stdout. A non-empty Error
+// fails the plugin run.
+type Reply struct {
+	Fields map[string]any `json:"fields"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// Plugin is one installed format plugin.
+type Plugin struct {
+	Name string
+	// Type is "exec" or "wasm".
+	Type           string
+	argv           []string
+	env            []string
+	timeout        time.Duration
+	maxOutputBytes int
+}
+
+// Registry holds the installed format plugins by lowercased name.
+type Registry struct {
+	plugins map[string]*Plugin
+}
+
+// NewRegistry builds a registry from the plugins config. Entries that
+// config validation rejects are skipped.
+func NewRegistry(cfg config.PluginsConfig) *Registry {
+	r := &Registry{plugins: map[string]*Plugin{}}
+	for _, pc := range cfg.Formats {
+		name := strings.ToLower(strings.TrimSpace(pc.Name))
+		if name == "" {
+			continue
+		}
+		p := &Plugin{
+			Name:           pc.Name,
+			Type:           pc.Type,
+			timeout:        time.Duration(pc.TimeoutMs) * time.Millisecond,
+			maxOutputBytes: pc.MaxOutputBytes,
+		}
+		switch pc.Type {
+		case "exec":
+			if len(pc.Command) == 0 {
+				continue
+			}
+			p.argv = append([]string(nil), pc.Command...)
+		case "wasm":
+			if len(cfg.WASMRuntime) == 0 || pc.Module == "" {
+				continue
+			}
+			p.argv = append(append([]string(nil), cfg.WASMRuntime...), pc.Module)
+		default:
+			continue
+		}
+		if p.timeout <= 0 {
+			p.timeout = defaultTimeout
+		}
+		if p.maxOutputBytes <= 0 {
+			p.maxOutputBytes = defaultMaxOutputBytes
+		}
+		for k, v := range pc.Env {
+			p.env = append(p.env, k+"="+v)
+		}
+		sort.Strings(p.env)
+		r.plugins[name] = p
+	}
+	return r
+}
+
+// Lookup returns the plugin installed under name.
+func (r *Registry) Lookup(name string) (*Plugin, bool) {
+	if r == nil {
+		return nil, false
+	}
+	p, ok := r.plugins[strings.ToLower(strings.TrimSpace(name))]
+	return p, ok
+}
+
+// Plugins returns the installed plugins sorted by name.
+func (r *Registry) Plugins() []*Plugin {
+	if r == nil {
+		return nil
+	}
+	out := make([]*Plugin, 0, len(r.plugins))
+	for _, p := range r.plugins {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Invocation is a plugin format found in a request's formats array.
+type Invocation struct {
+	Plugin  *Plugin
+	Options map[string]any
+}
+
+// Requested returns the plugin formats in a Firecrawl-style formats array,
+// given either as "name" or {"type": "name", ...options}.
+func (r *Registry) Requested(formats []any) []Invocation {
+	if r == nil || len(r.plugins) == 0 {
+		return nil
+	}
+	var out []Invocation
+	for _, f := range formats {
+		var (
+			name    string
+			options map[string]any
+		)
+		switch v := f.(type) {
+		case string:
+			name = v
+		case map[string]any:
+			name, _ = v["type"].(string)
+			options = v
+		}
+		if p, ok := r.Lookup(name); ok {
+			out = append(out, Invocation{Plugin: p, Options: options})
+		}
+	}
+	return out
+}
+
+// NewResult converts a scraper result into the plugin Result shape.
+func NewResult(res *scraper.Result) Result {
+	return Result{
+		URL:          res.URL,
+		StatusCode:   res.Status,
+		Engine:       res.Engine,
+		Markdown:     res.Markdown,
+		HTML:         res.HTML,
+		RawHTML:      res.RawHTML,
+		Links:        res.Links,
+		Metadata:     res.Metadata,
+		ETag:         res.ETag,
+		LastModified: res.LastModified,
+	}
+}
+
+// RunAll runs every requested plugin against res and returns their fields
+// keyed by format name. Failed plugins are left out of the map and their
+// errors joined into the returned error.
+func (r *Registry) RunAll(ctx context.Context, formats []any, res *scraper.Result) (map[string]any, error) {
+	invocations := r.Requested(formats)
+	if len(invocations) == 0 || res == nil {
+		return nil, nil
+	}
+	input := NewResult(res)
+	out := make(map[string]any, len(invocations))
+	var errs []error
+	for _, inv := range invocations {
+		fields, err := inv.Plugin.Run(ctx, Request{Format: inv.Plugin.Name, Options: inv.Options, Result: input})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out[inv.Plugin.Name] = fields
+	}
+	return out, errors.Join(errs...)
+}
+
+// Run executes the plugin once with req on stdin and returns the fields
+// of its reply.
+func (p *Plugin) Run(ctx context.Context, req Request) (map[string]any, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, p.argv[0], p.argv[1:]...)
+	// Plugins only see the variables configured for them.
+	cmd.Env = p.env
+	if cmd.Env == nil {
+		cmd.Env = []string{}
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	stdout := &limitedBuffer{limit: p.maxOutputBytes}
+	stderr := &limitedBuffer{limit: maxStderrBytes, truncate: true}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	switch {
+	case runCtx.Err() == context.DeadlineExceeded:
+		return nil, fmt.Errorf("plugin %s timed out after %s", p.Name, p.timeout)
+	case stdout.exceeded:
+		return nil, fmt.Errorf("plugin %s wrote more than %d bytes", p.Name, p.maxOutputBytes)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s failed: %v: %s", p.Name, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", p.Name, err)
+	}
+
+	var reply Reply
+	if err := json.Unmarshal(stdout.Bytes(), &reply); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %w", p.Name, err)
+	}
+	if reply.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", p.Name, reply.Error)
+	}
+	if reply.Fields == nil {
+		reply.Fields = map[string]any{}
+	}
+	return reply.Fields, nil
+}
+
+// errOutputLimit stops a plugin that writes more than its output limit.
+var errOutputLimit = errors.New("output limit exceeded")
+
+// limitedBuffer collects up to limit bytes. Past the limit it either
+// drops further output (truncate) or fails the write. The buffer is not
+// embedded so that io.Copy cannot bypass Write through ReadFrom.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	truncate bool
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	room := b.limit - b.buf.Len()
+	if len(p) <= room {
+		return b.buf.Write(p)
+	}
+	b.exceeded = true
+	if room > 0 {
+		b.buf.Write(p[:room])
+	}
+	if b.truncate {
+		return len(p), nil
+	}
+	return 0, errOutputLimit
+}
+
+func (b *limitedBuffer) Bytes() []byte  { return b.buf.Bytes() }
+func (b *limitedBuffer) String() string { return b.buf.String() }
@@ -0,0 +1,103 @@
+package plugins
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"raito/internal/config"
+	"raito/internal/scraper"
+)
+
+func shPlugin(name, script string, timeoutMs int) config.FormatPluginConfig {
+	return config.FormatPluginConfig{
+		Name:      name,
+		Type:      "exec",
+		Command:   []string{"/bin/sh", "-c", script},
+		TimeoutMs: timeoutMs,
+		Env:       map[string]string{"GREETING": "hi"},
+	}
+}
+
+func TestRegistry_RunAll(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires /bin/sh")
+	}
+	reg := NewRegistry(config.PluginsConfig{Formats: []config.FormatPluginConfig{
+		// Echo the request back as the plugin's fields.
+		shPlugin("echo", `printf '{"fields":'; cat; printf '}'`, 0),
+		shPlugin("env", `cat >/dev/null; printf '{"fields":{"greeting":"%s","home":"%s"}}' "$GREETING" "$HOME"`, 0),
+		shPlugin("broken", `cat >/dev/null; echo boom >&2; exit 3`, 0),
+	}})
+
+	res := &scraper.Result{URL: "https://example.com", Status: 200, Markdown: "# Hi"}
+	formats := []any{"markdown", map[string]any{"type": "Echo", "depth": float64(2)}, "env", "broken", "unknown"}
+
+	out, err := reg.RunAll(context.Background(), formats, res)
+	if err == nil || !strings.Contains(err.Error(), "plugin broken failed") || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected the broken plugin's error with stderr, got %v", err)
+	}
+
+	echo, ok := out["echo"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected echo output, got %+v", out)
+	}
+	result, _ := echo["result"].(map[string]any)
+	options, _ := echo["options"].(map[string]any)
+	if echo["format"] != "echo" || result["markdown"] != "# Hi" || options["depth"] != float64(2) {
+		t.Fatalf("unexpected plugin request: %+v", echo)
+	}
+
+	env, _ := out["env"].(map[string]any)
+	if env["greeting"] != "hi" || env["home"] != "" {
+		t.Fatalf("expected only configured env vars, got %+v", env)
+	}
+	if _, ok := out["broken"]; ok {
+		t.Fatalf("expected failed plugins to be left out")
+	}
+}
+
+func TestPlugin_Limits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires /bin/sh")
+	}
+	slow := shPlugin("slow", `sleep 5`, 100)
+	chatty := shPlugin("chatty", `cat >/dev/null; head -c 4096 /dev/zero`, 0)
+	chatty.MaxOutputBytes = 1024
+	refusing := shPlugin("refusing", `cat >/dev/null; echo '{"error":"unsupported page"}'`, 0)
+	reg := NewRegistry(config.PluginsConfig{Formats: []config.FormatPluginConfig{slow, chatty, refusing}})
+
+	cases := map[string]string{
+		"slow":     "timed out",
+		"chatty":   "more than 1024 bytes",
+		"refusing": "unsupported page",
+	}
+	for name, want := range cases {
+		p, ok := reg.Lookup(name)
+		if !ok {
+			t.Fatalf("plugin %s not registered", name)
+		}
+		if _, err := p.Run(context.Background(), Request{Format: name}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", name, want, err)
+		}
+	}
+}
+
+func TestNewRegistry_WASM(t *testing.T) {
+	reg := NewRegistry(config.PluginsConfig{
+		WASMRuntime: []string{"wasmtime", "run"},
+		Formats:     []config.FormatPluginConfig{{Name: "Readability", Type: "wasm", Module: "/plugins/readability.wasm"}},
+	})
+	p, ok := reg.Lookup("readability")
+	if !ok {
+		t.Fatalf("expected wasm plugin to be registered")
+	}
+	if got := strings.Join(p.argv, " "); got != "wasmtime run /plugins/readability.wasm" {
+		t.Fatalf("unexpected wasm command %q", got)
+	}
+
+	if _, ok := NewRegistry(config.PluginsConfig{Formats: []config.FormatPluginConfig{{Name: "x", Type: "wasm", Module: "x.wasm"}}}).Lookup("x"); ok {
+		t.Fatalf("expected wasm plugins to need a runtime")
+	}
+}
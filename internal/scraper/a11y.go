@@ -0,0 +1,53 @@
+package scraper
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"time"
+
+	"raito/internal/model"
+)
+
+// a11yAuditScript checks the rendered DOM against a subset of the
+// axe-core rules: names for images, buttons, links, form fields and
+// frames, document title and language, zoom, lists, headings, duplicate
+// ids, and text color contrast.
+//
+//go:embed a11y_audit.js
+var a11yAuditScript string
+
+// AuditAccessibility renders targetURL with rod and runs the accessibility
+// audit in the page. Like CaptureScreenshot, it always uses a local
+// headless browser.
+func AuditAccessibility(ctx context.Context, targetURL string, timeout time.Duration) (*model.AccessibilityReport, error) {
+	out, err := evalPageScript(ctx, targetURL, timeout, a11yAuditScript)
+	if err != nil {
+		return nil, err
+	}
+	var report model.AccessibilityReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		return nil, err
+	}
+	if report.Violations == nil {
+		report.Violations = []model.AccessibilityViolation{}
+	}
+	return &report, nil
+}
+
+// AuditBrowserAccessibility is AuditAccessibility honoring the configured
+// isolation mode.
+func AuditBrowserAccessibility(ctx context.Context, opts BrowserOptions, targetURL string, timeout time.Duration) (*model.AccessibilityReport, error) {
+	if !opts.isolated() {
+		return AuditAccessibility(ctx, targetURL, timeout)
+	}
+	reply, err := runBrowserProcess(ctx, opts, browserJob{
+		Kind:      browserJobA11y,
+		URL:       targetURL,
+		TimeoutMs: timeout.Milliseconds(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Accessibility, nil
+}
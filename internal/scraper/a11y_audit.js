@@ -0,0 +1,224 @@
+() => {
+  const MAX_TARGETS = 5;
+  const MAX_CONTRAST_NODES = 1000;
+  const results = {};
+
+  const report = (id, impact, description, help, el) => {
+    let r = results[id];
+    if (!r) {
+      r = results[id] = { id, impact, description, help, nodes: 0, targets: [] };
+    }
+    r.nodes++;
+    if (el && r.targets.length < MAX_TARGETS) {
+      r.targets.push(selector(el));
+    }
+  };
+
+  const selector = (el) => {
+    const parts = [];
+    for (let n = el; n && n.nodeType === 1 && parts.length < 5; n = n.parentElement) {
+      let part = n.localName;
+      if (n.id && document.querySelectorAll('#' + CSS.escape(n.id)).length === 1) {
+        parts.unshift(part + '#' + CSS.escape(n.id));
+        break;
+      }
+      const parent = n.parentElement;
+      if (parent) {
+        const same = Array.from(parent.children).filter((c) => c.localName === n.localName);
+        if (same.length > 1) {
+          part += ':nth-of-type(' + (same.indexOf(n) + 1) + ')';
+        }
+      }
+      parts.unshift(part);
+    }
+    return parts.join(' > ');
+  };
+
+  const hidden = (el) => {
+    for (let n = el; n && n.nodeType === 1; n = n.parentElement) {
+      if (n.getAttribute('aria-hidden') === 'true' || n.hidden) return true;
+    }
+    const style = getComputedStyle(el);
+    return style.display === 'none' || style.visibility === 'hidden';
+  };
+
+  const text = (s) => (s || '').replace(/\s+/g, ' ').trim();
+
+  const accessibleName = (el) => {
+    const label = text(el.getAttribute('aria-label'));
+    if (label) return label;
+    const ids = text(el.getAttribute('aria-labelledby'));
+    if (ids) {
+      const named = ids.split(' ').map((id) => {
+        const ref = document.getElementById(id);
+        return ref ? text(ref.textContent) : '';
+      }).join(' ');
+      if (text(named)) return text(named);
+    }
+    if (el.labels && el.labels.length) {
+      const labelled = Array.from(el.labels).map((l) => text(l.textContent)).join(' ');
+      if (text(labelled)) return text(labelled);
+    }
+    const content = text(el.innerText || el.textContent);
+    if (content) return content;
+    for (const img of el.querySelectorAll('img[alt], [role="img"][aria-label]')) {
+      const alt = text(img.getAttribute('alt') || img.getAttribute('aria-label'));
+      if (alt) return alt;
+    }
+    if (el.localName === 'input' && ['submit', 'reset', 'button'].includes(el.type)) {
+      if (text(el.value)) return text(el.value);
+      if (el.type !== 'button') return el.type;
+    }
+    return text(el.getAttribute('title')) || text(el.getAttribute('placeholder'));
+  };
+
+  // document-title, html-has-lang
+  if (!text(document.title)) {
+    report('document-title', 'serious', 'Documents must have a <title> element', 'Give the page a descriptive title', null);
+  }
+  if (!text(document.documentElement.getAttribute('lang'))) {
+    report('html-has-lang', 'serious', '<html> element must have a lang attribute', 'Set lang on <html> so screen readers use the right language', document.documentElement);
+  }
+
+  // meta-viewport
+  const viewport = document.querySelector('meta[name="viewport"]');
+  if (viewport) {
+    const content = (viewport.getAttribute('content') || '').toLowerCase().replace(/\s/g, '');
+    const max = /maximum-scale=([\d.]+)/.exec(content);
+    if (/user-scalable=(no|0)\b/.test(content) || (max && parseFloat(max[1]) < 2)) {
+      report('meta-viewport', 'critical', 'Zooming and scaling must not be disabled', 'Remove user-scalable=no and maximum-scale below 2 from the viewport meta tag', viewport);
+    }
+  }
+
+  // image-alt, input-image-alt
+  for (const img of document.querySelectorAll('img')) {
+    const role = img.getAttribute('role');
+    if (role === 'presentation' || role === 'none' || hidden(img)) continue;
+    if (!img.hasAttribute('alt') && !text(img.getAttribute('aria-label')) && !text(img.getAttribute('aria-labelledby'))) {
+      report('image-alt', 'critical', 'Images must have alternate text', 'Add an alt attribute; use alt="" for decorative images', img);
+    }
+  }
+  for (const input of document.querySelectorAll('input[type="image"]')) {
+    if (!hidden(input) && !accessibleName(input) && !text(input.getAttribute('alt'))) {
+      report('input-image-alt', 'critical', 'Image buttons must have alternate text', 'Add an alt attribute describing the button action', input);
+    }
+  }
+
+  // button-name, link-name
+  for (const el of document.querySelectorAll('button, [role="button"], input[type="submit"], input[type="button"], input[type="reset"]')) {
+    if (!hidden(el) && !accessibleName(el)) {
+      report('button-name', 'critical', 'Buttons must have discernible text', 'Give the button text content, aria-label, or aria-labelledby', el);
+    }
+  }
+  for (const el of document.querySelectorAll('a[href]')) {
+    if (!hidden(el) && !accessibleName(el)) {
+      report('link-name', 'serious', 'Links must have discernible text', 'Give the link text content, or alt text on its image', el);
+    }
+  }
+
+  // label
+  const unlabelledTypes = ['hidden', 'submit', 'reset', 'button', 'image'];
+  for (const el of document.querySelectorAll('input, select, textarea')) {
+    if (el.localName === 'input' && unlabelledTypes.includes((el.type || '').toLowerCase())) continue;
+    if (hidden(el)) continue;
+    const labelled = text(el.getAttribute('aria-label')) || text(el.getAttribute('aria-labelledby')) ||
+      (el.labels && Array.from(el.labels).some((l) => text(l.textContent))) || text(el.getAttribute('title'));
+    if (!labelled) {
+      report('label', 'critical', 'Form elements must have labels', 'Associate a <label> with the field, or add aria-label', el);
+    }
+  }
+
+  // frame-title
+  for (const el of document.querySelectorAll('iframe, frame')) {
+    if (!hidden(el) && !text(el.getAttribute('title')) && !text(el.getAttribute('aria-label'))) {
+      report('frame-title', 'serious', 'Frames must have an accessible name', 'Add a title attribute describing the frame content', el);
+    }
+  }
+
+  // duplicate-id
+  const seen = {};
+  for (const el of document.querySelectorAll('[id]')) {
+    const id = el.id;
+    if (!id) continue;
+    if (seen[id]) {
+      report('duplicate-id', 'minor', 'id attribute values must be unique', 'Rename duplicate ids so label and ARIA references resolve', el);
+    }
+    seen[id] = true;
+  }
+
+  // list
+  for (const list of document.querySelectorAll('ul, ol')) {
+    const invalid = Array.from(list.children).some((c) => !['li', 'script', 'template'].includes(c.localName));
+    if (invalid) {
+      report('list', 'serious', '<ul> and <ol> must only directly contain <li>, <script> or <template> elements', 'Wrap list content in <li> elements', list);
+    }
+  }
+
+  // page-has-heading-one, heading-order
+  const headings = Array.from(document.querySelectorAll('h1, h2, h3, h4, h5, h6')).filter((h) => !hidden(h));
+  if (!headings.some((h) => h.localName === 'h1')) {
+    report('page-has-heading-one', 'moderate', 'Page should contain a level-one heading', 'Add an <h1> describing the page', null);
+  }
+  let previous = 0;
+  for (const h of headings) {
+    const level = parseInt(h.localName.substring(1), 10);
+    if (previous && level > previous + 1) {
+      report('heading-order', 'moderate', 'Heading levels should only increase by one', 'Do not skip heading levels, e.g. from <h2> to <h4>', h);
+    }
+    previous = level;
+  }
+
+  // color-contrast
+  const parseColor = (value) => {
+    const m = /rgba?\(([^)]+)\)/.exec(value || '');
+    if (!m) return null;
+    const p = m[1].split(/[\s,/]+/).filter(Boolean).map(parseFloat);
+    return { r: p[0], g: p[1], b: p[2], a: p.length > 3 ? p[3] : 1 };
+  };
+  const luminance = (c) => {
+    const ch = [c.r, c.g, c.b].map((v) => {
+      v /= 255;
+      return v <= 0.03928 ? v / 12.92 : Math.pow((v + 0.055) / 1.055, 2.4);
+    });
+    return 0.2126 * ch[0] + 0.7152 * ch[1] + 0.0722 * ch[2];
+  };
+  const background = (el) => {
+    for (let n = el; n && n.nodeType === 1; n = n.parentElement) {
+      const style = getComputedStyle(n);
+      if (style.backgroundImage && style.backgroundImage !== 'none') return null;
+      const bg = parseColor(style.backgroundColor);
+      if (bg && bg.a >= 1) return bg;
+      if (bg && bg.a > 0) return null;
+    }
+    return { r: 255, g: 255, b: 255, a: 1 };
+  };
+  let checked = 0;
+  const walker = document.createTreeWalker(document.body || document.documentElement, NodeFilter.SHOW_TEXT);
+  const done = new Set();
+  while (walker.nextNode() && checked < MAX_CONTRAST_NODES) {
+    const node = walker.currentNode;
+    const el = node.parentElement;
+    if (!el || done.has(el) || !text(node.textContent)) continue;
+    if (['script', 'style', 'noscript', 'template'].includes(el.localName)) continue;
+    done.add(el);
+    if (hidden(el)) continue;
+    checked++;
+    const style = getComputedStyle(el);
+    const fg = parseColor(style.color);
+    const bg = background(el);
+    if (!fg || !bg || fg.a < 1) continue;
+    const l1 = luminance(fg);
+    const l2 = luminance(bg);
+    const ratio = (Math.max(l1, l2) + 0.05) / (Math.min(l1, l2) + 0.05);
+    const size = parseFloat(style.fontSize) || 16;
+    const bold = parseInt(style.fontWeight, 10) >= 700;
+    const large = size >= 24 || (bold && size >= 18.66);
+    if (ratio < (large ? 3 : 4.5)) {
+      report('color-contrast', 'serious', 'Text must have sufficient color contrast with its background', 'Raise the contrast to at least 4.5:1, or 3:1 for large text', el);
+    }
+  }
+
+  const order = { critical: 0, serious: 1, moderate: 2, minor: 3 };
+  const violations = Object.values(results).sort((a, b) => order[a.impact] - order[b.impact] || b.nodes - a.nodes);
+  return JSON.stringify({ violations });
+}
@@ -0,0 +1,273 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"raito/internal/metrics"
+	"raito/internal/model"
+)
+
+const (
+	// BrowserIsolationInProcess runs Chromium under the calling process.
+	BrowserIsolationInProcess = "inprocess"
+	// BrowserIsolationProcess runs each browser scrape in a child process
+	// so a crash or OOM fails only that URL.
+	BrowserIsolationProcess = "process"
+
+	// BrowserRenderCommand is the raito-api subcommand that serves one
+	// browser job from stdin in a child process.
+	BrowserRenderCommand = "browser-render"
+
+	// DefaultBrowserMaxProcesses caps concurrent browser child processes
+	// when rod.maxProcesses is not set.
+	DefaultBrowserMaxProcesses = 4
+)
+
+// ErrBrowserProcessCrashed is returned when a browser child process exits
+// without a reply, e.g. because Chromium or the renderer was OOM-killed.
+var ErrBrowserProcessCrashed = errors.New("browser process crashed")
+
+// BrowserOptions selects how browser scrapes and screenshots are run.
+type BrowserOptions struct {
+	// Isolation is BrowserIsolationInProcess (the default) or
+	// BrowserIsolationProcess.
+	Isolation string
+	// MaxProcesses caps concurrent child processes in process mode.
+	MaxProcesses int
+	// Command overrides the child command line; it defaults to this
+	// executable with BrowserRenderCommand.
+	Command []string
+}
+
+func (o BrowserOptions) isolated() bool {
+	return o.Isolation == BrowserIsolationProcess
+}
+
+// NewBrowserScraper returns the browser engine for opts: a RodScraper, or
+// a ProcessRodScraper when process isolation is configured.
+func NewBrowserScraper(timeout time.Duration, opts BrowserOptions) Scraper {
+	if opts.isolated() {
+		return &ProcessRodScraper{Timeout: timeout, Options: opts}
+	}
+	return NewRodScraper(timeout)
+}
+
+// CaptureBrowserScreenshot is CaptureScreenshot honoring the configured
+// isolation mode.
+func CaptureBrowserScreenshot(ctx context.Context, opts BrowserOptions, targetURL string, timeout time.Duration, fullPage bool) ([]byte, error) {
+	if !opts.isolated() {
+		return CaptureScreenshot(ctx, targetURL, timeout, fullPage)
+	}
+	reply, err := runBrowserProcess(ctx, opts, browserJob{
+		Kind:      browserJobScreenshot,
+		URL:       targetURL,
+		FullPage:  fullPage,
+		TimeoutMs: timeout.Milliseconds(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Screenshot, nil
+}
+
+// ProcessRodScraper runs each browser scrape in a child process that
+// drives its own Chromium. The parent only exchanges JSON with the child,
+// so a renderer crash surfaces as an error for one URL.
+type ProcessRodScraper struct {
+	Timeout time.Duration
+	Options BrowserOptions
+}
+
+func (p *ProcessRodScraper) Scrape(ctx context.Context, req Request) (*Result, error) {
+	metrics.JobRuntimeFrom(ctx).SetCurrentURL(req.URL)
+	started := time.Now()
+	defer func() { metrics.JobRuntimeFrom(ctx).AddBrowserTime(time.Since(started)) }()
+
+	reply, err := runBrowserProcess(ctx, p.Options, browserJob{
+		Kind:      browserJobScrape,
+		Request:   req,
+		TimeoutMs: p.Timeout.Milliseconds(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reply.Result == nil {
+		return nil, errors.New("browser process returned no result")
+	}
+	metrics.JobRuntimeFrom(ctx).AddPage(int64(len(reply.Result.RawHTML)))
+	return reply.Result, nil
+}
+
+const (
+	browserJobScrape      = "scrape"
+	browserJobScreenshot  = "screenshot"
+	browserJobA11y        = "a11y"
+	browserJobPerformance = "performance"
+)
+
+// browserJob is the request written to a child's stdin.
+type browserJob struct {
+	Kind      string  `json:"kind"`
+	Request   Request `json:"request"`
+	URL       string  `json:"url,omitempty"`
+	FullPage  bool    `json:"fullPage,omitempty"`
+	TimeoutMs int64   `json:"timeoutMs"`
+}
+
+// browserReply is the response a child writes to stdout.
+type browserReply struct {
+	Result     *Result `json:"result,omitempty"`
+	Screenshot []byte  `json:"screenshot,omitempty"`
+	// Accessibility is the reply to an a11y job.
+	Accessibility *model.AccessibilityReport `json:"accessibility,omitempty"`
+	// Performance is the reply to a performance job.
+	Performance *model.PerformanceReport `json:"performance,omitempty"`
+	Error       string                   `json:"error,omitempty"`
+}
+
+var (
+	browserSlotsOnce sync.Once
+	browserSlots     chan struct{}
+)
+
+// acquireBrowserSlot bounds concurrent child processes across all callers
+// in this process. The limit is fixed by the first caller.
+func acquireBrowserSlot(ctx context.Context, max int) (func(), error) {
+	browserSlotsOnce.Do(func() {
+		if max <= 0 {
+			max = DefaultBrowserMaxProcesses
+		}
+		browserSlots = make(chan struct{}, max)
+	})
+	select {
+	case browserSlots <- struct{}{}:
+		return func() { <-browserSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// maxStderrTail bounds how much child stderr is quoted in errors.
+const maxStderrTail = 512
+
+func runBrowserProcess(ctx context.Context, opts BrowserOptions, job browserJob) (*browserReply, error) {
+	command := opts.Command
+	if len(command) == 0 {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("locate executable for browser process: %w", err)
+		}
+		command = []string{exe, BrowserRenderCommand}
+	}
+
+	release, err := acquireBrowserSlot(ctx, opts.MaxProcesses)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	input, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Give the child a moment to close Chromium after a cancellation
+	// before it is killed.
+	cmd.WaitDelay = 5 * time.Second
+
+	runErr := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	var reply browserReply
+	if err := json.Unmarshal(stdout.Bytes(), &reply); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxStderrTail {
+			msg = msg[len(msg)-maxStderrTail:]
+		}
+		if runErr == nil {
+			runErr = errors.New("invalid reply")
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("%w: %v: %s", ErrBrowserProcessCrashed, runErr, msg)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrBrowserProcessCrashed, runErr)
+	}
+	if reply.Error != "" {
+		return nil, errors.New(reply.Error)
+	}
+	return &reply, nil
+}
+
+// ServeBrowserRender reads one browserJob from r, runs it with the
+// in-process rod engine and writes the reply to w. It is the body of the
+// BrowserRenderCommand child process.
+func ServeBrowserRender(ctx context.Context, r io.Reader, w io.Writer) error {
+	return serveBrowserJob(ctx, r, w, renderBrowserJob)
+}
+
+func serveBrowserJob(ctx context.Context, r io.Reader, w io.Writer, render func(context.Context, browserJob) (*browserReply, error)) error {
+	var job browserJob
+	if err := json.NewDecoder(r).Decode(&job); err != nil {
+		return fmt.Errorf("decode browser job: %w", err)
+	}
+	if job.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(job.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	reply, err := render(ctx, job)
+	if err != nil {
+		reply = &browserReply{Error: err.Error()}
+	}
+	return json.NewEncoder(w).Encode(reply)
+}
+
+func renderBrowserJob(ctx context.Context, job browserJob) (*browserReply, error) {
+	timeout := time.Duration(job.TimeoutMs) * time.Millisecond
+	switch job.Kind {
+	case browserJobScrape:
+		res, err := NewRodScraper(timeout).Scrape(ctx, job.Request)
+		if err != nil {
+			return nil, err
+		}
+		return &browserReply{Result: res}, nil
+	case browserJobScreenshot:
+		shot, err := CaptureScreenshot(ctx, job.URL, timeout, job.FullPage)
+		if err != nil {
+			return nil, err
+		}
+		return &browserReply{Screenshot: shot}, nil
+	case browserJobA11y:
+		report, err := AuditAccessibility(ctx, job.URL, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return &browserReply{Accessibility: report}, nil
+	case browserJobPerformance:
+		report, err := MeasurePerformance(ctx, job.URL, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return &browserReply{Performance: report}, nil
+	default:
+		return nil, fmt.Errorf("unknown browser job kind %q", job.Kind)
+	}
+}
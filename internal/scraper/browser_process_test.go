@@ -0,0 +1,96 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain lets this test binary act as a browser child process. The
+// fake renderer echoes the request instead of starting Chromium.
+func TestMain(m *testing.M) {
+	switch os.Getenv("RAITO_TEST_BROWSER_CHILD") {
+	case "echo":
+		err := serveBrowserJob(context.Background(), os.Stdin, os.Stdout, func(_ context.Context, job browserJob) (*browserReply, error) {
+			if job.Kind == browserJobScreenshot {
+				return &browserReply{Screenshot: []byte("png:" + job.URL)}, nil
+			}
+			if strings.Contains(job.Request.URL, "fail") {
+				return nil, fmt.Errorf("navigation failed")
+			}
+			return &browserReply{Result: &Result{
+				URL:     job.Request.URL,
+				RawHTML: "<p>" + job.Request.Actions[0].Fields["q"] + "</p>",
+				Status:  200,
+				Engine:  "browser",
+			}}, nil
+		})
+		if err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "crash":
+		fmt.Fprintln(os.Stderr, "fatal error: out of memory")
+		os.Exit(2)
+	}
+	os.Exit(m.Run())
+}
+
+func childOptions(t *testing.T, mode string) BrowserOptions {
+	t.Helper()
+	t.Setenv("RAITO_TEST_BROWSER_CHILD", mode)
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	return BrowserOptions{Isolation: BrowserIsolationProcess, Command: []string{exe}}
+}
+
+func TestProcessRodScraper_RoundTrip(t *testing.T) {
+	opts := childOptions(t, "echo")
+	s := NewBrowserScraper(5*time.Second, opts)
+	if _, ok := s.(*ProcessRodScraper); !ok {
+		t.Fatalf("expected ProcessRodScraper, got %T", s)
+	}
+
+	res, err := s.Scrape(context.Background(), Request{
+		URL:     "https://example.com/search",
+		Actions: []Action{{Type: ActionFillForm, Fields: map[string]string{"q": "raito"}, Submit: true}},
+	})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if res.URL != "https://example.com/search" || res.RawHTML != "<p>raito</p>" || res.Engine != "browser" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	if _, err := s.Scrape(context.Background(), Request{URL: "https://example.com/fail"}); err == nil || err.Error() != "navigation failed" {
+		t.Fatalf("expected renderer error to be returned, got %v", err)
+	}
+
+	shot, err := CaptureBrowserScreenshot(context.Background(), opts, "https://example.com", time.Second, true)
+	if err != nil || string(shot) != "png:https://example.com" {
+		t.Fatalf("CaptureBrowserScreenshot = %q, %v", shot, err)
+	}
+}
+
+func TestProcessRodScraper_Crash(t *testing.T) {
+	s := NewBrowserScraper(5*time.Second, childOptions(t, "crash"))
+	_, err := s.Scrape(context.Background(), Request{URL: "https://example.com"})
+	if !errors.Is(err, ErrBrowserProcessCrashed) {
+		t.Fatalf("expected ErrBrowserProcessCrashed, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "out of memory") {
+		t.Fatalf("expected child stderr in error, got %v", err)
+	}
+}
+
+func TestNewBrowserScraper_DefaultsInProcess(t *testing.T) {
+	if _, ok := NewBrowserScraper(time.Second, BrowserOptions{}).(*RodScraper); !ok {
+		t.Fatalf("expected in-process RodScraper by default")
+	}
+}
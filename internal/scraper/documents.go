@@ -0,0 +1,117 @@
+package scraper
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Document kinds accepted by ParseDocument.
+const (
+	DocumentHTML = "html"
+	DocumentPDF  = "pdf"
+	DocumentDOCX = "docx"
+)
+
+// ErrUnsupportedDocument is returned when an upload is not HTML, PDF or DOCX.
+var ErrUnsupportedDocument = errors.New("unsupported document type; expected HTML, PDF, or DOCX")
+
+// ErrDocumentTooLarge is returned when the compressed parts of a PDF or
+// DOCX expand beyond the decompressed size limit.
+var ErrDocumentTooLarge = errors.New("document expands beyond the decompressed size limit")
+
+// DefaultDocumentMaxDecompressedBytes is the decompressed size limit when
+// scraper.documentMaxDecompressedBytes is not set.
+const DefaultDocumentMaxDecompressedBytes int64 = 100 << 20
+
+// DetectDocumentKind identifies an uploaded file from its content, falling
+// back to the declared content type and the file extension. It returns ""
+// when the file is not a supported kind.
+func DetectDocumentKind(filename, contentType string, data []byte) string {
+	head := bytes.TrimLeft(data, " \t\r\n\xef\xbb\xbf")
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return DocumentPDF
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		// DOCX is a zip container; other zip uploads fail in conversion.
+		return DocumentDOCX
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "text/html", "application/xhtml+xml":
+			return DocumentHTML
+		case "application/pdf":
+			return DocumentPDF
+		case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+			return DocumentDOCX
+		}
+	}
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".html", ".htm", ".xhtml":
+		return DocumentHTML
+	case ".pdf":
+		return DocumentPDF
+	case ".docx":
+		return DocumentDOCX
+	}
+
+	lower := bytes.ToLower(head[:min(len(head), 512)])
+	if bytes.HasPrefix(lower, []byte("<!doctype html")) || bytes.HasPrefix(lower, []byte("<html")) {
+		return DocumentHTML
+	}
+	return ""
+}
+
+// documentInfo carries document properties (as opposed to page content)
+// that converters hand back alongside the rendered HTML body.
+type documentInfo struct {
+	Title       string
+	Description string
+}
+
+// ParseDocument converts an uploaded document into a Result using the same
+// HTML pipeline as scraping: PDF and DOCX files are first rendered to
+// simple HTML. sourceURL identifies the document in metadata and is used
+// to resolve relative links; it does not need to be reachable. maxBytes
+// caps how much the compressed parts of a PDF or DOCX may expand to, so a
+// small upload cannot exhaust memory; 0 uses
+// DefaultDocumentMaxDecompressedBytes.
+func ParseDocument(kind, sourceURL string, data []byte, maxBytes int64) (*Result, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultDocumentMaxDecompressedBytes
+	}
+
+	var body string
+	var info documentInfo
+	switch kind {
+	case DocumentHTML:
+		return ResultFromHTML(u, data, 200, "parse"), nil
+	case DocumentPDF:
+		body, info, err = pdfToHTML(data, maxBytes)
+	case DocumentDOCX:
+		body, info, err = docxToHTML(data, maxBytes)
+	default:
+		return nil, ErrUnsupportedDocument
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := ResultFromHTML(u, []byte("<html><body>\n"+body+"</body></html>"), 200, "parse")
+	if title := strings.TrimSpace(info.Title); title != "" {
+		res.Metadata["title"] = title
+	}
+	if desc := strings.TrimSpace(info.Description); desc != "" {
+		res.Metadata["description"] = desc
+	}
+	return res, nil
+}
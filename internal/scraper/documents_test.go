@@ -0,0 +1,201 @@
+package scraper
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func buildDocx(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip create: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("zip write: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseDocument_DOCX(t *testing.T) {
+	const ns = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	data := buildDocx(t, map[string]string{
+		"word/document.xml": `<w:document ` + ns + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Quarterly Report</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Revenue was </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>up</w:t></w:r><w:r><w:t xml:space="preserve"> again. See </w:t></w:r><w:hyperlink r:id="rId5"><w:r><w:t>details</w:t></w:r></w:hyperlink></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>First point</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Second point</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Region</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Total</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>EMEA</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>42</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:p><w:r><w:delText>removed</w:delText></w:r></w:p>
+</w:body></w:document>`,
+		"word/_rels/document.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId5" Type="hyperlink" Target="https://example.com/details" TargetMode="External"/></Relationships>`,
+		"docProps/core.xml": `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Q3 Report</dc:title></cp:coreProperties>`,
+	})
+
+	if kind := DetectDocumentKind("report.docx", "", data); kind != DocumentDOCX {
+		t.Fatalf("expected docx detection, got %q", kind)
+	}
+
+	res, err := ParseDocument(DocumentDOCX, "file:///report.docx", data, 0)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+
+	for _, want := range []string{"# Quarterly Report", "Revenue was **up** again", "[details](https://example.com/details)", "- First point", "- Second point", "EMEA"} {
+		if !strings.Contains(res.Markdown, want) {
+			t.Fatalf("expected markdown to contain %q, got:\n%s", want, res.Markdown)
+		}
+	}
+	if strings.Contains(res.Markdown, "removed") {
+		t.Fatalf("deleted text must not be included:\n%s", res.Markdown)
+	}
+	if res.Metadata["title"] != "Q3 Report" {
+		t.Fatalf("expected title from core properties, got %v", res.Metadata["title"])
+	}
+	if len(res.Links) != 1 || res.Links[0] != "https://example.com/details" {
+		t.Fatalf("expected hyperlink in links, got %v", res.Links)
+	}
+	if tables := ExtractTables(res.HTML); len(tables) != 1 {
+		t.Fatalf("expected one table, got %d", len(tables))
+	}
+}
+
+// buildPDF assembles a PDF with a classic xref table from object bodies
+// (numbered from 1) and a trailer dictionary.
+func buildPDF(objects []string, trailer string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer, xref)
+	return buf.Bytes()
+}
+
+func pdfStreamObject(content string, compress bool) string {
+	if !compress {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+	}
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write([]byte(content))
+	_ = zw.Close()
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", buf.Len(), buf.String())
+}
+
+func TestParseDocument_PDF(t *testing.T) {
+	page1 := "BT /F1 18 Tf 72 720 Td (Annual Summary) Tj ET\n" +
+		"BT /F1 12 Tf 72 680 Td (Sales grew \\(again\\) in) Tj 0 -14 Td [(every)-250(region.)] TJ ET\n" +
+		"BT /F1 12 Tf 72 600 Td (Caf\\351 openings doubled.) Tj ET"
+	// The second page uses a Type0 font whose two-byte codes only decode
+	// through its ToUnicode map.
+	page2 := "BT /F2 12 Tf 72 720 Td <00010002> Tj ET"
+	cmap := "/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+		"1 beginbfchar <0001> <0048> endbfchar\n" +
+		"1 beginbfrange <0002> <0002> <0069> endbfrange\n" +
+		"endcmap CMapName currentdict /CMap defineresource pop end end"
+
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 8 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [7 0 R] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		pdfStreamObject(page1, true),
+		pdfStreamObject(page2, false),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /ToUnicode 9 0 R >>",
+		pdfStreamObject(cmap, true),
+		"<< /Title (Yearly Numbers) >>",
+	}, "<< /Size 11 /Root 1 0 R /Info 10 0 R >>")
+
+	if kind := DetectDocumentKind("upload.bin", "application/octet-stream", data); kind != DocumentPDF {
+		t.Fatalf("expected pdf detection from content, got %q", kind)
+	}
+
+	res, err := ParseDocument(DocumentPDF, "file:///summary.pdf", data, 0)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+
+	md := res.Markdown
+	for _, want := range []string{"Annual Summary", "Sales grew (again) in every region.", "Café openings doubled.", "Hi"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("expected markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "Annual Summary") > strings.Index(md, "Hi") {
+		t.Fatalf("expected pages in document order, got:\n%s", md)
+	}
+	if !strings.Contains(md, "Sales grew (again) in every region.\n\nCafé") {
+		t.Fatalf("expected a paragraph break at the larger vertical gap, got:\n%s", md)
+	}
+	if res.Metadata["title"] != "Yearly Numbers" {
+		t.Fatalf("expected title from document info, got %v", res.Metadata["title"])
+	}
+}
+
+func TestParseDocument_RejectsInvalidInput(t *testing.T) {
+	if _, err := ParseDocument(DocumentPDF, "file:///x.pdf", []byte("not a pdf"), 0); err == nil {
+		t.Fatalf("expected error for invalid pdf")
+	}
+	if _, err := ParseDocument(DocumentDOCX, "file:///x.docx", []byte("PK\x03\x04garbage"), 0); err == nil {
+		t.Fatalf("expected error for invalid docx")
+	}
+	encrypted := buildPDF([]string{"<< /Type /Catalog >>"}, "<< /Root 1 0 R /Encrypt << /Filter /Standard >> >>")
+	if _, err := ParseDocument(DocumentPDF, "file:///x.pdf", encrypted, 0); err != errPDFEncrypted {
+		t.Fatalf("expected encrypted pdf error, got %v", err)
+	}
+	if kind := DetectDocumentKind("notes.txt", "text/plain", []byte("hello")); kind != "" {
+		t.Fatalf("expected unsupported kind, got %q", kind)
+	}
+}
+
+func TestParseDocument_DecompressionLimit(t *testing.T) {
+	const limit = 64 << 10
+	// Both compress to a few kilobytes but expand to 1 MiB.
+	filler := strings.Repeat("a", 1<<20)
+
+	docx := buildDocx(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>` + filler + `</w:t></w:r></w:p></w:body></w:document>`,
+	})
+	if _, err := ParseDocument(DocumentDOCX, "file:///bomb.docx", docx, limit); !errors.Is(err, ErrDocumentTooLarge) {
+		t.Fatalf("expected docx to exceed the limit, got %v", err)
+	}
+
+	pdf := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		pdfStreamObject("BT ("+filler+") Tj ET", true),
+	}, "<< /Size 5 /Root 1 0 R >>")
+	if _, err := ParseDocument(DocumentPDF, "file:///bomb.pdf", pdf, limit); !errors.Is(err, ErrDocumentTooLarge) {
+		t.Fatalf("expected pdf to exceed the limit, got %v", err)
+	}
+
+	// The same documents parse under the default limit.
+	if _, err := ParseDocument(DocumentPDF, "file:///bomb.pdf", pdf, 0); err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+}
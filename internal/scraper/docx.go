@@ -0,0 +1,339 @@
+package scraper
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// docxToHTML converts the main body of a Word document into simple HTML:
+// headings, paragraphs, bulleted/numbered paragraphs, tables, bold/italic
+// runs and hyperlinks. Layout, images, text boxes and tracked deletions
+// are dropped. Each part read from the archive is capped at maxBytes.
+func docxToHTML(data []byte, maxBytes int64) (string, documentInfo, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", documentInfo{}, fmt.Errorf("invalid docx: %w", err)
+	}
+
+	body, err := readZipFile(zr, "word/document.xml", maxBytes)
+	if err != nil {
+		return "", documentInfo{}, fmt.Errorf("invalid docx: %w", err)
+	}
+
+	var rels map[string]string
+	raw, err := readZipFile(zr, "word/_rels/document.xml.rels", maxBytes)
+	if errors.Is(err, ErrDocumentTooLarge) {
+		return "", documentInfo{}, err
+	} else if err == nil {
+		rels = parseDocxRelationships(raw)
+	}
+
+	var props docxCoreProperties
+	raw, err = readZipFile(zr, "docProps/core.xml", maxBytes)
+	if errors.Is(err, ErrDocumentTooLarge) {
+		return "", documentInfo{}, err
+	} else if err == nil {
+		_ = xml.Unmarshal(raw, &props)
+	}
+
+	content, err := convertDocxBody(body, rels)
+	if err != nil {
+		return "", documentInfo{}, fmt.Errorf("invalid docx: %w", err)
+	}
+
+	return content, documentInfo{Title: props.Title, Description: props.Description}, nil
+}
+
+// readZipFile reads the archive member called name, failing with
+// ErrDocumentTooLarge when it expands beyond maxBytes.
+func readZipFile(zr *zip.Reader, name string, maxBytes int64) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, maxBytes+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > maxBytes {
+			return nil, ErrDocumentTooLarge
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("missing %s", name)
+}
+
+type docxCoreProperties struct {
+	Title       string `xml:"title"`
+	Description string `xml:"description"`
+}
+
+// parseDocxRelationships maps relationship IDs to external targets so
+// hyperlinks can be resolved.
+func parseDocxRelationships(raw []byte) map[string]string {
+	var doc struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		return nil
+	}
+	out := make(map[string]string, len(doc.Relationships))
+	for _, r := range doc.Relationships {
+		out[r.ID] = r.Target
+	}
+	return out
+}
+
+// docxSkippedElements hold content that is either not part of the visible
+// text flow or would duplicate it (alternate renderings, deleted text).
+var docxSkippedElements = map[string]bool{
+	"drawing":    true,
+	"pict":       true,
+	"Fallback":   true,
+	"delText":    true,
+	"instrText":  true,
+	"footnotePr": true,
+	"endnotePr":  true,
+}
+
+type docxParagraph struct {
+	style  string
+	isList bool
+	buf    strings.Builder
+	run    strings.Builder
+	bold   bool
+	italic bool
+	// linkStart is the buffer offset where the current hyperlink began,
+	// or -1 outside a hyperlink.
+	linkStart int
+	linkHref  string
+}
+
+func convertDocxBody(raw []byte, rels map[string]string) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(raw))
+	var out strings.Builder
+	var para *docxParagraph
+	inList := false
+	tableDepth := 0
+	inRun := false
+	inRunProps := false
+
+	closeList := func() {
+		if inList {
+			out.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			if docxSkippedElements[name] {
+				if err := dec.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			}
+			switch name {
+			case "tbl":
+				closeList()
+				tableDepth++
+				out.WriteString("<table>\n")
+			case "tr":
+				out.WriteString("<tr>")
+			case "tc":
+				out.WriteString("<td>")
+			case "p":
+				para = &docxParagraph{linkStart: -1}
+			case "pStyle":
+				if para != nil {
+					para.style = docxAttr(t, "val")
+				}
+			case "numPr":
+				if para != nil {
+					para.isList = true
+				}
+			case "r":
+				inRun = true
+				if para != nil {
+					para.bold, para.italic = false, false
+					para.run.Reset()
+				}
+			case "rPr":
+				inRunProps = inRun
+			case "b":
+				if para != nil && inRunProps {
+					para.bold = docxToggleOn(t)
+				}
+			case "i":
+				if para != nil && inRunProps {
+					para.italic = docxToggleOn(t)
+				}
+			case "t":
+				if para == nil {
+					continue
+				}
+				var text string
+				if err := dec.DecodeElement(&text, &t); err != nil {
+					return "", err
+				}
+				para.run.WriteString(html.EscapeString(text))
+			case "tab":
+				if para != nil && inRun {
+					para.run.WriteString(" ")
+				}
+			case "br", "cr":
+				if para != nil && inRun {
+					para.run.WriteString("<br>")
+				}
+			case "hyperlink":
+				if para != nil {
+					para.linkStart = para.buf.Len()
+					para.linkHref = rels[docxAttr(t, "id")]
+				}
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "rPr":
+				inRunProps = false
+			case "r":
+				inRun = false
+				if para != nil {
+					para.flushRun()
+				}
+			case "hyperlink":
+				if para != nil {
+					para.closeLink()
+				}
+			case "p":
+				if para == nil {
+					continue
+				}
+				tag, list := docxParagraphTag(para)
+				text := strings.TrimSpace(para.buf.String())
+				para = nil
+				if text == "" {
+					continue
+				}
+				if list && tableDepth == 0 {
+					if !inList {
+						out.WriteString("<ul>\n")
+						inList = true
+					}
+					out.WriteString("<li>" + text + "</li>\n")
+					continue
+				}
+				if tableDepth == 0 {
+					closeList()
+				} else if list {
+					tag = "p"
+				}
+				out.WriteString("<" + tag + ">" + text + "</" + tag + ">\n")
+			case "tc":
+				out.WriteString("</td>")
+			case "tr":
+				out.WriteString("</tr>\n")
+			case "tbl":
+				tableDepth--
+				out.WriteString("</table>\n")
+			}
+		}
+	}
+	closeList()
+
+	return out.String(), nil
+}
+
+func (p *docxParagraph) flushRun() {
+	text := p.run.String()
+	p.run.Reset()
+	if strings.TrimSpace(text) == "" {
+		p.buf.WriteString(text)
+		return
+	}
+	if p.italic {
+		text = "<em>" + text + "</em>"
+	}
+	if p.bold {
+		text = "<strong>" + text + "</strong>"
+	}
+	p.buf.WriteString(text)
+}
+
+func (p *docxParagraph) closeLink() {
+	if p.linkStart < 0 {
+		return
+	}
+	if p.linkHref != "" {
+		current := p.buf.String()
+		inner := current[p.linkStart:]
+		p.buf.Reset()
+		p.buf.WriteString(current[:p.linkStart])
+		p.buf.WriteString(`<a href="` + html.EscapeString(p.linkHref) + `">` + inner + "</a>")
+	}
+	p.linkStart = -1
+	p.linkHref = ""
+}
+
+// docxParagraphTag maps a paragraph style to an HTML tag and reports
+// whether the paragraph is a list item.
+func docxParagraphTag(p *docxParagraph) (string, bool) {
+	style := strings.ToLower(strings.ReplaceAll(p.style, " ", ""))
+	switch {
+	case style == "title":
+		return "h1", false
+	case strings.HasPrefix(style, "heading") && len(style) == len("heading")+1:
+		level := style[len(style)-1]
+		if level >= '1' && level <= '6' {
+			return "h" + string(level), false
+		}
+	}
+	if p.isList || strings.HasPrefix(style, "listparagraph") || strings.HasPrefix(style, "listbullet") || strings.HasPrefix(style, "listnumber") {
+		return "li", true
+	}
+	return "p", false
+}
+
+func docxAttr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// docxToggleOn reports whether an on/off property such as <w:b/> is set;
+// an absent val means on.
+func docxToggleOn(el xml.StartElement) bool {
+	switch docxAttr(el, "val") {
+	case "0", "false", "off", "none":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,203 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"raito/internal/dnscache"
+)
+
+// ErrBlockedAddress is returned when a fetch would connect to a loopback,
+// private, link-local or otherwise non-public address.
+var ErrBlockedAddress = errors.New("destination address is not publicly routable")
+
+// DefaultFetchMaxBytes caps raw fetch bodies when scraper.fetchMaxBytes
+// is not set.
+const DefaultFetchMaxBytes int64 = 10 << 20
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// net.IP.IsPrivate does not cover.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// blockedIP reports whether ip must not be dialed by a public fetch.
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// NewPublicHTTPClient returns an http.Client that refuses to connect to
+// non-public addresses unless allowPrivate is set. The check runs on the
+// resolved address at dial time, so it also covers redirects and DNS
+// names that point at internal hosts. Host names are looked up through
+// dnscache.Default. Environment proxies (HTTP_PROXY, HTTPS_PROXY,
+// NO_PROXY) are honoured; see newPublicHTTPClient.
+func NewPublicHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	return newPublicHTTPClient(timeout, allowPrivate, http.ProxyFromEnvironment)
+}
+
+// newPublicHTTPClient is NewPublicHTTPClient with the proxy lookup made
+// explicit. A proxied request is never dialed by the client, so its
+// target host is checked with CheckPublicHost before it is handed to the
+// proxy, and the proxy itself, which usually sits on a private network,
+// may be dialed.
+func newPublicHTTPClient(timeout time.Duration, allowPrivate bool, proxy func(*http.Request) (*url.URL, error)) *http.Client {
+	var proxies sync.Map // proxy host:port -> struct{}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	direct := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blockedIP(ip) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxy(req)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+		if !allowPrivate {
+			if err := CheckPublicHost(req.Context(), req.URL.Hostname()); err != nil {
+				return nil, err
+			}
+		}
+		proxies.Store(proxyAddr(proxyURL), struct{}{})
+		return proxyURL, nil
+	}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if _, ok := proxies.Load(address); ok {
+			return dnscache.Default().DialContext(ctx, direct, network, address)
+		}
+		return dnscache.Default().DialContext(ctx, dialer, network, address)
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// proxyAddr returns the host:port the transport dials for proxyURL.
+func proxyAddr(proxyURL *url.URL) string {
+	if port := proxyURL.Port(); port != "" {
+		return net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	port := "80"
+	switch proxyURL.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
+
+// CheckPublicHost returns an error wrapping ErrBlockedAddress when host
+// is, or resolves to, an address NewPublicHTTPClient refuses to dial. It
+// lets callers reject a stored URL up front; the dial-time check still
+// applies when the URL is used.
+func CheckPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedIP(ip) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+		}
+		return nil
+	}
+	ips, err := dnscache.Default().LookupIP(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if blockedIP(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, ip)
+		}
+	}
+	return nil
+}
+
+// FetchRequest describes a raw fetch with no content processing.
+type FetchRequest struct {
+	URL       string
+	Headers   map[string]string
+	UserAgent string
+	// MaxBytes caps the body read; larger bodies are truncated.
+	MaxBytes int64
+}
+
+// FetchResult is the unprocessed upstream response.
+type FetchResult struct {
+	// URL is the final URL after redirects.
+	URL       string
+	Status    int
+	Header    http.Header
+	Body      []byte
+	Truncated bool
+}
+
+// Fetch performs a GET and returns the response as-is. Only http and
+// https URLs are accepted.
+func Fetch(ctx context.Context, client *http.Client, req FetchRequest) (*FetchResult, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	if req.UserAgent != "" && httpReq.Header.Get("User-Agent") == "" {
+		httpReq.Header.Set("User-Agent", req.UserAgent)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	maxBytes := req.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultFetchMaxBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	truncated := int64(len(body)) > maxBytes
+	if truncated {
+		body = body[:maxBytes]
+	}
+
+	return &FetchResult{
+		URL:       resp.Request.URL.String(),
+		Status:    resp.StatusCode,
+		Header:    resp.Header,
+		Body:      body,
+		Truncated: truncated,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBlockedIP(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"172.16.0.1":      true,
+		"192.168.1.1":     true,
+		"169.254.169.254": true,
+		"100.64.0.1":      true,
+		"0.0.0.0":         true,
+		"::1":             true,
+		"fe80::1":         true,
+		"fd00::1":         true,
+		"93.184.216.34":   false,
+		"2606:4700::1111": false,
+	}
+	for addr, want := range cases {
+		if got := blockedIP(net.ParseIP(addr)); got != want {
+			t.Fatalf("blockedIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestCheckPublicHost(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "169.254.169.254", "::1", "10.0.0.5"} {
+		if err := CheckPublicHost(context.Background(), host); !errors.Is(err, ErrBlockedAddress) {
+			t.Fatalf("CheckPublicHost(%s) = %v, want ErrBlockedAddress", host, err)
+		}
+	}
+	if err := CheckPublicHost(context.Background(), "93.184.216.34"); err != nil {
+		t.Fatalf("CheckPublicHost(public) = %v", err)
+	}
+}
+
+// Test that proxied requests go through the proxy, even though the proxy
+// is on a private address, while private targets are still refused.
+func TestPublicHTTPClient_Proxy(t *testing.T) {
+	var got []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.String())
+		_, _ = io.WriteString(w, "proxied")
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := newPublicHTTPClient(5*time.Second, false, http.ProxyURL(proxyURL))
+
+	resp, err := client.Get("http://93.184.216.34/page")
+	if err != nil {
+		t.Fatalf("public target through proxy: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "proxied" || len(got) != 1 || got[0] != "http://93.184.216.34/page" {
+		t.Fatalf("expected request through the proxy, got body %q and requests %v", body, got)
+	}
+
+	if _, err := client.Get("http://169.254.169.254/latest/meta-data"); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("private target through proxy: got %v, want ErrBlockedAddress", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("private target must not reach the proxy, got %v", got)
+	}
+}
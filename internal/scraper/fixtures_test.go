@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"raito/internal/fixtures"
+)
+
+func TestHTTPScraper_FixtureGolden(t *testing.T) {
+	site := fixtures.NewSite(t)
+	s := NewHTTPScraper(5 * time.Second)
+
+	for _, page := range []string{"index", "about", "blog/post-1", "noindex", "lang/fr"} {
+		t.Run(page, func(t *testing.T) {
+			path := "/" + page
+			if page == "index" {
+				path = "/"
+			}
+			res, err := s.Scrape(context.Background(), Request{URL: site.URL + path})
+			if err != nil {
+				t.Fatalf("Scrape: %v", err)
+			}
+			site.Golden(t, "scrape/"+page+".md", res.Markdown)
+			// The rest of the result, apart from the HTML it was built from.
+			site.GoldenJSON(t, "scrape/"+page+".json", map[string]any{
+				"url":          res.URL,
+				"status":       res.Status,
+				"engine":       res.Engine,
+				"metadata":     res.Metadata,
+				"links":        res.Links,
+				"linkMetadata": res.LinkMetadata,
+				"headers":      res.Headers,
+			})
+		})
+	}
+}
+
+func TestHTTPScraper_FixtureRedirectsAndErrors(t *testing.T) {
+	site := fixtures.NewSite(t)
+	s := NewHTTPScraper(5 * time.Second)
+
+	res, err := s.Scrape(context.Background(), Request{URL: site.URL + "/redirect-chain"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if res.Status != 200 || res.Metadata["title"] != "About the Fixture Site" {
+		t.Fatalf("expected the redirects to end at /about, got %v (%d)", res.Metadata["title"], res.Status)
+	}
+
+	res, err = s.Scrape(context.Background(), Request{URL: site.URL + "/missing"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if res.Status != 404 {
+		t.Fatalf("expected 404, got %d", res.Status)
+	}
+
+	_, err = NewHTTPScraper(100*time.Millisecond).Scrape(context.Background(), Request{URL: site.URL + "/slow?ms=2000"})
+	if err == nil {
+		t.Fatal("expected the slow page to time out")
+	}
+}
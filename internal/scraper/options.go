@@ -16,11 +16,17 @@ type LocationOptions struct {
 // low-level scraper.Request in a consistent way across handlers and
 // workers.
 type RequestOptions struct {
-	URL       string
-	Headers   map[string]string
-	TimeoutMs int
-	UserAgent string
-	Location  *LocationOptions
+	URL         string
+	Headers     map[string]string
+	AuthHeaders map[string]string
+	TimeoutMs   int
+	UserAgent   string
+	Location    *LocationOptions
+
+	Method      string
+	Body        string
+	ContentType string
+	Actions     []Action
 }
 
 // BuildRequestFromOptions builds a scraper.Request from higher-level
@@ -47,9 +53,14 @@ func BuildRequestFromOptions(opts RequestOptions) Request {
 	}
 
 	return Request{
-		URL:       opts.URL,
-		Headers:   headers,
-		Timeout:   timeout,
-		UserAgent: opts.UserAgent,
+		URL:         opts.URL,
+		Headers:     headers,
+		AuthHeaders: opts.AuthHeaders,
+		Timeout:     timeout,
+		UserAgent:   opts.UserAgent,
+		Method:      opts.Method,
+		Body:        opts.Body,
+		ContentType: opts.ContentType,
+		Actions:     opts.Actions,
 	}
 }
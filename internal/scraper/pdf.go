@@ -0,0 +1,908 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// This file implements a small, dependency-free PDF text extractor. It
+// understands classic and compressed (object stream) cross references,
+// Flate/ASCII85/ASCIIHex filters, page trees and ToUnicode CMaps, which
+// covers text produced by common office tools and browsers. Scanned
+// documents (images only) and encrypted files yield no text.
+
+// errPDFEncrypted is returned for password-protected documents.
+var errPDFEncrypted = errors.New("encrypted PDFs are not supported")
+
+type pdfName string
+
+type pdfKeyword string
+
+type pdfRef struct {
+	num, gen int
+}
+
+type pdfDict map[pdfName]any
+
+type pdfStream struct {
+	dict pdfDict
+	raw  []byte
+}
+
+type pdfDocument struct {
+	objects map[int]any
+	trailer pdfDict
+	// budget is how many more bytes Flate streams may decompress to. err
+	// is set to ErrDocumentTooLarge once it runs out.
+	budget int64
+	err    error
+}
+
+// pdfToHTML extracts the text of every page and returns it as HTML, one
+// <p> per detected paragraph. Decompressed streams are capped at maxBytes
+// in total.
+func pdfToHTML(data []byte, maxBytes int64) (string, documentInfo, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return "", documentInfo{}, errors.New("invalid pdf: missing header")
+	}
+
+	doc := parsePDFDocument(data, maxBytes)
+	if doc.err != nil {
+		return "", documentInfo{}, doc.err
+	}
+	if _, ok := doc.trailer["Encrypt"]; ok {
+		return "", documentInfo{}, errPDFEncrypted
+	}
+
+	pages := doc.pages()
+	var body strings.Builder
+	for _, page := range pages {
+		text := doc.pageText(page)
+		if doc.err != nil {
+			return "", documentInfo{}, doc.err
+		}
+		for _, para := range strings.Split(text, "\n\n") {
+			lines := strings.Fields(strings.ReplaceAll(para, "\n", " "))
+			if len(lines) == 0 {
+				continue
+			}
+			body.WriteString("<p>" + html.EscapeString(strings.Join(lines, " ")) + "</p>\n")
+		}
+	}
+
+	var info documentInfo
+	if dict, ok := doc.resolve(doc.trailer["Info"]).(pdfDict); ok {
+		info.Title = pdfTextString(doc.resolve(dict["Title"]))
+		info.Description = pdfTextString(doc.resolve(dict["Subject"]))
+	}
+
+	return body.String(), info, nil
+}
+
+var (
+	pdfObjectHeader  = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfTrailerHeader = regexp.MustCompile(`trailer\s*<<`)
+)
+
+// parsePDFDocument scans the file for indirect objects rather than
+// trusting xref offsets, which makes it tolerant of slightly damaged
+// files. Later definitions win, matching incremental-update semantics.
+func parsePDFDocument(data []byte, maxBytes int64) *pdfDocument {
+	doc := &pdfDocument{objects: make(map[int]any), trailer: pdfDict{}, budget: maxBytes}
+
+	pos := 0
+	for pos < len(data) {
+		loc := pdfObjectHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		p := &pdfParser{b: data, pos: pos + loc[1]}
+		obj := p.parseObject()
+		if dict, ok := obj.(pdfDict); ok {
+			p.skipSpace()
+			if p.hasPrefix("stream") {
+				raw, end := pdfStreamBody(data, p.pos+len("stream"), dict)
+				obj = &pdfStream{dict: dict, raw: raw}
+				p.pos = end
+			}
+		}
+		doc.objects[num] = obj
+		pos = p.pos
+	}
+
+	// Classic trailers; the last one describes the newest revision.
+	for _, idx := range pdfTrailerHeader.FindAllIndex(data, -1) {
+		p := &pdfParser{b: data, pos: idx[0] + len("trailer")}
+		if dict, ok := p.parseObject().(pdfDict); ok {
+			for k, v := range dict {
+				doc.trailer[k] = v
+			}
+		}
+	}
+
+	// Objects packed into object streams, and cross-reference streams
+	// which double as trailers in PDF 1.5+ files.
+	nums := make([]int, 0, len(doc.objects))
+	for num := range doc.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		stream, ok := doc.objects[num].(*pdfStream)
+		if !ok {
+			continue
+		}
+		switch stream.dict["Type"] {
+		case pdfName("XRef"):
+			for _, key := range []pdfName{"Root", "Info", "Encrypt"} {
+				if v, ok := stream.dict[key]; ok {
+					doc.trailer[key] = v
+				}
+			}
+		case pdfName("ObjStm"):
+			doc.loadObjectStream(stream)
+		}
+	}
+
+	return doc
+}
+
+// pdfStreamBody returns the bytes between the stream keyword and
+// endstream, preferring a direct /Length when it is consistent.
+func pdfStreamBody(data []byte, start int, dict pdfDict) ([]byte, int) {
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+	if n, ok := pdfInt(dict["Length"]); ok && n >= 0 && start+n <= len(data) {
+		rest := bytes.TrimLeft(data[start+n:], " \t\r\n")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return data[start : start+n], len(data) - len(rest) + len("endstream")
+		}
+	}
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		return data[start:], len(data)
+	}
+	raw := bytes.TrimRight(data[start:start+end], "\r\n")
+	return raw, start + end + len("endstream")
+}
+
+func (d *pdfDocument) loadObjectStream(stream *pdfStream) {
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	n, _ := pdfInt(stream.dict["N"])
+	first, _ := pdfInt(stream.dict["First"])
+	if first > len(data) {
+		return
+	}
+	header := &pdfParser{b: data[:first]}
+	for i := 0; i < n; i++ {
+		num, ok1 := pdfInt(header.parseObject())
+		off, ok2 := pdfInt(header.parseObject())
+		if !ok1 || !ok2 || first+off > len(data) {
+			return
+		}
+		if _, exists := d.objects[num]; exists {
+			continue
+		}
+		p := &pdfParser{b: data, pos: first + off}
+		d.objects[num] = p.parseObject()
+	}
+}
+
+func (d *pdfDocument) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = d.objects[ref.num]
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(v any) pdfDict {
+	switch t := d.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.dict
+	}
+	return nil
+}
+
+// pdfPage is a page dictionary with its inherited resources.
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages walks the page tree in document order. Files without a usable
+// catalog fall back to every page object in object-number order.
+func (d *pdfDocument) pages() []pdfPage {
+	var out []pdfPage
+	var walk func(node pdfDict, resources pdfDict, depth int)
+	walk = func(node pdfDict, resources pdfDict, depth int) {
+		if node == nil || depth > 64 {
+			return
+		}
+		if r := d.dict(node["Resources"]); r != nil {
+			resources = r
+		}
+		if node["Type"] == pdfName("Page") || node["Kids"] == nil {
+			out = append(out, pdfPage{dict: node, resources: resources})
+			return
+		}
+		kids, _ := d.resolve(node["Kids"]).([]any)
+		for _, kid := range kids {
+			walk(d.dict(kid), resources, depth+1)
+		}
+	}
+
+	if catalog := d.dict(d.trailer["Root"]); catalog != nil {
+		walk(d.dict(catalog["Pages"]), nil, 0)
+	}
+	if len(out) > 0 {
+		return out
+	}
+
+	nums := make([]int, 0)
+	for num, obj := range d.objects {
+		if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		dict := d.objects[num].(pdfDict)
+		out = append(out, pdfPage{dict: dict, resources: d.dict(dict["Resources"])})
+	}
+	return out
+}
+
+// pdfFont decodes shown strings for one font resource.
+type pdfFont struct {
+	toUnicode map[uint32]string
+	codeLen   int
+}
+
+func (d *pdfDocument) font(v any) *pdfFont {
+	dict := d.dict(v)
+	f := &pdfFont{codeLen: 1}
+	if dict == nil {
+		return f
+	}
+	if dict["Subtype"] == pdfName("Type0") {
+		f.codeLen = 2
+	}
+	if stream, ok := d.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := d.decodeStream(stream); err == nil {
+			f.toUnicode, f.codeLen = parseToUnicodeCMap(data, f.codeLen)
+		}
+	}
+	return f
+}
+
+func (f *pdfFont) decode(s []byte) string {
+	var b strings.Builder
+	step := f.codeLen
+	if step < 1 {
+		step = 1
+	}
+	for i := 0; i+step <= len(s); i += step {
+		var code uint32
+		for _, c := range s[i : i+step] {
+			code = code<<8 | uint32(c)
+		}
+		if text, ok := f.toUnicode[code]; ok {
+			b.WriteString(text)
+			continue
+		}
+		if step == 1 {
+			b.WriteRune(pdfWinAnsiRune(s[i]))
+		}
+	}
+	return b.String()
+}
+
+// pdfWinAnsiRune maps a single-byte code using WinAnsiEncoding, the
+// encoding most simple fonts without a ToUnicode map use.
+func pdfWinAnsiRune(c byte) rune {
+	switch c {
+	case 0x80:
+		return '€'
+	case 0x85:
+		return '…'
+	case 0x91:
+		return '‘'
+	case 0x92:
+		return '’'
+	case 0x93:
+		return '“'
+	case 0x94:
+		return '”'
+	case 0x95:
+		return '•'
+	case 0x96:
+		return '–'
+	case 0x97:
+		return '—'
+	}
+	if c < 0x20 && c != '\t' {
+		return ' '
+	}
+	return rune(c)
+}
+
+// parseToUnicodeCMap reads bfchar/bfrange mappings and the code width
+// declared by the codespace range.
+func parseToUnicodeCMap(data []byte, codeLen int) (map[uint32]string, int) {
+	out := make(map[uint32]string)
+	p := &pdfParser{b: data}
+	var operands []any
+	section := ""
+	for {
+		obj := p.parseObject()
+		if obj == nil && p.pos >= len(data) {
+			break
+		}
+		kw, ok := obj.(pdfKeyword)
+		if !ok {
+			if section != "" {
+				operands = append(operands, obj)
+			}
+			continue
+		}
+		switch kw {
+		case "begincodespacerange", "beginbfchar", "beginbfrange":
+			section = string(kw)
+			operands = operands[:0]
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if lo, ok := operands[0].([]byte); ok && len(lo) > 0 {
+					codeLen = len(lo)
+				}
+			}
+			section = ""
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					out[pdfCode(src)] = pdfUTF16(dst)
+				}
+			}
+			section = ""
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				start, end := pdfCode(lo), pdfCode(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					base := []rune(pdfUTF16(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for code := start; code <= end; code++ {
+						r := append([]rune{}, base...)
+						r[len(r)-1] += rune(code - start)
+						out[code] = string(r)
+					}
+				case []any:
+					for j, item := range dst {
+						if s, ok := item.([]byte); ok && start+uint32(j) <= end {
+							out[start+uint32(j)] = pdfUTF16(s)
+						}
+					}
+				}
+			}
+			section = ""
+		}
+	}
+	return out, codeLen
+}
+
+func pdfCode(b []byte) uint32 {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code
+}
+
+func pdfUTF16(b []byte) string {
+	if len(b)%2 != 0 {
+		return string(b)
+	}
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfTextString decodes document-level strings such as /Title, which are
+// either UTF-16BE with a byte order mark or PDFDocEncoding.
+func pdfTextString(v any) string {
+	b, ok := v.([]byte)
+	if !ok {
+		return ""
+	}
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		return pdfUTF16(b[2:])
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = pdfWinAnsiRune(c)
+	}
+	return string(runes)
+}
+
+// pageText runs the page's content streams through a minimal text state
+// machine: text-showing operators emit strings, positioning operators
+// emit line breaks, and large vertical gaps become paragraph breaks.
+func (d *pdfDocument) pageText(page pdfPage) string {
+	var content []byte
+	switch c := d.resolve(page.dict["Contents"]).(type) {
+	case *pdfStream:
+		content, _ = d.decodeStream(c)
+	case []any:
+		for _, part := range c {
+			if s, ok := d.resolve(part).(*pdfStream); ok {
+				if data, err := d.decodeStream(s); err == nil {
+					content = append(content, data...)
+					content = append(content, '\n')
+				}
+			}
+		}
+	}
+	if len(content) == 0 {
+		return ""
+	}
+
+	fonts := map[pdfName]*pdfFont{}
+	fontResources := d.dict(page.resources["Font"])
+	current := &pdfFont{codeLen: 1}
+
+	var out strings.Builder
+	var lineY, lastGap, leading float64
+	haveY := false
+
+	newline := func(y float64) {
+		if !haveY {
+			lineY, haveY = y, true
+			return
+		}
+		gap := math.Abs(lineY - y)
+		lineY = y
+		if gap < 0.01 {
+			return
+		}
+		if lastGap > 0 && gap > lastGap*1.6 {
+			out.WriteString("\n\n")
+		} else {
+			out.WriteString("\n")
+		}
+		if lastGap == 0 || gap < lastGap*1.6 {
+			lastGap = gap
+		}
+	}
+
+	p := &pdfParser{b: content}
+	var operands []any
+	for p.pos < len(content) {
+		obj := p.parseObject()
+		kw, ok := obj.(pdfKeyword)
+		if !ok {
+			if obj == nil && p.pos >= len(content) {
+				break
+			}
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch kw {
+		case "BI":
+			// Inline images carry binary data up to the EI operator.
+			if end := bytes.Index(content[p.pos:], []byte("EI")); end >= 0 {
+				p.pos += end + 2
+			}
+		case "Tf":
+			if len(operands) >= 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					f, cached := fonts[name]
+					if !cached {
+						var ref any
+						if fontResources != nil {
+							ref = fontResources[name]
+						}
+						f = d.font(ref)
+						fonts[name] = f
+					}
+					current = f
+				}
+			}
+		case "TL":
+			if len(operands) == 1 {
+				leading, _ = pdfFloat(operands[0])
+			}
+		case "Td", "TD":
+			if len(operands) == 2 {
+				ty, _ := pdfFloat(operands[1])
+				tx, _ := pdfFloat(operands[0])
+				if kw == "TD" {
+					leading = -ty
+				}
+				if ty != 0 {
+					newline(lineY + ty)
+				} else if tx > 0 {
+					out.WriteString(" ")
+				}
+			}
+		case "Tm":
+			if len(operands) == 6 {
+				y, _ := pdfFloat(operands[5])
+				newline(y)
+			}
+		case "T*":
+			newline(lineY - leading)
+		case "Tj":
+			if len(operands) >= 1 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					out.WriteString(current.decode(s))
+				}
+			}
+		case "'", "\"":
+			newline(lineY - leading)
+			if len(operands) >= 1 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					out.WriteString(current.decode(s))
+				}
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				if arr, ok := operands[len(operands)-1].([]any); ok {
+					for _, item := range arr {
+						switch v := item.(type) {
+						case []byte:
+							out.WriteString(current.decode(v))
+						default:
+							// Large negative kerning is how most producers
+							// encode inter-word spacing.
+							if n, ok := pdfFloat(v); ok && n < -200 {
+								out.WriteString(" ")
+							}
+						}
+					}
+				}
+			}
+		case "ET":
+			out.WriteString(" ")
+		}
+		operands = operands[:0]
+	}
+
+	return strings.TrimSpace(out.String())
+}
+
+// decodeStream applies the stream's filters. Image codecs are not
+// supported and return an error, as does running out of the document's
+// decompression budget.
+func (d *pdfDocument) decodeStream(s *pdfStream) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	var filters []any
+	switch f := s.dict["Filter"].(type) {
+	case pdfName:
+		filters = []any{f}
+	case []any:
+		filters = f
+	}
+	data := s.raw
+	for _, f := range filters {
+		name, _ := f.(pdfName)
+		switch name {
+		case "FlateDecode", "Fl":
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			out, err := io.ReadAll(io.LimitReader(zr, d.budget+1))
+			if int64(len(out)) > d.budget {
+				d.err = ErrDocumentTooLarge
+				return nil, d.err
+			}
+			d.budget -= int64(len(out))
+			if err != nil && len(out) == 0 {
+				return nil, err
+			}
+			data = out
+		case "ASCII85Decode", "A85":
+			trimmed := bytes.TrimSpace(data)
+			trimmed = bytes.TrimPrefix(trimmed, []byte("<~"))
+			trimmed = bytes.TrimSuffix(trimmed, []byte("~>"))
+			out := make([]byte, len(trimmed))
+			n, _, err := ascii85.Decode(out, trimmed, true)
+			if err != nil {
+				return nil, err
+			}
+			data = out[:n]
+		case "ASCIIHexDecode", "AHx":
+			data = pdfHexBytes(bytes.TrimSuffix(bytes.TrimSpace(data), []byte(">")))
+		default:
+			return nil, fmt.Errorf("unsupported pdf filter %q", name)
+		}
+	}
+	return data, nil
+}
+
+func pdfHexBytes(b []byte) []byte {
+	digits := make([]byte, 0, len(b))
+	for _, c := range b {
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	_, _ = hex.Decode(out, digits)
+	return out
+}
+
+func pdfInt(v any) (int, bool) {
+	f, ok := pdfFloat(v)
+	return int(f), ok
+}
+
+func pdfFloat(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+// pdfParser tokenizes PDF objects. Strings decode to []byte, arrays to
+// []any, dictionaries to pdfDict and numbers to float64; bare words that
+// are not true/false/null come back as pdfKeyword (content operators).
+type pdfParser struct {
+	b   []byte
+	pos int
+}
+
+func pdfIsSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func pdfIsDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (p *pdfParser) hasPrefix(s string) bool {
+	return bytes.HasPrefix(p.b[p.pos:], []byte(s))
+}
+
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.b) {
+		c := p.b[p.pos]
+		if pdfIsSpace(c) {
+			p.pos++
+			continue
+		}
+		if c == '%' {
+			for p.pos < len(p.b) && p.b[p.pos] != '\n' && p.b[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+func (p *pdfParser) parseObject() any {
+	p.skipSpace()
+	if p.pos >= len(p.b) {
+		return nil
+	}
+	c := p.b[p.pos]
+	switch {
+	case c == '/':
+		return p.parseName()
+	case c == '(':
+		return p.parseLiteralString()
+	case p.hasPrefix("<<"):
+		p.pos += 2
+		dict := pdfDict{}
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.b) {
+				return dict
+			}
+			if p.hasPrefix(">>") {
+				p.pos += 2
+				return dict
+			}
+			key, ok := p.parseObject().(pdfName)
+			if !ok {
+				continue
+			}
+			dict[key] = p.parseObject()
+		}
+	case c == '<':
+		p.pos++
+		end := bytes.IndexByte(p.b[p.pos:], '>')
+		if end < 0 {
+			end = len(p.b) - p.pos
+		}
+		s := pdfHexBytes(p.b[p.pos : p.pos+end])
+		p.pos += end + 1
+		return s
+	case c == '[':
+		p.pos++
+		arr := []any{}
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.b) {
+				return arr
+			}
+			if p.b[p.pos] == ']' {
+				p.pos++
+				return arr
+			}
+			arr = append(arr, p.parseObject())
+		}
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumberOrRef()
+	case c == ')' || c == '>' || c == ']' || c == '{' || c == '}':
+		p.pos++
+		return pdfKeyword(string(c))
+	}
+
+	start := p.pos
+	for p.pos < len(p.b) && !pdfIsSpace(p.b[p.pos]) && !pdfIsDelimiter(p.b[p.pos]) {
+		p.pos++
+	}
+	word := string(p.b[start:p.pos])
+	switch word {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	return pdfKeyword(word)
+}
+
+func (p *pdfParser) parseName() pdfName {
+	p.pos++
+	var b []byte
+	for p.pos < len(p.b) && !pdfIsSpace(p.b[p.pos]) && !pdfIsDelimiter(p.b[p.pos]) {
+		c := p.b[p.pos]
+		if c == '#' && p.pos+2 < len(p.b) {
+			if v, err := strconv.ParseUint(string(p.b[p.pos+1:p.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				p.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		p.pos++
+	}
+	return pdfName(b)
+}
+
+func (p *pdfParser) parseLiteralString() []byte {
+	p.pos++
+	var out []byte
+	depth := 1
+	for p.pos < len(p.b) {
+		c := p.b[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if p.pos >= len(p.b) {
+				return out
+			}
+			e := p.b[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if p.pos < len(p.b) && p.b[p.pos] == '\n' {
+					p.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.b) && p.b[p.pos] >= '0' && p.b[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.b[p.pos]-'0')
+						p.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (p *pdfParser) parseNumber() (float64, bool) {
+	start := p.pos
+	for p.pos < len(p.b) && strings.IndexByte("+-.0123456789", p.b[p.pos]) >= 0 {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(string(p.b[start:p.pos]), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// parseNumberOrRef reads a number and, when it is followed by
+// "<gen> R", an indirect reference instead.
+func (p *pdfParser) parseNumberOrRef() any {
+	n, ok := p.parseNumber()
+	if !ok {
+		return pdfKeyword("")
+	}
+	save := p.pos
+	p.skipSpace()
+	if p.pos < len(p.b) && p.b[p.pos] >= '0' && p.b[p.pos] <= '9' {
+		gen, ok := p.parseNumber()
+		p.skipSpace()
+		if ok && p.pos < len(p.b) && p.b[p.pos] == 'R' &&
+			(p.pos+1 == len(p.b) || pdfIsSpace(p.b[p.pos+1]) || pdfIsDelimiter(p.b[p.pos+1])) {
+			p.pos++
+			return pdfRef{num: int(n), gen: int(gen)}
+		}
+	}
+	p.pos = save
+	return n
+}
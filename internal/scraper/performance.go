@@ -0,0 +1,50 @@
+package scraper
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"time"
+
+	"raito/internal/model"
+)
+
+// performanceScript reads the page's navigation, paint, and resource
+// timing entries once it has loaded.
+//
+//go:embed performance.js
+var performanceScript string
+
+// MeasurePerformance renders targetURL with rod in a fresh browser, so
+// nothing is cached, and reports its timings and page weight.
+func MeasurePerformance(ctx context.Context, targetURL string, timeout time.Duration) (*model.PerformanceReport, error) {
+	out, err := evalPageScript(ctx, targetURL, timeout, performanceScript)
+	if err != nil {
+		return nil, err
+	}
+	var report model.PerformanceReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		return nil, err
+	}
+	if report.Resources.ByType == nil {
+		report.Resources.ByType = map[string]model.PerformanceResourceStat{}
+	}
+	return &report, nil
+}
+
+// MeasureBrowserPerformance is MeasurePerformance honoring the configured
+// isolation mode.
+func MeasureBrowserPerformance(ctx context.Context, opts BrowserOptions, targetURL string, timeout time.Duration) (*model.PerformanceReport, error) {
+	if !opts.isolated() {
+		return MeasurePerformance(ctx, targetURL, timeout)
+	}
+	reply, err := runBrowserProcess(ctx, opts, browserJob{
+		Kind:      browserJobPerformance,
+		URL:       targetURL,
+		TimeoutMs: timeout.Milliseconds(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Performance, nil
+}
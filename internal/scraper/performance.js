@@ -0,0 +1,103 @@
+async () => {
+  const round = (v) => (v > 0 ? Math.round(v * 10) / 10 : 0);
+  const nav = performance.getEntriesByType('navigation')[0] || {};
+  const paints = {};
+  for (const p of performance.getEntriesByType('paint')) {
+    paints[p.name] = p.startTime;
+  }
+
+  // Largest contentful paint is only reported to observers; buffered
+  // entries cover paints that happened before the audit started.
+  let lcp = 0;
+  let lcpSource = '';
+  try {
+    lcp = await new Promise((resolve) => {
+      let last = 0;
+      const po = new PerformanceObserver((list) => {
+        for (const e of list.getEntries()) {
+          last = e.renderTime || e.loadTime || e.startTime;
+        }
+      });
+      po.observe({ type: 'largest-contentful-paint', buffered: true });
+      setTimeout(() => {
+        po.disconnect();
+        resolve(last);
+      }, 200);
+    });
+    if (lcp) lcpSource = 'observer';
+  } catch (e) {
+    lcp = 0;
+  }
+
+  const category = (r) => {
+    const path = (r.name.split('?')[0] || '').toLowerCase();
+    switch (r.initiatorType) {
+      case 'script':
+        return 'script';
+      case 'img':
+      case 'image':
+        return 'image';
+      case 'css':
+        return /\.(woff2?|ttf|otf|eot)$/.test(path) ? 'font' : path.endsWith('.css') ? 'stylesheet' : 'image';
+      case 'xmlhttprequest':
+      case 'fetch':
+      case 'beacon':
+        return 'xhr';
+      case 'video':
+      case 'audio':
+        return 'media';
+    }
+    if (path.endsWith('.css')) return 'stylesheet';
+    if (path.endsWith('.js') || path.endsWith('.mjs')) return 'script';
+    if (/\.(woff2?|ttf|otf|eot)$/.test(path)) return 'font';
+    if (/\.(png|jpe?g|gif|webp|avif|svg|ico)$/.test(path)) return 'image';
+    return 'other';
+  };
+
+  const byType = {};
+  let count = 0;
+  let transfer = 0;
+  let decoded = 0;
+  let largestImage = null;
+  for (const r of performance.getEntriesByType('resource')) {
+    const type = category(r);
+    const t = byType[type] || (byType[type] = { count: 0, transferBytes: 0, decodedBytes: 0 });
+    t.count++;
+    t.transferBytes += r.transferSize || 0;
+    t.decodedBytes += r.decodedBodySize || 0;
+    count++;
+    transfer += r.transferSize || 0;
+    decoded += r.decodedBodySize || 0;
+    if (type === 'image' && (!largestImage || (r.decodedBodySize || 0) > (largestImage.decodedBodySize || 0))) {
+      largestImage = r;
+    }
+  }
+
+  // Without an observer entry, estimate LCP as the later of first
+  // contentful paint and the largest image finishing its download.
+  if (!lcp) {
+    lcp = Math.max(paints['first-contentful-paint'] || 0, largestImage ? largestImage.responseEnd : 0);
+    if (lcp) lcpSource = 'estimate';
+  }
+
+  const documentBytes = nav.transferSize || 0;
+  return JSON.stringify({
+    timings: {
+      dnsMs: round(nav.domainLookupEnd - nav.domainLookupStart),
+      connectMs: round(nav.connectEnd - nav.connectStart),
+      tlsMs: nav.secureConnectionStart > 0 ? round(nav.connectEnd - nav.secureConnectionStart) : 0,
+      ttfbMs: round(nav.responseStart - (nav.startTime || 0)),
+      downloadMs: round(nav.responseEnd - nav.responseStart),
+      domInteractiveMs: round(nav.domInteractive),
+      domContentLoadedMs: round(nav.domContentLoadedEventEnd),
+      loadMs: round(nav.loadEventEnd || nav.loadEventStart),
+      firstPaintMs: round(paints['first-paint'] || 0),
+      firstContentfulPaintMs: round(paints['first-contentful-paint'] || 0),
+      largestContentfulPaintMs: round(lcp),
+    },
+    largestContentfulPaintSource: lcpSource,
+    document: { transferBytes: documentBytes, decodedBytes: nav.decodedBodySize || 0 },
+    resources: { count, transferBytes: transfer, decodedBytes: decoded, byType },
+    pageWeightBytes: documentBytes + transfer,
+  });
+}
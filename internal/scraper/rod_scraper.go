@@ -2,6 +2,8 @@ package scraper
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
@@ -11,6 +13,8 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
+
+	"raito/internal/metrics"
 )
 
 // RodScraper uses a real browser (via rod) to render JS-heavy pages
@@ -28,6 +32,10 @@ func NewRodScraper(timeout time.Duration) *RodScraper {
 }
 
 func (r *RodScraper) Scrape(ctx context.Context, req Request) (*Result, error) {
+	if req.Method != "" && !strings.EqualFold(req.Method, http.MethodGet) {
+		return nil, fmt.Errorf("browser engine only loads pages with GET; use a %s action to submit forms", ActionFillForm)
+	}
+
 	u, err := url.Parse(req.URL)
 	if err != nil {
 		return nil, err
@@ -36,13 +44,17 @@ func (r *RodScraper) Scrape(ctx context.Context, req Request) (*Result, error) {
 		u.Scheme = "http"
 	}
 
+	metrics.JobRuntimeFrom(ctx).SetCurrentURL(u.String())
+	started := time.Now()
+	defer func() { metrics.JobRuntimeFrom(ctx).AddBrowserTime(time.Since(started)) }()
+
 	browser, err := newLocalRodBrowser(ctx, r.Timeout)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = browser.Close() }()
 
-	page, err := browser.Page(proto.TargetCreateTarget{URL: u.String()})
+	page, err := openRodPage(browser, u.String(), req.UserAgent, req.DiscoverRoutes)
 	if err != nil {
 		return nil, err
 	}
@@ -52,10 +64,31 @@ func (r *RodScraper) Scrape(ctx context.Context, req Request) (*Result, error) {
 		return nil, err
 	}
 
+	if len(req.Actions) > 0 {
+		if err := runRodActions(page, req.Actions); err != nil {
+			return nil, err
+		}
+		// A submitted form usually lands on a different URL.
+		if info, err := page.Info(); err == nil {
+			if final, err := url.Parse(info.URL); err == nil && final.Scheme != "" {
+				u = final
+			}
+		}
+	}
+
+	var routes []string
+	if req.DiscoverRoutes {
+		routes, err = collectRodRoutes(page)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	htmlStr, err := page.HTML()
 	if err != nil {
 		return nil, err
 	}
+	metrics.JobRuntimeFrom(ctx).AddPage(int64(len(htmlStr)))
 
 	// First, attempt HTML -> Markdown conversion (CommonMark-enabled)
 	converter := htmlmd.NewConverter(u.Hostname(), true, nil)
@@ -78,6 +111,7 @@ func (r *RodScraper) Scrape(ctx context.Context, req Request) (*Result, error) {
 				"statusCode": 200,
 				"sourceURL":  u.String(),
 			},
+			Routes: routes,
 		}, nil
 	}
 
@@ -158,9 +192,168 @@ func (r *RodScraper) Scrape(ctx context.Context, req Request) (*Result, error) {
 		Metadata: metadata,
 		Status:   200,
 		Engine:   "browser",
+		Routes:   routes,
 	}, nil
 }
 
+// routeSettleTimeout bounds how long route discovery waits for a
+// client-side router to finish rendering after the load event.
+const routeSettleTimeout = 3 * time.Second
+
+// recordRoutesJS runs before any page script and records every URL the
+// page navigates to through the History API or hash changes.
+const recordRoutesJS = `(() => {
+	const routes = window.__raitoRoutes = new Set();
+	const record = (u) => {
+		try { if (u != null && u !== '') routes.add(new URL(String(u), location.href).href); } catch (e) {}
+	};
+	for (const name of ['pushState', 'replaceState']) {
+		const original = history[name];
+		history[name] = function (state, title, url) {
+			record(url);
+			return original.apply(this, arguments);
+		};
+	}
+	addEventListener('popstate', () => record(location.href));
+	addEventListener('hashchange', () => record(location.href));
+})();`
+
+// collectRoutesJS returns the recorded routes plus the links and router
+// links present in the rendered DOM, as absolute URLs.
+const collectRoutesJS = `() => {
+	const routes = new Set(window.__raitoRoutes || []);
+	const add = (u) => {
+		try { if (u) routes.add(new URL(u, location.href).href); } catch (e) {}
+	};
+	add(location.href);
+	const attrs = ['href', 'routerlink', 'ng-reflect-router-link', 'data-href', 'data-route'];
+	document.querySelectorAll('a[href], [routerlink], [ng-reflect-router-link], [data-href], [data-route]').forEach((el) => {
+		for (const attr of attrs) {
+			if (el.hasAttribute(attr)) { add(el.getAttribute(attr)); break; }
+		}
+	});
+	return Array.from(routes);
+}`
+
+// openRodPage opens targetURL in a new page, sending userAgent when it is
+// set. With discoverRoutes the History API is instrumented before the
+// page's own scripts run.
+func openRodPage(browser *rod.Browser, targetURL, userAgent string, discoverRoutes bool) (*rod.Page, error) {
+	if !discoverRoutes && userAgent == "" {
+		return browser.Page(proto.TargetCreateTarget{URL: targetURL})
+	}
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, err
+	}
+	if userAgent != "" {
+		if err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{UserAgent: userAgent}); err != nil {
+			_ = page.Close()
+			return nil, err
+		}
+	}
+	if discoverRoutes {
+		if _, err := page.EvalOnNewDocument(recordRoutesJS); err != nil {
+			_ = page.Close()
+			return nil, err
+		}
+	}
+	if err := page.Navigate(targetURL); err != nil {
+		_ = page.Close()
+		return nil, err
+	}
+	return page, nil
+}
+
+// collectRodRoutes waits briefly for the client-side router to settle and
+// returns the routes the page navigated to or links to.
+func collectRodRoutes(page *rod.Page) ([]string, error) {
+	// Routers often render after the load event; a page that never
+	// settles is captured as it is.
+	_ = page.Timeout(routeSettleTimeout).WaitDOMStable(300*time.Millisecond, 0)
+
+	res, err := page.Eval(collectRoutesJS)
+	if err != nil {
+		return nil, fmt.Errorf("discover routes: %w", err)
+	}
+	values := res.Value.Arr()
+	routes := make([]string, 0, len(values))
+	for _, v := range values {
+		if r := v.Str(); r != "" {
+			routes = append(routes, r)
+		}
+	}
+	return routes, nil
+}
+
+// fillFormJS sets each named field on the form (this) and fires the
+// input/change events frameworks listen for. It returns the names that
+// matched no element so missing fields are reported instead of ignored.
+const fillFormJS = `(fields) => {
+	const missing = [];
+	for (const [name, value] of Object.entries(fields)) {
+		const els = this.querySelectorAll('[name="' + CSS.escape(name) + '"]');
+		if (els.length === 0) { missing.push(name); continue; }
+		for (const el of els) {
+			if (el.type === 'checkbox' || el.type === 'radio') {
+				el.checked = el.value === value || (el.type === 'checkbox' && value === 'true');
+			} else {
+				el.value = value;
+			}
+			el.dispatchEvent(new Event('input', { bubbles: true }));
+			el.dispatchEvent(new Event('change', { bubbles: true }));
+		}
+	}
+	return missing;
+}`
+
+// runRodActions performs the requested browser actions in order.
+func runRodActions(page *rod.Page, actions []Action) error {
+	for i, action := range actions {
+		if action.Type != ActionFillForm {
+			return fmt.Errorf("action %d: unsupported action type %q", i, action.Type)
+		}
+
+		selector := action.Selector
+		if selector == "" {
+			selector = "form"
+		}
+		form, err := page.Element(selector)
+		if err != nil {
+			return fmt.Errorf("action %d: form %q not found: %w", i, selector, err)
+		}
+
+		fields := action.Fields
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		res, err := form.Eval(fillFormJS, fields)
+		if err != nil {
+			return fmt.Errorf("action %d: fill form: %w", i, err)
+		}
+		if missing := res.Value.Arr(); len(missing) > 0 {
+			names := make([]string, 0, len(missing))
+			for _, m := range missing {
+				names = append(names, m.Str())
+			}
+			return fmt.Errorf("action %d: no form fields named %s", i, strings.Join(names, ", "))
+		}
+
+		if !action.Submit {
+			continue
+		}
+		wait := page.WaitNavigation(proto.PageLifecycleEventNameLoad)
+		if _, err := form.Eval(`() => this.requestSubmit ? this.requestSubmit() : this.submit()`); err != nil {
+			return fmt.Errorf("action %d: submit form: %w", i, err)
+		}
+		wait()
+		if err := page.WaitLoad(); err != nil {
+			return fmt.Errorf("action %d: wait for results: %w", i, err)
+		}
+	}
+	return nil
+}
+
 // CaptureScreenshot opens a browser page with rod and returns a screenshot
 // of the given URL as raw image bytes. It always uses a local headless
 // browser instance and is intended for use by the HTTP layer when the
@@ -197,6 +390,41 @@ func CaptureScreenshot(ctx context.Context, targetURL string, timeout time.Durat
 	return data, nil
 }
 
+// evalPageScript loads targetURL in a local headless browser, waits for
+// the load event, and returns the string result of script, a JavaScript
+// function expression that may return a promise.
+func evalPageScript(ctx context.Context, targetURL string, timeout time.Duration, script string) (string, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+	}
+
+	browser, err := newLocalRodBrowser(ctx, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = browser.Close() }()
+
+	page, err := browser.Page(proto.TargetCreateTarget{URL: u.String()})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = page.Close() }()
+
+	if err := page.WaitLoad(); err != nil {
+		return "", err
+	}
+
+	res, err := page.Eval(script)
+	if err != nil {
+		return "", err
+	}
+	return res.Value.Str(), nil
+}
+
 // newLocalRodBrowser launches a local Chromium instance inside this container
 // using Rod's launcher and connects to it.
 func newLocalRodBrowser(ctx context.Context, timeout time.Duration) (*rod.Browser, error) {
@@ -3,6 +3,7 @@ package scraper
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
@@ -11,6 +12,9 @@ import (
 
 	htmlmd "github.com/JohannesKaufmann/html-to-markdown"
 	"github.com/PuerkitoBio/goquery"
+
+	"raito/internal/dnscache"
+	"raito/internal/metrics"
 )
 
 // Request represents a simplified scrape request used by the scraper package.
@@ -19,8 +23,38 @@ type Request struct {
 	Headers   map[string]string
 	Timeout   time.Duration
 	UserAgent string
+	// AuthHeaders are credentials for the requested host. They are sent
+	// like Headers but dropped when a redirect leaves that host, which
+	// net/http only does by itself for Authorization and Cookie.
+	AuthHeaders map[string]string
+	// Method, Body and ContentType let the HTTP scraper submit a request
+	// body, e.g. to POST a search form. Method defaults to GET.
+	Method      string
+	Body        string
+	ContentType string
+	// Actions run in the browser after the page loads (browser engine only).
+	Actions []Action
+	// DiscoverRoutes records client-side routes the page navigates to or
+	// links to after rendering and returns them in Result.Routes (browser
+	// engine only).
+	DiscoverRoutes bool
+}
+
+// Action is a browser interaction performed before the page is captured.
+type Action struct {
+	// Type is the action kind; only ActionFillForm is supported.
+	Type string
+	// Selector identifies the form (default "form").
+	Selector string
+	// Fields maps input names to the values to enter.
+	Fields map[string]string
+	// Submit submits the form and waits for the resulting page.
+	Submit bool
 }
 
+// ActionFillForm fills a form's fields and optionally submits it.
+const ActionFillForm = "fillForm"
+
 // LinkMetadata captures additional information about an outbound link discovered during scraping.
 type LinkMetadata struct {
 	URL  string
@@ -39,6 +73,17 @@ type Result struct {
 	Metadata     map[string]any
 	Status       int
 	Engine       string
+	// ETag and LastModified echo the response validators so callers can
+	// issue conditional requests later.
+	ETag         string
+	LastModified string
+	// Headers holds the response headers named in CapturedHeaders that
+	// were present, keyed by lower-case name. Only the HTTP engine sets it.
+	Headers map[string]string
+	// Routes are the client-side routes found when Request.DiscoverRoutes
+	// is set: history.pushState/replaceState targets, hash routes, and
+	// router links in the rendered DOM.
+	Routes []string
 }
 
 // Scraper defines the interface for URL scrapers.
@@ -53,7 +98,7 @@ type HTTPScraper struct {
 
 func NewHTTPScraper(timeout time.Duration) *HTTPScraper {
 	return &HTTPScraper{
-		client: &http.Client{Timeout: timeout},
+		client: &http.Client{Timeout: timeout, Transport: dnscache.Transport()},
 	}
 }
 
@@ -67,19 +112,46 @@ func (s *HTTPScraper) Scrape(ctx context.Context, req Request) (*Result, error)
 		u.Scheme = "http"
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
+	metrics.JobRuntimeFrom(ctx).SetCurrentURL(u.String())
 
+	if req.Body != "" {
+		contentType := req.ContentType
+		if contentType == "" {
+			contentType = "application/x-www-form-urlencoded"
+		}
+		httpReq.Header.Set("Content-Type", contentType)
+	}
 	for k, v := range req.Headers {
 		httpReq.Header.Set(k, v)
 	}
+	for k, v := range req.AuthHeaders {
+		httpReq.Header.Set(k, v)
+	}
 	if req.UserAgent != "" {
 		httpReq.Header.Set("User-Agent", req.UserAgent)
 	}
 
-	resp, err := s.client.Do(httpReq)
+	client := s.client
+	if len(req.AuthHeaders) > 0 {
+		c := *s.client
+		c.CheckRedirect = stripAuthOnRedirect(req.AuthHeaders)
+		client = &c
+	}
+
+	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +161,74 @@ func (s *HTTPScraper) Scrape(ctx context.Context, req Request) (*Result, error)
 	if err != nil {
 		return nil, err
 	}
+	metrics.JobRuntimeFrom(ctx).AddPage(int64(len(bodyBytes)))
+
+	res := ResultFromHTML(u, bodyBytes, resp.StatusCode, "http")
+	res.ETag = resp.Header.Get("ETag")
+	res.LastModified = resp.Header.Get("Last-Modified")
+	res.Headers = captureHeaders(resp.Header)
+	return res, nil
+}
+
+// stripAuthOnRedirect returns a CheckRedirect function that removes the
+// auth headers from redirects to another host, and otherwise follows the
+// default policy of at most 10 redirects.
+func stripAuthOnRedirect(auth map[string]string) func(*http.Request, []*http.Request) error {
+	return func(r *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if !strings.EqualFold(r.URL.Hostname(), via[0].URL.Hostname()) {
+			for k := range auth {
+				r.Header.Del(k)
+			}
+		}
+		return nil
+	}
+}
+
+// CapturedHeaders are the response headers kept with scraped documents:
+// security headers, caching headers, and server identification.
+var CapturedHeaders = []string{
+	"content-security-policy",
+	"content-security-policy-report-only",
+	"strict-transport-security",
+	"x-frame-options",
+	"x-content-type-options",
+	"referrer-policy",
+	"permissions-policy",
+	"cross-origin-opener-policy",
+	"cross-origin-resource-policy",
+	"cross-origin-embedder-policy",
+	"cache-control",
+	"expires",
+	"age",
+	"vary",
+	"content-type",
+	"server",
+	"x-powered-by",
+	"x-robots-tag",
+}
+
+// captureHeaders returns the CapturedHeaders present in h. Repeated
+// headers are joined with ", ".
+func captureHeaders(h http.Header) map[string]string {
+	out := map[string]string{}
+	for _, name := range CapturedHeaders {
+		if values := h.Values(name); len(values) > 0 {
+			out[name] = strings.Join(values, ", ")
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
 
+// ResultFromHTML converts an HTML document into a Result: markdown,
+// outbound links, and page metadata. Relative links resolve against u.
+// It is shared by the HTTP scraper and uploads that never hit the network.
+func ResultFromHTML(u *url.URL, bodyBytes []byte, status int, engine string) *Result {
 	htmlStr := string(bodyBytes)
 
 	// First, attempt HTML -> Markdown conversion (CommonMark-enabled)
@@ -107,13 +246,13 @@ func (s *HTTPScraper) Scrape(ctx context.Context, req Request) (*Result, error)
 			Markdown: markdown,
 			HTML:     htmlStr,
 			RawHTML:  htmlStr,
-			Status:   resp.StatusCode,
-			Engine:   "http",
+			Status:   status,
+			Engine:   engine,
 			Metadata: map[string]any{
-				"statusCode": resp.StatusCode,
+				"statusCode": status,
 				"sourceURL":  u.String(),
 			},
-		}, nil
+		}
 	}
 
 	// Extract links (with basic metadata) and fallback plain-text markdown if converter failed
@@ -189,7 +328,7 @@ func (s *HTTPScraper) Scrape(ctx context.Context, req Request) (*Result, error)
 		"ogUrl":         ogURL,
 		"ogImage":       ogImage,
 		"ogSiteName":    ogSiteName,
-		"statusCode":    resp.StatusCode,
+		"statusCode":    status,
 		"sourceURL":     sourceURL,
 	}
 
@@ -201,9 +340,9 @@ func (s *HTTPScraper) Scrape(ctx context.Context, req Request) (*Result, error)
 		Links:        links,
 		LinkMetadata: linkMeta,
 		Metadata:     metadata,
-		Status:       resp.StatusCode,
-		Engine:       "http",
-	}, nil
+		Status:       status,
+		Engine:       engine,
+	}
 }
 
 // ExtractImages parses the given HTML string and extracts absolute HTTP(S) image URLs.
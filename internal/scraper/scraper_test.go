@@ -0,0 +1,98 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPScraper_PostBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = io.WriteString(w, "<html><body><p>"+r.Method+" "+r.Header.Get("Content-Type")+" "+string(body)+"</p></body></html>")
+	}))
+	defer srv.Close()
+
+	s := NewHTTPScraper(5 * time.Second)
+	res, err := s.Scrape(context.Background(), Request{URL: srv.URL, Method: "post", Body: "q=raito"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if !strings.Contains(res.Markdown, "POST application/x-www-form-urlencoded q=raito") {
+		t.Fatalf("expected form POST with default content type, got %q", res.Markdown)
+	}
+
+	res, err = s.Scrape(context.Background(), Request{URL: srv.URL, Method: "POST", Body: `{"q":1}`, ContentType: "application/json"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if !strings.Contains(res.Markdown, "POST application/json") {
+		t.Fatalf("expected explicit content type, got %q", res.Markdown)
+	}
+}
+
+func TestHTTPScraper_CapturesHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Set("X-Request-Id", "abc")
+		_, _ = io.WriteString(w, "<html><body><p>ok</p></body></html>")
+	}))
+	defer srv.Close()
+
+	res, err := NewHTTPScraper(5*time.Second).Scrape(context.Background(), Request{URL: srv.URL})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if res.Headers["content-security-policy"] != "default-src 'self'" {
+		t.Fatalf("expected CSP to be captured, got %v", res.Headers)
+	}
+	if res.Headers["vary"] != "Accept, Accept-Encoding" {
+		t.Fatalf("expected repeated headers to be joined, got %q", res.Headers["vary"])
+	}
+	if _, ok := res.Headers["x-request-id"]; ok {
+		t.Fatalf("unexpected uncaptured header in %v", res.Headers)
+	}
+}
+
+func TestHTTPScraper_AuthHeadersStayOnHost(t *testing.T) {
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<html><body><p>key="+r.Header.Get("X-Api-Key")+"</p></body></html>")
+	}))
+	defer echo.Close()
+	// The same server under another host name.
+	otherHost := strings.Replace(echo.URL, "127.0.0.1", "localhost", 1)
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/same" {
+			http.Redirect(w, r, echo.URL, http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, otherHost, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	s := NewHTTPScraper(5 * time.Second)
+	auth := map[string]string{"X-Api-Key": "secret"}
+
+	res, err := s.Scrape(context.Background(), Request{URL: redirect.URL + "/same", AuthHeaders: auth})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if !strings.Contains(res.Markdown, "key=secret") {
+		t.Fatalf("expected auth header kept on the same host, got %q", res.Markdown)
+	}
+
+	res, err = s.Scrape(context.Background(), Request{URL: redirect.URL + "/other", AuthHeaders: auth})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if strings.Contains(res.Markdown, "secret") {
+		t.Fatalf("expected auth header dropped on another host, got %q", res.Markdown)
+	}
+}
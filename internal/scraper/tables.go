@@ -0,0 +1,152 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"raito/internal/model"
+)
+
+// maxTableColspan bounds how many columns a single cell may expand to so a
+// malformed colspan cannot blow up the output.
+const maxTableColspan = 50
+
+// ExtractTables parses every data table in htmlStr into headers and rows.
+// Tables marked role="presentation" (layout tables) and tables without any
+// cell text are skipped. Nested tables are reported separately and do not
+// contribute rows to their parent.
+func ExtractTables(htmlStr string) []model.Table {
+	if htmlStr == "" {
+		return nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlStr))
+	if err != nil {
+		return nil
+	}
+
+	tables := make([]model.Table, 0)
+	doc.Find("table").Each(func(_ int, tbl *goquery.Selection) {
+		role := strings.ToLower(strings.TrimSpace(tbl.AttrOr("role", "")))
+		if role == "presentation" || role == "none" {
+			return
+		}
+
+		t := model.Table{
+			Caption: cellText(tbl.ChildrenFiltered("caption").First()),
+			Rows:    make([][]string, 0),
+		}
+
+		// The HTML parser wraps bare <tr> elements in <tbody>, so direct
+		// section children cover every row of this table but not nested ones.
+		headerFromThead := false
+		tbl.ChildrenFiltered("thead").ChildrenFiltered("tr").Each(func(i int, tr *goquery.Selection) {
+			if i == 0 {
+				t.Headers = tableRowCells(tr)
+				headerFromThead = true
+				return
+			}
+			t.Rows = append(t.Rows, tableRowCells(tr))
+		})
+
+		tbl.ChildrenFiltered("tbody, tfoot").ChildrenFiltered("tr").Each(func(_ int, tr *goquery.Selection) {
+			cells := tableRowCells(tr)
+			if len(cells) == 0 {
+				return
+			}
+			// Without a <thead>, a leading row made only of <th> cells is
+			// treated as the header row.
+			if !headerFromThead && t.Headers == nil && len(t.Rows) == 0 &&
+				tr.ChildrenFiltered("td").Length() == 0 && tr.ChildrenFiltered("th").Length() > 0 {
+				t.Headers = cells
+				return
+			}
+			t.Rows = append(t.Rows, cells)
+		})
+
+		if len(t.Rows) == 0 && len(t.Headers) == 0 {
+			return
+		}
+		if !tableHasText(t) {
+			return
+		}
+		tables = append(tables, t)
+	})
+
+	if len(tables) == 0 {
+		return nil
+	}
+	return tables
+}
+
+// TableCSV renders a table as CSV with the header row (when present) first.
+func TableCSV(t model.Table) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if len(t.Headers) > 0 {
+		if err := w.Write(t.Headers); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range t.Rows {
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// tableRowCells returns the text of each cell in a row, repeating cells
+// that span several columns so rows stay aligned with the headers.
+func tableRowCells(tr *goquery.Selection) []string {
+	var cells []string
+	tr.ChildrenFiltered("th, td").Each(func(_ int, cell *goquery.Selection) {
+		text := cellText(cell)
+		span, err := strconv.Atoi(strings.TrimSpace(cell.AttrOr("colspan", "1")))
+		if err != nil || span < 1 {
+			span = 1
+		}
+		if span > maxTableColspan {
+			span = maxTableColspan
+		}
+		for i := 0; i < span; i++ {
+			cells = append(cells, text)
+		}
+	})
+	return cells
+}
+
+// cellText returns the whitespace-collapsed text of a cell, ignoring any
+// nested tables.
+func cellText(sel *goquery.Selection) string {
+	if sel.Length() == 0 {
+		return ""
+	}
+	clone := sel.Clone()
+	clone.Find("table").Remove()
+	return strings.Join(strings.Fields(clone.Text()), " ")
+}
+
+func tableHasText(t model.Table) bool {
+	for _, h := range t.Headers {
+		if h != "" {
+			return true
+		}
+	}
+	for _, row := range t.Rows {
+		for _, c := range row {
+			if c != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
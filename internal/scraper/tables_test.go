@@ -0,0 +1,68 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractTables(t *testing.T) {
+	html := `<html><body>
+<table role="presentation"><tr><td>layout</td></tr></table>
+<table>
+  <caption>Plans</caption>
+  <thead><tr><th>Plan</th><th>Price</th><th>Seats</th></tr></thead>
+  <tbody>
+    <tr><td>Free</td><td>$0</td><td>1</td></tr>
+    <tr><td>Team</td><td colspan="2">Contact
+      us</td></tr>
+  </tbody>
+</table>
+<table>
+  <tr><th>Year</th><th>Visitors</th></tr>
+  <tr><td>2024</td><td>1,200<table><tr><td>nested</td></tr></table></td></tr>
+</table>
+</body></html>`
+
+	tables := ExtractTables(html)
+	if len(tables) != 3 {
+		t.Fatalf("expected 3 tables (two top-level, one nested), got %d: %#v", len(tables), tables)
+	}
+
+	plans := tables[0]
+	if plans.Caption != "Plans" {
+		t.Fatalf("unexpected caption %q", plans.Caption)
+	}
+	if !reflect.DeepEqual(plans.Headers, []string{"Plan", "Price", "Seats"}) {
+		t.Fatalf("unexpected headers %#v", plans.Headers)
+	}
+	wantRows := [][]string{{"Free", "$0", "1"}, {"Team", "Contact us", "Contact us"}}
+	if !reflect.DeepEqual(plans.Rows, wantRows) {
+		t.Fatalf("unexpected rows %#v", plans.Rows)
+	}
+
+	stats := tables[1]
+	if !reflect.DeepEqual(stats.Headers, []string{"Year", "Visitors"}) {
+		t.Fatalf("expected leading <th> row as headers, got %#v", stats.Headers)
+	}
+	if !reflect.DeepEqual(stats.Rows, [][]string{{"2024", "1,200"}}) {
+		t.Fatalf("nested table text leaked into parent rows: %#v", stats.Rows)
+	}
+
+	csv, err := TableCSV(plans)
+	if err != nil {
+		t.Fatalf("TableCSV: %v", err)
+	}
+	want := "Plan,Price,Seats\nFree,$0,1\nTeam,Contact us,Contact us\n"
+	if string(csv) != want {
+		t.Fatalf("unexpected csv:\n%s", csv)
+	}
+}
+
+func TestExtractTables_Empty(t *testing.T) {
+	if got := ExtractTables(""); got != nil {
+		t.Fatalf("expected nil for empty html, got %#v", got)
+	}
+	if got := ExtractTables("<p>no tables</p>"); got != nil {
+		t.Fatalf("expected nil without tables, got %#v", got)
+	}
+}
@@ -154,6 +154,66 @@ func GetBrandingFormatConfig(formats []any) (bool, string) {
 	return false, ""
 }
 
+// GetSummaryFormatConfig scans formats for a summary entry and returns
+// whether it was requested along with an optional custom prompt.
+func GetSummaryFormatConfig(formats []any) (bool, string) {
+	for _, f := range formats {
+		switch v := f.(type) {
+		case string:
+			if strings.ToLower(v) == "summary" {
+				return true, ""
+			}
+		case map[string]any:
+			rawType, ok := v["type"].(string)
+			if !ok || strings.ToLower(rawType) != "summary" {
+				continue
+			}
+
+			prompt := ""
+			if p, ok := v["prompt"].(string); ok {
+				prompt = p
+			}
+
+			return true, prompt
+		}
+	}
+
+	return false, ""
+}
+
+// GetClassifyFormatConfig scans formats for a classify entry
+// ({type: "classify", labels: [...]}) and returns whether it was requested
+// along with its candidate labels. Blank and non-string labels are
+// dropped; a bare "classify" string has no labels.
+func GetClassifyFormatConfig(formats []any) (bool, []string) {
+	for _, f := range formats {
+		switch v := f.(type) {
+		case string:
+			if strings.ToLower(v) == "classify" {
+				return true, nil
+			}
+		case map[string]any:
+			rawType, ok := v["type"].(string)
+			if !ok || strings.ToLower(rawType) != "classify" {
+				continue
+			}
+
+			var labels []string
+			if raw, ok := v["labels"].([]any); ok {
+				for _, l := range raw {
+					if s, ok := l.(string); ok && strings.TrimSpace(s) != "" {
+						labels = append(labels, strings.TrimSpace(s))
+					}
+				}
+			}
+
+			return true, labels
+		}
+	}
+
+	return false, nil
+}
+
 // NormalizeBrandingImages prunes nil values from the images sub-object
 // of a branding profile so that fields like favicon and ogImage are
 // omitted rather than returned as explicit nulls.
@@ -39,3 +39,30 @@ func TestFilterLinks(t *testing.T) {
 		t.Fatalf("expected 1 filtered link with maxPerDocument=1, got %d", len(filtered))
 	}
 }
+
+func TestGetSummaryFormatConfig(t *testing.T) {
+	if ok, prompt := GetSummaryFormatConfig([]any{"markdown", "summary"}); !ok || prompt != "" {
+		t.Fatalf("string summary = %v, %q", ok, prompt)
+	}
+	formats := []any{map[string]any{"type": "summary", "prompt": "Three bullet points."}}
+	if ok, prompt := GetSummaryFormatConfig(formats); !ok || prompt != "Three bullet points." {
+		t.Fatalf("object summary = %v, %q", ok, prompt)
+	}
+	if ok, _ := GetSummaryFormatConfig([]any{"markdown"}); ok {
+		t.Fatalf("expected no summary")
+	}
+}
+
+func TestGetClassifyFormatConfig(t *testing.T) {
+	formats := []any{"markdown", map[string]any{"type": "classify", "labels": []any{" Pricing ", "", 3, "Docs"}}}
+	ok, labels := GetClassifyFormatConfig(formats)
+	if !ok || len(labels) != 2 || labels[0] != "Pricing" || labels[1] != "Docs" {
+		t.Fatalf("object classify = %v, %q", ok, labels)
+	}
+	if ok, labels := GetClassifyFormatConfig([]any{"classify"}); !ok || labels != nil {
+		t.Fatalf("string classify = %v, %q", ok, labels)
+	}
+	if ok, _ := GetClassifyFormatConfig([]any{"summary"}); ok {
+		t.Fatalf("expected no classify")
+	}
+}
@@ -0,0 +1,202 @@
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// MaxSiteFilters bounds how many sites and excluded sites a single
+// request may list, since every one of them becomes a query operator.
+const MaxSiteFilters = 20
+
+// category describes a logical result category: results on one of its
+// sites or, for pdf, PDF files.
+type category struct {
+	sites    []string
+	fileType string
+}
+
+var categories = map[string]category{
+	"github": {sites: []string{"github.com"}},
+	"research": {sites: []string{
+		"arxiv.org",
+		"scholar.google.com",
+		"pubmed.ncbi.nlm.nih.gov",
+		"semanticscholar.org",
+		"researchgate.net",
+		"nature.com",
+		"sciencedirect.com",
+		"ieeexplore.ieee.org",
+	}},
+	"pdf": {fileType: "pdf"},
+}
+
+// CategoryNames returns the supported category names in sorted order.
+func CategoryNames() []string {
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeCategory lowercases name and reports whether it is supported.
+func NormalizeCategory(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	_, ok := categories[name]
+	return name, ok
+}
+
+// NormalizeSite reduces a site filter to a bare hostname. Callers may
+// pass "example.com", "https://example.com/docs" or "site:example.com";
+// paths and ports are dropped and a leading "www." is kept as given.
+func NormalizeSite(raw string) (string, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	s = strings.TrimPrefix(s, "site:")
+	if s == "" {
+		return "", fmt.Errorf("empty site")
+	}
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid site %q", raw)
+	}
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	if !strings.Contains(host, ".") || strings.ContainsAny(host, " \"()") {
+		return "", fmt.Errorf("invalid site %q", raw)
+	}
+	return host, nil
+}
+
+// includeSites returns the sites results are restricted to: the
+// request's Sites plus the sites of its Categories.
+func (r *Request) includeSites() []string {
+	sites := append([]string(nil), r.Sites...)
+	for _, name := range r.Categories {
+		sites = append(sites, categories[name].sites...)
+	}
+	return dedupe(sites)
+}
+
+// fileTypes returns the file types required by the request's Categories.
+func (r *Request) fileTypes() []string {
+	var types []string
+	for _, name := range r.Categories {
+		if ft := categories[name].fileType; ft != "" {
+			types = append(types, ft)
+		}
+	}
+	return dedupe(types)
+}
+
+// QueryWithOperators returns the request query with its filters appended
+// as search operators. Sites and categories are alternatives, so they
+// form one OR group; excluded sites always apply:
+//
+//	golang generics (site:go.dev OR site:github.com) -site:reddit.com
+//
+// Providers without native filters send this as the query text.
+func QueryWithOperators(r *Request) string {
+	parts := []string{strings.TrimSpace(r.Query)}
+	var include []string
+	for _, s := range r.includeSites() {
+		include = append(include, "site:"+s)
+	}
+	for _, ft := range r.fileTypes() {
+		include = append(include, "filetype:"+ft)
+	}
+	switch len(include) {
+	case 0:
+	case 1:
+		parts = append(parts, include[0])
+	default:
+		parts = append(parts, "("+strings.Join(include, " OR ")+")")
+	}
+	for _, s := range r.ExcludeSites {
+		parts = append(parts, "-site:"+s)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Allows reports whether rawURL satisfies the request's filters. Engines
+// behind a provider do not all honor operators, so providers re-check
+// every result with Allows.
+func (r *Request) Allows(rawURL string) bool {
+	restricted := len(r.Sites) > 0 || len(r.Categories) > 0
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Hostname() == "" {
+		// Leave malformed URLs to IgnoreInvalidURL handling.
+		return !restricted
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, s := range r.ExcludeSites {
+		if hostMatches(host, s) {
+			return false
+		}
+	}
+	if !restricted || matchSite(host, r.includeSites()) != "" {
+		return true
+	}
+	for _, ft := range r.fileTypes() {
+		if hasFileType(u, ft) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFileType(u *url.URL, fileType string) bool {
+	return strings.HasSuffix(strings.ToLower(u.Path), "."+fileType)
+}
+
+// CategoryOf returns the first requested category rawURL belongs to, or
+// "" when the request has no categories or the URL matches none.
+func (r *Request) CategoryOf(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, name := range r.Categories {
+		c := categories[name]
+		if c.fileType != "" && hasFileType(u, c.fileType) {
+			return name
+		}
+		if len(c.sites) > 0 && matchSite(host, c.sites) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func matchSite(host string, sites []string) string {
+	for _, s := range sites {
+		if hostMatches(host, s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// hostMatches reports whether host is site or one of its subdomains, the
+// same semantics as the site: operator.
+func hostMatches(host, site string) bool {
+	return host == site || strings.HasSuffix(host, "."+site)
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
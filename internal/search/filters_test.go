@@ -0,0 +1,71 @@
+package search
+
+import "testing"
+
+func TestQueryWithOperators(t *testing.T) {
+	req := &Request{
+		Query:        "golang generics",
+		Sites:        []string{"go.dev"},
+		ExcludeSites: []string{"reddit.com"},
+		Categories:   []string{"github", "pdf"},
+	}
+	want := "golang generics (site:go.dev OR site:github.com OR filetype:pdf) -site:reddit.com"
+	if got := QueryWithOperators(req); got != want {
+		t.Fatalf("QueryWithOperators() = %q, want %q", got, want)
+	}
+	if got := QueryWithOperators(&Request{Query: " plain "}); got != "plain" {
+		t.Fatalf("expected an unfiltered query to pass through, got %q", got)
+	}
+}
+
+func TestRequestAllows(t *testing.T) {
+	req := &Request{
+		Sites:        []string{"example.com"},
+		ExcludeSites: []string{"blog.example.com"},
+		Categories:   []string{"pdf"},
+	}
+	cases := map[string]bool{
+		"https://example.com/a":          true,
+		"https://docs.example.com/a":     true,
+		"https://blog.example.com/a":     false,
+		"https://notexample.com/a":       false,
+		"https://other.org/paper.PDF":    true,
+		"https://blog.example.com/x.pdf": false,
+		"":                               false,
+	}
+	for u, want := range cases {
+		if got := req.Allows(u); got != want {
+			t.Fatalf("Allows(%q) = %v, want %v", u, got, want)
+		}
+	}
+	if !(&Request{}).Allows("") {
+		t.Fatalf("expected unfiltered requests to leave empty URLs alone")
+	}
+
+	cat := &Request{Categories: []string{"research", "github"}}
+	if got := cat.CategoryOf("https://www.arxiv.org/abs/1"); got != "research" {
+		t.Fatalf("CategoryOf() = %q, want research", got)
+	}
+	if got := cat.CategoryOf("https://gist.github.com/x"); got != "github" {
+		t.Fatalf("CategoryOf() = %q, want github", got)
+	}
+}
+
+func TestNormalizeSite(t *testing.T) {
+	for raw, want := range map[string]string{
+		"Example.com":                 "example.com",
+		"site:docs.example.com":       "docs.example.com",
+		"https://www.example.com/a?b": "www.example.com",
+		"example.com:8080":            "example.com",
+	} {
+		got, err := NormalizeSite(raw)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeSite(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"", "localhost", "exa mple.com", "(a.com"} {
+		if _, err := NormalizeSite(raw); err == nil {
+			t.Fatalf("expected NormalizeSite(%q) to fail", raw)
+		}
+	}
+}
@@ -23,6 +23,14 @@ type Request struct {
 	TBS              string
 	Timeout          time.Duration
 	IgnoreInvalidURL bool
+
+	// Sites and ExcludeSites restrict results to, or drop results from,
+	// these hostnames and their subdomains. Categories restricts results
+	// to logical categories (see CategoryNames). Values are expected to
+	// be normalized with NormalizeSite and NormalizeCategory.
+	Sites        []string
+	ExcludeSites []string
+	Categories   []string
 }
 
 // Result represents a single search hit from a provider.
@@ -30,6 +38,8 @@ type Result struct {
 	Title       string
 	Description string
 	URL         string
+	// Category is the requested category the result belongs to, if any.
+	Category string
 }
 
 // Results groups provider results per logical source.
@@ -45,6 +55,8 @@ type Results struct {
 // back into the shared Results shape. Providers should:
 //   - respect the Limit and Timeout fields where possible,
 //   - treat IgnoreInvalidURL as a hint for filtering malformed URLs,
+//   - apply Sites, ExcludeSites and Categories, natively or via
+//     QueryWithOperators, and drop results that fail Request.Allows,
 //   - avoid returning sensitive configuration details in errors.
 type Provider interface {
 	Search(ctx context.Context, req *Request) (*Results, error)
@@ -144,7 +156,9 @@ func (p *SearxngProvider) Search(ctx context.Context, req *Request) (*Results, e
 	// API enabled and use the standard `q`, `format`, `categories`, and
 	// `language` parameters. This is intentionally minimal.
 	values := url.Values{}
-	values.Set("q", req.Query)
+	// SearxNG has no native site filters; its engines understand the
+	// site: and filetype: operators.
+	values.Set("q", QueryWithOperators(req))
 	values.Set("format", "json")
 	values.Set("limit", strconv.Itoa(limit))
 
@@ -224,10 +238,16 @@ func (p *SearxngProvider) Search(ctx context.Context, req *Request) (*Results, e
 				continue
 			}
 		}
+		// Not every engine honors the operators, so enforce the filters
+		// on the results as well.
+		if !req.Allows(r.URL) {
+			continue
+		}
 		out.Web = append(out.Web, Result{
 			Title:       r.Title,
 			Description: r.Content,
 			URL:         r.URL,
+			Category:    req.CategoryOf(r.URL),
 		})
 	}
 
@@ -0,0 +1,73 @@
+// Package secrets encrypts tenant-owned secrets at rest with
+// auth.secrets.encryptionKey.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"raito/internal/config"
+)
+
+// ErrNotConfigured is returned when auth.secrets.encryptionKey is empty.
+var ErrNotConfigured = errors.New("tenant secrets are not configured (auth.secrets.encryptionKey is empty)")
+
+// aead derives an AES-256-GCM cipher from the configured encryption key.
+func aead(cfg *config.Config) (cipher.AEAD, error) {
+	if cfg == nil || cfg.Auth.Secrets.EncryptionKey == "" {
+		return nil, ErrNotConfigured
+	}
+	key := sha256.Sum256([]byte(cfg.Auth.Secrets.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Check returns ErrNotConfigured when secrets cannot be sealed or opened.
+func Check(cfg *config.Config) error {
+	_, err := aead(cfg)
+	return err
+}
+
+// Seal encrypts value and returns the nonce followed by the ciphertext.
+// The tenant ID and name are bound as additional data so a stored value
+// cannot be moved to another tenant or name.
+func Seal(cfg *config.Config, tenantID uuid.UUID, name, value string) ([]byte, error) {
+	a, err := aead(cfg)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, a.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return a.Seal(nonce, nonce, []byte(value), additionalData(tenantID, name)), nil
+}
+
+// Open reverses Seal.
+func Open(cfg *config.Config, tenantID uuid.UUID, name string, sealed []byte) (string, error) {
+	a, err := aead(cfg)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < a.NonceSize() {
+		return "", errors.New("stored secret is corrupt")
+	}
+	nonce, ciphertext := sealed[:a.NonceSize()], sealed[a.NonceSize():]
+	plain, err := a.Open(nil, nonce, ciphertext, additionalData(tenantID, name))
+	if err != nil {
+		return "", errors.New("stored secret cannot be decrypted; was auth.secrets.encryptionKey changed?")
+	}
+	return string(plain), nil
+}
+
+func additionalData(tenantID uuid.UUID, name string) []byte {
+	return []byte(tenantID.String() + "/" + name)
+}
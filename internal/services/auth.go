@@ -26,12 +26,19 @@ var (
 	ErrOIDCDisabled         = errors.New("oidc auth is disabled")
 	ErrOIDCEmailNotAllowed  = errors.New("email domain is not allowed for oidc")
 	ErrOIDCEmailMissing     = errors.New("oidc token did not contain an email")
+	ErrPasswordTooShort     = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	ErrPasswordUnchanged    = errors.New("new password must differ from the current password")
 )
 
+// MinPasswordLength is the minimum length accepted when a local user
+// changes their password.
+const MinPasswordLength = 8
+
 // AuthService encapsulates user login flows (local and OIDC).
 type AuthService interface {
 	LoginLocal(ctx context.Context, email, password string) (*LocalAuthResult, error)
 	LoginOIDC(ctx context.Context, code, state string) (*OIDCAuthResult, error)
+	ChangeLocalPassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) (db.User, error)
 }
 
 type LocalAuthResult struct {
@@ -149,6 +156,14 @@ func (s *authService) LoginOIDC(ctx context.Context, code, state string) (*OIDCA
 		if existing.IsDisabled {
 			return nil, ErrUserDisabled
 		}
+		// Users provisioned over SCIM have no subject until their first
+		// login, which links it.
+		if existing.AuthProvider == "oidc" && !existing.AuthSubject.Valid && subject.Valid {
+			if err := q.SetUserAuthSubject(ctx, db.SetUserAuthSubjectParams{ID: existing.ID, AuthSubject: subject}); err != nil {
+				return nil, err
+			}
+			existing.AuthSubject = subject
+		}
 		// User exists but not wired for OIDC with this subject.
 		if existing.AuthProvider != "oidc" || !existing.AuthSubject.Valid || existing.AuthSubject.String != subject.String {
 			return nil, ErrAuthProviderMismatch
@@ -331,3 +346,47 @@ func generatePersonalTenantSlug(email string, id uuid.UUID) string {
 	}
 	return fmt.Sprintf("%s-%s", local, id.String())
 }
+
+// ChangeLocalPassword verifies the current password of a local user and
+// replaces it, clearing any pending forced password change.
+func (s *authService) ChangeLocalPassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) (db.User, error) {
+	q := db.New(s.st.DB)
+
+	user, err := q.GetUserByID(ctx, userID)
+	if err != nil {
+		return db.User{}, err
+	}
+	if user.IsDisabled {
+		return db.User{}, ErrUserDisabled
+	}
+	if user.AuthProvider != "local" {
+		return db.User{}, ErrAuthProviderMismatch
+	}
+	if !user.PasswordHash.Valid {
+		return db.User{}, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(currentPassword)); err != nil {
+		return db.User{}, ErrInvalidCredentials
+	}
+	if len(newPassword) < MinPasswordLength {
+		return db.User{}, ErrPasswordTooShort
+	}
+	if newPassword == currentPassword {
+		return db.User{}, ErrPasswordUnchanged
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return db.User{}, err
+	}
+	nextVersion := int32(1)
+	if user.PasswordVersion.Valid && user.PasswordVersion.Int32 > 0 {
+		nextVersion = user.PasswordVersion.Int32 + 1
+	}
+
+	return q.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		ID:              user.ID,
+		PasswordHash:    sql.NullString{String: string(hash), Valid: true},
+		PasswordVersion: sql.NullInt32{Int32: nextVersion, Valid: true},
+	})
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"mime"
+	"strings"
+
+	"raito/internal/model"
+	"raito/internal/scraper"
+	"raito/internal/scrapeutil"
+	"raito/internal/structured"
+)
+
+// FormatAuto asks Raito to pick the formats worth returning for each page.
+const FormatAuto = "auto"
+
+// minDataTableRows is the number of data rows a table needs before a page
+// is treated as a data page.
+const minDataTableRows = 3
+
+// AutoFormats picks the outputs that carry a page's useful content, based
+// on its content type and structure:
+//
+//   - JSON, XML and CSV bodies: rawHtml, which holds the body unchanged.
+//   - Other non-HTML text: markdown.
+//   - HTML: markdown, plus tables when the page has a data table with at
+//     least three rows and two columns, plus structuredData when it embeds
+//     JSON-LD.
+//
+// contentType may be empty, e.g. for pages rendered by the browser engine;
+// such pages are treated as HTML.
+func AutoFormats(contentType, rawHTML string) []string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+	switch {
+	case mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml":
+	case strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml") || mediaType == "text/csv":
+		return []string{"rawHtml"}
+	case strings.HasPrefix(mediaType, "text/"):
+		return []string{"markdown"}
+	}
+
+	out := []string{"markdown"}
+	for _, t := range scraper.ExtractTables(rawHTML) {
+		if isDataTable(t) {
+			out = append(out, "tables")
+			break
+		}
+	}
+	if structured.HasJSONLD(rawHTML) {
+		out = append(out, "structuredData")
+	}
+	return out
+}
+
+func isDataTable(t model.Table) bool {
+	if len(t.Rows) < minDataTableRows {
+		return false
+	}
+	cols := len(t.Headers)
+	for _, r := range t.Rows {
+		cols = max(cols, len(r))
+	}
+	return cols >= 2
+}
+
+// ExpandAutoFormats replaces the auto format in formats with the formats
+// picked for a page. Other requested formats are kept; picked formats that
+// were already requested are not repeated.
+func ExpandAutoFormats(formats []any, picked []string) []any {
+	out := make([]any, 0, len(formats)+len(picked))
+	for _, f := range formats {
+		if scrapeutil.WantsFormat([]any{f}, FormatAuto) {
+			continue
+		}
+		out = append(out, f)
+	}
+	for _, name := range picked {
+		if !scrapeutil.WantsFormat(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// autoPicked reports whether picked, a page's AutoFormats, holds name.
+func autoPicked(picked []string, name string) bool {
+	for _, p := range picked {
+		if strings.EqualFold(p, name) {
+			return true
+		}
+	}
+	return false
+}
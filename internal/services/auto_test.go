@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"raito/internal/config"
+	"raito/internal/scraper"
+)
+
+const autoDataPage = `<html><head>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"Dataset","name":"Prices"}</script>
+</head><body><h1>Prices</h1><table>
+<tr><th>Item</th><th>Price</th></tr>
+<tr><td>A</td><td>1</td></tr><tr><td>B</td><td>2</td></tr><tr><td>C</td><td>3</td></tr>
+</table></body></html>`
+
+func TestAutoFormats(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		html        string
+		want        []string
+	}{
+		{"article", "text/html; charset=utf-8", "<html><body><article><p>Hello</p></article></body></html>", []string{"markdown"}},
+		{"data page with JSON-LD", "", autoDataPage, []string{"markdown", "tables", "structuredData"}},
+		{"small table", "text/html", "<table><tr><td>a</td><td>b</td></tr></table>", []string{"markdown"}},
+		{"json", "application/json", `{"a":1}`, []string{"rawHtml"}},
+		{"feed", "application/rss+xml", "<rss></rss>", []string{"rawHtml"}},
+		{"plain text", "text/plain", "hello", []string{"markdown"}},
+	}
+	for _, tc := range cases {
+		if got := AutoFormats(tc.contentType, tc.html); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestScrape_AutoFormat(t *testing.T) {
+	res := &scraper.Result{
+		URL:      "https://example.com/prices",
+		Markdown: "# Prices",
+		HTML:     autoDataPage,
+		RawHTML:  autoDataPage,
+		Links:    []string{"https://example.com/"},
+		Headers:  map[string]string{"content-type": "text/html"},
+	}
+	out, err := NewScrapeService(&config.Config{}).Scrape(context.Background(), &ScrapeRequest{Result: res, Formats: []any{"auto", "links"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := out.Document
+	if !reflect.DeepEqual(doc.Metadata.AutoFormats, []string{"markdown", "tables", "structuredData"}) {
+		t.Fatalf("unexpected picked formats %v", doc.Metadata.AutoFormats)
+	}
+	if doc.Markdown == "" || len(doc.Tables) != 1 || len(doc.StructuredData) != 1 || len(doc.Links) != 1 {
+		t.Fatalf("expected markdown, tables, structured data and links, got %+v", doc)
+	}
+	if doc.HTML != "" || doc.RawHTML != "" {
+		t.Fatalf("expected html formats to be left out")
+	}
+}
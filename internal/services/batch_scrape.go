@@ -12,11 +12,18 @@ import (
 // enqueue a batch scrape job. Body is serialized as the job input
 // (typically a BatchScrapeRequest DTO from the HTTP layer).
 type BatchScrapeEnqueueRequest struct {
-	ID         uuid.UUID
-	PrimaryURL string
-	Body       interface{}
-	TenantID   *uuid.UUID
-	APIKeyID   *uuid.UUID
+	ID           uuid.UUID
+	PrimaryURL   string
+	Body         interface{}
+	TenantID     *uuid.UUID
+	APIKeyID     *uuid.UUID
+	UserID       *uuid.UUID
+	Visibility   string
+	CollectionID *uuid.UUID
+	// Pool is the worker pool the job is routed to.
+	Pool string
+	// ZeroRetention deletes the job's data once results are delivered.
+	ZeroRetention bool
 }
 
 // BatchScrapeService hides the details of inserting batch scrape jobs
@@ -37,6 +44,19 @@ func (s *batchScrapeService) Enqueue(ctx context.Context, req *BatchScrapeEnqueu
 	if req == nil {
 		return nil
 	}
-	_, err := s.st.CreateJob(ctx, req.ID, "batch_scrape", req.PrimaryURL, req.Body, false, 10, req.TenantID, req.APIKeyID)
+	_, err := s.st.CreateJob(ctx, store.CreateJobParams{
+		ID:            req.ID,
+		Type:          "batch_scrape",
+		URL:           req.PrimaryURL,
+		Input:         req.Body,
+		Priority:      10,
+		TenantID:      req.TenantID,
+		APIKeyID:      req.APIKeyID,
+		UserID:        req.UserID,
+		Visibility:    req.Visibility,
+		CollectionID:  req.CollectionID,
+		Pool:          req.Pool,
+		ZeroRetention: req.ZeroRetention,
+	})
 	return err
 }
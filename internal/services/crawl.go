@@ -17,6 +17,16 @@ type CrawlEnqueueRequest struct {
 	Body     interface{}
 	TenantID *uuid.UUID
 	APIKeyID *uuid.UUID
+	// UserID and Visibility control who within the tenant can see the job.
+	UserID       *uuid.UUID
+	Visibility   string
+	CollectionID *uuid.UUID
+	// Pool is the worker pool the job is routed to.
+	Pool string
+	// ZeroRetention deletes the job's data once results are delivered.
+	ZeroRetention bool
+	// AppliedOptions records the resolved crawl options for job detail.
+	AppliedOptions any
 }
 
 // CrawlService encapsulates the persistence of crawl jobs so HTTP
@@ -37,6 +47,20 @@ func (s *crawlService) Enqueue(ctx context.Context, req *CrawlEnqueueRequest) er
 	if req == nil {
 		return nil
 	}
-	_, err := s.st.CreateCrawlJob(ctx, req.ID, req.URL, req.Body, req.TenantID, req.APIKeyID)
+	_, err := s.st.CreateJob(ctx, store.CreateJobParams{
+		ID:             req.ID,
+		Type:           "crawl",
+		URL:            req.URL,
+		Input:          req.Body,
+		Priority:       10,
+		TenantID:       req.TenantID,
+		APIKeyID:       req.APIKeyID,
+		UserID:         req.UserID,
+		Visibility:     req.Visibility,
+		CollectionID:   req.CollectionID,
+		Pool:           req.Pool,
+		ZeroRetention:  req.ZeroRetention,
+		AppliedOptions: req.AppliedOptions,
+	})
 	return err
 }
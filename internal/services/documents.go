@@ -2,11 +2,13 @@ package services
 
 import (
 	"encoding/json"
+	"strings"
 
 	"raito/internal/db"
 	"raito/internal/model"
 	"raito/internal/scraper"
 	"raito/internal/scrapeutil"
+	"raito/internal/structured"
 )
 
 // JobDocumentFormatOptions controls how stored job documents are projected
@@ -22,6 +24,28 @@ type JobDocumentFormatOptions struct {
 	// summary/json stored in metadata, while batch scrape currently does not.
 	IncludeSummary bool
 	IncludeJSON    bool
+
+	// Annotations holds user annotations keyed by document ID. Annotated
+	// documents carry their annotation, and a title override replaces the
+	// scraped title.
+	Annotations map[int64]db.DocumentAnnotation
+
+	// Category, when set, keeps only documents the classify format
+	// labeled with it, compared case-insensitively.
+	Category string
+
+	// DuplicateClusters maps near-duplicate documents to their cluster ID.
+	DuplicateClusters map[int64]int
+}
+
+// DocumentAnnotation converts a stored annotation for API responses.
+func DocumentAnnotation(a db.DocumentAnnotation) *model.DocumentAnnotation {
+	return &model.DocumentAnnotation{
+		Notes:     a.Notes.String,
+		Title:     a.Title.String,
+		Excluded:  a.Excluded,
+		UpdatedAt: a.UpdatedAt,
+	}
 }
 
 // JobDocumentService maps stored db.Document rows into model.Document
@@ -42,6 +66,9 @@ func (s *JobDocumentService) BuildDocuments(docs []db.Document, opts JobDocument
 	includeHTML := !hasFormats || scrapeutil.WantsFormat(formats, "html")
 	includeRawHTML := !hasFormats || scrapeutil.WantsFormat(formats, "rawHtml")
 	includeImages := !hasFormats || scrapeutil.WantsFormat(formats, "images")
+	includeTables := scrapeutil.WantsFormat(formats, "tables")
+	includeStructuredData := scrapeutil.WantsFormat(formats, "structuredData")
+	wantAuto := scrapeutil.WantsFormat(formats, FormatAuto)
 
 	includeSummary := false
 	includeJSON := false
@@ -60,6 +87,9 @@ func (s *JobDocumentService) BuildDocuments(docs []db.Document, opts JobDocument
 			// Skip documents with invalid metadata payloads.
 			continue
 		}
+		if opts.Category != "" && !hasCategory(md, opts.Category) {
+			continue
+		}
 
 		var markdown, html, raw, engine string
 		if d.Markdown.Valid {
@@ -84,31 +114,85 @@ func (s *JobDocumentService) BuildDocuments(docs []db.Document, opts JobDocument
 		}
 
 		doc := model.Document{
+			ID:       d.ID,
 			Engine:   engine,
 			Metadata: md,
 		}
+		if id, ok := opts.DuplicateClusters[d.ID]; ok {
+			doc.DuplicateCluster = id
+		}
+		if a, ok := opts.Annotations[d.ID]; ok {
+			doc.Annotation = DocumentAnnotation(a)
+			if a.Title.Valid {
+				doc.Metadata.Title = a.Title.String
+			}
+		}
 
-		if includeMarkdown {
+		// picked reports whether the auto format chose name for this page.
+		picked := func(name string) bool {
+			return wantAuto && autoPicked(md.AutoFormats, name)
+		}
+
+		if includeMarkdown || picked("markdown") {
 			doc.Markdown = markdown
 		}
 		if includeHTML {
 			doc.HTML = html
 		}
-		if includeRawHTML {
+		if includeRawHTML || picked("rawHtml") {
 			doc.RawHTML = raw
 		}
 		if includeImages {
 			doc.Images = images
 		}
+		if includeTables || picked("tables") {
+			doc.Tables = documentTables(raw, html)
+		}
+		if includeStructuredData || picked("structuredData") {
+			doc.StructuredData = documentStructuredData(raw, html)
+		}
 		if includeSummary && md.Summary != "" {
 			doc.Summary = md.Summary
 		}
 		if includeJSON && md.JSON != nil {
 			doc.JSON = md.JSON
 		}
+		// Plugin output is only stored when plugin formats were requested.
+		if len(md.Plugins) > 0 {
+			doc.Plugins = md.Plugins
+		}
 
 		out = append(out, doc)
 	}
 
 	return out
 }
+
+// hasCategory reports whether md carries category among its classify
+// labels.
+func hasCategory(md model.Metadata, category string) bool {
+	for _, c := range md.Categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// documentTables parses tables from the stored raw HTML, falling back to
+// the cleaned HTML when raw HTML was not persisted.
+func documentTables(rawHTML, html string) []model.Table {
+	if rawHTML != "" {
+		return scraper.ExtractTables(rawHTML)
+	}
+	return scraper.ExtractTables(html)
+}
+
+// documentStructuredData extracts structured data items like
+// documentTables.
+func documentStructuredData(rawHTML, html string) []map[string]any {
+	if rawHTML != "" {
+		return structured.Extract(rawHTML)
+	}
+	return structured.Extract(html)
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"raito/internal/db"
+)
+
+func TestBuildDocuments_AppliesAnnotations(t *testing.T) {
+	docs := []db.Document{
+		{ID: 7, Url: "https://example.com/a", Metadata: json.RawMessage(`{"title":"Scraped A","sourceURL":"https://example.com/a","statusCode":200}`)},
+		{ID: 8, Url: "https://example.com/b", Metadata: json.RawMessage(`{"title":"Scraped B","sourceURL":"https://example.com/b","statusCode":200}`)},
+	}
+	annotations := map[int64]db.DocumentAnnotation{
+		7: {
+			DocumentID: 7,
+			Notes:      sql.NullString{String: "outdated", Valid: true},
+			Title:      sql.NullString{String: "Corrected A", Valid: true},
+			Excluded:   true,
+		},
+	}
+
+	out := NewJobDocumentService().BuildDocuments(docs, JobDocumentFormatOptions{Annotations: annotations})
+	if len(out) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(out))
+	}
+
+	a := out[0]
+	if a.ID != 7 || a.Annotation == nil {
+		t.Fatalf("expected document 7 to carry its annotation, got %+v", a)
+	}
+	if a.Metadata.Title != "Corrected A" || a.Annotation.Notes != "outdated" || !a.Annotation.Excluded {
+		t.Fatalf("unexpected annotated document: title=%q annotation=%+v", a.Metadata.Title, a.Annotation)
+	}
+
+	b := out[1]
+	if b.ID != 8 || b.Annotation != nil || b.Metadata.Title != "Scraped B" {
+		t.Fatalf("expected document 8 to be unchanged, got %+v", b)
+	}
+}
+
+func TestBuildDocuments_FiltersByCategory(t *testing.T) {
+	docs := []db.Document{
+		{ID: 1, Metadata: json.RawMessage(`{"sourceURL":"https://example.com/pricing","statusCode":200,"categories":["Pricing"]}`)},
+		{ID: 2, Metadata: json.RawMessage(`{"sourceURL":"https://example.com/docs","statusCode":200,"categories":["Docs","Support"]}`)},
+		{ID: 3, Metadata: json.RawMessage(`{"sourceURL":"https://example.com/","statusCode":200}`)},
+	}
+
+	out := NewJobDocumentService().BuildDocuments(docs, JobDocumentFormatOptions{Category: "pricing"})
+	if len(out) != 1 || out[0].ID != 1 {
+		t.Fatalf("expected only the pricing document, got %+v", out)
+	}
+	if got := out[0].Metadata.Categories; len(got) != 1 || got[0] != "Pricing" {
+		t.Fatalf("expected categories to be kept in metadata, got %v", got)
+	}
+
+	if out := NewJobDocumentService().BuildDocuments(docs, JobDocumentFormatOptions{}); len(out) != 3 {
+		t.Fatalf("expected no filtering without a category, got %d documents", len(out))
+	}
+}
+
+func TestBuildDocuments_AutoFormat(t *testing.T) {
+	docs := []db.Document{
+		{
+			ID:       1,
+			Markdown: sql.NullString{String: "# Hello", Valid: true},
+			RawHtml:  sql.NullString{String: "<h1>Hello</h1>", Valid: true},
+			Metadata: json.RawMessage(`{"sourceURL":"https://example.com/","statusCode":200,"autoFormats":["markdown"]}`),
+		},
+		{
+			ID:       2,
+			Markdown: sql.NullString{String: "{}", Valid: true},
+			RawHtml:  sql.NullString{String: `{"a":1}`, Valid: true},
+			Metadata: json.RawMessage(`{"sourceURL":"https://example.com/data.json","statusCode":200,"autoFormats":["rawHtml"]}`),
+		},
+	}
+
+	out := NewJobDocumentService().BuildDocuments(docs, JobDocumentFormatOptions{Formats: []any{"auto"}})
+	if out[0].Markdown != "# Hello" || out[0].RawHTML != "" {
+		t.Fatalf("expected markdown only for the article, got %+v", out[0])
+	}
+	if out[1].Markdown != "" || out[1].RawHTML != `{"a":1}` {
+		t.Fatalf("expected the raw body only for the JSON page, got %+v", out[1])
+	}
+}
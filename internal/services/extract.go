@@ -11,11 +11,18 @@ import (
 // ExtractRequest is the internal representation of an extract
 // enqueue request used by ExtractService.
 type ExtractRequest struct {
-	ID         uuid.UUID
-	Body       any
-	PrimaryURL string
-	TenantID   *uuid.UUID
-	APIKeyID   *uuid.UUID
+	ID           uuid.UUID
+	Body         any
+	PrimaryURL   string
+	TenantID     *uuid.UUID
+	APIKeyID     *uuid.UUID
+	UserID       *uuid.UUID
+	Visibility   string
+	CollectionID *uuid.UUID
+	// Pool is the worker pool the job is routed to.
+	Pool string
+	// ZeroRetention deletes the job's data once results are delivered.
+	ZeroRetention bool
 }
 
 // ExtractService encapsulates the business logic for enqueuing
@@ -46,6 +53,19 @@ func (s *extractService) Enqueue(ctx context.Context, req *ExtractRequest) error
 		return errors.New("primary url is required")
 	}
 
-	_, err := s.st.CreateJob(ctx, req.ID, "extract", req.PrimaryURL, req.Body, false, 10, req.TenantID, req.APIKeyID)
+	_, err := s.st.CreateJob(ctx, store.CreateJobParams{
+		ID:            req.ID,
+		Type:          "extract",
+		URL:           req.PrimaryURL,
+		Input:         req.Body,
+		Priority:      10,
+		TenantID:      req.TenantID,
+		APIKeyID:      req.APIKeyID,
+		UserID:        req.UserID,
+		Visibility:    req.Visibility,
+		CollectionID:  req.CollectionID,
+		Pool:          req.Pool,
+		ZeroRetention: req.ZeroRetention,
+	})
 	return err
 }
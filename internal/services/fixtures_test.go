@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"raito/internal/config"
+	"raito/internal/fixtures"
+	"raito/internal/scraper"
+)
+
+func TestScrapeService_FixtureGolden(t *testing.T) {
+	site := fixtures.NewSite(t)
+	svc := NewScrapeService(&config.Config{})
+
+	for _, c := range []struct {
+		name    string
+		path    string
+		formats []any
+	}{
+		{"about", "/about", []any{"markdown", "links", "images", "tables", "structuredData"}},
+		{"about-auto", "/about", []any{FormatAuto}},
+		{"spa", "/spa/", []any{"markdown", "links"}},
+		{"lang-en", "/lang/en", []any{"markdown", "links"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := scraper.NewHTTPScraper(5*time.Second).Scrape(context.Background(), scraper.Request{URL: site.URL + c.path})
+			if err != nil {
+				t.Fatalf("Scrape: %v", err)
+			}
+			out, err := svc.Scrape(context.Background(), &ScrapeRequest{Result: res, Formats: c.formats})
+			if err != nil {
+				t.Fatalf("ScrapeService.Scrape: %v", err)
+			}
+			site.GoldenJSON(t, "scrape/"+c.name+".json", out.Document)
+		})
+	}
+}
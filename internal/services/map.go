@@ -76,7 +76,8 @@ func (s *mapService) Map(ctx context.Context, req *MapRequest) (*MapResult, erro
 		SitemapMode:       req.SitemapMode,
 		Timeout:           time.Duration(timeoutMs) * time.Millisecond,
 		RespectRobots:     s.cfg.Robots.Respect,
-		UserAgent:         s.cfg.Scraper.UserAgent,
+		UserAgent:         s.cfg.RequestUserAgent(),
+		RobotsAgent:       s.cfg.RobotsAgent(),
 	})
 	if err != nil {
 		return nil, err
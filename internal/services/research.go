@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"raito/internal/store"
+)
+
+// ResearchRequest is the internal representation of a research
+// enqueue request used by ResearchService.
+type ResearchRequest struct {
+	ID         uuid.UUID
+	Body       any
+	Query      string
+	TenantID   *uuid.UUID
+	APIKeyID   *uuid.UUID
+	UserID     *uuid.UUID
+	Visibility string
+	// Pool is the worker pool the job is routed to.
+	Pool string
+	// ZeroRetention deletes the job's data once results are delivered.
+	ZeroRetention bool
+}
+
+// ResearchService enqueues research jobs, which search for a query and
+// extract one answer from the top results.
+type ResearchService interface {
+	Enqueue(ctx context.Context, req *ResearchRequest) error
+}
+
+type researchService struct {
+	st *store.Store
+}
+
+// NewResearchService constructs a ResearchService backed by the store
+// layer.
+func NewResearchService(st *store.Store) ResearchService {
+	return &researchService{st: st}
+}
+
+func (s *researchService) Enqueue(ctx context.Context, req *ResearchRequest) error {
+	if req == nil {
+		return errors.New("nil research request")
+	}
+	if req.ID == uuid.Nil {
+		return errors.New("research id is required")
+	}
+	if req.Query == "" {
+		return errors.New("query is required")
+	}
+
+	// The job has no URL until the search has run; the query stands in
+	// for it in job listings.
+	_, err := s.st.CreateJob(ctx, store.CreateJobParams{
+		ID:            req.ID,
+		Type:          "research",
+		URL:           req.Query,
+		Input:         req.Body,
+		Priority:      10,
+		TenantID:      req.TenantID,
+		APIKeyID:      req.APIKeyID,
+		UserID:        req.UserID,
+		Visibility:    req.Visibility,
+		Pool:          req.Pool,
+		ZeroRetention: req.ZeroRetention,
+	})
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"raito/internal/model"
 	"raito/internal/scraper"
 	"raito/internal/scrapeutil"
+	"raito/internal/structured"
 )
 
 // ScrapeRequest is the internal representation of a scrape request
@@ -66,6 +67,12 @@ func (s *scrapeService) Scrape(_ context.Context, req *ScrapeRequest) (*ScrapeRe
 		OgSiteName:    scrapeutil.ToString(res.Metadata["ogSiteName"]),
 		SourceURL:     scrapeutil.ToString(res.Metadata["sourceURL"]),
 		StatusCode:    res.Status,
+		Headers:       res.Headers,
+	}
+
+	if scrapeutil.WantsFormat(formats, FormatAuto) {
+		md.AutoFormats = AutoFormats(res.Headers["content-type"], resultHTML(res))
+		formats = ExpandAutoFormats(formats, md.AutoFormats)
 	}
 
 	links := res.Links
@@ -97,6 +104,9 @@ func (s *scrapeService) Scrape(_ context.Context, req *ScrapeRequest) (*ScrapeRe
 	includeRawHTML := !hasFormats || scrapeutil.WantsFormat(formats, "rawHtml")
 	includeLinks := !hasFormats || scrapeutil.WantsFormat(formats, "links")
 	includeImages := !hasFormats || scrapeutil.WantsFormat(formats, "images")
+	// Tables are opt-in since most pages only use them for layout.
+	includeTables := scrapeutil.WantsFormat(formats, "tables")
+	includeStructuredData := scrapeutil.WantsFormat(formats, "structuredData")
 
 	doc := &model.Document{
 		Engine:   res.Engine,
@@ -119,6 +129,20 @@ func (s *scrapeService) Scrape(_ context.Context, req *ScrapeRequest) (*ScrapeRe
 	if includeImages {
 		doc.Images = images
 	}
+	if includeTables {
+		doc.Tables = scraper.ExtractTables(resultHTML(res))
+	}
+	if includeStructuredData {
+		doc.StructuredData = structured.Extract(resultHTML(res))
+	}
 
 	return &ScrapeResult{Document: doc}, nil
 }
+
+// resultHTML returns the raw HTML of res, falling back to the cleaned HTML.
+func resultHTML(res *scraper.Result) string {
+	if res.RawHTML != "" {
+		return res.RawHTML
+	}
+	return res.HTML
+}
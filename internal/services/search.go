@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"raito/internal/config"
@@ -24,6 +26,9 @@ type SearchRequest struct {
 	TBS               string
 	TimeoutMs         int
 	IgnoreInvalidURLs bool
+	Sites             []string
+	ExcludeSites      []string
+	Categories        []string
 }
 
 // SearchWebResult is a provider-agnostic representation of a single
@@ -33,6 +38,7 @@ type SearchWebResult struct {
 	Title       string
 	Description string
 	URL         string
+	Category    string
 }
 
 // SearchResult groups web results and basic metadata about the
@@ -69,7 +75,13 @@ type ScrapedWebResult struct {
 	Title       string
 	Description string
 	URL         string
+	Category    string
 	Document    *model.Document
+	// Error explains why the result has no Document; empty on success
+	// and for results with an invalid URL.
+	Error string
+	// ScrapeDurationMs is how long scraping the result took.
+	ScrapeDurationMs int64
 }
 
 type SearchScrapeResult struct {
@@ -82,6 +94,8 @@ type SearchScrapeResult struct {
 type searchService struct {
 	cfg     *config.Config
 	scraper ScrapeService
+	// engine overrides the fetcher chosen from the options; tests set it.
+	engine scraper.Scraper
 }
 
 // NewSearchService constructs a SearchService backed by the provided
@@ -146,6 +160,9 @@ func (s *searchService) Search(ctx context.Context, req *SearchRequest) (*Search
 		TBS:              req.TBS,
 		Timeout:          time.Duration(timeoutMs) * time.Millisecond,
 		IgnoreInvalidURL: req.IgnoreInvalidURLs,
+		Sites:            req.Sites,
+		ExcludeSites:     req.ExcludeSites,
+		Categories:       req.Categories,
 	}
 
 	results, err := provider.Search(ctx, searchReq)
@@ -164,6 +181,7 @@ func (s *searchService) Search(ctx context.Context, req *SearchRequest) (*Search
 			Title:       r.Title,
 			Description: r.Description,
 			URL:         r.URL,
+			Category:    r.Category,
 		})
 	}
 
@@ -202,98 +220,114 @@ func (s *searchService) ScrapeResults(ctx context.Context, base []search.Result,
 		useBrowser = *opts.UseBrowser
 	}
 
-	var engine scraper.Scraper
-	if useBrowser && s.cfg.Rod.Enabled {
-		engine = scraper.NewRodScraper(dur)
-	} else {
+	engine := s.engine
+	if engine == nil && useBrowser && s.cfg.Rod.Enabled {
+		engine = scraper.NewBrowserScraper(dur, scraper.BrowserOptions{
+			Isolation:    s.cfg.Rod.Isolation,
+			MaxProcesses: s.cfg.Rod.MaxProcesses,
+		})
+	} else if engine == nil {
 		engine = scraper.NewHTTPScraper(dur)
 	}
 
-	out := make([]ScrapedWebResult, 0, len(base))
+	// Build a scraper.Request using shared helpers to keep headers and
+	// Accept-Language behavior consistent.
+	var locOpts *scraper.LocationOptions
+	if opts != nil && opts.Location != nil {
+		locOpts = &scraper.LocationOptions{
+			Country:   opts.Location.Country,
+			Languages: opts.Location.Languages,
+		}
+	}
+	var headers map[string]string
+	formats := []any{}
+	if opts != nil {
+		headers = opts.Headers
+		formats = opts.Formats
+	}
+	// For /v1/search, when no formats are provided we only include
+	// markdown by default for scraped documents.
+	if len(formats) == 0 {
+		formats = []any{"markdown"}
+	}
+
+	entries := make([]ScrapedWebResult, len(base))
+	failed := make([]bool, len(base))
 	invalidURLCount := 0
-	scrapeErrorCount := 0
-	scrapedCount := 0
 
-	for _, r := range base {
-		entry := ScrapedWebResult{
+	// Scrape with at most MaxConcurrentScrapes in flight. Each result
+	// records its own outcome, so one slow or failing page never fails
+	// the others.
+	sem := make(chan struct{}, searchScrapeConcurrency(s.cfg))
+	var wg sync.WaitGroup
+	for i, r := range base {
+		entries[i] = ScrapedWebResult{
 			Title:       r.Title,
 			Description: r.Description,
 			URL:         r.URL,
+			Category:    r.Category,
 		}
-
 		if strings.TrimSpace(r.URL) == "" {
 			invalidURLCount++
-			if ignoreInvalid {
-				continue
-			}
-			out = append(out, entry)
+			failed[i] = true
 			continue
 		}
 
-		// Build a scraper.Request using shared helpers to keep
-		// headers and Accept-Language behavior consistent.
-		var locOpts *scraper.LocationOptions
-		if opts != nil && opts.Location != nil {
-			loc := opts.Location
-			locOpts = &scraper.LocationOptions{
-				Country:   loc.Country,
-				Languages: loc.Languages,
+		wg.Add(1)
+		go func(entry *ScrapedWebResult) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				entry.Error = scrapeErrorMessage(ctx.Err())
+				return
 			}
-		}
 
-		baseHeaders := map[string]string{}
-		if opts != nil && opts.Headers != nil {
-			for k, v := range opts.Headers {
-				baseHeaders[k] = v
+			start := time.Now()
+			defer func() { entry.ScrapeDurationMs = time.Since(start).Milliseconds() }()
+
+			sReq := scraper.BuildRequestFromOptions(scraper.RequestOptions{
+				URL:       entry.URL,
+				Headers:   headers,
+				TimeoutMs: int(dur.Milliseconds()),
+				UserAgent: s.cfg.RequestUserAgent(),
+				Location:  locOpts,
+			})
+			res, err := engine.Scrape(ctx, sReq)
+			if err != nil {
+				entry.Error = scrapeErrorMessage(err)
+				return
 			}
-		}
-
-		sReq := scraper.BuildRequestFromOptions(scraper.RequestOptions{
-			URL:       r.URL,
-			Headers:   baseHeaders,
-			TimeoutMs: int(dur.Milliseconds()),
-			UserAgent: s.cfg.Scraper.UserAgent,
-			Location:  locOpts,
-		})
-
-		res, err := engine.Scrape(ctx, sReq)
-
-		if err != nil {
-			scrapeErrorCount++
-			if ignoreInvalid {
-				continue
+			svcRes, err := s.scraper.Scrape(ctx, &ScrapeRequest{
+				Result:  res,
+				Formats: formats,
+			})
+			if err != nil {
+				entry.Error = scrapeErrorMessage(err)
+				return
 			}
-			out = append(out, entry)
-			continue
-		}
-
-		formats := []any{}
-		if opts != nil {
-			formats = opts.Formats
-		}
-		// For /v1/search, when no formats are provided we only include
-		// markdown by default for scraped documents.
-		if len(formats) == 0 {
-			formats = []any{"markdown"}
-		}
+			if svcRes != nil {
+				entry.Document = svcRes.Document
+			}
+		}(&entries[i])
+	}
+	wg.Wait()
 
-		svcRes, err := s.scraper.Scrape(ctx, &ScrapeRequest{
-			Result:  res,
-			Formats: formats,
-		})
-		if err != nil {
+	out := make([]ScrapedWebResult, 0, len(entries))
+	scrapeErrorCount := 0
+	scrapedCount := 0
+	for i, entry := range entries {
+		if entry.Error != "" {
 			scrapeErrorCount++
-			if ignoreInvalid {
-				continue
-			}
-			out = append(out, entry)
-			continue
+			failed[i] = true
 		}
-		if svcRes != nil && svcRes.Document != nil {
-			entry.Document = svcRes.Document
+		if entry.Document != nil {
 			scrapedCount++
 		}
-
+		if failed[i] && ignoreInvalid {
+			continue
+		}
 		out = append(out, entry)
 	}
 
@@ -304,3 +338,21 @@ func (s *searchService) ScrapeResults(ctx context.Context, base []search.Result,
 		ScrapeErrorCount: scrapeErrorCount,
 	}, nil
 }
+
+// searchScrapeConcurrency returns search.maxConcurrentScrapes, defaulting
+// to 4 when unset.
+func searchScrapeConcurrency(cfg *config.Config) int {
+	if cfg.Search.MaxConcurrentScrapes > 0 {
+		return cfg.Search.MaxConcurrentScrapes
+	}
+	return 4
+}
+
+// scrapeErrorMessage annotates a failed result scrape with a code
+// prefix, in the "CODE: message" form used for job errors.
+func scrapeErrorMessage(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return "SCRAPE_TIMEOUT: " + err.Error()
+	}
+	return "SCRAPE_FAILED: " + err.Error()
+}
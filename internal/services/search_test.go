@@ -2,10 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"raito/internal/config"
 	"raito/internal/model"
+	"raito/internal/scraper"
 	"raito/internal/search"
 )
 
@@ -72,3 +76,81 @@ func TestSearchScrapeResults_IgnoreInvalidURLsTrue(t *testing.T) {
 // underlying search.Provider is exercised indirectly via the
 // search.Request type; here we focus tests on the ScrapeResults
 // behavior where ignoreInvalid controls how invalid URLs are handled.
+
+// fakeEngine fetches pages for ScrapeResults, failing the URLs in errs
+// and tracking how many fetches run at once.
+type fakeEngine struct {
+	errs map[string]error
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (f *fakeEngine) Scrape(ctx context.Context, req scraper.Request) (*scraper.Result, error) {
+	f.mu.Lock()
+	f.inFlight++
+	f.peak = max(f.peak, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if err := f.errs[req.URL]; err != nil {
+		return nil, err
+	}
+	return &scraper.Result{URL: req.URL, Status: 200}, nil
+}
+
+func TestSearchScrapeResults_PartialResultsWithConcurrencyLimit(t *testing.T) {
+	engine := &fakeEngine{errs: map[string]error{
+		"https://c.com": errors.New("connection refused"),
+		"https://d.com": context.DeadlineExceeded,
+	}}
+	svc := newSearchServiceWithFakeScraper()
+	svc.cfg.Search.MaxConcurrentScrapes = 2
+	svc.engine = engine
+
+	base := []search.Result{
+		{Title: "a", URL: "https://a.com"},
+		{Title: "b", URL: "https://b.com"},
+		{Title: "c", URL: "https://c.com"},
+		{Title: "d", URL: "https://d.com"},
+		{Title: "e", URL: "https://e.com"},
+	}
+	res, err := svc.ScrapeResults(context.Background(), base, nil, false)
+	if err != nil {
+		t.Fatalf("ScrapeResults returned error: %v", err)
+	}
+	if engine.peak > 2 {
+		t.Fatalf("expected at most 2 concurrent scrapes, got %d", engine.peak)
+	}
+	if res.ScrapedCount != 3 || res.ScrapeErrorCount != 2 || len(res.Web) != len(base) {
+		t.Fatalf("unexpected counts: scraped=%d errors=%d results=%d", res.ScrapedCount, res.ScrapeErrorCount, len(res.Web))
+	}
+	for i, r := range res.Web {
+		if r.URL != base[i].URL {
+			t.Fatalf("expected results in search order, got %q at %d", r.URL, i)
+		}
+		if r.ScrapeDurationMs <= 0 {
+			t.Fatalf("%s: expected a scrape duration", r.URL)
+		}
+	}
+	if got := res.Web[2].Error; got != "SCRAPE_FAILED: connection refused" {
+		t.Fatalf("unexpected error annotation %q", got)
+	}
+	if got := res.Web[3].Error; got != "SCRAPE_TIMEOUT: context deadline exceeded" {
+		t.Fatalf("unexpected error annotation %q", got)
+	}
+	if res.Web[0].Document == nil || res.Web[0].Error != "" {
+		t.Fatalf("expected the first result to be scraped, got %+v", res.Web[0])
+	}
+
+	res, _ = svc.ScrapeResults(context.Background(), base, nil, true)
+	if len(res.Web) != 3 {
+		t.Fatalf("expected failed results to be dropped with ignoreInvalidURLs, got %d", len(res.Web))
+	}
+}
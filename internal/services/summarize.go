@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"raito/internal/store"
+)
+
+// SummarizeRequest is the internal representation of a summarize
+// enqueue request used by SummarizeService.
+type SummarizeRequest struct {
+	ID   uuid.UUID
+	Body any
+	// URL is the source job's URL, or the first document's, which the
+	// job is listed under.
+	URL        string
+	TenantID   *uuid.UUID
+	APIKeyID   *uuid.UUID
+	UserID     *uuid.UUID
+	Visibility string
+	// Pool is the worker pool the job is routed to.
+	Pool string
+}
+
+// SummarizeService enqueues summarize jobs, which condense a set of
+// stored documents into one report.
+type SummarizeService interface {
+	Enqueue(ctx context.Context, req *SummarizeRequest) error
+}
+
+type summarizeService struct {
+	st *store.Store
+}
+
+// NewSummarizeService constructs a SummarizeService backed by the store
+// layer.
+func NewSummarizeService(st *store.Store) SummarizeService {
+	return &summarizeService{st: st}
+}
+
+func (s *summarizeService) Enqueue(ctx context.Context, req *SummarizeRequest) error {
+	if req == nil {
+		return errors.New("nil summarize request")
+	}
+	if req.ID == uuid.Nil {
+		return errors.New("summarize id is required")
+	}
+
+	_, err := s.st.CreateJob(ctx, store.CreateJobParams{
+		ID:         req.ID,
+		Type:       "summarize",
+		URL:        req.URL,
+		Input:      req.Body,
+		Priority:   10,
+		TenantID:   req.TenantID,
+		APIKeyID:   req.APIKeyID,
+		UserID:     req.UserID,
+		Visibility: req.Visibility,
+		Pool:       req.Pool,
+	})
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -15,11 +16,17 @@ import (
 	"github.com/sqlc-dev/pqtype"
 
 	"raito/internal/db"
+	"raito/internal/events"
+	"raito/internal/jobinput"
 )
 
 // Store wraps access to the database via sqlc-generated Queries.
 type Store struct {
 	DB *sql.DB
+
+	// LegacyJobInputs stores new jobs' inputs without the jobinput
+	// envelope, for workers from releases that cannot read it.
+	LegacyJobInputs bool
 }
 
 // hashAPIKey hashes a raw API key string using SHA-256 and returns a hex string.
@@ -41,63 +48,218 @@ func (s *Store) withQueries(ctx context.Context, fn func(ctx context.Context, q
 	return fn(ctx, q)
 }
 
+// CreateJobParams describes a job row to insert.
+type CreateJobParams struct {
+	ID       uuid.UUID
+	Type     string
+	URL      string
+	Input    any
+	Sync     bool
+	Priority int32
+	TenantID *uuid.UUID
+	APIKeyID *uuid.UUID
+	// UserID is the user who created the job, when known.
+	UserID *uuid.UUID
+	// Visibility is "private" (creator only) or "shared" (whole tenant).
+	// Empty defaults to "shared".
+	Visibility string
+	// CollectionID optionally groups the job into a tenant collection.
+	CollectionID *uuid.UUID
+	// Pool is the worker pool that may run the job. Empty defaults to
+	// "default".
+	Pool string
+	// ZeroRetention marks the job's inputs, outputs, and documents for
+	// deletion once its results have been delivered.
+	ZeroRetention bool
+	// AppliedOptions records the resolved request options and their
+	// sources; nil leaves the column empty.
+	AppliedOptions any
+}
+
+// marshalNullJSON encodes v as a nullable JSON column value.
+func marshalNullJSON(v any) (pqtype.NullRawMessage, error) {
+	if v == nil {
+		return pqtype.NullRawMessage{}, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil || string(raw) == "null" {
+		return pqtype.NullRawMessage{}, err
+	}
+	return pqtype.NullRawMessage{RawMessage: raw, Valid: true}, nil
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func jobVisibility(v string) string {
+	if v == "" {
+		return "shared"
+	}
+	return v
+}
+
+func jobPool(p string) string {
+	if p == "" {
+		return "default"
+	}
+	return p
+}
+
+// encodeJobInput marshals a new job's input for jobs.input.
+func (s *Store) encodeJobInput(input any) (json.RawMessage, error) {
+	if s.LegacyJobInputs {
+		return json.Marshal(input)
+	}
+	return jobinput.Encode(input)
+}
+
 // CreateJob inserts a new job row with the given parameters.
-func (s *Store) CreateJob(ctx context.Context, id uuid.UUID, jobType, url string, input any, sync bool, priority int32, tenantID, apiKeyID *uuid.UUID) (db.Job, error) {
-	payload, err := json.Marshal(input)
+func (s *Store) CreateJob(ctx context.Context, params CreateJobParams) (db.Job, error) {
+	payload, err := s.encodeJobInput(params.Input)
+	if err != nil {
+		return db.Job{}, err
+	}
+	applied, err := marshalNullJSON(params.AppliedOptions)
 	if err != nil {
 		return db.Job{}, err
 	}
 
 	var job db.Job
 	err = s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
-		var t uuid.NullUUID
-		if tenantID != nil {
-			t = uuid.NullUUID{UUID: *tenantID, Valid: true}
-		}
-		var k uuid.NullUUID
-		if apiKeyID != nil {
-			k = uuid.NullUUID{UUID: *apiKeyID, Valid: true}
-		}
 		row, err := q.InsertJob(ctx, db.InsertJobParams{
-			ID:       id,
-			Type:     jobType,
-			Status:   "pending",
-			Url:      url,
-			Input:    payload,
-			Sync:     sync,
-			Priority: priority,
-			TenantID: t,
-			ApiKeyID: k,
+			ID:              params.ID,
+			Type:            params.Type,
+			Status:          "pending",
+			Url:             params.URL,
+			Input:           payload,
+			Sync:            params.Sync,
+			Priority:        params.Priority,
+			TenantID:        nullUUID(params.TenantID),
+			ApiKeyID:        nullUUID(params.APIKeyID),
+			CreatedByUserID: nullUUID(params.UserID),
+			Visibility:      jobVisibility(params.Visibility),
+			CollectionID:    nullUUID(params.CollectionID),
+			Pool:            jobPool(params.Pool),
+			ZeroRetention:   params.ZeroRetention,
+			AppliedOptions:  applied,
 		})
 		if err != nil {
 			return err
 		}
 
 		job = db.Job{
-			ID:          row.ID,
-			Type:        row.Type,
-			Status:      row.Status,
-			Url:         row.Url,
-			Input:       row.Input,
-			Error:       row.Error,
-			CreatedAt:   row.CreatedAt,
-			UpdatedAt:   row.UpdatedAt,
-			CompletedAt: row.CompletedAt,
-			Priority:    row.Priority,
-			Sync:        row.Sync,
-			Output:      row.Output,
-			TenantID:    row.TenantID,
-			ApiKeyID:    row.ApiKeyID,
+			ID:              row.ID,
+			Type:            row.Type,
+			Status:          row.Status,
+			Url:             row.Url,
+			Input:           row.Input,
+			Error:           row.Error,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+			CompletedAt:     row.CompletedAt,
+			Priority:        row.Priority,
+			Sync:            row.Sync,
+			Output:          row.Output,
+			TenantID:        row.TenantID,
+			ApiKeyID:        row.ApiKeyID,
+			CreatedByUserID: row.CreatedByUserID,
+			Visibility:      row.Visibility,
+			CollectionID:    row.CollectionID,
+			PreviousJobID:   row.PreviousJobID,
+			Metrics:         row.Metrics,
+			Pool:            row.Pool,
+			ZeroRetention:   row.ZeroRetention,
+			PurgedAt:        row.PurgedAt,
+			AppliedOptions:  row.AppliedOptions,
+			Pinned:          row.Pinned,
 		}
+		_ = s.addEnqueuedEvent(ctx, job.ID, job.Pool, job.Priority)
 		return nil
 	})
 
 	return job, err
 }
 
-// CreateCrawlJob inserts a new crawl job row.
-func (s *Store) CreateCrawlJob(ctx context.Context, id uuid.UUID, url string, input any, tenantID, apiKeyID *uuid.UUID) (db.Job, error) {
-	return s.CreateJob(ctx, id, "crawl", url, input, false, 10, tenantID, apiKeyID)
+// addEnqueuedEvent starts a new job's timeline and wakes the workers of
+// its pool.
+func (s *Store) addEnqueuedEvent(ctx context.Context, jobID uuid.UUID, pool string, priority int32) error {
+	PublishJobQueued(ctx, pool)
+	return s.AddJobEvent(ctx, jobID, JobEventEnqueued, "", map[string]any{
+		"pool":     pool,
+		"priority": priority,
+	})
+}
+
+// CreateOrJoinJob inserts a new job tagged with the given request
+// fingerprint unless another pending/running job already holds the same
+// fingerprint, in which case that in-flight job is returned instead. The
+// boolean result reports whether a new job row was created.
+func (s *Store) CreateOrJoinJob(ctx context.Context, params CreateJobParams, fingerprint string) (db.Job, bool, error) {
+	payload, err := s.encodeJobInput(params.Input)
+	if err != nil {
+		return db.Job{}, false, err
+	}
+	applied, err := marshalNullJSON(params.AppliedOptions)
+	if err != nil {
+		return db.Job{}, false, err
+	}
+
+	// The in-flight job may finish between a conflicting insert and the
+	// lookup below, so retry a few times before giving up.
+	for attempt := 0; attempt < 3; attempt++ {
+		var insertedID uuid.UUID
+		err := s.DB.QueryRowContext(ctx, `
+INSERT INTO jobs (id, type, status, url, input, sync, priority, tenant_id, api_key_id, created_by_user_id, visibility, collection_id, fingerprint, pool, applied_options)
+VALUES ($1, $2, 'pending', $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (fingerprint) WHERE fingerprint IS NOT NULL AND status IN ('pending', 'running') DO NOTHING
+RETURNING id`,
+			params.ID, params.Type, params.URL, payload, params.Sync, params.Priority,
+			nullUUID(params.TenantID), nullUUID(params.APIKeyID), nullUUID(params.UserID),
+			jobVisibility(params.Visibility), nullUUID(params.CollectionID), fingerprint,
+			jobPool(params.Pool), applied,
+		).Scan(&insertedID)
+		if err == nil {
+			job, err := s.GetJobByID(ctx, insertedID)
+			if err != nil {
+				return db.Job{}, false, err
+			}
+			_ = s.addEnqueuedEvent(ctx, job.ID, job.Pool, job.Priority)
+			job.Fingerprint = sql.NullString{String: fingerprint, Valid: true}
+			return job, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return db.Job{}, false, err
+		}
+
+		var existingID uuid.UUID
+		err = s.DB.QueryRowContext(ctx, `
+SELECT id FROM jobs
+WHERE fingerprint = $1 AND status IN ('pending', 'running')
+ORDER BY created_at ASC
+LIMIT 1`, fingerprint).Scan(&existingID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return db.Job{}, false, err
+		}
+
+		job, err := s.GetJobByID(ctx, existingID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return db.Job{}, false, err
+		}
+		job.Fingerprint = sql.NullString{String: fingerprint, Valid: true}
+		return job, false, nil
+	}
+
+	return db.Job{}, false, fmt.Errorf("could not create or join job with fingerprint %s", fingerprint)
 }
 
 // UpdateCrawlJobStatus updates the status and optional error message for a crawl job.
@@ -108,16 +270,336 @@ func (s *Store) UpdateCrawlJobStatus(ctx context.Context, id uuid.UUID, status s
 	}
 
 	return s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
-		return q.UpdateJobStatus(ctx, db.UpdateJobStatusParams{
+		if err := q.UpdateJobStatus(ctx, db.UpdateJobStatusParams{
 			ID:     id,
 			Status: status,
 			Error:  sqlErr,
+		}); err != nil {
+			return err
+		}
+		// The timeline is informational; a failed insert must not fail
+		// the status change.
+		if err := q.InsertJobEvent(ctx, db.InsertJobEventParams{
+			JobID:   id,
+			Type:    status,
+			Message: sqlErr,
+			Data:    json.RawMessage(`{}`),
+		}); err == nil {
+			publishJobEvent(ctx, id, status)
+		}
+		return nil
+	})
+}
+
+// Job event types recorded besides the job statuses themselves.
+const (
+	// JobEventEnqueued is recorded when the job is created.
+	JobEventEnqueued = "enqueued"
+	// JobEventClaimed is recorded when a worker picks up the job.
+	JobEventClaimed = "claimed"
+	// JobEventDiscoveryFinished is recorded when a crawl or wildcard
+	// extract has finished discovering the URLs it will process.
+	JobEventDiscoveryFinished = "discovery_finished"
+	// JobEventSearchFinished is recorded when a research job has searched
+	// and selected the pages it will extract from.
+	JobEventSearchFinished = "search_finished"
+	// JobEventNotificationFailed is recorded when a completion or failure
+	// notification to the job's creator could not be delivered.
+	JobEventNotificationFailed = "notification_failed"
+)
+
+// ErrJobNotFinished is returned when a job that is still queued or running
+// would be queued again.
+var ErrJobNotFinished = errors.New("job is still queued or running")
+
+// ErrJobNotFailed is returned when a job that has not failed, or whose
+// zero-retention input was purged, would be requeued.
+var ErrJobNotFailed = errors.New("job has not failed or its input was purged")
+
+// ErrJobNotActive is returned when a job that is not queued or running
+// would be paused.
+var ErrJobNotActive = errors.New("job is not queued or running")
+
+// ErrJobNotPaused is returned when a job that is not paused would be
+// resumed.
+var ErrJobNotPaused = errors.New("job is not paused")
+
+// QueueCrawlRescrape records a rescrape of urls for a finished crawl job
+// and queues the job again, so the next worker to claim it scrapes only
+// those URLs. Earlier rescrapes of the job that never finished are marked
+// failed.
+func (s *Store) QueueCrawlRescrape(ctx context.Context, jobID uuid.UUID, urls []string, mode string, userID *uuid.UUID) (db.CrawlRescrape, error) {
+	rawURLs, err := json.Marshal(urls)
+	if err != nil {
+		return db.CrawlRescrape{}, err
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return db.CrawlRescrape{}, err
+	}
+	defer tx.Rollback()
+
+	q := db.New(tx)
+	n, err := q.RequeueFinishedJob(ctx, jobID)
+	if err != nil {
+		return db.CrawlRescrape{}, err
+	}
+	if n == 0 {
+		return db.CrawlRescrape{}, ErrJobNotFinished
+	}
+	if err := q.FailActiveCrawlRescrapes(ctx, db.FailActiveCrawlRescrapesParams{
+		JobID: jobID,
+		Error: sql.NullString{String: "the crawl job ended before the rescrape ran", Valid: true},
+	}); err != nil {
+		return db.CrawlRescrape{}, err
+	}
+	rescrape, err := q.InsertCrawlRescrape(ctx, db.InsertCrawlRescrapeParams{
+		ID:              uuid.New(),
+		JobID:           jobID,
+		Urls:            rawURLs,
+		Mode:            mode,
+		CreatedByUserID: nullUUID(userID),
+	})
+	if err != nil {
+		return db.CrawlRescrape{}, err
+	}
+	data, err := json.Marshal(map[string]any{
+		"rescrapeId": rescrape.ID,
+		"urls":       len(urls),
+		"mode":       mode,
+	})
+	if err != nil {
+		return db.CrawlRescrape{}, err
+	}
+	if err := q.InsertJobEvent(ctx, db.InsertJobEventParams{
+		JobID:   jobID,
+		Type:    "pending",
+		Message: sql.NullString{String: fmt.Sprintf("rescrape of %d URLs queued", len(urls)), Valid: true},
+		Data:    data,
+	}); err != nil {
+		return db.CrawlRescrape{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return db.CrawlRescrape{}, err
+	}
+	publishJobEvent(ctx, jobID, "pending")
+	PublishJobQueued(ctx, "")
+	return rescrape, nil
+}
+
+// PauseJob pauses a queued or running job and records it on the job's
+// timeline. The worker of a running job stops once it notices the status.
+func (s *Store) PauseJob(ctx context.Context, jobID uuid.UUID) error {
+	return s.setJobPaused(ctx, jobID, true)
+}
+
+// ResumeJob queues a paused job again and records it on the job's
+// timeline.
+func (s *Store) ResumeJob(ctx context.Context, jobID uuid.UUID) error {
+	return s.setJobPaused(ctx, jobID, false)
+}
+
+func (s *Store) setJobPaused(ctx context.Context, jobID uuid.UUID, paused bool) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := db.New(tx)
+	status, message := "paused", "job paused"
+	var n int64
+	if paused {
+		n, err = q.PauseJob(ctx, jobID)
+	} else {
+		status, message = "pending", "job resumed"
+		n, err = q.ResumeJob(ctx, jobID)
+	}
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if paused {
+			return ErrJobNotActive
+		}
+		return ErrJobNotPaused
+	}
+	if err := q.InsertJobEvent(ctx, db.InsertJobEventParams{
+		JobID:   jobID,
+		Type:    status,
+		Message: sql.NullString{String: message, Valid: true},
+		Data:    json.RawMessage(`{}`),
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	publishJobEvent(ctx, jobID, status)
+	if !paused {
+		PublishJobQueued(ctx, "")
+	}
+	return nil
+}
+
+// RequeueFailedJob queues a failed or dead-letter job again with its
+// stored input and records the previous error on the job's timeline.
+// previousError is the error being cleared. The documents, assets, output
+// and saved crawl frontier of the failed run are deleted with it, so the
+// replay starts over instead of adding duplicates.
+func (s *Store) RequeueFailedJob(ctx context.Context, jobID uuid.UUID, previousError string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := db.New(tx)
+	n, err := q.RequeueFailedJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobNotFailed
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE job_id = $1`, jobID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM job_assets WHERE job_id = $1`, jobID); err != nil {
+		return err
+	}
+	if err := q.DeleteCrawlFrontier(ctx, jobID); err != nil {
+		return err
+	}
+	data, err := json.Marshal(map[string]any{"previousError": previousError})
+	if err != nil {
+		return err
+	}
+	if err := q.InsertJobEvent(ctx, db.InsertJobEventParams{
+		JobID:   jobID,
+		Type:    "pending",
+		Message: sql.NullString{String: "job requeued", Valid: true},
+		Data:    data,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	publishJobEvent(ctx, jobID, "pending")
+	PublishJobQueued(ctx, "")
+	return nil
+}
+
+// SaveCrawlFrontier stores the frontier of a paused crawl, replacing any
+// saved earlier. depths maps queued URLs to their discovery depth, and
+// state holds the crawl's counters.
+func (s *Store) SaveCrawlFrontier(ctx context.Context, jobID uuid.UUID, queued, seen []string, admitted int, depths map[string]int, state json.RawMessage) error {
+	rawQueued, err := json.Marshal(queued)
+	if err != nil {
+		return err
+	}
+	rawSeen, err := json.Marshal(seen)
+	if err != nil {
+		return err
+	}
+	rawDepths, err := json.Marshal(depths)
+	if err != nil {
+		return err
+	}
+	return s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		return q.UpsertCrawlFrontier(ctx, db.UpsertCrawlFrontierParams{
+			JobID:    jobID,
+			Queued:   rawQueued,
+			Seen:     rawSeen,
+			Admitted: int32(admitted),
+			State:    state,
+			Depths:   rawDepths,
 		})
 	})
 }
 
+// AddJobEvent appends an event to a job's timeline. data may be nil.
+func (s *Store) AddJobEvent(ctx context.Context, jobID uuid.UUID, eventType, message string, data map[string]any) error {
+	raw := json.RawMessage(`{}`)
+	if len(data) > 0 {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		raw = b
+	}
+	return s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		if err := q.InsertJobEvent(ctx, db.InsertJobEventParams{
+			JobID:   jobID,
+			Type:    eventType,
+			Message: sql.NullString{String: message, Valid: message != ""},
+			Data:    raw,
+		}); err != nil {
+			return err
+		}
+		publishJobEvent(ctx, jobID, eventType)
+		return nil
+	})
+}
+
+// publishJobEvent tells live timeline streams in every process that a
+// job has a new event. Streams re-read the timeline, so a lost message
+// only delays them until their next poll.
+func publishJobEvent(ctx context.Context, jobID uuid.UUID, eventType string) {
+	payload, err := json.Marshal(map[string]string{"type": eventType})
+	if err != nil {
+		return
+	}
+	_ = events.Publish(ctx, events.JobTopic(jobID), payload)
+}
+
+// PublishJobQueued wakes the workers of pool, or of every pool when pool
+// is empty, because a job may be waiting for them.
+func PublishJobQueued(ctx context.Context, pool string) {
+	_ = events.Publish(ctx, events.JobQueuedTopic, []byte(pool))
+}
+
+// ListJobEvents returns a job's timeline, oldest first.
+func (s *Store) ListJobEvents(ctx context.Context, jobID uuid.UUID) ([]db.JobEvent, error) {
+	var events []db.JobEvent
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		events, err = q.ListJobEvents(ctx, jobID)
+		return err
+	})
+	return events, err
+}
+
+// Document types stored in documents.type.
+const (
+	// DocumentTypePage is a scraped page.
+	DocumentTypePage = "page"
+	// DocumentTypeExtract is one extract result; its metadata carries the
+	// extracted JSON.
+	DocumentTypeExtract = "extract"
+)
+
+// AddExtractDocument stores an extract result as a document so downloads,
+// retention and search treat it like a scraped page. markdown is the
+// source page's markdown, when it was scraped.
+func (s *Store) AddExtractDocument(ctx context.Context, jobID uuid.UUID, url string, markdown *string, metadata json.RawMessage, statusCode *int32) error {
+	return s.addDocument(ctx, DocumentTypeExtract, jobID, url, markdown, nil, nil, metadata, statusCode, nil)
+}
+
 // AddDocument stores a scraped document row.
 func (s *Store) AddDocument(ctx context.Context, jobID uuid.UUID, url string, markdown, html, rawHTML *string, metadata json.RawMessage, statusCode *int32, engine *string) error {
+	return s.addDocument(ctx, DocumentTypePage, jobID, url, markdown, html, rawHTML, metadata, statusCode, engine)
+}
+
+func (s *Store) addDocument(ctx context.Context, docType string, jobID uuid.UUID, url string, markdown, html, rawHTML *string, metadata json.RawMessage, statusCode *int32, engine *string) error {
+	return s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		return q.InsertDocument(ctx, documentParams(docType, jobID, url, markdown, html, rawHTML, metadata, statusCode, engine))
+	})
+}
+
+func documentParams(docType string, jobID uuid.UUID, url string, markdown, html, rawHTML *string, metadata json.RawMessage, statusCode *int32, engine *string) db.InsertDocumentParams {
 	var m, h, r sql.NullString
 	if markdown != nil {
 		m = sql.NullString{String: *markdown, Valid: true}
@@ -137,18 +619,36 @@ func (s *Store) AddDocument(ctx context.Context, jobID uuid.UUID, url string, ma
 		eng = sql.NullString{String: *engine, Valid: true}
 	}
 
-	return s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
-		return q.InsertDocument(ctx, db.InsertDocumentParams{
-			JobID:      jobID,
-			Url:        url,
-			Markdown:   m,
-			Html:       h,
-			RawHtml:    r,
-			Metadata:   metadata,
-			StatusCode: sc,
-			Engine:     eng,
-		})
-	})
+	return db.InsertDocumentParams{
+		JobID:      jobID,
+		Url:        url,
+		Markdown:   m,
+		Html:       h,
+		RawHtml:    r,
+		Metadata:   metadata,
+		StatusCode: sc,
+		Engine:     eng,
+		Type:       docType,
+	}
+}
+
+// ReplaceDocument stores a scraped document row in place of the job's
+// page documents for any of replaces, in one transaction.
+func (s *Store) ReplaceDocument(ctx context.Context, jobID uuid.UUID, replaces []string, url string, markdown, html, rawHTML *string, metadata json.RawMessage, statusCode *int32, engine *string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := db.New(tx)
+	if _, err := q.DeleteJobDocumentsByURL(ctx, db.DeleteJobDocumentsByURLParams{JobID: jobID, Urls: replaces}); err != nil {
+		return err
+	}
+	if err := q.InsertDocument(ctx, documentParams(DocumentTypePage, jobID, url, markdown, html, rawHTML, metadata, statusCode, engine)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetCrawlJobAndDocuments fetches a job and all associated documents.
@@ -163,20 +663,30 @@ func (s *Store) GetCrawlJobAndDocuments(ctx context.Context, id uuid.UUID) (db.J
 		}
 
 		job = db.Job{
-			ID:          row.ID,
-			Type:        row.Type,
-			Status:      row.Status,
-			Url:         row.Url,
-			Input:       row.Input,
-			Error:       row.Error,
-			CreatedAt:   row.CreatedAt,
-			UpdatedAt:   row.UpdatedAt,
-			CompletedAt: row.CompletedAt,
-			Priority:    row.Priority,
-			Sync:        row.Sync,
-			Output:      row.Output,
-			TenantID:    row.TenantID,
-			ApiKeyID:    row.ApiKeyID,
+			ID:              row.ID,
+			Type:            row.Type,
+			Status:          row.Status,
+			Url:             row.Url,
+			Input:           row.Input,
+			Error:           row.Error,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+			CompletedAt:     row.CompletedAt,
+			Priority:        row.Priority,
+			Sync:            row.Sync,
+			Output:          row.Output,
+			TenantID:        row.TenantID,
+			ApiKeyID:        row.ApiKeyID,
+			CreatedByUserID: row.CreatedByUserID,
+			Visibility:      row.Visibility,
+			CollectionID:    row.CollectionID,
+			PreviousJobID:   row.PreviousJobID,
+			Metrics:         row.Metrics,
+			Pool:            row.Pool,
+			ZeroRetention:   row.ZeroRetention,
+			PurgedAt:        row.PurgedAt,
+			AppliedOptions:  row.AppliedOptions,
+			Pinned:          row.Pinned,
 		}
 
 		docs, err = q.GetDocumentsByJobID(ctx, id)
@@ -189,13 +699,26 @@ func (s *Store) GetCrawlJobAndDocuments(ctx context.Context, id uuid.UUID) (db.J
 	return job, docs, nil
 }
 
-// ListPendingJobs returns up to `limit` jobs that are still pending,
-// ordered by priority (desc) and created_at (asc).
-func (s *Store) ListPendingJobs(ctx context.Context, limit int32) ([]db.Job, error) {
+// SyncBoost raises the claim priority of synchronous jobs that have been
+// pending for at least After by Priority.
+type SyncBoost struct {
+	Priority int32
+	After    time.Duration
+}
+
+// ListPendingJobs returns up to `limit` jobs that are still pending in
+// one of the given worker pools, ordered by priority (desc), with boost
+// applied, and created_at (asc).
+func (s *Store) ListPendingJobs(ctx context.Context, limit int32, pools []string, boost SyncBoost) ([]db.Job, error) {
 	var jobs []db.Job
 
 	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
-		rows, err := q.ListPendingJobs(ctx, limit)
+		rows, err := q.ListPendingJobs(ctx, db.ListPendingJobsParams{
+			Limit:            limit,
+			Pools:            pools,
+			SyncBoostAfterMs: int32(boost.After.Milliseconds()),
+			SyncBoost:        boost.Priority,
+		})
 		if err != nil {
 			return err
 		}
@@ -217,6 +740,7 @@ func (s *Store) ListPendingJobs(ctx context.Context, limit int32) ([]db.Job, err
 				Output:      row.Output,
 				TenantID:    row.TenantID,
 				ApiKeyID:    row.ApiKeyID,
+				Pool:        row.Pool,
 			})
 		}
 		return nil
@@ -225,14 +749,58 @@ func (s *Store) ListPendingJobs(ctx context.Context, limit int32) ([]db.Job, err
 	return jobs, err
 }
 
+// JobViewer identifies the caller for private-job visibility checks.
+type JobViewer struct {
+	UserID   *uuid.UUID
+	APIKeyID *uuid.UUID
+}
+
+// visibilityCondition returns a SQL condition that keeps shared jobs plus
+// private jobs created by the viewer (by user or API key).
+func visibilityCondition(viewer *JobViewer, alias string, args *[]any, argPos *int) string {
+	parts := []string{alias + ".visibility = 'shared'"}
+	if viewer.UserID != nil {
+		parts = append(parts, fmt.Sprintf("%s.created_by_user_id = $%d", alias, *argPos))
+		*args = append(*args, *viewer.UserID)
+		*argPos++
+	}
+	if viewer.APIKeyID != nil {
+		parts = append(parts, fmt.Sprintf("%s.api_key_id = $%d", alias, *argPos))
+		*args = append(*args, *viewer.APIKeyID)
+		*argPos++
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// CanSee reports whether the job is visible to the viewer, mirroring
+// visibilityCondition for single-job lookups. A nil viewer sees everything.
+func (v *JobViewer) CanSee(job db.Job) bool {
+	if v == nil || job.Visibility != "private" {
+		return true
+	}
+	if v.UserID != nil && job.CreatedByUserID.Valid && job.CreatedByUserID.UUID == *v.UserID {
+		return true
+	}
+	if v.APIKeyID != nil && job.ApiKeyID.Valid && job.ApiKeyID.UUID == *v.APIKeyID {
+		return true
+	}
+	return false
+}
+
 // JobListFilter describes optional filters for listing jobs in admin APIs.
 type JobListFilter struct {
 	Type     string
 	Status   string
 	Sync     *bool
 	TenantID *uuid.UUID
-	Limit    int32
-	Offset   int32
+	// CollectionID restricts results to jobs assigned to a collection.
+	CollectionID *uuid.UUID
+	// Pinned, when set, restricts results to pinned or unpinned jobs.
+	Pinned *bool
+	// VisibleTo, when set, hides private jobs not created by this viewer.
+	VisibleTo *JobViewer
+	Limit     int32
+	Offset    int32
 }
 
 // ListJobs returns jobs matching the given filter, ordered by created_at desc.
@@ -262,6 +830,19 @@ func (s *Store) ListJobs(ctx context.Context, filter JobListFilter) ([]db.Job, e
 		args = append(args, *filter.TenantID)
 		argPos++
 	}
+	if filter.CollectionID != nil {
+		conditions = append(conditions, fmt.Sprintf("collection_id = $%d", argPos))
+		args = append(args, *filter.CollectionID)
+		argPos++
+	}
+	if filter.Pinned != nil {
+		conditions = append(conditions, fmt.Sprintf("pinned = $%d", argPos))
+		args = append(args, *filter.Pinned)
+		argPos++
+	}
+	if filter.VisibleTo != nil {
+		conditions = append(conditions, visibilityCondition(filter.VisibleTo, "jobs", &args, &argPos))
+	}
 
 	if len(conditions) > 0 {
 		baseQuery = baseQuery + " WHERE " + strings.Join(conditions, " AND ")
@@ -315,6 +896,448 @@ func (s *Store) ListJobs(ctx context.Context, filter JobListFilter) ([]db.Job, e
 	return jobs, nil
 }
 
+// JobSearchFilter describes a search across a tenant's historical job results.
+// URLPattern matches as a case-insensitive substring unless it contains `*`,
+// in which case `*` acts as a wildcard and the pattern must match the whole URL.
+type JobSearchFilter struct {
+	TenantID   uuid.UUID
+	URLPattern string
+	Text       string
+	Type       string
+	// VisibleTo, when set, hides results from private jobs not created by
+	// this viewer.
+	VisibleTo *JobViewer
+	Limit     int32
+	Offset    int32
+}
+
+// JobSearchHit is a single matching page or job output.
+type JobSearchHit struct {
+	JobID     uuid.UUID
+	JobType   string
+	URL       string
+	Source    string
+	Snippet   string
+	CreatedAt time.Time
+}
+
+// escapeLike escapes LIKE metacharacters so user input matches literally.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// urlLikePattern converts a user-supplied URL pattern into an ILIKE pattern.
+func urlLikePattern(pattern string) string {
+	if strings.Contains(pattern, "*") {
+		parts := strings.Split(pattern, "*")
+		for i, p := range parts {
+			parts[i] = escapeLike(p)
+		}
+		return strings.Join(parts, "%")
+	}
+	return "%" + escapeLike(pattern) + "%"
+}
+
+// searchDocumentBody is the searchable text of a document. Extract
+// documents are matched on their extracted JSON as well as the source
+// page's markdown.
+const searchDocumentBody = `CASE WHEN d.type = 'extract' THEN COALESCE(d.metadata->>'json', '') || ' ' || COALESCE(d.markdown, '') ELSE COALESCE(d.markdown, '') END`
+
+// SearchJobResults searches stored documents and completed scrape/extract
+// outputs for the given tenant, newest first. Extract jobs whose results
+// are stored as documents are searched through those documents only.
+func (s *Store) SearchJobResults(ctx context.Context, filter JobSearchFilter) ([]JobSearchHit, error) {
+	args := []any{filter.TenantID}
+	argPos := 2
+
+	var docConds, outConds []string
+	if filter.URLPattern != "" {
+		docConds = append(docConds, fmt.Sprintf("d.url ILIKE $%d", argPos))
+		outConds = append(outConds, fmt.Sprintf("j.url ILIKE $%d", argPos))
+		args = append(args, urlLikePattern(filter.URLPattern))
+		argPos++
+	}
+	textPos := 0
+	if filter.Text != "" {
+		textPos = argPos
+		docConds = append(docConds, fmt.Sprintf("%s ILIKE $%d", searchDocumentBody, argPos))
+		outConds = append(outConds, fmt.Sprintf("j.output::text ILIKE $%d", argPos))
+		args = append(args, "%"+escapeLike(filter.Text)+"%", filter.Text)
+		argPos += 2
+	}
+	if filter.Type != "" {
+		docConds = append(docConds, fmt.Sprintf("j.type = $%d", argPos))
+		outConds = append(outConds, fmt.Sprintf("j.type = $%d", argPos))
+		args = append(args, filter.Type)
+		argPos++
+	}
+	if filter.VisibleTo != nil {
+		cond := visibilityCondition(filter.VisibleTo, "j", &args, &argPos)
+		docConds = append(docConds, cond)
+		outConds = append(outConds, cond)
+	}
+
+	docWhere := "j.tenant_id = $1"
+	if len(docConds) > 0 {
+		docWhere += " AND " + strings.Join(docConds, " AND ")
+	}
+	outWhere := "j.tenant_id = $1 AND j.status = 'completed' AND j.output IS NOT NULL AND j.type IN ('scrape', 'extract')" +
+		" AND NOT EXISTS (SELECT 1 FROM documents d WHERE d.job_id = j.id AND d.type = 'extract')"
+	if len(outConds) > 0 {
+		outWhere += " AND " + strings.Join(outConds, " AND ")
+	}
+
+	// Snippets are centred on the first text match when a query is given.
+	snippet := "LEFT(body, 240)"
+	if textPos > 0 {
+		snippet = fmt.Sprintf("SUBSTRING(body FROM GREATEST(STRPOS(LOWER(body), LOWER($%d)) - 80, 1) FOR 240)", textPos+1)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+
+	query := fmt.Sprintf(`
+WITH hits AS (
+    SELECT j.id AS job_id, j.type AS job_type, d.url AS url, 'document' AS source,
+           %s AS body, d.created_at AS created_at
+    FROM documents d
+    JOIN jobs j ON j.id = d.job_id
+    WHERE %s
+    UNION ALL
+    SELECT j.id, j.type, j.url, 'output',
+           CASE WHEN j.type = 'scrape' THEN COALESCE(j.output->>'markdown', j.output::text) ELSE j.output::text END,
+           j.created_at
+    FROM jobs j
+    WHERE %s
+)
+SELECT job_id, job_type, url, source, %s, created_at
+FROM hits
+ORDER BY created_at DESC
+LIMIT $%d OFFSET $%d`, searchDocumentBody, docWhere, outWhere, snippet, argPos, argPos+1)
+	args = append(args, limit, filter.Offset)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []JobSearchHit
+	for rows.Next() {
+		var h JobSearchHit
+		if err := rows.Scan(&h.JobID, &h.JobType, &h.URL, &h.Source, &h.Snippet, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+// ListCollectionDocuments returns documents from all jobs assigned to the
+// collection, newest first. Documents of private jobs are omitted unless
+// visible to the viewer (a nil viewer sees everything). A non-empty
+// category keeps only documents the classify format labeled with it.
+func (s *Store) ListCollectionDocuments(ctx context.Context, collectionID uuid.UUID, viewer *JobViewer, category string, limit, offset int32) ([]db.Document, error) {
+	args := []any{collectionID}
+	argPos := 2
+
+	query := `
+SELECT d.id, d.job_id, d.url, d.markdown, d.html, d.raw_html, d.metadata, d.status_code, d.created_at, d.engine, d.type
+FROM documents d
+JOIN jobs j ON j.id = d.job_id
+WHERE j.collection_id = $1`
+	if viewer != nil {
+		query += " AND " + visibilityCondition(viewer, "j", &args, &argPos)
+	}
+	if category != "" {
+		query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(COALESCE(d.metadata->'categories', '[]'::jsonb)) AS c(label) WHERE lower(c.label) = lower($%d))", argPos)
+		args = append(args, category)
+		argPos++
+	}
+
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	query += fmt.Sprintf(" ORDER BY d.created_at DESC, d.id DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []db.Document
+	for rows.Next() {
+		var d db.Document
+		if err := rows.Scan(
+			&d.ID,
+			&d.JobID,
+			&d.Url,
+			&d.Markdown,
+			&d.Html,
+			&d.RawHtml,
+			&d.Metadata,
+			&d.StatusCode,
+			&d.CreatedAt,
+			&d.Engine,
+			&d.Type,
+		); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// DocumentChangeEntry is a change feed row. Document holds the
+// document's current content when it was requested and the document
+// still exists.
+type DocumentChangeEntry struct {
+	db.DocumentChange
+	Document *db.Document
+}
+
+// ListDocumentChanges returns up to limit changes to tenantID's documents
+// after the since cursor, in feed order, skipping private jobs the viewer
+// cannot see. Changes written since the oldest open write transaction
+// began are held back until it ends, so a change can never appear behind
+// a cursor that has already been handed out.
+func (s *Store) ListDocumentChanges(ctx context.Context, tenantID uuid.UUID, viewer *JobViewer, since int64, limit int32, withContent bool) ([]DocumentChangeEntry, error) {
+	args := []any{tenantID, since}
+	argPos := 3
+
+	content := "NULL::bigint, NULL::text, NULL::jsonb, NULL::int, NULL::timestamptz, NULL::text"
+	join := ""
+	if withContent {
+		content = "d.id, d.markdown, d.metadata, d.status_code, d.created_at, d.type"
+		join = "LEFT JOIN documents d ON d.id = c.document_id AND c.change_type <> 'deleted'"
+	}
+
+	query := `
+WITH horizon AS (
+    SELECT COALESCE(MIN(xact_start), 'infinity'::timestamptz) AS started_at
+    FROM pg_stat_activity
+    WHERE datname = current_database() AND backend_xid IS NOT NULL
+), held AS (
+    SELECT MIN(seq) AS seq
+    FROM document_changes, horizon
+    WHERE seq > $2 AND changed_at >= horizon.started_at
+)
+SELECT c.seq, c.tenant_id, c.document_id, c.job_id, c.url, c.change_type, c.visibility, c.created_by_user_id, c.api_key_id, c.changed_at,
+       ` + content + `
+FROM document_changes c
+` + join + `
+WHERE c.tenant_id = $1
+  AND c.seq > $2
+  AND c.seq < COALESCE((SELECT seq FROM held), 9223372036854775807)`
+	if viewer != nil {
+		query += " AND " + visibilityCondition(viewer, "c", &args, &argPos)
+	}
+	query += fmt.Sprintf(" ORDER BY c.seq LIMIT $%d", argPos)
+	args = append(args, limit)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []DocumentChangeEntry
+	for rows.Next() {
+		var (
+			e        DocumentChangeEntry
+			docID    sql.NullInt64
+			markdown sql.NullString
+			metadata []byte
+			status   sql.NullInt32
+			created  sql.NullTime
+			docType  sql.NullString
+		)
+		if err := rows.Scan(
+			&e.Seq,
+			&e.TenantID,
+			&e.DocumentID,
+			&e.JobID,
+			&e.Url,
+			&e.ChangeType,
+			&e.Visibility,
+			&e.CreatedByUserID,
+			&e.ApiKeyID,
+			&e.ChangedAt,
+			&docID,
+			&markdown,
+			&metadata,
+			&status,
+			&created,
+			&docType,
+		); err != nil {
+			return nil, err
+		}
+		if docID.Valid {
+			e.Document = &db.Document{
+				ID:         docID.Int64,
+				JobID:      e.JobID,
+				Url:        e.Url,
+				Markdown:   markdown,
+				Metadata:   metadata,
+				StatusCode: status,
+				CreatedAt:  created.Time,
+				Type:       docType.String,
+			}
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// CompactDocumentChanges drops change feed rows recorded before cutoff
+// that later changes supersede, and deletes recorded before cutoff.
+func (s *Store) CompactDocumentChanges(ctx context.Context, cutoff time.Time) (int64, error) {
+	var n int64
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		n, err = q.CompactDocumentChanges(ctx, cutoff)
+		return err
+	})
+	return n, err
+}
+
+// RecordDocumentVersion adds a scraped page to its tenant's version
+// history of url. When the markdown matches the latest version recorded
+// for the same audience, that version is marked as seen again instead.
+// Only the newest keep versions of the URL are kept. Jobs without a
+// tenant and zero-retention jobs keep no history.
+func (s *Store) RecordDocumentVersion(ctx context.Context, jobID uuid.UUID, url, markdown, title string, statusCode int32, keep int) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := db.New(tx)
+	job, err := q.GetJobByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if !job.TenantID.Valid || job.ZeroRetention {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(markdown))
+	hash := hex.EncodeToString(sum[:])
+	sc := sql.NullInt32{Int32: statusCode, Valid: statusCode != 0}
+
+	latest, err := q.GetLatestDocumentVersion(ctx, db.GetLatestDocumentVersionParams{
+		TenantID:        job.TenantID.UUID,
+		Url:             url,
+		Visibility:      job.Visibility,
+		CreatedByUserID: job.CreatedByUserID,
+		ApiKeyID:        job.ApiKeyID,
+	})
+	switch {
+	case err == nil && latest.ContentHash == hash:
+		if err := q.TouchDocumentVersion(ctx, db.TouchDocumentVersionParams{
+			ID:         latest.ID,
+			JobID:      uuid.NullUUID{UUID: jobID, Valid: true},
+			StatusCode: sc,
+		}); err != nil {
+			return err
+		}
+		return tx.Commit()
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	if err := q.InsertDocumentVersion(ctx, db.InsertDocumentVersionParams{
+		TenantID:        job.TenantID.UUID,
+		Url:             url,
+		ContentHash:     hash,
+		Markdown:        markdown,
+		Title:           sql.NullString{String: title, Valid: title != ""},
+		StatusCode:      sc,
+		JobID:           uuid.NullUUID{UUID: jobID, Valid: true},
+		Visibility:      job.Visibility,
+		CreatedByUserID: job.CreatedByUserID,
+		ApiKeyID:        job.ApiKeyID,
+	}); err != nil {
+		return err
+	}
+	if keep > 0 {
+		if _, err := q.PruneDocumentVersions(ctx, db.PruneDocumentVersionsParams{
+			TenantID: job.TenantID.UUID,
+			Url:      url,
+			Limit:    int32(keep),
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListDocumentVersions returns up to limit versions of url in tenantID's
+// history, newest first, skipping versions of private jobs the viewer
+// cannot see.
+func (s *Store) ListDocumentVersions(ctx context.Context, tenantID uuid.UUID, viewer *JobViewer, url string, limit int32) ([]db.DocumentVersion, error) {
+	args := []any{tenantID, url}
+	argPos := 3
+
+	query := `
+SELECT v.id, v.tenant_id, v.url, v.content_hash, v.markdown, v.title, v.status_code, v.job_id, v.visibility, v.created_by_user_id, v.api_key_id, v.created_at, v.last_seen_at
+FROM document_versions v
+WHERE v.tenant_id = $1 AND v.url = $2`
+	if viewer != nil {
+		query += " AND " + visibilityCondition(viewer, "v", &args, &argPos)
+	}
+	query += fmt.Sprintf(" ORDER BY v.id DESC LIMIT $%d", argPos)
+	args = append(args, limit)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []db.DocumentVersion
+	for rows.Next() {
+		var v db.DocumentVersion
+		if err := rows.Scan(
+			&v.ID,
+			&v.TenantID,
+			&v.Url,
+			&v.ContentHash,
+			&v.Markdown,
+			&v.Title,
+			&v.StatusCode,
+			&v.JobID,
+			&v.Visibility,
+			&v.CreatedByUserID,
+			&v.ApiKeyID,
+			&v.CreatedAt,
+			&v.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// DeleteExpiredDocumentVersions deletes document versions whose content
+// has not been seen since cutoff.
+func (s *Store) DeleteExpiredDocumentVersions(ctx context.Context, cutoff time.Time) (int64, error) {
+	var n int64
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		n, err = q.DeleteExpiredDocumentVersions(ctx, cutoff)
+		return err
+	})
+	return n, err
+}
+
 // GetJobByID fetches a single job row by its ID.
 func (s *Store) GetJobByID(ctx context.Context, id uuid.UUID) (db.Job, error) {
 	var job db.Job
@@ -326,20 +1349,30 @@ func (s *Store) GetJobByID(ctx context.Context, id uuid.UUID) (db.Job, error) {
 		}
 
 		job = db.Job{
-			ID:          row.ID,
-			Type:        row.Type,
-			Status:      row.Status,
-			Url:         row.Url,
-			Input:       row.Input,
-			Error:       row.Error,
-			CreatedAt:   row.CreatedAt,
-			UpdatedAt:   row.UpdatedAt,
-			CompletedAt: row.CompletedAt,
-			Priority:    row.Priority,
-			Sync:        row.Sync,
-			Output:      row.Output,
-			TenantID:    row.TenantID,
-			ApiKeyID:    row.ApiKeyID,
+			ID:              row.ID,
+			Type:            row.Type,
+			Status:          row.Status,
+			Url:             row.Url,
+			Input:           row.Input,
+			Error:           row.Error,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+			CompletedAt:     row.CompletedAt,
+			Priority:        row.Priority,
+			Sync:            row.Sync,
+			Output:          row.Output,
+			TenantID:        row.TenantID,
+			ApiKeyID:        row.ApiKeyID,
+			CreatedByUserID: row.CreatedByUserID,
+			Visibility:      row.Visibility,
+			CollectionID:    row.CollectionID,
+			PreviousJobID:   row.PreviousJobID,
+			Metrics:         row.Metrics,
+			Pool:            row.Pool,
+			ZeroRetention:   row.ZeroRetention,
+			PurgedAt:        row.PurgedAt,
+			AppliedOptions:  row.AppliedOptions,
+			Pinned:          row.Pinned,
 		}
 		return nil
 	})
@@ -360,9 +1393,104 @@ func (s *Store) SetJobOutput(ctx context.Context, id uuid.UUID, output json.RawM
 	})
 }
 
-// DeleteExpiredDocuments deletes documents older than the given cutoff timestamp.
+// FindPreviousCrawlJob returns the ID of the most recently completed crawl
+// of the same root URL in the tenant, excluding the given job. Zero-retention
+// crawls are skipped since their documents are deleted.
+func (s *Store) FindPreviousCrawlJob(ctx context.Context, tenantID uuid.NullUUID, rootURL string, excludeID uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT id
+		FROM jobs
+		WHERE type = 'crawl'
+		  AND status = 'completed'
+		  AND url = $1
+		  AND tenant_id IS NOT DISTINCT FROM $2
+		  AND id <> $3
+		  AND NOT zero_retention
+		ORDER BY completed_at DESC NULLS LAST, created_at DESC
+		LIMIT 1`, rootURL, tenantID, excludeID).Scan(&id)
+	return id, err
+}
+
+// SetJobPreviousJob links a job to the earlier job it was built on, such as
+// the baseline of an incremental crawl.
+func (s *Store) SetJobPreviousJob(ctx context.Context, id, previousID uuid.UUID) error {
+	_, err := s.DB.ExecContext(ctx, `UPDATE jobs SET previous_job_id = $2, updated_at = NOW() WHERE id = $1`, id, previousID)
+	return err
+}
+
+// SetJobPinned pins or unpins a job. Pinned jobs and their documents are
+// skipped by TTL retention cleanup.
+func (s *Store) SetJobPinned(ctx context.Context, id uuid.UUID, pinned bool) error {
+	return s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		return q.SetJobPinned(ctx, db.SetJobPinnedParams{ID: id, Pinned: pinned})
+	})
+}
+
+// SetJobMetrics stores the runtime resource metrics recorded for a job.
+func (s *Store) SetJobMetrics(ctx context.Context, id uuid.UUID, metrics json.RawMessage) error {
+	_, err := s.DB.ExecContext(ctx, `UPDATE jobs SET metrics = $2 WHERE id = $1`, id, metrics)
+	return err
+}
+
+// PurgeJobData deletes the documents and assets of a zero-retention job
+// and clears its input and output, keeping only the job row's metadata
+// (type, URL, status, timestamps) for auditing.
+func (s *Store) PurgeJobData(ctx context.Context, id uuid.UUID) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE job_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM job_assets WHERE job_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE jobs
+		SET input = '{}'::jsonb, output = NULL, purged_at = NOW(), updated_at = NOW()
+		WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListUnpurgedZeroRetentionJobs returns zero-retention jobs that finished
+// before the cutoff and still hold data.
+func (s *Store) ListUnpurgedZeroRetentionJobs(ctx context.Context, cutoff time.Time) ([]uuid.UUID, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id
+		FROM jobs
+		WHERE zero_retention
+		  AND purged_at IS NULL
+		  AND status IN ('completed', 'failed', 'dead_letter')
+		  AND completed_at < $1`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteExpiredDocuments deletes documents older than the given cutoff
+// timestamp, except those of pinned jobs.
 func (s *Store) DeleteExpiredDocuments(ctx context.Context, cutoff time.Time) (int64, error) {
-	res, err := s.DB.ExecContext(ctx, `DELETE FROM documents WHERE created_at < $1`, cutoff)
+	res, err := s.DB.ExecContext(ctx, `
+DELETE FROM documents
+WHERE created_at < $1
+  AND NOT EXISTS (SELECT 1 FROM jobs WHERE jobs.id = documents.job_id AND jobs.pinned)`, cutoff)
 	if err != nil {
 		return 0, err
 	}
@@ -370,9 +1498,152 @@ func (s *Store) DeleteExpiredDocuments(ctx context.Context, cutoff time.Time) (i
 	return rows, nil
 }
 
-// DeleteExpiredJobsByType deletes jobs of the given type older than the cutoff.
+// DeleteExpiredSessions deletes browser sessions that expired before the cutoff.
+func (s *Store) DeleteExpiredSessions(ctx context.Context, cutoff time.Time) (int64, error) {
+	var n int64
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		n, err = q.DeleteExpiredSessions(ctx, cutoff)
+		return err
+	})
+	return n, err
+}
+
+// dbMaintenanceLockKey is the advisory lock key held while a database
+// maintenance run analyzes tables, so concurrent workers skip the run.
+const dbMaintenanceLockKey = 0x7261_6974_6f01
+
+// AnalyzeTables runs ANALYZE on each table within one transaction that
+// holds the maintenance advisory lock. It reports false without analyzing
+// anything when another process holds the lock.
+func (s *Store) AnalyzeTables(ctx context.Context, tables []string) (bool, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, int64(dbMaintenanceLockKey)).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	for _, t := range tables {
+		if _, err := tx.ExecContext(ctx, `ANALYZE "`+strings.ReplaceAll(t, `"`, `""`)+`"`); err != nil {
+			return true, fmt.Errorf("analyze %s: %w", t, err)
+		}
+	}
+	return true, tx.Commit()
+}
+
+// PutJobAsset stores a binary asset (such as an archived image) for a job
+// and returns its ID. Storing the same source URL twice for one job returns
+// the existing asset.
+func (s *Store) PutJobAsset(ctx context.Context, jobID uuid.UUID, sourceURL, contentType string, data []byte) (uuid.UUID, error) {
+	sum := sha256.Sum256(data)
+	var id uuid.UUID
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		id, err = q.InsertJobAsset(ctx, db.InsertJobAssetParams{
+			ID:          uuid.New(),
+			JobID:       jobID,
+			SourceUrl:   sourceURL,
+			ContentType: contentType,
+			SizeBytes:   int64(len(data)),
+			Sha256:      hex.EncodeToString(sum[:]),
+			Data:        data,
+		})
+		return err
+	})
+	return id, err
+}
+
+// GetJobAsset returns a single asset belonging to the given job.
+func (s *Store) GetJobAsset(ctx context.Context, jobID, assetID uuid.UUID) (db.JobAsset, error) {
+	var asset db.JobAsset
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		asset, err = q.GetJobAsset(ctx, db.GetJobAssetParams{ID: assetID, JobID: jobID})
+		return err
+	})
+	return asset, err
+}
+
+// DocumentAnnotations returns the annotations of the given documents,
+// keyed by document ID.
+func (s *Store) DocumentAnnotations(ctx context.Context, docs []db.Document) (map[int64]db.DocumentAnnotation, error) {
+	out := make(map[int64]db.DocumentAnnotation)
+	if len(docs) == 0 {
+		return out, nil
+	}
+	ids := make([]int64, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		rows, err := q.ListDocumentAnnotationsByDocumentIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, a := range rows {
+			out[a.DocumentID] = a
+		}
+		return nil
+	})
+	return out, err
+}
+
+// ListJobAssets returns every asset stored for a job.
+func (s *Store) ListJobAssets(ctx context.Context, jobID uuid.UUID) ([]db.JobAsset, error) {
+	var assets []db.JobAsset
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		assets, err = q.ListJobAssetsByJob(ctx, jobID)
+		return err
+	})
+	return assets, err
+}
+
+// GetExtractCacheEntry returns a cached extract result for key when it has
+// not expired and was written at or after notBefore.
+func (s *Store) GetExtractCacheEntry(ctx context.Context, key string, notBefore time.Time) (db.ExtractCache, error) {
+	var entry db.ExtractCache
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		entry, err = q.GetExtractCacheEntry(ctx, db.GetExtractCacheEntryParams{
+			CacheKey:  key,
+			CreatedAt: notBefore,
+		})
+		return err
+	})
+	return entry, err
+}
+
+// PutExtractCacheEntry stores (or replaces) a cached extract result.
+func (s *Store) PutExtractCacheEntry(ctx context.Context, params db.UpsertExtractCacheEntryParams) error {
+	return s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		return q.UpsertExtractCacheEntry(ctx, params)
+	})
+}
+
+// DeleteExpiredExtractCache removes cached extract results that expired
+// before the cutoff.
+func (s *Store) DeleteExpiredExtractCache(ctx context.Context, cutoff time.Time) (int64, error) {
+	var n int64
+	err := s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		var err error
+		n, err = q.DeleteExpiredExtractCacheEntries(ctx, cutoff)
+		return err
+	})
+	return n, err
+}
+
+// DeleteExpiredJobsByType deletes unpinned jobs of the given type older
+// than the cutoff.
 func (s *Store) DeleteExpiredJobsByType(ctx context.Context, jobType string, cutoff time.Time) (int64, error) {
-	res, err := s.DB.ExecContext(ctx, `DELETE FROM jobs WHERE type = $1 AND created_at < $2`, jobType, cutoff)
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM jobs WHERE type = $1 AND created_at < $2 AND NOT pinned`, jobType, cutoff)
 	if err != nil {
 		return 0, err
 	}
@@ -480,3 +1751,79 @@ func (s *Store) CreateRandomAPIKey(ctx context.Context, label string, isAdmin bo
 
 	return raw, out, err
 }
+
+// TouchAPIKeyUsage records that an API key authenticated a request.
+func (s *Store) TouchAPIKeyUsage(ctx context.Context, id uuid.UUID) error {
+	return s.withQueries(ctx, func(ctx context.Context, q *db.Queries) error {
+		return q.TouchAPIKeyUsage(ctx, id)
+	})
+}
+
+// SetAPIKeyCreatedBy records which user created an API key.
+func (s *Store) SetAPIKeyCreatedBy(ctx context.Context, id, userID uuid.UUID) error {
+	_, err := s.DB.ExecContext(ctx, `UPDATE api_keys SET created_by_user_id = $2 WHERE id = $1`, id, userID)
+	return err
+}
+
+// RevokeTenantAPIKeys revokes the given active keys of a tenant and
+// returns the IDs that were revoked. Keys of other tenants are ignored.
+func (s *Store) RevokeTenantAPIKeys(ctx context.Context, tenantID string, ids []uuid.UUID) ([]uuid.UUID, error) {
+	idStrs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		idStrs = append(idStrs, id.String())
+	}
+	rows, err := s.DB.QueryContext(ctx, `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE tenant_id = $1 AND id = ANY($2::uuid[]) AND revoked_at IS NULL
+		RETURNING id`, tenantID, idStrs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// CreateAPIKeyReveal stores rawKey behind a one-time token that expires
+// after ttl and returns the token.
+func (s *Store) CreateAPIKeyReveal(ctx context.Context, keyID uuid.UUID, rawKey string, ttl time.Duration) (string, time.Time, error) {
+	token := "reveal_" + uuid.New().String()
+	expiresAt := time.Now().UTC().Add(ttl)
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO api_key_reveals (token_hash, api_key_id, raw_key, expires_at)
+		VALUES ($1, $2, $3, $4)`, hashAPIKey(token), keyID, rawKey, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ConsumeAPIKeyReveal returns the raw key behind a reveal token for an
+// active key of the tenant and deletes the token so it cannot be used
+// again. Expired tokens are purged; unknown, expired, or foreign tokens
+// yield sql.ErrNoRows.
+func (s *Store) ConsumeAPIKeyReveal(ctx context.Context, token, tenantID string) (db.ApiKeyReveal, error) {
+	_, _ = s.DB.ExecContext(ctx, `DELETE FROM api_key_reveals WHERE expires_at <= NOW()`)
+
+	var r db.ApiKeyReveal
+	err := s.DB.QueryRowContext(ctx, `
+		DELETE FROM api_key_reveals r
+		USING api_keys k
+		WHERE r.token_hash = $1
+		  AND r.expires_at > NOW()
+		  AND k.id = r.api_key_id
+		  AND k.tenant_id = $2
+		  AND k.revoked_at IS NULL
+		RETURNING r.token_hash, r.api_key_id, r.raw_key, r.expires_at, r.created_at`, hashAPIKey(token), tenantID).
+		Scan(&r.TokenHash, &r.ApiKeyID, &r.RawKey, &r.ExpiresAt, &r.CreatedAt)
+	return r, err
+}
@@ -0,0 +1,317 @@
+package structured
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// typeRule lists the properties a schema.org type needs to be eligible
+// for rich results. AnyOf groups need at least one of their properties.
+type typeRule struct {
+	Required    []string
+	AnyOf       [][]string
+	Recommended []string
+}
+
+var articleRule = typeRule{
+	Required:    []string{"headline"},
+	Recommended: []string{"author", "datePublished", "image"},
+}
+
+var organizationRule = typeRule{
+	Recommended: []string{"name", "url", "logo"},
+}
+
+var localBusinessRule = typeRule{
+	Required:    []string{"name", "address"},
+	Recommended: []string{"telephone", "url", "openingHoursSpecification"},
+}
+
+// typeRules covers the schema.org types with rich result requirements.
+// Other types are only checked for well-formed property values.
+var typeRules = map[string]typeRule{
+	"Article":             articleRule,
+	"NewsArticle":         articleRule,
+	"BlogPosting":         articleRule,
+	"Product":             {Required: []string{"name"}, AnyOf: [][]string{{"offers", "review", "aggregateRating"}}, Recommended: []string{"image", "description", "brand", "sku"}},
+	"Offer":               {AnyOf: [][]string{{"price", "priceSpecification"}}, Required: []string{"priceCurrency"}, Recommended: []string{"availability", "url"}},
+	"AggregateOffer":      {Required: []string{"lowPrice", "priceCurrency"}, Recommended: []string{"highPrice", "offerCount"}},
+	"AggregateRating":     {Required: []string{"ratingValue"}, AnyOf: [][]string{{"ratingCount", "reviewCount"}}},
+	"Rating":              {Required: []string{"ratingValue"}},
+	"Review":              {Required: []string{"author"}, Recommended: []string{"reviewRating", "datePublished"}},
+	"Person":              {Required: []string{"name"}},
+	"Organization":        organizationRule,
+	"Corporation":         organizationRule,
+	"LocalBusiness":       localBusinessRule,
+	"Restaurant":          localBusinessRule,
+	"Store":               localBusinessRule,
+	"BreadcrumbList":      {Required: []string{"itemListElement"}},
+	"ListItem":            {Required: []string{"position"}, Recommended: []string{"name", "item"}},
+	"Event":               {Required: []string{"name", "startDate", "location"}, Recommended: []string{"endDate", "description", "image", "offers"}},
+	"Recipe":              {Required: []string{"name", "image"}, Recommended: []string{"recipeIngredient", "recipeInstructions", "author"}},
+	"FAQPage":             {Required: []string{"mainEntity"}},
+	"Question":            {Required: []string{"name", "acceptedAnswer"}},
+	"Answer":              {Required: []string{"text"}},
+	"HowTo":               {Required: []string{"name", "step"}},
+	"JobPosting":          {Required: []string{"title", "description", "datePosted", "hiringOrganization"}, Recommended: []string{"validThrough", "jobLocation", "employmentType"}},
+	"VideoObject":         {Required: []string{"name", "thumbnailUrl", "uploadDate"}, Recommended: []string{"description", "contentUrl", "duration"}},
+	"SoftwareApplication": {Required: []string{"name"}, Recommended: []string{"offers", "aggregateRating", "operatingSystem", "applicationCategory"}},
+	"WebSite":             {Recommended: []string{"name", "url"}},
+	"ImageObject":         {AnyOf: [][]string{{"contentUrl", "url"}}},
+	"PostalAddress":       {Recommended: []string{"streetAddress", "addressLocality", "addressCountry"}},
+}
+
+// expectedTypes lists the types a nested object may have for properties
+// whose value should be an item. Subtypes named after them, such as
+// NewsMediaOrganization, also match. Plain strings are accepted unless the
+// property is in itemOnlyProperties.
+var expectedTypes = map[string][]string{
+	"author":             {"Person", "Organization"},
+	"publisher":          {"Organization", "Person"},
+	"brand":              {"Brand", "Organization"},
+	"offers":             {"Offer", "AggregateOffer"},
+	"aggregateRating":    {"AggregateRating"},
+	"review":             {"Review"},
+	"reviewRating":       {"Rating", "AggregateRating"},
+	"address":            {"PostalAddress"},
+	"hiringOrganization": {"Organization"},
+	"acceptedAnswer":     {"Answer"},
+	"suggestedAnswer":    {"Answer"},
+	"itemListElement":    {"ListItem"},
+	"image":              {"ImageObject"},
+	"logo":               {"ImageObject"},
+	"step":               {"HowToStep", "HowToSection"},
+}
+
+// itemOnlyProperties must be items, not plain text.
+var itemOnlyProperties = map[string]bool{
+	"offers":          true,
+	"aggregateRating": true,
+	"reviewRating":    true,
+	"acceptedAnswer":  true,
+	"itemListElement": true,
+}
+
+var (
+	dateProperties = map[string]bool{
+		"datePublished": true, "dateModified": true, "dateCreated": true,
+		"startDate": true, "endDate": true, "uploadDate": true,
+		"datePosted": true, "validThrough": true, "priceValidUntil": true,
+	}
+	urlProperties = map[string]bool{
+		"url": true, "sameAs": true, "thumbnailUrl": true, "contentUrl": true,
+		"embedUrl": true, "image": true, "logo": true, "item": true,
+	}
+	numberProperties = map[string]bool{
+		"price": true, "lowPrice": true, "highPrice": true,
+		"ratingValue": true, "bestRating": true, "worstRating": true,
+		"ratingCount": true, "reviewCount": true, "position": true,
+		"offerCount": true,
+	}
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	availability    = map[string]bool{
+		"InStock": true, "OutOfStock": true, "PreOrder": true, "BackOrder": true,
+		"Discontinued": true, "InStoreOnly": true, "OnlineOnly": true,
+		"LimitedAvailability": true, "SoldOut": true, "PreSale": true,
+		"MadeToOrder": true, "Reserved": true,
+	}
+)
+
+// checkObject validates obj, typed as types, and its nested items. path is
+// the dotted property path of obj within its top-level item.
+func checkObject(obj map[string]any, types []string, path string) []Issue {
+	var issues []Issue
+	if t, rule, ok := ruleFor(types); ok {
+		for _, p := range rule.Required {
+			if !hasValue(obj[p]) {
+				issues = append(issues, Issue{
+					Property: joinPath(path, p),
+					Severity: SeverityError,
+					Code:     CodeMissingProperty,
+					Message:  fmt.Sprintf("%s is missing required property %s", t, p),
+				})
+			}
+		}
+		for _, group := range rule.AnyOf {
+			found := false
+			for _, p := range group {
+				if hasValue(obj[p]) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				issues = append(issues, Issue{
+					Property: joinPath(path, strings.Join(group, "|")),
+					Severity: SeverityError,
+					Code:     CodeMissingProperty,
+					Message:  fmt.Sprintf("%s needs one of %s", t, strings.Join(group, ", ")),
+				})
+			}
+		}
+		for _, p := range rule.Recommended {
+			if !hasValue(obj[p]) {
+				issues = append(issues, Issue{
+					Property: joinPath(path, p),
+					Severity: SeverityWarning,
+					Code:     CodeMissingRecommend,
+					Message:  fmt.Sprintf("%s is missing recommended property %s", t, p),
+				})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		if !strings.HasPrefix(name, "@") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		raw := obj[name]
+		values, ok := raw.([]any)
+		if !ok {
+			values = []any{raw}
+		}
+		for _, v := range values {
+			issues = append(issues, checkValue(name, v, joinPath(path, name))...)
+		}
+	}
+	return issues
+}
+
+// ruleFor returns the rule of the first type that has one.
+func ruleFor(types []string) (string, typeRule, bool) {
+	for _, t := range types {
+		if rule, ok := typeRules[t]; ok {
+			return t, rule, true
+		}
+	}
+	return "", typeRule{}, false
+}
+
+func checkValue(name string, v any, path string) []Issue {
+	invalid := func(msg string) []Issue {
+		return []Issue{{Property: path, Severity: SeverityError, Code: CodeInvalidValue, Message: msg}}
+	}
+
+	if nested, ok := v.(map[string]any); ok {
+		types := itemTypes(nested)
+		if want, ok := expectedTypes[name]; ok && len(types) > 0 && !anyIn(types, want) {
+			return []Issue{{
+				Property: path,
+				Severity: SeverityError,
+				Code:     CodeInvalidType,
+				Message:  fmt.Sprintf("%s should be a %s, not %s", name, strings.Join(want, " or "), strings.Join(types, ", ")),
+			}}
+		}
+		return checkObject(nested, types, path)
+	}
+
+	s, isString := v.(string)
+	if itemOnlyProperties[name] && isString {
+		return invalid(fmt.Sprintf("%s should be a %s item, not text", name, strings.Join(expectedTypes[name], " or ")))
+	}
+	switch {
+	case dateProperties[name]:
+		if !isString || !validDate(s) {
+			return invalid(fmt.Sprintf("%s should be an ISO 8601 date, got %v", name, v))
+		}
+	case urlProperties[name]:
+		if isString && !validURL(s) {
+			return invalid(fmt.Sprintf("%s should be a URL, got %q", name, s))
+		}
+	case numberProperties[name]:
+		if !validNumber(v) {
+			return invalid(fmt.Sprintf("%s should be a number, got %v", name, v))
+		}
+	case name == "priceCurrency":
+		if !isString || !currencyPattern.MatchString(s) {
+			return invalid(fmt.Sprintf("priceCurrency should be an ISO 4217 code such as USD, got %v", v))
+		}
+	case name == "availability":
+		if !isString || !availability[shortType(s)] {
+			return invalid(fmt.Sprintf("availability should be a schema.org ItemAvailability value such as https://schema.org/InStock, got %v", v))
+		}
+	}
+	return nil
+}
+
+func hasValue(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(t) != ""
+	case []any:
+		for _, e := range t {
+			if hasValue(e) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func validDate(s string) bool {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02", "2006-01", "2006"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validURL accepts absolute http(s) URLs and relative references that
+// look like paths, which are resolved against the page.
+func validURL(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "" {
+		return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	}
+	return strings.ContainsAny(s, "/.")
+}
+
+func validNumber(v any) bool {
+	switch t := v.(type) {
+	case float64:
+		return true
+	case string:
+		_, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return err == nil
+	}
+	return false
+}
+
+func anyIn(values, set []string) bool {
+	for _, v := range values {
+		for _, s := range set {
+			if v == s || strings.HasSuffix(v, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
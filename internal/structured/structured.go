@@ -0,0 +1,355 @@
+// Package structured extracts schema.org structured data (JSON-LD and
+// microdata) from HTML and checks it against the properties search
+// engines expect.
+package structured
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Sources of structured data items.
+const (
+	SourceJSONLD    = "json-ld"
+	SourceMicrodata = "microdata"
+)
+
+// Issue severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue codes.
+const (
+	CodeInvalidJSON      = "invalid_json"
+	CodeMissingType      = "missing_type"
+	CodeMissingContext   = "missing_context"
+	CodeMissingProperty  = "missing_property"
+	CodeMissingRecommend = "missing_recommended_property"
+	CodeInvalidType      = "invalid_type"
+	CodeInvalidValue     = "invalid_value"
+)
+
+// Item is one top-level structured data item found on a page.
+type Item struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+}
+
+// Issue is a problem with a page's structured data. Type is the top-level
+// item it was found in and Property the dotted path to the offending
+// property, e.g. "offers.price".
+type Issue struct {
+	Source   string `json:"source"`
+	Type     string `json:"type,omitempty"`
+	Property string `json:"property,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// Report is the structured data found on a page and its issues.
+type Report struct {
+	Items  []Item  `json:"items"`
+	Issues []Issue `json:"issues"`
+}
+
+// Errors counts the issues with error severity.
+func (r Report) Errors() int {
+	n := 0
+	for _, is := range r.Issues {
+		if is.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Warnings counts the issues with warning severity.
+func (r Report) Warnings() int {
+	return len(r.Issues) - r.Errors()
+}
+
+// Types returns the distinct item types on the page, sorted.
+func (r Report) Types() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, it := range r.Items {
+		if it.Type != "" && !seen[it.Type] {
+			seen[it.Type] = true
+			out = append(out, it.Type)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Validate extracts the JSON-LD and microdata items in html and checks
+// each of them.
+func Validate(html string) Report {
+	report := Report{Items: []Item{}, Issues: []Issue{}}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return report
+	}
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			return
+		}
+		var v any
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			report.Issues = append(report.Issues, Issue{
+				Source:   SourceJSONLD,
+				Severity: SeverityError,
+				Code:     CodeInvalidJSON,
+				Message:  "JSON-LD block is not valid JSON: " + err.Error(),
+			})
+			return
+		}
+		for _, obj := range jsonLDNodes(v) {
+			report.check(SourceJSONLD, obj)
+		}
+	})
+
+	doc.Find("[itemscope]").Each(func(_ int, s *goquery.Selection) {
+		// Items with itemprop are properties of an enclosing item.
+		if _, nested := s.Attr("itemprop"); nested {
+			return
+		}
+		report.check(SourceMicrodata, microdataItem(s))
+	})
+	return report
+}
+
+// HasJSONLD reports whether html embeds a non-empty JSON-LD block.
+func HasJSONLD(html string) bool {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return false
+	}
+	found := false
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		found = strings.TrimSpace(s.Text()) != ""
+		return !found
+	})
+	return found
+}
+
+// Extract returns the JSON-LD nodes and top-level microdata items in
+// html as JSON objects, JSON-LD first. JSON-LD arrays and @graph
+// containers are flattened; blocks that are not valid JSON are skipped.
+func Extract(html string) []map[string]any {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil
+	}
+	var out []map[string]any
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var v any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s.Text())), &v); err != nil {
+			return
+		}
+		out = append(out, jsonLDNodes(v)...)
+	})
+	doc.Find("[itemscope]").Each(func(_ int, s *goquery.Selection) {
+		if _, nested := s.Attr("itemprop"); nested {
+			return
+		}
+		out = append(out, microdataItem(s))
+	})
+	return out
+}
+
+func (r *Report) check(source string, obj map[string]any) {
+	types := itemTypes(obj)
+	top := ""
+	if len(types) > 0 {
+		top = types[0]
+	}
+	r.Items = append(r.Items, Item{Type: top, Source: source})
+	if top == "" {
+		r.Issues = append(r.Issues, Issue{
+			Source:   source,
+			Severity: SeverityError,
+			Code:     CodeMissingType,
+			Message:  "item has no @type",
+		})
+		return
+	}
+	if source == SourceJSONLD && !hasSchemaContext(obj) {
+		r.Issues = append(r.Issues, Issue{
+			Source:   source,
+			Type:     top,
+			Severity: SeverityWarning,
+			Code:     CodeMissingContext,
+			Message:  "@context should be https://schema.org",
+		})
+	}
+	for _, is := range checkObject(obj, types, "") {
+		is.Source = source
+		is.Type = top
+		r.Issues = append(r.Issues, is)
+	}
+}
+
+// jsonLDNodes returns the top-level objects of a JSON-LD value, unwrapping
+// arrays and @graph. Objects in a @graph inherit the outer @context.
+func jsonLDNodes(v any) []map[string]any {
+	switch t := v.(type) {
+	case []any:
+		var out []map[string]any
+		for _, e := range t {
+			out = append(out, jsonLDNodes(e)...)
+		}
+		return out
+	case map[string]any:
+		graph, ok := t["@graph"].([]any)
+		if !ok {
+			return []map[string]any{t}
+		}
+		var out []map[string]any
+		for _, e := range graph {
+			obj, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			if _, has := obj["@context"]; !has && t["@context"] != nil {
+				obj["@context"] = t["@context"]
+			}
+			out = append(out, obj)
+		}
+		return out
+	}
+	return nil
+}
+
+func hasSchemaContext(obj map[string]any) bool {
+	var contexts []any
+	switch c := obj["@context"].(type) {
+	case []any:
+		contexts = c
+	default:
+		contexts = []any{c}
+	}
+	for _, c := range contexts {
+		switch t := c.(type) {
+		case string:
+			if strings.Contains(t, "schema.org") {
+				return true
+			}
+		case map[string]any:
+			if v, ok := t["@vocab"].(string); ok && strings.Contains(v, "schema.org") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// itemTypes returns an object's types with any schema.org prefix removed.
+func itemTypes(obj map[string]any) []string {
+	var raw []any
+	switch t := obj["@type"].(type) {
+	case string:
+		raw = []any{t}
+	case []any:
+		raw = t
+	}
+	var out []string
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			if s = shortType(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func shortType(t string) string {
+	t = strings.TrimSpace(t)
+	t = strings.TrimPrefix(t, "schema:")
+	if i := strings.LastIndex(t, "schema.org/"); i >= 0 {
+		t = t[i+len("schema.org/"):]
+	}
+	return strings.Trim(t, "/")
+}
+
+// microdataItem converts an itemscope element into the same shape as a
+// JSON-LD object: "@type" plus one entry per itemprop, holding a list when
+// the property repeats.
+func microdataItem(s *goquery.Selection) map[string]any {
+	obj := map[string]any{}
+	if t := strings.Fields(s.AttrOr("itemtype", "")); len(t) > 0 {
+		types := make([]any, 0, len(t))
+		for _, v := range t {
+			types = append(types, v)
+		}
+		obj["@type"] = types
+	}
+	collectMicrodata(s, obj)
+	return obj
+}
+
+func collectMicrodata(s *goquery.Selection, obj map[string]any) {
+	s.Children().Each(func(_ int, child *goquery.Selection) {
+		_, scoped := child.Attr("itemscope")
+		names, hasProp := child.Attr("itemprop")
+		if !hasProp {
+			if !scoped {
+				collectMicrodata(child, obj)
+			}
+			return
+		}
+		var value any
+		if scoped {
+			value = microdataItem(child)
+		} else {
+			value = microdataValue(child)
+			collectMicrodata(child, obj)
+		}
+		for _, name := range strings.Fields(names) {
+			switch existing := obj[name].(type) {
+			case nil:
+				obj[name] = value
+			case []any:
+				obj[name] = append(existing, value)
+			default:
+				obj[name] = []any{existing, value}
+			}
+		}
+	})
+}
+
+func microdataValue(s *goquery.Selection) string {
+	var attr string
+	switch goquery.NodeName(s) {
+	case "meta":
+		attr = "content"
+	case "a", "link", "area":
+		attr = "href"
+	case "img", "audio", "video", "source", "iframe", "embed", "track":
+		attr = "src"
+	case "object":
+		attr = "data"
+	case "time":
+		attr = "datetime"
+	case "data", "meter":
+		attr = "value"
+	}
+	if attr != "" {
+		if v, ok := s.Attr(attr); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	if v, ok := s.Attr("content"); ok {
+		return strings.TrimSpace(v)
+	}
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
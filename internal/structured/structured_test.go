@@ -0,0 +1,91 @@
+package structured
+
+import (
+	"testing"
+)
+
+func issueCodes(r Report) map[string]string {
+	out := map[string]string{}
+	for _, is := range r.Issues {
+		out[is.Property] = is.Code
+	}
+	return out
+}
+
+func TestValidate_JSONLD(t *testing.T) {
+	html := `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Product", "name": "Widget", "image": "https://example.com/w.png",
+ "offers": {"@type": "Offer", "price": "abc", "priceCurrency": "usd", "availability": "https://schema.org/InStock"},
+ "aggregateRating": {"@type": "Person", "name": "x"}}
+</script>
+<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [{"@type": "Article"}, {"name": "untyped"}]}</script>
+<script type="application/ld+json">{not json</script>
+</head><body></body></html>`
+
+	r := Validate(html)
+	if len(r.Items) != 3 {
+		t.Fatalf("expected 3 items, got %+v", r.Items)
+	}
+	codes := issueCodes(r)
+	for prop, want := range map[string]string{
+		"offers.price":         CodeInvalidValue,
+		"offers.priceCurrency": CodeInvalidValue,
+		"aggregateRating":      CodeInvalidType,
+		"headline":             CodeMissingProperty,
+		"description":          CodeMissingRecommend,
+	} {
+		if codes[prop] != want {
+			t.Fatalf("expected %s for %s, got %q in %+v", want, prop, codes[prop], r.Issues)
+		}
+	}
+	if _, ok := codes["offers.availability"]; ok {
+		t.Fatalf("expected a valid availability, got %+v", r.Issues)
+	}
+
+	var invalidJSON, missingType bool
+	for _, is := range r.Issues {
+		invalidJSON = invalidJSON || is.Code == CodeInvalidJSON
+		missingType = missingType || is.Code == CodeMissingType
+	}
+	if !invalidJSON || !missingType {
+		t.Fatalf("expected invalid JSON and missing type issues, got %+v", r.Issues)
+	}
+	if got := r.Types(); len(got) != 2 || got[0] != "Article" || got[1] != "Product" {
+		t.Fatalf("unexpected types %v", got)
+	}
+	if r.Errors()+r.Warnings() != len(r.Issues) || r.Errors() == 0 {
+		t.Fatalf("unexpected counts: %d errors, %d warnings", r.Errors(), r.Warnings())
+	}
+}
+
+func TestValidate_Microdata(t *testing.T) {
+	html := `<div itemscope itemtype="https://schema.org/Event">
+  <h1 itemprop="name">Launch</h1>
+  <time itemprop="startDate" datetime="next week">soon</time>
+  <div itemprop="location" itemscope itemtype="https://schema.org/Place"><span itemprop="name">Hall</span></div>
+</div>
+<div itemscope itemtype="https://schema.org/BreadcrumbList">
+  <div itemprop="itemListElement" itemscope itemtype="https://schema.org/ListItem">
+    <a itemprop="item" href="/docs"><span itemprop="name">Docs</span></a>
+    <meta itemprop="position" content="1">
+  </div>
+</div>`
+
+	r := Validate(html)
+	if len(r.Items) != 2 || r.Items[0].Type != "Event" || r.Items[0].Source != SourceMicrodata {
+		t.Fatalf("unexpected items %+v", r.Items)
+	}
+	codes := issueCodes(r)
+	if codes["startDate"] != CodeInvalidValue {
+		t.Fatalf("expected an invalid startDate, got %+v", r.Issues)
+	}
+	if _, ok := codes["location"]; ok {
+		t.Fatalf("expected location to be present, got %+v", r.Issues)
+	}
+	for _, is := range r.Issues {
+		if is.Type == "BreadcrumbList" && is.Severity == SeverityError {
+			t.Fatalf("expected a valid breadcrumb, got %+v", is)
+		}
+	}
+}
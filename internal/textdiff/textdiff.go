@@ -0,0 +1,253 @@
+// Package textdiff computes line-based diffs between two texts and renders
+// them in unified diff format.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxEdits bounds the edit distance searched for a shortest diff. Texts
+// that differ by more lines are diffed as the removal of the old middle
+// section and the insertion of the new one, which is still correct but
+// not minimal.
+const maxEdits = 2000
+
+// Kind is the kind of a diff operation.
+type Kind int
+
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// Op is one line of a diff: kept, removed from a, or inserted from b.
+type Op struct {
+	Kind Kind
+	Line string
+}
+
+// SplitLines splits text into lines without their line endings. A final
+// line ending does not start an empty line.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Lines returns the operations that turn a into b, using Myers' algorithm
+// after trimming the common prefix and suffix.
+func Lines(a, b []string) []Op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]Op, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		ops = append(ops, Op{Kind: Equal, Line: l})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, Op{Kind: Equal, Line: l})
+	}
+	return ops
+}
+
+// myers returns a shortest edit script from a to b, or a full replacement
+// when more than maxEdits edits are needed.
+func myers(a, b []string) []Op {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// trace[d] is v after d edits, kept for backtracking.
+	var trace [][]int
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		trace = append(trace, append([]int(nil), v...))
+	}
+	if !found {
+		return replace(a, b)
+	}
+
+	var rev []Op
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[offset+k-1] < prev[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, Op{Kind: Equal, Line: a[x]})
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, Op{Kind: Insert, Line: b[y]})
+		} else {
+			x--
+			rev = append(rev, Op{Kind: Delete, Line: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		rev = append(rev, Op{Kind: Equal, Line: a[x]})
+	}
+
+	ops := make([]Op, len(rev))
+	for i, op := range rev {
+		ops[len(rev)-1-i] = op
+	}
+	return ops
+}
+
+func replace(a, b []string) []Op {
+	ops := make([]Op, 0, len(a)+len(b))
+	for _, l := range a {
+		ops = append(ops, Op{Kind: Delete, Line: l})
+	}
+	for _, l := range b {
+		ops = append(ops, Op{Kind: Insert, Line: l})
+	}
+	return ops
+}
+
+// Stats counts the lines a diff inserts and deletes.
+func Stats(ops []Op) (added, removed int) {
+	for _, op := range ops {
+		switch op.Kind {
+		case Insert:
+			added++
+		case Delete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// Unified renders ops as a unified diff with the given number of context
+// lines around each change. It returns "" when the texts are equal.
+func Unified(ops []Op, fromName, toName string, context int) string {
+	context = max(context, 0)
+	var b strings.Builder
+	i := 0
+	for i < len(ops) {
+		// Find the next change and the hunk around it.
+		for i < len(ops) && ops[i].Kind == Equal {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == Equal {
+				run++
+			}
+			// Merge changes separated by at most 2*context equal lines.
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		aStart, bStart := lineNumbers(ops, start)
+		aLen, bLen := 0, 0
+		for _, op := range ops[start:end] {
+			if op.Kind != Insert {
+				aLen++
+			}
+			if op.Kind != Delete {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		for _, op := range ops[start:end] {
+			switch op.Kind {
+			case Equal:
+				b.WriteString(" ")
+			case Delete:
+				b.WriteString("-")
+			case Insert:
+				b.WriteString("+")
+			}
+			b.WriteString(op.Line)
+			b.WriteString("\n")
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// lineNumbers returns the 1-based lines in a and b at which ops[i] starts.
+func lineNumbers(ops []Op, i int) (int, int) {
+	aLine, bLine := 1, 1
+	for _, op := range ops[:i] {
+		if op.Kind != Insert {
+			aLine++
+		}
+		if op.Kind != Delete {
+			bLine++
+		}
+	}
+	return aLine, bLine
+}
+
+// hunkRange formats a hunk's start and length. Empty ranges start at the
+// line before them, as in GNU diff.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		start--
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
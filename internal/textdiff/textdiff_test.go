@@ -0,0 +1,60 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+// apply rebuilds both sides of a diff from its operations.
+func apply(ops []Op) (a, b []string) {
+	for _, op := range ops {
+		if op.Kind != Insert {
+			a = append(a, op.Line)
+		}
+		if op.Kind != Delete {
+			b = append(b, op.Line)
+		}
+	}
+	return a, b
+}
+
+func TestLines_RoundTripsAndIsMinimal(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		edits int
+	}{
+		{"", "", 0},
+		{"a\nb\nc\n", "a\nb\nc\n", 0},
+		{"", "x\ny\n", 2},
+		{"a\nb\nc\n", "a\nc\n", 1},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n", 5},
+	}
+	for _, tc := range cases {
+		a, b := SplitLines(tc.a), SplitLines(tc.b)
+		ops := Lines(a, b)
+		gotA, gotB := apply(ops)
+		if strings.Join(gotA, "\n") != strings.Join(a, "\n") || strings.Join(gotB, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("diff of %q and %q does not round trip: %+v", tc.a, tc.b, ops)
+		}
+		added, removed := Stats(ops)
+		if added+removed != tc.edits {
+			t.Fatalf("diff of %q and %q has %d edits, want %d", tc.a, tc.b, added+removed, tc.edits)
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := SplitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	b := SplitLines("1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\neleven\n")
+	got := Unified(Lines(a, b), "a", "b", 1)
+	want := "--- a\n+++ b\n" +
+		"@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n" +
+		"@@ -10 +10,2 @@\n 10\n+eleven\n"
+	if got != want {
+		t.Fatalf("unexpected unified diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := Unified(Lines(a, a), "a", "b", 3); got != "" {
+		t.Fatalf("expected empty diff for equal texts, got %q", got)
+	}
+}